@@ -2,12 +2,15 @@
 package argocd
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/estudosdevops/opsmaster/cmd/argocd/app"
 	"github.com/estudosdevops/opsmaster/cmd/argocd/cluster"
 	"github.com/estudosdevops/opsmaster/cmd/argocd/project"
 	"github.com/estudosdevops/opsmaster/cmd/argocd/repo"
+	"github.com/estudosdevops/opsmaster/internal/i18n"
+	"github.com/estudosdevops/opsmaster/internal/secrets"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -25,39 +28,49 @@ var ArgocdCmd = &cobra.Command{
 	Long:  `Um conjunto de comandos para interagir com a API do Argo CD.`,
 	// PersistentPreRunE carrega e valida a configuração antes de qualquer subcomando ser executado.
 	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
-		// Se o usuário passou as flags de conexão, elas têm prioridade máxima.
-		if cmd.Flag("server").Changed && cmd.Flag("token").Changed {
-			// As variáveis globais (serverAddr, authToken, insecure) já são preenchidas
-			// automaticamente pelo Cobra, então não precisamos fazer nada aqui.
-			return nil
+		if err := loadConnectionConfig(cmd); err != nil {
+			return err
 		}
 
-		// Se as flags não foram passadas, tentamos usar o arquivo de configuração.
-		contextName, _ := cmd.Flags().GetString("context")
-		if contextName == "" {
-			contextName = viper.GetString("current-context")
-		}
-		if contextName == "" {
-			return fmt.Errorf("nenhum contexto definido e as flags --server e --token não foram fornecidas. Use a flag --context ou defina 'current-context' no seu ~/.opsmaster.yaml")
-		}
+		// Resolve referências de segredo (ex: vault:secret/data/argocd#token) em flags sensíveis.
+		return secrets.ResolveFlags(context.Background(), cmd.Flags(), "token", "password")
+	},
+}
+
+// loadConnectionConfig preenche servidor e token a partir das flags ou do contexto do arquivo de configuração.
+func loadConnectionConfig(cmd *cobra.Command) error {
+	// Se o usuário passou as flags de conexão, elas têm prioridade máxima.
+	if cmd.Flag("server").Changed && cmd.Flag("token").Changed {
+		// As variáveis globais (serverAddr, authToken, insecure) já são preenchidas
+		// automaticamente pelo Cobra, então não precisamos fazer nada aqui.
+		return nil
+	}
 
-		// Constrói as chaves para buscar no arquivo de configuração.
-		serverKey := fmt.Sprintf("contexts.%s.argocd.server", contextName)
-		tokenKey := fmt.Sprintf("contexts.%s.argocd.token", contextName)
-		insecureKey := fmt.Sprintf("contexts.%s.argocd.insecure", contextName)
+	// Se as flags não foram passadas, tentamos usar o arquivo de configuração.
+	contextName, _ := cmd.Flags().GetString("context")
+	if contextName == "" {
+		contextName = viper.GetString("current-context")
+	}
+	if contextName == "" {
+		return i18n.Errorf("nenhum contexto definido e as flags --server e --token não foram fornecidas. Use a flag --context ou defina 'current-context' no seu ~/.opsmaster.yaml")
+	}
 
-		// Preenche as variáveis globais com os valores do arquivo.
-		serverAddr = viper.GetString(serverKey)
-		authToken = viper.GetString(tokenKey)
-		insecure = viper.GetBool(insecureKey)
+	// Constrói as chaves para buscar no arquivo de configuração.
+	serverKey := fmt.Sprintf("contexts.%s.argocd.server", contextName)
+	tokenKey := fmt.Sprintf("contexts.%s.argocd.token", contextName)
+	insecureKey := fmt.Sprintf("contexts.%s.argocd.insecure", contextName)
 
-		// Validação final: se, mesmo após ler o config, ainda não tivermos os valores, retorna um erro.
-		if serverAddr == "" || authToken == "" {
-			return fmt.Errorf("o endereço do servidor e o token do Argo CD são obrigatórios. Forneça-os via flags ou no arquivo de configuração para o contexto '%s'", contextName)
-		}
+	// Preenche as variáveis globais com os valores do arquivo.
+	serverAddr = viper.GetString(serverKey)
+	authToken = viper.GetString(tokenKey)
+	insecure = viper.GetBool(insecureKey)
 
-		return nil
-	},
+	// Validação final: se, mesmo após ler o config, ainda não tivermos os valores, retorna um erro.
+	if serverAddr == "" || authToken == "" {
+		return i18n.Errorf("o endereço do servidor e o token do Argo CD são obrigatórios. Forneça-os via flags ou no arquivo de configuração para o contexto '%s'", contextName)
+	}
+
+	return nil
 }
 
 func init() {
@@ -0,0 +1,26 @@
+package collector
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// CollectorCmd represents the collector command
+// This is the root command for the central result collector
+// Usage: opsmaster collector <operation> [flags]
+var CollectorCmd = &cobra.Command{
+	Use:   "collector",
+	Short: "Coletor central dos resultados de execuções em vários bastions",
+	Long: `Recebe, via mTLS, os resultados enviados por execuções do opsmaster em vários
+hosts (ex: um bastion por VPC) e os consolida em um único relatório.
+
+Exemplos:
+  opsmaster collector serve --listen :8443 --tls-cert collector.pem --tls-key collector-key.pem --client-ca ca.pem
+  opsmaster install puppet ... --collector-url https://collector.internal:8443 --collector-cert bastion.pem --collector-key bastion-key.pem`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Help()
+	},
+}
+
+func init() {
+	CollectorCmd.AddCommand(serveCmd)
+}
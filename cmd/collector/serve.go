@@ -0,0 +1,79 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/spf13/cobra"
+
+	"github.com/estudosdevops/opsmaster/internal/collector"
+	"github.com/estudosdevops/opsmaster/internal/logger"
+)
+
+// collector serve command flags
+var (
+	listenAddr string // Address the collector listens on
+	tlsCert    string // Collector certificate
+	tlsKey     string // Collector certificate key
+	clientCA   string // CA that signs the client certificates of the runs
+	outputFile string // Aggregated report path
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Recebe resultados de execuções remotas e consolida um relatório",
+	Long: `Inicia o coletor central. Execuções de install com --collector-url enviam o
+resultado de cada instância assim que ela termina e o relatório completo (com
+as tags) ao final; o coletor mantém um relatório por run ID e grava a
+consolidação de todos em --output a cada atualização, no formato do --report.
+
+A conexão exige mTLS: apenas clientes com certificado assinado por --client-ca
+são aceitos. Todas as execuções devem instalar o mesmo pacote. O relatório
+consolidado também pode ser consultado em GET /v1/report.
+
+Exemplos:
+  opsmaster collector serve --listen :8443 --tls-cert collector.pem --tls-key collector-key.pem --client-ca ca.pem
+  opsmaster collector serve --tls-cert collector.pem --tls-key collector-key.pem --client-ca ca.pem --output rollout.json`,
+	RunE: runServe,
+}
+
+func init() {
+	serveCmd.Flags().StringVar(&listenAddr, "listen", ":8443", "Endereço em que o coletor escuta")
+	serveCmd.Flags().StringVar(&tlsCert, "tls-cert", "", "Certificado do coletor (PEM)")
+	serveCmd.Flags().StringVar(&tlsKey, "tls-key", "", "Chave privada do certificado do coletor (PEM)")
+	serveCmd.Flags().StringVar(&clientCA, "client-ca", "", "CA que assina os certificados cliente das execuções (PEM)")
+	serveCmd.Flags().StringVar(&outputFile, "output", "collector-report.json", "Arquivo do relatório consolidado (regravado periodicamente e ao receber relatórios finais)")
+	serveCmd.MarkFlagRequired("tls-cert")
+	serveCmd.MarkFlagRequired("tls-key")
+	serveCmd.MarkFlagRequired("client-ca")
+}
+
+// runServe serves the collector until interrupted (SIGINT/SIGTERM).
+func runServe(_ *cobra.Command, _ []string) error {
+	log := logger.Get()
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	tlsConfig, err := collector.ServerTLSConfig(tlsCert, tlsKey, clientCA)
+	if err != nil {
+		return err
+	}
+	listener, err := net.Listen("tcp", listenAddr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", listenAddr, err)
+	}
+
+	server := collector.NewServer(outputFile, log)
+	log.Info("🛰️  Collector listening", "address", listener.Addr().String(), "output", outputFile)
+	if err := server.Serve(ctx, listener, tlsConfig); err != nil {
+		return fmt.Errorf("collector failed: %w", err)
+	}
+
+	report := server.Report()
+	log.Info("Collector stopped", "instances", len(report.Results), "output", outputFile)
+	return nil
+}
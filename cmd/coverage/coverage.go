@@ -0,0 +1,207 @@
+package coverage
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/estudosdevops/opsmaster/internal/cloud"
+	awsprovider "github.com/estudosdevops/opsmaster/internal/cloud/aws"
+	"github.com/estudosdevops/opsmaster/internal/cloud/provider"
+	"github.com/estudosdevops/opsmaster/internal/coverage"
+	"github.com/estudosdevops/opsmaster/internal/csv"
+	"github.com/estudosdevops/opsmaster/internal/i18n"
+	"github.com/estudosdevops/opsmaster/internal/logger"
+	"github.com/estudosdevops/opsmaster/internal/presenter"
+	"github.com/estudosdevops/opsmaster/internal/retention"
+)
+
+// coverage command flags
+var (
+	inventoryFile  string   // CSV file with the desired fleet
+	expect         []string // Expected tags (tag=value)
+	dimensions     []string // Breakdowns (account, region or CSV columns)
+	where          []string // Column selectors applied to CSV rows
+	awsProfile     string   // AWS profile to use
+	historyFile    string   // JSON lines history of the coverage ("" = disabled)
+	listUncovered  bool     // List the uncovered instances
+	outputFormat   string   // table or json
+	failBelowLimit float64  // Exit with error below this percentage (0 = never)
+)
+
+// CoverageCmd represents the coverage command
+// Usage: opsmaster coverage --inventory <file.csv> --expect <tag=value> [flags]
+var CoverageCmd = &cobra.Command{
+	Use:   "coverage",
+	Short: "Mede a cobertura da frota em relação ao estado desejado",
+	Long: `Compara o inventário (CSV) com o estado desejado da frota: a porcentagem de
+instâncias com as tags esperadas (ex: puppet=true, aplicada por "install
+puppet"), no total e por conta, região e ambiente (coluna environment do CSV).
+
+As tags são lidas do provedor (EC2 DescribeInstances). Instâncias não
+encontradas ou encerradas (terminated) contam como desconhecidas e ficam fora da
+porcentagem.
+
+Com --history, cada execução acrescenta um resumo a um arquivo JSON lines e
+mostra a variação em relação à medição anterior, para acompanhar a tendência.
+
+Exemplos:
+  opsmaster coverage --inventory fleet.csv --expect puppet=true
+
+  # Por conta e time, listando as instâncias sem cobertura
+  opsmaster coverage --inventory fleet.csv --expect puppet=true --by account,team --list-uncovered
+
+  # Medição diária com histórico (ex: em um cron)
+  opsmaster coverage --inventory s3://inventory/fleet.csv --expect puppet=true --history coverage.jsonl`,
+	RunE: runCoverage,
+}
+
+func init() {
+	CoverageCmd.Flags().StringVar(&inventoryFile, "inventory", "", "Arquivo CSV com a frota desejada: caminho local, https:// ou s3:// (aceita .gz) (obrigatório)")
+	CoverageCmd.Flags().StringArrayVar(&expect, "expect", nil, "Tag esperada nas instâncias (ex: puppet=true); pode ser repetida, todas devem casar (obrigatório)")
+	CoverageCmd.Flags().StringSliceVar(&dimensions, "by", coverage.DefaultDimensions, "Quebras do relatório: account, region ou colunas do CSV")
+	CoverageCmd.Flags().StringArrayVar(&where, "where", nil, "Seleciona instâncias por coluna do CSV (ex: environment=blue); pode ser repetida")
+	CoverageCmd.Flags().StringVar(&awsProfile, "aws-profile", "", "Perfil AWS a usar (padrão: perfil default)")
+	CoverageCmd.Flags().StringVar(&historyFile, "history", "", "Arquivo JSON lines que acumula as medições, para acompanhar a tendência (opcional)")
+	CoverageCmd.Flags().BoolVar(&listUncovered, "list-uncovered", false, "Lista as instâncias sem cobertura e as desconhecidas")
+	CoverageCmd.Flags().Float64Var(&failBelowLimit, "fail-below", 0, "Termina com erro se a cobertura ficar abaixo desta porcentagem (0 = nunca)")
+	CoverageCmd.Flags().StringVarP(&outputFormat, "output", "o", presenter.OutputTable, "Formato de saída (table|json)")
+	CoverageCmd.MarkFlagRequired("inventory")
+	CoverageCmd.MarkFlagRequired("expect")
+}
+
+// runCoverage loads the inventory, describes the instances and prints the
+// coverage report.
+func runCoverage(_ *cobra.Command, _ []string) error {
+	log := logger.Get()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	if err := presenter.ValidateOutputFormat(outputFormat); err != nil {
+		return err
+	}
+	expectations, err := coverage.ParseExpectations(expect)
+	if err != nil {
+		return err
+	}
+
+	parser := csv.NewParser(csv.CSVConfig{
+		HasHeader:      true,
+		RequiredFields: []string{"instance_id", "account", "region"},
+		CloudDefault:   "aws",
+		Delimiter:      ',',
+		ColumnAliases:  viper.GetStringMapStringSlice("csv.column_aliases"),
+	})
+	instances, err := parser.ParseSource(ctx, inventoryFile, awsprovider.S3Opener(awsProfile))
+	if err != nil {
+		return fmt.Errorf("failed to parse CSV file: %w", err)
+	}
+	instances, err = csv.SelectInstances(instances, parser.Columns(), where)
+	if err != nil {
+		return i18n.Errorf("invalid --where selector: %w", err)
+	}
+	if len(instances) == 0 {
+		return i18n.Errorf("no instances selected from CSV file")
+	}
+
+	cloudType, err := provider.DetectCloudFromInstances(instances)
+	if err != nil {
+		return fmt.Errorf("failed to detect cloud provider: %w", err)
+	}
+	var providerOptions []provider.Option
+	if awsProfile != "" {
+		providerOptions = append(providerOptions, provider.WithProfile(awsProfile))
+	}
+	cloudProvider, err := provider.NewProvider(cloudType, providerOptions...)
+	if err != nil {
+		return fmt.Errorf("failed to create cloud provider: %w", err)
+	}
+	describer := cloud.CapabilitiesOf(cloudProvider).Describe
+	if describer == nil {
+		return cloud.Unsupported(cloudProvider, "describing instances")
+	}
+
+	log.Info("🔎 Measuring fleet coverage", "inventory", inventoryFile, "instances", len(instances), "expect", expect)
+
+	infos, err := describer.DescribeInstances(ctx, instances)
+	if err != nil {
+		return fmt.Errorf("failed to describe instances: %w", err)
+	}
+
+	report := coverage.Compute(instances, infos, expectations, dimensions)
+	report.Inventory = inventoryFile
+	if historyFile != "" {
+		if report.Previous, err = coverage.LastPoint(historyFile, report.Expect); err != nil {
+			return err
+		}
+		if err := coverage.AppendPoint(historyFile, report.Point()); err != nil {
+			return err
+		}
+		log.Info("   Coverage appended to history", "file", historyFile)
+		if results, err := retention.AutoPrune(viper.GetStringMap("history"), time.Now()); err != nil {
+			log.Warn("Failed to prune run history", "error", err)
+		} else {
+			for _, result := range results {
+				if len(result.Removed) > 0 {
+					log.Info("   Run history pruned", "path", result.Path, "removed", len(result.Removed), "freed", retention.FormatSize(result.Freed))
+				}
+			}
+		}
+	}
+
+	if outputFormat == presenter.OutputJSON {
+		if err := presenter.PrintJSON(report); err != nil {
+			return err
+		}
+	} else {
+		printReport(report)
+	}
+
+	if failBelowLimit > 0 && report.Percent < failBelowLimit {
+		return fmt.Errorf("coverage %.1f%% is below --fail-below %.1f%%", report.Percent, failBelowLimit)
+	}
+	return nil
+}
+
+// printReport prints the breakdowns, the uncovered instances (with
+// --list-uncovered) and the summary.
+func printReport(report *coverage.Report) {
+	header := []string{"QUEBRA", "VALOR", "COBERTAS", "SEM COBERTURA", "DESCONHECIDAS", "COBERTURA"}
+	rows := make([][]string, 0, len(report.Groups))
+	for _, group := range report.Groups {
+		rows = append(rows, []string{
+			group.Dimension,
+			group.Value,
+			strconv.Itoa(group.Covered),
+			strconv.Itoa(group.Uncovered),
+			strconv.Itoa(group.Unknown),
+			fmt.Sprintf("%.1f%%", group.Percent),
+		})
+	}
+	presenter.PrintTable(header, rows)
+
+	if listUncovered {
+		var uncovered [][]string
+		for _, entry := range report.Entries {
+			if entry.Status != coverage.StatusCovered {
+				uncovered = append(uncovered, []string{entry.InstanceID, entry.Account, entry.Region, entry.Status, entry.Detail})
+			}
+		}
+		if len(uncovered) > 0 {
+			fmt.Println()
+			presenter.PrintTable([]string{"INSTANCE ID", "ACCOUNT", "REGION", "STATUS", "DETALHE"}, uncovered)
+		}
+	}
+
+	presenter.Printf("\n📊 Cobertura (%s): %.1f%% — %d cobertas, %d sem cobertura, %d desconhecidas\n",
+		strings.Join(report.Expect, ", "), report.Percent, report.Covered, report.Uncovered, report.Unknown)
+	if previous := report.Previous; previous != nil {
+		presenter.Printf("📈 Medição anterior (%s): %.1f%% (%+.1f pontos)\n",
+			previous.GeneratedAt.Local().Format(time.DateTime), previous.Percent, report.Percent-previous.Percent)
+	}
+}
@@ -0,0 +1,64 @@
+package ctl
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/estudosdevops/opsmaster/internal/control"
+	"github.com/estudosdevops/opsmaster/internal/presenter"
+)
+
+var pauseCmd = &cobra.Command{
+	Use:   "pause",
+	Short: "Pausa o envio de novas instâncias",
+	Long: `Pausa o envio de novas instâncias da execução. Instâncias já em execução
+terminam normalmente; as da fila aguardam "opsmaster ctl resume".
+
+Exemplos:
+  opsmaster ctl pause`,
+	RunE: runAction,
+}
+
+var resumeCmd = &cobra.Command{
+	Use:   "resume",
+	Short: "Retoma o envio de instâncias de uma execução pausada",
+	Long: `Retoma o envio de instâncias de uma execução pausada com "opsmaster ctl pause".
+
+Exemplos:
+  opsmaster ctl resume`,
+	RunE: runAction,
+}
+
+var abortCmd = &cobra.Command{
+	Use:   "abort",
+	Short: "Aborta a fila: instâncias não iniciadas são canceladas",
+	Long: `Aborta a execução: as instâncias da fila são canceladas (CANCELLED no
+relatório) e as já em execução terminam normalmente. O comando de instalação
+então encerra com o resumo e o relatório de sempre.
+
+Exemplos:
+  opsmaster ctl abort --run-id 7c9e6679-7425-40de-944b-e07fc1f90ae7`,
+	RunE: runAction,
+}
+
+// actionMessages confirm each action on the console.
+var actionMessages = map[string]string{
+	"pause":  "⏸️  Execução %s pausada: instâncias em execução terminam, a fila aguarda\n",
+	"resume": "▶️  Execução %s retomada\n",
+	"abort":  "⏹️  Execução %s abortada: a fila será cancelada, instâncias em execução terminam\n",
+}
+
+// runAction sends the action named after the command to the selected run.
+func runAction(cmd *cobra.Command, _ []string) error {
+	path, err := resolveSocket()
+	if err != nil {
+		return err
+	}
+	status, err := control.NewClient(path).Send(cmd.Context(), cmd.Name())
+	if err != nil {
+		return err
+	}
+
+	presenter.Printf(actionMessages[cmd.Name()], status.RunID)
+	printStatus(status)
+	return nil
+}
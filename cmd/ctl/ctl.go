@@ -0,0 +1,79 @@
+package ctl
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/estudosdevops/opsmaster/internal/control"
+)
+
+// Flags shared by the ctl subcommands
+var (
+	runID      string // Run to control ("" = the only run in progress)
+	socketPath string // Control socket path (overrides --run-id)
+)
+
+// CtlCmd represents the ctl command
+// This is the root command for controlling runs in progress
+// Usage: opsmaster ctl <operation> [flags]
+var CtlCmd = &cobra.Command{
+	Use:   "ctl",
+	Short: "Controla uma execução em andamento (pausar, retomar, abortar)",
+	Long: `Controla uma execução de "opsmaster install" em andamento pelo socket de
+controle local, sem matar o processo: pausa o envio de novas instâncias
+durante um incidente, retoma depois ou aborta a fila. Instâncias já em
+execução sempre terminam.
+
+Sem --run-id, usa a única execução em andamento do usuário atual.
+
+Exemplos:
+  opsmaster ctl status
+  opsmaster ctl pause
+  opsmaster ctl resume --run-id 7c9e6679-7425-40de-944b-e07fc1f90ae7`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Help()
+	},
+}
+
+func init() {
+	CtlCmd.PersistentFlags().StringVar(&runID, "run-id", "", "Run ID da execução (padrão: a única execução em andamento)")
+	CtlCmd.PersistentFlags().StringVar(&socketPath, "socket", "", "Caminho do socket de controle (sobrepõe --run-id)")
+
+	CtlCmd.AddCommand(statusCmd)
+	CtlCmd.AddCommand(pauseCmd)
+	CtlCmd.AddCommand(resumeCmd)
+	CtlCmd.AddCommand(abortCmd)
+}
+
+// resolveSocket returns the control socket of the run selected by --socket
+// or --run-id, or of the only run in progress.
+func resolveSocket() (string, error) {
+	switch {
+	case socketPath != "":
+		return socketPath, nil
+	case runID != "":
+		if err := control.CheckDir(control.Dir()); err != nil {
+			return "", err
+		}
+		return control.SocketPath(runID), nil
+	}
+
+	sockets, err := control.Discover()
+	if err != nil {
+		return "", fmt.Errorf("failed to list control sockets: %w", err)
+	}
+	switch len(sockets) {
+	case 0:
+		return "", fmt.Errorf("no run in progress found in %s", control.Dir())
+	case 1:
+		return sockets[0], nil
+	}
+	runs := make([]string, len(sockets))
+	for i, socket := range sockets {
+		runs[i] = strings.TrimSuffix(filepath.Base(socket), ".sock")
+	}
+	return "", fmt.Errorf("%d runs in progress, choose one with --run-id: %s", len(runs), strings.Join(runs, ", "))
+}
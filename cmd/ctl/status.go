@@ -0,0 +1,71 @@
+package ctl
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/estudosdevops/opsmaster/internal/control"
+	"github.com/estudosdevops/opsmaster/internal/presenter"
+)
+
+var outputFormat string // table or json
+
+var statusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Mostra o estado e o progresso de uma execução em andamento",
+	Long: `Mostra o estado (starting, running, paused, aborting) e o progresso de uma
+execução em andamento: instâncias na fila, em execução e concluídas.
+
+Exemplos:
+  opsmaster ctl status
+  opsmaster ctl status -o json`,
+	RunE: runStatus,
+}
+
+func init() {
+	statusCmd.Flags().StringVarP(&outputFormat, "output", "o", presenter.OutputTable, "Formato de saída (table|json)")
+}
+
+// runStatus prints the status of the selected run.
+func runStatus(cmd *cobra.Command, _ []string) error {
+	if err := presenter.ValidateOutputFormat(outputFormat); err != nil {
+		return err
+	}
+	path, err := resolveSocket()
+	if err != nil {
+		return err
+	}
+	status, err := control.NewClient(path).Status(cmd.Context())
+	if err != nil {
+		return err
+	}
+
+	if outputFormat == presenter.OutputJSON {
+		return presenter.PrintJSON(status)
+	}
+	printStatus(status)
+	return nil
+}
+
+// printStatus prints a run status as a field/value table.
+func printStatus(status control.Status) {
+	rows := [][]string{
+		{"Run ID", status.RunID},
+		{"Command", status.Command},
+		{"PID", strconv.Itoa(status.PID)},
+		{"State", status.State},
+		{"Started", status.StartedAt.Format(time.RFC3339)},
+	}
+	if status.PausedAt != nil {
+		rows = append(rows, []string{"Paused", time.Since(*status.PausedAt).Round(time.Second).String() + " ago"})
+	}
+	rows = append(rows,
+		[]string{"Total", strconv.Itoa(status.Total)},
+		[]string{"Queued", strconv.Itoa(status.Queued)},
+		[]string{"In flight", strconv.Itoa(status.InFlight)},
+		[]string{"Completed", strconv.Itoa(status.Completed)},
+	)
+	presenter.PrintTable([]string{"CAMPO", "VALOR"}, rows)
+}
@@ -0,0 +1,143 @@
+package ec2
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	awsprovider "github.com/estudosdevops/opsmaster/internal/cloud/aws"
+	"github.com/estudosdevops/opsmaster/internal/csv"
+	"github.com/estudosdevops/opsmaster/internal/logger"
+)
+
+// EC2 discover command flags
+var (
+	discoverTags    []string // Tag filters (key=value or key)
+	regions         []string // Regions searched in every account
+	organization    bool     // Search every active account of the AWS Organization
+	orgUnit         string   // Restrict the organization to an OU (and child OUs)
+	orgRole         string   // Role assumed in member accounts
+	tagColumns      []string // Tags copied to CSV columns
+	profileFormat   string   // aws_profile column template ("" = column omitted)
+	outputFile      string   // Inventory CSV path ("" = stdout)
+	allowPartial    bool     // Write the inventory even if accounts/regions failed
+	discoverProfile string   // AWS profile of the caller
+)
+
+// accountPlaceholder is replaced by the account ID in --profile-format.
+const accountPlaceholder = "{account}"
+
+var discoverCmd = &cobra.Command{
+	Use:   "discover",
+	Short: "Gera o inventário CSV das instâncias EC2 com as tags informadas",
+	Long: `Procura instâncias EC2 pelas tags (--discover-tags) e grava um inventário CSV
+(instance_id, account, region, cloud, name e as colunas de --tag-columns), aceito por
+--instances-file nos demais comandos.
+
+Por padrão procura apenas na conta do perfil AWS. Com --organization, lista as contas
+ativas do AWS Organizations (ou de uma OU e suas OUs filhas, com --organizational-unit),
+assume a role --org-role em cada conta e procura em todas as regiões de --regions,
+gerando um único inventário: não é preciso manter a lista de contas à mão. O perfil
+precisa ser da conta de gerenciamento ou de um administrador delegado.
+
+Contas ou regiões que falham (ex: role não assumível) são listadas e o comando termina
+com erro sem gravar o inventário, a menos que --allow-partial seja informado.
+
+Exemplos:
+  opsmaster ec2 discover --discover-tags env=prod --output-file prod.csv
+
+  # Toda a organização, em duas regiões, com perfis AWS por conta
+  opsmaster ec2 discover --discover-tags puppet=true --organization \
+    --regions us-east-1,sa-east-1 --profile-format "org-{account}" --output-file fleet.csv
+
+  # Uma OU, com a coluna team para usar em --where
+  opsmaster ec2 discover --discover-tags env=prod --organizational-unit ou-ab12-34cd5678 \
+    --tag-columns team --output-file prod.csv`,
+	RunE: runDiscover,
+}
+
+func init() {
+	discoverCmd.Flags().StringArrayVar(&discoverTags, "discover-tags", nil, "Tag que as instâncias devem ter: chave=valor (aceita * e ?) ou só a chave (qualquer valor); pode ser repetida, a mesma chave aceita qualquer dos valores (obrigatório)")
+	discoverCmd.Flags().StringSliceVar(&regions, "regions", nil, "Regiões procuradas em cada conta (padrão: região do perfil AWS)")
+	discoverCmd.Flags().BoolVar(&organization, "organization", false, "Procura em todas as contas ativas do AWS Organizations, assumindo --org-role em cada uma")
+	discoverCmd.Flags().StringVar(&orgUnit, "organizational-unit", "", "Restringe a organização às contas de uma OU e suas OUs filhas (ex: ou-ab12-34cd5678; implica --organization)")
+	discoverCmd.Flags().StringVar(&orgRole, "org-role", awsprovider.DefaultDiscoveryRole, "Role assumida nas contas membro (a conta do perfil usa as próprias credenciais)")
+	discoverCmd.Flags().StringSliceVar(&tagColumns, "tag-columns", nil, "Tags copiadas para colunas do inventário (ex: team,environment)")
+	discoverCmd.Flags().StringVar(&profileFormat, "profile-format", "", "Preenche a coluna aws_profile com o perfil de cada conta, substituindo {account} pelo ID (ex: org-{account}); sem ela, os comandos usam o ID da conta como perfil")
+	discoverCmd.Flags().StringVar(&outputFile, "output-file", "", "Arquivo CSV do inventário (padrão: saída padrão)")
+	discoverCmd.Flags().BoolVar(&allowPartial, "allow-partial", false, "Grava o inventário mesmo se contas ou regiões falharem (listadas no log)")
+	discoverCmd.Flags().StringVar(&discoverProfile, "aws-profile", "", "Perfil AWS a usar (padrão: perfil default)")
+	discoverCmd.MarkFlagRequired("discover-tags")
+}
+
+// runDiscover finds the instances and writes the merged inventory.
+func runDiscover(_ *cobra.Command, _ []string) error {
+	log := logger.Get()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	tags, err := awsprovider.ParseTagFilters(discoverTags)
+	if err != nil {
+		return err
+	}
+	if profileFormat != "" && !strings.Contains(profileFormat, accountPlaceholder) {
+		return fmt.Errorf("--profile-format %q must contain %s", profileFormat, accountPlaceholder)
+	}
+	opts := awsprovider.DiscoveryOptions{
+		Profile:            discoverProfile,
+		Regions:            regions,
+		Tags:               tags,
+		Organization:       organization,
+		OrganizationalUnit: orgUnit,
+		Role:               orgRole,
+		TagColumns:         tagColumns,
+	}
+
+	log.Info("🔎 Discovering EC2 instances", "tags", discoverTags, "organization", organization || orgUnit != "", "regions", regions)
+	result, err := awsprovider.Discover(ctx, opts)
+	if err != nil {
+		return fmt.Errorf("discovery failed: %w", err)
+	}
+	for _, failure := range result.Failures {
+		log.Warn("Discovery failed", "account", failure.Account, "region", failure.Region, "error", failure.Err)
+	}
+	log.Info("✅ Discovery finished", "accounts", len(result.Accounts), "regions", len(result.Regions),
+		"instances", len(result.Instances), "failures", len(result.Failures))
+	if len(result.Failures) > 0 && !allowPartial {
+		return fmt.Errorf("discovery failed in %d account/region(s); use --allow-partial to write the inventory anyway", len(result.Failures))
+	}
+
+	columns := []string{"name"}
+	for _, column := range tagColumns {
+		if !slices.ContainsFunc(columns, func(c string) bool { return strings.EqualFold(c, column) }) {
+			columns = append(columns, column)
+		}
+	}
+	if profileFormat != "" {
+		columns = append(columns, "aws_profile")
+		for _, instance := range result.Instances {
+			instance.Metadata["aws_profile"] = strings.ReplaceAll(profileFormat, accountPlaceholder, instance.Account)
+		}
+	}
+
+	if outputFile == "" {
+		return csv.WriteInstances(os.Stdout, result.Instances, columns)
+	}
+	file, err := os.Create(outputFile)
+	if err != nil {
+		return fmt.Errorf("failed to create inventory file: %w", err)
+	}
+	if err := csv.WriteInstances(file, result.Instances, columns); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write inventory file: %w", err)
+	}
+	log.Info("📄 Inventory written", "file", outputFile, "instances", len(result.Instances))
+	return nil
+}
@@ -0,0 +1,37 @@
+package ec2
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// Ec2Cmd represents the ec2 command
+// This is the root command for EC2 instance operations
+// Usage: opsmaster ec2 <operation> [flags]
+var Ec2Cmd = &cobra.Command{
+	Use:   "ec2",
+	Short: "Operações em instâncias EC2",
+	Long: `Operações em lote sobre instâncias EC2 listadas em arquivo CSV, e geração do
+inventário CSV por tags (discover).
+
+Útil para ligar instâncias antes de um rollout de agentes (ex: install puppet)
+e desligá-las ao final, sem scripts separados.
+
+Exemplos:
+  # Iniciar instâncias e aguardar estado running
+  opsmaster ec2 start --instances-file fleet.csv --wait
+
+  # Parar instâncias
+  opsmaster ec2 stop --instances-file fleet.csv
+
+  # Inventário das instâncias com a tag env=prod em todas as contas da organização
+  opsmaster ec2 discover --discover-tags env=prod --organization --output-file prod.csv`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Help()
+	},
+}
+
+func init() {
+	Ec2Cmd.AddCommand(startCmd)
+	Ec2Cmd.AddCommand(stopCmd)
+	Ec2Cmd.AddCommand(discoverCmd)
+}
@@ -0,0 +1,275 @@
+package ec2
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/estudosdevops/opsmaster/internal/cloud"
+	awsprovider "github.com/estudosdevops/opsmaster/internal/cloud/aws"
+	"github.com/estudosdevops/opsmaster/internal/cloud/provider"
+	"github.com/estudosdevops/opsmaster/internal/csv"
+	"github.com/estudosdevops/opsmaster/internal/i18n"
+	"github.com/estudosdevops/opsmaster/internal/logger"
+	"github.com/estudosdevops/opsmaster/internal/presenter"
+	"github.com/estudosdevops/opsmaster/internal/quarantine"
+)
+
+// EC2 power command flags (shared by start and stop, only one runs per invocation)
+var (
+	instancesFile string        // CSV file with instance list
+	awsProfile    string        // AWS profile to use
+	wait          bool          // Wait for instances to reach target state
+	waitTimeout   time.Duration // Max time to wait for target state
+	includeMaint  bool          // Process instances in maintenance mode
+	maintTag      string        // Tag key marking maintenance mode
+	where         []string      // Column selectors applied to CSV rows
+)
+
+// powerAction describes a start or stop operation.
+type powerAction struct {
+	name        string // "start" or "stop"
+	targetState string // State reached when the action completes
+}
+
+// powerResult is the outcome of a power action on a single instance.
+type powerResult struct {
+	instance      *cloud.Instance
+	previousState string
+	skipReason    string
+	err           error
+}
+
+var startCmd = &cobra.Command{
+	Use:   "start",
+	Short: "Inicia instâncias EC2 listadas no CSV",
+	Long: `Inicia as instâncias EC2 listadas no arquivo CSV.
+
+Instâncias já em execução, terminadas ou em modo manutenção são ignoradas. Com --wait, aguarda
+até que todas as instâncias atinjam o estado running (ou o timeout expire).
+
+Exemplos:
+  opsmaster ec2 start --instances-file fleet.csv --wait
+  opsmaster ec2 start --instances-file fleet.csv --wait --wait-timeout 10m`,
+	RunE: runPowerAction(powerAction{name: "start", targetState: cloud.InstanceStateRunning}),
+}
+
+var stopCmd = &cobra.Command{
+	Use:   "stop",
+	Short: "Para instâncias EC2 listadas no CSV",
+	Long: `Para as instâncias EC2 listadas no arquivo CSV.
+
+Instâncias já paradas, terminadas ou em modo manutenção são ignoradas. Com --wait, aguarda
+até que todas as instâncias atinjam o estado stopped (ou o timeout expire).
+
+Exemplos:
+  opsmaster ec2 stop --instances-file fleet.csv
+  opsmaster ec2 stop --instances-file fleet.csv --wait`,
+	RunE: runPowerAction(powerAction{name: "stop", targetState: cloud.InstanceStateStopped}),
+}
+
+func init() {
+	for _, cmd := range []*cobra.Command{startCmd, stopCmd} {
+		cmd.Flags().StringVar(&instancesFile, "instances-file", "", "Arquivo CSV com lista de instâncias: caminho local, https:// ou s3:// (aceita .gz) (obrigatório)")
+		cmd.Flags().StringVar(&awsProfile, "aws-profile", "", "Perfil AWS a usar (padrão: perfil default)")
+		cmd.Flags().BoolVar(&wait, "wait", false, "Aguardar as instâncias atingirem o estado final")
+		cmd.Flags().DurationVar(&waitTimeout, "wait-timeout", 5*time.Minute, "Tempo máximo de espera com --wait")
+		cmd.Flags().BoolVar(&includeMaint, "include-maintenance", false, "Processar também instâncias em modo manutenção")
+		cmd.Flags().StringVar(&maintTag, "maintenance-tag", cloud.DefaultMaintenanceTagKey, "Tag que marca instâncias em modo manutenção (valor true)")
+		cmd.Flags().StringArrayVar(&where, "where", nil, "Seleciona instâncias por coluna do CSV (ex: environment=blue); pode ser repetida")
+		cmd.MarkFlagRequired("instances-file")
+	}
+}
+
+// runPowerAction returns the RunE function for a start/stop command.
+func runPowerAction(action powerAction) func(cmd *cobra.Command, args []string) error {
+	return func(_ *cobra.Command, _ []string) error {
+		log := logger.Get()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		parser := csv.NewParser(csv.CSVConfig{
+			HasHeader:      true,
+			RequiredFields: []string{"instance_id", "account", "region"},
+			CloudDefault:   "aws",
+			Delimiter:      ',',
+			ColumnAliases:  viper.GetStringMapStringSlice("csv.column_aliases"),
+		})
+		instances, err := parser.ParseSource(ctx, instancesFile, awsprovider.S3Opener(awsProfile))
+		if err != nil {
+			return fmt.Errorf("failed to parse CSV file: %w", err)
+		}
+		instances, err = csv.SelectInstances(instances, parser.Columns(), where)
+		if err != nil {
+			return i18n.Errorf("invalid --where selector: %w", err)
+		}
+		instances = quarantine.Exclude(log, instances)
+		if len(instances) == 0 {
+			return i18n.Errorf("no instances selected from CSV file")
+		}
+
+		cloudType, err := provider.DetectCloudFromInstances(instances)
+		if err != nil {
+			return fmt.Errorf("failed to detect cloud provider: %w", err)
+		}
+
+		var providerOptions []provider.Option
+		if awsProfile != "" {
+			providerOptions = append(providerOptions, provider.WithProfile(awsProfile))
+		}
+
+		cloudProvider, err := provider.NewProvider(cloudType, providerOptions...)
+		if err != nil {
+			return fmt.Errorf("failed to create cloud provider: %w", err)
+		}
+
+		caps := cloud.CapabilitiesOf(cloudProvider)
+		if err := checkPowerCapabilities(cloudProvider, caps, action); err != nil {
+			return err
+		}
+
+		log.Info("⚡ Changing instance state",
+			"action", action.name,
+			"total_instances", len(instances),
+			"wait", wait)
+
+		results := executePowerAction(ctx, caps, instances, action)
+		printPowerResults(results)
+
+		failed := 0
+		for _, r := range results {
+			if r.err != nil {
+				failed++
+			}
+		}
+		if failed > 0 {
+			return fmt.Errorf("%s failed for %d instances", action.name, failed)
+		}
+		return nil
+	}
+}
+
+// checkPowerCapabilities returns an error naming the first provider capability
+// the action needs but the provider lacks.
+func checkPowerCapabilities(provider cloud.CloudProvider, caps cloud.Capabilities, action powerAction) error {
+	switch {
+	case caps.Describe == nil:
+		return cloud.Unsupported(provider, "describing instance states")
+	case action.name == "start" && caps.Start == nil:
+		return cloud.Unsupported(provider, "starting instances")
+	case action.name == "stop" && caps.Stop == nil:
+		return cloud.Unsupported(provider, "stopping instances")
+	case wait && caps.Start == nil:
+		return cloud.Unsupported(provider, "waiting for instance states (--wait)")
+	}
+	return nil
+}
+
+// executePowerAction runs the action on instances not already in the target state
+// and optionally waits for them to reach it. Returns one result per instance.
+// The capabilities the action needs must be checked (checkPowerCapabilities).
+func executePowerAction(ctx context.Context, caps cloud.Capabilities, instances []*cloud.Instance, action powerAction) []*powerResult {
+	results := make([]*powerResult, 0, len(instances))
+	var targets []*cloud.Instance
+
+	infos, err := caps.Describe.DescribeInstances(ctx, instances)
+	if err != nil {
+		logger.Get().Warn("Failed to fetch current instance states", "error", err)
+	}
+
+	for _, instance := range instances {
+		result := &powerResult{instance: instance}
+		info := infos[instance.ID]
+		if info != nil {
+			result.previousState = info.State
+		}
+
+		switch {
+		case !includeMaint && cloud.InMaintenance(info, maintTag):
+			result.skipReason = fmt.Sprintf("instance in maintenance mode (%s=true)", maintTag)
+		case result.previousState == action.targetState:
+			result.skipReason = "already " + action.targetState
+		case result.previousState == cloud.InstanceStateTerminated, result.previousState == cloud.InstanceStateShuttingDown:
+			result.skipReason = "instance is " + result.previousState
+		default:
+			targets = append(targets, instance)
+		}
+		results = append(results, result)
+	}
+
+	if len(targets) == 0 {
+		return results
+	}
+
+	var failures map[string]error
+	if action.name == "start" {
+		failures = caps.Start.StartInstances(ctx, targets)
+	} else {
+		failures = caps.Stop.StopInstances(ctx, targets)
+	}
+	if failures == nil {
+		failures = make(map[string]error)
+	}
+
+	if wait {
+		var requested []*cloud.Instance
+		for _, instance := range targets {
+			if _, failed := failures[instance.ID]; !failed {
+				requested = append(requested, instance)
+			}
+		}
+		if len(requested) > 0 {
+			for id, err := range caps.Start.WaitForState(ctx, requested, action.targetState, waitTimeout) {
+				failures[id] = err
+			}
+		}
+	}
+
+	for _, result := range results {
+		result.err = failures[result.instance.ID]
+	}
+
+	return results
+}
+
+// printPowerResults prints per-instance results and a summary line.
+func printPowerResults(results []*powerResult) {
+	header := []string{"INSTANCE ID", "ACCOUNT", "REGION", "ESTADO ANTERIOR", "STATUS", "DETALHE"}
+	rows := make([][]string, 0, len(results))
+
+	changed, skipped, failed := 0, 0, 0
+	for _, r := range results {
+		status, detail := "✅", ""
+		switch {
+		case r.err != nil:
+			status, detail = "❌", r.err.Error()
+			failed++
+		case r.skipReason != "":
+			status, detail = "⏭️", r.skipReason
+			skipped++
+		default:
+			changed++
+		}
+
+		previousState := r.previousState
+		if previousState == "" {
+			previousState = "-"
+		}
+
+		rows = append(rows, []string{
+			r.instance.Label(),
+			r.instance.Account,
+			r.instance.Region,
+			previousState,
+			status,
+			detail,
+		})
+	}
+
+	fmt.Println()
+	presenter.PrintTable(header, rows)
+	presenter.Printf("\n📊 Summary: %d changed, %d skipped, %d failed\n", changed, skipped, failed)
+}
@@ -0,0 +1,26 @@
+package facts
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// FactsCmd represents the facts command
+// This is the root command for fleet-wide Facter queries
+// Usage: opsmaster facts <operation> [flags]
+var FactsCmd = &cobra.Command{
+	Use:   "facts",
+	Short: "Consulta facts (Facter) na frota",
+	Long: `Consulta facts do Facter diretamente nas instâncias listadas em arquivo CSV,
+para responder perguntas de inventário sem acessar o Puppetboard ou cada máquina.
+
+Exemplos:
+  # Comparar versão do SO e ambiente entre as instâncias
+  opsmaster facts get --fact os.release.full,location.environment --instances-file fleet.csv`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Help()
+	},
+}
+
+func init() {
+	FactsCmd.AddCommand(getCmd)
+}
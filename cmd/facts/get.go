@@ -0,0 +1,224 @@
+package facts
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/estudosdevops/opsmaster/cmd/fleet"
+	"github.com/estudosdevops/opsmaster/internal/cloud"
+	"github.com/estudosdevops/opsmaster/internal/executor"
+	"github.com/estudosdevops/opsmaster/internal/facter"
+	"github.com/estudosdevops/opsmaster/internal/logger"
+	"github.com/estudosdevops/opsmaster/internal/presenter"
+)
+
+// facts get command flags
+var (
+	instancesFile  string        // CSV file with instance list
+	awsProfile     string        // AWS profile to use
+	where          []string      // Column selectors applied to CSV rows
+	factPaths      []string      // Dotted fact paths to extract
+	maxConcurrency int           // Max simultaneous queries
+	timeout        time.Duration // Per-instance command timeout
+	outputFormat   string        // table or json
+	includeMaint   bool          // Query instances in maintenance mode
+	maintenanceTag string        // Tag key marking maintenance mode
+)
+
+// factRow is the result of a fact query on one instance.
+type factRow struct {
+	InstanceID string         `json:"instance_id"`
+	Name       string         `json:"name,omitempty"` // Display name (--display-column)
+	Account    string         `json:"account"`
+	Region     string         `json:"region"`
+	Facts      map[string]any `json:"facts"`                 // Requested path -> value (null when missing)
+	Error      string         `json:"error,omitempty"`       // Query failure on the instance
+	SkipReason string         `json:"skip_reason,omitempty"` // Why the instance wasn't queried (e.g., maintenance mode)
+}
+
+var getCmd = &cobra.Command{
+	Use:   "get",
+	Short: "Extrai facts das instâncias e compara os valores",
+	Long: `Executa "facter --json" em cada instância (via SSM), extrai os facts pedidos e
+mostra uma tabela comparativa (uma coluna por fact) ou JSON.
+
+Os facts usam a notação de pontos do Facter: hashes por chave e arrays por índice
+(ex: os.release.full, processors.models.0). Facts estruturados aparecem como JSON.
+Apenas os facts de primeiro nível pedidos são coletados, o que mantém a saída dentro
+do limite do SSM. O Facter do Puppet (/opt/puppetlabs/bin/facter) é preferido, pois
+também carrega os facts externos (ex: location.yaml criado pelo install puppet).
+
+Exemplos:
+  opsmaster facts get --fact os.release.full,location.environment --instances-file fleet.csv
+
+  # JSON para processar com jq
+  opsmaster facts get --fact kernelrelease --instances-file fleet.csv -o json | jq '.[].facts'`,
+	RunE: runGet,
+}
+
+func init() {
+	getCmd.Flags().StringSliceVar(&factPaths, "fact", nil, "Facts a extrair, separados por vírgula (ex: os.release.full,location.environment) (obrigatório)")
+	getCmd.Flags().StringVar(&instancesFile, "instances-file", "", "Arquivo CSV com lista de instâncias: caminho local, https:// ou s3:// (aceita .gz) (obrigatório)")
+	getCmd.Flags().StringArrayVar(&where, "where", nil, "Seleciona instâncias por coluna do CSV (ex: environment=blue); pode ser repetida")
+	getCmd.Flags().StringVar(&awsProfile, "aws-profile", "", "Perfil AWS a usar (padrão: perfil default)")
+	getCmd.Flags().IntVar(&maxConcurrency, "max-concurrency", 20, "Máximo de consultas em paralelo")
+	getCmd.Flags().DurationVar(&timeout, "timeout", time.Minute, "Tempo máximo do facter em cada instância")
+	getCmd.Flags().StringVarP(&outputFormat, "output", "o", presenter.OutputTable, "Formato de saída (table|json)")
+	getCmd.Flags().BoolVar(&includeMaint, "include-maintenance", false, "Processar também instâncias em modo manutenção")
+	getCmd.Flags().StringVar(&maintenanceTag, "maintenance-tag", cloud.DefaultMaintenanceTagKey, "Tag que marca instâncias em modo manutenção (valor true)")
+	getCmd.MarkFlagRequired("fact")
+	getCmd.MarkFlagRequired("instances-file")
+}
+
+// runGet queries the facts on every selected instance and prints them.
+func runGet(_ *cobra.Command, _ []string) error {
+	log := logger.Get()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	if err := presenter.ValidateOutputFormat(outputFormat); err != nil {
+		return err
+	}
+	if err := facter.ValidatePaths(factPaths); err != nil {
+		return err
+	}
+
+	instances, skipped, cloudProvider, err := fleet.Load(ctx, log, fleet.Selection{
+		InstancesFile:      instancesFile,
+		AWSProfile:         awsProfile,
+		Where:              where,
+		IncludeMaintenance: includeMaint,
+		MaintenanceTag:     maintenanceTag,
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Info("🔎 Querying facts", "facts", strings.Join(factPaths, ","), "instances", len(instances), "skipped", len(skipped))
+
+	rows := make([]*factRow, len(instances), len(instances)+len(skipped))
+	rowFor := make(map[*cloud.Instance]*factRow, len(instances))
+	for i, instance := range instances {
+		rows[i] = &factRow{InstanceID: instance.ID, Name: instance.DisplayName(), Account: instance.Account, Region: instance.Region}
+		rowFor[instance] = rows[i]
+	}
+
+	command := []string{facter.QueryCommand(factPaths)}
+	errs := executor.ForEachInstance(ctx, instances, maxConcurrency, func(ctx context.Context, instance *cloud.Instance) error {
+		facts, err := queryFacts(ctx, cloudProvider, instance, command)
+		rowFor[instance].Facts = facts
+		return err
+	})
+
+	failed := 0
+	for i, err := range errs {
+		if err != nil {
+			rows[i].Error = err.Error()
+			failed++
+		}
+	}
+	for _, result := range skipped {
+		instance := result.Instance
+		rows = append(rows, &factRow{InstanceID: instance.ID, Name: instance.DisplayName(), Account: instance.Account,
+			Region: instance.Region, SkipReason: result.SkipReason})
+	}
+
+	if outputFormat == presenter.OutputJSON {
+		if err := presenter.PrintJSON(rows); err != nil {
+			return err
+		}
+	} else {
+		printFactsTable(rows)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("fact query failed for %d instances", failed)
+	}
+	return nil
+}
+
+// queryFacts runs facter on an instance and extracts the requested paths.
+// Missing facts map to nil.
+func queryFacts(ctx context.Context, cloudProvider cloud.CloudProvider, instance *cloud.Instance, command []string) (map[string]any, error) {
+	result, err := cloudProvider.ExecuteCommandWithOptions(cloud.ReadOnlyCommands(ctx), instance, command, timeout, cloud.ExecOptions{Comment: "facts get"})
+	if err != nil {
+		return nil, err
+	}
+	if result.ExitCode != 0 {
+		return nil, fmt.Errorf("facter failed (exit code %d): %s", result.ExitCode, strings.TrimSpace(result.Stderr))
+	}
+
+	all, err := facter.Parse(result.Stdout)
+	if err != nil {
+		return nil, err
+	}
+	facts := make(map[string]any, len(factPaths))
+	for _, path := range factPaths {
+		value, _ := facter.Lookup(all, path)
+		facts[path] = value
+	}
+	return facts, nil
+}
+
+// printFactsTable prints one column per fact and the distinct values of
+// each fact across the fleet.
+func printFactsTable(rows []*factRow) {
+	header := append([]string{"INSTANCE ID", "ACCOUNT", "REGION"}, factPaths...)
+	header = append(header, "ERRO")
+
+	distinct := make(map[string]map[string]int, len(factPaths))
+	tableRows := make([][]string, 0, len(rows))
+	for _, row := range rows {
+		cells := []string{cloud.FormatLabel(row.InstanceID, row.Name), row.Account, row.Region}
+		for _, path := range factPaths {
+			value := "-"
+			if row.Error == "" && row.SkipReason == "" {
+				if formatted := facter.Format(row.Facts[path]); formatted != "" {
+					value = formatted
+				}
+				if distinct[path] == nil {
+					distinct[path] = make(map[string]int)
+				}
+				distinct[path][value]++
+			}
+			cells = append(cells, value)
+		}
+		status := presenter.OrDash(row.Error)
+		if row.SkipReason != "" {
+			status = "⏭️ " + row.SkipReason
+		}
+		tableRows = append(tableRows, append(cells, status))
+	}
+	presenter.PrintTable(header, tableRows)
+
+	fmt.Println()
+	for _, path := range factPaths {
+		presenter.Printf("📊 %s: %s\n", path, summarizeValues(distinct[path]))
+	}
+}
+
+// summarizeValues formats value counts, most frequent first.
+func summarizeValues(counts map[string]int) string {
+	if len(counts) == 0 {
+		return "-"
+	}
+	values := make([]string, 0, len(counts))
+	for value := range counts {
+		values = append(values, value)
+	}
+	slices.SortFunc(values, func(a, b string) int {
+		return cmp.Or(cmp.Compare(counts[b], counts[a]), cmp.Compare(a, b))
+	})
+
+	parts := make([]string, len(values))
+	for i, value := range values {
+		parts[i] = fmt.Sprintf("%s (%d)", value, counts[value])
+	}
+	return strings.Join(parts, " | ")
+}
@@ -0,0 +1,83 @@
+// Package fleet loads the instances a read-only fleet command (facts get,
+// logs tail) acts on and builds the cloud provider to reach them.
+package fleet
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/spf13/viper"
+
+	"github.com/estudosdevops/opsmaster/internal/cloud"
+	awsprovider "github.com/estudosdevops/opsmaster/internal/cloud/aws"
+	"github.com/estudosdevops/opsmaster/internal/cloud/provider"
+	"github.com/estudosdevops/opsmaster/internal/csv"
+	"github.com/estudosdevops/opsmaster/internal/executor"
+	"github.com/estudosdevops/opsmaster/internal/i18n"
+	"github.com/estudosdevops/opsmaster/internal/logger"
+	"github.com/estudosdevops/opsmaster/internal/quarantine"
+)
+
+// Selection is what the command flags select from the instances CSV.
+type Selection struct {
+	InstancesFile string   // --instances-file: local path, https:// or s3://
+	AWSProfile    string   // --aws-profile: empty = default profile
+	Where         []string // --where column selectors
+
+	IncludeMaintenance bool   // --include-maintenance: keep instances in maintenance mode
+	MaintenanceTag     string // --maintenance-tag: empty = cloud.DefaultMaintenanceTagKey
+}
+
+// Load parses the instances CSV, applies the --where selectors, drops
+// quarantined instances and creates the provider of the remaining ones,
+// labeling its commands with the run ID and operator. Unless
+// IncludeMaintenance is set, instances in maintenance mode are left out too
+// and returned as Skipped results, so the command can show the reason.
+func Load(ctx context.Context, log *slog.Logger, sel Selection) ([]*cloud.Instance, []*executor.ExecutionResult, cloud.CloudProvider, error) {
+	parser := csv.NewParser(csv.CSVConfig{
+		HasHeader:      true,
+		RequiredFields: []string{"instance_id", "account", "region"},
+		CloudDefault:   "aws",
+		Delimiter:      ',',
+		ColumnAliases:  viper.GetStringMapStringSlice("csv.column_aliases"),
+	})
+	instances, err := parser.ParseSource(ctx, sel.InstancesFile, awsprovider.S3Opener(sel.AWSProfile))
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to parse CSV file: %w", err)
+	}
+	instances, err = csv.SelectInstances(instances, parser.Columns(), sel.Where)
+	if err != nil {
+		return nil, nil, nil, i18n.Errorf("invalid --where selector: %w", err)
+	}
+	instances = quarantine.Exclude(log, instances)
+	if len(instances) == 0 {
+		return nil, nil, nil, i18n.Errorf("no instances selected from CSV file")
+	}
+
+	cloudType, err := provider.DetectCloudFromInstances(instances)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to detect cloud provider: %w", err)
+	}
+	providerOptions := []provider.Option{provider.WithCommandLabel(cloud.CommandLabel{
+		Prefix:   cloud.DefaultCommandPrefix,
+		RunID:    logger.RunID(),
+		Operator: cloud.CurrentOperator(),
+	})}
+	if sel.AWSProfile != "" {
+		providerOptions = append(providerOptions, provider.WithProfile(sel.AWSProfile))
+	}
+	cloudProvider, err := provider.NewProvider(cloudType, providerOptions...)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to create cloud provider: %w", err)
+	}
+
+	var skipped []*executor.ExecutionResult
+	if !sel.IncludeMaintenance {
+		instances, skipped, _, err = executor.ExcludeMaintenance(ctx, cloudProvider, instances, sel.MaintenanceTag)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+	}
+	return instances, skipped, cloudProvider, nil
+}
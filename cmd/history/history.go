@@ -0,0 +1,30 @@
+package history
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// HistoryCmd represents the history command
+// This is the root command for the run artifacts kept on disk
+// Usage: opsmaster history <operation> [flags]
+var HistoryCmd = &cobra.Command{
+	Use:   "history",
+	Short: "Gerencia os artefatos de execuções anteriores (relatórios e históricos)",
+	Long: `Gerencia os artefatos que as execuções deixam no disco: os relatórios JSON de
+install ... --report e os históricos de coverage --history. Os diretórios,
+arquivos e limites de retenção ficam na seção history do arquivo de
+configuração.
+
+Exemplos:
+  opsmaster history prune --keep 90d --max-size 1GB
+
+  # Mostrar o que seria removido, sem remover
+  opsmaster history prune --dry-run`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Help()
+	},
+}
+
+func init() {
+	HistoryCmd.AddCommand(pruneCmd)
+}
@@ -0,0 +1,133 @@
+package history
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/estudosdevops/opsmaster/internal/i18n"
+	"github.com/estudosdevops/opsmaster/internal/logger"
+	"github.com/estudosdevops/opsmaster/internal/presenter"
+	"github.com/estudosdevops/opsmaster/internal/retention"
+)
+
+// history prune command flags
+var (
+	keepAge    string   // Remove artifacts older than this (e.g., 90d)
+	maxSize    string   // Size limit of each report directory or history file (e.g., 1GB)
+	reportDirs []string // Report directories, overriding history.report_dirs
+	files      []string // History files, overriding history.files
+	dryRun     bool     // Show what would be removed without removing
+)
+
+var pruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Remove relatórios e pontos de histórico antigos",
+	Long: `Remove os artefatos antigos dos diretórios de relatórios (history.report_dirs
+ou --dir) e dos arquivos de histórico de cobertura (history.files ou --file):
+primeiro os mais antigos que --keep, depois os mais antigos até que cada
+diretório ou arquivo caiba em --max-size. O artefato mais recente nunca é
+removido por tamanho.
+
+Nos diretórios, só são considerados relatórios de execução (arquivos .json
+gravados por --report); relatórios de execuções em andamento (com o arquivo
+<relatório>.lock) são mantidos. Nos históricos, os pontos são removidos pelo
+campo generated_at e o arquivo é regravado.
+
+  history:
+    report_dirs: [/var/lib/opsmaster/reports]
+    files: [/var/lib/opsmaster/coverage.jsonl]
+    keep: 90d
+    max_size: 1GB
+    auto_prune: true   # poda após cada install --report e coverage --history
+
+Exemplos:
+  opsmaster history prune --keep 90d --max-size 1GB
+
+  # Mostrar o que seria removido, sem remover
+  opsmaster history prune --dir /var/lib/opsmaster/reports --keep 30d --dry-run`,
+	RunE: runPrune,
+}
+
+func init() {
+	pruneCmd.Flags().StringVar(&keepAge, "keep", "", "Remove artefatos mais antigos que esta idade (ex: 90d, 2w, 36h) (padrão: history.keep)")
+	pruneCmd.Flags().StringVar(&maxSize, "max-size", "", "Tamanho máximo de cada diretório ou arquivo (ex: 1GB, 500MB) (padrão: history.max_size)")
+	pruneCmd.Flags().StringSliceVar(&reportDirs, "dir", nil, "Diretório de relatórios de execução (repetível) (padrão: history.report_dirs)")
+	pruneCmd.Flags().StringSliceVar(&files, "file", nil, "Arquivo de histórico de cobertura (repetível) (padrão: history.files)")
+	pruneCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Mostra o que seria removido, sem remover")
+}
+
+// runPrune prunes the report directories and history files of the config,
+// with the flags overriding the history section.
+func runPrune(cmd *cobra.Command, args []string) error {
+	log := logger.Get()
+	cfg, err := retention.ParseConfig(viper.GetStringMap("history"))
+	if err != nil {
+		return err
+	}
+	if cmd.Flags().Changed("keep") {
+		if cfg.Keep, err = retention.ParseAge(keepAge); err != nil {
+			return fmt.Errorf("--keep: %w", err)
+		}
+	}
+	if cmd.Flags().Changed("max-size") {
+		if cfg.MaxSize, err = retention.ParseSize(maxSize); err != nil {
+			return fmt.Errorf("--max-size: %w", err)
+		}
+	}
+	if cmd.Flags().Changed("dir") || cmd.Flags().Changed("file") {
+		cfg.ReportDirs, cfg.Files = reportDirs, files
+	}
+	if len(cfg.ReportDirs) == 0 && len(cfg.Files) == 0 {
+		return i18n.Errorf("nothing to prune: use --dir/--file or set history.report_dirs/history.files in the config file")
+	}
+	if !cfg.Enabled() {
+		return i18n.Errorf("no retention limit: use --keep/--max-size or set history.keep/history.max_size in the config file")
+	}
+
+	log.Info("🧹 Pruning run history", "report_dirs", cfg.ReportDirs, "files", cfg.Files,
+		"keep", cfg.Keep, "max_size", cfg.MaxSize, "dry_run", dryRun)
+	results, err := cfg.Prune(time.Now(), dryRun)
+	printResults(results)
+	if err != nil {
+		return err
+	}
+
+	removed, freed := 0, int64(0)
+	for _, result := range results {
+		if dryRun {
+			for _, path := range result.Removed {
+				presenter.Printf("   %s\n", path)
+			}
+		}
+		removed += len(result.Removed)
+		freed += result.Freed
+	}
+	if dryRun {
+		presenter.Printf("\n🔍 Dry run: %d item(s) would be removed, freeing %s\n", removed, retention.FormatSize(freed))
+		return nil
+	}
+	presenter.Printf("\n🧹 %d item(s) removed, %s freed\n", removed, retention.FormatSize(freed))
+	return nil
+}
+
+// printResults prints one row per report directory or history file.
+func printResults(results []retention.Result) {
+	if len(results) == 0 {
+		return
+	}
+	rows := make([][]string, 0, len(results))
+	for _, result := range results {
+		rows = append(rows, []string{
+			result.Path,
+			result.Kind,
+			fmt.Sprint(result.Total),
+			fmt.Sprint(len(result.Removed)),
+			retention.FormatSize(result.Size),
+			retention.FormatSize(result.Freed),
+		})
+	}
+	presenter.PrintTable([]string{"CAMINHO", "TIPO", "ITENS", "REMOVIDOS", "TAMANHO", "LIBERADO"}, rows)
+}
@@ -0,0 +1,165 @@
+package install
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/estudosdevops/opsmaster/internal/cloud"
+	"github.com/estudosdevops/opsmaster/internal/installer"
+	"github.com/estudosdevops/opsmaster/internal/logger"
+)
+
+// Fluent Bit command flags (instance selection, AWS and execution flags are
+// shared with the puppet command)
+var (
+	fluentBitOutput        string   // Output plugin (es, loki, cloudwatch)
+	fluentBitHost          string   // Elasticsearch/Loki host (Go template)
+	fluentBitPort          int      // Elasticsearch/Loki port (0 = output default)
+	fluentBitTLS           bool     // Use TLS for Elasticsearch/Loki
+	fluentBitIndex         string   // Elasticsearch index (Go template)
+	fluentBitLabels        string   // Loki labels (Go template)
+	fluentBitLogGroup      string   // CloudWatch Logs group (Go template)
+	fluentBitRegion        string   // CloudWatch Logs region ("" = instance region)
+	fluentBitInputs        []string // Log files tailed
+	fluentBitRecordColumns []string // CSV columns added to every record
+	fluentBitConfigFile    string   // Complete fluent-bit.conf used as-is
+)
+
+// fluentBitCmd represents the Fluent Bit installation command
+var fluentBitCmd = &cobra.Command{
+	Use:   "fluent-bit",
+	Short: "Instala o Fluent Bit (coletor de logs) em instâncias na nuvem",
+	Long: `Instala e configura o Fluent Bit em múltiplas instâncias na nuvem em paralelo.
+
+O Fluent Bit é instalado a partir dos repositórios oficiais (packages.fluentbit.io)
+em Debian/Ubuntu e RHEL/Amazon Linux. A configuração de saída é gerada a partir
+das flags e dos metadados do CSV, validada com --dry-run na instância e o serviço
+é habilitado e reiniciado.
+
+Saídas suportadas (--output):
+  es          Elasticsearch/OpenSearch (--host, --port, --index)
+  loki        Grafana Loki (--host, --port, --labels)
+  cloudwatch  CloudWatch Logs (--log-group, --region)
+
+--host, --index, --labels, --log-group e --region aceitam templates Go com
+.InstanceID, .Account, .Region e .Metadata.<coluna do CSV>.
+
+Para configurações avançadas, --config-file envia um fluent-bit.conf completo
+sem alterações (as flags de saída são ignoradas).
+
+Exemplos:
+  # Enviar logs para o Elasticsearch, um índice por ambiente
+  opsmaster install fluent-bit --instances-file instances.csv \
+    --output es --host es.internal --index 'logs-{{ .Metadata.environment }}'
+
+  # Enviar logs para o Loki com TLS
+  opsmaster install fluent-bit --instances-file instances.csv \
+    --output loki --host loki.example.com --port 443 --tls --labels 'job=varlogs,env={{ .Metadata.environment }}'
+
+  # Enviar logs para o CloudWatch Logs, um grupo por conta
+  opsmaster install fluent-bit --instances-file instances.csv \
+    --output cloudwatch --log-group '/opsmaster/{{ .Account }}'
+
+  # Usar uma configuração própria
+  opsmaster install fluent-bit --instances-file instances.csv --config-file fluent-bit.conf`,
+
+	RunE: runFluentBitInstall,
+}
+
+func init() {
+	// Register fluent-bit subcommand
+	InstallCmd.AddCommand(fluentBitCmd)
+
+	// Output flags
+	fluentBitCmd.Flags().StringVar(&fluentBitOutput, "output", "", "Saída dos logs: es, loki ou cloudwatch (obrigatório sem --config-file)")
+	fluentBitCmd.Flags().StringVar(&fluentBitHost, "host", "", "Host do Elasticsearch/Loki (aceita template)")
+	fluentBitCmd.Flags().IntVar(&fluentBitPort, "port", 0, "Porta do Elasticsearch/Loki (padrão: 9200 para es, 3100 para loki)")
+	fluentBitCmd.Flags().BoolVar(&fluentBitTLS, "tls", false, "Usar TLS na conexão com Elasticsearch/Loki")
+	fluentBitCmd.Flags().StringVar(&fluentBitIndex, "index", "", "Índice do Elasticsearch (padrão: fluent-bit; aceita template)")
+	fluentBitCmd.Flags().StringVar(&fluentBitLabels, "labels", "", "Labels do Loki no formato chave=valor,chave=valor (padrão: job=fluent-bit; aceita template)")
+	fluentBitCmd.Flags().StringVar(&fluentBitLogGroup, "log-group", "", "Grupo do CloudWatch Logs (obrigatório para cloudwatch; aceita template)")
+	fluentBitCmd.Flags().StringVar(&fluentBitRegion, "region", "", "Região do CloudWatch Logs (padrão: região da instância; aceita template)")
+	fluentBitCmd.Flags().StringSliceVar(&fluentBitInputs, "inputs", nil, "Arquivos de log lidos (padrão: /var/log/syslog,/var/log/messages)")
+	fluentBitCmd.Flags().StringSliceVar(&fluentBitRecordColumns, "record-columns", nil, "Colunas do CSV adicionadas como campos em todos os registros (ex: environment,team)")
+	fluentBitCmd.Flags().StringVar(&fluentBitConfigFile, "config-file", "", "fluent-bit.conf completo enviado sem alterações (ignora as flags de saída)")
+
+	// Instance selection and execution flags (shared with install puppet)
+	addPackageInstallFlags(fluentBitCmd)
+}
+
+// runFluentBitInstall executes the Fluent Bit installation workflow
+func runFluentBitInstall(_ *cobra.Command, _ []string) error {
+	log := logger.Get()
+
+	log.Info("🚀 Fluent Bit Installation Started",
+		"run_id", logger.RunID(),
+		"instances_file", instancesFile,
+		"output", fluentBitOutput,
+		"max_concurrency", maxConcurrency,
+		"dry_run", dryRun,
+	)
+
+	// Validate flag values before doing any remote work
+	opts, err := fluentBitOptionsFromFlags()
+	if err != nil {
+		return fatalError(log, "Invalid Fluent Bit output settings", err)
+	}
+
+	// Create context with cancellation support (Ctrl+C)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	return runPackageInstall(ctx, log, packageInstall{
+		command:    "install fluent-bit",
+		title:      "Fluent Bit",
+		validation: "SSM + output connectivity",
+		installer:  installer.NewFluentBitInstaller(opts),
+		checkInstances: func(log *slog.Logger, instances []*cloud.Instance) {
+			if opts.RawConfig != "" {
+				log.Info("✅ Using custom configuration", "file", fluentBitConfigFile)
+				return
+			}
+			for _, column := range opts.RecordColumns {
+				if _, ok := instances[0].Metadata[column]; !ok {
+					log.Warn("⚠️  CSV column not found, field will not be added", "column", column)
+				}
+			}
+			log.Info("✅ Output configured", "output", opts.Output, "record_columns", len(opts.RecordColumns))
+		},
+	})
+}
+
+// fluentBitOptionsFromFlags builds and validates installer options,
+// reading --config-file when given.
+func fluentBitOptionsFromFlags() (installer.FluentBitOptions, error) {
+	opts := installer.FluentBitOptions{
+		Output:        fluentBitOutput,
+		Host:          fluentBitHost,
+		Port:          fluentBitPort,
+		TLS:           fluentBitTLS,
+		Index:         fluentBitIndex,
+		Labels:        fluentBitLabels,
+		LogGroup:      fluentBitLogGroup,
+		Region:        fluentBitRegion,
+		Inputs:        fluentBitInputs,
+		RecordColumns: fluentBitRecordColumns,
+	}
+
+	if fluentBitConfigFile != "" {
+		config, err := os.ReadFile(fluentBitConfigFile)
+		if err != nil {
+			return opts, fmt.Errorf("failed to read --config-file: %w", err)
+		}
+		if len(strings.TrimSpace(string(config))) == 0 {
+			return opts, fmt.Errorf("--config-file %s is empty", fluentBitConfigFile)
+		}
+		opts.RawConfig = string(config)
+	}
+
+	return opts, opts.Validate()
+}
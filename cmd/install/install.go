@@ -10,7 +10,7 @@ import (
 var InstallCmd = &cobra.Command{
 	Use:   "install",
 	Short: "Instala pacotes em instâncias na nuvem",
-	Long: `Instala pacotes (Puppet, Docker, etc) em múltiplas instâncias na nuvem em paralelo.
+	Long: `Instala pacotes (Puppet, Fluent Bit, osquery, Teleport, etc) em múltiplas instâncias na nuvem em paralelo.
 
 Suporta múltiplos provedores de nuvem (AWS, Azure, GCP) e pacotes.
 Utiliza execução remota (SSM para AWS) para instalar e configurar pacotes.
@@ -23,7 +23,13 @@ Exemplos:
   opsmaster install puppet --instances-file instances.csv --puppet-server puppet.example.com --max-concurrency 20
 
   # Modo dry run (simular sem executar)
-  opsmaster install puppet --instances-file instances.csv --puppet-server puppet.example.com --dry-run`,
+  opsmaster install puppet --instances-file instances.csv --puppet-server puppet.example.com --dry-run
+
+  # Instalar Fluent Bit enviando logs para o Loki
+  opsmaster install fluent-bit --instances-file instances.csv --output loki --host loki.example.com
+
+  # Instalar osquery registrando as instâncias no Fleet
+  opsmaster install osquery --instances-file instances.csv --fleet-url https://fleet.example.com --enroll-secret vault:secret/data/fleet#enroll_secret`,
 
 	// No Run function - this is just a parent command
 	// Actual work is done by subcommands (puppet, docker, etc)
@@ -0,0 +1,134 @@
+package install
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/estudosdevops/opsmaster/internal/cloud"
+	"github.com/estudosdevops/opsmaster/internal/installer"
+	"github.com/estudosdevops/opsmaster/internal/logger"
+	"github.com/estudosdevops/opsmaster/internal/secrets"
+)
+
+// osquery command flags (instance selection, AWS and execution flags are
+// shared with the puppet command)
+var (
+	osqueryFleetURL        string // Fleet/TLS server (host[:port] or https:// URL)
+	osqueryEnrollSecret    string // Enroll secret (supports vault: references)
+	osquerySecretParameter string // SSM parameter holding the enroll secret
+	osqueryCAFile          string // PEM bundle trusted for the fleet server
+	osqueryHostIdentifier  string // osquery --host_identifier
+)
+
+// osqueryCmd represents the osquery installation command
+var osqueryCmd = &cobra.Command{
+	Use:   "osquery",
+	Short: "Instala o osquery e registra as instâncias no Fleet",
+	Long: `Instala o osquery a partir dos repositórios oficiais (pkg.osquery.io) e registra
+as instâncias em um servidor Fleet (ou qualquer servidor TLS do osquery).
+
+O gerenciador de pacotes (apt ou yum) é detectado na instância. O segredo de
+registro é gravado em /etc/osquery/enroll_secret (modo 600) e nunca aparece no
+texto do script: --enroll-secret é enviado como variável de ambiente (aceita
+referência vault:caminho#campo) e --enroll-secret-parameter é resolvido pelo
+próprio SSM a partir do Parameter Store, sem aparecer no histórico de comandos.
+
+A verificação confirma que o osqueryd está ativo e que o registro no Fleet não
+falhou. Instâncias instaladas recebem a tag osquery=true.
+
+Exemplos:
+  # Segredo de registro vindo do Vault
+  opsmaster install osquery --instances-file instances.csv \
+    --fleet-url https://fleet.example.com --enroll-secret vault:secret/data/fleet#enroll_secret
+
+  # Segredo no SSM Parameter Store e CA própria
+  opsmaster install osquery --instances-file instances.csv \
+    --fleet-url fleet.internal:8412 --enroll-secret-parameter /fleet/enroll-secret --fleet-ca-file fleet-ca.pem`,
+
+	RunE: runOsqueryInstall,
+}
+
+func init() {
+	// Register osquery subcommand
+	InstallCmd.AddCommand(osqueryCmd)
+
+	// Fleet flags
+	osqueryCmd.Flags().StringVar(&osqueryFleetURL, "fleet-url", "", "Servidor Fleet/TLS do osquery: host[:porta] ou URL https:// (obrigatório)")
+	osqueryCmd.Flags().StringVar(&osqueryEnrollSecret, "enroll-secret", "", "Segredo de registro no Fleet; aceita referência vault:caminho#campo")
+	osqueryCmd.Flags().StringVar(&osquerySecretParameter, "enroll-secret-parameter", "", "Parâmetro do SSM Parameter Store com o segredo de registro (alternativa a --enroll-secret)")
+	osqueryCmd.Flags().StringVar(&osqueryCAFile, "fleet-ca-file", "", "Arquivo PEM com a CA do servidor Fleet (padrão: CAs do sistema)")
+	osqueryCmd.Flags().StringVar(&osqueryHostIdentifier, "host-identifier", "instance", "Identificador do host no Fleet: instance, uuid ou hostname")
+	osqueryCmd.MarkFlagRequired("fleet-url")
+
+	// Instance selection and execution flags (shared with install puppet)
+	addPackageInstallFlags(osqueryCmd)
+}
+
+// runOsqueryInstall executes the osquery installation workflow
+func runOsqueryInstall(cmd *cobra.Command, _ []string) error {
+	log := logger.Get()
+
+	log.Info("🚀 osquery Installation Started",
+		"run_id", logger.RunID(),
+		"instances_file", instancesFile,
+		"fleet_url", osqueryFleetURL,
+		"max_concurrency", maxConcurrency,
+		"dry_run", dryRun,
+	)
+
+	// Create context with cancellation support (Ctrl+C)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Validate flag values before doing any remote work
+	opts, err := osqueryOptionsFromFlags(ctx, cmd)
+	if err != nil {
+		return fatalError(log, "Invalid osquery settings", err)
+	}
+
+	return runPackageInstall(ctx, log, packageInstall{
+		command:    "install osquery",
+		title:      "osquery",
+		validation: "SSM + fleet server connectivity",
+		installer:  installer.NewOsqueryInstaller(opts),
+		checkInstances: func(log *slog.Logger, _ []*cloud.Instance) {
+			source := "flag"
+			if opts.EnrollSecretParameter != "" {
+				source = "ssm-parameter"
+			}
+			log.Info("✅ Fleet enrollment configured",
+				"fleet_server", opts.FleetURL,
+				"host_identifier", osqueryHostIdentifier,
+				"enroll_secret", source,
+				"custom_ca", opts.ServerCerts != "")
+		},
+	})
+}
+
+// osqueryOptionsFromFlags resolves the enroll secret, reads --fleet-ca-file
+// and validates the resulting options.
+func osqueryOptionsFromFlags(ctx context.Context, cmd *cobra.Command) (installer.OsqueryOptions, error) {
+	if err := secrets.ResolveFlags(ctx, cmd.Flags(), "enroll-secret"); err != nil {
+		return installer.OsqueryOptions{}, err
+	}
+
+	opts := installer.OsqueryOptions{
+		FleetURL:              osqueryFleetURL,
+		HostIdentifier:        osqueryHostIdentifier,
+		EnrollSecret:          osqueryEnrollSecret,
+		EnrollSecretParameter: osquerySecretParameter,
+	}
+	if osqueryCAFile != "" {
+		pem, err := os.ReadFile(osqueryCAFile)
+		if err != nil {
+			return opts, fmt.Errorf("failed to read --fleet-ca-file: %w", err)
+		}
+		opts.ServerCerts = string(pem)
+	}
+
+	return opts, opts.Validate()
+}
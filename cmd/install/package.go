@@ -0,0 +1,317 @@
+package install
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/estudosdevops/opsmaster/internal/cloud"
+	"github.com/estudosdevops/opsmaster/internal/cloud/provider"
+	"github.com/estudosdevops/opsmaster/internal/executor"
+	"github.com/estudosdevops/opsmaster/internal/i18n"
+	"github.com/estudosdevops/opsmaster/internal/installer"
+	"github.com/estudosdevops/opsmaster/internal/logger"
+)
+
+// packageInstall describes an agent installation sharing the generic
+// workflow (fluent-bit, osquery). Puppet keeps its own flow (facts, ENC,
+// per-server concurrency).
+type packageInstall struct {
+	command    string                     // Command for events and telemetry (e.g., "install osquery")
+	title      string                     // Package name shown in logs
+	version    string                     // Version recorded in result sinks ("" = not tracked)
+	validation string                     // Prerequisites checked, shown in logs
+	installer  installer.PackageInstaller // Configured installer
+
+	// checkInstances runs after instances are loaded, e.g. to warn about
+	// CSV columns the configuration needs (optional).
+	checkInstances func(log *slog.Logger, instances []*cloud.Instance)
+}
+
+// addPackageInstallFlags registers the instance selection, AWS and execution
+// flags shared by agent install commands (same variables as install puppet).
+func addPackageInstallFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&instancesFile, "instances-file", "", "Arquivo CSV com lista de instâncias: caminho local, https:// ou s3:// (aceita .gz) (obrigatório)")
+	cmd.Flags().IntVar(&maxConcurrency, "max-concurrency", 10, "Máximo de instalações paralelas")
+	cmd.Flags().IntVar(&requeueFailed, "requeue-failed", 0, "Reprocessa instâncias com falha até N vezes na mesma execução, depois de todas as primeiras tentativas (0 desativa)")
+	cmd.Flags().IntVar(&retryTransient, "requeue-transient", 2, "Reprocessa no fim da execução, até N vezes, instâncias com falhas transitórias (throttling do SSM, agente offline) (0 desativa)")
+	cmd.Flags().DurationVar(&heartbeatEvery, "heartbeat-interval", executor.DefaultHeartbeatInterval, "Intervalo dos logs de acompanhamento das instâncias em execução, com tempo decorrido, fase atual e ID do comando SSM (0 desativa)")
+	cmd.Flags().StringToStringVar(&expectedPhases, "expected-duration", nil, "Duração esperada por fase (ex: install=30m,verify=5m); fases mais longas são reportadas como travadas (padrão: validate=5m, install=20m, verify=10m, tag=2m, post-install=5m)")
+	cmd.Flags().StringVar(&dispatchOrder, "order", executor.OrderAsIs, "Ordem de despacho das instâncias: as-is (ordem do CSV), sorted (por conta, região e ID) ou shuffle (aleatória, distribui a carga entre contas e regiões)")
+	cmd.Flags().Int64Var(&orderSeed, "order-seed", 0, "Semente da ordem shuffle, para repetir a mesma ordem de uma execução anterior (0 = nova semente, registrada no log)")
+	cmd.Flags().StringVar(&successWhen, "success-when", "", "Expressão que decide o status final de cada instância instalada ou com falha (ex: metadata.certname_preserved == \"true\" || phase_durations.install < 300s); verdadeira = sucesso, falsa = falha")
+	cmd.Flags().StringVar(&awsProfile, "aws-profile", "", "Perfil AWS a usar (padrão: perfil default)")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Simular instalação sem executar")
+	cmd.Flags().BoolVar(&skipValidation, "skip-validation", false, "Pular validação de pré-requisitos (não recomendado)")
+	cmd.Flags().DurationVar(&waitForSSM, "wait-for-ssm", 0, "Aguarda até o tempo informado o registro da instância no SSM (agente Online) antes da validação, para instâncias recém-criadas (ex: 10m; 0 falha na hora)")
+	cmd.Flags().BoolVar(&skipTagging, "skip-tagging", false, "Não aplicar tags nas instâncias (aplique depois com 'opsmaster tags apply --from-report')")
+	cmd.Flags().BoolVar(&skipDiagnostics, "skip-diagnostics", false, "Não coletar o pacote de diagnóstico (df -h, free -m e fim dos logs do agente e do apt/yum) das instâncias que falham na instalação ou verificação")
+	cmd.Flags().StringVar(&reportFile, "report", "", "Grava o resultado da execução em JSON (instâncias, status e tags) no arquivo informado")
+	cmd.Flags().BoolVar(&forceLock, "force", false, "Assume o lock do arquivo de --report mesmo se outra execução do opsmaster parecer ativa (use apenas se ela já terminou)")
+	cmd.Flags().StringVar(&reusePreflight, "reuse-preflight", "", "Relatório (--report) de um dry-run recente: instâncias validadas com sucesso não são validadas novamente")
+	cmd.Flags().DurationVar(&preflightMaxAge, "preflight-max-age", 30*time.Minute, "Idade máxima das validações reaproveitadas com --reuse-preflight")
+	cmd.Flags().IntVar(&maxOutputBytes, "max-output-bytes", defaultMaxOutputBytes, "Máximo de bytes de stdout e stderr guardados no contexto de erro dos scripts com falha, no relatório (--report) (0 sem limite)")
+	cmd.Flags().IntVar(&slowestCount, "slowest", 5, "Quantidade de instâncias mais lentas listadas no resumo, com o tempo de cada fase (0 desativa)")
+	cmd.Flags().BoolVar(&startStopped, "start-stopped-instances", false, "Iniciar instâncias paradas antes da instalação (padrão: pular)")
+	cmd.Flags().BoolVar(&skipInvalidRows, "skip-invalid-rows", false, "Ignora linhas inválidas do CSV (listadas no resumo) em vez de abortar")
+	cmd.Flags().StringArrayVar(&whereSelectors, "where", nil, "Seleciona instâncias por coluna do CSV (ex: environment=blue, shard>=3, hostname~^web-); pode ser repetida")
+	cmd.Flags().StringVar(&onlyOS, "only-os", "", "Processa apenas instâncias da família de SO informada (debian, rhel, windows ou distribuição como ubuntu, amzn), pela coluna os do CSV ou metadados da instância")
+	cmd.Flags().BoolVar(&forceDetect, "force-detect", false, "Detecta o SO na instância mesmo com a coluna os do CSV preenchida (inventário desatualizado)")
+	cmd.Flags().BoolVar(&includeMaint, "include-maintenance", false, "Processar também instâncias em modo manutenção")
+	cmd.Flags().StringVar(&asgMode, "asg-mode", executor.AutoScalingOff, "Instâncias de autoscaling groups sendo terminadas ou substituídas: off (processa normalmente), skip (ignora com o motivo) ou replace (processa a instância substituta)")
+	cmd.Flags().BoolVar(&failDisappeared, "fail-on-disappeared", false, "Falhar a execução (código de saída) também quando instâncias desaparecem no meio dela (ex.: terminadas pelo autoscaling)")
+	cmd.Flags().StringVar(&maintenanceTag, "maintenance-tag", cloud.DefaultMaintenanceTagKey, "Tag que marca instâncias em modo manutenção (valor true)")
+	cmd.Flags().StringVar(&commandPrefix, "command-prefix", cloud.DefaultCommandPrefix, "Prefixo que identifica os comandos do opsmaster no histórico do SSM (comentário e primeira linha; \"-\" desativa a linha marcadora)")
+	cmd.Flags().StringVar(&dynamoDBTable, "dynamodb-table", "", "Tabela DynamoDB que recebe o estado mais recente de cada instância (opcional)")
+	cmd.Flags().StringVar(&dynamoDBRegion, "dynamodb-region", "", "Região da tabela DynamoDB (padrão: região do perfil AWS)")
+	cmd.Flags().BoolVar(&dynamoDBCreate, "dynamodb-create-table", false, "Cria a tabela DynamoDB (on-demand, chave instance_id) se não existir")
+	cmd.Flags().StringVar(&eventsARN, "events-arn", "", "ARN de tópico SNS ou barramento EventBridge que recebe um evento ao término de cada instância (opcional)")
+	addCollectorFlags(cmd)
+	addControlFlags(cmd)
+	cmd.Flags().BoolVar(&refreshMetadata, "refresh-metadata", false, "Ignorar cache de metadados das instâncias e consultar a API novamente")
+
+	cmd.MarkFlagRequired("instances-file")
+}
+
+// runPackageInstall loads the instances, initializes the cloud provider and
+// installs the package in parallel, reporting like install puppet.
+func runPackageInstall(ctx context.Context, log *slog.Logger, pkg packageInstall) error {
+	startTime := time.Now()
+
+	commandLabel := cloud.CommandLabel{Prefix: commandPrefix, RunID: logger.RunID(), Operator: cloud.CurrentOperator()}
+	if err := commandLabel.Validate(); err != nil {
+		return fatalError(log, "Invalid --command-prefix", err)
+	}
+	if requeueFailed < 0 || retryTransient < 0 {
+		return fatalError(log, "Invalid requeue flags", fmt.Errorf("--requeue-failed and --requeue-transient must not be negative"))
+	}
+	expectedDurations, err := parseLivenessFlags()
+	if err != nil {
+		return fatalError(log, "Invalid liveness flags", err)
+	}
+	if maxOutputBytes < 0 {
+		return fatalError(log, "Invalid --max-output-bytes", fmt.Errorf("must not be negative"))
+	}
+	if err := executor.ValidateOrder(dispatchOrder); err != nil {
+		return fatalError(log, "Invalid --order", err)
+	}
+	if err := executor.ValidateAutoScaling(asgMode); err != nil {
+		return fatalError(log, "Invalid --asg-mode", err)
+	}
+	successCriteria, err := parseSuccessWhen()
+	if err != nil {
+		return fatalError(log, "Invalid --success-when", err)
+	}
+	overrides, err := executor.ParseInstanceOverrides(viper.Get("instance_overrides"))
+	if err != nil {
+		return fatalError(log, "Invalid instance_overrides in config file", err)
+	}
+	osFamily, err := parseOnlyOS()
+	if err != nil {
+		return fatalError(log, "Invalid --only-os", err)
+	}
+	if err := checkReadOnly(pkg.command); err != nil {
+		return err
+	}
+	releaseLock, err := acquireRunLock(log)
+	if err != nil {
+		return fatalError(log, "Failed to lock run report", err)
+	}
+	defer releaseLock()
+
+	// ============================================================
+	// STEP 1: Parse CSV file and load instances
+	// ============================================================
+	logStep(log, 1, "Parsing CSV file")
+	log.Info("📄 Reading instances", "file", instancesFile)
+
+	instances, err := parseInstancesFile(ctx, instancesFile, whereSelectors)
+	if err != nil {
+		return fatalError(log, "Failed to parse CSV file", err)
+	}
+
+	log.Info("✅ CSV parsed successfully", "total_instances", len(instances))
+	if len(instances) == 0 {
+		if len(whereSelectors) > 0 {
+			return i18n.Errorf("no instances match --where selectors: %s", strings.Join(whereSelectors, ", "))
+		}
+		return i18n.Errorf("no instances found in CSV file")
+	}
+	if err := installer.ValidateInventoryOS(instances); err != nil {
+		return fatalError(log, "Invalid os column in CSV", err)
+	}
+
+	// ============================================================
+	// STEP 2: Initialize cloud provider
+	// ============================================================
+	logStep(log, 2, "Initializing cloud provider")
+
+	cloudType, err := provider.DetectCloudFromInstances(instances)
+	if err != nil {
+		return fatalError(log, "Failed to detect cloud provider", err)
+	}
+	log.Info("☁️  Detected cloud provider", "cloud", cloudType)
+
+	effectiveAWSProfile, err := determineAWSProfile(log, instances, awsProfile)
+	if err != nil {
+		return fatalError(log, "Failed to determine AWS profile", err)
+	}
+
+	var providerOptions []provider.Option
+	if effectiveAWSProfile != "" {
+		providerOptions = append(providerOptions, provider.WithProfile(effectiveAWSProfile))
+		log.Info("   Using AWS profile", "profile", effectiveAWSProfile)
+	}
+
+	// Share instance metadata (state, platform, tags) across commands in the same run
+	metadataCache := loadMetadataCache(log)
+	providerOptions = append(providerOptions, provider.WithMetadataCache(metadataCache))
+	providerOptions = append(providerOptions, provider.WithCommandLabel(commandLabel))
+	defer func() {
+		if err := metadataCache.Save(); err != nil {
+			log.Warn("Failed to save instance metadata cache", "error", err)
+		}
+	}()
+
+	cloudProvider, err := provider.NewProvider(cloudType, providerOptions...)
+	if err != nil {
+		return fatalError(log, "Failed to create cloud provider", err)
+	}
+	log.Info("✅ Cloud provider initialized", "provider", cloudProvider.Name())
+
+	if describer := cloud.CapabilitiesOf(cloudProvider).Describe; describer != nil {
+		infos, err := describer.DescribeInstances(ctx, instances)
+		if err != nil {
+			log.Warn("Failed to fetch instance metadata, continuing without it", "error", err)
+		} else {
+			cloud.EnrichInstances(instances, infos)
+			log.Info("   Instance metadata loaded", "instances", len(infos), "refresh", refreshMetadata)
+		}
+	} else {
+		log.Info("   Instance metadata not loaded", "reason", cloud.Unsupported(cloudProvider, "describing instances"))
+	}
+	instances, err = planByOS(log, instances, osFamily)
+	if err != nil {
+		return err
+	}
+
+	// ============================================================
+	// STEP 3: Check instances against the configuration
+	// ============================================================
+	logStep(log, 3, "Checking configuration")
+	if pkg.checkInstances != nil {
+		pkg.checkInstances(log, instances)
+	}
+
+	// ============================================================
+	// STEP 4: Installer
+	// ============================================================
+	logStep(log, 4, "Creating "+pkg.title+" installer")
+	log.Info("✅ Installer created",
+		"package", pkg.installer.Name(),
+		"capabilities", installer.CapabilitiesOf(pkg.installer).Names())
+
+	// ============================================================
+	// STEP 5: Setup skip validation flag
+	// ============================================================
+	logStep(log, 5, "Configuring validation settings")
+	if skipValidation {
+		log.Warn("⚠️  Validation skipped (--skip-validation enabled)")
+	} else {
+		log.Info("🔍 Validation will be performed (" + pkg.validation + ")")
+	}
+	if waitForSSM > 0 {
+		log.Info("⏳ Waiting for instances to come online in SSM before validation (--wait-for-ssm)", "timeout", waitForSSM)
+	}
+	if skipTagging {
+		log.Warn("⚠️  Tagging skipped (--skip-tagging enabled)")
+	}
+
+	trustedValidations, err := loadTrustedValidations(log, pkg.installer.Name(), len(instances))
+	if err != nil {
+		return fatalError(log, "Invalid --reuse-preflight", err)
+	}
+
+	// ============================================================
+	// STEP 6: Execute parallel installation
+	// ============================================================
+	logStep(log, 6, "Starting parallel installation")
+	if dryRun {
+		log.Warn("🔍 DRY RUN MODE: No changes will be made")
+	}
+
+	// Per-instance completion events (--events-arn)
+	onResult, err := createEventHook(ctx, pkg.command, pkg.version)
+	if err != nil {
+		return fatalError(log, "Invalid --events-arn", err)
+	}
+	collectorClient, streamResult, err := createCollectorStream(ctx, pkg.installer.Name())
+	if err != nil {
+		return fatalError(log, "Invalid --collector-url", err)
+	}
+	runControl, stopControl := startRunControl(pkg.command)
+	defer stopControl()
+
+	exec := executor.NewParallelExecutor(executor.ExecutorConfig{
+		Provider:           cloudProvider,
+		Installer:          pkg.installer,
+		MaxConcurrency:     maxConcurrency,
+		RequeueFailed:      requeueFailed,
+		RequeueTransient:   retryTransient,
+		SkipValidation:     skipValidation,
+		AgentWait:          waitForSSM,
+		SkipTagging:        skipTagging,
+		TrustedValidations: trustedValidations,
+		ForceDetect:        forceDetect,
+		DryRun:             dryRun,
+		StartStopped:       startStopped,
+		MaintenanceTag:     maintenanceTag,
+		IncludeMaintenance: includeMaint,
+		RunID:              logger.RunID(),
+		OnResult:           chainResultHooks(onResult, streamResult),
+		HeartbeatInterval:  heartbeatEvery,
+		ExpectedDurations:  expectedDurations,
+		MaxOutputBytes:     maxOutputBytes,
+		Order:              dispatchOrder,
+		OrderSeed:          orderSeed,
+		SuccessWhen:        successCriteria,
+		Overrides:          overrides,
+		Control:            runControl,
+		AutoScaling:        asgMode,
+		SkipDiagnostics:    skipDiagnostics,
+	})
+
+	result, err := exec.Execute(ctx, instances)
+	if err != nil {
+		log.Error("Failed to execute installation", "error", err)
+		return fmt.Errorf("execution failed: %w", err)
+	}
+
+	log.Info("📊 Installation Summary",
+		"run_id", result.RunID,
+		"total", result.Total,
+		"successful", result.Success,
+		"failed", result.Failed,
+		"skipped", result.Skipped,
+		"disappeared", result.Disappeared,
+		"duration", time.Since(startTime).Round(time.Second).String(),
+	)
+
+	printResults(result)
+	sendTelemetry(ctx, pkg.command, result)
+	writeResultSinks(ctx, result, pkg.version)
+	writeRunReport(exec, result)
+	sendCollectorReport(ctx, collectorClient, exec, result)
+
+	if err := runError(result); err != nil {
+		return err
+	}
+
+	log.Info("✅ All installations completed successfully!")
+	return nil
+}
@@ -2,35 +2,120 @@ package install
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 
 	"github.com/estudosdevops/opsmaster/internal/cloud"
+	awsprovider "github.com/estudosdevops/opsmaster/internal/cloud/aws"
 	"github.com/estudosdevops/opsmaster/internal/cloud/provider"
+	"github.com/estudosdevops/opsmaster/internal/collector"
+	"github.com/estudosdevops/opsmaster/internal/control"
+	"github.com/estudosdevops/opsmaster/internal/criteria"
 	"github.com/estudosdevops/opsmaster/internal/csv"
 	"github.com/estudosdevops/opsmaster/internal/executor"
+	"github.com/estudosdevops/opsmaster/internal/flagalias"
+	"github.com/estudosdevops/opsmaster/internal/httpclient"
+	"github.com/estudosdevops/opsmaster/internal/i18n"
 	"github.com/estudosdevops/opsmaster/internal/installer"
 	"github.com/estudosdevops/opsmaster/internal/logger"
 	"github.com/estudosdevops/opsmaster/internal/presenter"
+	"github.com/estudosdevops/opsmaster/internal/retention"
 	"github.com/estudosdevops/opsmaster/internal/retry"
+	"github.com/estudosdevops/opsmaster/internal/runlock"
+	"github.com/estudosdevops/opsmaster/internal/secrets"
+	"github.com/estudosdevops/opsmaster/internal/sink"
+	"github.com/estudosdevops/opsmaster/internal/telemetry"
+	"github.com/estudosdevops/opsmaster/internal/validator"
 )
 
 // Puppet command flags
 var (
-	instancesFile   string // CSV file with instance list
-	puppetServer    string // Puppet Server hostname
-	puppetPort      int    // Puppet Server port
-	puppetVersion   string // Puppet version to install
-	environment     string // Puppet environment
-	customFactsFile string // YAML file with custom facts definitions
-	maxConcurrency  int    // Max parallel executions
-	awsProfile      string // AWS profile to use
-	dryRun          bool   // Simulate without executing
-	skipValidation  bool   // Skip prerequisite validation
+	instancesFile   string        // CSV file with instance list
+	puppetServer    string        // Puppet Server hostname
+	puppetPort      int           // Puppet Server port
+	puppetVersion   string        // Puppet version to install
+	environment     string        // Puppet environment
+	customFactsFile string        // YAML file with custom facts definitions
+	csrAttrsFile    string        // YAML file mapping CSV columns to csr_attributes.yaml ("" = disabled)
+	hieraDataFile   string        // YAML file mapping CSV columns to node-local hiera data ("" = disabled)
+	maxConcurrency  int           // Max parallel executions
+	maxPerServer    int           // Max parallel executions per Puppet Server (0 = no limit)
+	firstRunStagger time.Duration // Random delay before each installation (0 = disabled)
+	firstRunSplay   time.Duration // Random sleep before the initial puppet run (0 = disabled)
+	verifyGrace     time.Duration // Window for retrying failed verifications (0 = no retry)
+	requeueFailed   int           // Times failed instances are requeued in the run (0 = disabled)
+	retryTransient  int           // Times instances with transient failures are requeued in the run (0 = disabled)
+	awsProfile      string        // AWS profile to use
+	dryRun          bool          // Simulate without executing
+	skipValidation  bool          // Skip prerequisite validation
+	waitForSSM      time.Duration // Max wait for SSM registration before validation (0 = fail at once)
+	skipTagging     bool          // Don't tag instances (tags can be applied later with "tags apply")
+	reportFile      string        // JSON run report path ("" = disabled)
+	forceLock       bool          // Take over the report lock held by another live run
+	reusePreflight  string        // Run report whose recent validations are trusted ("" = validate all)
+	preflightMaxAge time.Duration // Max age of trusted validations
+	slowestCount    int           // Slowest instances listed in the summary (0 = disabled)
+	maxOutputBytes  int           // Script output kept in the error context of failures (0 = no limit)
+	enableService   bool          // Enable puppet service at boot
+	serviceState    string        // Desired puppet service state (running/stopped)
+	refreshMetadata bool          // Ignore cached instance metadata and fetch again
+	startStopped    bool          // Start stopped instances before installing
+	includeMaint    bool          // Process instances in maintenance mode
+	maintenanceTag  string        // Tag key marking maintenance mode
+	failDisappeared bool          // Fail the run when instances disappear mid-run (e.g., autoscaling)
+	asgMode         string        // Autoscaling members being replaced: off, skip or replace
+	skipDiagnostics bool          // Don't collect the diagnostic bundle from failed instances
+	whereSelectors  []string      // Column selectors (column<op>value) applied to CSV rows
+	onlyOS          string        // Script family to target (debian, rhel, windows; "" = all)
+	forceDetect     bool          // Detect the OS remotely even when the CSV os column is set
+	remoteWorkdir   string        // Remote staging directory ("" = /tmp with noexec fallback)
+	encRegisterURL  string        // ENC/CMDB endpoint to register nodes after install ("" = disabled)
+	encTemplateFile string        // Go template file for the ENC payload ("" = default JSON)
+	encToken        string        // Bearer token for the ENC endpoint (supports vault: references)
+	foremanURL      string        // Foreman URL to create/update hosts after install ("" = disabled)
+	foremanUser     string        // Foreman API user
+	foremanPassword string        // Foreman API password/token (supports vault: references)
+	foremanOrg      string        // Foreman organization name
+	foremanLocation string        // Foreman location name
+	foremanHGColumn string        // CSV column with the Foreman hostgroup
+	commandPrefix   string        // Marker for commands in SSM history ("-" = no marker line)
+	chaosSpec       string        // Hidden: failure/latency injection (requires OPSMASTER_CHAOS=1)
+	skipInvalidRows bool          // Skip malformed CSV rows instead of failing the whole file
+	dynamoDBTable   string        // DynamoDB table receiving per-instance state ("" = disabled)
+	dynamoDBRegion  string        // Region of the DynamoDB table ("" = profile region)
+	dynamoDBCreate  bool          // Create the DynamoDB table when missing
+	eventsARN       string        // SNS topic or EventBridge bus receiving per-instance events ("" = disabled)
+	collectorURL    string        // Collector receiving streamed results ("" = disabled)
+	collectorCert   string        // Client certificate for the collector (mTLS)
+	collectorKey    string        // Client certificate key for the collector (mTLS)
+	collectorCA     string        // CA of the collector certificate ("" = system CAs)
+	repoSource      string        // Puppet package source: puppetlabs or distro
+	repoAptURL      string        // Internal apt mirror of apt.puppet.com ("" = official repo)
+	repoYumURL      string        // Internal yum mirror of yum.puppet.com ("" = official repo)
+	repoGPGKeyURL   string        // Key signing the internal mirrors
+	repoGPGFpr      string        // Expected fingerprint of the mirror key ("" = not checked)
+	skipGPGCheck    bool          // Trust the internal mirrors without signature checks
+	releaseFprs     []string      // Pinned keys of the official release packages (nil = built-in)
+	releaseKeyURL   string        // Key verifying the official .rpm release package ("" = built-in)
+	proxyHost       string        // HTTP proxy of the agent ("" = direct connections)
+	proxyPort       int           // HTTP proxy port (0 = Puppet default)
+	noProxy         []string      // Hosts the agent reaches without the proxy
+	shellOptions    string        // Safety options of the install scripts (strict, none or a list)
+	successWhen     string        // Success criteria expression deciding the final status ("" = workflow status)
+	controlSocket   bool          // Expose the run on a local control socket for "opsmaster ctl"
+	pruneFacts      bool          // Remove fact files of earlier runs no longer declared
+	agentLock       string        // Stuck agent run lock handling: wait, clear or fail
+	lockStaleAfter  time.Duration // Age from which the agent run lock is stale
+	lockTimeout     time.Duration // Max wait for the agent run lock to be released
 
 	// Retry configuration flags
 	maxRetries  int           // Maximum retry attempts for all operations
@@ -38,8 +123,20 @@ var (
 	retryJitter bool          // Add random jitter to retry delays
 	ssmRetries  int           // Maximum retry attempts for SSM operations (0 = use maxRetries)
 	ec2Retries  int           // Maximum retry attempts for EC2 operations (0 = use maxRetries)
+
+	// Liveness logging flags
+	heartbeatEvery time.Duration     // Interval of liveness logs for running instances (0 = disabled)
+	expectedPhases map[string]string // Phase -> expected max duration ("" = defaults)
+
+	// Dispatch order flags
+	dispatchOrder string // Order of the first attempts: as-is, sorted or shuffle
+	orderSeed     int64  // Seed of the shuffle order (0 = new seed, logged)
 )
 
+// defaultMaxOutputBytes keeps the error context of a failed script in the
+// report below the SSM output limit (24000 characters).
+const defaultMaxOutputBytes = 20000
+
 // totalSteps is the total number of steps in the Puppet installation process.
 const totalSteps = 6
 
@@ -117,6 +214,10 @@ Custom Facter Facts:
         compliance_level: "compliance"
         data_classification: "classification"
 
+  Os arquivos gravados ficam listados em facts.d/.opsmaster-manifest; com
+  --prune-facts, os listados por execuções anteriores e não declarados mais
+  (ex: arquivo renomeado) são removidos.
+
 Exemplos:
   # Instalação básica (cria location.yaml automaticamente)
   opsmaster install puppet \
@@ -149,6 +250,22 @@ Exemplos:
     --environment production \
     --max-concurrency 20
 
+  # Agente instalado com serviço desabilitado (execuções via cron)
+  opsmaster install puppet \
+    --instances-file instances.csv \
+    --puppet-server puppet.example.com \
+    --enable-service=false \
+    --service-state stopped
+
+  # Espelhos internos (repositórios re-assinados)
+  opsmaster install puppet \
+    --instances-file instances.csv \
+    --puppet-server puppet.example.com \
+    --repo-apt-url https://mirror.example.com/puppet-apt \
+    --repo-yum-url https://mirror.example.com/puppet-yum \
+    --repo-gpg-key-url https://mirror.example.com/keys/puppet.asc \
+    --repo-gpg-fingerprint "D681 1ED3 ADEE B844 1AF5 AA8F 4528 B6CD 9E61 EF26"
+
   # Dry run (simular)
   opsmaster install puppet \
     --instances-file instances.csv \
@@ -163,20 +280,89 @@ func init() {
 	InstallCmd.AddCommand(puppetCmd)
 
 	// Required flags
-	puppetCmd.Flags().StringVar(&instancesFile, "instances-file", "", "Arquivo CSV com lista de instâncias (obrigatório)")
+	puppetCmd.Flags().StringVar(&instancesFile, "instances-file", "", "Arquivo CSV com lista de instâncias: caminho local, https:// ou s3:// (aceita .gz) (obrigatório)")
 	puppetCmd.Flags().StringVar(&puppetServer, "puppet-server", "", "Hostname do Puppet Server (obrigatório)")
 	puppetCmd.MarkFlagRequired("instances-file")
 	puppetCmd.MarkFlagRequired("puppet-server")
 
 	// Optional flags with defaults
 	puppetCmd.Flags().IntVar(&puppetPort, "puppet-port", 8140, "Porta do Puppet Server")
-	puppetCmd.Flags().StringVar(&puppetVersion, "puppet-version", "7", "Versão do Puppet a instalar")
+	puppetCmd.Flags().StringVar(&puppetVersion, "puppet-version", "7", "Versão do Puppet a instalar (7 ou 8)")
 	puppetCmd.Flags().StringVar(&environment, "environment", "production", "Ambiente Puppet")
 	puppetCmd.Flags().StringVar(&customFactsFile, "custom-facts", "", "Arquivo YAML com definições de custom facts (opcional)")
+	puppetCmd.Flags().StringVar(&csrAttrsFile, "csr-attributes", "", "Arquivo YAML que mapeia colunas do CSV para extension_requests (pp_role, pp_environment...) do csr_attributes.yaml, gerando trusted facts (opcional)")
+	puppetCmd.Flags().StringVar(&hieraDataFile, "hiera-node-data", "", "Arquivo YAML que mapeia colunas do CSV para chaves do hiera gravadas em um arquivo de dados local do nó (padrão: /etc/hiera-node.yaml) (opcional)")
+	puppetCmd.Flags().BoolVar(&pruneFacts, "prune-facts", false, "Remove de facts.d os arquivos de facts gravados pelo opsmaster em execuções anteriores e não declarados mais (lista em .opsmaster-manifest)")
 	puppetCmd.Flags().IntVar(&maxConcurrency, "max-concurrency", 10, "Máximo de instalações paralelas")
+	puppetCmd.Flags().IntVar(&maxPerServer, "max-concurrency-per-server", 0, "Máximo de instalações paralelas por Puppet Server (0 = sem limite)")
+	puppetCmd.Flags().DurationVar(&firstRunSplay, "first-run-splay", 0, "Espera aleatória (0 até o valor, máx 20m) na instância antes da primeira execução do puppet agent (ex: 10m)")
+	puppetCmd.Flags().StringVar(&agentLock, "agent-lock", installer.AgentLockWait, "Como tratar um agente travado (agent_catalog_run.lock antigo) antes da primeira execução: wait (aguarda a liberação), clear (encerra o agente e remove o lock) ou fail (falha a instância)")
+	puppetCmd.Flags().DurationVar(&lockStaleAfter, "agent-lock-stale-after", installer.DefaultAgentLockStaleAfter, "Idade a partir da qual o lock do agente é considerado travado (mín 1m)")
+	puppetCmd.Flags().DurationVar(&lockTimeout, "agent-lock-timeout", installer.DefaultAgentLockTimeout, "Tempo máximo de espera pela liberação do lock do agente (máx 20m)")
+	puppetCmd.Flags().DurationVar(&firstRunStagger, "first-run-stagger", 0, "Atraso aleatório (0 até o valor) antes de cada instalação, para distribuir a carga no Puppet Server (ex: 30s)")
+	puppetCmd.Flags().DurationVar(&verifyGrace, "verify-grace-period", 0, "Janela em que a verificação pós-instalação é repetida com backoff antes de falhar, enquanto o agente conclui a primeira execução (ex: 5m; 0 desativa)")
+	puppetCmd.Flags().IntVar(&requeueFailed, "requeue-failed", 0, "Reprocessa instâncias com falha até N vezes na mesma execução, depois de todas as primeiras tentativas (0 desativa)")
+	puppetCmd.Flags().IntVar(&retryTransient, "requeue-transient", 2, "Reprocessa no fim da execução, até N vezes, instâncias com falhas transitórias (throttling do SSM, agente offline) (0 desativa)")
+	puppetCmd.Flags().DurationVar(&heartbeatEvery, "heartbeat-interval", executor.DefaultHeartbeatInterval, "Intervalo dos logs de acompanhamento das instâncias em execução, com tempo decorrido, fase atual e ID do comando SSM (0 desativa)")
+	puppetCmd.Flags().StringToStringVar(&expectedPhases, "expected-duration", nil, "Duração esperada por fase (ex: install=30m,verify=5m); fases mais longas são reportadas como travadas (padrão: validate=5m, install=20m, verify=10m, tag=2m, post-install=5m)")
+	puppetCmd.Flags().StringVar(&dispatchOrder, "order", executor.OrderAsIs, "Ordem de despacho das instâncias: as-is (ordem do CSV), sorted (por conta, região e ID) ou shuffle (aleatória, distribui a carga entre contas e regiões)")
+	puppetCmd.Flags().Int64Var(&orderSeed, "order-seed", 0, "Semente da ordem shuffle, para repetir a mesma ordem de uma execução anterior (0 = nova semente, registrada no log)")
+	puppetCmd.Flags().StringVar(&successWhen, "success-when", "", "Expressão que decide o status final de cada instância instalada ou com falha (ex: metadata.certname_preserved == \"true\" || phase_durations.install < 300s); verdadeira = sucesso, falsa = falha")
 	puppetCmd.Flags().StringVar(&awsProfile, "aws-profile", "", "Perfil AWS a usar (padrão: perfil default)")
 	puppetCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Simular instalação sem executar")
 	puppetCmd.Flags().BoolVar(&skipValidation, "skip-validation", false, "Pular validação de pré-requisitos (não recomendado)")
+	puppetCmd.Flags().DurationVar(&waitForSSM, "wait-for-ssm", 0, "Aguarda até o tempo informado o registro da instância no SSM (agente Online) antes da validação, para instâncias recém-criadas (ex: 10m; 0 falha na hora)")
+	puppetCmd.Flags().BoolVar(&skipTagging, "skip-tagging", false, "Não aplicar tags nas instâncias (aplique depois com 'opsmaster tags apply --from-report')")
+	puppetCmd.Flags().BoolVar(&skipDiagnostics, "skip-diagnostics", false, "Não coletar o pacote de diagnóstico (df -h, free -m e fim dos logs do agente e do apt/yum) das instâncias que falham na instalação ou verificação")
+	puppetCmd.Flags().StringVar(&reportFile, "report", "", "Grava o resultado da execução em JSON (instâncias, status e tags) no arquivo informado")
+	puppetCmd.Flags().BoolVar(&forceLock, "force", false, "Assume o lock do arquivo de --report mesmo se outra execução do opsmaster parecer ativa (use apenas se ela já terminou)")
+	puppetCmd.Flags().StringVar(&reusePreflight, "reuse-preflight", "", "Relatório (--report) de um dry-run recente: instâncias validadas com sucesso não são validadas novamente")
+	puppetCmd.Flags().DurationVar(&preflightMaxAge, "preflight-max-age", 30*time.Minute, "Idade máxima das validações reaproveitadas com --reuse-preflight")
+	puppetCmd.Flags().IntVar(&maxOutputBytes, "max-output-bytes", defaultMaxOutputBytes, "Máximo de bytes de stdout e stderr guardados no contexto de erro dos scripts com falha, no relatório (--report) (0 sem limite)")
+	puppetCmd.Flags().IntVar(&slowestCount, "slowest", 5, "Quantidade de instâncias mais lentas listadas no resumo, com o tempo de cada fase (0 desativa)")
+	puppetCmd.Flags().BoolVar(&enableService, "enable-service", true, "Habilitar serviço puppet no boot (false para execuções via cron)")
+	puppetCmd.Flags().StringVar(&serviceState, "service-state", installer.ServiceStateRunning, "Estado do serviço puppet após instalação (running|stopped)")
+	puppetCmd.Flags().BoolVar(&startStopped, "start-stopped-instances", false, "Iniciar instâncias paradas antes da instalação (padrão: pular)")
+	puppetCmd.Flags().BoolVar(&skipInvalidRows, "skip-invalid-rows", false, "Ignora linhas inválidas do CSV (listadas no resumo) em vez de abortar")
+	puppetCmd.Flags().StringArrayVar(&whereSelectors, "where", nil, "Seleciona instâncias por coluna do CSV (ex: environment=blue, shard>=3, hostname~^web-); pode ser repetida")
+	puppetCmd.Flags().StringVar(&onlyOS, "only-os", "", "Processa apenas instâncias da família de SO informada (debian, rhel, windows ou distribuição como ubuntu, amzn), pela coluna os do CSV ou metadados da instância")
+	puppetCmd.Flags().BoolVar(&forceDetect, "force-detect", false, "Detecta o SO na instância mesmo com a coluna os do CSV preenchida (inventário desatualizado)")
+	puppetCmd.Flags().BoolVar(&includeMaint, "include-maintenance", false, "Processar também instâncias em modo manutenção")
+	puppetCmd.Flags().StringVar(&asgMode, "asg-mode", executor.AutoScalingOff, "Instâncias de autoscaling groups sendo terminadas ou substituídas: off (processa normalmente), skip (ignora com o motivo) ou replace (processa a instância substituta)")
+	puppetCmd.Flags().BoolVar(&failDisappeared, "fail-on-disappeared", false, "Falhar a execução (código de saída) também quando instâncias desaparecem no meio dela (ex.: terminadas pelo autoscaling)")
+	puppetCmd.Flags().StringVar(&maintenanceTag, "maintenance-tag", cloud.DefaultMaintenanceTagKey, "Tag que marca instâncias em modo manutenção (valor true)")
+	puppetCmd.Flags().StringVar(&remoteWorkdir, "remote-workdir", "", "Diretório de trabalho nas instâncias para arquivos temporários (padrão: /tmp, ou /var/lib/opsmaster se /tmp for noexec)")
+	puppetCmd.Flags().StringVar(&encRegisterURL, "enc-register-url", "", "URL do ENC/CMDB para registrar o nó após a instalação (POST; opcional)")
+	puppetCmd.Flags().StringVar(&encTemplateFile, "enc-payload-template", "", "Arquivo com template Go do payload enviado ao ENC (padrão: JSON com certname, ambiente e metadados do CSV)")
+	puppetCmd.Flags().StringVar(&encToken, "enc-token", "", "Token Bearer para o ENC (aceita referência vault:caminho#campo)")
+	puppetCmd.Flags().StringVar(&foremanURL, "foreman-url", "", "URL do Foreman para criar/atualizar o host após a instalação (opcional; ou foreman.url no ~/.opsmaster.yaml)")
+	puppetCmd.Flags().StringVar(&foremanUser, "foreman-user", "", "Usuário da API do Foreman (ou foreman.username no ~/.opsmaster.yaml)")
+	puppetCmd.Flags().StringVar(&foremanPassword, "foreman-password", "", "Senha/token da API do Foreman; aceita referência vault:caminho#campo (ou foreman.password no ~/.opsmaster.yaml)")
+	puppetCmd.Flags().StringVar(&foremanOrg, "foreman-organization", "", "Organização do host no Foreman (opcional)")
+	puppetCmd.Flags().StringVar(&foremanLocation, "foreman-location", "", "Localização do host no Foreman (opcional)")
+	puppetCmd.Flags().StringVar(&foremanHGColumn, "foreman-hostgroup-column", installer.DefaultForemanHostgroupColumn, "Coluna do CSV com o hostgroup do Foreman")
+	puppetCmd.Flags().StringVar(&commandPrefix, "command-prefix", cloud.DefaultCommandPrefix, "Prefixo que identifica os comandos do opsmaster no histórico do SSM (comentário e primeira linha; \"-\" desativa a linha marcadora)")
+	puppetCmd.Flags().StringVar(&chaosSpec, "chaos", "", "Injeta falhas/latência aleatórias em modo dry-run (ex: fail-rate=10%,latency=5s; requer OPSMASTER_CHAOS=1)")
+	_ = puppetCmd.Flags().MarkHidden("chaos")
+	puppetCmd.Flags().StringVar(&dynamoDBTable, "dynamodb-table", "", "Tabela DynamoDB que recebe o estado mais recente de cada instância (opcional)")
+	puppetCmd.Flags().StringVar(&dynamoDBRegion, "dynamodb-region", "", "Região da tabela DynamoDB (padrão: região do perfil AWS)")
+	puppetCmd.Flags().StringVar(&eventsARN, "events-arn", "", "ARN de tópico SNS ou barramento EventBridge que recebe um evento ao término de cada instância (opcional)")
+	addCollectorFlags(puppetCmd)
+	addControlFlags(puppetCmd)
+	puppetCmd.Flags().BoolVar(&dynamoDBCreate, "dynamodb-create-table", false, "Cria a tabela DynamoDB (on-demand, chave instance_id) se não existir")
+	puppetCmd.Flags().StringVar(&repoSource, "repo-source", installer.RepoSourcePuppetlabs, "Origem do pacote Puppet: puppetlabs (puppet-agent de apt/yum.puppet.com ou espelhos) ou distro (pacote puppet dos repositórios da distribuição, binário em /usr/bin/puppet)")
+	puppetCmd.Flags().StringVar(&repoAptURL, "repo-apt-url", "", "URL base de um espelho interno do apt.puppet.com (ex: https://mirror.example.com/puppet-apt; padrão: repositório oficial)")
+	puppetCmd.Flags().StringVar(&repoYumURL, "repo-yum-url", "", "URL base de um espelho interno do yum.puppet.com (ex: https://mirror.example.com/puppet-yum; padrão: repositório oficial)")
+	puppetCmd.Flags().StringVar(&repoGPGKeyURL, "repo-gpg-key-url", "", "URL da chave GPG que assina os espelhos internos (obrigatória com --repo-apt-url/--repo-yum-url)")
+	puppetCmd.Flags().StringVar(&repoGPGFpr, "repo-gpg-fingerprint", "", "Fingerprint esperado da chave GPG dos espelhos, verificado na instância antes de confiar na chave (opcional)")
+	puppetCmd.Flags().StringVar(&shellOptions, "shell-options", "none", "Opções de segurança dos scripts de instalação: errexit, nounset, pipefail, errtrap (separadas por vírgula), strict (todas) ou none")
+	puppetCmd.Flags().BoolVar(&skipGPGCheck, "skip-gpg-check", false, "Não verificar assinaturas dos espelhos internos (desaconselhado; apenas espelhos air-gapped sem chave)")
+	puppetCmd.Flags().StringSliceVar(&releaseFprs, "release-gpg-fingerprint", nil, "Fingerprint da chave GPG da Puppet, Inc. aceita nos pacotes puppet<N>-release oficiais, verificados antes da instalação (padrão: chave de release atual; pode ser repetida)")
+	puppetCmd.Flags().StringVar(&releaseKeyURL, "release-gpg-key-url", "", "URL da chave GPG importada para verificar a assinatura do pacote .rpm de release oficial (padrão: chave de release da Puppet em yum.puppet.com)")
+	puppetCmd.Flags().StringVar(&proxyHost, "http-proxy-host", "", "Proxy HTTP usado pelo agente para alcançar o Puppet Server e o Forge (http_proxy_host no puppet.conf; hostname ou IP, sem esquema)")
+	puppetCmd.Flags().IntVar(&proxyPort, "http-proxy-port", 0, "Porta do proxy HTTP do agente (http_proxy_port; padrão 3128)")
+	puppetCmd.Flags().StringSliceVar(&noProxy, "no-proxy", nil, "Hosts acessados sem o proxy (no_proxy; *.dominio ou .dominio para um domínio inteiro; pode ser repetida)")
+	puppetCmd.Flags().BoolVar(&refreshMetadata, "refresh-metadata", false, "Ignorar cache de metadados das instâncias e consultar a API novamente")
 
 	// Retry configuration flags
 	puppetCmd.Flags().IntVar(&maxRetries, "max-retries", 3, "Maximum retry attempts for operations")
@@ -277,30 +463,146 @@ func runPuppetInstall(cmd *cobra.Command, args []string) error {
 
 	startTime := time.Now()
 	log.Info("🚀 Puppet Installation Started",
+		"run_id", logger.RunID(),
 		"instances_file", instancesFile,
 		"puppet_server", puppetServer,
 		"max_concurrency", maxConcurrency,
 		"dry_run", dryRun,
 	)
 
+	// Validate flag values before doing any remote work
+	if err := installer.ValidatePuppetVersion(puppetVersion); err != nil {
+		return fatalError(log, "Invalid --puppet-version", err)
+	}
+	if err := installer.ValidatePuppetEnvironment(environment); err != nil {
+		return fatalError(log, "Invalid --environment", err)
+	}
+	if err := installer.ValidateServiceState(serviceState); err != nil {
+		return fatalError(log, "Invalid --service-state", err)
+	}
+	if err := installer.ValidateRemoteWorkdir(remoteWorkdir); err != nil {
+		return fatalError(log, "Invalid --remote-workdir", err)
+	}
+	if err := installer.ValidateFirstRunSplay(firstRunSplay); err != nil {
+		return fatalError(log, "Invalid --first-run-splay", err)
+	}
+	agentLockOptions := installer.AgentLockOptions{Action: agentLock, StaleAfter: lockStaleAfter, Timeout: lockTimeout}
+	if err := agentLockOptions.Validate(); err != nil {
+		return fatalError(log, "Invalid agent lock settings", err)
+	}
+	repoOptions := installer.PuppetRepoOptions{
+		Source:         repoSource,
+		AptURL:         repoAptURL,
+		YumURL:         repoYumURL,
+		GPGKeyURL:      repoGPGKeyURL,
+		GPGFingerprint: repoGPGFpr,
+		SkipGPGCheck:   skipGPGCheck,
+
+		ReleaseFingerprints: releaseFprs,
+		ReleaseKeyURL:       releaseKeyURL,
+	}
+	if err := repoOptions.Validate(); err != nil {
+		return fatalError(log, "Invalid Puppet repository settings", err)
+	}
+	scriptShell, err := installer.ParseShellOptions(shellOptions)
+	if err != nil {
+		return fatalError(log, "Invalid --shell-options", err)
+	}
+	agentProxy := installer.PuppetProxy{Host: proxyHost, Port: proxyPort, NoProxy: noProxy}
+	if err := agentProxy.Validate(); err != nil {
+		return fatalError(log, "Invalid proxy settings", err)
+	}
+	if repoOptions.SkipGPGCheck {
+		log.Warn("⚠️  --skip-gpg-check: Puppet packages from the internal mirrors will not be signature-checked")
+	}
+	if repoOptions.IsDistro() && cmd.Flags().Changed("puppet-version") {
+		log.Warn("⚠️  --repo-source distro: --puppet-version is ignored, the OS release decides the Puppet version")
+	}
+	chaos, err := parseChaosFlag(log)
+	if err != nil {
+		return fatalError(log, "Invalid --chaos", err)
+	}
+	commandLabel := cloud.CommandLabel{Prefix: commandPrefix, RunID: logger.RunID(), Operator: cloud.CurrentOperator()}
+	if err := commandLabel.Validate(); err != nil {
+		return fatalError(log, "Invalid --command-prefix", err)
+	}
+	if maxPerServer < 0 || firstRunStagger < 0 {
+		return fatalError(log, "Invalid concurrency flags",
+			fmt.Errorf("--max-concurrency-per-server and --first-run-stagger must not be negative"))
+	}
+	if verifyGrace < 0 {
+		return fatalError(log, "Invalid --verify-grace-period", fmt.Errorf("must not be negative"))
+	}
+	if requeueFailed < 0 || retryTransient < 0 {
+		return fatalError(log, "Invalid requeue flags", fmt.Errorf("--requeue-failed and --requeue-transient must not be negative"))
+	}
+	expectedDurations, err := parseLivenessFlags()
+	if err != nil {
+		return fatalError(log, "Invalid liveness flags", err)
+	}
+	if maxOutputBytes < 0 {
+		return fatalError(log, "Invalid --max-output-bytes", fmt.Errorf("must not be negative"))
+	}
+	if err := executor.ValidateOrder(dispatchOrder); err != nil {
+		return fatalError(log, "Invalid --order", err)
+	}
+	if err := executor.ValidateAutoScaling(asgMode); err != nil {
+		return fatalError(log, "Invalid --asg-mode", err)
+	}
+	successCriteria, err := parseSuccessWhen()
+	if err != nil {
+		return fatalError(log, "Invalid --success-when", err)
+	}
+	overrides, err := executor.ParseInstanceOverrides(viper.Get("instance_overrides"))
+	if err != nil {
+		return fatalError(log, "Invalid instance_overrides in config file", err)
+	}
+	osFamily, err := parseOnlyOS()
+	if err != nil {
+		return fatalError(log, "Invalid --only-os", err)
+	}
+	if err := checkReadOnly("install puppet"); err != nil {
+		return err
+	}
+	releaseLock, err := acquireRunLock(log)
+	if err != nil {
+		return fatalError(log, "Failed to lock run report", err)
+	}
+	defer releaseLock()
+
 	// Create context with cancellation support (Ctrl+C)
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
+	encRegistrar, err := createENCRegistrar(ctx, cmd)
+	if err != nil {
+		return fatalError(log, "Invalid ENC registration settings", err)
+	}
+	foremanRegistrar, err := createForemanRegistrar(ctx)
+	if err != nil {
+		return fatalError(log, "Invalid Foreman settings", err)
+	}
+
 	// ============================================================
 	// STEP 1: Parse CSV file and load instances
 	// ============================================================
 	logStep(log, 1, "Parsing CSV file")
 	log.Info("📄 Reading instances", "file", instancesFile)
 
-	instances, err := parseInstancesFile(instancesFile)
+	instances, err := parseInstancesFile(ctx, instancesFile, whereSelectors)
 	if err != nil {
 		return fatalError(log, "Failed to parse CSV file", err)
 	}
 
 	log.Info("✅ CSV parsed successfully", "total_instances", len(instances))
 	if len(instances) == 0 {
-		return fmt.Errorf("no instances found in CSV file")
+		if len(whereSelectors) > 0 {
+			return i18n.Errorf("no instances match --where selectors: %s", strings.Join(whereSelectors, ", "))
+		}
+		return i18n.Errorf("no instances found in CSV file")
+	}
+	if err := installer.ValidateInventoryOS(instances); err != nil {
+		return fatalError(log, "Invalid os column in CSV", err)
 	}
 
 	// ============================================================
@@ -346,6 +648,16 @@ func runPuppetInstall(cmd *cobra.Command, args []string) error {
 		log.Info("   Using default retry policies")
 	}
 
+	// Share instance metadata (state, platform, tags) across commands in the same run
+	metadataCache := loadMetadataCache(log)
+	providerOptions = append(providerOptions, provider.WithMetadataCache(metadataCache))
+	providerOptions = append(providerOptions, provider.WithCommandLabel(commandLabel))
+	defer func() {
+		if err := metadataCache.Save(); err != nil {
+			log.Warn("Failed to save instance metadata cache", "error", err)
+		}
+	}()
+
 	cloudProvider, err := provider.NewProvider(cloudType, providerOptions...)
 	if err != nil {
 		return fatalError(log, "Failed to create cloud provider", err)
@@ -353,6 +665,23 @@ func runPuppetInstall(cmd *cobra.Command, args []string) error {
 
 	log.Info("✅ Cloud provider initialized", "provider", cloudProvider.Name())
 
+	// Enrich instances with provider metadata (fetched once, reused by later steps)
+	if describer := cloud.CapabilitiesOf(cloudProvider).Describe; describer != nil {
+		infos, err := describer.DescribeInstances(ctx, instances)
+		if err != nil {
+			log.Warn("Failed to fetch instance metadata, continuing without it", "error", err)
+		} else {
+			cloud.EnrichInstances(instances, infos)
+			log.Info("   Instance metadata loaded", "instances", len(infos), "refresh", refreshMetadata)
+		}
+	} else {
+		log.Info("   Instance metadata not loaded", "reason", cloud.Unsupported(cloudProvider, "describing instances"))
+	}
+	instances, err = planByOS(log, instances, osFamily)
+	if err != nil {
+		return err
+	}
+
 	// ============================================================
 	// STEP 3: Load custom facts configuration
 	// ============================================================
@@ -379,6 +708,8 @@ func runPuppetInstall(cmd *cobra.Command, args []string) error {
 				"file", factDef.FilePath,
 				"fact_name", factDef.FactName,
 				"field_count", len(factDef.Fields),
+				"environments", factDef.Environments,
+				"when", factDef.When,
 			)
 		}
 	} else {
@@ -391,6 +722,50 @@ func runPuppetInstall(cmd *cobra.Command, args []string) error {
 	// Validate that CSV columns required by facts exist
 	if len(instances) > 0 {
 		installer.LogMissingFactColumns(log, customFacts, instances[0])
+		installer.LogFactEnvironments(log, customFacts, instances)
+	}
+
+	// Load csr_attributes.yaml mapping (trusted facts)
+	var csrAttributes *installer.CSRAttributes
+	if csrAttrsFile != "" {
+		csrAttributes, err = installer.LoadCSRAttributesFromYAML(csrAttrsFile)
+		if err != nil {
+			return fatalError(log, "Failed to load csr attributes", err)
+		}
+		log.Info("✅ CSR attributes loaded from file",
+			"file", csrAttrsFile,
+			"extension_requests", len(csrAttributes.ExtensionRequests),
+			"custom_attributes", len(csrAttributes.CustomAttributes),
+		)
+		if len(instances) > 0 {
+			if missing := installer.ValidateCSRAttributeColumns(csrAttributes, instances[0]); len(missing) > 0 {
+				log.Warn("⚠️  Some csr attribute columns are missing or empty in CSV",
+					"missing_columns", missing,
+				)
+				log.Warn("   → These extensions will be omitted from csr_attributes.yaml")
+			}
+		}
+	}
+
+	// Load node-local hiera data mapping
+	var hieraNodeData *installer.HieraNodeData
+	if hieraDataFile != "" {
+		hieraNodeData, err = installer.LoadHieraNodeDataFromYAML(hieraDataFile)
+		if err != nil {
+			return fatalError(log, "Failed to load hiera node data", err)
+		}
+		log.Info("✅ Hiera node data loaded from file",
+			"file", hieraDataFile,
+			"keys", len(hieraNodeData.Keys),
+		)
+		if len(instances) > 0 {
+			if missing := installer.ValidateHieraNodeDataColumns(hieraNodeData, instances[0]); len(missing) > 0 {
+				log.Warn("⚠️  Some hiera node data columns are missing or empty in CSV",
+					"missing_columns", missing,
+				)
+				log.Warn("   → These keys will be omitted from the hiera data file")
+			}
+		}
 	}
 
 	// ============================================================
@@ -399,11 +774,25 @@ func runPuppetInstall(cmd *cobra.Command, args []string) error {
 	logStep(log, 4, "Creating Puppet installer")
 
 	puppetInstaller := installer.NewPuppetInstaller(installer.PuppetOptions{
-		Server:      puppetServer,
-		Port:        puppetPort,
-		Version:     puppetVersion,
-		Environment: environment,
-		CustomFacts: customFacts,
+		Server:         puppetServer,
+		Port:           puppetPort,
+		Version:        puppetVersion,
+		Environment:    environment,
+		CustomFacts:    customFacts,
+		DisableService: !enableService,
+		ServiceState:   serviceState,
+		RemoteWorkdir:  remoteWorkdir,
+		FirstRunSplay:  firstRunSplay,
+		ENC:            encRegistrar,
+		Foreman:        foremanRegistrar,
+		RunID:          logger.RunID(),
+		Repo:           repoOptions,
+		Shell:          scriptShell,
+		CSRAttributes:  csrAttributes,
+		HieraNodeData:  hieraNodeData,
+		Proxy:          agentProxy,
+		PruneFacts:     pruneFacts,
+		AgentLock:      agentLockOptions,
 	})
 
 	log.Info("✅ Puppet installer created",
@@ -412,6 +801,15 @@ func runPuppetInstall(cmd *cobra.Command, args []string) error {
 		"version", puppetVersion,
 		"environment", environment,
 		"custom_facts_enabled", len(customFacts) > 0,
+		"csr_attributes_enabled", csrAttributes != nil,
+		"hiera_node_data_enabled", hieraNodeData != nil,
+		"prune_facts", pruneFacts,
+		"agent_lock", agentLock,
+		"enable_service", enableService,
+		"service_state", serviceState,
+		"repo_source", repoSource,
+		"repo_mirror", repoOptions.IsMirror(),
+		"shell_options", scriptShell.Names(),
 	)
 
 	// ============================================================
@@ -423,6 +821,17 @@ func runPuppetInstall(cmd *cobra.Command, args []string) error {
 	} else {
 		log.Info("🔍 Validation will be performed (SSM + Puppet Server connectivity)")
 	}
+	if waitForSSM > 0 {
+		log.Info("⏳ Waiting for instances to come online in SSM before validation (--wait-for-ssm)", "timeout", waitForSSM)
+	}
+	if skipTagging {
+		log.Warn("⚠️  Tagging skipped (--skip-tagging enabled)")
+	}
+
+	trustedValidations, err := loadTrustedValidations(log, puppetInstaller.Name(), len(instances))
+	if err != nil {
+		return fatalError(log, "Invalid --reuse-preflight", err)
+	}
 
 	// ============================================================
 	// STEP 6: Execute parallel installation
@@ -431,6 +840,11 @@ func runPuppetInstall(cmd *cobra.Command, args []string) error {
 	log.Info("⚡ Executing installation",
 		"total_instances", len(instances),
 		"max_concurrency", maxConcurrency,
+		"max_concurrency_per_server", maxPerServer,
+		"first_run_stagger", firstRunStagger,
+		"verify_grace_period", verifyGrace,
+		"requeue_failed", requeueFailed,
+		"requeue_transient", retryTransient,
 		"dry_run", dryRun,
 	)
 
@@ -438,14 +852,54 @@ func runPuppetInstall(cmd *cobra.Command, args []string) error {
 		log.Warn("🔍 DRY RUN MODE: No changes will be made")
 	}
 
+	// Per-instance completion events (--events-arn)
+	onResult, err := createEventHook(ctx, "install puppet", puppetVersion)
+	if err != nil {
+		return fatalError(log, "Invalid --events-arn", err)
+	}
+
+	// Results streamed to a central collector (--collector-url)
+	collectorClient, streamResult, err := createCollectorStream(ctx, puppetInstaller.Name())
+	if err != nil {
+		return fatalError(log, "Invalid --collector-url", err)
+	}
+
+	// Pause/resume/abort from "opsmaster ctl" (--control-socket)
+	runControl, stopControl := startRunControl("install puppet")
+	defer stopControl()
+
 	// Create parallel executor
 	exec := executor.NewParallelExecutor(executor.ExecutorConfig{
-		Provider:       cloudProvider,
-		Installer:      puppetInstaller,
-		MaxConcurrency: maxConcurrency,
-		SkipValidation: skipValidation,
-		SkipTagging:    false,
-		DryRun:         dryRun,
+		Provider:           cloudProvider,
+		Installer:          puppetInstaller,
+		MaxConcurrency:     maxConcurrency,
+		MaxPerGroup:        maxPerServer,
+		FirstRunStagger:    firstRunStagger,
+		VerifyGracePeriod:  verifyGrace,
+		RequeueFailed:      requeueFailed,
+		RequeueTransient:   retryTransient,
+		SkipValidation:     skipValidation,
+		AgentWait:          waitForSSM,
+		SkipTagging:        skipTagging,
+		TrustedValidations: trustedValidations,
+		ForceDetect:        forceDetect,
+		DryRun:             dryRun,
+		StartStopped:       startStopped,
+		MaintenanceTag:     maintenanceTag,
+		IncludeMaintenance: includeMaint,
+		RunID:              logger.RunID(),
+		Chaos:              chaos,
+		OnResult:           chainResultHooks(onResult, streamResult),
+		HeartbeatInterval:  heartbeatEvery,
+		ExpectedDurations:  expectedDurations,
+		MaxOutputBytes:     maxOutputBytes,
+		Order:              dispatchOrder,
+		OrderSeed:          orderSeed,
+		SuccessWhen:        successCriteria,
+		Overrides:          overrides,
+		Control:            runControl,
+		AutoScaling:        asgMode,
+		SkipDiagnostics:    skipDiagnostics,
 	})
 
 	// Execute installation on all instances
@@ -461,42 +915,563 @@ func runPuppetInstall(cmd *cobra.Command, args []string) error {
 	duration := time.Since(startTime)
 
 	log.Info("📊 Installation Summary",
+		"run_id", result.RunID,
 		"total", result.Total,
 		"successful", result.Success,
 		"failed", result.Failed,
 		"skipped", result.Skipped,
+		"disappeared", result.Disappeared,
 		"duration", duration.Round(time.Second).String(),
 	)
 
 	// Print detailed results
 	printResults(result)
 
+	// Print slowest prerequisite validations
+	printValidationStats(puppetInstaller.ValidationStats())
+
+	// Opt-in anonymized usage report (telemetry.enabled in ~/.opsmaster.yaml)
+	sendTelemetry(ctx, "install puppet", result)
+
+	// Per-instance state for fleet dashboards (--dynamodb-table)
+	writeResultSinks(ctx, result, puppetVersion)
+
+	// Run report for "tags apply" and later runs (--report)
+	writeRunReport(exec, result)
+
+	// Final report with tags for the aggregated report (--collector-url)
+	sendCollectorReport(ctx, collectorClient, exec, result)
+
 	// Exit with error if any installations failed
+	if err := runError(result); err != nil {
+		return err
+	}
+
+	log.Info("✅ All installations completed successfully!")
+	return nil
+}
+
+// runError returns the error failing the command: failed installations and,
+// with --fail-on-disappeared, instances that disappeared mid-run.
+func runError(result *executor.AggregatedResult) error {
 	if result.Failed > 0 {
 		return fmt.Errorf("installation failed for %d instances", result.Failed)
 	}
+	if failDisappeared && result.Disappeared > 0 {
+		return fmt.Errorf("%d instances disappeared mid-run", result.Disappeared)
+	}
+	return nil
+}
 
-	log.Info("✅ All installations completed successfully!")
+// parseChaosFlag parses the hidden --chaos flag. Chaos mode is only
+// accepted with OPSMASTER_CHAOS=1 and always runs as a dry-run.
+func parseChaosFlag(log *slog.Logger) (*executor.ChaosConfig, error) {
+	if chaosSpec == "" {
+		return nil, nil
+	}
+	if !executor.ChaosEnabled() {
+		return nil, fmt.Errorf("chaos mode requires %s=1", executor.ChaosEnvVar)
+	}
+
+	chaos, err := executor.ParseChaos(chaosSpec)
+	if err != nil {
+		return nil, err
+	}
+	dryRun = true
+	log.Warn("🐒 Chaos mode enabled: forcing dry-run with injected failures",
+		"fail_rate", chaos.FailRate,
+		"latency", chaos.Latency)
+	return chaos, nil
+}
+
+// sendTelemetry reports anonymized usage when telemetry is enabled in the
+// config file. Failures are only logged at debug level.
+func sendTelemetry(ctx context.Context, command string, result *executor.AggregatedResult) {
+	config := telemetry.Config{
+		Enabled:  viper.GetBool("telemetry.enabled"),
+		Endpoint: viper.GetString("telemetry.endpoint"),
+	}
+	if !config.Enabled {
+		return
+	}
+
+	summary := telemetry.Summary{
+		Total:   result.Total,
+		Success: result.Success,
+		Failed:  result.Failed,
+		Skipped: result.Skipped,
+		DryRun:  dryRun,
+	}
+	for _, r := range result.Results {
+		if r.Status != executor.StatusSkipped {
+			summary.OS = append(summary.OS, r.Metadata.Get(installer.MetadataKeyOS))
+		}
+	}
+
+	if err := telemetry.Send(ctx, config, telemetry.NewEvent(command, summary)); err != nil {
+		logger.Get().Debug("Failed to send telemetry", "error", err)
+	}
+}
+
+// createEventHook builds the executor callback publishing an event per
+// finished instance of command to --events-arn. Returns nil when disabled or
+// in dry-run. Publish failures are logged and never fail the instance.
+func createEventHook(ctx context.Context, command, version string) (func(*executor.ExecutionResult), error) {
+	if eventsARN == "" {
+		return nil, nil
+	}
+	log := logger.Get()
+	if dryRun {
+		log.Info("🔍 DRY-RUN: skipping per-instance events", "target", eventsARN)
+		return nil, nil
+	}
+
+	publisher, err := awsprovider.NewEventPublisher(ctx, awsProfile, eventsARN, sink.EventSource)
+	if err != nil {
+		return nil, err
+	}
+	emitter := sink.NewEventEmitter(publisher, command, logger.RunID(), version)
+
+	return func(result *executor.ExecutionResult) {
+		if err := emitter.Emit(ctx, result); err != nil {
+			log.Warn("Failed to publish instance event", "instance_id", result.Instance.ID, "error", err)
+		}
+	}, nil
+}
+
+// addCollectorFlags registers the --collector-* flags of install commands.
+func addCollectorFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&collectorURL, "collector-url", "", "URL https do coletor central (opsmaster collector serve) que recebe os resultados de cada instância durante a execução (opcional)")
+	cmd.Flags().StringVar(&collectorCert, "collector-cert", "", "Certificado cliente (mTLS) para o coletor")
+	cmd.Flags().StringVar(&collectorKey, "collector-key", "", "Chave privada do certificado cliente (mTLS) para o coletor")
+	cmd.Flags().StringVar(&collectorCA, "collector-ca", "", "CA do certificado do coletor (padrão: CAs do sistema)")
+}
+
+// addControlFlags registers the --control-socket flag of install commands.
+func addControlFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&controlSocket, "control-socket", true, "Expõe a execução em um socket local para pausar, retomar ou abortar com opsmaster ctl")
+}
+
+// startRunControl exposes the run of command on its control socket
+// (--control-socket) for "opsmaster ctl". Returns a nil control when
+// disabled or when the socket can't be created: the run goes on without it.
+// The returned func closes the socket.
+func startRunControl(command string) (*executor.RunControl, func()) {
+	runID := logger.RunID()
+	if !controlSocket || runID == "" {
+		return nil, func() {}
+	}
+	log := logger.Get()
+
+	runControl := executor.NewRunControl(runID, command)
+	server, err := control.Listen(control.SocketPath(runID), runControl)
+	if err != nil {
+		log.Warn("Control socket unavailable: the run can't be paused with opsmaster ctl", "error", err)
+		return nil, func() {}
+	}
+	log.Info("🎛️  Control socket ready: opsmaster ctl pause|resume|abort|status", "socket", server.Path(), "run_id", runID)
+
+	return runControl, func() {
+		if err := server.Close(); err != nil {
+			log.Debug("Failed to close control socket", "error", err)
+		}
+	}
+}
+
+// createCollectorStream connects to --collector-url and builds the executor
+// callback streaming each finished instance of pkg to it. Returns a nil
+// client when disabled. Send failures are logged and never fail the instance.
+func createCollectorStream(ctx context.Context, pkg string) (*collector.Client, func(*executor.ExecutionResult), error) {
+	if collectorURL == "" {
+		return nil, nil, nil
+	}
+	log := logger.Get()
+
+	httpClient, err := httpclient.New(httpclient.Config{
+		CABundle:   collectorCA,
+		ClientCert: collectorCert,
+		ClientKey:  collectorKey,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	client, err := collector.NewClient(collectorURL, httpClient, collector.Run{
+		RunID:       logger.RunID(),
+		Package:     pkg,
+		DryRun:      dryRun,
+		SkipTagging: skipTagging,
+		StartTime:   time.Now().UTC(),
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := client.Ping(ctx); err != nil {
+		return nil, nil, err
+	}
+	log.Info("🛰️  Streaming results to collector", "url", collectorURL)
+
+	return client, func(result *executor.ExecutionResult) {
+		if err := client.SendResult(ctx, executor.NewReportEntry(result)); err != nil {
+			log.Warn("Failed to stream result to collector", "instance_id", result.Instance.ID, "error", err)
+		}
+	}, nil
+}
+
+// sendCollectorReport sends the final run report, with the tags of each
+// instance, to the collector. Failures are logged and never fail the command.
+func sendCollectorReport(ctx context.Context, client *collector.Client, exec *executor.ParallelExecutor, result *executor.AggregatedResult) {
+	if client == nil {
+		return
+	}
+	log := logger.Get()
+	if err := client.SendReport(ctx, exec.Report(result)); err != nil {
+		log.Warn("Failed to send run report to collector", "url", collectorURL, "error", err)
+		return
+	}
+	log.Info("   Run report sent to collector", "url", collectorURL)
+}
+
+// chainResultHooks combines executor callbacks, calling each non-nil hook in
+// order. Returns nil when no hook is set.
+func chainResultHooks(hooks ...func(*executor.ExecutionResult)) func(*executor.ExecutionResult) {
+	var active []func(*executor.ExecutionResult)
+	for _, hook := range hooks {
+		if hook != nil {
+			active = append(active, hook)
+		}
+	}
+	if len(active) == 0 {
+		return nil
+	}
+	return func(result *executor.ExecutionResult) {
+		for _, hook := range active {
+			hook(result)
+		}
+	}
+}
+
+// writeResultSinks upserts per-instance state into the configured sinks,
+// recording version as the installed package version.
+// Dry-runs write nothing; failures are logged and never fail the command.
+func writeResultSinks(ctx context.Context, result *executor.AggregatedResult, version string) {
+	if dynamoDBTable == "" {
+		return
+	}
+	log := logger.Get()
+	if dryRun {
+		log.Info("🔍 DRY-RUN: skipping DynamoDB state upsert", "table", dynamoDBTable)
+		return
+	}
+
+	client, err := awsprovider.NewDynamoDB(ctx, awsProfile, dynamoDBRegion)
+	if err != nil {
+		log.Warn("Failed to create DynamoDB client, instance state not recorded", "error", err)
+		return
+	}
+
+	records := sink.RecordsFromResult(result, version)
+	if err := sink.NewDynamoDBSink(client, dynamoDBTable, dynamoDBCreate).Write(ctx, records); err != nil {
+		log.Warn("Failed to record instance state in DynamoDB", "table", dynamoDBTable, "error", err)
+		return
+	}
+	log.Info("   Instance state recorded in DynamoDB",
+		"table", dynamoDBTable,
+		"region", client.Region(),
+		"instances", len(records))
+}
+
+// writeRunReport writes the JSON run report to --report.
+// Failures are logged and never fail the command.
+func writeRunReport(exec *executor.ParallelExecutor, result *executor.AggregatedResult) {
+	if reportFile == "" {
+		return
+	}
+	log := logger.Get()
+	report := exec.Report(result)
+	for _, usage := range flagalias.Used() {
+		report.DeprecatedFlags = append(report.DeprecatedFlags, executor.ReportDeprecatedFlag{Flag: usage.Flag, Replacement: usage.Replacement})
+	}
+	if err := executor.WriteReport(reportFile, report); err != nil {
+		log.Warn("Failed to write run report", "file", reportFile, "error", err)
+		return
+	}
+	log.Info("   Run report written", "file", reportFile)
+	if skipTagging && !dryRun {
+		log.Info("   Apply the skipped tags with: opsmaster tags apply --from-report " + reportFile)
+	}
+	autoPruneHistory(log)
+}
+
+// autoPruneHistory applies the retention of the history section of the
+// config when history.auto_prune is set. Failures are logged and never fail
+// the command.
+func autoPruneHistory(log *slog.Logger) {
+	results, err := retention.AutoPrune(viper.GetStringMap("history"), time.Now())
+	if err != nil {
+		log.Warn("Failed to prune run history", "error", err)
+		return
+	}
+	for _, result := range results {
+		if len(result.Removed) > 0 {
+			log.Info("   Run history pruned", "path", result.Path, "removed", len(result.Removed), "freed", retention.FormatSize(result.Freed))
+		}
+	}
+}
+
+// checkReadOnly refuses installs other than --dry-run in read-only mode
+// (--read-only), before any instance is processed.
+func checkReadOnly(command string) error {
+	if provider.ReadOnly() && !dryRun {
+		return i18n.Errorf("read-only mode: %s changes the instances, only --dry-run is allowed", command)
+	}
 	return nil
 }
 
+// acquireRunLock locks the --report file so two opsmaster runs never write it
+// at the same time. Returns the release function (no-op without --report).
+func acquireRunLock(log *slog.Logger) (func(), error) {
+	if reportFile == "" {
+		return func() {}, nil
+	}
+
+	lock, err := runlock.Acquire(reportFile, runlock.Options{
+		RunID:    logger.RunID(),
+		Operator: cloud.CurrentOperator(),
+		Force:    forceLock,
+	})
+	var held *runlock.HeldError
+	if errors.As(err, &held) {
+		return nil, fmt.Errorf("%w; wait for it to finish or use --force if it is no longer running", err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if prev := lock.Previous(); prev != nil {
+		log.Warn("⚠️  Took over run lock", "lock", lock.Path(),
+			"previous_pid", prev.PID, "previous_host", prev.Host, "previous_run_id", prev.RunID, "force", forceLock)
+	}
+
+	return func() {
+		if err := lock.Release(); err != nil {
+			log.Warn("Failed to release run lock", "lock", lock.Path(), "error", err)
+		}
+	}, nil
+}
+
+// loadTrustedValidations reads the validations of --reuse-preflight that are
+// recent enough to skip. Returns nil when the flag is not set.
+func loadTrustedValidations(log *slog.Logger, pkg string, total int) (map[string]time.Time, error) {
+	if reusePreflight == "" {
+		return nil, nil
+	}
+	report, err := executor.ReadReport(reusePreflight)
+	if err != nil {
+		return nil, err
+	}
+	trusted, err := report.TrustedValidations(pkg, preflightMaxAge, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	log.Info("♻️  Reusing preflight validations",
+		"report", reusePreflight,
+		"run_id", report.RunID,
+		"trusted", len(trusted),
+		"revalidated", max(total-len(trusted), 0),
+		"max_age", preflightMaxAge)
+	return trusted, nil
+}
+
+// createENCRegistrar builds the ENC registrar from --enc-* flags.
+// Returns nil when --enc-register-url is not set.
+func createENCRegistrar(ctx context.Context, cmd *cobra.Command) (*installer.ENCRegistrar, error) {
+	if encRegisterURL == "" {
+		return nil, nil
+	}
+
+	if err := secrets.ResolveFlags(ctx, cmd.Flags(), "enc-token"); err != nil {
+		return nil, err
+	}
+
+	config := installer.ENCConfig{URL: encRegisterURL, Token: encToken}
+	if encTemplateFile != "" {
+		payload, err := os.ReadFile(encTemplateFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read --enc-payload-template: %w", err)
+		}
+		config.PayloadTemplate = string(payload)
+	}
+
+	return installer.NewENCRegistrar(config)
+}
+
+// createForemanRegistrar builds the Foreman registrar from --foreman-* flags,
+// falling back to the foreman.* keys of the config file for URL and credentials.
+// Returns nil when no Foreman URL is configured.
+func createForemanRegistrar(ctx context.Context) (*installer.ForemanRegistrar, error) {
+	config := installer.ForemanConfig{
+		URL:             firstNonEmpty(foremanURL, viper.GetString("foreman.url")),
+		Username:        firstNonEmpty(foremanUser, viper.GetString("foreman.username")),
+		Password:        firstNonEmpty(foremanPassword, viper.GetString("foreman.password")),
+		Organization:    foremanOrg,
+		Location:        foremanLocation,
+		HostgroupColumn: foremanHGColumn,
+	}
+	if config.URL == "" {
+		return nil, nil
+	}
+
+	password, err := secrets.Resolve(ctx, config.Password)
+	if err != nil {
+		return nil, fmt.Errorf("foreman password: %w", err)
+	}
+	config.Password = password
+
+	return installer.NewForemanRegistrar(config)
+}
+
+// firstNonEmpty returns the first non-empty value.
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// loadMetadataCache opens the persisted instance metadata cache.
+// Falls back to an in-memory cache when the file cannot be used.
+func loadMetadataCache(log *slog.Logger) *cloud.MetadataCache {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		log.Warn("Cannot resolve home directory, using in-memory metadata cache", "error", err)
+		return cloud.NewMetadataCache(cloud.DefaultMetadataTTL)
+	}
+
+	path := filepath.Join(home, ".opsmaster", "cache", "instance-metadata.json")
+	cache, err := cloud.NewFileMetadataCache(path, cloud.DefaultMetadataTTL, refreshMetadata)
+	if err != nil {
+		log.Warn("Failed to load metadata cache, using in-memory cache", "error", err)
+		return cloud.NewMetadataCache(cloud.DefaultMetadataTTL)
+	}
+	return cache
+}
+
 // parseInstancesFile parses CSV file and returns list of instances
-func parseInstancesFile(filePath string) ([]*cloud.Instance, error) {
+// matching the --where selectors (all instances if none given).
+func parseInstancesFile(ctx context.Context, filePath string, where []string) ([]*cloud.Instance, error) {
 	// Create CSV parser with configuration
 	parser := csv.NewParser(csv.CSVConfig{
-		HasHeader:      true, // Expect header row
-		RequiredFields: []string{"instance_id", "account", "region"},
-		CloudDefault:   "aws",
-		Delimiter:      ',',
+		HasHeader:       true, // Expect header row
+		RequiredFields:  []string{"instance_id", "account", "region"},
+		CloudDefault:    "aws",
+		Delimiter:       ',',
+		SkipInvalidRows: skipInvalidRows,
+		ColumnAliases:   viper.GetStringMapStringSlice("csv.column_aliases"),
 	})
 
-	// Parse file (ParseFile expects filePath string, not *os.File)
-	instances, err := parser.ParseFile(filePath)
+	// Parse local file, https:// or s3:// URL (optionally gzipped)
+	instances, err := parser.ParseSource(ctx, filePath, awsprovider.S3Opener(awsProfile))
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse CSV: %w", err)
 	}
+	printSkippedRows(parser.SkippedRows())
+
+	selected, err := csv.SelectInstances(instances, parser.Columns(), where)
+	if err != nil {
+		return nil, i18n.Errorf("invalid --where selector: %w", err)
+	}
+	if len(where) > 0 {
+		logger.Get().Info("   Applied --where selectors",
+			"selectors", strings.Join(where, ", "),
+			"matched", len(selected),
+			"total", len(instances))
+	}
+
+	return selected, nil
+}
+
+// maxSkippedRowsShown limits how many skipped CSV rows are listed.
+const maxSkippedRowsShown = 20
+
+// printSkippedRows lists CSV rows ignored by --skip-invalid-rows.
+func printSkippedRows(skipped []*csv.ParseError) {
+	if len(skipped) == 0 {
+		return
+	}
+
+	logger.Get().Warn("⚠️  Invalid CSV rows skipped", "count", len(skipped))
+	presenter.Printf("\n⚠️  %d linha(s) inválida(s) ignorada(s) no CSV:\n", len(skipped))
+	for i, rowErr := range skipped {
+		if i == maxSkippedRowsShown {
+			fmt.Printf("   ... e mais %d\n", len(skipped)-maxSkippedRowsShown)
+			break
+		}
+		fmt.Printf("   - %s\n", rowErr.Error())
+	}
+	fmt.Println()
+}
+
+// parseOnlyOS normalizes --only-os ("" when not set).
+func parseOnlyOS() (string, error) {
+	if onlyOS == "" {
+		return "", nil
+	}
+	return installer.ParseOSFamily(onlyOS)
+}
+
+// parseLivenessFlags validates --heartbeat-interval and returns the phase
+// durations of --expected-duration.
+func parseLivenessFlags() (map[string]time.Duration, error) {
+	if heartbeatEvery < 0 {
+		return nil, fmt.Errorf("--heartbeat-interval must not be negative")
+	}
+	durations, err := executor.ParseExpectedDurations(expectedPhases)
+	if err != nil {
+		return nil, fmt.Errorf("--expected-duration: %w", err)
+	}
+	return durations, nil
+}
+
+// parseSuccessWhen parses --success-when (nil when unset).
+func parseSuccessWhen() (*criteria.Expr, error) {
+	if successWhen == "" {
+		return nil, nil
+	}
+	return executor.ParseSuccessCriteria(successWhen)
+}
+
+// planByOS prints how many instances will use each install script family
+// (from the CSV os column or the instance platform) and, when family is set
+// (--only-os), keeps only the instances of that family.
+func planByOS(log *slog.Logger, instances []*cloud.Instance, family string) ([]*cloud.Instance, error) {
+	plan := installer.PlanByOS(instances)
+	rows := make([][]string, 0, len(plan))
+	for _, entry := range plan {
+		script := entry.Family
+		if entry.Family == installer.OSFamilyUnknown {
+			script = "detectado na instalação (dry-run assume debian)"
+		}
+		rows = append(rows, []string{entry.Family, strconv.Itoa(entry.Count), script})
+	}
+	presenter.Println("\n🖥️  Instâncias por família de SO:")
+	presenter.PrintTable([]string{"OS", "INSTANCES", "SCRIPT"}, rows)
+	fmt.Println()
+
+	if family == "" {
+		return instances, nil
+	}
 
-	return instances, nil
+	selected, unknown := installer.FilterByOS(instances, family)
+	if unknown > 0 {
+		log.Warn("⚠️  Instances with unknown OS excluded by --only-os (add an os column to the CSV to target them)",
+			"count", unknown)
+	}
+	if len(selected) == 0 {
+		return nil, i18n.Errorf("no instances match --only-os %s", onlyOS)
+	}
+	log.Info("🎯 Targeting OS family", "only_os", family, "instances", len(selected), "excluded", len(instances)-len(selected))
+	return selected, nil
 }
 
 // prepareResultRows converts AggregatedResult to table rows for presenter.PrintTable.
@@ -527,13 +1502,19 @@ func prepareResultRows(result *executor.AggregatedResult) (header []string, rows
 	// Convert results to rows
 	rows = [][]string{}
 	for _, r := range result.Results {
+		// Instances that failed before installation have no metadata
+		metadata := r.Metadata
+		if metadata == nil {
+			metadata = &installer.InstallMetadata{}
+		}
+
 		row := []string{
-			r.Instance.ID,
+			r.Instance.Label(),
 			r.Instance.Account,
 			r.Instance.Region,
 			getStatusEmoji(r.Status),
-			getCertnameDisplay(r.Metadata),
-			r.Metadata["os"],
+			getCertnameDisplay(metadata),
+			metadata.OS,
 			formatDuration(r.Duration),
 			formatError(r),
 		}
@@ -552,24 +1533,25 @@ func getStatusEmoji(status executor.ExecutionStatus) string {
 		return "❌"
 	case executor.StatusSkipped:
 		return "⏭️"
+	case executor.StatusDisappeared:
+		return "👻"
 	default:
 		return "❓"
 	}
 }
 
 // getCertnameDisplay formats certname with (*) marker if preserved.
-func getCertnameDisplay(metadata map[string]string) string {
-	certname := metadata["certname"]
-	if certname == "" {
+func getCertnameDisplay(metadata *installer.InstallMetadata) string {
+	if metadata.Certname == "" {
 		return "-"
 	}
 
 	// Add marker if certname was preserved
-	if metadata["certname_preserved"] == "true" {
-		return certname + " (*)"
+	if metadata.CertnamePreserved {
+		return metadata.Certname + " (*)"
 	}
 
-	return certname
+	return metadata.Certname
 }
 
 // formatDuration formats duration in human-readable format (45s, 1m30s, etc.)
@@ -596,8 +1578,13 @@ func formatDuration(d time.Duration) string {
 // Returns empty string for successful installations, first line of error for failed ones.
 // Multi-line errors are truncated to first line for table compactness.
 func formatError(r *executor.ExecutionResult) string {
-	// Success/Skipped = no error message
-	if r.Status != executor.StatusFailed {
+	// Skipped = show why it was skipped
+	if r.Status == executor.StatusSkipped {
+		return r.SkipReason
+	}
+
+	// Success = no error message
+	if r.Status != executor.StatusFailed && r.Status != executor.StatusDisappeared {
 		return ""
 	}
 
@@ -607,11 +1594,17 @@ func formatError(r *executor.ExecutionResult) string {
 		return "Unknown error"
 	}
 
-	// Extract first line only (for table readability)
+	// Extract first line only (for table readability), plus the last output
+	// line of failed scripts, which usually holds the cause
 	errMsg := err.Error()
 	lines := strings.Split(errMsg, "\n")
 	if len(lines) > 0 && strings.TrimSpace(lines[0]) != "" {
-		return strings.TrimSpace(lines[0])
+		first := strings.TrimSpace(lines[0])
+		if r.ErrorContext != nil && len(r.ErrorContext.OutputTail) > 0 {
+			tail := r.ErrorContext.OutputTail
+			return strings.TrimSuffix(first, ":") + ": " + strings.TrimSpace(tail[len(tail)-1])
+		}
+		return first
 	}
 
 	return errMsg
@@ -639,12 +1632,146 @@ func printResults(result *executor.AggregatedResult) {
 
 	// Print summary
 	printSummary(result)
+
+	// Print per-account/per-region breakdown
+	printBreakdown(result)
+
+	// Print slowest instances with per-phase timings
+	printSlowest(result.Slowest(slowestCount))
+
+	// Print dominant failure modes
+	printFailureClusters(result.FailureClusters())
+
+	// Installed instances whose post-install hook (ENC registration) failed
+	printPostInstallWarnings(result)
+
+	// Instances that only passed verification within --verify-grace-period
+	printVerifiedLate(result)
+
+	// Instances with phases longer than --expected-duration
+	printStuck(result)
+
+	// Instances where a previous agent run still held the run lock
+	printAgentLocks(result)
+
+	// Instances whose status --success-when changed
+	printStatusOverrides(result)
+}
+
+// printVerifiedLate lists instances that passed verification only after
+// retries, which hints at slow first converges worth looking into.
+func printVerifiedLate(result *executor.AggregatedResult) {
+	var lines []string
+	for _, r := range result.Results {
+		if late := r.Metadata.Get(installer.MetadataKeyVerifiedLate); late != "" {
+			lines = append(lines, fmt.Sprintf("   %s: verified %s after the first check", r.Instance.Label(), late))
+		}
+	}
+	if len(lines) == 0 {
+		return
+	}
+
+	presenter.Printf("\n⏳ %d instance(s) verified late (within --verify-grace-period):\n", len(lines))
+	fmt.Println(strings.Join(lines, "\n"))
+}
+
+// printStuck lists instances whose phases ran longer than expected, reported
+// stuck by the liveness logs while the run went on.
+func printStuck(result *executor.AggregatedResult) {
+	var lines []string
+	for _, r := range result.Results {
+		if len(r.StuckPhases) > 0 {
+			lines = append(lines, fmt.Sprintf("   %s: %s (%s)", r.Instance.Label(), strings.Join(r.StuckPhases, ", "), r.Status))
+		}
+	}
+	if len(lines) == 0 {
+		return
+	}
+
+	presenter.Printf("\n🐌 %d instance(s) with phases longer than expected (--expected-duration):\n", len(lines))
+	fmt.Println(strings.Join(lines, "\n"))
+}
+
+// printAgentLocks lists instances where an earlier agent run still held
+// agent_catalog_run.lock, with the lock age, so stuck agents stand out.
+func printAgentLocks(result *executor.AggregatedResult) {
+	var lines []string
+	for _, r := range result.Results {
+		if age := r.Metadata.Get(installer.MetadataKeyAgentLockAge); age != "" {
+			lines = append(lines, fmt.Sprintf("   %s: lock held for %s (%s)", r.Instance.Label(), age, r.Status))
+		}
+	}
+	if len(lines) == 0 {
+		return
+	}
+
+	presenter.Printf("\n🔒 %d instance(s) with a puppet agent run lock (--agent-lock %s):\n", len(lines), agentLock)
+	fmt.Println(strings.Join(lines, "\n"))
+}
+
+// printStatusOverrides lists instances whose workflow status the success
+// criteria replaced, so an upgraded failure never goes unnoticed.
+func printStatusOverrides(result *executor.AggregatedResult) {
+	var lines []string
+	for _, r := range result.Results {
+		if r.OriginalStatus != executor.StatusPending {
+			lines = append(lines, fmt.Sprintf("   %s: %s → %s", r.Instance.Label(), r.OriginalStatus, r.Status))
+		}
+	}
+	if len(lines) == 0 {
+		return
+	}
+
+	presenter.Printf("\n⚖️  %d instance(s) with status changed by --success-when:\n", len(lines))
+	fmt.Println(strings.Join(lines, "\n"))
+}
+
+// printPostInstallWarnings lists successful instances whose post-install
+// hook failed (e.g., ENC registration), since they need manual follow-up.
+func printPostInstallWarnings(result *executor.AggregatedResult) {
+	var lines []string
+	for _, r := range result.Results {
+		if r.Status == executor.StatusSuccess && r.PostInstallErr != nil {
+			lines = append(lines, fmt.Sprintf("   %s: %v", r.Instance.Label(), r.PostInstallErr))
+		}
+	}
+	if len(lines) == 0 {
+		return
+	}
+
+	presenter.Printf("\n⚠️  Post-install hook failed for %d installed instance(s):\n", len(lines))
+	fmt.Println(strings.Join(lines, "\n"))
+}
+
+// maxPrintedClusters limits failure clusters printed at the end of a run.
+const maxPrintedClusters = 10
+
+// printFailureClusters prints failed instances grouped by error signature,
+// largest cluster first, with sample instance IDs for each.
+func printFailureClusters(clusters []executor.FailureCluster) {
+	if len(clusters) == 0 {
+		return
+	}
+
+	presenter.Println("\n🔎 Failure clusters:")
+	for i, cluster := range clusters {
+		if i == maxPrintedClusters {
+			fmt.Printf("   ... and %d more cluster(s)\n", len(clusters)-maxPrintedClusters)
+			break
+		}
+
+		samples := strings.Join(cluster.SampleIDs, ", ")
+		if cluster.Count > len(cluster.SampleIDs) {
+			samples += ", ..."
+		}
+		presenter.Printf("   %d× %s\n      e.g. %s\n", cluster.Count, cluster.Signature, samples)
+	}
 }
 
 // hasCertnamePreserved checks if any instance had certname preserved.
 func hasCertnamePreserved(result *executor.AggregatedResult) bool {
 	for _, r := range result.Results {
-		if r.Metadata["certname_preserved"] == "true" {
+		if r.Metadata != nil && r.Metadata.CertnamePreserved {
 			return true
 		}
 	}
@@ -668,8 +1795,93 @@ func printSummary(result *executor.AggregatedResult) {
 		}
 	}
 
-	fmt.Printf("\n📊 Summary: %d successful, %d failed, %d skipped\n",
+	presenter.Printf("\n📊 Summary: %d successful, %d failed, %d skipped\n",
 		successCount, failedCount, skippedCount)
+	if result.Disappeared > 0 {
+		presenter.Printf("👻 %d instances disappeared mid-run (terminated or deregistered)\n", result.Disappeared)
+	}
+	if result.RunID != "" {
+		presenter.Printf("🔖 Run ID: %s\n", result.RunID)
+	}
+}
+
+// printBreakdown prints per-account and per-region counts and durations,
+// only when the run spans more than one account or region.
+func printBreakdown(result *executor.AggregatedResult) {
+	for _, breakdown := range []struct {
+		title   string
+		heading string
+		groups  []executor.GroupSummary
+	}{
+		{"ACCOUNT", "📊 By account:", result.AccountBreakdown()},
+		{"REGION", "📊 By region:", result.RegionBreakdown()},
+	} {
+		if len(breakdown.groups) < 2 {
+			continue
+		}
+
+		header := []string{breakdown.title, "TOTAL", "SUCCESS", "FAILED", "SKIPPED", "FAILURE RATE", "AVG DURATION", "MAX DURATION"}
+		rows := make([][]string, 0, len(breakdown.groups))
+		for _, group := range breakdown.groups {
+			rows = append(rows, []string{
+				group.Key,
+				strconv.Itoa(group.Total),
+				strconv.Itoa(group.Success),
+				strconv.Itoa(group.Failed),
+				strconv.Itoa(group.Skipped),
+				fmt.Sprintf("%.1f%%", group.FailureRate()),
+				group.AvgDuration.Round(time.Second).String(),
+				group.MaxDuration.Round(time.Second).String(),
+			})
+		}
+
+		presenter.Println("\n" + breakdown.heading)
+		presenter.PrintTable(header, rows)
+	}
+}
+
+// printSlowest lists the slowest instances with the time of each phase.
+func printSlowest(slowest []*executor.ExecutionResult) {
+	if len(slowest) == 0 {
+		return
+	}
+
+	presenter.Printf("\n🐢 Slowest %d instance(s):\n", len(slowest))
+	for _, r := range slowest {
+		phases := make([]string, 0, len(r.Phases))
+		for _, phase := range r.Phases {
+			phases = append(phases, fmt.Sprintf("%s %s", phase.Name, phase.Duration.Round(100*time.Millisecond)))
+		}
+		fmt.Printf("   %s (%s/%s) %s [%s]: %s\n",
+			r.Instance.Label(),
+			r.Instance.Account,
+			r.Instance.Region,
+			r.Duration.Round(100*time.Millisecond),
+			r.Status,
+			strings.Join(phases, ", "))
+	}
+}
+
+// printValidationStats prints per-validator timing statistics, slowest first.
+// Helps spotting a single hung connectivity check stalling the validation phase.
+func printValidationStats(stats []validator.ValidatorStats) {
+	if len(stats) == 0 {
+		return
+	}
+
+	presenter.Println("\n⏱️  Slowest validations:")
+	for _, stat := range stats {
+		line := fmt.Sprintf("   %s: max %s (instance %s), avg %s over %d run(s)",
+			stat.Name,
+			stat.MaxDuration.Round(time.Millisecond),
+			stat.SlowestInstance,
+			stat.AverageDuration().Round(time.Millisecond),
+			stat.Count)
+		if stat.TimedOut > 0 {
+			line += fmt.Sprintf(", %d timed out", stat.TimedOut)
+		}
+		fmt.Println(line)
+	}
 }
 
 // determineAWSProfile determines which AWS profile to use for authentication.
@@ -0,0 +1,151 @@
+package install
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/estudosdevops/opsmaster/internal/cloud"
+	awsprovider "github.com/estudosdevops/opsmaster/internal/cloud/aws"
+	"github.com/estudosdevops/opsmaster/internal/installer"
+	"github.com/estudosdevops/opsmaster/internal/logger"
+)
+
+// systemd-unit command flags (instance selection, AWS and execution flags
+// are shared with the puppet command)
+var (
+	unitFile     string        // Local unit file deployed to the instances
+	binaryURL    string        // https:// or s3:// URL of the binary
+	binaryPath   string        // Install path of the binary
+	binarySHA256 string        // Expected SHA-256 of the binary
+	binaryURLTTL time.Duration // Validity of the presigned s3:// URL of each instance
+)
+
+// systemdUnitCmd represents the systemd unit deployment command
+var systemdUnitCmd = &cobra.Command{
+	Use:   "systemd-unit",
+	Short: "Implanta um binário e uma unit do systemd em instâncias na nuvem",
+	Long: `Implanta pequenos agentes internos sem empacotamento: baixa o binário (opcional),
+grava a unit em /etc/systemd/system, recarrega o systemd, habilita e reinicia a
+unit e verifica que ela continua ativa.
+
+O binário pode vir de uma URL https:// ou s3://. Para s3://, o opsmaster gera
+localmente, para cada instância, uma URL pré-assinada (válida por --binary-url-ttl)
+com o perfil AWS, então as instâncias só precisam de curl ou wget, sem credenciais
+de S3.
+
+Reexecutar o comando atualiza o binário e a unit e reinicia o serviço.
+
+Exemplos:
+  # Unit e binário no S3 com checksum
+  opsmaster install systemd-unit --instances-file instances.csv \
+    --unit-file ./my-agent.service \
+    --binary-url s3://artifacts/my-agent/1.4.0/my-agent \
+    --binary-sha256 3b1f...
+
+  # Apenas a unit (binário já presente nas instâncias)
+  opsmaster install systemd-unit --instances-file instances.csv --unit-file ./cleanup.service`,
+
+	RunE: runSystemdUnitInstall,
+}
+
+func init() {
+	// Register systemd-unit subcommand
+	InstallCmd.AddCommand(systemdUnitCmd)
+
+	// Unit flags
+	systemdUnitCmd.Flags().StringVar(&unitFile, "unit-file", "", "Arquivo .service local enviado para /etc/systemd/system (obrigatório)")
+	systemdUnitCmd.Flags().StringVar(&binaryURL, "binary-url", "", "URL https:// ou s3:// do binário (opcional)")
+	systemdUnitCmd.Flags().StringVar(&binaryPath, "binary-path", "", "Caminho do binário na instância (padrão: /usr/local/bin/<nome do arquivo na URL>)")
+	systemdUnitCmd.Flags().StringVar(&binarySHA256, "binary-sha256", "", "SHA-256 esperado do binário (recomendado)")
+	systemdUnitCmd.Flags().DurationVar(&binaryURLTTL, "binary-url-ttl", 15*time.Minute, "Validade da URL pré-assinada de binários s3://, gerada para cada instância antes do download")
+	systemdUnitCmd.MarkFlagRequired("unit-file")
+
+	// Instance selection and execution flags (shared with install puppet)
+	addPackageInstallFlags(systemdUnitCmd)
+}
+
+// runSystemdUnitInstall executes the systemd unit deployment workflow
+func runSystemdUnitInstall(_ *cobra.Command, _ []string) error {
+	log := logger.Get()
+
+	log.Info("🚀 systemd Unit Deployment Started",
+		"run_id", logger.RunID(),
+		"instances_file", instancesFile,
+		"unit_file", unitFile,
+		"binary_url", binaryURL,
+		"max_concurrency", maxConcurrency,
+		"dry_run", dryRun,
+	)
+
+	// Create context with cancellation support (Ctrl+C)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Validate flag values before doing any remote work
+	opts, err := systemdUnitOptionsFromFlags(ctx)
+	if err != nil {
+		return fatalError(log, "Invalid systemd unit settings", err)
+	}
+
+	return runPackageInstall(ctx, log, packageInstall{
+		command:    "install systemd-unit",
+		title:      "systemd unit",
+		validation: "SSM + systemd",
+		installer:  installer.NewSystemdUnitInstaller(opts),
+		checkInstances: func(log *slog.Logger, _ []*cloud.Instance) {
+			log.Info("✅ Unit configured",
+				"unit", opts.UnitName,
+				"binary_path", opts.BinaryPath,
+				"checksum", opts.BinarySHA256 != "")
+			if opts.BinaryURL != "" && opts.BinarySHA256 == "" {
+				log.Warn("⚠️  No --binary-sha256: the downloaded binary is not verified")
+			}
+		},
+	})
+}
+
+// systemdUnitOptionsFromFlags reads --unit-file, presigns s3:// binary URLs
+// and validates the resulting options.
+func systemdUnitOptionsFromFlags(ctx context.Context) (installer.SystemdUnitOptions, error) {
+	content, err := os.ReadFile(unitFile)
+	if err != nil {
+		return installer.SystemdUnitOptions{}, fmt.Errorf("failed to read --unit-file: %w", err)
+	}
+
+	opts := installer.SystemdUnitOptions{
+		UnitName:     filepath.Base(unitFile),
+		UnitContent:  string(content),
+		BinaryURL:    binaryURL,
+		BinaryPath:   binaryPath,
+		BinarySHA256: binarySHA256,
+	}
+
+	if strings.HasPrefix(binaryURL, "s3://") {
+		object, err := awsprovider.ParseS3URL(binaryURL)
+		if err != nil {
+			return opts, err
+		}
+		if opts.BinaryPath == "" {
+			opts.BinaryPath = "/usr/local/bin/" + filepath.Base(object.Key)
+		}
+		// The URL is recorded in the SSM command history: each instance
+		// gets its own, valid only for --binary-url-ttl
+		presign, err := awsprovider.NewS3Presigner(ctx, awsProfile, object)
+		if err != nil {
+			return opts, err
+		}
+		opts.PresignBinaryURL = func() (string, error) { return presign(binaryURLTTL) }
+		if opts.BinaryURL, err = opts.PresignBinaryURL(); err != nil {
+			return opts, err
+		}
+	}
+
+	return opts, opts.Validate()
+}
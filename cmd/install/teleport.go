@@ -0,0 +1,133 @@
+package install
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/spf13/cobra"
+
+	"github.com/estudosdevops/opsmaster/internal/cloud"
+	"github.com/estudosdevops/opsmaster/internal/installer"
+	"github.com/estudosdevops/opsmaster/internal/logger"
+	"github.com/estudosdevops/opsmaster/internal/secrets"
+)
+
+// Teleport command flags (instance selection, AWS and execution flags are
+// shared with the puppet command)
+var (
+	teleportProxyServer    string   // Teleport proxy address (host[:port])
+	teleportAuthServer     string   // Teleport auth server address (host[:port])
+	teleportVersion        string   // Major version of the release channel
+	teleportJoinToken      string   // Join token (supports vault: references)
+	teleportTokenParameter string   // SSM parameter holding the join token
+	teleportCAPin          string   // CA pin for auth server joins
+	teleportNodenameColumn string   // CSV column with the node name
+	teleportLabelColumns   []string // CSV columns added as node labels
+)
+
+// teleportCmd represents the Teleport node installation command
+var teleportCmd = &cobra.Command{
+	Use:   "teleport",
+	Short: "Instala o Teleport (nó SSH) em instâncias na nuvem",
+	Long: `Instala o Teleport a partir dos repositórios oficiais e registra as instâncias
+como nós SSH do cluster, para substituir o acesso via bastion.
+
+O SO é detectado na instância (Debian/Ubuntu ou RHEL/Amazon Linux) e o pacote vem
+do canal estável da versão principal (--teleport-version). O /etc/teleport.yaml é
+gerado por instância (nome do nó a partir do CSV e labels com instance_id, conta,
+região e as colunas de --label-columns), validado com "teleport configure --test"
+e o serviço é habilitado e reiniciado.
+
+O token de registro é gravado em /etc/teleport/join-token (modo 600) e nunca
+aparece no texto do script: --join-token é enviado como variável de ambiente
+(aceita referência vault:caminho#campo) e --join-token-parameter é resolvido
+pelo próprio SSM a partir do Parameter Store.
+
+Exemplos:
+  # Registrar via proxy com token vindo do Vault
+  opsmaster install teleport --instances-file instances.csv \
+    --proxy-server teleport.example.com --join-token vault:secret/data/teleport#node_token
+
+  # Registrar direto no auth server com token no Parameter Store
+  opsmaster install teleport --instances-file instances.csv \
+    --auth-server auth.internal:3025 --ca-pin sha256:abc123... \
+    --join-token-parameter /teleport/node-token --label-columns environment,team`,
+
+	RunE: runTeleportInstall,
+}
+
+func init() {
+	// Register teleport subcommand
+	InstallCmd.AddCommand(teleportCmd)
+
+	// Cluster flags
+	teleportCmd.Flags().StringVar(&teleportProxyServer, "proxy-server", "", "Proxy do Teleport: host[:porta] (porta padrão 443)")
+	teleportCmd.Flags().StringVar(&teleportAuthServer, "auth-server", "", "Auth server do Teleport: host[:porta] (porta padrão 3025; alternativa a --proxy-server)")
+	teleportCmd.Flags().StringVar(&teleportVersion, "teleport-version", "16", "Versão principal do Teleport (deve corresponder à versão do cluster)")
+	teleportCmd.Flags().StringVar(&teleportJoinToken, "join-token", "", "Token de registro dos nós; aceita referência vault:caminho#campo")
+	teleportCmd.Flags().StringVar(&teleportTokenParameter, "join-token-parameter", "", "Parâmetro do SSM Parameter Store com o token de registro (alternativa a --join-token)")
+	teleportCmd.Flags().StringVar(&teleportCAPin, "ca-pin", "", "CA pin do cluster (sha256:...), recomendado com --auth-server")
+	teleportCmd.Flags().StringVar(&teleportNodenameColumn, "nodename-column", installer.DefaultTeleportNodenameColumn, "Coluna do CSV com o nome do nó (vazia = instance_id)")
+	teleportCmd.Flags().StringSliceVar(&teleportLabelColumns, "label-columns", nil, "Colunas do CSV adicionadas como labels do nó (ex: environment,team)")
+
+	// Instance selection and execution flags (shared with install puppet)
+	addPackageInstallFlags(teleportCmd)
+}
+
+// runTeleportInstall executes the Teleport installation workflow
+func runTeleportInstall(cmd *cobra.Command, _ []string) error {
+	log := logger.Get()
+
+	log.Info("🚀 Teleport Installation Started",
+		"run_id", logger.RunID(),
+		"instances_file", instancesFile,
+		"proxy_server", teleportProxyServer,
+		"auth_server", teleportAuthServer,
+		"max_concurrency", maxConcurrency,
+		"dry_run", dryRun,
+	)
+
+	// Create context with cancellation support (Ctrl+C)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Validate flag values before doing any remote work
+	if err := secrets.ResolveFlags(ctx, cmd.Flags(), "join-token"); err != nil {
+		return fatalError(log, "Failed to resolve --join-token", err)
+	}
+	opts := installer.TeleportOptions{
+		ProxyServer:        teleportProxyServer,
+		AuthServer:         teleportAuthServer,
+		Version:            teleportVersion,
+		CAPin:              teleportCAPin,
+		JoinToken:          teleportJoinToken,
+		JoinTokenParameter: teleportTokenParameter,
+		NodenameColumn:     teleportNodenameColumn,
+		LabelColumns:       teleportLabelColumns,
+	}
+	if err := opts.Validate(); err != nil {
+		return fatalError(log, "Invalid Teleport settings", err)
+	}
+
+	return runPackageInstall(ctx, log, packageInstall{
+		command:    "install teleport",
+		title:      "Teleport",
+		version:    teleportVersion,
+		validation: "SSM + Teleport server connectivity",
+		installer:  installer.NewTeleportInstaller(opts),
+		checkInstances: func(log *slog.Logger, instances []*cloud.Instance) {
+			for _, column := range append([]string{teleportNodenameColumn}, teleportLabelColumns...) {
+				if _, ok := instances[0].Metadata[column]; !ok {
+					log.Warn("⚠️  CSV column not found", "column", column)
+				}
+			}
+			if opts.AuthServer != "" && opts.CAPin == "" {
+				log.Warn("⚠️  Joining the auth server without --ca-pin: the cluster CA is trusted on first use")
+			}
+			log.Info("✅ Teleport cluster configured",
+				"server", opts.ProxyServer+opts.AuthServer,
+				"version", teleportVersion,
+				"label_columns", len(teleportLabelColumns))
+		},
+	})
+}
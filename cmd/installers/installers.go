@@ -0,0 +1,27 @@
+package installers
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// InstallersCmd represents the installers command
+// This is the root command for installer discovery
+// Usage: opsmaster installers <operation> [flags]
+var InstallersCmd = &cobra.Command{
+	Use:   "installers",
+	Short: "Mostra os instaladores disponíveis e o que suportam",
+	Long: `Mostra os instaladores registrados (os subcomandos de "opsmaster install"),
+os sistemas e arquiteturas que suportam, as flags obrigatórias e as
+capacidades opcionais, sem precisar ler o código.
+
+Exemplos:
+  # Matriz de suporte completa
+  opsmaster installers list --verbose`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Help()
+	},
+}
+
+func init() {
+	InstallersCmd.AddCommand(listCmd)
+}
@@ -0,0 +1,122 @@
+package installers
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/estudosdevops/opsmaster/internal/installer"
+	"github.com/estudosdevops/opsmaster/internal/presenter"
+)
+
+// installers list command flags
+var (
+	verbose      bool   // Include required flags and capabilities
+	outputFormat string // table or json
+)
+
+// installerRow is the support matrix entry of one installer.
+type installerRow struct {
+	Name          string   `json:"name"`
+	OSFamilies    []string `json:"os_families"`
+	Architectures []string `json:"architectures"` // Empty = any
+	RequiredFlags []string `json:"required_flags"`
+	Capabilities  []string `json:"capabilities"`
+}
+
+var listCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Lista os instaladores registrados",
+	Long: `Lista os instaladores registrados com os sistemas (debian, rhel, windows) e
+arquiteturas suportados. Com --verbose, mostra também as flags obrigatórias do
+subcomando "install" e as capacidades opcionais:
+
+  local-install       conduz a instalação por conta própria (recuperação, várias chamadas)
+  auto-detect         detecta o sistema da instância antes de gerar o script
+  step-based          instala em etapas; falhas apontam a etapa que quebrou
+  verifies-facts      verifica facts/configuração após a instalação
+  concurrency-groups  limite de concorrência por backend (ex: CA do Puppet Server)
+  post-install        notifica sistemas externos após a instalação (ex: ENC/CMDB)
+  diagnostics         logs incluídos no diagnóstico das instâncias com falha
+
+Exemplos:
+  opsmaster installers list --verbose
+
+  # JSON para processar com jq
+  opsmaster installers list -o json | jq '.[] | select(.capabilities | index("local-install")) | .name'`,
+	RunE: runList,
+}
+
+func init() {
+	listCmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "Mostra também as flags obrigatórias e as capacidades de cada instalador")
+	listCmd.Flags().StringVarP(&outputFormat, "output", "o", presenter.OutputTable, "Formato de saída (table|json)")
+}
+
+// runList prints the support matrix built from the installer registry.
+func runList(cmd *cobra.Command, _ []string) error {
+	if err := presenter.ValidateOutputFormat(outputFormat); err != nil {
+		return err
+	}
+
+	descriptors := installer.Registered()
+	rows := make([]installerRow, len(descriptors))
+	for i, d := range descriptors {
+		rows[i] = installerRow{
+			Name:          d.Name,
+			OSFamilies:    d.OSFamilies,
+			Architectures: d.Architectures,
+			RequiredFlags: requiredFlags(cmd.Root(), d.Name),
+			Capabilities:  d.Capabilities(),
+		}
+	}
+
+	if outputFormat == presenter.OutputJSON {
+		return presenter.PrintJSON(rows)
+	}
+
+	header := []string{"NOME", "SO", "ARQUITETURAS"}
+	if verbose {
+		header = append(header, "FLAGS OBRIGATÓRIAS", "CAPACIDADES")
+	}
+	tableRows := make([][]string, 0, len(rows))
+	for _, row := range rows {
+		arch := "*"
+		if len(row.Architectures) > 0 {
+			arch = strings.Join(row.Architectures, ",")
+		}
+		cells := []string{row.Name, strings.Join(row.OSFamilies, ","), arch}
+		if verbose {
+			cells = append(cells, joinOrDash(row.RequiredFlags, " "), joinOrDash(row.Capabilities, ","))
+		}
+		tableRows = append(tableRows, cells)
+	}
+	presenter.PrintTable(header, tableRows)
+	return nil
+}
+
+// requiredFlags returns the flags marked required on "install <name>",
+// so the matrix follows the command definitions.
+func requiredFlags(root *cobra.Command, name string) []string {
+	installCmd, _, err := root.Find([]string{"install", name})
+	if err != nil || installCmd.Name() != name {
+		return nil
+	}
+	var flags []string
+	installCmd.LocalFlags().VisitAll(func(flag *pflag.Flag) {
+		if _, required := flag.Annotations[cobra.BashCompOneRequiredFlag]; required {
+			flags = append(flags, "--"+flag.Name)
+		}
+	})
+	sort.Strings(flags)
+	return flags
+}
+
+// joinOrDash joins values for a table cell, "-" when empty.
+func joinOrDash(values []string, sep string) string {
+	if len(values) == 0 {
+		return "-"
+	}
+	return strings.Join(values, sep)
+}
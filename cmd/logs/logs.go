@@ -0,0 +1,26 @@
+package logs
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// LogsCmd represents the logs command
+// This is the root command for reading logs across the fleet
+// Usage: opsmaster logs <operation> [flags]
+var LogsCmd = &cobra.Command{
+	Use:   "logs",
+	Short: "Lê logs das instâncias da frota",
+	Long: `Lê arquivos de log das instâncias listadas em arquivo CSV (via SSM), útil para
+depurar um rollout que falhou em parte da frota.
+
+Exemplos:
+  # Últimas 50 linhas do log do agente Puppet em cada instância
+  opsmaster logs tail --path /var/log/puppetlabs/puppet/puppet.log --lines 50 --instances-file fleet.csv`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Help()
+	},
+}
+
+func init() {
+	LogsCmd.AddCommand(tailCmd)
+}
@@ -0,0 +1,235 @@
+package logs
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/estudosdevops/opsmaster/cmd/fleet"
+	"github.com/estudosdevops/opsmaster/internal/cloud"
+	"github.com/estudosdevops/opsmaster/internal/executor"
+	"github.com/estudosdevops/opsmaster/internal/i18n"
+	"github.com/estudosdevops/opsmaster/internal/logger"
+	"github.com/estudosdevops/opsmaster/internal/presenter"
+)
+
+// maxTailLines bounds --lines (output is also capped by cloud.MaxTailBytes).
+const maxTailLines = 1000
+
+// logs tail command flags
+var (
+	instancesFile  string        // CSV file with instance list
+	awsProfile     string        // AWS profile to use
+	where          []string      // Column selectors applied to CSV rows
+	logPath        string        // Remote file to read
+	lines          int           // Number of lines from the end of the file
+	noGroup        bool          // Print one block per instance even for identical output
+	prefixLines    bool          // Prefix each line with the instance ID
+	maxConcurrency int           // Max simultaneous reads
+	timeout        time.Duration // Per-instance command timeout
+	outputFormat   string        // table (text blocks) or json
+	includeMaint   bool          // Read instances in maintenance mode
+	maintenanceTag string        // Tag key marking maintenance mode
+)
+
+// tailResult is the tail of the log file on one instance.
+type tailResult struct {
+	InstanceID string   `json:"instance_id"`
+	Name       string   `json:"name,omitempty"` // Display name (--display-column)
+	Account    string   `json:"account"`
+	Region     string   `json:"region"`
+	Lines      []string `json:"lines"`
+	Error      string   `json:"error,omitempty"`
+	SkipReason string   `json:"skip_reason,omitempty"` // Why the file wasn't read (e.g., maintenance mode)
+}
+
+var tailCmd = &cobra.Command{
+	Use:   "tail",
+	Short: "Mostra as últimas linhas de um arquivo em cada instância",
+	Long: `Lê as últimas --lines linhas de um arquivo em todas as instâncias do CSV (via SSM)
+e as imprime agrupadas.
+
+Instâncias com saída idêntica são agrupadas em um único bloco (o cabeçalho lista as
+instâncias), o que destaca rapidamente quais máquinas falharam da mesma forma. Use
+--no-group para um bloco por instância ou --prefix para linhas prefixadas com o ID
+da instância (bom para grep). A saída por instância é limitada a 20 KB (limite do SSM).
+
+Exemplos:
+  opsmaster logs tail --path /var/log/puppetlabs/puppet/puppet.log --lines 50 --instances-file fleet.csv
+
+  # Só as instâncias do lote que falhou, linhas prefixadas
+  opsmaster logs tail --path /var/log/syslog --lines 200 --instances-file fleet.csv \
+    --where batch=3 --prefix | grep -i error`,
+	RunE: runTail,
+}
+
+func init() {
+	tailCmd.Flags().StringVar(&logPath, "path", "", "Caminho absoluto do arquivo nas instâncias (obrigatório)")
+	tailCmd.Flags().IntVar(&lines, "lines", 50, "Quantidade de linhas do final do arquivo")
+	tailCmd.Flags().StringVar(&instancesFile, "instances-file", "", "Arquivo CSV com lista de instâncias: caminho local, https:// ou s3:// (aceita .gz) (obrigatório)")
+	tailCmd.Flags().StringArrayVar(&where, "where", nil, "Seleciona instâncias por coluna do CSV (ex: environment=blue); pode ser repetida")
+	tailCmd.Flags().StringVar(&awsProfile, "aws-profile", "", "Perfil AWS a usar (padrão: perfil default)")
+	tailCmd.Flags().BoolVar(&noGroup, "no-group", false, "Não agrupar instâncias com saída idêntica")
+	tailCmd.Flags().BoolVar(&prefixLines, "prefix", false, "Prefixar cada linha com o ID da instância em vez de imprimir blocos")
+	tailCmd.Flags().IntVar(&maxConcurrency, "max-concurrency", 20, "Máximo de leituras em paralelo")
+	tailCmd.Flags().DurationVar(&timeout, "timeout", time.Minute, "Tempo máximo da leitura em cada instância")
+	tailCmd.Flags().StringVarP(&outputFormat, "output", "o", presenter.OutputTable, "Formato de saída (table|json)")
+	tailCmd.Flags().BoolVar(&includeMaint, "include-maintenance", false, "Processar também instâncias em modo manutenção")
+	tailCmd.Flags().StringVar(&maintenanceTag, "maintenance-tag", cloud.DefaultMaintenanceTagKey, "Tag que marca instâncias em modo manutenção (valor true)")
+	tailCmd.MarkFlagRequired("path")
+	tailCmd.MarkFlagRequired("instances-file")
+}
+
+// runTail reads the log file on every selected instance and prints it.
+func runTail(_ *cobra.Command, _ []string) error {
+	log := logger.Get()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	if err := presenter.ValidateOutputFormat(outputFormat); err != nil {
+		return err
+	}
+	if !path.IsAbs(logPath) {
+		return i18n.Errorf("invalid --path %q: must be an absolute path", logPath)
+	}
+	if lines < 1 || lines > maxTailLines {
+		return i18n.Errorf("invalid --lines %d: must be between 1 and %d", lines, maxTailLines)
+	}
+
+	instances, skipped, cloudProvider, err := fleet.Load(ctx, log, fleet.Selection{
+		InstancesFile:      instancesFile,
+		AWSProfile:         awsProfile,
+		Where:              where,
+		IncludeMaintenance: includeMaint,
+		MaintenanceTag:     maintenanceTag,
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Info("📜 Reading logs", "path", logPath, "lines", lines, "instances", len(instances), "skipped", len(skipped))
+
+	results := make([]*tailResult, len(instances), len(instances)+len(skipped))
+	resultFor := make(map[*cloud.Instance]*tailResult, len(instances))
+	for i, instance := range instances {
+		results[i] = &tailResult{InstanceID: instance.ID, Name: instance.DisplayName(), Account: instance.Account, Region: instance.Region}
+		resultFor[instance] = results[i]
+	}
+
+	script := []string{cloud.TailFileScript(logPath, lines)}
+	errs := executor.ForEachInstance(ctx, instances, maxConcurrency, func(ctx context.Context, instance *cloud.Instance) error {
+		tail, err := tailFile(ctx, cloudProvider, instance, script)
+		resultFor[instance].Lines = tail
+		return err
+	})
+
+	failed := 0
+	for i, err := range errs {
+		if err != nil {
+			results[i].Error = err.Error()
+			failed++
+		}
+	}
+	for _, result := range skipped {
+		instance := result.Instance
+		results = append(results, &tailResult{InstanceID: instance.ID, Name: instance.DisplayName(), Account: instance.Account,
+			Region: instance.Region, SkipReason: result.SkipReason})
+	}
+
+	switch {
+	case outputFormat == presenter.OutputJSON:
+		if err := presenter.PrintJSON(results); err != nil {
+			return err
+		}
+	case prefixLines:
+		printPrefixed(results)
+	default:
+		printBlocks(results, !noGroup)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("failed to read %s on %d instances", logPath, failed)
+	}
+	return nil
+}
+
+// tailFile runs the tail script on an instance and returns the lines.
+func tailFile(ctx context.Context, cloudProvider cloud.CloudProvider, instance *cloud.Instance, script []string) ([]string, error) {
+	result, err := cloudProvider.ExecuteCommandWithOptions(cloud.ReadOnlyCommands(ctx), instance, script, timeout, cloud.ExecOptions{Comment: "logs tail " + logPath})
+	if err != nil {
+		return nil, err
+	}
+	if result.ExitCode != 0 {
+		return nil, fmt.Errorf("tail failed (exit code %d): %s", result.ExitCode, strings.TrimSpace(result.Stderr))
+	}
+	return cloud.ParseTailOutput(logPath, result.Stdout)
+}
+
+// tailGroup is a block of output shared by one or more instances.
+type tailGroup struct {
+	instances  []string
+	lines      []string
+	err        string
+	skipReason string
+}
+
+// groupResults groups instances with identical output (or error), keeping
+// the order of first appearance. Without grouping each instance is a group.
+func groupResults(results []*tailResult, group bool) []*tailGroup {
+	var groups []*tailGroup
+	byKey := make(map[string]*tailGroup)
+	for _, result := range results {
+		key := result.Error + "\x00" + result.SkipReason + "\x00" + strings.Join(result.Lines, "\n")
+		if existing, ok := byKey[key]; ok && group {
+			existing.instances = append(existing.instances, cloud.FormatLabel(result.InstanceID, result.Name))
+			continue
+		}
+		g := &tailGroup{instances: []string{cloud.FormatLabel(result.InstanceID, result.Name)}, lines: result.Lines, err: result.Error, skipReason: result.SkipReason}
+		byKey[key] = g
+		groups = append(groups, g)
+	}
+	return groups
+}
+
+// printBlocks prints one block per group, like tail with several files.
+func printBlocks(results []*tailResult, group bool) {
+	for _, g := range groupResults(results, group) {
+		header := strings.Join(g.instances, ", ")
+		if len(g.instances) > 1 {
+			header = fmt.Sprintf("%s (%d instâncias)", header, len(g.instances))
+		}
+		fmt.Printf("\n==> %s <==\n", header)
+		switch {
+		case g.err != "":
+			presenter.Printf("❌ %s\n", g.err)
+		case g.skipReason != "":
+			presenter.Printf("⏭️  %s\n", g.skipReason)
+		case len(g.lines) == 0:
+			fmt.Println("(arquivo vazio)")
+		default:
+			fmt.Println(strings.Join(g.lines, "\n"))
+		}
+	}
+}
+
+// printPrefixed prints every line prefixed with its instance ID (and name).
+func printPrefixed(results []*tailResult) {
+	for _, result := range results {
+		label := cloud.FormatLabel(result.InstanceID, result.Name)
+		if result.Error != "" {
+			presenter.Printf("%s | ❌ %s\n", label, result.Error)
+			continue
+		}
+		if result.SkipReason != "" {
+			presenter.Printf("%s | ⏭️  %s\n", label, result.SkipReason)
+			continue
+		}
+		for _, line := range result.Lines {
+			fmt.Printf("%s | %s\n", label, line)
+		}
+	}
+}
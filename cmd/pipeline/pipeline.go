@@ -0,0 +1,29 @@
+package pipeline
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// PipelineCmd represents the pipeline command
+// This is the root command for the named pipelines of the config file
+// Usage: opsmaster pipeline <operation> [flags]
+var PipelineCmd = &cobra.Command{
+	Use:   "pipeline",
+	Short: "Executa pipelines nomeados do arquivo de configuração",
+	Long: `Executa pipelines definidos na seção pipelines do arquivo de configuração:
+estágios em ordem (ex: preflight → install puppet → install node-exporter →
+verify → notify), cada um um comando do opsmaster com as suas flags.
+
+Exemplos:
+  opsmaster pipeline run nightly-baseline --instances-file fleet.csv
+
+  # Mostrar os comandos de cada estágio sem executar
+  opsmaster pipeline run nightly-baseline --instances-file fleet.csv --plan`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Help()
+	},
+}
+
+func init() {
+	PipelineCmd.AddCommand(runCmd)
+}
@@ -0,0 +1,165 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+
+	awsprovider "github.com/estudosdevops/opsmaster/internal/cloud/aws"
+	"github.com/estudosdevops/opsmaster/internal/i18n"
+	"github.com/estudosdevops/opsmaster/internal/logger"
+	"github.com/estudosdevops/opsmaster/internal/pipeline"
+	"github.com/estudosdevops/opsmaster/internal/presenter"
+	"github.com/estudosdevops/opsmaster/internal/sink"
+)
+
+// pipeline run command flags
+var (
+	instancesFile string // CSV file passed to every command stage
+	planOnly      bool   // Print the stage commands without running them
+)
+
+var runCmd = &cobra.Command{
+	Use:   "run <pipeline>",
+	Short: "Executa os estágios de um pipeline nas instâncias do CSV",
+	Long: `Executa em ordem os estágios do pipeline informado, definido na seção pipelines
+do arquivo de configuração. Cada estágio é um comando do opsmaster (install
+puppet, install systemd-unit, run script, facts get, coverage, ...) executado
+com o arquivo --instances-file, o perfil (profile) e as flags do estágio; o
+estágio notify publica o resumo do pipeline em um tópico SNS ou barramento
+EventBridge (events-arn).
+
+Um estágio com falha interrompe o pipeline: os seguintes são ignorados, exceto
+os marcados com always: true (ex: notify). Com continue_on_error: true, a
+falha do estágio não interrompe os seguintes.
+
+  pipelines:
+    nightly-baseline:
+      stages:
+        - name: preflight
+          command: install puppet
+          profile: prod-puppet
+          flags: {dry-run: true}
+        - name: puppet
+          command: install puppet
+          profile: prod-puppet
+        - name: verify
+          command: coverage
+          flags: {expect: [puppet=true]}
+        - name: notify
+          command: notify
+          always: true
+          flags: {events-arn: "arn:aws:sns:us-east-1:111111111111:ops"}
+
+Exemplos:
+  opsmaster pipeline run nightly-baseline --instances-file fleet.csv
+
+  # Mostrar os comandos de cada estágio sem executar
+  opsmaster pipeline run nightly-baseline --instances-file fleet.csv --plan`,
+	Args: cobra.ExactArgs(1),
+	RunE: runPipeline,
+}
+
+func init() {
+	runCmd.Flags().StringVar(&instancesFile, "instances-file", "", "Arquivo CSV com lista de instâncias, passado a cada estágio: caminho local, https:// ou s3:// (aceita .gz) (obrigatório)")
+	runCmd.Flags().BoolVar(&planOnly, "plan", false, "Mostra o comando de cada estágio sem executar")
+	runCmd.MarkFlagRequired("instances-file")
+}
+
+// runPipeline runs the stages of the pipeline and prints a summary.
+// Command stages run as child opsmaster processes, so every stage gets the
+// same flag handling (profiles, environment, validation) as when run alone.
+func runPipeline(cmd *cobra.Command, args []string) error {
+	log := logger.Get()
+	p, err := pipeline.Lookup(viper.GetStringMap("pipelines"), args[0])
+	if err != nil {
+		return err
+	}
+	global := globalArgs(cmd.Root().PersistentFlags())
+
+	if planOnly {
+		presenter.Printf("📋 Pipeline %s: %d stages\n", p.Name, len(p.Stages))
+		for i, stage := range p.Stages {
+			line := "notify " + fmt.Sprint(stage.Flags["events-arn"])
+			if stage.Command != pipeline.NotifyCommand {
+				line = "opsmaster " + strings.Join(append(global, stage.Args(instancesFile)...), " ")
+			}
+			presenter.Printf("%d. %s: %s\n", i+1, stage.Name, line)
+		}
+		return nil
+	}
+
+	self, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	log.Info("Starting pipeline", "pipeline", p.Name, "stages", len(p.Stages), "instances_file", instancesFile)
+	result := pipeline.Run(ctx, p, func(ctx context.Context, stage pipeline.Stage, done []pipeline.StageResult) error {
+		presenter.Printf("\n▶️  Stage %d/%d: %s (%s)\n", len(done)+1, len(p.Stages), stage.Name, stage.Command)
+		if stage.Command == pipeline.NotifyCommand {
+			return notify(ctx, p.Name, stage, done)
+		}
+		child := exec.CommandContext(ctx, self, append(global, stage.Args(instancesFile)...)...)
+		child.Stdin, child.Stdout, child.Stderr = os.Stdin, os.Stdout, os.Stderr
+		if err := child.Run(); err != nil {
+			log.Error("Pipeline stage failed", "pipeline", p.Name, "stage", stage.Name, "error", err)
+			return err
+		}
+		return nil
+	})
+
+	rows := make([][]string, 0, len(result.Stages))
+	for _, stage := range result.Stages {
+		duration := ""
+		if stage.Status != pipeline.StatusSkipped {
+			duration = stage.Duration.Round(time.Second).String()
+		}
+		rows = append(rows, []string{stage.Name, stage.Command, stage.Status, duration, stage.Error})
+	}
+	presenter.Printf("\n📋 Pipeline %s:\n", p.Name)
+	presenter.PrintTable([]string{"ESTÁGIO", "COMANDO", "STATUS", "DURAÇÃO", "ERRO"}, rows)
+
+	if result.Failed() {
+		return i18n.Errorf("pipeline %s failed", p.Name)
+	}
+	return nil
+}
+
+// notify publishes the summary of the stages run so far to the events-arn
+// of a notify stage.
+func notify(ctx context.Context, name string, stage pipeline.Stage, done []pipeline.StageResult) error {
+	detail, err := pipeline.Event(name, logger.RunID(), done)
+	if err != nil {
+		return err
+	}
+	awsProfile, _ := stage.Flags["aws-profile"].(string)
+	publisher, err := awsprovider.NewEventPublisher(ctx, awsProfile, stage.Flags["events-arn"].(string), sink.EventSource)
+	if err != nil {
+		return err
+	}
+	return publisher.Publish(ctx, pipeline.EventDetailType, detail, map[string]string{"pipeline": name})
+}
+
+// globalArgs returns the global flags set for this run (e.g., --config,
+// --provider), passed on to every stage. --profile is per stage.
+func globalArgs(flags *pflag.FlagSet) []string {
+	var args []string
+	flags.VisitAll(func(flag *pflag.Flag) {
+		if flag.Changed && flag.Name != "profile" {
+			args = append(args, "--"+flag.Name+"="+flag.Value.String())
+		}
+	})
+	return args
+}
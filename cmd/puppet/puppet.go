@@ -0,0 +1,29 @@
+package puppet
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// PuppetCmd represents the puppet command
+// This is the root command for Puppet fleet operations
+// Usage: opsmaster puppet <operation> [flags]
+var PuppetCmd = &cobra.Command{
+	Use:   "puppet",
+	Short: "Operações na frota Puppet",
+	Long: `Operações sobre a frota gerenciada pelo Puppet (PuppetDB, relatórios, certificados).
+
+Exemplos:
+  # Comparar inventário (CSV) com os nós ativos no PuppetDB
+  opsmaster puppet reconcile --instances-file fleet.csv --puppetdb-url https://puppetdb.example.com:8081
+
+  # Regenerar certificados dos agentes após rotação da CA
+  opsmaster puppet regen-cert --instances-file fleet.csv --ca-url https://puppet.example.com:8140`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Help()
+	},
+}
+
+func init() {
+	PuppetCmd.AddCommand(reconcileCmd)
+	PuppetCmd.AddCommand(regenCertCmd)
+}
@@ -0,0 +1,163 @@
+package puppet
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	awsprovider "github.com/estudosdevops/opsmaster/internal/cloud/aws"
+	"github.com/estudosdevops/opsmaster/internal/csv"
+	"github.com/estudosdevops/opsmaster/internal/httpclient"
+	"github.com/estudosdevops/opsmaster/internal/i18n"
+	"github.com/estudosdevops/opsmaster/internal/logger"
+	"github.com/estudosdevops/opsmaster/internal/presenter"
+	"github.com/estudosdevops/opsmaster/internal/puppetdb"
+	"github.com/estudosdevops/opsmaster/internal/secrets"
+)
+
+// Reconcile command flags
+var (
+	instancesFile  string        // CSV file with instance list (inventory)
+	puppetDBURL    string        // PuppetDB base URL
+	puppetDBToken  string        // RBAC token (Puppet Enterprise)
+	puppetDBCACert string        // CA certificate of PuppetDB (Puppet CA)
+	puppetDBCert   string        // Client certificate for mutual TLS
+	puppetDBKey    string        // Client key for mutual TLS
+	staleAfter     time.Duration // Nodes without reports for longer are stale
+	certnameColumn string        // CSV column with the certname
+	where          []string      // Column selectors applied to CSV rows
+	outputFormat   string        // table or json
+)
+
+var reconcileCmd = &cobra.Command{
+	Use:   "reconcile",
+	Short: "Compara o inventário (CSV) com os nós ativos no PuppetDB",
+	Long: `Compara as instâncias do arquivo CSV com os nós ativos no PuppetDB e aponta:
+
+  - not-reporting:    instâncias do inventário sem nó ativo (ou sem nenhum relatório) no PuppetDB
+  - stale:            instâncias cujo último relatório é mais antigo que --stale-after
+  - not-in-inventory: nós ativos no PuppetDB que não estão no inventário
+
+Instâncias são associadas aos nós pela coluna certname do CSV, quando existir, ou pelo fact
+ec2_metadata.instance-id.
+
+Exemplos:
+  opsmaster puppet reconcile --instances-file fleet.csv --puppetdb-url https://puppetdb.example.com:8081 \
+    --puppetdb-cacert ca.pem --puppetdb-cert client.pem --puppetdb-key client-key.pem
+
+  opsmaster puppet reconcile --instances-file fleet.csv --puppetdb-url http://localhost:8080 \
+    --stale-after 6h --output json`,
+	RunE: runReconcile,
+}
+
+func init() {
+	reconcileCmd.Flags().StringVar(&instancesFile, "instances-file", "", "Arquivo CSV com lista de instâncias: caminho local, https:// ou s3:// (aceita .gz) (obrigatório)")
+	reconcileCmd.Flags().StringVar(&puppetDBURL, "puppetdb-url", "", "URL do PuppetDB (obrigatório, ex: https://puppetdb.example.com:8081)")
+	reconcileCmd.Flags().StringVar(&puppetDBToken, "puppetdb-token", "", "Token RBAC do Puppet Enterprise; aceita referência vault:caminho#campo")
+	reconcileCmd.Flags().StringVar(&puppetDBCACert, "puppetdb-cacert", "", "Certificado da CA do Puppet para validar o PuppetDB")
+	reconcileCmd.Flags().StringVar(&puppetDBCert, "puppetdb-cert", "", "Certificado cliente (mTLS) para o PuppetDB")
+	reconcileCmd.Flags().StringVar(&puppetDBKey, "puppetdb-key", "", "Chave privada do certificado cliente (mTLS)")
+	reconcileCmd.Flags().DurationVar(&staleAfter, "stale-after", 24*time.Hour, "Nós sem relatório há mais tempo que isso são considerados stale")
+	reconcileCmd.Flags().StringVar(&certnameColumn, "certname-column", puppetdb.DefaultCertnameColumn, "Coluna do CSV com o certname (opcional)")
+	reconcileCmd.Flags().StringArrayVar(&where, "where", nil, "Seleciona instâncias por coluna do CSV (ex: environment=blue); pode ser repetida")
+	reconcileCmd.Flags().StringVarP(&outputFormat, "output", "o", presenter.OutputTable, "Formato de saída (table|json)")
+	reconcileCmd.MarkFlagRequired("instances-file")
+	reconcileCmd.MarkFlagRequired("puppetdb-url")
+}
+
+// runReconcile loads the inventory, queries PuppetDB and prints the comparison.
+func runReconcile(cmd *cobra.Command, _ []string) error {
+	log := logger.Get()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	if err := presenter.ValidateOutputFormat(outputFormat); err != nil {
+		return err
+	}
+	if err := secrets.ResolveFlags(ctx, cmd.Flags(), "puppetdb-token"); err != nil {
+		return err
+	}
+
+	parser := csv.NewParser(csv.CSVConfig{
+		HasHeader:      true,
+		RequiredFields: []string{"instance_id", "account", "region"},
+		CloudDefault:   "aws",
+		Delimiter:      ',',
+		ColumnAliases:  viper.GetStringMapStringSlice("csv.column_aliases"),
+	})
+	instances, err := parser.ParseSource(ctx, instancesFile, awsprovider.S3Opener(""))
+	if err != nil {
+		return fmt.Errorf("failed to parse CSV file: %w", err)
+	}
+	instances, err = csv.SelectInstances(instances, parser.Columns(), where)
+	if err != nil {
+		return i18n.Errorf("invalid --where selector: %w", err)
+	}
+
+	httpClient, err := httpclient.New(httpclient.Config{
+		CABundle:   puppetDBCACert,
+		ClientCert: puppetDBCert,
+		ClientKey:  puppetDBKey,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to configure PuppetDB client: %w", err)
+	}
+	client, err := puppetdb.NewClient(puppetdb.Config{URL: puppetDBURL, Token: puppetDBToken, Client: httpClient})
+	if err != nil {
+		return err
+	}
+
+	log.Info("🔎 Querying PuppetDB", "url", puppetDBURL, "instances", len(instances))
+
+	nodes, err := client.Nodes(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list PuppetDB nodes: %w", err)
+	}
+	instanceIDs, err := client.FactValues(ctx, puppetdb.InstanceIDFactPath)
+	if err != nil {
+		return fmt.Errorf("failed to query instance IDs from PuppetDB: %w", err)
+	}
+
+	entries := puppetdb.Reconcile(instances, nodes, instanceIDs, puppetdb.ReconcileOptions{
+		StaleAfter:     staleAfter,
+		CertnameColumn: certnameColumn,
+	})
+
+	if outputFormat == presenter.OutputJSON {
+		return presenter.PrintJSON(entries)
+	}
+	printReconcileTable(entries)
+	return nil
+}
+
+// printReconcileTable prints reconciliation entries and a status summary.
+func printReconcileTable(entries []puppetdb.Entry) {
+	header := []string{"INSTANCE ID", "ACCOUNT", "REGION", "CERTNAME", "STATUS", "LAST REPORT", "DETAIL"}
+	rows := make([][]string, 0, len(entries))
+	for _, entry := range entries {
+		lastReport := "-"
+		if entry.LastReport != nil {
+			lastReport = entry.LastReport.Local().Format(time.DateTime)
+		}
+		rows = append(rows, []string{
+			presenter.OrDash(entry.InstanceID),
+			presenter.OrDash(entry.Account),
+			presenter.OrDash(entry.Region),
+			presenter.OrDash(entry.Certname),
+			entry.Status,
+			lastReport,
+			entry.Detail,
+		})
+	}
+	presenter.PrintTable(header, rows)
+
+	counts := puppetdb.CountByStatus(entries)
+	presenter.Printf("\n📊 ok: %d | not-reporting: %d | stale: %d | not-in-inventory: %d\n",
+		counts[puppetdb.StatusOK],
+		counts[puppetdb.StatusNotReporting],
+		counts[puppetdb.StatusStale],
+		counts[puppetdb.StatusNotInInventory])
+}
@@ -0,0 +1,233 @@
+package puppet
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/estudosdevops/opsmaster/internal/cloud"
+	awsprovider "github.com/estudosdevops/opsmaster/internal/cloud/aws"
+	"github.com/estudosdevops/opsmaster/internal/cloud/provider"
+	"github.com/estudosdevops/opsmaster/internal/csv"
+	"github.com/estudosdevops/opsmaster/internal/executor"
+	"github.com/estudosdevops/opsmaster/internal/httpclient"
+	"github.com/estudosdevops/opsmaster/internal/i18n"
+	"github.com/estudosdevops/opsmaster/internal/installer"
+	"github.com/estudosdevops/opsmaster/internal/logger"
+	"github.com/estudosdevops/opsmaster/internal/presenter"
+	"github.com/estudosdevops/opsmaster/internal/puppetca"
+	"github.com/estudosdevops/opsmaster/internal/secrets"
+)
+
+// regen-cert command flags (--instances-file and --where are shared with reconcile)
+var (
+	regenCertname       string        // Certname strategy: preserve or generate
+	regenCertnameColumn string        // CSV column with the new certname
+	regenCAURL          string        // Puppet CA URL for cleanup/signing
+	regenCAToken        string        // RBAC token (Puppet Enterprise)
+	regenCACert         string        // CA certificate of the Puppet Server
+	regenClientCert     string        // Client certificate allowed on certificate_status
+	regenClientKey      string        // Client key for mutual TLS
+	regenSign           bool          // Sign new requests through the CA API
+	regenWaitForCert    time.Duration // Agent --waitforcert
+	regenAWSProfile     string        // AWS profile to use
+	regenConcurrency    int           // Max simultaneous instances
+	regenDryRun         bool          // Simulate without changes
+	regenIncludeMaint   bool          // Process instances in maintenance mode
+	regenMaintTag       string        // Tag key marking maintenance mode
+)
+
+var regenCertCmd = &cobra.Command{
+	Use:   "regen-cert",
+	Short: "Regenera o certificado do agente Puppet nas instâncias",
+	Long: `Regenera o certificado do agente Puppet das instâncias do CSV, para recuperar nós
+após uma rotação da CA do Puppet.
+
+Para cada instância:
+  1. Lê o certname atual e o estado do serviço puppet
+  2. Para o agente, salva /etc/puppetlabs/puppet/ssl em /var/lib/opsmaster/puppet-ssl-backups
+     e remove o diretório
+  3. Define o novo certname (--certname generate ou coluna --certname-column) ou preserva o atual
+  4. Com --ca-url, revoga e remove o certificado antigo na CA (equivalente a "puppetserver ca clean")
+  5. Executa o agente para solicitar o novo certificado (com --sign, a CA assina a requisição)
+  6. Reinicia o serviço puppet se ele estava ativo
+
+Sem --ca-url a limpeza na CA deve ser feita antes, fora do opsmaster; caso contrário a CA
+rejeita a nova requisição de um certname preservado. A API certificate_status exige um
+certificado cliente liberado no auth.conf da CA (--ca-client-cert/--ca-client-key).
+
+Se uma etapa falhar após a remoção do diretório ssl, o caminho do backup aparece no erro.
+
+Exemplos:
+  # Preservar certnames, limpar a CA e deixar o autosign emitir os certificados
+  opsmaster puppet regen-cert --instances-file fleet.csv --ca-url https://puppet.example.com:8140 \
+    --ca-cacert ca.pem --ca-client-cert admin.pem --ca-client-key admin-key.pem
+
+  # Novos certnames, assinados pelo opsmaster
+  opsmaster puppet regen-cert --instances-file fleet.csv --certname generate --sign \
+    --ca-url https://puppet.example.com:8140 --ca-cacert ca.pem --ca-client-cert admin.pem --ca-client-key admin-key.pem`,
+	RunE: runRegenCert,
+}
+
+func init() {
+	regenCertCmd.Flags().StringVar(&instancesFile, "instances-file", "", "Arquivo CSV com lista de instâncias: caminho local, https:// ou s3:// (aceita .gz) (obrigatório)")
+	regenCertCmd.Flags().StringArrayVar(&where, "where", nil, "Seleciona instâncias por coluna do CSV (ex: environment=blue); pode ser repetida")
+	regenCertCmd.Flags().StringVar(&regenCertname, "certname", installer.CertnamePreserve, "Certname após a regeneração: preserve (atual) ou generate (novo <uuid>.puppet)")
+	regenCertCmd.Flags().StringVar(&regenCertnameColumn, "certname-column", "", "Coluna do CSV com o novo certname (tem prioridade sobre --certname quando preenchida)")
+	regenCertCmd.Flags().StringVar(&regenCAURL, "ca-url", "", "URL da CA do Puppet para limpar/assinar certificados (ex: https://puppet.example.com:8140)")
+	regenCertCmd.Flags().StringVar(&regenCAToken, "ca-token", "", "Token RBAC do Puppet Enterprise; aceita referência vault:caminho#campo")
+	regenCertCmd.Flags().StringVar(&regenCACert, "ca-cacert", "", "Certificado da CA do Puppet para validar o servidor")
+	regenCertCmd.Flags().StringVar(&regenClientCert, "ca-client-cert", "", "Certificado cliente (mTLS) autorizado na API certificate_status")
+	regenCertCmd.Flags().StringVar(&regenClientKey, "ca-client-key", "", "Chave privada do certificado cliente (mTLS)")
+	regenCertCmd.Flags().BoolVar(&regenSign, "sign", false, "Assinar as novas requisições pela API da CA (CAs sem autosign; requer --ca-url)")
+	regenCertCmd.Flags().DurationVar(&regenWaitForCert, "waitforcert", time.Minute, "Tempo que o agente aguarda o certificado assinado")
+	regenCertCmd.Flags().StringVar(&regenAWSProfile, "aws-profile", "", "Perfil AWS a usar (padrão: perfil default)")
+	regenCertCmd.Flags().IntVar(&regenConcurrency, "max-concurrency", 10, "Máximo de instâncias processadas em paralelo")
+	regenCertCmd.Flags().BoolVar(&regenDryRun, "dry-run", false, "Simular sem executar")
+	regenCertCmd.Flags().BoolVar(&regenIncludeMaint, "include-maintenance", false, "Processar também instâncias em modo manutenção")
+	regenCertCmd.Flags().StringVar(&regenMaintTag, "maintenance-tag", cloud.DefaultMaintenanceTagKey, "Tag que marca instâncias em modo manutenção (valor true)")
+	regenCertCmd.MarkFlagRequired("instances-file")
+}
+
+// runRegenCert regenerates agent certificates on the selected instances.
+func runRegenCert(cmd *cobra.Command, _ []string) error {
+	log := logger.Get()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	if err := secrets.ResolveFlags(ctx, cmd.Flags(), "ca-token"); err != nil {
+		return err
+	}
+	if provider.ReadOnly() && !regenDryRun {
+		return i18n.Errorf("read-only mode: %s changes the instances, only --dry-run is allowed", "puppet regen-cert")
+	}
+
+	opts := installer.PuppetCertOptions{
+		Certname:       regenCertname,
+		CertnameColumn: regenCertnameColumn,
+		Sign:           regenSign,
+		WaitForCert:    regenWaitForCert,
+	}
+	if regenCAURL != "" {
+		ca, err := newCAClient()
+		if err != nil {
+			return err
+		}
+		opts.CA = ca
+	}
+	if err := opts.Validate(); err != nil {
+		return err
+	}
+
+	parser := csv.NewParser(csv.CSVConfig{
+		HasHeader:      true,
+		RequiredFields: []string{"instance_id", "account", "region"},
+		CloudDefault:   "aws",
+		Delimiter:      ',',
+		ColumnAliases:  viper.GetStringMapStringSlice("csv.column_aliases"),
+	})
+	instances, err := parser.ParseSource(ctx, instancesFile, awsprovider.S3Opener(regenAWSProfile))
+	if err != nil {
+		return fmt.Errorf("failed to parse CSV file: %w", err)
+	}
+	instances, err = csv.SelectInstances(instances, parser.Columns(), where)
+	if err != nil {
+		return i18n.Errorf("invalid --where selector: %w", err)
+	}
+	if len(instances) == 0 {
+		return i18n.Errorf("no instances selected from CSV file")
+	}
+
+	cloudType, err := provider.DetectCloudFromInstances(instances)
+	if err != nil {
+		return fmt.Errorf("failed to detect cloud provider: %w", err)
+	}
+	providerOptions := []provider.Option{provider.WithCommandLabel(cloud.CommandLabel{
+		Prefix:   cloud.DefaultCommandPrefix,
+		RunID:    logger.RunID(),
+		Operator: cloud.CurrentOperator(),
+	})}
+	if regenAWSProfile != "" {
+		providerOptions = append(providerOptions, provider.WithProfile(regenAWSProfile))
+	}
+	cloudProvider, err := provider.NewProvider(cloudType, providerOptions...)
+	if err != nil {
+		return fmt.Errorf("failed to create cloud provider: %w", err)
+	}
+
+	if opts.CA == nil {
+		log.Warn("⚠️  No --ca-url: clean the old certificates on the Puppet CA before the agents run")
+	}
+	log.Info("🔐 Regenerating Puppet agent certificates",
+		"run_id", logger.RunID(),
+		"instances", len(instances),
+		"certname", opts.Certname,
+		"certname_column", opts.CertnameColumn,
+		"ca_cleanup", opts.CA != nil,
+		"sign", opts.Sign,
+		"dry_run", regenDryRun)
+
+	exec := executor.NewParallelExecutor(executor.ExecutorConfig{
+		Provider:           cloudProvider,
+		Installer:          installer.NewPuppetCertRegenerator(opts),
+		MaxConcurrency:     regenConcurrency,
+		DryRun:             regenDryRun,
+		MaintenanceTag:     regenMaintTag,
+		IncludeMaintenance: regenIncludeMaint,
+		RunID:              logger.RunID(),
+	})
+	result, err := exec.Execute(ctx, instances)
+	if err != nil {
+		return fmt.Errorf("execution failed: %w", err)
+	}
+
+	printRegenResults(result)
+	if result.Failed > 0 {
+		return fmt.Errorf("certificate regeneration failed for %d instances", result.Failed)
+	}
+	return nil
+}
+
+// newCAClient creates the Puppet CA client from the --ca-* flags.
+func newCAClient() (*puppetca.Client, error) {
+	httpClient, err := httpclient.New(httpclient.Config{
+		CABundle:   regenCACert,
+		ClientCert: regenClientCert,
+		ClientKey:  regenClientKey,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure Puppet CA client: %w", err)
+	}
+	return puppetca.NewClient(puppetca.Config{URL: regenCAURL, Token: regenCAToken, Client: httpClient})
+}
+
+// printRegenResults prints per-instance certnames, backups and errors.
+func printRegenResults(result *executor.AggregatedResult) {
+	header := []string{"INSTANCE ID", "ACCOUNT", "REGION", "CERTNAME ANTERIOR", "CERTNAME", "STATUS", "DETALHE"}
+	rows := make([][]string, 0, len(result.Results))
+	for _, r := range result.Results {
+		status, detail := "✅", r.Metadata.Get(installer.MetadataKeySSLBackup)
+		switch {
+		case r.Status == executor.StatusSkipped:
+			status, detail = "⏭️", r.SkipReason
+		case r.Failed():
+			status, detail = "❌", fmt.Sprint(r.GetError())
+		}
+		rows = append(rows, []string{
+			r.Instance.Label(),
+			r.Instance.Account,
+			r.Instance.Region,
+			presenter.OrDash(r.Metadata.Get(installer.MetadataKeyPreviousCertname)),
+			presenter.OrDash(r.Metadata.Get(installer.MetadataKeyCertname)),
+			status,
+			presenter.OrDash(detail),
+		})
+	}
+
+	fmt.Println()
+	presenter.PrintTable(header, rows)
+	presenter.Printf("\n📊 regenerated: %d | failed: %d | skipped: %d\n", result.Success, result.Failed, result.Skipped)
+}
@@ -0,0 +1,190 @@
+package reboot
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/estudosdevops/opsmaster/internal/cloud"
+	awsprovider "github.com/estudosdevops/opsmaster/internal/cloud/aws"
+	"github.com/estudosdevops/opsmaster/internal/cloud/provider"
+	"github.com/estudosdevops/opsmaster/internal/csv"
+	"github.com/estudosdevops/opsmaster/internal/executor"
+	"github.com/estudosdevops/opsmaster/internal/i18n"
+	"github.com/estudosdevops/opsmaster/internal/logger"
+	"github.com/estudosdevops/opsmaster/internal/presenter"
+	"github.com/estudosdevops/opsmaster/internal/quarantine"
+)
+
+// reboot command flags
+var (
+	instancesFile     string        // CSV file with instance list
+	awsProfile        string        // AWS profile to use
+	where             []string      // Column selectors applied to CSV rows
+	batchSize         int           // Instances rebooted at once
+	wait              bool          // Wait for instances to come back online
+	waitTimeout       time.Duration // Max wait per batch
+	postCheck         string        // Command that must succeed after the reboot
+	continueOnFailure bool          // Keep going after an unhealthy batch
+	includeMaint      bool          // Process instances in maintenance mode
+	maintTag          string        // Tag key marking maintenance mode
+)
+
+// RebootCmd represents the reboot command
+// Usage: opsmaster reboot --instances-file fleet.csv [flags]
+var RebootCmd = &cobra.Command{
+	Use:   "reboot",
+	Short: "Reinicia instâncias em lotes e verifica a saúde após o boot",
+	Long: `Reinicia as instâncias listadas no arquivo CSV em lotes sequenciais de --batch-size.
+
+Com --wait, cada lote só termina quando todas as instâncias voltam a responder via SSM
+(o boot ID em /proc/sys/kernel/random/boot_id muda) e, com --post-check, quando o comando
+de verificação retorna código 0. Se alguma instância do lote não voltar saudável dentro de
+--wait-timeout, os lotes seguintes não são reiniciados (use --continue-on-failure para seguir).
+
+Instâncias paradas ou em modo manutenção são ignoradas. Ao final, a tabela mostra as
+instâncias que não voltaram saudáveis.
+
+Exemplos:
+  opsmaster reboot --instances-file fleet.csv --wait --post-check 'systemctl is-active puppet'
+
+  # Lotes de 5, até 15 minutos por lote
+  opsmaster reboot --instances-file fleet.csv --batch-size 5 --wait --wait-timeout 15m`,
+	RunE: runReboot,
+}
+
+func init() {
+	RebootCmd.Flags().StringVar(&instancesFile, "instances-file", "", "Arquivo CSV com lista de instâncias: caminho local, https:// ou s3:// (aceita .gz) (obrigatório)")
+	RebootCmd.Flags().StringArrayVar(&where, "where", nil, "Seleciona instâncias por coluna do CSV (ex: environment=blue); pode ser repetida")
+	RebootCmd.Flags().StringVar(&awsProfile, "aws-profile", "", "Perfil AWS a usar (padrão: perfil default)")
+	RebootCmd.Flags().IntVar(&batchSize, "batch-size", 1, "Quantidade de instâncias reiniciadas por lote")
+	RebootCmd.Flags().BoolVar(&wait, "wait", false, "Aguardar as instâncias voltarem (via SSM) antes do próximo lote")
+	RebootCmd.Flags().DurationVar(&waitTimeout, "wait-timeout", 10*time.Minute, "Tempo máximo de espera por lote com --wait")
+	RebootCmd.Flags().StringVar(&postCheck, "post-check", "", "Comando executado após o boot; código diferente de 0 marca a instância como não saudável (requer --wait)")
+	RebootCmd.Flags().BoolVar(&continueOnFailure, "continue-on-failure", false, "Continuar com os próximos lotes mesmo se um lote não voltar saudável")
+	RebootCmd.Flags().BoolVar(&includeMaint, "include-maintenance", false, "Processar também instâncias em modo manutenção")
+	RebootCmd.Flags().StringVar(&maintTag, "maintenance-tag", cloud.DefaultMaintenanceTagKey, "Tag que marca instâncias em modo manutenção (valor true)")
+	RebootCmd.MarkFlagRequired("instances-file")
+}
+
+// runReboot reboots the selected instances batch by batch.
+func runReboot(_ *cobra.Command, _ []string) error {
+	log := logger.Get()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	if postCheck != "" && !wait {
+		return i18n.Errorf("--post-check requires --wait")
+	}
+	if batchSize < 1 {
+		return i18n.Errorf("--batch-size must be at least 1")
+	}
+
+	parser := csv.NewParser(csv.CSVConfig{
+		HasHeader:      true,
+		RequiredFields: []string{"instance_id", "account", "region"},
+		CloudDefault:   "aws",
+		Delimiter:      ',',
+		ColumnAliases:  viper.GetStringMapStringSlice("csv.column_aliases"),
+	})
+	instances, err := parser.ParseSource(ctx, instancesFile, awsprovider.S3Opener(awsProfile))
+	if err != nil {
+		return fmt.Errorf("failed to parse CSV file: %w", err)
+	}
+	instances, err = csv.SelectInstances(instances, parser.Columns(), where)
+	if err != nil {
+		return i18n.Errorf("invalid --where selector: %w", err)
+	}
+	instances = quarantine.Exclude(log, instances)
+	if len(instances) == 0 {
+		return i18n.Errorf("no instances selected from CSV file")
+	}
+
+	cloudType, err := provider.DetectCloudFromInstances(instances)
+	if err != nil {
+		return fmt.Errorf("failed to detect cloud provider: %w", err)
+	}
+	providerOptions := []provider.Option{provider.WithCommandLabel(cloud.CommandLabel{
+		Prefix:   cloud.DefaultCommandPrefix,
+		RunID:    logger.RunID(),
+		Operator: cloud.CurrentOperator(),
+	})}
+	if awsProfile != "" {
+		providerOptions = append(providerOptions, provider.WithProfile(awsProfile))
+	}
+	cloudProvider, err := provider.NewProvider(cloudType, providerOptions...)
+	if err != nil {
+		return fmt.Errorf("failed to create cloud provider: %w", err)
+	}
+
+	log.Info("🔄 Rebooting instances",
+		"run_id", logger.RunID(),
+		"instances", len(instances),
+		"batch_size", batchSize,
+		"wait", wait,
+		"post_check", postCheck)
+
+	results, err := executor.Reboot(ctx, executor.RebootConfig{
+		Provider:           cloudProvider,
+		BatchSize:          batchSize,
+		Wait:               wait,
+		WaitTimeout:        waitTimeout,
+		PostCheck:          postCheck,
+		ContinueOnFailure:  continueOnFailure,
+		MaintenanceTag:     maintTag,
+		IncludeMaintenance: includeMaint,
+	}, instances)
+	if err != nil {
+		return err
+	}
+
+	if unhealthy := printRebootResults(results); unhealthy > 0 {
+		return fmt.Errorf("%d instances did not return healthy", unhealthy)
+	}
+	return nil
+}
+
+// printRebootResults prints per-instance results and a summary line.
+// Returns the number of instances that failed or didn't come back healthy.
+func printRebootResults(results []*executor.RebootResult) int {
+	header := []string{"INSTANCE ID", "ACCOUNT", "REGION", "LOTE", "STATUS", "DURAÇÃO", "DETALHE"}
+	rows := make([][]string, 0, len(results))
+
+	rebooted, skipped, unhealthy := 0, 0, 0
+	for _, r := range results {
+		batch, duration := "-", "-"
+		if r.Batch > 0 {
+			batch, duration = fmt.Sprint(r.Batch), r.Duration.Round(time.Second).String()
+		}
+
+		status := "✅"
+		switch r.Status {
+		case executor.RebootStatusSkipped:
+			status = "⏭️"
+			skipped++
+		case executor.RebootStatusFailed, executor.RebootStatusUnhealthy:
+			status = "❌"
+			unhealthy++
+		default:
+			rebooted++
+		}
+
+		rows = append(rows, []string{
+			r.Instance.Label(),
+			r.Instance.Account,
+			r.Instance.Region,
+			batch,
+			status + " " + r.Status,
+			duration,
+			r.Detail,
+		})
+	}
+
+	fmt.Println()
+	presenter.PrintTable(header, rows)
+	presenter.Printf("\n📊 Summary: %d healthy/rebooted, %d skipped, %d unhealthy\n", rebooted, skipped, unhealthy)
+	return unhealthy
+}
@@ -0,0 +1,32 @@
+package report
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// ReportCmd represents the report command
+// This is the root command for working with run report files
+// Usage: opsmaster report <operation> [flags]
+var ReportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Valida e descreve os relatórios de execução (--report)",
+	Long: `Trabalha com os relatórios JSON gerados por install ... --report.
+
+O formato é versionado pelo campo schema_version e descrito por um JSON schema
+embutido no binário, para que outras ferramentas possam consumir os relatórios
+com segurança.
+
+Exemplos:
+  opsmaster report validate run.json
+  opsmaster report show run.json i-0abc123def456
+  opsmaster report schema > report.schema.json`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Help()
+	},
+}
+
+func init() {
+	ReportCmd.AddCommand(validateCmd)
+	ReportCmd.AddCommand(schemaCmd)
+	ReportCmd.AddCommand(showCmd)
+}
@@ -0,0 +1,100 @@
+package report
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/estudosdevops/opsmaster/internal/executor"
+	"github.com/estudosdevops/opsmaster/internal/presenter"
+)
+
+var showCmd = &cobra.Command{
+	Use:   "show <arquivo.json> <instance-id>",
+	Short: "Mostra o resultado e a saída completa de uma instância do relatório",
+	Long: `Mostra o resultado de uma instância de um relatório (--report): status, fase
+e passo que falharam, código de saída e a saída completa (stdout e stderr)
+capturada do script, que a tabela do resumo reduz a uma linha.
+
+A saída capturada é limitada por --max-output-bytes do comando de instalação;
+com --ssm-output-bucket, a saída completa de cada comando fica no S3 e os
+endereços aparecem em output_urls.
+
+Exemplos:
+  opsmaster report show run.json i-0abc123def456`,
+	Args: cobra.ExactArgs(2),
+	RunE: runShow,
+}
+
+// runShow prints the report entry of one instance with its captured output.
+func runShow(_ *cobra.Command, args []string) error {
+	path, instanceID := args[0], args[1]
+	report, err := executor.ReadReport(path)
+	if err != nil {
+		return err
+	}
+
+	var entry *executor.ReportEntry
+	for i := range report.Results {
+		if report.Results[i].InstanceID == instanceID {
+			entry = &report.Results[i]
+			break
+		}
+	}
+	if entry == nil {
+		return fmt.Errorf("instance %s not found in report %s", instanceID, path)
+	}
+
+	rows := [][]string{
+		{"Instance", entry.InstanceID},
+		{"Account", entry.Account},
+		{"Region", entry.Region},
+		{"Status", entry.Status},
+	}
+	if entry.Duration != "" {
+		rows = append(rows, []string{"Duration", entry.Duration})
+	}
+	if entry.SkipReason != "" {
+		rows = append(rows, []string{"Skip reason", entry.SkipReason})
+	}
+	if entry.FailurePhase != "" {
+		rows = append(rows, []string{"Failure phase", entry.FailurePhase})
+	}
+	if entry.ExitCode != 0 {
+		rows = append(rows, []string{"Exit code", strconv.Itoa(entry.ExitCode)})
+	}
+	if len(entry.Attempts) > 0 {
+		rows = append(rows, []string{"Attempts", strconv.Itoa(len(entry.Attempts) + 1)})
+	}
+	errorContext := entry.ErrorContext
+	if errorContext != nil && errorContext.Step != "" {
+		rows = append(rows, []string{"Step", errorContext.Step})
+	}
+	presenter.PrintTable([]string{"CAMPO", "VALOR"}, rows)
+
+	// Script failures: the full captured output; other errors: the message
+	if errorContext == nil || errorContext.Stdout == "" && errorContext.Stderr == "" {
+		if entry.Error != "" {
+			fmt.Printf("\nerror:\n%s\n", entry.Error)
+		}
+	} else {
+		printOutput("stderr", errorContext.Stderr)
+		printOutput("stdout", errorContext.Stdout)
+	}
+	if errorContext != nil {
+		printOutput("diagnostics", errorContext.Diagnostics)
+	}
+	// Full output stored by the provider (--ssm-output-bucket)
+	printOutput("output_urls", strings.Join(entry.OutputURLs, "\n"))
+	return nil
+}
+
+// printOutput prints a captured output stream under a heading.
+func printOutput(name, output string) {
+	if strings.TrimSpace(output) == "" {
+		return
+	}
+	fmt.Printf("\n--- %s ---\n%s\n", name, strings.TrimRight(output, "\n"))
+}
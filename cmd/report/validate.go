@@ -0,0 +1,81 @@
+package report
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/estudosdevops/opsmaster/internal/executor"
+	"github.com/estudosdevops/opsmaster/internal/i18n"
+	"github.com/estudosdevops/opsmaster/internal/presenter"
+	"github.com/estudosdevops/opsmaster/internal/schema"
+)
+
+// report schema command flags
+var schemaVersion int // Schema version to print
+
+var validateCmd = &cobra.Command{
+	Use:   "validate <arquivo.json>",
+	Short: "Valida um relatório contra o JSON schema da sua versão",
+	Long: `Valida um relatório JSON contra o schema embutido correspondente ao seu
+schema_version, listando cada campo inválido.
+
+Retorna erro (exit code 1) se o relatório for inválido, útil em pipelines que
+consomem os relatórios.
+
+Exemplos:
+  opsmaster report validate run.json`,
+	Args: cobra.ExactArgs(1),
+	RunE: runValidate,
+}
+
+var schemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Mostra o JSON schema dos relatórios",
+	Long: `Mostra o JSON schema embutido dos relatórios (por padrão, o da versão
+escrita por este binário).
+
+Exemplos:
+  opsmaster report schema > report.schema.json
+  opsmaster report schema --version 1`,
+	RunE: runSchema,
+}
+
+func init() {
+	schemaCmd.Flags().IntVar(&schemaVersion, "version", executor.ReportSchemaVersion, "schema_version do schema a mostrar")
+}
+
+// runValidate validates a report file and prints its violations.
+func runValidate(_ *cobra.Command, args []string) error {
+	path := args[0]
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read report: %w", err)
+	}
+
+	version, violations, err := schema.Validate(schema.KindReport, data)
+	if err != nil {
+		return fmt.Errorf("invalid report %s: %w", path, err)
+	}
+	if len(violations) == 0 {
+		presenter.Printf("✅ %s is a valid report (schema_version %d)\n", path, version)
+		return nil
+	}
+
+	presenter.Printf("❌ %s does not match schema_version %d:\n", path, version)
+	for _, violation := range violations {
+		presenter.Printf("   • %s\n", violation)
+	}
+	return i18n.Errorf("report %s has %d schema violations", path, len(violations))
+}
+
+// runSchema prints the embedded schema.
+func runSchema(_ *cobra.Command, _ []string) error {
+	data, err := schema.Schema(schema.KindReport, schemaVersion)
+	if err != nil {
+		return err
+	}
+	fmt.Print(string(data))
+	return nil
+}
@@ -0,0 +1,25 @@
+package retry
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// RetryCmd represents the retry command
+// This is the root command for reasoning about retry policies
+// Usage: opsmaster retry <operation> [flags]
+var RetryCmd = &cobra.Command{
+	Use:   "retry",
+	Short: "Ferramentas para ajustar as políticas de retry",
+	Long: `Ferramentas para ajustar as políticas de retry (--max-retries, --retry-delay)
+sem tentativa e erro em produção.
+
+Exemplos:
+  opsmaster retry simulate --policy ssm --failure-rate 0.2 --operations 1000`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Help()
+	},
+}
+
+func init() {
+	RetryCmd.AddCommand(simulateCmd)
+}
@@ -0,0 +1,184 @@
+package retry
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/estudosdevops/opsmaster/internal/flagalias"
+	"github.com/estudosdevops/opsmaster/internal/presenter"
+	"github.com/estudosdevops/opsmaster/internal/retry"
+)
+
+// retry simulate command flags
+var (
+	policyName   string        // Predefined policy (ssm, ec2, network)
+	failureRate  float64       // Probability of each attempt failing
+	operations   int           // Operations simulated
+	maxRetries   int           // Overrides the policy max attempts (0 = policy)
+	retryDelay   time.Duration // Overrides the policy base delay (0 = policy)
+	maxDelay     time.Duration // Overrides the policy max delay (0 = policy)
+	retryJitter  bool          // Overrides the policy jitter (when set)
+	callTime     time.Duration // Duration of each API call
+	concurrency  int           // Operations in parallel, for the wall time
+	seed         int64         // Random seed (0 = new seed, printed)
+	outputFormat string        // table or json
+)
+
+// simulationOutput is the JSON output of retry simulate.
+type simulationOutput struct {
+	Policy      string  `json:"policy"`
+	MaxAttempts int     `json:"max_attempts"`
+	BaseDelay   string  `json:"base_delay"`
+	MaxDelay    string  `json:"max_delay"`
+	Jitter      bool    `json:"jitter"`
+	FailureRate float64 `json:"failure_rate"`
+	Seed        int64   `json:"seed"`
+	Operations  int     `json:"operations"`
+	Succeeded   int     `json:"succeeded"`
+	Exhausted   int     `json:"exhausted"`
+	APICalls    int     `json:"api_calls"`
+	CallsPerOp  float64 `json:"calls_per_operation"`
+	TotalTime   string  `json:"total_time"`
+	WallTime    string  `json:"wall_time"`
+	WaitP50     string  `json:"wait_p50"`
+	WaitP90     string  `json:"wait_p90"`
+	WaitP99     string  `json:"wait_p99"`
+	WaitMax     string  `json:"wait_max"`
+}
+
+var simulateCmd = &cobra.Command{
+	Use:   "simulate",
+	Short: "Simula uma política de retry (Monte Carlo)",
+	Long: `Simula uma política de retry com Monte Carlo: cada tentativa falha com a
+probabilidade de --failure-rate e as esperas seguem o mesmo backoff exponencial
+(com jitter) usado pelo opsmaster, sem chamar nenhuma API nem esperar de fato.
+
+Mostra o tempo total esperado, o volume de chamadas à API, as operações que
+esgotam as tentativas e os percentis da espera por operação, para comparar
+valores de --max-retries e --retry-delay antes de usá-los.
+
+Políticas: ssm (comandos remotos), ec2 (metadados, tags) e network. As flags
+--max-retries, --retry-delay, --max-delay e --retry-jitter sobrepõem a política.
+
+Exemplos:
+  opsmaster retry simulate --policy ssm --failure-rate 0.2 --operations 1000
+
+  # Comparar com 5 tentativas e espera inicial de 5s
+  opsmaster retry simulate --policy ssm --failure-rate 0.2 --max-retries 5 --retry-delay 5s`,
+	RunE: runSimulate,
+}
+
+func init() {
+	simulateCmd.Flags().StringVar(&policyName, "policy", "ssm", "Política simulada (ssm, ec2, network)")
+	simulateCmd.Flags().Float64Var(&failureRate, "failure-rate", 0.1, "Probabilidade de cada tentativa falhar com erro transitório (0 a 1)")
+	simulateCmd.Flags().IntVar(&operations, "operations", 1000, "Quantidade de operações simuladas")
+	simulateCmd.Flags().IntVar(&maxRetries, "max-retries", 0, "Máximo de tentativas (padrão: o da política)")
+	simulateCmd.Flags().DurationVar(&retryDelay, "retry-delay", 0, "Espera inicial entre tentativas (padrão: a da política)")
+	simulateCmd.Flags().DurationVar(&maxDelay, "max-delay", 0, "Espera máxima entre tentativas (padrão: a da política)")
+	simulateCmd.Flags().BoolVar(&retryJitter, "retry-jitter", true, "Adiciona variação aleatória às esperas (padrão: o da política)")
+	simulateCmd.Flags().DurationVar(&callTime, "call-time", 500*time.Millisecond, "Duração de cada chamada à API")
+	simulateCmd.Flags().IntVar(&concurrency, "max-concurrency", 10, "Máximo de operações em paralelo, para o tempo decorrido")
+	simulateCmd.Flags().Int64Var(&seed, "seed", 0, "Semente aleatória, para repetir uma simulação (0 = nova semente, mostrada no resultado)")
+	simulateCmd.Flags().StringVarP(&outputFormat, "output", "o", presenter.OutputTable, "Formato de saída (table|json)")
+	// --concurrency foi renomeada para o nome usado pelos outros comandos
+	_ = flagalias.Deprecate(simulateCmd.Flags(), "concurrency", "max-concurrency")
+}
+
+// runSimulate simulates the selected policy and prints the outcome.
+func runSimulate(cmd *cobra.Command, _ []string) error {
+	if err := presenter.ValidateOutputFormat(outputFormat); err != nil {
+		return err
+	}
+	policy, err := retry.PolicyByName(policyName)
+	if err != nil {
+		return err
+	}
+	if maxRetries > 0 {
+		policy.MaxAttempts = maxRetries
+	}
+	if retryDelay > 0 {
+		// Same derivation as the install commands (see createPuppetRetryPolicies)
+		switch policyName {
+		case "ssm":
+			policy.BaseDelay, policy.MaxDelay = retryDelay, retryDelay*30
+		case "ec2":
+			policy.BaseDelay, policy.MaxDelay = retryDelay/2, retryDelay*5
+		default:
+			policy.BaseDelay = retryDelay
+		}
+	}
+	if maxDelay > 0 {
+		policy.MaxDelay = maxDelay
+	}
+	if cmd.Flags().Changed("retry-jitter") {
+		policy.Jitter = retryJitter
+	}
+	if policy.MaxDelay < policy.BaseDelay {
+		return fmt.Errorf("invalid --max-delay %s: shorter than the base delay %s", policy.MaxDelay, policy.BaseDelay)
+	}
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+
+	result, err := retry.Simulate(retry.SimulationConfig{
+		Policy:      policy,
+		FailureRate: failureRate,
+		Operations:  operations,
+		CallTime:    callTime,
+		Concurrency: concurrency,
+		Seed:        seed,
+	})
+	if err != nil {
+		return err
+	}
+
+	output := simulationOutput{
+		Policy:      policyName,
+		MaxAttempts: policy.MaxAttempts,
+		BaseDelay:   policy.BaseDelay.String(),
+		MaxDelay:    policy.MaxDelay.String(),
+		Jitter:      policy.Jitter,
+		FailureRate: failureRate,
+		Seed:        seed,
+		Operations:  result.Operations,
+		Succeeded:   result.Succeeded,
+		Exhausted:   result.Exhausted,
+		APICalls:    result.APICalls,
+		CallsPerOp:  result.CallsPerOp,
+		TotalTime:   roundDuration(result.TotalTime),
+		WallTime:    roundDuration(result.WallTime),
+		WaitP50:     roundDuration(result.WaitP50),
+		WaitP90:     roundDuration(result.WaitP90),
+		WaitP99:     roundDuration(result.WaitP99),
+		WaitMax:     roundDuration(result.WaitMax),
+	}
+	if outputFormat == presenter.OutputJSON {
+		return presenter.PrintJSON(output)
+	}
+
+	presenter.PrintTable([]string{"CAMPO", "VALOR"}, [][]string{
+		{"Policy", fmt.Sprintf("%s (%d attempts, base %s, max %s, jitter %t)",
+			output.Policy, output.MaxAttempts, output.BaseDelay, output.MaxDelay, output.Jitter)},
+		{"Failure rate", strconv.FormatFloat(failureRate*100, 'f', -1, 64) + "%"},
+		{"Seed", strconv.FormatInt(seed, 10)},
+		{"Operations", strconv.Itoa(output.Operations)},
+		{"Succeeded", strconv.Itoa(output.Succeeded)},
+		{"Exhausted", fmt.Sprintf("%d (%.2f%%)", output.Exhausted, 100*float64(output.Exhausted)/float64(output.Operations))},
+		{"API calls", fmt.Sprintf("%d (%.2f per operation)", output.APICalls, output.CallsPerOp)},
+		{"Total time", output.TotalTime},
+		{"Wall time", fmt.Sprintf("%s (concurrency %d)", output.WallTime, max(concurrency, 1))},
+		{"Wait p50", output.WaitP50},
+		{"Wait p90", output.WaitP90},
+		{"Wait p99", output.WaitP99},
+		{"Wait max", output.WaitMax},
+	})
+	return nil
+}
+
+// roundDuration formats a simulated duration to the millisecond.
+func roundDuration(d time.Duration) string {
+	return d.Round(time.Millisecond).String()
+}
@@ -0,0 +1,41 @@
+package retry
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/estudosdevops/opsmaster/internal/flagalias"
+)
+
+// TestSimulateCmd_DeprecatedConcurrency tests that the old --concurrency
+// name still sets --max-concurrency and warns with the replacement
+func TestSimulateCmd_DeprecatedConcurrency(t *testing.T) {
+	// ARRANGE
+	flags := simulateCmd.Flags()
+	var stderr bytes.Buffer
+
+	// ACT
+	if err := flags.Parse([]string{"--concurrency", "4"}); err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+	err := flagalias.Check(flags, &stderr)
+
+	// ASSERT
+	if err != nil {
+		t.Fatalf("Check() error: %v", err)
+	}
+	if concurrency != 4 || !flags.Changed("max-concurrency") {
+		t.Errorf("--max-concurrency = %d (changed %v), want 4 set by --concurrency", concurrency, flags.Changed("max-concurrency"))
+	}
+	if warning := stderr.String(); !strings.Contains(warning, "--concurrency") || !strings.Contains(warning, "--max-concurrency") {
+		t.Errorf("warning = %q, want --concurrency and its replacement", warning)
+	}
+	want := flagalias.Usage{Flag: "concurrency", Replacement: "max-concurrency"}
+	if used := flagalias.Used(); len(used) != 1 || used[0] != want {
+		t.Errorf("Used() = %v, want [%v]", used, want)
+	}
+	if flag := flags.Lookup("concurrency"); flag == nil || !flag.Hidden {
+		t.Error("--concurrency should be a hidden alias")
+	}
+}
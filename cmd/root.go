@@ -3,19 +3,68 @@ package cmd
 
 import (
 	"github.com/estudosdevops/opsmaster/cmd/argocd"
+	"github.com/estudosdevops/opsmaster/cmd/collector"
+	"github.com/estudosdevops/opsmaster/cmd/coverage"
+	"github.com/estudosdevops/opsmaster/cmd/ctl"
+	"github.com/estudosdevops/opsmaster/cmd/ec2"
+	"github.com/estudosdevops/opsmaster/cmd/facts"
 	"github.com/estudosdevops/opsmaster/cmd/get"
+	"github.com/estudosdevops/opsmaster/cmd/history"
 	"github.com/estudosdevops/opsmaster/cmd/install"
+	"github.com/estudosdevops/opsmaster/cmd/installers"
+	"github.com/estudosdevops/opsmaster/cmd/logs"
 	"github.com/estudosdevops/opsmaster/cmd/nelm"
+	"github.com/estudosdevops/opsmaster/cmd/pipeline"
+	"github.com/estudosdevops/opsmaster/cmd/puppet"
+	"github.com/estudosdevops/opsmaster/cmd/reboot"
+	"github.com/estudosdevops/opsmaster/cmd/report"
+	"github.com/estudosdevops/opsmaster/cmd/retry"
+	"github.com/estudosdevops/opsmaster/cmd/run"
 	"github.com/estudosdevops/opsmaster/cmd/scan"
+	"github.com/estudosdevops/opsmaster/cmd/tags"
+	"github.com/estudosdevops/opsmaster/internal/ascii"
+	"github.com/estudosdevops/opsmaster/internal/cloud"
+	"github.com/estudosdevops/opsmaster/internal/cloud/aws"
+	"github.com/estudosdevops/opsmaster/internal/cloud/provider"
+	"github.com/estudosdevops/opsmaster/internal/flagalias"
+	"github.com/estudosdevops/opsmaster/internal/httpclient"
+	"github.com/estudosdevops/opsmaster/internal/i18n"
+	"github.com/estudosdevops/opsmaster/internal/logger"
+	"github.com/estudosdevops/opsmaster/internal/presenter"
+	"github.com/estudosdevops/opsmaster/internal/profile"
+	"github.com/estudosdevops/opsmaster/internal/quarantine"
 
 	"fmt"
 	"os"
+	"strings"
 
+	"github.com/google/uuid"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 )
 
-var cfgFile string
+var (
+	cfgFile        string
+	caBundle       string
+	providerName   string
+	fakeScenario   string
+	quarantineFile string
+	profileName    string
+	asciiOutput    bool
+	readOnly       bool
+	langName       string
+	displayColumn  string
+
+	// Documento SSM aprovado pela organização no lugar do AWS-RunShellScript
+	ssmDocumentName   string
+	ssmDocumentParams []string
+
+	// Bucket S3 (SSE-KMS) com a saída completa dos comandos SSM
+	ssmOutputBucket string
+	ssmOutputPrefix string
+	ssmOutputKMSKey string
+)
 
 // RootCmd é o comando raiz da nossa aplicação.
 var RootCmd = &cobra.Command{
@@ -24,9 +73,34 @@ var RootCmd = &cobra.Command{
 	Long: `OpsMaster é uma ferramenta de CLI projetada para ajudar em várias
 operações de DevOps. Ela fornece um conjunto de comandos para automatizar e
 simplificar tarefas comuns de DevOps.`,
+	// Flags obsoletas, variáveis OPSMASTER_* e perfil de flags (--profile),
+	// tratados antes dos hooks dos subcomandos e da validação de flags
+	// obrigatórias. Precedência: linha de comando > ambiente > perfil. As
+	// flags obsoletas vêm primeiro: marcam a flag atual como informada, senão
+	// o ambiente ou o perfil sobrescreveriam o valor da linha de comando
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if err := flagalias.Check(cmd.Flags(), os.Stderr); err != nil {
+			return err
+		}
+		if _, err := profile.ApplyEnv(cmd.Flags(), os.LookupEnv); err != nil {
+			return err
+		}
+		return applyProfile(cmd, args)
+	},
 }
 
 func Execute() error {
+	// O idioma é escolhido antes do parse: --help não executa os hooks
+	lang, err := i18n.Detect(langFromArgs(os.Args[1:]), os.Getenv)
+	if err != nil {
+		return err
+	}
+	i18n.Set(lang)
+
+	RootCmd.InitDefaultHelpCmd()
+	RootCmd.InitDefaultCompletionCmd()
+	localizeHelp(RootCmd)
+	RootCmd.SetUsageTemplate(localizeTemplate(RootCmd.UsageTemplate()))
 	return RootCmd.Execute()
 }
 
@@ -36,13 +110,61 @@ func init() {
 	RootCmd.AddCommand(argocd.ArgocdCmd)
 	RootCmd.AddCommand(nelm.NelmCmd)
 	RootCmd.AddCommand(install.InstallCmd)
+	RootCmd.AddCommand(installers.InstallersCmd)
+	RootCmd.AddCommand(ec2.Ec2Cmd)
+	RootCmd.AddCommand(puppet.PuppetCmd)
+	RootCmd.AddCommand(facts.FactsCmd)
+	RootCmd.AddCommand(logs.LogsCmd)
+	RootCmd.AddCommand(reboot.RebootCmd)
+	RootCmd.AddCommand(report.ReportCmd)
+	RootCmd.AddCommand(retry.RetryCmd)
+	RootCmd.AddCommand(tags.TagsCmd)
+	RootCmd.AddCommand(run.RunCmd)
+	RootCmd.AddCommand(collector.CollectorCmd)
+	RootCmd.AddCommand(ctl.CtlCmd)
+	RootCmd.AddCommand(coverage.CoverageCmd)
+	RootCmd.AddCommand(pipeline.PipelineCmd)
+	RootCmd.AddCommand(history.HistoryCmd)
 
+	// Hooks de todos os níveis rodam (raiz primeiro), senão o PersistentPreRunE
+	// de um subcomando (ex: argocd) substituiria o da raiz
+	cobra.EnableTraverseRunHooks = true
 	cobra.OnInitialize(initConfig)
 	RootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "arquivo de configuração (o padrão é $HOME/.opsmaster.yaml)")
 	RootCmd.PersistentFlags().String("context", "", "O contexto a ser usado do arquivo de configuração (ex: staging, producao)")
+	RootCmd.PersistentFlags().StringVar(&caBundle, "ca-bundle", "", "Arquivo PEM com CAs adicionais para chamadas HTTP de saída")
+	RootCmd.PersistentFlags().StringVar(&providerName, "provider", "", "Força o provider de todas as instâncias (fake: simulado, sem conta na nuvem)")
+	RootCmd.PersistentFlags().StringVar(&fakeScenario, "fake-scenario", "", "Cenário YAML do provider simulado (--provider fake)")
+	RootCmd.PersistentFlags().StringVar(&profileName, "profile", "", "Perfil de flags da seção profiles do arquivo de configuração (ex: prod-puppet); flags da linha de comando têm precedência")
+	RootCmd.PersistentFlags().BoolVar(&asciiOutput, "ascii", false, "Saída apenas em ASCII: [OK]/[FAIL]/[SKIP] no lugar de emojis e bordas simples nas tabelas (padrão: ativada quando o locale não é UTF-8)")
+	RootCmd.PersistentFlags().StringVar(&langName, "lang", "", "Idioma da ajuda e das mensagens: en ou pt-BR (padrão: OPSMASTER_LANG ou o locale; pt-BR se não suportado)")
+	RootCmd.PersistentFlags().StringVar(&displayColumn, "display-column", "", "Coluna do CSV com o nome exibido ao lado do ID da instância em tabelas, logs e notificações (padrão: name ou hostname, ou a tag Name)")
+	RootCmd.PersistentFlags().BoolVar(&readOnly, "read-only", false, "Modo somente leitura: recusa tags, start/stop/reboot e comandos remotos que alteram as instâncias (padrão: read_only.enabled do config)")
+	RootCmd.PersistentFlags().StringVar(&ssmDocumentName, "ssm-document-name", "", "Documento SSM (nome ou ARN) que executa os comandos shell no lugar do AWS-RunShellScript, validado em cada conta/região (padrão: ssm.document_name do config)")
+	RootCmd.PersistentFlags().StringSliceVar(&ssmDocumentParams, "ssm-document-params", nil, "Nomes dos parâmetros do documento --ssm-document-name (ex: commands=Script,executionTimeout=TimeoutSeconds; executionTimeout= quando não existe) (padrão: ssm.document_parameters do config)")
+	RootCmd.PersistentFlags().StringVar(&ssmOutputBucket, "ssm-output-bucket", "", "Bucket S3 (criptografado com SSE-KMS) onde o SSM grava a saída completa de cada comando; os endereços vão para o relatório (padrão: ssm.output_bucket do config)")
+	RootCmd.PersistentFlags().StringVar(&ssmOutputPrefix, "ssm-output-prefix", "", "Prefixo das chaves da saída no --ssm-output-bucket (ex: opsmaster/prod) (padrão: ssm.output_prefix do config)")
+	RootCmd.PersistentFlags().StringVar(&ssmOutputKMSKey, "ssm-output-kms-key", "", "Chave KMS (ID ou ARN) exigida na criptografia padrão do --ssm-output-bucket (padrão: qualquer chave KMS; ssm.output_kms_key do config)")
+	RootCmd.PersistentFlags().StringVar(&quarantineFile, "quarantine-file", "", "Arquivo YAML de instâncias em quarentena, sempre ignoradas (padrão: quarantine.file do config ou $HOME/.opsmaster-quarantine.yaml)")
 }
 
 func initConfig() {
+	// ID único desta execução: logs, comentários do SSM, tags e registros externos
+	logger.SetRunID(uuid.NewString())
+
+	// Flags globais também vêm de variáveis OPSMASTER_* (ex: OPSMASTER_CONFIG),
+	// lidas aqui pois são usadas antes dos hooks dos comandos
+	_, err := profile.ApplyEnv(RootCmd.PersistentFlags(), os.LookupEnv)
+	cobra.CheckErr(err)
+
+	// Saída ASCII para terminais, agregadores de log e leitores de tela sem
+	// suporte a emojis: --ascii (ou --ascii=false) ou locale sem UTF-8
+	if RootCmd.PersistentFlags().Changed("ascii") {
+		ascii.Enable(asciiOutput)
+	} else {
+		ascii.Enable(ascii.FromLocale(os.Getenv))
+	}
+
 	if cfgFile != "" {
 		viper.SetConfigFile(cfgFile)
 	} else {
@@ -56,6 +178,132 @@ func initConfig() {
 	viper.AutomaticEnv()
 
 	if err := viper.ReadInConfig(); err == nil {
-		fmt.Println("Usando o arquivo de configuração:", viper.ConfigFileUsed())
+		presenter.Println("Usando o arquivo de configuração:", viper.ConfigFileUsed())
+	}
+
+	// Cliente HTTP compartilhado por todos os subsistemas (proxy via env, CA bundle, retries)
+	cobra.CheckErr(httpclient.Configure(httpclient.Config{CABundle: caBundle}))
+
+	// Nome exibido ao lado do ID das instâncias (coluna do CSV ou tag Name)
+	cloud.SetDisplayColumn(displayColumn)
+
+	// Quarentena consultada por todos os comandos: instâncias frágeis são sempre ignoradas
+	if quarantineFile == "" {
+		quarantineFile = viper.GetString("quarantine.file")
+	}
+	cobra.CheckErr(quarantine.Configure(quarantineFile))
+
+	// Modo somente leitura para credenciais compartilhadas (auditoria,
+	// preflight): os providers recusam as operações que alteram as instâncias.
+	// read_only.enabled no config não pode ser desativado pela flag
+	if readOnly || viper.GetBool("read_only.enabled") {
+		provider.UseReadOnly(viper.GetStringSlice("read_only.allowed_scripts"))
+	}
+
+	// Documento SSM customizado para organizações que proíbem o
+	// AWS-RunShellScript: a validação confere que ele existe em cada
+	// conta/região antes de qualquer comando
+	if ssmDocumentName == "" {
+		ssmDocumentName = viper.GetString("ssm.document_name")
+	}
+	if len(ssmDocumentParams) == 0 {
+		ssmDocumentParams = viper.GetStringSlice("ssm.document_parameters")
+	}
+	documentParams, err := aws.ParseDocumentParameters(ssmDocumentParams)
+	cobra.CheckErr(err)
+	cobra.CheckErr(provider.UseSSMDocument(aws.SSMDocument{Name: ssmDocumentName, Parameters: documentParams}))
+
+	// Saída completa dos comandos SSM guardada em um bucket do cliente,
+	// criptografado com SSE-KMS (conferido na validação)
+	if ssmOutputBucket == "" {
+		ssmOutputBucket = viper.GetString("ssm.output_bucket")
+	}
+	if ssmOutputPrefix == "" {
+		ssmOutputPrefix = viper.GetString("ssm.output_prefix")
+	}
+	if ssmOutputKMSKey == "" {
+		ssmOutputKMSKey = viper.GetString("ssm.output_kms_key")
+	}
+	cobra.CheckErr(provider.UseSSMOutput(aws.SSMOutput{Bucket: ssmOutputBucket, KeyPrefix: ssmOutputPrefix, KMSKeyID: ssmOutputKMSKey}))
+
+	// Provider simulado para demos e CI: substitui o provider detectado pelo CSV
+	switch {
+	case providerName == "fake":
+		cobra.CheckErr(provider.UseFake(fakeScenario))
+	case providerName != "":
+		cobra.CheckErr(i18n.Errorf("invalid --provider %q (supported: fake)", providerName))
+	case fakeScenario != "":
+		cobra.CheckErr(i18n.Errorf("--fake-scenario requires --provider fake"))
+	}
+}
+
+// applyProfile aplica as flags do perfil --profile (seção profiles do config)
+// que não foram informadas na linha de comando.
+func applyProfile(cmd *cobra.Command, _ []string) error {
+	if profileName == "" {
+		return nil
+	}
+	values, err := profile.Lookup(viper.GetStringMap("profiles"), profileName)
+	if err != nil {
+		return err
+	}
+	// Flags globais já foram lidas em initConfig e não podem vir do perfil
+	applied, err := profile.Apply(cmd.Flags(), cmd.Root().PersistentFlags(), values)
+	if err != nil {
+		return fmt.Errorf("profile %q: %w", profileName, err)
+	}
+	presenter.Println("Usando o perfil:", profileName, "("+strings.Join(applied, ", ")+")")
+	return nil
+}
+
+// langFromArgs retorna o valor de --lang na linha de comando (--lang en ou
+// --lang=en), lido antes do parse do cobra.
+func langFromArgs(args []string) string {
+	for i, arg := range args {
+		switch {
+		case arg == "--":
+			return ""
+		case arg == "--lang" && i+1 < len(args):
+			return args[i+1]
+		case strings.HasPrefix(arg, "--lang="):
+			return strings.TrimPrefix(arg, "--lang=")
+		}
+	}
+	return ""
+}
+
+// localizeHelp traduz a ajuda de cmd e dos seus subcomandos (descrições,
+// exemplos e flags) para o idioma selecionado.
+func localizeHelp(cmd *cobra.Command) {
+	cmd.Short = i18n.T(cmd.Short)
+	if cmd.HasParent() && cmd.Parent().Name() == "completion" {
+		// Short gerado pelo cobra com o nome do shell (ex: bash)
+		cmd.Short = fmt.Sprintf(i18n.T("Generate the autocompletion script for %s"), cmd.Name())
+	}
+	cmd.Long = i18n.T(cmd.Long)
+	cmd.Example = i18n.T(cmd.Example)
+
+	cmd.InitDefaultHelpFlag()
+	translate := func(flag *pflag.Flag) { flag.Usage = i18n.T(flag.Usage) }
+	cmd.Flags().VisitAll(translate)
+	cmd.PersistentFlags().VisitAll(translate)
+	if help := cmd.Flags().Lookup("help"); help != nil {
+		help.Usage = fmt.Sprintf(i18n.T("help for %s"), cmd.DisplayName())
+	}
+
+	for _, sub := range cmd.Commands() {
+		localizeHelp(sub)
+	}
+}
+
+// localizeTemplate traduz os títulos do template de uso do cobra.
+func localizeTemplate(template string) string {
+	for _, heading := range []string{
+		"Usage:", "Examples:", "Available Commands:", "Additional Commands:",
+		"Global Flags:", "Additional help topics:",
+		`Use "{{.CommandPath}} [command] --help" for more information about a command.`,
+	} {
+		template = strings.ReplaceAll(template, heading, i18n.T(heading))
 	}
+	return template
 }
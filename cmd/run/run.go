@@ -0,0 +1,26 @@
+package run
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// RunCmd represents the run command
+// This is the root command for ad-hoc remote execution on the fleet
+// Usage: opsmaster run <operation> [flags]
+var RunCmd = &cobra.Command{
+	Use:   "run",
+	Short: "Executa comandos e scripts na frota",
+	Long: `Executa comandos e scripts ad-hoc nas instâncias listadas em arquivo CSV (via SSM),
+com o resultado de cada instância em uma tabela ou JSON.
+
+Exemplos:
+  # Verificar o espaço em disco em toda a frota
+  opsmaster run script --command "df -h /" --instances-file fleet.csv`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Help()
+	},
+}
+
+func init() {
+	RunCmd.AddCommand(scriptCmd)
+}
@@ -0,0 +1,318 @@
+package run
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/estudosdevops/opsmaster/internal/cloud"
+	awsprovider "github.com/estudosdevops/opsmaster/internal/cloud/aws"
+	"github.com/estudosdevops/opsmaster/internal/cloud/provider"
+	"github.com/estudosdevops/opsmaster/internal/csv"
+	"github.com/estudosdevops/opsmaster/internal/executor"
+	"github.com/estudosdevops/opsmaster/internal/i18n"
+	"github.com/estudosdevops/opsmaster/internal/logger"
+	"github.com/estudosdevops/opsmaster/internal/presenter"
+	"github.com/estudosdevops/opsmaster/internal/quarantine"
+)
+
+// Values of --shell.
+const (
+	shellAuto       = "auto"
+	shellSh         = "sh"
+	shellBash       = "bash"
+	shellPowerShell = cloud.ShellPowerShell
+)
+
+// maxOutputBytes keeps stdout/stderr of each instance below the SSM limit.
+const maxOutputBytes = 20000
+
+// run script command flags
+var (
+	instancesFile  string        // CSV file with instance list
+	awsProfile     string        // AWS profile to use
+	where          []string      // Column selectors applied to CSV rows
+	command        string        // Inline script
+	scriptFile     string        // Local script file
+	windowsFile    string        // Local PowerShell script for Windows instances (--shell auto)
+	shell          string        // auto, sh, bash or powershell
+	maxConcurrency int           // Max simultaneous executions
+	timeout        time.Duration // Per-instance command timeout
+	outputFormat   string        // table or json
+	includeMaint   bool          // Run on instances in maintenance mode
+	maintenanceTag string        // Tag key marking maintenance mode
+)
+
+// scriptRow is the result of the script on one instance.
+type scriptRow struct {
+	InstanceID string `json:"instance_id"`
+	Name       string `json:"name,omitempty"` // Display name (--display-column)
+	Account    string `json:"account"`
+	Region     string `json:"region"`
+	Shell      string `json:"shell"`
+	ExitCode   *int   `json:"exit_code"` // Null when the command could not run
+	Stdout     string `json:"stdout"`
+	Stderr     string `json:"stderr"`
+	Error      string `json:"error,omitempty"`       // Execution failure or non-zero exit code
+	SkipReason string `json:"skip_reason,omitempty"` // Why the script didn't run (e.g., maintenance mode)
+}
+
+var scriptCmd = &cobra.Command{
+	Use:   "script",
+	Short: "Executa um script em cada instância e mostra o resultado",
+	Long: `Executa um script (inline com --command ou de um arquivo local com --file) em cada
+instância via SSM e mostra o exit code e a saída de cada uma.
+
+O shell é escolhido com --shell:
+  auto        (padrão) PowerShell nas instâncias Windows e sh nas demais, pela plataforma
+              detectada (coluna "platform" do CSV ou API do provider)
+  sh, bash    Shell POSIX em todas as instâncias (documento AWS-RunShellScript)
+  powershell  Windows PowerShell em todas as instâncias (documento AWS-RunPowerShellScript)
+
+Em PowerShell, erros interrompem o script com exit code 1 e o exit code do último
+executável é retornado, como em um script shell. Em CSVs com Linux e Windows, use
+--windows-file com a versão PowerShell do script para as instâncias Windows.
+
+Exemplos:
+  opsmaster run script --command "uptime" --instances-file fleet.csv
+
+  # Frota mista: script.sh no Linux e script.ps1 no Windows
+  opsmaster run script --file script.sh --windows-file script.ps1 --instances-file fleet.csv
+
+  # Saída JSON para processar com jq
+  opsmaster run script --shell powershell --command "Get-Service W32Time" --instances-file windows.csv -o json`,
+	RunE: runScript,
+}
+
+func init() {
+	scriptCmd.Flags().StringVar(&command, "command", "", "Script inline a executar")
+	scriptCmd.Flags().StringVar(&scriptFile, "file", "", "Arquivo local com o script a executar")
+	scriptCmd.Flags().StringVar(&windowsFile, "windows-file", "", "Arquivo PowerShell executado nas instâncias Windows (apenas com --shell auto)")
+	scriptCmd.Flags().StringVar(&shell, "shell", shellAuto, "Shell do script (auto|sh|bash|powershell)")
+	scriptCmd.Flags().StringVar(&instancesFile, "instances-file", "", "Arquivo CSV com lista de instâncias: caminho local, https:// ou s3:// (aceita .gz) (obrigatório)")
+	scriptCmd.Flags().StringArrayVar(&where, "where", nil, "Seleciona instâncias por coluna do CSV (ex: environment=blue); pode ser repetida")
+	scriptCmd.Flags().StringVar(&awsProfile, "aws-profile", "", "Perfil AWS a usar (padrão: perfil default)")
+	scriptCmd.Flags().IntVar(&maxConcurrency, "max-concurrency", 20, "Máximo de execuções em paralelo")
+	scriptCmd.Flags().DurationVar(&timeout, "timeout", 5*time.Minute, "Tempo máximo do script em cada instância")
+	scriptCmd.Flags().StringVarP(&outputFormat, "output", "o", presenter.OutputTable, "Formato de saída (table|json)")
+	scriptCmd.Flags().BoolVar(&includeMaint, "include-maintenance", false, "Processar também instâncias em modo manutenção")
+	scriptCmd.Flags().StringVar(&maintenanceTag, "maintenance-tag", cloud.DefaultMaintenanceTagKey, "Tag que marca instâncias em modo manutenção (valor true)")
+	scriptCmd.MarkFlagsMutuallyExclusive("command", "file")
+	scriptCmd.MarkFlagsOneRequired("command", "file")
+	scriptCmd.MarkFlagRequired("instances-file")
+}
+
+// runScript runs the script on every selected instance and prints the results.
+func runScript(_ *cobra.Command, _ []string) error {
+	log := logger.Get()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	if err := presenter.ValidateOutputFormat(outputFormat); err != nil {
+		return err
+	}
+	switch shell {
+	case shellAuto, shellSh, shellBash, shellPowerShell:
+	default:
+		return i18n.Errorf("invalid --shell %q (supported: auto, sh, bash, powershell)", shell)
+	}
+	if windowsFile != "" && shell != shellAuto {
+		return i18n.Errorf("--windows-file requires --shell auto")
+	}
+
+	script, err := readScript(command, scriptFile)
+	if err != nil {
+		return err
+	}
+	windowsScript := script
+	if windowsFile != "" {
+		if windowsScript, err = readScript("", windowsFile); err != nil {
+			return err
+		}
+	}
+
+	parser := csv.NewParser(csv.CSVConfig{
+		HasHeader:      true,
+		RequiredFields: []string{"instance_id", "account", "region"},
+		CloudDefault:   "aws",
+		Delimiter:      ',',
+		ColumnAliases:  viper.GetStringMapStringSlice("csv.column_aliases"),
+	})
+	instances, err := parser.ParseSource(ctx, instancesFile, awsprovider.S3Opener(awsProfile))
+	if err != nil {
+		return fmt.Errorf("failed to parse CSV file: %w", err)
+	}
+	instances, err = csv.SelectInstances(instances, parser.Columns(), where)
+	if err != nil {
+		return i18n.Errorf("invalid --where selector: %w", err)
+	}
+	instances = quarantine.Exclude(log, instances)
+	if len(instances) == 0 {
+		return i18n.Errorf("no instances selected from CSV file")
+	}
+
+	cloudType, err := provider.DetectCloudFromInstances(instances)
+	if err != nil {
+		return fmt.Errorf("failed to detect cloud provider: %w", err)
+	}
+	providerOptions := []provider.Option{provider.WithCommandLabel(cloud.CommandLabel{
+		Prefix:   cloud.DefaultCommandPrefix,
+		RunID:    logger.RunID(),
+		Operator: cloud.CurrentOperator(),
+	})}
+	if awsProfile != "" {
+		providerOptions = append(providerOptions, provider.WithProfile(awsProfile))
+	}
+	cloudProvider, err := provider.NewProvider(cloudType, providerOptions...)
+	if err != nil {
+		return fmt.Errorf("failed to create cloud provider: %w", err)
+	}
+
+	// Instances in maintenance mode are skipped, as in install
+	var skipped []*executor.ExecutionResult
+	var infos map[string]*cloud.InstanceInfo
+	if !includeMaint {
+		instances, skipped, infos, err = executor.ExcludeMaintenance(ctx, cloudProvider, instances, maintenanceTag)
+		if err != nil {
+			return err
+		}
+	}
+
+	// The platform decides the shell of each instance in auto mode
+	switch {
+	case shell == shellAuto && infos != nil:
+		cloud.EnrichInstances(instances, infos)
+	case shell == shellAuto:
+		if describer := cloud.CapabilitiesOf(cloudProvider).Describe; describer != nil {
+			infos, err := describer.DescribeInstances(ctx, instances)
+			if err != nil {
+				log.Warn("Failed to fetch instance platforms, assuming Linux", "error", err)
+			} else {
+				cloud.EnrichInstances(instances, infos)
+			}
+		} else {
+			log.Warn("Instance platforms unknown, assuming Linux", "reason", cloud.Unsupported(cloudProvider, "describing instances"))
+		}
+	}
+
+	log.Info("🚀 Running script", "shell", shell, "instances", len(instances), "skipped", len(skipped))
+
+	rows := make([]*scriptRow, len(instances), len(instances)+len(skipped))
+	rowFor := make(map[*cloud.Instance]*scriptRow, len(instances))
+	for i, instance := range instances {
+		rows[i] = &scriptRow{InstanceID: instance.ID, Name: instance.DisplayName(), Account: instance.Account, Region: instance.Region, Shell: instanceShell(instance)}
+		rowFor[instance] = rows[i]
+	}
+	for _, result := range skipped {
+		instance := result.Instance
+		rows = append(rows, &scriptRow{InstanceID: instance.ID, Name: instance.DisplayName(), Account: instance.Account,
+			Region: instance.Region, Shell: instanceShell(instance), SkipReason: result.SkipReason})
+	}
+
+	errs := executor.ForEachInstance(ctx, instances, maxConcurrency, func(ctx context.Context, instance *cloud.Instance) error {
+		row := rowFor[instance]
+		body := script
+		if row.Shell == shellPowerShell {
+			body = windowsScript
+		}
+		return runOnInstance(ctx, cloudProvider, instance, row, body)
+	})
+
+	failed := 0
+	for i, err := range errs {
+		if err != nil {
+			rows[i].Error = err.Error()
+			failed++
+		}
+	}
+
+	if outputFormat == presenter.OutputJSON {
+		if err := presenter.PrintJSON(rows); err != nil {
+			return err
+		}
+	} else {
+		printScriptTable(rows)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("script failed on %d of %d instances", failed, len(instances))
+	}
+	return nil
+}
+
+// readScript returns the inline script or the contents of file.
+func readScript(inline, file string) (string, error) {
+	if file == "" {
+		return inline, nil
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return "", fmt.Errorf("failed to read script: %w", err)
+	}
+	return string(data), nil
+}
+
+// instanceShell returns the --shell value used on an instance.
+func instanceShell(instance *cloud.Instance) string {
+	if shell != shellAuto {
+		return shell
+	}
+	if cloud.ShellForPlatform(instance.Metadata["platform"]) == cloud.ShellPowerShell {
+		return shellPowerShell
+	}
+	return shellSh
+}
+
+// runOnInstance runs body with the shell of row and records the output.
+// Returns an error for execution failures and non-zero exit codes.
+func runOnInstance(ctx context.Context, cloudProvider cloud.CloudProvider, instance *cloud.Instance, row *scriptRow, body string) error {
+	opts := cloud.ExecOptions{Comment: "run script", MaxOutputBytes: maxOutputBytes}
+	if row.Shell != shellSh {
+		opts.Shell = row.Shell
+	}
+
+	result, err := cloudProvider.ExecuteCommandWithOptions(ctx, instance, []string{body}, timeout, opts)
+	if err != nil {
+		return err
+	}
+	row.ExitCode = &result.ExitCode
+	row.Stdout = result.Stdout
+	row.Stderr = result.Stderr
+	if result.ExitCode != 0 {
+		return fmt.Errorf("exit code %d", result.ExitCode)
+	}
+	return nil
+}
+
+// printScriptTable prints the exit code and the last output line of each instance.
+func printScriptTable(rows []*scriptRow) {
+	header := []string{"INSTANCE ID", "ACCOUNT", "REGION", "SHELL", "EXIT", "SAÍDA", "ERRO"}
+	tableRows := make([][]string, 0, len(rows))
+	for _, row := range rows {
+		exitCode := "-"
+		if row.ExitCode != nil {
+			exitCode = strconv.Itoa(*row.ExitCode)
+		}
+		output := lastLine(row.Stdout)
+		switch {
+		case row.SkipReason != "":
+			output = "⏭️ " + row.SkipReason
+		case row.Error != "" && row.Stderr != "":
+			output = lastLine(row.Stderr)
+		}
+		tableRows = append(tableRows, []string{cloud.FormatLabel(row.InstanceID, row.Name), row.Account, row.Region, row.Shell, exitCode, presenter.OrDash(output), presenter.OrDash(row.Error)})
+	}
+	presenter.PrintTable(header, tableRows)
+}
+
+// lastLine returns the last non-empty line of output.
+func lastLine(output string) string {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
@@ -0,0 +1,201 @@
+package tags
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/estudosdevops/opsmaster/internal/cloud"
+	"github.com/estudosdevops/opsmaster/internal/cloud/provider"
+	"github.com/estudosdevops/opsmaster/internal/executor"
+	"github.com/estudosdevops/opsmaster/internal/i18n"
+	"github.com/estudosdevops/opsmaster/internal/logger"
+	"github.com/estudosdevops/opsmaster/internal/presenter"
+	"github.com/estudosdevops/opsmaster/internal/quarantine"
+)
+
+// tags apply command flags
+var (
+	fromReport     string // JSON run report written by install --report
+	includeFailed  bool   // Also apply failure tags of failed instances
+	awsProfile     string // AWS profile to use
+	maxConcurrency int    // Max simultaneous tagging calls
+	dryRun         bool   // Show the tags without applying them
+	includeMaint   bool   // Tag instances in maintenance mode
+	maintenanceTag string // Tag key marking maintenance mode
+)
+
+var applyCmd = &cobra.Command{
+	Use:   "apply",
+	Short: "Aplica as tags registradas no relatório de uma execução",
+	Long: `Aplica nas instâncias as tags registradas no relatório JSON de uma execução
+(install ... --report run.json), sem reinstalar nada.
+
+Útil quando a instalação rodou com --skip-tagging (ex: para validar antes de marcar as
+instâncias) ou quando as tags foram removidas. Por padrão só as instâncias com status
+SUCCESS recebem tags; --include-failed também reaplica as tags de falha.
+
+Exemplos:
+  opsmaster tags apply --from-report run.json
+  opsmaster tags apply --from-report run.json --include-failed --dry-run`,
+	RunE: runApply,
+}
+
+func init() {
+	applyCmd.Flags().StringVar(&fromReport, "from-report", "", "Relatório JSON gerado por install --report (obrigatório)")
+	applyCmd.Flags().BoolVar(&includeFailed, "include-failed", false, "Aplicar também as tags de falha das instâncias que falharam")
+	applyCmd.Flags().StringVar(&awsProfile, "aws-profile", "", "Perfil AWS a usar (padrão: perfil default)")
+	applyCmd.Flags().IntVar(&maxConcurrency, "max-concurrency", 10, "Máximo de instâncias marcadas em paralelo")
+	applyCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Mostrar as tags sem aplicá-las")
+	applyCmd.Flags().BoolVar(&includeMaint, "include-maintenance", false, "Processar também instâncias em modo manutenção")
+	applyCmd.Flags().StringVar(&maintenanceTag, "maintenance-tag", cloud.DefaultMaintenanceTagKey, "Tag que marca instâncias em modo manutenção (valor true)")
+	applyCmd.MarkFlagRequired("from-report")
+}
+
+// runApply tags the instances recorded in the report.
+func runApply(_ *cobra.Command, _ []string) error {
+	log := logger.Get()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	report, err := executor.ReadReport(fromReport)
+	if err != nil {
+		return err
+	}
+	if report.DryRun {
+		return i18n.Errorf("report %s is from a dry-run: no tags were recorded", fromReport)
+	}
+
+	var instances []*cloud.Instance
+	for _, entry := range report.Results {
+		if len(entry.Tags) == 0 || (entry.Status != executor.StatusSuccess.String() && !includeFailed) {
+			continue
+		}
+		instances = append(instances, entry.Instance())
+	}
+	instances = quarantine.Exclude(log, instances)
+	if len(instances) == 0 {
+		return i18n.Errorf("no instances with tags to apply in report %s", fromReport)
+	}
+
+	cloudType, err := provider.DetectCloudFromInstances(instances)
+	if err != nil {
+		return fmt.Errorf("failed to detect cloud provider: %w", err)
+	}
+	var providerOptions []provider.Option
+	if awsProfile != "" {
+		providerOptions = append(providerOptions, provider.WithProfile(awsProfile))
+	}
+	cloudProvider, err := provider.NewProvider(cloudType, providerOptions...)
+	if err != nil {
+		return fmt.Errorf("failed to create cloud provider: %w", err)
+	}
+
+	// Instances in maintenance mode are skipped, as in install
+	var skipped []*executor.ExecutionResult
+	if !includeMaint {
+		instances, skipped, _, err = executor.ExcludeMaintenance(ctx, cloudProvider, instances, maintenanceTag)
+		if err != nil {
+			return err
+		}
+	}
+	entries := entriesOf(report.Results, instances)
+
+	log.Info("🏷️  Applying tags from report",
+		"report", fromReport,
+		"run_id", report.RunID,
+		"package", report.Package,
+		"instances", len(instances),
+		"skipped", len(skipped),
+		"dry_run", dryRun)
+
+	errs := make([]error, len(instances))
+	if !dryRun {
+		errs = executor.ForEachInstance(ctx, instances, maxConcurrency, func(ctx context.Context, instance *cloud.Instance) error {
+			return cloudProvider.TagInstance(ctx, instance, tagsOf(entries, instance.ID))
+		})
+	}
+
+	if failed := printApplyResults(entries, errs, entriesOf(report.Results, skippedInstances(skipped)), skipped); failed > 0 {
+		return fmt.Errorf("tagging failed for %d instances", failed)
+	}
+	return nil
+}
+
+// entriesOf returns the report entries of instances, in the same order.
+func entriesOf(results []executor.ReportEntry, instances []*cloud.Instance) []executor.ReportEntry {
+	entries := make([]executor.ReportEntry, 0, len(instances))
+	for _, instance := range instances {
+		for _, entry := range results {
+			if entry.InstanceID == instance.ID {
+				entries = append(entries, entry)
+				break
+			}
+		}
+	}
+	return entries
+}
+
+// skippedInstances returns the instances of skipped results.
+func skippedInstances(skipped []*executor.ExecutionResult) []*cloud.Instance {
+	instances := make([]*cloud.Instance, 0, len(skipped))
+	for _, result := range skipped {
+		instances = append(instances, result.Instance)
+	}
+	return instances
+}
+
+// tagsOf returns the recorded tags of an instance.
+func tagsOf(entries []executor.ReportEntry, instanceID string) map[string]string {
+	for _, entry := range entries {
+		if entry.InstanceID == instanceID {
+			return entry.Tags
+		}
+	}
+	return nil
+}
+
+// printApplyResults prints the tags per instance, then the skipped ones
+// (skippedEntries and skipped in the same order). Returns the number of
+// failures.
+func printApplyResults(entries []executor.ReportEntry, errs []error, skippedEntries []executor.ReportEntry, skipped []*executor.ExecutionResult) int {
+	header := []string{"INSTANCE ID", "ACCOUNT", "REGION", "STATUS DA EXECUÇÃO", "TAGS", "RESULTADO"}
+	rows := make([][]string, 0, len(entries)+len(skippedEntries))
+
+	failed := 0
+	for i, entry := range entries {
+		result := "✅"
+		switch {
+		case errs[i] != nil:
+			result = "❌ " + errs[i].Error()
+			failed++
+		case dryRun:
+			result = "🔍 dry-run"
+		}
+		rows = append(rows, []string{entry.InstanceID, entry.Account, entry.Region, entry.Status, formatTags(entry.Tags), result})
+	}
+	for i, entry := range skippedEntries {
+		rows = append(rows, []string{entry.InstanceID, entry.Account, entry.Region, entry.Status, formatTags(entry.Tags), "⏭️ " + skipped[i].SkipReason})
+	}
+
+	fmt.Println()
+	presenter.PrintTable(header, rows)
+	presenter.Printf("\n📊 Summary: %d tagged, %d failed\n", len(entries)-failed, failed)
+	if len(skippedEntries) > 0 {
+		presenter.Printf("⏭️  %d instances in maintenance mode skipped\n", len(skippedEntries))
+	}
+	return failed
+}
+
+// formatTags renders tags as sorted key=value pairs.
+func formatTags(tags map[string]string) string {
+	pairs := make([]string, 0, len(tags))
+	for key, value := range tags {
+		pairs = append(pairs, key+"="+value)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ", ")
+}
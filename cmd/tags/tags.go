@@ -0,0 +1,28 @@
+package tags
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// TagsCmd represents the tags command
+// This is the root command for managing opsmaster tags on instances
+// Usage: opsmaster tags <operation> [flags]
+var TagsCmd = &cobra.Command{
+	Use:   "tags",
+	Short: "Gerencia as tags do opsmaster nas instâncias",
+	Long: `Gerencia as tags que o opsmaster aplica nas instâncias (ex: puppet=true,
+opsmaster:last_run_id).
+
+Exemplos:
+  # Aplicar as tags de uma instalação executada com --skip-tagging
+  opsmaster install puppet --instances-file fleet.csv --puppet-server puppet.example.com \
+    --skip-tagging --report run.json
+  opsmaster tags apply --from-report run.json`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Help()
+	},
+}
+
+func init() {
+	TagsCmd.AddCommand(applyCmd)
+}
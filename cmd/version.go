@@ -4,6 +4,8 @@ import (
 	"fmt"
 
 	"github.com/spf13/cobra"
+
+	"github.com/estudosdevops/opsmaster/internal/version"
 )
 
 // versionCmd representa o comando "version".
@@ -12,7 +14,7 @@ var versionCmd = &cobra.Command{
 	Short: "Exibe o número da versão do OpsMaster",
 	Long:  `Exibe o número da versão da ferramenta de CLI OpsMaster.`,
 	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Println("OpsMaster v0.1.0")
+		fmt.Println("OpsMaster " + version.Version)
 	},
 }
 
@@ -0,0 +1,128 @@
+// Package ascii implements the plain-ASCII output mode (--ascii) for
+// terminals, log aggregators and screen readers that don't handle emoji or
+// box-drawing characters. Plain rewrites text: status emoji become markers
+// ([OK], [FAIL], [SKIP], [WARN]), box drawing becomes -, | and +, accented
+// letters lose their accents and decorative emoji are dropped.
+//
+// The mode is process-wide: the root command enables it once (Enable) and
+// the presenter and the logger check Enabled on every output.
+package ascii
+
+import (
+	"strings"
+	"sync/atomic"
+	"unicode"
+)
+
+var enabled atomic.Bool
+
+// Enable turns the plain-ASCII output mode on or off.
+func Enable(on bool) {
+	enabled.Store(on)
+}
+
+// Enabled reports whether the plain-ASCII output mode is on.
+func Enabled() bool {
+	return enabled.Load()
+}
+
+// FromLocale reports whether the locale (LC_ALL, LC_CTYPE or LANG, the first
+// set wins, read with getenv) lacks UTF-8, so the mode should be on by
+// default. An unset locale is the C locale, which is ASCII.
+func FromLocale(getenv func(string) string) bool {
+	for _, name := range []string{"LC_ALL", "LC_CTYPE", "LANG"} {
+		if locale := getenv(name); locale != "" {
+			locale = strings.ToLower(locale)
+			return !strings.Contains(locale, "utf-8") && !strings.Contains(locale, "utf8")
+		}
+	}
+	return true
+}
+
+// markers replaces symbols with a meaning (status, arrows, bullets).
+var markers = map[rune]string{
+	'✅': "[OK]", '✓': "[OK]", '✔': "[OK]",
+	'❌': "[FAIL]", '✗': "[FAIL]", '✘': "[FAIL]", '⛔': "[FAIL]",
+	'⏭': "[SKIP]",
+	'⚠': "[WARN]",
+	'❓': "[?]",
+	'→': "->", '←': "<-", '•': "*", '×': "x", '…': "...",
+	'“': `"`, '”': `"`, '‘': "'", '’': "'", '–': "-", '—': "-",
+}
+
+// letters maps the accented letters of the (Portuguese) messages.
+var letters = map[rune]rune{
+	'á': 'a', 'à': 'a', 'â': 'a', 'ã': 'a', 'ä': 'a',
+	'é': 'e', 'è': 'e', 'ê': 'e', 'ë': 'e',
+	'í': 'i', 'ì': 'i', 'î': 'i', 'ï': 'i',
+	'ó': 'o', 'ò': 'o', 'ô': 'o', 'õ': 'o', 'ö': 'o',
+	'ú': 'u', 'ù': 'u', 'û': 'u', 'ü': 'u',
+	'ç': 'c', 'ñ': 'n',
+	'Á': 'A', 'À': 'A', 'Â': 'A', 'Ã': 'A', 'Ä': 'A',
+	'É': 'E', 'È': 'E', 'Ê': 'E', 'Ë': 'E',
+	'Í': 'I', 'Ì': 'I', 'Î': 'I', 'Ï': 'I',
+	'Ó': 'O', 'Ò': 'O', 'Ô': 'O', 'Õ': 'O', 'Ö': 'O',
+	'Ú': 'U', 'Ù': 'U', 'Û': 'U', 'Ü': 'U',
+	'Ç': 'C', 'Ñ': 'N',
+}
+
+// Plain returns s with only ASCII characters (see the package doc).
+// Spaces following a dropped emoji are dropped too, so "📊 Summary" becomes
+// "Summary". Other non-ASCII characters become "?".
+func Plain(s string) string {
+	if isASCII(s) {
+		return s
+	}
+
+	var b strings.Builder
+	b.Grow(len(s))
+	dropSpaces := false
+	for _, r := range s {
+		if dropSpaces && r == ' ' {
+			continue
+		}
+		dropped := dropSpaces
+		dropSpaces = false
+
+		switch {
+		case r <= unicode.MaxASCII:
+			b.WriteRune(r)
+		case markers[r] != "":
+			b.WriteString(markers[r])
+		case letters[r] != 0:
+			b.WriteRune(letters[r])
+		case r >= 0x2500 && r <= 0x257F: // Box drawing
+			b.WriteByte(boxDrawing(r))
+		case r == '\u200d' || unicode.Is(unicode.Variation_Selector, r) || unicode.Is(unicode.Mn, r):
+			// Emoji joiners and presentation selectors (e.g., ⚠️ = ⚠ + U+FE0F)
+			dropSpaces = dropped
+		case unicode.Is(unicode.So, r):
+			dropSpaces = true // Decorative emoji
+		default:
+			b.WriteByte('?')
+		}
+	}
+	return b.String()
+}
+
+// boxDrawing returns the ASCII replacement of a box-drawing character.
+func boxDrawing(r rune) byte {
+	switch r {
+	case '─', '━', '═', '╌', '╍', '┄', '┅', '┈', '┉':
+		return '-'
+	case '│', '┃', '║', '╎', '╏', '┆', '┇', '┊', '┋':
+		return '|'
+	default:
+		return '+'
+	}
+}
+
+// isASCII reports whether s has only ASCII characters.
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] > unicode.MaxASCII {
+			return false
+		}
+	}
+	return true
+}
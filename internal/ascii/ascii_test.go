@@ -0,0 +1,55 @@
+package ascii
+
+import "testing"
+
+// TestPlain tests the plain-ASCII rewriting of output text
+func TestPlain(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"ascii unchanged", "Summary: 3 successful", "Summary: 3 successful"},
+		{"status markers", "✅ | ❌ | ⏭️ | ✓ done", "[OK] | [FAIL] | [SKIP] | [OK] done"},
+		{"warning with selector", "⚠️  Validation skipped", "[WARN]  Validation skipped"},
+		{"decorative emoji dropped", "\n📊 Summary: 1 failed", "\nSummary: 1 failed"},
+		{"emoji with selector and spaces", "🖥️  Instâncias por família de SO:", "Instancias por familia de SO:"},
+		{"accents", "Configuração inválida", "Configuracao invalida"},
+		{"arrows and bullets", "   • a → b", "   * a -> b"},
+		{"box drawing", "╭──┬──╮\n│ a│ b│\n╰──┴──╯", "+--+--+\n| a| b|\n+--+--+"},
+		{"unknown character", "日本", "??"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Plain(tt.input); got != tt.want {
+				t.Errorf("Plain(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestFromLocale tests detecting locales without UTF-8
+func TestFromLocale(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		want bool
+	}{
+		{"utf-8 lang", map[string]string{"LANG": "pt_BR.UTF-8"}, false},
+		{"utf8 lang", map[string]string{"LANG": "en_US.utf8"}, false},
+		{"C locale", map[string]string{"LANG": "C"}, true},
+		{"latin1", map[string]string{"LANG": "pt_BR.ISO-8859-1"}, true},
+		{"LC_ALL wins over LANG", map[string]string{"LC_ALL": "POSIX", "LANG": "en_US.UTF-8"}, true},
+		{"LC_CTYPE", map[string]string{"LC_CTYPE": "C.UTF-8"}, false},
+		{"unset", map[string]string{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FromLocale(func(name string) string { return tt.env[name] }); got != tt.want {
+				t.Errorf("FromLocale() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,16 @@
+package cloud
+
+import (
+	"context"
+	"time"
+)
+
+// AgentWaiter is implemented by providers whose command agent registers
+// some time after the instance launches (AWS: SSM agent), so a run started
+// right after launch can wait for it instead of failing validation.
+// This is an optional capability - callers discover it with CapabilitiesOf.
+type AgentWaiter interface {
+	// WaitForAgent blocks until the instance's agent is registered and
+	// online, or timeout expires.
+	WaitForAgent(ctx context.Context, instance *Instance, timeout time.Duration) error
+}
@@ -0,0 +1,65 @@
+package cloud
+
+import (
+	"context"
+	"strings"
+)
+
+// Autoscaling lifecycle states (EC2 Auto Scaling naming, used as
+// AutoScalingMember.LifecycleState values). Pending:Wait and friends are
+// matched by prefix.
+const (
+	LifecycleStatePending     = "Pending"
+	LifecycleStateInService   = "InService"
+	LifecycleStateTerminating = "Terminating"
+	LifecycleStateTerminated  = "Terminated"
+	LifecycleStateDetaching   = "Detaching"
+	LifecycleStateDetached    = "Detached"
+)
+
+// HealthStatusUnhealthy is the health status of members the group is
+// about to replace.
+const HealthStatusUnhealthy = "Unhealthy"
+
+// AutoScalingMember is the membership of an instance in an autoscaling group.
+type AutoScalingMember struct {
+	InstanceID     string // Instance ID
+	Group          string // Autoscaling group name
+	LifecycleState string // e.g. InService, Terminating:Wait
+	HealthStatus   string // Healthy or Unhealthy
+}
+
+// LeavingReason returns why the group is taking the member out of service
+// (terminating, detaching or unhealthy and about to be replaced), "" when
+// it stays in service.
+func (m *AutoScalingMember) LeavingReason() string {
+	for _, state := range []string{LifecycleStateTerminating, LifecycleStateTerminated, LifecycleStateDetaching, LifecycleStateDetached} {
+		if strings.HasPrefix(m.LifecycleState, state) {
+			return "autoscaling group " + m.Group + ": instance is " + m.LifecycleState
+		}
+	}
+	if strings.EqualFold(m.HealthStatus, HealthStatusUnhealthy) {
+		return "autoscaling group " + m.Group + ": instance is unhealthy (being replaced)"
+	}
+	return ""
+}
+
+// Serving reports whether the member is (or is about to be) in service:
+// pending or in service, and healthy.
+func (m *AutoScalingMember) Serving() bool {
+	inService := strings.HasPrefix(m.LifecycleState, LifecycleStatePending) || m.LifecycleState == LifecycleStateInService
+	return inService && !strings.EqualFold(m.HealthStatus, HealthStatusUnhealthy)
+}
+
+// AutoScalingDescriber is implemented by providers whose instances may
+// belong to autoscaling groups. Optional capability, discovered with
+// CapabilitiesOf (see InstanceDescriber).
+type AutoScalingDescriber interface {
+	// DescribeAutoScaling returns the membership of instances keyed by
+	// instance ID. Instances outside any group are absent from the map.
+	DescribeAutoScaling(ctx context.Context, instances []*Instance) (map[string]*AutoScalingMember, error)
+
+	// GroupMembers lists the members of group, in the account and region
+	// of instance (one of its members).
+	GroupMembers(ctx context.Context, instance *Instance, group string) ([]*AutoScalingMember, error)
+}
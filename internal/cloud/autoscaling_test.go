@@ -0,0 +1,30 @@
+package cloud
+
+import "testing"
+
+func TestAutoScalingMember(t *testing.T) {
+	tests := []struct {
+		name        string
+		member      AutoScalingMember
+		wantLeaving bool
+		wantServing bool
+	}{
+		{name: "in service", member: AutoScalingMember{LifecycleState: "InService", HealthStatus: "HEALTHY"}, wantServing: true},
+		{name: "pending", member: AutoScalingMember{LifecycleState: "Pending:Wait", HealthStatus: "Healthy"}, wantServing: true},
+		{name: "terminating", member: AutoScalingMember{LifecycleState: "Terminating:Proceed", HealthStatus: "HEALTHY"}, wantLeaving: true},
+		{name: "detaching", member: AutoScalingMember{LifecycleState: "Detaching", HealthStatus: "HEALTHY"}, wantLeaving: true},
+		{name: "unhealthy", member: AutoScalingMember{LifecycleState: "InService", HealthStatus: "UNHEALTHY"}, wantLeaving: true},
+		{name: "standby", member: AutoScalingMember{LifecycleState: "Standby", HealthStatus: "HEALTHY"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.member.LeavingReason() != ""; got != tt.wantLeaving {
+				t.Errorf("LeavingReason() = %q, want leaving %v", tt.member.LeavingReason(), tt.wantLeaving)
+			}
+			if got := tt.member.Serving(); got != tt.wantServing {
+				t.Errorf("Serving() = %v, want %v", got, tt.wantServing)
+			}
+		})
+	}
+}
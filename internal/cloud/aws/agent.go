@@ -0,0 +1,96 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	"github.com/aws/aws-sdk-go-v2/service/ssm/types"
+
+	"github.com/estudosdevops/opsmaster/internal/cloud"
+	"github.com/estudosdevops/opsmaster/internal/logger"
+)
+
+// agentPollInterval is how often WaitForAgent polls DescribeInstanceInformation.
+const agentPollInterval = 10 * time.Second
+
+// instanceInformationDescriber is the part of the SSM client used to read
+// an instance's registration.
+type instanceInformationDescriber interface {
+	DescribeInstanceInformation(ctx context.Context, params *ssm.DescribeInstanceInformationInput, optFns ...func(*ssm.Options)) (*ssm.DescribeInstanceInformationOutput, error)
+}
+
+// describeInstanceInformation returns the SSM registration of an instance,
+// or nil if the instance is not registered (yet).
+func describeInstanceInformation(ctx context.Context, client instanceInformationDescriber, instanceID string) (*types.InstanceInformation, error) {
+	output, err := client.DescribeInstanceInformation(ctx, &ssm.DescribeInstanceInformationInput{
+		Filters: []types.InstanceInformationStringFilter{
+			{
+				Key:    aws.String("InstanceIds"),
+				Values: []string{instanceID},
+			},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("SSM API error for instance %s: %w", instanceID, err)
+	}
+	if len(output.InstanceInformationList) == 0 {
+		return nil, nil
+	}
+	return &output.InstanceInformationList[0], nil
+}
+
+// WaitForAgent polls SSM until the instance is registered with ping status
+// Online (--wait-for-ssm). The SSM agent of a freshly launched instance
+// registers minutes after launch, so validation would fail at once.
+// Implements cloud.AgentWaiter.
+func (p *AWSProvider) WaitForAgent(ctx context.Context, instance *cloud.Instance, timeout time.Duration) error {
+	client, err := p.sessionManager.GetSSMClient(ctx, getProfileForInstance(instance), instance.Region)
+	if err != nil {
+		return fmt.Errorf("failed to get SSM client: %w", err)
+	}
+	return waitForAgent(ctx, client, instance.ID, timeout, agentPollInterval)
+}
+
+// waitForAgent polls the registration of instanceID every interval until it
+// is Online or timeout expires.
+func waitForAgent(ctx context.Context, client instanceInformationDescriber, instanceID string, timeout, interval time.Duration) error {
+	log := logger.FromContext(ctx)
+	waitCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	start := time.Now()
+	lastStatus := "not registered"
+	for {
+		info, err := describeInstanceInformation(waitCtx, client, instanceID)
+		switch {
+		case err != nil:
+			// Transient API errors are retried on next poll
+			log.Debug("Failed to poll SSM registration", "error", err)
+		case info == nil:
+			lastStatus = "not registered"
+		case info.PingStatus == types.PingStatusOnline:
+			if waited := time.Since(start); waited >= interval {
+				log.Info("Instance online in SSM", "waited", waited.Round(time.Second))
+			}
+			return nil
+		default:
+			lastStatus = string(info.PingStatus)
+		}
+
+		select {
+		case <-waitCtx.Done():
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return fmt.Errorf("instance %s not online in SSM after %s (last status: %s)", instanceID, timeout, lastStatus)
+		case <-ticker.C:
+			log.Debug("Waiting for instance to come online in SSM", "status", lastStatus)
+		}
+	}
+}
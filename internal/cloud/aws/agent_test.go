@@ -0,0 +1,78 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	"github.com/aws/aws-sdk-go-v2/service/ssm/types"
+)
+
+// stubRegistration answers DescribeInstanceInformation with a scripted
+// sequence of ping statuses ("" = not registered); the last one repeats.
+type stubRegistration struct {
+	mu       sync.Mutex
+	statuses []types.PingStatus
+	errs     []error
+	calls    int
+}
+
+func (s *stubRegistration) DescribeInstanceInformation(_ context.Context, _ *ssm.DescribeInstanceInformationInput, _ ...func(*ssm.Options)) (*ssm.DescribeInstanceInformationOutput, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := min(s.calls, len(s.statuses)-1)
+	s.calls++
+	if i < len(s.errs) && s.errs[i] != nil {
+		return nil, s.errs[i]
+	}
+	output := &ssm.DescribeInstanceInformationOutput{}
+	if s.statuses[i] != "" {
+		output.InstanceInformationList = []types.InstanceInformation{{PingStatus: s.statuses[i]}}
+	}
+	return output, nil
+}
+
+// TestWaitForAgent tests polling the SSM registration of a new instance.
+func TestWaitForAgent(t *testing.T) {
+	tests := []struct {
+		name      string
+		stub      *stubRegistration
+		wantErr   string
+		wantCalls int
+	}{
+		{"already online", &stubRegistration{statuses: []types.PingStatus{types.PingStatusOnline}}, "", 1},
+		{"registers late", &stubRegistration{statuses: []types.PingStatus{"", "", types.PingStatusOnline}}, "", 3},
+		{"api error retried", &stubRegistration{statuses: []types.PingStatus{"", types.PingStatusOnline}, errs: []error{errors.New("ThrottlingException")}}, "", 2},
+		{"never registers", &stubRegistration{statuses: []types.PingStatus{""}}, "not online in SSM after 50ms (last status: not registered)", 0},
+		{"connection lost", &stubRegistration{statuses: []types.PingStatus{types.PingStatusConnectionLost}}, "last status: ConnectionLost", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := waitForAgent(context.Background(), tt.stub, "i-test", 50*time.Millisecond, time.Millisecond)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if tt.stub.calls != tt.wantCalls {
+					t.Errorf("calls = %d, want %d", tt.stub.calls, tt.wantCalls)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := waitForAgent(ctx, &stubRegistration{statuses: []types.PingStatus{""}}, "i-test", time.Minute, time.Millisecond)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("error = %v, want context.Canceled", err)
+	}
+}
@@ -0,0 +1,163 @@
+package aws
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+
+	"github.com/estudosdevops/opsmaster/internal/cloud"
+)
+
+const (
+	// autoScalingAPIVersion is the EC2 Auto Scaling Query API version.
+	autoScalingAPIVersion = "2011-01-01"
+
+	// autoScalingBatchSize is the maximum number of instance IDs per
+	// DescribeAutoScalingInstances call.
+	autoScalingBatchSize = 50
+)
+
+// autoScalingMember is an instance in the XML of the Auto Scaling API.
+type autoScalingMember struct {
+	InstanceID     string `xml:"InstanceId"`
+	Group          string `xml:"AutoScalingGroupName"`
+	LifecycleState string `xml:"LifecycleState"`
+	HealthStatus   string `xml:"HealthStatus"`
+}
+
+// member converts m, whose group is group when the response doesn't carry it.
+func (m autoScalingMember) member(group string) *cloud.AutoScalingMember {
+	if m.Group != "" {
+		group = m.Group
+	}
+	return &cloud.AutoScalingMember{
+		InstanceID:     m.InstanceID,
+		Group:          group,
+		LifecycleState: m.LifecycleState,
+		HealthStatus:   m.HealthStatus,
+	}
+}
+
+// autoScalingAPI calls the Auto Scaling Query API of one account and region
+// (opsmaster doesn't depend on its SDK client).
+type autoScalingAPI struct {
+	api *signedClient
+}
+
+// newAutoScalingAPI creates the API client for the profile and region of instance.
+func newAutoScalingAPI(ctx context.Context, instance *cloud.Instance) (*autoScalingAPI, error) {
+	sc, err := loadSigningConfig(ctx, getProfileForInstance(instance), instance.Region)
+	if err != nil {
+		return nil, err
+	}
+	return &autoScalingAPI{api: newSignedClient(sc, "autoscaling")}, nil
+}
+
+// describeInstances calls DescribeAutoScalingInstances for up to
+// autoScalingBatchSize IDs, following NextToken.
+func (a *autoScalingAPI) describeInstances(ctx context.Context, ids []string) ([]*cloud.AutoScalingMember, error) {
+	var members []*cloud.AutoScalingMember
+	nextToken := ""
+	for {
+		form := url.Values{
+			"Action":  {"DescribeAutoScalingInstances"},
+			"Version": {autoScalingAPIVersion},
+		}
+		for i, id := range ids {
+			form.Set("InstanceIds.member."+strconv.Itoa(i+1), id)
+		}
+		if nextToken != "" {
+			form.Set("NextToken", nextToken)
+		}
+
+		var output struct {
+			Instances []autoScalingMember `xml:"DescribeAutoScalingInstancesResult>AutoScalingInstances>member"`
+			NextToken string              `xml:"DescribeAutoScalingInstancesResult>NextToken"`
+		}
+		if err := a.api.callQuery(ctx, form, &output); err != nil {
+			return nil, err
+		}
+		for _, instance := range output.Instances {
+			members = append(members, instance.member(""))
+		}
+		if output.NextToken == "" {
+			return members, nil
+		}
+		nextToken = output.NextToken
+	}
+}
+
+// groupMembers calls DescribeAutoScalingGroups for group.
+func (a *autoScalingAPI) groupMembers(ctx context.Context, group string) ([]*cloud.AutoScalingMember, error) {
+	form := url.Values{
+		"Action":                         {"DescribeAutoScalingGroups"},
+		"Version":                        {autoScalingAPIVersion},
+		"AutoScalingGroupNames.member.1": {group},
+	}
+	var output struct {
+		Groups []struct {
+			Instances []autoScalingMember `xml:"Instances>member"`
+		} `xml:"DescribeAutoScalingGroupsResult>AutoScalingGroups>member"`
+	}
+	if err := a.api.callQuery(ctx, form, &output); err != nil {
+		return nil, err
+	}
+
+	var members []*cloud.AutoScalingMember
+	for _, g := range output.Groups {
+		for _, instance := range g.Instances {
+			members = append(members, instance.member(group))
+		}
+	}
+	return members, nil
+}
+
+// DescribeAutoScaling returns the autoscaling group membership of instances.
+// Implements cloud.AutoScalingDescriber. Instances are grouped by profile and
+// region; not cached, since membership changes during a run is the point.
+func (p *AWSProvider) DescribeAutoScaling(ctx context.Context, instances []*cloud.Instance) (map[string]*cloud.AutoScalingMember, error) {
+	result := make(map[string]*cloud.AutoScalingMember)
+	for _, group := range groupByProfileRegion(instances) {
+		api, err := newAutoScalingAPI(ctx, group[0])
+		if err != nil {
+			return result, err
+		}
+		for _, batch := range batchInstances(group, autoScalingBatchSize) {
+			ids := make([]string, 0, len(batch))
+			for _, instance := range batch {
+				ids = append(ids, instance.ID)
+			}
+
+			var members []*cloud.AutoScalingMember
+			err := p.ec2Retryer.Do(ctx, func() error {
+				var describeErr error
+				members, describeErr = api.describeInstances(ctx, ids)
+				return describeErr
+			})
+			if err != nil {
+				return result, err
+			}
+			for _, member := range members {
+				result[member.InstanceID] = member
+			}
+		}
+	}
+	return result, nil
+}
+
+// GroupMembers lists the members of group in the account and region of
+// instance. Implements cloud.AutoScalingDescriber.
+func (p *AWSProvider) GroupMembers(ctx context.Context, instance *cloud.Instance, group string) ([]*cloud.AutoScalingMember, error) {
+	api, err := newAutoScalingAPI(ctx, instance)
+	if err != nil {
+		return nil, err
+	}
+
+	var members []*cloud.AutoScalingMember
+	err = p.ec2Retryer.Do(ctx, func() error {
+		var describeErr error
+		members, describeErr = api.groupMembers(ctx, group)
+		return describeErr
+	})
+	return members, err
+}
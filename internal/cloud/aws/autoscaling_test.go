@@ -0,0 +1,97 @@
+package aws
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/estudosdevops/opsmaster/internal/cloud"
+)
+
+// TestAutoScalingAPI tests the Auto Scaling Query API wire format
+func TestAutoScalingAPI(t *testing.T) {
+	t.Run("describe instances follows NextToken", func(t *testing.T) {
+		var forms []url.Values
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			form, _ := url.ParseQuery(string(body))
+			forms = append(forms, form)
+			if form.Get("NextToken") == "" {
+				_, _ = io.WriteString(w, `<DescribeAutoScalingInstancesResponse><DescribeAutoScalingInstancesResult>
+<AutoScalingInstances><member><InstanceId>i-1</InstanceId><AutoScalingGroupName>web</AutoScalingGroupName>
+<LifecycleState>Terminating:Wait</LifecycleState><HealthStatus>HEALTHY</HealthStatus></member></AutoScalingInstances>
+<NextToken>page2</NextToken></DescribeAutoScalingInstancesResult></DescribeAutoScalingInstancesResponse>`)
+				return
+			}
+			_, _ = io.WriteString(w, `<DescribeAutoScalingInstancesResponse><DescribeAutoScalingInstancesResult>
+<AutoScalingInstances><member><InstanceId>i-2</InstanceId><AutoScalingGroupName>web</AutoScalingGroupName>
+<LifecycleState>InService</LifecycleState><HealthStatus>UNHEALTHY</HealthStatus></member></AutoScalingInstances>
+</DescribeAutoScalingInstancesResult></DescribeAutoScalingInstancesResponse>`)
+		}))
+		defer server.Close()
+		api := &autoScalingAPI{api: newTestSignedClient(server, "autoscaling")}
+
+		members, err := api.describeInstances(context.Background(), []string{"i-1", "i-2", "i-3"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(forms) != 2 || forms[0].Get("Action") != "DescribeAutoScalingInstances" ||
+			forms[0].Get("InstanceIds.member.3") != "i-3" || forms[1].Get("NextToken") != "page2" {
+			t.Fatalf("unexpected requests %v", forms)
+		}
+		want := []cloud.AutoScalingMember{
+			{InstanceID: "i-1", Group: "web", LifecycleState: "Terminating:Wait", HealthStatus: "HEALTHY"},
+			{InstanceID: "i-2", Group: "web", LifecycleState: "InService", HealthStatus: "UNHEALTHY"},
+		}
+		if len(members) != len(want) {
+			t.Fatalf("got %d members, want %d", len(members), len(want))
+		}
+		for i := range want {
+			if *members[i] != want[i] {
+				t.Errorf("member %d = %+v, want %+v", i, *members[i], want[i])
+			}
+		}
+	})
+
+	t.Run("group members carry the group name", func(t *testing.T) {
+		var form url.Values
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			form, _ = url.ParseQuery(string(body))
+			_, _ = io.WriteString(w, `<DescribeAutoScalingGroupsResponse><DescribeAutoScalingGroupsResult><AutoScalingGroups>
+<member><AutoScalingGroupName>web</AutoScalingGroupName><Instances>
+<member><InstanceId>i-new</InstanceId><LifecycleState>Pending</LifecycleState><HealthStatus>Healthy</HealthStatus></member>
+</Instances></member></AutoScalingGroups></DescribeAutoScalingGroupsResult></DescribeAutoScalingGroupsResponse>`)
+		}))
+		defer server.Close()
+		api := &autoScalingAPI{api: newTestSignedClient(server, "autoscaling")}
+
+		members, err := api.groupMembers(context.Background(), "web")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if form.Get("AutoScalingGroupNames.member.1") != "web" {
+			t.Errorf("unexpected form %v", form)
+		}
+		if len(members) != 1 || members[0].InstanceID != "i-new" || members[0].Group != "web" {
+			t.Errorf("unexpected members %+v", members)
+		}
+	})
+
+	t.Run("query API error", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = io.WriteString(w, `<ErrorResponse><Error><Code>AccessDenied</Code><Message>nope</Message></Error></ErrorResponse>`)
+		}))
+		defer server.Close()
+		api := &autoScalingAPI{api: newTestSignedClient(server, "autoscaling")}
+
+		_, err := api.groupMembers(context.Background(), "web")
+		if !IsAPIError(err, "AccessDenied") {
+			t.Errorf("error = %v, want AccessDenied", err)
+		}
+	})
+}
@@ -0,0 +1,148 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+
+	"github.com/estudosdevops/opsmaster/internal/cloud"
+)
+
+// describeBatchSize is the maximum number of instance IDs per DescribeInstances call.
+const describeBatchSize = 100
+
+// DescribeInstances fetches state, platform and tags for instances.
+// Implements cloud.InstanceDescriber. Fresh entries are served from the
+// metadata cache; misses are fetched in batches grouped by profile and region.
+func (p *AWSProvider) DescribeInstances(ctx context.Context, instances []*cloud.Instance) (map[string]*cloud.InstanceInfo, error) {
+	result := make(map[string]*cloud.InstanceInfo, len(instances))
+
+	// Group cache misses by profile+region (one EC2 client per group)
+	var misses []*cloud.Instance
+	for _, instance := range instances {
+		if info, ok := p.metadataCache.Get(instance); ok {
+			result[instance.ID] = info
+			continue
+		}
+		misses = append(misses, instance)
+	}
+	groups := groupByProfileRegion(misses)
+
+	p.log.Debug("Describing instances",
+		"total", len(instances),
+		"cache_hits", len(result),
+		"groups", len(groups))
+
+	for _, group := range groups {
+		for _, batch := range batchInstances(group, describeBatchSize) {
+			var infos map[string]*cloud.InstanceInfo
+			err := p.ec2Retryer.Do(ctx, func() error {
+				var describeErr error
+				infos, describeErr = p.describeInstancesInternal(ctx, batch)
+				return describeErr
+			})
+			if err != nil {
+				return result, err
+			}
+
+			for _, instance := range batch {
+				if info, ok := infos[instance.ID]; ok {
+					p.metadataCache.Put(instance, info)
+					result[instance.ID] = info
+				}
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// groupByProfileRegion groups instances sharing the same EC2 client (profile+region).
+func groupByProfileRegion(instances []*cloud.Instance) map[string][]*cloud.Instance {
+	groups := make(map[string][]*cloud.Instance)
+	for _, instance := range instances {
+		key := getProfileForInstance(instance) + "|" + instance.Region
+		groups[key] = append(groups[key], instance)
+	}
+	return groups
+}
+
+// batchInstances splits instances into batches of at most size elements.
+func batchInstances(instances []*cloud.Instance, size int) [][]*cloud.Instance {
+	var batches [][]*cloud.Instance
+	for start := 0; start < len(instances); start += size {
+		end := min(start+size, len(instances))
+		batches = append(batches, instances[start:end])
+	}
+	return batches
+}
+
+// describeInstancesInternal performs a single DescribeInstances call without retry.
+// All instances in the batch must share the same profile and region.
+func (p *AWSProvider) describeInstancesInternal(ctx context.Context, batch []*cloud.Instance) (map[string]*cloud.InstanceInfo, error) {
+	first := batch[0]
+	ec2Client, err := p.sessionManager.GetEC2Client(ctx, getProfileForInstance(first), first.Region)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get EC2 client: %w", err)
+	}
+
+	// Filter by instance-id instead of InstanceIds: unknown IDs are simply
+	// absent from the response rather than failing the whole batch.
+	input := &ec2.DescribeInstancesInput{
+		Filters: []ec2types.Filter{
+			{
+				Name:   aws.String("instance-id"),
+				Values: instanceIDs(batch),
+			},
+		},
+	}
+
+	infos := make(map[string]*cloud.InstanceInfo, len(batch))
+	fetchedAt := time.Now()
+
+	paginator := ec2.NewDescribeInstancesPaginator(ec2Client, input)
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to describe instances in %s: %w", first.Region, err)
+		}
+
+		for _, reservation := range output.Reservations {
+			for i := range reservation.Instances {
+				info := toInstanceInfo(&reservation.Instances[i])
+				info.FetchedAt = fetchedAt
+				infos[info.ID] = info
+			}
+		}
+	}
+
+	return infos, nil
+}
+
+// toInstanceInfo converts an EC2 instance description to cloud.InstanceInfo.
+func toInstanceInfo(instance *ec2types.Instance) *cloud.InstanceInfo {
+	info := &cloud.InstanceInfo{
+		ID:       aws.ToString(instance.InstanceId),
+		Platform: "linux",
+		Tags:     make(map[string]string, len(instance.Tags)),
+	}
+
+	if instance.State != nil {
+		info.State = string(instance.State.Name)
+	}
+
+	// EC2 only sets Platform for Windows instances
+	if instance.Platform == ec2types.PlatformValuesWindows {
+		info.Platform = cloud.PlatformWindows
+	}
+
+	for _, tag := range instance.Tags {
+		info.Tags[aws.ToString(tag.Key)] = aws.ToString(tag.Value)
+	}
+
+	return info
+}
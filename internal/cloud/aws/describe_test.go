@@ -0,0 +1,92 @@
+package aws
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+
+	"github.com/estudosdevops/opsmaster/internal/cloud"
+)
+
+// TestToInstanceInfo tests conversion from EC2 instance description
+func TestToInstanceInfo(t *testing.T) {
+	tests := []struct {
+		name             string
+		instance         ec2types.Instance
+		expectedState    string
+		expectedPlatform string
+	}{
+		{
+			name: "running linux instance",
+			instance: ec2types.Instance{
+				InstanceId: aws.String("i-123"),
+				State:      &ec2types.InstanceState{Name: ec2types.InstanceStateNameRunning},
+				Tags:       []ec2types.Tag{{Key: aws.String("Name"), Value: aws.String("web")}},
+			},
+			expectedState:    "running",
+			expectedPlatform: "linux",
+		},
+		{
+			name: "stopped windows instance",
+			instance: ec2types.Instance{
+				InstanceId: aws.String("i-456"),
+				State:      &ec2types.InstanceState{Name: ec2types.InstanceStateNameStopped},
+				Platform:   ec2types.PlatformValuesWindows,
+			},
+			expectedState:    "stopped",
+			expectedPlatform: "windows",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info := toInstanceInfo(&tt.instance)
+
+			if info.ID != aws.ToString(tt.instance.InstanceId) {
+				t.Errorf("expected ID '%s', got '%s'", aws.ToString(tt.instance.InstanceId), info.ID)
+			}
+			if info.State != tt.expectedState {
+				t.Errorf("expected state '%s', got '%s'", tt.expectedState, info.State)
+			}
+			if info.Platform != tt.expectedPlatform {
+				t.Errorf("expected platform '%s', got '%s'", tt.expectedPlatform, info.Platform)
+			}
+			if len(info.Tags) != len(tt.instance.Tags) {
+				t.Errorf("expected %d tags, got %d", len(tt.instance.Tags), len(info.Tags))
+			}
+		})
+	}
+}
+
+// TestAWSProvider_DescribeInstances_CacheHit tests that cached metadata
+// is served without calling the EC2 API
+func TestAWSProvider_DescribeInstances_CacheHit(t *testing.T) {
+	// ARRANGE
+	provider := NewAWSProvider()
+	instance := &cloud.Instance{ID: "i-123", Account: "111111111111", Region: "us-east-1"}
+	provider.metadataCache.Put(instance, &cloud.InstanceInfo{
+		ID:        "i-123",
+		State:     "running",
+		Tags:      map[string]string{"puppet": "true"},
+		FetchedAt: time.Now(),
+	})
+
+	// ACT
+	infos, err := provider.DescribeInstances(context.Background(), []*cloud.Instance{instance})
+
+	// ASSERT
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if infos["i-123"] == nil || infos["i-123"].State != "running" {
+		t.Errorf("expected cached info, got %v", infos["i-123"])
+	}
+
+	found, err := provider.HasTag(context.Background(), instance, "puppet", "true")
+	if err != nil || !found {
+		t.Errorf("expected HasTag served from cache, got found=%v err=%v", found, err)
+	}
+}
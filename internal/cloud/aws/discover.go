@@ -0,0 +1,455 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+
+	"github.com/estudosdevops/opsmaster/internal/cloud"
+)
+
+const (
+	// API versions and targets of the services called by Discover
+	// (opsmaster doesn't depend on their SDK clients).
+	ec2APIVersion             = "2016-11-15"
+	stsAPIVersion             = "2011-06-15"
+	organizationsTarget       = "AWSOrganizationsV20161128."
+	organizationsContentType  = "application/x-amz-json-1.1"
+	organizationsSigningScope = "us-east-1" // Organizations is a global service signed in us-east-1
+
+	// DefaultDiscoveryRole is the role created by AWS Organizations in every
+	// member account, assumed by Discover to search it.
+	DefaultDiscoveryRole = "OrganizationAccountAccessRole"
+
+	// discoverySessionName identifies the assumed-role sessions in CloudTrail.
+	discoverySessionName = "opsmaster-discovery"
+
+	// discoveryConcurrency is how many accounts are searched at once.
+	discoveryConcurrency = 8
+
+	// accountActive is the status of accounts that can be searched.
+	accountActive = "ACTIVE"
+)
+
+// DiscoveryOptions selects the instances found by Discover.
+type DiscoveryOptions struct {
+	Profile string      // AWS profile of the caller ("" = default credential chain)
+	Regions []string    // Regions searched in every account (default: profile region)
+	Tags    []TagFilter // Tags the instances must have, all of them (--discover-tags)
+
+	// Organization searches every active account of the AWS Organization
+	// instead of the caller's account only. The caller must be the
+	// management account or a delegated administrator.
+	Organization bool
+
+	// OrganizationalUnit restricts the organization accounts to an OU and
+	// its child OUs (e.g. ou-ab12-34cd5678; implies Organization)
+	OrganizationalUnit string
+
+	// Role is the role assumed in each member account (default:
+	// OrganizationAccountAccessRole). The caller's account is searched with
+	// the caller's credentials.
+	Role string
+
+	// TagColumns are tags copied to instance metadata (CSV columns),
+	// besides Name copied to the name column.
+	TagColumns []string
+}
+
+// TagFilter matches instances having tag Key with one of Values
+// (none = any value). Values accept the * and ? wildcards of EC2 filters.
+type TagFilter struct {
+	Key    string
+	Values []string
+}
+
+// ParseTagFilters parses --discover-tags entries: "key=value" or "key"
+// (any value). Repeating a key accepts any of its values.
+func ParseTagFilters(entries []string) ([]TagFilter, error) {
+	var filters []TagFilter
+	index := make(map[string]int)
+	for _, entry := range entries {
+		key, value, hasValue := strings.Cut(entry, "=")
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		if key == "" {
+			return nil, fmt.Errorf("invalid tag filter %q: use key=value or key", entry)
+		}
+		i, ok := index[key]
+		if !ok {
+			i = len(filters)
+			index[key] = i
+			filters = append(filters, TagFilter{Key: key})
+		}
+		if hasValue {
+			if value == "" {
+				return nil, fmt.Errorf("invalid tag filter %q: empty value (use %s to match any value)", entry, key)
+			}
+			filters[i].Values = append(filters[i].Values, value)
+		}
+	}
+	return filters, nil
+}
+
+// Validate checks the options.
+func (o DiscoveryOptions) Validate() error {
+	if len(o.Tags) == 0 {
+		return errors.New("discovery requires at least one tag filter (--discover-tags)")
+	}
+	if o.OrganizationalUnit != "" && !strings.HasPrefix(o.OrganizationalUnit, "ou-") && !strings.HasPrefix(o.OrganizationalUnit, "r-") {
+		return fmt.Errorf("invalid organizational unit %q: use an OU ID (ou-...) or the root ID (r-...)", o.OrganizationalUnit)
+	}
+	if o.Role != "" && strings.Contains(o.Role, ":") {
+		return fmt.Errorf("invalid discovery role %q: use the role name (the ARN is built for each account)", o.Role)
+	}
+	return nil
+}
+
+// organization reports whether organization accounts are enumerated.
+func (o DiscoveryOptions) organization() bool {
+	return o.Organization || o.OrganizationalUnit != ""
+}
+
+// role returns the role assumed in member accounts.
+func (o DiscoveryOptions) role() string {
+	if o.Role == "" {
+		return DefaultDiscoveryRole
+	}
+	return o.Role
+}
+
+// DiscoveryFailure is an account or account/region that could not be searched.
+type DiscoveryFailure struct {
+	Account string
+	Region  string // "" = the whole account (e.g. role not assumable)
+	Err     error
+}
+
+func (f DiscoveryFailure) Error() string {
+	if f.Region == "" {
+		return fmt.Sprintf("account %s: %v", f.Account, f.Err)
+	}
+	return fmt.Sprintf("account %s, region %s: %v", f.Account, f.Region, f.Err)
+}
+
+// DiscoveryResult is the merged inventory found by Discover.
+type DiscoveryResult struct {
+	Instances []*cloud.Instance  // Sorted by account, region and ID
+	Accounts  []string           // Accounts in scope, sorted
+	Regions   []string           // Regions searched in each account
+	Failures  []DiscoveryFailure // Accounts/regions not searched, sorted
+}
+
+// Discover finds the instances with the tags of opts in every account and
+// region in scope and merges them into one inventory. With an organization
+// scope, the active accounts are listed from AWS Organizations and a role
+// is assumed in each one. Accounts or regions that fail are reported in
+// DiscoveryResult.Failures; the error is for failures of the whole run
+// (credentials, listing the organization).
+func Discover(ctx context.Context, opts DiscoveryOptions) (*DiscoveryResult, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	sc, err := loadSigningConfig(ctx, opts.Profile, "")
+	if err != nil {
+		return nil, err
+	}
+	d := &discoverer{opts: opts, caller: sc, newClient: newSignedClient}
+	return d.run(ctx)
+}
+
+// discoverer runs a discovery. newClient builds the service clients, so
+// tests can point them at a test server.
+type discoverer struct {
+	opts      DiscoveryOptions
+	caller    *signingConfig
+	newClient func(sc *signingConfig, service string) *signedClient
+}
+
+// callerIdentity is the GetCallerIdentity result.
+type callerIdentity struct {
+	Account string `xml:"GetCallerIdentityResult>Account"`
+	ARN     string `xml:"GetCallerIdentityResult>Arn"`
+}
+
+// partition returns the ARN partition (aws, aws-cn, aws-us-gov).
+func (c callerIdentity) partition() string {
+	if parts := strings.SplitN(c.ARN, ":", 3); len(parts) == 3 && parts[1] != "" {
+		return parts[1]
+	}
+	return "aws"
+}
+
+func (d *discoverer) run(ctx context.Context) (*DiscoveryResult, error) {
+	var identity callerIdentity
+	form := url.Values{"Action": {"GetCallerIdentity"}, "Version": {stsAPIVersion}}
+	if err := d.newClient(d.caller, "sts").callQuery(ctx, form, &identity); err != nil {
+		return nil, fmt.Errorf("failed to get the caller identity: %w", err)
+	}
+
+	accounts := []string{identity.Account}
+	if d.opts.organization() {
+		var err error
+		if accounts, err = d.organizationAccounts(ctx); err != nil {
+			return nil, err
+		}
+	}
+	regions := d.opts.Regions
+	if len(regions) == 0 {
+		regions = []string{d.caller.region}
+	}
+
+	result := &DiscoveryResult{Accounts: accounts, Regions: regions}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, discoveryConcurrency)
+	for _, account := range accounts {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			instances, failures := d.searchAccount(ctx, identity, account, regions)
+			mu.Lock()
+			defer mu.Unlock()
+			result.Instances = append(result.Instances, instances...)
+			result.Failures = append(result.Failures, failures...)
+		}()
+	}
+	wg.Wait()
+
+	slices.SortFunc(result.Instances, func(a, b *cloud.Instance) int {
+		return strings.Compare(a.Account+"/"+a.Region+"/"+a.ID, b.Account+"/"+b.Region+"/"+b.ID)
+	})
+	slices.SortFunc(result.Failures, func(a, b DiscoveryFailure) int {
+		return strings.Compare(a.Account+"/"+a.Region, b.Account+"/"+b.Region)
+	})
+	return result, ctx.Err()
+}
+
+// searchAccount searches every region of account, assuming the discovery
+// role unless it is the caller's account.
+func (d *discoverer) searchAccount(ctx context.Context, identity callerIdentity, account string, regions []string) ([]*cloud.Instance, []DiscoveryFailure) {
+	creds := d.caller.credentials
+	if account != identity.Account {
+		roleARN := "arn:" + identity.partition() + ":iam::" + account + ":role/" + d.opts.role()
+		assumed, err := d.assumeRole(ctx, roleARN)
+		if err != nil {
+			return nil, []DiscoveryFailure{{Account: account, Err: err}}
+		}
+		creds = assumed
+	}
+
+	var instances []*cloud.Instance
+	var failures []DiscoveryFailure
+	for _, region := range regions {
+		found, err := d.describeInstances(ctx, &signingConfig{credentials: creds, region: region}, account, region)
+		if err != nil {
+			failures = append(failures, DiscoveryFailure{Account: account, Region: region, Err: err})
+			continue
+		}
+		instances = append(instances, found...)
+	}
+	return instances, failures
+}
+
+// assumeRole returns temporary credentials of roleARN (STS AssumeRole).
+func (d *discoverer) assumeRole(ctx context.Context, roleARN string) (aws.Credentials, error) {
+	form := url.Values{
+		"Action":          {"AssumeRole"},
+		"Version":         {stsAPIVersion},
+		"RoleArn":         {roleARN},
+		"RoleSessionName": {discoverySessionName},
+	}
+	var output struct {
+		AccessKeyID     string    `xml:"AssumeRoleResult>Credentials>AccessKeyId"`
+		SecretAccessKey string    `xml:"AssumeRoleResult>Credentials>SecretAccessKey"`
+		SessionToken    string    `xml:"AssumeRoleResult>Credentials>SessionToken"`
+		Expiration      time.Time `xml:"AssumeRoleResult>Credentials>Expiration"`
+	}
+	if err := d.newClient(d.caller, "sts").callQuery(ctx, form, &output); err != nil {
+		return aws.Credentials{}, fmt.Errorf("failed to assume role %s: %w", roleARN, err)
+	}
+	return aws.Credentials{
+		AccessKeyID:     output.AccessKeyID,
+		SecretAccessKey: output.SecretAccessKey,
+		SessionToken:    output.SessionToken,
+		CanExpire:       true,
+		Expires:         output.Expiration,
+	}, nil
+}
+
+// organizationAccount is an account in the Organizations API.
+type organizationAccount struct {
+	ID     string `json:"Id"`
+	Status string `json:"Status"`
+}
+
+// organizationAccounts lists the active accounts of the organization, or
+// of the OU and its child OUs, sorted.
+func (d *discoverer) organizationAccounts(ctx context.Context) ([]string, error) {
+	api := d.newClient(&signingConfig{credentials: d.caller.credentials, region: organizationsSigningScope}, "organizations")
+
+	var all []organizationAccount
+	var err error
+	if d.opts.OrganizationalUnit == "" {
+		all, err = listOrganizationAccounts(ctx, api, "ListAccounts", nil)
+	} else {
+		all, err = d.unitAccounts(ctx, api, d.opts.OrganizationalUnit)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list AWS Organizations accounts: %w", err)
+	}
+
+	var accounts []string
+	for _, account := range all {
+		if account.Status == accountActive && !slices.Contains(accounts, account.ID) {
+			accounts = append(accounts, account.ID)
+		}
+	}
+	slices.Sort(accounts)
+	return accounts, nil
+}
+
+// unitAccounts lists the accounts of an OU (or root) and of its child OUs.
+func (d *discoverer) unitAccounts(ctx context.Context, api *signedClient, parent string) ([]organizationAccount, error) {
+	accounts, err := listOrganizationAccounts(ctx, api, "ListAccountsForParent", map[string]string{"ParentId": parent})
+	if err != nil {
+		return nil, err
+	}
+
+	nextToken := ""
+	for {
+		input := map[string]string{"ParentId": parent}
+		if nextToken != "" {
+			input["NextToken"] = nextToken
+		}
+		var output struct {
+			Units []struct {
+				ID string `json:"Id"`
+			} `json:"OrganizationalUnits"`
+			NextToken string `json:"NextToken"`
+		}
+		if err := api.callJSON(ctx, organizationsContentType, organizationsTarget+"ListOrganizationalUnitsForParent", input, &output); err != nil {
+			return nil, err
+		}
+		for _, unit := range output.Units {
+			children, err := d.unitAccounts(ctx, api, unit.ID)
+			if err != nil {
+				return nil, err
+			}
+			accounts = append(accounts, children...)
+		}
+		if output.NextToken == "" {
+			return accounts, nil
+		}
+		nextToken = output.NextToken
+	}
+}
+
+// listOrganizationAccounts calls an account listing operation, following NextToken.
+func listOrganizationAccounts(ctx context.Context, api *signedClient, operation string, input map[string]string) ([]organizationAccount, error) {
+	var accounts []organizationAccount
+	request := make(map[string]string, len(input)+1)
+	for key, value := range input {
+		request[key] = value
+	}
+	for {
+		var output struct {
+			Accounts  []organizationAccount `json:"Accounts"`
+			NextToken string                `json:"NextToken"`
+		}
+		if err := api.callJSON(ctx, organizationsContentType, organizationsTarget+operation, request, &output); err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, output.Accounts...)
+		if output.NextToken == "" {
+			return accounts, nil
+		}
+		request["NextToken"] = output.NextToken
+	}
+}
+
+// ec2Instance is an instance in the XML of the EC2 Query API.
+type ec2Instance struct {
+	ID   string `xml:"instanceId"`
+	Tags []struct {
+		Key   string `xml:"key"`
+		Value string `xml:"value"`
+	} `xml:"tagSet>item"`
+}
+
+// describeInstances calls EC2 DescribeInstances with the tag filters,
+// skipping terminated instances, following NextToken.
+func (d *discoverer) describeInstances(ctx context.Context, sc *signingConfig, account, region string) ([]*cloud.Instance, error) {
+	api := d.newClient(sc, "ec2")
+	form := url.Values{
+		"Action":  {"DescribeInstances"},
+		"Version": {ec2APIVersion},
+	}
+	filter := 0
+	addFilter := func(name string, values []string) {
+		filter++
+		prefix := "Filter." + strconv.Itoa(filter) + "."
+		form.Set(prefix+"Name", name)
+		for i, value := range values {
+			form.Set(prefix+"Value."+strconv.Itoa(i+1), value)
+		}
+	}
+	for _, tag := range d.opts.Tags {
+		if len(tag.Values) == 0 {
+			addFilter("tag-key", []string{tag.Key})
+		} else {
+			addFilter("tag:"+tag.Key, tag.Values)
+		}
+	}
+	addFilter("instance-state-name", []string{
+		cloud.InstanceStatePending, cloud.InstanceStateRunning, cloud.InstanceStateStopping, cloud.InstanceStateStopped,
+	})
+
+	var instances []*cloud.Instance
+	for {
+		var output struct {
+			Instances []ec2Instance `xml:"reservationSet>item>instancesSet>item"`
+			NextToken string        `xml:"nextToken"`
+		}
+		if err := api.callQuery(ctx, form, &output); err != nil {
+			return nil, err
+		}
+		for _, found := range output.Instances {
+			instances = append(instances, d.instance(found, account, region))
+		}
+		if output.NextToken == "" {
+			return instances, nil
+		}
+		form.Set("NextToken", output.NextToken)
+	}
+}
+
+// instance converts a found instance, copying Name and the tag columns.
+func (d *discoverer) instance(found ec2Instance, account, region string) *cloud.Instance {
+	instance := &cloud.Instance{
+		ID:       found.ID,
+		Cloud:    "aws",
+		Account:  account,
+		Region:   region,
+		Metadata: make(map[string]string),
+	}
+	for _, tag := range found.Tags {
+		if tag.Key == cloud.NameTagKey {
+			instance.Metadata["name"] = tag.Value
+		}
+		if slices.Contains(d.opts.TagColumns, tag.Key) {
+			instance.Metadata[tag.Key] = tag.Value
+		}
+	}
+	return instance
+}
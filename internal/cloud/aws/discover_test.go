@@ -0,0 +1,308 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+)
+
+// TestParseTagFilters tests the --discover-tags entries.
+func TestParseTagFilters(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []string
+		want    []TagFilter
+		wantErr bool
+	}{
+		{"value", []string{"env=prod"}, []TagFilter{{Key: "env", Values: []string{"prod"}}}, false},
+		{"repeated key", []string{"env=prod", "team=web", " env = staging "},
+			[]TagFilter{{Key: "env", Values: []string{"prod", "staging"}}, {Key: "team", Values: []string{"web"}}}, false},
+		{"any value", []string{"puppet"}, []TagFilter{{Key: "puppet"}}, false},
+		{"empty key", []string{"=prod"}, nil, true},
+		{"empty value", []string{"env="}, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseTagFilters(tt.entries)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestDiscoveryOptions_Validate tests the discovery settings.
+func TestDiscoveryOptions_Validate(t *testing.T) {
+	tags := []TagFilter{{Key: "env", Values: []string{"prod"}}}
+	tests := []struct {
+		name    string
+		opts    DiscoveryOptions
+		wantErr bool
+	}{
+		{"tags", DiscoveryOptions{Tags: tags}, false},
+		{"organizational unit", DiscoveryOptions{Tags: tags, OrganizationalUnit: "ou-ab12-34cd5678"}, false},
+		{"root", DiscoveryOptions{Tags: tags, OrganizationalUnit: "r-ab12"}, false},
+		{"no tags", DiscoveryOptions{Organization: true}, true},
+		{"OU name", DiscoveryOptions{Tags: tags, OrganizationalUnit: "Production"}, true},
+		{"role ARN", DiscoveryOptions{Tags: tags, Role: "arn:aws:iam::111111111111:role/Discovery"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.opts.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// fakeOrganization answers STS, Organizations and EC2 calls for a test
+// organization: the caller is the management account 111111111111, member
+// accounts are searched with credentials whose key ID is "ASIA<account>".
+type fakeOrganization struct {
+	mu        sync.Mutex
+	accounts  map[string][]organizationAccount // Parent ID -> accounts
+	units     map[string][]string              // Parent ID -> child OUs
+	instances map[string][]string              // account/region -> instance IDs
+	denied    []string                         // Accounts whose role cannot be assumed
+	filters   []url.Values                     // DescribeInstances requests
+}
+
+func (f *fakeOrganization) handler(t *testing.T) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		service, region, _ := strings.Cut(strings.Trim(r.URL.Path, "/"), "/")
+		body, _ := io.ReadAll(r.Body)
+		f.mu.Lock()
+		defer f.mu.Unlock()
+
+		switch service {
+		case "sts":
+			form, _ := url.ParseQuery(string(body))
+			if form.Get("Action") == "GetCallerIdentity" {
+				_, _ = io.WriteString(w, `<GetCallerIdentityResponse><GetCallerIdentityResult>
+<Account>111111111111</Account><Arn>arn:aws:iam::111111111111:user/ops</Arn></GetCallerIdentityResult></GetCallerIdentityResponse>`)
+				return
+			}
+			account := strings.Split(form.Get("RoleArn"), ":")[4]
+			if slices.Contains(f.denied, account) {
+				w.WriteHeader(http.StatusForbidden)
+				_, _ = io.WriteString(w, `<ErrorResponse><Error><Code>AccessDenied</Code><Message>not authorized</Message></Error></ErrorResponse>`)
+				return
+			}
+			_, _ = fmt.Fprintf(w, `<AssumeRoleResponse><AssumeRoleResult><Credentials><AccessKeyId>ASIA%s</AccessKeyId>
+<SecretAccessKey>secret</SecretAccessKey><SessionToken>token</SessionToken><Expiration>2030-01-01T00:00:00Z</Expiration>
+</Credentials></AssumeRoleResult></AssumeRoleResponse>`, account)
+		case "organizations":
+			var input map[string]string
+			_ = json.Unmarshal(body, &input)
+			parent := input["ParentId"]
+			switch strings.TrimPrefix(r.Header.Get("X-Amz-Target"), organizationsTarget) {
+			case "ListAccounts":
+				// Two pages: the root accounts, then the rest
+				if input["NextToken"] == "" {
+					_ = json.NewEncoder(w).Encode(map[string]any{"Accounts": f.accounts["r-root"], "NextToken": "page2"})
+					return
+				}
+				var rest []organizationAccount
+				for id, accounts := range f.accounts {
+					if id != "r-root" {
+						rest = append(rest, accounts...)
+					}
+				}
+				_ = json.NewEncoder(w).Encode(map[string]any{"Accounts": rest})
+			case "ListAccountsForParent":
+				_ = json.NewEncoder(w).Encode(map[string]any{"Accounts": f.accounts[parent]})
+			case "ListOrganizationalUnitsForParent":
+				var units []map[string]string
+				for _, id := range f.units[parent] {
+					units = append(units, map[string]string{"Id": id})
+				}
+				_ = json.NewEncoder(w).Encode(map[string]any{"OrganizationalUnits": units})
+			}
+		case "ec2":
+			form, _ := url.ParseQuery(string(body))
+			f.filters = append(f.filters, form)
+			account := "111111111111"
+			if key := credentialKey(r); strings.HasPrefix(key, "ASIA") {
+				account = strings.TrimPrefix(key, "ASIA")
+			}
+			if region == "eu-west-1" && account == "333333333333" {
+				w.WriteHeader(http.StatusForbidden)
+				_, _ = io.WriteString(w, `<Response><Errors><Error><Code>UnauthorizedOperation</Code></Error></Errors></Response>`)
+				return
+			}
+			var items strings.Builder
+			for _, id := range f.instances[account+"/"+region] {
+				fmt.Fprintf(&items, `<item><instancesSet><item><instanceId>%s</instanceId><tagSet>
+<item><key>Name</key><value>web-%s</value></item><item><key>team</key><value>payments</value></item>
+</tagSet></item></instancesSet></item>`, id, id)
+			}
+			_, _ = fmt.Fprintf(w, `<DescribeInstancesResponse><reservationSet>%s</reservationSet></DescribeInstancesResponse>`, items.String())
+		default:
+			t.Errorf("unexpected request to %s", r.URL.Path)
+		}
+	})
+}
+
+// credentialKey returns the access key ID of a SigV4 Authorization header.
+func credentialKey(r *http.Request) string {
+	_, credential, _ := strings.Cut(r.Header.Get("Authorization"), "Credential=")
+	key, _, _ := strings.Cut(credential, "/")
+	return key
+}
+
+// newTestDiscoverer points every service client at server, under
+// /<service>/<region>/.
+func newTestDiscoverer(server *httptest.Server, opts DiscoveryOptions) *discoverer {
+	return &discoverer{
+		opts: opts,
+		caller: &signingConfig{
+			credentials: aws.Credentials{AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "secret"},
+			region:      "us-east-1",
+		},
+		newClient: func(sc *signingConfig, service string) *signedClient {
+			return &signedClient{
+				signing:  sc,
+				service:  service,
+				endpoint: server.URL + "/" + service + "/" + sc.region + "/",
+				client:   server.Client(),
+			}
+		},
+	}
+}
+
+// TestDiscover tests account enumeration, role assumption and the merged
+// inventory.
+func TestDiscover(t *testing.T) {
+	tags := []TagFilter{{Key: "env", Values: []string{"prod", "staging"}}, {Key: "puppet"}}
+	newOrganization := func() *fakeOrganization {
+		return &fakeOrganization{
+			accounts: map[string][]organizationAccount{
+				"r-root":   {{ID: "111111111111", Status: "ACTIVE"}},
+				"ou-prod":  {{ID: "222222222222", Status: "ACTIVE"}, {ID: "444444444444", Status: "SUSPENDED"}},
+				"ou-child": {{ID: "333333333333", Status: "ACTIVE"}},
+			},
+			units: map[string][]string{"r-root": {"ou-prod"}, "ou-prod": {"ou-child"}},
+			instances: map[string][]string{
+				"111111111111/us-east-1": {"i-mgmt"},
+				"222222222222/us-east-1": {"i-b2", "i-b1"},
+				"222222222222/eu-west-1": {"i-b3"},
+				"333333333333/us-east-1": {"i-c1"},
+			},
+		}
+	}
+	ids := func(result *DiscoveryResult) []string {
+		var got []string
+		for _, instance := range result.Instances {
+			got = append(got, instance.Account+"/"+instance.Region+"/"+instance.ID)
+		}
+		return got
+	}
+
+	tests := []struct {
+		name         string
+		opts         DiscoveryOptions
+		denied       []string
+		wantAccounts []string
+		wantIDs      []string
+		wantFailures []string
+	}{
+		{
+			name:         "caller account only",
+			opts:         DiscoveryOptions{Tags: tags},
+			wantAccounts: []string{"111111111111"},
+			wantIDs:      []string{"111111111111/us-east-1/i-mgmt"},
+		},
+		{
+			name:         "whole organization",
+			opts:         DiscoveryOptions{Tags: tags, Organization: true},
+			wantAccounts: []string{"111111111111", "222222222222", "333333333333"},
+			wantIDs: []string{
+				"111111111111/us-east-1/i-mgmt",
+				"222222222222/us-east-1/i-b1", "222222222222/us-east-1/i-b2",
+				"333333333333/us-east-1/i-c1",
+			},
+		},
+		{
+			name:         "OU and child OUs in two regions",
+			opts:         DiscoveryOptions{Tags: tags, OrganizationalUnit: "ou-prod", Regions: []string{"us-east-1", "eu-west-1"}},
+			wantAccounts: []string{"222222222222", "333333333333"},
+			wantIDs: []string{
+				"222222222222/eu-west-1/i-b3", "222222222222/us-east-1/i-b1", "222222222222/us-east-1/i-b2",
+				"333333333333/us-east-1/i-c1",
+			},
+			wantFailures: []string{"account 333333333333, region eu-west-1"},
+		},
+		{
+			name:         "role not assumable",
+			opts:         DiscoveryOptions{Tags: tags, Organization: true},
+			denied:       []string{"222222222222"},
+			wantAccounts: []string{"111111111111", "222222222222", "333333333333"},
+			wantIDs:      []string{"111111111111/us-east-1/i-mgmt", "333333333333/us-east-1/i-c1"},
+			wantFailures: []string{"account 222222222222: failed to assume role arn:aws:iam::222222222222:role/OrganizationAccountAccessRole"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			org := newOrganization()
+			org.denied = tt.denied
+			server := httptest.NewServer(org.handler(t))
+			defer server.Close()
+
+			result, err := newTestDiscoverer(server, tt.opts).run(context.Background())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !slices.Equal(result.Accounts, tt.wantAccounts) {
+				t.Errorf("Accounts = %v, want %v", result.Accounts, tt.wantAccounts)
+			}
+			if got := ids(result); !slices.Equal(got, tt.wantIDs) {
+				t.Errorf("instances = %v, want %v", got, tt.wantIDs)
+			}
+			if len(result.Failures) != len(tt.wantFailures) {
+				t.Fatalf("failures = %v, want %v", result.Failures, tt.wantFailures)
+			}
+			for i, want := range tt.wantFailures {
+				if got := result.Failures[i].Error(); !strings.Contains(got, want) {
+					t.Errorf("failure %d = %q, want %q", i, got, want)
+				}
+			}
+		})
+	}
+
+	t.Run("filters and metadata", func(t *testing.T) {
+		org := newOrganization()
+		server := httptest.NewServer(org.handler(t))
+		defer server.Close()
+
+		result, err := newTestDiscoverer(server, DiscoveryOptions{Tags: tags, TagColumns: []string{"team"}}).run(context.Background())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		form := org.filters[0]
+		if form.Get("Filter.1.Name") != "tag:env" || form.Get("Filter.1.Value.2") != "staging" ||
+			form.Get("Filter.2.Name") != "tag-key" || form.Get("Filter.2.Value.1") != "puppet" ||
+			form.Get("Filter.3.Name") != "instance-state-name" {
+			t.Errorf("unexpected filters %v", form)
+		}
+		instance := result.Instances[0]
+		if instance.Cloud != "aws" || instance.Metadata["name"] != "web-i-mgmt" || instance.Metadata["team"] != "payments" {
+			t.Errorf("unexpected instance %+v", instance)
+		}
+	})
+}
@@ -0,0 +1,175 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	"github.com/aws/aws-sdk-go-v2/service/ssm/types"
+)
+
+// Parameters of AWS-RunShellScript that opsmaster sets, and the keys of
+// SSMDocument.Parameters.
+const (
+	ParamCommands         = "commands"
+	ParamExecutionTimeout = "executionTimeout"
+)
+
+// SSMDocument is the SSM document that runs shell commands. Organizations
+// that restrict which documents may be invoked replace AWS-RunShellScript
+// with an approved customer-managed copy (--ssm-document-name), whose
+// parameters may have other names.
+type SSMDocument struct {
+	// Name is the document name, or its ARN when shared from another account
+	Name string
+
+	// Parameters maps commands and executionTimeout to the document's
+	// parameter names; unmapped ones keep their name. An empty
+	// executionTimeout means the document has no timeout parameter (the
+	// agent uses the document default and the client still gives up).
+	Parameters map[string]string
+}
+
+// ParseDocumentParameters parses --ssm-document-params entries
+// (opsmaster=document, e.g. "commands=Script") into SSMDocument.Parameters.
+func ParseDocumentParameters(entries []string) (map[string]string, error) {
+	params := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		name, mapped, ok := strings.Cut(entry, "=")
+		name, mapped = strings.TrimSpace(name), strings.TrimSpace(mapped)
+		if !ok {
+			return nil, fmt.Errorf("invalid SSM document parameter %q: use %s=<name> or %s=<name>", entry, ParamCommands, ParamExecutionTimeout)
+		}
+		if _, dup := params[name]; dup {
+			return nil, fmt.Errorf("SSM document parameter %q mapped twice", name)
+		}
+		params[name] = mapped
+	}
+	return params, nil
+}
+
+// Validate checks the parameter mapping.
+func (d SSMDocument) Validate() error {
+	for name, mapped := range d.Parameters {
+		switch name {
+		case ParamCommands:
+			if mapped == "" {
+				return fmt.Errorf("SSM document parameter %s cannot be empty", ParamCommands)
+			}
+		case ParamExecutionTimeout:
+		default:
+			return fmt.Errorf("unknown SSM document parameter %q: use %s or %s", name, ParamCommands, ParamExecutionTimeout)
+		}
+	}
+	if d.Name == "" && len(d.Parameters) > 0 {
+		return errors.New("SSM document parameters require --ssm-document-name")
+	}
+	return nil
+}
+
+// Custom reports whether the document replaces an AWS-managed one.
+func (d SSMDocument) Custom() bool {
+	return d.Name != "" && d.Name != documentShellScript
+}
+
+// parameter returns the document's name of an opsmaster parameter.
+func (d SSMDocument) parameter(name string) string {
+	if mapped, ok := d.Parameters[name]; ok {
+		return mapped
+	}
+	return name
+}
+
+// requiredParameters returns the document parameter names opsmaster sets.
+func (d SSMDocument) requiredParameters() []string {
+	var names []string
+	for _, name := range []string{ParamCommands, ParamExecutionTimeout} {
+		if mapped := d.parameter(name); mapped != "" {
+			names = append(names, mapped)
+		}
+	}
+	return names
+}
+
+// documentDescriber is the part of the SSM client used by checkDocument.
+type documentDescriber interface {
+	DescribeDocument(ctx context.Context, params *ssm.DescribeDocumentInput, optFns ...func(*ssm.Options)) (*ssm.DescribeDocumentOutput, error)
+}
+
+// checkDocument checks that doc exists in the client's account and region,
+// is an active Command document and declares the mapped parameters.
+func checkDocument(ctx context.Context, client documentDescriber, doc SSMDocument) error {
+	output, err := client.DescribeDocument(ctx, &ssm.DescribeDocumentInput{Name: aws.String(doc.Name)})
+	if err != nil {
+		var invalid *types.InvalidDocument
+		if errors.As(err, &invalid) {
+			return fmt.Errorf("SSM document %s not found: %w", doc.Name, err)
+		}
+		return fmt.Errorf("failed to describe SSM document %s: %w", doc.Name, err)
+	}
+
+	info := output.Document
+	if info.DocumentType != types.DocumentTypeCommand {
+		return fmt.Errorf("SSM document %s is a %s document (expected Command)", doc.Name, info.DocumentType)
+	}
+	if info.Status != types.DocumentStatusActive {
+		return fmt.Errorf("SSM document %s is %s (expected Active)", doc.Name, info.Status)
+	}
+
+	declared := make([]string, 0, len(info.Parameters))
+	for _, param := range info.Parameters {
+		declared = append(declared, aws.ToString(param.Name))
+	}
+	for _, name := range doc.requiredParameters() {
+		if !slices.Contains(declared, name) {
+			sort.Strings(declared)
+			return fmt.Errorf("parameter %s not found in SSM document %s (parameters: %s) - map it with --ssm-document-params",
+				name, doc.Name, strings.Join(declared, ", "))
+		}
+	}
+	return nil
+}
+
+// validationChecks runs the checks of run-wide settings (custom document,
+// output bucket) once per key, e.g. profile and region: every instance of
+// an account/region shares a successful result. Failures are not kept, so
+// a throttled or denied call is checked again by the next instance.
+type validationChecks struct {
+	mu     sync.Mutex
+	checks map[string]*validationCheck
+}
+
+type validationCheck struct {
+	once sync.Once
+	err  error
+}
+
+// check returns the result of run for key, calling it on first use only.
+func (c *validationChecks) check(key string, run func() error) error {
+	c.mu.Lock()
+	if c.checks == nil {
+		c.checks = make(map[string]*validationCheck)
+	}
+	entry, ok := c.checks[key]
+	if !ok {
+		entry = &validationCheck{}
+		c.checks[key] = entry
+	}
+	c.mu.Unlock()
+
+	entry.once.Do(func() { entry.err = run() })
+	if entry.err != nil {
+		c.mu.Lock()
+		if c.checks[key] == entry {
+			delete(c.checks, key)
+		}
+		c.mu.Unlock()
+	}
+	return entry.err
+}
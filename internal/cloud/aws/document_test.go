@@ -0,0 +1,170 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	"github.com/aws/aws-sdk-go-v2/service/ssm/types"
+)
+
+// TestParseDocumentParameters tests the --ssm-document-params mapping.
+func TestParseDocumentParameters(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []string
+		want    map[string]string
+		wantErr string
+	}{
+		{"none", nil, map[string]string{}, ""},
+		{"renamed", []string{"commands=Script", " executionTimeout = TimeoutSeconds "}, map[string]string{"commands": "Script", "executionTimeout": "TimeoutSeconds"}, ""},
+		{"timeout not supported", []string{"executionTimeout="}, map[string]string{"executionTimeout": ""}, ""},
+		{"unknown parameter", []string{"workingDirectory=Dir"}, nil, "unknown SSM document parameter"},
+		{"empty commands", []string{"commands="}, nil, "cannot be empty"},
+		{"missing separator", []string{"commands"}, nil, "invalid SSM document parameter"},
+		{"mapped twice", []string{"commands=A", "commands=B"}, nil, "mapped twice"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseDocumentParameters(tt.entries)
+			if err == nil {
+				err = SSMDocument{Name: "MyOrg-RunShellScript", Parameters: got}.Validate()
+			}
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+			for name, mapped := range tt.want {
+				if got[name] != mapped {
+					t.Errorf("%s = %q, want %q", name, got[name], mapped)
+				}
+			}
+		})
+	}
+
+	if err := (SSMDocument{Parameters: map[string]string{"commands": "Script"}}).Validate(); err == nil {
+		t.Error("parameters without a document name should be rejected")
+	}
+}
+
+// TestSendCommandInput_ParameterMapping tests that SendCommand uses the
+// custom document's parameter names.
+func TestSendCommandInput_ParameterMapping(t *testing.T) {
+	commands := []string{"echo test"}
+	timeouts := newCommandTimeouts(5 * time.Minute)
+
+	renamed := SSMDocument{Name: "MyOrg-RunShellScript", Parameters: map[string]string{"commands": "Script", "executionTimeout": "TimeoutSeconds"}}
+	input := sendCommandInput("i-test", renamed, SSMOutput{}, commands, "comment", timeouts)
+	if *input.DocumentName != "MyOrg-RunShellScript" {
+		t.Errorf("DocumentName = %s", *input.DocumentName)
+	}
+	if len(input.Parameters) != 2 || input.Parameters["Script"][0] != "echo test" || input.Parameters["TimeoutSeconds"][0] != "300" {
+		t.Errorf("Parameters = %v, want Script and TimeoutSeconds", input.Parameters)
+	}
+
+	noTimeout := SSMDocument{Name: "MyOrg-RunShellScript", Parameters: map[string]string{"executionTimeout": ""}}
+	input = sendCommandInput("i-test", noTimeout, SSMOutput{}, commands, "comment", timeouts)
+	if len(input.Parameters) != 1 || input.Parameters["commands"][0] != "echo test" {
+		t.Errorf("Parameters = %v, want only commands", input.Parameters)
+	}
+}
+
+// stubDescriber answers DescribeDocument with a fixed document or error.
+type stubDescriber struct {
+	doc *types.DocumentDescription
+	err error
+}
+
+func (s *stubDescriber) DescribeDocument(_ context.Context, _ *ssm.DescribeDocumentInput, _ ...func(*ssm.Options)) (*ssm.DescribeDocumentOutput, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &ssm.DescribeDocumentOutput{Document: s.doc}, nil
+}
+
+// TestCheckDocument tests the existence and shape checks of a custom document.
+func TestCheckDocument(t *testing.T) {
+	document := func(docType types.DocumentType, status types.DocumentStatus, params ...string) *types.DocumentDescription {
+		desc := &types.DocumentDescription{DocumentType: docType, Status: status}
+		for _, name := range params {
+			desc.Parameters = append(desc.Parameters, types.DocumentParameter{Name: aws.String(name)})
+		}
+		return desc
+	}
+	doc := SSMDocument{Name: "MyOrg-RunShellScript"}
+
+	tests := []struct {
+		name    string
+		stub    *stubDescriber
+		doc     SSMDocument
+		wantErr string
+	}{
+		{"valid", &stubDescriber{doc: document(types.DocumentTypeCommand, types.DocumentStatusActive, "commands", "executionTimeout", "workingDirectory")}, doc, ""},
+		{"missing document", &stubDescriber{err: &types.InvalidDocument{Message: aws.String("document does not exist")}}, doc, "SSM document MyOrg-RunShellScript not found"},
+		{"api error", &stubDescriber{err: errors.New("AccessDeniedException")}, doc, "failed to describe SSM document"},
+		{"not a command document", &stubDescriber{doc: document(types.DocumentTypeAutomation, types.DocumentStatusActive, "commands")}, doc, "expected Command"},
+		{"not active", &stubDescriber{doc: document(types.DocumentTypeCommand, types.DocumentStatusCreating, "commands", "executionTimeout")}, doc, "expected Active"},
+		{"missing parameter", &stubDescriber{doc: document(types.DocumentTypeCommand, types.DocumentStatusActive, "commands")}, doc, "parameter executionTimeout not found"},
+		{"mapped parameters", &stubDescriber{doc: document(types.DocumentTypeCommand, types.DocumentStatusActive, "Script")},
+			SSMDocument{Name: "MyOrg-RunShellScript", Parameters: map[string]string{"commands": "Script", "executionTimeout": ""}}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkDocument(context.Background(), tt.stub, tt.doc)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestValidationChecks tests that a successful check is shared per key and
+// a failed one is run again.
+func TestValidationChecks(t *testing.T) {
+	var checks validationChecks
+	var calls int
+	fail := true
+	run := func() error {
+		calls++
+		if fail {
+			return errors.New("throttled")
+		}
+		return nil
+	}
+
+	if err := checks.check("prod/us-east-1", run); err == nil {
+		t.Fatal("first check should fail")
+	}
+	fail = false
+	for range 3 {
+		if err := checks.check("prod/us-east-1", run); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if err := checks.check("prod/sa-east-1", run); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls != 3 {
+		t.Errorf("calls = %d, want 3 (failure retried, success shared per region)", calls)
+	}
+}
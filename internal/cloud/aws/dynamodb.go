@@ -0,0 +1,41 @@
+package aws
+
+import (
+	"context"
+)
+
+const (
+	// dynamoDBTargetPrefix prefixes operation names in the X-Amz-Target header.
+	dynamoDBTargetPrefix = "DynamoDB_20120810."
+
+	// dynamoDBContentType is the DynamoDB JSON protocol content type.
+	dynamoDBContentType = "application/x-amz-json-1.0"
+)
+
+// DynamoDB is a minimal client for the DynamoDB JSON API. Inputs and
+// outputs are the API's JSON shapes (see the DynamoDB API reference).
+// Errors are *APIError, e.g. IsAPIError(err, "ResourceNotFoundException").
+type DynamoDB struct {
+	api *signedClient
+}
+
+// NewDynamoDB creates a client using the profile's credentials
+// ("" = default credential chain). region overrides the profile region.
+func NewDynamoDB(ctx context.Context, profile, region string) (*DynamoDB, error) {
+	sc, err := loadSigningConfig(ctx, profile, region)
+	if err != nil {
+		return nil, err
+	}
+	return &DynamoDB{api: newSignedClient(sc, "dynamodb")}, nil
+}
+
+// Region returns the region the client talks to.
+func (d *DynamoDB) Region() string {
+	return d.api.signing.region
+}
+
+// Call invokes a DynamoDB operation (e.g. "PutItem"), encoding input as the
+// request body and decoding the response into output (nil to discard it).
+func (d *DynamoDB) Call(ctx context.Context, operation string, input, output any) error {
+	return d.api.callJSON(ctx, dynamoDBContentType, dynamoDBTargetPrefix+operation, input, output)
+}
@@ -0,0 +1,70 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+)
+
+// newTestDynamoDB points a client with static credentials at a test server.
+func newTestDynamoDB(server *httptest.Server) *DynamoDB {
+	return &DynamoDB{api: newTestSignedClient(server, "dynamodb")}
+}
+
+// newTestSignedClient signs with static credentials and targets a test server.
+func newTestSignedClient(server *httptest.Server, service string) *signedClient {
+	return &signedClient{
+		signing: &signingConfig{
+			credentials: aws.Credentials{AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "secret"},
+			region:      "us-east-1",
+		},
+		service:  service,
+		endpoint: server.URL + "/",
+		client:   server.Client(),
+	}
+}
+
+// TestDynamoDB_Call tests request signing, target header and error decoding
+func TestDynamoDB_Call(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256") {
+			t.Errorf("request not signed: %q", r.Header.Get("Authorization"))
+		}
+		switch r.Header.Get("X-Amz-Target") {
+		case "DynamoDB_20120810.DescribeTable":
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"__type":"com.amazonaws.dynamodb.v20120810#ResourceNotFoundException","message":"Requested resource not found"}`))
+		case "DynamoDB_20120810.PutItem":
+			var input map[string]any
+			json.NewDecoder(r.Body).Decode(&input)
+			json.NewEncoder(w).Encode(map[string]any{"echo": input["TableName"]})
+		default:
+			t.Errorf("unexpected target %q", r.Header.Get("X-Amz-Target"))
+		}
+	}))
+	defer server.Close()
+	client := newTestDynamoDB(server)
+
+	t.Run("success decodes output", func(t *testing.T) {
+		var output struct{ Echo string }
+		err := client.Call(context.Background(), "PutItem", map[string]any{"TableName": "fleet"}, &output)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if output.Echo != "fleet" {
+			t.Errorf("Echo = %q, want fleet", output.Echo)
+		}
+	})
+
+	t.Run("error type is decoded", func(t *testing.T) {
+		err := client.Call(context.Background(), "DescribeTable", map[string]any{"TableName": "fleet"}, nil)
+		if !IsAPIError(err, "ResourceNotFoundException") {
+			t.Fatalf("expected ResourceNotFoundException, got %v", err)
+		}
+	})
+}
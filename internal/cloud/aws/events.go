@@ -0,0 +1,140 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const (
+	// eventBridgeContentType is the EventBridge JSON protocol content type.
+	eventBridgeContentType = "application/x-amz-json-1.1"
+
+	// snsAPIVersion is the SNS Query API version.
+	snsAPIVersion = "2010-03-31"
+
+	// maxSNSSubject is the longest subject SNS accepts.
+	maxSNSSubject = 100
+)
+
+// EventPublisher publishes JSON events to an SNS topic or an EventBridge
+// bus, chosen by the target ARN:
+//
+//	arn:aws:sns:us-east-1:111111111111:opsmaster-events
+//	arn:aws:events:us-east-1:111111111111:event-bus/opsmaster
+type EventPublisher struct {
+	api     *signedClient
+	service string // "sns" or "events"
+	arn     string
+	source  string // EventBridge Source
+}
+
+// NewEventPublisher creates a publisher for targetARN using the profile's
+// credentials ("" = default credential chain). The region comes from the ARN.
+// source is the EventBridge event source (ignored for SNS).
+func NewEventPublisher(ctx context.Context, profile, targetARN, source string) (*EventPublisher, error) {
+	service, region, err := parseEventTargetARN(targetARN)
+	if err != nil {
+		return nil, err
+	}
+	sc, err := loadSigningConfig(ctx, profile, region)
+	if err != nil {
+		return nil, err
+	}
+	return &EventPublisher{
+		api:     newSignedClient(sc, service),
+		service: service,
+		arn:     targetARN,
+		source:  source,
+	}, nil
+}
+
+// parseEventTargetARN extracts service and region from an SNS topic or
+// EventBridge bus ARN.
+func parseEventTargetARN(arn string) (service, region string, err error) {
+	parts := strings.SplitN(arn, ":", 6)
+	if len(parts) != 6 || parts[0] != "arn" || parts[3] == "" || parts[5] == "" {
+		return "", "", fmt.Errorf("invalid event target ARN %q", arn)
+	}
+
+	service, region = parts[2], parts[3]
+	switch {
+	case service == "sns":
+	case service == "events" && strings.HasPrefix(parts[5], "event-bus/"):
+	default:
+		return "", "", fmt.Errorf("unsupported event target %q: use an SNS topic or EventBridge event-bus ARN", arn)
+	}
+	return service, region, nil
+}
+
+// Publish sends one event. detailType describes it (EventBridge DetailType,
+// SNS Subject); attributes become SNS message attributes for subscription
+// filtering (EventBridge rules match on the JSON detail instead).
+func (p *EventPublisher) Publish(ctx context.Context, detailType string, detail []byte, attributes map[string]string) error {
+	if p.service == "sns" {
+		return p.publishSNS(ctx, detailType, detail, attributes)
+	}
+	return p.putEvent(ctx, detailType, detail)
+}
+
+// putEvent calls EventBridge PutEvents with a single entry.
+func (p *EventPublisher) putEvent(ctx context.Context, detailType string, detail []byte) error {
+	input := map[string]any{
+		"Entries": []map[string]string{{
+			"EventBusName": p.arn,
+			"Source":       p.source,
+			"DetailType":   detailType,
+			"Detail":       string(detail),
+		}},
+	}
+
+	// PutEvents reports per-entry failures in a successful response
+	var output struct {
+		FailedEntryCount int `json:"FailedEntryCount"`
+		Entries          []struct {
+			ErrorCode    string `json:"ErrorCode"`
+			ErrorMessage string `json:"ErrorMessage"`
+		} `json:"Entries"`
+	}
+	if err := p.api.callJSON(ctx, eventBridgeContentType, "AWSEvents.PutEvents", input, &output); err != nil {
+		return err
+	}
+	if output.FailedEntryCount > 0 && len(output.Entries) > 0 {
+		entry := output.Entries[0]
+		return &APIError{Service: p.service, StatusCode: 200, Type: entry.ErrorCode, Message: entry.ErrorMessage}
+	}
+	return nil
+}
+
+// publishSNS calls the SNS Query API Publish action.
+func (p *EventPublisher) publishSNS(ctx context.Context, subject string, message []byte, attributes map[string]string) error {
+	if len(subject) > maxSNSSubject {
+		subject = subject[:maxSNSSubject]
+	}
+
+	form := url.Values{
+		"Action":   {"Publish"},
+		"Version":  {snsAPIVersion},
+		"TopicArn": {p.arn},
+		"Subject":  {subject},
+		"Message":  {string(message)},
+	}
+	i := 1
+	for name, value := range attributes {
+		prefix := "MessageAttributes.entry." + strconv.Itoa(i)
+		form.Set(prefix+".Name", name)
+		form.Set(prefix+".Value.DataType", "String")
+		form.Set(prefix+".Value.StringValue", value)
+		i++
+	}
+
+	resp, err := p.api.post(ctx, []byte(form.Encode()), map[string]string{
+		"Content-Type": "application/x-www-form-urlencoded; charset=utf-8",
+	})
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
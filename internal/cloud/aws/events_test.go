@@ -0,0 +1,99 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+// TestParseEventTargetARN tests SNS/EventBridge detection and validation
+func TestParseEventTargetARN(t *testing.T) {
+	tests := []struct {
+		name        string
+		arn         string
+		wantService string
+		wantRegion  string
+		wantErr     bool
+	}{
+		{name: "sns topic", arn: "arn:aws:sns:sa-east-1:111111111111:opsmaster-events", wantService: "sns", wantRegion: "sa-east-1"},
+		{name: "eventbridge bus", arn: "arn:aws:events:us-east-1:111111111111:event-bus/opsmaster", wantService: "events", wantRegion: "us-east-1"},
+		{name: "eventbridge rule is rejected", arn: "arn:aws:events:us-east-1:111111111111:rule/x", wantErr: true},
+		{name: "other service", arn: "arn:aws:sqs:us-east-1:111111111111:queue", wantErr: true},
+		{name: "not an ARN", arn: "opsmaster-events", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, region, err := parseEventTargetARN(tt.arn)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if service != tt.wantService || region != tt.wantRegion {
+				t.Errorf("got %s/%s, want %s/%s", service, region, tt.wantService, tt.wantRegion)
+			}
+		})
+	}
+}
+
+// TestEventPublisher_Publish tests the SNS and EventBridge wire formats
+func TestEventPublisher_Publish(t *testing.T) {
+	detail := []byte(`{"instance_id":"i-1","status":"SUCCESS"}`)
+
+	t.Run("sns publish with attributes", func(t *testing.T) {
+		var form url.Values
+		server := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			form, _ = url.ParseQuery(string(body))
+		}))
+		defer server.Close()
+		publisher := &EventPublisher{api: newTestSignedClient(server, "sns"), service: "sns", arn: "arn:aws:sns:us-east-1:1:t"}
+
+		if err := publisher.Publish(context.Background(), "Instance Completed", detail, map[string]string{"status": "SUCCESS"}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if form.Get("Action") != "Publish" || form.Get("Message") != string(detail) || form.Get("TopicArn") != "arn:aws:sns:us-east-1:1:t" {
+			t.Errorf("unexpected form %v", form)
+		}
+		if form.Get("MessageAttributes.entry.1.Name") != "status" || form.Get("MessageAttributes.entry.1.Value.StringValue") != "SUCCESS" {
+			t.Errorf("missing message attribute in %v", form)
+		}
+	})
+
+	t.Run("eventbridge failed entry is an error", func(t *testing.T) {
+		var input struct{ Entries []map[string]string }
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("X-Amz-Target") != "AWSEvents.PutEvents" {
+				t.Errorf("unexpected target %q", r.Header.Get("X-Amz-Target"))
+			}
+			json.NewDecoder(r.Body).Decode(&input)
+			w.Write([]byte(`{"FailedEntryCount":1,"Entries":[{"ErrorCode":"AccessDeniedException","ErrorMessage":"denied"}]}`))
+		}))
+		defer server.Close()
+		publisher := &EventPublisher{api: newTestSignedClient(server, "events"), service: "events", arn: "arn:aws:events:us-east-1:1:event-bus/b", source: "opsmaster"}
+
+		err := publisher.Publish(context.Background(), "Instance Completed", detail, nil)
+		if !IsAPIError(err, "AccessDeniedException") {
+			t.Fatalf("expected AccessDeniedException, got %v", err)
+		}
+		if len(input.Entries) != 1 || input.Entries[0]["Source"] != "opsmaster" || input.Entries[0]["Detail"] != string(detail) {
+			t.Errorf("unexpected entries %v", input.Entries)
+		}
+	})
+
+	t.Run("sns xml error is decoded", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`<ErrorResponse><Error><Type>Sender</Type><Code>NotFound</Code><Message>Topic does not exist</Message></Error></ErrorResponse>`))
+		}))
+		defer server.Close()
+		publisher := &EventPublisher{api: newTestSignedClient(server, "sns"), service: "sns", arn: "arn:aws:sns:us-east-1:1:t"}
+
+		if err := publisher.Publish(context.Background(), "x", detail, nil); !IsAPIError(err, "NotFound") {
+			t.Fatalf("expected NotFound, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,156 @@
+package aws
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+
+	"github.com/estudosdevops/opsmaster/internal/httpclient"
+)
+
+// SSE algorithms of S3 default encryption that use a KMS key.
+const (
+	sseKMS     = "aws:kms"
+	sseKMSDSSE = "aws:kms:dsse"
+)
+
+// SSMOutput is the S3 bucket where SSM stores the full output of every
+// command (--ssm-output-bucket), beyond the 24,000 characters returned by
+// GetCommandInvocation. The agent uploads the output with the instance
+// profile, encrypted by the bucket's default encryption, which must be
+// SSE-KMS.
+type SSMOutput struct {
+	Bucket    string // Bucket name ("" = output not stored)
+	KeyPrefix string // Key prefix, e.g. "opsmaster/prod" (optional)
+	KMSKeyID  string // KMS key the bucket must encrypt with: ID or ARN ("" = any KMS key)
+}
+
+// Enabled reports whether command output is stored in S3.
+func (o SSMOutput) Enabled() bool {
+	return o.Bucket != ""
+}
+
+// Validate checks the bucket settings.
+func (o SSMOutput) Validate() error {
+	if o.Bucket == "" {
+		if o.KeyPrefix != "" || o.KMSKeyID != "" {
+			return errors.New("SSM output prefix and KMS key require --ssm-output-bucket")
+		}
+		return nil
+	}
+	if strings.ContainsAny(o.Bucket, "/:") {
+		return fmt.Errorf("invalid SSM output bucket %q: use the bucket name, without s3:// or a key", o.Bucket)
+	}
+	return nil
+}
+
+// prefix returns the key prefix without surrounding slashes.
+func (o SSMOutput) prefix() string {
+	return strings.Trim(o.KeyPrefix, "/")
+}
+
+// Location returns the S3 folder holding the output of a command on an
+// instance; SSM writes one stdout/stderr pair per document step under it.
+func (o SSMOutput) Location(commandID, instanceID string) string {
+	location := "s3://" + o.Bucket + "/"
+	if prefix := o.prefix(); prefix != "" {
+		location += prefix + "/"
+	}
+	return location + commandID + "/" + instanceID + "/"
+}
+
+// bucketEncryption is the GetBucketEncryption response.
+type bucketEncryption struct {
+	Rules []struct {
+		Default struct {
+			Algorithm string `xml:"SSEAlgorithm"`
+			KMSKeyID  string `xml:"KMSMasterKeyID"`
+		} `xml:"ApplyServerSideEncryptionByDefault"`
+	} `xml:"Rule"`
+}
+
+// checkEncryption checks a GetBucketEncryption response against the
+// settings: the default encryption must be SSE-KMS, with KMSKeyID if set.
+func (o SSMOutput) checkEncryption(body []byte) error {
+	var config bucketEncryption
+	if err := xml.Unmarshal(body, &config); err != nil {
+		return fmt.Errorf("invalid encryption configuration of bucket %s: %w", o.Bucket, err)
+	}
+	for _, rule := range config.Rules {
+		algorithm, keyID := rule.Default.Algorithm, rule.Default.KMSKeyID
+		if algorithm != sseKMS && algorithm != sseKMSDSSE {
+			return fmt.Errorf("bucket %s default encryption is %s (expected SSE-KMS)", o.Bucket, algorithm)
+		}
+		if o.KMSKeyID != "" && kmsKeyName(keyID) != kmsKeyName(o.KMSKeyID) {
+			if keyID == "" {
+				keyID = "the AWS managed key aws/s3"
+			}
+			return fmt.Errorf("bucket %s encrypts with %s (expected KMS key %s)", o.Bucket, keyID, o.KMSKeyID)
+		}
+		return nil
+	}
+	return fmt.Errorf("bucket %s has no default encryption (expected SSE-KMS)", o.Bucket)
+}
+
+// kmsKeyName returns the key ID of a key ID or ARN
+// (arn:aws:kms:<region>:<account>:key/<id>), so both forms compare equal.
+func kmsKeyName(key string) string {
+	if _, id, ok := strings.Cut(key, ":key/"); ok {
+		return id
+	}
+	return key
+}
+
+// checkOutputBucket reads the bucket's default encryption (signed GET
+// ?encryption, following the bucket region) and checks it is SSE-KMS.
+func checkOutputBucket(ctx context.Context, profile string, output SSMOutput) error {
+	sc, err := loadSigningConfig(ctx, profile, "")
+	if err != nil {
+		return err
+	}
+	bucket := S3Object{Bucket: output.Bucket}
+
+	for attempt := 0; attempt < 2; attempt++ {
+		u := s3ObjectURL(bucket, sc.region)
+		u.RawQuery = "encryption="
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), http.NoBody)
+		if err != nil {
+			return fmt.Errorf("failed to create S3 request: %w", err)
+		}
+		err = sc.sign(ctx, req, payloadHash(nil), "s3", func(o *v4.SignerOptions) {
+			o.DisableURIPathEscaping = true
+		})
+		if err != nil {
+			return err
+		}
+
+		resp, err := httpclient.Shared().Do(req)
+		if err != nil {
+			return fmt.Errorf("failed to check SSM output bucket %s: %w", output.Bucket, err)
+		}
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+		resp.Body.Close()
+
+		switch {
+		case resp.StatusCode == http.StatusOK:
+			return output.checkEncryption(body)
+		case resp.Header.Get("X-Amz-Bucket-Region") != "" && resp.Header.Get("X-Amz-Bucket-Region") != sc.region && attempt == 0:
+			sc.region = resp.Header.Get("X-Amz-Bucket-Region")
+		case resp.StatusCode == http.StatusNotFound && strings.Contains(string(body), "ServerSideEncryptionConfigurationNotFoundError"):
+			return fmt.Errorf("bucket %s has no default encryption (expected SSE-KMS)", output.Bucket)
+		case resp.StatusCode == http.StatusNotFound:
+			return fmt.Errorf("SSM output bucket %s not found", output.Bucket)
+		case resp.StatusCode == http.StatusForbidden:
+			return fmt.Errorf("access denied to SSM output bucket %s (check s3:GetEncryptionConfiguration permission for the AWS profile)", output.Bucket)
+		default:
+			return fmt.Errorf("failed to check SSM output bucket %s: HTTP %d: %s", output.Bucket, resp.StatusCode, strings.TrimSpace(string(body[:min(len(body), maxErrorBody)])))
+		}
+	}
+	return fmt.Errorf("failed to check SSM output bucket %s: bucket region redirect loop", output.Bucket)
+}
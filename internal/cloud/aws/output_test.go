@@ -0,0 +1,113 @@
+package aws
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestSSMOutput_Validate tests the --ssm-output-* settings.
+func TestSSMOutput_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		output  SSMOutput
+		wantErr bool
+	}{
+		{"disabled", SSMOutput{}, false},
+		{"bucket only", SSMOutput{Bucket: "ssm-output"}, false},
+		{"all settings", SSMOutput{Bucket: "ssm-output", KeyPrefix: "opsmaster/prod", KMSKeyID: "1234abcd-12ab-34cd-56ef-1234567890ab"}, false},
+		{"prefix without bucket", SSMOutput{KeyPrefix: "opsmaster"}, true},
+		{"KMS key without bucket", SSMOutput{KMSKeyID: "1234abcd"}, true},
+		{"S3 URL as bucket", SSMOutput{Bucket: "s3://ssm-output"}, true},
+		{"bucket with key", SSMOutput{Bucket: "ssm-output/opsmaster"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.output.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestSSMOutput_SendCommand tests the output settings of SendCommand and
+// the reported location, which follows the SSM layout
+// <prefix>/<command-id>/<instance-id>/.
+func TestSSMOutput_SendCommand(t *testing.T) {
+	tests := []struct {
+		name         string
+		output       SSMOutput
+		wantPrefix   string
+		wantLocation string
+	}{
+		{"with prefix", SSMOutput{Bucket: "ssm-output", KeyPrefix: "/opsmaster/prod/"}, "opsmaster/prod", "s3://ssm-output/opsmaster/prod/cmd-1/i-test/"},
+		{"without prefix", SSMOutput{Bucket: "ssm-output"}, "", "s3://ssm-output/cmd-1/i-test/"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := sendCommandInput("i-test", SSMDocument{Name: documentShellScript}, tt.output, []string{"echo test"}, "comment", newCommandTimeouts(time.Minute))
+
+			if input.OutputS3BucketName == nil || *input.OutputS3BucketName != tt.output.Bucket {
+				t.Errorf("OutputS3BucketName = %v, want %s", input.OutputS3BucketName, tt.output.Bucket)
+			}
+			if got := input.OutputS3KeyPrefix; (got == nil) != (tt.wantPrefix == "") || got != nil && *got != tt.wantPrefix {
+				t.Errorf("OutputS3KeyPrefix = %v, want %q", got, tt.wantPrefix)
+			}
+			if got := tt.output.Location("cmd-1", "i-test"); got != tt.wantLocation {
+				t.Errorf("Location() = %s, want %s", got, tt.wantLocation)
+			}
+		})
+	}
+
+	input := sendCommandInput("i-test", SSMDocument{Name: documentShellScript}, SSMOutput{}, []string{"echo test"}, "comment", newCommandTimeouts(time.Minute))
+	if input.OutputS3BucketName != nil || input.OutputS3KeyPrefix != nil {
+		t.Error("output bucket set without --ssm-output-bucket")
+	}
+}
+
+// TestSSMOutput_CheckEncryption tests the SSE-KMS check of the bucket's
+// GetBucketEncryption response.
+func TestSSMOutput_CheckEncryption(t *testing.T) {
+	const keyARN = "arn:aws:kms:us-east-1:123456789012:key/1234abcd-12ab-34cd-56ef-1234567890ab"
+	encryption := func(algorithm, keyID string) string {
+		return `<ServerSideEncryptionConfiguration xmlns="http://s3.amazonaws.com/doc/2006-03-01/"><Rule>` +
+			`<ApplyServerSideEncryptionByDefault><SSEAlgorithm>` + algorithm + `</SSEAlgorithm>` +
+			`<KMSMasterKeyID>` + keyID + `</KMSMasterKeyID></ApplyServerSideEncryptionByDefault>` +
+			`<BucketKeyEnabled>true</BucketKeyEnabled></Rule></ServerSideEncryptionConfiguration>`
+	}
+
+	tests := []struct {
+		name    string
+		kmsKey  string
+		body    string
+		wantErr string
+	}{
+		{"any KMS key", "", encryption("aws:kms", keyARN), ""},
+		{"key ID matches ARN", "1234abcd-12ab-34cd-56ef-1234567890ab", encryption("aws:kms", keyARN), ""},
+		{"key ARN matches", keyARN, encryption("aws:kms", keyARN), ""},
+		{"dual-layer KMS", "", encryption("aws:kms:dsse", keyARN), ""},
+		{"other key", "ffff0000-12ab-34cd-56ef-1234567890ab", encryption("aws:kms", keyARN), "expected KMS key ffff0000"},
+		{"AWS managed key", keyARN, encryption("aws:kms", ""), "AWS managed key aws/s3"},
+		{"SSE-S3", "", encryption("AES256", ""), "default encryption is AES256"},
+		{"no rules", "", `<ServerSideEncryptionConfiguration/>`, "no default encryption"},
+		{"invalid XML", "", `<ServerSideEncryptionConfiguration>`, "invalid encryption configuration"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output := SSMOutput{Bucket: "ssm-output", KMSKeyID: tt.kmsKey}
+			err := output.checkEncryption([]byte(tt.body))
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,163 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+
+	"github.com/estudosdevops/opsmaster/internal/cloud"
+)
+
+const (
+	// powerBatchSize is the maximum number of instance IDs per Start/Stop/RebootInstances call.
+	powerBatchSize = 50
+
+	// statePollInterval is how often WaitForState polls DescribeInstances.
+	statePollInterval = 5 * time.Second
+)
+
+// StartInstances requests EC2 instances to start. Implements cloud.InstanceStarter.
+// Returns per-instance errors keyed by instance ID (empty map = all requested).
+func (p *AWSProvider) StartInstances(ctx context.Context, instances []*cloud.Instance) map[string]error {
+	return p.changeInstancesState(ctx, instances, "start")
+}
+
+// StopInstances requests EC2 instances to stop. Implements cloud.InstanceStopper.
+// Returns per-instance errors keyed by instance ID (empty map = all requested).
+func (p *AWSProvider) StopInstances(ctx context.Context, instances []*cloud.Instance) map[string]error {
+	return p.changeInstancesState(ctx, instances, "stop")
+}
+
+// RebootInstances requests EC2 instances to reboot. Implements cloud.InstanceRebooter.
+// Returns per-instance errors keyed by instance ID (empty map = all requested).
+func (p *AWSProvider) RebootInstances(ctx context.Context, instances []*cloud.Instance) map[string]error {
+	return p.changeInstancesState(ctx, instances, "reboot")
+}
+
+// changeInstancesState runs a start/stop/reboot action in batches grouped by profile and region.
+func (p *AWSProvider) changeInstancesState(ctx context.Context, instances []*cloud.Instance, action string) map[string]error {
+	failures := make(map[string]error)
+
+	for _, group := range groupByProfileRegion(instances) {
+		for _, batch := range batchInstances(group, powerBatchSize) {
+			err := p.ec2Retryer.Do(ctx, func() error {
+				return p.changeInstancesStateInternal(ctx, batch, action)
+			})
+
+			for _, instance := range batch {
+				// State changes - cached metadata is no longer valid
+				p.metadataCache.Invalidate(instance)
+				if err != nil {
+					failures[instance.ID] = err
+				}
+			}
+		}
+	}
+
+	return failures
+}
+
+// changeInstancesStateInternal performs a single Start/Stop/RebootInstances call without retry.
+// All instances in the batch must share the same profile and region.
+func (p *AWSProvider) changeInstancesStateInternal(ctx context.Context, batch []*cloud.Instance, action string) error {
+	first := batch[0]
+	ec2Client, err := p.sessionManager.GetEC2Client(ctx, getProfileForInstance(first), first.Region)
+	if err != nil {
+		return fmt.Errorf("failed to get EC2 client: %w", err)
+	}
+
+	ids := instanceIDs(batch)
+	switch action {
+	case "start":
+		_, err = ec2Client.StartInstances(ctx, &ec2.StartInstancesInput{InstanceIds: ids})
+	case "stop":
+		_, err = ec2Client.StopInstances(ctx, &ec2.StopInstancesInput{InstanceIds: ids})
+	case "reboot":
+		_, err = ec2Client.RebootInstances(ctx, &ec2.RebootInstancesInput{InstanceIds: ids})
+	default:
+		return fmt.Errorf("unsupported instance action: %s", action)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to %s instances in %s: %w", action, first.Region, err)
+	}
+
+	p.log.Info("Instance state change requested",
+		"action", action,
+		"region", first.Region,
+		"count", len(batch))
+
+	return nil
+}
+
+// WaitForState polls instances until all reach the desired state or timeout expires.
+// Implements cloud.InstanceStarter. Returns per-instance errors for instances that
+// did not reach the state (including their last observed state).
+func (p *AWSProvider) WaitForState(ctx context.Context, instances []*cloud.Instance, state string, timeout time.Duration) map[string]error {
+	waitCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	pending := make(map[string]*cloud.Instance, len(instances))
+	lastState := make(map[string]string, len(instances))
+	for _, instance := range instances {
+		pending[instance.ID] = instance
+	}
+
+	ticker := time.NewTicker(statePollInterval)
+	defer ticker.Stop()
+
+	for {
+		remaining := make([]*cloud.Instance, 0, len(pending))
+		for _, instance := range pending {
+			remaining = append(remaining, instance)
+		}
+
+		for _, group := range groupByProfileRegion(remaining) {
+			for _, batch := range batchInstances(group, describeBatchSize) {
+				infos, err := p.describeInstancesInternal(waitCtx, batch)
+				if err != nil {
+					// Transient describe errors are retried on next poll
+					p.log.Debug("Failed to poll instance state", "error", err)
+					continue
+				}
+
+				for _, instance := range batch {
+					info, ok := infos[instance.ID]
+					if !ok {
+						continue
+					}
+					p.metadataCache.Put(instance, info)
+					lastState[instance.ID] = info.State
+					if info.State == state {
+						delete(pending, instance.ID)
+					}
+				}
+			}
+		}
+
+		if len(pending) == 0 {
+			return map[string]error{}
+		}
+
+		select {
+		case <-waitCtx.Done():
+			failures := make(map[string]error, len(pending))
+			for id := range pending {
+				failures[id] = fmt.Errorf("timed out after %s waiting for state %s (current: %s)",
+					timeout, state, lastState[id])
+			}
+			return failures
+		case <-ticker.C:
+		}
+	}
+}
+
+// instanceIDs extracts instance IDs from instances.
+func instanceIDs(instances []*cloud.Instance) []string {
+	ids := make([]string, 0, len(instances))
+	for _, instance := range instances {
+		ids = append(ids, instance.ID)
+	}
+	return ids
+}
@@ -0,0 +1,234 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+
+	"github.com/estudosdevops/opsmaster/internal/httpclient"
+)
+
+// maxErrorBody limits how much of an error response is read.
+const maxErrorBody = 1024
+
+// S3Object locates an object parsed from an s3://bucket/key URL.
+type S3Object struct {
+	Bucket string
+	Key    string
+}
+
+// ParseS3URL parses an s3://bucket/key URL.
+func ParseS3URL(raw string) (S3Object, error) {
+	rest, ok := strings.CutPrefix(raw, "s3://")
+	if !ok {
+		return S3Object{}, fmt.Errorf("invalid S3 URL %q: must start with s3://", raw)
+	}
+	bucket, key, _ := strings.Cut(rest, "/")
+	if bucket == "" || key == "" {
+		return S3Object{}, fmt.Errorf("invalid S3 URL %q: expected s3://bucket/key", raw)
+	}
+	return S3Object{Bucket: bucket, Key: key}, nil
+}
+
+// S3Opener returns a function that downloads s3:// URLs with the given
+// profile's credentials ("" = default credential chain). Its signature
+// matches csv.Opener.
+func S3Opener(profile string) func(ctx context.Context, location string) (io.ReadCloser, error) {
+	return func(ctx context.Context, location string) (io.ReadCloser, error) {
+		object, err := ParseS3URL(location)
+		if err != nil {
+			return nil, err
+		}
+		return OpenS3Object(ctx, profile, object)
+	}
+}
+
+// OpenS3Object downloads an S3 object as a stream. The caller must close it.
+//
+// 🎓 CONCEPT: SigV4 Without the Service SDK
+// A GetObject call is just a signed HTTPS GET. Signing it with the core SDK's
+// v4 signer avoids pulling the whole S3 client for a single download.
+func OpenS3Object(ctx context.Context, profile string, object S3Object) (io.ReadCloser, error) {
+	sc, err := loadSigningConfig(ctx, profile, "")
+	if err != nil {
+		return nil, err
+	}
+	client := httpclient.Shared()
+	emptyPayloadHash := payloadHash(nil)
+
+	// A bucket outside the profile region answers with its real region;
+	// retry once there.
+	for attempt := 0; attempt < 2; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, s3ObjectURL(object, sc.region).String(), http.NoBody)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create S3 request: %w", err)
+		}
+		err = sc.sign(ctx, req, emptyPayloadHash, "s3", func(o *v4.SignerOptions) {
+			o.DisableURIPathEscaping = true // Key is already escaped by s3ObjectURL
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to download s3://%s/%s: %w", object.Bucket, object.Key, err)
+		}
+		if resp.StatusCode == http.StatusOK {
+			return resp.Body, nil
+		}
+
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		resp.Body.Close()
+
+		if bucketRegion := resp.Header.Get("X-Amz-Bucket-Region"); bucketRegion != "" && bucketRegion != sc.region && attempt == 0 {
+			sc.region = bucketRegion
+			continue
+		}
+		return nil, s3Error(object, resp.StatusCode, body)
+	}
+	return nil, fmt.Errorf("failed to download s3://%s/%s: bucket region redirect loop", object.Bucket, object.Key)
+}
+
+// s3ObjectURL builds the object URL. Virtual-hosted style is used unless the
+// bucket name has dots, which break the TLS wildcard certificate.
+func s3ObjectURL(object S3Object, region string) *url.URL {
+	escapedKey := s3EscapePath(object.Key)
+	u := &url.URL{Scheme: "https"}
+	if strings.Contains(object.Bucket, ".") {
+		u.Host = "s3." + region + ".amazonaws.com"
+		u.Path = "/" + object.Bucket + "/" + object.Key
+		u.RawPath = "/" + object.Bucket + "/" + escapedKey
+	} else {
+		u.Host = object.Bucket + ".s3." + region + ".amazonaws.com"
+		u.Path = "/" + object.Key
+		u.RawPath = "/" + escapedKey
+	}
+	return u
+}
+
+// s3EscapePath URI-encodes every key byte except unreserved characters
+// and '/', as SigV4 expects for S3.
+func s3EscapePath(key string) string {
+	var b strings.Builder
+	for i := 0; i < len(key); i++ {
+		c := key[i]
+		switch {
+		case c >= 'A' && c <= 'Z', c >= 'a' && c <= 'z', c >= '0' && c <= '9',
+			c == '-', c == '_', c == '.', c == '~', c == '/':
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+// s3Error turns an S3 error response into a readable error.
+func s3Error(object S3Object, status int, body []byte) error {
+	location := "s3://" + object.Bucket + "/" + object.Key
+	switch status {
+	case http.StatusNotFound:
+		return fmt.Errorf("%s not found", location)
+	case http.StatusForbidden:
+		return fmt.Errorf("access denied to %s (check s3:GetObject permission for the AWS profile)", location)
+	default:
+		return fmt.Errorf("failed to download %s: HTTP %d: %s", location, status, strings.TrimSpace(string(body)))
+	}
+}
+
+// maxPresignExpiry is the longest validity SigV4 accepts for presigned URLs.
+const maxPresignExpiry = 7 * 24 * time.Hour
+
+// PresignS3Object returns an HTTPS URL that downloads the object without
+// credentials until it expires, so instances only need curl or wget.
+// The object is checked first (signed HEAD), which also finds the bucket
+// region. URLs signed with temporary credentials (SSO, assumed roles) stop
+// working when those credentials expire, even before expires.
+func PresignS3Object(ctx context.Context, profile string, object S3Object, expires time.Duration) (string, error) {
+	presign, err := NewS3Presigner(ctx, profile, object)
+	if err != nil {
+		return "", err
+	}
+	return presign(expires)
+}
+
+// S3Presigner returns a new presigned URL of an object valid for expires.
+type S3Presigner func(expires time.Duration) (string, error)
+
+// NewS3Presigner checks the object like PresignS3Object and returns a
+// presigner for it. Signing is local (no API call), so callers can sign a
+// short-lived URL right before each use instead of one URL for a whole run.
+func NewS3Presigner(ctx context.Context, profile string, object S3Object) (S3Presigner, error) {
+	sc, err := loadSigningConfig(ctx, profile, "")
+	if err != nil {
+		return nil, err
+	}
+	if err := checkS3Object(ctx, sc, object); err != nil {
+		return nil, err
+	}
+	return func(expires time.Duration) (string, error) {
+		if expires <= 0 || expires > maxPresignExpiry {
+			return "", fmt.Errorf("invalid presigned URL expiry %s (max %s)", expires, maxPresignExpiry)
+		}
+		return presignS3URL(ctx, sc, object, expires, time.Now())
+	}, nil
+}
+
+// checkS3Object checks that the object exists with a signed HEAD, setting
+// sc.region to the bucket region.
+func checkS3Object(ctx context.Context, sc *signingConfig, object S3Object) error {
+	for attempt := 0; attempt < 2; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, http.MethodHead, s3ObjectURL(object, sc.region).String(), http.NoBody)
+		if err != nil {
+			return fmt.Errorf("failed to create S3 request: %w", err)
+		}
+		err = sc.sign(ctx, req, payloadHash(nil), "s3", func(o *v4.SignerOptions) {
+			o.DisableURIPathEscaping = true
+		})
+		if err != nil {
+			return err
+		}
+
+		resp, err := httpclient.Shared().Do(req)
+		if err != nil {
+			return fmt.Errorf("failed to check s3://%s/%s: %w", object.Bucket, object.Key, err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode == http.StatusOK {
+			return nil
+		}
+
+		if bucketRegion := resp.Header.Get("X-Amz-Bucket-Region"); bucketRegion != "" && bucketRegion != sc.region && attempt == 0 {
+			sc.region = bucketRegion
+			continue
+		}
+		return s3Error(object, resp.StatusCode, nil)
+	}
+	return fmt.Errorf("failed to check s3://%s/%s: bucket region redirect loop", object.Bucket, object.Key)
+}
+
+// presignS3URL builds a presigned GET URL for the object in sc.region.
+func presignS3URL(ctx context.Context, sc *signingConfig, object S3Object, expires time.Duration, now time.Time) (string, error) {
+	u := s3ObjectURL(object, sc.region)
+	u.RawQuery = url.Values{"X-Amz-Expires": {strconv.Itoa(int(expires.Seconds()))}}.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), http.NoBody)
+	if err != nil {
+		return "", fmt.Errorf("failed to create S3 request: %w", err)
+	}
+	signed, _, err := v4.NewSigner(func(o *v4.SignerOptions) {
+		o.DisableURIPathEscaping = true
+	}).PresignHTTP(ctx, sc.credentials, req, "UNSIGNED-PAYLOAD", "s3", sc.region, now)
+	if err != nil {
+		return "", fmt.Errorf("failed to presign s3://%s/%s: %w", object.Bucket, object.Key, err)
+	}
+	return signed, nil
+}
@@ -0,0 +1,113 @@
+package aws
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+)
+
+// TestParseS3URL tests bucket/key extraction
+func TestParseS3URL(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     string
+		want    S3Object
+		wantErr bool
+	}{
+		{name: "simple key", raw: "s3://inventory/fleet.csv", want: S3Object{Bucket: "inventory", Key: "fleet.csv"}},
+		{name: "nested key", raw: "s3://inventory/prod/fleet.csv.gz", want: S3Object{Bucket: "inventory", Key: "prod/fleet.csv.gz"}},
+		{name: "missing key", raw: "s3://inventory", wantErr: true},
+		{name: "missing bucket", raw: "s3:///fleet.csv", wantErr: true},
+		{name: "wrong scheme", raw: "https://inventory/fleet.csv", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// ACT
+			got, err := ParseS3URL(tt.raw)
+
+			// ASSERT
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestS3ObjectURL tests addressing style and key escaping
+func TestS3ObjectURL(t *testing.T) {
+	tests := []struct {
+		name   string
+		object S3Object
+		want   string
+	}{
+		{
+			name:   "virtual-hosted style",
+			object: S3Object{Bucket: "inventory", Key: "prod/fleet 01+a.csv"},
+			want:   "https://inventory.s3.sa-east-1.amazonaws.com/prod/fleet%2001%2Ba.csv",
+		},
+		{
+			name:   "path style for dotted buckets",
+			object: S3Object{Bucket: "inventory.example.com", Key: "fleet.csv"},
+			want:   "https://s3.sa-east-1.amazonaws.com/inventory.example.com/fleet.csv",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := s3ObjectURL(tt.object, "sa-east-1").String(); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestS3Error tests error messages for common statuses
+func TestS3Error(t *testing.T) {
+	object := S3Object{Bucket: "inventory", Key: "fleet.csv"}
+
+	if err := s3Error(object, http.StatusNotFound, nil); !strings.Contains(err.Error(), "not found") {
+		t.Errorf("unexpected 404 error: %v", err)
+	}
+	if err := s3Error(object, http.StatusForbidden, nil); !strings.Contains(err.Error(), "s3:GetObject") {
+		t.Errorf("unexpected 403 error: %v", err)
+	}
+}
+
+// TestPresignS3URL tests the presigned query parameters
+func TestPresignS3URL(t *testing.T) {
+	// ARRANGE
+	sc := &signingConfig{
+		credentials: aws.Credentials{AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "secret"},
+		region:      "sa-east-1",
+	}
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	// ACT
+	signed, err := presignS3URL(context.Background(), sc, S3Object{Bucket: "artifacts", Key: "agents/my agent"}, time.Hour, now)
+
+	// ASSERT
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	u, err := url.Parse(signed)
+	if err != nil {
+		t.Fatalf("invalid URL %q: %v", signed, err)
+	}
+	query := u.Query()
+	if u.Host != "artifacts.s3.sa-east-1.amazonaws.com" || u.EscapedPath() != "/agents/my%20agent" {
+		t.Errorf("unexpected object URL %s", signed)
+	}
+	if query.Get("X-Amz-Expires") != "3600" || query.Get("X-Amz-Date") != "20260102T030405Z" ||
+		!strings.HasPrefix(query.Get("X-Amz-Credential"), "AKIDEXAMPLE/20260102/sa-east-1/s3/") || query.Get("X-Amz-Signature") == "" {
+		t.Errorf("unexpected presign query %v", query)
+	}
+}
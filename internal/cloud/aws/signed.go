@@ -0,0 +1,205 @@
+package aws
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+
+	"github.com/estudosdevops/opsmaster/internal/httpclient"
+)
+
+// defaultSigningRegion is used when the profile has no region configured.
+const defaultSigningRegion = "us-east-1"
+
+// signingConfig holds what is needed to sign raw API requests for services
+// whose SDK clients opsmaster doesn't depend on (S3, DynamoDB, SNS, EventBridge).
+type signingConfig struct {
+	credentials aws.Credentials
+	region      string
+}
+
+// loadSigningConfig resolves credentials and region for a profile
+// ("" = default credential chain). region overrides the profile region.
+func loadSigningConfig(ctx context.Context, profile, region string) (*signingConfig, error) {
+	cfg, err := NewAWSConfig(ctx, AuthConfig{Profile: profile, Region: region})
+	if err != nil {
+		return nil, err
+	}
+	creds, err := cfg.Credentials.Retrieve(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve AWS credentials: %w", err)
+	}
+
+	if cfg.Region == "" {
+		cfg.Region = defaultSigningRegion
+	}
+	return &signingConfig{credentials: creds, region: cfg.Region}, nil
+}
+
+// sign adds SigV4 headers for service to req, whose body hashes to payloadHash.
+func (sc *signingConfig) sign(ctx context.Context, req *http.Request, payloadHash, service string, optFns ...func(*v4.SignerOptions)) error {
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if err := v4.NewSigner(optFns...).SignHTTP(ctx, sc.credentials, req, payloadHash, service, sc.region, time.Now()); err != nil {
+		return fmt.Errorf("failed to sign %s request: %w", service, err)
+	}
+	return nil
+}
+
+// payloadHash returns the hex SHA-256 of a request body.
+func payloadHash(body []byte) string {
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:])
+}
+
+// APIError is an error returned by an AWS service API.
+type APIError struct {
+	Service    string
+	StatusCode int
+	Type       string // e.g. "ResourceNotFoundException"
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%s: %s: %s (HTTP %d)", e.Service, e.Type, e.Message, e.StatusCode)
+}
+
+// IsAPIError reports whether err is an AWS API error of the given type.
+func IsAPIError(err error, errorType string) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.Type == errorType
+}
+
+// signedClient sends signed POST requests to one service endpoint.
+type signedClient struct {
+	signing  *signingConfig
+	service  string // Signing name, e.g. "dynamodb"
+	endpoint string
+	client   *http.Client
+}
+
+// newSignedClient builds a client for https://<service>.<region>.amazonaws.com/.
+func newSignedClient(sc *signingConfig, service string) *signedClient {
+	return &signedClient{
+		signing:  sc,
+		service:  service,
+		endpoint: "https://" + service + "." + sc.region + ".amazonaws.com/",
+		client:   httpclient.Shared(),
+	}
+}
+
+// post signs and sends body. Non-2xx responses are returned as *APIError.
+// The caller must close the response body.
+func (c *signedClient) post(ctx context.Context, body []byte, headers map[string]string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s request: %w", c.service, err)
+	}
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	if err := c.signing.sign(ctx, req, payloadHash(body), c.service); err != nil {
+		return nil, err
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%s request failed: %w", c.service, err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		defer resp.Body.Close()
+		return nil, decodeAPIError(c.service, resp)
+	}
+	return resp, nil
+}
+
+// callJSON invokes an AWS JSON protocol operation: input is encoded as the
+// body and the response decoded into output (nil to discard it).
+func (c *signedClient) callJSON(ctx context.Context, contentType, target string, input, output any) error {
+	body, err := json.Marshal(input)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s input: %w", target, err)
+	}
+
+	resp, err := c.post(ctx, body, map[string]string{
+		"Content-Type": contentType,
+		"X-Amz-Target": target,
+	})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if output == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(output); err != nil {
+		return fmt.Errorf("failed to decode %s output: %w", target, err)
+	}
+	return nil
+}
+
+// callQuery invokes an AWS Query protocol operation: form (with Action and
+// Version) is sent URL-encoded and the XML response decoded into output.
+func (c *signedClient) callQuery(ctx context.Context, form url.Values, output any) error {
+	resp, err := c.post(ctx, []byte(form.Encode()), map[string]string{
+		"Content-Type": "application/x-www-form-urlencoded; charset=utf-8",
+	})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if err := xml.NewDecoder(resp.Body).Decode(output); err != nil {
+		return fmt.Errorf("failed to decode %s output: %w", form.Get("Action"), err)
+	}
+	return nil
+}
+
+// decodeAPIError parses JSON ({"__type": "...#Type", "message": "..."}) and
+// Query/XML (<Error><Code>..</Code><Message>..</Message></Error>) error bodies.
+func decodeAPIError(service string, resp *http.Response) error {
+	raw, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	apiErr := &APIError{Service: service, StatusCode: resp.StatusCode}
+
+	var jsonErr struct {
+		Type         string `json:"__type"`
+		Message      string `json:"message"`
+		MessageUpper string `json:"Message"` // Some services capitalize the key
+	}
+	var xmlErr struct {
+		Code    string `xml:"Error>Code"`
+		Message string `xml:"Error>Message"`
+	}
+
+	switch {
+	case json.Unmarshal(raw, &jsonErr) == nil && jsonErr.Type != "":
+		apiErr.Type = jsonErr.Type
+		if _, errorType, ok := strings.Cut(jsonErr.Type, "#"); ok {
+			apiErr.Type = errorType
+		}
+		apiErr.Message = jsonErr.Message
+		if apiErr.Message == "" {
+			apiErr.Message = jsonErr.MessageUpper
+		}
+	case xml.Unmarshal(raw, &xmlErr) == nil && xmlErr.Code != "":
+		apiErr.Type = xmlErr.Code
+		apiErr.Message = xmlErr.Message
+	default:
+		apiErr.Type = http.StatusText(resp.StatusCode)
+		apiErr.Message = strings.TrimSpace(string(raw))
+	}
+	return apiErr
+}
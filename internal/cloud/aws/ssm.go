@@ -2,8 +2,11 @@ package aws
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"math"
+	"strconv"
 	"strings"
 	"time"
 
@@ -22,8 +25,78 @@ const (
 	// connectivityTestTimeout is the maximum time to wait for network connectivity test commands.
 	// Network operations may take longer than regular commands due to connection attempts.
 	connectivityTestTimeout = 30 * time.Second
+
+	// fetchFileTimeout is the maximum time to wait for a FetchFile command.
+	fetchFileTimeout = 30 * time.Second
+
+	// SSM documents running shell (Linux) and PowerShell (Windows) commands.
+	// The shell document can be replaced (see SSMDocument).
+	documentShellScript      = "AWS-RunShellScript"
+	documentPowerShellScript = "AWS-RunPowerShellScript"
+
+	// SSM limits: SendCommand TimeoutSeconds (delivery) accepts 30s to 30
+	// days, the executionTimeout parameter of both documents up to 48h.
+	minDeliveryTimeout  = 30 * time.Second
+	maxDeliveryTimeout  = 30 * 24 * time.Hour
+	maxExecutionTimeout = 48 * time.Hour
+
+	// commandWaitGrace extends the client-side wait beyond executionTimeout,
+	// so the agent's TimedOut status (with partial output) normally arrives
+	// before the client gives up and cancels the command.
+	commandWaitGrace = 30 * time.Second
+
+	// cancelCommandTimeout bounds the CancelCommand call after a client timeout.
+	cancelCommandTimeout = 10 * time.Second
 )
 
+// commandTimeouts links the timeout passed to ExecuteCommand to the SSM
+// command: the agent stops the script at executionTimeout, SSM drops the
+// command if no agent picks it up within the delivery timeout, and the
+// client cancels it server-side if no result arrives within wait.
+type commandTimeouts struct {
+	execution time.Duration // executionTimeout document parameter (whole seconds)
+	delivery  time.Duration // SendCommand TimeoutSeconds
+	wait      time.Duration // Client-side wait for the result
+}
+
+// newCommandTimeouts maps a caller timeout to the SSM timeouts, rounding up
+// to whole seconds and clamping to the SSM limits.
+func newCommandTimeouts(timeout time.Duration) commandTimeouts {
+	execution := time.Duration(math.Ceil(timeout.Seconds())) * time.Second
+	execution = min(max(execution, time.Second), maxExecutionTimeout)
+	return commandTimeouts{
+		execution: execution,
+		delivery:  min(max(execution, minDeliveryTimeout), maxDeliveryTimeout),
+		wait:      execution + commandWaitGrace,
+	}
+}
+
+// sendCommandInput builds the SendCommand request of commands, with the
+// executionTimeout parameter set from timeouts. Parameter names follow the
+// document's mapping; output is stored in the output bucket, if enabled.
+func sendCommandInput(instanceID string, document SSMDocument, output SSMOutput, commands []string, comment string, timeouts commandTimeouts) *ssm.SendCommandInput {
+	parameters := map[string][]string{
+		document.parameter(ParamCommands): commands,
+	}
+	if name := document.parameter(ParamExecutionTimeout); name != "" {
+		parameters[name] = []string{strconv.Itoa(int(timeouts.execution / time.Second))}
+	}
+	input := &ssm.SendCommandInput{
+		InstanceIds:    []string{instanceID},
+		DocumentName:   aws.String(document.Name),
+		Parameters:     parameters,
+		TimeoutSeconds: aws.Int32(int32(timeouts.delivery / time.Second)),
+		Comment:        aws.String(comment),
+	}
+	if output.Enabled() {
+		input.OutputS3BucketName = aws.String(output.Bucket)
+		if prefix := output.prefix(); prefix != "" {
+			input.OutputS3KeyPrefix = aws.String(prefix)
+		}
+	}
+	return input
+}
+
 // AWSProvider implements cloud.CloudProvider interface for AWS.
 // Uses AWS Systems Manager (SSM) for remote command execution and
 // EC2 API for instance tagging.
@@ -34,8 +107,17 @@ const (
 type AWSProvider struct {
 	sessionManager *SessionManager
 	log            *slog.Logger
-	ssmRetryer     retry.Retryer // For SSM operations (validation, commands)
-	ec2Retryer     retry.Retryer // For EC2 operations (tagging)
+	ssmRetryer     retry.Retryer        // For SSM operations (validation, commands)
+	ec2Retryer     retry.Retryer        // For EC2 operations (tagging)
+	metadataCache  *cloud.MetadataCache // Instance metadata shared across validation and tagging
+	commandLabel   cloud.CommandLabel   // Comment and marker for commands in SSM history
+
+	// Document running shell commands (default: AWS-RunShellScript), S3
+	// bucket storing the full output and the per account/region checks
+	// that they are usable
+	shellDocument SSMDocument
+	output        SSMOutput
+	checks        validationChecks
 }
 
 // NewAWSProvider creates a new AWS provider with connection pooling
@@ -45,6 +127,7 @@ func NewAWSProvider() *AWSProvider {
 		log:            logger.Get(),
 		ssmRetryer:     retry.New(retry.SSMPolicy),
 		ec2Retryer:     retry.New(retry.EC2Policy),
+		metadataCache:  cloud.NewMetadataCache(cloud.DefaultMetadataTTL),
 	}
 }
 
@@ -87,6 +170,7 @@ func NewAWSProviderWithProfile(ctx context.Context, profile string) (*AWSProvide
 		log:            logger.Get(),
 		ssmRetryer:     retry.New(retry.SSMPolicy),
 		ec2Retryer:     retry.New(retry.EC2Policy),
+		metadataCache:  cloud.NewMetadataCache(cloud.DefaultMetadataTTL),
 	}, nil
 }
 
@@ -130,9 +214,46 @@ func NewAWSProviderWithPolicies(ctx context.Context, profile string, ssmPolicy,
 		log:            logger.Get(),
 		ssmRetryer:     retry.New(ssmPolicy),
 		ec2Retryer:     retry.New(ec2Policy),
+		metadataCache:  cloud.NewMetadataCache(cloud.DefaultMetadataTTL),
 	}, nil
 }
 
+// SetMetadataCache replaces the instance metadata cache.
+// Used to share a persisted cache across commands in the same run.
+func (p *AWSProvider) SetMetadataCache(cache *cloud.MetadataCache) {
+	if cache != nil {
+		p.metadataCache = cache
+	}
+}
+
+// SetCommandLabel sets the run ID, operator and prefix recorded in the
+// comment (and marker line) of every SSM command sent by this provider.
+func (p *AWSProvider) SetCommandLabel(label cloud.CommandLabel) {
+	p.commandLabel = label
+}
+
+// SetSSMDocument replaces AWS-RunShellScript with a customer-managed
+// document (--ssm-document-name). ValidateInstance then checks that the
+// document exists in the instance's account and region.
+func (p *AWSProvider) SetSSMDocument(doc SSMDocument) {
+	p.shellDocument = doc
+}
+
+// SetSSMOutput makes SSM store the full output of every command in an
+// SSE-KMS encrypted S3 bucket (--ssm-output-bucket). The S3 location of
+// each command is reported with cloud.ObserveOutput.
+func (p *AWSProvider) SetSSMOutput(output SSMOutput) {
+	p.output = output
+}
+
+// shellScriptDocument returns the document running shell commands.
+func (p *AWSProvider) shellScriptDocument() SSMDocument {
+	if p.shellDocument.Name == "" {
+		return SSMDocument{Name: documentShellScript}
+	}
+	return p.shellDocument
+}
+
 // Name returns the provider name
 func (*AWSProvider) Name() string {
 	return "aws"
@@ -144,12 +265,14 @@ func (*AWSProvider) Name() string {
 // 2. Online (ping status = Online)
 // 3. SSM agent running and healthy
 //
+// With a custom shell document (SetSSMDocument), the document must also
+// exist in the instance's account and region (checked once per pair); with
+// an output bucket (SetSSMOutput), the bucket must use SSE-KMS (checked
+// once per profile).
+//
 // Returns error if instance is not reachable via SSM.
 func (p *AWSProvider) ValidateInstance(ctx context.Context, instance *cloud.Instance) error {
-	p.log.Debug("Starting SSM instance validation",
-		"instance_id", instance.ID,
-		"account", instance.Account,
-		"region", instance.Region)
+	logger.FromContext(ctx).Debug("Starting SSM instance validation")
 
 	// Use retry mechanism for SSM validation
 	return p.ssmRetryer.Do(ctx, func() error {
@@ -168,34 +291,40 @@ func (p *AWSProvider) validateInstanceInternal(ctx context.Context, instance *cl
 	}
 
 	// Query instance information from SSM
-	input := &ssm.DescribeInstanceInformationInput{
-		Filters: []types.InstanceInformationStringFilter{
-			{
-				Key:    aws.String("InstanceIds"),
-				Values: []string{instance.ID},
-			},
-		},
-	}
-
-	output, err := client.DescribeInstanceInformation(ctx, input)
+	info, err := describeInstanceInformation(ctx, client, instance.ID)
 	if err != nil {
-		return fmt.Errorf("SSM API error for instance %s: %w", instance.ID, err)
+		return err
 	}
 
 	// Check if instance was found
-	if len(output.InstanceInformationList) == 0 {
+	if info == nil {
 		return fmt.Errorf("instance %s not found in SSM - ensure SSM agent is installed and running", instance.ID)
 	}
 
 	// Check ping status (must be Online)
-	info := output.InstanceInformationList[0]
 	if info.PingStatus != types.PingStatusOnline {
 		return fmt.Errorf("instance %s is %s (expected Online) - SSM agent may be stopped or network issue",
 			instance.ID, info.PingStatus)
 	}
 
-	p.log.Debug("Instance SSM validation successful",
-		"instance_id", instance.ID,
+	if doc := p.shellScriptDocument(); doc.Custom() {
+		err := p.checks.check("document/"+profile+"/"+instance.Region, func() error {
+			return checkDocument(ctx, client, doc)
+		})
+		if err != nil {
+			return fmt.Errorf("%w (profile %s, region %s)", err, profile, instance.Region)
+		}
+	}
+	if p.output.Enabled() {
+		err := p.checks.check("output/"+profile, func() error {
+			return checkOutputBucket(ctx, profile, p.output)
+		})
+		if err != nil {
+			return fmt.Errorf("%w (profile %s)", err, profile)
+		}
+	}
+
+	logger.FromContext(ctx).Debug("Instance SSM validation successful",
 		"ping_status", info.PingStatus,
 		"platform", info.PlatformType)
 
@@ -203,7 +332,8 @@ func (p *AWSProvider) validateInstanceInternal(ctx context.Context, instance *cl
 }
 
 // ExecuteCommand executes shell commands remotely on the instance via SSM.
-// Uses AWS-RunShellScript document to execute commands.
+// Uses AWS-RunShellScript document (or the one set by SetSSMDocument) to
+// execute commands (see ExecuteCommandWithOptions for PowerShell).
 //
 // Parameters:
 //   - ctx: context for timeout/cancellation
@@ -213,25 +343,73 @@ func (p *AWSProvider) validateInstanceInternal(ctx context.Context, instance *cl
 //
 // Returns CommandResult with stdout, stderr, exit code, and duration.
 func (p *AWSProvider) ExecuteCommand(ctx context.Context, instance *cloud.Instance, commands []string, timeout time.Duration) (*cloud.CommandResult, error) {
-	p.log.Info("Starting command execution on instance",
-		"instance_id", instance.ID,
+	return p.ExecuteCommandWithOptions(ctx, instance, commands, timeout, cloud.ExecOptions{})
+}
+
+// ExecuteCommandWithOptions executes commands with ExecOptions applied.
+// AWS-RunShellScript has no environment/user/shell parameters, so options
+// are applied by wrapping the commands (see cloud.WrapCommands). With
+// cloud.ShellPowerShell, commands are wrapped by cloud.WrapPowerShell and
+// sent with AWS-RunPowerShellScript instead. Env values
+// stay out of installer scripts and logs, but are part of the SSM command.
+// SecretEnv becomes {{ssm:parameter}} references, resolved by SSM on the
+// instance side, so secret values never show up in command history.
+func (p *AWSProvider) ExecuteCommandWithOptions(ctx context.Context, instance *cloud.Instance, commands []string, timeout time.Duration, opts cloud.ExecOptions) (*cloud.CommandResult, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	logger.FromContext(ctx).Info("Starting command execution on instance",
 		"commands_count", len(commands),
-		"timeout", timeout)
+		"shell", opts.Shell,
+		"timeout", timeout,
+		"env_vars", len(opts.Env),
+		"run_as", opts.RunAsUser)
+
+	if len(opts.SecretEnv) > 0 {
+		env := make(map[string]string, len(opts.Env)+len(opts.SecretEnv))
+		for name, value := range opts.Env {
+			env[name] = value
+		}
+		for name, param := range opts.SecretEnv {
+			env[name] = ssmParameterReference(param)
+		}
+		opts.Env = env
+	}
+
+	document := p.shellScriptDocument()
+	if opts.IsPowerShell() {
+		document = SSMDocument{Name: documentPowerShellScript}
+		commands = cloud.WrapPowerShell(commands, opts)
+	} else {
+		commands = cloud.WrapCommands(commands, opts)
+	}
+	// The marker line is a comment in both sh and PowerShell
+	commands = p.commandLabel.MarkCommands(commands)
+	comment := p.commandLabel.Comment(opts.Comment)
 
 	// Use retry mechanism for command execution
 	var result *cloud.CommandResult
 	err := p.ssmRetryer.Do(ctx, func() error {
 		var execErr error
-		result, execErr = p.executeCommandInternal(ctx, instance, commands, timeout)
+		result, execErr = p.executeCommandInternal(ctx, instance, document, commands, timeout, comment)
 		return execErr
 	})
+	if result != nil {
+		if opts.IsPowerShell() {
+			// Windows output uses CRLF line endings
+			result.Stdout = strings.ReplaceAll(result.Stdout, "\r\n", "\n")
+			result.Stderr = strings.ReplaceAll(result.Stderr, "\r\n", "\n")
+		}
+		result.TruncateOutput(opts.MaxOutputBytes)
+	}
 
 	return result, err
 }
 
 // executeCommandInternal performs the actual command execution without retry.
 // This is wrapped by ExecuteCommand with retry logic.
-func (p *AWSProvider) executeCommandInternal(ctx context.Context, instance *cloud.Instance, commands []string, timeout time.Duration) (*cloud.CommandResult, error) {
+func (p *AWSProvider) executeCommandInternal(ctx context.Context, instance *cloud.Instance, document SSMDocument, commands []string, timeout time.Duration, comment string) (*cloud.CommandResult, error) {
 	// Get SSM client
 	profile := getProfileForInstance(instance)
 	client, err := p.sessionManager.GetSSMClient(ctx, profile, instance.Region)
@@ -239,29 +417,61 @@ func (p *AWSProvider) executeCommandInternal(ctx context.Context, instance *clou
 		return nil, fmt.Errorf("failed to get SSM client: %w", err)
 	}
 
-	// Send command via SSM
-	sendInput := &ssm.SendCommandInput{
-		InstanceIds:  []string{instance.ID},
-		DocumentName: aws.String("AWS-RunShellScript"),
-		Parameters: map[string][]string{
-			"commands": commands,
-		},
-		TimeoutSeconds: aws.Int32(int32(timeout.Seconds())),
-		Comment:        aws.String("OpsMaster package installation"),
-	}
-
-	sendOutput, err := client.SendCommand(ctx, sendInput)
+	// Send command via SSM (the agent enforces the same timeout)
+	timeouts := newCommandTimeouts(timeout)
+	sendOutput, err := client.SendCommand(ctx, sendCommandInput(instance.ID, document, p.output, commands, comment, timeouts))
 	if err != nil {
-		return nil, fmt.Errorf("failed to send SSM command: %w", err)
+		return nil, fmt.Errorf("failed to send SSM command: %w", instanceGone(err))
 	}
 
 	commandID := *sendOutput.Command.CommandId
-	p.log.Debug("SSM command sent",
-		"instance_id", instance.ID,
-		"command_id", commandID)
+	cloud.ObserveCommand(ctx, commandID)
+	if p.output.Enabled() {
+		cloud.ObserveOutput(ctx, p.output.Location(commandID, instance.ID))
+	}
+	logger.FromContext(ctx).Debug("SSM command sent",
+		"command_id", commandID,
+		"document", document.Name,
+		"execution_timeout", timeouts.execution,
+		"delivery_timeout", timeouts.delivery)
 
 	// Wait for command completion and get result
-	return p.waitForCommand(ctx, client, commandID, instance.ID, timeout)
+	return p.waitForCommand(ctx, client, commandID, instance.ID, timeouts.wait)
+}
+
+// cancelCommand cancels a command the client stopped waiting for, so it
+// doesn't keep running on the instance. Best effort: failures are logged.
+func cancelCommand(ctx context.Context, client *ssm.Client, commandID, instanceID string) {
+	cancelCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), cancelCommandTimeout)
+	defer cancel()
+
+	_, err := client.CancelCommand(cancelCtx, &ssm.CancelCommandInput{
+		CommandId:   aws.String(commandID),
+		InstanceIds: []string{instanceID},
+	})
+	if err != nil {
+		logger.FromContext(ctx).Warn("Failed to cancel SSM command", "command_id", commandID, "error", err)
+		return
+	}
+	logger.FromContext(ctx).Info("SSM command canceled on the instance", "command_id", commandID)
+}
+
+// instanceGone wraps InvalidInstanceId errors with cloud.ErrInstanceGone.
+// SSM returns it for instances that are terminated, stopped or no longer
+// managed; ValidateInstance already passed, so mid-run it means the
+// instance disappeared.
+func instanceGone(err error) error {
+	var invalid *types.InvalidInstanceId
+	if errors.As(err, &invalid) {
+		return fmt.Errorf("%w: %w", cloud.ErrInstanceGone, err)
+	}
+	return err
+}
+
+// ssmParameterReference returns the Run Command reference to a Parameter
+// Store parameter (String or SecureString), resolved when the command runs.
+func ssmParameterReference(name string) string {
+	return "{{ssm:" + name + "}}"
 }
 
 // TestConnectivity tests network connectivity from instance to a host:port.
@@ -270,17 +480,15 @@ func (p *AWSProvider) executeCommandInternal(ctx context.Context, instance *clou
 // Methods tried in order:
 // 1. nc (netcat) - most reliable
 // 2. telnet - fallback
-// 3. /dev/tcp - bash built-in (limited compatibility)
+// 3. /dev/tcp - bash built-in (only when bash is installed)
 //
 // This is useful for validating prerequisites, e.g., checking if instance
 // can reach Puppet Server before attempting installation.
 func (p *AWSProvider) TestConnectivity(ctx context.Context, instance *cloud.Instance, host string, port int) error {
-	p.log.Info("Testing connectivity",
-		"instance_id", instance.ID,
-		"target", fmt.Sprintf("%s:%d", host, port))
+	logger.FromContext(ctx).Info("Testing connectivity", "target", fmt.Sprintf("%s:%d", host, port))
 
 	// Script that tries multiple methods with fallback
-	testScript := fmt.Sprintf(`#!/bin/bash
+	testScript := fmt.Sprintf(`#!/bin/sh
 set +e  # Don't exit on error
 
 TARGET_HOST="%s"
@@ -302,7 +510,7 @@ fi
 # Method 2: Try telnet
 if command -v telnet >/dev/null 2>&1; then
     echo "Trying telnet..."
-    timeout 10 bash -c "echo -e '\x1dclose\x0d' | telnet ${TARGET_HOST} ${TARGET_PORT}" 2>&1 | grep -q "Connected\|Escape"
+    printf '\035close\015' | timeout 10 telnet "${TARGET_HOST}" "${TARGET_PORT}" 2>&1 | grep -q "Connected\|Escape"
     if [ $? -eq 0 ]; then
         echo "SUCCESS: telnet test passed"
         exit 0
@@ -310,12 +518,14 @@ if command -v telnet >/dev/null 2>&1; then
     echo "telnet failed, trying next method..."
 fi
 
-# Method 3: Try /dev/tcp (bash built-in)
-echo "Trying /dev/tcp..."
-timeout 10 bash -c "cat < /dev/null > /dev/tcp/${TARGET_HOST}/${TARGET_PORT}" 2>&1
-if [ $? -eq 0 ]; then
-    echo "SUCCESS: /dev/tcp test passed"
-    exit 0
+# Method 3: Try /dev/tcp (bash built-in, skipped on sh-only instances)
+if command -v bash >/dev/null 2>&1; then
+    echo "Trying /dev/tcp..."
+    timeout 10 bash -c "cat < /dev/null > /dev/tcp/${TARGET_HOST}/${TARGET_PORT}" 2>&1
+    if [ $? -eq 0 ]; then
+        echo "SUCCESS: /dev/tcp test passed"
+        exit 0
+    fi
 fi
 
 # All methods failed
@@ -330,22 +540,20 @@ exit 1
 	commands := []string{testScript}
 
 	// Increase timeout for network operations (connectivity tests may take longer)
-	result, err := p.ExecuteCommand(ctx, instance, commands, connectivityTestTimeout)
+	result, err := p.ExecuteCommandWithOptions(ctx, instance, commands, connectivityTestTimeout,
+		cloud.ExecOptions{Comment: fmt.Sprintf("connectivity test %s:%d", host, port)})
 	if err != nil {
 		return fmt.Errorf("connectivity test execution failed: %w", err)
 	}
 
 	// Check if any method succeeded
 	if strings.Contains(result.Stdout, "SUCCESS") {
-		p.log.Info("Connectivity test passed",
-			"instance_id", instance.ID,
-			"target", fmt.Sprintf("%s:%d", host, port))
+		logger.FromContext(ctx).Info("Connectivity test passed", "target", fmt.Sprintf("%s:%d", host, port))
 		return nil
 	}
 
 	// Test failed - provide detailed error
-	p.log.Error("Connectivity test failed",
-		"instance_id", instance.ID,
+	logger.FromContext(ctx).Error("Connectivity test failed",
 		"target", fmt.Sprintf("%s:%d", host, port),
 		"output", result.Stdout,
 		"error", result.Stderr)
@@ -354,8 +562,25 @@ exit 1
 		host, port, instance.ID, result.Stdout, result.Stderr)
 }
 
+// FetchFile reads a remote file by running cat/base64 over SSM
+// (see cloud.FetchFileScript). Limited to cloud.MaxFetchFileSize bytes.
+func (p *AWSProvider) FetchFile(ctx context.Context, instance *cloud.Instance, path string) ([]byte, error) {
+	logger.FromContext(ctx).Debug("Fetching remote file", "path", path)
+
+	result, err := p.ExecuteCommandWithOptions(ctx, instance, []string{cloud.FetchFileScript(path)}, fetchFileTimeout,
+		cloud.ExecOptions{Comment: "fetch file " + path})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", path, err)
+	}
+	if result.Failed() {
+		return nil, fmt.Errorf("failed to fetch %s: exit code %d: %s", path, result.ExitCode, strings.TrimSpace(result.Stderr))
+	}
+
+	return cloud.DecodeFetchedFile(path, result.Stdout)
+}
+
 // waitForCommand polls SSM until command completes or times out.
-// Uses exponential backoff polling pattern.
+// On timeout or cancellation the command is canceled server-side too.
 //
 // SSM commands are asynchronous - SendCommand returns immediately,
 // then we must poll GetCommandInvocation to get the result.
@@ -363,13 +588,17 @@ func (p *AWSProvider) waitForCommand(ctx context.Context, client *ssm.Client, co
 	start := time.Now()
 	ticker := time.NewTicker(2 * time.Second) // Poll every 2 seconds
 	defer ticker.Stop()
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
 
 	for {
 		select {
 		case <-ctx.Done():
+			cancelCommand(ctx, client, commandID, instanceID)
 			return nil, fmt.Errorf("command canceled: %w", ctx.Err())
 
-		case <-time.After(timeout):
+		case <-deadline.C:
+			cancelCommand(ctx, client, commandID, instanceID)
 			return nil, fmt.Errorf("command timeout after %v", timeout)
 
 		case <-ticker.C:
@@ -381,6 +610,11 @@ func (p *AWSProvider) waitForCommand(ctx context.Context, client *ssm.Client, co
 
 			output, err := client.GetCommandInvocation(ctx, input)
 			if err != nil {
+				var invalid *types.InvalidInstanceId
+				if errors.As(err, &invalid) {
+					// Instance went away while the command ran
+					return nil, fmt.Errorf("failed to get command %s: %w", commandID, instanceGone(err))
+				}
 				// Command might not be ready yet, continue polling
 				continue
 			}
@@ -404,8 +638,7 @@ func (p *AWSProvider) waitForCommand(ctx context.Context, client *ssm.Client, co
 					result.Error = fmt.Errorf("command %s with exit code %d", output.Status, result.ExitCode)
 				}
 
-				p.log.Debug("Command completed",
-					"instance_id", instanceID,
+				logger.FromContext(ctx).Debug("Command completed",
 					"command_id", commandID,
 					"status", output.Status,
 					"exit_code", result.ExitCode,
@@ -415,8 +648,7 @@ func (p *AWSProvider) waitForCommand(ctx context.Context, client *ssm.Client, co
 			}
 
 			// Command still running (InProgress, Pending), continue polling
-			p.log.Debug("Command still running",
-				"instance_id", instanceID,
+			logger.FromContext(ctx).Debug("Command still running",
 				"command_id", commandID,
 				"status", output.Status,
 				"elapsed", time.Since(start))
@@ -434,9 +666,7 @@ func (p *AWSProvider) waitForCommand(ctx context.Context, client *ssm.Client, co
 //
 // Note: Tags are applied at EC2 level, not SSM. Requires ec2:CreateTags permission.
 func (p *AWSProvider) TagInstance(ctx context.Context, instance *cloud.Instance, tags map[string]string) error {
-	p.log.Info("Starting instance tagging",
-		"instance_id", instance.ID,
-		"tags_count", len(tags))
+	logger.FromContext(ctx).Info("Starting instance tagging", "tags_count", len(tags))
 
 	// Use retry mechanism for EC2 tagging
 	return p.ec2Retryer.Do(ctx, func() error {
@@ -474,9 +704,10 @@ func (p *AWSProvider) tagInstanceInternal(ctx context.Context, instance *cloud.I
 		return fmt.Errorf("failed to tag instance %s: %w", instance.ID, err)
 	}
 
-	p.log.Info("Instance tagged successfully",
-		"instance_id", instance.ID,
-		"tags", tags)
+	// Keep cached tags consistent with what was just applied
+	p.metadataCache.MergeTags(instance, tags)
+
+	logger.FromContext(ctx).Info("Instance tagged successfully", "tags", tags)
 
 	return nil
 }
@@ -488,8 +719,7 @@ func (p *AWSProvider) tagInstanceInternal(ctx context.Context, instance *cloud.I
 //
 // Returns true if tag exists with exact key and value, false otherwise.
 func (p *AWSProvider) HasTag(ctx context.Context, instance *cloud.Instance, key, value string) (bool, error) {
-	p.log.Debug("Starting tag check with retry",
-		"instance_id", instance.ID,
+	logger.FromContext(ctx).Debug("Starting tag check with retry",
 		"tag_key", key,
 		"tag_value", value)
 
@@ -507,6 +737,16 @@ func (p *AWSProvider) HasTag(ctx context.Context, instance *cloud.Instance, key,
 // hasTagInternal performs the actual tag checking without retry.
 // This is wrapped by HasTag with retry logic.
 func (p *AWSProvider) hasTagInternal(ctx context.Context, instance *cloud.Instance, key, value string) (bool, error) {
+	// Answer from cached metadata when available (avoids DescribeTags per instance)
+	if info, ok := p.metadataCache.Get(instance); ok {
+		tagValue, exists := info.Tags[key]
+		found := exists && tagValue == value
+		logger.FromContext(ctx).Debug("Tag check served from metadata cache",
+			"tag_key", key,
+			"found", found)
+		return found, nil
+	}
+
 	// Get EC2 client
 	profile := getProfileForInstance(instance)
 	ec2Client, err := p.sessionManager.GetEC2Client(ctx, profile, instance.Region)
@@ -536,16 +776,14 @@ func (p *AWSProvider) hasTagInternal(ctx context.Context, instance *cloud.Instan
 	// Check if tag with specified value exists
 	for _, tag := range output.Tags {
 		if aws.ToString(tag.Key) == key && aws.ToString(tag.Value) == value {
-			p.log.Debug("Tag found on instance",
-				"instance_id", instance.ID,
+			logger.FromContext(ctx).Debug("Tag found on instance",
 				"tag_key", key,
 				"tag_value", value)
 			return true, nil
 		}
 	}
 
-	p.log.Debug("Tag not found on instance",
-		"instance_id", instance.ID,
+	logger.FromContext(ctx).Debug("Tag not found on instance",
 		"tag_key", key,
 		"tag_value", value)
 
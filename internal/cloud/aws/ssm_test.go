@@ -50,6 +50,7 @@ func TestAWSProvider_Name(t *testing.T) {
 // TestAWSProvider_InterfaceCompliance validates that AWSProvider implements CloudProvider
 func TestAWSProvider_InterfaceCompliance(t *testing.T) {
 	var _ cloud.CloudProvider = (*AWSProvider)(nil)
+	var _ cloud.AutoScalingDescriber = (*AWSProvider)(nil)
 	t.Log("AWSProvider correctly implements CloudProvider interface")
 }
 
@@ -567,6 +568,78 @@ func TestAWSProvider_TimeoutHandling(t *testing.T) {
 	}
 }
 
+// TestNewCommandTimeouts tests how the ExecuteCommand timeout maps to SSM
+// (executionTimeout, delivery timeout and client-side wait)
+func TestNewCommandTimeouts(t *testing.T) {
+	tests := []struct {
+		name          string
+		timeout       time.Duration
+		wantExecution time.Duration
+		wantDelivery  time.Duration
+	}{
+		{"whole seconds", 10 * time.Minute, 10 * time.Minute, 10 * time.Minute},
+		{"rounds up fractions", 1500 * time.Millisecond, 2 * time.Second, 30 * time.Second},
+		{"short timeout keeps minimum delivery", 5 * time.Second, 5 * time.Second, 30 * time.Second},
+		{"zero becomes one second", 0, time.Second, 30 * time.Second},
+		{"clamped to SSM maximum", 72 * time.Hour, 48 * time.Hour, 48 * time.Hour},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := newCommandTimeouts(tt.timeout)
+
+			if got.execution != tt.wantExecution {
+				t.Errorf("execution = %v, want %v", got.execution, tt.wantExecution)
+			}
+			if got.delivery != tt.wantDelivery {
+				t.Errorf("delivery = %v, want %v", got.delivery, tt.wantDelivery)
+			}
+			// The client must wait longer than the agent runs the command
+			if got.wait <= got.execution {
+				t.Errorf("wait = %v, want more than execution %v", got.wait, got.execution)
+			}
+		})
+	}
+}
+
+// TestSendCommandInput tests that the timeout reaches the SSM document parameters
+func TestSendCommandInput(t *testing.T) {
+	tests := []struct {
+		name             string
+		timeout          time.Duration
+		document         string
+		wantExecution    string
+		wantDeliverySecs int32
+	}{
+		{"shell script", 10 * time.Minute, documentShellScript, "600", 600},
+		{"powershell script", 90 * time.Second, documentPowerShellScript, "90", 90},
+		{"custom document", 10 * time.Minute, "MyOrg-RunShellScript", "600", 600},
+		{"connectivity test", connectivityTestTimeout, documentShellScript, "30", 30},
+		{"short timeout", 2 * time.Second, documentShellScript, "2", 30},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			commands := []string{"echo test"}
+
+			input := sendCommandInput("i-test", SSMDocument{Name: tt.document}, SSMOutput{}, commands, "comment", newCommandTimeouts(tt.timeout))
+
+			if got := input.Parameters["executionTimeout"]; len(got) != 1 || got[0] != tt.wantExecution {
+				t.Errorf("executionTimeout = %v, want [%s]", got, tt.wantExecution)
+			}
+			if got := *input.TimeoutSeconds; got != tt.wantDeliverySecs {
+				t.Errorf("TimeoutSeconds = %d, want %d", got, tt.wantDeliverySecs)
+			}
+			if got := input.Parameters["commands"]; len(got) != 1 || got[0] != commands[0] {
+				t.Errorf("commands = %v, want %v", got, commands)
+			}
+			if *input.DocumentName != tt.document || input.InstanceIds[0] != "i-test" {
+				t.Errorf("document/instance = %s/%v", *input.DocumentName, input.InstanceIds)
+			}
+		})
+	}
+}
+
 // ============================================================
 // HELPER FUNCTIONS
 // ============================================================
@@ -747,3 +820,10 @@ func BenchmarkGetProfileForInstance(b *testing.B) {
 		getProfileForInstance(instance)
 	}
 }
+
+// TestSSMParameterReference tests the Run Command parameter reference syntax
+func TestSSMParameterReference(t *testing.T) {
+	if got := ssmParameterReference("/opsmaster/enc-token"); got != "{{ssm:/opsmaster/enc-token}}" {
+		t.Errorf("ssmParameterReference() = %q", got)
+	}
+}
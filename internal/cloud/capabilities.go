@@ -0,0 +1,132 @@
+package cloud
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// Optional provider capabilities.
+//
+// CloudProvider is the contract every provider implements. Providers opt into
+// extra operations by implementing InstanceDescriber, InstanceStarter,
+// InstanceStopper, InstanceRebooter, AutoScalingDescriber or AgentWaiter; callers discover them once with
+// CapabilitiesOf instead of type-asserting, and report a missing capability
+// with Unsupported so features degrade with a clear message.
+//
+// Providers that forward calls to another implementation (plugins, proxies)
+// implement every method but may not support all of them at runtime: they
+// implement CapabilityReporter to narrow what is detected.
+
+// Capability names, as listed by Capabilities.Names.
+const (
+	CapabilityDescribe = "describe-instances"
+	CapabilityStart    = "start-instances"
+	CapabilityStop     = "stop-instances"
+	CapabilityReboot   = "reboot-instances"
+
+	CapabilityAutoScaling = "autoscaling-groups"
+	CapabilityWaitAgent   = "wait-agent"
+)
+
+// CapabilityReporter is implemented by providers whose optional methods are
+// not always backed (e.g., a plugin older than the interface). Capabilities
+// not returned are treated as unsupported even if the method exists.
+type CapabilityReporter interface {
+	// SupportedCapabilities returns the Capability* names the provider supports.
+	SupportedCapabilities() []string
+}
+
+// Capabilities holds the optional interfaces implemented by a provider.
+// Nil fields mean the capability is not supported.
+type Capabilities struct {
+	Describe InstanceDescriber
+	Start    InstanceStarter
+	Stop     InstanceStopper
+	Reboot   InstanceRebooter
+
+	AutoScaling AutoScalingDescriber
+	Agent       AgentWaiter
+}
+
+// CapabilitiesOf discovers the optional capabilities of a provider.
+func CapabilitiesOf(provider CloudProvider) Capabilities {
+	var caps Capabilities
+	caps.Describe, _ = provider.(InstanceDescriber)
+	caps.Start, _ = provider.(InstanceStarter)
+	caps.Stop, _ = provider.(InstanceStopper)
+	caps.Reboot, _ = provider.(InstanceRebooter)
+	caps.AutoScaling, _ = provider.(AutoScalingDescriber)
+	caps.Agent, _ = provider.(AgentWaiter)
+
+	if reporter, ok := provider.(CapabilityReporter); ok {
+		supported := reporter.SupportedCapabilities()
+		if !slices.Contains(supported, CapabilityDescribe) {
+			caps.Describe = nil
+		}
+		if !slices.Contains(supported, CapabilityStart) {
+			caps.Start = nil
+		}
+		if !slices.Contains(supported, CapabilityStop) {
+			caps.Stop = nil
+		}
+		if !slices.Contains(supported, CapabilityReboot) {
+			caps.Reboot = nil
+		}
+		if !slices.Contains(supported, CapabilityAutoScaling) {
+			caps.AutoScaling = nil
+		}
+		if !slices.Contains(supported, CapabilityWaitAgent) {
+			caps.Agent = nil
+		}
+	}
+	return caps
+}
+
+// Names returns the supported capability names (for logging).
+func (c Capabilities) Names() []string {
+	var names []string
+	if c.Describe != nil {
+		names = append(names, CapabilityDescribe)
+	}
+	if c.Start != nil {
+		names = append(names, CapabilityStart)
+	}
+	if c.Stop != nil {
+		names = append(names, CapabilityStop)
+	}
+	if c.Reboot != nil {
+		names = append(names, CapabilityReboot)
+	}
+	if c.AutoScaling != nil {
+		names = append(names, CapabilityAutoScaling)
+	}
+	if c.Agent != nil {
+		names = append(names, CapabilityWaitAgent)
+	}
+	return names
+}
+
+// ErrNotSupported is matched (errors.Is) by errors of features the provider
+// does not support.
+var ErrNotSupported = errors.New("not supported by provider")
+
+// UnsupportedError reports a feature that needs a capability the provider lacks.
+type UnsupportedError struct {
+	Provider string // Provider name
+	Feature  string // What could not be done, e.g. "starting instances"
+}
+
+func (e *UnsupportedError) Error() string {
+	return fmt.Sprintf("provider %s does not support %s", e.Provider, e.Feature)
+}
+
+// Is makes errors.Is(err, ErrNotSupported) match.
+func (e *UnsupportedError) Is(target error) bool {
+	return target == ErrNotSupported
+}
+
+// Unsupported returns the error for a feature provider can't perform.
+func Unsupported(provider CloudProvider, feature string) error {
+	return &UnsupportedError{Provider: provider.Name(), Feature: feature}
+}
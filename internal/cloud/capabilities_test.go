@@ -0,0 +1,84 @@
+package cloud
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+// baseProvider implements only the required CloudProvider methods.
+type baseProvider struct{}
+
+func (baseProvider) Name() string                                      { return "base" }
+func (baseProvider) ValidateInstance(context.Context, *Instance) error { return nil }
+func (baseProvider) ExecuteCommand(context.Context, *Instance, []string, time.Duration) (*CommandResult, error) {
+	return &CommandResult{}, nil
+}
+func (baseProvider) ExecuteCommandWithOptions(context.Context, *Instance, []string, time.Duration, ExecOptions) (*CommandResult, error) {
+	return &CommandResult{}, nil
+}
+func (baseProvider) TestConnectivity(context.Context, *Instance, string, int) error { return nil }
+func (baseProvider) FetchFile(context.Context, *Instance, string) ([]byte, error) {
+	return nil, ErrFileNotFound
+}
+func (baseProvider) TagInstance(context.Context, *Instance, map[string]string) error { return nil }
+func (baseProvider) HasTag(context.Context, *Instance, string, string) (bool, error) {
+	return false, nil
+}
+
+// powerProvider adds every optional capability.
+type powerProvider struct{ baseProvider }
+
+func (powerProvider) DescribeInstances(context.Context, []*Instance) (map[string]*InstanceInfo, error) {
+	return nil, nil
+}
+func (powerProvider) StartInstances(context.Context, []*Instance) map[string]error { return nil }
+func (powerProvider) WaitForState(context.Context, []*Instance, string, time.Duration) map[string]error {
+	return nil
+}
+func (powerProvider) StopInstances(context.Context, []*Instance) map[string]error   { return nil }
+func (powerProvider) RebootInstances(context.Context, []*Instance) map[string]error { return nil }
+func (powerProvider) WaitForAgent(context.Context, *Instance, time.Duration) error  { return nil }
+
+// pluginProvider forwards every method but its backend only describes instances.
+type pluginProvider struct{ powerProvider }
+
+func (pluginProvider) SupportedCapabilities() []string { return []string{CapabilityDescribe} }
+
+func TestCapabilitiesOf(t *testing.T) {
+	tests := []struct {
+		name     string
+		provider CloudProvider
+		want     []string
+	}{
+		{name: "required methods only", provider: baseProvider{}, want: nil},
+		{name: "all optional capabilities", provider: powerProvider{}, want: []string{CapabilityDescribe, CapabilityStart, CapabilityStop, CapabilityReboot, CapabilityWaitAgent}},
+		{name: "reporter narrows detection", provider: pluginProvider{}, want: []string{CapabilityDescribe}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			caps := CapabilitiesOf(tt.provider)
+			if got := caps.Names(); !slices.Equal(got, tt.want) {
+				t.Errorf("Names() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnsupported(t *testing.T) {
+	err := Unsupported(baseProvider{}, "output streaming")
+
+	if err.Error() != "provider base does not support output streaming" {
+		t.Errorf("Error() = %q", err.Error())
+	}
+	if !errors.Is(err, ErrNotSupported) {
+		t.Error("errors.Is(err, ErrNotSupported) = false")
+	}
+	var unsupported *UnsupportedError
+	if !errors.As(err, &unsupported) || unsupported.Feature != "output streaming" {
+		t.Errorf("errors.As() did not expose the feature: %+v", unsupported)
+	}
+}
@@ -0,0 +1,340 @@
+// Package cloudtest provides a configurable fake cloud.CloudProvider for
+// tests of executors, installers, validators and other provider consumers.
+//
+// The fake answers commands from scripted responses, records every call and
+// remembers applied tags, so tests only configure the behavior they assert
+// on instead of hand-rolling a full provider mock.
+//
+// Example:
+//
+//	provider := cloudtest.New().
+//		OnCommand("os-release", &cloud.CommandResult{Stdout: "ubuntu"}).
+//		OnCommandError("puppet.conf", errors.New("file does not exist"))
+//	// ... run the code under test ...
+//	if provider.CallCount(cloudtest.MethodTagInstance) != 1 { ... }
+//
+// Latencies (WithLatency, Response.Delay) make calls overlap in concurrency
+// stress tests (see package stresstest).
+package cloudtest
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"math/rand/v2"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/estudosdevops/opsmaster/internal/cloud"
+)
+
+// Method names recorded in Call.Method.
+const (
+	MethodValidateInstance = "ValidateInstance"
+	MethodExecuteCommand   = "ExecuteCommand" // Also ExecuteCommandWithOptions
+	MethodTestConnectivity = "TestConnectivity"
+	MethodFetchFile        = "FetchFile"
+	MethodTagInstance      = "TagInstance"
+	MethodHasTag           = "HasTag"
+)
+
+// Response is a scripted answer to commands. The first response whose
+// Contains is a substring of the joined commands (and whose InstanceID
+// matches, when set) answers the command.
+type Response struct {
+	Contains   string               // Substring of the commands ("" = any command)
+	InstanceID string               // Only for this instance ("" = any instance)
+	Result     *cloud.CommandResult // Returned result (nil = exit code 0, no output)
+	Err        error                // Returned error
+	Delay      time.Duration        // Added to the Latency of the call before answering
+}
+
+// Call is a recorded provider call. Only the fields of the method are set.
+type Call struct {
+	Method     string
+	InstanceID string
+	Commands   []string          // ExecuteCommand
+	Timeout    time.Duration     // ExecuteCommand
+	Options    cloud.ExecOptions // ExecuteCommand (zero for plain ExecuteCommand)
+	Host       string            // TestConnectivity
+	Port       int               // TestConnectivity
+	Path       string            // FetchFile
+	Tags       map[string]string // TagInstance
+	Key, Value string            // HasTag
+}
+
+// Provider is a fake cloud.CloudProvider. The zero value is ready to use:
+// every instance is valid and reachable, commands succeed with no output,
+// files are missing and no tags are set.
+//
+// The *Func hooks, when set, take precedence over the scripted behavior.
+// Provider is safe for concurrent use; configure it before use.
+type Provider struct {
+	ProviderName string            // Name() result (default "mock")
+	Responses    []Response        // Scripted command responses (see OnCommand)
+	Files        map[string][]byte // FetchFile contents by path
+
+	// Latency, when set, delays every call by the returned duration before
+	// answering (see WithLatency). A delayed call returns the context error
+	// when the context ends first.
+	Latency func(call Call) time.Duration
+
+	ValidateInstanceFunc func(ctx context.Context, instance *cloud.Instance) error
+	ExecuteCommandFunc   func(ctx context.Context, instance *cloud.Instance, commands []string, timeout time.Duration) (*cloud.CommandResult, error)
+	TestConnectivityFunc func(ctx context.Context, instance *cloud.Instance, host string, port int) error
+	TagInstanceFunc      func(ctx context.Context, instance *cloud.Instance, tags map[string]string) error
+	HasTagFunc           func(ctx context.Context, instance *cloud.Instance, key, value string) (bool, error)
+
+	mu    sync.Mutex
+	calls []Call
+	tags  map[string]map[string]string // Applied tags by instance ID
+}
+
+// Compile-time check that Provider implements cloud.CloudProvider.
+var _ cloud.CloudProvider = (*Provider)(nil)
+
+// New creates a fake provider with default behavior.
+func New() *Provider {
+	return &Provider{}
+}
+
+// OnCommand scripts the result of commands containing substr.
+func (p *Provider) OnCommand(substr string, result *cloud.CommandResult) *Provider {
+	p.Responses = append(p.Responses, Response{Contains: substr, Result: result})
+	return p
+}
+
+// OnCommandError scripts an execution error for commands containing substr.
+func (p *Provider) OnCommandError(substr string, err error) *Provider {
+	p.Responses = append(p.Responses, Response{Contains: substr, Err: err})
+	return p
+}
+
+// OnCommandDelay scripts the result of commands containing substr, answered
+// after delay (e.g., a slow install script).
+func (p *Provider) OnCommandDelay(substr string, delay time.Duration, result *cloud.CommandResult) *Provider {
+	p.Responses = append(p.Responses, Response{Contains: substr, Result: result, Delay: delay})
+	return p
+}
+
+// WithLatency delays every call by a random duration in [minDelay, maxDelay].
+func (p *Provider) WithLatency(minDelay, maxDelay time.Duration) *Provider {
+	p.Latency = func(Call) time.Duration {
+		if maxDelay <= minDelay {
+			return minDelay
+		}
+		// #nosec G404 - Test latencies don't need a cryptographic source
+		return minDelay + rand.N(maxDelay-minDelay+1)
+	}
+	return p
+}
+
+// WithFile sets the contents returned by FetchFile for path.
+func (p *Provider) WithFile(path string, content []byte) *Provider {
+	if p.Files == nil {
+		p.Files = make(map[string][]byte)
+	}
+	p.Files[path] = content
+	return p
+}
+
+// Name implements cloud.CloudProvider.
+func (p *Provider) Name() string {
+	if p.ProviderName != "" {
+		return p.ProviderName
+	}
+	return "mock"
+}
+
+// ValidateInstance implements cloud.CloudProvider.
+func (p *Provider) ValidateInstance(ctx context.Context, instance *cloud.Instance) error {
+	if err := p.call(ctx, Call{Method: MethodValidateInstance, InstanceID: instance.ID}, 0); err != nil {
+		return err
+	}
+	if p.ValidateInstanceFunc != nil {
+		return p.ValidateInstanceFunc(ctx, instance)
+	}
+	return nil
+}
+
+// ExecuteCommand implements cloud.CloudProvider.
+func (p *Provider) ExecuteCommand(ctx context.Context, instance *cloud.Instance, commands []string, timeout time.Duration) (*cloud.CommandResult, error) {
+	return p.ExecuteCommandWithOptions(ctx, instance, commands, timeout, cloud.ExecOptions{})
+}
+
+// ExecuteCommandWithOptions implements cloud.CloudProvider. Options are
+// recorded but don't change the response.
+func (p *Provider) ExecuteCommandWithOptions(ctx context.Context, instance *cloud.Instance, commands []string, timeout time.Duration, opts cloud.ExecOptions) (*cloud.CommandResult, error) {
+	call := Call{Method: MethodExecuteCommand, InstanceID: instance.ID, Commands: commands, Timeout: timeout, Options: opts}
+	if p.ExecuteCommandFunc != nil {
+		if err := p.call(ctx, call, 0); err != nil {
+			return nil, err
+		}
+		return p.ExecuteCommandFunc(ctx, instance, commands, timeout)
+	}
+
+	response := p.response(instance, commands)
+	if err := p.call(ctx, call, response.Delay); err != nil {
+		return nil, err
+	}
+	if response.Err != nil {
+		return nil, response.Err
+	}
+	return commandResult(instance, response.Result), nil
+}
+
+// response returns the first scripted response matching the commands
+// (zero Response = success, no output).
+func (p *Provider) response(instance *cloud.Instance, commands []string) Response {
+	script := strings.Join(commands, "\n")
+	for _, response := range p.Responses {
+		if response.InstanceID != "" && response.InstanceID != instance.ID {
+			continue
+		}
+		if strings.Contains(script, response.Contains) {
+			return response
+		}
+	}
+	return Response{}
+}
+
+// TestConnectivity implements cloud.CloudProvider.
+func (p *Provider) TestConnectivity(ctx context.Context, instance *cloud.Instance, host string, port int) error {
+	if err := p.call(ctx, Call{Method: MethodTestConnectivity, InstanceID: instance.ID, Host: host, Port: port}, 0); err != nil {
+		return err
+	}
+	if p.TestConnectivityFunc != nil {
+		return p.TestConnectivityFunc(ctx, instance, host, port)
+	}
+	return nil
+}
+
+// FetchFile implements cloud.CloudProvider with the contents of Files.
+func (p *Provider) FetchFile(ctx context.Context, instance *cloud.Instance, path string) ([]byte, error) {
+	if err := p.call(ctx, Call{Method: MethodFetchFile, InstanceID: instance.ID, Path: path}, 0); err != nil {
+		return nil, err
+	}
+	content, ok := p.Files[path]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", cloud.ErrFileNotFound, path)
+	}
+	if len(content) > cloud.MaxFetchFileSize {
+		return nil, fmt.Errorf("%w: %s", cloud.ErrFileTooLarge, path)
+	}
+	return content, nil
+}
+
+// TagInstance implements cloud.CloudProvider. Successfully applied tags are
+// remembered (see Tags) and reported by HasTag.
+func (p *Provider) TagInstance(ctx context.Context, instance *cloud.Instance, tags map[string]string) error {
+	if err := p.call(ctx, Call{Method: MethodTagInstance, InstanceID: instance.ID, Tags: maps.Clone(tags)}, 0); err != nil {
+		return err
+	}
+	if p.TagInstanceFunc != nil {
+		if err := p.TagInstanceFunc(ctx, instance, tags); err != nil {
+			return err
+		}
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.tags == nil {
+		p.tags = make(map[string]map[string]string)
+	}
+	if p.tags[instance.ID] == nil {
+		p.tags[instance.ID] = make(map[string]string)
+	}
+	maps.Copy(p.tags[instance.ID], tags)
+	return nil
+}
+
+// HasTag implements cloud.CloudProvider with the tags applied by TagInstance.
+func (p *Provider) HasTag(ctx context.Context, instance *cloud.Instance, key, value string) (bool, error) {
+	if err := p.call(ctx, Call{Method: MethodHasTag, InstanceID: instance.ID, Key: key, Value: value}, 0); err != nil {
+		return false, err
+	}
+	if p.HasTagFunc != nil {
+		return p.HasTagFunc(ctx, instance, key, value)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	current, ok := p.tags[instance.ID][key]
+	return ok && current == value, nil
+}
+
+// Calls returns the recorded calls of a method ("" = all), in call order.
+func (p *Provider) Calls(method string) []Call {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var calls []Call
+	for _, call := range p.calls {
+		if method == "" || call.Method == method {
+			calls = append(calls, call)
+		}
+	}
+	return calls
+}
+
+// CallCount returns the number of recorded calls of a method ("" = all).
+func (p *Provider) CallCount(method string) int {
+	return len(p.Calls(method))
+}
+
+// Tags returns a copy of the tags applied to an instance.
+func (p *Provider) Tags(instanceID string) map[string]string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return maps.Clone(p.tags[instanceID])
+}
+
+// Reset forgets recorded calls and applied tags, keeping the configuration.
+func (p *Provider) Reset() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.calls = nil
+	p.tags = nil
+}
+
+func (p *Provider) record(call Call) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.calls = append(p.calls, call)
+}
+
+// call records call and waits for its latency plus extra, returning the
+// context error when the context ends first.
+func (p *Provider) call(ctx context.Context, call Call, extra time.Duration) error {
+	p.record(call)
+	delay := extra
+	if p.Latency != nil {
+		delay += p.Latency(call)
+	}
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// commandResult returns a copy of result (default: success, no output) for
+// instance, so callers can't mutate the scripted response.
+func commandResult(instance *cloud.Instance, result *cloud.CommandResult) *cloud.CommandResult {
+	if result == nil {
+		return &cloud.CommandResult{InstanceID: instance.ID}
+	}
+	copied := *result
+	if copied.InstanceID == "" {
+		copied.InstanceID = instance.ID
+	}
+	return &copied
+}
@@ -0,0 +1,170 @@
+package cloudtest
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/estudosdevops/opsmaster/internal/cloud"
+)
+
+var testInstance = &cloud.Instance{ID: "i-1", Cloud: "aws", Account: "111", Region: "us-east-1"}
+
+// TestProvider_ExecuteCommand tests scripted responses
+//
+// 🎓 CONCEPT: First match wins
+// Responses are checked in order, so specific scripts (instance + command)
+// go before generic ones.
+func TestProvider_ExecuteCommand(t *testing.T) {
+	errBoom := errors.New("boom")
+	provider := New().
+		OnCommand("os-release", &cloud.CommandResult{Stdout: "ubuntu"}).
+		OnCommandError("puppet.conf", errBoom)
+	provider.Responses = append([]Response{{InstanceID: "i-2", Contains: "os-release", Result: &cloud.CommandResult{Stdout: "rhel"}}}, provider.Responses...)
+
+	tests := []struct {
+		name       string
+		instance   *cloud.Instance
+		commands   []string
+		wantStdout string
+		wantErr    error
+	}{
+		{name: "scripted response", instance: testInstance, commands: []string{"cat /etc/os-release"}, wantStdout: "ubuntu"},
+		{name: "instance-specific response", instance: &cloud.Instance{ID: "i-2"}, commands: []string{"cat /etc/os-release"}, wantStdout: "rhel"},
+		{name: "scripted error", instance: testInstance, commands: []string{"cat puppet.conf"}, wantErr: errBoom},
+		{name: "default success", instance: testInstance, commands: []string{"true"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// ACT
+			result, err := provider.ExecuteCommand(context.Background(), tt.instance, tt.commands, time.Minute)
+
+			// ASSERT
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if result.Stdout != tt.wantStdout || result.ExitCode != 0 || result.InstanceID != tt.instance.ID {
+				t.Errorf("unexpected result %+v", result)
+			}
+		})
+	}
+
+	if got := provider.CallCount(MethodExecuteCommand); got != len(tests) {
+		t.Errorf("CallCount = %d, want %d", got, len(tests))
+	}
+}
+
+// TestProvider_Tags tests that applied tags are recorded and reported
+func TestProvider_Tags(t *testing.T) {
+	ctx := context.Background()
+	provider := New()
+
+	if err := provider.TagInstance(ctx, testInstance, map[string]string{"puppet": "true"}); err != nil {
+		t.Fatalf("TagInstance() error: %v", err)
+	}
+
+	if has, _ := provider.HasTag(ctx, testInstance, "puppet", "true"); !has {
+		t.Error("expected HasTag to report applied tag")
+	}
+	if has, _ := provider.HasTag(ctx, testInstance, "puppet", "false"); has {
+		t.Error("expected HasTag to compare values")
+	}
+	if tags := provider.Tags(testInstance.ID); tags["puppet"] != "true" {
+		t.Errorf("Tags() = %v", tags)
+	}
+
+	provider.TagInstanceFunc = func(context.Context, *cloud.Instance, map[string]string) error {
+		return errors.New("access denied")
+	}
+	if err := provider.TagInstance(ctx, testInstance, map[string]string{"osquery": "true"}); err == nil {
+		t.Error("expected TagInstanceFunc error")
+	}
+	if _, ok := provider.Tags(testInstance.ID)["osquery"]; ok {
+		t.Error("failed tagging must not be recorded as applied")
+	}
+}
+
+// TestProvider_FetchFile tests file contents and the missing-file error
+func TestProvider_FetchFile(t *testing.T) {
+	provider := New().WithFile("/etc/hostname", []byte("web-1"))
+
+	content, err := provider.FetchFile(context.Background(), testInstance, "/etc/hostname")
+	if err != nil || string(content) != "web-1" {
+		t.Errorf("FetchFile() = %q, %v", content, err)
+	}
+	if _, err := provider.FetchFile(context.Background(), testInstance, "/missing"); !errors.Is(err, cloud.ErrFileNotFound) {
+		t.Errorf("expected ErrFileNotFound, got %v", err)
+	}
+}
+
+// TestProvider_Concurrent tests call recording from many goroutines
+func TestProvider_Concurrent(t *testing.T) {
+	provider := New()
+	var wg sync.WaitGroup
+	for range 50 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			provider.ValidateInstance(context.Background(), testInstance)
+			provider.TagInstance(context.Background(), testInstance, map[string]string{"k": "v"})
+		}()
+	}
+	wg.Wait()
+
+	if provider.CallCount(MethodValidateInstance) != 50 || provider.CallCount("") != 100 {
+		t.Errorf("unexpected call counts: %d validate, %d total", provider.CallCount(MethodValidateInstance), provider.CallCount(""))
+	}
+
+	provider.Reset()
+	if provider.CallCount("") != 0 || provider.Tags(testInstance.ID) != nil {
+		t.Error("Reset() should forget calls and tags")
+	}
+}
+
+// TestProvider_Latency tests scripted latencies and their cancellation.
+func TestProvider_Latency(t *testing.T) {
+	provider := New().
+		WithLatency(20*time.Millisecond, 20*time.Millisecond).
+		OnCommandDelay("install", 30*time.Millisecond, &cloud.CommandResult{Stdout: "done"})
+
+	tests := []struct {
+		name     string
+		call     func(ctx context.Context) error
+		minDelay time.Duration
+	}{
+		{"every call", func(ctx context.Context) error {
+			return provider.TagInstance(ctx, testInstance, map[string]string{"a": "b"})
+		}, 20 * time.Millisecond},
+		{"scripted delay", func(ctx context.Context) error {
+			_, err := provider.ExecuteCommand(ctx, testInstance, []string{"install"}, 0)
+			return err
+		}, 50 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			began := time.Now()
+			if err := tt.call(context.Background()); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if elapsed := time.Since(began); elapsed < tt.minDelay {
+				t.Errorf("call took %s, want at least %s", elapsed, tt.minDelay)
+			}
+		})
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := provider.ExecuteCommand(ctx, testInstance, []string{"install"}, 0); !errors.Is(err, context.Canceled) {
+		t.Errorf("ExecuteCommand(cancelled) error = %v, want context.Canceled", err)
+	}
+	if got := provider.CallCount(MethodExecuteCommand); got != 2 {
+		t.Errorf("CallCount() = %d, want delayed calls recorded too", got)
+	}
+}
@@ -0,0 +1,313 @@
+package cloud
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/user"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var (
+	envNamePattern  = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	userNamePattern = regexp.MustCompile(`^[a-z_][a-z0-9_.-]*\$?$`)
+	shellPattern    = regexp.MustCompile(`^[A-Za-z0-9_./-]+$`)
+	secretPattern   = regexp.MustCompile(`^[A-Za-z0-9_./-]+$`)
+	prefixPattern   = regexp.MustCompile(`^[A-Za-z0-9_.:-]*$`)
+)
+
+// DefaultCommandPrefix marks commands sent by opsmaster in provider history.
+const DefaultCommandPrefix = "opsmaster"
+
+// maxCommandComment is the longest comment providers accept (SSM: 100).
+const maxCommandComment = 100
+
+// ShellPowerShell runs commands with Windows PowerShell (ExecOptions.Shell).
+// Providers send such commands through their PowerShell API (AWS:
+// AWS-RunPowerShellScript) wrapped by WrapPowerShell instead of WrapCommands.
+const ShellPowerShell = "powershell"
+
+// PlatformWindows is the InstanceInfo.Platform of Windows instances.
+const PlatformWindows = "windows"
+
+// ShellForPlatform returns the shell for an instance platform:
+// ShellPowerShell for Windows, "" (default sh) otherwise.
+func ShellForPlatform(platform string) string {
+	if strings.EqualFold(platform, PlatformWindows) {
+		return ShellPowerShell
+	}
+	return ""
+}
+
+// ExecOptions controls how commands run on the instance.
+// The zero value runs commands as-is (root, default shell, provider cwd).
+type ExecOptions struct {
+	// Env is exported before the commands run. Keeps values out of script
+	// bodies that end up in logs, dry-run output and reports, but providers
+	// may still record them in command history - use SecretEnv for secrets.
+	Env map[string]string
+
+	// SecretEnv maps environment variable names to parameters in the
+	// provider's secret store (AWS: SSM Parameter Store). Only the reference
+	// is sent; the value is resolved on the provider side, so it never
+	// appears in command history.
+	SecretEnv map[string]string
+
+	Comment        string // Short description of the command (e.g., install step name)
+	WorkingDir     string // Directory commands run in (default: provider default)
+	RunAsUser      string // Run commands as this user via sudo (default: root)
+	Shell          string // Interpreter for the commands, e.g. "bash" or ShellPowerShell (default: sh)
+	MaxOutputBytes int    // Truncate stdout/stderr beyond this size (0 = no limit)
+}
+
+// IsZero reports whether no option is set.
+func (o ExecOptions) IsZero() bool {
+	return len(o.Env) == 0 && len(o.SecretEnv) == 0 && o.Comment == "" &&
+		o.WorkingDir == "" && o.RunAsUser == "" && o.Shell == "" && o.MaxOutputBytes == 0
+}
+
+// Validate checks names that end up in the generated shell wrapper.
+func (o ExecOptions) Validate() error {
+	for name := range o.Env {
+		if !envNamePattern.MatchString(name) {
+			return fmt.Errorf("invalid environment variable name %q", name)
+		}
+	}
+	for name, param := range o.SecretEnv {
+		if !envNamePattern.MatchString(name) {
+			return fmt.Errorf("invalid environment variable name %q", name)
+		}
+		if _, dup := o.Env[name]; dup {
+			return fmt.Errorf("environment variable %q set both as value and as secret", name)
+		}
+		if !secretPattern.MatchString(param) {
+			return fmt.Errorf("invalid secret parameter name %q for %s", param, name)
+		}
+	}
+	if o.RunAsUser != "" && !userNamePattern.MatchString(o.RunAsUser) {
+		return fmt.Errorf("invalid run-as user %q", o.RunAsUser)
+	}
+	if o.Shell != "" && !shellPattern.MatchString(o.Shell) {
+		return fmt.Errorf("invalid shell %q", o.Shell)
+	}
+	if o.IsPowerShell() && o.RunAsUser != "" {
+		return fmt.Errorf("run-as user is not supported with %s", ShellPowerShell)
+	}
+	if o.MaxOutputBytes < 0 {
+		return fmt.Errorf("max output bytes must not be negative")
+	}
+	return nil
+}
+
+// IsPowerShell reports whether the commands run with PowerShell.
+func (o ExecOptions) IsPowerShell() bool {
+	return o.Shell == ShellPowerShell
+}
+
+// WrapCommands applies environment, working directory, user and shell
+// options by wrapping the commands in a POSIX sh script. Providers whose
+// remote execution API lacks these features use it before sending commands.
+// Without such options the commands are returned unchanged.
+func WrapCommands(commands []string, opts ExecOptions) []string {
+	if len(opts.Env) == 0 && opts.WorkingDir == "" && opts.RunAsUser == "" && opts.Shell == "" {
+		return commands
+	}
+
+	var exports strings.Builder
+	names := make([]string, 0, len(opts.Env))
+	for name := range opts.Env {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(&exports, "export %s=%s\n", name, shellQuote(opts.Env[name]))
+	}
+
+	body := strings.Join(commands, "\n")
+
+	var script strings.Builder
+	if opts.WorkingDir != "" {
+		fmt.Fprintf(&script, "cd %s || exit 1\n", shellQuote(opts.WorkingDir))
+	}
+
+	switch {
+	case opts.RunAsUser != "":
+		// sudo resets the environment - exports go inside the user's shell
+		shell := opts.Shell
+		if shell == "" {
+			shell = "sh"
+		}
+		fmt.Fprintf(&script, "exec sudo -H -u %s -- %s -c %s\n",
+			shellQuote(opts.RunAsUser), shell, shellQuote(exports.String()+body))
+	case opts.Shell != "":
+		script.WriteString(exports.String())
+		fmt.Fprintf(&script, "exec %s -c %s\n", opts.Shell, shellQuote(body))
+	default:
+		script.WriteString(exports.String())
+		script.WriteString(body)
+		script.WriteString("\n")
+	}
+
+	return []string{script.String()}
+}
+
+// WrapPowerShell applies environment and working directory options to
+// PowerShell commands and normalizes their exit code like a shell script:
+// errors stop the script with exit code 1 and the exit code of the last
+// native command is returned (PowerShell otherwise exits 0 after a failing
+// executable). Always wraps, so every PowerShell command behaves the same.
+func WrapPowerShell(commands []string, opts ExecOptions) []string {
+	var script strings.Builder
+	script.WriteString("$ErrorActionPreference = 'Stop'\n")
+
+	names := make([]string, 0, len(opts.Env))
+	for name := range opts.Env {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(&script, "$env:%s = %s\n", name, powerShellQuote(opts.Env[name]))
+	}
+	if opts.WorkingDir != "" {
+		fmt.Fprintf(&script, "Set-Location -LiteralPath %s\n", powerShellQuote(opts.WorkingDir))
+	}
+
+	script.WriteString(strings.Join(commands, "\n"))
+	script.WriteString("\nif ($LASTEXITCODE) { exit $LASTEXITCODE }\n")
+	return []string{script.String()}
+}
+
+// powerShellQuote quotes a value as a PowerShell literal string.
+func powerShellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// CommandLabel identifies commands sent by opsmaster in provider history
+// (SSM command comments), so they can be audited or cleaned up later.
+type CommandLabel struct {
+	Prefix   string // Recognizable marker (default: "opsmaster"; "-" disables the marker line)
+	RunID    string // Unique ID of the invocation that sent the command
+	Operator string // Local user who started the run
+}
+
+// CurrentOperator returns the local user name recorded in command labels.
+func CurrentOperator() string {
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return u.Username
+	}
+	return os.Getenv("USER")
+}
+
+// Validate checks that the prefix is safe to embed in a shell comment.
+func (l CommandLabel) Validate() error {
+	if l.Prefix != "-" && !prefixPattern.MatchString(l.Prefix) {
+		return fmt.Errorf("invalid command prefix %q: use letters, digits, '.', '_', ':' or '-'", l.Prefix)
+	}
+	return nil
+}
+
+// prefix returns the effective prefix ("" when disabled).
+func (l CommandLabel) prefix() string {
+	switch l.Prefix {
+	case "":
+		return DefaultCommandPrefix
+	case "-":
+		return ""
+	default:
+		return l.Prefix
+	}
+}
+
+// Comment builds the command comment, e.g.
+// "opsmaster: install step configure-repo run=1b9d... by=alice",
+// truncated to the provider limit.
+func (l CommandLabel) Comment(action string) string {
+	var parts []string
+	if prefix := l.prefix(); prefix != "" {
+		parts = append(parts, prefix+":")
+	}
+	if action == "" {
+		action = "command"
+	}
+	parts = append(parts, action)
+	if l.RunID != "" {
+		parts = append(parts, "run="+l.RunID)
+	}
+	if l.Operator != "" {
+		parts = append(parts, "by="+l.Operator)
+	}
+
+	comment := strings.Join(parts, " ")
+	if len(comment) > maxCommandComment {
+		comment = comment[:maxCommandComment]
+	}
+	return comment
+}
+
+// MarkCommands prepends a shell comment line with the prefix and run ID,
+// so the command body itself is recognizable in history.
+// Returns commands unchanged when the prefix is disabled.
+func (l CommandLabel) MarkCommands(commands []string) []string {
+	prefix := l.prefix()
+	if prefix == "" {
+		return commands
+	}
+	marker := "# " + prefix
+	if l.RunID != "" {
+		marker += " run=" + l.RunID
+	}
+	return append([]string{marker}, commands...)
+}
+
+// TruncateOutput limits Stdout and Stderr to maxBytes each (0 = no limit),
+// noting how much was dropped.
+func (cr *CommandResult) TruncateOutput(maxBytes int) {
+	if maxBytes <= 0 {
+		return
+	}
+	cr.Stdout = truncate(cr.Stdout, maxBytes)
+	cr.Stderr = truncate(cr.Stderr, maxBytes)
+}
+
+func truncate(s string, maxBytes int) string {
+	if len(s) <= maxBytes {
+		return s
+	}
+	return s[:maxBytes] + fmt.Sprintf("\n... [truncated %d bytes]", len(s)-maxBytes)
+}
+
+type commandObserverKey struct{}
+
+// WithCommandObserver returns a context in which providers report the ID of
+// each command they send (AWS: SSM command ID) to observe, e.g. for liveness
+// logs of long executions.
+func WithCommandObserver(ctx context.Context, observe func(commandID string)) context.Context {
+	return context.WithValue(ctx, commandObserverKey{}, observe)
+}
+
+// ObserveCommand reports a command sent by a provider to the observer of ctx,
+// if any (see WithCommandObserver).
+func ObserveCommand(ctx context.Context, commandID string) {
+	if observe, ok := ctx.Value(commandObserverKey{}).(func(string)); ok {
+		observe(commandID)
+	}
+}
+
+type outputObserverKey struct{}
+
+// WithOutputObserver returns a context in which providers report where they
+// store the full output of each command they send (AWS: S3 folder with
+// --ssm-output-bucket), e.g. to link it from the run report.
+func WithOutputObserver(ctx context.Context, observe func(location string)) context.Context {
+	return context.WithValue(ctx, outputObserverKey{}, observe)
+}
+
+// ObserveOutput reports the output location of a command to the observer
+// of ctx, if any (see WithOutputObserver).
+func ObserveOutput(ctx context.Context, location string) {
+	if observe, ok := ctx.Value(outputObserverKey{}).(func(string)); ok {
+		observe(location)
+	}
+}
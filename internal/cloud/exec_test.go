@@ -0,0 +1,208 @@
+package cloud
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+// TestWrapCommands_RunsLocally runs wrapped commands with /bin/sh to check
+// env, working directory and shell options end to end.
+func TestWrapCommands_RunsLocally(t *testing.T) {
+	dir := t.TempDir()
+
+	tests := []struct {
+		name     string
+		commands []string
+		opts     ExecOptions
+		want     string
+	}{
+		{
+			name:     "no options keeps commands",
+			commands: []string{"echo a", "echo b"},
+			want:     "a\nb",
+		},
+		{
+			name:     "env values are exported and quoted",
+			commands: []string{`echo "$TOKEN"`},
+			opts:     ExecOptions{Env: map[string]string{"TOKEN": "it's $ecret"}},
+			want:     "it's $ecret",
+		},
+		{
+			name:     "working directory",
+			commands: []string{"pwd"},
+			opts:     ExecOptions{WorkingDir: dir},
+			want:     dir,
+		},
+		{
+			name:     "custom shell receives env",
+			commands: []string{`echo "$A-$B"`},
+			opts:     ExecOptions{Shell: "sh", Env: map[string]string{"A": "1", "B": "2"}},
+			want:     "1-2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// ACT
+			script := strings.Join(WrapCommands(tt.commands, tt.opts), "\n")
+			out, err := exec.Command("/bin/sh", "-c", script).Output()
+
+			// ASSERT
+			if err != nil {
+				t.Fatalf("script failed: %v\n%s", err, script)
+			}
+			if got := strings.TrimSpace(string(out)); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestWrapCommands_RunAsUser tests that exports go inside the sudo shell
+// (sudo resets the environment)
+func TestWrapCommands_RunAsUser(t *testing.T) {
+	script := WrapCommands([]string{"id"}, ExecOptions{RunAsUser: "puppet", Shell: "bash", Env: map[string]string{"X": "1"}})[0]
+
+	if !strings.Contains(script, "exec sudo -H -u 'puppet' -- bash -c 'export X='") {
+		t.Errorf("unexpected wrapper:\n%s", script)
+	}
+}
+
+// TestExecOptions_Validate tests rejection of names that would break the wrapper
+func TestExecOptions_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    ExecOptions
+		wantErr bool
+	}{
+		{name: "zero value", opts: ExecOptions{}},
+		{name: "valid options", opts: ExecOptions{Env: map[string]string{"API_TOKEN": "x"}, RunAsUser: "puppet", Shell: "/bin/bash"}},
+		{name: "invalid env name", opts: ExecOptions{Env: map[string]string{"A B": "x"}}, wantErr: true},
+		{name: "invalid user", opts: ExecOptions{RunAsUser: "root; rm -rf /"}, wantErr: true},
+		{name: "invalid shell", opts: ExecOptions{Shell: "bash -x"}, wantErr: true},
+		{name: "negative output limit", opts: ExecOptions{MaxOutputBytes: -1}, wantErr: true},
+		{name: "valid secret", opts: ExecOptions{SecretEnv: map[string]string{"TOKEN": "/opsmaster/enc-token"}}},
+		{name: "invalid secret parameter", opts: ExecOptions{SecretEnv: map[string]string{"TOKEN": "{{ssm:x}}"}}, wantErr: true},
+		{name: "secret also set as value", opts: ExecOptions{Env: map[string]string{"TOKEN": "x"}, SecretEnv: map[string]string{"TOKEN": "/p"}}, wantErr: true},
+		{name: "powershell", opts: ExecOptions{Shell: ShellPowerShell, Env: map[string]string{"A": "1"}, WorkingDir: `C:\temp`}},
+		{name: "powershell with run-as user", opts: ExecOptions{Shell: ShellPowerShell, RunAsUser: "admin"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.opts.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestWrapPowerShell tests env, working directory and exit code handling
+//
+// 🎓 CONCEPT: PowerShell exit codes
+// PowerShell exits 0 after a failing executable unless the script returns
+// $LASTEXITCODE, and only stops on cmdlet errors with $ErrorActionPreference
+// = 'Stop'. The wrapper makes both behave like a failing shell script.
+func TestWrapPowerShell(t *testing.T) {
+	script := strings.Join(WrapPowerShell([]string{"choco install git", "git --version"}, ExecOptions{
+		Shell:      ShellPowerShell,
+		Env:        map[string]string{"B": "it's", "A": "1"},
+		WorkingDir: `C:\Program Files\app`,
+	}), "\n")
+
+	want := "$ErrorActionPreference = 'Stop'\n" +
+		"$env:A = '1'\n" +
+		"$env:B = 'it''s'\n" +
+		"Set-Location -LiteralPath 'C:\\Program Files\\app'\n" +
+		"choco install git\ngit --version\n" +
+		"if ($LASTEXITCODE) { exit $LASTEXITCODE }\n"
+	if script != want {
+		t.Errorf("got:\n%s\nwant:\n%s", script, want)
+	}
+}
+
+// TestShellForPlatform tests the shell chosen per instance platform
+func TestShellForPlatform(t *testing.T) {
+	tests := map[string]string{"windows": ShellPowerShell, "Windows": ShellPowerShell, "linux": "", "": ""}
+	for platform, want := range tests {
+		if got := ShellForPlatform(platform); got != want {
+			t.Errorf("ShellForPlatform(%q) = %q, want %q", platform, got, want)
+		}
+	}
+}
+
+// TestCommandResult_TruncateOutput tests output limits
+func TestCommandResult_TruncateOutput(t *testing.T) {
+	result := &CommandResult{Stdout: "0123456789", Stderr: "short"}
+
+	result.TruncateOutput(4)
+
+	if result.Stdout != "0123\n... [truncated 6 bytes]" {
+		t.Errorf("unexpected stdout %q", result.Stdout)
+	}
+	if result.Stderr != "shor\n... [truncated 1 bytes]" {
+		t.Errorf("unexpected stderr %q", result.Stderr)
+	}
+}
+
+// TestCommandLabel_Comment tests comment format, disabled prefix and length limit
+func TestCommandLabel_Comment(t *testing.T) {
+	tests := []struct {
+		name   string
+		label  CommandLabel
+		action string
+		want   string
+	}{
+		{
+			name:   "default prefix with run and operator",
+			label:  CommandLabel{RunID: "r-1", Operator: "alice"},
+			action: "install step configure-repo",
+			want:   "opsmaster: install step configure-repo run=r-1 by=alice",
+		},
+		{
+			name:  "empty action",
+			label: CommandLabel{Prefix: "ops"},
+			want:  "ops: command",
+		},
+		{
+			name:   "disabled prefix",
+			label:  CommandLabel{Prefix: "-"},
+			action: "fetch file /etc/hosts",
+			want:   "fetch file /etc/hosts",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.label.Comment(tt.action); got != tt.want {
+				t.Errorf("Comment() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("truncated to provider limit", func(t *testing.T) {
+		got := CommandLabel{}.Comment(strings.Repeat("x", 200))
+		if len(got) != maxCommandComment {
+			t.Errorf("len = %d, want %d", len(got), maxCommandComment)
+		}
+	})
+}
+
+// TestCommandLabel_MarkCommands tests the marker line
+func TestCommandLabel_MarkCommands(t *testing.T) {
+	marked := CommandLabel{RunID: "r-1"}.MarkCommands([]string{"echo ok"})
+	if len(marked) != 2 || marked[0] != "# opsmaster run=r-1" {
+		t.Errorf("unexpected marked commands %q", marked)
+	}
+
+	unmarked := CommandLabel{Prefix: "-"}.MarkCommands([]string{"echo ok"})
+	if len(unmarked) != 1 {
+		t.Errorf("expected no marker when disabled, got %q", unmarked)
+	}
+
+	if err := (CommandLabel{Prefix: "ops; rm -rf /"}).Validate(); err == nil {
+		t.Error("expected invalid prefix error")
+	}
+}
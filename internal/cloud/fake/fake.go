@@ -0,0 +1,253 @@
+// Package fake implements a simulated cloud provider (--provider fake).
+//
+// Instances exist only in memory: remote commands "run" with the latency and
+// failure profile of a YAML scenario, and tags are kept for the lifetime of
+// the process. Full CLI flows (parse → validate → install → tag → report)
+// can be exercised in CI and demos without any cloud account.
+//
+// The provider does not interpret scripts. It answers OS detection from the
+// instance profile, returns scripted outputs for other commands and succeeds
+// with no output otherwise.
+package fake
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"maps"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/estudosdevops/opsmaster/internal/cloud"
+	"github.com/estudosdevops/opsmaster/internal/logger"
+)
+
+// Name is the provider name (and the --provider value).
+const Name = "fake"
+
+// defaultOS is the OS of instances without one in the scenario.
+const defaultOS = "ubuntu"
+
+// builtinResponses make the verification of the bundled installers pass.
+var builtinResponses = []Response{
+	{Contains: "puppet --version", Stdout: "8.10.0\n"},
+}
+
+// Provider is the simulated cloud provider.
+type Provider struct {
+	scenario *Scenario
+	seed     int64
+
+	mu   sync.Mutex
+	tags map[string]map[string]string // Current tags by instance ID
+}
+
+// Compile-time checks of the implemented capabilities.
+var (
+	_ cloud.CloudProvider     = (*Provider)(nil)
+	_ cloud.InstanceDescriber = (*Provider)(nil)
+)
+
+// New creates a provider simulating scenario (nil = defaults only: every
+// instance is an Ubuntu host that answers instantly and never fails).
+func New(scenario *Scenario) *Provider {
+	if scenario == nil {
+		scenario = &Scenario{}
+	}
+	seed := scenario.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	return &Provider{scenario: scenario, seed: seed, tags: make(map[string]map[string]string)}
+}
+
+// Name implements cloud.CloudProvider.
+func (*Provider) Name() string {
+	return Name
+}
+
+// ValidateInstance implements cloud.CloudProvider. Offline instances (see
+// Profile.SSMOfflineRate) fail like an unregistered SSM agent.
+func (p *Provider) ValidateInstance(ctx context.Context, instance *cloud.Instance) error {
+	profile := p.scenario.profile(instance.ID)
+	if err := p.wait(ctx, instance, profile, "validate"); err != nil {
+		return err
+	}
+	if p.draw(instance.ID, "ssm-offline") < float64(profile.SSMOfflineRate) {
+		return fmt.Errorf("instance %s is not registered with SSM (simulated)", instance.ID)
+	}
+	return nil
+}
+
+// ExecuteCommand implements cloud.CloudProvider.
+func (p *Provider) ExecuteCommand(ctx context.Context, instance *cloud.Instance, commands []string, timeout time.Duration) (*cloud.CommandResult, error) {
+	return p.ExecuteCommandWithOptions(ctx, instance, commands, timeout, cloud.ExecOptions{})
+}
+
+// ExecuteCommandWithOptions implements cloud.CloudProvider.
+func (p *Provider) ExecuteCommandWithOptions(ctx context.Context, instance *cloud.Instance, commands []string, _ time.Duration, opts cloud.ExecOptions) (*cloud.CommandResult, error) {
+	profile := p.scenario.profile(instance.ID)
+	script := strings.Join(commands, "\n")
+	logger.FromContext(ctx).Debug("Simulating command", "comment", opts.Comment)
+
+	start := time.Now()
+	if err := p.wait(ctx, instance, profile, script); err != nil {
+		return nil, err
+	}
+	result := p.respond(instance, profile, script)
+	result.InstanceID = instance.ID
+	result.Duration = time.Since(start)
+	return result, nil
+}
+
+// respond builds the simulated result of a script.
+func (p *Provider) respond(instance *cloud.Instance, profile Profile, script string) *cloud.CommandResult {
+	for _, failing := range profile.FailCommands {
+		if strings.Contains(script, failing) {
+			return &cloud.CommandResult{ExitCode: 1, Stderr: fmt.Sprintf("simulated failure (%q)", failing)}
+		}
+	}
+	if p.draw(instance.ID, script) < float64(profile.CommandFailureRate) {
+		return &cloud.CommandResult{ExitCode: 1, Stderr: "simulated random failure"}
+	}
+
+	for _, responses := range [][]Response{p.scenario.Responses, builtinResponses} {
+		for _, response := range responses {
+			if strings.Contains(script, response.Contains) {
+				return &cloud.CommandResult{Stdout: response.Stdout, Stderr: response.Stderr, ExitCode: response.ExitCode}
+			}
+		}
+	}
+	if isOSDetection(script) {
+		return &cloud.CommandResult{Stdout: osFamily(profile.OS) + "\nshell=bash\n"}
+	}
+	return &cloud.CommandResult{}
+}
+
+// TestConnectivity implements cloud.CloudProvider. Simulated instances
+// reach every host.
+func (p *Provider) TestConnectivity(ctx context.Context, instance *cloud.Instance, host string, port int) error {
+	return p.wait(ctx, instance, p.scenario.profile(instance.ID), fmt.Sprintf("connect %s:%d", host, port))
+}
+
+// FetchFile implements cloud.CloudProvider. Simulated instances have no files.
+func (p *Provider) FetchFile(ctx context.Context, instance *cloud.Instance, path string) ([]byte, error) {
+	if err := p.wait(ctx, instance, p.scenario.profile(instance.ID), "fetch "+path); err != nil {
+		return nil, err
+	}
+	return nil, fmt.Errorf("%w: %s", cloud.ErrFileNotFound, path)
+}
+
+// TagInstance implements cloud.CloudProvider.
+func (p *Provider) TagInstance(ctx context.Context, instance *cloud.Instance, tags map[string]string) error {
+	profile := p.scenario.profile(instance.ID)
+	if err := p.wait(ctx, instance, profile, "tag"); err != nil {
+		return err
+	}
+	if p.draw(instance.ID, "tag") < float64(profile.TagFailureRate) {
+		return fmt.Errorf("simulated tagging failure on %s", instance.ID)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	maps.Copy(p.currentTags(instance.ID), tags)
+	return nil
+}
+
+// HasTag implements cloud.CloudProvider.
+func (p *Provider) HasTag(_ context.Context, instance *cloud.Instance, key, value string) (bool, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	current, ok := p.currentTags(instance.ID)[key]
+	return ok && current == value, nil
+}
+
+// DescribeInstances implements cloud.InstanceDescriber: every simulated
+// instance is running.
+func (p *Provider) DescribeInstances(_ context.Context, instances []*cloud.Instance) (map[string]*cloud.InstanceInfo, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	infos := make(map[string]*cloud.InstanceInfo, len(instances))
+	for _, instance := range instances {
+		infos[instance.ID] = &cloud.InstanceInfo{
+			ID:        instance.ID,
+			State:     cloud.InstanceStateRunning,
+			Platform:  platform(p.scenario.profile(instance.ID).OS),
+			Tags:      maps.Clone(p.currentTags(instance.ID)),
+			FetchedAt: time.Now(),
+		}
+	}
+	return infos, nil
+}
+
+// currentTags returns the tags of an instance, initialized from the
+// scenario. Caller must hold mu.
+func (p *Provider) currentTags(instanceID string) map[string]string {
+	tags, ok := p.tags[instanceID]
+	if !ok {
+		tags = maps.Clone(p.scenario.profile(instanceID).Tags)
+		if tags == nil {
+			tags = make(map[string]string)
+		}
+		p.tags[instanceID] = tags
+	}
+	return tags
+}
+
+// wait simulates the latency of a remote call. The jitter is drawn per
+// instance and operation, so a scenario with a seed replays identically.
+func (p *Provider) wait(ctx context.Context, instance *cloud.Instance, profile Profile, operation string) error {
+	delay := profile.Latency
+	if profile.Jitter > 0 {
+		delay += time.Duration(p.draw(instance.ID, "latency:"+operation) * float64(profile.Jitter))
+	}
+	if delay <= 0 {
+		return ctx.Err()
+	}
+
+	select {
+	case <-time.After(delay):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// draw returns a deterministic number in [0, 1) for the seed, instance and
+// key, so each instance fails (or not) consistently within a run.
+func (p *Provider) draw(instanceID, key string) float64 {
+	h := fnv.New64a()
+	fmt.Fprintf(h, "%d\x00%s\x00%s", p.seed, instanceID, key)
+	return float64(h.Sum64()>>11) / float64(1<<53)
+}
+
+// isOSDetection reports whether script is an OS detection (reads
+// /etc/os-release and prints the normalized family).
+func isOSDetection(script string) bool {
+	return strings.Contains(script, "/etc/os-release") && strings.Contains(script, "unknown:")
+}
+
+// platform returns the InstanceInfo.Platform of an OS ("windows" or "linux").
+func platform(os string) string {
+	if strings.EqualFold(os, cloud.PlatformWindows) {
+		return cloud.PlatformWindows
+	}
+	return "linux"
+}
+
+// osFamily normalizes an os-release ID like the detection scripts do.
+func osFamily(os string) string {
+	if os == "" {
+		os = defaultOS
+	}
+	switch strings.ToLower(os) {
+	case "ubuntu", "debian":
+		return "debian"
+	case "rhel", "centos", "fedora", "rocky", "alma", "almalinux", "amzn", "amazonlinux", "amazon":
+		return "rhel"
+	default:
+		return "unknown:" + os
+	}
+}
@@ -0,0 +1,138 @@
+package fake
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/estudosdevops/opsmaster/internal/cloud"
+)
+
+// osDetectScript mimics the installers' OS detection script.
+const osDetectScript = ". /etc/os-release\ncase \"$ID\" in *) echo \"unknown:$ID\" ;; esac"
+
+func loadTestScenario(t *testing.T) *Provider {
+	t.Helper()
+	scenario, err := LoadScenario(filepath.Join("testdata", "scenario.yaml"))
+	if err != nil {
+		t.Fatalf("LoadScenario() error: %v", err)
+	}
+	return New(scenario)
+}
+
+// TestProvider_ExecuteCommand tests the simulated command results
+//
+// 🎓 CONCEPT: Simulation, not emulation
+// The fake never runs scripts: it recognizes OS detection, scripted
+// responses and failure profiles, and succeeds silently otherwise.
+func TestProvider_ExecuteCommand(t *testing.T) {
+	provider := loadTestScenario(t)
+
+	tests := []struct {
+		name       string
+		instanceID string
+		script     string
+		wantExit   int
+		wantStdout string
+	}{
+		{name: "OS detection uses default OS", instanceID: "i-default", script: osDetectScript, wantStdout: "debian\nshell=bash\n"},
+		{name: "OS detection uses instance OS", instanceID: "i-rhel", script: osDetectScript, wantStdout: "rhel\nshell=bash\n"},
+		{name: "scripted response", instanceID: "i-default", script: "facter -p --json role", wantStdout: `{"role": "web"}`},
+		{name: "built-in puppet version", instanceID: "i-default", script: "/opt/puppetlabs/bin/puppet --version || exit 2", wantStdout: "8.10.0\n"},
+		{name: "failing command", instanceID: "i-broken", script: "apt-get install -y puppet-agent", wantExit: 1},
+		{name: "other commands succeed", instanceID: "i-default", script: "systemctl restart puppet"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// ACT
+			result, err := provider.ExecuteCommand(context.Background(), &cloud.Instance{ID: tt.instanceID}, []string{tt.script}, time.Minute)
+
+			// ASSERT
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.ExitCode != tt.wantExit || result.Stdout != tt.wantStdout {
+				t.Errorf("got exit %d stdout %q, want exit %d stdout %q", result.ExitCode, result.Stdout, tt.wantExit, tt.wantStdout)
+			}
+		})
+	}
+}
+
+// TestProvider_ValidateAndTags tests SSM availability and tag state
+func TestProvider_ValidateAndTags(t *testing.T) {
+	ctx := context.Background()
+	provider := loadTestScenario(t)
+
+	if err := provider.ValidateInstance(ctx, &cloud.Instance{ID: "i-offline"}); err == nil {
+		t.Error("expected offline instance to fail validation")
+	}
+	if err := provider.ValidateInstance(ctx, &cloud.Instance{ID: "i-default"}); err != nil {
+		t.Errorf("unexpected validation error: %v", err)
+	}
+
+	tagged := &cloud.Instance{ID: "i-tagged"}
+	if has, _ := provider.HasTag(ctx, tagged, "puppet", "true"); !has {
+		t.Error("expected tag from scenario")
+	}
+	if err := provider.TagInstance(ctx, tagged, map[string]string{"osquery": "true"}); err != nil {
+		t.Fatalf("TagInstance() error: %v", err)
+	}
+	infos, err := provider.DescribeInstances(ctx, []*cloud.Instance{tagged})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info := infos["i-tagged"]; info.State != cloud.InstanceStateRunning || info.Tags["puppet"] != "true" || info.Tags["osquery"] != "true" {
+		t.Errorf("unexpected instance info %+v", info)
+	}
+}
+
+// TestProvider_FailureRates tests that failure draws are reproducible with a seed
+func TestProvider_FailureRates(t *testing.T) {
+	scenario := &Scenario{Seed: 7, Defaults: Profile{SSMOfflineRate: 0.5}}
+	first, second := New(scenario), New(scenario)
+
+	offline := 0
+	for i := range 200 {
+		instance := &cloud.Instance{ID: "i-" + strings.Repeat("x", i%7) + string(rune('a'+i%26)) + time.Duration(i).String()}
+		err1 := first.ValidateInstance(context.Background(), instance)
+		err2 := second.ValidateInstance(context.Background(), instance)
+		if (err1 == nil) != (err2 == nil) {
+			t.Fatalf("%s: same seed gave different outcomes", instance.ID)
+		}
+		if err1 != nil {
+			offline++
+		}
+	}
+	if offline < 60 || offline > 140 {
+		t.Errorf("offline = %d of 200, want about half", offline)
+	}
+}
+
+// TestLoadScenario_Errors tests invalid scenario files
+func TestLoadScenario_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{name: "rate out of range", content: "defaults:\n  ssm_offline_rate: 150%\n", wantErr: "out of range"},
+		{name: "invalid rate", content: "defaults:\n  tag_failure_rate: often\n", wantErr: "invalid rate"},
+		{name: "unknown field", content: "defaults:\n  latncy: 1s\n", wantErr: "latncy"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "scenario.yaml")
+			if err := os.WriteFile(path, []byte(tt.content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := LoadScenario(path); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
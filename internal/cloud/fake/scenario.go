@@ -0,0 +1,130 @@
+package fake
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Scenario describes the simulated fleet (YAML file of --fake-scenario).
+//
+// Example:
+//
+//	seed: 42
+//	defaults:
+//	  os: ubuntu
+//	  latency: 300ms
+//	  jitter: 200ms
+//	  command_failure_rate: 5%
+//	instances:
+//	  i-0abc123:
+//	    os: rhel
+//	    ssm_offline_rate: 100%
+//	responses:
+//	  - contains: "puppet --version"
+//	    stdout: "8.10.0"
+type Scenario struct {
+	Seed      int64              `yaml:"seed"`      // Seed of the failure draws (0 = new draws every run)
+	Defaults  Profile            `yaml:"defaults"`  // Profile of every instance
+	Instances map[string]Profile `yaml:"instances"` // Per-instance overrides of Defaults (by instance ID)
+	Responses []Response         `yaml:"responses"` // Scripted command outputs, checked before the built-in ones
+}
+
+// Profile is the simulated behavior of an instance. In Scenario.Instances,
+// only the non-zero fields override Defaults.
+type Profile struct {
+	OS                 string            `yaml:"os"`                   // /etc/os-release ID: ubuntu, debian, rhel, amzn, ... or windows
+	Latency            time.Duration     `yaml:"latency"`              // Duration of every remote call
+	Jitter             time.Duration     `yaml:"jitter"`               // Random extra latency (0..jitter)
+	SSMOfflineRate     Rate              `yaml:"ssm_offline_rate"`     // Chance the instance is not reachable via SSM
+	CommandFailureRate Rate              `yaml:"command_failure_rate"` // Chance a command exits with code 1
+	TagFailureRate     Rate              `yaml:"tag_failure_rate"`     // Chance tagging fails
+	FailCommands       []string          `yaml:"fail_commands"`        // Commands containing one of these exit with code 1
+	Tags               map[string]string `yaml:"tags"`                 // Tags present before the run
+}
+
+// Response is a scripted command output: the first response whose Contains
+// is a substring of the commands answers them.
+type Response struct {
+	Contains string `yaml:"contains"`
+	Stdout   string `yaml:"stdout"`
+	Stderr   string `yaml:"stderr"`
+	ExitCode int    `yaml:"exit_code"`
+}
+
+// Rate is a probability between 0 and 1, written as a fraction (0.05) or a
+// percentage ("5%").
+type Rate float64
+
+// UnmarshalYAML parses fractions and percentages.
+func (r *Rate) UnmarshalYAML(node *yaml.Node) error {
+	value := strings.TrimSpace(node.Value)
+	percent := strings.HasSuffix(value, "%")
+	parsed, err := strconv.ParseFloat(strings.TrimSuffix(value, "%"), 64)
+	if err != nil {
+		return fmt.Errorf("line %d: invalid rate %q (use 0.05 or 5%%)", node.Line, node.Value)
+	}
+	if percent {
+		parsed /= 100
+	}
+	if parsed < 0 || parsed > 1 {
+		return fmt.Errorf("line %d: rate %q out of range (0-100%%)", node.Line, node.Value)
+	}
+	*r = Rate(parsed)
+	return nil
+}
+
+// LoadScenario reads a scenario file.
+func LoadScenario(path string) (*Scenario, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fake scenario: %w", err)
+	}
+
+	var scenario Scenario
+	decoder := yaml.NewDecoder(strings.NewReader(string(data)))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&scenario); err != nil {
+		return nil, fmt.Errorf("invalid fake scenario %s: %w", path, err)
+	}
+	return &scenario, nil
+}
+
+// profile returns the effective profile of an instance.
+func (s *Scenario) profile(instanceID string) Profile {
+	profile := s.Defaults
+	override, ok := s.Instances[instanceID]
+	if !ok {
+		return profile
+	}
+
+	if override.OS != "" {
+		profile.OS = override.OS
+	}
+	if override.Latency != 0 {
+		profile.Latency = override.Latency
+	}
+	if override.Jitter != 0 {
+		profile.Jitter = override.Jitter
+	}
+	if override.SSMOfflineRate != 0 {
+		profile.SSMOfflineRate = override.SSMOfflineRate
+	}
+	if override.CommandFailureRate != 0 {
+		profile.CommandFailureRate = override.CommandFailureRate
+	}
+	if override.TagFailureRate != 0 {
+		profile.TagFailureRate = override.TagFailureRate
+	}
+	if len(override.FailCommands) > 0 {
+		profile.FailCommands = override.FailCommands
+	}
+	if len(override.Tags) > 0 {
+		profile.Tags = override.Tags
+	}
+	return profile
+}
@@ -0,0 +1,102 @@
+package cloud
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// MaxFetchFileSize is the largest file FetchFile will retrieve.
+// Remote command output is limited (SSM keeps only 24000 characters of
+// stdout), and base64 grows the content by 4/3, so files must stay small.
+const MaxFetchFileSize = 16 * 1024
+
+// Errors returned by FetchFile implementations (use errors.Is).
+var (
+	ErrFileNotFound = errors.New("remote file not found")
+	ErrFileTooLarge = errors.New("remote file too large")
+)
+
+// Markers printed by FetchFileScript instead of the content.
+const (
+	fetchNotFoundMarker = "OPSMASTER_FETCH_NOT_FOUND"
+	fetchTooLargeMarker = "OPSMASTER_FETCH_TOO_LARGE"
+)
+
+// FetchFileScript builds a POSIX sh script that prints the file at path as
+// base64, or a marker when it is missing or larger than MaxFetchFileSize.
+// Shared by providers that read files through remote command execution.
+func FetchFileScript(path string) string {
+	return fmt.Sprintf(`#!/bin/sh
+FILE=%s
+if [ ! -f "$FILE" ] || [ ! -r "$FILE" ]; then
+    echo "%s"
+    exit 0
+fi
+SIZE=$(wc -c < "$FILE" | tr -d ' ')
+if [ "$SIZE" -gt %d ]; then
+    echo "%s $SIZE"
+    exit 0
+fi
+base64 "$FILE"
+`, shellQuote(path), fetchNotFoundMarker, MaxFetchFileSize, fetchTooLargeMarker)
+}
+
+// DecodeFetchedFile parses the output of FetchFileScript.
+// Returns ErrFileNotFound or ErrFileTooLarge (wrapped) for the markers.
+func DecodeFetchedFile(path, stdout string) ([]byte, error) {
+	output := strings.TrimSpace(stdout)
+
+	switch {
+	case output == fetchNotFoundMarker:
+		return nil, fmt.Errorf("%w: %s", ErrFileNotFound, path)
+	case strings.HasPrefix(output, fetchTooLargeMarker):
+		size, _ := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(output, fetchTooLargeMarker)))
+		return nil, fmt.Errorf("%w: %s has %d bytes (max %d)", ErrFileTooLarge, path, size, MaxFetchFileSize)
+	}
+
+	// base64 wraps lines at 76 columns - drop all whitespace before decoding
+	encoded := strings.Join(strings.Fields(output), "")
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode content of %s: %w", path, err)
+	}
+	return data, nil
+}
+
+// shellQuote wraps s in single quotes for safe use in sh scripts.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// MaxTailBytes caps the output of TailFileScript below the SSM stdout limit.
+const MaxTailBytes = 20000
+
+// TailFileScript builds a POSIX sh script that prints the last lines of the
+// file at path (at most MaxTailBytes, dropping the oldest bytes), or a
+// marker when the file is missing.
+func TailFileScript(path string, lines int) string {
+	return fmt.Sprintf(`#!/bin/sh
+FILE=%s
+if [ ! -f "$FILE" ] || [ ! -r "$FILE" ]; then
+    echo "%s"
+    exit 0
+fi
+tail -n %d "$FILE" | tail -c %d
+`, shellQuote(path), fetchNotFoundMarker, lines, MaxTailBytes)
+}
+
+// ParseTailOutput returns the lines printed by TailFileScript.
+// Returns ErrFileNotFound (wrapped) for the marker.
+func ParseTailOutput(path, stdout string) ([]string, error) {
+	if strings.TrimSpace(stdout) == fetchNotFoundMarker {
+		return nil, fmt.Errorf("%w: %s", ErrFileNotFound, path)
+	}
+	output := strings.TrimSuffix(stdout, "\n")
+	if output == "" {
+		return []string{}, nil
+	}
+	return strings.Split(output, "\n"), nil
+}
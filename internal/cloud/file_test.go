@@ -0,0 +1,130 @@
+package cloud
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// ============================================================
+// CONCEPT: Testing Remote Scripts Locally
+// 🎓 FetchFileScript is plain POSIX sh, so the same script sent over SSM
+// can run here with /bin/sh against temp files.
+// ============================================================
+
+// runFetchScript executes the fetch script locally and returns stdout.
+func runFetchScript(t *testing.T, path string) string {
+	t.Helper()
+	if _, err := exec.LookPath("base64"); err != nil {
+		t.Skip("base64 not available")
+	}
+	out, err := exec.Command("/bin/sh", "-c", FetchFileScript(path)).Output()
+	if err != nil {
+		t.Fatalf("script failed: %v", err)
+	}
+	return string(out)
+}
+
+// TestFetchFile_RoundTrip tests script output decoding for existing, missing and large files
+func TestFetchFile_RoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	small := filepath.Join(dir, "it's small.yaml") // Quote in the name exercises shell quoting
+	large := filepath.Join(dir, "large.log")
+	content := strings.Repeat("version: 1700000000\n", 200) // Wraps in multiple base64 lines
+	if err := os.WriteFile(small, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(large, make([]byte, MaxFetchFileSize+1), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		want    string
+		wantErr error
+	}{
+		{name: "existing file", path: small, want: content},
+		{name: "missing file", path: filepath.Join(dir, "missing"), wantErr: ErrFileNotFound},
+		{name: "file too large", path: large, wantErr: ErrFileTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// ACT
+			data, err := DecodeFetchedFile(tt.path, runFetchScript(t, tt.path))
+
+			// ASSERT
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("expected %v, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(data) != tt.want {
+				t.Errorf("content mismatch: got %d bytes, want %d", len(data), len(tt.want))
+			}
+		})
+	}
+}
+
+// TestDecodeFetchedFile_InvalidOutput tests that garbage output is an error
+func TestDecodeFetchedFile_InvalidOutput(t *testing.T) {
+	if _, err := DecodeFetchedFile("/tmp/x", "not base64!"); err == nil {
+		t.Error("expected decode error")
+	}
+}
+
+// TestTailFile tests the tail script locally for existing, empty and missing files
+func TestTailFile(t *testing.T) {
+	dir := t.TempDir()
+	logFile := filepath.Join(dir, "puppet's.log")
+	empty := filepath.Join(dir, "empty.log")
+	if err := os.WriteFile(logFile, []byte("one\ntwo\nthree\nfour\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(empty, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		want    []string
+		wantErr error
+	}{
+		{name: "last lines", path: logFile, want: []string{"three", "four"}},
+		{name: "empty file", path: empty, want: []string{}},
+		{name: "missing file", path: filepath.Join(dir, "missing.log"), wantErr: ErrFileNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// ARRANGE
+			out, err := exec.Command("/bin/sh", "-c", TailFileScript(tt.path, 2)).Output()
+			if err != nil {
+				t.Fatalf("script failed: %v", err)
+			}
+
+			// ACT
+			lines, err := ParseTailOutput(tt.path, string(out))
+
+			// ASSERT
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("expected %v, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil || strings.Join(lines, ",") != strings.Join(tt.want, ",") || lines == nil {
+				t.Errorf("ParseTailOutput() = %q, %v; want %q", lines, err, tt.want)
+			}
+		})
+	}
+}
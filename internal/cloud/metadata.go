@@ -0,0 +1,243 @@
+package cloud
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultMetadataTTL is how long cached instance metadata stays fresh.
+const DefaultMetadataTTL = 15 * time.Minute
+
+// InstanceInfo holds instance metadata fetched from the cloud provider API
+// (e.g., EC2 DescribeInstances). Cached so discovery, validation and tagging
+// don't each call the API for the same instance.
+type InstanceInfo struct {
+	ID        string            `json:"id"`         // Instance ID
+	State     string            `json:"state"`      // Lifecycle state (running, stopped, terminated, etc)
+	Platform  string            `json:"platform"`   // Platform (linux, windows)
+	Tags      map[string]string `json:"tags"`       // Current instance tags
+	FetchedAt time.Time         `json:"fetched_at"` // When metadata was fetched from the API
+}
+
+// InstanceDescriber is implemented by providers that can describe instances in bulk.
+// This is an optional capability - callers discover it with CapabilitiesOf:
+//
+//	if describer := cloud.CapabilitiesOf(provider).Describe; describer != nil {
+//	    infos, err := describer.DescribeInstances(ctx, instances)
+//	}
+type InstanceDescriber interface {
+	// DescribeInstances returns metadata keyed by instance ID.
+	// Instances not found by the API are absent from the map.
+	DescribeInstances(ctx context.Context, instances []*Instance) (map[string]*InstanceInfo, error)
+}
+
+// MetadataCache caches InstanceInfo per account+region scope.
+// Thread-safe for concurrent access. Optionally persisted to a JSON file so
+// consecutive commands in the same run reuse already fetched metadata.
+type MetadataCache struct {
+	mu      sync.RWMutex
+	entries map[string]map[string]*InstanceInfo // scope ("account:region") -> instance ID -> info
+	ttl     time.Duration
+	path    string // Optional file path for persistence (empty = in-memory only)
+	refresh bool   // Ignore cached entries on reads (--refresh-metadata)
+}
+
+// NewMetadataCache creates an in-memory metadata cache.
+// ttl <= 0 uses DefaultMetadataTTL.
+func NewMetadataCache(ttl time.Duration) *MetadataCache {
+	if ttl <= 0 {
+		ttl = DefaultMetadataTTL
+	}
+	return &MetadataCache{
+		entries: make(map[string]map[string]*InstanceInfo),
+		ttl:     ttl,
+	}
+}
+
+// NewFileMetadataCache creates a metadata cache persisted at path.
+// Existing entries are loaded unless refresh is true, in which case
+// every read misses and metadata is fetched again (escape hatch for stale data).
+func NewFileMetadataCache(path string, ttl time.Duration, refresh bool) (*MetadataCache, error) {
+	cache := NewMetadataCache(ttl)
+	cache.path = path
+	cache.refresh = refresh
+
+	if refresh {
+		return cache, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return cache, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read metadata cache: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &cache.entries); err != nil {
+		// Corrupted cache is not fatal - start fresh
+		cache.entries = make(map[string]map[string]*InstanceInfo)
+	}
+
+	return cache, nil
+}
+
+// metadataScope builds the cache scope key for an instance.
+func metadataScope(instance *Instance) string {
+	return instance.Account + ":" + instance.Region
+}
+
+// Get returns fresh cached metadata for an instance.
+// Returns false on miss, expired entry, or when refresh mode is enabled.
+func (mc *MetadataCache) Get(instance *Instance) (*InstanceInfo, bool) {
+	if mc == nil {
+		return nil, false
+	}
+
+	mc.mu.RLock()
+	defer mc.mu.RUnlock()
+
+	if mc.refresh {
+		return nil, false
+	}
+
+	info, ok := mc.entries[metadataScope(instance)][instance.ID]
+	if !ok || time.Since(info.FetchedAt) > mc.ttl {
+		return nil, false
+	}
+	return info, true
+}
+
+// Put stores metadata for an instance.
+// Disables refresh mode for subsequent reads: freshly fetched data is reused.
+func (mc *MetadataCache) Put(instance *Instance, info *InstanceInfo) {
+	if mc == nil || info == nil {
+		return
+	}
+
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	scope := metadataScope(instance)
+	if mc.entries[scope] == nil {
+		mc.entries[scope] = make(map[string]*InstanceInfo)
+	}
+	if info.FetchedAt.IsZero() {
+		info.FetchedAt = time.Now()
+	}
+	mc.entries[scope][instance.ID] = info
+	mc.refresh = false
+}
+
+// MergeTags updates cached tags after a successful tagging call,
+// keeping the cache consistent without another API round-trip.
+func (mc *MetadataCache) MergeTags(instance *Instance, tags map[string]string) {
+	if mc == nil {
+		return
+	}
+
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	info, ok := mc.entries[metadataScope(instance)][instance.ID]
+	if !ok {
+		return
+	}
+	if info.Tags == nil {
+		info.Tags = make(map[string]string)
+	}
+	for key, value := range tags {
+		info.Tags[key] = value
+	}
+}
+
+// Invalidate removes cached metadata for an instance.
+func (mc *MetadataCache) Invalidate(instance *Instance) {
+	if mc == nil {
+		return
+	}
+
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	delete(mc.entries[metadataScope(instance)], instance.ID)
+}
+
+// Save persists the cache to its file path (no-op for in-memory caches).
+func (mc *MetadataCache) Save() error {
+	if mc == nil || mc.path == "" {
+		return nil
+	}
+
+	mc.mu.RLock()
+	data, err := json.MarshalIndent(mc.entries, "", "  ")
+	mc.mu.RUnlock()
+	if err != nil {
+		return fmt.Errorf("failed to encode metadata cache: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(mc.path), 0o750); err != nil {
+		return fmt.Errorf("failed to create metadata cache directory: %w", err)
+	}
+	if err := os.WriteFile(mc.path, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write metadata cache: %w", err)
+	}
+	return nil
+}
+
+// EnrichInstances copies provider metadata (state, platform and the Name tag
+// as name) into instance Metadata so downstream steps can use it without
+// calling the API again. Values already present (e.g., from CSV) are not
+// overwritten.
+func EnrichInstances(instances []*Instance, infos map[string]*InstanceInfo) {
+	for _, instance := range instances {
+		info, ok := infos[instance.ID]
+		if !ok {
+			continue
+		}
+		if instance.Metadata == nil {
+			instance.Metadata = make(map[string]string)
+		}
+		if _, exists := instance.Metadata["state"]; !exists && info.State != "" {
+			instance.Metadata["state"] = info.State
+		}
+		if _, exists := instance.Metadata["platform"]; !exists && info.Platform != "" {
+			instance.Metadata["platform"] = info.Platform
+		}
+		if _, exists := instance.Metadata["name"]; !exists && info.Tags[NameTagKey] != "" {
+			instance.Metadata["name"] = info.Tags[NameTagKey]
+		}
+	}
+}
+
+// DefaultMaintenanceTagKey is the tag marking instances in maintenance mode.
+// Instances with this tag set to "true" are skipped by every command unless overridden.
+const DefaultMaintenanceTagKey = "opsmaster:maintenance"
+
+// RunIDTagKey is the tag recording the ID of the last run that changed the instance.
+const RunIDTagKey = "opsmaster:last_run_id"
+
+// StatusTagKey returns the tag recording the outcome of the last run of a
+// package on the instance: "success" or "failed-<phase>" (e.g.,
+// opsmaster:puppet_status=failed-verify).
+func StatusTagKey(pkg string) string {
+	return "opsmaster:" + pkg + "_status"
+}
+
+// InMaintenance reports whether instance metadata has the maintenance tag set to "true".
+// Empty key uses DefaultMaintenanceTagKey.
+func InMaintenance(info *InstanceInfo, key string) bool {
+	if info == nil {
+		return false
+	}
+	if key == "" {
+		key = DefaultMaintenanceTagKey
+	}
+	return strings.EqualFold(info.Tags[key], "true")
+}
@@ -0,0 +1,131 @@
+package cloud
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// ============================================================
+// CONCEPT: Testing Caches
+// 🎓 A cache must return fresh entries, miss on expired or unknown ones,
+// and keep scopes (account+region) isolated from each other.
+// ============================================================
+
+// TestMetadataCache_GetPut tests cache hits, misses and scope isolation
+func TestMetadataCache_GetPut(t *testing.T) {
+	// ARRANGE
+	cache := NewMetadataCache(time.Minute)
+	instance := &Instance{ID: "i-123", Account: "111111111111", Region: "us-east-1"}
+	sameIDOtherRegion := &Instance{ID: "i-123", Account: "111111111111", Region: "sa-east-1"}
+
+	// ACT
+	cache.Put(instance, &InstanceInfo{ID: "i-123", State: "running"})
+
+	// ASSERT
+	info, ok := cache.Get(instance)
+	if !ok {
+		t.Fatal("expected cache hit")
+	}
+	if info.State != "running" {
+		t.Errorf("expected state 'running', got '%s'", info.State)
+	}
+	if info.FetchedAt.IsZero() {
+		t.Error("expected FetchedAt to be set on Put")
+	}
+	if _, ok := cache.Get(sameIDOtherRegion); ok {
+		t.Error("expected miss for same ID in a different region")
+	}
+}
+
+// TestMetadataCache_Expired tests that stale entries are not served
+func TestMetadataCache_Expired(t *testing.T) {
+	cache := NewMetadataCache(time.Minute)
+	instance := &Instance{ID: "i-123", Account: "111111111111", Region: "us-east-1"}
+
+	cache.Put(instance, &InstanceInfo{ID: "i-123", FetchedAt: time.Now().Add(-2 * time.Minute)})
+
+	if _, ok := cache.Get(instance); ok {
+		t.Error("expected miss for expired entry")
+	}
+}
+
+// TestMetadataCache_MergeTags tests that tagging updates cached tags
+func TestMetadataCache_MergeTags(t *testing.T) {
+	cache := NewMetadataCache(time.Minute)
+	instance := &Instance{ID: "i-123", Account: "111111111111", Region: "us-east-1"}
+	cache.Put(instance, &InstanceInfo{ID: "i-123", Tags: map[string]string{"Name": "web"}})
+
+	cache.MergeTags(instance, map[string]string{"puppet": "true"})
+
+	info, _ := cache.Get(instance)
+	if info.Tags["puppet"] != "true" || info.Tags["Name"] != "web" {
+		t.Errorf("unexpected tags after merge: %v", info.Tags)
+	}
+}
+
+// TestFileMetadataCache tests persistence and the refresh escape hatch
+func TestFileMetadataCache(t *testing.T) {
+	// ARRANGE
+	path := filepath.Join(t.TempDir(), "cache", "instance-metadata.json")
+	instance := &Instance{ID: "i-123", Account: "111111111111", Region: "us-east-1"}
+
+	cache, err := NewFileMetadataCache(path, time.Minute, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cache.Put(instance, &InstanceInfo{ID: "i-123", State: "stopped"})
+	if err := cache.Save(); err != nil {
+		t.Fatalf("failed to save cache: %v", err)
+	}
+
+	// ACT & ASSERT: reload reuses persisted entries
+	reloaded, err := NewFileMetadataCache(path, time.Minute, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if info, ok := reloaded.Get(instance); !ok || info.State != "stopped" {
+		t.Errorf("expected persisted entry, got %v (hit=%v)", info, ok)
+	}
+
+	// ACT & ASSERT: refresh ignores persisted entries until fetched again
+	refreshed, err := NewFileMetadataCache(path, time.Minute, true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := refreshed.Get(instance); ok {
+		t.Error("expected miss with refresh enabled")
+	}
+	refreshed.Put(instance, &InstanceInfo{ID: "i-123", State: "running"})
+	if info, ok := refreshed.Get(instance); !ok || info.State != "running" {
+		t.Error("expected freshly fetched entry to be served after refresh")
+	}
+}
+
+// TestEnrichInstances tests that provider metadata doesn't override CSV values
+func TestEnrichInstances(t *testing.T) {
+	instances := []*Instance{
+		{ID: "i-1"},
+		{ID: "i-2", Metadata: map[string]string{"platform": "custom"}},
+		{ID: "i-3"},
+	}
+	infos := map[string]*InstanceInfo{
+		"i-1": {ID: "i-1", State: "running", Platform: "linux", Tags: map[string]string{NameTagKey: "web-01"}},
+		"i-2": {ID: "i-2", State: "stopped", Platform: "windows", Tags: map[string]string{NameTagKey: "db-01"}},
+	}
+
+	EnrichInstances(instances, infos)
+
+	if instances[0].Metadata["state"] != "running" || instances[0].Metadata["platform"] != "linux" || instances[0].Metadata["name"] != "web-01" {
+		t.Errorf("instance i-1 not enriched: %v", instances[0].Metadata)
+	}
+	if instances[1].Metadata["platform"] != "custom" {
+		t.Errorf("CSV platform overwritten: %v", instances[1].Metadata)
+	}
+	if instances[1].Metadata["state"] != "stopped" {
+		t.Errorf("instance i-2 state not enriched: %v", instances[1].Metadata)
+	}
+	if instances[2].Metadata != nil {
+		t.Errorf("instance without metadata should be untouched: %v", instances[2].Metadata)
+	}
+}
@@ -0,0 +1,63 @@
+package cloud
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Instance lifecycle states (EC2 naming, used as InstanceInfo.State values).
+const (
+	InstanceStatePending      = "pending"
+	InstanceStateRunning      = "running"
+	InstanceStateStopping     = "stopping"
+	InstanceStateStopped      = "stopped"
+	InstanceStateShuttingDown = "shutting-down"
+	InstanceStateTerminated   = "terminated"
+)
+
+// ErrInstanceGone is matched (errors.Is) by command errors of instances
+// that no longer exist (e.g., terminated by autoscaling mid-run). Contains
+// "not found" so retry.Do gives up at once instead of resending.
+var ErrInstanceGone = errors.New("instance not found: terminated or deregistered")
+
+// IsRunnableState reports whether commands can be sent to an instance in this state.
+// Unknown (empty) state is treated as runnable - validation will report the real problem.
+func IsRunnableState(state string) bool {
+	switch state {
+	case "", InstanceStatePending, InstanceStateRunning:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsStartableState reports whether an instance in this state can be started.
+func IsStartableState(state string) bool {
+	return state == InstanceStateStopped || state == InstanceStateStopping
+}
+
+// InstanceStarter is implemented by providers that can power on instances.
+// Optional capability, discovered with CapabilitiesOf (see InstanceDescriber).
+// Results are keyed by instance ID; instances absent from the map succeeded.
+type InstanceStarter interface {
+	// StartInstances requests instances to start (does not wait).
+	StartInstances(ctx context.Context, instances []*Instance) map[string]error
+
+	// WaitForState blocks until instances reach state or timeout expires.
+	WaitForState(ctx context.Context, instances []*Instance, state string, timeout time.Duration) map[string]error
+}
+
+// InstanceStopper is implemented by providers that can power off instances.
+// Optional capability, discovered with CapabilitiesOf.
+type InstanceStopper interface {
+	// StopInstances requests instances to stop (does not wait).
+	StopInstances(ctx context.Context, instances []*Instance) map[string]error
+}
+
+// InstanceRebooter is implemented by providers that can reboot instances.
+// Optional capability, discovered with CapabilitiesOf.
+type InstanceRebooter interface {
+	// RebootInstances requests instances to reboot (does not wait).
+	RebootInstances(ctx context.Context, instances []*Instance) map[string]error
+}
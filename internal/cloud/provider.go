@@ -2,6 +2,8 @@ package cloud
 
 import (
 	"context"
+	"strings"
+	"sync/atomic"
 	"time"
 )
 
@@ -23,11 +25,20 @@ type CloudProvider interface {
 	// Returns execution result with stdout, stderr and exit code.
 	ExecuteCommand(ctx context.Context, instance *Instance, commands []string, timeout time.Duration) (*CommandResult, error)
 
+	// ExecuteCommandWithOptions is ExecuteCommand with environment variables,
+	// working directory, run-as user, shell and output limits (see ExecOptions).
+	ExecuteCommandWithOptions(ctx context.Context, instance *Instance, commands []string, timeout time.Duration, opts ExecOptions) (*CommandResult, error)
+
 	// TestConnectivity tests network connectivity from instance to a host:port.
 	// Useful for validating if instance can reach external services
 	// (e.g., Puppet Server on port 8140).
 	TestConnectivity(ctx context.Context, instance *Instance, host string, port int) error
 
+	// FetchFile returns the contents of a remote file (e.g., Puppet's
+	// last_run_summary.yaml). Files larger than MaxFetchFileSize are refused.
+	// Returns ErrFileNotFound or ErrFileTooLarge (wrapped) for those cases.
+	FetchFile(ctx context.Context, instance *Instance, path string) ([]byte, error)
+
 	// TagInstance adds tags/labels to the instance.
 	// tags: map of key-value to apply
 	// Used to mark instances after successful installation.
@@ -53,6 +64,53 @@ func (i *Instance) String() string {
 	return i.Cloud + ":" + i.Account + ":" + i.Region + ":" + i.ID
 }
 
+// NameTagKey is the tag with the instance name shown by cloud consoles.
+const NameTagKey = "Name"
+
+// defaultDisplayColumns are the metadata columns with a human-readable
+// instance name, in order of preference. "name" also receives the Name tag
+// (see EnrichInstances).
+var defaultDisplayColumns = []string{"name", "hostname"}
+
+var displayColumn atomic.Value // string
+
+// SetDisplayColumn selects the metadata (CSV) column with the instance name
+// shown next to the ID in outputs (--display-column; "" = name or hostname).
+func SetDisplayColumn(column string) {
+	displayColumn.Store(strings.ToLower(strings.TrimSpace(column)))
+}
+
+// DisplayName returns the human-readable name of the instance: the column
+// selected with SetDisplayColumn, else the name or hostname column ("" if
+// none is set).
+func (i *Instance) DisplayName() string {
+	if column, _ := displayColumn.Load().(string); column != "" {
+		if name := i.Metadata[column]; name != "" {
+			return name
+		}
+	}
+	for _, column := range defaultDisplayColumns {
+		if name := i.Metadata[column]; name != "" {
+			return name
+		}
+	}
+	return ""
+}
+
+// Label returns the instance ID followed by its name, if any (e.g.,
+// "i-0abc (web-01)"), for tables and messages read by humans.
+func (i *Instance) Label() string {
+	return FormatLabel(i.ID, i.DisplayName())
+}
+
+// FormatLabel formats an instance ID and name like Instance.Label.
+func FormatLabel(id, name string) string {
+	if name == "" || name == id {
+		return id
+	}
+	return id + " (" + name + ")"
+}
+
 // CommandResult encapsulates the result of a remote command execution
 type CommandResult struct {
 	InstanceID string        // ID of instance where command was executed
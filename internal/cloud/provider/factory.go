@@ -7,6 +7,7 @@ import (
 
 	"github.com/estudosdevops/opsmaster/internal/cloud"
 	"github.com/estudosdevops/opsmaster/internal/cloud/aws"
+	"github.com/estudosdevops/opsmaster/internal/cloud/fake"
 	"github.com/estudosdevops/opsmaster/internal/retry"
 )
 
@@ -24,8 +25,80 @@ const (
 	// ProviderAzure represents Microsoft Azure
 	// Currently not implemented, reserved for future use
 	ProviderAzure ProviderType = "azure"
+
+	// ProviderFake represents the simulated provider (demos and CI, no cloud account)
+	// Used for every instance with the global --provider fake flag (see UseFake)
+	ProviderFake ProviderType = fake.Name
 )
 
+// override is returned by NewProvider for every cloud type when set (see UseFake).
+var override cloud.CloudProvider
+
+// UseFake makes NewProvider return a simulated provider for every cloud
+// type, driven by the YAML scenario at scenarioPath ("" = defaults: instant,
+// failure-free Ubuntu instances). Called once at startup (--provider fake);
+// all commands of the process share the provider, so tags applied by one
+// step are seen by the next.
+func UseFake(scenarioPath string) error {
+	var scenario *fake.Scenario
+	if scenarioPath != "" {
+		var err error
+		if scenario, err = fake.LoadScenario(scenarioPath); err != nil {
+			return err
+		}
+	}
+	override = fake.New(scenario)
+	return nil
+}
+
+// readOnly makes NewProvider wrap every provider in read-only mode (see
+// UseReadOnly); allowedScripts are the digests of the scripts allowed anyway.
+var (
+	readOnly       bool
+	allowedScripts []string
+)
+
+// UseReadOnly makes NewProvider return read-only providers (--read-only):
+// tagging, power operations and remote commands other than inspections and
+// the allowed scripts (SHA-256 digests) are refused. Called once at startup.
+func UseReadOnly(scripts []string) {
+	readOnly, allowedScripts = true, scripts
+}
+
+// ReadOnly reports whether NewProvider returns read-only providers.
+func ReadOnly() bool {
+	return readOnly
+}
+
+// ssmDocument replaces AWS-RunShellScript in AWS providers (see UseSSMDocument).
+var ssmDocument aws.SSMDocument
+
+// UseSSMDocument makes NewProvider return AWS providers that run shell
+// commands with the customer-managed document doc (--ssm-document-name),
+// for organizations that restrict which SSM documents may be invoked.
+// Called once at startup.
+func UseSSMDocument(doc aws.SSMDocument) error {
+	if err := doc.Validate(); err != nil {
+		return err
+	}
+	ssmDocument = doc
+	return nil
+}
+
+// ssmOutput is where AWS providers store command output (see UseSSMOutput).
+var ssmOutput aws.SSMOutput
+
+// UseSSMOutput makes NewProvider return AWS providers that store the full
+// output of every command in an SSE-KMS encrypted S3 bucket
+// (--ssm-output-bucket). Called once at startup.
+func UseSSMOutput(output aws.SSMOutput) error {
+	if err := output.Validate(); err != nil {
+		return err
+	}
+	ssmOutput = output
+	return nil
+}
+
 // Config holds configuration for cloud provider initialization.
 // Used with functional options pattern for flexible provider creation.
 type Config struct {
@@ -47,6 +120,14 @@ type Config struct {
 	// Optional: uses default policy if not provided
 	EC2RetryConfig *retry.RetryConfig
 
+	// MetadataCache is the instance metadata cache shared across commands
+	// Optional: provider uses a private in-memory cache if not provided
+	MetadataCache *cloud.MetadataCache
+
+	// CommandLabel tags remote commands (comment, marker line) for auditing
+	// Optional: default prefix only, without run ID or operator
+	CommandLabel cloud.CommandLabel
+
 	// Additional provider-specific options can be added here
 	// Examples: Timeout, CustomEndpoint, etc.
 }
@@ -76,6 +157,20 @@ func WithRetryPolicies(ssmPolicy, ec2Policy retry.RetryConfig) Option {
 	}
 }
 
+// WithMetadataCache sets a shared instance metadata cache
+func WithMetadataCache(cache *cloud.MetadataCache) Option {
+	return func(c *Config) {
+		c.MetadataCache = cache
+	}
+}
+
+// WithCommandLabel sets the run ID, operator and prefix recorded with remote commands
+func WithCommandLabel(label cloud.CommandLabel) Option {
+	return func(c *Config) {
+		c.CommandLabel = label
+	}
+}
+
 // NewProvider creates a new cloud provider based on the provider type.
 // Uses Factory Pattern to abstract provider creation logic from CLI layer.
 //
@@ -112,6 +207,19 @@ func WithRetryPolicies(ssmPolicy, ec2Policy retry.RetryConfig) Option {
 //	cloudType := instances[0].Cloud  // "aws", "gcp", "azure"
 //	provider, err := provider.NewProvider(cloudType)
 func NewProvider(cloudType string, options ...Option) (cloud.CloudProvider, error) {
+	p, err := newProvider(cloudType, options...)
+	if err != nil || !readOnly {
+		return p, err
+	}
+	return cloud.ReadOnly(p, allowedScripts), nil
+}
+
+// newProvider creates the provider of cloudType, before the read-only wrapper.
+func newProvider(cloudType string, options ...Option) (cloud.CloudProvider, error) {
+	if override != nil {
+		return override, nil
+	}
+
 	// Apply functional options to config
 	config := &Config{}
 	for _, opt := range options {
@@ -124,19 +232,15 @@ func NewProvider(cloudType string, options ...Option) (cloud.CloudProvider, erro
 	// Create provider based on type
 	switch ProviderType(normalizedType) {
 	case ProviderAWS:
-		// Check if custom retry policies are provided
-		if config.SSMRetryConfig != nil && config.EC2RetryConfig != nil {
-			// Use custom retry policies
-			return aws.NewAWSProviderWithPolicies(context.Background(), config.Profile, *config.SSMRetryConfig, *config.EC2RetryConfig)
+		awsProvider, err := newAWSProvider(config)
+		if err != nil {
+			return nil, err
 		}
-
-		// Create AWS provider with profile support (using default retry policies)
-		if config.Profile != "" {
-			// Use profile-based authentication (supports SSO)
-			return aws.NewAWSProviderWithProfile(context.Background(), config.Profile)
-		}
-		// Fallback to default provider (uses default credentials)
-		return aws.NewAWSProvider(), nil
+		awsProvider.SetMetadataCache(config.MetadataCache)
+		awsProvider.SetCommandLabel(config.CommandLabel)
+		awsProvider.SetSSMDocument(ssmDocument)
+		awsProvider.SetSSMOutput(ssmOutput)
+		return awsProvider, nil
 
 	case ProviderGCP:
 		// GCP provider not yet implemented
@@ -146,11 +250,32 @@ func NewProvider(cloudType string, options ...Option) (cloud.CloudProvider, erro
 		// Azure provider not yet implemented
 		return nil, fmt.Errorf("azure provider not yet implemented (coming soon)")
 
+	case ProviderFake:
+		// Instances with cloud "fake" in the CSV, default scenario
+		return fake.New(nil), nil
+
 	default:
 		return nil, fmt.Errorf("unsupported cloud provider: %s (supported: aws, gcp, azure)", cloudType)
 	}
 }
 
+// newAWSProvider creates the AWS provider using the most specific constructor for config.
+func newAWSProvider(config *Config) (*aws.AWSProvider, error) {
+	// Check if custom retry policies are provided
+	if config.SSMRetryConfig != nil && config.EC2RetryConfig != nil {
+		// Use custom retry policies
+		return aws.NewAWSProviderWithPolicies(context.Background(), config.Profile, *config.SSMRetryConfig, *config.EC2RetryConfig)
+	}
+
+	// Create AWS provider with profile support (using default retry policies)
+	if config.Profile != "" {
+		// Use profile-based authentication (supports SSO)
+		return aws.NewAWSProviderWithProfile(context.Background(), config.Profile)
+	}
+	// Fallback to default provider (uses default credentials)
+	return aws.NewAWSProvider(), nil
+}
+
 // DetectCloudFromInstances detects the cloud provider from a list of instances.
 // Returns the most common cloud provider or error if instances use different clouds.
 //
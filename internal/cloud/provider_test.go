@@ -12,6 +12,35 @@ import (
 // and can be properly initialized. This ensures data integrity.
 // ============================================================
 
+// TestInstance_Label tests the display name shown next to the instance ID
+func TestInstance_Label(t *testing.T) {
+	tests := []struct {
+		name     string
+		column   string // --display-column
+		metadata map[string]string
+		expected string
+	}{
+		{name: "no name", metadata: nil, expected: "i-1"},
+		{name: "name column", metadata: map[string]string{"name": "web-01", "hostname": "web-01.example.com"}, expected: "i-1 (web-01)"},
+		{name: "hostname column", metadata: map[string]string{"hostname": "web-01.example.com"}, expected: "i-1 (web-01.example.com)"},
+		{name: "display column", column: "FQDN", metadata: map[string]string{"name": "web-01", "fqdn": "web-01.prod.example.com"}, expected: "i-1 (web-01.prod.example.com)"},
+		{name: "display column empty, falls back", column: "fqdn", metadata: map[string]string{"hostname": "web-01"}, expected: "i-1 (web-01)"},
+		{name: "name equal to ID", metadata: map[string]string{"name": "i-1"}, expected: "i-1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetDisplayColumn(tt.column)
+			t.Cleanup(func() { SetDisplayColumn("") })
+
+			instance := &Instance{ID: "i-1", Metadata: tt.metadata}
+			if got := instance.Label(); got != tt.expected {
+				t.Errorf("Label() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
 // TestInstance_String tests the string representation of an instance
 func TestInstance_String(t *testing.T) {
 	tests := []struct {
@@ -338,6 +367,10 @@ func (*mockCloudProvider) ExecuteCommand(_ context.Context, _ *Instance, _ []str
 	return &CommandResult{ExitCode: 0}, nil
 }
 
+func (*mockCloudProvider) ExecuteCommandWithOptions(_ context.Context, _ *Instance, _ []string, _ time.Duration, _ ExecOptions) (*CommandResult, error) {
+	return &CommandResult{ExitCode: 0}, nil
+}
+
 func (*mockCloudProvider) TestConnectivity(_ context.Context, _ *Instance, _ string, _ int) error {
 	return nil
 }
@@ -350,6 +383,10 @@ func (*mockCloudProvider) HasTag(_ context.Context, _ *Instance, _, _ string) (b
 	return false, nil
 }
 
+func (*mockCloudProvider) FetchFile(_ context.Context, _ *Instance, _ string) ([]byte, error) {
+	return []byte("content"), nil
+}
+
 // TestCloudProvider_InterfaceCompliance validates that mockCloudProvider implements CloudProvider
 // This is a compile-time test - if mockCloudProvider doesn't implement all methods,
 // this won't compile
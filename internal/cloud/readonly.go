@@ -0,0 +1,219 @@
+package cloud
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Read-only mode (--read-only).
+//
+// ReadOnly wraps a provider so shared production credentials can be used
+// for discovery, preflight (dry-run), verify and coverage without changing
+// any instance: tagging, starting, stopping and rebooting are refused, and so
+// are remote commands, except those sent with a ReadOnlyCommands context
+// (validation, verification, diagnostics, facts) and scripts whose SHA-256
+// digest was explicitly allowed. Refused operations return a *ReadOnlyError.
+
+// ErrReadOnly is matched (errors.Is) by operations refused in read-only mode.
+var ErrReadOnly = errors.New("refused in read-only mode")
+
+// ReadOnlyError reports a mutating operation refused in read-only mode.
+type ReadOnlyError struct {
+	Operation string // What was refused, e.g. "tagging instances"
+	Digest    string // SHA-256 of the refused commands (remote commands only)
+}
+
+func (e *ReadOnlyError) Error() string {
+	if e.Digest != "" {
+		return fmt.Sprintf("read-only mode: %s is not allowed (script sha256 %s is not in read_only.allowed_scripts)", e.Operation, e.Digest)
+	}
+	return fmt.Sprintf("read-only mode: %s is not allowed", e.Operation)
+}
+
+// Is makes errors.Is(err, ErrReadOnly) match.
+func (e *ReadOnlyError) Is(target error) bool {
+	return target == ErrReadOnly
+}
+
+type readOnlyCommandsKey struct{}
+
+// ReadOnlyCommands returns a context marking the remote commands sent with
+// it as read-only: they only inspect the instance (e.g., prerequisite
+// validation, verification), so they run in read-only mode.
+func ReadOnlyCommands(ctx context.Context) context.Context {
+	return context.WithValue(ctx, readOnlyCommandsKey{}, true)
+}
+
+// IsReadOnlyCommands reports whether ctx was marked with ReadOnlyCommands.
+func IsReadOnlyCommands(ctx context.Context) bool {
+	readOnly, _ := ctx.Value(readOnlyCommandsKey{}).(bool)
+	return readOnly
+}
+
+// CommandsDigest returns the SHA-256 (hex) of commands joined by newlines.
+// For a single script, it is the sha256sum of the script file.
+func CommandsDigest(commands []string) string {
+	sum := sha256.Sum256([]byte(strings.Join(commands, "\n")))
+	return hex.EncodeToString(sum[:])
+}
+
+// ReadOnlyProvider refuses the mutating operations of the wrapped provider.
+// It implements every optional capability and reports those of the wrapped
+// provider (CapabilityReporter), so CapabilitiesOf sees the same set.
+type ReadOnlyProvider struct {
+	inner          CloudProvider
+	allowedScripts []string
+}
+
+// ReadOnly wraps provider in read-only mode. allowedScripts are the SHA-256
+// digests (see CommandsDigest) of scripts allowed to run anyway.
+func ReadOnly(provider CloudProvider, allowedScripts []string) *ReadOnlyProvider {
+	allowed := make([]string, 0, len(allowedScripts))
+	for _, digest := range allowedScripts {
+		allowed = append(allowed, strings.ToLower(strings.TrimSpace(digest)))
+	}
+	return &ReadOnlyProvider{inner: provider, allowedScripts: allowed}
+}
+
+// Name returns the name of the wrapped provider.
+func (p *ReadOnlyProvider) Name() string {
+	return p.inner.Name()
+}
+
+// SupportedCapabilities returns the capabilities of the wrapped provider.
+func (p *ReadOnlyProvider) SupportedCapabilities() []string {
+	return CapabilitiesOf(p.inner).Names()
+}
+
+// ValidateInstance only reads the instance state.
+func (p *ReadOnlyProvider) ValidateInstance(ctx context.Context, instance *Instance) error {
+	return p.inner.ValidateInstance(ctx, instance)
+}
+
+// ExecuteCommand runs read-only commands (see ReadOnlyCommands) and allowed
+// scripts; other commands are refused.
+func (p *ReadOnlyProvider) ExecuteCommand(ctx context.Context, instance *Instance, commands []string, timeout time.Duration) (*CommandResult, error) {
+	if err := p.checkCommands(ctx, commands); err != nil {
+		return nil, err
+	}
+	return p.inner.ExecuteCommand(ctx, instance, commands, timeout)
+}
+
+// ExecuteCommandWithOptions is ExecuteCommand with options.
+func (p *ReadOnlyProvider) ExecuteCommandWithOptions(ctx context.Context, instance *Instance, commands []string, timeout time.Duration, opts ExecOptions) (*CommandResult, error) {
+	if err := p.checkCommands(ctx, commands); err != nil {
+		return nil, err
+	}
+	return p.inner.ExecuteCommandWithOptions(ctx, instance, commands, timeout, opts)
+}
+
+// checkCommands refuses commands not marked read-only nor allowed.
+func (p *ReadOnlyProvider) checkCommands(ctx context.Context, commands []string) error {
+	if IsReadOnlyCommands(ctx) {
+		return nil
+	}
+	digest := CommandsDigest(commands)
+	if slices.Contains(p.allowedScripts, digest) {
+		return nil
+	}
+	return &ReadOnlyError{Operation: "running commands", Digest: digest}
+}
+
+// TestConnectivity only opens a connection from the instance.
+func (p *ReadOnlyProvider) TestConnectivity(ctx context.Context, instance *Instance, host string, port int) error {
+	return p.inner.TestConnectivity(ctx, instance, host, port)
+}
+
+// FetchFile only reads a file of the instance.
+func (p *ReadOnlyProvider) FetchFile(ctx context.Context, instance *Instance, path string) ([]byte, error) {
+	return p.inner.FetchFile(ctx, instance, path)
+}
+
+// TagInstance is refused.
+func (p *ReadOnlyProvider) TagInstance(ctx context.Context, instance *Instance, tags map[string]string) error {
+	return &ReadOnlyError{Operation: "tagging instances"}
+}
+
+// HasTag only reads the instance tags.
+func (p *ReadOnlyProvider) HasTag(ctx context.Context, instance *Instance, key, value string) (bool, error) {
+	return p.inner.HasTag(ctx, instance, key, value)
+}
+
+// DescribeInstances delegates to the wrapped provider (InstanceDescriber).
+func (p *ReadOnlyProvider) DescribeInstances(ctx context.Context, instances []*Instance) (map[string]*InstanceInfo, error) {
+	describer := CapabilitiesOf(p.inner).Describe
+	if describer == nil {
+		return nil, Unsupported(p.inner, "describing instances")
+	}
+	return describer.DescribeInstances(ctx, instances)
+}
+
+// DescribeAutoScaling delegates to the wrapped provider (AutoScalingDescriber).
+func (p *ReadOnlyProvider) DescribeAutoScaling(ctx context.Context, instances []*Instance) (map[string]*AutoScalingMember, error) {
+	describer := CapabilitiesOf(p.inner).AutoScaling
+	if describer == nil {
+		return nil, Unsupported(p.inner, "describing autoscaling groups")
+	}
+	return describer.DescribeAutoScaling(ctx, instances)
+}
+
+// GroupMembers delegates to the wrapped provider (AutoScalingDescriber).
+func (p *ReadOnlyProvider) GroupMembers(ctx context.Context, instance *Instance, group string) ([]*AutoScalingMember, error) {
+	describer := CapabilitiesOf(p.inner).AutoScaling
+	if describer == nil {
+		return nil, Unsupported(p.inner, "describing autoscaling groups")
+	}
+	return describer.GroupMembers(ctx, instance, group)
+}
+
+// WaitForAgent only reads the agent registration (AgentWaiter).
+func (p *ReadOnlyProvider) WaitForAgent(ctx context.Context, instance *Instance, timeout time.Duration) error {
+	waiter := CapabilitiesOf(p.inner).Agent
+	if waiter == nil {
+		return Unsupported(p.inner, "waiting for the agent")
+	}
+	return waiter.WaitForAgent(ctx, instance, timeout)
+}
+
+// StartInstances is refused for every instance.
+func (p *ReadOnlyProvider) StartInstances(ctx context.Context, instances []*Instance) map[string]error {
+	return refuseAll(instances, "starting instances")
+}
+
+// WaitForState only reads the instance state (InstanceStarter).
+func (p *ReadOnlyProvider) WaitForState(ctx context.Context, instances []*Instance, state string, timeout time.Duration) map[string]error {
+	starter := CapabilitiesOf(p.inner).Start
+	if starter == nil {
+		errs := make(map[string]error, len(instances))
+		for _, instance := range instances {
+			errs[instance.ID] = Unsupported(p.inner, "starting instances")
+		}
+		return errs
+	}
+	return starter.WaitForState(ctx, instances, state, timeout)
+}
+
+// StopInstances is refused for every instance.
+func (p *ReadOnlyProvider) StopInstances(ctx context.Context, instances []*Instance) map[string]error {
+	return refuseAll(instances, "stopping instances")
+}
+
+// RebootInstances is refused for every instance.
+func (p *ReadOnlyProvider) RebootInstances(ctx context.Context, instances []*Instance) map[string]error {
+	return refuseAll(instances, "rebooting instances")
+}
+
+// refuseAll returns a ReadOnlyError for each instance.
+func refuseAll(instances []*Instance, operation string) map[string]error {
+	errs := make(map[string]error, len(instances))
+	for _, instance := range instances {
+		errs[instance.ID] = &ReadOnlyError{Operation: operation}
+	}
+	return errs
+}
@@ -0,0 +1,81 @@
+package cloud
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// TestReadOnly_Capabilities tests that the read-only wrapper keeps the
+// capabilities of the wrapped provider.
+func TestReadOnly_Capabilities(t *testing.T) {
+	tests := []struct {
+		name     string
+		provider CloudProvider
+		want     []string
+	}{
+		{"required methods only", baseProvider{}, nil},
+		{"all optional capabilities", powerProvider{}, []string{CapabilityDescribe, CapabilityStart, CapabilityStop, CapabilityReboot, CapabilityWaitAgent}},
+		{"reporter narrows detection", pluginProvider{}, []string{CapabilityDescribe}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := CapabilitiesOf(ReadOnly(tt.provider, nil)).Names()
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("CapabilitiesOf(ReadOnly()).Names() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestReadOnly_Refused tests which operations are refused in read-only mode.
+func TestReadOnly_Refused(t *testing.T) {
+	ctx := context.Background()
+	instance := &Instance{ID: "i-1"}
+	script := "#!/bin/sh\ndf -h\n"
+	provider := ReadOnly(powerProvider{}, []string{" " + strings.ToUpper(CommandsDigest([]string{script})) + " "})
+
+	tests := []struct {
+		name    string
+		call    func() error
+		refused bool
+	}{
+		{"command", func() error {
+			_, err := provider.ExecuteCommand(ctx, instance, []string{"rm -rf /opt/app"}, 0)
+			return err
+		}, true},
+		{"read-only command", func() error {
+			_, err := provider.ExecuteCommand(ReadOnlyCommands(ctx), instance, []string{"puppet --version"}, 0)
+			return err
+		}, false},
+		{"allowed script", func() error {
+			_, err := provider.ExecuteCommandWithOptions(ctx, instance, []string{script}, 0, ExecOptions{})
+			return err
+		}, false},
+		{"tag", func() error { return provider.TagInstance(ctx, instance, map[string]string{"a": "b"}) }, true},
+		{"has tag", func() error { _, err := provider.HasTag(ctx, instance, "a", "b"); return err }, false},
+		{"start", func() error { return provider.StartInstances(ctx, []*Instance{instance})["i-1"] }, true},
+		{"stop", func() error { return provider.StopInstances(ctx, []*Instance{instance})["i-1"] }, true},
+		{"reboot", func() error { return provider.RebootInstances(ctx, []*Instance{instance})["i-1"] }, true},
+		{"wait for state", func() error { return provider.WaitForState(ctx, []*Instance{instance}, "running", 0)["i-1"] }, false},
+		{"wait for agent", func() error { return provider.WaitForAgent(ctx, instance, 0) }, false},
+		{"describe", func() error { _, err := provider.DescribeInstances(ctx, []*Instance{instance}); return err }, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.call()
+			if refused := errors.Is(err, ErrReadOnly); refused != tt.refused {
+				t.Errorf("refused = %v (error %v), want %v", refused, err, tt.refused)
+			}
+		})
+	}
+
+	_, err := provider.ExecuteCommand(ctx, instance, []string{"touch /x"}, 0)
+	if digest := CommandsDigest([]string{"touch /x"}); !strings.Contains(err.Error(), digest) {
+		t.Errorf("error = %v, want the script digest %s", err, digest)
+	}
+}
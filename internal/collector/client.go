@@ -0,0 +1,89 @@
+package collector
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/estudosdevops/opsmaster/internal/executor"
+)
+
+// Client streams the results of one run to a collector.
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+	run        Run
+}
+
+// NewClient creates a client for the collector at rawURL (https only; the
+// collector requires a client certificate, see httpclient.Config).
+func NewClient(rawURL string, httpClient *http.Client, run Run) (*Client, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Scheme != "https" || parsed.Host == "" {
+		return nil, fmt.Errorf("invalid collector URL %q: expected https://host:port", rawURL)
+	}
+	return &Client{
+		baseURL:    strings.TrimRight(rawURL, "/"),
+		httpClient: httpClient,
+		run:        run,
+	}, nil
+}
+
+// Ping checks that the collector is reachable and accepts the client
+// certificate, so a misconfigured run fails before installing anything.
+func (c *Client) Ping(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/healthz", nil)
+	if err != nil {
+		return fmt.Errorf("failed to create collector request: %w", err)
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("collector unreachable: %w", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("collector health check returned %s", resp.Status)
+	}
+	return nil
+}
+
+// SendResult streams the entry of one finished instance.
+func (c *Client) SendResult(ctx context.Context, entry executor.ReportEntry) error {
+	return c.post(ctx, ResultsPath, ResultMessage{Run: c.run, Entry: entry})
+}
+
+// SendReport sends the final report of the run, replacing its streamed
+// results (the report adds the tags of each instance).
+func (c *Client) SendReport(ctx context.Context, report *executor.Report) error {
+	return c.post(ctx, ReportsPath, report)
+}
+
+// post sends body as JSON to path.
+func (c *Client) post(ctx context.Context, path string, body any) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to encode collector request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+path, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to create collector request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("collector request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("collector returned %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
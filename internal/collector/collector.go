@@ -0,0 +1,314 @@
+// Package collector merges the results streamed by opsmaster runs on several
+// hosts (e.g., one bastion per VPC) into one aggregated run report.
+//
+// Runs send each instance result as it finishes (ResultsPath) and their full
+// report at the end (ReportsPath), over mutual TLS. The collector keeps one
+// report per run ID and writes their merge (executor.MergeReports) every
+// flushInterval, when a final report arrives and on shutdown. Writes happen
+// outside the request path, so senders never wait on the disk.
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/estudosdevops/opsmaster/internal/executor"
+)
+
+const (
+	// ResultsPath receives one ResultMessage per finished instance (POST).
+	ResultsPath = "/v1/results"
+
+	// ReportsPath receives the final executor.Report of a run (POST).
+	ReportsPath = "/v1/reports"
+
+	// ReportPath serves the aggregated report (GET).
+	ReportPath = "/v1/report"
+
+	// maxBodyBytes bounds request bodies (a final report of ~50k instances).
+	maxBodyBytes = 64 << 20
+
+	// flushInterval is how often the aggregated report is written while
+	// results are streamed.
+	flushInterval = 2 * time.Second
+)
+
+// Run describes the run that sent a result.
+type Run struct {
+	RunID       string    `json:"run_id"`
+	Package     string    `json:"package"`
+	DryRun      bool      `json:"dry_run"`
+	SkipTagging bool      `json:"skip_tagging"`
+	StartTime   time.Time `json:"start_time"`
+}
+
+// ResultMessage is one instance result streamed while a run goes on.
+type ResultMessage struct {
+	Run
+	Entry executor.ReportEntry `json:"entry"`
+}
+
+// Server receives the results of several runs and keeps their merged report.
+type Server struct {
+	mu      sync.Mutex
+	output  string                      // Aggregated report path ("" = memory only)
+	order   []string                    // Run IDs in order of first message
+	runs    map[string]*executor.Report // Report of each run
+	entries map[string]map[string]int   // Run ID → instance ID → index in Results
+	log     *slog.Logger
+
+	// Aggregated report writes (see Flush)
+	dirty   bool          // Updated since the last write
+	flushes chan struct{} // Asks flushLoop to write now (final reports)
+	writeMu sync.Mutex    // Serializes writes of the report file
+}
+
+// NewServer creates a collector writing the aggregated report to output
+// (see Flush). An existing file at output is overwritten.
+func NewServer(output string, log *slog.Logger) *Server {
+	return &Server{
+		output:  output,
+		runs:    make(map[string]*executor.Report),
+		entries: make(map[string]map[string]int),
+		log:     log,
+		flushes: make(chan struct{}, 1),
+	}
+}
+
+// Handler returns the HTTP handler of the collector API.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST "+ResultsPath, s.handleResult)
+	mux.HandleFunc("POST "+ReportsPath, s.handleReport)
+	mux.HandleFunc("GET "+ReportPath, s.handleGetReport)
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	return mux
+}
+
+// Report returns the merged report of all runs received so far.
+func (s *Server) Report() *executor.Report {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.mergedLocked()
+}
+
+// handleResult adds one streamed instance result to its run.
+func (s *Server) handleResult(w http.ResponseWriter, r *http.Request) {
+	var msg ResultMessage
+	if !decodeBody(w, r, &msg) {
+		return
+	}
+	if msg.RunID == "" {
+		http.Error(w, "run_id is required", http.StatusBadRequest)
+		return
+	}
+	if msg.Entry.InstanceID == "" {
+		http.Error(w, "entry.instance_id is required", http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	report, err := s.runLocked(msg.Run)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	entries := s.entries[msg.RunID]
+	if i, exists := entries[msg.Entry.InstanceID]; exists {
+		report.Results[i] = msg.Entry
+	} else {
+		entries[msg.Entry.InstanceID] = len(report.Results)
+		report.Results = append(report.Results, msg.Entry)
+	}
+	report.EndTime = time.Now().UTC()
+
+	s.log.Debug("Result received", "source", source(r), "run_id", msg.RunID,
+		"instance_id", msg.Entry.InstanceID, "status", msg.Entry.Status)
+	s.updatedLocked(w, false)
+}
+
+// handleReport replaces the streamed results of a run with its final report.
+func (s *Server) handleReport(w http.ResponseWriter, r *http.Request) {
+	var report executor.Report
+	if !decodeBody(w, r, &report) {
+		return
+	}
+	if report.RunID == "" {
+		http.Error(w, "run_id is required", http.StatusBadRequest)
+		return
+	}
+	if report.SchemaVersion != executor.ReportSchemaVersion {
+		http.Error(w, fmt.Sprintf("unsupported report schema_version %d (expected %d)",
+			report.SchemaVersion, executor.ReportSchemaVersion), http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.runLocked(Run{RunID: report.RunID, Package: report.Package}); err != nil {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	entries := make(map[string]int, len(report.Results))
+	for i, entry := range report.Results {
+		entries[entry.InstanceID] = i
+	}
+	s.runs[report.RunID] = &report
+	s.entries[report.RunID] = entries
+
+	s.log.Info("📥 Run report received", "source", source(r), "run_id", report.RunID,
+		"package", report.Package, "instances", len(report.Results))
+	s.updatedLocked(w, true)
+}
+
+// handleGetReport serves the aggregated report.
+func (s *Server) handleGetReport(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(s.Report()); err != nil {
+		s.log.Warn("Failed to send aggregated report", "error", err)
+	}
+}
+
+// runLocked returns the report of run, creating it on its first message.
+// All runs must install the same package: their reports are merged.
+func (s *Server) runLocked(run Run) (*executor.Report, error) {
+	if run.RunID == "" {
+		return nil, errors.New("run_id is required")
+	}
+	if len(s.order) > 0 {
+		if pkg := s.runs[s.order[0]].Package; run.Package != pkg {
+			return nil, fmt.Errorf("run %s installs %q but the collector aggregates %q runs", run.RunID, run.Package, pkg)
+		}
+	}
+
+	if report, exists := s.runs[run.RunID]; exists {
+		return report, nil
+	}
+	report := &executor.Report{
+		SchemaVersion: executor.ReportSchemaVersion,
+		RunID:         run.RunID,
+		Package:       run.Package,
+		DryRun:        run.DryRun,
+		SkipTagging:   run.SkipTagging,
+		StartTime:     run.StartTime,
+		Results:       []executor.ReportEntry{},
+	}
+	s.order = append(s.order, run.RunID)
+	s.runs[run.RunID] = report
+	s.entries[run.RunID] = make(map[string]int)
+	s.log.Info("🛰️  New run streaming results", "run_id", run.RunID, "package", run.Package, "dry_run", run.DryRun)
+	return report, nil
+}
+
+// mergedLocked merges the run reports in order of first message.
+func (s *Server) mergedLocked() *executor.Report {
+	reports := make([]*executor.Report, 0, len(s.order))
+	for _, runID := range s.order {
+		reports = append(reports, s.runs[runID])
+	}
+	return executor.MergeReports(reports)
+}
+
+// updatedLocked marks the aggregated report as changed and acknowledges the
+// request. A final report asks flushLoop to write it now; streamed results
+// wait for the next interval.
+func (s *Server) updatedLocked(w http.ResponseWriter, final bool) {
+	s.dirty = true
+	if final {
+		select {
+		case s.flushes <- struct{}{}:
+		default: // A write is already pending
+		}
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Flush writes the aggregated report if it changed since the last write.
+// The report is merged under the lock and written without it, so requests
+// aren't blocked by the disk. Write failures are logged and returned: the
+// results stay in memory and are written again on the next flush.
+func (s *Server) Flush() error {
+	if s.output == "" {
+		return nil
+	}
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	s.mu.Lock()
+	if !s.dirty {
+		s.mu.Unlock()
+		return nil
+	}
+	report := s.mergedLocked()
+	s.dirty = false
+	s.mu.Unlock()
+
+	if err := writeReportFile(s.output, report); err != nil {
+		s.mu.Lock()
+		s.dirty = true
+		s.mu.Unlock()
+		s.log.Warn("Failed to write aggregated report", "file", s.output, "error", err)
+		return err
+	}
+	return nil
+}
+
+// flushLoop writes the aggregated report every flushInterval and when a
+// final report arrives, until ctx is done.
+func (s *Server) flushLoop(ctx context.Context) {
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-s.flushes:
+		}
+		_ = s.Flush()
+	}
+}
+
+// writeReportFile replaces path atomically, so readers never see a
+// partially written report.
+func writeReportFile(path string, report *executor.Report) error {
+	tmp := filepath.Join(filepath.Dir(path), "."+filepath.Base(path)+".tmp")
+	if err := executor.WriteReport(tmp, report); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+	return nil
+}
+
+// decodeBody decodes the JSON body of r into v, answering 400 on error.
+func decodeBody(w http.ResponseWriter, r *http.Request, v any) bool {
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodyBytes)).Decode(v); err != nil {
+		http.Error(w, "invalid JSON body: "+err.Error(), http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+// source identifies the sender by the common name of its client certificate.
+func source(r *http.Request) string {
+	if r.TLS != nil && len(r.TLS.PeerCertificates) > 0 {
+		return r.TLS.PeerCertificates[0].Subject.CommonName
+	}
+	return r.RemoteAddr
+}
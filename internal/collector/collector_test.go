@@ -0,0 +1,297 @@
+package collector
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"log/slog"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/estudosdevops/opsmaster/internal/executor"
+	"github.com/estudosdevops/opsmaster/internal/httpclient"
+	"github.com/estudosdevops/opsmaster/internal/retry"
+	"github.com/estudosdevops/opsmaster/internal/schema"
+)
+
+// testPKI writes a CA plus a server and a client certificate signed by it.
+type testPKI struct {
+	dir                   string
+	caFile                string
+	serverCert, serverKey string
+	clientCert, clientKey string
+}
+
+func newTestPKI(t *testing.T) *testPKI {
+	t.Helper()
+	dir := t.TempDir()
+	pki := &testPKI{dir: dir, caFile: filepath.Join(dir, "ca.pem")}
+
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "opsmaster test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writePEM(t, pki.caFile, "CERTIFICATE", caDER)
+	caCert, _ := x509.ParseCertificate(caDER)
+
+	issue := func(name string, serial int64, usage x509.ExtKeyUsage) (certFile, keyFile string) {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		template := &x509.Certificate{
+			SerialNumber: big.NewInt(serial),
+			Subject:      pkix.Name{CommonName: name},
+			NotBefore:    time.Now().Add(-time.Hour),
+			NotAfter:     time.Now().Add(time.Hour),
+			ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+			IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		}
+		der, err := x509.CreateCertificate(rand.Reader, template, caCert, &key.PublicKey, caKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		keyDER, err := x509.MarshalECPrivateKey(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		certFile, keyFile = filepath.Join(dir, name+".pem"), filepath.Join(dir, name+"-key.pem")
+		writePEM(t, certFile, "CERTIFICATE", der)
+		writePEM(t, keyFile, "EC PRIVATE KEY", keyDER)
+		return certFile, keyFile
+	}
+	pki.serverCert, pki.serverKey = issue("collector", 2, x509.ExtKeyUsageServerAuth)
+	pki.clientCert, pki.clientKey = issue("bastion-vpc-a", 3, x509.ExtKeyUsageClientAuth)
+	return pki
+}
+
+func writePEM(t *testing.T, path, blockType string, der []byte) {
+	t.Helper()
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// startCollector serves a collector over mTLS and returns its URL.
+func startCollector(t *testing.T, pki *testPKI, output string) (*Server, string) {
+	t.Helper()
+	tlsConfig, err := ServerTLSConfig(pki.serverCert, pki.serverKey, pki.caFile)
+	if err != nil {
+		t.Fatalf("ServerTLSConfig() error: %v", err)
+	}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := NewServer(output, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- server.Serve(ctx, listener, tlsConfig) }()
+	t.Cleanup(func() {
+		cancel()
+		if err := <-done; err != nil {
+			t.Errorf("Serve() error: %v", err)
+		}
+	})
+	return server, "https://" + listener.Addr().String()
+}
+
+// TestCollector_StreamAndMerge tests two bastions streaming over mTLS
+func TestCollector_StreamAndMerge(t *testing.T) {
+	// ARRANGE
+	pki := newTestPKI(t)
+	output := filepath.Join(t.TempDir(), "aggregated.json")
+	server, url := startCollector(t, pki, output)
+
+	httpClient, err := httpclient.New(httpclient.Config{CABundle: pki.caFile, ClientCert: pki.clientCert, ClientKey: pki.clientKey})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	vpcA, err := NewClient(url, httpClient, Run{RunID: "run-a", Package: "puppet"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	vpcB, _ := NewClient(url+"/", httpClient, Run{RunID: "run-b", Package: "puppet"})
+	if err := vpcA.Ping(ctx); err != nil {
+		t.Fatalf("Ping() error: %v", err)
+	}
+
+	// ACT - stream results, then the final report of run-a
+	for _, send := range []struct {
+		client *Client
+		entry  executor.ReportEntry
+	}{
+		{vpcA, executor.ReportEntry{InstanceID: "i-1", Account: "111", Status: "FAILED", Error: "timeout"}},
+		{vpcB, executor.ReportEntry{InstanceID: "i-2", Account: "222", Status: "SUCCESS"}},
+		{vpcA, executor.ReportEntry{InstanceID: "i-3", Account: "111", Status: "SUCCESS"}},
+	} {
+		if err := send.client.SendResult(ctx, send.entry); err != nil {
+			t.Fatalf("SendResult(%s) error: %v", send.entry.InstanceID, err)
+		}
+	}
+	final := &executor.Report{
+		SchemaVersion: executor.ReportSchemaVersion, RunID: "run-a", Package: "puppet",
+		Results: []executor.ReportEntry{
+			{InstanceID: "i-1", Account: "111", Status: "FAILED", Tags: map[string]string{"puppet": "failed"}},
+			{InstanceID: "i-3", Account: "111", Status: "SUCCESS", Tags: map[string]string{"puppet": "true"}},
+		},
+	}
+	if err := vpcA.SendReport(ctx, final); err != nil {
+		t.Fatalf("SendReport() error: %v", err)
+	}
+
+	// ASSERT - the final report is written right away, outside the request
+	got := waitForReport(t, output, func(report *executor.Report) bool {
+		return len(report.Results) == 3 && report.Results[0].Tags["puppet"] != ""
+	})
+	if len(got.Results) != 3 || got.Results[0].Tags["puppet"] != "failed" || got.Results[2].InstanceID != "i-2" {
+		t.Errorf("unexpected aggregated results %+v", got.Results)
+	}
+	if len(got.Accounts) != 2 || got.Accounts[0].Key != "111" || got.Accounts[0].Failed != 1 {
+		t.Errorf("unexpected account groups %+v", got.Accounts)
+	}
+	data, _ := os.ReadFile(output)
+	if _, violations, err := schema.Validate(schema.KindReport, data); err != nil || len(violations) > 0 {
+		t.Errorf("aggregated report does not match the report schema: %v %v", err, violations)
+	}
+	if report := server.Report(); len(report.Results) != 3 {
+		t.Errorf("Report() has %d results", len(report.Results))
+	}
+
+	// Runs of another package are rejected
+	osquery, _ := NewClient(url, httpClient, Run{RunID: "run-c", Package: "osquery"})
+	if err := osquery.SendResult(ctx, executor.ReportEntry{InstanceID: "i-9"}); err == nil || !strings.Contains(err.Error(), "409") {
+		t.Errorf("expected conflict for another package, got %v", err)
+	}
+}
+
+// waitForReport waits until the report file at path satisfies done.
+func waitForReport(t *testing.T, path string, done func(*executor.Report) bool) *executor.Report {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		report, err := executor.ReadReport(path)
+		if err == nil && done(report) {
+			return report
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("aggregated report not written: %v", err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// TestServer_Flush tests that streamed results are written by Flush, not
+// in the request path
+func TestServer_Flush(t *testing.T) {
+	// ARRANGE
+	output := filepath.Join(t.TempDir(), "aggregated.json")
+	server := NewServer(output, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	handler := server.Handler()
+	body := `{"run_id":"run-a","package":"puppet","entry":{"instance_id":"i-1","status":"SUCCESS"}}`
+
+	// ACT
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, ResultsPath, strings.NewReader(body)))
+
+	// ASSERT
+	if recorder.Code != http.StatusNoContent {
+		t.Fatalf("POST %s = %d, want 204", ResultsPath, recorder.Code)
+	}
+	if _, err := os.Stat(output); !os.IsNotExist(err) {
+		t.Fatalf("report written in the request path (stat error %v)", err)
+	}
+	if err := server.Flush(); err != nil {
+		t.Fatalf("Flush() error: %v", err)
+	}
+	got, err := executor.ReadReport(output)
+	if err != nil || len(got.Results) != 1 {
+		t.Fatalf("ReadReport() = %v, %v; want 1 result", got, err)
+	}
+
+	// Nothing changed: the file is not rewritten
+	os.Remove(output)
+	if err := server.Flush(); err != nil {
+		t.Fatalf("Flush() error: %v", err)
+	}
+	if _, err := os.Stat(output); !os.IsNotExist(err) {
+		t.Error("unchanged report was written again")
+	}
+}
+
+// TestServer_RejectsInvalidMessages tests that malformed results and
+// reports are bad requests, not conflicts
+func TestServer_RejectsInvalidMessages(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+		body string
+	}{
+		{"result without run_id", ResultsPath, `{"package":"puppet","entry":{"instance_id":"i-1"}}`},
+		{"result without instance_id", ResultsPath, `{"run_id":"run-a","package":"puppet","entry":{}}`},
+		{"report without run_id", ReportsPath, `{"schema_version":` + strconv.Itoa(executor.ReportSchemaVersion) + `,"package":"puppet"}`},
+		{"report with another schema_version", ReportsPath, `{"schema_version":999,"run_id":"run-a","package":"puppet"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewServer(filepath.Join(t.TempDir(), "aggregated.json"), slog.New(slog.NewTextHandler(io.Discard, nil))).Handler()
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(tt.body)))
+			if recorder.Code != http.StatusBadRequest {
+				t.Errorf("POST %s = %d, want 400 (%s)", tt.path, recorder.Code, strings.TrimSpace(recorder.Body.String()))
+			}
+		})
+	}
+}
+
+// TestCollector_RequiresClientCertificate tests that mTLS is enforced
+func TestCollector_RequiresClientCertificate(t *testing.T) {
+	pki := newTestPKI(t)
+	_, url := startCollector(t, pki, "")
+
+	httpClient, err := httpclient.New(httpclient.Config{CABundle: pki.caFile, RetryConfig: &retry.RetryConfig{MaxAttempts: 1}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, _ := NewClient(url, httpClient, Run{RunID: "run-a", Package: "puppet"})
+
+	if err := client.Ping(context.Background()); err == nil {
+		t.Fatal("expected request without client certificate to fail")
+	}
+}
+
+func TestNewClient_InvalidURL(t *testing.T) {
+	for _, rawURL := range []string{"http://collector:8443", "collector:8443", "https://"} {
+		if _, err := NewClient(rawURL, http.DefaultClient, Run{}); err == nil {
+			t.Errorf("NewClient(%q) expected error", rawURL)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package collector
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"time"
+)
+
+// shutdownTimeout bounds the wait for in-flight requests on shutdown.
+const shutdownTimeout = 10 * time.Second
+
+// ServerTLSConfig builds the mutual TLS configuration of the collector:
+// its certificate and key, and the CA that must have signed the client
+// certificates of the runs (system CAs are not trusted for clients).
+func ServerTLSConfig(certFile, keyFile, clientCAFile string) (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load collector certificate: %w", err)
+	}
+
+	pem, err := os.ReadFile(clientCAFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read client CA: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no valid PEM certificates found in client CA %s", clientCAFile)
+	}
+
+	return &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		Certificates: []tls.Certificate{cert},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    pool,
+	}, nil
+}
+
+// Serve accepts mutual TLS connections on listener until ctx is canceled,
+// then waits for in-flight requests. The aggregated report is written in
+// the background while serving and once more before returning.
+func (s *Server) Serve(ctx context.Context, listener net.Listener, tlsConfig *tls.Config) error {
+	server := &http.Server{
+		Handler:           s.Handler(),
+		TLSConfig:         tlsConfig,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	flushCtx, stopFlush := context.WithCancel(context.Background())
+	go s.flushLoop(flushCtx)
+	defer func() {
+		stopFlush()
+		_ = s.Flush() // Logged by Flush
+	}()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			s.log.Warn("Collector shutdown did not complete", "error", err)
+		}
+	}()
+
+	err := server.Serve(tls.NewListener(listener, tlsConfig))
+	if errors.Is(err, http.ErrServerClosed) {
+		<-done
+		return nil
+	}
+	return err
+}
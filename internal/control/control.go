@@ -0,0 +1,264 @@
+// Package control exposes a running command (e.g., install puppet) on a
+// local unix socket, so an operator can pause, resume or abort the dispatch
+// of new instances with "opsmaster ctl" during an incident, without killing
+// the process and losing the work in flight.
+//
+// Each run listens on <Dir>/<run-id>.sock (directory and socket readable by
+// the current user only, see CheckDir) and serves a small HTTP API:
+//
+//	GET  /status   current Status
+//	POST /pause    stop dispatching new instances
+//	POST /resume   dispatch again
+//	POST /abort    cancel the queued instances; in-flight ones finish
+package control
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// States of a controlled run.
+const (
+	StateStarting = "starting" // Not dispatching yet (pre-flight checks)
+	StateRunning  = "running"
+	StatePaused   = "paused"
+	StateAborting = "aborting" // Queued instances canceled, in-flight ones finishing
+)
+
+// Status is the progress of a controlled run.
+type Status struct {
+	RunID     string     `json:"run_id"`
+	Command   string     `json:"command"`
+	PID       int        `json:"pid"`
+	State     string     `json:"state"`
+	StartedAt time.Time  `json:"started_at"`
+	PausedAt  *time.Time `json:"paused_at,omitempty"` // Set while paused
+	Total     int        `json:"total"`
+	Queued    int        `json:"queued"`
+	InFlight  int        `json:"in_flight"`
+	Completed int        `json:"completed"`
+}
+
+// Controller is implemented by the runs exposed on the socket (see
+// executor.RunControl).
+type Controller interface {
+	Pause() error
+	Resume() error
+	Abort() error
+	Status() Status
+}
+
+// Actions accepted by the socket (POST /<action>).
+var actions = []string{"pause", "resume", "abort"}
+
+// Dir returns the directory of the control sockets of the current user:
+// $XDG_RUNTIME_DIR/opsmaster when set (private to the user), otherwise
+// opsmaster-<uid> in the temporary directory.
+func Dir() string {
+	if runtimeDir := os.Getenv("XDG_RUNTIME_DIR"); runtimeDir != "" {
+		return filepath.Join(runtimeDir, "opsmaster")
+	}
+	return filepath.Join(os.TempDir(), fmt.Sprintf("opsmaster-%d", os.Getuid()))
+}
+
+// CheckDir rejects a socket directory another user could have created or
+// can write to (Dir has a predictable name in the shared temporary
+// directory): it must be a real directory, not a symlink, owned by the
+// current user and accessible by them only (mode 0700).
+func CheckDir(dir string) error {
+	info, err := os.Lstat(dir)
+	if err != nil {
+		return fmt.Errorf("failed to check control socket directory: %w", err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("control socket directory %s is not a directory", dir)
+	}
+	if err := checkPrivate(info); err != nil {
+		return fmt.Errorf("control socket directory %s: %w", dir, err)
+	}
+	return nil
+}
+
+// SocketPath returns the control socket of a run.
+func SocketPath(runID string) string {
+	return filepath.Join(Dir(), runID+".sock")
+}
+
+// Server serves a Controller on a unix socket.
+type Server struct {
+	path   string
+	server *http.Server
+}
+
+// Listen starts serving ctl on the unix socket at path. A socket left by a
+// run that is gone is replaced; a live one is an error.
+func Listen(path string, ctl Controller) (*Server, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create control socket directory: %w", err)
+	}
+	if err := CheckDir(filepath.Dir(path)); err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(path); err == nil {
+		if alive(path) {
+			return nil, fmt.Errorf("control socket %s is in use by another run", path)
+		}
+		_ = os.Remove(path)
+	}
+
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on control socket: %w", err)
+	}
+	if err := os.Chmod(path, 0o600); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("failed to restrict control socket: %w", err)
+	}
+
+	s := &Server{path: path, server: &http.Server{Handler: newHandler(ctl), ReadHeaderTimeout: 5 * time.Second}}
+	go func() {
+		_ = s.server.Serve(listener) // ErrServerClosed after Close
+	}()
+	return s, nil
+}
+
+// Path returns the socket path.
+func (s *Server) Path() string {
+	return s.path
+}
+
+// Close stops serving and removes the socket.
+func (s *Server) Close() error {
+	err := s.server.Close()
+	_ = os.Remove(s.path)
+	return err
+}
+
+// newHandler routes the control API to ctl.
+func newHandler(ctl Controller) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /status", func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, http.StatusOK, ctl.Status())
+	})
+	handlers := map[string]func() error{"pause": ctl.Pause, "resume": ctl.Resume, "abort": ctl.Abort}
+	for action, do := range handlers {
+		mux.HandleFunc("POST /"+action, func(w http.ResponseWriter, _ *http.Request) {
+			if err := do(); err != nil {
+				writeJSON(w, http.StatusConflict, map[string]string{"error": err.Error()})
+				return
+			}
+			writeJSON(w, http.StatusOK, ctl.Status())
+		})
+	}
+	return mux
+}
+
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// Client talks to the control socket of a run.
+type Client struct {
+	path string
+	http *http.Client
+}
+
+// NewClient returns a client of the socket at path.
+func NewClient(path string) *Client {
+	return &Client{
+		path: path,
+		http: &http.Client{
+			Timeout: 10 * time.Second,
+			Transport: &http.Transport{
+				DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+					var dialer net.Dialer
+					return dialer.DialContext(ctx, "unix", path)
+				},
+			},
+		},
+	}
+}
+
+// Status returns the status of the run.
+func (c *Client) Status(ctx context.Context) (Status, error) {
+	return c.do(ctx, http.MethodGet, "status")
+}
+
+// Send sends an action (pause, resume or abort) and returns the status
+// after it.
+func (c *Client) Send(ctx context.Context, action string) (Status, error) {
+	if !slices.Contains(actions, action) {
+		return Status{}, fmt.Errorf("unknown action %q (supported: %s)", action, strings.Join(actions, ", "))
+	}
+	return c.do(ctx, http.MethodPost, action)
+}
+
+func (c *Client) do(ctx context.Context, method, endpoint string) (Status, error) {
+	req, err := http.NewRequestWithContext(ctx, method, "http://opsmaster/"+endpoint, nil)
+	if err != nil {
+		return Status{}, err
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return Status{}, fmt.Errorf("run not reachable at %s: %w", c.path, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var failure struct {
+			Error string `json:"error"`
+		}
+		if json.NewDecoder(resp.Body).Decode(&failure) == nil && failure.Error != "" {
+			return Status{}, errors.New(failure.Error)
+		}
+		return Status{}, fmt.Errorf("control socket returned %s", resp.Status)
+	}
+	var status Status
+	if err := json.NewDecoder(resp.Body).Decode(&status); err != nil {
+		return Status{}, fmt.Errorf("invalid control socket response: %w", err)
+	}
+	return status, nil
+}
+
+// Discover returns the sockets in Dir of runs that are still alive, sorted
+// by name. Dir must pass CheckDir (a missing one has no runs).
+func Discover() ([]string, error) {
+	if _, err := os.Lstat(Dir()); errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err := CheckDir(Dir()); err != nil {
+		return nil, err
+	}
+	paths, err := filepath.Glob(filepath.Join(Dir(), "*.sock"))
+	if err != nil {
+		return nil, err
+	}
+	var live []string
+	for _, path := range paths {
+		if alive(path) {
+			live = append(live, path)
+		}
+	}
+	return live, nil
+}
+
+// alive reports whether a process accepts connections on the socket.
+func alive(path string) bool {
+	conn, err := net.DialTimeout("unix", path, time.Second)
+	if err != nil {
+		return false
+	}
+	conn.Close()
+	return true
+}
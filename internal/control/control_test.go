@@ -0,0 +1,181 @@
+package control
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeController records the actions it receives.
+type fakeController struct {
+	mu     sync.Mutex
+	state  string
+	failOn string
+}
+
+func (f *fakeController) set(action, state string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if action == f.failOn {
+		return errors.New("run is aborting")
+	}
+	f.state = state
+	return nil
+}
+
+func (f *fakeController) Pause() error  { return f.set("pause", StatePaused) }
+func (f *fakeController) Resume() error { return f.set("resume", StateRunning) }
+func (f *fakeController) Abort() error  { return f.set("abort", StateAborting) }
+
+func (f *fakeController) Status() Status {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return Status{RunID: "run-1", State: f.state, Total: 10}
+}
+
+// socketPath returns a short socket path (unix sockets are limited to ~100
+// bytes, t.TempDir() may exceed it).
+func socketPath(t *testing.T) string {
+	t.Helper()
+	dir, err := os.MkdirTemp("", "ctl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	return filepath.Join(dir, "run-1.sock")
+}
+
+func TestServerClient(t *testing.T) {
+	path := socketPath(t)
+	ctl := &fakeController{state: StateRunning, failOn: "resume"}
+	server, err := Listen(path, ctl)
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	defer server.Close()
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("socket not created: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Errorf("socket permissions = %o, want 600", perm)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	client := NewClient(path)
+
+	status, err := client.Status(ctx)
+	if err != nil || status.RunID != "run-1" || status.State != StateRunning || status.Total != 10 {
+		t.Fatalf("Status() = %+v, %v", status, err)
+	}
+	if status, err = client.Send(ctx, "pause"); err != nil || status.State != StatePaused {
+		t.Errorf("Send(pause) = %+v, %v, want paused", status, err)
+	}
+	if _, err = client.Send(ctx, "resume"); err == nil || err.Error() != "run is aborting" {
+		t.Errorf("Send(resume) error = %v, want the controller error", err)
+	}
+	if _, err = client.Send(ctx, "kill"); err == nil || !strings.Contains(err.Error(), "unknown action") {
+		t.Errorf("Send(kill) error = %v, want unknown action", err)
+	}
+
+	// A second run can't take a live socket
+	if _, err := Listen(path, ctl); err == nil || !strings.Contains(err.Error(), "in use") {
+		t.Errorf("Listen() on a live socket error = %v, want in use", err)
+	}
+
+	server.Close()
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("socket not removed on Close: %v", err)
+	}
+	if _, err := client.Status(ctx); err == nil {
+		t.Error("Status() after Close should fail")
+	}
+}
+
+// TestListen_StaleSocket tests that a socket left by a dead run is replaced
+func TestListen_StaleSocket(t *testing.T) {
+	path := socketPath(t)
+	if err := os.WriteFile(path, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	server, err := Listen(path, &fakeController{state: StateRunning})
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	defer server.Close()
+
+	if !alive(path) {
+		t.Error("replaced socket is not alive")
+	}
+}
+
+func TestCheckDir(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("directory modes are not enforced on Windows")
+	}
+	base := t.TempDir()
+	private := filepath.Join(base, "private")
+	shared := filepath.Join(base, "shared")
+	link := filepath.Join(base, "link")
+	for _, dir := range []string{private, shared} {
+		if err := os.Mkdir(dir, 0o700); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Chmod(shared, 0o777); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(private, link); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		dir     string
+		wantErr string
+	}{
+		{name: "private directory", dir: private},
+		{name: "writable by others", dir: shared, wantErr: "mode 0777"},
+		{name: "symlink", dir: link, wantErr: "not a directory"},
+		{name: "missing", dir: filepath.Join(base, "missing"), wantErr: "failed to check"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckDir(tt.dir)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("CheckDir() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("CheckDir() error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	// Listen refuses to serve from a directory others can write to
+	if _, err := Listen(filepath.Join(shared, "run-1.sock"), &fakeController{}); err == nil {
+		t.Error("Listen() in a shared directory should fail")
+	}
+}
+
+func TestDir(t *testing.T) {
+	t.Setenv("XDG_RUNTIME_DIR", "/run/user/1000")
+	if got := Dir(); got != filepath.Join("/run/user/1000", "opsmaster") {
+		t.Errorf("Dir() = %q, want under XDG_RUNTIME_DIR", got)
+	}
+	t.Setenv("XDG_RUNTIME_DIR", "")
+	if got := Dir(); !strings.HasPrefix(got, os.TempDir()) {
+		t.Errorf("Dir() = %q, want under %s", got, os.TempDir())
+	}
+}
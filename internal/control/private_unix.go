@@ -0,0 +1,21 @@
+//go:build !windows
+
+package control
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// checkPrivate checks that a directory is owned by the current user and
+// has mode 0700.
+func checkPrivate(info os.FileInfo) error {
+	if stat, ok := info.Sys().(*syscall.Stat_t); ok && int(stat.Uid) != os.Getuid() {
+		return fmt.Errorf("owned by uid %d, not the current user (%d)", stat.Uid, os.Getuid())
+	}
+	if perm := info.Mode().Perm(); perm != 0o700 {
+		return fmt.Errorf("mode %04o, expected 0700", perm)
+	}
+	return nil
+}
@@ -0,0 +1,280 @@
+// Package coverage compares an inventory with the desired state of the
+// fleet: the share of instances carrying the expected tags (e.g., puppet=true,
+// applied by "install puppet"), overall and broken down by account, region
+// or any CSV column.
+package coverage
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/estudosdevops/opsmaster/internal/cloud"
+)
+
+// Instance statuses.
+const (
+	StatusCovered   = "covered"   // Carries every expected tag
+	StatusUncovered = "uncovered" // Missing or different tag
+	StatusUnknown   = "unknown"   // Not found by the provider or terminated (outside the percentage)
+)
+
+// DefaultDimensions are the breakdowns of a report.
+var DefaultDimensions = []string{"account", "region", "environment"}
+
+// Expectation is a tag the instances should carry.
+type Expectation struct {
+	Key   string
+	Value string
+}
+
+// String returns the expectation as key=value.
+func (e Expectation) String() string {
+	return e.Key + "=" + e.Value
+}
+
+// ParseExpectations parses key=value expectations (e.g., puppet=true).
+func ParseExpectations(values []string) ([]Expectation, error) {
+	if len(values) == 0 {
+		return nil, errors.New("at least one expectation is required (e.g., puppet=true)")
+	}
+	expectations := make([]Expectation, 0, len(values))
+	for _, value := range values {
+		key, expected, ok := strings.Cut(value, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid expectation %q: use tag=value (e.g., puppet=true)", value)
+		}
+		expectations = append(expectations, Expectation{Key: key, Value: strings.TrimSpace(expected)})
+	}
+	return expectations, nil
+}
+
+// Group is the coverage of the instances sharing a dimension value.
+type Group struct {
+	Dimension string  `json:"dimension"`
+	Value     string  `json:"value"`
+	Covered   int     `json:"covered"`
+	Uncovered int     `json:"uncovered"`
+	Unknown   int     `json:"unknown"`
+	Percent   float64 `json:"percent"`
+}
+
+// Entry is the coverage status of one instance.
+type Entry struct {
+	InstanceID string `json:"instance_id"`
+	Account    string `json:"account"`
+	Region     string `json:"region"`
+	Status     string `json:"status"`
+	Detail     string `json:"detail,omitempty"` // Tags that don't match, or why the status is unknown
+}
+
+// Report is the coverage of an inventory.
+type Report struct {
+	GeneratedAt time.Time `json:"generated_at"`
+	Inventory   string    `json:"inventory"`
+	Expect      []string  `json:"expect"`
+	Total       int       `json:"total"`
+	Covered     int       `json:"covered"`
+	Uncovered   int       `json:"uncovered"`
+	Unknown     int       `json:"unknown"`
+	Percent     float64   `json:"percent"` // Covered / (covered + uncovered)
+	Groups      []Group   `json:"groups"`  // By dimension, then lowest coverage first
+	Entries     []Entry   `json:"entries,omitempty"`
+
+	// Last point of the history with the same expectations (see LastPoint)
+	Previous *HistoryPoint `json:"previous,omitempty"`
+}
+
+// Compute builds the coverage report of instances from their provider
+// metadata (infos, keyed by instance ID). Dimensions are account, region or
+// CSV columns; instances without the column count under "-".
+func Compute(instances []*cloud.Instance, infos map[string]*cloud.InstanceInfo, expect []Expectation, dimensions []string) *Report {
+	report := &Report{GeneratedAt: time.Now().UTC(), Total: len(instances)}
+	for _, expectation := range expect {
+		report.Expect = append(report.Expect, expectation.String())
+	}
+
+	groups := make(map[[2]string]*Group)
+	for _, instance := range instances {
+		entry := Entry{InstanceID: instance.ID, Account: instance.Account, Region: instance.Region}
+		entry.Status, entry.Detail = status(infos[instance.ID], expect)
+		report.Entries = append(report.Entries, entry)
+		count(&report.Covered, &report.Uncovered, &report.Unknown, entry.Status)
+
+		for _, dimension := range dimensions {
+			value := dimensionValue(instance, dimension)
+			group, ok := groups[[2]string{dimension, value}]
+			if !ok {
+				group = &Group{Dimension: dimension, Value: value}
+				groups[[2]string{dimension, value}] = group
+			}
+			count(&group.Covered, &group.Uncovered, &group.Unknown, entry.Status)
+		}
+	}
+
+	report.Percent = percent(report.Covered, report.Uncovered)
+	for _, group := range groups {
+		group.Percent = percent(group.Covered, group.Uncovered)
+		report.Groups = append(report.Groups, *group)
+	}
+	order := make(map[string]int, len(dimensions))
+	for i, dimension := range dimensions {
+		order[dimension] = i
+	}
+	sort.Slice(report.Groups, func(i, j int) bool {
+		a, b := report.Groups[i], report.Groups[j]
+		if a.Dimension != b.Dimension {
+			return order[a.Dimension] < order[b.Dimension]
+		}
+		if a.Percent != b.Percent {
+			return a.Percent < b.Percent
+		}
+		return a.Value < b.Value
+	})
+	return report
+}
+
+// status returns the coverage status of an instance and its detail.
+func status(info *cloud.InstanceInfo, expect []Expectation) (string, string) {
+	if info == nil {
+		return StatusUnknown, "not found by the provider"
+	}
+	if info.State == "terminated" {
+		return StatusUnknown, "terminated"
+	}
+	var mismatches []string
+	for _, expectation := range expect {
+		value, ok := info.Tags[expectation.Key]
+		switch {
+		case !ok:
+			mismatches = append(mismatches, expectation.Key+" missing")
+		case value != expectation.Value:
+			mismatches = append(mismatches, fmt.Sprintf("%s=%s", expectation.Key, value))
+		}
+	}
+	if len(mismatches) > 0 {
+		return StatusUncovered, strings.Join(mismatches, ", ")
+	}
+	return StatusCovered, ""
+}
+
+// count increments the counter of status.
+func count(covered, uncovered, unknown *int, status string) {
+	switch status {
+	case StatusCovered:
+		*covered++
+	case StatusUncovered:
+		*uncovered++
+	default:
+		*unknown++
+	}
+}
+
+// percent returns covered as a percentage of the known instances (0 when
+// there are none), rounded to one decimal.
+func percent(covered, uncovered int) float64 {
+	if covered+uncovered == 0 {
+		return 0
+	}
+	return float64(int(1000*float64(covered)/float64(covered+uncovered)+0.5)) / 10
+}
+
+// dimensionValue returns the value of a breakdown dimension for instance.
+func dimensionValue(instance *cloud.Instance, dimension string) string {
+	var value string
+	switch dimension {
+	case "account":
+		value = instance.Account
+	case "region":
+		value = instance.Region
+	default:
+		value = instance.Metadata[strings.ToLower(dimension)]
+	}
+	if value == "" {
+		return "-"
+	}
+	return value
+}
+
+// HistoryPoint is one report summary in a history file.
+type HistoryPoint struct {
+	GeneratedAt time.Time `json:"generated_at"`
+	Inventory   string    `json:"inventory"`
+	Expect      []string  `json:"expect"`
+	Covered     int       `json:"covered"`
+	Uncovered   int       `json:"uncovered"`
+	Unknown     int       `json:"unknown"`
+	Percent     float64   `json:"percent"`
+	Groups      []Group   `json:"groups"`
+}
+
+// Point returns the summary of the report kept in a history file.
+func (r *Report) Point() HistoryPoint {
+	return HistoryPoint{
+		GeneratedAt: r.GeneratedAt,
+		Inventory:   r.Inventory,
+		Expect:      r.Expect,
+		Covered:     r.Covered,
+		Uncovered:   r.Uncovered,
+		Unknown:     r.Unknown,
+		Percent:     r.Percent,
+		Groups:      r.Groups,
+	}
+}
+
+// LastPoint returns the last point of a history file (JSON lines) with the
+// same expectations, nil when there is none or the file doesn't exist.
+func LastPoint(path string, expect []string) (*HistoryPoint, error) {
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open coverage history: %w", err)
+	}
+	defer file.Close()
+
+	var last *HistoryPoint
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+		var point HistoryPoint
+		if err := json.Unmarshal(scanner.Bytes(), &point); err != nil {
+			return nil, fmt.Errorf("invalid coverage history %s line %d: %w", path, line, err)
+		}
+		if strings.Join(point.Expect, ",") == strings.Join(expect, ",") {
+			last = &point
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read coverage history: %w", err)
+	}
+	return last, nil
+}
+
+// AppendPoint appends a point to a history file (JSON lines), creating it
+// when missing.
+func AppendPoint(path string, point HistoryPoint) error {
+	data, err := json.Marshal(point)
+	if err != nil {
+		return err
+	}
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open coverage history: %w", err)
+	}
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write coverage history: %w", err)
+	}
+	return file.Close()
+}
@@ -0,0 +1,116 @@
+package coverage
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/estudosdevops/opsmaster/internal/cloud"
+)
+
+func TestParseExpectations(t *testing.T) {
+	tests := []struct {
+		name    string
+		values  []string
+		want    string
+		wantErr string
+	}{
+		{name: "tag", values: []string{"puppet=true"}, want: "puppet=true"},
+		{name: "several", values: []string{"puppet=true", " opsmaster:puppet:status = success"}, want: "puppet=true,opsmaster:puppet:status=success"},
+		{name: "empty value", values: []string{"owner="}, want: "owner="},
+		{name: "none", wantErr: "at least one expectation"},
+		{name: "no value", values: []string{"puppet"}, wantErr: "invalid expectation"},
+		{name: "no key", values: []string{"=true"}, wantErr: "invalid expectation"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expectations, err := ParseExpectations(tt.values)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			got := make([]string, len(expectations))
+			for i, expectation := range expectations {
+				got[i] = expectation.String()
+			}
+			if strings.Join(got, ",") != tt.want {
+				t.Errorf("ParseExpectations() = %v, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompute(t *testing.T) {
+	instances := []*cloud.Instance{
+		{ID: "i-1", Account: "111", Region: "us-east-1", Metadata: map[string]string{"environment": "prod"}},
+		{ID: "i-2", Account: "111", Region: "us-east-1", Metadata: map[string]string{"environment": "prod"}},
+		{ID: "i-3", Account: "222", Region: "sa-east-1", Metadata: map[string]string{"environment": "dev"}},
+		{ID: "i-4", Account: "222", Region: "sa-east-1"},
+		{ID: "i-5", Account: "222", Region: "sa-east-1"},
+	}
+	infos := map[string]*cloud.InstanceInfo{
+		"i-1": {ID: "i-1", State: "running", Tags: map[string]string{"puppet": "true"}},
+		"i-2": {ID: "i-2", State: "running", Tags: map[string]string{"puppet": "false"}},
+		"i-3": {ID: "i-3", State: "stopped", Tags: map[string]string{"puppet": "true"}},
+		"i-4": {ID: "i-4", State: "running"},
+		"i-5": {ID: "i-5", State: "terminated", Tags: map[string]string{"puppet": "true"}},
+	}
+	expect, _ := ParseExpectations([]string{"puppet=true"})
+
+	report := Compute(instances, infos, expect, DefaultDimensions)
+
+	if report.Total != 5 || report.Covered != 2 || report.Uncovered != 2 || report.Unknown != 1 || report.Percent != 50 {
+		t.Errorf("report = %d total, %d covered, %d uncovered, %d unknown, %.1f%%, want 5, 2, 2, 1, 50%%",
+			report.Total, report.Covered, report.Uncovered, report.Unknown, report.Percent)
+	}
+
+	details := map[string]string{}
+	for _, entry := range report.Entries {
+		details[entry.InstanceID] = entry.Status + " " + entry.Detail
+	}
+	if details["i-2"] != "uncovered puppet=false" || details["i-4"] != "uncovered puppet missing" || details["i-5"] != "unknown terminated" {
+		t.Errorf("entries = %v", details)
+	}
+
+	var got []string
+	for _, group := range report.Groups {
+		got = append(got, group.Dimension+":"+group.Value)
+	}
+	want := "account:111,account:222,region:sa-east-1,region:us-east-1,environment:-,environment:prod,environment:dev"
+	if strings.Join(got, ",") != want {
+		t.Errorf("groups = %v, want %s", got, want)
+	}
+	if group := report.Groups[1]; group.Covered != 1 || group.Uncovered != 1 || group.Unknown != 1 || group.Percent != 50 {
+		t.Errorf("account 222 = %+v, want 1 covered, 1 uncovered, 1 unknown, 50%%", group)
+	}
+}
+
+func TestHistory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "coverage.jsonl")
+
+	if point, err := LastPoint(path, []string{"puppet=true"}); err != nil || point != nil {
+		t.Fatalf("LastPoint() on missing file = %v, %v, want nil", point, err)
+	}
+
+	for _, point := range []HistoryPoint{
+		{Expect: []string{"puppet=true"}, Percent: 80},
+		{Expect: []string{"osquery=true"}, Percent: 10},
+		{Expect: []string{"puppet=true"}, Percent: 90},
+		{Expect: []string{"osquery=true"}, Percent: 20},
+	} {
+		if err := AppendPoint(path, point); err != nil {
+			t.Fatalf("AppendPoint() error = %v", err)
+		}
+	}
+
+	point, err := LastPoint(path, []string{"puppet=true"})
+	if err != nil || point == nil || point.Percent != 90 {
+		t.Errorf("LastPoint() = %+v, %v, want the last puppet=true point (90%%)", point, err)
+	}
+}
@@ -0,0 +1,410 @@
+// Package criteria evaluates the small boolean expressions teams use to
+// define acceptable outcomes (install --success-when), e.g.:
+//
+//	metadata.certname_preserved == "true" || phase_durations.install < 300s
+//	status == "SUCCESS" && !(exit_code != 0)
+//	error =~ "already registered" && failure_phase == "verify"
+//
+// Expressions compare fields (dotted names resolved by the caller) with
+// literals or other fields:
+//
+//	== != < <= > >=   comparison: numbers ("3", "1.5") and durations ("300s",
+//	                  "2m30s") compare by value, anything else as text;
+//	                  < <= > >= are false when either side isn't a number or
+//	                  a duration (e.g., a missing field)
+//	=~ !~             regular expression match ("..." pattern)
+//	&& || ! ( )       boolean logic, ! binds tightest, then &&, then ||
+//
+// A bare field is true when its value is "true"; true and false are literals.
+// Strings use double quotes with Go escapes, or single quotes taken as is.
+package criteria
+
+import (
+	"cmp"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Resolver returns the value of a field of the evaluated record ("" if unset).
+type Resolver func(field string) string
+
+// Expr is a parsed expression.
+type Expr struct {
+	source string
+	root   node
+	fields []string
+}
+
+// Parse parses an expression. Regular expressions are compiled up front,
+// so an invalid expression fails before anything runs.
+func Parse(source string) (*Expr, error) {
+	p := &parser{source: source}
+	if err := p.tokenize(); err != nil {
+		return nil, fmt.Errorf("invalid expression %q: %w", source, err)
+	}
+	root, err := p.parseOr()
+	if err == nil && p.peek().kind != tokenEOF {
+		err = fmt.Errorf("unexpected %s at position %d", p.peek(), p.peek().pos+1)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid expression %q: %w", source, err)
+	}
+	return &Expr{source: source, root: root, fields: p.fields}, nil
+}
+
+// String returns the expression source.
+func (e *Expr) String() string {
+	return e.source
+}
+
+// Fields returns the fields referenced by the expression, in order of
+// appearance, so callers can reject unknown names before evaluating.
+func (e *Expr) Fields() []string {
+	return e.fields
+}
+
+// Eval evaluates the expression with the fields returned by resolve.
+func (e *Expr) Eval(resolve Resolver) bool {
+	return e.root.eval(resolve)
+}
+
+// node is an element of the expression tree.
+type node interface {
+	eval(resolve Resolver) bool
+}
+
+type orNode struct{ left, right node }
+
+func (n orNode) eval(resolve Resolver) bool { return n.left.eval(resolve) || n.right.eval(resolve) }
+
+type andNode struct{ left, right node }
+
+func (n andNode) eval(resolve Resolver) bool { return n.left.eval(resolve) && n.right.eval(resolve) }
+
+type notNode struct{ operand node }
+
+func (n notNode) eval(resolve Resolver) bool { return !n.operand.eval(resolve) }
+
+// truthNode is a bare operand: true when its value is "true".
+type truthNode struct{ operand operand }
+
+func (n truthNode) eval(resolve Resolver) bool { return n.operand.value(resolve) == "true" }
+
+type compareNode struct {
+	op          string
+	left, right operand
+	pattern     *regexp.Regexp // =~ and !~
+}
+
+func (n compareNode) eval(resolve Resolver) bool {
+	left := n.left.value(resolve)
+	switch n.op {
+	case "=~":
+		return n.pattern.MatchString(left)
+	case "!~":
+		return !n.pattern.MatchString(left)
+	}
+
+	right := n.right.value(resolve)
+	order, ordered := compareValues(left, right)
+	switch n.op {
+	case "==":
+		return left == right || (ordered && order == 0)
+	case "!=":
+		return left != right && (!ordered || order != 0)
+	case "<":
+		return ordered && order < 0
+	case "<=":
+		return ordered && order <= 0
+	case ">":
+		return ordered && order > 0
+	case ">=":
+		return ordered && order >= 0
+	default:
+		return false
+	}
+}
+
+// compareValues orders two values as numbers or, failing that, as
+// durations. ordered is false when neither applies to both.
+func compareValues(a, b string) (order int, ordered bool) {
+	if x, err := strconv.ParseFloat(a, 64); err == nil {
+		if y, err := strconv.ParseFloat(b, 64); err == nil {
+			return cmp.Compare(x, y), true
+		}
+	}
+	if x, err := time.ParseDuration(a); err == nil {
+		if y, err := time.ParseDuration(b); err == nil {
+			return cmp.Compare(x, y), true
+		}
+	}
+	return 0, false
+}
+
+// operand is a field reference or a literal.
+type operand struct {
+	field   string // Field name ("" = literal)
+	literal string
+}
+
+func (o operand) value(resolve Resolver) string {
+	if o.field != "" {
+		return resolve(o.field)
+	}
+	return o.literal
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenField
+	tokenString
+	tokenNumber // Numbers and durations
+	tokenOp
+	tokenLParen
+	tokenRParen
+)
+
+type token struct {
+	kind tokenKind
+	text string // Operator, field name or literal value (unquoted)
+	pos  int
+}
+
+func (t token) String() string {
+	switch t.kind {
+	case tokenEOF:
+		return "end of expression"
+	case tokenString:
+		return strconv.Quote(t.text)
+	default:
+		return fmt.Sprintf("%q", t.text)
+	}
+}
+
+// operators in matching order: two-character operators before their
+// one-character prefixes.
+var operators = []string{"&&", "||", "==", "!=", "<=", ">=", "=~", "!~", "<", ">", "!"}
+
+type parser struct {
+	source string
+	tokens []token
+	next   int
+	fields []string
+}
+
+// tokenize splits the source into tokens.
+func (p *parser) tokenize() error {
+	s := p.source
+	for i := 0; i < len(s); {
+		c := rune(s[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '(' || c == ')':
+			kind := tokenLParen
+			if c == ')' {
+				kind = tokenRParen
+			}
+			p.tokens = append(p.tokens, token{kind: kind, text: string(c), pos: i})
+			i++
+		case c == '"' || c == '\'':
+			end, value, err := scanString(s, i)
+			if err != nil {
+				return err
+			}
+			p.tokens = append(p.tokens, token{kind: tokenString, text: value, pos: i})
+			i = end
+		case isDigit(s[i]) || (c == '-' && i+1 < len(s) && isDigit(s[i+1])):
+			end := i + 1
+			for end < len(s) && (isWordByte(s[end]) || s[end] == '.') {
+				end++
+			}
+			p.tokens = append(p.tokens, token{kind: tokenNumber, text: s[i:end], pos: i})
+			i = end
+		case isWordByte(s[i]):
+			end := i + 1
+			for end < len(s) && (isWordByte(s[end]) || s[end] == '.' || s[end] == '-') {
+				end++
+			}
+			p.tokens = append(p.tokens, token{kind: tokenField, text: s[i:end], pos: i})
+			i = end
+		default:
+			op := ""
+			for _, candidate := range operators {
+				if strings.HasPrefix(s[i:], candidate) {
+					op = candidate
+					break
+				}
+			}
+			if op == "" {
+				return fmt.Errorf("unexpected character %q at position %d", c, i+1)
+			}
+			p.tokens = append(p.tokens, token{kind: tokenOp, text: op, pos: i})
+			i += len(op)
+		}
+	}
+	p.tokens = append(p.tokens, token{kind: tokenEOF, pos: len(s)})
+	return nil
+}
+
+func isDigit(b byte) bool {
+	return '0' <= b && b <= '9'
+}
+
+func isWordByte(b byte) bool {
+	return b == '_' || isDigit(b) || ('a' <= b && b <= 'z') || ('A' <= b && b <= 'Z')
+}
+
+// scanString reads the quoted string starting at s[start], returning the
+// index after the closing quote and the unquoted value. Double-quoted
+// strings use Go escapes; single-quoted ones are raw (handy for regexes).
+func scanString(s string, start int) (end int, value string, err error) {
+	quote := s[start]
+	for end = start + 1; end < len(s); end++ {
+		switch {
+		case s[end] == '\\' && quote == '"':
+			end++
+		case s[end] == quote:
+			if quote == '\'' {
+				return end + 1, s[start+1 : end], nil
+			}
+			value, err := strconv.Unquote(s[start : end+1])
+			if err != nil {
+				return 0, "", fmt.Errorf("invalid string at position %d", start+1)
+			}
+			return end + 1, value, nil
+		}
+	}
+	return 0, "", fmt.Errorf("unterminated string at position %d", start+1)
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.next]
+}
+
+func (p *parser) advance() token {
+	t := p.tokens[p.next]
+	if t.kind != tokenEOF {
+		p.next++
+	}
+	return t
+}
+
+// acceptOp consumes the next token if it is the operator op.
+func (p *parser) acceptOp(op string) bool {
+	if t := p.peek(); t.kind == tokenOp && t.text == op {
+		p.next++
+		return true
+	}
+	return false
+}
+
+// parseOr parses: and ("||" and)*
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.acceptOp("||") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orNode{left: left, right: right}
+	}
+	return left, nil
+}
+
+// parseAnd parses: unary ("&&" unary)*
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.acceptOp("&&") {
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = andNode{left: left, right: right}
+	}
+	return left, nil
+}
+
+// parseUnary parses: "!" unary | "(" or ")" | comparison
+func (p *parser) parseUnary() (node, error) {
+	if p.acceptOp("!") {
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{operand: operand}, nil
+	}
+	if p.peek().kind == tokenLParen {
+		open := p.advance()
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.advance().kind != tokenRParen {
+			return nil, fmt.Errorf("missing ) for ( at position %d", open.pos+1)
+		}
+		return inner, nil
+	}
+	return p.parseComparison()
+}
+
+// parseComparison parses: operand [op operand]
+func (p *parser) parseComparison() (node, error) {
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+
+	t := p.peek()
+	if t.kind != tokenOp || t.text == "&&" || t.text == "||" || t.text == "!" {
+		return truthNode{operand: left}, nil
+	}
+	p.next++
+
+	if t.text == "=~" || t.text == "!~" {
+		pattern := p.advance()
+		if pattern.kind != tokenString {
+			return nil, fmt.Errorf("%s at position %d requires a quoted pattern, got %s", t.text, t.pos+1, pattern)
+		}
+		re, err := regexp.Compile(pattern.text)
+		if err != nil {
+			return nil, fmt.Errorf("bad regex at position %d: %w", pattern.pos+1, err)
+		}
+		return compareNode{op: t.text, left: left, pattern: re}, nil
+	}
+
+	right, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	return compareNode{op: t.text, left: left, right: right}, nil
+}
+
+// parseOperand parses a field, a literal or true/false.
+func (p *parser) parseOperand() (operand, error) {
+	t := p.advance()
+	switch t.kind {
+	case tokenString, tokenNumber:
+		return operand{literal: t.text}, nil
+	case tokenField:
+		if t.text == "true" || t.text == "false" {
+			return operand{literal: t.text}, nil
+		}
+		p.fields = append(p.fields, t.text)
+		return operand{field: t.text}, nil
+	default:
+		return operand{}, fmt.Errorf("expected a field or value at position %d, got %s", t.pos+1, t)
+	}
+}
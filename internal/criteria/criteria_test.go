@@ -0,0 +1,85 @@
+package criteria
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestExpr_Eval(t *testing.T) {
+	fields := map[string]string{
+		"status":                       "FAILED",
+		"exit_code":                    "3",
+		"error":                        "verify: certificate already registered",
+		"metadata.certname_preserved":  "true",
+		"phase_durations.install":      "4m12.5s",
+		"phase_durations.post-install": "",
+	}
+	resolve := func(field string) string { return fields[field] }
+
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{expr: `status == "FAILED"`, want: true},
+		{expr: `status != 'FAILED'`, want: false},
+		{expr: `exit_code == 3.0`, want: true},
+		{expr: `exit_code >= 4`, want: false},
+		{expr: `phase_durations.install < 300s`, want: true},
+		{expr: `phase_durations.install > 5m`, want: false},
+		{expr: `phase_durations.post-install < 300s`, want: false},
+		{expr: `metadata.certname_preserved`, want: true},
+		{expr: `!metadata.certname_preserved`, want: false},
+		{expr: `metadata.certname_preserved == "true" || phase_durations.install < 300s`, want: true},
+		{expr: `error =~ 'already\s+registered' && exit_code != 0`, want: true},
+		{expr: `error !~ "registered"`, want: false},
+		{expr: `status == "SUCCESS" || exit_code == 3 && metadata.certname_preserved`, want: true},
+		{expr: `(status == "SUCCESS" || exit_code == 3) && !metadata.certname_preserved`, want: false},
+		{expr: `missing == ""`, want: true},
+		{expr: `true && !false`, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			expr, err := Parse(tt.expr)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if got := expr.Eval(resolve); got != tt.want {
+				t.Errorf("Eval() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := []string{
+		``,
+		`status ==`,
+		`status == "FAILED`,
+		`(status == "FAILED"`,
+		`status == "FAILED")`,
+		`error =~ registered`,
+		`error =~ "("`,
+		`status = "FAILED"`,
+		`status == "FAILED" &&`,
+	}
+
+	for _, source := range tests {
+		t.Run(source, func(t *testing.T) {
+			if _, err := Parse(source); err == nil {
+				t.Errorf("Parse(%q) expected error", source)
+			}
+		})
+	}
+}
+
+func TestExpr_Fields(t *testing.T) {
+	expr, err := Parse(`status == "FAILED" && (metadata.os == 'rhel' || true) && exit_code > 1`)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	want := []string{"status", "metadata.os", "exit_code"}
+	if got := expr.Fields(); !slices.Equal(got, want) {
+		t.Errorf("Fields() = %v, want %v", got, want)
+	}
+}
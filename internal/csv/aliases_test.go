@@ -0,0 +1,59 @@
+package csv
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestParseStream_ColumnAliases tests third-party headers mapped to canonical columns
+func TestParseStream_ColumnAliases(t *testing.T) {
+	aliases := map[string][]string{
+		"instance_id": {"InstanceId", "ec2_id"},
+		"account":     {"AccountId"},
+		"region":      {"az_region"},
+	}
+
+	tests := []struct {
+		name        string
+		content     string
+		wantID      string
+		wantColumns string
+	}{
+		{
+			name:        "aliases are renamed",
+			content:     "InstanceId,AccountId,az_region,Team\ni-001,111111111111,us-east-1,core\n",
+			wantID:      "i-001",
+			wantColumns: "instance_id,account,region,team",
+		},
+		{
+			name:        "canonical column wins over alias",
+			content:     "ec2_id,instance_id,account,region\ni-alias,i-canonical,111111111111,us-east-1\n",
+			wantID:      "i-canonical",
+			wantColumns: "ec2_id,instance_id,account,region",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// ARRANGE
+			parser := NewParser(CSVConfig{HasHeader: true, ColumnAliases: aliases})
+
+			// ACT
+			var rows []Row
+			for row := range parser.ParseStream(strings.NewReader(tt.content)) {
+				rows = append(rows, row)
+			}
+
+			// ASSERT
+			if len(rows) != 1 || rows[0].Err != nil {
+				t.Fatalf("unexpected rows %+v", rows)
+			}
+			if rows[0].Instance.ID != tt.wantID {
+				t.Errorf("ID = %q, want %q", rows[0].Instance.ID, tt.wantID)
+			}
+			if got := strings.Join(parser.Columns(), ","); got != tt.wantColumns {
+				t.Errorf("Columns() = %q, want %q", got, tt.wantColumns)
+			}
+		})
+	}
+}
@@ -1,8 +1,12 @@
 package csv
 
 import (
+	"context"
 	"encoding/csv"
+	"errors"
 	"fmt"
+	"io"
+	"iter"
 	"os"
 	"strings"
 
@@ -12,7 +16,9 @@ import (
 // Parser parses CSV files containing instance information.
 // Supports flexible formats with/without headers and extra columns.
 type Parser struct {
-	config CSVConfig
+	config  CSVConfig
+	columns []string      // Normalized column names from the last parsed file
+	skipped []*ParseError // Rows skipped by the last ParseFile (SkipInvalidRows)
 }
 
 // NewParser creates a new CSV parser with given configuration.
@@ -48,92 +54,189 @@ func NewParser(config CSVConfig) *Parser {
 //
 // Returns ParseError with line/column information if validation fails.
 func (p *Parser) ParseFile(filePath string) ([]*cloud.Instance, error) {
-	// Open CSV file
-	file, err := os.Open(filePath)
+	return p.ParseSource(context.Background(), filePath, nil)
+}
+
+// ParseSource is like ParseFile but also accepts https:// URLs and, when
+// s3 is not nil, s3:// URLs. Gzip-compressed content is decompressed
+// transparently (see OpenSource).
+func (p *Parser) ParseSource(ctx context.Context, location string, s3 Opener) ([]*cloud.Instance, error) {
+	source, err := OpenSource(ctx, location, s3)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open CSV file: %w", err)
 	}
-	defer file.Close()
+	defer source.Close()
 
-	// Create CSV reader
-	reader := csv.NewReader(file)
-	reader.Comma = p.config.Delimiter
-	reader.TrimLeadingSpace = true
-	reader.FieldsPerRecord = -1 // Allow variable number of fields
-
-	// Read all records at once
-	records, err := reader.ReadAll()
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse CSV: %w", err)
+	// Collect rows, failing on the first error unless invalid rows are skipped
+	var instances []*cloud.Instance
+	p.skipped = nil
+	for row := range p.ParseStream(source) {
+		if row.Err != nil {
+			if row.Fatal || !p.config.SkipInvalidRows {
+				return nil, row.Err
+			}
+			p.skipped = append(p.skipped, asParseError(row))
+			continue
+		}
+		instances = append(instances, row.Instance)
 	}
 
-	// Check if CSV is empty
-	if len(records) == 0 {
+	// Check if we got any instances
+	if len(instances) == 0 {
 		return nil, &ParseError{
 			Line:    0,
-			Message: "CSV file is empty",
+			Message: "no valid instances found in CSV",
 		}
 	}
 
-	// Process header and determine column mapping
-	var headerMap map[string]int
-	startIndex := 0
+	return instances, nil
+}
 
-	if p.config.HasHeader {
-		// First row is header - build column mapping
-		headerMap = p.buildHeaderMap(records[0])
-		startIndex = 1
+// ParseStream parses CSV rows lazily, yielding one Row per data line, so
+// very large inventories never need to fit in memory.
+//
+// Row-level problems (missing fields, malformed quoting) are yielded as
+// Row.Err and parsing continues with the next line; the caller decides
+// whether to stop. Fatal problems (unreadable input, empty file, missing
+// required columns) are yielded once with Row.Fatal set, ending the stream.
+// Columns() is valid once the first row has been yielded.
+func (p *Parser) ParseStream(r io.Reader) iter.Seq[Row] {
+	return func(yield func(Row) bool) {
+		reader := csv.NewReader(r)
+		reader.Comma = p.config.Delimiter
+		reader.TrimLeadingSpace = true
+		reader.FieldsPerRecord = -1 // Allow variable number of fields
+		reader.ReuseRecord = true   // Records are copied into instances, never kept
+
+		var headerMap map[string]int
+		first := true
+
+		for {
+			record, err := reader.Read()
+			if errors.Is(err, io.EOF) {
+				if first {
+					yield(Row{Fatal: true, Err: &ParseError{Line: 0, Message: "CSV file is empty"}})
+				}
+				return
+			}
+			line, _ := reader.FieldPos(0)
+
+			var csvErr *csv.ParseError
+			if errors.As(err, &csvErr) {
+				if !yield(Row{Line: csvErr.Line, Err: &ParseError{Line: csvErr.Line, Message: "malformed CSV row: " + csvErr.Err.Error(), Err: err}}) {
+					return
+				}
+				continue
+			}
+			if err != nil {
+				yield(Row{Fatal: true, Err: fmt.Errorf("failed to parse CSV: %w", err)})
+				return
+			}
 
-		// Validate that all required fields exist in header
-		if err := p.validateHeaders(headerMap); err != nil {
-			return nil, err
-		}
-	} else {
-		// No header - use default column order
-		headerMap = p.buildDefaultHeaderMap()
-	}
+			// Process header (or default column order) on the first record
+			if first {
+				first = false
+				if p.config.HasHeader {
+					headerMap = p.buildHeaderMap(record)
+					p.columns = p.canonicalColumns(record)
+
+					// Validate that all required fields exist in header
+					if err := p.validateHeaders(headerMap); err != nil {
+						yield(Row{Line: line, Fatal: true, Err: err})
+						return
+					}
+					continue
+				}
+				headerMap = p.buildDefaultHeaderMap()
+				p.columns = []string{"instance_id", "account", "region", "cloud"}
+			}
 
-	// Parse data rows
-	var instances []*cloud.Instance
-	for i := startIndex; i < len(records); i++ {
-		lineNumber := i + 1 // Line numbers are 1-based for users
+			// Skip empty lines
+			if len(record) == 0 || (len(record) == 1 && strings.TrimSpace(record[0]) == "") {
+				continue
+			}
 
-		// Skip empty lines
-		if len(records[i]) == 0 || (len(records[i]) == 1 && strings.TrimSpace(records[i][0]) == "") {
-			continue
+			instance, err := p.parseRecord(record, headerMap, line)
+			if !yield(Row{Line: line, Instance: instance, Err: err}) {
+				return
+			}
 		}
+	}
+}
 
-		instance, err := p.parseRecord(records[i], headerMap, lineNumber)
-		if err != nil {
-			return nil, err
-		}
+// SkippedRows returns the rows skipped by the last ParseFile call
+// (only with SkipInvalidRows), in file order.
+func (p *Parser) SkippedRows() []*ParseError {
+	return p.skipped
+}
 
-		instances = append(instances, instance)
+// asParseError returns the row error as *ParseError, adding the line number.
+func asParseError(row Row) *ParseError {
+	var parseErr *ParseError
+	if errors.As(row.Err, &parseErr) {
+		return parseErr
 	}
+	return &ParseError{Line: row.Line, Message: row.Err.Error(), Err: row.Err}
+}
 
-	// Check if we got any instances
-	if len(instances) == 0 {
-		return nil, &ParseError{
-			Line:    0,
-			Message: "no valid instances found in CSV",
-		}
-	}
+// Columns returns the normalized column names of the last parsed file.
+// Used to validate --where selectors against the CSV header.
+func (p *Parser) Columns() []string {
+	return p.columns
+}
 
-	return instances, nil
+// normalizeColumns lowercases and trims header column names.
+func normalizeColumns(header []string) []string {
+	columns := make([]string, 0, len(header))
+	for _, col := range header {
+		columns = append(columns, strings.ToLower(strings.TrimSpace(col)))
+	}
+	return columns
 }
 
 // buildHeaderMap creates mapping from column names to their indices.
-// Column names are normalized (lowercase, trimmed) for case-insensitive matching.
-func (*Parser) buildHeaderMap(header []string) map[string]int {
-	headerMap := make(map[string]int)
-	for i, col := range header {
-		// Normalize: lowercase and trim spaces
-		normalized := strings.ToLower(strings.TrimSpace(col))
-		headerMap[normalized] = i
+// Column names are normalized (lowercase, trimmed) for case-insensitive matching,
+// and aliased headers are renamed to their canonical name (see ColumnAliases).
+func (p *Parser) buildHeaderMap(header []string) map[string]int {
+	columns := p.canonicalColumns(header)
+	headerMap := make(map[string]int, len(columns))
+	for i, col := range columns {
+		headerMap[col] = i
 	}
 	return headerMap
 }
 
+// canonicalColumns normalizes header names and resolves column aliases.
+func (p *Parser) canonicalColumns(header []string) []string {
+	columns := normalizeColumns(header)
+	if len(p.config.ColumnAliases) == 0 {
+		return columns
+	}
+
+	present := make(map[string]bool, len(columns))
+	for _, col := range columns {
+		present[col] = true
+	}
+
+	aliases := make(map[string]string)
+	for canonical, names := range p.config.ColumnAliases {
+		canonical = strings.ToLower(strings.TrimSpace(canonical))
+		for _, name := range names {
+			aliases[strings.ToLower(strings.TrimSpace(name))] = canonical
+		}
+	}
+
+	for i, col := range columns {
+		canonical, ok := aliases[col]
+		if !ok || present[canonical] {
+			continue // Not an alias, or the canonical column exists
+		}
+		columns[i] = canonical
+		present[canonical] = true
+	}
+	return columns
+}
+
 // buildDefaultHeaderMap creates default column mapping when no header exists.
 // Default order: instance_id, account, region, cloud (optional)
 func (*Parser) buildDefaultHeaderMap() map[string]int {
@@ -0,0 +1,207 @@
+package csv
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/estudosdevops/opsmaster/internal/cloud"
+)
+
+// Selector operators supported in --where expressions.
+// Two-character operators must be checked before their one-character prefixes.
+var selectorOperators = []string{"!=", ">=", "<=", "!~", "=", "~", ">", "<"}
+
+// Selector is a structured column filter parsed from a --where expression.
+//
+// Examples:
+//
+//	environment=blue        exact match
+//	environment!=green      not equal
+//	hostname~^web-[0-9]+$   regex match
+//	hostname!~canary        regex not match
+//	shard>=3                numeric comparison (>, >=, <, <=)
+type Selector struct {
+	Column   string // Normalized column name (lowercase)
+	Operator string // One of selectorOperators
+	Value    string // Raw comparison value
+
+	pattern *regexp.Regexp // Compiled regex for ~ and !~
+	number  float64        // Parsed number for numeric operators
+}
+
+// ParseSelector parses a "column<op>value" expression into a Selector.
+// Regex values are compiled and numeric values parsed up front, so invalid
+// expressions fail before any instance is touched.
+func ParseSelector(expr string) (*Selector, error) {
+	idx := strings.IndexAny(expr, "=!~<>")
+	if idx <= 0 {
+		return nil, fmt.Errorf("invalid selector %q: expected column<op>value (ops: %s)",
+			expr, strings.Join(selectorOperators, " "))
+	}
+
+	var operator string
+	for _, op := range selectorOperators {
+		if strings.HasPrefix(expr[idx:], op) {
+			operator = op
+			break
+		}
+	}
+	if operator == "" {
+		return nil, fmt.Errorf("invalid selector %q: unknown operator", expr)
+	}
+
+	selector := &Selector{
+		Column:   strings.ToLower(strings.TrimSpace(expr[:idx])),
+		Operator: operator,
+		Value:    strings.TrimSpace(expr[idx+len(operator):]),
+	}
+
+	switch operator {
+	case "~", "!~":
+		pattern, err := regexp.Compile(selector.Value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid selector %q: bad regex: %w", expr, err)
+		}
+		selector.pattern = pattern
+	case ">", ">=", "<", "<=":
+		number, err := strconv.ParseFloat(selector.Value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid selector %q: %s requires a numeric value", expr, operator)
+		}
+		selector.number = number
+	}
+
+	return selector, nil
+}
+
+// ParseSelectors parses multiple --where expressions.
+func ParseSelectors(exprs []string) ([]*Selector, error) {
+	selectors := make([]*Selector, 0, len(exprs))
+	for _, expr := range exprs {
+		selector, err := ParseSelector(expr)
+		if err != nil {
+			return nil, err
+		}
+		selectors = append(selectors, selector)
+	}
+	return selectors, nil
+}
+
+// String returns the normalized expression.
+func (s *Selector) String() string {
+	return s.Column + s.Operator + s.Value
+}
+
+// Match reports whether the instance satisfies the selector.
+// Numeric operators never match non-numeric values.
+func (s *Selector) Match(instance *cloud.Instance) bool {
+	value := columnValue(instance, s.Column)
+
+	switch s.Operator {
+	case "=":
+		return value == s.Value
+	case "!=":
+		return value != s.Value
+	case "~":
+		return s.pattern.MatchString(value)
+	case "!~":
+		return !s.pattern.MatchString(value)
+	}
+
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return false
+	}
+
+	switch s.Operator {
+	case ">":
+		return number > s.number
+	case ">=":
+		return number >= s.number
+	case "<":
+		return number < s.number
+	case "<=":
+		return number <= s.number
+	default:
+		return false
+	}
+}
+
+// columnValue returns the value of a CSV column for an instance.
+// Known columns map to Instance fields, extra columns to Metadata.
+func columnValue(instance *cloud.Instance, column string) string {
+	switch column {
+	case "instance_id":
+		return instance.ID
+	case "account":
+		return instance.Account
+	case "region":
+		return instance.Region
+	case "cloud":
+		return instance.Cloud
+	default:
+		return instance.Metadata[column]
+	}
+}
+
+// ValidateSelectorColumns checks that every selector references a column
+// present in the CSV header, so a typo never silently selects nothing.
+func ValidateSelectorColumns(selectors []*Selector, columns []string) error {
+	known := make(map[string]bool, len(columns))
+	for _, column := range columns {
+		known[column] = true
+	}
+
+	for _, selector := range selectors {
+		if !known[selector.Column] {
+			sorted := append([]string(nil), columns...)
+			sort.Strings(sorted)
+			return fmt.Errorf("selector %q references unknown column %q (available: %s)",
+				selector.String(), selector.Column, strings.Join(sorted, ", "))
+		}
+	}
+	return nil
+}
+
+// FilterInstances returns instances matching all selectors (AND semantics).
+func FilterInstances(instances []*cloud.Instance, selectors []*Selector) []*cloud.Instance {
+	if len(selectors) == 0 {
+		return instances
+	}
+
+	var filtered []*cloud.Instance
+	for _, instance := range instances {
+		matched := true
+		for _, selector := range selectors {
+			if !selector.Match(instance) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			filtered = append(filtered, instance)
+		}
+	}
+	return filtered
+}
+
+// SelectInstances parses --where expressions, validates them against the CSV
+// columns and returns the matching instances. No expressions = all instances.
+func SelectInstances(instances []*cloud.Instance, columns, exprs []string) ([]*cloud.Instance, error) {
+	if len(exprs) == 0 {
+		return instances, nil
+	}
+
+	selectors, err := ParseSelectors(exprs)
+	if err != nil {
+		return nil, err
+	}
+	if err := ValidateSelectorColumns(selectors, columns); err != nil {
+		return nil, err
+	}
+
+	return FilterInstances(instances, selectors), nil
+}
@@ -0,0 +1,121 @@
+package csv
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/estudosdevops/opsmaster/internal/cloud"
+)
+
+// TestParseSelector tests parsing of --where expressions
+func TestParseSelector(t *testing.T) {
+	tests := []struct {
+		name     string
+		expr     string
+		column   string
+		operator string
+		value    string
+		wantErr  bool
+	}{
+		{name: "equal", expr: "environment=blue", column: "environment", operator: "=", value: "blue"},
+		{name: "not equal", expr: "environment!=green", column: "environment", operator: "!=", value: "green"},
+		{name: "regex", expr: "hostname~^web-[0-9]+$", column: "hostname", operator: "~", value: "^web-[0-9]+$"},
+		{name: "regex not match", expr: "hostname!~canary", column: "hostname", operator: "!~", value: "canary"},
+		{name: "numeric greater or equal", expr: "shard>=3", column: "shard", operator: ">=", value: "3"},
+		{name: "numeric less", expr: "shard<10", column: "shard", operator: "<", value: "10"},
+		{name: "column normalized", expr: " Environment = blue ", column: "environment", operator: "=", value: "blue"},
+		{name: "value containing operator", expr: "tags=a=b", column: "tags", operator: "=", value: "a=b"},
+		{name: "missing column", expr: "=blue", wantErr: true},
+		{name: "missing operator", expr: "environment", wantErr: true},
+		{name: "unknown operator", expr: "environment!blue", wantErr: true},
+		{name: "invalid regex", expr: "hostname~[", wantErr: true},
+		{name: "non-numeric comparison", expr: "shard>abc", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			selector, err := ParseSelector(tt.expr)
+
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("ParseSelector(%q) expected error, got nil", tt.expr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseSelector(%q) unexpected error: %v", tt.expr, err)
+			}
+			if selector.Column != tt.column || selector.Operator != tt.operator || selector.Value != tt.value {
+				t.Errorf("ParseSelector(%q) = {%s %s %s}, want {%s %s %s}", tt.expr,
+					selector.Column, selector.Operator, selector.Value, tt.column, tt.operator, tt.value)
+			}
+		})
+	}
+}
+
+// TestSelectInstances tests filtering instances with --where selectors
+func TestSelectInstances(t *testing.T) {
+	// ARRANGE
+	instances := []*cloud.Instance{
+		{ID: "i-1", Region: "us-east-1", Metadata: map[string]string{"color": "blue", "shard": "1", "hostname": "web-01"}},
+		{ID: "i-2", Region: "us-east-1", Metadata: map[string]string{"color": "green", "shard": "2", "hostname": "web-02"}},
+		{ID: "i-3", Region: "sa-east-1", Metadata: map[string]string{"color": "blue", "shard": "3", "hostname": "db-01"}},
+		{ID: "i-4", Region: "sa-east-1", Metadata: map[string]string{"color": "blue", "hostname": "web-03"}},
+	}
+	columns := []string{"instance_id", "account", "region", "color", "shard", "hostname"}
+
+	tests := []struct {
+		name    string
+		exprs   []string
+		wantIDs []string
+		wantErr string
+	}{
+		{name: "no selectors", exprs: nil, wantIDs: []string{"i-1", "i-2", "i-3", "i-4"}},
+		{name: "single equal", exprs: []string{"color=blue"}, wantIDs: []string{"i-1", "i-3", "i-4"}},
+		{name: "AND semantics", exprs: []string{"color=blue", "region=sa-east-1"}, wantIDs: []string{"i-3", "i-4"}},
+		{name: "numeric skips empty values", exprs: []string{"shard>=2"}, wantIDs: []string{"i-2", "i-3"}},
+		{name: "regex", exprs: []string{"hostname~^web-"}, wantIDs: []string{"i-1", "i-2", "i-4"}},
+		{name: "unknown column", exprs: []string{"colour=blue"}, wantErr: "unknown column"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// ACT
+			selected, err := SelectInstances(instances, columns, tt.exprs)
+
+			// ASSERT
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var ids []string
+			for _, instance := range selected {
+				ids = append(ids, instance.ID)
+			}
+			if strings.Join(ids, ",") != strings.Join(tt.wantIDs, ",") {
+				t.Errorf("selected %v, want %v", ids, tt.wantIDs)
+			}
+		})
+	}
+}
+
+// TestParser_Columns tests that parsed header columns are exposed for selector validation
+func TestParser_Columns(t *testing.T) {
+	parser := NewParser(DefaultCSVConfig())
+
+	_, err := parser.ParseString("Instance_ID,Account,Region,Color\ni-1,111111111111,us-east-1,blue\n")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got := strings.Join(parser.Columns(), ",")
+	if got != "instance_id,account,region,color" {
+		t.Errorf("Columns() = %s, want instance_id,account,region,color", got)
+	}
+}
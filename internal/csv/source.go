@@ -0,0 +1,109 @@
+package csv
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/estudosdevops/opsmaster/internal/httpclient"
+)
+
+// Opener opens a remote instances file the csv package can't reach by
+// itself, e.g. s3:// URLs that need cloud credentials.
+type Opener func(ctx context.Context, location string) (io.ReadCloser, error)
+
+// gzipMagic are the first bytes of every gzip stream.
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// OpenSource opens an instances file from a local path, an https:// URL or,
+// through s3, an s3:// URL. Gzip content is detected by its magic bytes and
+// decompressed, whatever the file name. The caller must close the result.
+func OpenSource(ctx context.Context, location string, s3 Opener) (io.ReadCloser, error) {
+	var (
+		raw io.ReadCloser
+		err error
+	)
+
+	switch {
+	case strings.HasPrefix(location, "s3://"):
+		if s3 == nil {
+			return nil, fmt.Errorf("s3 URLs are not supported here: %s", location)
+		}
+		raw, err = s3(ctx, location)
+	case strings.HasPrefix(location, "https://"):
+		raw, err = openHTTPS(ctx, location)
+	case strings.HasPrefix(location, "http://"):
+		return nil, fmt.Errorf("insecure URL %s: use https://", location)
+	default:
+		raw, err = os.Open(location)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return maybeGunzip(raw)
+}
+
+// openHTTPS downloads a file with the shared HTTP client.
+func openHTTPS(ctx context.Context, location string) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, location, http.NoBody)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL %s: %w", location, err)
+	}
+
+	resp, err := httpclient.Shared().Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", location, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("failed to download %s: HTTP %d", location, resp.StatusCode)
+	}
+	return resp.Body, nil
+}
+
+// gzipReadCloser closes both the decompressor and the underlying source.
+type gzipReadCloser struct {
+	*gzip.Reader
+	source io.Closer
+}
+
+func (g *gzipReadCloser) Close() error {
+	gzErr := g.Reader.Close()
+	if err := g.source.Close(); err != nil {
+		return err
+	}
+	return gzErr
+}
+
+// bufferedReadCloser keeps the peeked bytes while closing the source.
+type bufferedReadCloser struct {
+	*bufio.Reader
+	source io.Closer
+}
+
+func (b *bufferedReadCloser) Close() error {
+	return b.source.Close()
+}
+
+// maybeGunzip wraps raw in a gzip reader when it starts with the gzip magic.
+func maybeGunzip(raw io.ReadCloser) (io.ReadCloser, error) {
+	buffered := bufio.NewReader(raw)
+	magic, err := buffered.Peek(len(gzipMagic))
+	if err != nil || magic[0] != gzipMagic[0] || magic[1] != gzipMagic[1] {
+		// Short or empty files are left for the parser to report
+		return &bufferedReadCloser{Reader: buffered, source: raw}, nil
+	}
+
+	gz, err := gzip.NewReader(buffered)
+	if err != nil {
+		raw.Close()
+		return nil, fmt.Errorf("invalid gzip content: %w", err)
+	}
+	return &gzipReadCloser{Reader: gz, source: raw}, nil
+}
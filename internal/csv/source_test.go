@@ -0,0 +1,118 @@
+package csv
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/pem"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/estudosdevops/opsmaster/internal/httpclient"
+)
+
+const sourceCSV = "instance_id,account,region\ni-001,111111111111,us-east-1\ni-002,111111111111,us-east-1\n"
+
+// gzipped compresses content for tests.
+func gzipped(t *testing.T, content string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	if _, err := gz.Write([]byte(content)); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// trustTestServer makes the shared HTTP client trust the test server's
+// self-signed certificate, restoring the default client afterwards.
+func trustTestServer(t *testing.T, server *httptest.Server) {
+	t.Helper()
+	bundle := filepath.Join(t.TempDir(), "ca.pem")
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(bundle, certPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := httpclient.Configure(httpclient.Config{CABundle: bundle}); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { httpclient.Configure(httpclient.Config{}) })
+}
+
+// TestParseSource tests local, gzipped, https and s3 sources
+func TestParseSource(t *testing.T) {
+	dir := t.TempDir()
+	plain := filepath.Join(dir, "fleet.csv")
+	compressed := filepath.Join(dir, "fleet.csv.gz")
+	if err := os.WriteFile(plain, []byte(sourceCSV), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(compressed, gzipped(t, sourceCSV), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing.csv" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(gzipped(t, sourceCSV))
+	}))
+	defer server.Close()
+	trustTestServer(t, server)
+
+	s3 := func(_ context.Context, location string) (io.ReadCloser, error) {
+		if location != "s3://inventory/fleet.csv.gz" {
+			return nil, errors.New("unexpected location " + location)
+		}
+		return io.NopCloser(bytes.NewReader(gzipped(t, sourceCSV))), nil
+	}
+
+	tests := []struct {
+		name     string
+		location string
+		s3       Opener
+		wantErr  string
+	}{
+		{name: "local file", location: plain},
+		{name: "local gzip file", location: compressed},
+		{name: "https gzip", location: server.URL + "/fleet.csv.gz"},
+		{name: "s3 with opener", location: "s3://inventory/fleet.csv.gz", s3: s3},
+		{name: "s3 without opener", location: "s3://inventory/fleet.csv.gz", wantErr: "not supported"},
+		{name: "plain http rejected", location: "http://example.com/fleet.csv", wantErr: "use https://"},
+		{name: "https not found", location: server.URL + "/missing.csv", wantErr: "HTTP 404"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// ARRANGE
+			parser := NewParser(CSVConfig{HasHeader: true})
+
+			// ACT
+			instances, err := parser.ParseSource(context.Background(), tt.location, tt.s3)
+
+			// ASSERT
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(instances) != 2 || instances[1].ID != "i-002" {
+				t.Errorf("unexpected instances %+v", instances)
+			}
+		})
+	}
+}
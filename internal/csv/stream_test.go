@@ -0,0 +1,159 @@
+package csv
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// ============================================================
+// STREAM TESTS
+// ============================================================
+
+// TestParseStream_RowErrors tests that row-level errors carry line numbers
+// and don't stop the stream.
+//
+// 🎓 CONCEPT: Iterators (Go 1.23+)
+// ParseStream returns iter.Seq[Row]; the caller consumes it with range and
+// can stop early with break - rows after that are never read.
+func TestParseStream_RowErrors(t *testing.T) {
+	// ARRANGE
+	content := `instance_id,account,region,environment
+i-001,111111111111,us-east-1,prod
+
+i-002,,us-east-1,prod
+i-003,111111111111,us-east-1,"unterminated
+`
+	content += "i-004,111111111111,sa-east-1,dev\n"
+	parser := NewParser(CSVConfig{HasHeader: true})
+
+	// ACT
+	var ids []string
+	var errLines []int
+	for row := range parser.ParseStream(strings.NewReader(content)) {
+		if row.Err != nil {
+			if row.Fatal {
+				t.Fatalf("unexpected fatal error: %v", row.Err)
+			}
+			errLines = append(errLines, row.Line)
+			continue
+		}
+		ids = append(ids, row.Instance.ID)
+	}
+
+	// ASSERT
+	if strings.Join(ids, ",") != "i-001" {
+		t.Errorf("instances = %v, want [i-001] (malformed quote swallows the rest)", ids)
+	}
+	if len(errLines) != 2 || errLines[0] != 4 {
+		t.Errorf("error lines = %v, want [4 5]", errLines)
+	}
+}
+
+// TestParseStream_ValidRows tests line numbers and metadata of streamed rows
+func TestParseStream_ValidRows(t *testing.T) {
+	content := "instance_id,account,region,environment\ni-001,111111111111,us-east-1,prod\n\ni-002,222222222222,sa-east-1,dev\n"
+	parser := NewParser(CSVConfig{HasHeader: true})
+
+	var rows []Row
+	for row := range parser.ParseStream(strings.NewReader(content)) {
+		rows = append(rows, row)
+	}
+
+	if len(rows) != 2 {
+		t.Fatalf("got %d rows, want 2", len(rows))
+	}
+	if rows[1].Line != 4 || rows[1].Instance.Metadata["environment"] != "dev" {
+		t.Errorf("unexpected second row %+v", rows[1])
+	}
+	if len(parser.Columns()) != 4 {
+		t.Errorf("Columns() = %v, want 4 columns", parser.Columns())
+	}
+}
+
+// TestParseStream_Fatal tests errors that end the stream
+func TestParseStream_Fatal(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantMsg string
+	}{
+		{name: "empty input", content: "", wantMsg: "CSV file is empty"},
+		{name: "missing required column", content: "instance_id,region\ni-001,us-east-1\n", wantMsg: "missing required columns: account"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var rows []Row
+			for row := range NewParser(CSVConfig{HasHeader: true}).ParseStream(strings.NewReader(tt.content)) {
+				rows = append(rows, row)
+			}
+
+			if len(rows) != 1 || !rows[0].Fatal || !strings.Contains(rows[0].Err.Error(), tt.wantMsg) {
+				t.Errorf("expected single fatal row with %q, got %+v", tt.wantMsg, rows)
+			}
+		})
+	}
+}
+
+// TestParseStream_EarlyStop tests that breaking out of the loop stops reading
+func TestParseStream_EarlyStop(t *testing.T) {
+	var content strings.Builder
+	content.WriteString("instance_id,account,region\n")
+	for range 1000 {
+		content.WriteString("i-001,111111111111,us-east-1\n")
+	}
+
+	count := 0
+	for range NewParser(CSVConfig{HasHeader: true}).ParseStream(strings.NewReader(content.String())) {
+		count++
+		if count == 3 {
+			break
+		}
+	}
+
+	if count != 3 {
+		t.Errorf("count = %d, want 3", count)
+	}
+}
+
+// TestParseError_Error tests that line numbers are rendered as digits
+func TestParseError_Error(t *testing.T) {
+	err := error(&ParseError{Line: 42, Column: "account", Message: "account is required and cannot be empty"})
+
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) || err.Error() != "line 42, column 'account': account is required and cannot be empty" {
+		t.Errorf("Error() = %q", err.Error())
+	}
+}
+
+// TestParseFile_SkipInvalidRows tests tolerant parsing of files with bad rows
+func TestParseFile_SkipInvalidRows(t *testing.T) {
+	content := "instance_id,account,region\ni-001,111111111111,us-east-1\ni-002,,us-east-1\ni-003,333333333333,\ni-004,444444444444,sa-east-1\n"
+	filePath, cleanup := createTempCSVFile(t, content)
+	defer cleanup()
+
+	t.Run("strict mode fails on first bad row", func(t *testing.T) {
+		_, err := NewParser(CSVConfig{HasHeader: true}).ParseFile(filePath)
+		if err == nil || !strings.Contains(err.Error(), "line 3") {
+			t.Errorf("expected line 3 error, got %v", err)
+		}
+	})
+
+	t.Run("skip mode keeps valid rows and reports skipped ones", func(t *testing.T) {
+		parser := NewParser(CSVConfig{HasHeader: true, SkipInvalidRows: true})
+
+		instances, err := parser.ParseFile(filePath)
+
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(instances) != 2 {
+			t.Errorf("got %d instances, want 2", len(instances))
+		}
+		skipped := parser.SkippedRows()
+		if len(skipped) != 2 || skipped[0].Line != 3 || skipped[1].Line != 4 || skipped[1].Column != "region" {
+			t.Errorf("unexpected skipped rows %+v", skipped)
+		}
+	})
+}
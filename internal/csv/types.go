@@ -1,6 +1,8 @@
 package csv
 
 import (
+	"strconv"
+
 	"github.com/estudosdevops/opsmaster/internal/cloud"
 )
 
@@ -56,6 +58,16 @@ type CSVConfig struct {
 	// CloudDefault default value for cloud if column doesn't exist
 	// Useful for legacy CSVs that didn't have cloud column
 	CloudDefault string
+
+	// SkipInvalidRows makes ParseFile skip rows with errors instead of
+	// failing the whole file. Skipped rows are available via SkippedRows().
+	// Header and I/O errors still fail.
+	SkipInvalidRows bool
+
+	// ColumnAliases maps canonical column names to alternative headers used
+	// by other tools, e.g. {"instance_id": ["InstanceId", "ec2_id"]}.
+	// Matching is case-insensitive; a canonical header always wins over aliases.
+	ColumnAliases map[string][]string
 }
 
 // DefaultCSVConfig returns default configuration.
@@ -69,6 +81,14 @@ func DefaultCSVConfig() CSVConfig {
 	}
 }
 
+// Row is one item of Parser.ParseStream: a parsed instance or an error.
+type Row struct {
+	Line     int             // 1-based line number in the file
+	Instance *cloud.Instance // Parsed instance (nil when Err is set)
+	Err      error           // Row-level error (*ParseError) or fatal error
+	Fatal    bool            // Err ends the stream (unreadable input, bad header)
+}
+
 // ParseError represents error during CSV parsing
 type ParseError struct {
 	Line    int    // Line number where error occurred
@@ -80,9 +100,9 @@ type ParseError struct {
 // Error implements error interface
 func (pe *ParseError) Error() string {
 	if pe.Column != "" {
-		return "line " + string(rune(pe.Line)) + ", column '" + pe.Column + "': " + pe.Message
+		return "line " + strconv.Itoa(pe.Line) + ", column '" + pe.Column + "': " + pe.Message
 	}
-	return "line " + string(rune(pe.Line)) + ": " + pe.Message
+	return "line " + strconv.Itoa(pe.Line) + ": " + pe.Message
 }
 
 // Unwrap allows using errors.Is() and errors.As()
@@ -0,0 +1,36 @@
+package csv
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+
+	"github.com/estudosdevops/opsmaster/internal/cloud"
+)
+
+// inventoryColumns are the fixed columns written by WriteInstances.
+var inventoryColumns = []string{"instance_id", "account", "region", "cloud"}
+
+// WriteInstances writes instances as an inventory CSV readable by
+// Parser (--instances-file): the fixed columns followed by the metadata
+// columns, in order. Missing metadata is written empty.
+func WriteInstances(w io.Writer, instances []*cloud.Instance, columns []string) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(append(append([]string{}, inventoryColumns...), columns...)); err != nil {
+		return fmt.Errorf("failed to write CSV header: %w", err)
+	}
+
+	record := make([]string, len(inventoryColumns)+len(columns))
+	for _, instance := range instances {
+		record[0], record[1], record[2], record[3] = instance.ID, instance.Account, instance.Region, instance.Cloud
+		for i, column := range columns {
+			record[len(inventoryColumns)+i] = instance.Metadata[column]
+		}
+		if err := writer.Write(record); err != nil {
+			return fmt.Errorf("failed to write CSV row: %w", err)
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,38 @@
+package csv
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/estudosdevops/opsmaster/internal/cloud"
+)
+
+// TestWriteInstances tests that a written inventory parses back.
+func TestWriteInstances(t *testing.T) {
+	instances := []*cloud.Instance{
+		{ID: "i-1", Cloud: "aws", Account: "111111111111", Region: "us-east-1", Metadata: map[string]string{"name": "web, primary", "team": "payments"}},
+		{ID: "i-2", Cloud: "aws", Account: "222222222222", Region: "eu-west-1", Metadata: map[string]string{}},
+	}
+
+	var out strings.Builder
+	if err := WriteInstances(&out, instances, []string{"name", "team"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if header, _, _ := strings.Cut(out.String(), "\n"); header != "instance_id,account,region,cloud,name,team" {
+		t.Errorf("header = %q", header)
+	}
+
+	parsed, err := NewParser(DefaultCSVConfig()).ParseString(out.String())
+	if err != nil {
+		t.Fatalf("ParseString() error: %v", err)
+	}
+	if len(parsed) != 2 {
+		t.Fatalf("parsed %d instances, want 2", len(parsed))
+	}
+	if got := parsed[0]; got.ID != "i-1" || got.Account != "111111111111" || got.Metadata["name"] != "web, primary" || got.Metadata["team"] != "payments" {
+		t.Errorf("first instance = %+v", got)
+	}
+	if got := parsed[1]; got.Region != "eu-west-1" || got.Metadata["team"] != "" {
+		t.Errorf("second instance = %+v", got)
+	}
+}
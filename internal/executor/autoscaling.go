@@ -0,0 +1,128 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"maps"
+
+	"github.com/estudosdevops/opsmaster/internal/cloud"
+)
+
+// Autoscaling group checks of the pre-flight (ExecutorConfig.AutoScaling).
+const (
+	AutoScalingOff     = "off"     // No check
+	AutoScalingSkip    = "skip"    // Skip members the group is terminating or replacing
+	AutoScalingReplace = "replace" // Skip them and process their replacement instead
+)
+
+// ValidateAutoScaling checks an autoscaling mode ("" = off).
+func ValidateAutoScaling(mode string) error {
+	switch mode {
+	case "", AutoScalingOff, AutoScalingSkip, AutoScalingReplace:
+		return nil
+	default:
+		return fmt.Errorf("invalid autoscaling mode %q (valid: %s, %s, %s)", mode, AutoScalingOff, AutoScalingSkip, AutoScalingReplace)
+	}
+}
+
+// checkAutoScaling skips instances whose autoscaling group is taking them
+// out of service (terminating, detaching or unhealthy), which would fail or
+// vanish mid-run. With AutoScalingReplace, each one is swapped for a member
+// of the same group that is in service (or pending) and not targeted yet.
+// Replacements keep the CSV columns of the instance they replace: members of
+// a group share the launch template.
+//
+// Requires the autoscaling capability (see cloud.CapabilitiesOf); otherwise,
+// or when the API fails, all instances are kept.
+func (pe *ParallelExecutor) checkAutoScaling(ctx context.Context, instances []*cloud.Instance) ([]*cloud.Instance, []*ExecutionResult) {
+	if pe.autoScaling == "" || pe.autoScaling == AutoScalingOff || len(instances) == 0 {
+		return instances, nil
+	}
+	describer := pe.providerCaps.AutoScaling
+	if describer == nil {
+		pe.log.Info("Autoscaling check skipped", "reason", cloud.Unsupported(pe.provider, "autoscaling groups"))
+		return instances, nil
+	}
+
+	members, err := describer.DescribeAutoScaling(ctx, instances)
+	if err != nil {
+		pe.log.Warn("Autoscaling check failed, processing all instances", "error", err)
+		return instances, nil
+	}
+
+	targeted := make(map[string]bool, len(instances))
+	for _, instance := range instances {
+		targeted[instance.ID] = true
+	}
+	groups := make(map[string][]*cloud.AutoScalingMember) // Group members, listed once per group
+
+	var kept []*cloud.Instance
+	var results []*ExecutionResult
+	replaced := 0
+	for _, instance := range instances {
+		member := members[instance.ID]
+		if member == nil || member.LeavingReason() == "" {
+			kept = append(kept, instance)
+			continue
+		}
+		reason := member.LeavingReason()
+		if pe.autoScaling != AutoScalingReplace {
+			results = append(results, skippedResult(instance, reason))
+			continue
+		}
+
+		if _, listed := groups[member.Group]; !listed {
+			groupMembers, err := describer.GroupMembers(ctx, instance, member.Group)
+			if err != nil {
+				pe.log.Warn("Failed to list autoscaling group", "group", member.Group, "error", err)
+			}
+			groups[member.Group] = groupMembers
+		}
+		replacement := pickReplacement(groups[member.Group], targeted)
+		if replacement == "" {
+			results = append(results, skippedResult(instance, reason+" (no replacement found)"))
+			continue
+		}
+
+		targeted[replacement] = true
+		replaced++
+		kept = append(kept, &cloud.Instance{
+			ID:       replacement,
+			Cloud:    instance.Cloud,
+			Account:  instance.Account,
+			Region:   instance.Region,
+			Metadata: maps.Clone(instance.Metadata),
+		})
+		results = append(results, skippedResult(instance, reason+", replaced by "+replacement))
+		pe.log.Info("Targeting autoscaling replacement",
+			"instance_id", instance.ID,
+			"replacement", replacement,
+			"group", member.Group)
+	}
+
+	if len(results) > 0 {
+		pe.log.Warn("Autoscaling check completed",
+			"kept", len(kept),
+			"skipped", len(results),
+			"replaced", replaced)
+	}
+	return kept, results
+}
+
+// pickReplacement returns a serving member not targeted yet, preferring
+// members already in service over pending ones ("" = none).
+func pickReplacement(members []*cloud.AutoScalingMember, targeted map[string]bool) string {
+	pending := ""
+	for _, member := range members {
+		if targeted[member.InstanceID] || !member.Serving() {
+			continue
+		}
+		if member.LifecycleState == cloud.LifecycleStateInService {
+			return member.InstanceID
+		}
+		if pending == "" {
+			pending = member.InstanceID
+		}
+	}
+	return pending
+}
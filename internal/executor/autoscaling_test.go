@@ -0,0 +1,106 @@
+package executor
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/estudosdevops/opsmaster/internal/cloud"
+	"github.com/estudosdevops/opsmaster/internal/cloud/cloudtest"
+)
+
+// mockASGProvider extends cloudtest.Provider with the optional
+// AutoScalingDescriber capability.
+type mockASGProvider struct {
+	cloudtest.Provider
+	members map[string]*cloud.AutoScalingMember   // instance ID -> membership
+	groups  map[string][]*cloud.AutoScalingMember // group -> members
+}
+
+func (m *mockASGProvider) DescribeAutoScaling(_ context.Context, instances []*cloud.Instance) (map[string]*cloud.AutoScalingMember, error) {
+	result := make(map[string]*cloud.AutoScalingMember)
+	for _, instance := range instances {
+		if member, ok := m.members[instance.ID]; ok {
+			result[instance.ID] = member
+		}
+	}
+	return result, nil
+}
+
+func (m *mockASGProvider) GroupMembers(_ context.Context, _ *cloud.Instance, group string) ([]*cloud.AutoScalingMember, error) {
+	return m.groups[group], nil
+}
+
+func TestExecute_AutoScaling(t *testing.T) {
+	terminating := &cloud.AutoScalingMember{InstanceID: "i-test000", Group: "web", LifecycleState: "Terminating:Wait", HealthStatus: "HEALTHY"}
+	unhealthy := &cloud.AutoScalingMember{InstanceID: "i-test001", Group: "web", LifecycleState: "InService", HealthStatus: "UNHEALTHY"}
+	healthy := &cloud.AutoScalingMember{InstanceID: "i-test002", Group: "web", LifecycleState: "InService", HealthStatus: "HEALTHY"}
+	newMember := &cloud.AutoScalingMember{InstanceID: "i-new", Group: "web", LifecycleState: "Pending", HealthStatus: "Healthy"}
+
+	tests := []struct {
+		name        string
+		mode        string
+		wantIDs     []string          // Processed instances, in order
+		wantSkipped map[string]string // Skipped instance -> reason fragment
+	}{
+		{name: "off", mode: AutoScalingOff, wantIDs: []string{"i-test000", "i-test001", "i-test002"}},
+		{
+			name:        "skip",
+			mode:        AutoScalingSkip,
+			wantIDs:     []string{"i-test002"},
+			wantSkipped: map[string]string{"i-test000": "Terminating:Wait", "i-test001": "unhealthy"},
+		},
+		{
+			name:        "replace once per serving member",
+			mode:        AutoScalingReplace,
+			wantIDs:     []string{"i-new", "i-test002"},
+			wantSkipped: map[string]string{"i-test000": "replaced by i-new", "i-test001": "no replacement found"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// ARRANGE
+			provider := &mockASGProvider{
+				members: map[string]*cloud.AutoScalingMember{"i-test000": terminating, "i-test001": unhealthy, "i-test002": healthy},
+				groups:  map[string][]*cloud.AutoScalingMember{"web": {terminating, unhealthy, healthy, newMember}},
+			}
+			executor := NewParallelExecutor(ExecutorConfig{
+				Provider:    provider,
+				Installer:   &mockPackageInstaller{},
+				AutoScaling: tt.mode,
+			})
+
+			// ACT
+			kept, results := executor.checkAutoScaling(context.Background(), createTestInstances(3))
+
+			// ASSERT
+			var ids []string
+			for _, instance := range kept {
+				ids = append(ids, instance.ID)
+			}
+			if strings.Join(ids, ",") != strings.Join(tt.wantIDs, ",") {
+				t.Errorf("processed %v, want %v", ids, tt.wantIDs)
+			}
+			if len(results) != len(tt.wantSkipped) {
+				t.Fatalf("got %d skipped results, want %d", len(results), len(tt.wantSkipped))
+			}
+			for _, result := range results {
+				if result.Status != StatusSkipped || !strings.Contains(result.SkipReason, tt.wantSkipped[result.Instance.ID]) {
+					t.Errorf("unexpected result of %s: %s %q", result.Instance.ID, result.Status, result.SkipReason)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateAutoScaling(t *testing.T) {
+	for _, mode := range []string{"", AutoScalingOff, AutoScalingSkip, AutoScalingReplace} {
+		if err := ValidateAutoScaling(mode); err != nil {
+			t.Errorf("ValidateAutoScaling(%q) = %v, want nil", mode, err)
+		}
+	}
+	if err := ValidateAutoScaling("wait"); err == nil {
+		t.Error("ValidateAutoScaling(\"wait\") = nil, want error")
+	}
+}
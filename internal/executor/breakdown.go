@@ -0,0 +1,98 @@
+package executor
+
+import (
+	"sort"
+	"time"
+
+	"github.com/estudosdevops/opsmaster/internal/cloud"
+)
+
+// GroupSummary aggregates the results of one account or region.
+type GroupSummary struct {
+	Key         string        // Account ID or region
+	Total       int           // Instances in the group
+	Success     int           // Successful executions
+	Failed      int           // Failed executions
+	Skipped     int           // Skipped executions
+	Canceled    int           // Canceled executions
+	Disappeared int           // Instances gone mid-run
+	AvgDuration time.Duration // Average duration of processed (not skipped) instances
+	MaxDuration time.Duration // Slowest processed instance
+}
+
+// FailureRate returns the failure rate of the group in percentage.
+func (gs *GroupSummary) FailureRate() float64 {
+	if gs.Total == 0 {
+		return 0.0
+	}
+	return float64(gs.Failed) / float64(gs.Total) * percentageMultiplier
+}
+
+// AccountBreakdown summarizes results per account.
+// Rollouts often fail systematically in one account (e.g., missing IAM
+// permissions); groups are sorted by failures (most first), then key.
+func (ar *AggregatedResult) AccountBreakdown() []GroupSummary {
+	return ar.breakdown(func(instance *cloud.Instance) string { return instance.Account })
+}
+
+// RegionBreakdown summarizes results per region, sorted like AccountBreakdown.
+// Surfaces regional problems such as an unreachable package mirror.
+func (ar *AggregatedResult) RegionBreakdown() []GroupSummary {
+	return ar.breakdown(func(instance *cloud.Instance) string { return instance.Region })
+}
+
+// breakdown groups results by the key of their instance.
+func (ar *AggregatedResult) breakdown(keyOf func(*cloud.Instance) string) []GroupSummary {
+	index := make(map[string]int)
+	var groups []GroupSummary
+	processed := make(map[string]int)
+	totalDuration := make(map[string]time.Duration)
+
+	for _, result := range ar.Results {
+		if result.Instance == nil {
+			continue
+		}
+		key := keyOf(result.Instance)
+		i, exists := index[key]
+		if !exists {
+			i = len(groups)
+			index[key] = i
+			groups = append(groups, GroupSummary{Key: key})
+		}
+
+		group := &groups[i]
+		group.Total++
+		switch result.Status {
+		case StatusSuccess:
+			group.Success++
+		case StatusFailed:
+			group.Failed++
+		case StatusSkipped:
+			group.Skipped++
+			continue
+		case StatusCancelled:
+			group.Canceled++
+		case StatusDisappeared:
+			group.Disappeared++
+		}
+
+		processed[key]++
+		totalDuration[key] += result.Duration
+		group.MaxDuration = max(group.MaxDuration, result.Duration)
+	}
+
+	for i := range groups {
+		if n := processed[groups[i].Key]; n > 0 {
+			groups[i].AvgDuration = totalDuration[groups[i].Key] / time.Duration(n)
+		}
+	}
+
+	sort.SliceStable(groups, func(a, b int) bool {
+		if groups[a].Failed != groups[b].Failed {
+			return groups[a].Failed > groups[b].Failed
+		}
+		return groups[a].Key < groups[b].Key
+	})
+
+	return groups
+}
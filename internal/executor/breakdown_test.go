@@ -0,0 +1,49 @@
+package executor
+
+import (
+	"testing"
+	"time"
+)
+
+// TestAggregatedResult_Breakdown tests per-account and per-region summaries
+//
+// 🎓 CONCEPT: Systematic failures
+// A bad IAM role fails every instance of one account; a broken mirror fails
+// one region. Sorting groups by failures puts the culprit on the first row.
+func TestAggregatedResult_Breakdown(t *testing.T) {
+	// ARRANGE
+	result := NewAggregatedResult()
+	add := func(id, account, region string, status ExecutionStatus, duration time.Duration) {
+		r := createTestExecutionResult(status, id)
+		r.Instance.Account, r.Instance.Region, r.Duration = account, region, duration
+		result.Add(r)
+	}
+	add("i-1", "111", "us-east-1", StatusSuccess, 10*time.Second)
+	add("i-2", "111", "sa-east-1", StatusSuccess, 30*time.Second)
+	add("i-3", "222", "us-east-1", StatusFailed, 20*time.Second)
+	add("i-4", "222", "us-east-1", StatusFailed, 40*time.Second)
+	add("i-5", "222", "sa-east-1", StatusSkipped, 0)
+
+	// ACT
+	accounts := result.AccountBreakdown()
+	regions := result.RegionBreakdown()
+
+	// ASSERT
+	if len(accounts) != 2 || accounts[0].Key != "222" || accounts[1].Key != "111" {
+		t.Fatalf("unexpected account order %+v", accounts)
+	}
+	bad := accounts[0]
+	if bad.Total != 3 || bad.Failed != 2 || bad.Skipped != 1 || bad.Success != 0 {
+		t.Errorf("unexpected counts %+v", bad)
+	}
+	if bad.AvgDuration != 30*time.Second || bad.MaxDuration != 40*time.Second {
+		t.Errorf("durations avg=%s max=%s, want 30s/40s (skipped excluded)", bad.AvgDuration, bad.MaxDuration)
+	}
+	if rate := bad.FailureRate(); rate < 66 || rate > 67 {
+		t.Errorf("FailureRate() = %.1f, want ~66.7", rate)
+	}
+
+	if len(regions) != 2 || regions[0].Key != "us-east-1" || regions[0].Failed != 2 || regions[1].Key != "sa-east-1" {
+		t.Errorf("unexpected regions %+v", regions)
+	}
+}
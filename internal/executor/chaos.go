@@ -0,0 +1,108 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/estudosdevops/opsmaster/internal/cloud"
+	"github.com/estudosdevops/opsmaster/internal/logger"
+)
+
+// ChaosEnvVar must be set to "1" for chaos mode to be accepted.
+// Keeps the hidden --chaos flag from being enabled by accident.
+const ChaosEnvVar = "OPSMASTER_CHAOS"
+
+// ErrChaosInjected marks failures injected by chaos mode.
+var ErrChaosInjected = errors.New("chaos: injected failure")
+
+// ChaosConfig injects random failures and latency into instance executions,
+// to rehearse monitoring and retry workflows on huge runs. Only meant for
+// dry-run executions - it never touches instances by itself.
+type ChaosConfig struct {
+	FailRate float64       // Probability (0-1) of failing an instance
+	Latency  time.Duration // Max random delay added to each instance
+}
+
+// ChaosEnabled reports whether chaos mode is allowed by the environment.
+func ChaosEnabled() bool {
+	return os.Getenv(ChaosEnvVar) == "1"
+}
+
+// ParseChaos parses a spec like "fail-rate=10%,latency=5s".
+func ParseChaos(spec string) (*ChaosConfig, error) {
+	config := &ChaosConfig{}
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid chaos option %q: expected key=value", part)
+		}
+
+		switch strings.TrimSpace(key) {
+		case "fail-rate":
+			rate, err := parseRate(strings.TrimSpace(value))
+			if err != nil {
+				return nil, err
+			}
+			config.FailRate = rate
+		case "latency":
+			latency, err := time.ParseDuration(strings.TrimSpace(value))
+			if err != nil || latency < 0 {
+				return nil, fmt.Errorf("invalid chaos latency %q", value)
+			}
+			config.Latency = latency
+		default:
+			return nil, fmt.Errorf("unknown chaos option %q (valid: fail-rate, latency)", key)
+		}
+	}
+	return config, nil
+}
+
+// parseRate accepts "10%" or "0.1".
+func parseRate(value string) (float64, error) {
+	percent := strings.HasSuffix(value, "%")
+	rate, err := strconv.ParseFloat(strings.TrimSuffix(value, "%"), 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid chaos fail-rate %q", value)
+	}
+	if percent {
+		rate /= 100
+	}
+	if rate < 0 || rate > 1 {
+		return 0, fmt.Errorf("chaos fail-rate %q out of range (0-100%%)", value)
+	}
+	return rate, nil
+}
+
+// injectChaos delays the instance and randomly fails it.
+func (pe *ParallelExecutor) injectChaos(ctx context.Context, instance *cloud.Instance) error {
+	if pe.chaos == nil {
+		return nil
+	}
+
+	if pe.chaos.Latency > 0 {
+		// #nosec G404 - Using math/rand for chaos is acceptable (not cryptographic)
+		delay := time.Duration(rand.Int63n(int64(pe.chaos.Latency) + 1))
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	// #nosec G404 - Using math/rand for chaos is acceptable (not cryptographic)
+	if rand.Float64() < pe.chaos.FailRate {
+		logger.FromContext(ctx).Debug("Chaos: injecting failure")
+		return ErrChaosInjected
+	}
+	return nil
+}
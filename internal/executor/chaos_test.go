@@ -0,0 +1,73 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/estudosdevops/opsmaster/internal/cloud/cloudtest"
+)
+
+// TestParseChaos tests chaos spec parsing
+func TestParseChaos(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    string
+		want    ChaosConfig
+		wantErr bool
+	}{
+		{name: "percent and latency", spec: "fail-rate=10%,latency=5s", want: ChaosConfig{FailRate: 0.1, Latency: 5 * time.Second}},
+		{name: "fraction", spec: "fail-rate=0.25", want: ChaosConfig{FailRate: 0.25}},
+		{name: "spaces are ignored", spec: " latency = 1s , ", want: ChaosConfig{Latency: time.Second}},
+		{name: "rate out of range", spec: "fail-rate=150%", wantErr: true},
+		{name: "invalid latency", spec: "latency=soon", wantErr: true},
+		{name: "unknown option", spec: "explode=1", wantErr: true},
+		{name: "missing value", spec: "fail-rate", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseChaos(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseChaos() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && *got != tt.want {
+				t.Errorf("ParseChaos() = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+// TestExecute_Chaos tests that chaos mode fails instances without executing commands.
+//
+// 🎓 CONCEPT: Deterministic randomness
+// fail-rate=100% makes every instance fail, so the test does not depend on luck.
+func TestExecute_Chaos(t *testing.T) {
+	// ARRANGE
+	provider := &cloudtest.Provider{}
+	executor := NewParallelExecutor(ExecutorConfig{
+		Provider:  provider,
+		Installer: &mockPackageInstaller{},
+		Chaos:     &ChaosConfig{FailRate: 1, Latency: time.Millisecond},
+	})
+
+	// ACT
+	result, err := executor.Execute(context.Background(), createTestInstances(5))
+
+	// ASSERT
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Failed != 5 {
+		t.Errorf("Failed = %d, want 5", result.Failed)
+	}
+	for _, r := range result.Results {
+		if !errors.Is(r.GetError(), ErrChaosInjected) {
+			t.Errorf("expected injected failure, got %v", r.GetError())
+		}
+	}
+	if provider.CallCount(cloudtest.MethodExecuteCommand) != 0 || provider.CallCount(cloudtest.MethodTagInstance) != 0 {
+		t.Error("chaos mode must not run commands or tag instances")
+	}
+}
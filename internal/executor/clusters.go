@@ -0,0 +1,93 @@
+package executor
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// maxClusterSamples is the number of sample instance IDs kept per failure cluster.
+const maxClusterSamples = 3
+
+// FailureCluster groups failed instances sharing the same normalized error signature.
+type FailureCluster struct {
+	Signature string   // Normalized error message
+	Count     int      // Number of failed instances with this signature
+	SampleIDs []string // Up to maxClusterSamples instance IDs
+}
+
+// volatileTokens matches error fragments that differ between instances but not
+// between failure modes (IDs, addresses, timings). Applied in order.
+var volatileTokens = []struct {
+	pattern     *regexp.Regexp
+	replacement string
+}{
+	{regexp.MustCompile(`\bi-[0-9a-f]{8,17}\b`), "<instance>"},
+	{regexp.MustCompile(`\b[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}\b`), "<uuid>"},
+	{regexp.MustCompile(`\b\d{1,3}(\.\d{1,3}){3}(:\d+)?\b`), "<ip>"},
+	{regexp.MustCompile(`\b\d+(\.\d+)?(ms|s|m|h)\b`), "<duration>"},
+	{regexp.MustCompile(`\b\d{4}-\d{2}-\d{2}[T ][0-9:.]+Z?\b`), "<time>"},
+	{regexp.MustCompile(`(?i)request[ -]?id: [0-9a-z-]+`), "RequestID: <id>"},
+}
+
+// detailKeywords identifies the most informative detail line of multi-line
+// errors (e.g., script output after "installation script failed with exit code 1:").
+var detailKeywords = regexp.MustCompile(`(?i)(error|failed|cannot|unable|not found|denied)`)
+
+// ErrorSignature normalizes an error message into a signature suitable for clustering.
+// Uses the first line plus the first detail line mentioning an error, with volatile
+// tokens (instance IDs, IPs, durations, request IDs) replaced by placeholders.
+func ErrorSignature(message string) string {
+	lines := strings.Split(strings.TrimSpace(message), "\n")
+	signature := strings.TrimSpace(lines[0])
+
+	for _, line := range lines[1:] {
+		line = strings.TrimSpace(line)
+		line = strings.TrimPrefix(line, "stdout: ")
+		line = strings.TrimPrefix(line, "stderr: ")
+		if line != "" && detailKeywords.MatchString(line) {
+			signature = strings.TrimSuffix(signature, ":") + ": " + line
+			break
+		}
+	}
+
+	for _, token := range volatileTokens {
+		signature = token.pattern.ReplaceAllString(signature, token.replacement)
+	}
+
+	return signature
+}
+
+// FailureClusters groups failed instances by normalized error signature.
+// Clusters are sorted by size (largest first), so the dominant failure
+// modes of large runs appear at the top.
+func (ar *AggregatedResult) FailureClusters() []FailureCluster {
+	index := make(map[string]int)
+	var clusters []FailureCluster
+
+	for _, result := range ar.GetFailedInstances() {
+		message := "unknown error"
+		if err := result.GetError(); err != nil {
+			message = err.Error()
+		}
+		signature := ErrorSignature(message)
+
+		i, exists := index[signature]
+		if !exists {
+			i = len(clusters)
+			index[signature] = i
+			clusters = append(clusters, FailureCluster{Signature: signature})
+		}
+
+		clusters[i].Count++
+		if len(clusters[i].SampleIDs) < maxClusterSamples {
+			clusters[i].SampleIDs = append(clusters[i].SampleIDs, result.Instance.ID)
+		}
+	}
+
+	sort.SliceStable(clusters, func(a, b int) bool {
+		return clusters[a].Count > clusters[b].Count
+	})
+
+	return clusters
+}
@@ -0,0 +1,90 @@
+package executor
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+// TestErrorSignature tests normalization of error messages into cluster signatures
+func TestErrorSignature(t *testing.T) {
+	tests := []struct {
+		name     string
+		message  string
+		expected string
+	}{
+		{
+			name:     "volatile IP and duration removed",
+			message:  "Cannot reach puppet.example.com:8140 - dial tcp 10.0.1.15:8140: i/o timeout after 5s",
+			expected: "Cannot reach puppet.example.com:8140 - dial tcp <ip>: i/o timeout after <duration>",
+		},
+		{
+			name:     "instance ID removed",
+			message:  "instance validation failed: instance i-0123456789abcdef0 not managed by SSM",
+			expected: "instance validation failed: instance <instance> not managed by SSM",
+		},
+		{
+			name:     "multi-line error uses first detail line with error keyword",
+			message:  "installation failed: installation script failed with exit code 1:\nstdout: Loaded plugins\nstderr: Error: yum install failed",
+			expected: "installation failed: installation script failed with exit code 1: Error: yum install failed",
+		},
+		{
+			name:     "request ID removed",
+			message:  "operation error EC2: CreateTags, https response error StatusCode: 403, RequestID: 1a2b-3c4d, api error",
+			expected: "operation error EC2: CreateTags, https response error StatusCode: 403, RequestID: <id>, api error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ErrorSignature(tt.message)
+			if got != tt.expected {
+				t.Errorf("ErrorSignature() =\n  %q\nwant\n  %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+// TestAggregatedResult_FailureClusters tests clustering failed instances by signature
+func TestAggregatedResult_FailureClusters(t *testing.T) {
+	// ARRANGE: 5 connectivity failures (different IPs), 2 yum failures, 1 success
+	agg := NewAggregatedResult()
+	for i := range 5 {
+		result := createTestExecutionResult(StatusFailed, fmt.Sprintf("i-conn%d", i))
+		result.ValidationErr = fmt.Errorf("Cannot reach puppet.example.com:8140 - dial tcp 10.0.0.%d:8140: i/o timeout", i)
+		agg.Add(result)
+	}
+	for i := range 2 {
+		result := createTestExecutionResult(StatusFailed, fmt.Sprintf("i-yum%d", i))
+		result.InstallationErr = errors.New("installation script failed with exit code 1:\nstderr: yum install failed")
+		agg.Add(result)
+	}
+	agg.Add(createTestExecutionResult(StatusSuccess, "i-ok"))
+
+	// ACT
+	clusters := agg.FailureClusters()
+
+	// ASSERT
+	if len(clusters) != 2 {
+		t.Fatalf("expected 2 clusters, got %d: %+v", len(clusters), clusters)
+	}
+	if clusters[0].Count != 5 || clusters[1].Count != 2 {
+		t.Errorf("expected clusters sorted by size (5, 2), got (%d, %d)", clusters[0].Count, clusters[1].Count)
+	}
+	if len(clusters[0].SampleIDs) != maxClusterSamples {
+		t.Errorf("expected %d sample IDs, got %d", maxClusterSamples, len(clusters[0].SampleIDs))
+	}
+	if clusters[1].SampleIDs[0] != "i-yum0" {
+		t.Errorf("expected first sample i-yum0, got %s", clusters[1].SampleIDs[0])
+	}
+}
+
+// TestAggregatedResult_FailureClusters_NoFailures tests that successful runs have no clusters
+func TestAggregatedResult_FailureClusters_NoFailures(t *testing.T) {
+	agg := NewAggregatedResult()
+	agg.Add(createTestExecutionResult(StatusSuccess, "i-ok"))
+
+	if clusters := agg.FailureClusters(); len(clusters) != 0 {
+		t.Errorf("expected no clusters, got %+v", clusters)
+	}
+}
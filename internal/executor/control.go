@@ -0,0 +1,142 @@
+package executor
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/estudosdevops/opsmaster/internal/control"
+	"github.com/estudosdevops/opsmaster/internal/logger"
+)
+
+// RunControl pauses, resumes and aborts the dispatch of new instances of an
+// execution, e.g. from the control socket (see package control and
+// ExecutorConfig.Control). Instances in flight always finish; an aborted
+// run reports the queued instances as canceled. Safe for concurrent use.
+type RunControl struct {
+	runID     string
+	command   string
+	startedAt time.Time
+
+	mu        sync.Mutex
+	queue     *workQueue // Set when the execution starts dispatching
+	total     int
+	completed int
+	paused    bool
+	pausedAt  time.Time
+	aborted   bool
+}
+
+// NewRunControl creates the control of a run. Pause and abort requests made
+// before the execution starts dispatching apply once it does.
+func NewRunControl(runID, command string) *RunControl {
+	return &RunControl{runID: runID, command: command, startedAt: time.Now()}
+}
+
+// Pause stops dispatching new instances.
+func (rc *RunControl) Pause() error {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	if rc.aborted {
+		return fmt.Errorf("run %s is aborting", rc.runID)
+	}
+	if rc.paused {
+		return nil
+	}
+	rc.paused, rc.pausedAt = true, time.Now()
+	if rc.queue != nil {
+		rc.queue.setPaused(true)
+	}
+	logger.Get().Warn("⏸️  Dispatch paused from the control socket: in-flight instances will finish")
+	return nil
+}
+
+// Resume dispatches new instances again.
+func (rc *RunControl) Resume() error {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	if rc.aborted {
+		return fmt.Errorf("run %s is aborting", rc.runID)
+	}
+	if !rc.paused {
+		return nil
+	}
+	logger.Get().Info("▶️  Dispatch resumed from the control socket",
+		"paused_for", time.Since(rc.pausedAt).Round(time.Second))
+	rc.paused, rc.pausedAt = false, time.Time{}
+	if rc.queue != nil {
+		rc.queue.setPaused(false)
+	}
+	return nil
+}
+
+// Abort stops the dispatch for good: queued instances are canceled and
+// in-flight ones finish.
+func (rc *RunControl) Abort() error {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	if rc.aborted {
+		return nil
+	}
+	rc.aborted = true
+	if rc.queue != nil {
+		rc.queue.stop()
+	}
+	logger.Get().Warn("⏹️  Run aborted from the control socket: queued instances will be canceled, in-flight ones will finish")
+	return nil
+}
+
+// Status returns the progress of the run.
+func (rc *RunControl) Status() control.Status {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	status := control.Status{
+		RunID:     rc.runID,
+		Command:   rc.command,
+		PID:       os.Getpid(),
+		State:     control.StateRunning,
+		StartedAt: rc.startedAt,
+		Total:     rc.total,
+		Completed: rc.completed,
+	}
+	if rc.queue != nil {
+		status.Queued, status.InFlight = rc.queue.counts()
+	}
+	switch {
+	case rc.aborted:
+		status.State = control.StateAborting
+	case rc.paused:
+		status.State = control.StatePaused
+		pausedAt := rc.pausedAt
+		status.PausedAt = &pausedAt
+	case rc.queue == nil:
+		status.State = control.StateStarting
+	}
+	return status
+}
+
+// attach starts controlling the queue of an execution of total instances,
+// completed of which already have a result (skipped in pre-flight).
+func (rc *RunControl) attach(queue *workQueue, total, completed int) {
+	if rc == nil {
+		return
+	}
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.queue, rc.total, rc.completed = queue, total, completed
+	queue.setPaused(rc.paused)
+	if rc.aborted {
+		queue.stop()
+	}
+}
+
+// observe counts a finished instance.
+func (rc *RunControl) observe() {
+	if rc == nil {
+		return
+	}
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.completed++
+}
@@ -0,0 +1,68 @@
+package executor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/estudosdevops/opsmaster/internal/control"
+)
+
+func TestRunControl(t *testing.T) {
+	rc := NewRunControl("run-1", "install puppet")
+	if got := rc.Status().State; got != control.StateStarting {
+		t.Errorf("State before attach = %s, want %s", got, control.StateStarting)
+	}
+
+	// Paused before the dispatch starts: applied on attach
+	if err := rc.Pause(); err != nil {
+		t.Fatalf("Pause() error = %v", err)
+	}
+	q := newWorkQueue(0)
+	q.push(newItem("i-a", 1, ""))
+	q.push(newItem("i-b", 1, ""))
+	rc.attach(q, 3, 1)
+
+	status := rc.Status()
+	if status.State != control.StatePaused || status.PausedAt == nil {
+		t.Errorf("State = %s (paused at %v), want %s", status.State, status.PausedAt, control.StatePaused)
+	}
+	if status.Total != 3 || status.Completed != 1 || status.Queued != 2 {
+		t.Errorf("Status() = %+v, want total 3, completed 1, queued 2", status)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, ok := q.pop(ctx); ok {
+		t.Fatal("pop() returned an item while paused")
+	}
+
+	if err := rc.Resume(); err != nil {
+		t.Fatalf("Resume() error = %v", err)
+	}
+	popIDs(t, q, 1)
+	rc.observe()
+	if status := rc.Status(); status.State != control.StateRunning || status.InFlight != 1 || status.Completed != 2 {
+		t.Errorf("Status() = %+v, want running, 1 in flight, 2 completed", status)
+	}
+
+	if err := rc.Abort(); err != nil {
+		t.Fatalf("Abort() error = %v", err)
+	}
+	if _, ok := q.pop(context.Background()); ok {
+		t.Error("pop() returned an item after abort")
+	}
+	if got := rc.Status().State; got != control.StateAborting {
+		t.Errorf("State = %s, want %s", got, control.StateAborting)
+	}
+	if err := rc.Pause(); err == nil {
+		t.Error("Pause() after abort should fail")
+	}
+}
+
+// TestRunControl_Nil tests that executions without control ignore it
+func TestRunControl_Nil(t *testing.T) {
+	var rc *RunControl
+	rc.attach(newWorkQueue(0), 1, 0)
+	rc.observe()
+}
@@ -0,0 +1,51 @@
+package executor
+
+import (
+	"context"
+	"time"
+
+	"github.com/estudosdevops/opsmaster/internal/cloud"
+	"github.com/estudosdevops/opsmaster/internal/installer"
+	"github.com/estudosdevops/opsmaster/internal/logger"
+)
+
+// diagnosticsTimeout bounds the diagnostic bundle of a failed instance.
+const diagnosticsTimeout = time.Minute
+
+// collectDiagnostics runs the diagnostic bundle (see installer.DiagnosticScript)
+// on an instance that failed installing or verifying and attaches its output
+// to the error context, so most failures can be debugged from the report
+// without opening a session on the instance.
+//
+// Validation failures are explained by the failed validators (and the
+// instance is often unreachable), transient ones by their reason: both are
+// skipped, as are dry-runs. Errors are logged and never change the result.
+func (pe *ParallelExecutor) collectDiagnostics(ctx context.Context, result *ExecutionResult) {
+	if pe.skipDiagnostics || pe.dryRun || result.Status != StatusFailed || ctx.Err() != nil {
+		return
+	}
+	if result.FailurePhase == installer.ScriptPhaseValidation || transientReason(result.GetError()) != "" {
+		return
+	}
+
+	var logs []string
+	if pe.caps.Diagnostics != nil {
+		logs = pe.caps.Diagnostics.DiagnosticLogs()
+	}
+	log := logger.FromContext(ctx)
+	log.Debug("Collecting diagnostics", "logs", logs)
+
+	ctx, cancel := context.WithTimeout(ctx, diagnosticsTimeout)
+	defer cancel()
+	output, err := pe.provider.ExecuteCommand(cloud.ReadOnlyCommands(ctx), result.Instance, installer.DiagnosticScript(logs), diagnosticsTimeout)
+	if err != nil {
+		log.Warn("Failed to collect diagnostics", "error", err)
+		return
+	}
+
+	output.TruncateOutput(pe.maxOutputBytes)
+	if result.ErrorContext == nil {
+		result.ErrorContext = &ErrorContext{}
+	}
+	result.ErrorContext.Diagnostics = output.Stdout
+}
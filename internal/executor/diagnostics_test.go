@@ -0,0 +1,99 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/estudosdevops/opsmaster/internal/cloud"
+	"github.com/estudosdevops/opsmaster/internal/cloud/cloudtest"
+)
+
+// TestExecute_Diagnostics tests the diagnostic bundle attached to the error
+// context of failed instances.
+func TestExecute_Diagnostics(t *testing.T) {
+	installFails := cloudtest.Response{Contains: "install with auto-detect", Result: &cloud.CommandResult{ExitCode: 1, Stderr: "E: Unable to locate package"}}
+	bundle := cloudtest.Response{Contains: "diag df -h", Result: &cloud.CommandResult{Stdout: "=== df -h ===\n/dev/root 100% /\n"}}
+
+	tests := []struct {
+		name            string
+		responses       []cloudtest.Response
+		installer       *mockPackageInstaller
+		skipDiagnostics bool
+		wantBundle      bool
+		wantStderr      string
+	}{
+		{
+			name:       "install failure keeps script output and adds the bundle",
+			responses:  []cloudtest.Response{installFails, bundle},
+			installer:  &mockPackageInstaller{},
+			wantBundle: true,
+			wantStderr: "E: Unable to locate package",
+		},
+		{
+			name:      "verify failure gets an error context with the bundle",
+			responses: []cloudtest.Response{bundle},
+			installer: &mockPackageInstaller{
+				verifyInstallationFunc: func(context.Context, *cloud.Instance, cloud.CloudProvider) error {
+					return errors.New("puppet agent not running")
+				},
+			},
+			wantBundle: true,
+		},
+		{
+			name:      "validation failure is not diagnosed",
+			responses: []cloudtest.Response{bundle},
+			installer: &mockPackageInstaller{
+				validatePrerequisitesFunc: func(context.Context, *cloud.Instance, cloud.CloudProvider) error {
+					return errors.New("puppet_server_reachable: connection refused")
+				},
+			},
+		},
+		{
+			name:            "disabled",
+			responses:       []cloudtest.Response{installFails, bundle},
+			installer:       &mockPackageInstaller{},
+			skipDiagnostics: true,
+			wantStderr:      "E: Unable to locate package",
+		},
+		{
+			name:       "bundle error leaves the result unchanged",
+			responses:  []cloudtest.Response{installFails, {Contains: "diag df -h", Err: errors.New("InvocationDoesNotExist")}},
+			installer:  &mockPackageInstaller{},
+			wantStderr: "E: Unable to locate package",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := &cloudtest.Provider{Responses: tt.responses}
+			executor := NewParallelExecutor(ExecutorConfig{
+				Provider:        provider,
+				Installer:       tt.installer,
+				SkipTagging:     true,
+				SkipDiagnostics: tt.skipDiagnostics,
+			})
+
+			result, err := executor.Execute(context.Background(), createTestInstances(1))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.Failed != 1 {
+				t.Fatalf("Failed = %d, want 1", result.Failed)
+			}
+
+			errorContext := result.Results[0].ErrorContext
+			var diagnostics, stderr string
+			if errorContext != nil {
+				diagnostics, stderr = errorContext.Diagnostics, errorContext.Stderr
+			}
+			if got := strings.Contains(diagnostics, "/dev/root 100% /"); got != tt.wantBundle {
+				t.Errorf("Diagnostics = %q, want bundle: %v", diagnostics, tt.wantBundle)
+			}
+			if stderr != tt.wantStderr {
+				t.Errorf("Stderr = %q, want %q", stderr, tt.wantStderr)
+			}
+		})
+	}
+}
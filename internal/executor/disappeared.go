@@ -0,0 +1,46 @@
+package executor
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/estudosdevops/opsmaster/internal/cloud"
+	"github.com/estudosdevops/opsmaster/internal/installer"
+)
+
+// disappearedFailures are error fragments of instances that went away
+// mid-run, for providers (e.g., plugins) whose errors don't wrap
+// cloud.ErrInstanceGone.
+var disappearedFailures = []string{
+	"invalidinstanceid",
+	"instance is terminated",
+	"instance terminated",
+}
+
+// instanceDisappeared reports whether err means the instance no longer
+// exists (e.g., terminated by autoscaling), rather than a failure of the
+// instance. Script failures never qualify: the script ran on the instance.
+func instanceDisappeared(err error) bool {
+	if err == nil {
+		return false
+	}
+	var scriptErr *installer.ScriptError
+	if errors.As(err, &scriptErr) {
+		return false
+	}
+	if errors.Is(err, cloud.ErrInstanceGone) {
+		return true
+	}
+
+	errStr := strings.ToLower(err.Error())
+	if strings.Contains(errStr, "invalidinstanceid.malformed") {
+		// Bad ID in the inventory, not an instance that went away
+		return false
+	}
+	for _, fragment := range disappearedFailures {
+		if strings.Contains(errStr, fragment) {
+			return true
+		}
+	}
+	return false
+}
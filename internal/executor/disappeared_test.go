@@ -0,0 +1,34 @@
+package executor
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/estudosdevops/opsmaster/internal/cloud"
+	"github.com/estudosdevops/opsmaster/internal/installer"
+)
+
+func TestInstanceDisappeared(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "nil", err: nil, want: false},
+		{name: "wrapped ErrInstanceGone", err: fmt.Errorf("installation failed: %w", cloud.ErrInstanceGone), want: true},
+		{name: "SSM InvalidInstanceId", err: errors.New("failed to send SSM command: operation error SSM: SendCommand, InvalidInstanceId: Instances not in a valid state"), want: true},
+		{name: "plugin terminated", err: errors.New("instance terminated while running command"), want: true},
+		{name: "malformed ID", err: errors.New("InvalidInstanceID.Malformed: Invalid id: \"i-xyz\""), want: false},
+		{name: "agent offline", err: errors.New("command Undeliverable"), want: false},
+		{name: "script failure", err: &installer.ScriptError{ExitCode: 1, Stderr: "instance terminated"}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := instanceDisappeared(tt.err); got != tt.want {
+				t.Errorf("instanceDisappeared() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
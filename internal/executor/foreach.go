@@ -0,0 +1,59 @@
+package executor
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+
+	"github.com/estudosdevops/opsmaster/internal/cloud"
+	"github.com/estudosdevops/opsmaster/internal/logger"
+)
+
+// ForEachInstance calls fn for every instance with at most maxConcurrency
+// calls in flight (default 10) and waits for all of them. Used by read-only
+// fleet commands (fact queries, log tails) that don't need the install
+// workflow of ParallelExecutor.
+//
+// Returns one error per instance, in input order. Instances still waiting
+// for a slot when ctx is canceled get ctx.Err(). fn's context carries a
+// logger with the instance fields (see logger.FromContext).
+func ForEachInstance(ctx context.Context, instances []*cloud.Instance, maxConcurrency int, fn func(ctx context.Context, instance *cloud.Instance) error) []error {
+	if maxConcurrency <= 0 {
+		maxConcurrency = 10
+	}
+
+	errs := make([]error, len(instances))
+	semaphore := make(chan struct{}, maxConcurrency)
+	var wg sync.WaitGroup
+
+	for i, instance := range instances {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// select picks randomly among ready cases: check ctx first
+			if ctx.Err() != nil || !acquire(ctx, semaphore) {
+				errs[i] = ctx.Err()
+				return
+			}
+			defer func() { <-semaphore }()
+			errs[i] = fn(withInstanceLogger(ctx, logger.FromContext(ctx), instance), instance)
+		}()
+	}
+
+	wg.Wait()
+	return errs
+}
+
+// withInstanceLogger returns ctx carrying a child of log with the instance
+// fields (and its name, when known), so per-instance log lines don't have to
+// repeat them.
+func withInstanceLogger(ctx context.Context, log *slog.Logger, instance *cloud.Instance) context.Context {
+	log = log.With(
+		"instance_id", instance.ID,
+		"account", instance.Account,
+		"region", instance.Region)
+	if name := instance.DisplayName(); name != "" {
+		log = log.With("instance_name", name)
+	}
+	return logger.NewContext(ctx, log)
+}
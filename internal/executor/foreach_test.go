@@ -0,0 +1,63 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/estudosdevops/opsmaster/internal/cloud"
+)
+
+// TestForEachInstance tests ordering of errors and the concurrency limit
+func TestForEachInstance(t *testing.T) {
+	// ARRANGE
+	instances := make([]*cloud.Instance, 8)
+	for i := range instances {
+		instances[i] = &cloud.Instance{ID: fmt.Sprintf("i-%d", i)}
+	}
+	var running, peak atomic.Int32
+
+	// ACT
+	errs := ForEachInstance(context.Background(), instances, 3, func(_ context.Context, instance *cloud.Instance) error {
+		current := running.Add(1)
+		defer running.Add(-1)
+		for {
+			old := peak.Load()
+			if current <= old || peak.CompareAndSwap(old, current) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		if instance.ID == "i-5" {
+			return fmt.Errorf("boom")
+		}
+		return nil
+	})
+
+	// ASSERT
+	if peak.Load() > 3 {
+		t.Errorf("peak concurrency = %d, want <= 3", peak.Load())
+	}
+	for i, err := range errs {
+		if (err != nil) != (i == 5) {
+			t.Errorf("errs[%d] = %v", i, err)
+		}
+	}
+}
+
+// TestForEachInstance_Cancelled tests instances not started after cancellation
+func TestForEachInstance_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	errs := ForEachInstance(ctx, []*cloud.Instance{{ID: "i-1"}}, 1, func(context.Context, *cloud.Instance) error {
+		t.Error("fn must not run after cancellation")
+		return nil
+	})
+
+	if errs[0] != context.Canceled {
+		t.Errorf("err = %v, want context.Canceled", errs[0])
+	}
+}
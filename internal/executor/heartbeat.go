@@ -0,0 +1,190 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/estudosdevops/opsmaster/internal/cloud"
+	"github.com/estudosdevops/opsmaster/internal/logger"
+)
+
+// DefaultHeartbeatInterval is the default interval of liveness logs for
+// in-flight instances.
+const DefaultHeartbeatInterval = time.Minute
+
+// defaultExpectedPhaseDurations is how long each phase is expected to take at
+// most; a phase running longer is reported stuck. The stagger phase is
+// bounded by FirstRunStagger and never reported.
+var defaultExpectedPhaseDurations = map[string]time.Duration{
+	PhaseValidate:    5 * time.Minute,
+	PhaseInstall:     20 * time.Minute,
+	PhaseVerify:      10 * time.Minute,
+	PhaseTag:         2 * time.Minute,
+	PhasePostInstall: 5 * time.Minute,
+}
+
+// ParseExpectedDurations converts phase=duration values (e.g., from a CLI
+// flag) to ExecutorConfig.ExpectedDurations.
+func ParseExpectedDurations(values map[string]string) (map[string]time.Duration, error) {
+	durations := make(map[string]time.Duration, len(values))
+	for phase, value := range values {
+		if _, ok := defaultExpectedPhaseDurations[phase]; !ok {
+			return nil, fmt.Errorf("unknown phase %q (supported: %s, %s, %s, %s, %s)",
+				phase, PhaseValidate, PhaseInstall, PhaseVerify, PhaseTag, PhasePostInstall)
+		}
+		duration, err := time.ParseDuration(value)
+		if err != nil {
+			return nil, fmt.Errorf("phase %s: %w", phase, err)
+		}
+		if duration <= 0 {
+			return nil, fmt.Errorf("phase %s: duration must be positive", phase)
+		}
+		durations[phase] = duration
+	}
+	return durations, nil
+}
+
+// heartbeat logs the liveness of in-flight instances: every interval, the
+// elapsed time, the current phase and the last provider command ID of each
+// one, so a 20 minute installation doesn't look hung. A phase running longer
+// than expected is reported stuck once and recorded in the result.
+type heartbeat struct {
+	interval time.Duration
+	expected map[string]time.Duration
+
+	mu       sync.Mutex
+	inflight map[*liveness]struct{}
+}
+
+// liveness is the progress of one in-flight instance (guarded by heartbeat.mu).
+type liveness struct {
+	hb         *heartbeat
+	log        *slog.Logger
+	start      time.Time
+	phase      string
+	phaseStart time.Time
+	commandID  string
+	stuck      []string // Phases reported stuck
+}
+
+type livenessKey struct{}
+
+// newHeartbeat creates a heartbeat logging every interval (0 = disabled),
+// with expected phase durations overriding the defaults.
+func newHeartbeat(interval time.Duration, expected map[string]time.Duration) *heartbeat {
+	merged := make(map[string]time.Duration, len(defaultExpectedPhaseDurations))
+	for phase, duration := range defaultExpectedPhaseDurations {
+		merged[phase] = duration
+	}
+	for phase, duration := range expected {
+		merged[phase] = duration
+	}
+	return &heartbeat{interval: interval, expected: merged, inflight: make(map[*liveness]struct{})}
+}
+
+// start logs heartbeats until the returned stop function is called or ctx
+// is canceled.
+func (hb *heartbeat) start(ctx context.Context) (stop func()) {
+	if hb.interval <= 0 {
+		return func() {}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(hb.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case now := <-ticker.C:
+				hb.beat(now)
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+// track registers an instance that starts processing, logging with the
+// logger of ctx. The returned context carries its liveness (see enterPhase)
+// and reports provider command IDs to it.
+func (hb *heartbeat) track(ctx context.Context, start time.Time) (context.Context, *liveness) {
+	if hb.interval <= 0 {
+		return ctx, nil
+	}
+
+	live := &liveness{hb: hb, log: logger.FromContext(ctx), start: start, phaseStart: start}
+	hb.mu.Lock()
+	hb.inflight[live] = struct{}{}
+	hb.mu.Unlock()
+
+	ctx = context.WithValue(ctx, livenessKey{}, live)
+	return cloud.WithCommandObserver(ctx, live.observeCommand), live
+}
+
+// finish unregisters an instance and records its stuck phases in result.
+func (hb *heartbeat) finish(live *liveness, result *ExecutionResult) {
+	if live == nil {
+		return
+	}
+	hb.mu.Lock()
+	defer hb.mu.Unlock()
+	delete(hb.inflight, live)
+	result.StuckPhases = slices.Clone(live.stuck)
+}
+
+// beat logs the liveness of every in-flight instance at now.
+func (hb *heartbeat) beat(now time.Time) {
+	hb.mu.Lock()
+	defer hb.mu.Unlock()
+
+	for live := range hb.inflight {
+		phaseElapsed := now.Sub(live.phaseStart).Round(time.Second)
+		attrs := []any{
+			"elapsed", now.Sub(live.start).Round(time.Second),
+			"phase", live.phase,
+			"phase_elapsed", phaseElapsed,
+		}
+		if live.commandID != "" {
+			attrs = append(attrs, "command_id", live.commandID)
+		}
+		live.log.Info("Instance still running", attrs...)
+
+		expected, ok := hb.expected[live.phase]
+		if !ok || phaseElapsed <= expected || slices.Contains(live.stuck, live.phase) {
+			continue
+		}
+		live.stuck = append(live.stuck, live.phase)
+		live.log.Warn("Instance may be stuck: phase exceeds its expected duration",
+			append(attrs, "expected", expected)...)
+	}
+}
+
+// enterPhase records that the instance of ctx started a workflow phase.
+func enterPhase(ctx context.Context, phase string) {
+	live, ok := ctx.Value(livenessKey{}).(*liveness)
+	if !ok {
+		return
+	}
+	live.hb.mu.Lock()
+	defer live.hb.mu.Unlock()
+	live.phase = phase
+	live.phaseStart = time.Now()
+	live.commandID = ""
+}
+
+// observeCommand records the last command sent to the instance.
+func (live *liveness) observeCommand(commandID string) {
+	live.hb.mu.Lock()
+	defer live.hb.mu.Unlock()
+	live.commandID = commandID
+}
@@ -0,0 +1,101 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/estudosdevops/opsmaster/internal/cloud"
+	"github.com/estudosdevops/opsmaster/internal/logger"
+)
+
+func TestParseExpectedDurations(t *testing.T) {
+	tests := []struct {
+		name    string
+		values  map[string]string
+		want    map[string]time.Duration
+		wantErr bool
+	}{
+		{name: "empty", values: nil, want: map[string]time.Duration{}},
+		{name: "phases", values: map[string]string{"install": "30m", "verify": "90s"}, want: map[string]time.Duration{PhaseInstall: 30 * time.Minute, PhaseVerify: 90 * time.Second}},
+		{name: "unknown phase", values: map[string]string{"stagger": "1m"}, wantErr: true},
+		{name: "invalid duration", values: map[string]string{"install": "soon"}, wantErr: true},
+		{name: "zero duration", values: map[string]string{"install": "0s"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseExpectedDurations(tt.values)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseExpectedDurations() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("ParseExpectedDurations() = %v, want %v", got, tt.want)
+			}
+			for phase, duration := range tt.want {
+				if got[phase] != duration {
+					t.Errorf("ParseExpectedDurations()[%s] = %v, want %v", phase, got[phase], duration)
+				}
+			}
+		})
+	}
+}
+
+func TestHeartbeat_Beat(t *testing.T) {
+	// ARRANGE: an instance in the install phase, expected to take 10m
+	var logs bytes.Buffer
+	ctx := logger.NewContext(context.Background(), slog.New(slog.NewTextHandler(&logs, nil)))
+	hb := newHeartbeat(time.Minute, map[string]time.Duration{PhaseInstall: 10 * time.Minute})
+	start := time.Now()
+
+	ctx, live := hb.track(ctx, start)
+	enterPhase(ctx, PhaseInstall)
+	cloud.ObserveCommand(ctx, "cmd-123")
+
+	// ACT: a beat within the expected duration, then two past it
+	hb.beat(start.Add(5 * time.Minute))
+	withinExpected := logs.String()
+	hb.beat(start.Add(11 * time.Minute))
+	hb.beat(start.Add(12 * time.Minute))
+
+	result := &ExecutionResult{}
+	hb.finish(live, result)
+
+	// ASSERT
+	if !strings.Contains(withinExpected, "Instance still running") || !strings.Contains(withinExpected, "command_id=cmd-123") {
+		t.Errorf("heartbeat log = %q, want the phase and command ID", withinExpected)
+	}
+	if strings.Contains(withinExpected, "stuck") {
+		t.Errorf("heartbeat within expected duration reported stuck: %q", withinExpected)
+	}
+	if got := strings.Count(logs.String(), "may be stuck"); got != 1 {
+		t.Errorf("stuck reported %d times, want once per phase", got)
+	}
+	if len(result.StuckPhases) != 1 || result.StuckPhases[0] != PhaseInstall {
+		t.Errorf("StuckPhases = %v, want [%s]", result.StuckPhases, PhaseInstall)
+	}
+	if len(hb.inflight) != 0 {
+		t.Errorf("inflight = %d after finish, want 0", len(hb.inflight))
+	}
+}
+
+func TestHeartbeat_Disabled(t *testing.T) {
+	hb := newHeartbeat(0, nil)
+	ctx, live := hb.track(context.Background(), time.Now())
+	enterPhase(ctx, PhaseInstall)
+	cloud.ObserveCommand(ctx, "cmd-123")
+
+	result := &ExecutionResult{}
+	hb.finish(live, result)
+	hb.start(ctx)()
+
+	if live != nil || result.StuckPhases != nil {
+		t.Errorf("disabled heartbeat tracked the instance (live=%v, stuck=%v)", live, result.StuckPhases)
+	}
+}
@@ -0,0 +1,54 @@
+package executor
+
+import (
+	"cmp"
+	"fmt"
+	"math/rand"
+	"slices"
+	"time"
+
+	"github.com/estudosdevops/opsmaster/internal/cloud"
+)
+
+// Dispatch orders of the first attempts (ExecutorConfig.Order).
+const (
+	OrderAsIs    = "as-is"   // Inventory (CSV) order
+	OrderSorted  = "sorted"  // By account, region and instance ID
+	OrderShuffle = "shuffle" // Random, reproducible with a seed
+)
+
+// ValidateOrder checks a dispatch order ("" = as-is).
+func ValidateOrder(order string) error {
+	switch order {
+	case "", OrderAsIs, OrderSorted, OrderShuffle:
+		return nil
+	default:
+		return fmt.Errorf("invalid order %q (valid: %s, %s, %s)", order, OrderAsIs, OrderSorted, OrderShuffle)
+	}
+}
+
+// orderInstances returns the instances in dispatch order, without changing
+// the given slice. Shuffling with seed 0 draws a new seed; the seed used is
+// returned so the run can be reproduced (0 for the other orders).
+func orderInstances(instances []*cloud.Instance, order string, seed int64) ([]*cloud.Instance, int64) {
+	ordered := slices.Clone(instances)
+	switch order {
+	case OrderSorted:
+		slices.SortStableFunc(ordered, func(a, b *cloud.Instance) int {
+			return cmp.Or(
+				cmp.Compare(a.Account, b.Account),
+				cmp.Compare(a.Region, b.Region),
+				cmp.Compare(a.ID, b.ID))
+		})
+		return ordered, 0
+	case OrderShuffle:
+		if seed == 0 {
+			seed = time.Now().UnixNano()
+		}
+		rng := rand.New(rand.NewSource(seed))
+		rng.Shuffle(len(ordered), func(i, j int) { ordered[i], ordered[j] = ordered[j], ordered[i] })
+		return ordered, seed
+	default:
+		return ordered, 0
+	}
+}
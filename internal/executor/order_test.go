@@ -0,0 +1,81 @@
+package executor
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/estudosdevops/opsmaster/internal/cloud"
+)
+
+func TestValidateOrder(t *testing.T) {
+	for _, order := range []string{"", OrderAsIs, OrderSorted, OrderShuffle} {
+		if err := ValidateOrder(order); err != nil {
+			t.Errorf("ValidateOrder(%q) error = %v", order, err)
+		}
+	}
+	if err := ValidateOrder("random"); err == nil {
+		t.Error("ValidateOrder(\"random\") expected error")
+	}
+}
+
+func TestOrderInstances(t *testing.T) {
+	instances := []*cloud.Instance{
+		{ID: "i-3", Account: "222", Region: "us-east-1"},
+		{ID: "i-2", Account: "111", Region: "us-west-2"},
+		{ID: "i-1", Account: "111", Region: "us-east-1"},
+		{ID: "i-4", Account: "111", Region: "us-east-1"},
+		{ID: "i-5", Account: "333", Region: "sa-east-1"},
+	}
+	ids := func(instances []*cloud.Instance) []string {
+		out := make([]string, len(instances))
+		for i, instance := range instances {
+			out[i] = instance.ID
+		}
+		return out
+	}
+	original := ids(instances)
+
+	tests := []struct {
+		name  string
+		order string
+		want  []string
+	}{
+		{name: "default", order: "", want: original},
+		{name: "as-is", order: OrderAsIs, want: original},
+		{name: "sorted", order: OrderSorted, want: []string{"i-1", "i-4", "i-2", "i-3", "i-5"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, seed := orderInstances(instances, tt.order, 42)
+			if !slices.Equal(ids(got), tt.want) {
+				t.Errorf("orderInstances() = %v, want %v", ids(got), tt.want)
+			}
+			if seed != 0 {
+				t.Errorf("orderInstances() seed = %d, want 0", seed)
+			}
+		})
+	}
+
+	t.Run("shuffle", func(t *testing.T) {
+		first, seed := orderInstances(instances, OrderShuffle, 42)
+		again, _ := orderInstances(instances, OrderShuffle, 42)
+		if seed != 42 {
+			t.Errorf("orderInstances() seed = %d, want 42", seed)
+		}
+		if !slices.Equal(ids(first), ids(again)) {
+			t.Errorf("same seed gave %v and %v, want the same order", ids(first), ids(again))
+		}
+		sorted := slices.Sorted(slices.Values(ids(first)))
+		if !slices.Equal(sorted, []string{"i-1", "i-2", "i-3", "i-4", "i-5"}) {
+			t.Errorf("shuffle = %v, want a permutation of the instances", ids(first))
+		}
+		if _, drawn := orderInstances(instances, OrderShuffle, 0); drawn == 0 {
+			t.Error("shuffle without seed returned seed 0, want the drawn seed")
+		}
+	})
+
+	if !slices.Equal(ids(instances), original) {
+		t.Errorf("orderInstances() changed the input to %v", ids(instances))
+	}
+}
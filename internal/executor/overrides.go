@@ -0,0 +1,182 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/estudosdevops/opsmaster/internal/cloud"
+)
+
+// InstanceOverride replaces run-level settings for the instances whose CSV
+// column Column (or, when the CSV doesn't have it, instance tag) equals
+// Value, e.g. a slow concurrency class with a longer install timeout and
+// fewer requeues. Nil settings keep the run-level value.
+type InstanceOverride struct {
+	Column string // CSV column or tag key (e.g., opsmaster_concurrency_class)
+	Value  string // Value selecting the instances (e.g., slow)
+
+	InstallTimeout    *time.Duration // Timeout of install scripts/steps run by the executor
+	VerifyGracePeriod *time.Duration // Replaces ExecutorConfig.VerifyGracePeriod
+	RequeueFailed     *int           // Replaces ExecutorConfig.RequeueFailed
+	RequeueTransient  *int           // Replaces ExecutorConfig.RequeueTransient
+}
+
+// String returns the selector of the override (column=value).
+func (o InstanceOverride) String() string {
+	return o.Column + "=" + o.Value
+}
+
+// ParseInstanceOverrides parses the instance_overrides section of the
+// config file (as decoded by viper), e.g.:
+//
+//	instance_overrides:
+//	  - column: opsmaster_concurrency_class
+//	    value: slow
+//	    install_timeout: 60m
+//	    verify_grace_period: 15m
+//	    requeue_failed: 0
+//	    requeue_transient: 1
+//
+// When several overrides match an instance they apply in order: later
+// entries win for the settings they set.
+func ParseInstanceOverrides(raw any) ([]InstanceOverride, error) {
+	if raw == nil {
+		return nil, nil
+	}
+	data, err := yaml.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid instance_overrides: %w", err)
+	}
+
+	var entries []struct {
+		Column            string `yaml:"column"`
+		Value             string `yaml:"value"`
+		InstallTimeout    string `yaml:"install_timeout"`
+		VerifyGracePeriod string `yaml:"verify_grace_period"`
+		RequeueFailed     *int   `yaml:"requeue_failed"`
+		RequeueTransient  *int   `yaml:"requeue_transient"`
+	}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&entries); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("invalid instance_overrides: %w", err)
+	}
+
+	overrides := make([]InstanceOverride, 0, len(entries))
+	for i, entry := range entries {
+		override := InstanceOverride{
+			Column:           entry.Column,
+			Value:            entry.Value,
+			RequeueFailed:    entry.RequeueFailed,
+			RequeueTransient: entry.RequeueTransient,
+		}
+		if override.Column == "" || override.Value == "" {
+			return nil, fmt.Errorf("instance_overrides[%d]: column and value are required", i)
+		}
+		if override.InstallTimeout, err = parseOverrideDuration(entry.InstallTimeout, false); err != nil {
+			return nil, fmt.Errorf("instance_overrides[%d] (%s): install_timeout: %w", i, override, err)
+		}
+		if override.VerifyGracePeriod, err = parseOverrideDuration(entry.VerifyGracePeriod, true); err != nil {
+			return nil, fmt.Errorf("instance_overrides[%d] (%s): verify_grace_period: %w", i, override, err)
+		}
+		for name, value := range map[string]*int{"requeue_failed": override.RequeueFailed, "requeue_transient": override.RequeueTransient} {
+			if value != nil && *value < 0 {
+				return nil, fmt.Errorf("instance_overrides[%d] (%s): %s must be zero or positive", i, override, name)
+			}
+		}
+		if override.InstallTimeout == nil && override.VerifyGracePeriod == nil &&
+			override.RequeueFailed == nil && override.RequeueTransient == nil {
+			return nil, fmt.Errorf("instance_overrides[%d] (%s): no settings to override "+
+				"(install_timeout, verify_grace_period, requeue_failed, requeue_transient)", i, override)
+		}
+		overrides = append(overrides, override)
+	}
+	return overrides, nil
+}
+
+// parseOverrideDuration parses an optional duration ("" = not set).
+func parseOverrideDuration(value string, allowZero bool) (*time.Duration, error) {
+	if value == "" {
+		return nil, nil
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return nil, err
+	}
+	if duration < 0 || (duration == 0 && !allowZero) {
+		return nil, fmt.Errorf("duration must be positive")
+	}
+	return &duration, nil
+}
+
+// instanceSettings are the settings an instance is processed with: the
+// run-level ones with the matching overrides applied.
+type instanceSettings struct {
+	installTimeout    time.Duration // 0 = step timeout or defaultInstallTimeout
+	verifyGracePeriod time.Duration
+	requeueFailed     int
+	requeueTransient  int
+	overrides         []string // Applied overrides (column=value)
+}
+
+type settingsKey struct{}
+
+// runSettings returns the run-level settings.
+func (pe *ParallelExecutor) runSettings() *instanceSettings {
+	return &instanceSettings{
+		verifyGracePeriod: pe.verifyGracePeriod,
+		requeueFailed:     pe.requeueFailed,
+		requeueTransient:  pe.requeueTransient,
+	}
+}
+
+// settingsFor resolves the settings of an instance. Override columns are
+// looked up in the CSV metadata, then in the instance tags (info, nil when
+// the provider can't describe instances).
+func (pe *ParallelExecutor) settingsFor(instance *cloud.Instance, info *cloud.InstanceInfo) *instanceSettings {
+	settings := pe.runSettings()
+	for _, override := range pe.overrides {
+		value, ok := instance.Metadata[strings.ToLower(override.Column)]
+		if !ok && info != nil {
+			value = info.Tags[override.Column]
+		}
+		if value != override.Value {
+			continue
+		}
+
+		if override.InstallTimeout != nil {
+			settings.installTimeout = *override.InstallTimeout
+		}
+		if override.VerifyGracePeriod != nil {
+			settings.verifyGracePeriod = *override.VerifyGracePeriod
+		}
+		if override.RequeueFailed != nil {
+			settings.requeueFailed = *override.RequeueFailed
+		}
+		if override.RequeueTransient != nil {
+			settings.requeueTransient = *override.RequeueTransient
+		}
+		settings.overrides = append(settings.overrides, override.String())
+	}
+	return settings
+}
+
+// withSettings returns a context carrying the settings of the instance.
+func withSettings(ctx context.Context, settings *instanceSettings) context.Context {
+	return context.WithValue(ctx, settingsKey{}, settings)
+}
+
+// settingsFrom returns the settings carried by ctx, or the run-level ones.
+func (pe *ParallelExecutor) settingsFrom(ctx context.Context) *instanceSettings {
+	if settings, ok := ctx.Value(settingsKey{}).(*instanceSettings); ok && settings != nil {
+		return settings
+	}
+	return pe.runSettings()
+}
@@ -0,0 +1,124 @@
+package executor
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/estudosdevops/opsmaster/internal/cloud"
+)
+
+func TestParseInstanceOverrides(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     any
+		want    int
+		wantErr string
+	}{
+		{name: "not configured", raw: nil},
+		{
+			name: "overrides",
+			raw: []any{
+				map[string]any{"column": "opsmaster_concurrency_class", "value": "slow",
+					"install_timeout": "60m", "requeue_transient": 0},
+				map[string]any{"column": "tier", "value": "db", "verify_grace_period": "0s", "requeue_failed": 1},
+			},
+			want: 2,
+		},
+		{name: "missing value", raw: []any{map[string]any{"column": "tier", "requeue_failed": 1}}, wantErr: "column and value are required"},
+		{name: "no settings", raw: []any{map[string]any{"column": "tier", "value": "db"}}, wantErr: "no settings to override"},
+		{name: "unknown setting", raw: []any{map[string]any{"column": "tier", "value": "db", "timeout": "5m"}}, wantErr: "field timeout not found"},
+		{name: "bad duration", raw: []any{map[string]any{"column": "tier", "value": "db", "install_timeout": "soon"}}, wantErr: "install_timeout"},
+		{name: "zero install timeout", raw: []any{map[string]any{"column": "tier", "value": "db", "install_timeout": "0s"}}, wantErr: "must be positive"},
+		{name: "negative requeue", raw: []any{map[string]any{"column": "tier", "value": "db", "requeue_failed": -1}}, wantErr: "requeue_failed"},
+		{name: "not a list", raw: map[string]any{"column": "tier"}, wantErr: "invalid instance_overrides"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			overrides, err := ParseInstanceOverrides(tt.raw)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(overrides) != tt.want {
+				t.Errorf("got %d overrides, want %d", len(overrides), tt.want)
+			}
+		})
+	}
+}
+
+func TestSettingsFor(t *testing.T) {
+	overrides, err := ParseInstanceOverrides([]any{
+		map[string]any{"column": "opsmaster_concurrency_class", "value": "slow",
+			"install_timeout": "60m", "verify_grace_period": "15m", "requeue_transient": 0},
+		map[string]any{"column": "Team", "value": "payments", "requeue_failed": 0, "verify_grace_period": "0s"},
+	})
+	if err != nil {
+		t.Fatalf("ParseInstanceOverrides() error = %v", err)
+	}
+	pe := &ParallelExecutor{overrides: overrides, verifyGracePeriod: 5 * time.Minute, requeueFailed: 1, requeueTransient: 2}
+
+	tests := []struct {
+		name     string
+		instance *cloud.Instance
+		info     *cloud.InstanceInfo
+		want     instanceSettings
+	}{
+		{
+			name:     "no match keeps run settings",
+			instance: &cloud.Instance{ID: "i-1", Metadata: map[string]string{"opsmaster_concurrency_class": "fast"}},
+			want:     instanceSettings{verifyGracePeriod: 5 * time.Minute, requeueFailed: 1, requeueTransient: 2},
+		},
+		{
+			name:     "CSV column",
+			instance: &cloud.Instance{ID: "i-2", Metadata: map[string]string{"opsmaster_concurrency_class": "slow"}},
+			want: instanceSettings{installTimeout: time.Hour, verifyGracePeriod: 15 * time.Minute, requeueFailed: 1,
+				overrides: []string{"opsmaster_concurrency_class=slow"}},
+		},
+		{
+			name:     "tag when the CSV lacks the column, later override wins",
+			instance: &cloud.Instance{ID: "i-3", Metadata: map[string]string{"opsmaster_concurrency_class": "slow"}},
+			info:     &cloud.InstanceInfo{Tags: map[string]string{"Team": "payments"}},
+			want: instanceSettings{installTimeout: time.Hour,
+				overrides: []string{"opsmaster_concurrency_class=slow", "Team=payments"}},
+		},
+		{
+			name:     "CSV column takes precedence over the tag",
+			instance: &cloud.Instance{ID: "i-4", Metadata: map[string]string{"team": "search"}},
+			info:     &cloud.InstanceInfo{Tags: map[string]string{"Team": "payments"}},
+			want:     instanceSettings{verifyGracePeriod: 5 * time.Minute, requeueFailed: 1, requeueTransient: 2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := pe.settingsFor(tt.instance, tt.info)
+			if got.installTimeout != tt.want.installTimeout || got.verifyGracePeriod != tt.want.verifyGracePeriod ||
+				got.requeueFailed != tt.want.requeueFailed || got.requeueTransient != tt.want.requeueTransient ||
+				!slices.Equal(got.overrides, tt.want.overrides) {
+				t.Errorf("settingsFor() = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSettingsFrom(t *testing.T) {
+	pe := &ParallelExecutor{verifyGracePeriod: time.Minute, requeueFailed: 3}
+
+	if got := pe.settingsFrom(context.Background()); got.verifyGracePeriod != time.Minute || got.requeueFailed != 3 {
+		t.Errorf("settingsFrom() without settings = %+v, want run settings", *got)
+	}
+
+	ctx := withSettings(context.Background(), &instanceSettings{installTimeout: time.Hour})
+	if got := pe.settingsFrom(ctx); got.installTimeout != time.Hour {
+		t.Errorf("settingsFrom() = %+v, want the instance settings", *got)
+	}
+}
@@ -4,35 +4,110 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"maps"
+	"math/rand"
+	"slices"
 	"sync"
 	"time"
 
 	"github.com/estudosdevops/opsmaster/internal/cloud"
+	"github.com/estudosdevops/opsmaster/internal/criteria"
 	"github.com/estudosdevops/opsmaster/internal/installer"
 	"github.com/estudosdevops/opsmaster/internal/logger"
+	"github.com/estudosdevops/opsmaster/internal/quarantine"
+)
+
+// defaultInstallTimeout is the generous timeout for installation scripts/steps.
+const defaultInstallTimeout = 30 * time.Minute
+
+// Verification retry delays within the grace period (doubling up to the max).
+const (
+	defaultVerifyRetryDelay = 10 * time.Second
+	maxVerifyRetryDelay     = time.Minute
 )
 
 // ParallelExecutor executes package installations across multiple instances concurrently.
-// Uses goroutines with semaphore pattern to limit concurrency and avoid overwhelming
-// cloud APIs or network resources.
+// Uses a pool of workers over a priority queue to limit concurrency and avoid
+// overwhelming cloud APIs or network resources.
 type ParallelExecutor struct {
-	provider       cloud.CloudProvider
-	installer      installer.PackageInstaller
-	maxConcurrency int
-	skipValidation bool
-	skipTagging    bool
-	dryRun         bool
-	log            *slog.Logger
+	provider           cloud.CloudProvider
+	installer          installer.PackageInstaller
+	caps               installer.Capabilities
+	providerCaps       cloud.Capabilities
+	maxConcurrency     int
+	maxPerGroup        int
+	firstRunStagger    time.Duration
+	verifyGracePeriod  time.Duration
+	verifyRetryDelay   time.Duration
+	requeueFailed      int
+	requeueTransient   int
+	forceDetect        bool
+	skipValidation     bool
+	agentWait          time.Duration
+	skipTagging        bool
+	trustedValidations map[string]time.Time
+	dryRun             bool
+	startStopped       bool
+	startTimeout       time.Duration
+	maintenanceTag     string
+	includeMaintenance bool
+	quarantine         *quarantine.List
+	runID              string
+	chaos              *ChaosConfig
+	heartbeat          *heartbeat
+	maxOutputBytes     int
+	order              string
+	orderSeed          int64
+	successWhen        *criteria.Expr
+	overrides          []InstanceOverride
+	control            *RunControl
+	autoScaling        string
+	skipDiagnostics    bool
+	onResult           func(*ExecutionResult)
+	log                *slog.Logger
 }
 
 // ExecutorConfig contains configuration for the parallel executor.
 type ExecutorConfig struct {
-	Provider       cloud.CloudProvider        // Cloud provider (AWS, Azure, GCP)
-	Installer      installer.PackageInstaller // Package installer (Puppet, Docker, etc)
-	MaxConcurrency int                        // Max simultaneous installations (default: 10)
-	SkipValidation bool                       // Skip prerequisite validations
-	SkipTagging    bool                       // Skip tagging after installation
-	DryRun         bool                       // Simulate without executing
+	Provider           cloud.CloudProvider        // Cloud provider (AWS, Azure, GCP)
+	Installer          installer.PackageInstaller // Package installer (Puppet, Docker, etc)
+	MaxConcurrency     int                        // Max simultaneous installations (default: 10)
+	MaxPerGroup        int                        // Max simultaneous installations per concurrency group, e.g. Puppet Server (0 = no limit)
+	FirstRunStagger    time.Duration              // Random delay (0..stagger) before each installation (0 = disabled)
+	VerifyGracePeriod  time.Duration              // Window in which failed verifications are retried with backoff (0 = no retry)
+	VerifyRetryDelay   time.Duration              // First delay between verification retries (default: 10s)
+	RequeueFailed      int                        // Times a failed instance is requeued in the run, after all first attempts (0 = disabled)
+	RequeueTransient   int                        // Times an instance failing with a transient error (SSM throttling, agent offline) is requeued (0 = disabled)
+	ForceDetect        bool                       // Detect the OS on instances even when the CSV os column is set
+	SkipValidation     bool                       // Skip prerequisite validations
+	AgentWait          time.Duration              // Max wait for the instance agent (SSM) to come online before validation, for just-launched instances (0 = fail at once)
+	SkipTagging        bool                       // Skip tagging after installation
+	TrustedValidations map[string]time.Time       // Instance ID -> when validation passed in a recent preflight (validation skipped)
+	DryRun             bool                       // Simulate without executing
+	StartStopped       bool                       // Start stopped instances before processing
+	StartTimeout       time.Duration              // Max wait for started instances to run (default: 5m)
+	MaintenanceTag     string                     // Tag key marking maintenance mode (default: opsmaster:maintenance)
+	IncludeMaintenance bool                       // Process instances in maintenance mode anyway
+	Quarantine         *quarantine.List           // Instances always skipped (default: quarantine.Default())
+	RunID              string                     // Invocation ID, recorded in results and the opsmaster:last_run_id tag
+	Chaos              *ChaosConfig               // Failure/latency injection for rehearsals (forces DryRun)
+	OnResult           func(*ExecutionResult)     // Called as each instance finishes, from a single goroutine (optional)
+	HeartbeatInterval  time.Duration              // Interval of liveness logs for in-flight instances (0 = disabled)
+	ExpectedDurations  map[string]time.Duration   // Phase -> expected max duration, longer phases are reported stuck (default per phase)
+	MaxOutputBytes     int                        // Max stdout/stderr bytes kept in the error context of failed scripts (0 = no limit)
+	Order              string                     // Dispatch order of the first attempts: as-is (default), sorted or shuffle
+	OrderSeed          int64                      // Seed of the shuffle order (0 = new seed every run, logged)
+	SuccessWhen        *criteria.Expr             // Success criteria deciding the final status of installed/failed instances (optional, see ParseSuccessCriteria)
+	Overrides          []InstanceOverride         // Per-instance settings selected by CSV column or tag (optional, see ParseInstanceOverrides)
+	Control            *RunControl                // Pause/resume/abort of the dispatch, e.g. from the control socket (optional)
+
+	// AutoScaling skips (AutoScalingSkip) or replaces (AutoScalingReplace)
+	// members of autoscaling groups being terminated or replaced ("" = off)
+	AutoScaling string
+
+	// SkipDiagnostics disables the diagnostic bundle collected from instances
+	// failing to install or verify (see ErrorContext.Diagnostics)
+	SkipDiagnostics bool
 }
 
 // NewParallelExecutor creates a new parallel executor with given configuration.
@@ -41,15 +116,68 @@ func NewParallelExecutor(config ExecutorConfig) *ParallelExecutor {
 	if config.MaxConcurrency <= 0 {
 		config.MaxConcurrency = 10
 	}
+	if config.StartTimeout <= 0 {
+		config.StartTimeout = defaultStartTimeout
+	}
+	if config.MaintenanceTag == "" {
+		config.MaintenanceTag = cloud.DefaultMaintenanceTagKey
+	}
+	if config.VerifyRetryDelay <= 0 {
+		config.VerifyRetryDelay = defaultVerifyRetryDelay
+	}
+	if config.Quarantine == nil {
+		config.Quarantine = quarantine.Default()
+	}
+	if _, ok := config.ExpectedDurations[PhaseValidate]; !ok && config.AgentWait > 0 {
+		// Waiting for the agent is part of the validation phase
+		expected := maps.Clone(config.ExpectedDurations)
+		if expected == nil {
+			expected = make(map[string]time.Duration, 1)
+		}
+		expected[PhaseValidate] = defaultExpectedPhaseDurations[PhaseValidate] + config.AgentWait
+		config.ExpectedDurations = expected
+	}
+	if config.Chaos != nil {
+		// Chaos rehearsals never touch real fleets
+		config.DryRun = true
+	}
 
 	return &ParallelExecutor{
-		provider:       config.Provider,
-		installer:      config.Installer,
-		maxConcurrency: config.MaxConcurrency,
-		skipValidation: config.SkipValidation,
-		skipTagging:    config.SkipTagging,
-		dryRun:         config.DryRun,
-		log:            logger.Get(),
+		provider:           config.Provider,
+		installer:          config.Installer,
+		caps:               installer.CapabilitiesOf(config.Installer),
+		providerCaps:       cloud.CapabilitiesOf(config.Provider),
+		maxConcurrency:     config.MaxConcurrency,
+		maxPerGroup:        config.MaxPerGroup,
+		firstRunStagger:    config.FirstRunStagger,
+		verifyGracePeriod:  config.VerifyGracePeriod,
+		verifyRetryDelay:   config.VerifyRetryDelay,
+		requeueFailed:      config.RequeueFailed,
+		requeueTransient:   config.RequeueTransient,
+		forceDetect:        config.ForceDetect,
+		skipValidation:     config.SkipValidation,
+		agentWait:          config.AgentWait,
+		skipTagging:        config.SkipTagging,
+		trustedValidations: config.TrustedValidations,
+		dryRun:             config.DryRun,
+		startStopped:       config.StartStopped,
+		startTimeout:       config.StartTimeout,
+		maintenanceTag:     config.MaintenanceTag,
+		includeMaintenance: config.IncludeMaintenance,
+		quarantine:         config.Quarantine,
+		runID:              config.RunID,
+		chaos:              config.Chaos,
+		heartbeat:          newHeartbeat(config.HeartbeatInterval, config.ExpectedDurations),
+		maxOutputBytes:     config.MaxOutputBytes,
+		order:              config.Order,
+		orderSeed:          config.OrderSeed,
+		successWhen:        config.SuccessWhen,
+		overrides:          config.Overrides,
+		control:            config.Control,
+		autoScaling:        config.AutoScaling,
+		skipDiagnostics:    config.SkipDiagnostics,
+		onResult:           config.OnResult,
+		log:                logger.Get(),
 	}
 }
 
@@ -57,11 +185,12 @@ func NewParallelExecutor(config ExecutorConfig) *ParallelExecutor {
 // Returns aggregated results with success/failure counts.
 //
 // Workflow:
-// 1. Create semaphore channel to limit concurrency
-// 2. Launch goroutine for each instance
-// 3. Each goroutine: validate -> install -> verify -> tag
-// 4. Collect all results
-// 5. Return aggregated result
+// 1. Pre-flight check (skip autoscaling members being replaced, quarantined, maintenance-mode and stopped/terminated instances)
+// 2. Queue the first attempt of each instance (see workQueue)
+// 3. Launch max-concurrency workers that pop the queue by priority
+// 4. Each worker: validate -> install -> verify -> tag (failures requeued after first attempts)
+// 5. Collect the final result of each instance
+// 6. Return aggregated result
 func (pe *ParallelExecutor) Execute(ctx context.Context, instances []*cloud.Instance) (*AggregatedResult, error) {
 	if len(instances) == 0 {
 		return nil, fmt.Errorf("no instances to process")
@@ -71,66 +200,97 @@ func (pe *ParallelExecutor) Execute(ctx context.Context, instances []*cloud.Inst
 		"total_instances", len(instances),
 		"max_concurrency", pe.maxConcurrency,
 		"package", pe.installer.Name(),
-		"cloud", pe.provider.Name())
+		"capabilities", pe.caps.Names(),
+		"cloud", pe.provider.Name(),
+		"provider_capabilities", pe.providerCaps.Names())
 
 	// Create aggregated result tracker
 	aggResult := NewAggregatedResult()
+	aggResult.RunID = pe.runID
+
+	// Pre-flight: skip (or replace) autoscaling members on their way out,
+	// quarantined, maintenance and stopped/terminated instances (or start
+	// them first)
+	instances, autoScalingResults := pe.checkAutoScaling(ctx, instances)
+	total := len(instances) + len(autoScalingResults)
+	instances, quarantinedResults := pe.skipQuarantined(instances)
+	instances, preflightResults, infos := pe.preflightStates(ctx, instances)
+	for _, result := range slices.Concat(autoScalingResults, quarantinedResults, preflightResults) {
+		aggResult.Add(result)
+		pe.notifyResult(result)
+	}
 
-	// Create semaphore channel to limit concurrency
-	// Buffer size = max concurrent goroutines
-	semaphore := make(chan struct{}, pe.maxConcurrency)
+	// Queue every instance for its first attempt; requeues of failed
+	// instances go behind all first attempts
+	queue := pe.newQueue(instances, infos)
+	pe.control.attach(queue, total, aggResult.Total)
 
-	// Create channel to collect results
+	// Create channel to collect results (one final result per instance)
 	results := make(chan *ExecutionResult, len(instances))
 
-	// WaitGroup to wait for all goroutines to complete
+	// Liveness logs of in-flight instances until all results are collected
+	stopHeartbeat := pe.heartbeat.start(ctx)
+	defer stopHeartbeat()
+
+	// WaitGroup to wait for all workers to complete
 	var wg sync.WaitGroup
 
-	// Launch goroutine for each instance
-	for _, instance := range instances {
+	// Launch workers (max concurrency) that process the queue by priority
+	for range min(pe.maxConcurrency, len(instances)) {
 		wg.Add(1)
-
-		go func(inst *cloud.Instance) {
+		go func() {
 			defer wg.Done()
-
-			// Acquire semaphore (blocks if max concurrency reached)
-			select {
-			case semaphore <- struct{}{}:
-				// Acquired semaphore, proceed
-				defer func() { <-semaphore }() // Release semaphore when done
-			case <-ctx.Done():
-				// Context canceled while waiting for semaphore
-				results <- &ExecutionResult{
-					Instance:  inst,
-					Status:    StatusCancelled,
-					StartTime: time.Now(),
-					EndTime:   time.Now(),
+			for {
+				item, ok := queue.pop(ctx)
+				if !ok {
+					return
 				}
-				return
+				result := pe.processInstance(withSettings(ctx, item.settings), item.instance)
+				result.Attempts, result.History = item.attempt, item.history
+				if requeue := pe.requeue(ctx, item, result); requeue != nil {
+					queue.done(item, requeue)
+					last := requeue.history[len(requeue.history)-1]
+					pe.log.Warn("Requeuing failed instance",
+						"instance_id", item.instance.ID,
+						"attempt", item.attempt,
+						"error", result.GetError(),
+						"transient", last.TransientReason,
+						"queued", queue.len())
+					continue
+				}
+				queue.done(item, nil)
+				pe.applySuccessCriteria(result)
+				results <- result
 			}
-
-			// Process instance
-			result := pe.processInstance(ctx, inst)
-			results <- result
-		}(instance)
+		}()
 	}
 
-	// Close results channel when all goroutines complete
+	// Close results channel when all workers complete; instances still
+	// queued were canceled before processing
 	go func() {
 		wg.Wait()
+		for _, item := range queue.remaining() {
+			result := cancelledResult(item.instance)
+			result.Attempts, result.History = item.attempt-1, item.history
+			results <- result
+		}
 		close(results)
 	}()
 
 	// Collect results
 	for result := range results {
 		aggResult.Add(result)
+		pe.control.observe()
 
 		// Log progress
 		pe.log.Info("Instance processed",
 			"instance_id", result.Instance.ID,
 			"status", result.Status,
 			"duration", result.Duration,
-			"progress", fmt.Sprintf("%d/%d", aggResult.Total, len(instances)))
+			"progress", fmt.Sprintf("%d/%d", aggResult.Total, total),
+			"queued", queue.len())
+
+		pe.notifyResult(result)
 	}
 
 	// Finalize aggregated result
@@ -146,25 +306,157 @@ func (pe *ParallelExecutor) Execute(ctx context.Context, instances []*cloud.Inst
 	return aggResult, nil
 }
 
+// notifyResult passes a finished result to the OnResult callback, if any.
+func (pe *ParallelExecutor) notifyResult(result *ExecutionResult) {
+	if pe.onResult != nil {
+		pe.onResult(result)
+	}
+}
+
+// newQueue queues the first attempt of every instance in the configured
+// order (see orderInstances), with its settings (see settingsFor). When a
+// per-group limit is configured and the installer groups instances, each
+// item carries its concurrency group (e.g., Puppet Server) and the queue
+// enforces the limit.
+func (pe *ParallelExecutor) newQueue(instances []*cloud.Instance, infos map[string]*cloud.InstanceInfo) *workQueue {
+	grouped := pe.maxPerGroup > 0 && pe.caps.Grouping != nil
+	maxPerGroup := 0
+	if grouped {
+		maxPerGroup = pe.maxPerGroup
+	}
+	queue := newWorkQueue(maxPerGroup)
+	groups := make(map[string]bool)
+
+	instances, seed := orderInstances(instances, pe.order, pe.orderSeed)
+	if pe.order == OrderShuffle {
+		// Logged so a run can be replayed with the same order (--order-seed)
+		pe.log.Info("Dispatching instances in shuffled order", "seed", seed)
+	} else if pe.order == OrderSorted {
+		pe.log.Info("Dispatching instances sorted by account, region and instance ID")
+	}
+
+	overridden := make(map[string]int)
+	for _, instance := range instances {
+		item := &workItem{instance: instance, attempt: 1, settings: pe.settingsFor(instance, infos[instance.ID])}
+		for _, override := range item.settings.overrides {
+			overridden[override]++
+		}
+		if grouped {
+			item.group = pe.caps.Grouping.ConcurrencyGroup(instance)
+			groups[item.group] = true
+		}
+		queue.push(item)
+	}
+
+	if len(overridden) > 0 {
+		pe.log.Info("Instance overrides applied", "instances_per_override", overridden)
+	}
+	if grouped {
+		pe.log.Info("Per-group concurrency limit enabled",
+			"groups", len(groups),
+			"max_per_group", pe.maxPerGroup)
+	}
+	return queue
+}
+
+// requeue returns the next attempt of a failed instance, carrying the failed
+// attempt in its history, or nil when the result is final (success, skipped,
+// canceled or out of attempts). Transient failures (see transientReason) may
+// be requeued up to RequeueTransient times, any failure up to RequeueFailed.
+func (pe *ParallelExecutor) requeue(ctx context.Context, item *workItem, result *ExecutionResult) *workItem {
+	if result.Status != StatusFailed || ctx.Err() != nil {
+		return nil
+	}
+	transient := transientReason(result.GetError())
+	limit := item.settings.requeueFailed
+	if transient != "" {
+		limit = max(limit, item.settings.requeueTransient)
+	}
+	if item.attempt > limit {
+		return nil
+	}
+
+	history := append(slices.Clone(item.history), AttemptRecord{
+		Attempt:         item.attempt,
+		Status:          result.Status,
+		Err:             result.GetError(),
+		FailurePhase:    result.FailurePhase,
+		TransientReason: transient,
+		StartTime:       result.StartTime,
+		Duration:        result.Duration,
+	})
+	return &workItem{instance: item.instance, attempt: item.attempt + 1, group: item.group, settings: item.settings, history: history}
+}
+
+// acquire takes a semaphore slot, returning false if ctx is canceled first.
+func acquire(ctx context.Context, semaphore chan struct{}) bool {
+	select {
+	case semaphore <- struct{}{}:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// cancelledResult builds the result for an instance canceled before processing.
+func cancelledResult(instance *cloud.Instance) *ExecutionResult {
+	return &ExecutionResult{
+		Instance:  instance,
+		Status:    StatusCancelled,
+		StartTime: time.Now(),
+		EndTime:   time.Now(),
+	}
+}
+
+// stagger waits a random delay (0..firstRunStagger) so first runs of many
+// instances don't hit the backend (e.g., Puppet CA) at the same moment.
+// Returns ctx error if canceled while waiting.
+func (pe *ParallelExecutor) stagger(ctx context.Context, instance *cloud.Instance) error {
+	if pe.firstRunStagger <= 0 || pe.dryRun {
+		return nil
+	}
+
+	// #nosec G404 - Using math/rand for jitter is acceptable (not cryptographic)
+	delay := time.Duration(rand.Int63n(int64(pe.firstRunStagger)))
+	logger.FromContext(ctx).Debug("Staggering installation", "delay", delay.Round(time.Millisecond))
+
+	select {
+	case <-time.After(delay):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
 // validateInstanceAndPrereqs validates instance accessibility and prerequisites.
 // Returns error if validation fails, nil on success.
 func (pe *ParallelExecutor) validateInstanceAndPrereqs(ctx context.Context, instance *cloud.Instance) error {
+	log := logger.FromContext(ctx)
+	// Wait for the agent of just-launched instances to register
+	if pe.agentWait > 0 {
+		if waiter := pe.providerCaps.Agent; waiter != nil {
+			log.Debug("Waiting for instance agent", "timeout", pe.agentWait)
+			if err := waiter.WaitForAgent(ctx, instance, pe.agentWait); err != nil {
+				log.Error("Instance agent not online", "error", err)
+				return fmt.Errorf("instance validation failed: %w", err)
+			}
+		} else {
+			log.Debug("Provider cannot wait for the instance agent, validating at once", "provider", pe.provider.Name())
+		}
+	}
+
 	// Validate instance accessibility
-	pe.log.Debug("Validating instance", "instance_id", instance.ID)
+	log.Debug("Validating instance")
 	if err := pe.provider.ValidateInstance(ctx, instance); err != nil {
-		pe.log.Error("Instance validation failed",
-			"instance_id", instance.ID,
-			"error", err)
+		log.Error("Instance validation failed", "error", err)
 		return fmt.Errorf("instance validation failed: %w", err)
 	}
 
 	// Validate prerequisites (unless skipped)
 	if !pe.skipValidation {
-		pe.log.Debug("Validating prerequisites", "instance_id", instance.ID)
-		if err := pe.installer.ValidatePrerequisites(ctx, instance, pe.provider); err != nil {
-			pe.log.Error("Prerequisite validation failed",
-				"instance_id", instance.ID,
-				"error", err)
+		log.Debug("Validating prerequisites")
+		if err := pe.installer.ValidatePrerequisites(cloud.ReadOnlyCommands(ctx), instance, pe.provider); err != nil {
+			log.Error("Prerequisite validation failed", "error", err)
 			return fmt.Errorf("prerequisite validation failed: %w", err)
 		}
 	}
@@ -174,25 +466,20 @@ func (pe *ParallelExecutor) validateInstanceAndPrereqs(ctx context.Context, inst
 
 // executeInstallation performs package installation or dry-run simulation.
 // Returns (metadata, error). Metadata contains installation details, error if installation fails.
-func (pe *ParallelExecutor) executeInstallation(ctx context.Context, instance *cloud.Instance) (map[string]string, error) {
+func (pe *ParallelExecutor) executeInstallation(ctx context.Context, instance *cloud.Instance) (*installer.InstallMetadata, error) {
+	log := logger.FromContext(ctx)
 	// Dry run mode - simulate installation
 	if pe.dryRun {
-		pe.log.Info("DRY RUN: Would install package",
-			"instance_id", instance.ID,
-			"package", pe.installer.Name())
+		log.Info("DRY RUN: Would install package", "package", pe.installer.Name())
 		return nil, nil
 	}
 
 	// Actual installation
-	pe.log.Info("Installing package",
-		"instance_id", instance.ID,
-		"package", pe.installer.Name())
+	log.Info("Installing package", "package", pe.installer.Name())
 
 	metadata, err := pe.installPackage(ctx, instance)
 	if err != nil {
-		pe.log.Error("Installation failed",
-			"instance_id", instance.ID,
-			"error", err)
+		log.Error("Installation failed", "error", err)
 		return metadata, fmt.Errorf("installation failed: %w", err)
 	}
 
@@ -202,25 +489,103 @@ func (pe *ParallelExecutor) executeInstallation(ctx context.Context, instance *c
 // verifyAndTag verifies installation and tags instance with success.
 // Returns verification error if any, tagging errors are logged but not returned.
 func (pe *ParallelExecutor) verifyAndTag(ctx context.Context, instance *cloud.Instance, result *ExecutionResult) error {
-	// Verify installation
-	pe.log.Debug("Verifying installation", "instance_id", instance.ID)
-	if err := pe.installer.VerifyInstallation(ctx, instance, pe.provider); err != nil {
-		pe.log.Error("Installation verification failed",
-			"instance_id", instance.ID,
-			"error", err)
-		return fmt.Errorf("installation verification failed: %w", err)
+	log := logger.FromContext(ctx)
+	enterPhase(ctx, PhaseVerify)
+	phaseStart := time.Now()
+	err := pe.verifyWithGrace(ctx, instance, result)
+	result.trackPhase(PhaseVerify, phaseStart)
+	if err != nil {
+		return err
 	}
 
 	// Tag instance with success (unless skipped)
 	if !pe.skipTagging {
-		pe.log.Debug("Tagging instance", "instance_id", instance.ID)
-		tags := pe.installer.GetSuccessTags()
-		if err := pe.provider.TagInstance(ctx, instance, tags); err != nil {
+		log.Debug("Tagging instance")
+		enterPhase(ctx, PhaseTag)
+		phaseStart = time.Now()
+		if err := pe.provider.TagInstance(ctx, instance, pe.successTags()); err != nil {
 			// Log warning but don't fail the installation
 			result.TaggingErr = err
-			pe.log.Warn("Failed to tag instance, but installation succeeded",
-				"instance_id", instance.ID,
-				"error", err)
+			log.Warn("Failed to tag instance, but installation succeeded", "error", err)
+		}
+		result.trackPhase(PhaseTag, phaseStart)
+	}
+
+	// Notify external systems (installers with PostInstall capability)
+	if pe.caps.PostInstall != nil {
+		enterPhase(ctx, PhasePostInstall)
+		phaseStart = time.Now()
+		if err := pe.caps.PostInstall.AfterInstall(ctx, instance, result.Metadata); err != nil {
+			// Log warning but don't fail the installation
+			result.PostInstallErr = err
+			log.Warn("Post-install hook failed, but installation succeeded", "error", err)
+		}
+		result.trackPhase(PhasePostInstall, phaseStart)
+	}
+
+	return nil
+}
+
+// verifyWithGrace runs verifyInstallation, retrying failures with backoff
+// during the grace period: right after the first run the agent may still be
+// converging (e.g., service not active yet). A verification that only passes
+// on a retry is recorded as verified late in the result metadata.
+func (pe *ParallelExecutor) verifyWithGrace(ctx context.Context, instance *cloud.Instance, result *ExecutionResult) error {
+	gracePeriod := pe.settingsFrom(ctx).verifyGracePeriod
+	err := pe.verifyInstallation(ctx, instance)
+	if err == nil || gracePeriod <= 0 {
+		return err
+	}
+
+	log := logger.FromContext(ctx)
+	start := time.Now()
+	deadline := start.Add(gracePeriod)
+	delay := pe.verifyRetryDelay
+	for attempt := 2; ; attempt++ {
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return fmt.Errorf("%w (still failing after %s grace period)", err, gracePeriod)
+		}
+		wait := min(delay, remaining)
+		log.Info("Verification failed, retrying within grace period",
+			"attempt", attempt,
+			"wait", wait.Round(time.Millisecond),
+			"remaining", remaining.Round(time.Second))
+
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return err
+		}
+
+		if err = pe.verifyInstallation(ctx, instance); err == nil {
+			late := time.Since(start).Round(time.Second)
+			log.Info("Installation verified late", "after", late, "attempts", attempt)
+			if result.Metadata == nil {
+				result.Metadata = &installer.InstallMetadata{}
+			}
+			result.Metadata.Set(installer.MetadataKeyVerifiedLate, late.String())
+			return nil
+		}
+		delay = min(delay*2, maxVerifyRetryDelay)
+	}
+}
+
+// verifyInstallation checks the installation and, for installers with the
+// VerifiesFacts capability, the facts.
+func (pe *ParallelExecutor) verifyInstallation(ctx context.Context, instance *cloud.Instance) error {
+	log := logger.FromContext(ctx)
+	log.Debug("Verifying installation")
+	if err := pe.installer.VerifyInstallation(cloud.ReadOnlyCommands(ctx), instance, pe.provider); err != nil {
+		log.Error("Installation verification failed", "error", err)
+		return fmt.Errorf("installation verification failed: %w", err)
+	}
+
+	if pe.caps.VerifiesFacts != nil {
+		log.Debug("Verifying facts")
+		if err := pe.caps.VerifiesFacts.VerifyFacts(cloud.ReadOnlyCommands(ctx), instance, pe.provider); err != nil {
+			log.Error("Fact verification failed", "error", err)
+			return fmt.Errorf("fact verification failed: %w", err)
 		}
 	}
 
@@ -229,7 +594,11 @@ func (pe *ParallelExecutor) verifyAndTag(ctx context.Context, instance *cloud.In
 
 // finalizeResult updates execution result with final status, timing and error.
 // Automatically classifies error type based on current result state.
-func (*ParallelExecutor) finalizeResult(result *ExecutionResult, status ExecutionStatus, err error) {
+func (pe *ParallelExecutor) finalizeResult(result *ExecutionResult, status ExecutionStatus, err error) {
+	if status == StatusFailed && instanceDisappeared(err) {
+		status = StatusDisappeared
+		pe.log.Warn("Instance disappeared mid-run", "instance_id", result.Instance.ID, "error", err)
+	}
 	result.Status = status
 	result.EndTime = time.Now()
 	result.Duration = result.EndTime.Sub(result.StartTime)
@@ -243,23 +612,47 @@ func (*ParallelExecutor) finalizeResult(result *ExecutionResult, status Executio
 			// Error happened during validation
 			result.ValidationErr = err
 		}
+		if status == StatusFailed {
+			result.classifyFailure(err)
+			result.ErrorContext = newErrorContext(err, pe.maxOutputBytes)
+		}
 	}
 }
 
+// observeOutputs returns a context in which the provider's output locations
+// are recorded in result.OutputURLs.
+func observeOutputs(ctx context.Context, result *ExecutionResult) context.Context {
+	var mu sync.Mutex
+	return cloud.WithOutputObserver(ctx, func(location string) {
+		mu.Lock()
+		defer mu.Unlock()
+		result.OutputURLs = append(result.OutputURLs, location)
+	})
+}
+
 // processInstance processes a single instance through the complete workflow.
 // Workflow: validate -> install -> verify -> tag
 func (pe *ParallelExecutor) processInstance(ctx context.Context, instance *cloud.Instance) *ExecutionResult {
+	// Everything called with ctx (provider, installer) logs the instance fields
+	ctx = withInstanceLogger(ctx, pe.log, instance)
+	if pe.forceDetect {
+		ctx = installer.WithForceDetect(ctx)
+	}
+	log := logger.FromContext(ctx)
+
 	result := &ExecutionResult{
 		Instance:  instance,
 		Status:    StatusRunning,
 		StartTime: time.Now(),
 	}
+	ctx, live := pe.heartbeat.track(ctx, result.StartTime)
+	defer pe.heartbeat.finish(live, result)
+	ctx = observeOutputs(ctx, result)
 
-	pe.log.Info("Processing instance",
-		"instance_id", instance.ID,
-		"cloud", instance.Cloud,
-		"account", instance.Account,
-		"region", instance.Region)
+	log.Info("Processing instance", "cloud", instance.Cloud)
+	if overrides := pe.settingsFrom(ctx).overrides; len(overrides) > 0 {
+		log.Debug("Processing with instance overrides", "overrides", overrides)
+	}
 
 	// Check if context already canceled
 	select {
@@ -269,22 +662,56 @@ func (pe *ParallelExecutor) processInstance(ctx context.Context, instance *cloud
 	default:
 	}
 
-	// STEP 1-2: Validate instance and prerequisites
-	if err := pe.validateInstanceAndPrereqs(ctx, instance); err != nil {
-		pe.finalizeResult(result, StatusFailed, err)
-		if !pe.skipTagging && !pe.dryRun {
-			pe.tagFailure(ctx, instance, err)
+	// STEP 1-2: Validate instance and prerequisites (unless a recent preflight did)
+	if validatedAt, ok := pe.trustedValidations[instance.ID]; ok {
+		log.Debug("Reusing preflight validation", "validated_at", validatedAt)
+		result.ValidatedAt = validatedAt
+	} else {
+		enterPhase(ctx, PhaseValidate)
+		phaseStart := time.Now()
+		err := pe.validateInstanceAndPrereqs(ctx, instance)
+		result.trackPhase(PhaseValidate, phaseStart)
+		if err != nil {
+			pe.finalizeResult(result, StatusFailed, err)
+			if !pe.skipTagging && !pe.dryRun {
+				pe.tagFailure(ctx, instance, result)
+			}
+			return result
+		}
+		if !pe.skipValidation {
+			result.ValidatedAt = time.Now()
 		}
-		return result
 	}
 
-	// STEP 3: Install package (or dry-run)
+	// STEP 3: Install package (or dry-run), staggered to smooth backend load
+	if pe.firstRunStagger > 0 && !pe.dryRun {
+		enterPhase(ctx, PhaseStagger)
+		phaseStart := time.Now()
+		err := pe.stagger(ctx, instance)
+		result.trackPhase(PhaseStagger, phaseStart)
+		if err != nil {
+			pe.finalizeResult(result, StatusCancelled, err)
+			return result
+		}
+	}
+	enterPhase(ctx, PhaseInstall)
+	phaseStart := time.Now()
+	if err := pe.injectChaos(ctx, instance); err != nil {
+		status := StatusFailed
+		if ctx.Err() != nil {
+			status = StatusCancelled
+		}
+		pe.finalizeResult(result, status, err)
+		return result
+	}
 	metadata, err := pe.executeInstallation(ctx, instance)
+	result.trackPhase(PhaseInstall, phaseStart)
 	if err != nil {
 		pe.finalizeResult(result, StatusFailed, err)
 		result.Metadata = metadata
+		pe.collectDiagnostics(ctx, result)
 		if !pe.skipTagging {
-			pe.tagFailure(ctx, instance, err)
+			pe.tagFailure(ctx, instance, result)
 		}
 		return result
 	}
@@ -301,8 +728,9 @@ func (pe *ParallelExecutor) processInstance(ctx context.Context, instance *cloud
 	// STEP 4-5: Verify installation and tag success
 	if err := pe.verifyAndTag(ctx, instance, result); err != nil {
 		pe.finalizeResult(result, StatusFailed, err)
+		pe.collectDiagnostics(ctx, result)
 		if !pe.skipTagging {
-			pe.tagFailure(ctx, instance, err)
+			pe.tagFailure(ctx, instance, result)
 		}
 		return result
 	}
@@ -310,110 +738,169 @@ func (pe *ParallelExecutor) processInstance(ctx context.Context, instance *cloud
 	// STEP 6: Finalize with success (metadata already captured)
 	pe.finalizeResult(result, StatusSuccess, nil)
 
-	pe.log.Info("Instance processed successfully",
-		"instance_id", instance.ID,
-		"duration", result.Duration)
+	log.Info("Instance processed successfully", "duration", result.Duration)
 
 	return result
 }
 
 // installPackage performs the actual package installation.
 // Returns metadata from installation and error if installation fails.
-func (pe *ParallelExecutor) installPackage(ctx context.Context, instance *cloud.Instance) (map[string]string, error) {
-	var commands []string
-	var metadata map[string]string
-	var err error
-
-	// Check if installer supports auto-detection (e.g., PuppetInstaller)
-	// Use type assertion to access GenerateInstallScriptWithAutoDetect if available
-	type autoDetectInstaller interface {
-		GenerateInstallScriptWithAutoDetect(ctx context.Context, instance *cloud.Instance, provider cloud.CloudProvider, options map[string]string) ([]string, map[string]string, error)
-	}
-
-	if autoDetect, ok := pe.installer.(autoDetectInstaller); ok {
-		if pe.dryRun {
-			// DRY-RUN MODE: Skip OS detection (remote command execution)
-			// Use metadata from CSV or fallback to default
-			osType := instance.Metadata["os"]
-			if osType == "" {
-				osType = "ubuntu" // Default fallback
-				pe.log.Warn("Dry-run: OS not specified in CSV, assuming Ubuntu",
-					"instance_id", instance.ID,
-					"tip", "Add 'os' column to CSV for accurate dry-run preview")
-			} else {
-				pe.log.Info("Dry-run: Using OS from CSV metadata",
-					"instance_id", instance.ID,
-					"os", osType)
-			}
+// The flow depends on installer capabilities (see installer.Capabilities).
+func (pe *ParallelExecutor) installPackage(ctx context.Context, instance *cloud.Instance) (*installer.InstallMetadata, error) {
+	log := logger.FromContext(ctx)
+	// Installer drives the installation itself
+	if pe.caps.LocalInstall != nil && !pe.dryRun {
+		log.Info("Installing package with installer-managed flow", "package", pe.installer.Name())
+		return pe.caps.LocalInstall.InstallLocal(ctx, instance, pe.provider)
+	}
 
-			// Generate script WITHOUT remote detection
-			commands, err = pe.installer.GenerateInstallScript(osType, map[string]string{})
-			if err != nil {
-				return nil, fmt.Errorf("failed to generate install script: %w", err)
-			}
+	steps, metadata, err := pe.generateInstallSteps(ctx, instance)
+	if err != nil {
+		return nil, err
+	}
 
-			pe.log.Info("Dry-run: Installation script generated",
-				"instance_id", instance.ID,
-				"os", osType,
-				"script_lines", len(commands))
-		} else {
-			// REAL EXECUTION: Use auto-detection
-			pe.log.Info("Detecting OS for installation", "instance_id", instance.ID)
-			commands, metadata, err = autoDetect.GenerateInstallScriptWithAutoDetect(ctx, instance, pe.provider, map[string]string{})
-			if err != nil {
-				return nil, fmt.Errorf("failed to generate install script with auto-detect: %w", err)
-			}
+	// DRY-RUN MODE: Skip actual execution
+	if pe.dryRun {
+		log.Info("Dry-run: Skipping installation execution",
+			"package", pe.installer.Name(),
+			"steps", len(steps))
+		return metadata, nil
+	}
+
+	// REAL EXECUTION: Execute installation commands
+	log.Info("Installing package", "package", pe.installer.Name())
+
+	for _, step := range steps {
+		if err := pe.executeInstallStep(ctx, instance, step); err != nil {
+			return metadata, err
 		}
-	} else {
-		// Fallback: installer doesn't support auto-detection
-		osType := instance.Metadata["os"]
-		if osType == "" {
-			osType = "ubuntu" // Default
+	}
+
+	return metadata, nil
+}
+
+// generateInstallSteps builds the installation steps for an instance.
+// Installers without StepBased capability produce a single unnamed step.
+func (pe *ParallelExecutor) generateInstallSteps(ctx context.Context, instance *cloud.Instance) ([]installer.InstallStep, *installer.InstallMetadata, error) {
+	log := logger.FromContext(ctx)
+	// REAL EXECUTION with auto-detection (e.g., PuppetInstaller)
+	if pe.caps.AutoDetect != nil && !pe.dryRun {
+		log.Info("Detecting OS for installation")
+		commands, metadata, err := pe.caps.AutoDetect.GenerateInstallScriptWithAutoDetect(ctx, instance, pe.provider, map[string]string{})
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to generate install script with auto-detect: %w", err)
 		}
+		return []installer.InstallStep{{Commands: commands}}, metadata, nil
+	}
 
-		// Generate installation script
-		commands, err = pe.installer.GenerateInstallScript(osType, map[string]string{})
+	// Use OS from CSV metadata (no remote detection)
+	osType := instance.Metadata["os"]
+	if osType == "" {
+		osType = "ubuntu" // Default fallback
+		if pe.dryRun && pe.caps.AutoDetect != nil {
+			log.Warn("Dry-run: OS not specified in CSV, assuming Ubuntu", "tip", "Add 'os' column to CSV for accurate dry-run preview")
+		}
+	} else if pe.dryRun {
+		log.Info("Dry-run: Using OS from CSV metadata", "os", osType)
+	}
+
+	var steps []installer.InstallStep
+	if pe.caps.StepBased != nil {
+		var err error
+		steps, err = pe.caps.StepBased.GenerateInstallSteps(osType, map[string]string{})
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to generate install steps: %w", err)
+		}
+	} else {
+		commands, err := pe.installer.GenerateInstallScript(osType, map[string]string{})
 		if err != nil {
-			return nil, fmt.Errorf("failed to generate install script: %w", err)
+			return nil, nil, fmt.Errorf("failed to generate install script: %w", err)
 		}
+		steps = []installer.InstallStep{{Commands: commands}}
 	}
 
-	// DRY-RUN MODE: Skip actual execution
 	if pe.dryRun {
-		pe.log.Info("Dry-run: Skipping installation execution",
-			"instance_id", instance.ID,
-			"package", pe.installer.Name())
-		return metadata, nil
+		log.Info("Dry-run: Installation script generated",
+			"os", osType,
+			"steps", len(steps))
 	}
 
-	// REAL EXECUTION: Execute installation commands
-	pe.log.Info("Installing package",
-		"instance_id", instance.ID,
-		"package", pe.installer.Name())
+	return steps, nil, nil
+}
+
+// executeInstallStep runs one installation step on the instance.
+// Unnamed steps (single-script installers) keep the classic error format.
+// An instance override of the install timeout replaces the step timeout.
+func (pe *ParallelExecutor) executeInstallStep(ctx context.Context, instance *cloud.Instance, step installer.InstallStep) error {
+	timeout := step.Timeout
+	if override := pe.settingsFrom(ctx).installTimeout; override > 0 {
+		timeout = override
+	}
+	if timeout <= 0 {
+		timeout = defaultInstallTimeout
+	}
+
+	if step.Name != "" {
+		logger.FromContext(ctx).Info("Running install step", "step", step.Name)
+	}
+
+	comment := "install"
+	if step.Name != "" {
+		comment = "install step " + step.Name
+	}
 
-	// Set generous timeout for installation (30 minutes)
-	installTimeout := 30 * time.Minute
-	result, err := pe.provider.ExecuteCommand(ctx, instance, commands, installTimeout)
+	result, err := pe.provider.ExecuteCommandWithOptions(ctx, instance, step.Commands, timeout,
+		cloud.ExecOptions{Env: step.Env, SecretEnv: step.SecretEnv, Comment: comment})
 	if err != nil {
-		return metadata, fmt.Errorf("failed to execute install commands: %w", err)
+		if step.Name != "" {
+			return fmt.Errorf("failed to execute install step %q: %w", step.Name, err)
+		}
+		return fmt.Errorf("failed to execute install commands: %w", err)
 	}
 
-	// Check if command succeeded
+	// Check if command succeeded (exit code classified in the result, see installer.ScriptError)
 	if result.ExitCode != 0 {
-		return metadata, fmt.Errorf("installation script failed with exit code %d:\nstdout: %s\nstderr: %s",
-			result.ExitCode, result.Stdout, result.Stderr)
+		return &installer.ScriptError{Step: step.Name, ExitCode: result.ExitCode, Stdout: result.Stdout, Stderr: result.Stderr}
 	}
 
-	return metadata, nil
+	return nil
 }
 
-// tagFailure applies failure tags to instance.
+// tagFailure applies failure tags to the instance of a failed result.
 // Doesn't fail the operation if tagging fails - just logs warning.
-func (pe *ParallelExecutor) tagFailure(ctx context.Context, instance *cloud.Instance, err error) {
-	tags := pe.installer.GetFailureTags(err)
-	if tagErr := pe.provider.TagInstance(ctx, instance, tags); tagErr != nil {
-		pe.log.Warn("Failed to tag instance with failure status",
-			"instance_id", instance.ID,
-			"error", tagErr)
+func (pe *ParallelExecutor) tagFailure(ctx context.Context, instance *cloud.Instance, result *ExecutionResult) {
+	// Nothing left to tag when the instance disappeared
+	if pe.dryRun || result.Status == StatusDisappeared {
+		return
+	}
+	if tagErr := pe.provider.TagInstance(ctx, instance, pe.failureTags(result)); tagErr != nil {
+		logger.FromContext(ctx).Warn("Failed to tag instance with failure status", "error", tagErr)
+	}
+}
+
+// successTags returns the installer success tags plus the run tags.
+func (pe *ParallelExecutor) successTags() map[string]string {
+	return pe.withRunTags(pe.installer.GetSuccessTags(), "success")
+}
+
+// failureTags returns the installer failure tags plus the run tags, with the
+// failure phase in the status tag so remediation can target instances that
+// failed at a specific phase (e.g., opsmaster:puppet_status=failed-verify).
+func (pe *ParallelExecutor) failureTags(result *ExecutionResult) map[string]string {
+	return pe.withRunTags(pe.installer.GetFailureTags(result.GetError()), "failed-"+result.FailurePhase)
+}
+
+// withRunTags adds the opsmaster:<package>_status tag and, when a run ID is
+// set, the opsmaster:last_run_id tag.
+// Returns a copy - installers may return shared maps.
+func (pe *ParallelExecutor) withRunTags(tags map[string]string, status string) map[string]string {
+	merged := make(map[string]string, len(tags)+2)
+	for key, value := range tags {
+		merged[key] = value
+	}
+	merged[cloud.StatusTagKey(pe.installer.Name())] = status
+	if pe.runID != "" {
+		merged[cloud.RunIDTagKey] = pe.runID
 	}
+	return merged
 }
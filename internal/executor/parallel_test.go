@@ -2,110 +2,35 @@ package executor
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"reflect"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/estudosdevops/opsmaster/internal/cloud"
+	"github.com/estudosdevops/opsmaster/internal/cloud/cloudtest"
+	"github.com/estudosdevops/opsmaster/internal/installer"
+	"github.com/estudosdevops/opsmaster/internal/stresstest"
 )
 
 // ============================================================
 // MOCKS - External dependency simulation
+// (cloud providers: cloudtest.Provider)
 // ============================================================
 
-// mockCloudProvider simulates a cloud provider for testing.
-//
-// 🎓 CONCEPT: Mock with thread-safe counters
-// We use atomic.Int32 to count calls safely in goroutines.
-type mockCloudProvider struct {
-	name                  string
-	executeCommandFunc    func(ctx context.Context, instance *cloud.Instance, commands []string, timeout time.Duration) (*cloud.CommandResult, error)
-	validateInstanceFunc  func(ctx context.Context, instance *cloud.Instance) error
-	testConnectivityFunc  func(ctx context.Context, instance *cloud.Instance, host string, port int) error
-	tagInstanceFunc       func(ctx context.Context, instance *cloud.Instance, tags map[string]string) error
-	hasTagFunc            func(ctx context.Context, instance *cloud.Instance, key, value string) (bool, error)
-	executeCommandCount   atomic.Int32 // Thread-safe counter
-	validateInstanceCount atomic.Int32
-	testConnectivityCount atomic.Int32
-	tagInstanceCount      atomic.Int32
-}
-
-func (m *mockCloudProvider) Name() string {
-	if m.name != "" {
-		return m.name
-	}
-	return "mock"
-}
-
-func (m *mockCloudProvider) ExecuteCommand(ctx context.Context, instance *cloud.Instance, commands []string, timeout time.Duration) (*cloud.CommandResult, error) {
-	m.executeCommandCount.Add(1)
-	if m.executeCommandFunc != nil {
-		return m.executeCommandFunc(ctx, instance, commands, timeout)
-	}
-	return &cloud.CommandResult{
-		Stdout:   "command output",
-		ExitCode: 0,
-	}, nil
-}
-
-func (m *mockCloudProvider) ValidateInstance(ctx context.Context, instance *cloud.Instance) error {
-	m.validateInstanceCount.Add(1)
-	if m.validateInstanceFunc != nil {
-		return m.validateInstanceFunc(ctx, instance)
-	}
-	return nil
-}
-
-func (m *mockCloudProvider) TestConnectivity(ctx context.Context, instance *cloud.Instance, host string, port int) error {
-	m.testConnectivityCount.Add(1)
-	if m.testConnectivityFunc != nil {
-		return m.testConnectivityFunc(ctx, instance, host, port)
-	}
-	return nil
-}
-
-func (m *mockCloudProvider) TagInstance(ctx context.Context, instance *cloud.Instance, tags map[string]string) error {
-	m.tagInstanceCount.Add(1)
-	if m.tagInstanceFunc != nil {
-		return m.tagInstanceFunc(ctx, instance, tags)
-	}
-	return nil
-}
-
-func (m *mockCloudProvider) HasTag(ctx context.Context, instance *cloud.Instance, key, value string) (bool, error) {
-	if m.hasTagFunc != nil {
-		return m.hasTagFunc(ctx, instance, key, value)
-	}
-	return false, nil
-}
-
-// GetExecuteCommandCount returns the number of times ExecuteCommand was called (thread-safe)
-func (m *mockCloudProvider) GetExecuteCommandCount() int32 {
-	return m.executeCommandCount.Load()
-}
-
-// GetValidateInstanceCount returns the number of times ValidateInstance was called (thread-safe)
-func (m *mockCloudProvider) GetValidateInstanceCount() int32 {
-	return m.validateInstanceCount.Load()
-}
-
-// GetTagInstanceCount returns the number of times TagInstance was called (thread-safe)
-func (m *mockCloudProvider) GetTagInstanceCount() int32 {
-	return m.tagInstanceCount.Load()
-}
-
-// ============================================================
 // mockPackageInstaller simulates an installer for testing.
 //
 // 🎓 CONCEPT: Interface with auto-detection
-// Implements both PackageInstaller and autoDetectInstaller
+// Implements both PackageInstaller and installer.AutoDetector
 // to simulate PuppetInstaller behavior.
 type mockPackageInstaller struct {
 	name                        string
 	generateInstallScriptFunc   func(osType string, options map[string]string) ([]string, error)
-	generateWithAutoDetectFunc  func(ctx context.Context, instance *cloud.Instance, provider cloud.CloudProvider, options map[string]string) ([]string, map[string]string, error)
+	generateWithAutoDetectFunc  func(ctx context.Context, instance *cloud.Instance, provider cloud.CloudProvider, options map[string]string) ([]string, *installer.InstallMetadata, error)
 	validatePrerequisitesFunc   func(ctx context.Context, instance *cloud.Instance, provider cloud.CloudProvider) error
 	verifyInstallationFunc      func(ctx context.Context, instance *cloud.Instance, provider cloud.CloudProvider) error
 	getSuccessTagsFunc          func() map[string]string
@@ -114,8 +39,8 @@ type mockPackageInstaller struct {
 	generateWithAutoDetectCount atomic.Int32
 	validatePrerequisitesCount  atomic.Int32
 	verifyInstallationCount     atomic.Int32
-	metadataByInstanceMutex     sync.Mutex                   // Protects map
-	metadataByInstance          map[string]map[string]string // instance_id -> metadata
+	metadataByInstanceMutex     sync.Mutex                            // Protects map
+	metadataByInstance          map[string]*installer.InstallMetadata // instance_id -> metadata
 }
 
 func (m *mockPackageInstaller) Name() string {
@@ -133,23 +58,23 @@ func (m *mockPackageInstaller) GenerateInstallScript(osType string, options map[
 	return []string{"echo 'install package'"}, nil
 }
 
-// GenerateInstallScriptWithAutoDetect implements autoDetectInstaller interface
-func (m *mockPackageInstaller) GenerateInstallScriptWithAutoDetect(ctx context.Context, instance *cloud.Instance, provider cloud.CloudProvider, options map[string]string) (commands []string, metadata map[string]string, err error) {
+// GenerateInstallScriptWithAutoDetect implements installer.AutoDetector interface
+func (m *mockPackageInstaller) GenerateInstallScriptWithAutoDetect(ctx context.Context, instance *cloud.Instance, provider cloud.CloudProvider, options map[string]string) (commands []string, metadata *installer.InstallMetadata, err error) {
 	m.generateWithAutoDetectCount.Add(1)
 	if m.generateWithAutoDetectFunc != nil {
 		return m.generateWithAutoDetectFunc(ctx, instance, provider, options)
 	}
 
 	// Generate UNIQUE metadata for each instance
-	metadata = map[string]string{
-		"os":       "ubuntu",
-		"certname": fmt.Sprintf("%s.puppet", instance.ID), // Unique certname based on ID
+	metadata = &installer.InstallMetadata{
+		OS:       "ubuntu",
+		Certname: fmt.Sprintf("%s.puppet", instance.ID), // Unique certname based on ID
 	}
 
 	// Store metadata for later validation
 	m.metadataByInstanceMutex.Lock()
 	if m.metadataByInstance == nil {
-		m.metadataByInstance = make(map[string]map[string]string)
+		m.metadataByInstance = make(map[string]*installer.InstallMetadata)
 	}
 	m.metadataByInstance[instance.ID] = metadata
 	m.metadataByInstanceMutex.Unlock()
@@ -187,13 +112,13 @@ func (m *mockPackageInstaller) GetFailureTags(err error) map[string]string {
 	return map[string]string{"status": "failed"}
 }
 
-func (_ *mockPackageInstaller) GetInstallMetadata() map[string]string {
+func (_ *mockPackageInstaller) GetInstallMetadata() *installer.InstallMetadata {
 	// Return empty metadata (legacy method not used in tests)
-	return map[string]string{}
+	return &installer.InstallMetadata{}
 }
 
 // GetMetadataForInstance returns captured metadata for an instance (thread-safe)
-func (m *mockPackageInstaller) GetMetadataForInstance(instanceID string) map[string]string {
+func (m *mockPackageInstaller) GetMetadataForInstance(instanceID string) *installer.InstallMetadata {
 	m.metadataByInstanceMutex.Lock()
 	defer m.metadataByInstanceMutex.Unlock()
 	return m.metadataByInstance[instanceID]
@@ -231,7 +156,7 @@ func createTestInstances(count int) []*cloud.Instance {
 func TestNewParallelExecutor(t *testing.T) {
 	t.Run("creates executor with default max concurrency", func(t *testing.T) {
 		// ARRANGE
-		provider := &mockCloudProvider{}
+		provider := &cloudtest.Provider{}
 		installer := &mockPackageInstaller{}
 
 		// ACT
@@ -253,7 +178,7 @@ func TestNewParallelExecutor(t *testing.T) {
 
 	t.Run("creates executor with custom max concurrency", func(t *testing.T) {
 		// ARRANGE
-		provider := &mockCloudProvider{}
+		provider := &cloudtest.Provider{}
 		installer := &mockPackageInstaller{}
 
 		// ACT
@@ -271,7 +196,7 @@ func TestNewParallelExecutor(t *testing.T) {
 
 	t.Run("creates executor with dry run mode", func(t *testing.T) {
 		// ARRANGE
-		provider := &mockCloudProvider{}
+		provider := &cloudtest.Provider{}
 		installer := &mockPackageInstaller{}
 
 		// ACT
@@ -295,7 +220,7 @@ func TestNewParallelExecutor(t *testing.T) {
 func TestExecute_EmptyInstanceList(t *testing.T) {
 	// ARRANGE
 	ctx := context.Background()
-	provider := &mockCloudProvider{}
+	provider := &cloudtest.Provider{}
 	installer := &mockPackageInstaller{}
 
 	executor := NewParallelExecutor(ExecutorConfig{
@@ -323,7 +248,7 @@ func TestExecute_EmptyInstanceList(t *testing.T) {
 func TestExecute_SingleInstance(t *testing.T) {
 	// ARRANGE
 	ctx := context.Background()
-	provider := &mockCloudProvider{}
+	provider := &cloudtest.Provider{}
 	installer := &mockPackageInstaller{}
 
 	executor := NewParallelExecutor(ExecutorConfig{
@@ -354,16 +279,16 @@ func TestExecute_SingleInstance(t *testing.T) {
 	}
 
 	// Verify that provider functions were called
-	if provider.GetValidateInstanceCount() != 1 {
-		t.Errorf("ValidateInstance called %d times, want 1", provider.GetValidateInstanceCount())
+	if provider.CallCount(cloudtest.MethodValidateInstance) != 1 {
+		t.Errorf("ValidateInstance called %d times, want 1", provider.CallCount(cloudtest.MethodValidateInstance))
 	}
 
-	if provider.GetExecuteCommandCount() != 1 {
-		t.Errorf("ExecuteCommand called %d times, want 1", provider.GetExecuteCommandCount())
+	if provider.CallCount(cloudtest.MethodExecuteCommand) != 1 {
+		t.Errorf("ExecuteCommand called %d times, want 1", provider.CallCount(cloudtest.MethodExecuteCommand))
 	}
 
-	if provider.GetTagInstanceCount() != 1 {
-		t.Errorf("TagInstance called %d times, want 1", provider.GetTagInstanceCount())
+	if provider.CallCount(cloudtest.MethodTagInstance) != 1 {
+		t.Errorf("TagInstance called %d times, want 1", provider.CallCount(cloudtest.MethodTagInstance))
 	}
 }
 
@@ -374,7 +299,7 @@ func TestExecute_SingleInstance(t *testing.T) {
 func TestExecute_MultipleInstances_ParallelExecution(t *testing.T) {
 	// ARRANGE
 	ctx := context.Background()
-	provider := &mockCloudProvider{}
+	provider := &cloudtest.Provider{}
 	installer := &mockPackageInstaller{}
 
 	executor := NewParallelExecutor(ExecutorConfig{
@@ -420,8 +345,8 @@ func TestExecute_MultipleInstances_ParallelExecution(t *testing.T) {
 	t.Logf("Duration: %v (should be fast due to parallelism)", duration)
 
 	// Verify that provider was called for each instance
-	if provider.GetValidateInstanceCount() != 20 {
-		t.Errorf("ValidateInstance called %d times, want 20", provider.GetValidateInstanceCount())
+	if provider.CallCount(cloudtest.MethodValidateInstance) != 20 {
+		t.Errorf("ValidateInstance called %d times, want 20", provider.CallCount(cloudtest.MethodValidateInstance))
 	}
 }
 
@@ -432,7 +357,7 @@ func TestExecute_MultipleInstances_ParallelExecution(t *testing.T) {
 func TestExecute_CapturesUniqueMetadata(t *testing.T) {
 	// ARRANGE
 	ctx := context.Background()
-	provider := &mockCloudProvider{}
+	provider := cloudtest.New().WithLatency(0, 2*time.Millisecond) // Overlapping calls
 	installer := &mockPackageInstaller{}
 
 	executor := NewParallelExecutor(ExecutorConfig{
@@ -451,47 +376,27 @@ func TestExecute_CapturesUniqueMetadata(t *testing.T) {
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
+	if len(result.Results) != 50 {
+		t.Fatalf("got %d results, want 50", len(result.Results))
+	}
 
-	// CRITICAL VALIDATION: Each instance must have UNIQUE metadata
-	certnames := make(map[string]string) // certname -> instance_id
-
-	for _, execResult := range result.Results {
-		instanceID := execResult.Instance.ID
-
-		// Verify that metadata exists
-		if execResult.Metadata == nil {
-			t.Errorf("instance %s: metadata is nil", instanceID)
-			continue
-		}
-
-		// Verify that certname exists
-		certname := execResult.Metadata["certname"]
-		if certname == "" {
-			t.Errorf("instance %s: metadata[certname] is empty", instanceID)
-			continue
+	// CRITICAL VALIDATION: Each instance must have UNIQUE metadata, matching
+	// the instance it was generated for
+	stresstest.Each(t, result.Results, func(r *ExecutionResult) error {
+		if r.Metadata == nil {
+			return fmt.Errorf("instance %s: metadata is nil", r.Instance.ID)
 		}
-
-		// RACE CONDITION VERIFICATION: certname must be UNIQUE
-		if previousInstanceID, exists := certnames[certname]; exists {
-			t.Errorf("RACE CONDITION DETECTED: instances %s and %s have same certname: %q",
-				instanceID, previousInstanceID, certname)
+		if want := fmt.Sprintf("%s.puppet", r.Instance.ID); r.Metadata.Certname != want {
+			return fmt.Errorf("instance %s: metadata.Certname = %q, want %q", r.Instance.ID, r.Metadata.Certname, want)
 		}
-
-		certnames[certname] = instanceID
-
-		// Validate that certname matches expected for this instance
-		expectedCertname := fmt.Sprintf("%s.puppet", instanceID)
-		if certname != expectedCertname {
-			t.Errorf("instance %s: metadata[certname] = %q, want %q",
-				instanceID, certname, expectedCertname)
+		return nil
+	})
+	stresstest.Unique(t, result.Results, func(r *ExecutionResult) string {
+		if r.Metadata == nil {
+			return ""
 		}
-	}
-
-	// Verify that we have exactly 50 unique certnames
-	if len(certnames) != 50 {
-		t.Errorf("captured %d unique certnames, want 50 (possible race condition)",
-			len(certnames))
-	}
+		return r.Metadata.Certname
+	})
 }
 
 // TestExecute_MixedResults tests scenario with successes and failures.
@@ -501,9 +406,9 @@ func TestExecute_CapturesUniqueMetadata(t *testing.T) {
 func TestExecute_MixedResults(t *testing.T) {
 	// ARRANGE
 	ctx := context.Background()
-	provider := &mockCloudProvider{
+	provider := &cloudtest.Provider{
 		// Validation fails for odd instances
-		validateInstanceFunc: func(_ context.Context, instance *cloud.Instance) error {
+		ValidateInstanceFunc: func(_ context.Context, instance *cloud.Instance) error {
 			// Extract instance number (i-test001, i-test002, etc)
 			var instanceNum int
 			_, _ = fmt.Sscanf(instance.ID, "i-test%d", &instanceNum)
@@ -579,7 +484,7 @@ func TestExecute_MixedResults(t *testing.T) {
 func TestExecute_DryRunMode(t *testing.T) {
 	// ARRANGE
 	ctx := context.Background()
-	provider := &mockCloudProvider{}
+	provider := &cloudtest.Provider{}
 	installer := &mockPackageInstaller{}
 
 	executor := NewParallelExecutor(ExecutorConfig{
@@ -605,8 +510,8 @@ func TestExecute_DryRunMode(t *testing.T) {
 
 	// Dry-run should NOT execute real commands
 	// ValidateInstance is still called
-	if provider.GetValidateInstanceCount() != 5 {
-		t.Errorf("ValidateInstance called %d times, want 5", provider.GetValidateInstanceCount())
+	if provider.CallCount(cloudtest.MethodValidateInstance) != 5 {
+		t.Errorf("ValidateInstance called %d times, want 5", provider.CallCount(cloudtest.MethodValidateInstance))
 	}
 
 	// VerifyInstallation should NOT be called in dry-run
@@ -614,6 +519,130 @@ func TestExecute_DryRunMode(t *testing.T) {
 	// This is validated through the installer mock not having VerifyInstallation called
 }
 
+// mockStepInstaller simulates an installer with StepBased and VerifiesFacts capabilities.
+//
+// 🎓 CONCEPT: Embedding an interface
+// Embedding installer.PackageInstaller promotes only the base methods,
+// so the auto-detect capability of mockPackageInstaller is hidden.
+type mockStepInstaller struct {
+	installer.PackageInstaller
+	steps          []installer.InstallStep
+	verifyFactsErr error
+	verifyFacts    atomic.Int32
+}
+
+func (m *mockStepInstaller) GenerateInstallSteps(_ string, _ map[string]string) ([]installer.InstallStep, error) {
+	return m.steps, nil
+}
+
+func (m *mockStepInstaller) VerifyFacts(_ context.Context, _ *cloud.Instance, _ cloud.CloudProvider) error {
+	m.verifyFacts.Add(1)
+	return m.verifyFactsErr
+}
+
+// TestExecute_StepBasedInstaller tests step-by-step execution and fact verification.
+//
+// 🎓 CONCEPT: Capability negotiation
+// The executor adapts its workflow to the optional interfaces an installer implements.
+func TestExecute_StepBasedInstaller(t *testing.T) {
+	steps := []installer.InstallStep{
+		{Name: "configure-repo", Commands: []string{"echo repo"}},
+		{Name: "install-package", Commands: []string{"echo install"}},
+	}
+
+	t.Run("runs each step and verifies facts", func(t *testing.T) {
+		// ARRANGE
+		provider := &cloudtest.Provider{}
+		stepInstaller := &mockStepInstaller{PackageInstaller: &mockPackageInstaller{}, steps: steps}
+		executor := NewParallelExecutor(ExecutorConfig{Provider: provider, Installer: stepInstaller})
+
+		// ACT
+		result, err := executor.Execute(context.Background(), createTestInstances(1))
+
+		// ASSERT
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result.Success != 1 {
+			t.Errorf("Success = %d, want 1", result.Success)
+		}
+		if provider.CallCount(cloudtest.MethodExecuteCommand) != 2 {
+			t.Errorf("ExecuteCommand called %d times, want 2 (one per step)", provider.CallCount(cloudtest.MethodExecuteCommand))
+		}
+		if stepInstaller.verifyFacts.Load() != 1 {
+			t.Errorf("VerifyFacts called %d times, want 1", stepInstaller.verifyFacts.Load())
+		}
+	})
+
+	t.Run("failing step stops installation and is reported by name", func(t *testing.T) {
+		// ARRANGE
+		provider := &cloudtest.Provider{
+			ExecuteCommandFunc: func(_ context.Context, _ *cloud.Instance, _ []string, _ time.Duration) (*cloud.CommandResult, error) {
+				return &cloud.CommandResult{ExitCode: 1, Stderr: "repo unreachable"}, nil
+			},
+		}
+		stepInstaller := &mockStepInstaller{PackageInstaller: &mockPackageInstaller{}, steps: steps}
+		executor := NewParallelExecutor(ExecutorConfig{Provider: provider, Installer: stepInstaller, SkipTagging: true, SkipDiagnostics: true})
+
+		// ACT
+		result, err := executor.Execute(context.Background(), createTestInstances(1))
+
+		// ASSERT
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if provider.CallCount(cloudtest.MethodExecuteCommand) != 1 {
+			t.Errorf("ExecuteCommand called %d times, want 1 (stop at first failing step)", provider.CallCount(cloudtest.MethodExecuteCommand))
+		}
+		failed := result.GetFailedInstances()
+		if len(failed) != 1 || !strings.Contains(failed[0].GetError().Error(), `install step "configure-repo" failed`) {
+			t.Errorf("expected configure-repo step failure, got %+v", failed)
+		}
+	})
+
+	t.Run("step env is passed as exec options", func(t *testing.T) {
+		// ARRANGE
+		provider := &cloudtest.Provider{}
+		envSteps := []installer.InstallStep{
+			{Name: "register", Commands: []string{"register.sh"}, Env: map[string]string{"API_TOKEN": "s3cr3t"}},
+		}
+		stepInstaller := &mockStepInstaller{PackageInstaller: &mockPackageInstaller{}, steps: envSteps}
+		executor := NewParallelExecutor(ExecutorConfig{Provider: provider, Installer: stepInstaller, SkipTagging: true})
+
+		// ACT
+		if _, err := executor.Execute(context.Background(), createTestInstances(1)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		// ASSERT
+		calls := provider.Calls(cloudtest.MethodExecuteCommand)
+		if len(calls) != 1 || calls[0].Options.Env["API_TOKEN"] != "s3cr3t" {
+			t.Errorf("expected step env in exec options, got %+v", calls)
+		}
+	})
+
+	t.Run("fact verification failure fails the instance", func(t *testing.T) {
+		// ARRANGE
+		stepInstaller := &mockStepInstaller{
+			PackageInstaller: &mockPackageInstaller{},
+			steps:            steps,
+			verifyFactsErr:   fmt.Errorf("fact role missing"),
+		}
+		executor := NewParallelExecutor(ExecutorConfig{Provider: &cloudtest.Provider{}, Installer: stepInstaller, SkipTagging: true})
+
+		// ACT
+		result, err := executor.Execute(context.Background(), createTestInstances(1))
+
+		// ASSERT
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result.Failed != 1 {
+			t.Errorf("Failed = %d, want 1", result.Failed)
+		}
+	})
+}
+
 // TestExecute_ContextCancellation tests cancellation via context.
 //
 // 🎓 CONCEPT: Context cancellation
@@ -621,9 +650,9 @@ func TestExecute_DryRunMode(t *testing.T) {
 func TestExecute_ContextCancellation(t *testing.T) {
 	// ARRANGE
 	ctx, cancel := context.WithCancel(context.Background())
-	provider := &mockCloudProvider{
+	provider := &cloudtest.Provider{
 		// Simulate slow operation
-		executeCommandFunc: func(_ context.Context, _ *cloud.Instance, _ []string, _ time.Duration) (*cloud.CommandResult, error) {
+		ExecuteCommandFunc: func(_ context.Context, _ *cloud.Instance, _ []string, _ time.Duration) (*cloud.CommandResult, error) {
 			// Wait a bit to allow time for cancellation
 			time.Sleep(100 * time.Millisecond)
 			return &cloud.CommandResult{Stdout: "output", ExitCode: 0}, nil
@@ -670,29 +699,16 @@ func TestExecute_ConcurrencyLimit(t *testing.T) {
 	// ARRANGE
 	ctx := context.Background()
 
-	// Counter for simultaneous executions
-	var currentConcurrent atomic.Int32
-	var maxConcurrentSeen atomic.Int32
+	// Tracks simultaneous executions
+	var inFlight stresstest.Gauge
 
-	provider := &mockCloudProvider{
-		executeCommandFunc: func(_ context.Context, _ *cloud.Instance, _ []string, _ time.Duration) (*cloud.CommandResult, error) {
-			// Increment counter
-			current := currentConcurrent.Add(1)
-
-			// Update maximum seen
-			for {
-				max := maxConcurrentSeen.Load()
-				if current <= max || maxConcurrentSeen.CompareAndSwap(max, current) {
-					break
-				}
-			}
+	provider := &cloudtest.Provider{
+		ExecuteCommandFunc: func(_ context.Context, _ *cloud.Instance, _ []string, _ time.Duration) (*cloud.CommandResult, error) {
+			defer inFlight.Enter()()
 
 			// Simulate work
 			time.Sleep(50 * time.Millisecond)
 
-			// Decrement counter
-			currentConcurrent.Add(-1)
-
 			return &cloud.CommandResult{Stdout: "output", ExitCode: 0}, nil
 		},
 	}
@@ -716,10 +732,670 @@ func TestExecute_ConcurrencyLimit(t *testing.T) {
 	}
 
 	// Verify that we never exceeded concurrency limit
-	maxSeen := maxConcurrentSeen.Load()
-	if maxSeen > int32(maxConcurrency) {
-		t.Errorf("max concurrent executions = %d, want <= %d", maxSeen, maxConcurrency)
+	inFlight.AtMost(t, maxConcurrency)
+
+	t.Logf("Max concurrent executions seen: %d (limit was %d)", inFlight.Max(), maxConcurrency)
+}
+
+// TestExecute_Stress runs executors concurrently over distinct fleets sharing
+// one provider and installer (as pipelines and foreach runs do), with
+// scripted provider latencies, and checks the executor invariants: every
+// instance gets exactly one result, with its own metadata, within the
+// concurrency limit of its executor.
+func TestExecute_Stress(t *testing.T) {
+	// ARRANGE
+	var inFlight stresstest.Gauge
+	provider := cloudtest.New().WithLatency(0, 3*time.Millisecond)
+	provider.ExecuteCommandFunc = func(_ context.Context, instance *cloud.Instance, _ []string, _ time.Duration) (*cloud.CommandResult, error) {
+		defer inFlight.Enter()()
+		time.Sleep(time.Millisecond)
+		return &cloud.CommandResult{InstanceID: instance.ID}, nil
+	}
+	installer := &mockPackageInstaller{}
+
+	executors, instancesPerRun, maxConcurrency := 4, 25, 5
+	fleets := make([][]*cloud.Instance, executors)
+	for i := range fleets {
+		fleets[i] = stresstest.Instances(fmt.Sprintf("run%d-", i), instancesPerRun)
+	}
+
+	// ACT
+	calls := stresstest.Run(t, stresstest.Config{Goroutines: executors}, func(ctx context.Context, worker, _ int) ([]*ExecutionResult, error) {
+		result, err := NewParallelExecutor(ExecutorConfig{
+			Provider:       provider,
+			Installer:      installer,
+			MaxConcurrency: maxConcurrency,
+		}).Execute(ctx, fleets[worker])
+		if err != nil {
+			return nil, err
+		}
+		return result.Results, nil
+	})
+
+	// ASSERT
+	stresstest.NoErrors(t, calls)
+	var results []*ExecutionResult
+	for _, call := range calls {
+		if len(call.Value) != instancesPerRun {
+			t.Errorf("%s: %d results, want %d", call, len(call.Value), instancesPerRun)
+		}
+		results = append(results, call.Value...)
+	}
+	stresstest.Unique(t, results, func(r *ExecutionResult) string { return r.Instance.ID })
+	stresstest.Each(t, results, func(r *ExecutionResult) error {
+		if r.Status != StatusSuccess {
+			return fmt.Errorf("instance %s: status = %s, want success", r.Instance.ID, r.Status)
+		}
+		if want := r.Instance.ID + ".puppet"; r.Metadata == nil || r.Metadata.Certname != want {
+			return fmt.Errorf("instance %s: metadata = %+v, want certname %q", r.Instance.ID, r.Metadata, want)
+		}
+		return nil
+	})
+	inFlight.AtMost(t, executors*maxConcurrency)
+}
+
+// mockGroupingInstaller groups instances by the "group" metadata column.
+type mockGroupingInstaller struct {
+	installer.PackageInstaller
+}
+
+func (m *mockGroupingInstaller) ConcurrencyGroup(instance *cloud.Instance) string {
+	return instance.Metadata["group"]
+}
+
+// TestExecute_MaxPerGroup tests the per-group concurrency limit.
+//
+// 🎓 CONCEPT: Nested semaphores
+// Each group (e.g., Puppet Server) has its own semaphore inside the global limit.
+func TestExecute_MaxPerGroup(t *testing.T) {
+	// ARRANGE
+	var mu sync.Mutex
+	current := map[string]int{}
+	maxSeen := map[string]int{}
+
+	provider := &cloudtest.Provider{
+		ExecuteCommandFunc: func(_ context.Context, instance *cloud.Instance, _ []string, _ time.Duration) (*cloud.CommandResult, error) {
+			group := instance.Metadata["group"]
+
+			mu.Lock()
+			current[group]++
+			maxSeen[group] = max(maxSeen[group], current[group])
+			mu.Unlock()
+
+			time.Sleep(30 * time.Millisecond)
+
+			mu.Lock()
+			current[group]--
+			mu.Unlock()
+
+			return &cloud.CommandResult{Stdout: "output", ExitCode: 0}, nil
+		},
+	}
+
+	instances := createTestInstances(12)
+	for i, instance := range instances {
+		instance.Metadata["group"] = fmt.Sprintf("puppet-%d", i%2)
 	}
 
-	t.Logf("Max concurrent executions seen: %d (limit was %d)", maxSeen, maxConcurrency)
+	executor := NewParallelExecutor(ExecutorConfig{
+		Provider:       provider,
+		Installer:      &mockGroupingInstaller{PackageInstaller: &mockPackageInstaller{}},
+		MaxConcurrency: 10,
+		MaxPerGroup:    2,
+	})
+
+	// ACT
+	result, err := executor.Execute(context.Background(), instances)
+
+	// ASSERT
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Success != len(instances) {
+		t.Errorf("Success = %d, want %d", result.Success, len(instances))
+	}
+	for group, seen := range maxSeen {
+		if seen > 2 {
+			t.Errorf("group %s: max concurrent = %d, want <= 2", group, seen)
+		}
+	}
+}
+
+// TestExecute_FirstRunStagger tests that stagger respects context cancellation.
+func TestExecute_FirstRunStagger(t *testing.T) {
+	// ARRANGE
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	provider := &cloudtest.Provider{}
+	executor := NewParallelExecutor(ExecutorConfig{
+		Provider:        provider,
+		Installer:       &mockGroupingInstaller{PackageInstaller: &mockPackageInstaller{}},
+		FirstRunStagger: time.Hour,
+	})
+
+	// ACT
+	start := time.Now()
+	result, _ := executor.Execute(ctx, createTestInstances(3))
+
+	// ASSERT
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("stagger ignored cancellation, took %v", elapsed)
+	}
+	if provider.CallCount(cloudtest.MethodExecuteCommand) != 0 {
+		t.Errorf("expected no install commands while staggering, got %d", provider.CallCount(cloudtest.MethodExecuteCommand))
+	}
+	if result.Success != 0 {
+		t.Errorf("Success = %d, want 0", result.Success)
+	}
+}
+
+// mockHookInstaller fails its post-install hook.
+type mockHookInstaller struct {
+	installer.PackageInstaller
+	hookErr error
+}
+
+func (m *mockHookInstaller) AfterInstall(_ context.Context, _ *cloud.Instance, _ *installer.InstallMetadata) error {
+	return m.hookErr
+}
+
+// TestExecute_PostInstallHookFailure tests that hook failures don't fail the installation
+func TestExecute_PostInstallHookFailure(t *testing.T) {
+	// ARRANGE
+	hookInstaller := &mockHookInstaller{
+		PackageInstaller: &mockPackageInstaller{},
+		hookErr:          errors.New("ENC returned 500"),
+	}
+	executor := NewParallelExecutor(ExecutorConfig{Provider: &cloudtest.Provider{}, Installer: hookInstaller})
+
+	// ACT
+	result, err := executor.Execute(context.Background(), createTestInstances(1))
+
+	// ASSERT
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Success != 1 {
+		t.Fatalf("Success = %d, want 1", result.Success)
+	}
+	if hookErr := result.Results[0].PostInstallErr; hookErr == nil || hookErr.Error() != "ENC returned 500" {
+		t.Errorf("PostInstallErr = %v, want hook error", hookErr)
+	}
+}
+
+// TestExecute_VerifyGracePeriod tests that failed verifications are retried
+// within the grace period and late successes are recorded in metadata
+func TestExecute_VerifyGracePeriod(t *testing.T) {
+	tests := []struct {
+		name         string
+		failures     int32
+		grace        time.Duration
+		wantSuccess  bool
+		wantLate     bool
+		wantAttempts int32
+	}{
+		{name: "passes first time", failures: 0, grace: time.Second, wantSuccess: true, wantAttempts: 1},
+		{name: "verified late", failures: 2, grace: time.Second, wantSuccess: true, wantLate: true, wantAttempts: 3},
+		{name: "no grace period", failures: 2, grace: 0, wantSuccess: false, wantAttempts: 1},
+		{name: "grace period exhausted", failures: 1000, grace: 50 * time.Millisecond, wantSuccess: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// ARRANGE
+			var calls atomic.Int32
+			pkg := &mockPackageInstaller{
+				verifyInstallationFunc: func(context.Context, *cloud.Instance, cloud.CloudProvider) error {
+					if calls.Add(1) <= tt.failures {
+						return errors.New("puppet service not active yet")
+					}
+					return nil
+				},
+			}
+			executor := NewParallelExecutor(ExecutorConfig{
+				Provider:          &cloudtest.Provider{},
+				Installer:         pkg,
+				SkipTagging:       true,
+				VerifyGracePeriod: tt.grace,
+				VerifyRetryDelay:  time.Millisecond,
+			})
+
+			// ACT
+			result, err := executor.Execute(context.Background(), createTestInstances(1))
+
+			// ASSERT
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := result.Success == 1; got != tt.wantSuccess {
+				t.Fatalf("success = %v, want %v (error: %v)", got, tt.wantSuccess, result.Results[0].GetError())
+			}
+			if tt.wantAttempts > 0 && calls.Load() != tt.wantAttempts {
+				t.Errorf("verification attempts = %d, want %d", calls.Load(), tt.wantAttempts)
+			}
+			late := result.Results[0].Metadata.Get(installer.MetadataKeyVerifiedLate)
+			if (late != "") != tt.wantLate {
+				t.Errorf("verified_late = %q, want set=%v", late, tt.wantLate)
+			}
+			if !tt.wantSuccess && tt.grace > 0 && !strings.Contains(result.Results[0].GetError().Error(), "grace period") {
+				t.Errorf("error should mention the grace period: %v", result.Results[0].GetError())
+			}
+		})
+	}
+}
+
+// TestExecute_FailurePhase tests failure classification from script exit
+// codes, falling back to the workflow phase of the failure
+func TestExecute_FailurePhase(t *testing.T) {
+	tests := []struct {
+		name         string
+		exitCode     int
+		validateErr  error
+		verifyErr    error
+		wantPhase    string
+		wantExitCode int
+	}{
+		{name: "download exit code", exitCode: installer.ExitCodeDownload, wantPhase: installer.ScriptPhaseDownload, wantExitCode: 20},
+		{name: "configure exit code", exitCode: installer.ExitCodeConfigure, wantPhase: installer.ScriptPhaseConfigure, wantExitCode: 40},
+		{name: "unclassified exit code", exitCode: 1, wantPhase: installer.ScriptPhaseInstall, wantExitCode: 1},
+		{name: "validation error", validateErr: errors.New("ssm agent offline"), wantPhase: installer.ScriptPhaseValidation},
+		{name: "verification error", verifyErr: errors.New("agent not running"), wantPhase: installer.ScriptPhaseVerify},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// ARRANGE
+			provider := &cloudtest.Provider{
+				ExecuteCommandFunc: func(context.Context, *cloud.Instance, []string, time.Duration) (*cloud.CommandResult, error) {
+					return &cloud.CommandResult{ExitCode: tt.exitCode, Stderr: "boom"}, nil
+				},
+			}
+			pkg := &mockPackageInstaller{
+				validatePrerequisitesFunc: func(context.Context, *cloud.Instance, cloud.CloudProvider) error {
+					return tt.validateErr
+				},
+				verifyInstallationFunc: func(context.Context, *cloud.Instance, cloud.CloudProvider) error {
+					return tt.verifyErr
+				},
+			}
+			executor := NewParallelExecutor(ExecutorConfig{Provider: provider, Installer: pkg, SkipTagging: true})
+
+			// ACT
+			result, err := executor.Execute(context.Background(), createTestInstances(1))
+
+			// ASSERT
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.Failed != 1 {
+				t.Fatalf("Failed = %d, want 1", result.Failed)
+			}
+			entry := executor.Report(result).Results[0]
+			if entry.FailurePhase != tt.wantPhase || entry.ExitCode != tt.wantExitCode {
+				t.Errorf("failure_phase = %q, exit_code = %d; want %q, %d",
+					entry.FailurePhase, entry.ExitCode, tt.wantPhase, tt.wantExitCode)
+			}
+		})
+	}
+}
+
+// TestExecute_RunIDTag tests that the run ID and the outcome (with the failure
+// phase) are tagged on success and failure and recorded in the aggregated result
+func TestExecute_RunIDTag(t *testing.T) {
+	tests := []struct {
+		name          string
+		prereqErr     error
+		verifyErr     error
+		wantStatus    string
+		wantRunStatus string
+	}{
+		{name: "success tags carry run ID", wantStatus: "installed", wantRunStatus: "success"},
+		{name: "failure tags carry run ID", verifyErr: errors.New("agent not running"), wantStatus: "failed", wantRunStatus: "failed-verify"},
+		{name: "failure tags carry validation phase", prereqErr: errors.New("no internet"), wantStatus: "failed", wantRunStatus: "failed-validation"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// ARRANGE
+			var mu sync.Mutex
+			var tagged map[string]string
+			provider := &cloudtest.Provider{
+				TagInstanceFunc: func(_ context.Context, _ *cloud.Instance, tags map[string]string) error {
+					mu.Lock()
+					defer mu.Unlock()
+					tagged = tags
+					return nil
+				},
+			}
+			pkg := &mockPackageInstaller{
+				name: "puppet",
+				validatePrerequisitesFunc: func(context.Context, *cloud.Instance, cloud.CloudProvider) error {
+					return tt.prereqErr
+				},
+				verifyInstallationFunc: func(context.Context, *cloud.Instance, cloud.CloudProvider) error {
+					return tt.verifyErr
+				},
+			}
+			executor := NewParallelExecutor(ExecutorConfig{Provider: provider, Installer: pkg, RunID: "run-123"})
+
+			// ACT
+			result, err := executor.Execute(context.Background(), createTestInstances(1))
+
+			// ASSERT
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.RunID != "run-123" {
+				t.Errorf("RunID = %q, want run-123", result.RunID)
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if tagged[cloud.RunIDTagKey] != "run-123" || tagged["status"] != tt.wantStatus {
+				t.Errorf("unexpected tags %v", tagged)
+			}
+			if got := tagged["opsmaster:puppet_status"]; got != tt.wantRunStatus {
+				t.Errorf("opsmaster:puppet_status = %q, want %q", got, tt.wantRunStatus)
+			}
+			if got := executor.Report(result).Results[0].Tags; !reflect.DeepEqual(got, tagged) {
+				t.Errorf("report tags %v differ from applied tags %v", got, tagged)
+			}
+		})
+	}
+}
+
+// TestExecute_RequeueFailed tests that failed instances are requeued after all
+// first attempts and only their final result is recorded
+func TestExecute_RequeueFailed(t *testing.T) {
+	// ARRANGE - the first attempt of i-test000 fails verification
+	var mu sync.Mutex
+	var order []string
+	failed := false
+	pkg := &mockPackageInstaller{
+		validatePrerequisitesFunc: func(_ context.Context, instance *cloud.Instance, _ cloud.CloudProvider) error {
+			mu.Lock()
+			defer mu.Unlock()
+			order = append(order, instance.ID)
+			return nil
+		},
+		verifyInstallationFunc: func(_ context.Context, instance *cloud.Instance, _ cloud.CloudProvider) error {
+			mu.Lock()
+			defer mu.Unlock()
+			if instance.ID == "i-test000" && !failed {
+				failed = true
+				return errors.New("agent not running")
+			}
+			return nil
+		},
+	}
+	executor := NewParallelExecutor(ExecutorConfig{
+		Provider:       &cloudtest.Provider{},
+		Installer:      pkg,
+		MaxConcurrency: 1,
+		RequeueFailed:  1,
+	})
+
+	// ACT
+	result, err := executor.Execute(context.Background(), createTestInstances(3))
+
+	// ASSERT
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Total != 3 || result.Success != 3 {
+		t.Fatalf("Total = %d, Success = %d; want 3, 3", result.Total, result.Success)
+	}
+	want := []string{"i-test000", "i-test001", "i-test002", "i-test000"}
+	if strings.Join(order, ",") != strings.Join(want, ",") {
+		t.Errorf("processing order = %v, want %v", order, want)
+	}
+	for _, r := range result.Results {
+		wantAttempts := 1
+		if r.Instance.ID == "i-test000" {
+			wantAttempts = 2
+		}
+		if r.Attempts != wantAttempts {
+			t.Errorf("%s: Attempts = %d, want %d", r.Instance.ID, r.Attempts, wantAttempts)
+		}
+	}
+}
+
+// TestExecute_RequeueTransient tests that only transient failures are requeued
+// and that earlier attempts are kept in the result history
+func TestExecute_RequeueTransient(t *testing.T) {
+	// ARRANGE - i-test000 is throttled once, i-test001 always fails permanently
+	var mu sync.Mutex
+	throttled := false
+	provider := &cloudtest.Provider{
+		ValidateInstanceFunc: func(_ context.Context, instance *cloud.Instance) error {
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case instance.ID == "i-test000" && !throttled:
+				throttled = true
+				return errors.New("ThrottlingException: Rate exceeded")
+			case instance.ID == "i-test001":
+				return errors.New("access denied")
+			}
+			return nil
+		},
+	}
+	executor := NewParallelExecutor(ExecutorConfig{
+		Provider:         provider,
+		Installer:        &mockPackageInstaller{},
+		MaxConcurrency:   2,
+		RequeueTransient: 2,
+	})
+
+	// ACT
+	result, err := executor.Execute(context.Background(), createTestInstances(2))
+
+	// ASSERT
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Success != 1 || result.Failed != 1 {
+		t.Fatalf("Success = %d, Failed = %d; want 1, 1", result.Success, result.Failed)
+	}
+	entries := make(map[string]ReportEntry)
+	for _, entry := range executor.Report(result).Results {
+		entries[entry.InstanceID] = entry
+	}
+
+	retried := entries["i-test000"]
+	if retried.Status != StatusSuccess.String() || len(retried.Attempts) != 1 {
+		t.Fatalf("unexpected entry of requeued instance %+v", retried)
+	}
+	if attempt := retried.Attempts[0]; attempt.Attempt != 1 || attempt.Status != "FAILED" ||
+		attempt.TransientReason != "throttled" || attempt.FailurePhase != "validation" {
+		t.Errorf("unexpected earlier attempt %+v", attempt)
+	}
+	if permanent := entries["i-test001"]; len(permanent.Attempts) != 0 {
+		t.Errorf("permanent failure was requeued: %+v", permanent.Attempts)
+	}
+}
+
+// TestExecute_InstanceDisappeared tests that instances terminated mid-run
+// are reported as disappeared, not failed, and are neither requeued nor tagged
+func TestExecute_InstanceDisappeared(t *testing.T) {
+	// ARRANGE - i-test000 is terminated once the installation starts
+	var mu sync.Mutex
+	tagged := make(map[string]int)
+	provider := &cloudtest.Provider{
+		ExecuteCommandFunc: func(_ context.Context, instance *cloud.Instance, _ []string, _ time.Duration) (*cloud.CommandResult, error) {
+			if instance.ID == "i-test000" {
+				return nil, fmt.Errorf("failed to send SSM command: %w: InvalidInstanceId", cloud.ErrInstanceGone)
+			}
+			return &cloud.CommandResult{InstanceID: instance.ID}, nil
+		},
+		TagInstanceFunc: func(_ context.Context, instance *cloud.Instance, _ map[string]string) error {
+			mu.Lock()
+			defer mu.Unlock()
+			tagged[instance.ID]++
+			return nil
+		},
+	}
+	executor := NewParallelExecutor(ExecutorConfig{
+		Provider:       provider,
+		Installer:      &mockPackageInstaller{},
+		MaxConcurrency: 2,
+		RequeueFailed:  2,
+	})
+
+	// ACT
+	result, err := executor.Execute(context.Background(), createTestInstances(2))
+
+	// ASSERT
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Success != 1 || result.Failed != 0 || result.Disappeared != 1 {
+		t.Fatalf("Success = %d, Failed = %d, Disappeared = %d; want 1, 0, 1", result.Success, result.Failed, result.Disappeared)
+	}
+	for _, entry := range executor.Report(result).Results {
+		if entry.InstanceID != "i-test000" {
+			continue
+		}
+		if entry.Status != "DISAPPEARED" || len(entry.Attempts) != 0 || entry.Tags != nil {
+			t.Errorf("unexpected entry of disappeared instance %+v", entry)
+		}
+	}
+	if tagged["i-test000"] != 0 {
+		t.Errorf("disappeared instance was tagged %d times", tagged["i-test000"])
+	}
+}
+
+// TestExecute_OnResult tests that every finished instance is passed to the callback
+func TestExecute_OnResult(t *testing.T) {
+	// ARRANGE
+	seen := make(map[string]ExecutionStatus)
+	executor := NewParallelExecutor(ExecutorConfig{
+		Provider:  &cloudtest.Provider{},
+		Installer: &mockPackageInstaller{},
+		OnResult: func(result *ExecutionResult) {
+			// No lock needed: callbacks run from the collector goroutine only
+			seen[result.Instance.ID] = result.Status
+		},
+	})
+	instances := createTestInstances(3)
+
+	// ACT
+	result, err := executor.Execute(context.Background(), instances)
+
+	// ASSERT
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(seen) != result.Total {
+		t.Fatalf("callback saw %d instances, want %d", len(seen), result.Total)
+	}
+	for _, instance := range instances {
+		if seen[instance.ID] != StatusSuccess {
+			t.Errorf("instance %s: status %v, want SUCCESS", instance.ID, seen[instance.ID])
+		}
+	}
+}
+
+// TestExecute_Phases tests that each workflow phase is timed in order
+func TestExecute_Phases(t *testing.T) {
+	// ARRANGE
+	pkg := &mockPackageInstaller{
+		verifyInstallationFunc: func(context.Context, *cloud.Instance, cloud.CloudProvider) error {
+			time.Sleep(20 * time.Millisecond)
+			return nil
+		},
+	}
+	executor := NewParallelExecutor(ExecutorConfig{Provider: &cloudtest.Provider{}, Installer: pkg})
+
+	// ACT
+	result, err := executor.Execute(context.Background(), createTestInstances(1))
+
+	// ASSERT
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	phases := result.Results[0].Phases
+	var names []string
+	for _, phase := range phases {
+		names = append(names, phase.Name)
+	}
+	if want := []string{PhaseValidate, PhaseInstall, PhaseVerify, PhaseTag}; fmt.Sprint(names) != fmt.Sprint(want) {
+		t.Fatalf("phases = %v, want %v", names, want)
+	}
+	if phases[2].Duration < 20*time.Millisecond {
+		t.Errorf("verify phase = %s, want >= 20ms", phases[2].Duration)
+	}
+}
+
+// mockAgentProvider extends cloudtest.Provider with the optional
+// AgentWaiter capability.
+type mockAgentProvider struct {
+	cloudtest.Provider
+	waitErr error
+	waits   atomic.Int32
+}
+
+func (m *mockAgentProvider) WaitForAgent(context.Context, *cloud.Instance, time.Duration) error {
+	m.waits.Add(1)
+	return m.waitErr
+}
+
+// TestExecute_AgentWait tests that --wait-for-ssm waits for the agent
+// before validating and fails validation when it never comes online.
+func TestExecute_AgentWait(t *testing.T) {
+	tests := []struct {
+		name          string
+		agentWait     time.Duration
+		waitErr       error
+		wantWaits     int32
+		wantValidates int
+		wantErr       string
+	}{
+		{name: "disabled", wantWaits: 0, wantValidates: 1},
+		{name: "agent online", agentWait: 10 * time.Minute, wantWaits: 1, wantValidates: 1},
+		{name: "agent never online", agentWait: 10 * time.Minute, waitErr: errors.New("instance i-test000 not online in SSM after 10m0s"),
+			wantWaits: 1, wantValidates: 0, wantErr: "instance validation failed: instance i-test000 not online"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// ARRANGE
+			provider := &mockAgentProvider{waitErr: tt.waitErr}
+			executor := NewParallelExecutor(ExecutorConfig{
+				Provider:    provider,
+				Installer:   &mockPackageInstaller{},
+				AgentWait:   tt.agentWait,
+				SkipTagging: true,
+			})
+
+			// ACT
+			result, err := executor.Execute(context.Background(), createTestInstances(1))
+
+			// ASSERT
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := provider.waits.Load(); got != tt.wantWaits {
+				t.Errorf("WaitForAgent calls = %d, want %d", got, tt.wantWaits)
+			}
+			if got := provider.CallCount(cloudtest.MethodValidateInstance); got != tt.wantValidates {
+				t.Errorf("ValidateInstance calls = %d, want %d", got, tt.wantValidates)
+			}
+			if tt.wantErr == "" {
+				if result.Success != 1 {
+					t.Errorf("Success = %d, want 1", result.Success)
+				}
+				return
+			}
+			if err := result.Results[0].GetError(); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	// The wait counts toward the expected duration of the validation phase
+	executor := NewParallelExecutor(ExecutorConfig{Provider: &mockAgentProvider{}, Installer: &mockPackageInstaller{}, AgentWait: 10 * time.Minute})
+	if got, want := executor.heartbeat.expected[PhaseValidate], defaultExpectedPhaseDurations[PhaseValidate]+10*time.Minute; got != want {
+		t.Errorf("expected validate duration = %v, want %v", got, want)
+	}
 }
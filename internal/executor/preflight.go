@@ -0,0 +1,217 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/estudosdevops/opsmaster/internal/cloud"
+	"github.com/estudosdevops/opsmaster/internal/logger"
+)
+
+// defaultStartTimeout is how long to wait for started instances to reach running state.
+const defaultStartTimeout = 5 * time.Minute
+
+// preflightStates checks maintenance mode and lifecycle state before processing.
+// Maintenance-mode (unless includeMaintenance) and stopped/terminated instances are returned
+// as Skipped results with explicit reasons, unless startStopped is enabled, in which
+// case stopped instances are started first.
+//
+// Requires the describe capability (see cloud.CapabilitiesOf); otherwise all
+// instances are returned as runnable (validation reports unreachable instances).
+// The described metadata is returned too (nil without the capability), for
+// the instance overrides matched by tag.
+func (pe *ParallelExecutor) preflightStates(ctx context.Context, instances []*cloud.Instance) ([]*cloud.Instance, []*ExecutionResult, map[string]*cloud.InstanceInfo) {
+	if len(instances) == 0 {
+		return instances, nil, nil
+	}
+	describer := pe.providerCaps.Describe
+	if describer == nil {
+		pe.log.Info("Pre-flight state check skipped", "reason", cloud.Unsupported(pe.provider, "describing instances"))
+		return instances, nil, nil
+	}
+
+	infos, err := describer.DescribeInstances(ctx, instances)
+	if err != nil {
+		pe.log.Warn("Pre-flight state check failed, processing all instances", "error", err)
+		return instances, nil, nil
+	}
+
+	var runnable, toStart []*cloud.Instance
+	var results []*ExecutionResult
+
+	for _, instance := range instances {
+		info := infos[instance.ID]
+		state := ""
+		if info != nil {
+			state = info.State
+		}
+
+		switch {
+		case !pe.includeMaintenance && cloud.InMaintenance(info, pe.maintenanceTag):
+			results = append(results, skippedResult(instance, maintenanceReason(pe.maintenanceTag)))
+		case cloud.IsRunnableState(state):
+			runnable = append(runnable, instance)
+		case cloud.IsStartableState(state) && pe.startStopped && !pe.dryRun:
+			toStart = append(toStart, instance)
+		case cloud.IsStartableState(state) && pe.startStopped:
+			results = append(results, skippedResult(instance, fmt.Sprintf("instance is %s (dry-run: would start it)", state)))
+		default:
+			results = append(results, skippedResult(instance, fmt.Sprintf("instance is %s", state)))
+		}
+	}
+
+	if len(toStart) > 0 {
+		started, failed := pe.startInstances(ctx, toStart)
+		runnable = append(runnable, started...)
+		results = append(results, failed...)
+	}
+
+	if len(results) > 0 {
+		pe.log.Info("Pre-flight state check completed",
+			"runnable", len(runnable),
+			"started", len(toStart),
+			"not_runnable", len(results))
+	}
+
+	return runnable, results, infos
+}
+
+// ExcludeMaintenance returns the instances not in maintenance mode and a
+// Skipped result for each one that is, for commands that act on instances
+// outside the executor (e.g., run script, tags apply) and must honor the
+// maintenance tag like its pre-flight check. Empty tag uses
+// cloud.DefaultMaintenanceTagKey. The described metadata is returned too
+// (nil without the describe capability, when all instances are kept).
+//
+// Unlike the pre-flight check, a failed describe is an error: nothing
+// tells which instances are in maintenance.
+func ExcludeMaintenance(ctx context.Context, provider cloud.CloudProvider, instances []*cloud.Instance, tag string) ([]*cloud.Instance, []*ExecutionResult, map[string]*cloud.InstanceInfo, error) {
+	if tag == "" {
+		tag = cloud.DefaultMaintenanceTagKey
+	}
+	log := logger.Get()
+	describer := cloud.CapabilitiesOf(provider).Describe
+	if describer == nil || len(instances) == 0 {
+		if describer == nil {
+			log.Warn("Maintenance check skipped", "reason", cloud.Unsupported(provider, "describing instances"))
+		}
+		return instances, nil, nil, nil
+	}
+
+	infos, err := describer.DescribeInstances(ctx, instances)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to check maintenance mode: %w", err)
+	}
+
+	var kept []*cloud.Instance
+	var results []*ExecutionResult
+	for _, instance := range instances {
+		if cloud.InMaintenance(infos[instance.ID], tag) {
+			results = append(results, skippedResult(instance, maintenanceReason(tag)))
+			continue
+		}
+		kept = append(kept, instance)
+	}
+	if len(results) > 0 {
+		log.Warn("Instances in maintenance mode skipped", "count", len(results), "tag", tag)
+	}
+	return kept, results, infos, nil
+}
+
+// maintenanceReason is the skip reason of instances in maintenance mode.
+func maintenanceReason(tag string) string {
+	return fmt.Sprintf("instance in maintenance mode (%s=true)", tag)
+}
+
+// skipQuarantined returns the instances not in the quarantine list and a
+// Skipped result, with the quarantine reason, for each quarantined one.
+// Runs before any cloud API call: quarantined instances are never touched.
+func (pe *ParallelExecutor) skipQuarantined(instances []*cloud.Instance) ([]*cloud.Instance, []*ExecutionResult) {
+	kept, quarantined := pe.quarantine.Partition(instances, time.Now())
+	if len(quarantined) == 0 {
+		return kept, nil
+	}
+
+	results := make([]*ExecutionResult, 0, len(quarantined))
+	for _, match := range quarantined {
+		results = append(results, skippedResult(match.Instance, match.Entry.SkipReason()))
+	}
+	pe.log.Warn("Quarantined instances skipped",
+		"count", len(quarantined),
+		"file", pe.quarantine.Path())
+	return kept, results
+}
+
+// startInstances starts stopped instances and waits until they are running.
+// Returns instances ready for processing and Failed results for the rest.
+func (pe *ParallelExecutor) startInstances(ctx context.Context, instances []*cloud.Instance) ([]*cloud.Instance, []*ExecutionResult) {
+	starter := pe.providerCaps.Start
+	if starter == nil {
+		var results []*ExecutionResult
+		for _, instance := range instances {
+			results = append(results, skippedResult(instance,
+				fmt.Sprintf("instance is stopped (%s)", cloud.Unsupported(pe.provider, "starting instances"))))
+		}
+		return nil, results
+	}
+
+	pe.log.Info("Starting stopped instances",
+		"count", len(instances),
+		"timeout", pe.startTimeout)
+
+	startTime := time.Now()
+	failures := starter.StartInstances(ctx, instances)
+	if failures == nil {
+		failures = make(map[string]error)
+	}
+
+	var requested []*cloud.Instance
+	for _, instance := range instances {
+		if _, failed := failures[instance.ID]; !failed {
+			requested = append(requested, instance)
+		}
+	}
+
+	if len(requested) > 0 {
+		for id, err := range starter.WaitForState(ctx, requested, cloud.InstanceStateRunning, pe.startTimeout) {
+			failures[id] = err
+		}
+	}
+
+	var ready []*cloud.Instance
+	var results []*ExecutionResult
+	for _, instance := range instances {
+		err, failed := failures[instance.ID]
+		if !failed {
+			ready = append(ready, instance)
+			continue
+		}
+
+		result := &ExecutionResult{
+			Instance:  instance,
+			StartTime: startTime,
+		}
+		pe.finalizeResult(result, StatusFailed, fmt.Errorf("failed to start instance: %w", err))
+		results = append(results, result)
+	}
+
+	pe.log.Info("Stopped instances started",
+		"ready", len(ready),
+		"failed", len(results),
+		"duration", time.Since(startTime).Round(time.Second))
+
+	return ready, results
+}
+
+// skippedResult builds a Skipped result with the given reason.
+func skippedResult(instance *cloud.Instance, reason string) *ExecutionResult {
+	now := time.Now()
+	return &ExecutionResult{
+		Instance:   instance,
+		Status:     StatusSkipped,
+		SkipReason: reason,
+		StartTime:  now,
+		EndTime:    now,
+	}
+}
@@ -0,0 +1,315 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/estudosdevops/opsmaster/internal/cloud"
+	"github.com/estudosdevops/opsmaster/internal/cloud/cloudtest"
+	"github.com/estudosdevops/opsmaster/internal/quarantine"
+)
+
+// mockPowerProvider extends cloudtest.Provider with the optional
+// InstanceDescriber and InstanceStarter capabilities.
+//
+// 🎓 CONCEPT: Optional capabilities via embedding
+// Embedding keeps the CloudProvider methods; the extra methods make the
+// type assertion in preflightStates succeed.
+type mockPowerProvider struct {
+	cloudtest.Provider
+	states     map[string]string            // instance ID -> state
+	tags       map[string]map[string]string // instance ID -> tags
+	startErr   map[string]error             // instance ID -> StartInstances error
+	startCalls int
+	described  []string // instance IDs passed to DescribeInstances
+}
+
+func (m *mockPowerProvider) DescribeInstances(_ context.Context, instances []*cloud.Instance) (map[string]*cloud.InstanceInfo, error) {
+	infos := make(map[string]*cloud.InstanceInfo)
+	for _, instance := range instances {
+		m.described = append(m.described, instance.ID)
+		if state, ok := m.states[instance.ID]; ok {
+			infos[instance.ID] = &cloud.InstanceInfo{ID: instance.ID, State: state, Tags: m.tags[instance.ID]}
+		}
+	}
+	return infos, nil
+}
+
+func (m *mockPowerProvider) StartInstances(_ context.Context, instances []*cloud.Instance) map[string]error {
+	m.startCalls++
+	failures := make(map[string]error)
+	for _, instance := range instances {
+		if err := m.startErr[instance.ID]; err != nil {
+			failures[instance.ID] = err
+			continue
+		}
+		m.states[instance.ID] = cloud.InstanceStateRunning
+	}
+	return failures
+}
+
+func (*mockPowerProvider) WaitForState(_ context.Context, _ []*cloud.Instance, _ string, _ time.Duration) map[string]error {
+	return map[string]error{}
+}
+
+// TestExecute_PreflightSkipsNonRunning tests that stopped/terminated instances are skipped
+func TestExecute_PreflightSkipsNonRunning(t *testing.T) {
+	// ARRANGE
+	instances := createTestInstances(3)
+	provider := &mockPowerProvider{
+		states: map[string]string{
+			instances[0].ID: cloud.InstanceStateRunning,
+			instances[1].ID: cloud.InstanceStateStopped,
+			instances[2].ID: cloud.InstanceStateTerminated,
+		},
+	}
+
+	executor := NewParallelExecutor(ExecutorConfig{
+		Provider:  provider,
+		Installer: &mockPackageInstaller{},
+	})
+
+	// ACT
+	result, err := executor.Execute(context.Background(), instances)
+
+	// ASSERT
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Success != 1 || result.Skipped != 2 {
+		t.Fatalf("Success = %d, Skipped = %d, want 1 and 2", result.Success, result.Skipped)
+	}
+	if provider.startCalls != 0 {
+		t.Error("StartInstances should not be called without StartStopped")
+	}
+	if got := provider.CallCount(cloudtest.MethodValidateInstance); got != 1 {
+		t.Errorf("ValidateInstance called %d times, want 1", got)
+	}
+
+	for _, r := range result.Results {
+		if r.Status != StatusSkipped {
+			continue
+		}
+		expected := "instance is " + provider.states[r.Instance.ID]
+		if r.SkipReason != expected {
+			t.Errorf("SkipReason = %q, want %q", r.SkipReason, expected)
+		}
+	}
+}
+
+// TestExecute_PreflightStartsStopped tests that stopped instances are started when requested
+func TestExecute_PreflightStartsStopped(t *testing.T) {
+	// ARRANGE
+	instances := createTestInstances(2)
+	provider := &mockPowerProvider{
+		states: map[string]string{
+			instances[0].ID: cloud.InstanceStateStopped,
+			instances[1].ID: cloud.InstanceStateStopped,
+		},
+		startErr: map[string]error{
+			instances[1].ID: errors.New("InsufficientInstanceCapacity"),
+		},
+	}
+
+	executor := NewParallelExecutor(ExecutorConfig{
+		Provider:     provider,
+		Installer:    &mockPackageInstaller{},
+		StartStopped: true,
+	})
+
+	// ACT
+	result, err := executor.Execute(context.Background(), instances)
+
+	// ASSERT
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Success != 1 || result.Failed != 1 {
+		t.Fatalf("Success = %d, Failed = %d, want 1 and 1", result.Success, result.Failed)
+	}
+
+	for _, r := range result.Results {
+		if r.Failed() && !strings.Contains(r.GetError().Error(), "failed to start instance") {
+			t.Errorf("unexpected error for %s: %v", r.Instance.ID, r.GetError())
+		}
+	}
+}
+
+// TestExecute_PreflightDryRunDoesNotStart tests that dry-run never starts instances
+func TestExecute_PreflightDryRunDoesNotStart(t *testing.T) {
+	instances := createTestInstances(1)
+	provider := &mockPowerProvider{
+		states: map[string]string{instances[0].ID: cloud.InstanceStateStopped},
+	}
+
+	executor := NewParallelExecutor(ExecutorConfig{
+		Provider:     provider,
+		Installer:    &mockPackageInstaller{},
+		StartStopped: true,
+		DryRun:       true,
+	})
+
+	result, err := executor.Execute(context.Background(), instances)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if provider.startCalls != 0 {
+		t.Error("StartInstances should not be called in dry-run")
+	}
+	if result.Skipped != 1 || !strings.Contains(result.Results[0].SkipReason, "would start") {
+		t.Errorf("expected dry-run skip reason, got %+v", result.Results[0])
+	}
+}
+
+// TestExecute_PreflightMaintenanceMode tests that maintenance-tagged instances are skipped
+// unless IncludeMaintenance is set
+func TestExecute_PreflightMaintenanceMode(t *testing.T) {
+	tests := []struct {
+		name               string
+		maintenanceTag     string
+		includeMaintenance bool
+		tags               map[string]string
+		wantSkipped        int
+	}{
+		{
+			name:        "default tag skips instance",
+			tags:        map[string]string{cloud.DefaultMaintenanceTagKey: "true"},
+			wantSkipped: 1,
+		},
+		{
+			name:               "include maintenance processes instance",
+			tags:               map[string]string{cloud.DefaultMaintenanceTagKey: "true"},
+			includeMaintenance: true,
+			wantSkipped:        0,
+		},
+		{
+			name:           "custom tag key",
+			maintenanceTag: "ops:frozen",
+			tags:           map[string]string{"ops:frozen": "TRUE"},
+			wantSkipped:    1,
+		},
+		{
+			name:        "tag set to false is ignored",
+			tags:        map[string]string{cloud.DefaultMaintenanceTagKey: "false"},
+			wantSkipped: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// ARRANGE
+			instances := createTestInstances(1)
+			provider := &mockPowerProvider{
+				states: map[string]string{instances[0].ID: cloud.InstanceStateRunning},
+				tags:   map[string]map[string]string{instances[0].ID: tt.tags},
+			}
+
+			executor := NewParallelExecutor(ExecutorConfig{
+				Provider:           provider,
+				Installer:          &mockPackageInstaller{},
+				MaintenanceTag:     tt.maintenanceTag,
+				IncludeMaintenance: tt.includeMaintenance,
+			})
+
+			// ACT
+			result, err := executor.Execute(context.Background(), instances)
+
+			// ASSERT
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.Skipped != tt.wantSkipped {
+				t.Fatalf("Skipped = %d, want %d", result.Skipped, tt.wantSkipped)
+			}
+			if tt.wantSkipped > 0 && !strings.Contains(result.Results[0].SkipReason, "maintenance mode") {
+				t.Errorf("unexpected skip reason: %q", result.Results[0].SkipReason)
+			}
+		})
+	}
+}
+
+// TestExecute_SkipsQuarantined tests that quarantined instances are skipped
+// with their reason before any provider call
+func TestExecute_SkipsQuarantined(t *testing.T) {
+	// ARRANGE
+	instances := createTestInstances(2)
+	provider := &mockPowerProvider{
+		states: map[string]string{instances[1].ID: cloud.InstanceStateRunning},
+	}
+	executor := NewParallelExecutor(ExecutorConfig{
+		Provider:  provider,
+		Installer: &mockPackageInstaller{},
+		Quarantine: quarantine.New(quarantine.Entry{
+			InstanceID: instances[0].ID,
+			Reason:     "kernel panic after reboot",
+			Expires:    time.Now().Add(time.Hour),
+		}),
+	})
+
+	// ACT
+	result, err := executor.Execute(context.Background(), instances)
+
+	// ASSERT
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Skipped != 1 || result.Success != 1 {
+		t.Fatalf("expected 1 skipped and 1 success, got %s", result)
+	}
+	for _, r := range result.Results {
+		if r.Instance.ID != instances[0].ID {
+			continue
+		}
+		if !strings.HasPrefix(r.SkipReason, "quarantined: kernel panic after reboot (until ") {
+			t.Errorf("SkipReason = %q", r.SkipReason)
+		}
+	}
+	if len(provider.described) != 1 || provider.described[0] != instances[1].ID {
+		t.Errorf("quarantined instance reached the provider: described %v", provider.described)
+	}
+}
+
+// TestExcludeMaintenance tests the maintenance filter of commands that run
+// outside the executor
+func TestExcludeMaintenance(t *testing.T) {
+	// ARRANGE
+	instances := createTestInstances(3)
+	provider := &mockPowerProvider{
+		states: map[string]string{
+			instances[0].ID: cloud.InstanceStateRunning,
+			instances[1].ID: cloud.InstanceStateRunning,
+			instances[2].ID: cloud.InstanceStateRunning,
+		},
+		tags: map[string]map[string]string{
+			instances[1].ID: {cloud.DefaultMaintenanceTagKey: "true"},
+			instances[2].ID: {"ops:frozen": "true"},
+		},
+	}
+
+	// ACT
+	kept, skipped, infos, err := ExcludeMaintenance(context.Background(), provider, instances, "")
+
+	// ASSERT
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(kept) != 2 || kept[0] != instances[0] || kept[1] != instances[2] {
+		t.Errorf("kept = %v, want instances 0 and 2", kept)
+	}
+	if len(skipped) != 1 || skipped[0].Instance != instances[1] || skipped[0].Status != StatusSkipped ||
+		!strings.Contains(skipped[0].SkipReason, "maintenance mode (opsmaster:maintenance=true)") {
+		t.Errorf("skipped = %+v, want instance 1 in maintenance mode", skipped)
+	}
+	if len(infos) != 3 {
+		t.Errorf("infos = %d, want the described metadata of the 3 instances", len(infos))
+	}
+
+	// Without the describe capability every instance is kept
+	kept, skipped, _, err = ExcludeMaintenance(context.Background(), &cloudtest.Provider{}, instances, "ops:frozen")
+	if err != nil || len(kept) != 3 || len(skipped) != 0 {
+		t.Errorf("without describe: kept = %d, skipped = %d, err = %v; want all kept", len(kept), len(skipped), err)
+	}
+}
@@ -0,0 +1,157 @@
+package executor
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/estudosdevops/opsmaster/internal/cloud"
+)
+
+// workItem is one attempt of an instance waiting in the work queue.
+type workItem struct {
+	instance *cloud.Instance
+	attempt  int             // 1 = first attempt, >1 = requeued after a failure
+	group    string          // Concurrency group ("" = none)
+	history  []AttemptRecord // Earlier attempts, oldest first
+	seq      uint64          // Insertion order, FIFO within the same attempt
+
+	// Run-level settings with the instance overrides applied (see settingsFor)
+	settings *instanceSettings
+}
+
+// workQueue is a concurrency-safe priority queue of instance attempts.
+// First attempts are dispatched before requeues (in insertion order within
+// each attempt), so retries never interleave with fresh instances. Items
+// whose concurrency group is at its limit are passed over until a slot frees
+// up, without holding a worker.
+//
+// The queue drains itself: pop returns false once no item is queued or in
+// flight (nothing can be requeued anymore), or when ctx is canceled or the
+// dispatch is stopped. While paused, queued items wait.
+type workQueue struct {
+	mu          sync.Mutex
+	cond        *sync.Cond
+	items       []*workItem // Sorted by (attempt, seq)
+	seq         uint64
+	maxPerGroup int            // Max items in flight per group (0 = no limit)
+	running     map[string]int // Items in flight per group
+	inFlight    int
+	paused      bool // Nothing dispatched while set (see RunControl)
+	stopped     bool // Dispatch stopped for good: pop returns false
+}
+
+// newWorkQueue creates an empty queue with a per-group limit (0 = no limit).
+func newWorkQueue(maxPerGroup int) *workQueue {
+	q := &workQueue{maxPerGroup: maxPerGroup, running: make(map[string]int)}
+	q.cond = sync.NewCond(&q.mu)
+	return q
+}
+
+// push queues an item behind the items of the same or a lower attempt.
+func (q *workQueue) push(item *workItem) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.insert(item)
+	q.cond.Broadcast()
+}
+
+// insert adds an item in priority order. Caller must hold mu.
+func (q *workQueue) insert(item *workItem) {
+	q.seq++
+	item.seq = q.seq
+	i := sort.Search(len(q.items), func(i int) bool { return q.items[i].attempt > item.attempt })
+	q.items = append(q.items, nil)
+	copy(q.items[i+1:], q.items[i:])
+	q.items[i] = item
+}
+
+// pop waits for the highest-priority item whose group has a free slot and
+// marks it in flight. Returns false when the queue is drained or ctx is
+// canceled (items left queued are returned by remaining).
+func (q *workQueue) pop(ctx context.Context) (*workItem, bool) {
+	stop := context.AfterFunc(ctx, func() {
+		q.mu.Lock()
+		defer q.mu.Unlock()
+		q.cond.Broadcast()
+	})
+	defer stop()
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for {
+		if ctx.Err() != nil || q.stopped {
+			return nil, false
+		}
+		for i, item := range q.items {
+			if q.paused {
+				break
+			}
+			if q.maxPerGroup > 0 && item.group != "" && q.running[item.group] >= q.maxPerGroup {
+				continue
+			}
+			q.items = append(q.items[:i], q.items[i+1:]...)
+			q.running[item.group]++
+			q.inFlight++
+			return item, true
+		}
+		if len(q.items) == 0 && q.inFlight == 0 {
+			return nil, false
+		}
+		q.cond.Wait()
+	}
+}
+
+// done releases an item popped from the queue and queues requeue (if not
+// nil) atomically, so no worker sees a drained queue in between.
+func (q *workQueue) done(item, requeue *workItem) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.running[item.group]--
+	q.inFlight--
+	if requeue != nil {
+		q.insert(requeue)
+	}
+	q.cond.Broadcast()
+}
+
+// setPaused pauses or resumes the dispatch of queued items. Items in
+// flight are not affected.
+func (q *workQueue) setPaused(paused bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.paused = paused
+	q.cond.Broadcast()
+}
+
+// stop stops the dispatch for good: pop returns false and the items left
+// queued are returned by remaining.
+func (q *workQueue) stop() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.stopped = true
+	q.cond.Broadcast()
+}
+
+// counts returns the number of queued and in-flight items.
+func (q *workQueue) counts() (queued, inFlight int) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.items), q.inFlight
+}
+
+// len returns the number of queued items (in-flight items excluded).
+func (q *workQueue) len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.items)
+}
+
+// remaining removes and returns the items still queued.
+func (q *workQueue) remaining() []*workItem {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	items := q.items
+	q.items = nil
+	return items
+}
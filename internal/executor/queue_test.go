@@ -0,0 +1,176 @@
+package executor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/estudosdevops/opsmaster/internal/cloud"
+)
+
+// popIDs pops n items without blocking forever, returning their instance IDs.
+func popIDs(t *testing.T, q *workQueue, n int) []string {
+	t.Helper()
+	ids := make([]string, 0, n)
+	for range n {
+		item, ok := q.pop(context.Background())
+		if !ok {
+			t.Fatalf("pop() returned false after %v", ids)
+		}
+		ids = append(ids, item.instance.ID)
+	}
+	return ids
+}
+
+func newItem(id string, attempt int, group string) *workItem {
+	return &workItem{instance: &cloud.Instance{ID: id}, attempt: attempt, group: group}
+}
+
+// TestWorkQueue_Priority tests that first attempts are popped before requeues
+func TestWorkQueue_Priority(t *testing.T) {
+	q := newWorkQueue(0)
+	q.push(newItem("i-a", 1, ""))
+	q.push(newItem("i-retry", 2, ""))
+	q.push(newItem("i-b", 1, ""))
+	q.push(newItem("i-retry-again", 3, ""))
+	q.push(newItem("i-c", 1, ""))
+
+	got := popIDs(t, q, 5)
+
+	want := []string{"i-a", "i-b", "i-c", "i-retry", "i-retry-again"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("pop order = %v, want %v", got, want)
+		}
+	}
+	if q.len() != 0 {
+		t.Errorf("len() = %d, want 0", q.len())
+	}
+}
+
+// TestWorkQueue_GroupLimit tests that items of a busy group are passed over
+// until a slot frees up
+func TestWorkQueue_GroupLimit(t *testing.T) {
+	q := newWorkQueue(1)
+	first := newItem("i-a", 1, "puppet-1")
+	q.push(first)
+	q.push(newItem("i-b", 1, "puppet-1"))
+	q.push(newItem("i-c", 1, "puppet-2"))
+
+	if got := popIDs(t, q, 2); got[0] != "i-a" || got[1] != "i-c" {
+		t.Fatalf("pop order = %v, want [i-a i-c]", got)
+	}
+
+	popped := make(chan string, 1)
+	go func() { popped <- popIDs(t, q, 1)[0] }()
+	select {
+	case id := <-popped:
+		t.Fatalf("popped %s while its group was busy", id)
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	q.done(first, nil)
+	select {
+	case id := <-popped:
+		if id != "i-b" {
+			t.Errorf("popped %s, want i-b", id)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("pop() did not return after the group slot was released")
+	}
+}
+
+// TestWorkQueue_Drain tests that pop returns false once nothing is queued or
+// in flight, and that requeues keep the queue open
+func TestWorkQueue_Drain(t *testing.T) {
+	q := newWorkQueue(0)
+	q.push(newItem("i-a", 1, ""))
+
+	item, _ := q.pop(context.Background())
+	q.done(item, newItem("i-a", 2, ""))
+	retry, ok := q.pop(context.Background())
+	if !ok || retry.attempt != 2 {
+		t.Fatalf("expected requeued item, got %+v %v", retry, ok)
+	}
+	q.done(retry, nil)
+
+	if _, ok := q.pop(context.Background()); ok {
+		t.Error("pop() on drained queue returned an item")
+	}
+}
+
+// TestWorkQueue_Cancel tests that a blocked pop returns on cancellation and
+// leaves queued items to remaining
+func TestWorkQueue_Cancel(t *testing.T) {
+	q := newWorkQueue(1)
+	q.push(newItem("i-a", 1, "g"))
+	q.push(newItem("i-b", 1, "g"))
+	popIDs(t, q, 1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan bool, 1)
+	go func() {
+		_, ok := q.pop(ctx)
+		done <- ok
+	}()
+	cancel()
+
+	select {
+	case ok := <-done:
+		if ok {
+			t.Error("pop() returned an item after cancellation")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("pop() did not return after cancellation")
+	}
+	if items := q.remaining(); len(items) != 1 || items[0].instance.ID != "i-b" {
+		t.Errorf("remaining() = %v, want [i-b]", items)
+	}
+}
+
+// TestWorkQueue_Pause tests that nothing is popped while paused and that
+// resuming dispatches the queued items
+func TestWorkQueue_Pause(t *testing.T) {
+	q := newWorkQueue(0)
+	q.push(newItem("i-a", 1, ""))
+	q.setPaused(true)
+
+	popped := make(chan string, 1)
+	go func() { popped <- popIDs(t, q, 1)[0] }()
+	select {
+	case id := <-popped:
+		t.Fatalf("popped %s while paused", id)
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	q.setPaused(false)
+	select {
+	case id := <-popped:
+		if id != "i-a" {
+			t.Errorf("popped %s, want i-a", id)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("pop() did not return after resuming")
+	}
+}
+
+// TestWorkQueue_Stop tests that a stopped queue pops nothing and leaves
+// queued items to remaining
+func TestWorkQueue_Stop(t *testing.T) {
+	q := newWorkQueue(0)
+	q.push(newItem("i-a", 1, ""))
+	q.push(newItem("i-b", 1, ""))
+	popIDs(t, q, 1)
+
+	q.stop()
+
+	if _, ok := q.pop(context.Background()); ok {
+		t.Error("pop() on stopped queue returned an item")
+	}
+	if queued, inFlight := q.counts(); queued != 1 || inFlight != 1 {
+		t.Errorf("counts() = %d, %d, want 1, 1", queued, inFlight)
+	}
+	if items := q.remaining(); len(items) != 1 || items[0].instance.ID != "i-b" {
+		t.Errorf("remaining() = %v, want [i-b]", items)
+	}
+}
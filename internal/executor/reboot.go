@@ -0,0 +1,270 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/estudosdevops/opsmaster/internal/cloud"
+	"github.com/estudosdevops/opsmaster/internal/logger"
+)
+
+// Reboot defaults.
+const (
+	defaultRebootWaitTimeout  = 10 * time.Minute
+	defaultRebootPollInterval = 15 * time.Second
+	rebootCommandTimeout      = 30 * time.Second
+)
+
+// bootIDCommand prints the Linux boot ID, which changes on every boot.
+// Comparing it before and after the reboot tells a rebooted instance apart
+// from one that didn't go down yet.
+const bootIDCommand = "cat /proc/sys/kernel/random/boot_id"
+
+// Reboot result statuses.
+const (
+	RebootStatusHealthy   = "healthy"   // Back online (and post-check passed)
+	RebootStatusRebooted  = "rebooted"  // Reboot requested, not waited for
+	RebootStatusUnhealthy = "unhealthy" // Didn't come back or post-check failed
+	RebootStatusFailed    = "failed"    // Reboot could not be requested
+	RebootStatusSkipped   = "skipped"   // Not rebooted (maintenance, state, earlier batch failed)
+)
+
+// RebootConfig configures a rolling reboot.
+type RebootConfig struct {
+	Provider           cloud.CloudProvider // Must implement cloud.InstanceRebooter
+	BatchSize          int                 // Instances rebooted at once (default: 1)
+	Wait               bool                // Wait for instances to come back online
+	WaitTimeout        time.Duration       // Max wait per batch (default: 10m)
+	PollInterval       time.Duration       // Interval between boot checks (default: 15s)
+	PostCheck          string              // Shell command that must succeed after boot (requires Wait)
+	ContinueOnFailure  bool                // Keep rebooting batches after an unhealthy one
+	MaintenanceTag     string              // Tag key marking maintenance mode (default: opsmaster:maintenance)
+	IncludeMaintenance bool                // Reboot instances in maintenance mode anyway
+}
+
+// RebootResult is the outcome of the reboot of one instance.
+type RebootResult struct {
+	Instance *cloud.Instance
+	Batch    int    // 1-based batch number (0 = skipped in pre-flight)
+	Status   string // One of the RebootStatus constants
+	Detail   string // Skip reason, error or failed post-check output
+	Duration time.Duration
+}
+
+// Reboot reboots instances in sequential batches. With Wait, each batch must
+// come back (new boot ID over the provider's command channel) and pass the
+// post-check before the next batch starts; an unhealthy batch stops the
+// rollout unless ContinueOnFailure is set.
+//
+// Returns one result per instance, in input order.
+func Reboot(ctx context.Context, config RebootConfig, instances []*cloud.Instance) ([]*RebootResult, error) {
+	rebooter := cloud.CapabilitiesOf(config.Provider).Reboot
+	if rebooter == nil {
+		return nil, cloud.Unsupported(config.Provider, "rebooting instances")
+	}
+	if config.PostCheck != "" && !config.Wait {
+		return nil, fmt.Errorf("post-check requires waiting for instances to come back")
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = 1
+	}
+	if config.WaitTimeout <= 0 {
+		config.WaitTimeout = defaultRebootWaitTimeout
+	}
+	if config.PollInterval <= 0 {
+		config.PollInterval = defaultRebootPollInterval
+	}
+	if config.MaintenanceTag == "" {
+		config.MaintenanceTag = cloud.DefaultMaintenanceTagKey
+	}
+
+	results := make([]*RebootResult, len(instances))
+	resultFor := make(map[*cloud.Instance]*RebootResult, len(instances))
+	for i, instance := range instances {
+		results[i] = &RebootResult{Instance: instance}
+		resultFor[instance] = results[i]
+	}
+
+	targets := rebootPreflight(ctx, config, instances, resultFor)
+	log := logger.Get()
+
+	stopped := false
+	for start, batch := 0, 1; start < len(targets); start, batch = start+config.BatchSize, batch+1 {
+		members := targets[start:min(start+config.BatchSize, len(targets))]
+		if stopped || ctx.Err() != nil {
+			reason := "not rebooted: an earlier batch was unhealthy"
+			if ctx.Err() != nil {
+				reason = "not rebooted: canceled"
+			}
+			for _, instance := range members {
+				resultFor[instance].Status, resultFor[instance].Detail = RebootStatusSkipped, reason
+			}
+			continue
+		}
+
+		log.Info("🔄 Rebooting batch", "batch", batch, "instances", len(members))
+		rebootBatch(ctx, config, rebooter, members, batch, resultFor)
+
+		for _, instance := range members {
+			if resultFor[instance].Status != RebootStatusHealthy && resultFor[instance].Status != RebootStatusRebooted {
+				stopped = !config.ContinueOnFailure
+			}
+		}
+	}
+
+	return results, nil
+}
+
+// rebootPreflight marks maintenance-mode and non-running instances as
+// skipped and returns the instances to reboot.
+func rebootPreflight(ctx context.Context, config RebootConfig, instances []*cloud.Instance, resultFor map[*cloud.Instance]*RebootResult) []*cloud.Instance {
+	describer := cloud.CapabilitiesOf(config.Provider).Describe
+	if describer == nil {
+		logger.Get().Warn("Pre-flight state check skipped", "reason", cloud.Unsupported(config.Provider, "describing instances"))
+		return instances
+	}
+	infos, err := describer.DescribeInstances(ctx, instances)
+	if err != nil {
+		logger.Get().Warn("Pre-flight state check failed, rebooting all instances", "error", err)
+		return instances
+	}
+
+	var targets []*cloud.Instance
+	for _, instance := range instances {
+		info := infos[instance.ID]
+		switch {
+		case !config.IncludeMaintenance && cloud.InMaintenance(info, config.MaintenanceTag):
+			resultFor[instance].Status = RebootStatusSkipped
+			resultFor[instance].Detail = maintenanceReason(config.MaintenanceTag)
+		case info != nil && info.State != cloud.InstanceStateRunning:
+			resultFor[instance].Status = RebootStatusSkipped
+			resultFor[instance].Detail = "instance is " + info.State
+		default:
+			targets = append(targets, instance)
+		}
+	}
+	return targets
+}
+
+// rebootBatch records boot IDs, reboots the batch and (with Wait) waits for
+// each instance to boot and pass the post-check.
+func rebootBatch(ctx context.Context, config RebootConfig, rebooter cloud.InstanceRebooter, members []*cloud.Instance, batch int, resultFor map[*cloud.Instance]*RebootResult) {
+	start := time.Now()
+	bootIDs := make(map[*cloud.Instance]string, len(members))
+	var mu sync.Mutex
+	var reboot []*cloud.Instance
+
+	// Boot IDs are only needed to detect the reboot when waiting
+	if config.Wait {
+		errs := ForEachInstance(ctx, members, len(members), func(ctx context.Context, instance *cloud.Instance) error {
+			id, err := readBootID(ctx, config.Provider, instance)
+			if err == nil {
+				mu.Lock()
+				bootIDs[instance] = id
+				mu.Unlock()
+			}
+			return err
+		})
+		for i, instance := range members {
+			resultFor[instance].Batch = batch
+			if errs[i] != nil {
+				resultFor[instance].Status = RebootStatusFailed
+				resultFor[instance].Detail = fmt.Sprintf("not rebooted: failed to read boot ID: %v", errs[i])
+				continue
+			}
+			reboot = append(reboot, instance)
+		}
+	} else {
+		reboot = members
+	}
+
+	failures := map[string]error{}
+	if len(reboot) > 0 {
+		failures = rebooter.RebootInstances(ctx, reboot)
+	}
+
+	var waiting []*cloud.Instance
+	for _, instance := range reboot {
+		result := resultFor[instance]
+		result.Batch = batch
+		if err := failures[instance.ID]; err != nil {
+			result.Status, result.Detail = RebootStatusFailed, err.Error()
+			continue
+		}
+		if !config.Wait {
+			result.Status = RebootStatusRebooted
+			continue
+		}
+		waiting = append(waiting, instance)
+	}
+
+	waitCtx, cancel := context.WithTimeout(ctx, config.WaitTimeout)
+	defer cancel()
+	errs := ForEachInstance(waitCtx, waiting, len(waiting), func(waitCtx context.Context, instance *cloud.Instance) error {
+		if err := waitForBoot(waitCtx, config, instance, bootIDs[instance]); err != nil {
+			return err
+		}
+		return runPostCheck(ctx, config, instance)
+	})
+	for i, instance := range waiting {
+		result := resultFor[instance]
+		if errs[i] != nil {
+			result.Status, result.Detail = RebootStatusUnhealthy, errs[i].Error()
+			continue
+		}
+		result.Status = RebootStatusHealthy
+	}
+
+	for _, instance := range members {
+		resultFor[instance].Duration = time.Since(start)
+	}
+}
+
+// readBootID returns the current boot ID of an instance.
+func readBootID(ctx context.Context, provider cloud.CloudProvider, instance *cloud.Instance) (string, error) {
+	result, err := provider.ExecuteCommandWithOptions(cloud.ReadOnlyCommands(ctx), instance, []string{bootIDCommand}, rebootCommandTimeout,
+		cloud.ExecOptions{Comment: "reboot boot-id"})
+	if err != nil {
+		return "", err
+	}
+	id := strings.TrimSpace(result.Stdout)
+	if result.ExitCode != 0 || id == "" {
+		return "", fmt.Errorf("exit code %d: %s", result.ExitCode, strings.TrimSpace(result.Stderr))
+	}
+	return id, nil
+}
+
+// waitForBoot polls the boot ID until it changes. Commands fail or time out
+// while the instance is down, so errors just mean "not back yet".
+func waitForBoot(ctx context.Context, config RebootConfig, instance *cloud.Instance, previous string) error {
+	for {
+		if id, err := readBootID(ctx, config.Provider, instance); err == nil && id != previous {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("instance did not come back within %s", config.WaitTimeout)
+		case <-time.After(config.PollInterval):
+		}
+	}
+}
+
+// runPostCheck runs the post-check command once the instance is back.
+func runPostCheck(ctx context.Context, config RebootConfig, instance *cloud.Instance) error {
+	if config.PostCheck == "" {
+		return nil
+	}
+	result, err := config.Provider.ExecuteCommandWithOptions(ctx, instance, []string{config.PostCheck}, rebootCommandTimeout,
+		cloud.ExecOptions{Comment: "reboot post-check"})
+	if err != nil {
+		return fmt.Errorf("post-check failed: %w", err)
+	}
+	if result.ExitCode != 0 {
+		output := strings.TrimSpace(result.Stdout + "\n" + result.Stderr)
+		return fmt.Errorf("post-check failed (exit code %d): %s", result.ExitCode, output)
+	}
+	return nil
+}
@@ -0,0 +1,174 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/estudosdevops/opsmaster/internal/cloud"
+	"github.com/estudosdevops/opsmaster/internal/cloud/cloudtest"
+)
+
+// rebootingProvider simulates reboots: a rebooted instance reports a new
+// boot ID and runs the post-check with the configured exit code.
+type rebootingProvider struct {
+	cloudtest.Provider
+	infos         map[string]*cloud.InstanceInfo
+	rebootErr     map[string]error
+	neverBack     map[string]bool // Instances that keep the old boot ID
+	postCheckExit map[string]int
+
+	mu       sync.Mutex
+	boots    map[string]int
+	rebooted [][]string // Instance IDs per RebootInstances call
+}
+
+func newRebootingProvider() *rebootingProvider {
+	p := &rebootingProvider{boots: map[string]int{}}
+	p.ExecuteCommandFunc = func(_ context.Context, instance *cloud.Instance, commands []string, _ time.Duration) (*cloud.CommandResult, error) {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		if commands[0] == bootIDCommand {
+			return &cloud.CommandResult{Stdout: fmt.Sprintf("boot-%d\n", p.boots[instance.ID])}, nil
+		}
+		return &cloud.CommandResult{ExitCode: p.postCheckExit[instance.ID], Stdout: "inactive"}, nil
+	}
+	return p
+}
+
+func (p *rebootingProvider) RebootInstances(_ context.Context, instances []*cloud.Instance) map[string]error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var ids []string
+	failures := map[string]error{}
+	for _, instance := range instances {
+		ids = append(ids, instance.ID)
+		if err := p.rebootErr[instance.ID]; err != nil {
+			failures[instance.ID] = err
+			continue
+		}
+		if !p.neverBack[instance.ID] {
+			p.boots[instance.ID]++
+		}
+	}
+	p.rebooted = append(p.rebooted, ids)
+	return failures
+}
+
+func (p *rebootingProvider) DescribeInstances(_ context.Context, _ []*cloud.Instance) (map[string]*cloud.InstanceInfo, error) {
+	return p.infos, nil
+}
+
+// TestReboot tests batching, health checks and stopping after a bad batch
+//
+// 🎓 CONCEPT: Boot ID
+// The fake bumps the boot ID on reboot, exactly what the executor polls
+// for, so "came back" and "never went down" are both testable.
+func TestReboot(t *testing.T) {
+	instances := []*cloud.Instance{{ID: "i-1"}, {ID: "i-2"}, {ID: "i-3"}, {ID: "i-4"}}
+
+	tests := []struct {
+		name        string
+		config      RebootConfig
+		setup       func(p *rebootingProvider)
+		wantStatus  []string
+		wantBatches string
+	}{
+		{
+			name:        "all healthy in batches of two",
+			config:      RebootConfig{BatchSize: 2, Wait: true, PostCheck: "systemctl is-active puppet"},
+			wantStatus:  []string{RebootStatusHealthy, RebootStatusHealthy, RebootStatusHealthy, RebootStatusHealthy},
+			wantBatches: "i-1 i-2|i-3 i-4",
+		},
+		{
+			name:        "no wait",
+			config:      RebootConfig{BatchSize: 4},
+			wantStatus:  []string{RebootStatusRebooted, RebootStatusRebooted, RebootStatusRebooted, RebootStatusRebooted},
+			wantBatches: "i-1 i-2 i-3 i-4",
+		},
+		{
+			name:        "failed post-check stops the rollout",
+			config:      RebootConfig{BatchSize: 2, Wait: true, PostCheck: "systemctl is-active puppet"},
+			setup:       func(p *rebootingProvider) { p.postCheckExit = map[string]int{"i-2": 3} },
+			wantStatus:  []string{RebootStatusHealthy, RebootStatusUnhealthy, RebootStatusSkipped, RebootStatusSkipped},
+			wantBatches: "i-1 i-2",
+		},
+		{
+			name:        "continue on failure",
+			config:      RebootConfig{BatchSize: 2, Wait: true, ContinueOnFailure: true},
+			setup:       func(p *rebootingProvider) { p.rebootErr = map[string]error{"i-1": fmt.Errorf("IncorrectState")} },
+			wantStatus:  []string{RebootStatusFailed, RebootStatusHealthy, RebootStatusHealthy, RebootStatusHealthy},
+			wantBatches: "i-1 i-2|i-3 i-4",
+		},
+		{
+			name:        "instance never comes back",
+			config:      RebootConfig{BatchSize: 4, Wait: true, WaitTimeout: 50 * time.Millisecond},
+			setup:       func(p *rebootingProvider) { p.neverBack = map[string]bool{"i-3": true} },
+			wantStatus:  []string{RebootStatusHealthy, RebootStatusHealthy, RebootStatusUnhealthy, RebootStatusHealthy},
+			wantBatches: "i-1 i-2 i-3 i-4",
+		},
+		{
+			name:   "maintenance and stopped instances are skipped",
+			config: RebootConfig{BatchSize: 4, Wait: true},
+			setup: func(p *rebootingProvider) {
+				p.infos = map[string]*cloud.InstanceInfo{
+					"i-1": {State: cloud.InstanceStateRunning, Tags: map[string]string{cloud.DefaultMaintenanceTagKey: "true"}},
+					"i-2": {State: "stopped"},
+					"i-3": {State: cloud.InstanceStateRunning},
+					"i-4": {State: cloud.InstanceStateRunning},
+				}
+			},
+			wantStatus:  []string{RebootStatusSkipped, RebootStatusSkipped, RebootStatusHealthy, RebootStatusHealthy},
+			wantBatches: "i-3 i-4",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// ARRANGE
+			p := newRebootingProvider()
+			if tt.setup != nil {
+				tt.setup(p)
+			}
+			tt.config.Provider = p
+			tt.config.PollInterval = time.Millisecond
+
+			// ACT
+			results, err := Reboot(context.Background(), tt.config, instances)
+
+			// ASSERT
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for i, r := range results {
+				if r.Status != tt.wantStatus[i] {
+					t.Errorf("%s: status = %s (%s), want %s", r.Instance.ID, r.Status, r.Detail, tt.wantStatus[i])
+				}
+			}
+			var batches []string
+			for _, ids := range p.rebooted {
+				batches = append(batches, strings.Join(ids, " "))
+			}
+			if got := strings.Join(batches, "|"); got != tt.wantBatches {
+				t.Errorf("batches = %q, want %q", got, tt.wantBatches)
+			}
+		})
+	}
+}
+
+// TestReboot_Validation tests configuration errors
+func TestReboot_Validation(t *testing.T) {
+	instances := []*cloud.Instance{{ID: "i-1"}}
+
+	if _, err := Reboot(context.Background(), RebootConfig{Provider: &cloudtest.Provider{}}, instances); err == nil {
+		t.Error("expected error for provider without reboot support")
+	}
+
+	_, err := Reboot(context.Background(), RebootConfig{Provider: newRebootingProvider(), PostCheck: "true"}, instances)
+	if err == nil || !strings.Contains(err.Error(), "post-check requires") {
+		t.Errorf("expected post-check error, got %v", err)
+	}
+}
@@ -0,0 +1,315 @@
+package executor
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/estudosdevops/opsmaster/internal/cloud"
+)
+
+// ReportSchemaVersion is the schema_version of reports written by this
+// build. Bumped on incompatible changes to the Report format; the matching
+// JSON schema is embedded in internal/schema.
+const ReportSchemaVersion = 1
+
+// Report is the JSON record of a run (install --report), with the tags each
+// instance received or, with SkipTagging, would have received. Used by
+// "tags apply" to (re)apply tags without reinstalling.
+type Report struct {
+	SchemaVersion int           `json:"schema_version"`
+	RunID         string        `json:"run_id,omitempty"`
+	Package       string        `json:"package"`
+	DryRun        bool          `json:"dry_run"`
+	SkipTagging   bool          `json:"skip_tagging"`
+	StartTime     time.Time     `json:"start_time"`
+	EndTime       time.Time     `json:"end_time"`
+	Accounts      []ReportGroup `json:"accounts"` // Per-account breakdown, most failures first
+	Regions       []ReportGroup `json:"regions"`  // Per-region breakdown, most failures first
+	Results       []ReportEntry `json:"results"`
+
+	// DeprecatedFlags lists the deprecated flag names used in the run (set
+	// by the CLI), to find users before the old names are removed
+	DeprecatedFlags []ReportDeprecatedFlag `json:"deprecated_flags,omitempty"`
+}
+
+// ReportDeprecatedFlag is a deprecated flag name used in a run.
+type ReportDeprecatedFlag struct {
+	Flag        string `json:"flag"`        // Deprecated name
+	Replacement string `json:"replacement"` // Flag replacing it
+}
+
+// ReportGroup is the per-account or per-region summary of a Report.
+type ReportGroup struct {
+	Key         string `json:"key"`
+	Total       int    `json:"total"`
+	Success     int    `json:"success"`
+	Failed      int    `json:"failed"`
+	Skipped     int    `json:"skipped"`
+	Canceled    int    `json:"canceled"`
+	Disappeared int    `json:"disappeared,omitempty"`
+	AvgDuration string `json:"avg_duration"`
+	MaxDuration string `json:"max_duration"`
+}
+
+// ReportEntry is the outcome of one instance in a Report.
+type ReportEntry struct {
+	InstanceID   string            `json:"instance_id"`
+	Cloud        string            `json:"cloud"`
+	Account      string            `json:"account"`
+	Region       string            `json:"region"`
+	Status       string            `json:"status"` // ExecutionStatus, e.g. "SUCCESS"
+	Error        string            `json:"error,omitempty"`
+	SkipReason   string            `json:"skip_reason,omitempty"`
+	FailurePhase string            `json:"failure_phase,omitempty"` // Phase that failed (validation, download, install, configure, verify)
+	ExitCode     int               `json:"exit_code,omitempty"`     // Exit code of the failed script
+	Duration     string            `json:"duration,omitempty"`
+	ValidatedAt  *time.Time        `json:"validated_at,omitempty"` // When prerequisite validation passed
+	Phases       map[string]string `json:"phases,omitempty"`       // Duration per workflow phase
+	Tags         map[string]string `json:"tags,omitempty"`         // Success or failure tags of the run
+	Attempts     []ReportAttempt   `json:"attempts,omitempty"`     // Earlier attempts of a requeued instance (the entry holds the last one)
+
+	// ErrorContext is the step and output of a failed script, with the
+	// cause the one-line Error summary leaves out (see "report show")
+	ErrorContext *ErrorContext `json:"error_context,omitempty"`
+
+	// OutputURLs are where the provider stored the full output of each
+	// command sent to the instance (AWS: S3 folders with --ssm-output-bucket)
+	OutputURLs []string `json:"output_urls,omitempty"`
+
+	// OriginalStatus is the workflow status the success criteria replaced
+	OriginalStatus string `json:"original_status,omitempty"`
+}
+
+// ReportAttempt is an earlier attempt of a requeued instance in a ReportEntry.
+type ReportAttempt struct {
+	Attempt         int    `json:"attempt"`
+	Status          string `json:"status"`
+	Error           string `json:"error,omitempty"`
+	FailurePhase    string `json:"failure_phase,omitempty"`
+	TransientReason string `json:"transient_reason,omitempty"` // e.g. "throttled", "agent offline"
+	Duration        string `json:"duration,omitempty"`
+}
+
+// Instance returns the instance the entry refers to.
+func (e *ReportEntry) Instance() *cloud.Instance {
+	return &cloud.Instance{ID: e.InstanceID, Cloud: e.Cloud, Account: e.Account, Region: e.Region}
+}
+
+// Report builds the run report of result. Successful and failed instances
+// carry the tags of the installer (plus the run ID tag), even when tagging
+// was skipped; dry-runs record no tags since nothing was installed.
+func (pe *ParallelExecutor) Report(result *AggregatedResult) *Report {
+	report := &Report{
+		SchemaVersion: ReportSchemaVersion,
+		RunID:         result.RunID,
+		Package:       pe.installer.Name(),
+		DryRun:        pe.dryRun,
+		SkipTagging:   pe.skipTagging,
+		StartTime:     result.StartTime,
+		EndTime:       result.EndTime,
+		Accounts:      reportGroups(result.AccountBreakdown()),
+		Regions:       reportGroups(result.RegionBreakdown()),
+		Results:       make([]ReportEntry, 0, len(result.Results)),
+	}
+
+	for _, r := range result.Results {
+		entry := NewReportEntry(r)
+		if !pe.dryRun {
+			switch r.Status {
+			case StatusSuccess:
+				entry.Tags = pe.successTags()
+			case StatusFailed:
+				entry.Tags = pe.failureTags(r)
+			}
+		}
+		report.Results = append(report.Results, entry)
+	}
+
+	return report
+}
+
+// NewReportEntry builds the report entry of one finished instance, without
+// tags (Report adds them). Used to stream entries while the run goes on.
+func NewReportEntry(r *ExecutionResult) ReportEntry {
+	entry := ReportEntry{
+		InstanceID:   r.Instance.ID,
+		Cloud:        r.Instance.Cloud,
+		Account:      r.Instance.Account,
+		Region:       r.Instance.Region,
+		Status:       r.Status.String(),
+		SkipReason:   r.SkipReason,
+		FailurePhase: r.FailurePhase,
+		ExitCode:     r.ExitCode,
+		ErrorContext: r.ErrorContext,
+		OutputURLs:   r.OutputURLs,
+	}
+	if r.OriginalStatus != StatusPending {
+		entry.OriginalStatus = r.OriginalStatus.String()
+	}
+	if r.Duration > 0 {
+		entry.Duration = r.Duration.Round(time.Millisecond).String()
+	}
+	if len(r.Phases) > 0 {
+		entry.Phases = make(map[string]string, len(r.Phases))
+		for _, phase := range r.Phases {
+			entry.Phases[phase.Name] = phase.Duration.Round(time.Millisecond).String()
+		}
+	}
+	if !r.ValidatedAt.IsZero() {
+		validatedAt := r.ValidatedAt
+		entry.ValidatedAt = &validatedAt
+	}
+	if err := r.GetError(); err != nil {
+		entry.Error = err.Error()
+	}
+	for _, attempt := range r.History {
+		reportAttempt := ReportAttempt{
+			Attempt:         attempt.Attempt,
+			Status:          attempt.Status.String(),
+			FailurePhase:    attempt.FailurePhase,
+			TransientReason: attempt.TransientReason,
+			Duration:        attempt.Duration.Round(time.Millisecond).String(),
+		}
+		if attempt.Err != nil {
+			reportAttempt.Error = attempt.Err.Error()
+		}
+		entry.Attempts = append(entry.Attempts, reportAttempt)
+	}
+	return entry
+}
+
+// MergeReports combines the reports of runs over disjoint parts of a fleet
+// (e.g., one run per bastion) into one report. An instance present in
+// several reports keeps the entry of the last one; account and region
+// groups are recomputed from the merged entries. The run ID is kept only
+// when all reports share it.
+func MergeReports(reports []*Report) *Report {
+	merged := &Report{SchemaVersion: ReportSchemaVersion, Results: []ReportEntry{}}
+	index := make(map[string]int)
+
+	for i, report := range reports {
+		if i == 0 {
+			merged.RunID = report.RunID
+			merged.Package = report.Package
+			merged.DryRun = report.DryRun
+			merged.StartTime = report.StartTime
+			merged.EndTime = report.EndTime
+		}
+		if report.RunID != merged.RunID {
+			merged.RunID = ""
+		}
+		merged.DryRun = merged.DryRun && report.DryRun
+		merged.SkipTagging = merged.SkipTagging || report.SkipTagging
+		if !report.StartTime.IsZero() && (merged.StartTime.IsZero() || report.StartTime.Before(merged.StartTime)) {
+			merged.StartTime = report.StartTime
+		}
+		if report.EndTime.After(merged.EndTime) {
+			merged.EndTime = report.EndTime
+		}
+
+		for _, flag := range report.DeprecatedFlags {
+			if !slices.Contains(merged.DeprecatedFlags, flag) {
+				merged.DeprecatedFlags = append(merged.DeprecatedFlags, flag)
+			}
+		}
+		for _, entry := range report.Results {
+			if j, exists := index[entry.InstanceID]; exists {
+				merged.Results[j] = entry
+				continue
+			}
+			index[entry.InstanceID] = len(merged.Results)
+			merged.Results = append(merged.Results, entry)
+		}
+	}
+
+	// Rebuild results to reuse the breakdowns of AggregatedResult
+	result := &AggregatedResult{}
+	for i := range merged.Results {
+		entry := &merged.Results[i]
+		duration, _ := time.ParseDuration(entry.Duration)
+		result.Add(&ExecutionResult{Instance: entry.Instance(), Status: parseStatus(entry.Status), Duration: duration})
+	}
+	merged.Accounts = reportGroups(result.AccountBreakdown())
+	merged.Regions = reportGroups(result.RegionBreakdown())
+	return merged
+}
+
+// parseStatus returns the ExecutionStatus named s (see String).
+func parseStatus(s string) ExecutionStatus {
+	for status := StatusPending; status <= StatusDisappeared; status++ {
+		if status.String() == s {
+			return status
+		}
+	}
+	return StatusPending
+}
+
+// reportGroups converts group summaries to their report form.
+func reportGroups(groups []GroupSummary) []ReportGroup {
+	converted := make([]ReportGroup, 0, len(groups))
+	for _, group := range groups {
+		converted = append(converted, ReportGroup{
+			Key:         group.Key,
+			Total:       group.Total,
+			Success:     group.Success,
+			Failed:      group.Failed,
+			Skipped:     group.Skipped,
+			Canceled:    group.Canceled,
+			Disappeared: group.Disappeared,
+			AvgDuration: group.AvgDuration.Round(time.Millisecond).String(),
+			MaxDuration: group.MaxDuration.Round(time.Millisecond).String(),
+		})
+	}
+	return converted
+}
+
+// TrustedValidations returns the instances whose validation for pkg passed
+// less than maxAge before now, keyed by instance ID, for
+// ExecutorConfig.TrustedValidations. Typically the report comes from a
+// --dry-run, which validates every instance without installing.
+func (r *Report) TrustedValidations(pkg string, maxAge time.Duration, now time.Time) (map[string]time.Time, error) {
+	if r.Package != pkg {
+		return nil, fmt.Errorf("report is for package %q, not %q", r.Package, pkg)
+	}
+
+	trusted := make(map[string]time.Time)
+	for _, entry := range r.Results {
+		if entry.ValidatedAt == nil || now.Sub(*entry.ValidatedAt) > maxAge {
+			continue
+		}
+		trusted[entry.InstanceID] = *entry.ValidatedAt
+	}
+	return trusted, nil
+}
+
+// WriteReport writes report as indented JSON to path.
+func WriteReport(path string, report *Report) error {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode report: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+	return nil
+}
+
+// ReadReport reads a report written by WriteReport.
+func ReadReport(path string) (*Report, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read report: %w", err)
+	}
+
+	var report Report
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("invalid report %s: %w", path, err)
+	}
+	if report.SchemaVersion != ReportSchemaVersion {
+		return nil, fmt.Errorf("unsupported report schema_version %d (expected %d)", report.SchemaVersion, ReportSchemaVersion)
+	}
+	return &report, nil
+}
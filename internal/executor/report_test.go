@@ -0,0 +1,243 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/estudosdevops/opsmaster/internal/cloud"
+	"github.com/estudosdevops/opsmaster/internal/cloud/cloudtest"
+)
+
+// TestParallelExecutor_Report tests that skipped tagging still records the tags
+//
+// 🎓 CONCEPT: Tag-only replay
+// A run with SkipTagging leaves instances untagged but the report keeps the
+// tags each instance would have received, so "tags apply" can replay them.
+func TestParallelExecutor_Report(t *testing.T) {
+	tests := []struct {
+		name     string
+		dryRun   bool
+		wantTags []string // "status" tag per instance ("" = no tags)
+	}{
+		{name: "skip tagging records tags", wantTags: []string{"installed", "failed"}},
+		{name: "dry-run records no tags", dryRun: true, wantTags: []string{"", ""}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// ARRANGE
+			provider := &cloudtest.Provider{}
+			pkg := &mockPackageInstaller{
+				verifyInstallationFunc: func(_ context.Context, instance *cloud.Instance, _ cloud.CloudProvider) error {
+					if instance.ID == "i-test001" {
+						return errors.New("agent not running")
+					}
+					return nil
+				},
+			}
+			executor := NewParallelExecutor(ExecutorConfig{
+				Provider: provider, Installer: pkg, RunID: "run-123", SkipTagging: true, DryRun: tt.dryRun, MaxConcurrency: 1,
+			})
+			result, err := executor.Execute(context.Background(), createTestInstances(2))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			// ACT
+			report := executor.Report(result)
+
+			// ASSERT
+			if provider.CallCount(cloudtest.MethodTagInstance) != 0 {
+				t.Errorf("expected no tagging, got %d calls", provider.CallCount(cloudtest.MethodTagInstance))
+			}
+			if report.RunID != "run-123" || !report.SkipTagging || report.Package != "mock-package" {
+				t.Errorf("unexpected report header %+v", report)
+			}
+			got := map[string]string{}
+			for _, entry := range report.Results {
+				got[entry.InstanceID] = entry.Tags["status"]
+				if entry.Tags != nil && entry.Tags[cloud.RunIDTagKey] != "run-123" {
+					t.Errorf("%s: missing run ID tag in %v", entry.InstanceID, entry.Tags)
+				}
+			}
+			for i, want := range tt.wantTags {
+				id := createTestInstances(2)[i].ID
+				if got[id] != want {
+					t.Errorf("%s: status tag = %q, want %q", id, got[id], want)
+				}
+			}
+		})
+	}
+}
+
+// TestReadReport tests the JSON round trip and version check
+func TestReadReport(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "run.json")
+	report := &Report{
+		SchemaVersion: ReportSchemaVersion,
+		RunID:         "run-1",
+		Results:       []ReportEntry{{InstanceID: "i-1", Cloud: "aws", Account: "111", Region: "us-east-1", Status: "SUCCESS", Tags: map[string]string{"puppet": "true"}}},
+	}
+
+	if err := WriteReport(path, report); err != nil {
+		t.Fatalf("WriteReport() error: %v", err)
+	}
+	got, err := ReadReport(path)
+	if err != nil {
+		t.Fatalf("ReadReport() error: %v", err)
+	}
+	if got.RunID != "run-1" || len(got.Results) != 1 || got.Results[0].Tags["puppet"] != "true" {
+		t.Errorf("unexpected report %+v", got)
+	}
+	if instance := got.Results[0].Instance(); instance.String() != "aws:111:us-east-1:i-1" {
+		t.Errorf("Instance() = %s", instance)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"schema_version": 99}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadReport(path); err == nil || !strings.Contains(err.Error(), "unsupported report schema_version") {
+		t.Errorf("expected version error, got %v", err)
+	}
+}
+
+// TestMergeReports tests merging the reports of runs on several bastions
+func TestMergeReports(t *testing.T) {
+	start := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	vpcA := &Report{
+		SchemaVersion: ReportSchemaVersion, RunID: "run-a", Package: "puppet",
+		StartTime: start.Add(time.Minute), EndTime: start.Add(5 * time.Minute),
+		Results: []ReportEntry{
+			{InstanceID: "i-1", Account: "111", Region: "us-east-1", Status: "SUCCESS", Duration: "2m0s"},
+			{InstanceID: "i-2", Account: "111", Region: "us-east-1", Status: "FAILED", Duration: "4m0s", Error: "timeout"},
+		},
+		DeprecatedFlags: []ReportDeprecatedFlag{{Flag: "instances-file", Replacement: "inventory"}},
+	}
+	vpcB := &Report{
+		SchemaVersion: ReportSchemaVersion, RunID: "run-b", Package: "puppet", SkipTagging: true,
+		StartTime: start, EndTime: start.Add(3 * time.Minute),
+		Results: []ReportEntry{
+			{InstanceID: "i-3", Account: "222", Region: "sa-east-1", Status: "SKIPPED"},
+			{InstanceID: "i-2", Account: "111", Region: "us-east-1", Status: "SUCCESS", Duration: "1m0s"},
+		},
+		DeprecatedFlags: []ReportDeprecatedFlag{{Flag: "instances-file", Replacement: "inventory"}, {Flag: "filter", Replacement: "where"}},
+	}
+
+	merged := MergeReports([]*Report{vpcA, vpcB})
+
+	if merged.RunID != "" || merged.Package != "puppet" || !merged.SkipTagging || merged.DryRun {
+		t.Errorf("unexpected header %+v", merged)
+	}
+	if !merged.StartTime.Equal(start) || !merged.EndTime.Equal(start.Add(5*time.Minute)) {
+		t.Errorf("time window = %s..%s", merged.StartTime, merged.EndTime)
+	}
+	if len(merged.Results) != 3 || merged.Results[1].InstanceID != "i-2" || merged.Results[1].Status != "SUCCESS" {
+		t.Fatalf("unexpected results %+v", merged.Results)
+	}
+	if len(merged.DeprecatedFlags) != 2 || merged.DeprecatedFlags[1].Flag != "filter" {
+		t.Errorf("deprecated flags = %+v, want each flag once", merged.DeprecatedFlags)
+	}
+	if len(merged.Accounts) != 2 || merged.Accounts[0].Key != "111" || merged.Accounts[0].Success != 2 ||
+		merged.Accounts[0].MaxDuration != "2m0s" || merged.Accounts[1].Skipped != 1 {
+		t.Errorf("unexpected account groups %+v", merged.Accounts)
+	}
+
+	if same := MergeReports([]*Report{vpcA, vpcA}); same.RunID != "run-a" || len(same.Results) != 2 {
+		t.Errorf("merging one run twice: %+v", same)
+	}
+}
+
+// TestReport_TrustedValidations tests max age and package matching
+func TestReport_TrustedValidations(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	recent, old := now.Add(-10*time.Minute), now.Add(-time.Hour)
+	report := &Report{
+		Package: "puppet",
+		Results: []ReportEntry{
+			{InstanceID: "i-recent", ValidatedAt: &recent},
+			{InstanceID: "i-old", ValidatedAt: &old},
+			{InstanceID: "i-failed", Status: "FAILED"},
+		},
+	}
+
+	trusted, err := report.TrustedValidations("puppet", 30*time.Minute, now)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(trusted) != 1 || !trusted["i-recent"].Equal(recent) {
+		t.Errorf("trusted = %v, want only i-recent", trusted)
+	}
+
+	if _, err := report.TrustedValidations("osquery", 30*time.Minute, now); err == nil {
+		t.Error("expected error for report of another package")
+	}
+}
+
+// TestExecute_TrustedValidations tests that trusted instances skip validation
+func TestExecute_TrustedValidations(t *testing.T) {
+	// ARRANGE
+	instances := createTestInstances(2)
+	validatedAt := time.Now().Add(-5 * time.Minute)
+	provider := &cloudtest.Provider{}
+	pkg := &mockPackageInstaller{}
+	executor := NewParallelExecutor(ExecutorConfig{
+		Provider:           provider,
+		Installer:          pkg,
+		TrustedValidations: map[string]time.Time{instances[0].ID: validatedAt},
+	})
+
+	// ACT
+	result, err := executor.Execute(context.Background(), instances)
+
+	// ASSERT
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if provider.CallCount(cloudtest.MethodValidateInstance) != 1 || pkg.validatePrerequisitesCount.Load() != 1 {
+		t.Errorf("expected validation only for the untrusted instance, got %d/%d",
+			provider.CallCount(cloudtest.MethodValidateInstance), pkg.validatePrerequisitesCount.Load())
+	}
+	for _, r := range result.Results {
+		if r.ValidatedAt.IsZero() {
+			t.Errorf("%s: ValidatedAt not set", r.Instance.ID)
+		}
+		if r.Instance.ID == instances[0].ID && !r.ValidatedAt.Equal(validatedAt) {
+			t.Errorf("trusted instance ValidatedAt = %v, want %v", r.ValidatedAt, validatedAt)
+		}
+	}
+}
+
+// TestExecute_OutputURLs tests that the output locations reported by the
+// provider reach the instance's result and report entry
+func TestExecute_OutputURLs(t *testing.T) {
+	// ARRANGE: the installer's commands report where their output is stored
+	pkg := &mockPackageInstaller{
+		verifyInstallationFunc: func(ctx context.Context, instance *cloud.Instance, _ cloud.CloudProvider) error {
+			cloud.ObserveOutput(ctx, "s3://ssm-output/cmd-1/"+instance.ID+"/")
+			cloud.ObserveOutput(ctx, "s3://ssm-output/cmd-2/"+instance.ID+"/")
+			return nil
+		},
+	}
+	executor := NewParallelExecutor(ExecutorConfig{Provider: &cloudtest.Provider{}, Installer: pkg})
+
+	// ACT
+	result, err := executor.Execute(context.Background(), createTestInstances(2))
+
+	// ASSERT
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, r := range result.Results {
+		entry := NewReportEntry(r)
+		want := "s3://ssm-output/cmd-1/" + r.Instance.ID + "/,s3://ssm-output/cmd-2/" + r.Instance.ID + "/"
+		if got := strings.Join(entry.OutputURLs, ","); got != want {
+			t.Errorf("%s: output_urls = %s, want %s", r.Instance.ID, got, want)
+		}
+	}
+}
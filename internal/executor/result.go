@@ -1,7 +1,10 @@
 package executor
 
 import (
+	"errors"
 	"fmt"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/estudosdevops/opsmaster/internal/cloud"
@@ -33,6 +36,10 @@ const (
 
 	// StatusSkipped execution skipped (e.g., already has puppet=true tag)
 	StatusSkipped
+
+	// StatusDisappeared instance went away mid-run (e.g., terminated by
+	// autoscaling); not counted as a failure
+	StatusDisappeared
 )
 
 // String returns readable representation of the status.
@@ -51,6 +58,8 @@ func (s ExecutionStatus) String() string {
 		return "CANCELED"
 	case StatusSkipped:
 		return "SKIPPED"
+	case StatusDisappeared:
+		return "DISAPPEARED"
 	default:
 		return "UNKNOWN"
 	}
@@ -59,16 +68,147 @@ func (s ExecutionStatus) String() string {
 // ExecutionResult represents installation result on an instance.
 // Extends installer.InstallResult with execution information.
 type ExecutionResult struct {
-	Instance        *cloud.Instance          // Instance processed
-	Status          ExecutionStatus          // Final execution status
-	InstallResult   *installer.InstallResult // Detailed installation result
-	ValidationErr   error                    // Validation error (if any)
-	InstallationErr error                    // Installation error (if any)
-	TaggingErr      error                    // Tagging error (if any)
-	StartTime       time.Time                // When it started
-	EndTime         time.Time                // When it finished
-	Duration        time.Duration            // Total time
-	Metadata        map[string]string        // Installation metadata (OS, certname, etc)
+	Instance        *cloud.Instance            // Instance processed
+	Status          ExecutionStatus            // Final execution status
+	InstallResult   *installer.InstallResult   // Detailed installation result
+	ValidationErr   error                      // Validation error (if any)
+	InstallationErr error                      // Installation error (if any)
+	TaggingErr      error                      // Tagging error (if any)
+	PostInstallErr  error                      // Post-install hook error, e.g. ENC registration (if any)
+	SkipReason      string                     // Why the instance was skipped (StatusSkipped only)
+	FailurePhase    string                     // Phase that failed, one of the installer.ScriptPhase constants (StatusFailed only)
+	ExitCode        int                        // Exit code of the failed script (0 = not a script failure)
+	ValidatedAt     time.Time                  // When prerequisite validation passed (zero = not validated)
+	Attempts        int                        // Times the instance was processed in the run (>1 = requeued after failures)
+	History         []AttemptRecord            // Earlier attempts of a requeued instance, oldest first
+	Phases          []PhaseTiming              // Time spent in each workflow phase, in execution order
+	StuckPhases     []string                   // Phases that ran longer than expected (see ExecutorConfig.ExpectedDurations)
+	ErrorContext    *ErrorContext              // Step and output of the failed script (script failures only)
+	OutputURLs      []string                   // Where the provider stored the full output of each command, in order (e.g., SSM S3 output)
+	OriginalStatus  ExecutionStatus            // Workflow status replaced by the success criteria (StatusPending = not replaced)
+	StartTime       time.Time                  // When it started
+	EndTime         time.Time                  // When it finished
+	Duration        time.Duration              // Total time
+	Metadata        *installer.InstallMetadata // Installation metadata (OS, certname, etc)
+}
+
+// AttemptRecord is the outcome of an earlier attempt of an instance that was
+// requeued, kept so the final result doesn't hide how it got there.
+type AttemptRecord struct {
+	Attempt         int             // Attempt number (1 = first)
+	Status          ExecutionStatus // Outcome of the attempt (StatusFailed)
+	Err             error           // Error of the attempt
+	FailurePhase    string          // Phase that failed
+	TransientReason string          // Transient failure reason, e.g. "throttled" ("" = not transient)
+	StartTime       time.Time       // When the attempt started
+	Duration        time.Duration   // Time spent in the attempt
+}
+
+// Workflow phases timed in ExecutionResult.Phases.
+const (
+	PhaseValidate    = "validate"     // Instance and prerequisite validation
+	PhaseStagger     = "stagger"      // Random first-run delay
+	PhaseInstall     = "install"      // Installation (or dry-run simulation)
+	PhaseVerify      = "verify"       // Installation and fact verification
+	PhaseTag         = "tag"          // Success tagging
+	PhasePostInstall = "post-install" // Post-install hook (e.g., ENC registration)
+)
+
+// PhaseTiming is the time an execution spent in one phase.
+type PhaseTiming struct {
+	Name     string        // One of the Phase constants
+	Duration time.Duration // Time spent in the phase
+}
+
+// trackPhase records a phase that started at start and just finished.
+func (er *ExecutionResult) trackPhase(name string, start time.Time) {
+	er.Phases = append(er.Phases, PhaseTiming{Name: name, Duration: time.Since(start)})
+}
+
+// workflowFailurePhases maps workflow phases to the failure phase reported
+// when the error doesn't carry a classified script exit code.
+var workflowFailurePhases = map[string]string{
+	PhaseValidate: installer.ScriptPhaseValidation,
+	PhaseInstall:  installer.ScriptPhaseInstall,
+	PhaseVerify:   installer.ScriptPhaseVerify,
+}
+
+// classifyFailure sets FailurePhase and ExitCode of a failed result: the
+// phase encoded in the script exit code (see installer.ScriptError) or, for
+// other errors, the workflow phase in which the failure happened.
+func (er *ExecutionResult) classifyFailure(err error) {
+	var scriptErr *installer.ScriptError
+	if errors.As(err, &scriptErr) {
+		er.ExitCode = scriptErr.ExitCode
+		if phase := scriptErr.Phase(); phase != "" {
+			er.FailurePhase = phase
+			return
+		}
+	}
+
+	if len(er.Phases) > 0 {
+		if phase, ok := workflowFailurePhases[er.Phases[len(er.Phases)-1].Name]; ok {
+			er.FailurePhase = phase
+			return
+		}
+	}
+	if er.ValidationErr != nil {
+		er.FailurePhase = installer.ScriptPhaseValidation
+	} else {
+		er.FailurePhase = installer.ScriptPhaseInstall
+	}
+}
+
+// ErrorContextLines is how many trailing output lines of a failed script are
+// kept in ErrorContext.OutputTail.
+const ErrorContextLines = 20
+
+// ErrorContext is the captured context of a failed script, beyond the
+// one-line error shown in the summary table: the real cause (e.g., the yum
+// error) is usually at the end of the output.
+type ErrorContext struct {
+	Step       string   `json:"step,omitempty"`        // Install step that failed ("" = single-script installer)
+	OutputTail []string `json:"output_tail,omitempty"` // Last ErrorContextLines lines of stderr (stdout when stderr is empty)
+	Stdout     string   `json:"stdout,omitempty"`      // Captured stdout (up to ExecutorConfig.MaxOutputBytes)
+	Stderr     string   `json:"stderr,omitempty"`      // Captured stderr (up to ExecutorConfig.MaxOutputBytes)
+
+	// Diagnostics is the output of the diagnostic bundle collected after the
+	// failure (disk, memory and log tails, see installer.DiagnosticScript),
+	// also set for failures that aren't script failures
+	Diagnostics string `json:"diagnostics,omitempty"`
+}
+
+// newErrorContext captures the context of err when it is a script failure
+// (nil otherwise), truncating stdout and stderr to maxOutputBytes each
+// (0 = no limit). The tail is taken before truncation.
+func newErrorContext(err error, maxOutputBytes int) *ErrorContext {
+	var scriptErr *installer.ScriptError
+	if !errors.As(err, &scriptErr) {
+		return nil
+	}
+
+	output := scriptErr.Stderr
+	if strings.TrimSpace(output) == "" {
+		output = scriptErr.Stdout
+	}
+	captured := &cloud.CommandResult{Stdout: scriptErr.Stdout, Stderr: scriptErr.Stderr}
+	captured.TruncateOutput(maxOutputBytes)
+	return &ErrorContext{
+		Step:       scriptErr.Step,
+		OutputTail: tailLines(output, ErrorContextLines),
+		Stdout:     captured.Stdout,
+		Stderr:     captured.Stderr,
+	}
+}
+
+// tailLines returns the last n lines of s, ignoring trailing blank lines.
+func tailLines(s string, n int) []string {
+	s = strings.TrimRight(s, " \t\r\n")
+	if s == "" {
+		return nil
+	}
+	lines := strings.Split(s, "\n")
+	return lines[max(0, len(lines)-n):]
 }
 
 // Success returns true if execution was successful
@@ -87,7 +227,7 @@ func (er *ExecutionResult) String() string {
 }
 
 // GetError returns the first error found (if any).
-// Order: ValidationErr > InstallationErr > TaggingErr
+// Order: ValidationErr > InstallationErr > TaggingErr > PostInstallErr
 func (er *ExecutionResult) GetError() error {
 	if er.ValidationErr != nil {
 		return er.ValidationErr
@@ -98,12 +238,16 @@ func (er *ExecutionResult) GetError() error {
 	if er.TaggingErr != nil {
 		return er.TaggingErr
 	}
+	if er.PostInstallErr != nil {
+		return er.PostInstallErr
+	}
 	return nil
 }
 
 // AggregatedResult aggregates results from multiple executions.
 // Useful for final reports.
 type AggregatedResult struct {
+	RunID     string             // Invocation that produced these results
 	Total     int                // Total instances processed
 	Success   int                // Successful installations
 	Failed    int                // Failed installations
@@ -113,6 +257,10 @@ type AggregatedResult struct {
 	TotalTime time.Duration      // Total execution time
 	StartTime time.Time          // When it started
 	EndTime   time.Time          // When it finished
+
+	// Disappeared counts instances gone mid-run (StatusDisappeared), kept
+	// out of Failed so autoscaling churn doesn't fail the run
+	Disappeared int
 }
 
 // NewAggregatedResult creates empty aggregated result
@@ -137,6 +285,8 @@ func (ar *AggregatedResult) Add(result *ExecutionResult) {
 		ar.Skipped++
 	case StatusCancelled:
 		ar.Canceled++
+	case StatusDisappeared:
+		ar.Disappeared++
 	}
 }
 
@@ -173,8 +323,25 @@ func (ar *AggregatedResult) GetFailedInstances() []*ExecutionResult {
 	return failed
 }
 
+// Slowest returns the n slowest processed (not skipped) executions, slowest
+// first. Hosts that drag out every campaign (swap-thrashing, slow mirrors)
+// show up at the top; see ExecutionResult.Phases for where the time went.
+func (ar *AggregatedResult) Slowest(n int) []*ExecutionResult {
+	var processed []*ExecutionResult
+	for _, result := range ar.Results {
+		if result.Status != StatusSkipped {
+			processed = append(processed, result)
+		}
+	}
+
+	sort.SliceStable(processed, func(a, b int) bool {
+		return processed[a].Duration > processed[b].Duration
+	})
+	return processed[:min(n, len(processed))]
+}
+
 // String returns readable representation of aggregated result
 func (ar *AggregatedResult) String() string {
-	return fmt.Sprintf("Total: %d | Success: %d | Failed: %d | Skipped: %d | Time: %s",
-		ar.Total, ar.Success, ar.Failed, ar.Skipped, ar.TotalTime)
+	return fmt.Sprintf("Total: %d | Success: %d | Failed: %d | Skipped: %d | Disappeared: %d | Time: %s",
+		ar.Total, ar.Success, ar.Failed, ar.Skipped, ar.Disappeared, ar.TotalTime)
 }
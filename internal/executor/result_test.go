@@ -3,10 +3,12 @@ package executor
 import (
 	"errors"
 	"fmt"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/estudosdevops/opsmaster/internal/cloud"
+	"github.com/estudosdevops/opsmaster/internal/installer"
 )
 
 // ============================================================
@@ -58,6 +60,7 @@ func TestExecutionStatus_String(t *testing.T) {
 		{StatusFailed, "FAILED"},
 		{StatusCancelled, "CANCELED"},
 		{StatusSkipped, "SKIPPED"},
+		{StatusDisappeared, "DISAPPEARED"},
 		{ExecutionStatus(999), "UNKNOWN"}, // Invalid status
 	}
 
@@ -288,10 +291,11 @@ func TestAggregatedResult_Add(t *testing.T) {
 	ar.Add(createTestExecutionResult(StatusFailed, "i-003"))
 	ar.Add(createTestExecutionResult(StatusSkipped, "i-004"))
 	ar.Add(createTestExecutionResult(StatusCancelled, "i-005"))
+	ar.Add(createTestExecutionResult(StatusDisappeared, "i-006"))
 
 	// ASSERT
-	if ar.Total != 5 {
-		t.Errorf("Total = %d, want 5", ar.Total)
+	if ar.Total != 6 {
+		t.Errorf("Total = %d, want 6", ar.Total)
 	}
 
 	if ar.Success != 2 {
@@ -310,8 +314,12 @@ func TestAggregatedResult_Add(t *testing.T) {
 		t.Errorf("Canceled = %d, want 1", ar.Canceled)
 	}
 
-	if len(ar.Results) != 5 {
-		t.Errorf("len(Results) = %d, want 5", len(ar.Results))
+	if ar.Disappeared != 1 {
+		t.Errorf("Disappeared = %d, want 1", ar.Disappeared)
+	}
+
+	if len(ar.Results) != 6 {
+		t.Errorf("len(Results) = %d, want 6", len(ar.Results))
 	}
 }
 
@@ -621,6 +629,106 @@ func TestAggregatedResult_CompleteWorkflow(t *testing.T) {
 	}
 }
 
+// TestAggregatedResult_Slowest tests ordering, skipped exclusion and bounds
+func TestAggregatedResult_Slowest(t *testing.T) {
+	tests := []struct {
+		name string
+		n    int
+		want []string
+	}{
+		{name: "top 2", n: 2, want: []string{"i-slow", "i-medium"}},
+		{name: "n larger than processed", n: 10, want: []string{"i-slow", "i-medium", "i-fast"}},
+		{name: "zero", n: 0, want: []string{}},
+	}
+
+	ar := NewAggregatedResult()
+	for id, duration := range map[string]time.Duration{"i-fast": time.Second, "i-slow": time.Minute, "i-medium": 10 * time.Second} {
+		r := createTestExecutionResult(StatusSuccess, id)
+		r.Duration = duration
+		ar.Add(r)
+	}
+	skipped := createTestExecutionResult(StatusSkipped, "i-skipped")
+	skipped.Duration = time.Hour
+	ar.Add(skipped)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			slowest := ar.Slowest(tt.n)
+
+			got := make([]string, 0, len(slowest))
+			for _, r := range slowest {
+				got = append(got, r.Instance.ID)
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("Slowest(%d) = %v, want %v", tt.n, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestNewErrorContext tests the output captured from failed scripts
+func TestNewErrorContext(t *testing.T) {
+	var longStderr []string
+	for i := 1; i <= 30; i++ {
+		longStderr = append(longStderr, fmt.Sprintf("line %d", i))
+	}
+
+	tests := []struct {
+		name       string
+		err        error
+		maxBytes   int
+		wantNil    bool
+		wantStep   string
+		wantTail   []string
+		wantStderr string
+	}{
+		{name: "not a script failure", err: errors.New("instance validation failed"), wantNil: true},
+		{
+			name:       "stderr tail of a named step",
+			err:        fmt.Errorf("installation failed: %w", &installer.ScriptError{Step: "install", ExitCode: 1, Stderr: "Loaded plugins\nError: Nothing to do\n\n"}),
+			wantStep:   "install",
+			wantTail:   []string{"Loaded plugins", "Error: Nothing to do"},
+			wantStderr: "Loaded plugins\nError: Nothing to do\n\n",
+		},
+		{
+			name:     "stdout when stderr is empty",
+			err:      &installer.ScriptError{ExitCode: 2, Stdout: "downloading\ncurl: (6) Could not resolve host"},
+			wantTail: []string{"downloading", "curl: (6) Could not resolve host"},
+		},
+		{
+			name:       "tail limited, output truncated",
+			err:        &installer.ScriptError{ExitCode: 1, Stderr: strings.Join(longStderr, "\n")},
+			maxBytes:   10,
+			wantTail:   longStderr[30-ErrorContextLines:],
+			wantStderr: "line 1\nlin\n... [truncated 220 bytes]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := newErrorContext(tt.err, tt.maxBytes)
+			if tt.wantNil {
+				if got != nil {
+					t.Errorf("newErrorContext() = %+v, want nil", got)
+				}
+				return
+			}
+			if got == nil {
+				t.Fatal("newErrorContext() = nil")
+			}
+			if got.Step != tt.wantStep {
+				t.Errorf("Step = %q, want %q", got.Step, tt.wantStep)
+			}
+			if fmt.Sprint(got.OutputTail) != fmt.Sprint(tt.wantTail) {
+				t.Errorf("OutputTail = %q, want %q", got.OutputTail, tt.wantTail)
+			}
+			if got.Stderr != tt.wantStderr {
+				t.Errorf("Stderr = %q, want %q", got.Stderr, tt.wantStderr)
+			}
+		})
+	}
+}
+
 // ============================================================
 // UTILITY FUNCTIONS
 // ============================================================
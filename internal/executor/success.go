@@ -0,0 +1,143 @@
+package executor
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/estudosdevops/opsmaster/internal/criteria"
+)
+
+// Fields of ExecutionResult available to success criteria (see resultField).
+var successFields = []string{
+	"instance_id", "name", "account", "region", "cloud",
+	"status", "error", "failure_phase", "exit_code", "duration", "attempts", "stuck_phases",
+}
+
+// Prefixes of the success criteria fields keyed by name, e.g. metadata.certname.
+var successFieldPrefixes = []string{"metadata.", "phase_durations.", "csv."}
+
+// ParseSuccessCriteria parses a success criteria expression (see package
+// criteria) over the fields of a result, rejecting unknown fields:
+//
+//	instance_id, name, account, region, cloud   the instance
+//	status, error, failure_phase, exit_code     the workflow outcome
+//	duration, attempts, stuck_phases            timing (stuck phases comma separated)
+//	metadata.<key>                              installation metadata (e.g., certname_preserved)
+//	phase_durations.<phase>                     time spent in a phase (e.g., install)
+//	csv.<column>                                inventory column
+func ParseSuccessCriteria(source string) (*criteria.Expr, error) {
+	expr, err := criteria.Parse(source)
+	if err != nil {
+		return nil, err
+	}
+	for _, field := range expr.Fields() {
+		if !knownSuccessField(field) {
+			return nil, fmt.Errorf("unknown field %q in %q (fields: %s, %s<key>, %s<phase>, %s<column>)",
+				field, source, strings.Join(successFields, ", "),
+				successFieldPrefixes[0], successFieldPrefixes[1], successFieldPrefixes[2])
+		}
+	}
+	return expr, nil
+}
+
+func knownSuccessField(field string) bool {
+	for _, prefix := range successFieldPrefixes {
+		if key, ok := strings.CutPrefix(field, prefix); ok {
+			return key != ""
+		}
+	}
+	for _, name := range successFields {
+		if field == name {
+			return true
+		}
+	}
+	return false
+}
+
+// resultField returns the value of a success criteria field of a result.
+func resultField(result *ExecutionResult, field string) string {
+	if key, ok := strings.CutPrefix(field, "metadata."); ok {
+		return result.Metadata.Get(key)
+	}
+	if phase, ok := strings.CutPrefix(field, "phase_durations."); ok {
+		for _, timing := range result.Phases {
+			if timing.Name == phase {
+				return timing.Duration.String()
+			}
+		}
+		return ""
+	}
+	if column, ok := strings.CutPrefix(field, "csv."); ok {
+		return result.Instance.Metadata[column]
+	}
+
+	switch field {
+	case "instance_id":
+		return result.Instance.ID
+	case "name":
+		return result.Instance.DisplayName()
+	case "account":
+		return result.Instance.Account
+	case "region":
+		return result.Instance.Region
+	case "cloud":
+		return result.Instance.Cloud
+	case "status":
+		return result.Status.String()
+	case "error":
+		if err := result.GetError(); err != nil {
+			return err.Error()
+		}
+		return ""
+	case "failure_phase":
+		return result.FailurePhase
+	case "exit_code":
+		return strconv.Itoa(result.ExitCode)
+	case "duration":
+		return result.Duration.String()
+	case "attempts":
+		return strconv.Itoa(max(result.Attempts, 1))
+	case "stuck_phases":
+		return strings.Join(result.StuckPhases, ",")
+	default:
+		return ""
+	}
+}
+
+// applySuccessCriteria sets the final status of a successful or failed
+// result from the success criteria: SUCCESS when the expression holds,
+// FAILED otherwise. A changed status keeps the workflow one in
+// OriginalStatus; a downgrade records the unmet criteria as the error.
+// Dry-runs, skipped and canceled results are left alone.
+func (pe *ParallelExecutor) applySuccessCriteria(result *ExecutionResult) {
+	if pe.successWhen == nil || pe.dryRun {
+		return
+	}
+	if result.Status != StatusSuccess && result.Status != StatusFailed {
+		return
+	}
+
+	status := StatusFailed
+	if pe.successWhen.Eval(func(field string) string { return resultField(result, field) }) {
+		status = StatusSuccess
+	}
+	if status == result.Status {
+		return
+	}
+
+	pe.log.Info("Success criteria changed instance status",
+		"instance_id", result.Instance.ID,
+		"from", result.Status,
+		"to", status,
+		"criteria", pe.successWhen.String())
+	result.OriginalStatus = result.Status
+	result.Status = status
+	if status == StatusFailed {
+		err := fmt.Errorf("success criteria not met: %s", pe.successWhen)
+		if result.InstallationErr != nil {
+			err = fmt.Errorf("%w: %w", err, result.InstallationErr)
+		}
+		result.InstallationErr = err
+	}
+}
@@ -0,0 +1,127 @@
+package executor
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/estudosdevops/opsmaster/internal/cloud"
+	"github.com/estudosdevops/opsmaster/internal/installer"
+	"github.com/estudosdevops/opsmaster/internal/logger"
+)
+
+func TestParseSuccessCriteria(t *testing.T) {
+	tests := []struct {
+		source  string
+		wantErr bool
+	}{
+		{source: `metadata.certname_preserved == "true" || phase_durations.install < 300s`},
+		{source: `status == "FAILED" && failure_phase == "verify" && csv.environment == "staging"`},
+		{source: `attempts > 1 || stuck_phases =~ "install"`},
+		{source: `exitcode == 0`, wantErr: true},
+		{source: `metadata. == "x"`, wantErr: true},
+		{source: `status ==`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.source, func(t *testing.T) {
+			_, err := ParseSuccessCriteria(tt.source)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseSuccessCriteria() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestApplySuccessCriteria(t *testing.T) {
+	instance := &cloud.Instance{ID: "i-1", Metadata: map[string]string{"environment": "staging"}}
+	verifyErr := errors.New("verification failed: puppet service not running")
+
+	tests := []struct {
+		name         string
+		criteria     string
+		dryRun       bool
+		result       ExecutionResult
+		wantStatus   ExecutionStatus
+		wantOriginal ExecutionStatus
+		wantErr      string
+	}{
+		{
+			name:     "success kept",
+			criteria: `phase_durations.install < 300s`,
+			result: ExecutionResult{Status: StatusSuccess,
+				Phases: []PhaseTiming{{Name: PhaseInstall, Duration: 2 * time.Minute}}},
+			wantStatus: StatusSuccess,
+		},
+		{
+			name:     "success downgraded",
+			criteria: `metadata.certname_preserved == "true"`,
+			result: ExecutionResult{Status: StatusSuccess,
+				Metadata: &installer.InstallMetadata{Certname: "web-01", CertnamePreserved: false}},
+			wantStatus:   StatusFailed,
+			wantOriginal: StatusSuccess,
+			wantErr:      `success criteria not met: metadata.certname_preserved == "true"`,
+		},
+		{
+			name:     "failure upgraded",
+			criteria: `status == "SUCCESS" || failure_phase == "verify" && csv.environment == "staging"`,
+			result: ExecutionResult{Status: StatusFailed, FailurePhase: "verify",
+				InstallationErr: verifyErr},
+			wantStatus:   StatusSuccess,
+			wantOriginal: StatusFailed,
+			wantErr:      verifyErr.Error(),
+		},
+		{
+			name:       "failure kept",
+			criteria:   `status == "SUCCESS"`,
+			result:     ExecutionResult{Status: StatusFailed, InstallationErr: verifyErr},
+			wantStatus: StatusFailed,
+			wantErr:    verifyErr.Error(),
+		},
+		{
+			name:       "skipped untouched",
+			criteria:   `true`,
+			result:     ExecutionResult{Status: StatusSkipped},
+			wantStatus: StatusSkipped,
+		},
+		{
+			name:       "dry-run untouched",
+			criteria:   `false`,
+			dryRun:     true,
+			result:     ExecutionResult{Status: StatusSuccess},
+			wantStatus: StatusSuccess,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// ARRANGE
+			expr, err := ParseSuccessCriteria(tt.criteria)
+			if err != nil {
+				t.Fatalf("ParseSuccessCriteria() error = %v", err)
+			}
+			pe := &ParallelExecutor{successWhen: expr, dryRun: tt.dryRun, log: logger.Get()}
+			result := tt.result
+			result.Instance = instance
+
+			// ACT
+			pe.applySuccessCriteria(&result)
+
+			// ASSERT
+			if result.Status != tt.wantStatus {
+				t.Errorf("Status = %v, want %v", result.Status, tt.wantStatus)
+			}
+			if result.OriginalStatus != tt.wantOriginal {
+				t.Errorf("OriginalStatus = %v, want %v", result.OriginalStatus, tt.wantOriginal)
+			}
+			gotErr := ""
+			if err := result.GetError(); err != nil {
+				gotErr = err.Error()
+			}
+			if !strings.HasPrefix(gotErr, tt.wantErr) || (tt.wantErr == "") != (gotErr == "") {
+				t.Errorf("error = %q, want prefix %q", gotErr, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package executor
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/estudosdevops/opsmaster/internal/installer"
+)
+
+// transientFailures maps error fragments of failures that usually pass on a
+// later attempt in the same run (after per-operation retries gave up) to a
+// short reason. Checked in order; the first match wins.
+var transientFailures = []struct {
+	fragment string
+	reason   string
+}{
+	{"throttl", "throttled"},
+	{"rate exceeded", "throttled"},
+	{"too many requests", "throttled"},
+	{"connectionlost", "agent offline"},
+	{"undeliverable", "agent offline"},
+	{"deliverytimedout", "agent offline"},
+}
+
+// transientReason classifies the error of a failed attempt: a short reason
+// (e.g., "throttled", "agent offline") for transient failures worth a
+// requeue (see ExecutorConfig.RequeueTransient), "" otherwise. Script
+// failures are never transient: the script ran and reported an exit code.
+func transientReason(err error) string {
+	if err == nil {
+		return ""
+	}
+	var scriptErr *installer.ScriptError
+	if errors.As(err, &scriptErr) {
+		return ""
+	}
+
+	errStr := strings.ToLower(err.Error())
+	for _, t := range transientFailures {
+		if strings.Contains(errStr, t.fragment) {
+			return t.reason
+		}
+	}
+	return ""
+}
@@ -0,0 +1,33 @@
+package executor
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/estudosdevops/opsmaster/internal/installer"
+)
+
+func TestTransientReason(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{name: "nil", err: nil, want: ""},
+		{name: "SSM throttling", err: errors.New("failed to send SSM command: operation error SSM: SendCommand, ThrottlingException: Rate exceeded"), want: "throttled"},
+		{name: "wrapped throttling", err: fmt.Errorf("instance validation failed: %w", errors.New("429 Too Many Requests")), want: "throttled"},
+		{name: "agent connection lost", err: errors.New("instance i-0abc is ConnectionLost (expected Online) - SSM agent may be stopped or network issue"), want: "agent offline"},
+		{name: "command undeliverable", err: errors.New("command Undeliverable"), want: "agent offline"},
+		{name: "permanent error", err: errors.New("instance i-0abc not found in SSM"), want: ""},
+		{name: "script failure is never transient", err: &installer.ScriptError{ExitCode: 1, Stderr: "Rate exceeded"}, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := transientReason(tt.err); got != tt.want {
+				t.Errorf("transientReason() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+// Package facter runs Facter on instances and extracts facts by dotted
+// path (e.g., os.release.full), the same notation used by `facter` queries.
+package facter
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// facterBin prefers the Puppet AIO Facter, which also loads external facts
+// from /opt/puppetlabs/facter/facts.d.
+const facterBin = "/opt/puppetlabs/bin/facter"
+
+// pathPattern matches dotted fact paths; segments are fact names, hash keys
+// or array indexes.
+var pathPattern = regexp.MustCompile(`^[A-Za-z0-9_:-]+(\.[A-Za-z0-9_:-]+)*$`)
+
+// ValidatePaths checks fact paths before they are used in remote commands.
+func ValidatePaths(paths []string) error {
+	if len(paths) == 0 {
+		return fmt.Errorf("at least one fact is required")
+	}
+	for _, path := range paths {
+		if !pathPattern.MatchString(path) {
+			return fmt.Errorf("invalid fact path %q: use dotted names like os.release.full", path)
+		}
+	}
+	return nil
+}
+
+// QueryCommand returns the shell command printing the top-level facts of
+// the given paths as JSON. Call ValidatePaths first.
+func QueryCommand(paths []string) string {
+	var names []string
+	for _, path := range paths {
+		name, _, _ := strings.Cut(path, ".")
+		if !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	return `FACTER=` + facterBin + `; [ -x "${FACTER}" ] || FACTER=facter; "${FACTER}" --json ` + strings.Join(names, " ")
+}
+
+// Parse decodes `facter --json` output.
+func Parse(output string) (map[string]any, error) {
+	var facts map[string]any
+	if err := json.Unmarshal([]byte(output), &facts); err != nil {
+		return nil, fmt.Errorf("invalid facter output: %w", err)
+	}
+	return facts, nil
+}
+
+// Lookup returns the value at a dotted path, walking hashes by key and
+// arrays by index. Facter reports unknown top-level facts as null, so a nil
+// value is treated as missing.
+func Lookup(facts map[string]any, path string) (any, bool) {
+	var current any = facts
+	for _, segment := range strings.Split(path, ".") {
+		switch node := current.(type) {
+		case map[string]any:
+			value, ok := node[segment]
+			if !ok {
+				return nil, false
+			}
+			current = value
+		case []any:
+			index, err := strconv.Atoi(segment)
+			if err != nil || index < 0 || index >= len(node) {
+				return nil, false
+			}
+			current = node[index]
+		default:
+			return nil, false
+		}
+	}
+	return current, current != nil
+}
+
+// Format renders a fact value for tables: scalars as text, hashes and
+// arrays as compact JSON.
+func Format(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case map[string]any, []any:
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return string(encoded)
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,71 @@
+package facter
+
+import (
+	"strings"
+	"testing"
+)
+
+const sampleOutput = `{
+  "os": {"name": "Ubuntu", "release": {"full": "22.04", "major": "22.04"}},
+  "location": {"environment": "prod"},
+  "processors": {"count": 4, "models": ["Intel Xeon", "Intel Xeon"]},
+  "missing_fact": null
+}`
+
+// TestLookup tests dotted path extraction from facter JSON
+func TestLookup(t *testing.T) {
+	facts, err := Parse(sampleOutput)
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+
+	tests := []struct {
+		path   string
+		want   string
+		wantOK bool
+	}{
+		{path: "os.release.full", want: "22.04", wantOK: true},
+		{path: "location.environment", want: "prod", wantOK: true},
+		{path: "processors.count", want: "4", wantOK: true},
+		{path: "processors.models.1", want: "Intel Xeon", wantOK: true},
+		{path: "os.release", want: `{"full":"22.04","major":"22.04"}`, wantOK: true},
+		{path: "processors.models.7"},
+		{path: "os.name.first"},
+		{path: "missing_fact"},
+		{path: "unknown"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			// ACT
+			value, ok := Lookup(facts, tt.path)
+
+			// ASSERT
+			if ok != tt.wantOK || Format(value) != tt.want {
+				t.Errorf("Lookup(%q) = %q, %v; want %q, %v", tt.path, Format(value), ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+// TestQueryCommand tests top-level fact deduplication and path validation
+func TestQueryCommand(t *testing.T) {
+	paths := []string{"os.release.full", "os.name", "location.environment"}
+	if err := ValidatePaths(paths); err != nil {
+		t.Fatalf("ValidatePaths() error: %v", err)
+	}
+
+	command := QueryCommand(paths)
+
+	if !strings.HasSuffix(command, `--json os location`) {
+		t.Errorf("unexpected command %q", command)
+	}
+	for _, invalid := range [][]string{nil, {"os.release;reboot"}, {"os..name"}, {".os"}} {
+		if err := ValidatePaths(invalid); err == nil {
+			t.Errorf("expected error for %q", invalid)
+		}
+	}
+	if _, err := Parse("facter: command not found"); err == nil {
+		t.Error("expected error for non-JSON output")
+	}
+}
@@ -0,0 +1,113 @@
+// Package flagalias keeps renamed flags working. The old name becomes a
+// hidden alias sharing the value of the new flag; using it warns once with
+// the replacement and is recorded, so run reports show which deprecated
+// flags are still in use before they are removed.
+//
+//	cmd.Flags().StringVar(&inventory, "inventory", "", "...")
+//	_ = flagalias.Deprecate(cmd.Flags(), "instances-file", "inventory")
+//
+// Check must run once after the flags are parsed and before flags are set
+// from the environment or a config profile (the root command does it for
+// every command), so those see the current flag as set on the command line.
+package flagalias
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"sync"
+
+	"github.com/spf13/pflag"
+
+	"github.com/estudosdevops/opsmaster/internal/i18n"
+)
+
+// annotation marks alias flags, holding the name of the current flag.
+const annotation = "opsmaster_deprecated_alias"
+
+// Usage is a deprecated flag used in this run.
+type Usage struct {
+	Flag        string // Deprecated name
+	Replacement string // Current name
+}
+
+var (
+	mu   sync.Mutex
+	used []Usage
+)
+
+// Deprecate registers old as a deprecated alias of the flag current, which
+// must already be defined in flags.
+func Deprecate(flags *pflag.FlagSet, old, current string) error {
+	flag := flags.Lookup(current)
+	if flag == nil {
+		return fmt.Errorf("flag --%s not defined", current)
+	}
+	if flags.Lookup(old) != nil {
+		return fmt.Errorf("flag --%s already defined", old)
+	}
+	flags.AddFlag(&pflag.Flag{
+		Name:        old,
+		Usage:       fmt.Sprintf("Obsoleta: use --%s", current),
+		Value:       flag.Value,
+		DefValue:    flag.DefValue,
+		NoOptDefVal: flag.NoOptDefVal,
+		Hidden:      true,
+		Annotations: map[string][]string{annotation: {current}},
+	})
+	return nil
+}
+
+// Check handles the deprecated aliases set in flags: marks the current flag
+// as set (so required-flag checks and Changed see it), records the usage
+// and writes one warning per alias to w. Setting both names is an error.
+func Check(flags *pflag.FlagSet, w io.Writer) error {
+	var err error
+	flags.VisitAll(func(flag *pflag.Flag) {
+		replacement := flag.Annotations[annotation]
+		if err != nil || !flag.Changed || len(replacement) == 0 {
+			return
+		}
+		current := flags.Lookup(replacement[0])
+		if current == nil {
+			return
+		}
+		if current.Changed {
+			err = fmt.Errorf("flags --%s and --%s are the same flag (--%s is deprecated): use only --%[2]s", flag.Name, current.Name, flag.Name)
+			return
+		}
+		current.Changed = true
+		if record(Usage{Flag: flag.Name, Replacement: current.Name}) {
+			fmt.Fprintf(w, i18n.T("Aviso: a flag --%s está obsoleta e será removida; use --%s\n"), flag.Name, current.Name)
+		}
+	})
+	return err
+}
+
+// record adds a usage, returning false if it was already recorded.
+func record(usage Usage) bool {
+	mu.Lock()
+	defer mu.Unlock()
+	if slices.Contains(used, usage) {
+		return false
+	}
+	used = append(used, usage)
+	return true
+}
+
+// Used returns the deprecated flags used in this run, sorted by name.
+func Used() []Usage {
+	mu.Lock()
+	defer mu.Unlock()
+	usages := slices.Clone(used)
+	sort.Slice(usages, func(i, j int) bool { return usages[i].Flag < usages[j].Flag })
+	return usages
+}
+
+// reset clears the recorded usages (tests).
+func reset() {
+	mu.Lock()
+	defer mu.Unlock()
+	used = nil
+}
@@ -0,0 +1,169 @@
+package flagalias
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/spf13/pflag"
+
+	"github.com/estudosdevops/opsmaster/internal/profile"
+)
+
+// newFlags creates a flag set with --inventory and its deprecated alias
+// --instances-file, plus a repeatable --where renamed from --filter.
+func newFlags(t *testing.T) (*pflag.FlagSet, *string, *[]string) {
+	t.Helper()
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	inventory := flags.String("inventory", "", "")
+	where := flags.StringArray("where", nil, "")
+	if err := Deprecate(flags, "instances-file", "inventory"); err != nil {
+		t.Fatalf("Deprecate() unexpected error: %v", err)
+	}
+	if err := Deprecate(flags, "filter", "where"); err != nil {
+		t.Fatalf("Deprecate() unexpected error: %v", err)
+	}
+	return flags, inventory, where
+}
+
+// TestCheck tests parsing flags with deprecated aliases
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name          string
+		args          []string
+		wantInventory string
+		wantWhere     string
+		wantUsed      string
+		wantWarnings  int
+		expectError   bool
+	}{
+		{
+			name:          "current names",
+			args:          []string{"--inventory", "fleet.csv", "--where", "env=prod"},
+			wantInventory: "fleet.csv",
+			wantWhere:     "env=prod",
+		},
+		{
+			name:          "deprecated name",
+			args:          []string{"--instances-file", "fleet.csv"},
+			wantInventory: "fleet.csv",
+			wantUsed:      "instances-file->inventory",
+			wantWarnings:  1,
+		},
+		{
+			name:         "repeated deprecated flag warns once",
+			args:         []string{"--filter", "env=prod", "--filter", "shard<5"},
+			wantWhere:    "env=prod,shard<5",
+			wantUsed:     "filter->where",
+			wantWarnings: 1,
+		},
+		{
+			name:        "both names",
+			args:        []string{"--instances-file", "a.csv", "--inventory", "b.csv"},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// ARRANGE
+			reset()
+			flags, inventory, where := newFlags(t)
+			if err := flags.Parse(tt.args); err != nil {
+				t.Fatalf("Parse() unexpected error: %v", err)
+			}
+			var warnings bytes.Buffer
+
+			// ACT
+			err := Check(flags, &warnings)
+
+			// ASSERT
+			if tt.expectError {
+				if err == nil {
+					t.Fatal("Check() expected error when both names are set")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Check() unexpected error: %v", err)
+			}
+			if *inventory != tt.wantInventory {
+				t.Errorf("inventory = %q, want %q", *inventory, tt.wantInventory)
+			}
+			if got := strings.Join(*where, ","); got != tt.wantWhere {
+				t.Errorf("where = %q, want %q", got, tt.wantWhere)
+			}
+			if tt.wantInventory != "" && !flags.Changed("inventory") {
+				t.Error("current flag should be marked as changed")
+			}
+
+			var used []string
+			for _, u := range Used() {
+				used = append(used, u.Flag+"->"+u.Replacement)
+			}
+			if got := strings.Join(used, ","); got != tt.wantUsed {
+				t.Errorf("Used() = %q, want %q", got, tt.wantUsed)
+			}
+			if got := strings.Count(warnings.String(), "obsoleta"); got != tt.wantWarnings {
+				t.Errorf("warnings = %d, want %d (%q)", got, tt.wantWarnings, warnings.String())
+			}
+		})
+	}
+}
+
+// TestCheck_BeforeEnvAndProfile tests that a deprecated name on the command
+// line wins over the environment and the config profile of the current name
+func TestCheck_BeforeEnvAndProfile(t *testing.T) {
+	// ARRANGE
+	reset()
+	flags, inventory, _ := newFlags(t)
+	if err := flags.Parse([]string{"--instances-file", "cli.csv"}); err != nil {
+		t.Fatalf("Parse() unexpected error: %v", err)
+	}
+	env := map[string]string{"OPSMASTER_INVENTORY": "env.csv"}
+	lookup := func(name string) (string, bool) {
+		value, ok := env[name]
+		return value, ok
+	}
+
+	// ACT (same order as the root command)
+	if err := Check(flags, &bytes.Buffer{}); err != nil {
+		t.Fatalf("Check() unexpected error: %v", err)
+	}
+	applied, err := profile.ApplyEnv(flags, lookup)
+	if err != nil {
+		t.Fatalf("ApplyEnv() unexpected error: %v", err)
+	}
+	profileApplied, err := profile.Apply(flags, nil, map[string]any{"inventory": "profile.csv"})
+	if err != nil {
+		t.Fatalf("Apply() unexpected error: %v", err)
+	}
+
+	// ASSERT
+	if *inventory != "cli.csv" {
+		t.Errorf("inventory = %q, want the command line value cli.csv", *inventory)
+	}
+	if len(applied) != 0 || len(profileApplied) != 0 {
+		t.Errorf("env applied %v, profile applied %v, want none", applied, profileApplied)
+	}
+}
+
+// TestDeprecate_Errors tests invalid alias registrations
+func TestDeprecate_Errors(t *testing.T) {
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	flags.String("inventory", "", "")
+	flags.String("output", "", "")
+
+	if err := Deprecate(flags, "instances-file", "missing"); err == nil {
+		t.Error("Deprecate() expected error for an undefined current flag")
+	}
+	if err := Deprecate(flags, "output", "inventory"); err == nil {
+		t.Error("Deprecate() expected error when the old name is still defined")
+	}
+	if err := Deprecate(flags, "instances-file", "inventory"); err != nil {
+		t.Fatalf("Deprecate() unexpected error: %v", err)
+	}
+	if !flags.Lookup("instances-file").Hidden {
+		t.Error("deprecated alias should be hidden from help")
+	}
+}
@@ -0,0 +1,218 @@
+package httpclient
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/estudosdevops/opsmaster/internal/retry"
+)
+
+const (
+	// defaultTimeout is the overall timeout for a single HTTP request (all retries included).
+	defaultTimeout = 30 * time.Second
+
+	// defaultDialTimeout is the maximum time to establish a TCP connection.
+	defaultDialTimeout = 10 * time.Second
+)
+
+// Config holds configuration for outbound HTTP clients.
+// The zero value is valid and yields proxy-aware clients with system CAs.
+type Config struct {
+	// Timeout is the overall request timeout (default: 30s)
+	Timeout time.Duration
+
+	// CABundle is a PEM file with extra CA certificates trusted in addition
+	// to the system pool (e.g., corporate proxy or internal Puppet CA)
+	CABundle string
+
+	// ClientCert and ClientKey are PEM files for mutual TLS (optional,
+	// e.g., PuppetDB with the agent's certificate). Both must be set together.
+	ClientCert string
+	ClientKey  string
+
+	// RetryConfig is the retry policy for idempotent requests
+	// Optional: uses retry.NetworkPolicy if nil
+	RetryConfig *retry.RetryConfig
+}
+
+// shared holds the process-wide client built from the last Configure call,
+// or from the default configuration on first use. Guarded by mu so
+// subsystems can fetch it from any goroutine, and a first use racing an
+// explicit Configure never replaces the configured clients with defaults.
+var (
+	mu            sync.RWMutex
+	sharedClient  *http.Client
+	sharedNoRetry *http.Client
+)
+
+// Configure (re)builds the shared clients with the given configuration.
+// Call once during CLI initialization (e.g., from --ca-bundle).
+func Configure(cfg Config) error {
+	transport, err := newTransport(cfg)
+	if err != nil {
+		return err
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	setShared(cfg, transport)
+	return nil
+}
+
+// Shared returns the process-wide HTTP client.
+// Honors proxy environment variables (HTTP_PROXY, HTTPS_PROXY, NO_PROXY),
+// the configured CA bundle, timeouts and retries for idempotent requests.
+// If the default configuration cannot be built, requests return its error.
+func Shared() *http.Client {
+	client, _, err := shared()
+	if err != nil {
+		return failingClient(err)
+	}
+	return client
+}
+
+// SharedNoRetry returns the process-wide HTTP client without retries.
+// Use it where a single attempt must be observed as-is (e.g., monitoring).
+func SharedNoRetry() *http.Client {
+	_, noRetry, err := shared()
+	if err != nil {
+		return failingClient(err)
+	}
+	return noRetry
+}
+
+// New creates a standalone HTTP client with the given configuration.
+// Prefer Shared() unless a subsystem needs different settings.
+func New(cfg Config) (*http.Client, error) {
+	transport, err := newTransport(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return newClient(cfg, newRetryTransport(transport, retryPolicy(cfg))), nil
+}
+
+// shared returns the shared clients, building them with the default
+// configuration on first use unless Configure already ran.
+func shared() (client, noRetry *http.Client, err error) {
+	mu.RLock()
+	client, noRetry = sharedClient, sharedNoRetry
+	mu.RUnlock()
+	if client != nil {
+		return client, noRetry, nil
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	// Checked again under the write lock: Configure may have run meanwhile
+	if sharedClient == nil {
+		transport, err := newTransport(Config{})
+		if err != nil {
+			return nil, nil, fmt.Errorf("httpclient: default configuration failed: %w", err)
+		}
+		setShared(Config{}, transport)
+	}
+	return sharedClient, sharedNoRetry, nil
+}
+
+// setShared builds the shared clients over transport. Callers hold mu.
+func setShared(cfg Config, transport *http.Transport) {
+	sharedClient = newClient(cfg, newRetryTransport(transport, retryPolicy(cfg)))
+	sharedNoRetry = newClient(cfg, transport)
+}
+
+// failingTransport fails every request with err.
+type failingTransport struct{ err error }
+
+func (t failingTransport) RoundTrip(*http.Request) (*http.Response, error) {
+	return nil, t.err
+}
+
+// failingClient returns a client whose requests fail with err, so callers
+// get the error from Do instead of a panic.
+func failingClient(err error) *http.Client {
+	return &http.Client{Transport: failingTransport{err: err}}
+}
+
+// newClient wraps a transport in an http.Client with the configured timeout.
+func newClient(cfg Config, transport http.RoundTripper) *http.Client {
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	return &http.Client{
+		Transport: transport,
+		Timeout:   timeout,
+	}
+}
+
+// newTransport builds a proxy-aware transport trusting system CAs plus the CA bundle.
+func newTransport(cfg Config) (*http.Transport, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if cfg.CABundle != "" {
+		pool, err := loadCABundle(cfg.CABundle)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if cfg.ClientCert != "" || cfg.ClientKey != "" {
+		if cfg.ClientCert == "" || cfg.ClientKey == "" {
+			return nil, fmt.Errorf("client certificate and key must be set together")
+		}
+		cert, err := tls.LoadX509KeyPair(cfg.ClientCert, cfg.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   defaultDialTimeout,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		TLSClientConfig:       tlsConfig,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          100,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   defaultDialTimeout,
+		ExpectContinueTimeout: time.Second,
+	}, nil
+}
+
+// loadCABundle returns the system cert pool extended with certificates from path.
+func loadCABundle(path string) (*x509.CertPool, error) {
+	pem, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA bundle: %w", err)
+	}
+
+	pool, err := x509.SystemCertPool()
+	if err != nil || pool == nil {
+		pool = x509.NewCertPool()
+	}
+
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no valid PEM certificates found in CA bundle %s", path)
+	}
+
+	return pool, nil
+}
+
+// retryPolicy returns the configured retry policy or the network default.
+func retryPolicy(cfg Config) retry.RetryConfig {
+	if cfg.RetryConfig != nil {
+		return *cfg.RetryConfig
+	}
+	return retry.NetworkPolicy
+}
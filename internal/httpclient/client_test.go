@@ -0,0 +1,211 @@
+package httpclient
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/estudosdevops/opsmaster/internal/retry"
+)
+
+// fastRetry is a retry policy with tiny delays to keep tests fast.
+var fastRetry = retry.RetryConfig{
+	MaxAttempts: 3,
+	BaseDelay:   time.Millisecond,
+	MaxDelay:    5 * time.Millisecond,
+}
+
+// TestNew_RetriesTransientStatus tests that idempotent requests are retried on 503.
+func TestNew_RetriesTransientStatus(t *testing.T) {
+	// ARRANGE
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client, err := New(Config{RetryConfig: &fastRetry})
+	if err != nil {
+		t.Fatalf("New() unexpected error: %v", err)
+	}
+
+	// ACT
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Get() unexpected error: %v", err)
+	}
+	defer resp.Body.Close()
+
+	// ASSERT
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("StatusCode = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+	if calls.Load() != 3 {
+		t.Errorf("calls = %d, want 3", calls.Load())
+	}
+}
+
+// TestNew_ReturnsLastResponseWhenExhausted tests that the final transient
+// response is returned to the caller after retries are exhausted.
+func TestNew_ReturnsLastResponseWhenExhausted(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	client, err := New(Config{RetryConfig: &fastRetry})
+	if err != nil {
+		t.Fatalf("New() unexpected error: %v", err)
+	}
+
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Get() unexpected error: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusBadGateway {
+		t.Errorf("StatusCode = %d, want %d", resp.StatusCode, http.StatusBadGateway)
+	}
+}
+
+// TestNew_DoesNotRetryPost tests that non-idempotent requests are sent once.
+func TestNew_DoesNotRetryPost(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client, err := New(Config{RetryConfig: &fastRetry})
+	if err != nil {
+		t.Fatalf("New() unexpected error: %v", err)
+	}
+
+	resp, err := client.Post(server.URL, "application/json", strings.NewReader("{}"))
+	if err != nil {
+		t.Fatalf("Post() unexpected error: %v", err)
+	}
+	resp.Body.Close()
+
+	if calls.Load() != 1 {
+		t.Errorf("calls = %d, want 1", calls.Load())
+	}
+}
+
+// TestNew_InvalidCABundle tests CA bundle loading errors.
+func TestNew_InvalidCABundle(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		create  bool
+	}{
+		{name: "missing file", create: false},
+		{name: "no PEM certificates", content: "not a certificate", create: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "ca.pem")
+			if tt.create {
+				if err := os.WriteFile(path, []byte(tt.content), 0o600); err != nil {
+					t.Fatalf("failed to write CA bundle: %v", err)
+				}
+			}
+
+			if _, err := New(Config{CABundle: path}); err == nil {
+				t.Error("Expected error for invalid CA bundle")
+			}
+		})
+	}
+}
+
+// TestNew_InvalidClientCert tests mutual TLS configuration errors.
+func TestNew_InvalidClientCert(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "missing.pem")
+
+	tests := []struct {
+		name string
+		cfg  Config
+	}{
+		{name: "cert without key", cfg: Config{ClientCert: missing}},
+		{name: "key without cert", cfg: Config{ClientKey: missing}},
+		{name: "unreadable pair", cfg: Config{ClientCert: missing, ClientKey: missing}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := New(tt.cfg); err == nil {
+				t.Error("Expected error for invalid client certificate")
+			}
+		})
+	}
+}
+
+// TestShared_ReturnsSameClient tests that the shared client is reused.
+func TestShared_ReturnsSameClient(t *testing.T) {
+	if err := Configure(Config{Timeout: 5 * time.Second}); err != nil {
+		t.Fatalf("Configure() unexpected error: %v", err)
+	}
+
+	first := Shared()
+	second := Shared()
+	if first != second {
+		t.Error("Shared() should return the same client instance")
+	}
+	if first.Timeout != 5*time.Second {
+		t.Errorf("Timeout = %v, want %v", first.Timeout, 5*time.Second)
+	}
+	if SharedNoRetry() == first {
+		t.Error("SharedNoRetry() should be a distinct client")
+	}
+}
+
+// TestShared_ConcurrentFirstUse tests that a first use racing Configure
+// never replaces the configured clients with the defaults.
+func TestShared_ConcurrentFirstUse(t *testing.T) {
+	for range 50 {
+		mu.Lock()
+		sharedClient, sharedNoRetry = nil, nil
+		mu.Unlock()
+
+		var wg sync.WaitGroup
+		for range 4 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				Shared()
+			}()
+		}
+		if err := Configure(Config{Timeout: 5 * time.Second}); err != nil {
+			t.Fatalf("Configure() unexpected error: %v", err)
+		}
+		wg.Wait()
+
+		if got := Shared().Timeout; got != 5*time.Second {
+			t.Fatalf("Timeout = %v after concurrent first use, want %v", got, 5*time.Second)
+		}
+	}
+}
+
+// TestFailingClient tests that requests of a client that could not be
+// built return the configuration error.
+func TestFailingClient(t *testing.T) {
+	client := failingClient(errors.New("default configuration failed"))
+	_, err := client.Get("http://example.invalid")
+	if err == nil || !strings.Contains(err.Error(), "default configuration failed") {
+		t.Errorf("error = %v, want the configuration error", err)
+	}
+}
@@ -0,0 +1,102 @@
+package httpclient
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/estudosdevops/opsmaster/internal/retry"
+)
+
+// retryTransport retries idempotent requests on network errors and
+// transient HTTP statuses (429, 502, 503, 504) using the retry package.
+type retryTransport struct {
+	base    http.RoundTripper
+	retryer retry.Retryer
+}
+
+// newRetryTransport wraps base with retries according to policy.
+func newRetryTransport(base http.RoundTripper, policy retry.RetryConfig) *retryTransport {
+	return &retryTransport{
+		base:    base,
+		retryer: retry.New(policy),
+	}
+}
+
+// RoundTrip implements http.RoundTripper.
+// Non-idempotent requests are sent once. When retries are exhausted on a
+// transient status, the last response is returned so callers can inspect it.
+func (rt *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !isIdempotent(req) {
+		return rt.base.RoundTrip(req)
+	}
+
+	var resp *http.Response
+	err := rt.retryer.Do(req.Context(), func() error {
+		// Discard response from previous attempt
+		if resp != nil {
+			resp.Body.Close()
+			resp = nil
+		}
+
+		attemptReq, err := rewindRequest(req)
+		if err != nil {
+			return err
+		}
+
+		r, err := rt.base.RoundTrip(attemptReq)
+		if err != nil {
+			return err
+		}
+
+		resp = r
+		if isRetryableStatus(r.StatusCode) {
+			return fmt.Errorf("server returned %s", r.Status)
+		}
+		return nil
+	})
+
+	if resp != nil && req.Context().Err() == nil {
+		return resp, nil
+	}
+	if resp != nil {
+		resp.Body.Close()
+	}
+	return nil, err
+}
+
+// rewindRequest returns a request whose body can be read again for a new attempt.
+func rewindRequest(req *http.Request) (*http.Request, error) {
+	if req.Body == nil || req.Body == http.NoBody || req.GetBody == nil {
+		return req, nil
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, fmt.Errorf("failed to rewind request body: %w", err)
+	}
+
+	clone := req.Clone(req.Context())
+	clone.Body = body
+	return clone, nil
+}
+
+// isIdempotent reports whether a request can be safely retried.
+// Requests with a body that cannot be rewound are never retried.
+func isIdempotent(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+	default:
+		return false
+	}
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+// isRetryableStatus reports whether an HTTP status indicates a transient failure.
+func isRetryableStatus(code int) bool {
+	switch code {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,155 @@
+package i18n
+
+// message is a catalog entry: the same text in both languages. Either one
+// can be the text used in the code (the catalog key).
+type message struct {
+	ptBR string
+	en   string
+}
+
+// catalog holds every translated message.
+var catalog = append(append([]message{}, helpMessages...), outputMessages...)
+
+// outputMessages translates console output: cobra's help template, table
+// headers, summaries and validation errors.
+var outputMessages = []message{
+	// Help template (see cmd/root.go)
+	{ptBR: "Uso:", en: "Usage:"},
+	{ptBR: "Exemplos:", en: "Examples:"},
+	{ptBR: "Comandos Disponíveis:", en: "Available Commands:"},
+	{ptBR: "Comandos Adicionais:", en: "Additional Commands:"},
+	{ptBR: "Flags Globais:", en: "Global Flags:"},
+	{ptBR: "Tópicos de ajuda adicionais:", en: "Additional help topics:"},
+	{ptBR: `Use "{{.CommandPath}} [comando] --help" para mais informações sobre um comando.`, en: `Use "{{.CommandPath}} [command] --help" for more information about a command.`},
+	{ptBR: "ajuda para %s", en: "help for %s"},
+	{ptBR: "Ajuda sobre qualquer comando", en: "Help about any command"},
+	{ptBR: "Gera o script de autocompletar para o shell informado", en: "Generate the autocompletion script for the specified shell"},
+	{ptBR: "Gera o script de autocompletar para %s", en: "Generate the autocompletion script for %s"},
+
+	// Table headers
+	{ptBR: "CONTA", en: "ACCOUNT"},
+	{ptBR: "REGIÃO", en: "REGION"},
+	{ptBR: "ERRO", en: "ERROR"},
+	{ptBR: "ESTÁGIO", en: "STAGE"},
+	{ptBR: "COMANDO", en: "COMMAND"},
+	{ptBR: "CAMINHO", en: "PATH"},
+	{ptBR: "ITENS", en: "ITEMS"},
+	{ptBR: "REMOVIDOS", en: "REMOVED"},
+	{ptBR: "TAMANHO", en: "SIZE"},
+	{ptBR: "LIBERADO", en: "FREED"},
+	{ptBR: "DETALHE", en: "DETAIL"},
+	{ptBR: "DURAÇÃO", en: "DURATION"},
+	{ptBR: "INSTÂNCIAS", en: "INSTANCES"},
+	{ptBR: "SO", en: "OS"},
+	{ptBR: "SUCESSO", en: "SUCCESS"},
+	{ptBR: "FALHAS", en: "FAILED"},
+	{ptBR: "IGNORADAS", en: "SKIPPED"},
+	{ptBR: "TAXA DE FALHA", en: "FAILURE RATE"},
+	{ptBR: "DURAÇÃO MÉDIA", en: "AVG DURATION"},
+	{ptBR: "DURAÇÃO MÁXIMA", en: "MAX DURATION"},
+	{ptBR: "ESTADO ANTERIOR", en: "PREVIOUS STATE"},
+	{ptBR: "CERTNAME ANTERIOR", en: "PREVIOUS CERTNAME"},
+	{ptBR: "ÚLTIMO RELATÓRIO", en: "LAST REPORT"},
+	{ptBR: "LOTE", en: "BATCH"},
+	{ptBR: "SAÍDA", en: "OUTPUT"},
+	{ptBR: "STATUS DA EXECUÇÃO", en: "RUN STATUS"},
+	{ptBR: "RESULTADO", en: "RESULT"},
+	{ptBR: "NOME", en: "NAME"},
+	{ptBR: "PROJETO", en: "PROJECT"},
+	{ptBR: "STATUS SAÚDE", en: "HEALTH STATUS"},
+	{ptBR: "REPOSITÓRIO", en: "REPOSITORY"},
+	{ptBR: "DESCRIÇÃO", en: "DESCRIPTION"},
+	{ptBR: "URL DO REPOSITÓRIO", en: "REPOSITORY URL"},
+	{ptBR: "NOME DE USUÁRIO", en: "USERNAME"},
+	{ptBR: "SERVIDOR", en: "SERVER"},
+	{ptBR: "STATUS DA CONEXÃO", en: "CONNECTION STATUS"},
+	{ptBR: "VERSÃO DO SERVIDOR", en: "SERVER VERSION"},
+	{ptBR: "CAMPO", en: "FIELD"},
+	{ptBR: "VALOR", en: "VALUE"},
+	{ptBR: "GRUPO", en: "GROUP"},
+	{ptBR: "TIPO", en: "TYPE"},
+	{ptBR: "SAÚDE", en: "HEALTH"},
+	{ptBR: "MENSAGEM", en: "MESSAGE"},
+	{ptBR: "DOMÍNIO", en: "DOMAIN"},
+	{ptBR: "MÁSCARA", en: "MASK"},
+	{ptBR: "CIDADE", en: "CITY"},
+	{ptBR: "PAÍS", en: "COUNTRY"},
+	{ptBR: "ORGANIZAÇÃO", en: "ORGANIZATION"},
+	{ptBR: "PORTA", en: "PORT"},
+	{ptBR: "ARQUITETURAS", en: "ARCHITECTURES"},
+	{ptBR: "FLAGS OBRIGATÓRIAS", en: "REQUIRED FLAGS"},
+	{ptBR: "CAPACIDADES", en: "CAPABILITIES"},
+	{ptBR: "QUEBRA", en: "BREAKDOWN"},
+	{ptBR: "COBERTAS", en: "COVERED"},
+	{ptBR: "SEM COBERTURA", en: "UNCOVERED"},
+	{ptBR: "DESCONHECIDAS", en: "UNKNOWN"},
+	{ptBR: "COBERTURA", en: "COVERAGE"},
+	{ptBR: "Ambiente", en: "Environment"},
+	{ptBR: "Valores", en: "Values"},
+
+	// Summaries
+	{ptBR: "Usando o arquivo de configuração:", en: "Using config file:"},
+	{ptBR: "Usando o perfil:", en: "Using profile:"},
+	{ptBR: "Aviso: a flag --%s está obsoleta e será removida; use --%s", en: "Warning: flag --%s is deprecated and will be removed; use --%s"},
+	{ptBR: "📊 Resumo: %d sucesso, %d falhas, %d ignoradas", en: "📊 Summary: %d successful, %d failed, %d skipped"},
+	{ptBR: "📊 Resumo: %d alteradas, %d ignoradas, %d falhas", en: "📊 Summary: %d changed, %d skipped, %d failed"},
+	{ptBR: "📊 Resumo: %d saudáveis/reiniciadas, %d ignoradas, %d não saudáveis", en: "📊 Summary: %d healthy/rebooted, %d skipped, %d unhealthy"},
+	{ptBR: "📊 Resumo: %d marcadas, %d falhas", en: "📊 Summary: %d tagged, %d failed"},
+	{ptBR: "⏭️  %d instâncias em modo manutenção ignoradas", en: "⏭️  %d instances in maintenance mode skipped"},
+	{ptBR: "👻 %d instâncias desapareceram durante a execução (terminadas ou desregistradas)", en: "👻 %d instances disappeared mid-run (terminated or deregistered)"},
+	{ptBR: "📊 regeneradas: %d | falhas: %d | ignoradas: %d", en: "📊 regenerated: %d | failed: %d | skipped: %d"},
+	{ptBR: "📊 Por conta:", en: "📊 By account:"},
+	{ptBR: "📊 Por região:", en: "📊 By region:"},
+	{ptBR: "🐢 %d instância(s) mais lenta(s):", en: "🐢 Slowest %d instance(s):"},
+	{ptBR: "⏱️  Validações mais lentas:", en: "⏱️  Slowest validations:"},
+	{ptBR: "🔎 Grupos de falhas:", en: "🔎 Failure clusters:"},
+	{ptBR: "%d× %s\n      ex: %s", en: "%d× %s\n      e.g. %s"},
+	{ptBR: "⚖️  %d instância(s) com status alterado por --success-when:", en: "⚖️  %d instance(s) with status changed by --success-when:"},
+	{ptBR: "🐌 %d instância(s) com fases mais longas que o esperado (--expected-duration):", en: "🐌 %d instance(s) with phases longer than expected (--expected-duration):"},
+	{ptBR: "⏳ %d instância(s) verificada(s) com atraso (dentro de --verify-grace-period):", en: "⏳ %d instance(s) verified late (within --verify-grace-period):"},
+	{ptBR: "🔒 %d instância(s) com lock de execução do puppet agent (--agent-lock %s):", en: "🔒 %d instance(s) with a puppet agent run lock (--agent-lock %s):"},
+	{ptBR: "⚠️  O hook pós-instalação falhou em %d instância(s) instalada(s):", en: "⚠️  Post-install hook failed for %d installed instance(s):"},
+	{ptBR: "⚠️  %d linha(s) inválida(s) ignorada(s) no CSV:", en: "⚠️  %d invalid CSV row(s) skipped:"},
+	{ptBR: "🖥️  Instâncias por família de SO:", en: "🖥️  Instances by OS family:"},
+	{ptBR: "✅ %s é um relatório válido (schema_version %d)", en: "✅ %s is a valid report (schema_version %d)"},
+	{ptBR: "❌ %s não corresponde ao schema_version %d:", en: "❌ %s does not match schema_version %d:"},
+	{ptBR: "⏸️  Execução %s pausada: instâncias em execução terminam, a fila aguarda", en: "⏸️  Run %s paused: running instances finish, the queue waits"},
+	{ptBR: "▶️  Execução %s retomada", en: "▶️  Run %s resumed"},
+	{ptBR: "📊 Cobertura (%s): %.1f%% — %d cobertas, %d sem cobertura, %d desconhecidas", en: "📊 Coverage (%s): %.1f%% — %d covered, %d uncovered, %d unknown"},
+	{ptBR: "📈 Medição anterior (%s): %.1f%% (%+.1f pontos)", en: "📈 Previous measurement (%s): %.1f%% (%+.1f points)"},
+	{ptBR: "⏹️  Execução %s abortada: a fila será cancelada, instâncias em execução terminam", en: "⏹️  Run %s aborted: the queue will be canceled, running instances finish"},
+	{ptBR: "📋 Pipeline %s: %d estágios", en: "📋 Pipeline %s: %d stages"},
+	{ptBR: "▶️  Estágio %d/%d: %s (%s)", en: "▶️  Stage %d/%d: %s (%s)"},
+	{ptBR: "🔍 Dry run: %d item(s) seriam removidos, liberando %s", en: "🔍 Dry run: %d item(s) would be removed, freeing %s"},
+	{ptBR: "🧹 %d item(s) removido(s), %s liberados", en: "🧹 %d item(s) removed, %s freed"},
+
+	// Validation errors
+	{ptBR: "--provider inválido %q (suportado: fake)", en: "invalid --provider %q (supported: fake)"},
+	{ptBR: "--fake-scenario requer --provider fake", en: "--fake-scenario requires --provider fake"},
+	{ptBR: "seletor --where inválido: %w", en: "invalid --where selector: %w"},
+	{ptBR: "nenhuma instância selecionada do arquivo CSV", en: "no instances selected from CSV file"},
+	{ptBR: "nenhuma instância encontrada no arquivo CSV", en: "no instances found in CSV file"},
+	{ptBR: "nenhuma instância corresponde aos seletores --where: %s", en: "no instances match --where selectors: %s"},
+	{ptBR: "nenhuma instância corresponde a --only-os %s", en: "no instances match --only-os %s"},
+	{ptBR: "--path inválido %q: deve ser um caminho absoluto", en: "invalid --path %q: must be an absolute path"},
+	{ptBR: "--lines inválido %d: deve estar entre 1 e %d", en: "invalid --lines %d: must be between 1 and %d"},
+	{ptBR: "--shell inválido %q (suportados: auto, sh, bash, powershell)", en: "invalid --shell %q (supported: auto, sh, bash, powershell)"},
+	{ptBR: "--windows-file requer --shell auto", en: "--windows-file requires --shell auto"},
+	{ptBR: "--post-check requer --wait", en: "--post-check requires --wait"},
+	{ptBR: "--batch-size deve ser no mínimo 1", en: "--batch-size must be at least 1"},
+	{ptBR: "o relatório %s é de um dry-run: nenhuma tag foi registrada", en: "report %s is from a dry-run: no tags were recorded"},
+	{ptBR: "nenhuma instância com tags a aplicar no relatório %s", en: "no instances with tags to apply in report %s"},
+	{ptBR: "o relatório %s tem %d violações do schema", en: "report %s has %d schema violations"},
+	{ptBR: "o pipeline %s falhou", en: "pipeline %s failed"},
+	{ptBR: "modo somente leitura: %s altera as instâncias, apenas --dry-run é permitido", en: "read-only mode: %s changes the instances, only --dry-run is allowed"},
+	{ptBR: "nada para podar: use --dir/--file ou defina history.report_dirs/history.files no arquivo de configuração", en: "nothing to prune: use --dir/--file or set history.report_dirs/history.files in the config file"},
+	{ptBR: "nenhum limite de retenção: use --keep/--max-size ou defina history.keep/history.max_size no arquivo de configuração", en: "no retention limit: use --keep/--max-size or set history.keep/history.max_size in the config file"},
+	{
+		ptBR: "nenhum contexto definido e as flags --server e --token não foram fornecidas. Use a flag --context ou defina 'current-context' no seu ~/.opsmaster.yaml",
+		en:   "no context set and the --server and --token flags were not given. Use the --context flag or set 'current-context' in your ~/.opsmaster.yaml",
+	},
+	{
+		ptBR: "o endereço do servidor e o token do Argo CD são obrigatórios. Forneça-os via flags ou no arquivo de configuração para o contexto '%s'",
+		en:   "the Argo CD server address and token are required. Give them via flags or in the config file for the context '%s'",
+	},
+}
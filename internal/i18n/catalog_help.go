@@ -0,0 +1,3087 @@
+package i18n
+
+// helpMessages translates the help of the commands (Short, Long and flag
+// usages), in the order of the command files.
+var helpMessages = []message{
+	// cmd/argocd/app/app.go
+	{
+		ptBR: "Gerencia aplicações do Argo CD",
+		en:   "Manages Argo CD applications",
+	},
+	{
+		ptBR: "Um conjunto de subcomandos para criar, listar e aguardar o status de aplicações no Argo CD.",
+		en:   "A set of subcommands to create, list and wait for the status of Argo CD applications.",
+	},
+	// cmd/argocd/app/create.go
+	{
+		ptBR: "Cria ou atualiza uma aplicação no Argo CD a partir de um repositório",
+		en:   "Creates or updates an Argo CD application from a repository",
+	},
+	{
+		ptBR: "Cria uma Application no Argo CD apontando para um repositório Git que contém um Helm Chart e um arquivo de valores.",
+		en:   "Creates an Argo CD Application pointing to a Git repository that holds a Helm chart and a values file.",
+	},
+	{
+		ptBR: "Nome da aplicação no Argo CD (obrigatório)",
+		en:   "Name of the application in Argo CD (required)",
+	},
+	{
+		ptBR: "Projeto do Argo CD ao qual a aplicação pertencerá",
+		en:   "Argo CD project the application will belong to",
+	},
+	{
+		ptBR: "Namespace de destino no Kubernetes (obrigatório)",
+		en:   "Target Kubernetes namespace (required)",
+	},
+	{
+		ptBR: "URL do repositório Git que contém o chart (obrigatório)",
+		en:   "URL of the Git repository holding the chart (required)",
+	},
+	{
+		ptBR: "Caminho para o diretório do chart dentro do repositório",
+		en:   "Path to the chart directory inside the repository",
+	},
+	{
+		ptBR: "Branch, tag ou commit do Git a ser usado",
+		en:   "Git branch, tag or commit to use",
+	},
+	{
+		ptBR: "Caminho para o arquivo de valores dentro do repositório (relativo ao repo-path)",
+		en:   "Path to the values file inside the repository (relative to repo-path)",
+	},
+	{
+		ptBR: "Define o nome do repositório da imagem (obrigatório)",
+		en:   "Sets the image repository name (required)",
+	},
+	{
+		ptBR: "Define a tag da imagem a ser usada no deploy (obrigatório)",
+		en:   "Sets the image tag to deploy (required)",
+	},
+	{
+		ptBR: "Nome da dependência do chart no Chart.yaml",
+		en:   "Name of the chart dependency in Chart.yaml",
+	},
+	{
+		ptBR: "Endereço do cluster Kubernetes de destino",
+		en:   "Address of the target Kubernetes cluster",
+	},
+	// cmd/argocd/app/delete.go
+	{
+		ptBR: "Apaga uma aplicação do Argo CD",
+		en:   "Deletes an Argo CD application",
+	},
+	{
+		ptBR: "Remove uma aplicação do Argo CD. Por padrão, esta operação não apaga os recursos no cluster Kubernetes.",
+		en:   "Removes an application from Argo CD. By default, this operation doesn't delete the resources in the Kubernetes cluster.",
+	},
+	// cmd/argocd/app/get.go
+	{
+		ptBR: "Exibe detalhes de uma aplicação específica",
+		en:   "Shows the details of an application",
+	},
+	{
+		ptBR: "Busca e exibe informações detalhadas sobre uma única aplicação no Argo CD, incluindo status, repositório e recursos sincronizados.",
+		en:   "Fetches and shows detailed information about a single Argo CD application, including status, repository and synced resources.",
+	},
+	// cmd/argocd/app/list.go
+	{
+		ptBR: "Lista todas as aplicações ou uma aplicação específica no Argo CD",
+		en:   "Lists all applications or a specific application in Argo CD",
+	},
+	{
+		ptBR: "Busca e exibe aplicações gerenciadas pelo Argo CD. Se um nome de aplicação for fornecido, exibe apenas os detalhes daquela aplicação.",
+		en:   "Fetches and shows the applications managed by Argo CD. If an application name is given, shows only the details of that application.",
+	},
+	// cmd/argocd/app/rollout/abort.go
+	{
+		ptBR: "Aborta um rollout em andamento",
+		en:   "Aborts a rollout in progress",
+	},
+	{
+		ptBR: "Envia um comando para o Argo Rollouts para cancelar um deploy em andamento e reverter para a versão estável anterior.",
+		en:   "Sends a command to Argo Rollouts to cancel a deploy in progress and revert to the previous stable version.",
+	},
+	{
+		ptBR: "Aguarda a aplicação ficar saudável e sincronizada após o abort",
+		en:   "Waits for the application to be healthy and synced after the abort",
+	},
+	{
+		ptBR: "Tempo máximo de espera pela aplicação",
+		en:   "Maximum time to wait for the application",
+	},
+	// cmd/argocd/app/rollout/promote.go
+	{
+		ptBR: "Promove o rollout de uma aplicação para a próxima etapa",
+		en:   "Promotes the rollout of an application to the next step",
+	},
+	{
+		ptBR: "Envia um comando para o Argo Rollouts para avançar o deploy de uma aplicação para a próxima etapa definida na sua estratégia.",
+		en:   "Sends a command to Argo Rollouts to advance the deploy of an application to the next step defined in its strategy.",
+	},
+	{
+		ptBR: "Aguarda a aplicação ficar saudável e sincronizada após a promoção",
+		en:   "Waits for the application to be healthy and synced after the promotion",
+	},
+	// cmd/argocd/app/rollout/retry.go
+	{
+		ptBR: "Tenta novamente uma etapa de um rollout que falhou",
+		en:   "Retries a failed rollout step",
+	},
+	{
+		ptBR: "Envia um comando para o Argo Rollouts para tentar executar novamente a última etapa de um rollout que resultou em falha.",
+		en:   "Sends a command to Argo Rollouts to run again the last step of a rollout that failed.",
+	},
+	{
+		ptBR: "Aguarda a aplicação ficar saudável e sincronizada após a nova tentativa",
+		en:   "Waits for the application to be healthy and synced after the retry",
+	},
+	// cmd/argocd/app/rollout/rollout.go
+	{
+		ptBR: "Gerencia rollouts de uma aplicação",
+		en:   "Manages the rollouts of an application",
+	},
+	{
+		ptBR: "Um conjunto de subcomandos para gerenciar o ciclo de vida de um Argo Rollout associado a uma aplicação.",
+		en:   "A set of subcommands to manage the lifecycle of an Argo Rollout associated with an application.",
+	},
+	// cmd/argocd/app/sync.go
+	{
+		ptBR: "Força a sincronização de uma aplicação no Argo CD",
+		en:   "Forces the sync of an Argo CD application",
+	},
+	{
+		ptBR: "Inicia uma sincronização imediata para uma aplicação, fazendo com que ela corresponda ao estado definido no repositório Git.",
+		en:   "Starts an immediate sync of an application, making it match the state defined in the Git repository.",
+	},
+	{
+		ptBR: "Força a sincronização, substituindo recursos e apagando os que não existem mais no Git (prune)",
+		en:   "Forces the sync, replacing resources and deleting the ones no longer in Git (prune)",
+	},
+	// cmd/argocd/app/wait.go
+	{
+		ptBR: "Aguarda uma aplicação ficar saudável e sincronizada",
+		en:   "Waits for an application to be healthy and synced",
+	},
+	{
+		ptBR: `Monitora continuamente uma aplicação no Argo CD e encerra com sucesso quando
+o status de saúde for 'Healthy' e o status de sincronização for 'Synced'.`,
+		en: `Continuously monitors an Argo CD application and exits successfully when
+its health status is 'Healthy' and its sync status is 'Synced'.`,
+	},
+	{
+		ptBR: "Intervalo entre as verificações de status",
+		en:   "Interval between status checks",
+	},
+	{
+		ptBR: "Exibe os detalhes da aplicação após a conclusão bem-sucedida",
+		en:   "Shows the application details after it completes successfully",
+	},
+	// cmd/argocd/argocd.go
+	{
+		ptBR: "Gerencia interações com o Argo CD",
+		en:   "Manages interactions with Argo CD",
+	},
+	{
+		ptBR: "Um conjunto de comandos para interagir com a API do Argo CD.",
+		en:   "A set of commands to interact with the Argo CD API.",
+	},
+	{
+		ptBR: "Endereço do servidor Argo CD (sobrescreve o config)",
+		en:   "Argo CD server address (overrides the config)",
+	},
+	{
+		ptBR: "Token de autenticação para a API do Argo CD (sobrescreve o config)",
+		en:   "Authentication token for the Argo CD API (overrides the config)",
+	},
+	{
+		ptBR: "Pula a verificação de certificado TLS (sobrescreve o config)",
+		en:   "Skips TLS certificate verification (overrides the config)",
+	},
+	// cmd/argocd/cluster/cluster.go
+	{
+		ptBR: "Gerencia clusters Kubernetes registrados no Argo CD",
+		en:   "Manages Kubernetes clusters registered in Argo CD",
+	},
+	{
+		ptBR: "Um conjunto de subcomandos para adicionar, listar e remover clusters do Argo CD.",
+		en:   "A set of subcommands to add, list and remove Argo CD clusters.",
+	},
+	// cmd/argocd/cluster/list.go
+	{
+		ptBR: "Lista todos os clusters registrados no Argo CD",
+		en:   "Lists all clusters registered in Argo CD",
+	},
+	{
+		ptBR: "Busca e exibe todos os clusters Kubernetes registrados no Argo CD em um formato de tabela.",
+		en:   "Fetches and shows all Kubernetes clusters registered in Argo CD as a table.",
+	},
+	// cmd/argocd/project/create.go
+	{
+		ptBR: "Cria um novo projeto no Argo CD",
+		en:   "Creates a new Argo CD project",
+	},
+	{
+		ptBR: "Cria um novo AppProject no Argo CD com uma descrição e repositórios de origem permitidos.",
+		en:   "Creates a new AppProject in Argo CD with a description and allowed source repositories.",
+	},
+	{
+		ptBR: "Descrição do projeto",
+		en:   "Project description",
+	},
+	{
+		ptBR: "Repositório Git permitido para este projeto",
+		en:   "Git repository allowed for this project",
+	},
+	// cmd/argocd/project/delete.go
+	{
+		ptBR: "Apaga um projeto do Argo CD",
+		en:   "Deletes an Argo CD project",
+	},
+	{
+		ptBR: "Apaga um AppProject específico do Argo CD. Esta ação não pode ser desfeita.",
+		en:   "Deletes a specific AppProject from Argo CD. This action can't be undone.",
+	},
+	// cmd/argocd/project/list.go
+	{
+		ptBR: "Lista todos os projetos ou um projeto específico",
+		en:   "Lists all projects or a specific project",
+	},
+	{
+		ptBR: "Busca e exibe projetos registrados no Argo CD. Se um nome de projeto for fornecido, exibe apenas os detalhes daquele projeto.",
+		en:   "Fetches and shows the projects registered in Argo CD. If a project name is given, shows only the details of that project.",
+	},
+	// cmd/argocd/project/project.go
+	{
+		ptBR: "Gerencia projetos do Argo CD",
+		en:   "Manages Argo CD projects",
+	},
+	{
+		ptBR: "Um conjunto de subcomandos para criar e gerenciar projetos no Argo CD.",
+		en:   "A set of subcommands to create and manage Argo CD projects.",
+	},
+	// cmd/argocd/repo/add.go
+	{
+		ptBR: "Adiciona um novo repositório Git ao Argo CD",
+		en:   "Adds a new Git repository to Argo CD",
+	},
+	{
+		ptBR: "Registra um novo repositório Git no Argo CD. Se o repositório for privado, forneça as credenciais com as flags --username e --password.",
+		en:   "Registers a new Git repository in Argo CD. If the repository is private, give the credentials with the --username and --password flags.",
+	},
+	{
+		ptBR: "Nome de usuário para repositórios privados",
+		en:   "Username for private repositories",
+	},
+	{
+		ptBR: "Senha ou token de acesso pessoal para repositórios privados",
+		en:   "Password or personal access token for private repositories",
+	},
+	// cmd/argocd/repo/delete.go
+	{
+		ptBR: "Remove um repositório do Argo CD",
+		en:   "Removes a repository from Argo CD",
+	},
+	{
+		ptBR: "Remove o registro de um repositório Git do Argo CD. Esta ação não apaga o repositório no Git.",
+		en:   "Removes the registration of a Git repository from Argo CD. This action doesn't delete the repository in Git.",
+	},
+	// cmd/argocd/repo/list.go
+	{
+		ptBR: "Lista todos os repositórios ou um repositório específico",
+		en:   "Lists all repositories or a specific repository",
+	},
+	{
+		ptBR: "Busca e exibe repositórios Git registrados no Argo CD. Se uma URL for fornecida, exibe apenas os detalhes daquele repositório.",
+		en:   "Fetches and shows the Git repositories registered in Argo CD. If a URL is given, shows only the details of that repository.",
+	},
+	// cmd/argocd/repo/repo.go
+	{
+		ptBR: "Gerencia repositórios do Argo CD",
+		en:   "Manages Argo CD repositories",
+	},
+	{
+		ptBR: "Um conjunto de subcomandos para adicionar e gerenciar repositórios no Argo CD.",
+		en:   "A set of subcommands to add and manage Argo CD repositories.",
+	},
+	// cmd/collector/collector.go
+	{
+		ptBR: "Coletor central dos resultados de execuções em vários bastions",
+		en:   "Central collector of the results of runs on several bastions",
+	},
+	{
+		ptBR: `Recebe, via mTLS, os resultados enviados por execuções do opsmaster em vários
+hosts (ex: um bastion por VPC) e os consolida em um único relatório.
+
+Exemplos:
+  opsmaster collector serve --listen :8443 --tls-cert collector.pem --tls-key collector-key.pem --client-ca ca.pem
+  opsmaster install puppet ... --collector-url https://collector.internal:8443 --collector-cert bastion.pem --collector-key bastion-key.pem`,
+		en: `Receives, over mTLS, the results sent by opsmaster runs on several
+hosts (e.g., one bastion per VPC) and merges them into a single report.
+
+Examples:
+  opsmaster collector serve --listen :8443 --tls-cert collector.pem --tls-key collector-key.pem --client-ca ca.pem
+  opsmaster install puppet ... --collector-url https://collector.internal:8443 --collector-cert bastion.pem --collector-key bastion-key.pem`,
+	},
+	// cmd/collector/serve.go
+	{
+		ptBR: "Recebe resultados de execuções remotas e consolida um relatório",
+		en:   "Receives the results of remote runs and merges them into a report",
+	},
+	{
+		ptBR: `Inicia o coletor central. Execuções de install com --collector-url enviam o
+resultado de cada instância assim que ela termina e o relatório completo (com
+as tags) ao final; o coletor mantém um relatório por run ID e grava a
+consolidação de todos em --output a cada atualização, no formato do --report.
+
+A conexão exige mTLS: apenas clientes com certificado assinado por --client-ca
+são aceitos. Todas as execuções devem instalar o mesmo pacote. O relatório
+consolidado também pode ser consultado em GET /v1/report.
+
+Exemplos:
+  opsmaster collector serve --listen :8443 --tls-cert collector.pem --tls-key collector-key.pem --client-ca ca.pem
+  opsmaster collector serve --tls-cert collector.pem --tls-key collector-key.pem --client-ca ca.pem --output rollout.json`,
+		en: `Starts the central collector. install runs with --collector-url send the
+result of each instance as soon as it finishes and the full report (with
+the tags) at the end; the collector keeps one report per run ID and writes
+the merge of all of them to --output on every update, in the --report format.
+
+The connection requires mTLS: only clients with a certificate signed by
+--client-ca are accepted. All runs must install the same package. The merged
+report can also be fetched from GET /v1/report.
+
+Examples:
+  opsmaster collector serve --listen :8443 --tls-cert collector.pem --tls-key collector-key.pem --client-ca ca.pem
+  opsmaster collector serve --tls-cert collector.pem --tls-key collector-key.pem --client-ca ca.pem --output rollout.json`,
+	},
+	{
+		ptBR: "Endereço em que o coletor escuta",
+		en:   "Address the collector listens on",
+	},
+	{
+		ptBR: "Certificado do coletor (PEM)",
+		en:   "Collector certificate (PEM)",
+	},
+	{
+		ptBR: "Chave privada do certificado do coletor (PEM)",
+		en:   "Private key of the collector certificate (PEM)",
+	},
+	{
+		ptBR: "CA que assina os certificados cliente das execuções (PEM)",
+		en:   "CA that signs the client certificates of the runs (PEM)",
+	},
+	{
+		ptBR: "Arquivo do relatório consolidado (regravado periodicamente e ao receber relatórios finais)",
+		en:   "File of the merged report (rewritten periodically and when final reports arrive)",
+	},
+	// cmd/coverage/coverage.go
+	{
+		ptBR: "Mede a cobertura da frota em relação ao estado desejado",
+		en:   "Measures the fleet coverage against the desired state",
+	},
+	{
+		ptBR: `Compara o inventário (CSV) com o estado desejado da frota: a porcentagem de
+instâncias com as tags esperadas (ex: puppet=true, aplicada por "install
+puppet"), no total e por conta, região e ambiente (coluna environment do CSV).
+
+As tags são lidas do provedor (EC2 DescribeInstances). Instâncias não
+encontradas ou encerradas (terminated) contam como desconhecidas e ficam fora da
+porcentagem.
+
+Com --history, cada execução acrescenta um resumo a um arquivo JSON lines e
+mostra a variação em relação à medição anterior, para acompanhar a tendência.
+
+Exemplos:
+  opsmaster coverage --inventory fleet.csv --expect puppet=true
+
+  # Por conta e time, listando as instâncias sem cobertura
+  opsmaster coverage --inventory fleet.csv --expect puppet=true --by account,team --list-uncovered
+
+  # Medição diária com histórico (ex: em um cron)
+  opsmaster coverage --inventory s3://inventory/fleet.csv --expect puppet=true --history coverage.jsonl`,
+		en: `Compares the inventory (CSV) with the desired state of the fleet: the
+percentage of instances with the expected tags (e.g., puppet=true, applied by
+"install puppet"), overall and by account, region and environment (CSV column
+environment).
+
+Tags are read from the provider (EC2 DescribeInstances). Instances not found
+or terminated count as unknown and are left out of the percentage.
+
+With --history, each run appends a summary to a JSON lines file and shows the
+change since the previous measurement, to follow the trend.
+
+Examples:
+  opsmaster coverage --inventory fleet.csv --expect puppet=true
+
+  # By account and team, listing the uncovered instances
+  opsmaster coverage --inventory fleet.csv --expect puppet=true --by account,team --list-uncovered
+
+  # Daily measurement with history (e.g., in a cron)
+  opsmaster coverage --inventory s3://inventory/fleet.csv --expect puppet=true --history coverage.jsonl`,
+	},
+	{
+		ptBR: "Arquivo CSV com a frota desejada: caminho local, https:// ou s3:// (aceita .gz) (obrigatório)",
+		en:   "CSV file with the desired fleet: local path, https:// or s3:// (accepts .gz) (required)",
+	},
+	{
+		ptBR: "Tag esperada nas instâncias (ex: puppet=true); pode ser repetida, todas devem casar (obrigatório)",
+		en:   "Tag expected on the instances (e.g., puppet=true); can be repeated, all must match (required)",
+	},
+	{
+		ptBR: "Quebras do relatório: account, region ou colunas do CSV",
+		en:   "Report breakdowns: account, region or CSV columns",
+	},
+	{
+		ptBR: "Arquivo JSON lines que acumula as medições, para acompanhar a tendência (opcional)",
+		en:   "JSON lines file accumulating the measurements, to follow the trend (optional)",
+	},
+	{
+		ptBR: "Lista as instâncias sem cobertura e as desconhecidas",
+		en:   "Lists the uncovered and unknown instances",
+	},
+	{
+		ptBR: "Termina com erro se a cobertura ficar abaixo desta porcentagem (0 = nunca)",
+		en:   "Exits with an error if the coverage is below this percentage (0 = never)",
+	},
+	// cmd/ctl/actions.go
+	{
+		ptBR: "Pausa o envio de novas instâncias",
+		en:   "Pauses the dispatch of new instances",
+	},
+	{
+		ptBR: `Pausa o envio de novas instâncias da execução. Instâncias já em execução
+terminam normalmente; as da fila aguardam "opsmaster ctl resume".
+
+Exemplos:
+  opsmaster ctl pause`,
+		en: `Pauses the dispatch of new instances of the run. Instances already running
+finish normally; queued ones wait for "opsmaster ctl resume".
+
+Examples:
+  opsmaster ctl pause`,
+	},
+	{
+		ptBR: "Retoma o envio de instâncias de uma execução pausada",
+		en:   "Resumes the dispatch of instances of a paused run",
+	},
+	{
+		ptBR: `Retoma o envio de instâncias de uma execução pausada com "opsmaster ctl pause".
+
+Exemplos:
+  opsmaster ctl resume`,
+		en: `Resumes the dispatch of instances of a run paused with "opsmaster ctl pause".
+
+Examples:
+  opsmaster ctl resume`,
+	},
+	{
+		ptBR: "Aborta a fila: instâncias não iniciadas são canceladas",
+		en:   "Aborts the queue: instances not started are canceled",
+	},
+	{
+		ptBR: `Aborta a execução: as instâncias da fila são canceladas (CANCELLED no
+relatório) e as já em execução terminam normalmente. O comando de instalação
+então encerra com o resumo e o relatório de sempre.
+
+Exemplos:
+  opsmaster ctl abort --run-id 7c9e6679-7425-40de-944b-e07fc1f90ae7`,
+		en: `Aborts the run: queued instances are canceled (CANCELLED in the report)
+and the ones already running finish normally. The install command then ends
+with the usual summary and report.
+
+Examples:
+  opsmaster ctl abort --run-id 7c9e6679-7425-40de-944b-e07fc1f90ae7`,
+	},
+	// cmd/ctl/ctl.go
+	{
+		ptBR: "Controla uma execução em andamento (pausar, retomar, abortar)",
+		en:   "Controls a run in progress (pause, resume, abort)",
+	},
+	{
+		ptBR: `Controla uma execução de "opsmaster install" em andamento pelo socket de
+controle local, sem matar o processo: pausa o envio de novas instâncias
+durante um incidente, retoma depois ou aborta a fila. Instâncias já em
+execução sempre terminam.
+
+Sem --run-id, usa a única execução em andamento do usuário atual.
+
+Exemplos:
+  opsmaster ctl status
+  opsmaster ctl pause
+  opsmaster ctl resume --run-id 7c9e6679-7425-40de-944b-e07fc1f90ae7`,
+		en: `Controls an "opsmaster install" run in progress through the local control
+socket, without killing the process: pauses the dispatch of new instances
+during an incident, resumes it later or aborts the queue. Instances already
+running always finish.
+
+Without --run-id, uses the only run in progress of the current user.
+
+Examples:
+  opsmaster ctl status
+  opsmaster ctl pause
+  opsmaster ctl resume --run-id 7c9e6679-7425-40de-944b-e07fc1f90ae7`,
+	},
+	{
+		ptBR: "Run ID da execução (padrão: a única execução em andamento)",
+		en:   "Run ID of the run (default: the only run in progress)",
+	},
+	{
+		ptBR: "Caminho do socket de controle (sobrepõe --run-id)",
+		en:   "Path of the control socket (overrides --run-id)",
+	},
+	// cmd/ctl/status.go
+	{
+		ptBR: "Mostra o estado e o progresso de uma execução em andamento",
+		en:   "Shows the state and progress of a run in progress",
+	},
+	{
+		ptBR: `Mostra o estado (starting, running, paused, aborting) e o progresso de uma
+execução em andamento: instâncias na fila, em execução e concluídas.
+
+Exemplos:
+  opsmaster ctl status
+  opsmaster ctl status -o json`,
+		en: `Shows the state (starting, running, paused, aborting) and progress of a run
+in progress: queued, running and completed instances.
+
+Examples:
+  opsmaster ctl status
+  opsmaster ctl status -o json`,
+	},
+	// cmd/ec2/discover.go
+	{
+		ptBR: "Gera o inventário CSV das instâncias EC2 com as tags informadas",
+		en:   "Writes the CSV inventory of the EC2 instances with the given tags",
+	},
+	{
+		ptBR: `Procura instâncias EC2 pelas tags (--discover-tags) e grava um inventário CSV
+(instance_id, account, region, cloud, name e as colunas de --tag-columns), aceito por
+--instances-file nos demais comandos.
+
+Por padrão procura apenas na conta do perfil AWS. Com --organization, lista as contas
+ativas do AWS Organizations (ou de uma OU e suas OUs filhas, com --organizational-unit),
+assume a role --org-role em cada conta e procura em todas as regiões de --regions,
+gerando um único inventário: não é preciso manter a lista de contas à mão. O perfil
+precisa ser da conta de gerenciamento ou de um administrador delegado.
+
+Contas ou regiões que falham (ex: role não assumível) são listadas e o comando termina
+com erro sem gravar o inventário, a menos que --allow-partial seja informado.
+
+Exemplos:
+  opsmaster ec2 discover --discover-tags env=prod --output-file prod.csv
+
+  # Toda a organização, em duas regiões, com perfis AWS por conta
+  opsmaster ec2 discover --discover-tags puppet=true --organization \
+    --regions us-east-1,sa-east-1 --profile-format "org-{account}" --output-file fleet.csv
+
+  # Uma OU, com a coluna team para usar em --where
+  opsmaster ec2 discover --discover-tags env=prod --organizational-unit ou-ab12-34cd5678 \
+    --tag-columns team --output-file prod.csv`,
+		en: `Searches EC2 instances by tags (--discover-tags) and writes a CSV inventory
+(instance_id, account, region, cloud, name and the --tag-columns columns), accepted by
+--instances-file in the other commands.
+
+By default it searches only the AWS profile account. With --organization, it lists the
+active accounts of AWS Organizations (or of an OU and its child OUs, with
+--organizational-unit), assumes the --org-role role in each account and searches every
+region of --regions, producing a single inventory: no need to maintain account lists by
+hand. The profile must belong to the management account or a delegated administrator.
+
+Accounts or regions that fail (e.g., role not assumable) are listed and the command
+exits with an error without writing the inventory, unless --allow-partial is set.
+
+Examples:
+  opsmaster ec2 discover --discover-tags env=prod --output-file prod.csv
+
+  # The whole organization, in two regions, with AWS profiles per account
+  opsmaster ec2 discover --discover-tags puppet=true --organization \
+    --regions us-east-1,sa-east-1 --profile-format "org-{account}" --output-file fleet.csv
+
+  # One OU, with the team column to use in --where
+  opsmaster ec2 discover --discover-tags env=prod --organizational-unit ou-ab12-34cd5678 \
+    --tag-columns team --output-file prod.csv`,
+	},
+	{
+		ptBR: "Tag que as instâncias devem ter: chave=valor (aceita * e ?) ou só a chave (qualquer valor); pode ser repetida, a mesma chave aceita qualquer dos valores (obrigatório)",
+		en:   "Tag the instances must have: key=value (accepts * and ?) or just the key (any value); can be repeated, the same key accepts any of its values (required)",
+	},
+	{
+		ptBR: "Regiões procuradas em cada conta (padrão: região do perfil AWS)",
+		en:   "Regions searched in each account (default: AWS profile region)",
+	},
+	{
+		ptBR: "Procura em todas as contas ativas do AWS Organizations, assumindo --org-role em cada uma",
+		en:   "Searches every active account of AWS Organizations, assuming --org-role in each one",
+	},
+	{
+		ptBR: "Restringe a organização às contas de uma OU e suas OUs filhas (ex: ou-ab12-34cd5678; implica --organization)",
+		en:   "Restricts the organization to the accounts of an OU and its child OUs (e.g., ou-ab12-34cd5678; implies --organization)",
+	},
+	{
+		ptBR: "Role assumida nas contas membro (a conta do perfil usa as próprias credenciais)",
+		en:   "Role assumed in member accounts (the profile account uses its own credentials)",
+	},
+	{
+		ptBR: "Tags copiadas para colunas do inventário (ex: team,environment)",
+		en:   "Tags copied to inventory columns (e.g., team,environment)",
+	},
+	{
+		ptBR: "Preenche a coluna aws_profile com o perfil de cada conta, substituindo {account} pelo ID (ex: org-{account}); sem ela, os comandos usam o ID da conta como perfil",
+		en:   "Fills the aws_profile column with the profile of each account, replacing {account} with the ID (e.g., org-{account}); without it, commands use the account ID as the profile",
+	},
+	{
+		ptBR: "Arquivo CSV do inventário (padrão: saída padrão)",
+		en:   "Inventory CSV file (default: standard output)",
+	},
+	{
+		ptBR: "Grava o inventário mesmo se contas ou regiões falharem (listadas no log)",
+		en:   "Writes the inventory even if accounts or regions fail (listed in the log)",
+	},
+	// cmd/ec2/ec2.go
+	{
+		ptBR: "Operações em instâncias EC2",
+		en:   "Operations on EC2 instances",
+	},
+	{
+		ptBR: `Operações em lote sobre instâncias EC2 listadas em arquivo CSV, e geração do
+inventário CSV por tags (discover).
+
+Útil para ligar instâncias antes de um rollout de agentes (ex: install puppet)
+e desligá-las ao final, sem scripts separados.
+
+Exemplos:
+  # Iniciar instâncias e aguardar estado running
+  opsmaster ec2 start --instances-file fleet.csv --wait
+
+  # Parar instâncias
+  opsmaster ec2 stop --instances-file fleet.csv
+
+  # Inventário das instâncias com a tag env=prod em todas as contas da organização
+  opsmaster ec2 discover --discover-tags env=prod --organization --output-file prod.csv`,
+		en: `Batch operations on EC2 instances listed in a CSV file, and generation of
+the CSV inventory by tags (discover).
+
+Useful to start instances before an agent rollout (e.g., install puppet)
+and stop them at the end, without separate scripts.
+
+Examples:
+  # Start instances and wait for the running state
+  opsmaster ec2 start --instances-file fleet.csv --wait
+
+  # Stop instances
+  opsmaster ec2 stop --instances-file fleet.csv
+
+  # Inventory of the instances tagged env=prod in every account of the organization
+  opsmaster ec2 discover --discover-tags env=prod --organization --output-file prod.csv`,
+	},
+	// cmd/ec2/power.go
+	{
+		ptBR: "Inicia instâncias EC2 listadas no CSV",
+		en:   "Starts the EC2 instances listed in the CSV",
+	},
+	{
+		ptBR: `Inicia as instâncias EC2 listadas no arquivo CSV.
+
+Instâncias já em execução, terminadas ou em modo manutenção são ignoradas. Com --wait, aguarda
+até que todas as instâncias atinjam o estado running (ou o timeout expire).
+
+Exemplos:
+  opsmaster ec2 start --instances-file fleet.csv --wait
+  opsmaster ec2 start --instances-file fleet.csv --wait --wait-timeout 10m`,
+		en: `Starts the EC2 instances listed in the CSV file.
+
+Instances already running, terminated or in maintenance mode are skipped. With --wait, waits
+until all instances reach the running state (or the timeout expires).
+
+Examples:
+  opsmaster ec2 start --instances-file fleet.csv --wait
+  opsmaster ec2 start --instances-file fleet.csv --wait --wait-timeout 10m`,
+	},
+	{
+		ptBR: "Para instâncias EC2 listadas no CSV",
+		en:   "Stops the EC2 instances listed in the CSV",
+	},
+	{
+		ptBR: `Para as instâncias EC2 listadas no arquivo CSV.
+
+Instâncias já paradas, terminadas ou em modo manutenção são ignoradas. Com --wait, aguarda
+até que todas as instâncias atinjam o estado stopped (ou o timeout expire).
+
+Exemplos:
+  opsmaster ec2 stop --instances-file fleet.csv
+  opsmaster ec2 stop --instances-file fleet.csv --wait`,
+		en: `Stops the EC2 instances listed in the CSV file.
+
+Instances already stopped, terminated or in maintenance mode are skipped. With --wait, waits
+until all instances reach the stopped state (or the timeout expires).
+
+Examples:
+  opsmaster ec2 stop --instances-file fleet.csv
+  opsmaster ec2 stop --instances-file fleet.csv --wait`,
+	},
+	{
+		ptBR: "Arquivo CSV com lista de instâncias: caminho local, https:// ou s3:// (aceita .gz) (obrigatório)",
+		en:   "CSV file with the list of instances: local path, https:// or s3:// (accepts .gz) (required)",
+	},
+	{
+		ptBR: "Perfil AWS a usar (padrão: perfil default)",
+		en:   "AWS profile to use (default: default profile)",
+	},
+	{
+		ptBR: "Aguardar as instâncias atingirem o estado final",
+		en:   "Wait for the instances to reach the final state",
+	},
+	{
+		ptBR: "Tempo máximo de espera com --wait",
+		en:   "Maximum wait time with --wait",
+	},
+	{
+		ptBR: "Processar também instâncias em modo manutenção",
+		en:   "Also process instances in maintenance mode",
+	},
+	{
+		ptBR: "Tag que marca instâncias em modo manutenção (valor true)",
+		en:   "Tag that marks instances in maintenance mode (value true)",
+	},
+	{
+		ptBR: "Seleciona instâncias por coluna do CSV (ex: environment=blue); pode ser repetida",
+		en:   "Selects instances by CSV column (e.g., environment=blue); can be repeated",
+	},
+	// cmd/facts/facts.go
+	{
+		ptBR: "Consulta facts (Facter) na frota",
+		en:   "Queries facts (Facter) across the fleet",
+	},
+	{
+		ptBR: `Consulta facts do Facter diretamente nas instâncias listadas em arquivo CSV,
+para responder perguntas de inventário sem acessar o Puppetboard ou cada máquina.
+
+Exemplos:
+  # Comparar versão do SO e ambiente entre as instâncias
+  opsmaster facts get --fact os.release.full,location.environment --instances-file fleet.csv`,
+		en: `Queries Facter facts directly on the instances listed in a CSV file,
+to answer inventory questions without opening Puppetboard or each machine.
+
+Examples:
+  # Compare the OS version and environment across the instances
+  opsmaster facts get --fact os.release.full,location.environment --instances-file fleet.csv`,
+	},
+	// cmd/facts/get.go
+	{
+		ptBR: "Extrai facts das instâncias e compara os valores",
+		en:   "Extracts facts from the instances and compares the values",
+	},
+	{
+		ptBR: `Executa "facter --json" em cada instância (via SSM), extrai os facts pedidos e
+mostra uma tabela comparativa (uma coluna por fact) ou JSON.
+
+Os facts usam a notação de pontos do Facter: hashes por chave e arrays por índice
+(ex: os.release.full, processors.models.0). Facts estruturados aparecem como JSON.
+Apenas os facts de primeiro nível pedidos são coletados, o que mantém a saída dentro
+do limite do SSM. O Facter do Puppet (/opt/puppetlabs/bin/facter) é preferido, pois
+também carrega os facts externos (ex: location.yaml criado pelo install puppet).
+
+Exemplos:
+  opsmaster facts get --fact os.release.full,location.environment --instances-file fleet.csv
+
+  # JSON para processar com jq
+  opsmaster facts get --fact kernelrelease --instances-file fleet.csv -o json | jq '.[].facts'`,
+		en: `Runs "facter --json" on each instance (via SSM), extracts the requested facts and
+shows a comparison table (one column per fact) or JSON.
+
+Facts use Facter's dot notation: hashes by key and arrays by index
+(e.g., os.release.full, processors.models.0). Structured facts are shown as JSON.
+Only the requested top-level facts are collected, which keeps the output within
+the SSM limit. Puppet's Facter (/opt/puppetlabs/bin/facter) is preferred, since it
+also loads external facts (e.g., the location.yaml created by install puppet).
+
+Examples:
+  opsmaster facts get --fact os.release.full,location.environment --instances-file fleet.csv
+
+  # JSON to process with jq
+  opsmaster facts get --fact kernelrelease --instances-file fleet.csv -o json | jq '.[].facts'`,
+	},
+	{
+		ptBR: "Facts a extrair, separados por vírgula (ex: os.release.full,location.environment) (obrigatório)",
+		en:   "Facts to extract, comma-separated (e.g., os.release.full,location.environment) (required)",
+	},
+	{
+		ptBR: "Máximo de consultas em paralelo",
+		en:   "Maximum parallel queries",
+	},
+	{
+		ptBR: "Tempo máximo do facter em cada instância",
+		en:   "Maximum time of facter on each instance",
+	},
+	{
+		ptBR: "Formato de saída (table|json)",
+		en:   "Output format (table|json)",
+	},
+	// cmd/installers/installers.go
+	{
+		ptBR: "Mostra os instaladores disponíveis e o que suportam",
+		en:   "Shows the available installers and what they support",
+	},
+	{
+		ptBR: `Mostra os instaladores registrados (os subcomandos de "opsmaster install"),
+os sistemas e arquiteturas que suportam, as flags obrigatórias e as
+capacidades opcionais, sem precisar ler o código.
+
+Exemplos:
+  # Matriz de suporte completa
+  opsmaster installers list --verbose`,
+		en: `Shows the registered installers (the "opsmaster install" subcommands),
+the systems and architectures they support, the required flags and the
+optional capabilities, without reading the code.
+
+Examples:
+  # Full support matrix
+  opsmaster installers list --verbose`,
+	},
+	// cmd/installers/list.go
+	{
+		ptBR: "Lista os instaladores registrados",
+		en:   "Lists the registered installers",
+	},
+	{
+		ptBR: `Lista os instaladores registrados com os sistemas (debian, rhel, windows) e
+arquiteturas suportados. Com --verbose, mostra também as flags obrigatórias do
+subcomando "install" e as capacidades opcionais:
+
+  local-install       conduz a instalação por conta própria (recuperação, várias chamadas)
+  auto-detect         detecta o sistema da instância antes de gerar o script
+  step-based          instala em etapas; falhas apontam a etapa que quebrou
+  verifies-facts      verifica facts/configuração após a instalação
+  concurrency-groups  limite de concorrência por backend (ex: CA do Puppet Server)
+  post-install        notifica sistemas externos após a instalação (ex: ENC/CMDB)
+  diagnostics         logs incluídos no diagnóstico das instâncias com falha
+
+Exemplos:
+  opsmaster installers list --verbose
+
+  # JSON para processar com jq
+  opsmaster installers list -o json | jq '.[] | select(.capabilities | index("local-install")) | .name'`,
+		en: `Lists the registered installers with the supported systems (debian, rhel,
+windows) and architectures. With --verbose, also shows the required flags of the
+"install" subcommand and the optional capabilities:
+
+  local-install       drives the installation itself (recovery, multiple calls)
+  auto-detect         detects the instance OS before generating the script
+  step-based          installs in steps; failures point to the step that broke
+  verifies-facts      checks facts/configuration after the installation
+  concurrency-groups  concurrency limit per backend (e.g., Puppet Server CA)
+  post-install        notifies external systems after the installation (e.g., ENC/CMDB)
+  diagnostics         logs included in the diagnostics of failed instances
+
+Examples:
+  opsmaster installers list --verbose
+
+  # JSON to process with jq
+  opsmaster installers list -o json | jq '.[] | select(.capabilities | index("local-install")) | .name'`,
+	},
+	{
+		ptBR: "Mostra também as flags obrigatórias e as capacidades de cada instalador",
+		en:   "Also shows the required flags and capabilities of each installer",
+	},
+	// cmd/get/dns.go
+	{
+		ptBR: "Busca registros DNS de um domínio (similar a 'dig')",
+		en:   "Looks up the DNS records of a domain (similar to 'dig')",
+	},
+	{
+		ptBR: "Realiza uma consulta DNS para encontrar registros de um tipo específico (A, AAAA, MX, TXT, etc.) associados a um nome de domínio.",
+		en:   "Runs a DNS query to find the records of a given type (A, AAAA, MX, TXT, etc.) associated with a domain name.",
+	},
+	{
+		ptBR: "Tipo de registro DNS a consultar (ex: A, AAAA, MX, TXT, CNAME, NS)",
+		en:   "DNS record type to query (e.g., A, AAAA, MX, TXT, CNAME, NS)",
+	},
+	// cmd/get/get.go
+	{
+		ptBR: "Busca e exibe diferentes tipos de recursos",
+		en:   "Fetches and shows different kinds of resources",
+	},
+	{
+		ptBR: "O comando 'get' é um agrupador para subcomandos que buscam e exibem informações de vários recursos, como IP público, registros DNS, etc.",
+		en:   "The 'get' command groups subcommands that fetch and show information about several resources, such as public IP, DNS records, etc.",
+	},
+	// cmd/get/ip.go
+	{
+		ptBR: "Busca e exibe seus endereços de IP (público e/ou local)",
+		en:   "Fetches and shows your IP addresses (public and/or local)",
+	},
+	{
+		ptBR: "Busca seu endereço de IP público na internet e/ou seu endereço de IP local na rede.",
+		en:   "Fetches your public IP address on the internet and/or your local IP address on the network.",
+	},
+	{
+		ptBR: "Exibe apenas o endereço de IP local",
+		en:   "Shows only the local IP address",
+	},
+	{
+		ptBR: "Exibe apenas o endereço de IP público",
+		en:   "Shows only the public IP address",
+	},
+	// cmd/history/history.go
+	{
+		ptBR: "Gerencia os artefatos de execuções anteriores (relatórios e históricos)",
+		en:   "Manages the artifacts of previous runs (reports and histories)",
+	},
+	{
+		ptBR: `Gerencia os artefatos que as execuções deixam no disco: os relatórios JSON de
+install ... --report e os históricos de coverage --history. Os diretórios,
+arquivos e limites de retenção ficam na seção history do arquivo de
+configuração.
+
+Exemplos:
+  opsmaster history prune --keep 90d --max-size 1GB
+
+  # Mostrar o que seria removido, sem remover
+  opsmaster history prune --dry-run`,
+		en: `Manages the artifacts runs leave on disk: the JSON reports of install ...
+--report and the histories of coverage --history. The directories, files and
+retention limits are set in the history section of the config file.
+
+Examples:
+  opsmaster history prune --keep 90d --max-size 1GB
+
+  # Show what would be removed, without removing
+  opsmaster history prune --dry-run`,
+	},
+	// cmd/history/prune.go
+	{
+		ptBR: "Remove relatórios e pontos de histórico antigos",
+		en:   "Removes old reports and history points",
+	},
+	{
+		ptBR: `Remove os artefatos antigos dos diretórios de relatórios (history.report_dirs
+ou --dir) e dos arquivos de histórico de cobertura (history.files ou --file):
+primeiro os mais antigos que --keep, depois os mais antigos até que cada
+diretório ou arquivo caiba em --max-size. O artefato mais recente nunca é
+removido por tamanho.
+
+Nos diretórios, só são considerados relatórios de execução (arquivos .json
+gravados por --report); relatórios de execuções em andamento (com o arquivo
+<relatório>.lock) são mantidos. Nos históricos, os pontos são removidos pelo
+campo generated_at e o arquivo é regravado.
+
+  history:
+    report_dirs: [/var/lib/opsmaster/reports]
+    files: [/var/lib/opsmaster/coverage.jsonl]
+    keep: 90d
+    max_size: 1GB
+    auto_prune: true   # poda após cada install --report e coverage --history
+
+Exemplos:
+  opsmaster history prune --keep 90d --max-size 1GB
+
+  # Mostrar o que seria removido, sem remover
+  opsmaster history prune --dir /var/lib/opsmaster/reports --keep 30d --dry-run`,
+		en: `Removes the old artifacts of the report directories (history.report_dirs or
+--dir) and of the coverage history files (history.files or --file): first
+those older than --keep, then the oldest until each directory or file fits
+--max-size. The newest artifact is never removed for size.
+
+In directories, only run reports (.json files written by --report) are
+considered; reports of runs in progress (with the <report>.lock file) are
+kept. In histories, points are removed by their generated_at field and the
+file is rewritten.
+
+  history:
+    report_dirs: [/var/lib/opsmaster/reports]
+    files: [/var/lib/opsmaster/coverage.jsonl]
+    keep: 90d
+    max_size: 1GB
+    auto_prune: true   # prune after each install --report and coverage --history
+
+Examples:
+  opsmaster history prune --keep 90d --max-size 1GB
+
+  # Show what would be removed, without removing
+  opsmaster history prune --dir /var/lib/opsmaster/reports --keep 30d --dry-run`,
+	},
+	{
+		ptBR: "Remove artefatos mais antigos que esta idade (ex: 90d, 2w, 36h) (padrão: history.keep)",
+		en:   "Removes artifacts older than this age (e.g., 90d, 2w, 36h) (default: history.keep)",
+	},
+	{
+		ptBR: "Tamanho máximo de cada diretório ou arquivo (ex: 1GB, 500MB) (padrão: history.max_size)",
+		en:   "Maximum size of each directory or file (e.g., 1GB, 500MB) (default: history.max_size)",
+	},
+	{
+		ptBR: "Diretório de relatórios de execução (repetível) (padrão: history.report_dirs)",
+		en:   "Run report directory (repeatable) (default: history.report_dirs)",
+	},
+	{
+		ptBR: "Arquivo de histórico de cobertura (repetível) (padrão: history.files)",
+		en:   "Coverage history file (repeatable) (default: history.files)",
+	},
+	{
+		ptBR: "Mostra o que seria removido, sem remover",
+		en:   "Shows what would be removed, without removing",
+	},
+	// cmd/install/fluentbit.go
+	{
+		ptBR: "Instala o Fluent Bit (coletor de logs) em instâncias na nuvem",
+		en:   "Installs Fluent Bit (log collector) on cloud instances",
+	},
+	{
+		ptBR: `Instala e configura o Fluent Bit em múltiplas instâncias na nuvem em paralelo.
+
+O Fluent Bit é instalado a partir dos repositórios oficiais (packages.fluentbit.io)
+em Debian/Ubuntu e RHEL/Amazon Linux. A configuração de saída é gerada a partir
+das flags e dos metadados do CSV, validada com --dry-run na instância e o serviço
+é habilitado e reiniciado.
+
+Saídas suportadas (--output):
+  es          Elasticsearch/OpenSearch (--host, --port, --index)
+  loki        Grafana Loki (--host, --port, --labels)
+  cloudwatch  CloudWatch Logs (--log-group, --region)
+
+--host, --index, --labels, --log-group e --region aceitam templates Go com
+.InstanceID, .Account, .Region e .Metadata.<coluna do CSV>.
+
+Para configurações avançadas, --config-file envia um fluent-bit.conf completo
+sem alterações (as flags de saída são ignoradas).
+
+Exemplos:
+  # Enviar logs para o Elasticsearch, um índice por ambiente
+  opsmaster install fluent-bit --instances-file instances.csv \
+    --output es --host es.internal --index 'logs-{{ .Metadata.environment }}'
+
+  # Enviar logs para o Loki com TLS
+  opsmaster install fluent-bit --instances-file instances.csv \
+    --output loki --host loki.example.com --port 443 --tls --labels 'job=varlogs,env={{ .Metadata.environment }}'
+
+  # Enviar logs para o CloudWatch Logs, um grupo por conta
+  opsmaster install fluent-bit --instances-file instances.csv \
+    --output cloudwatch --log-group '/opsmaster/{{ .Account }}'
+
+  # Usar uma configuração própria
+  opsmaster install fluent-bit --instances-file instances.csv --config-file fluent-bit.conf`,
+		en: `Installs and configures Fluent Bit on multiple cloud instances in parallel.
+
+Fluent Bit is installed from the official repositories (packages.fluentbit.io)
+on Debian/Ubuntu and RHEL/Amazon Linux. The output configuration is generated from
+the flags and the CSV metadata, validated with --dry-run on the instance and the
+service is enabled and restarted.
+
+Supported outputs (--output):
+  es          Elasticsearch/OpenSearch (--host, --port, --index)
+  loki        Grafana Loki (--host, --port, --labels)
+  cloudwatch  CloudWatch Logs (--log-group, --region)
+
+--host, --index, --labels, --log-group and --region accept Go templates with
+.InstanceID, .Account, .Region and .Metadata.<CSV column>.
+
+For advanced configurations, --config-file sends a complete fluent-bit.conf
+unchanged (the output flags are ignored).
+
+Examples:
+  # Send logs to Elasticsearch, one index per environment
+  opsmaster install fluent-bit --instances-file instances.csv \
+    --output es --host es.internal --index 'logs-{{ .Metadata.environment }}'
+
+  # Send logs to Loki over TLS
+  opsmaster install fluent-bit --instances-file instances.csv \
+    --output loki --host loki.example.com --port 443 --tls --labels 'job=varlogs,env={{ .Metadata.environment }}'
+
+  # Send logs to CloudWatch Logs, one group per account
+  opsmaster install fluent-bit --instances-file instances.csv \
+    --output cloudwatch --log-group '/opsmaster/{{ .Account }}'
+
+  # Use your own configuration
+  opsmaster install fluent-bit --instances-file instances.csv --config-file fluent-bit.conf`,
+	},
+	{
+		ptBR: "Saída dos logs: es, loki ou cloudwatch (obrigatório sem --config-file)",
+		en:   "Log output: es, loki or cloudwatch (required without --config-file)",
+	},
+	{
+		ptBR: "Host do Elasticsearch/Loki (aceita template)",
+		en:   "Elasticsearch/Loki host (accepts a template)",
+	},
+	{
+		ptBR: "Porta do Elasticsearch/Loki (padrão: 9200 para es, 3100 para loki)",
+		en:   "Elasticsearch/Loki port (default: 9200 for es, 3100 for loki)",
+	},
+	{
+		ptBR: "Usar TLS na conexão com Elasticsearch/Loki",
+		en:   "Use TLS to connect to Elasticsearch/Loki",
+	},
+	{
+		ptBR: "Índice do Elasticsearch (padrão: fluent-bit; aceita template)",
+		en:   "Elasticsearch index (default: fluent-bit; accepts a template)",
+	},
+	{
+		ptBR: "Labels do Loki no formato chave=valor,chave=valor (padrão: job=fluent-bit; aceita template)",
+		en:   "Loki labels as key=value,key=value (default: job=fluent-bit; accepts a template)",
+	},
+	{
+		ptBR: "Grupo do CloudWatch Logs (obrigatório para cloudwatch; aceita template)",
+		en:   "CloudWatch Logs group (required for cloudwatch; accepts a template)",
+	},
+	{
+		ptBR: "Região do CloudWatch Logs (padrão: região da instância; aceita template)",
+		en:   "CloudWatch Logs region (default: the instance region; accepts a template)",
+	},
+	{
+		ptBR: "Arquivos de log lidos (padrão: /var/log/syslog,/var/log/messages)",
+		en:   "Log files read (default: /var/log/syslog,/var/log/messages)",
+	},
+	{
+		ptBR: "Colunas do CSV adicionadas como campos em todos os registros (ex: environment,team)",
+		en:   "CSV columns added as fields to every record (e.g., environment,team)",
+	},
+	{
+		ptBR: "fluent-bit.conf completo enviado sem alterações (ignora as flags de saída)",
+		en:   "Complete fluent-bit.conf sent unchanged (ignores the output flags)",
+	},
+	// cmd/install/install.go
+	{
+		ptBR: "Instala pacotes em instâncias na nuvem",
+		en:   "Installs packages on cloud instances",
+	},
+	{
+		ptBR: `Instala pacotes (Puppet, Fluent Bit, osquery, Teleport, etc) em múltiplas instâncias na nuvem em paralelo.
+
+Suporta múltiplos provedores de nuvem (AWS, Azure, GCP) e pacotes.
+Utiliza execução remota (SSM para AWS) para instalar e configurar pacotes.
+
+Exemplos:
+  # Instalar Puppet em instâncias a partir de arquivo CSV
+  opsmaster install puppet --instances-file instances.csv --puppet-server puppet.example.com
+
+  # Instalar com concorrência customizada
+  opsmaster install puppet --instances-file instances.csv --puppet-server puppet.example.com --max-concurrency 20
+
+  # Modo dry run (simular sem executar)
+  opsmaster install puppet --instances-file instances.csv --puppet-server puppet.example.com --dry-run
+
+  # Instalar Fluent Bit enviando logs para o Loki
+  opsmaster install fluent-bit --instances-file instances.csv --output loki --host loki.example.com
+
+  # Instalar osquery registrando as instâncias no Fleet
+  opsmaster install osquery --instances-file instances.csv --fleet-url https://fleet.example.com --enroll-secret vault:secret/data/fleet#enroll_secret`,
+		en: `Installs packages (Puppet, Fluent Bit, osquery, Teleport, etc) on multiple cloud instances in parallel.
+
+Supports multiple cloud providers (AWS, Azure, GCP) and packages.
+Uses remote execution (SSM for AWS) to install and configure packages.
+
+Examples:
+  # Install Puppet on instances from a CSV file
+  opsmaster install puppet --instances-file instances.csv --puppet-server puppet.example.com
+
+  # Install with custom concurrency
+  opsmaster install puppet --instances-file instances.csv --puppet-server puppet.example.com --max-concurrency 20
+
+  # Dry run mode (simulate without running)
+  opsmaster install puppet --instances-file instances.csv --puppet-server puppet.example.com --dry-run
+
+  # Install Fluent Bit sending logs to Loki
+  opsmaster install fluent-bit --instances-file instances.csv --output loki --host loki.example.com
+
+  # Install osquery enrolling the instances in Fleet
+  opsmaster install osquery --instances-file instances.csv --fleet-url https://fleet.example.com --enroll-secret vault:secret/data/fleet#enroll_secret`,
+	},
+	// cmd/install/osquery.go
+	{
+		ptBR: "Instala o osquery e registra as instâncias no Fleet",
+		en:   "Installs osquery and enrolls the instances in Fleet",
+	},
+	{
+		ptBR: `Instala o osquery a partir dos repositórios oficiais (pkg.osquery.io) e registra
+as instâncias em um servidor Fleet (ou qualquer servidor TLS do osquery).
+
+O gerenciador de pacotes (apt ou yum) é detectado na instância. O segredo de
+registro é gravado em /etc/osquery/enroll_secret (modo 600) e nunca aparece no
+texto do script: --enroll-secret é enviado como variável de ambiente (aceita
+referência vault:caminho#campo) e --enroll-secret-parameter é resolvido pelo
+próprio SSM a partir do Parameter Store, sem aparecer no histórico de comandos.
+
+A verificação confirma que o osqueryd está ativo e que o registro no Fleet não
+falhou. Instâncias instaladas recebem a tag osquery=true.
+
+Exemplos:
+  # Segredo de registro vindo do Vault
+  opsmaster install osquery --instances-file instances.csv \
+    --fleet-url https://fleet.example.com --enroll-secret vault:secret/data/fleet#enroll_secret
+
+  # Segredo no SSM Parameter Store e CA própria
+  opsmaster install osquery --instances-file instances.csv \
+    --fleet-url fleet.internal:8412 --enroll-secret-parameter /fleet/enroll-secret --fleet-ca-file fleet-ca.pem`,
+		en: `Installs osquery from the official repositories (pkg.osquery.io) and enrolls
+the instances in a Fleet server (or any osquery TLS server).
+
+The package manager (apt or yum) is detected on the instance. The enroll
+secret is written to /etc/osquery/enroll_secret (mode 600) and never shows in
+the script text: --enroll-secret is sent as an environment variable (accepts
+a vault:path#field reference) and --enroll-secret-parameter is resolved by
+SSM itself from Parameter Store, without showing in the command history.
+
+Verification confirms that osqueryd is active and that the Fleet enrollment
+didn't fail. Installed instances get the osquery=true tag.
+
+Examples:
+  # Enroll secret from Vault
+  opsmaster install osquery --instances-file instances.csv \
+    --fleet-url https://fleet.example.com --enroll-secret vault:secret/data/fleet#enroll_secret
+
+  # Secret in SSM Parameter Store and a custom CA
+  opsmaster install osquery --instances-file instances.csv \
+    --fleet-url fleet.internal:8412 --enroll-secret-parameter /fleet/enroll-secret --fleet-ca-file fleet-ca.pem`,
+	},
+	{
+		ptBR: "Servidor Fleet/TLS do osquery: host[:porta] ou URL https:// (obrigatório)",
+		en:   "osquery Fleet/TLS server: host[:port] or https:// URL (required)",
+	},
+	{
+		ptBR: "Segredo de registro no Fleet; aceita referência vault:caminho#campo",
+		en:   "Fleet enroll secret; accepts a vault:path#field reference",
+	},
+	{
+		ptBR: "Parâmetro do SSM Parameter Store com o segredo de registro (alternativa a --enroll-secret)",
+		en:   "SSM Parameter Store parameter with the enroll secret (alternative to --enroll-secret)",
+	},
+	{
+		ptBR: "Arquivo PEM com a CA do servidor Fleet (padrão: CAs do sistema)",
+		en:   "PEM file with the CA of the Fleet server (default: system CAs)",
+	},
+	{
+		ptBR: "Identificador do host no Fleet: instance, uuid ou hostname",
+		en:   "Host identifier in Fleet: instance, uuid or hostname",
+	},
+	// cmd/install/package.go
+	{
+		ptBR: "Máximo de instalações paralelas",
+		en:   "Maximum parallel installations",
+	},
+	{
+		ptBR: "Reprocessa instâncias com falha até N vezes na mesma execução, depois de todas as primeiras tentativas (0 desativa)",
+		en:   "Reprocesses failed instances up to N times in the same run, after all first attempts (0 disables)",
+	},
+	{
+		ptBR: "Reprocessa no fim da execução, até N vezes, instâncias com falhas transitórias (throttling do SSM, agente offline) (0 desativa)",
+		en:   "Reprocesses at the end of the run, up to N times, instances with transient failures (SSM throttling, agent offline) (0 disables)",
+	},
+	{
+		ptBR: "Intervalo dos logs de acompanhamento das instâncias em execução, com tempo decorrido, fase atual e ID do comando SSM (0 desativa)",
+		en:   "Interval of the progress logs of running instances, with elapsed time, current phase and SSM command ID (0 disables)",
+	},
+	{
+		ptBR: "Duração esperada por fase (ex: install=30m,verify=5m); fases mais longas são reportadas como travadas (padrão: validate=5m, install=20m, verify=10m, tag=2m, post-install=5m)",
+		en:   "Expected duration per phase (e.g., install=30m,verify=5m); longer phases are reported as stuck (default: validate=5m, install=20m, verify=10m, tag=2m, post-install=5m)",
+	},
+	{
+		ptBR: "Ordem de despacho das instâncias: as-is (ordem do CSV), sorted (por conta, região e ID) ou shuffle (aleatória, distribui a carga entre contas e regiões)",
+		en:   "Dispatch order of the instances: as-is (CSV order), sorted (by account, region and ID) or shuffle (random, spreads the load across accounts and regions)",
+	},
+	{
+		ptBR: "Semente da ordem shuffle, para repetir a mesma ordem de uma execução anterior (0 = nova semente, registrada no log)",
+		en:   "Seed of the shuffle order, to repeat the order of an earlier run (0 = new seed, logged)",
+	},
+	{
+		ptBR: "Expressão que decide o status final de cada instância instalada ou com falha (ex: metadata.certname_preserved == \"true\" || phase_durations.install < 300s); verdadeira = sucesso, falsa = falha",
+		en:   "Expression deciding the final status of each installed or failed instance (e.g., metadata.certname_preserved == \"true\" || phase_durations.install < 300s); true = success, false = failure",
+	},
+	{
+		ptBR: "Simular instalação sem executar",
+		en:   "Simulate the installation without running it",
+	},
+	{
+		ptBR: "Pular validação de pré-requisitos (não recomendado)",
+		en:   "Skip prerequisite validation (not recommended)",
+	},
+	{
+		ptBR: "Aguarda até o tempo informado o registro da instância no SSM (agente Online) antes da validação, para instâncias recém-criadas (ex: 10m; 0 falha na hora)",
+		en:   "Wait up to this long for the instance to register in SSM (agent Online) before validation, for freshly launched instances (e.g., 10m; 0 fails at once)",
+	},
+	{
+		ptBR: "Não aplicar tags nas instâncias (aplique depois com 'opsmaster tags apply --from-report')",
+		en:   "Don't tag the instances (apply later with 'opsmaster tags apply --from-report')",
+	},
+	{
+		ptBR: "Grava o resultado da execução em JSON (instâncias, status e tags) no arquivo informado",
+		en:   "Writes the run result as JSON (instances, status and tags) to the given file",
+	},
+	{
+		ptBR: "Assume o lock do arquivo de --report mesmo se outra execução do opsmaster parecer ativa (use apenas se ela já terminou)",
+		en:   "Takes over the lock of the --report file even if another opsmaster run seems active (use only if it already finished)",
+	},
+	{
+		ptBR: "Relatório (--report) de um dry-run recente: instâncias validadas com sucesso não são validadas novamente",
+		en:   "Report (--report) of a recent dry-run: instances validated successfully are not validated again",
+	},
+	{
+		ptBR: "Idade máxima das validações reaproveitadas com --reuse-preflight",
+		en:   "Maximum age of the validations reused with --reuse-preflight",
+	},
+	{
+		ptBR: "Máximo de bytes de stdout e stderr guardados no contexto de erro dos scripts com falha, no relatório (--report) (0 sem limite)",
+		en:   "Maximum stdout and stderr bytes kept in the error context of failed scripts, in the report (--report) (0 no limit)",
+	},
+	{
+		ptBR: "Quantidade de instâncias mais lentas listadas no resumo, com o tempo de cada fase (0 desativa)",
+		en:   "Number of slowest instances listed in the summary, with the time of each phase (0 disables)",
+	},
+	{
+		ptBR: "Iniciar instâncias paradas antes da instalação (padrão: pular)",
+		en:   "Start stopped instances before the installation (default: skip)",
+	},
+	{
+		ptBR: "Ignora linhas inválidas do CSV (listadas no resumo) em vez de abortar",
+		en:   "Skips invalid CSV rows (listed in the summary) instead of aborting",
+	},
+	{
+		ptBR: "Instâncias de autoscaling groups sendo terminadas ou substituídas: off (processa normalmente), skip (ignora com o motivo) ou replace (processa a instância substituta)",
+		en:   "Autoscaling group instances being terminated or replaced: off (processed as usual), skip (skipped with the reason) or replace (the replacement instance is processed)",
+	},
+	{
+		ptBR: "Não coletar o pacote de diagnóstico (df -h, free -m e fim dos logs do agente e do apt/yum) das instâncias que falham na instalação ou verificação",
+		en:   "Don't collect the diagnostic bundle (df -h, free -m and the tail of the agent and apt/yum logs) from instances failing to install or verify",
+	},
+	{
+		ptBR: "Falhar a execução (código de saída) também quando instâncias desaparecem no meio dela (ex.: terminadas pelo autoscaling)",
+		en:   "Also fail the run (exit code) when instances disappear mid-run (e.g., terminated by autoscaling)",
+	},
+	{
+		ptBR: "Seleciona instâncias por coluna do CSV (ex: environment=blue, shard>=3, hostname~^web-); pode ser repetida",
+		en:   "Selects instances by CSV column (e.g., environment=blue, shard>=3, hostname~^web-); can be repeated",
+	},
+	{
+		ptBR: "Processa apenas instâncias da família de SO informada (debian, rhel, windows ou distribuição como ubuntu, amzn), pela coluna os do CSV ou metadados da instância",
+		en:   "Processes only instances of the given OS family (debian, rhel, windows or a distribution such as ubuntu, amzn), from the CSV os column or the instance metadata",
+	},
+	{
+		ptBR: "Detecta o SO na instância mesmo com a coluna os do CSV preenchida (inventário desatualizado)",
+		en:   "Detects the OS on the instance even when the CSV os column is filled in (outdated inventory)",
+	},
+	{
+		ptBR: "Prefixo que identifica os comandos do opsmaster no histórico do SSM (comentário e primeira linha; \"-\" desativa a linha marcadora)",
+		en:   "Prefix that identifies opsmaster commands in the SSM history (comment and first line; \"-\" disables the marker line)",
+	},
+	{
+		ptBR: "Tabela DynamoDB que recebe o estado mais recente de cada instância (opcional)",
+		en:   "DynamoDB table that receives the latest state of each instance (optional)",
+	},
+	{
+		ptBR: "Região da tabela DynamoDB (padrão: região do perfil AWS)",
+		en:   "Region of the DynamoDB table (default: region of the AWS profile)",
+	},
+	{
+		ptBR: "Cria a tabela DynamoDB (on-demand, chave instance_id) se não existir",
+		en:   "Creates the DynamoDB table (on-demand, key instance_id) if it doesn't exist",
+	},
+	{
+		ptBR: "ARN de tópico SNS ou barramento EventBridge que recebe um evento ao término de cada instância (opcional)",
+		en:   "ARN of an SNS topic or EventBridge bus that receives an event when each instance finishes (optional)",
+	},
+	{
+		ptBR: "Ignorar cache de metadados das instâncias e consultar a API novamente",
+		en:   "Ignore the instance metadata cache and query the API again",
+	},
+	// cmd/install/puppet.go
+	{
+		ptBR: "Proxy HTTP usado pelo agente para alcançar o Puppet Server e o Forge (http_proxy_host no puppet.conf; hostname ou IP, sem esquema)",
+		en:   "HTTP proxy the agent uses to reach the Puppet Server and the Forge (http_proxy_host in puppet.conf; hostname or IP, no scheme)",
+	},
+	{
+		ptBR: "Porta do proxy HTTP do agente (http_proxy_port; padrão 3128)",
+		en:   "Port of the agent HTTP proxy (http_proxy_port; default 3128)",
+	},
+	{
+		ptBR: "Hosts acessados sem o proxy (no_proxy; *.dominio ou .dominio para um domínio inteiro; pode ser repetida)",
+		en:   "Hosts reached without the proxy (no_proxy; *.domain or .domain for a whole domain; repeatable)",
+	},
+	{
+		ptBR: "Instala Puppet Agent em instâncias na nuvem",
+		en:   "Installs Puppet Agent on cloud instances",
+	},
+	{
+		ptBR: `Instala e configura Puppet Agent em múltiplas instâncias na nuvem em paralelo.
+
+Lê lista de instâncias de arquivo CSV, valida pré-requisitos, instala Puppet Agent,
+configura puppet.conf com certname único e cria tags nas instâncias após instalação bem-sucedida.
+
+Formato CSV:
+  Formato básico (obrigatório):
+    instance_id,account,region,environment
+    i-0123456789abcdef0,111111111111,us-east-1,production
+    i-fedcba9876543210,111111111111,us-west-2,staging
+
+  Formato com AWS Profile (para SSO):
+    instance_id,account,region,environment,aws_profile
+    i-0123456789abcdef0,111111111111,us-east-1,production,aws-staging-applications
+    i-fedcba9876543210,111111111111,us-west-2,staging,aws-staging-applications
+
+O arquivo CSV deve ter cabeçalhos: instance_id, account, region
+Colunas opcionais:
+  - cloud (padrão aws)
+  - environment
+  - aws_profile (para autenticação SSO)
+  - quaisquer colunas extras são armazenadas como metadados
+
+Autenticação AWS:
+  O OpsMaster suporta três métodos de autenticação (em ordem de prioridade):
+  1. Flag --aws-profile (maior prioridade)
+  2. Coluna aws_profile no CSV
+  3. Account ID como profile (compatibilidade com versões anteriores)
+
+  Para usar SSO, configure profiles em ~/.aws/config e use:
+    - Flag: --aws-profile nome-do-profile
+    - CSV: coluna aws_profile com nome do profile por instância
+
+Custom Facter Facts:
+  Por padrão, o OpsMaster cria automaticamente um arquivo location.yaml em
+  /opt/puppetlabs/facter/facts.d/ com os seguintes campos do CSV:
+    - account
+    - environment
+    - region
+
+  Para customizar os facts criados, use --custom-facts com arquivo YAML:
+
+  Exemplo custom-facts.yaml:
+    location:
+      file_path: "location.yaml"
+      fact_name: "location"
+      fields:
+        account: "account"
+        environment: "environment"
+        region: "region"
+    compliance:
+      file_path: "compliance.yaml"
+      fact_name: "compliance"
+      fields:
+        compliance_level: "compliance"
+        data_classification: "classification"
+
+  Os arquivos gravados ficam listados em facts.d/.opsmaster-manifest; com
+  --prune-facts, os listados por execuções anteriores e não declarados mais
+  (ex: arquivo renomeado) são removidos.
+
+Exemplos:
+  # Instalação básica (cria location.yaml automaticamente)
+  opsmaster install puppet \
+    --instances-file instances.csv \
+    --puppet-server puppet.example.com
+
+  # Com SSO usando flag
+  opsmaster install puppet \
+    --instances-file instances.csv \
+    --puppet-server puppet.example.com \
+    --aws-profile aws-staging-applications
+
+  # Com SSO usando CSV (csv deve ter coluna aws_profile)
+  opsmaster install puppet \
+    --instances-file instances-with-profiles.csv \
+    --puppet-server puppet.example.com
+
+  # Com custom facts personalizados
+  opsmaster install puppet \
+    --instances-file instances.csv \
+    --puppet-server puppet.example.com \
+    --custom-facts custom-facts.yaml
+
+  # Com configurações customizadas
+  opsmaster install puppet \
+    --instances-file instances.csv \
+    --puppet-server puppet.example.com \
+    --puppet-port 8140 \
+    --puppet-version 7 \
+    --environment production \
+    --max-concurrency 20
+
+  # Agente instalado com serviço desabilitado (execuções via cron)
+  opsmaster install puppet \
+    --instances-file instances.csv \
+    --puppet-server puppet.example.com \
+    --enable-service=false \
+    --service-state stopped
+
+  # Espelhos internos (repositórios re-assinados)
+  opsmaster install puppet \
+    --instances-file instances.csv \
+    --puppet-server puppet.example.com \
+    --repo-apt-url https://mirror.example.com/puppet-apt \
+    --repo-yum-url https://mirror.example.com/puppet-yum \
+    --repo-gpg-key-url https://mirror.example.com/keys/puppet.asc \
+    --repo-gpg-fingerprint "D681 1ED3 ADEE B844 1AF5 AA8F 4528 B6CD 9E61 EF26"
+
+  # Dry run (simular)
+  opsmaster install puppet \
+    --instances-file instances.csv \
+    --puppet-server puppet.example.com \
+    --dry-run`,
+		en: `Installs and configures Puppet Agent on multiple cloud instances in parallel.
+
+Reads the list of instances from a CSV file, validates prerequisites, installs Puppet Agent,
+configures puppet.conf with a unique certname and tags the instances after a successful installation.
+
+CSV format:
+  Basic format (required):
+    instance_id,account,region,environment
+    i-0123456789abcdef0,111111111111,us-east-1,production
+    i-fedcba9876543210,111111111111,us-west-2,staging
+
+  Format with AWS Profile (for SSO):
+    instance_id,account,region,environment,aws_profile
+    i-0123456789abcdef0,111111111111,us-east-1,production,aws-staging-applications
+    i-fedcba9876543210,111111111111,us-west-2,staging,aws-staging-applications
+
+The CSV file must have the headers: instance_id, account, region
+Optional columns:
+  - cloud (default aws)
+  - environment
+  - aws_profile (for SSO authentication)
+  - any extra columns are stored as metadata
+
+AWS authentication:
+  OpsMaster supports three authentication methods (in order of priority):
+  1. --aws-profile flag (highest priority)
+  2. aws_profile column in the CSV
+  3. Account ID as the profile (backward compatibility)
+
+  To use SSO, configure profiles in ~/.aws/config and use:
+    - Flag: --aws-profile profile-name
+    - CSV: aws_profile column with the profile name per instance
+
+Custom Facter Facts:
+  By default, OpsMaster automatically creates a location.yaml file in
+  /opt/puppetlabs/facter/facts.d/ with the following CSV fields:
+    - account
+    - environment
+    - region
+
+  To customize the created facts, use --custom-facts with a YAML file:
+
+  Example custom-facts.yaml:
+    location:
+      file_path: "location.yaml"
+      fact_name: "location"
+      fields:
+        account: "account"
+        environment: "environment"
+        region: "region"
+    compliance:
+      file_path: "compliance.yaml"
+      fact_name: "compliance"
+      fields:
+        compliance_level: "compliance"
+        data_classification: "classification"
+
+  The written files are listed in facts.d/.opsmaster-manifest; with
+  --prune-facts, those listed by earlier runs and no longer declared
+  (e.g., a renamed file) are removed.
+
+Examples:
+  # Basic installation (creates location.yaml automatically)
+  opsmaster install puppet \
+    --instances-file instances.csv \
+    --puppet-server puppet.example.com
+
+  # SSO with the flag
+  opsmaster install puppet \
+    --instances-file instances.csv \
+    --puppet-server puppet.example.com \
+    --aws-profile aws-staging-applications
+
+  # SSO with the CSV (the csv must have the aws_profile column)
+  opsmaster install puppet \
+    --instances-file instances-with-profiles.csv \
+    --puppet-server puppet.example.com
+
+  # With custom facts
+  opsmaster install puppet \
+    --instances-file instances.csv \
+    --puppet-server puppet.example.com \
+    --custom-facts custom-facts.yaml
+
+  # With custom settings
+  opsmaster install puppet \
+    --instances-file instances.csv \
+    --puppet-server puppet.example.com \
+    --puppet-port 8140 \
+    --puppet-version 7 \
+    --environment production \
+    --max-concurrency 20
+
+  # Agent installed with the service disabled (runs via cron)
+  opsmaster install puppet \
+    --instances-file instances.csv \
+    --puppet-server puppet.example.com \
+    --enable-service=false \
+    --service-state stopped
+
+  # Internal mirrors (re-signed repositories)
+  opsmaster install puppet \
+    --instances-file instances.csv \
+    --puppet-server puppet.example.com \
+    --repo-apt-url https://mirror.example.com/puppet-apt \
+    --repo-yum-url https://mirror.example.com/puppet-yum \
+    --repo-gpg-key-url https://mirror.example.com/keys/puppet.asc \
+    --repo-gpg-fingerprint "D681 1ED3 ADEE B844 1AF5 AA8F 4528 B6CD 9E61 EF26"
+
+  # Dry run (simulate)
+  opsmaster install puppet \
+    --instances-file instances.csv \
+    --puppet-server puppet.example.com \
+    --dry-run`,
+	},
+	{
+		ptBR: "Hostname do Puppet Server (obrigatório)",
+		en:   "Puppet Server hostname (required)",
+	},
+	{
+		ptBR: "Porta do Puppet Server",
+		en:   "Puppet Server port",
+	},
+	{
+		ptBR: "Versão do Puppet a instalar (7 ou 8)",
+		en:   "Puppet version to install (7 or 8)",
+	},
+	{
+		ptBR: "Ambiente Puppet",
+		en:   "Puppet environment",
+	},
+	{
+		ptBR: "Arquivo YAML com definições de custom facts (opcional)",
+		en:   "YAML file with custom fact definitions (optional)",
+	},
+	{
+		ptBR: "Arquivo YAML que mapeia colunas do CSV para extension_requests (pp_role, pp_environment...) do csr_attributes.yaml, gerando trusted facts (opcional)",
+		en:   "YAML file mapping CSV columns to extension_requests (pp_role, pp_environment...) of csr_attributes.yaml, producing trusted facts (optional)",
+	},
+	{
+		ptBR: "Arquivo YAML que mapeia colunas do CSV para chaves do hiera gravadas em um arquivo de dados local do nó (padrão: /etc/hiera-node.yaml) (opcional)",
+		en:   "YAML file mapping CSV columns to hiera keys written to a node-local data file (default: /etc/hiera-node.yaml) (optional)",
+	},
+	{
+		ptBR: "Remove de facts.d os arquivos de facts gravados pelo opsmaster em execuções anteriores e não declarados mais (lista em .opsmaster-manifest)",
+		en:   "Remove from facts.d the fact files written by opsmaster in earlier runs and no longer declared (listed in .opsmaster-manifest)",
+	},
+	{
+		ptBR: "Máximo de instalações paralelas por Puppet Server (0 = sem limite)",
+		en:   "Maximum parallel installations per Puppet Server (0 = no limit)",
+	},
+	{
+		ptBR: "Espera aleatória (0 até o valor, máx 20m) na instância antes da primeira execução do puppet agent (ex: 10m)",
+		en:   "Random wait (0 up to the value, max 20m) on the instance before the first puppet agent run (e.g., 10m)",
+	},
+	{
+		ptBR: "Como tratar um agente travado (agent_catalog_run.lock antigo) antes da primeira execução: wait (aguarda a liberação), clear (encerra o agente e remove o lock) ou fail (falha a instância)",
+		en:   "How to handle a stuck agent (old agent_catalog_run.lock) before the first run: wait (wait for the release), clear (stop the agent and remove the lock) or fail (fail the instance)",
+	},
+	{
+		ptBR: "Idade a partir da qual o lock do agente é considerado travado (mín 1m)",
+		en:   "Age from which the agent lock is considered stuck (min 1m)",
+	},
+	{
+		ptBR: "Tempo máximo de espera pela liberação do lock do agente (máx 20m)",
+		en:   "Max wait for the agent lock to be released (max 20m)",
+	},
+	{
+		ptBR: "Atraso aleatório (0 até o valor) antes de cada instalação, para distribuir a carga no Puppet Server (ex: 30s)",
+		en:   "Random delay (0 up to the value) before each installation, to spread the load on the Puppet Server (e.g., 30s)",
+	},
+	{
+		ptBR: "Janela em que a verificação pós-instalação é repetida com backoff antes de falhar, enquanto o agente conclui a primeira execução (ex: 5m; 0 desativa)",
+		en:   "Window in which the post-install verification is retried with backoff before failing, while the agent finishes its first run (e.g., 5m; 0 disables)",
+	},
+	{
+		ptBR: "Habilitar serviço puppet no boot (false para execuções via cron)",
+		en:   "Enable the puppet service at boot (false for runs via cron)",
+	},
+	{
+		ptBR: "Estado do serviço puppet após instalação (running|stopped)",
+		en:   "State of the puppet service after installation (running|stopped)",
+	},
+	{
+		ptBR: "Diretório de trabalho nas instâncias para arquivos temporários (padrão: /tmp, ou /var/lib/opsmaster se /tmp for noexec)",
+		en:   "Working directory on the instances for temporary files (default: /tmp, or /var/lib/opsmaster if /tmp is noexec)",
+	},
+	{
+		ptBR: "URL do ENC/CMDB para registrar o nó após a instalação (POST; opcional)",
+		en:   "ENC/CMDB URL to register the node after installation (POST; optional)",
+	},
+	{
+		ptBR: "Arquivo com template Go do payload enviado ao ENC (padrão: JSON com certname, ambiente e metadados do CSV)",
+		en:   "File with the Go template of the payload sent to the ENC (default: JSON with certname, environment and CSV metadata)",
+	},
+	{
+		ptBR: "Token Bearer para o ENC (aceita referência vault:caminho#campo)",
+		en:   "Bearer token for the ENC (accepts a vault:path#field reference)",
+	},
+	{
+		ptBR: "URL do Foreman para criar/atualizar o host após a instalação (opcional; ou foreman.url no ~/.opsmaster.yaml)",
+		en:   "Foreman URL to create/update the host after installation (optional; or foreman.url in ~/.opsmaster.yaml)",
+	},
+	{
+		ptBR: "Usuário da API do Foreman (ou foreman.username no ~/.opsmaster.yaml)",
+		en:   "Foreman API user (or foreman.username in ~/.opsmaster.yaml)",
+	},
+	{
+		ptBR: "Senha/token da API do Foreman; aceita referência vault:caminho#campo (ou foreman.password no ~/.opsmaster.yaml)",
+		en:   "Foreman API password/token; accepts a vault:path#field reference (or foreman.password in ~/.opsmaster.yaml)",
+	},
+	{
+		ptBR: "Organização do host no Foreman (opcional)",
+		en:   "Organization of the host in Foreman (optional)",
+	},
+	{
+		ptBR: "Localização do host no Foreman (opcional)",
+		en:   "Location of the host in Foreman (optional)",
+	},
+	{
+		ptBR: "Coluna do CSV com o hostgroup do Foreman",
+		en:   "CSV column with the Foreman hostgroup",
+	},
+	{
+		ptBR: "Injeta falhas/latência aleatórias em modo dry-run (ex: fail-rate=10%,latency=5s; requer OPSMASTER_CHAOS=1)",
+		en:   "Injects random failures/latency in dry-run mode (e.g., fail-rate=10%,latency=5s; requires OPSMASTER_CHAOS=1)",
+	},
+	{
+		ptBR: "Origem do pacote Puppet: puppetlabs (puppet-agent de apt/yum.puppet.com ou espelhos) ou distro (pacote puppet dos repositórios da distribuição, binário em /usr/bin/puppet)",
+		en:   "Source of the Puppet package: puppetlabs (puppet-agent from apt/yum.puppet.com or mirrors) or distro (puppet package from the distribution repositories, binary in /usr/bin/puppet)",
+	},
+	{
+		ptBR: "URL base de um espelho interno do apt.puppet.com (ex: https://mirror.example.com/puppet-apt; padrão: repositório oficial)",
+		en:   "Base URL of an internal mirror of apt.puppet.com (e.g., https://mirror.example.com/puppet-apt; default: official repository)",
+	},
+	{
+		ptBR: "URL base de um espelho interno do yum.puppet.com (ex: https://mirror.example.com/puppet-yum; padrão: repositório oficial)",
+		en:   "Base URL of an internal mirror of yum.puppet.com (e.g., https://mirror.example.com/puppet-yum; default: official repository)",
+	},
+	{
+		ptBR: "URL da chave GPG que assina os espelhos internos (obrigatória com --repo-apt-url/--repo-yum-url)",
+		en:   "URL of the GPG key that signs the internal mirrors (required with --repo-apt-url/--repo-yum-url)",
+	},
+	{
+		ptBR: "Fingerprint esperado da chave GPG dos espelhos, verificado na instância antes de confiar na chave (opcional)",
+		en:   "Expected fingerprint of the mirrors' GPG key, checked on the instance before trusting the key (optional)",
+	},
+	{
+		ptBR: "Opções de segurança dos scripts de instalação: errexit, nounset, pipefail, errtrap (separadas por vírgula), strict (todas) ou none",
+		en:   "Safety options of the install scripts: errexit, nounset, pipefail, errtrap (comma-separated), strict (all) or none",
+	},
+	{
+		ptBR: "Não verificar assinaturas dos espelhos internos (desaconselhado; apenas espelhos air-gapped sem chave)",
+		en:   "Don't verify the signatures of the internal mirrors (discouraged; only air-gapped mirrors without a key)",
+	},
+	{
+		ptBR: "Fingerprint da chave GPG da Puppet, Inc. aceita nos pacotes puppet<N>-release oficiais, verificados antes da instalação (padrão: chave de release atual; pode ser repetida)",
+		en:   "Fingerprint of the Puppet, Inc. GPG key accepted for the official puppet<N>-release packages, verified before installing them (default: current release key; repeatable)",
+	},
+	{
+		ptBR: "URL da chave GPG importada para verificar a assinatura do pacote .rpm de release oficial (padrão: chave de release da Puppet em yum.puppet.com)",
+		en:   "URL of the GPG key imported to verify the signature of the official .rpm release package (default: Puppet release key at yum.puppet.com)",
+	},
+	{
+		ptBR: "Máximo de tentativas das operações",
+		en:   "Maximum retry attempts for operations",
+	},
+	{
+		ptBR: "Espera base entre as tentativas",
+		en:   "Base delay between retries",
+	},
+	{
+		ptBR: "Adiciona variação aleatória (jitter) às esperas entre tentativas",
+		en:   "Add random jitter to retry delays",
+	},
+	{
+		ptBR: "Máximo de tentativas das operações do SSM (0 = usa --max-retries)",
+		en:   "Max retries for SSM operations (0 = use --max-retries)",
+	},
+	{
+		ptBR: "Máximo de tentativas das operações do EC2 (0 = usa --max-retries)",
+		en:   "Max retries for EC2 operations (0 = use --max-retries)",
+	},
+	{
+		ptBR: "URL https do coletor central (opsmaster collector serve) que recebe os resultados de cada instância durante a execução (opcional)",
+		en:   "https URL of the central collector (opsmaster collector serve) that receives the results of each instance during the run (optional)",
+	},
+	{
+		ptBR: "Certificado cliente (mTLS) para o coletor",
+		en:   "Client certificate (mTLS) for the collector",
+	},
+	{
+		ptBR: "Chave privada do certificado cliente (mTLS) para o coletor",
+		en:   "Private key of the client certificate (mTLS) for the collector",
+	},
+	{
+		ptBR: "CA do certificado do coletor (padrão: CAs do sistema)",
+		en:   "CA of the collector certificate (default: system CAs)",
+	},
+	{
+		ptBR: "Expõe a execução em um socket local para pausar, retomar ou abortar com opsmaster ctl",
+		en:   "Exposes the run on a local socket to pause, resume or abort it with opsmaster ctl",
+	},
+	// cmd/install/systemd.go
+	{
+		ptBR: "Implanta um binário e uma unit do systemd em instâncias na nuvem",
+		en:   "Deploys a binary and a systemd unit on cloud instances",
+	},
+	{
+		ptBR: `Implanta pequenos agentes internos sem empacotamento: baixa o binário (opcional),
+grava a unit em /etc/systemd/system, recarrega o systemd, habilita e reinicia a
+unit e verifica que ela continua ativa.
+
+O binário pode vir de uma URL https:// ou s3://. Para s3://, o opsmaster gera
+localmente, para cada instância, uma URL pré-assinada (válida por --binary-url-ttl)
+com o perfil AWS, então as instâncias só precisam de curl ou wget, sem credenciais
+de S3.
+
+Reexecutar o comando atualiza o binário e a unit e reinicia o serviço.
+
+Exemplos:
+  # Unit e binário no S3 com checksum
+  opsmaster install systemd-unit --instances-file instances.csv \
+    --unit-file ./my-agent.service \
+    --binary-url s3://artifacts/my-agent/1.4.0/my-agent \
+    --binary-sha256 3b1f...
+
+  # Apenas a unit (binário já presente nas instâncias)
+  opsmaster install systemd-unit --instances-file instances.csv --unit-file ./cleanup.service`,
+		en: `Deploys small internal agents without packaging: downloads the binary (optional),
+writes the unit to /etc/systemd/system, reloads systemd, enables and restarts the
+unit and checks that it stays active.
+
+The binary can come from an https:// or s3:// URL. For s3://, opsmaster generates
+a presigned URL locally for each instance (valid for --binary-url-ttl) with the
+AWS profile, so the instances only need curl or wget, without S3 credentials.
+
+Running the command again updates the binary and the unit and restarts the service.
+
+Examples:
+  # Unit and binary in S3 with a checksum
+  opsmaster install systemd-unit --instances-file instances.csv \
+    --unit-file ./my-agent.service \
+    --binary-url s3://artifacts/my-agent/1.4.0/my-agent \
+    --binary-sha256 3b1f...
+
+  # Only the unit (binary already on the instances)
+  opsmaster install systemd-unit --instances-file instances.csv --unit-file ./cleanup.service`,
+	},
+	{
+		ptBR: "Arquivo .service local enviado para /etc/systemd/system (obrigatório)",
+		en:   "Local .service file sent to /etc/systemd/system (required)",
+	},
+	{
+		ptBR: "URL https:// ou s3:// do binário (opcional)",
+		en:   "https:// or s3:// URL of the binary (optional)",
+	},
+	{
+		ptBR: "Caminho do binário na instância (padrão: /usr/local/bin/<nome do arquivo na URL>)",
+		en:   "Path of the binary on the instance (default: /usr/local/bin/<file name in the URL>)",
+	},
+	{
+		ptBR: "SHA-256 esperado do binário (recomendado)",
+		en:   "Expected SHA-256 of the binary (recommended)",
+	},
+	{
+		ptBR: "Validade da URL pré-assinada de binários s3://, gerada para cada instância antes do download",
+		en:   "Validity of the presigned URL of s3:// binaries, generated for each instance before the download",
+	},
+	// cmd/install/teleport.go
+	{
+		ptBR: "Instala o Teleport (nó SSH) em instâncias na nuvem",
+		en:   "Installs Teleport (SSH node) on cloud instances",
+	},
+	{
+		ptBR: `Instala o Teleport a partir dos repositórios oficiais e registra as instâncias
+como nós SSH do cluster, para substituir o acesso via bastion.
+
+O SO é detectado na instância (Debian/Ubuntu ou RHEL/Amazon Linux) e o pacote vem
+do canal estável da versão principal (--teleport-version). O /etc/teleport.yaml é
+gerado por instância (nome do nó a partir do CSV e labels com instance_id, conta,
+região e as colunas de --label-columns), validado com "teleport configure --test"
+e o serviço é habilitado e reiniciado.
+
+O token de registro é gravado em /etc/teleport/join-token (modo 600) e nunca
+aparece no texto do script: --join-token é enviado como variável de ambiente
+(aceita referência vault:caminho#campo) e --join-token-parameter é resolvido
+pelo próprio SSM a partir do Parameter Store.
+
+Exemplos:
+  # Registrar via proxy com token vindo do Vault
+  opsmaster install teleport --instances-file instances.csv \
+    --proxy-server teleport.example.com --join-token vault:secret/data/teleport#node_token
+
+  # Registrar direto no auth server com token no Parameter Store
+  opsmaster install teleport --instances-file instances.csv \
+    --auth-server auth.internal:3025 --ca-pin sha256:abc123... \
+    --join-token-parameter /teleport/node-token --label-columns environment,team`,
+		en: `Installs Teleport from the official repositories and registers the instances
+as SSH nodes of the cluster, to replace access through a bastion.
+
+The OS is detected on the instance (Debian/Ubuntu or RHEL/Amazon Linux) and the package comes
+from the stable channel of the major version (--teleport-version). /etc/teleport.yaml is
+generated per instance (node name from the CSV and labels with instance_id, account,
+region and the --label-columns columns), validated with "teleport configure --test"
+and the service is enabled and restarted.
+
+The join token is written to /etc/teleport/join-token (mode 600) and never
+shows in the script text: --join-token is sent as an environment variable
+(accepts a vault:path#field reference) and --join-token-parameter is resolved
+by SSM itself from Parameter Store.
+
+Examples:
+  # Join through the proxy with a token from Vault
+  opsmaster install teleport --instances-file instances.csv \
+    --proxy-server teleport.example.com --join-token vault:secret/data/teleport#node_token
+
+  # Join the auth server directly with a token in Parameter Store
+  opsmaster install teleport --instances-file instances.csv \
+    --auth-server auth.internal:3025 --ca-pin sha256:abc123... \
+    --join-token-parameter /teleport/node-token --label-columns environment,team`,
+	},
+	{
+		ptBR: "Proxy do Teleport: host[:porta] (porta padrão 443)",
+		en:   "Teleport proxy: host[:port] (default port 443)",
+	},
+	{
+		ptBR: "Auth server do Teleport: host[:porta] (porta padrão 3025; alternativa a --proxy-server)",
+		en:   "Teleport auth server: host[:port] (default port 3025; alternative to --proxy-server)",
+	},
+	{
+		ptBR: "Versão principal do Teleport (deve corresponder à versão do cluster)",
+		en:   "Teleport major version (must match the cluster version)",
+	},
+	{
+		ptBR: "Token de registro dos nós; aceita referência vault:caminho#campo",
+		en:   "Node join token; accepts a vault:path#field reference",
+	},
+	{
+		ptBR: "Parâmetro do SSM Parameter Store com o token de registro (alternativa a --join-token)",
+		en:   "SSM Parameter Store parameter with the join token (alternative to --join-token)",
+	},
+	{
+		ptBR: "CA pin do cluster (sha256:...), recomendado com --auth-server",
+		en:   "Cluster CA pin (sha256:...), recommended with --auth-server",
+	},
+	{
+		ptBR: "Coluna do CSV com o nome do nó (vazia = instance_id)",
+		en:   "CSV column with the node name (empty = instance_id)",
+	},
+	{
+		ptBR: "Colunas do CSV adicionadas como labels do nó (ex: environment,team)",
+		en:   "CSV columns added as node labels (e.g., environment,team)",
+	},
+	// cmd/logs/logs.go
+	{
+		ptBR: "Lê logs das instâncias da frota",
+		en:   "Reads logs from the fleet instances",
+	},
+	{
+		ptBR: `Lê arquivos de log das instâncias listadas em arquivo CSV (via SSM), útil para
+depurar um rollout que falhou em parte da frota.
+
+Exemplos:
+  # Últimas 50 linhas do log do agente Puppet em cada instância
+  opsmaster logs tail --path /var/log/puppetlabs/puppet/puppet.log --lines 50 --instances-file fleet.csv`,
+		en: `Reads log files from the instances listed in a CSV file (via SSM), useful to
+debug a rollout that failed on part of the fleet.
+
+Examples:
+  # Last 50 lines of the Puppet agent log on each instance
+  opsmaster logs tail --path /var/log/puppetlabs/puppet/puppet.log --lines 50 --instances-file fleet.csv`,
+	},
+	// cmd/logs/tail.go
+	{
+		ptBR: "Mostra as últimas linhas de um arquivo em cada instância",
+		en:   "Shows the last lines of a file on each instance",
+	},
+	{
+		ptBR: `Lê as últimas --lines linhas de um arquivo em todas as instâncias do CSV (via SSM)
+e as imprime agrupadas.
+
+Instâncias com saída idêntica são agrupadas em um único bloco (o cabeçalho lista as
+instâncias), o que destaca rapidamente quais máquinas falharam da mesma forma. Use
+--no-group para um bloco por instância ou --prefix para linhas prefixadas com o ID
+da instância (bom para grep). A saída por instância é limitada a 20 KB (limite do SSM).
+
+Exemplos:
+  opsmaster logs tail --path /var/log/puppetlabs/puppet/puppet.log --lines 50 --instances-file fleet.csv
+
+  # Só as instâncias do lote que falhou, linhas prefixadas
+  opsmaster logs tail --path /var/log/syslog --lines 200 --instances-file fleet.csv \
+    --where batch=3 --prefix | grep -i error`,
+		en: `Reads the last --lines lines of a file on all instances of the CSV (via SSM)
+and prints them grouped.
+
+Instances with identical output are grouped in a single block (the header lists the
+instances), which quickly shows which machines failed the same way. Use
+--no-group for one block per instance or --prefix for lines prefixed with the
+instance ID (good for grep). The output per instance is limited to 20 KB (SSM limit).
+
+Examples:
+  opsmaster logs tail --path /var/log/puppetlabs/puppet/puppet.log --lines 50 --instances-file fleet.csv
+
+  # Only the instances of the failed batch, prefixed lines
+  opsmaster logs tail --path /var/log/syslog --lines 200 --instances-file fleet.csv \
+    --where batch=3 --prefix | grep -i error`,
+	},
+	{
+		ptBR: "Caminho absoluto do arquivo nas instâncias (obrigatório)",
+		en:   "Absolute path of the file on the instances (required)",
+	},
+	{
+		ptBR: "Quantidade de linhas do final do arquivo",
+		en:   "Number of lines from the end of the file",
+	},
+	{
+		ptBR: "Não agrupar instâncias com saída idêntica",
+		en:   "Don't group instances with identical output",
+	},
+	{
+		ptBR: "Prefixar cada linha com o ID da instância em vez de imprimir blocos",
+		en:   "Prefix each line with the instance ID instead of printing blocks",
+	},
+	{
+		ptBR: "Máximo de leituras em paralelo",
+		en:   "Maximum parallel reads",
+	},
+	{
+		ptBR: "Tempo máximo da leitura em cada instância",
+		en:   "Maximum time of the read on each instance",
+	},
+	// cmd/nelm/install.go
+	{
+		ptBR: "Instala releases do nelm",
+		en:   "Installs nelm releases",
+	},
+	{
+		ptBR: `Executa um fluxo completo (chart lint, plan install, install) para instalar releases do nelm.
+
+Exemplos:
+  # Instalar todas as releases detectadas
+  opsmaster nelm install --env stg -x kubedev
+
+  # Instalar uma release específica
+  opsmaster nelm install -r sample-api --env stg -x kubedev
+
+  # Com namespace customizado
+  opsmaster nelm install -r sample-api --env stg -x kubedev -n default
+
+  # Com auto-approve
+  opsmaster nelm install -r sample-api --env stg -x kubedev --auto-approve
+
+  # Com timeout e concorrência personalizados
+  opsmaster nelm install -r sample-api --env stg -x kubedev --timeout 10m --max-concurrency 5`,
+		en: `Runs a complete flow (chart lint, plan install, install) to install nelm releases.
+
+Examples:
+  # Install all detected releases
+  opsmaster nelm install --env stg -x kubedev
+
+  # Install a specific release
+  opsmaster nelm install -r sample-api --env stg -x kubedev
+
+  # With a custom namespace
+  opsmaster nelm install -r sample-api --env stg -x kubedev -n default
+
+  # With auto-approve
+  opsmaster nelm install -r sample-api --env stg -x kubedev --auto-approve
+
+  # With custom timeout and concurrency
+  opsmaster nelm install -r sample-api --env stg -x kubedev --timeout 10m --max-concurrency 5`,
+	},
+	{
+		ptBR: "Nome da release específica a ser instalada (opcional)",
+		en:   "Name of the specific release to install (optional)",
+	},
+	{
+		ptBR: "Namespace onde a release será instalada (opcional - usa o nome da release se não fornecido)",
+		en:   "Namespace where the release will be installed (optional - uses the release name if not given)",
+	},
+	{
+		ptBR: "O ambiente de destino (ex: stg, prd) (obrigatório)",
+		en:   "The target environment (e.g., stg, prd) (required)",
+	},
+	{
+		ptBR: "Contexto do kubeconfig a ser usado (obrigatório)",
+		en:   "kubeconfig context to use (required)",
+	},
+	{
+		ptBR: "Pula a confirmação interativa",
+		en:   "Skips the interactive confirmation",
+	},
+	{
+		ptBR: "Timeout para a operação (ex: 10s, 1m, 1h)",
+		en:   "Timeout for the operation (e.g., 10s, 1m, 1h)",
+	},
+	{
+		ptBR: "Máximo de releases executadas em paralelo",
+		en:   "Maximum releases run in parallel",
+	},
+	// cmd/nelm/nelm.go
+	{
+		ptBR: "Executa comandos 'nelm' de forma inteligente",
+		en:   "Runs 'nelm' commands smartly",
+	},
+	{
+		ptBR: "Um conjunto de comandos para orquestrar a ferramenta 'nelm', simplificando a gestão de releases em ambientes de CI/CD.",
+		en:   "A set of commands to orchestrate the 'nelm' tool, simplifying release management in CI/CD environments.",
+	},
+	// cmd/nelm/rollback.go
+	{
+		ptBR: "Faz rollback de uma release do nelm",
+		en:   "Rolls back a nelm release",
+	},
+	{
+		ptBR: `Executa rollback de uma release para uma revisão anterior.
+
+Exemplos:
+  # Fazer rollback para a revisão anterior
+  opsmaster nelm rollback -r sample-api -x kubedev
+
+  # Fazer rollback para uma revisão específica
+  opsmaster nelm rollback -r sample-api -x kubedev --revision 2
+
+  # Com namespace customizado
+  opsmaster nelm rollback -r sample-api -x kubedev -n default --revision 3
+
+  # Com auto-approve
+  opsmaster nelm rollback -r sample-api -x kubedev --auto-approve
+
+  # Com timeout personalizado
+  opsmaster nelm rollback -r sample-api -x kubedev --timeout 10m`,
+		en: `Rolls back a release to a previous revision.
+
+Examples:
+  # Roll back to the previous revision
+  opsmaster nelm rollback -r sample-api -x kubedev
+
+  # Roll back to a specific revision
+  opsmaster nelm rollback -r sample-api -x kubedev --revision 2
+
+  # With a custom namespace
+  opsmaster nelm rollback -r sample-api -x kubedev -n default --revision 3
+
+  # With auto-approve
+  opsmaster nelm rollback -r sample-api -x kubedev --auto-approve
+
+  # With a custom timeout
+  opsmaster nelm rollback -r sample-api -x kubedev --timeout 10m`,
+	},
+	{
+		ptBR: "Nome da release para rollback (obrigatório)",
+		en:   "Name of the release to roll back (required)",
+	},
+	{
+		ptBR: "Número da revisão para rollback (0 = revisão anterior)",
+		en:   "Revision number to roll back to (0 = previous revision)",
+	},
+	{
+		ptBR: "Pular confirmação interativa",
+		en:   "Skip the interactive confirmation",
+	},
+	// cmd/nelm/status.go
+	{
+		ptBR: "Verifica o status de releases do nelm",
+		en:   "Checks the status of nelm releases",
+	},
+	{
+		ptBR: `Verifica o status de releases instaladas no nelm.
+
+Exemplos:
+  # Verificar status de uma release específica
+  opsmaster nelm status -r sample-api -x kubedev
+
+  # Com namespace customizado
+  opsmaster nelm status -r sample-api -x kubedev -n default
+
+  # Com timeout personalizado
+  opsmaster nelm status -r sample-api -x kubedev --timeout 10m`,
+		en: `Checks the status of releases installed with nelm.
+
+Examples:
+  # Check the status of a specific release
+  opsmaster nelm status -r sample-api -x kubedev
+
+  # With a custom namespace
+  opsmaster nelm status -r sample-api -x kubedev -n default
+
+  # With a custom timeout
+  opsmaster nelm status -r sample-api -x kubedev --timeout 10m`,
+	},
+	{
+		ptBR: "Nome da release específica a ser verificada (obrigatório)",
+		en:   "Name of the specific release to check (required)",
+	},
+	{
+		ptBR: "Namespace onde buscar releases (opcional)",
+		en:   "Namespace to look for releases in (optional)",
+	},
+	// cmd/nelm/uninstall.go
+	{
+		ptBR: "Desinstala uma release do nelm",
+		en:   "Uninstalls a nelm release",
+	},
+	{
+		ptBR: `Executa um fluxo completo para remover uma release do nelm.
+
+Exemplos:
+  # Desinstalar uma release específica
+  opsmaster nelm uninstall -r sample-api -x kubedev
+
+  # Com namespace customizado
+  opsmaster nelm uninstall -r sample-api -x kubedev -n default
+
+  # Com auto-approve
+  opsmaster nelm uninstall -r sample-api -x kubedev --auto-approve
+
+  # Com timeout personalizado
+  opsmaster nelm uninstall -r sample-api -x kubedev --timeout 10m`,
+		en: `Runs a complete flow to remove a nelm release.
+
+Examples:
+  # Uninstall a specific release
+  opsmaster nelm uninstall -r sample-api -x kubedev
+
+  # With a custom namespace
+  opsmaster nelm uninstall -r sample-api -x kubedev -n default
+
+  # With auto-approve
+  opsmaster nelm uninstall -r sample-api -x kubedev --auto-approve
+
+  # With a custom timeout
+  opsmaster nelm uninstall -r sample-api -x kubedev --timeout 10m`,
+	},
+	{
+		ptBR: "Nome da release específica a ser desinstalada (opcional)",
+		en:   "Name of the specific release to uninstall (optional)",
+	},
+	{
+		ptBR: "Namespace onde a release está instalada (opcional - usa o nome da release se não fornecido)",
+		en:   "Namespace where the release is installed (optional - uses the release name if not given)",
+	},
+	// cmd/pipeline/pipeline.go
+	{
+		ptBR: "Executa pipelines nomeados do arquivo de configuração",
+		en:   "Runs named pipelines of the config file",
+	},
+	{
+		ptBR: `Executa pipelines definidos na seção pipelines do arquivo de configuração:
+estágios em ordem (ex: preflight → install puppet → install node-exporter →
+verify → notify), cada um um comando do opsmaster com as suas flags.
+
+Exemplos:
+  opsmaster pipeline run nightly-baseline --instances-file fleet.csv
+
+  # Mostrar os comandos de cada estágio sem executar
+  opsmaster pipeline run nightly-baseline --instances-file fleet.csv --plan`,
+		en: `Runs pipelines defined in the pipelines section of the config file: ordered
+stages (e.g., preflight → install puppet → install node-exporter → verify →
+notify), each one an opsmaster command with its flags.
+
+Examples:
+  opsmaster pipeline run nightly-baseline --instances-file fleet.csv
+
+  # Show the command of each stage without running it
+  opsmaster pipeline run nightly-baseline --instances-file fleet.csv --plan`,
+	},
+	// cmd/pipeline/run.go
+	{
+		ptBR: "Executa os estágios de um pipeline nas instâncias do CSV",
+		en:   "Runs the stages of a pipeline on the instances of the CSV",
+	},
+	{
+		ptBR: `Executa em ordem os estágios do pipeline informado, definido na seção pipelines
+do arquivo de configuração. Cada estágio é um comando do opsmaster (install
+puppet, install systemd-unit, run script, facts get, coverage, ...) executado
+com o arquivo --instances-file, o perfil (profile) e as flags do estágio; o
+estágio notify publica o resumo do pipeline em um tópico SNS ou barramento
+EventBridge (events-arn).
+
+Um estágio com falha interrompe o pipeline: os seguintes são ignorados, exceto
+os marcados com always: true (ex: notify). Com continue_on_error: true, a
+falha do estágio não interrompe os seguintes.
+
+  pipelines:
+    nightly-baseline:
+      stages:
+        - name: preflight
+          command: install puppet
+          profile: prod-puppet
+          flags: {dry-run: true}
+        - name: puppet
+          command: install puppet
+          profile: prod-puppet
+        - name: verify
+          command: coverage
+          flags: {expect: [puppet=true]}
+        - name: notify
+          command: notify
+          always: true
+          flags: {events-arn: "arn:aws:sns:us-east-1:111111111111:ops"}
+
+Exemplos:
+  opsmaster pipeline run nightly-baseline --instances-file fleet.csv
+
+  # Mostrar os comandos de cada estágio sem executar
+  opsmaster pipeline run nightly-baseline --instances-file fleet.csv --plan`,
+		en: `Runs in order the stages of the given pipeline, defined in the pipelines section
+of the config file. Each stage is an opsmaster command (install puppet, install
+systemd-unit, run script, facts get, coverage, ...) run with the
+--instances-file file, the profile and the flags of the stage; the notify
+stage publishes the pipeline summary to an SNS topic or EventBridge bus
+(events-arn).
+
+A failed stage stops the pipeline: the next stages are skipped, except those
+marked always: true (e.g., notify). With continue_on_error: true, a failed
+stage doesn't stop the next ones.
+
+  pipelines:
+    nightly-baseline:
+      stages:
+        - name: preflight
+          command: install puppet
+          profile: prod-puppet
+          flags: {dry-run: true}
+        - name: puppet
+          command: install puppet
+          profile: prod-puppet
+        - name: verify
+          command: coverage
+          flags: {expect: [puppet=true]}
+        - name: notify
+          command: notify
+          always: true
+          flags: {events-arn: "arn:aws:sns:us-east-1:111111111111:ops"}
+
+Examples:
+  opsmaster pipeline run nightly-baseline --instances-file fleet.csv
+
+  # Show the commands of the stages without running them
+  opsmaster pipeline run nightly-baseline --instances-file fleet.csv --plan`,
+	},
+	{
+		ptBR: "Arquivo CSV com lista de instâncias, passado a cada estágio: caminho local, https:// ou s3:// (aceita .gz) (obrigatório)",
+		en:   "CSV file with the instance list, passed to every stage: local path, https:// or s3:// (.gz accepted) (required)",
+	},
+	{
+		ptBR: "Mostra o comando de cada estágio sem executar",
+		en:   "Shows the command of each stage without running it",
+	},
+	// cmd/puppet/puppet.go
+	{
+		ptBR: "Operações na frota Puppet",
+		en:   "Operations on the Puppet fleet",
+	},
+	{
+		ptBR: `Operações sobre a frota gerenciada pelo Puppet (PuppetDB, relatórios, certificados).
+
+Exemplos:
+  # Comparar inventário (CSV) com os nós ativos no PuppetDB
+  opsmaster puppet reconcile --instances-file fleet.csv --puppetdb-url https://puppetdb.example.com:8081
+
+  # Regenerar certificados dos agentes após rotação da CA
+  opsmaster puppet regen-cert --instances-file fleet.csv --ca-url https://puppet.example.com:8140`,
+		en: `Operations on the fleet managed by Puppet (PuppetDB, reports, certificates).
+
+Examples:
+  # Compare the inventory (CSV) with the active nodes in PuppetDB
+  opsmaster puppet reconcile --instances-file fleet.csv --puppetdb-url https://puppetdb.example.com:8081
+
+  # Regenerate the agent certificates after a CA rotation
+  opsmaster puppet regen-cert --instances-file fleet.csv --ca-url https://puppet.example.com:8140`,
+	},
+	// cmd/puppet/reconcile.go
+	{
+		ptBR: "Compara o inventário (CSV) com os nós ativos no PuppetDB",
+		en:   "Compares the inventory (CSV) with the active nodes in PuppetDB",
+	},
+	{
+		ptBR: `Compara as instâncias do arquivo CSV com os nós ativos no PuppetDB e aponta:
+
+  - not-reporting:    instâncias do inventário sem nó ativo (ou sem nenhum relatório) no PuppetDB
+  - stale:            instâncias cujo último relatório é mais antigo que --stale-after
+  - not-in-inventory: nós ativos no PuppetDB que não estão no inventário
+
+Instâncias são associadas aos nós pela coluna certname do CSV, quando existir, ou pelo fact
+ec2_metadata.instance-id.
+
+Exemplos:
+  opsmaster puppet reconcile --instances-file fleet.csv --puppetdb-url https://puppetdb.example.com:8081 \
+    --puppetdb-cacert ca.pem --puppetdb-cert client.pem --puppetdb-key client-key.pem
+
+  opsmaster puppet reconcile --instances-file fleet.csv --puppetdb-url http://localhost:8080 \
+    --stale-after 6h --output json`,
+		en: `Compares the instances of the CSV file with the active nodes in PuppetDB and reports:
+
+  - not-reporting:    inventory instances without an active node (or without any report) in PuppetDB
+  - stale:            instances whose last report is older than --stale-after
+  - not-in-inventory: active nodes in PuppetDB that are not in the inventory
+
+Instances are matched to nodes by the certname column of the CSV, when present, or by the
+ec2_metadata.instance-id fact.
+
+Examples:
+  opsmaster puppet reconcile --instances-file fleet.csv --puppetdb-url https://puppetdb.example.com:8081 \
+    --puppetdb-cacert ca.pem --puppetdb-cert client.pem --puppetdb-key client-key.pem
+
+  opsmaster puppet reconcile --instances-file fleet.csv --puppetdb-url http://localhost:8080 \
+    --stale-after 6h --output json`,
+	},
+	{
+		ptBR: "URL do PuppetDB (obrigatório, ex: https://puppetdb.example.com:8081)",
+		en:   "PuppetDB URL (required, e.g., https://puppetdb.example.com:8081)",
+	},
+	{
+		ptBR: "Token RBAC do Puppet Enterprise; aceita referência vault:caminho#campo",
+		en:   "Puppet Enterprise RBAC token; accepts a vault:path#field reference",
+	},
+	{
+		ptBR: "Certificado da CA do Puppet para validar o PuppetDB",
+		en:   "Puppet CA certificate to validate PuppetDB",
+	},
+	{
+		ptBR: "Certificado cliente (mTLS) para o PuppetDB",
+		en:   "Client certificate (mTLS) for PuppetDB",
+	},
+	{
+		ptBR: "Chave privada do certificado cliente (mTLS)",
+		en:   "Private key of the client certificate (mTLS)",
+	},
+	{
+		ptBR: "Nós sem relatório há mais tempo que isso são considerados stale",
+		en:   "Nodes without a report for longer than this are considered stale",
+	},
+	{
+		ptBR: "Coluna do CSV com o certname (opcional)",
+		en:   "CSV column with the certname (optional)",
+	},
+	// cmd/puppet/regencert.go
+	{
+		ptBR: "Regenera o certificado do agente Puppet nas instâncias",
+		en:   "Regenerates the Puppet agent certificate on the instances",
+	},
+	{
+		ptBR: `Regenera o certificado do agente Puppet das instâncias do CSV, para recuperar nós
+após uma rotação da CA do Puppet.
+
+Para cada instância:
+  1. Lê o certname atual e o estado do serviço puppet
+  2. Para o agente, salva /etc/puppetlabs/puppet/ssl em /var/lib/opsmaster/puppet-ssl-backups
+     e remove o diretório
+  3. Define o novo certname (--certname generate ou coluna --certname-column) ou preserva o atual
+  4. Com --ca-url, revoga e remove o certificado antigo na CA (equivalente a "puppetserver ca clean")
+  5. Executa o agente para solicitar o novo certificado (com --sign, a CA assina a requisição)
+  6. Reinicia o serviço puppet se ele estava ativo
+
+Sem --ca-url a limpeza na CA deve ser feita antes, fora do opsmaster; caso contrário a CA
+rejeita a nova requisição de um certname preservado. A API certificate_status exige um
+certificado cliente liberado no auth.conf da CA (--ca-client-cert/--ca-client-key).
+
+Se uma etapa falhar após a remoção do diretório ssl, o caminho do backup aparece no erro.
+
+Exemplos:
+  # Preservar certnames, limpar a CA e deixar o autosign emitir os certificados
+  opsmaster puppet regen-cert --instances-file fleet.csv --ca-url https://puppet.example.com:8140 \
+    --ca-cacert ca.pem --ca-client-cert admin.pem --ca-client-key admin-key.pem
+
+  # Novos certnames, assinados pelo opsmaster
+  opsmaster puppet regen-cert --instances-file fleet.csv --certname generate --sign \
+    --ca-url https://puppet.example.com:8140 --ca-cacert ca.pem --ca-client-cert admin.pem --ca-client-key admin-key.pem`,
+		en: `Regenerates the Puppet agent certificate of the CSV instances, to recover nodes
+after a Puppet CA rotation.
+
+For each instance:
+  1. Reads the current certname and the state of the puppet service
+  2. Stops the agent, saves /etc/puppetlabs/puppet/ssl to /var/lib/opsmaster/puppet-ssl-backups
+     and removes the directory
+  3. Sets the new certname (--certname generate or the --certname-column column) or keeps the current one
+  4. With --ca-url, revokes and removes the old certificate on the CA (same as "puppetserver ca clean")
+  5. Runs the agent to request the new certificate (with --sign, the CA signs the request)
+  6. Restarts the puppet service if it was active
+
+Without --ca-url the CA cleanup must be done beforehand, outside opsmaster; otherwise the CA
+rejects the new request of a preserved certname. The certificate_status API requires a
+client certificate allowed in the auth.conf of the CA (--ca-client-cert/--ca-client-key).
+
+If a step fails after the ssl directory is removed, the backup path shows in the error.
+
+Examples:
+  # Keep certnames, clean the CA and let autosign issue the certificates
+  opsmaster puppet regen-cert --instances-file fleet.csv --ca-url https://puppet.example.com:8140 \
+    --ca-cacert ca.pem --ca-client-cert admin.pem --ca-client-key admin-key.pem
+
+  # New certnames, signed by opsmaster
+  opsmaster puppet regen-cert --instances-file fleet.csv --certname generate --sign \
+    --ca-url https://puppet.example.com:8140 --ca-cacert ca.pem --ca-client-cert admin.pem --ca-client-key admin-key.pem`,
+	},
+	{
+		ptBR: "Certname após a regeneração: preserve (atual) ou generate (novo <uuid>.puppet)",
+		en:   "Certname after the regeneration: preserve (current) or generate (new <uuid>.puppet)",
+	},
+	{
+		ptBR: "Coluna do CSV com o novo certname (tem prioridade sobre --certname quando preenchida)",
+		en:   "CSV column with the new certname (takes priority over --certname when filled in)",
+	},
+	{
+		ptBR: "URL da CA do Puppet para limpar/assinar certificados (ex: https://puppet.example.com:8140)",
+		en:   "Puppet CA URL to clean/sign certificates (e.g., https://puppet.example.com:8140)",
+	},
+	{
+		ptBR: "Certificado da CA do Puppet para validar o servidor",
+		en:   "Puppet CA certificate to validate the server",
+	},
+	{
+		ptBR: "Certificado cliente (mTLS) autorizado na API certificate_status",
+		en:   "Client certificate (mTLS) allowed on the certificate_status API",
+	},
+	{
+		ptBR: "Assinar as novas requisições pela API da CA (CAs sem autosign; requer --ca-url)",
+		en:   "Sign the new requests through the CA API (CAs without autosign; requires --ca-url)",
+	},
+	{
+		ptBR: "Tempo que o agente aguarda o certificado assinado",
+		en:   "Time the agent waits for the signed certificate",
+	},
+	{
+		ptBR: "Máximo de instâncias processadas em paralelo",
+		en:   "Maximum instances processed in parallel",
+	},
+	{
+		ptBR: "Simular sem executar",
+		en:   "Simulate without running",
+	},
+	// cmd/reboot/reboot.go
+	{
+		ptBR: "Reinicia instâncias em lotes e verifica a saúde após o boot",
+		en:   "Reboots instances in batches and checks their health after boot",
+	},
+	{
+		ptBR: `Reinicia as instâncias listadas no arquivo CSV em lotes sequenciais de --batch-size.
+
+Com --wait, cada lote só termina quando todas as instâncias voltam a responder via SSM
+(o boot ID em /proc/sys/kernel/random/boot_id muda) e, com --post-check, quando o comando
+de verificação retorna código 0. Se alguma instância do lote não voltar saudável dentro de
+--wait-timeout, os lotes seguintes não são reiniciados (use --continue-on-failure para seguir).
+
+Instâncias paradas ou em modo manutenção são ignoradas. Ao final, a tabela mostra as
+instâncias que não voltaram saudáveis.
+
+Exemplos:
+  opsmaster reboot --instances-file fleet.csv --wait --post-check 'systemctl is-active puppet'
+
+  # Lotes de 5, até 15 minutos por lote
+  opsmaster reboot --instances-file fleet.csv --batch-size 5 --wait --wait-timeout 15m`,
+		en: `Reboots the instances listed in the CSV file in sequential batches of --batch-size.
+
+With --wait, each batch only ends when all instances answer again via SSM
+(the boot ID in /proc/sys/kernel/random/boot_id changes) and, with --post-check, when the
+check command returns code 0. If any instance of the batch doesn't come back healthy within
+--wait-timeout, the following batches are not rebooted (use --continue-on-failure to go on).
+
+Stopped instances and instances in maintenance mode are skipped. At the end, the table shows the
+instances that didn't come back healthy.
+
+Examples:
+  opsmaster reboot --instances-file fleet.csv --wait --post-check 'systemctl is-active puppet'
+
+  # Batches of 5, up to 15 minutes per batch
+  opsmaster reboot --instances-file fleet.csv --batch-size 5 --wait --wait-timeout 15m`,
+	},
+	{
+		ptBR: "Quantidade de instâncias reiniciadas por lote",
+		en:   "Number of instances rebooted per batch",
+	},
+	{
+		ptBR: "Aguardar as instâncias voltarem (via SSM) antes do próximo lote",
+		en:   "Wait for the instances to come back (via SSM) before the next batch",
+	},
+	{
+		ptBR: "Tempo máximo de espera por lote com --wait",
+		en:   "Maximum wait time per batch with --wait",
+	},
+	{
+		ptBR: "Comando executado após o boot; código diferente de 0 marca a instância como não saudável (requer --wait)",
+		en:   "Command run after boot; a code other than 0 marks the instance as unhealthy (requires --wait)",
+	},
+	{
+		ptBR: "Continuar com os próximos lotes mesmo se um lote não voltar saudável",
+		en:   "Go on with the next batches even if a batch doesn't come back healthy",
+	},
+	// cmd/report/report.go
+	{
+		ptBR: "Valida e descreve os relatórios de execução (--report)",
+		en:   "Validates and describes run reports (--report)",
+	},
+	{
+		ptBR: `Trabalha com os relatórios JSON gerados por install ... --report.
+
+O formato é versionado pelo campo schema_version e descrito por um JSON schema
+embutido no binário, para que outras ferramentas possam consumir os relatórios
+com segurança.
+
+Exemplos:
+  opsmaster report validate run.json
+  opsmaster report show run.json i-0abc123def456
+  opsmaster report schema > report.schema.json`,
+		en: `Works with the JSON reports generated by install ... --report.
+
+The format is versioned by the schema_version field and described by a JSON schema
+embedded in the binary, so other tools can consume the reports
+safely.
+
+Examples:
+  opsmaster report validate run.json
+  opsmaster report show run.json i-0abc123def456
+  opsmaster report schema > report.schema.json`,
+	},
+	// cmd/report/show.go
+	{
+		ptBR: "Mostra o resultado e a saída completa de uma instância do relatório",
+		en:   "Shows the result and full output of one instance of the report",
+	},
+	{
+		ptBR: `Mostra o resultado de uma instância de um relatório (--report): status, fase
+e passo que falharam, código de saída e a saída completa (stdout e stderr)
+capturada do script, que a tabela do resumo reduz a uma linha.
+
+A saída capturada é limitada por --max-output-bytes do comando de instalação;
+com --ssm-output-bucket, a saída completa de cada comando fica no S3 e os
+endereços aparecem em output_urls.
+
+Exemplos:
+  opsmaster report show run.json i-0abc123def456`,
+		en: `Shows the result of one instance of a report (--report): status, failed
+phase and step, exit code and the full output (stdout and stderr) captured
+from the script, which the summary table reduces to one line.
+
+The captured output is limited by --max-output-bytes of the install command;
+with --ssm-output-bucket, the full output of each command is stored in S3 and
+its addresses show up in output_urls.
+
+Examples:
+  opsmaster report show run.json i-0abc123def456`,
+	},
+	// cmd/report/validate.go
+	{
+		ptBR: "Valida um relatório contra o JSON schema da sua versão",
+		en:   "Validates a report against the JSON schema of its version",
+	},
+	{
+		ptBR: `Valida um relatório JSON contra o schema embutido correspondente ao seu
+schema_version, listando cada campo inválido.
+
+Retorna erro (exit code 1) se o relatório for inválido, útil em pipelines que
+consomem os relatórios.
+
+Exemplos:
+  opsmaster report validate run.json`,
+		en: `Validates a JSON report against the embedded schema matching its
+schema_version, listing each invalid field.
+
+Returns an error (exit code 1) if the report is invalid, useful in pipelines that
+consume the reports.
+
+Examples:
+  opsmaster report validate run.json`,
+	},
+	{
+		ptBR: "Mostra o JSON schema dos relatórios",
+		en:   "Shows the JSON schema of the reports",
+	},
+	{
+		ptBR: `Mostra o JSON schema embutido dos relatórios (por padrão, o da versão
+escrita por este binário).
+
+Exemplos:
+  opsmaster report schema > report.schema.json
+  opsmaster report schema --version 1`,
+		en: `Shows the embedded JSON schema of the reports (by default, the one of the version
+written by this binary).
+
+Examples:
+  opsmaster report schema > report.schema.json
+  opsmaster report schema --version 1`,
+	},
+	{
+		ptBR: "schema_version do schema a mostrar",
+		en:   "schema_version of the schema to show",
+	},
+	// cmd/retry/retry.go
+	{
+		ptBR: "Ferramentas para ajustar as políticas de retry",
+		en:   "Tools to tune the retry policies",
+	},
+	{
+		ptBR: `Ferramentas para ajustar as políticas de retry (--max-retries, --retry-delay)
+sem tentativa e erro em produção.
+
+Exemplos:
+  opsmaster retry simulate --policy ssm --failure-rate 0.2 --operations 1000`,
+		en: `Tools to tune the retry policies (--max-retries, --retry-delay) without
+trial and error in production.
+
+Examples:
+  opsmaster retry simulate --policy ssm --failure-rate 0.2 --operations 1000`,
+	},
+	// cmd/retry/simulate.go
+	{
+		ptBR: "Simula uma política de retry (Monte Carlo)",
+		en:   "Simulates a retry policy (Monte Carlo)",
+	},
+	{
+		ptBR: `Simula uma política de retry com Monte Carlo: cada tentativa falha com a
+probabilidade de --failure-rate e as esperas seguem o mesmo backoff exponencial
+(com jitter) usado pelo opsmaster, sem chamar nenhuma API nem esperar de fato.
+
+Mostra o tempo total esperado, o volume de chamadas à API, as operações que
+esgotam as tentativas e os percentis da espera por operação, para comparar
+valores de --max-retries e --retry-delay antes de usá-los.
+
+Políticas: ssm (comandos remotos), ec2 (metadados, tags) e network. As flags
+--max-retries, --retry-delay, --max-delay e --retry-jitter sobrepõem a política.
+
+Exemplos:
+  opsmaster retry simulate --policy ssm --failure-rate 0.2 --operations 1000
+
+  # Comparar com 5 tentativas e espera inicial de 5s
+  opsmaster retry simulate --policy ssm --failure-rate 0.2 --max-retries 5 --retry-delay 5s`,
+		en: `Simulates a retry policy with Monte Carlo: each attempt fails with the
+probability of --failure-rate and the waits follow the same exponential backoff
+(with jitter) used by opsmaster, without calling any API or actually waiting.
+
+Shows the expected total time, the API call volume, the operations that run
+out of attempts and the percentiles of the wait per operation, to compare
+values of --max-retries and --retry-delay before using them.
+
+Policies: ssm (remote commands), ec2 (metadata, tags) and network. The flags
+--max-retries, --retry-delay, --max-delay and --retry-jitter override the policy.
+
+Examples:
+  opsmaster retry simulate --policy ssm --failure-rate 0.2 --operations 1000
+
+  # Compare with 5 attempts and a 5s initial wait
+  opsmaster retry simulate --policy ssm --failure-rate 0.2 --max-retries 5 --retry-delay 5s`,
+	},
+	{
+		ptBR: "Política simulada (ssm, ec2, network)",
+		en:   "Simulated policy (ssm, ec2, network)",
+	},
+	{
+		ptBR: "Probabilidade de cada tentativa falhar com erro transitório (0 a 1)",
+		en:   "Probability of each attempt failing with a transient error (0 to 1)",
+	},
+	{
+		ptBR: "Quantidade de operações simuladas",
+		en:   "Number of simulated operations",
+	},
+	{
+		ptBR: "Máximo de tentativas (padrão: o da política)",
+		en:   "Maximum attempts (default: the policy's)",
+	},
+	{
+		ptBR: "Espera inicial entre tentativas (padrão: a da política)",
+		en:   "Initial wait between attempts (default: the policy's)",
+	},
+	{
+		ptBR: "Espera máxima entre tentativas (padrão: a da política)",
+		en:   "Maximum wait between attempts (default: the policy's)",
+	},
+	{
+		ptBR: "Adiciona variação aleatória às esperas (padrão: o da política)",
+		en:   "Adds random variation to the waits (default: the policy's)",
+	},
+	{
+		ptBR: "Duração de cada chamada à API",
+		en:   "Duration of each API call",
+	},
+	{
+		ptBR: "Máximo de operações em paralelo, para o tempo decorrido",
+		en:   "Maximum operations in parallel, for the elapsed time",
+	},
+	{
+		ptBR: "Semente aleatória, para repetir uma simulação (0 = nova semente, mostrada no resultado)",
+		en:   "Random seed, to repeat a simulation (0 = new seed, shown in the result)",
+	},
+	// cmd/root.go
+	{
+		ptBR: "OpsMaster - Uma ferramenta de CLI para operações de DevOps",
+		en:   "OpsMaster - A CLI tool for DevOps operations",
+	},
+	{
+		ptBR: `OpsMaster é uma ferramenta de CLI projetada para ajudar em várias
+operações de DevOps. Ela fornece um conjunto de comandos para automatizar e
+simplificar tarefas comuns de DevOps.`,
+		en: `OpsMaster is a CLI tool designed to help with several
+DevOps operations. It provides a set of commands to automate and
+simplify common DevOps tasks.`,
+	},
+	{
+		ptBR: "arquivo de configuração (o padrão é $HOME/.opsmaster.yaml)",
+		en:   "config file (default is $HOME/.opsmaster.yaml)",
+	},
+	{
+		ptBR: "O contexto a ser usado do arquivo de configuração (ex: staging, producao)",
+		en:   "The context of the config file to use (e.g., staging, producao)",
+	},
+	{
+		ptBR: "Arquivo PEM com CAs adicionais para chamadas HTTP de saída",
+		en:   "PEM file with additional CAs for outgoing HTTP calls",
+	},
+	{
+		ptBR: "Força o provider de todas as instâncias (fake: simulado, sem conta na nuvem)",
+		en:   "Forces the provider of all instances (fake: simulated, no cloud account)",
+	},
+	{
+		ptBR: "Cenário YAML do provider simulado (--provider fake)",
+		en:   "YAML scenario of the simulated provider (--provider fake)",
+	},
+	{
+		ptBR: "Perfil de flags da seção profiles do arquivo de configuração (ex: prod-puppet); flags da linha de comando têm precedência",
+		en:   "Flag profile from the profiles section of the config file (e.g., prod-puppet); command-line flags take precedence",
+	},
+	{
+		ptBR: "Saída apenas em ASCII: [OK]/[FAIL]/[SKIP] no lugar de emojis e bordas simples nas tabelas (padrão: ativada quando o locale não é UTF-8)",
+		en:   "ASCII-only output: [OK]/[FAIL]/[SKIP] instead of emoji and plain table borders (default: on when the locale isn't UTF-8)",
+	},
+	{
+		ptBR: "Coluna do CSV com o nome exibido ao lado do ID da instância em tabelas, logs e notificações (padrão: name ou hostname, ou a tag Name)",
+		en:   "CSV column with the name shown next to the instance ID in tables, logs and notifications (default: name or hostname, or the Name tag)",
+	},
+	{
+		ptBR: "Idioma da ajuda e das mensagens: en ou pt-BR (padrão: OPSMASTER_LANG ou o locale; pt-BR se não suportado)",
+		en:   "Language of the help and messages: en or pt-BR (default: OPSMASTER_LANG or the locale; pt-BR if unsupported)",
+	},
+	{
+		ptBR: "Arquivo YAML de instâncias em quarentena, sempre ignoradas (padrão: quarantine.file do config ou $HOME/.opsmaster-quarantine.yaml)",
+		en:   "YAML file of quarantined instances, always skipped (default: quarantine.file from the config or $HOME/.opsmaster-quarantine.yaml)",
+	},
+	{
+		ptBR: "Modo somente leitura: recusa tags, start/stop/reboot e comandos remotos que alteram as instâncias (padrão: read_only.enabled do config)",
+		en:   "Read-only mode: refuses tags, start/stop/reboot and remote commands that change the instances (default: read_only.enabled from the config)",
+	},
+	{
+		ptBR: "Documento SSM (nome ou ARN) que executa os comandos shell no lugar do AWS-RunShellScript, validado em cada conta/região (padrão: ssm.document_name do config)",
+		en:   "SSM document (name or ARN) that runs shell commands instead of AWS-RunShellScript, validated in each account/region (default: ssm.document_name from the config)",
+	},
+	{
+		ptBR: "Nomes dos parâmetros do documento --ssm-document-name (ex: commands=Script,executionTimeout=TimeoutSeconds; executionTimeout= quando não existe) (padrão: ssm.document_parameters do config)",
+		en:   "Parameter names of the --ssm-document-name document (e.g., commands=Script,executionTimeout=TimeoutSeconds; executionTimeout= when it has none) (default: ssm.document_parameters from the config)",
+	},
+	{
+		ptBR: "Bucket S3 (criptografado com SSE-KMS) onde o SSM grava a saída completa de cada comando; os endereços vão para o relatório (padrão: ssm.output_bucket do config)",
+		en:   "S3 bucket (encrypted with SSE-KMS) where SSM stores the full output of each command; the addresses go to the report (default: ssm.output_bucket from the config)",
+	},
+	{
+		ptBR: "Prefixo das chaves da saída no --ssm-output-bucket (ex: opsmaster/prod) (padrão: ssm.output_prefix do config)",
+		en:   "Key prefix of the output in --ssm-output-bucket (e.g., opsmaster/prod) (default: ssm.output_prefix from the config)",
+	},
+	{
+		ptBR: "Chave KMS (ID ou ARN) exigida na criptografia padrão do --ssm-output-bucket (padrão: qualquer chave KMS; ssm.output_kms_key do config)",
+		en:   "KMS key (ID or ARN) required in the default encryption of --ssm-output-bucket (default: any KMS key; ssm.output_kms_key from the config)",
+	},
+	// cmd/run/run.go
+	{
+		ptBR: "Executa comandos e scripts na frota",
+		en:   "Runs commands and scripts across the fleet",
+	},
+	{
+		ptBR: `Executa comandos e scripts ad-hoc nas instâncias listadas em arquivo CSV (via SSM),
+com o resultado de cada instância em uma tabela ou JSON.
+
+Exemplos:
+  # Verificar o espaço em disco em toda a frota
+  opsmaster run script --command "df -h /" --instances-file fleet.csv`,
+		en: `Runs ad-hoc commands and scripts on the instances listed in a CSV file (via SSM),
+with the result of each instance in a table or JSON.
+
+Examples:
+  # Check the disk space across the fleet
+  opsmaster run script --command "df -h /" --instances-file fleet.csv`,
+	},
+	// cmd/run/script.go
+	{
+		ptBR: "Executa um script em cada instância e mostra o resultado",
+		en:   "Runs a script on each instance and shows the result",
+	},
+	{
+		ptBR: `Executa um script (inline com --command ou de um arquivo local com --file) em cada
+instância via SSM e mostra o exit code e a saída de cada uma.
+
+O shell é escolhido com --shell:
+  auto        (padrão) PowerShell nas instâncias Windows e sh nas demais, pela plataforma
+              detectada (coluna "platform" do CSV ou API do provider)
+  sh, bash    Shell POSIX em todas as instâncias (documento AWS-RunShellScript)
+  powershell  Windows PowerShell em todas as instâncias (documento AWS-RunPowerShellScript)
+
+Em PowerShell, erros interrompem o script com exit code 1 e o exit code do último
+executável é retornado, como em um script shell. Em CSVs com Linux e Windows, use
+--windows-file com a versão PowerShell do script para as instâncias Windows.
+
+Exemplos:
+  opsmaster run script --command "uptime" --instances-file fleet.csv
+
+  # Frota mista: script.sh no Linux e script.ps1 no Windows
+  opsmaster run script --file script.sh --windows-file script.ps1 --instances-file fleet.csv
+
+  # Saída JSON para processar com jq
+  opsmaster run script --shell powershell --command "Get-Service W32Time" --instances-file windows.csv -o json`,
+		en: `Runs a script (inline with --command or from a local file with --file) on each
+instance via SSM and shows the exit code and the output of each one.
+
+The shell is chosen with --shell:
+  auto        (default) PowerShell on Windows instances and sh on the others, by the platform
+              detected (CSV "platform" column or provider API)
+  sh, bash    POSIX shell on all instances (AWS-RunShellScript document)
+  powershell  Windows PowerShell on all instances (AWS-RunPowerShellScript document)
+
+In PowerShell, errors stop the script with exit code 1 and the exit code of the last
+executable is returned, as in a shell script. In CSVs with Linux and Windows, use
+--windows-file with the PowerShell version of the script for the Windows instances.
+
+Examples:
+  opsmaster run script --command "uptime" --instances-file fleet.csv
+
+  # Mixed fleet: script.sh on Linux and script.ps1 on Windows
+  opsmaster run script --file script.sh --windows-file script.ps1 --instances-file fleet.csv
+
+  # JSON output to process with jq
+  opsmaster run script --shell powershell --command "Get-Service W32Time" --instances-file windows.csv -o json`,
+	},
+	{
+		ptBR: "Script inline a executar",
+		en:   "Inline script to run",
+	},
+	{
+		ptBR: "Arquivo local com o script a executar",
+		en:   "Local file with the script to run",
+	},
+	{
+		ptBR: "Arquivo PowerShell executado nas instâncias Windows (apenas com --shell auto)",
+		en:   "PowerShell file run on Windows instances (only with --shell auto)",
+	},
+	{
+		ptBR: "Shell do script (auto|sh|bash|powershell)",
+		en:   "Script shell (auto|sh|bash|powershell)",
+	},
+	{
+		ptBR: "Máximo de execuções em paralelo",
+		en:   "Maximum parallel runs",
+	},
+	{
+		ptBR: "Tempo máximo do script em cada instância",
+		en:   "Maximum time of the script on each instance",
+	},
+	// cmd/scan/monitor.go
+	{
+		ptBR: "Monitora uma URL em intervalos regulares",
+		en:   "Monitors a URL at regular intervals",
+	},
+	{
+		ptBR: "Realiza requisições HTTP para uma URL em intervalos de tempo definidos para verificar a sua disponibilidade e status.",
+		en:   "Sends HTTP requests to a URL at set intervals to check its availability and status.",
+	},
+	{
+		ptBR: "Intervalo entre as verificações",
+		en:   "Interval between checks",
+	},
+	{
+		ptBR: "Número de verificações a serem feitas (0 para infinito)",
+		en:   "Number of checks to run (0 for infinite)",
+	},
+	// cmd/scan/ports.go
+	{
+		ptBR: "Escaneia portas TCP em um host",
+		en:   "Scans TCP ports on a host",
+	},
+	{
+		ptBR: "Verifica o status de portas TCP (abertas, fechadas ou filtradas) em um determinado host.",
+		en:   "Checks the status of TCP ports (open, closed or filtered) on a given host.",
+	},
+	{
+		ptBR: "Portas a escanear (ex: 80,443 ou 1-1024)",
+		en:   "Ports to scan (e.g., 80,443 or 1-1024)",
+	},
+	{
+		ptBR: "Timeout para cada conexão de porta (ex: 500ms, 2s)",
+		en:   "Timeout for each port connection (e.g., 500ms, 2s)",
+	},
+	// cmd/scan/scan.go
+	{
+		ptBR: "Executa vários tipos de escaneamento em alvos de rede",
+		en:   "Runs several kinds of scans on network targets",
+	},
+	{
+		ptBR: "O comando 'scan' é um agrupador para subcomandos que realizam verificações ativas em alvos de rede, como escaneamento de portas e monitoramento de URLs.",
+		en:   "The 'scan' command groups subcommands that run active checks on network targets, such as port scanning and URL monitoring.",
+	},
+	// cmd/tags/apply.go
+	{
+		ptBR: "Aplica as tags registradas no relatório de uma execução",
+		en:   "Applies the tags recorded in the report of a run",
+	},
+	{
+		ptBR: `Aplica nas instâncias as tags registradas no relatório JSON de uma execução
+(install ... --report run.json), sem reinstalar nada.
+
+Útil quando a instalação rodou com --skip-tagging (ex: para validar antes de marcar as
+instâncias) ou quando as tags foram removidas. Por padrão só as instâncias com status
+SUCCESS recebem tags; --include-failed também reaplica as tags de falha.
+
+Exemplos:
+  opsmaster tags apply --from-report run.json
+  opsmaster tags apply --from-report run.json --include-failed --dry-run`,
+		en: `Applies to the instances the tags recorded in the JSON report of a run
+(install ... --report run.json), without reinstalling anything.
+
+Useful when the installation ran with --skip-tagging (e.g., to validate before tagging the
+instances) or when the tags were removed. By default only instances with status
+SUCCESS get tags; --include-failed also reapplies the failure tags.
+
+Examples:
+  opsmaster tags apply --from-report run.json
+  opsmaster tags apply --from-report run.json --include-failed --dry-run`,
+	},
+	{
+		ptBR: "Relatório JSON gerado por install --report (obrigatório)",
+		en:   "JSON report generated by install --report (required)",
+	},
+	{
+		ptBR: "Aplicar também as tags de falha das instâncias que falharam",
+		en:   "Also apply the failure tags of the instances that failed",
+	},
+	{
+		ptBR: "Máximo de instâncias marcadas em paralelo",
+		en:   "Maximum instances tagged in parallel",
+	},
+	{
+		ptBR: "Mostrar as tags sem aplicá-las",
+		en:   "Show the tags without applying them",
+	},
+	// cmd/tags/tags.go
+	{
+		ptBR: "Gerencia as tags do opsmaster nas instâncias",
+		en:   "Manages the opsmaster tags on the instances",
+	},
+	{
+		ptBR: `Gerencia as tags que o opsmaster aplica nas instâncias (ex: puppet=true,
+opsmaster:last_run_id).
+
+Exemplos:
+  # Aplicar as tags de uma instalação executada com --skip-tagging
+  opsmaster install puppet --instances-file fleet.csv --puppet-server puppet.example.com \
+    --skip-tagging --report run.json
+  opsmaster tags apply --from-report run.json`,
+		en: `Manages the tags opsmaster applies to the instances (e.g., puppet=true,
+opsmaster:last_run_id).
+
+Examples:
+  # Apply the tags of an installation run with --skip-tagging
+  opsmaster install puppet --instances-file fleet.csv --puppet-server puppet.example.com \
+    --skip-tagging --report run.json
+  opsmaster tags apply --from-report run.json`,
+	},
+	// cmd/version.go
+	{
+		ptBR: "Exibe o número da versão do OpsMaster",
+		en:   "Shows the OpsMaster version number",
+	},
+	{
+		ptBR: "Exibe o número da versão da ferramenta de CLI OpsMaster.",
+		en:   "Shows the version number of the OpsMaster CLI tool.",
+	},
+}
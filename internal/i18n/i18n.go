@@ -0,0 +1,142 @@
+// Package i18n translates the CLI help and console messages between
+// Portuguese (pt-BR) and English (en). The catalog pairs each message in
+// both languages and is keyed by the text itself, gettext-style: code keeps
+// writing messages in either language and T returns the one of the current
+// language, or the text unchanged when it isn't in the catalog.
+//
+//	presenter.Printf("\n📊 Summary: %d tagged, %d failed\n", ...) // catalog: "📊 Resumo: ..."
+//	return i18n.Errorf("invalid --shell %q (supported: ...)", shell)
+//
+// The language is process-wide: the root command selects it once (--lang,
+// OPSMASTER_LANG or the locale, see Detect) before parsing the command line.
+// Logs are not translated.
+package i18n
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"unicode"
+)
+
+// Lang is a supported language.
+type Lang string
+
+const (
+	PortugueseBR Lang = "pt-BR"
+	English      Lang = "en"
+
+	// Default is the language of locales that aren't supported (e.g., C).
+	Default = PortugueseBR
+
+	// EnvVar selects the language (same as --lang) over the locale.
+	EnvVar = "OPSMASTER_LANG"
+)
+
+var current atomic.Value // Lang
+
+// Set selects the language of T.
+func Set(lang Lang) {
+	current.Store(lang)
+}
+
+// Current returns the selected language (Default until Set is called).
+func Current() Lang {
+	if lang, ok := current.Load().(Lang); ok {
+		return lang
+	}
+	return Default
+}
+
+// Parse returns the language of s, a language tag or a locale name (e.g.,
+// "en", "pt-BR", "en_US.UTF-8", "pt_BR.utf8").
+func Parse(s string) (Lang, error) {
+	tag := strings.ToLower(s)
+	if i := strings.IndexAny(tag, ".@"); i >= 0 {
+		tag = tag[:i] // Encoding and modifier of locale names
+	}
+	tag = strings.ReplaceAll(tag, "_", "-")
+
+	switch {
+	case tag == "en" || strings.HasPrefix(tag, "en-"):
+		return English, nil
+	case tag == "pt" || strings.HasPrefix(tag, "pt-"):
+		return PortugueseBR, nil
+	default:
+		return "", fmt.Errorf("unsupported language %q (supported: %s, %s)", s, English, PortugueseBR)
+	}
+}
+
+// Detect returns the language selected by flag (the value of --lang), else
+// by OPSMASTER_LANG, else by the locale (LC_ALL, LC_MESSAGES or LANG, the
+// first set wins, read with getenv). An unsupported --lang or OPSMASTER_LANG
+// is an error; an unsupported locale selects Default.
+func Detect(flag string, getenv func(string) string) (Lang, error) {
+	if flag != "" {
+		return Parse(flag)
+	}
+	if value := getenv(EnvVar); value != "" {
+		lang, err := Parse(value)
+		if err != nil {
+			return "", fmt.Errorf("%s: %w", EnvVar, err)
+		}
+		return lang, nil
+	}
+	for _, name := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if locale := getenv(name); locale != "" {
+			if lang, err := Parse(locale); err == nil {
+				return lang, nil
+			}
+			return Default, nil
+		}
+	}
+	return Default, nil
+}
+
+// T returns s in the current language. Leading and trailing whitespace is
+// kept and not part of the catalog key, so "\n📊 Summary: %d\n" matches the
+// catalog entry "📊 Summary: %d".
+func T(s string) string {
+	core := strings.TrimFunc(s, unicode.IsSpace)
+	if core == "" {
+		return s
+	}
+	msg, ok := lookup()[core]
+	if !ok {
+		return s
+	}
+	translated := msg.ptBR
+	if Current() == English {
+		translated = msg.en
+	}
+	if translated == core {
+		return s
+	}
+	start := strings.Index(s, core)
+	return s[:start] + translated + s[start+len(core):]
+}
+
+// Errorf is fmt.Errorf with the format translated by T, for messages shown
+// to the user (e.g., flag validation). Wrapped errors (%w) keep their text.
+func Errorf(format string, args ...any) error {
+	return fmt.Errorf(T(format), args...)
+}
+
+var (
+	indexOnce sync.Once
+	index     map[string]*message
+)
+
+// lookup returns the catalog indexed by the text of both languages.
+func lookup() map[string]*message {
+	indexOnce.Do(func() {
+		index = make(map[string]*message, 2*len(catalog))
+		for i := range catalog {
+			msg := &catalog[i]
+			index[msg.ptBR] = msg
+			index[msg.en] = msg
+		}
+	})
+	return index
+}
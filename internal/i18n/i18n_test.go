@@ -0,0 +1,193 @@
+package i18n
+
+import (
+	"errors"
+	"regexp"
+	"slices"
+	"testing"
+)
+
+// TestParse tests language tags and locale names
+func TestParse(t *testing.T) {
+	tests := []struct {
+		input       string
+		want        Lang
+		expectError bool
+	}{
+		{input: "en", want: English},
+		{input: "EN", want: English},
+		{input: "en_US.UTF-8", want: English},
+		{input: "en-GB", want: English},
+		{input: "pt-BR", want: PortugueseBR},
+		{input: "pt_BR.utf8", want: PortugueseBR},
+		{input: "pt", want: PortugueseBR},
+		{input: "pt_PT@euro", want: PortugueseBR},
+		{input: "C", expectError: true},
+		{input: "fr_FR.UTF-8", expectError: true},
+		{input: "english", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := Parse(tt.input)
+			if tt.expectError {
+				if err == nil {
+					t.Fatalf("Parse(%q) expected error, got %q", tt.input, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse(%q) unexpected error: %v", tt.input, err)
+			}
+			if got != tt.want {
+				t.Errorf("Parse(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestDetect tests the precedence of --lang, OPSMASTER_LANG and the locale
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name        string
+		flag        string
+		env         map[string]string
+		want        Lang
+		expectError bool
+	}{
+		{name: "nothing set", want: Default},
+		{name: "flag", flag: "en", env: map[string]string{EnvVar: "pt-BR", "LANG": "pt_BR.UTF-8"}, want: English},
+		{name: "env var over locale", env: map[string]string{EnvVar: "en", "LANG": "pt_BR.UTF-8"}, want: English},
+		{name: "LANG", env: map[string]string{"LANG": "en_US.UTF-8"}, want: English},
+		{name: "LC_ALL over LANG", env: map[string]string{"LC_ALL": "pt_BR.UTF-8", "LANG": "en_US.UTF-8"}, want: PortugueseBR},
+		{name: "LC_MESSAGES over LANG", env: map[string]string{"LC_MESSAGES": "en_US.UTF-8", "LANG": "pt_BR.UTF-8"}, want: English},
+		{name: "unsupported locale", env: map[string]string{"LANG": "C.UTF-8"}, want: Default},
+		{name: "invalid flag", flag: "klingon", expectError: true},
+		{name: "invalid env var", env: map[string]string{EnvVar: "klingon"}, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Detect(tt.flag, func(name string) string { return tt.env[name] })
+			if tt.expectError {
+				if err == nil {
+					t.Fatalf("Detect() expected error, got %q", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Detect() unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Detect() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestT tests translating messages written in either language
+func TestT(t *testing.T) {
+	t.Cleanup(func() { Set(Default) })
+
+	tests := []struct {
+		name  string
+		lang  Lang
+		input string
+		want  string
+	}{
+		{
+			name:  "portuguese to english",
+			lang:  English,
+			input: "Perfil AWS a usar (padrão: perfil default)",
+			want:  "AWS profile to use (default: default profile)",
+		},
+		{
+			name:  "english to portuguese",
+			lang:  PortugueseBR,
+			input: "Base delay between retries",
+			want:  "Espera base entre as tentativas",
+		},
+		{
+			name:  "same language",
+			lang:  PortugueseBR,
+			input: "Perfil AWS a usar (padrão: perfil default)",
+			want:  "Perfil AWS a usar (padrão: perfil default)",
+		},
+		{
+			name:  "surrounding whitespace kept",
+			lang:  PortugueseBR,
+			input: "\n📊 Summary: %d tagged, %d failed\n",
+			want:  "\n📊 Resumo: %d marcadas, %d falhas\n",
+		},
+		{
+			name:  "not in the catalog",
+			lang:  English,
+			input: "i-0123456789abcdef0",
+			want:  "i-0123456789abcdef0",
+		},
+		{
+			name:  "empty",
+			lang:  English,
+			input: "",
+			want:  "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			Set(tt.lang)
+			if got := T(tt.input); got != tt.want {
+				t.Errorf("T(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestErrorf tests that wrapped errors keep working after translation
+func TestErrorf(t *testing.T) {
+	t.Cleanup(func() { Set(Default) })
+	Set(PortugueseBR)
+
+	cause := Errorf("--batch-size must be at least 1")
+	err := Errorf("invalid --where selector: %w", cause)
+
+	if got, want := err.Error(), "seletor --where inválido: --batch-size deve ser no mínimo 1"; got != want {
+		t.Errorf("Errorf() = %q, want %q", got, want)
+	}
+	if !errors.Is(err, cause) {
+		t.Error("Errorf() should wrap %w errors")
+	}
+}
+
+// verbs matches format verbs and template actions, which translations must keep.
+var verbs = regexp.MustCompile(`%[-+# 0-9.]*[a-zA-Z]|\{\{[^}]*\}\}`)
+
+// TestCatalog tests that every message has both languages, keeps the format
+// verbs of the original and is not ambiguous
+func TestCatalog(t *testing.T) {
+	seen := make(map[string]message)
+	for _, msg := range catalog {
+		if msg.ptBR == "" || msg.en == "" {
+			t.Errorf("message %+v: missing a language", msg)
+			continue
+		}
+		if msg.ptBR == msg.en {
+			t.Errorf("message %q: same text in both languages", msg.en)
+		}
+
+		ptVerbs := verbs.FindAllString(msg.ptBR, -1)
+		enVerbs := verbs.FindAllString(msg.en, -1)
+		slices.Sort(ptVerbs)
+		slices.Sort(enVerbs)
+		if !slices.Equal(ptVerbs, enVerbs) {
+			t.Errorf("message %q: verbs %v, translation has %v", msg.en, enVerbs, ptVerbs)
+		}
+
+		for _, text := range []string{msg.ptBR, msg.en} {
+			if other, ok := seen[text]; ok && other != msg {
+				t.Errorf("text %q is in two messages with different translations", text)
+			}
+			seen[text] = msg
+		}
+	}
+}
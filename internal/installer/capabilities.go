@@ -0,0 +1,139 @@
+package installer
+
+import (
+	"context"
+	"time"
+
+	"github.com/estudosdevops/opsmaster/internal/cloud"
+)
+
+// Optional installer capabilities.
+//
+// PackageInstaller is the contract every installer implements. Installers can
+// opt into extra behavior by implementing any of the interfaces below; the
+// executor discovers them once with CapabilitiesOf and adapts its workflow.
+//
+// Precedence when installing (real execution):
+//  1. LocalInstaller      - installer runs its own installation flow
+//  2. AutoDetector        - script generated after remote OS detection
+//     (skipped when the CSV "os" column is set, unless WithForceDetect)
+//  3. StepBasedInstaller  - named steps executed one by one
+//  4. GenerateInstallScript (OS from the CSV "os" column, default ubuntu)
+//
+// In dry-run mode no remote call is made: LocalInstaller and AutoDetector are
+// skipped and the script is generated from the CSV "os" column.
+
+// AutoDetector is implemented by installers that detect the instance OS
+// remotely before generating the install script (e.g., PuppetInstaller).
+type AutoDetector interface {
+	// GenerateInstallScriptWithAutoDetect returns the install commands and
+	// metadata collected during detection (OS, certname, ...).
+	GenerateInstallScriptWithAutoDetect(ctx context.Context, instance *cloud.Instance, provider cloud.CloudProvider, options map[string]string) ([]string, *InstallMetadata, error)
+}
+
+// LocalInstaller is implemented by installers that drive the installation
+// themselves (custom recovery, multiple remote calls) instead of having the
+// executor run a generated script.
+type LocalInstaller interface {
+	// InstallLocal performs the installation and returns metadata for reporting.
+	InstallLocal(ctx context.Context, instance *cloud.Instance, provider cloud.CloudProvider) (*InstallMetadata, error)
+}
+
+// InstallStep is a named group of commands executed as one remote call.
+// A failing step stops the installation and is reported by name.
+type InstallStep struct {
+	Name      string            // Step name used in logs and errors (e.g., "configure-repo")
+	Commands  []string          // Shell commands executed together
+	Timeout   time.Duration     // Step timeout (0 = executor default)
+	Env       map[string]string // Environment for the commands, kept out of the script body
+	SecretEnv map[string]string // Env var -> secret store parameter (see cloud.ExecOptions)
+}
+
+// StepBasedInstaller is implemented by installers that split installation
+// into steps, so failures point to the step that broke.
+type StepBasedInstaller interface {
+	// GenerateInstallSteps returns ordered steps for the operating system.
+	GenerateInstallSteps(os string, options map[string]string) ([]InstallStep, error)
+}
+
+// FactVerifier is implemented by installers that check facts/configuration
+// on the instance after VerifyInstallation succeeds.
+type FactVerifier interface {
+	// VerifyFacts returns error if expected facts are missing or wrong.
+	VerifyFacts(ctx context.Context, instance *cloud.Instance, provider cloud.CloudProvider) error
+}
+
+// ConcurrencyGrouper is implemented by installers whose instances share a
+// backend that needs its own concurrency limit (e.g., one Puppet Server CA).
+type ConcurrencyGrouper interface {
+	// ConcurrencyGroup returns the group key for an instance (e.g., server hostname).
+	ConcurrencyGroup(instance *cloud.Instance) string
+}
+
+// PostInstallHook is implemented by installers that notify external systems
+// after a verified installation (e.g., registering the node in an ENC/CMDB).
+type PostInstallHook interface {
+	// AfterInstall runs after verification and tagging. Errors are reported
+	// but don't fail the installation (the package is already in place).
+	AfterInstall(ctx context.Context, instance *cloud.Instance, metadata *InstallMetadata) error
+}
+
+// DiagnosticsProvider is implemented by installers whose logs help debugging
+// failed installations: they are tailed in the diagnostic bundle collected
+// from failed instances (see DiagnosticScript).
+type DiagnosticsProvider interface {
+	// DiagnosticLogs returns the log files to tail on the instance.
+	DiagnosticLogs() []string
+}
+
+// Capabilities holds the optional interfaces implemented by an installer.
+// Nil fields mean the capability is not supported.
+type Capabilities struct {
+	AutoDetect    AutoDetector
+	LocalInstall  LocalInstaller
+	StepBased     StepBasedInstaller
+	VerifiesFacts FactVerifier
+	Grouping      ConcurrencyGrouper
+	PostInstall   PostInstallHook
+	Diagnostics   DiagnosticsProvider
+}
+
+// CapabilitiesOf discovers the optional capabilities of an installer.
+func CapabilitiesOf(pi PackageInstaller) Capabilities {
+	var caps Capabilities
+	caps.AutoDetect, _ = pi.(AutoDetector)
+	caps.LocalInstall, _ = pi.(LocalInstaller)
+	caps.StepBased, _ = pi.(StepBasedInstaller)
+	caps.VerifiesFacts, _ = pi.(FactVerifier)
+	caps.Grouping, _ = pi.(ConcurrencyGrouper)
+	caps.PostInstall, _ = pi.(PostInstallHook)
+	caps.Diagnostics, _ = pi.(DiagnosticsProvider)
+	return caps
+}
+
+// Names returns the supported capability names (for logging).
+func (c Capabilities) Names() []string {
+	var names []string
+	if c.LocalInstall != nil {
+		names = append(names, "local-install")
+	}
+	if c.AutoDetect != nil {
+		names = append(names, "auto-detect")
+	}
+	if c.StepBased != nil {
+		names = append(names, "step-based")
+	}
+	if c.VerifiesFacts != nil {
+		names = append(names, "verifies-facts")
+	}
+	if c.Grouping != nil {
+		names = append(names, "concurrency-groups")
+	}
+	if c.PostInstall != nil {
+		names = append(names, "post-install")
+	}
+	if c.Diagnostics != nil {
+		names = append(names, "diagnostics")
+	}
+	return names
+}
@@ -0,0 +1,37 @@
+package installer
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestCapabilitiesOf tests discovery of optional installer capabilities
+func TestCapabilitiesOf(t *testing.T) {
+	tests := []struct {
+		name      string
+		installer PackageInstaller
+		expected  string
+	}{
+		{
+			name:      "puppet installer auto-detects OS",
+			installer: NewPuppetInstaller(PuppetOptions{Server: "puppet.example.com"}),
+			expected:  "auto-detect,concurrency-groups,post-install,diagnostics",
+		},
+		{
+			name:      "basic installer has no optional capabilities",
+			installer: &mockPackageInstaller{name: "basic"},
+			expected:  "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			caps := CapabilitiesOf(tt.installer)
+
+			got := strings.Join(caps.Names(), ",")
+			if got != tt.expected {
+				t.Errorf("CapabilitiesOf().Names() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,233 @@
+package installer
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/estudosdevops/opsmaster/internal/cloud"
+)
+
+// CSRAttributesPath is where the agent (puppet-agent package) reads the
+// attributes of its certificate signing request. The distro package reads
+// /etc/puppet/csr_attributes.yaml.
+const CSRAttributesPath = "/etc/puppetlabs/puppet/csr_attributes.yaml"
+
+// CSRAttributes maps CSV columns to the attributes written to
+// csr_attributes.yaml before the first agent run. Extension requests are
+// embedded in the certificate and become trusted facts
+// ($trusted['extensions']) once it is signed.
+type CSRAttributes struct {
+	ExtensionRequests map[string]string // Extension (pp_role, pp_environment or OID) → CSV column
+	CustomAttributes  map[string]string // CSR attribute (challengePassword or OID) → CSV column
+}
+
+var (
+	// csrOIDPattern matches dotted OIDs (e.g., 1.3.6.1.4.1.34380.1.2.1).
+	csrOIDPattern = regexp.MustCompile(`^[0-9]+(\.[0-9]+)+$`)
+
+	// csrAttributeNamePattern matches custom attribute short names.
+	csrAttributeNamePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9]*$`)
+)
+
+// puppetExtensionNames are the short names Puppet registers for its
+// certificate extension OIDs (ppRegCertExt and ppAuthCertExt arcs).
+var puppetExtensionNames = map[string]bool{
+	"pp_uuid": true, "pp_instance_id": true, "pp_image_name": true, "pp_preshared_key": true,
+	"pp_cost_center": true, "pp_product": true, "pp_project": true, "pp_application": true,
+	"pp_service": true, "pp_employee": true, "pp_created_by": true, "pp_environment": true,
+	"pp_role": true, "pp_software_version": true, "pp_department": true, "pp_cluster": true,
+	"pp_provisioner": true, "pp_region": true, "pp_datacenter": true, "pp_zone": true,
+	"pp_network": true, "pp_securitypolicy": true, "pp_cloudplatform": true, "pp_apptier": true,
+	"pp_hostname": true, "pp_owner": true, "pp_authorization": true, "pp_auth_role": true,
+}
+
+// LoadCSRAttributesFromYAML loads the csr_attributes.yaml mapping from a
+// YAML file. Each entry maps an extension or attribute name to a CSV column.
+//
+// Expected YAML format:
+//
+//	extension_requests:
+//	  pp_role: "role"
+//	  pp_environment: "environment"
+//	  1.3.6.1.4.1.34380.1.2.1: "team"
+//	custom_attributes:
+//	  challengePassword: "autosign_token"
+func LoadCSRAttributesFromYAML(filepath string) (*CSRAttributes, error) {
+	data, err := os.ReadFile(filepath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read csr attributes file: %w", err)
+	}
+
+	var raw struct {
+		ExtensionRequests map[string]string `yaml:"extension_requests"`
+		CustomAttributes  map[string]string `yaml:"custom_attributes"`
+	}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&raw); err != nil {
+		return nil, fmt.Errorf("failed to parse csr attributes YAML: %w", err)
+	}
+
+	attrs := &CSRAttributes{
+		ExtensionRequests: raw.ExtensionRequests,
+		CustomAttributes:  raw.CustomAttributes,
+	}
+	if len(attrs.ExtensionRequests) == 0 && len(attrs.CustomAttributes) == 0 {
+		return nil, fmt.Errorf("no extension_requests or custom_attributes defined in file")
+	}
+	if err := ValidateCSRAttributes(attrs); err != nil {
+		return nil, err
+	}
+	return attrs, nil
+}
+
+// ValidateCSRAttributes checks the names and CSV columns of a mapping.
+// Extension names must be Puppet short names (pp_*) or dotted OIDs.
+func ValidateCSRAttributes(attrs *CSRAttributes) error {
+	for name, column := range attrs.ExtensionRequests {
+		if !puppetExtensionNames[name] && !csrOIDPattern.MatchString(name) {
+			return fmt.Errorf("invalid extension request %q: use a Puppet short name (pp_role, pp_environment, ...) or a dotted OID", name)
+		}
+		if err := validateCSRColumn(name, column); err != nil {
+			return err
+		}
+	}
+	for name, column := range attrs.CustomAttributes {
+		if !csrAttributeNamePattern.MatchString(name) && !csrOIDPattern.MatchString(name) {
+			return fmt.Errorf("invalid custom attribute %q: use a short name (challengePassword) or a dotted OID", name)
+		}
+		if err := validateCSRColumn(name, column); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateCSRColumn checks the CSV column mapped to a csr attribute.
+func validateCSRColumn(name, column string) error {
+	if column == "" {
+		return fmt.Errorf("csr attribute %q: CSV column is required", name)
+	}
+	if !factNamePattern.MatchString(column) {
+		return fmt.Errorf("csr attribute %q: invalid CSV column %q", name, column)
+	}
+	return nil
+}
+
+// ValidateCSRAttributeColumns returns the CSV columns referenced by the
+// mapping that are missing or empty for instance (their entries are omitted).
+func ValidateCSRAttributeColumns(attrs *CSRAttributes, instance *cloud.Instance) []string {
+	if attrs == nil {
+		return nil
+	}
+	missing := []string{}
+	seen := make(map[string]bool)
+	for _, column := range attrs.columns() {
+		if seen[column] || csvColumnValue(instance, column) != "" {
+			continue
+		}
+		seen[column] = true
+		missing = append(missing, column)
+	}
+	sort.Strings(missing)
+	return missing
+}
+
+// columns returns every CSV column referenced by the mapping.
+func (attrs *CSRAttributes) columns() []string {
+	columns := make([]string, 0, len(attrs.ExtensionRequests)+len(attrs.CustomAttributes))
+	for _, column := range attrs.ExtensionRequests {
+		columns = append(columns, column)
+	}
+	for _, column := range attrs.CustomAttributes {
+		columns = append(columns, column)
+	}
+	return columns
+}
+
+// csvColumnValue returns the CSV value of a column: account and region are
+// instance fields, other columns come from the metadata.
+func csvColumnValue(instance *cloud.Instance, column string) string {
+	switch column {
+	case "account":
+		return instance.Account
+	case "region":
+		return instance.Region
+	}
+	return instance.Metadata[column]
+}
+
+// renderCSRAttributes renders csr_attributes.yaml for instance, sorted by
+// name. Values are always quoted: Puppet expects strings. Entries with an
+// empty CSV value are omitted; returns "" when nothing is left.
+func renderCSRAttributes(attrs *CSRAttributes, instance *cloud.Instance) string {
+	var content strings.Builder
+	for _, section := range []struct {
+		name    string
+		entries map[string]string
+	}{
+		{"custom_attributes", attrs.CustomAttributes},
+		{"extension_requests", attrs.ExtensionRequests},
+	} {
+		names := make([]string, 0, len(section.entries))
+		for name, column := range section.entries {
+			if csvColumnValue(instance, column) != "" {
+				names = append(names, name)
+			}
+		}
+		if len(names) == 0 {
+			continue
+		}
+		sort.Strings(names)
+
+		content.WriteString(section.name + ":\n")
+		for _, name := range names {
+			value := csvColumnValue(instance, section.entries[name])
+			fmt.Fprintf(&content, "  %s: %s\n", name, yamlQuoted(value))
+		}
+	}
+	if content.Len() == 0 {
+		return ""
+	}
+	return "---\n" + content.String()
+}
+
+// generateCSRAttributesScript generates shell script to write
+// csr_attributes.yaml before the first agent run, so the certificate
+// request carries the trusted facts of the instance.
+//
+// The file only affects new certificate requests: when the agent already
+// has a certificate for certname, the script warns that the trusted facts
+// require regenerating it (opsmaster puppet regen-cert).
+//
+// Returns empty string if no mapping is configured.
+func (pi *PuppetInstaller) generateCSRAttributesScript(certname string, instance *cloud.Instance) string {
+	if pi.csrAttributes == nil || instance == nil {
+		return ""
+	}
+	content := renderCSRAttributes(pi.csrAttributes, instance)
+	paths := pi.repo.paths()
+	if content == "" {
+		return "# csr_attributes.yaml skipped: no CSV values for the configured extensions\n"
+	}
+
+	return fmt.Sprintf(`# ============================================================
+# Creating csr_attributes.yaml (trusted facts) from CSV data
+# ============================================================
+echo "Creating csr_attributes.yaml..."
+mkdir -p %[4]s
+cat > %[1]s << 'CSR_ATTRIBUTES_EOF'
+%[2]sCSR_ATTRIBUTES_EOF
+chmod 640 %[1]s
+echo "  ✓ Created %[1]s"
+if [ -f %[5]s/certs/%[3]s.pem ]; then
+    echo "  ⚠ Certificate for %[3]s already exists - trusted facts only apply to a new certificate request"
+fi
+`, paths.confDir+"/csr_attributes.yaml", content, certname, paths.confDir, paths.sslDir)
+}
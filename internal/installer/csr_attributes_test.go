@@ -0,0 +1,169 @@
+package installer
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/estudosdevops/opsmaster/internal/cloud"
+)
+
+func TestLoadCSRAttributesFromYAML(t *testing.T) {
+	tests := []struct {
+		name        string
+		content     string
+		expectError string
+	}{
+		{
+			name: "extension requests and custom attributes",
+			content: `extension_requests:
+  pp_role: role
+  pp_environment: environment
+  1.3.6.1.4.1.34380.1.2.1: team
+custom_attributes:
+  challengePassword: autosign_token
+`,
+		},
+		{name: "empty file", content: "{}\n", expectError: "no extension_requests"},
+		{name: "unknown section", content: "extensions:\n  pp_role: role\n", expectError: "field extensions not found"},
+		{name: "unknown pp name", content: "extension_requests:\n  pp_rol: role\n", expectError: `invalid extension request "pp_rol"`},
+		{name: "invalid attribute", content: "custom_attributes:\n  \"challenge password\": token\n", expectError: "invalid custom attribute"},
+		{name: "invalid column", content: "extension_requests:\n  pp_role: \"ro le\"\n", expectError: "invalid CSV column"},
+		{name: "missing column", content: "extension_requests:\n  pp_role: \"\"\n", expectError: "CSV column is required"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// ARRANGE
+			path, cleanup := createTempYAMLFile(t, tt.content)
+			defer cleanup()
+
+			// ACT
+			attrs, err := LoadCSRAttributesFromYAML(path)
+
+			// ASSERT
+			if tt.expectError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectError) {
+					t.Fatalf("error = %v, want containing %q", err, tt.expectError)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(attrs.ExtensionRequests) != 3 || attrs.CustomAttributes["challengePassword"] != "autosign_token" {
+				t.Errorf("unexpected mapping: %+v", attrs)
+			}
+		})
+	}
+}
+
+func TestValidateCSRAttributeColumns(t *testing.T) {
+	attrs := &CSRAttributes{
+		ExtensionRequests: map[string]string{"pp_role": "role", "pp_region": "region", "pp_zone": "zone"},
+		CustomAttributes:  map[string]string{"challengePassword": "zone"},
+	}
+	instance := &cloud.Instance{Region: "us-east-1", Metadata: map[string]string{"role": "web"}}
+
+	missing := ValidateCSRAttributeColumns(attrs, instance)
+
+	if len(missing) != 1 || missing[0] != "zone" {
+		t.Errorf("missing = %v, want [zone]", missing)
+	}
+}
+
+func TestGenerateCSRAttributesScript(t *testing.T) {
+	pi := NewPuppetInstaller(PuppetOptions{
+		Server: "puppet.example.com",
+		CSRAttributes: &CSRAttributes{
+			ExtensionRequests: map[string]string{
+				"pp_role":        "role",
+				"pp_environment": "environment",
+				"pp_region":      "region",
+				"pp_cluster":     "cluster",
+			},
+		},
+	})
+	instance := &cloud.Instance{
+		Region:   "us-east-1",
+		Metadata: map[string]string{"role": "web", "environment": "42"},
+	}
+
+	script := pi.generateCSRAttributesScript("web01.example.com", instance)
+
+	for _, want := range []string{
+		"cat > /etc/puppetlabs/puppet/csr_attributes.yaml << 'CSR_ATTRIBUTES_EOF'\n---\nextension_requests:\n" +
+			"  pp_environment: \"42\"\n  pp_region: \"us-east-1\"\n  pp_role: \"web\"\nCSR_ATTRIBUTES_EOF\n",
+		"chmod 640 /etc/puppetlabs/puppet/csr_attributes.yaml",
+		"/etc/puppetlabs/puppet/ssl/certs/web01.example.com.pem",
+	} {
+		if !strings.Contains(script, want) {
+			t.Errorf("script missing %q:\n%s", want, script)
+		}
+	}
+	if strings.Contains(script, "pp_cluster") {
+		t.Error("extensions with empty CSV values should be omitted")
+	}
+
+	// Without any value the file is not written
+	script = pi.generateCSRAttributesScript("web01.example.com", &cloud.Instance{})
+	if strings.Contains(script, "cat >") {
+		t.Errorf("expected no csr_attributes.yaml without values, got:\n%s", script)
+	}
+
+	// Disabled by default
+	if got := NewPuppetInstaller(PuppetOptions{}).generateCSRAttributesScript("web01", instance); got != "" {
+		t.Errorf("expected empty script without mapping, got:\n%s", got)
+	}
+}
+
+// TestGenerateCSRAttributesScript_HostileCSV runs the generated script and
+// checks that hostile CSV values round-trip as YAML strings.
+func TestGenerateCSRAttributesScript_HostileCSV(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+
+	hostile := "x\" $(touch pwned) `id` 'q'\nCSR_ATTRIBUTES_EOF"
+	pi := NewPuppetInstaller(PuppetOptions{
+		Server:        "puppet.example.com",
+		CSRAttributes: &CSRAttributes{ExtensionRequests: map[string]string{"pp_role": "role"}},
+	})
+	instance := &cloud.Instance{Metadata: map[string]string{"role": hostile}}
+
+	// Control characters are rejected before any script is generated
+	if err := pi.checkScriptInputs("web01", instance); err == nil || !strings.Contains(err.Error(), "CSV column role") {
+		t.Fatalf("expected newline in CSV value to be rejected, got %v", err)
+	}
+
+	// Without the newline the value is written verbatim
+	instance.Metadata["role"] = strings.ReplaceAll(hostile, "\n", " ")
+	dir := t.TempDir()
+	script := strings.ReplaceAll(pi.generateCSRAttributesScript("web01", instance), "/etc/puppetlabs/puppet", dir)
+	cmd := exec.Command("sh", "-c", script)
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("script failed: %v\n%s", err, out)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "pwned")); err == nil {
+		t.Fatal("CSV value was executed by the shell")
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "csr_attributes.yaml"))
+	if err != nil {
+		t.Fatalf("csr_attributes.yaml not written: %v", err)
+	}
+	var parsed struct {
+		ExtensionRequests map[string]string `yaml:"extension_requests"`
+	}
+	if err := yaml.Unmarshal(data, &parsed); err != nil {
+		t.Fatalf("invalid YAML: %v\n%s", err, data)
+	}
+	if parsed.ExtensionRequests["pp_role"] != instance.Metadata["role"] {
+		t.Errorf("pp_role = %q, want %q", parsed.ExtensionRequests["pp_role"], instance.Metadata["role"])
+	}
+}
@@ -0,0 +1,41 @@
+package installer
+
+import "fmt"
+
+// DiagnosticLines is how many trailing lines of each log file are included
+// in the diagnostic bundle.
+const DiagnosticLines = 50
+
+// packageManagerLogs are the apt/dpkg and yum/dnf logs tailed in every
+// diagnostic bundle; missing files are skipped on the instance.
+var packageManagerLogs = []string{
+	"/var/log/apt/term.log",
+	"/var/log/dpkg.log",
+	"/var/log/yum.log",
+	"/var/log/dnf.log",
+}
+
+// DiagnosticScript returns the commands of the diagnostic bundle collected
+// from instances after a failed installation: disk and memory usage plus the
+// tail of the package manager logs and of the installer logs (see
+// DiagnosticsProvider). Every section is printed under a "=== <command> ==="
+// header and never fails the script, so one missing tool doesn't hide the
+// rest of the bundle.
+func DiagnosticScript(logs []string) []string {
+	commands := []string{
+		`diag() { echo "=== $* ==="; "$@" 2>&1 || true; echo; }`,
+		"diag df -h",
+		"diag free -m",
+	}
+	for _, path := range append(append([]string{}, logs...), packageManagerLogs...) {
+		commands = append(commands, fmt.Sprintf("[ -r %s ] && diag tail -n %d %s", shellQuote(path), DiagnosticLines, shellQuote(path)))
+	}
+	// The last test may be false: the bundle itself always succeeds
+	return append(commands, "true")
+}
+
+// DiagnosticLogs implements DiagnosticsProvider: the agent log, written when
+// puppet.conf logs to a file instead of syslog.
+func (pi *PuppetInstaller) DiagnosticLogs() []string {
+	return []string{pi.repo.paths().logFile}
+}
@@ -0,0 +1,51 @@
+package installer
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestDiagnosticScript tests the commands of the diagnostic bundle.
+func TestDiagnosticScript(t *testing.T) {
+	commands := DiagnosticScript([]string{"/var/log/app dir/app.log"})
+	script := strings.Join(commands, "\n")
+
+	for _, want := range []string{
+		"diag df -h",
+		"diag free -m",
+		"[ -r '/var/log/app dir/app.log' ] && diag tail -n 50 '/var/log/app dir/app.log'",
+		"[ -r '/var/log/dpkg.log' ] && diag tail -n 50 '/var/log/dpkg.log'",
+		"[ -r '/var/log/yum.log' ] && diag tail -n 50 '/var/log/yum.log'",
+	} {
+		if !strings.Contains(script, want) {
+			t.Errorf("DiagnosticScript() missing %q", want)
+		}
+	}
+	if strings.Index(script, "app.log") > strings.Index(script, "dpkg.log") {
+		t.Error("Installer logs should come before the package manager logs")
+	}
+	if commands[len(commands)-1] != "true" {
+		t.Errorf("DiagnosticScript() should end with true, got %q", commands[len(commands)-1])
+	}
+}
+
+// TestPuppetInstaller_DiagnosticLogs tests the agent log per package source.
+func TestPuppetInstaller_DiagnosticLogs(t *testing.T) {
+	tests := []struct {
+		source string
+		want   string
+	}{
+		{RepoSourcePuppetlabs, "/var/log/puppetlabs/puppet/puppet.log"},
+		{RepoSourceDistro, "/var/log/puppet/puppet.log"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.source, func(t *testing.T) {
+			pi := NewPuppetInstaller(PuppetOptions{Server: "puppet.example.com", Repo: PuppetRepoOptions{Source: tt.source}})
+			logs := pi.DiagnosticLogs()
+			if len(logs) != 1 || logs[0] != tt.want {
+				t.Errorf("DiagnosticLogs() = %v, want [%s]", logs, tt.want)
+			}
+		})
+	}
+}
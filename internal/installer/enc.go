@@ -0,0 +1,164 @@
+package installer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"text/template"
+
+	"github.com/estudosdevops/opsmaster/internal/cloud"
+	"github.com/estudosdevops/opsmaster/internal/httpclient"
+)
+
+// maxENCErrorBody limits how much of an ENC error response is kept in errors.
+const maxENCErrorBody = 512
+
+// ENCConfig configures node registration in an external node classifier
+// (ENC) or CMDB after a successful installation.
+type ENCConfig struct {
+	URL             string       // Endpoint receiving the registration (HTTP POST)
+	PayloadTemplate string       // Go text/template for the body (default: ENCNode as JSON)
+	Token           string       // Optional bearer token
+	Client          *http.Client // HTTP client (default: httpclient.Shared())
+}
+
+// ENCNode is the data available to payload templates.
+// Without a template it is sent as JSON.
+type ENCNode struct {
+	Certname    string            `json:"certname"`
+	Environment string            `json:"environment"`
+	Server      string            `json:"puppet_server"`
+	InstanceID  string            `json:"instance_id"`
+	Account     string            `json:"account"`
+	Region      string            `json:"region"`
+	OS          string            `json:"os,omitempty"`
+	Metadata    map[string]string `json:"metadata,omitempty"` // CSV columns of the instance
+	RunID       string            `json:"run_id,omitempty"`   // Invocation that installed the node
+}
+
+// ENCRegistrar registers installed nodes in an ENC/CMDB.
+// Safe for concurrent use.
+type ENCRegistrar struct {
+	config   ENCConfig
+	template *template.Template // nil = JSON of ENCNode
+}
+
+// NewENCRegistrar validates the configuration and parses the payload template.
+func NewENCRegistrar(config ENCConfig) (*ENCRegistrar, error) {
+	parsed, err := url.Parse(config.URL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return nil, fmt.Errorf("invalid ENC registration URL %q: must be an http(s) URL", config.URL)
+	}
+
+	registrar := &ENCRegistrar{config: config}
+	if config.PayloadTemplate != "" {
+		tmpl, err := template.New("enc-payload").
+			Funcs(template.FuncMap{"json": toJSON}).
+			Option("missingkey=zero").
+			Parse(config.PayloadTemplate)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ENC payload template: %w", err)
+		}
+		registrar.template = tmpl
+	}
+
+	return registrar, nil
+}
+
+// toJSON is the "json" template function, quoting values safely
+// (e.g., {"name": {{ json .Certname }}}).
+func toJSON(value any) (string, error) {
+	data, err := json.Marshal(value)
+	return string(data), err
+}
+
+// Payload renders the request body for a node.
+func (r *ENCRegistrar) Payload(node ENCNode) ([]byte, error) {
+	if r.template == nil {
+		return json.Marshal(node)
+	}
+
+	var buf bytes.Buffer
+	if err := r.template.Execute(&buf, node); err != nil {
+		return nil, fmt.Errorf("failed to render ENC payload: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// Register sends the node registration. Any non-2xx response is an error.
+func (r *ENCRegistrar) Register(ctx context.Context, node ENCNode) error {
+	body, err := r.Payload(node)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.config.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create ENC request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if r.config.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+r.config.Token)
+	}
+
+	client := r.config.Client
+	if client == nil {
+		client = httpclient.Shared()
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("ENC registration request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, maxENCErrorBody))
+		return fmt.Errorf("ENC returned %d for %s: %s", resp.StatusCode, node.Certname, strings.TrimSpace(string(detail)))
+	}
+
+	return nil
+}
+
+// AfterInstall implements PostInstallHook: registers the node in the ENC
+// and/or Foreman (when configured) so classification exists before the
+// next agent run. Every configured target is attempted; errors are joined.
+func (pi *PuppetInstaller) AfterInstall(ctx context.Context, instance *cloud.Instance, metadata *InstallMetadata) error {
+	if pi.enc == nil && pi.foreman == nil {
+		return nil
+	}
+
+	node := ENCNode{
+		Certname:    metadata.Get(MetadataKeyCertname),
+		Environment: pi.environment,
+		Server:      pi.ServerFor(instance),
+		InstanceID:  instance.ID,
+		Account:     instance.Account,
+		Region:      instance.Region,
+		OS:          metadata.Get(MetadataKeyOS),
+		Metadata:    instance.Metadata,
+		RunID:       pi.runID,
+	}
+	if node.Certname == "" {
+		return fmt.Errorf("cannot register node: certname unknown")
+	}
+
+	var errs []error
+	if pi.enc != nil {
+		if err := pi.enc.Register(ctx, node); err != nil {
+			errs = append(errs, fmt.Errorf("ENC registration failed: %w", err))
+		}
+	}
+	if pi.foreman != nil {
+		if err := pi.foreman.Register(ctx, node); err != nil {
+			errs = append(errs, fmt.Errorf("foreman registration failed: %w", err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,125 @@
+package installer
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/estudosdevops/opsmaster/internal/cloud"
+)
+
+// TestNewENCRegistrar tests validation of ENC settings
+func TestNewENCRegistrar(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  ENCConfig
+		wantErr bool
+	}{
+		{name: "https URL", config: ENCConfig{URL: "https://cmdb.example.com/nodes"}, wantErr: false},
+		{name: "missing scheme", config: ENCConfig{URL: "cmdb.example.com/nodes"}, wantErr: true},
+		{name: "unsupported scheme", config: ENCConfig{URL: "ftp://cmdb.example.com"}, wantErr: true},
+		{name: "valid template", config: ENCConfig{URL: "http://enc", PayloadTemplate: `{"name": {{ json .Certname }}}`}, wantErr: false},
+		{name: "broken template", config: ENCConfig{URL: "http://enc", PayloadTemplate: `{{ .Certname `}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewENCRegistrar(tt.config)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewENCRegistrar() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestAfterInstall_RegistersNode tests the ENC request sent after installation.
+//
+// 🎓 CONCEPT: httptest.Server
+// A local HTTP server captures the request, so no real ENC is needed.
+func TestAfterInstall_RegistersNode(t *testing.T) {
+	// ARRANGE
+	var gotBody []byte
+	var gotAuth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotBody, _ = io.ReadAll(r.Body)
+		gotAuth = r.Header.Get("Authorization")
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	registrar, err := NewENCRegistrar(ENCConfig{URL: server.URL, Token: "s3cr3t", Client: server.Client()})
+	if err != nil {
+		t.Fatalf("NewENCRegistrar() error: %v", err)
+	}
+	installer := NewPuppetInstaller(PuppetOptions{Server: "puppet.example.com", Environment: "staging", ENC: registrar, RunID: "run-123"})
+	instance := &cloud.Instance{ID: "i-abc", Account: "111111111111", Region: "us-east-1", Metadata: map[string]string{"role": "web"}}
+
+	// ACT
+	err = installer.AfterInstall(context.Background(), instance, &InstallMetadata{Certname: "abc.puppet", OS: "debian"})
+
+	// ASSERT
+	if err != nil {
+		t.Fatalf("AfterInstall() error: %v", err)
+	}
+	if gotAuth != "Bearer s3cr3t" {
+		t.Errorf("Authorization = %q, want bearer token", gotAuth)
+	}
+	var node ENCNode
+	if err := json.Unmarshal(gotBody, &node); err != nil {
+		t.Fatalf("payload is not JSON: %v\n%s", err, gotBody)
+	}
+	if node.Certname != "abc.puppet" || node.Environment != "staging" || node.Server != "puppet.example.com" || node.Metadata["role"] != "web" || node.RunID != "run-123" {
+		t.Errorf("unexpected payload: %+v", node)
+	}
+}
+
+// TestENCRegistrar_Template tests templated payloads and error responses
+func TestENCRegistrar_Template(t *testing.T) {
+	var gotBody string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		gotBody = string(body)
+		if strings.Contains(gotBody, "reject") {
+			http.Error(w, "duplicate node", http.StatusConflict)
+		}
+	}))
+	defer server.Close()
+
+	registrar, err := NewENCRegistrar(ENCConfig{
+		URL:             server.URL,
+		PayloadTemplate: `{"host": {{ json .Certname }}, "role": {{ json (index .Metadata "role") }}}`,
+		Client:          server.Client(),
+	})
+	if err != nil {
+		t.Fatalf("NewENCRegistrar() error: %v", err)
+	}
+
+	t.Run("renders template", func(t *testing.T) {
+		err := registrar.Register(context.Background(), ENCNode{Certname: "abc.puppet", Metadata: map[string]string{"role": "db"}})
+		if err != nil {
+			t.Fatalf("Register() error: %v", err)
+		}
+		if gotBody != `{"host": "abc.puppet", "role": "db"}` {
+			t.Errorf("payload = %s", gotBody)
+		}
+	})
+
+	t.Run("non-2xx is an error", func(t *testing.T) {
+		err := registrar.Register(context.Background(), ENCNode{Certname: "reject.puppet"})
+		if err == nil || !strings.Contains(err.Error(), "409") || !strings.Contains(err.Error(), "duplicate node") {
+			t.Errorf("Register() error = %v, want 409 with response detail", err)
+		}
+	})
+}
+
+// TestAfterInstall_Disabled tests that nothing happens without ENC settings
+func TestAfterInstall_Disabled(t *testing.T) {
+	installer := NewPuppetInstaller(PuppetOptions{Server: "puppet.example.com"})
+	if err := installer.AfterInstall(context.Background(), &cloud.Instance{ID: "i-abc"}, nil); err != nil {
+		t.Errorf("AfterInstall() without ENC = %v, want nil", err)
+	}
+}
@@ -0,0 +1,101 @@
+package installer
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Values interpolated into generated scripts come from the CSV inventory,
+// flags and the instances themselves (e.g., an existing certname). Each one
+// is validated for its context and rendered so that it can't end a heredoc,
+// expand in the shell or change the structure of a config file.
+
+var (
+	// hostnamePattern matches DNS names and IPv4 addresses.
+	hostnamePattern = regexp.MustCompile(`^[A-Za-z0-9]([A-Za-z0-9.-]*[A-Za-z0-9])?$`)
+
+	// puppetEnvironmentPattern matches Puppet environment names.
+	puppetEnvironmentPattern = regexp.MustCompile(`^[A-Za-z0-9_]+$`)
+
+	// factNamePattern matches fact names and fact field keys.
+	factNamePattern = regexp.MustCompile(`^[A-Za-z0-9_]+$`)
+
+	// factFilePattern matches fact file names (inside facts.d, no paths).
+	factFilePattern = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9_.-]*\.(yaml|yml)$`)
+
+	// yamlPlainPattern matches values safe as YAML plain scalars. They are
+	// written unquoted so facts keep their YAML types (numbers, booleans).
+	yamlPlainPattern = regexp.MustCompile(`^[A-Za-z0-9_./][A-Za-z0-9_./@+-]*$`)
+)
+
+// checkScriptValue rejects values that can't be rendered safely in a
+// generated script: control characters (newlines, NUL, terminal escapes).
+func checkScriptValue(field, value string) error {
+	for _, r := range value {
+		if unicode.IsControl(r) {
+			return fmt.Errorf("invalid %s %q: control characters are not allowed", field, value)
+		}
+	}
+	return nil
+}
+
+// shellQuote quotes value as a single shell word.
+func shellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
+
+// yamlScalar renders value as a YAML scalar on a single line: plain when
+// safe, otherwise double-quoted with control characters escaped.
+func yamlScalar(value string) string {
+	if yamlPlainPattern.MatchString(value) {
+		return value
+	}
+	return yamlQuoted(value)
+}
+
+// yamlQuoted renders value as a double-quoted YAML string on a single line,
+// for values that must stay strings (e.g., certificate extension requests).
+func yamlQuoted(value string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range value {
+		switch {
+		case r == '"' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case unicode.IsControl(r):
+			fmt.Fprintf(&b, `\u%04X`, r)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// ValidatePuppetEnvironment checks a Puppet environment name.
+func ValidatePuppetEnvironment(environment string) error {
+	if !puppetEnvironmentPattern.MatchString(environment) {
+		return fmt.Errorf("invalid puppet environment %q: use letters, digits and '_'", environment)
+	}
+	return nil
+}
+
+// ValidateFactDefinition checks the names a fact definition writes into the
+// install script (file name, fact name and field keys).
+func ValidateFactDefinition(def FactDefinition) error {
+	if !factFilePattern.MatchString(def.FilePath) {
+		return fmt.Errorf("invalid fact file_path %q: use a .yaml file name without directories", def.FilePath)
+	}
+	if !factNamePattern.MatchString(def.FactName) {
+		return fmt.Errorf("invalid fact_name %q: use letters, digits and '_'", def.FactName)
+	}
+	for column, field := range def.Fields {
+		if !factNamePattern.MatchString(field) {
+			return fmt.Errorf("invalid field %q (column %s) in fact %s: use letters, digits and '_'", field, column, def.FactName)
+		}
+	}
+	return nil
+}
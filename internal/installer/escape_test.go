@@ -0,0 +1,165 @@
+package installer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/estudosdevops/opsmaster/internal/cloud"
+)
+
+// hostileValues are CSV values that break or inject into naive scripts.
+var hostileValues = []string{
+	"$(touch /tmp/opsmaster-pwned)",
+	"`id`",
+	"EOF",
+	"FACT_EOF_app",
+	"'; rm -rf / #",
+	`say "hi" \ bye`,
+	"key: value # comment",
+	"- item",
+	"@handle",
+	"{json: true}",
+	"production",
+	"42",
+}
+
+// TestYAMLScalar tests that rendered values parse back to the original string.
+func TestYAMLScalar(t *testing.T) {
+	values := append(hostileValues, "line1\nFACT_EOF_app\nrm -rf /", "tab\there", "esc\x1b[31m")
+	for _, value := range values {
+		t.Run(value, func(t *testing.T) {
+			rendered := yamlScalar(value)
+			if strings.ContainsAny(rendered, "\n\r") {
+				t.Fatalf("yamlScalar(%q) = %q spans lines", value, rendered)
+			}
+
+			var parsed map[string]any
+			if err := yaml.Unmarshal([]byte("field: "+rendered+"\n"), &parsed); err != nil {
+				t.Fatalf("yamlScalar(%q) = %q is invalid YAML: %v", value, rendered, err)
+			}
+			// Plain numbers keep their YAML type; everything else is a string
+			if got, ok := parsed["field"].(string); ok && got != value {
+				t.Errorf("yamlScalar(%q) parsed as %q", value, got)
+			}
+		})
+	}
+}
+
+// TestShellQuote tests that quoted values reach the command unchanged.
+func TestShellQuote(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+	for _, value := range hostileValues {
+		out, err := exec.Command("sh", "-c", "printf '%s' "+shellQuote(value)).Output()
+		if err != nil {
+			t.Fatalf("sh failed for %q: %v", value, err)
+		}
+		if string(out) != value {
+			t.Errorf("shellQuote(%q) printed %q", value, out)
+		}
+	}
+}
+
+// TestGenerateFactsScript_HostileCSV runs the generated facts section with
+// hostile CSV values and checks the fact files hold them verbatim.
+func TestGenerateFactsScript_HostileCSV(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+
+	for i, value := range hostileValues {
+		// Indexed names: the subtest name is part of the temp dir path
+		t.Run(fmt.Sprintf("value %d", i), func(t *testing.T) {
+			// ARRANGE
+			pi := NewPuppetInstaller(PuppetOptions{
+				Server:      "puppet.example.com",
+				CustomFacts: map[string]FactDefinition{"app": {FilePath: "app.yaml", FactName: "app", Fields: map[string]string{"owner": "owner"}}},
+			})
+			instance := &cloud.Instance{ID: "i-1", Metadata: map[string]string{"owner": value}}
+			if err := pi.checkScriptInputs("node.puppet", instance); err != nil {
+				t.Fatalf("checkScriptInputs() unexpected error: %v", err)
+			}
+			dir := t.TempDir()
+			script := strings.ReplaceAll(pi.generateFactsScript(instance), "/opt/puppetlabs/facter/facts.d", dir)
+
+			// ACT
+			if out, err := exec.Command("sh", "-c", script).CombinedOutput(); err != nil {
+				t.Fatalf("facts script failed: %v\n%s", err, out)
+			}
+
+			// ASSERT
+			data, err := os.ReadFile(filepath.Join(dir, "app.yaml"))
+			if err != nil {
+				t.Fatalf("fact file not written: %v", err)
+			}
+			var facts map[string]map[string]any
+			if err := yaml.Unmarshal(data, &facts); err != nil {
+				t.Fatalf("fact file is invalid YAML: %v\n%s", err, data)
+			}
+			if got, ok := facts["app"]["owner"].(string); ok && got != value {
+				t.Errorf("fact owner = %q, want %q", got, value)
+			}
+			if _, err := os.Stat("/tmp/opsmaster-pwned"); err == nil {
+				t.Error("CSV value was executed by the script")
+			}
+		})
+	}
+}
+
+// TestCheckScriptInputs tests rejection of values unsafe for the install script.
+func TestCheckScriptInputs(t *testing.T) {
+	facts := map[string]FactDefinition{"app": {FilePath: "app.yaml", FactName: "app", Fields: map[string]string{"owner": "owner"}}}
+	tests := []struct {
+		name     string
+		opts     PuppetOptions
+		certname string
+		metadata map[string]string
+		wantErr  string
+	}{
+		{name: "valid", opts: PuppetOptions{Server: "puppet.example.com", CustomFacts: facts}, certname: "web-01.puppet", metadata: map[string]string{"owner": "team a"}},
+		{name: "newline in fact value", opts: PuppetOptions{Server: "puppet.example.com", CustomFacts: facts}, certname: "web-01.puppet",
+			metadata: map[string]string{"owner": "x\nFACT_EOF_app\nreboot"}, wantErr: "control characters"},
+		{name: "server from CSV", opts: PuppetOptions{Server: "puppet.example.com"}, certname: "web-01.puppet",
+			metadata: map[string]string{PuppetServerColumn: "puppet;reboot"}, wantErr: "invalid puppet server"},
+		{name: "environment", opts: PuppetOptions{Server: "puppet.example.com", Environment: "prod$(id)"}, certname: "web-01.puppet", wantErr: "invalid puppet environment"},
+		{name: "certname from instance", opts: PuppetOptions{Server: "puppet.example.com"}, certname: "web`id`", wantErr: "invalid certname"},
+		{name: "fact file path", opts: PuppetOptions{Server: "puppet.example.com",
+			CustomFacts: map[string]FactDefinition{"x": {FilePath: "../../etc/cron.d/x.yaml", FactName: "x", Fields: map[string]string{"a": "a"}}}},
+			certname: "web-01.puppet", wantErr: "invalid fact file_path"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := NewPuppetInstaller(tt.opts).checkScriptInputs(tt.certname, &cloud.Instance{ID: "i-1", Metadata: tt.metadata})
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("checkScriptInputs() unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("checkScriptInputs() = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestGenerateInstallScriptWithAutoDetect_HostileCertname tests that an
+// unexpected certname read from the instance is not rendered into the script.
+func TestGenerateInstallScriptWithAutoDetect_HostileCertname(t *testing.T) {
+	provider := createMockProviderWithCertnameResponse("$(reboot)", false)
+	pi := NewPuppetInstaller(PuppetOptions{Server: "puppet.example.com"})
+
+	_, _, err := pi.GenerateInstallScriptWithAutoDetect(context.Background(), createTestInstance(), provider, nil)
+	if err == nil || !strings.Contains(err.Error(), "invalid certname") {
+		t.Errorf("GenerateInstallScriptWithAutoDetect() = %v, want invalid certname error", err)
+	}
+}
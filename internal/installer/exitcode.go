@@ -0,0 +1,79 @@
+package installer
+
+import "fmt"
+
+// Exit codes of generated install scripts, shared by all installers so the
+// executor can tell which phase failed on the instance without knowing the
+// installer:
+//
+//	0   success
+//	10  validation (unsupported OS/release, missing prerequisite or input)
+//	20  download (repository, key, package index or binary download)
+//	30  install (package installation)
+//	40  configure (configuration files, certname, service setup)
+//	50  verify (checks performed by the script itself)
+//
+// Any other non-zero code (e.g., 1 from an unexpected command or 126/127
+// from the shell) is unclassified. Verification commands run by
+// VerifyInstallation keep their own small codes, interpreted by each installer.
+const (
+	ExitCodeSuccess    = 0
+	ExitCodeValidation = 10
+	ExitCodeDownload   = 20
+	ExitCodeInstall    = 30
+	ExitCodeConfigure  = 40
+	ExitCodeVerify     = 50
+)
+
+// Script failure phases, as reported for failed instances.
+const (
+	ScriptPhaseValidation = "validation"
+	ScriptPhaseDownload   = "download"
+	ScriptPhaseInstall    = "install"
+	ScriptPhaseConfigure  = "configure"
+	ScriptPhaseVerify     = "verify"
+)
+
+// ScriptFailurePhase returns the phase a script exit code stands for, or ""
+// for success and unclassified codes.
+func ScriptFailurePhase(exitCode int) string {
+	switch exitCode {
+	case ExitCodeValidation:
+		return ScriptPhaseValidation
+	case ExitCodeDownload:
+		return ScriptPhaseDownload
+	case ExitCodeInstall:
+		return ScriptPhaseInstall
+	case ExitCodeConfigure:
+		return ScriptPhaseConfigure
+	case ExitCodeVerify:
+		return ScriptPhaseVerify
+	default:
+		return ""
+	}
+}
+
+// ScriptError is returned when a generated script (or install step) exits
+// with a non-zero code. Callers use errors.As to classify the failure.
+type ScriptError struct {
+	Step     string // Install step name ("" for single-script installers)
+	ExitCode int    // Exit code of the script
+	Stdout   string
+	Stderr   string
+}
+
+// Phase returns the failure phase of the exit code ("" if unclassified).
+func (e *ScriptError) Phase() string {
+	return ScriptFailurePhase(e.ExitCode)
+}
+
+func (e *ScriptError) Error() string {
+	code := fmt.Sprintf("exit code %d", e.ExitCode)
+	if phase := e.Phase(); phase != "" {
+		code += " (" + phase + ")"
+	}
+	if e.Step != "" {
+		return fmt.Sprintf("install step %q failed with %s:\nstdout: %s\nstderr: %s", e.Step, code, e.Stdout, e.Stderr)
+	}
+	return fmt.Sprintf("installation script failed with %s:\nstdout: %s\nstderr: %s", code, e.Stdout, e.Stderr)
+}
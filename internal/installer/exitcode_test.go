@@ -0,0 +1,100 @@
+package installer
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// TestScriptFailurePhase tests the shared exit code convention.
+func TestScriptFailurePhase(t *testing.T) {
+	tests := []struct {
+		exitCode int
+		expected string
+	}{
+		{ExitCodeSuccess, ""},
+		{ExitCodeValidation, ScriptPhaseValidation},
+		{ExitCodeDownload, ScriptPhaseDownload},
+		{ExitCodeInstall, ScriptPhaseInstall},
+		{ExitCodeConfigure, ScriptPhaseConfigure},
+		{ExitCodeVerify, ScriptPhaseVerify},
+		{1, ""},
+		{127, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.exitCode), func(t *testing.T) {
+			if got := ScriptFailurePhase(tt.exitCode); got != tt.expected {
+				t.Errorf("ScriptFailurePhase(%d) = %q, want %q", tt.exitCode, got, tt.expected)
+			}
+		})
+	}
+}
+
+// TestScriptError tests error messages and classification through wrapping.
+func TestScriptError(t *testing.T) {
+	err := fmt.Errorf("installation failed: %w", &ScriptError{Step: "install-package", ExitCode: ExitCodeDownload, Stderr: "404"})
+	if !strings.Contains(err.Error(), `install step "install-package" failed with exit code 20 (download):`) {
+		t.Errorf("unexpected message %q", err)
+	}
+
+	var scriptErr *ScriptError
+	if !errors.As(err, &scriptErr) || scriptErr.Phase() != ScriptPhaseDownload {
+		t.Errorf("expected download ScriptError, got %#v", err)
+	}
+
+	unclassified := &ScriptError{ExitCode: 1, Stdout: "out"}
+	if got := unclassified.Error(); !strings.HasPrefix(got, "installation script failed with exit code 1:\n") {
+		t.Errorf("unexpected message %q", got)
+	}
+}
+
+// scriptExitPattern matches explicit exit codes in generated scripts.
+var scriptExitPattern = regexp.MustCompile(`\bexit (\d+)`)
+
+// TestGeneratedScripts_UseExitCodeConvention tests that generated install
+// scripts only exit with codes of the shared convention.
+func TestGeneratedScripts_UseExitCodeConvention(t *testing.T) {
+	scripts := map[string]func() ([]string, error){
+		"puppet debian": func() ([]string, error) {
+			return NewPuppetInstaller(PuppetOptions{Server: "puppet.example.com"}).GenerateInstallScript("debian", nil)
+		},
+		"puppet rhel mirror": func() ([]string, error) {
+			return NewPuppetInstaller(PuppetOptions{Server: "puppet.example.com", Repo: PuppetRepoOptions{
+				YumURL: "https://mirror/yum", GPGKeyURL: "https://mirror/key", GPGFingerprint: "D6811ED3ADEEB8441AF5AA8F4528B6CD9E61EF26",
+			}}).GenerateInstallScript("rhel", nil)
+		},
+		"fluent-bit": func() ([]string, error) {
+			return NewFluentBitInstaller(FluentBitOptions{Output: "loki", Host: "loki.internal"}).GenerateInstallScript("rhel", nil)
+		},
+		"osquery": func() ([]string, error) {
+			return NewOsqueryInstaller(OsqueryOptions{FleetURL: "fleet.internal:443", EnrollSecret: "s"}).GenerateInstallScript("debian", nil)
+		},
+		"systemd-unit": func() ([]string, error) {
+			return NewSystemdUnitInstaller(SystemdUnitOptions{
+				UnitName: "my-agent.service", UnitContent: testUnit, BinaryURL: "https://example.com/my-agent", BinarySHA256: strings.Repeat("ab", 32),
+			}).GenerateInstallScript("", nil)
+		},
+		"teleport": func() ([]string, error) {
+			return NewTeleportInstaller(TeleportOptions{ProxyServer: "p:443", Version: "16", JoinToken: "t"}).GenerateInstallScript("debian", nil)
+		},
+	}
+
+	for name, generate := range scripts {
+		t.Run(name, func(t *testing.T) {
+			commands, err := generate()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for _, match := range scriptExitPattern.FindAllStringSubmatch(strings.Join(commands, "\n"), -1) {
+				code, _ := strconv.Atoi(match[1])
+				if code != ExitCodeSuccess && ScriptFailurePhase(code) == "" {
+					t.Errorf("exit code outside the convention: %q", match[0])
+				}
+			}
+		})
+	}
+}
@@ -4,12 +4,21 @@ import (
 	"fmt"
 	"log/slog"
 	"os"
+	"slices"
+	"sort"
+	"strings"
 
 	"gopkg.in/yaml.v3"
 
 	"github.com/estudosdevops/opsmaster/internal/cloud"
+	"github.com/estudosdevops/opsmaster/internal/csv"
 )
 
+// FactsManifestFile lists, inside facts.d, the fact files written by
+// opsmaster (one name per line), so --prune-facts can remove those no longer
+// declared without touching files managed by other tools.
+const FactsManifestFile = ".opsmaster-manifest"
+
 // GetDefaultCustomFacts returns default custom facts configuration.
 // Creates a location.yaml fact with standard fields from CSV.
 //
@@ -56,6 +65,8 @@ func GetDefaultCustomFacts() map[string]FactDefinition {
 //	compliance:
 //	  file_path: "compliance.yaml"
 //	  fact_name: "compliance"
+//	  environments: ["prod", "staging"]  # only these environments (optional)
+//	  when: ["tier!=bastion"]            # only matching instances, --where syntax (optional)
 //	  fields:
 //	    compliance_level: "level"
 //	    data_classification: "classification"
@@ -75,9 +86,11 @@ func LoadCustomFactsFromYAML(filepath string) (map[string]FactDefinition, error)
 
 	// Parse YAML into intermediate structure
 	var rawFacts map[string]struct {
-		FilePath string            `yaml:"file_path"`
-		FactName string            `yaml:"fact_name"`
-		Fields   map[string]string `yaml:"fields"`
+		FilePath     string            `yaml:"file_path"`
+		FactName     string            `yaml:"fact_name"`
+		Fields       map[string]string `yaml:"fields"`
+		Environments []string          `yaml:"environments"`
+		When         []string          `yaml:"when"`
 	}
 
 	if err := yaml.Unmarshal(data, &rawFacts); err != nil {
@@ -98,10 +111,25 @@ func LoadCustomFactsFromYAML(filepath string) (map[string]FactDefinition, error)
 			return nil, fmt.Errorf("fact '%s': at least one field mapping is required", key)
 		}
 
+		when, err := csv.ParseSelectors(raw.When)
+		if err != nil {
+			return nil, fmt.Errorf("fact '%s': when: %w", key, err)
+		}
+		for _, environment := range raw.Environments {
+			if strings.TrimSpace(environment) == "" {
+				return nil, fmt.Errorf("fact '%s': environments must not be empty", key)
+			}
+		}
+
 		facts[key] = FactDefinition{
-			FilePath: raw.FilePath,
-			FactName: raw.FactName,
-			Fields:   raw.Fields,
+			FilePath:     raw.FilePath,
+			FactName:     raw.FactName,
+			Fields:       raw.Fields,
+			Environments: raw.Environments,
+			When:         when,
+		}
+		if err := ValidateFactDefinition(facts[key]); err != nil {
+			return nil, fmt.Errorf("fact '%s': %w", key, err)
 		}
 	}
 
@@ -113,6 +141,86 @@ func LoadCustomFactsFromYAML(filepath string) (map[string]FactDefinition, error)
 	return facts, nil
 }
 
+// AppliesTo reports whether the fact is written on instance: its environment
+// column is one of Environments (when set) and it matches every When selector.
+func (f FactDefinition) AppliesTo(instance *cloud.Instance) bool {
+	if len(f.Environments) > 0 {
+		environment := strings.TrimSpace(instance.Metadata["environment"])
+		if !slices.ContainsFunc(f.Environments, func(e string) bool { return strings.EqualFold(strings.TrimSpace(e), environment) }) {
+			return false
+		}
+	}
+	for _, selector := range f.When {
+		if !selector.Match(instance) {
+			return false
+		}
+	}
+	return true
+}
+
+// FactEnvironmentCheck lists mismatches between the environments of
+// per-environment facts and the environment column of the CSV.
+type FactEnvironmentCheck struct {
+	Unknown      map[string][]string // Fact key -> listed environments no instance has (likely typos)
+	Unreferenced []string            // CSV environments no per-environment fact lists
+}
+
+// CheckFactEnvironments compares the environments listed by the facts with
+// those of the instances. Returns an empty check when no fact lists
+// environments.
+func CheckFactEnvironments(facts map[string]FactDefinition, instances []*cloud.Instance) FactEnvironmentCheck {
+	check := FactEnvironmentCheck{Unknown: map[string][]string{}}
+	inCSV := map[string]bool{}
+	for _, instance := range instances {
+		if environment := strings.ToLower(strings.TrimSpace(instance.Metadata["environment"])); environment != "" {
+			inCSV[environment] = true
+		}
+	}
+
+	referenced := map[string]bool{}
+	for key, fact := range facts {
+		for _, environment := range fact.Environments {
+			environment = strings.ToLower(strings.TrimSpace(environment))
+			referenced[environment] = true
+			if !inCSV[environment] {
+				check.Unknown[key] = append(check.Unknown[key], environment)
+			}
+		}
+	}
+	if len(referenced) == 0 {
+		return check
+	}
+	for environment := range inCSV {
+		if !referenced[environment] {
+			check.Unreferenced = append(check.Unreferenced, environment)
+		}
+	}
+	sort.Strings(check.Unreferenced)
+	return check
+}
+
+// LogFactEnvironments warns about environments of per-environment facts
+// missing from the CSV and CSV environments no per-environment fact lists.
+func LogFactEnvironments(log *slog.Logger, facts map[string]FactDefinition, instances []*cloud.Instance) {
+	check := CheckFactEnvironments(facts, instances)
+	keys := make([]string, 0, len(check.Unknown))
+	for key := range check.Unknown {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		log.Warn("⚠️  Custom fact lists environments not found in the CSV",
+			"fact", key,
+			"environments", check.Unknown[key],
+		)
+	}
+	if len(check.Unreferenced) > 0 {
+		log.Warn("⚠️  CSV environments not listed by any per-environment custom fact",
+			"environments", check.Unreferenced,
+		)
+	}
+}
+
 // ValidateFactColumns checks if CSV has all columns referenced in custom facts.
 // Returns list of missing columns that will result in empty fact fields.
 //
@@ -121,6 +229,7 @@ func LoadCustomFactsFromYAML(filepath string) (map[string]FactDefinition, error)
 // omitted fields in the generated Facter facts.
 //
 // Standard columns (account, region) are always available and not validated.
+// Facts that don't apply to the instance (see AppliesTo) are ignored.
 //
 // Returns:
 //   - Empty slice if all columns are present
@@ -136,6 +245,9 @@ func ValidateFactColumns(facts map[string]FactDefinition, instance *cloud.Instan
 	// Collect all CSV columns referenced in facts
 	requiredColumns := make(map[string]bool)
 	for _, factDef := range facts {
+		if !factDef.AppliesTo(instance) {
+			continue
+		}
 		for csvColumn := range factDef.Fields {
 			requiredColumns[csvColumn] = true
 		}
@@ -2,10 +2,13 @@ package installer
 
 import (
 	"os"
+	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/estudosdevops/opsmaster/internal/cloud"
+	"github.com/estudosdevops/opsmaster/internal/csv"
 )
 
 // ============================================================
@@ -623,3 +626,163 @@ func contains(s, substr string) bool {
 			return false
 		}())
 }
+
+// ============================================================
+// PER-ENVIRONMENT FACTS TESTS
+// ============================================================
+
+// TestLoadCustomFactsFromYAML_Conditions tests loading the environments and
+// when rules of a fact.
+func TestLoadCustomFactsFromYAML_Conditions(t *testing.T) {
+	path, cleanup := createTempYAMLFile(t, `
+compliance:
+  file_path: compliance.yaml
+  fact_name: compliance
+  environments: [prod, staging]
+  when: ["tier!=bastion"]
+  fields:
+    level: level
+`)
+	defer cleanup()
+
+	facts, err := LoadCustomFactsFromYAML(path)
+	if err != nil {
+		t.Fatalf("LoadCustomFactsFromYAML() unexpected error: %v", err)
+	}
+	fact := facts["compliance"]
+	if len(fact.Environments) != 2 || len(fact.When) != 1 || fact.When[0].String() != "tier!=bastion" {
+		t.Errorf("compliance fact = %+v", fact)
+	}
+
+	invalid, cleanupInvalid := createTempYAMLFile(t, `
+compliance:
+  file_path: compliance.yaml
+  fact_name: compliance
+  when: ["tier"]
+  fields:
+    level: level
+`)
+	defer cleanupInvalid()
+	if _, err := LoadCustomFactsFromYAML(invalid); err == nil {
+		t.Error("LoadCustomFactsFromYAML() with an invalid when selector should fail")
+	}
+}
+
+// TestFactDefinition_AppliesTo tests the inclusion rules of a fact.
+func TestFactDefinition_AppliesTo(t *testing.T) {
+	when, err := csv.ParseSelectors([]string{"tier!=bastion"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	fact := FactDefinition{Environments: []string{"Prod", "staging"}, When: when}
+
+	tests := []struct {
+		name     string
+		metadata map[string]string
+		want     bool
+	}{
+		{"listed environment", map[string]string{"environment": "prod", "tier": "web"}, true},
+		{"case-insensitive environment", map[string]string{"environment": "STAGING"}, true},
+		{"other environment", map[string]string{"environment": "dev", "tier": "web"}, false},
+		{"no environment", map[string]string{"tier": "web"}, false},
+		{"when not matched", map[string]string{"environment": "prod", "tier": "bastion"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := fact.AppliesTo(&cloud.Instance{ID: "i-1", Metadata: tt.metadata}); got != tt.want {
+				t.Errorf("AppliesTo() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if !(FactDefinition{}).AppliesTo(&cloud.Instance{ID: "i-1"}) {
+		t.Error("a fact without rules should apply to every instance")
+	}
+}
+
+// TestCheckFactEnvironments tests the warnings about per-environment facts.
+func TestCheckFactEnvironments(t *testing.T) {
+	instances := []*cloud.Instance{
+		{ID: "i-1", Metadata: map[string]string{"environment": "prod"}},
+		{ID: "i-2", Metadata: map[string]string{"environment": "dev"}},
+		{ID: "i-3", Metadata: map[string]string{"environment": "qa"}},
+		{ID: "i-4"},
+	}
+	facts := map[string]FactDefinition{
+		"location":   {FilePath: "location.yaml"},
+		"compliance": {FilePath: "compliance.yaml", Environments: []string{"prod", "prd"}},
+		"debug":      {FilePath: "debug.yaml", Environments: []string{"dev"}},
+	}
+
+	check := CheckFactEnvironments(facts, instances)
+	if got := check.Unknown["compliance"]; len(check.Unknown) != 1 || len(got) != 1 || got[0] != "prd" {
+		t.Errorf("Unknown = %v, want compliance: [prd]", check.Unknown)
+	}
+	if len(check.Unreferenced) != 1 || check.Unreferenced[0] != "qa" {
+		t.Errorf("Unreferenced = %v, want [qa]", check.Unreferenced)
+	}
+
+	check = CheckFactEnvironments(GetDefaultCustomFacts(), instances)
+	if len(check.Unknown) != 0 || len(check.Unreferenced) != 0 {
+		t.Errorf("CheckFactEnvironments() without per-environment facts = %+v, want no warnings", check)
+	}
+}
+
+// ============================================================
+// FACTS MANIFEST TESTS
+// ============================================================
+
+// TestGenerateFactsScript_PruneFacts runs the facts section twice, renaming a
+// fact file in between, and checks only stale opsmaster files are removed.
+func TestGenerateFactsScript_PruneFacts(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+
+	tests := []struct {
+		name       string
+		pruneFacts bool
+		wantStale  bool // old.yaml still present after the second run
+	}{
+		{"without --prune-facts", false, true},
+		{"with --prune-facts", true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			instance := &cloud.Instance{ID: "i-1", Metadata: map[string]string{"owner": "team-a"}}
+			run := func(facts map[string]FactDefinition) {
+				t.Helper()
+				pi := NewPuppetInstaller(PuppetOptions{Server: "puppet.example.com", CustomFacts: facts, PruneFacts: tt.pruneFacts})
+				script := strings.ReplaceAll(pi.generateFactsScript(instance), "/opt/puppetlabs/facter/facts.d", dir)
+				if out, err := exec.Command("sh", "-c", script).CombinedOutput(); err != nil {
+					t.Fatalf("facts script failed: %v\n%s", err, out)
+				}
+			}
+			fact := func(file string) FactDefinition {
+				return FactDefinition{FilePath: file, FactName: "app", Fields: map[string]string{"owner": "owner"}}
+			}
+
+			run(map[string]FactDefinition{"app": fact("old.yaml"), "location": fact("location.yaml")})
+			if err := os.WriteFile(filepath.Join(dir, "other-tool.yaml"), []byte("x: 1\n"), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			run(map[string]FactDefinition{"app": fact("new.yaml"), "location": fact("location.yaml")})
+
+			for name, want := range map[string]bool{"old.yaml": tt.wantStale, "new.yaml": true, "location.yaml": true, "other-tool.yaml": true} {
+				if _, err := os.Stat(filepath.Join(dir, name)); (err == nil) != want {
+					t.Errorf("%s present = %v, want %v", name, err == nil, want)
+				}
+			}
+			manifest, err := os.ReadFile(filepath.Join(dir, FactsManifestFile))
+			if err != nil {
+				t.Fatalf("manifest not written: %v", err)
+			}
+			if string(manifest) != "location.yaml\nnew.yaml\n" {
+				t.Errorf("manifest = %q, want the declared files", manifest)
+			}
+		})
+	}
+}
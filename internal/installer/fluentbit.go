@@ -0,0 +1,522 @@
+package installer
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/estudosdevops/opsmaster/internal/cloud"
+	"github.com/estudosdevops/opsmaster/internal/validator"
+)
+
+// Fluent Bit outputs supported by the generated configuration.
+const (
+	FluentBitOutputElasticsearch = "es"
+	FluentBitOutputLoki          = "loki"
+	FluentBitOutputCloudWatch    = "cloudwatch"
+)
+
+// Fluent Bit paths installed by the official packages.
+const (
+	fluentBitBinary     = "/opt/fluent-bit/bin/fluent-bit"
+	fluentBitConfigDir  = "/etc/fluent-bit"
+	fluentBitConfigFile = fluentBitConfigDir + "/fluent-bit.conf"
+	fluentBitStateDir   = "/var/lib/fluent-bit"
+)
+
+// MetadataKeyFluentBitOutput records the configured output in install metadata.
+const MetadataKeyFluentBitOutput = "fluent_bit_output"
+
+// defaultFluentBitInputs are tailed when no input paths are given
+// (syslog on Debian, messages on RHEL; missing paths are ignored).
+var defaultFluentBitInputs = []string{"/var/log/syslog", "/var/log/messages"}
+
+// FluentBitOptions contains Fluent Bit installation options.
+//
+// Host, Index, Labels, LogGroup and Region are Go templates rendered per
+// instance with FluentBitTemplateData, e.g. "logs-{{ .Metadata.environment }}".
+type FluentBitOptions struct {
+	Output   string // Output plugin: es, loki or cloudwatch (required unless RawConfig is set)
+	Host     string // Elasticsearch/Loki host
+	Port     int    // Elasticsearch/Loki port (default: 9200 for es, 3100 for loki)
+	TLS      bool   // Use TLS for Elasticsearch/Loki
+	Index    string // Elasticsearch index (default: "fluent-bit")
+	Labels   string // Loki labels, "key=value,key=value" (default: "job=fluent-bit")
+	LogGroup string // CloudWatch Logs group (required for cloudwatch)
+	Region   string // CloudWatch Logs region (default: instance region)
+
+	Inputs        []string // Log files tailed (default: /var/log/syslog, /var/log/messages)
+	RecordColumns []string // CSV columns added as fields to every record
+
+	// RawConfig is a complete fluent-bit.conf written as-is, for advanced
+	// setups. Output options are ignored when set.
+	RawConfig string
+}
+
+// FluentBitTemplateData is available to templated option values.
+type FluentBitTemplateData struct {
+	InstanceID string
+	Account    string
+	Region     string
+	Metadata   map[string]string // Extra CSV columns
+}
+
+// newFluentBitTemplateData builds template data for an instance (nil = empty values).
+func newFluentBitTemplateData(instance *cloud.Instance) FluentBitTemplateData {
+	if instance == nil {
+		return FluentBitTemplateData{Metadata: map[string]string{}}
+	}
+	metadata := instance.Metadata
+	if metadata == nil {
+		metadata = map[string]string{}
+	}
+	return FluentBitTemplateData{
+		InstanceID: instance.ID,
+		Account:    instance.Account,
+		Region:     instance.Region,
+		Metadata:   metadata,
+	}
+}
+
+// Validate checks the output settings and template syntax.
+func (o FluentBitOptions) Validate() error {
+	if o.RawConfig != "" {
+		return nil
+	}
+
+	switch o.Output {
+	case FluentBitOutputElasticsearch, FluentBitOutputLoki:
+		if o.Host == "" {
+			return fmt.Errorf("fluent-bit output %s requires a host", o.Output)
+		}
+	case FluentBitOutputCloudWatch:
+		if o.LogGroup == "" {
+			return fmt.Errorf("fluent-bit output cloudwatch requires a log group")
+		}
+	default:
+		return fmt.Errorf("invalid fluent-bit output %q (valid: %s, %s, %s)", o.Output,
+			FluentBitOutputElasticsearch, FluentBitOutputLoki, FluentBitOutputCloudWatch)
+	}
+	if o.Port < 0 || o.Port > 65535 {
+		return fmt.Errorf("invalid fluent-bit output port %d", o.Port)
+	}
+
+	for name, value := range map[string]string{"host": o.Host, "index": o.Index, "labels": o.Labels, "log group": o.LogGroup, "region": o.Region} {
+		if _, err := template.New(name).Option("missingkey=zero").Parse(value); err != nil {
+			return fmt.Errorf("invalid fluent-bit %s template: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// FluentBitInstaller implements PackageInstaller for the Fluent Bit log
+// shipper. Supports Debian/Ubuntu and RHEL/Amazon Linux via the official
+// packages.fluentbit.io repositories.
+type FluentBitInstaller struct {
+	opts         FluentBitOptions
+	lastMetadata *InstallMetadata
+}
+
+func init() {
+	Register(Descriptor{
+		Name:          "fluent-bit",
+		OSFamilies:    []string{OSTypeDebian, OSTypeRHEL},
+		Architectures: []string{"amd64", "arm64"},
+		New:           func() PackageInstaller { return NewFluentBitInstaller(FluentBitOptions{}) },
+	})
+}
+
+// NewFluentBitInstaller creates a new Fluent Bit installer with given options.
+// Call opts.Validate first; invalid templates fail per instance.
+func NewFluentBitInstaller(opts FluentBitOptions) *FluentBitInstaller {
+	// Set defaults
+	if opts.Port == 0 {
+		switch opts.Output {
+		case FluentBitOutputElasticsearch:
+			opts.Port = 9200
+		case FluentBitOutputLoki:
+			opts.Port = 3100
+		}
+	}
+	if opts.Index == "" {
+		opts.Index = "fluent-bit"
+	}
+	if opts.Labels == "" {
+		opts.Labels = "job=fluent-bit"
+	}
+	if len(opts.Inputs) == 0 {
+		opts.Inputs = defaultFluentBitInputs
+	}
+
+	return &FluentBitInstaller{opts: opts, lastMetadata: &InstallMetadata{}}
+}
+
+// Name returns the package name
+func (*FluentBitInstaller) Name() string {
+	return "fluent-bit"
+}
+
+// GenerateInstallScriptWithAutoDetect implements AutoDetector: detects the
+// OS remotely and renders the configuration for the instance.
+func (fi *FluentBitInstaller) GenerateInstallScriptWithAutoDetect(ctx context.Context, instance *cloud.Instance, provider cloud.CloudProvider, _ map[string]string) ([]string, *InstallMetadata, error) {
+	detectedOS, shell, err := detectOS(ctx, instance, provider)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to detect OS: %w", err)
+	}
+
+	normalizedOS, err := normalizeOS(detectedOS)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to normalize OS type: %w", err)
+	}
+
+	script, err := fi.generateScript(normalizedOS, instance)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	metadata := &InstallMetadata{OS: detectedOS, Shell: shell}
+	metadata.Set(MetadataKeyFluentBitOutput, fi.outputName())
+	return []string{script}, metadata, nil
+}
+
+// GenerateInstallScript generates the installation script for an OS
+// (used in dry-run mode; instance-specific template values render empty).
+func (fi *FluentBitInstaller) GenerateInstallScript(os string, _ map[string]string) ([]string, error) {
+	normalizedOS, err := normalizeOS(os)
+	if err != nil {
+		// Same fallback as the Puppet installer for unknown CSV values
+		normalizedOS = OSTypeDebian
+	}
+
+	script, err := fi.generateScript(normalizedOS, nil)
+	if err != nil {
+		return nil, err
+	}
+	return []string{script}, nil
+}
+
+// outputName returns the configured output for logs and metadata.
+func (fi *FluentBitInstaller) outputName() string {
+	if fi.opts.RawConfig != "" {
+		return "custom"
+	}
+	return fi.opts.Output
+}
+
+// generateScript builds the full installation script for a normalized OS.
+func (fi *FluentBitInstaller) generateScript(normalizedOS string, instance *cloud.Instance) (string, error) {
+	config, err := fi.renderConfig(instance)
+	if err != nil {
+		return "", err
+	}
+
+	var repo string
+	switch normalizedOS {
+	case OSTypeDebian:
+		repo = fluentBitDebianRepoScript
+	case OSTypeRHEL:
+		repo = fluentBitRHELRepoScript
+	default:
+		return "", fmt.Errorf("internal error: unexpected normalized OS type: %s", normalizedOS)
+	}
+
+	return fluentBitScriptHeader + downloadShellFunc + repo + fluentBitConfigScript(config) + fluentBitServiceScript, nil
+}
+
+// fluentBitScriptHeader loads os-release.
+const fluentBitScriptHeader = `#!/bin/sh
+echo "================================================"
+echo "Installing Fluent Bit"
+echo "================================================"
+
+if [ -f /etc/os-release ]; then
+    . /etc/os-release
+    echo "Detected OS: ${NAME} ${VERSION_ID}"
+else
+    echo "ERROR: Cannot detect OS version"
+    exit 10
+fi
+`
+
+// fluentBitDebianRepoScript adds the signed apt repository and installs the package.
+const fluentBitDebianRepoScript = `
+echo "Configuring Fluent Bit apt repository..."
+install -d -m 0755 /usr/share/keyrings
+if ! download https://packages.fluentbit.io/fluentbit.key | gpg --batch --yes --dearmor -o /usr/share/keyrings/fluentbit-keyring.gpg; then
+    echo "Error importing Fluent Bit repository key"
+    exit 20
+fi
+echo "deb [signed-by=/usr/share/keyrings/fluentbit-keyring.gpg] https://packages.fluentbit.io/${ID}/${VERSION_CODENAME} ${VERSION_CODENAME} main" > /etc/apt/sources.list.d/fluent-bit.list
+
+if ! apt-get update -qq; then
+    echo "Error updating package cache"
+    exit 20
+fi
+echo "Installing fluent-bit package..."
+if ! DEBIAN_FRONTEND=noninteractive apt-get install -y fluent-bit; then
+    echo "Error installing fluent-bit package"
+    exit 30
+fi
+`
+
+// fluentBitRHELRepoScript adds the yum repository and installs the package.
+const fluentBitRHELRepoScript = `
+echo "Configuring Fluent Bit yum repository..."
+case "$ID" in
+    amzn) REPO_PATH="amazonlinux/${VERSION_ID%%.*}" ;;
+    *)    REPO_PATH="centos/${VERSION_ID%%.*}" ;;
+esac
+cat > /etc/yum.repos.d/fluent-bit.repo <<EOF
+[fluent-bit]
+name=Fluent Bit
+baseurl=https://packages.fluentbit.io/${REPO_PATH}/
+gpgcheck=1
+repo_gpgcheck=1
+gpgkey=https://packages.fluentbit.io/fluentbit.key
+enabled=1
+EOF
+
+echo "Installing fluent-bit package..."
+if ! yum install -y fluent-bit; then
+    echo "Error installing fluent-bit package (repository: ${REPO_PATH})"
+    exit 30
+fi
+`
+
+// fluentBitConfigScript writes the configuration after validating it with
+// --dry-run, so a bad config never replaces a working one.
+func fluentBitConfigScript(config string) string {
+	encoded := base64.StdEncoding.EncodeToString([]byte(config))
+	return `
+echo "Writing Fluent Bit configuration..."
+mkdir -p ` + fluentBitConfigDir + ` ` + fluentBitStateDir + `
+CONFIG_TMP="` + fluentBitConfigDir + `/.fluent-bit.conf.new"
+echo '` + encoded + `' | base64 -d > "${CONFIG_TMP}"
+if ! ` + fluentBitBinary + ` -c "${CONFIG_TMP}" --dry-run >/dev/null; then
+    echo "Error: invalid Fluent Bit configuration"
+    rm -f "${CONFIG_TMP}"
+    exit 40
+fi
+mv "${CONFIG_TMP}" ` + fluentBitConfigFile + `
+`
+}
+
+// fluentBitServiceScript enables and (re)starts the service.
+const fluentBitServiceScript = `
+echo "Starting fluent-bit service..."
+systemctl enable fluent-bit
+if ! systemctl restart fluent-bit; then
+    echo "Error starting fluent-bit service"
+    journalctl -u fluent-bit -n 20 --no-pager 2>/dev/null
+    exit 40
+fi
+echo "✓ Fluent Bit installed and running"
+`
+
+// renderConfig builds fluent-bit.conf for an instance (RawConfig as-is).
+func (fi *FluentBitInstaller) renderConfig(instance *cloud.Instance) (string, error) {
+	if fi.opts.RawConfig != "" {
+		return fi.opts.RawConfig, nil
+	}
+
+	data := newFluentBitTemplateData(instance)
+	render := func(name, value string) (string, error) {
+		return renderFluentBitValue(name, value, data)
+	}
+
+	var b strings.Builder
+	b.WriteString("[SERVICE]\n")
+	b.WriteString("    Flush        5\n")
+	b.WriteString("    Log_Level    info\n")
+	b.WriteString("    Parsers_File " + fluentBitConfigDir + "/parsers.conf\n\n")
+
+	b.WriteString("[INPUT]\n")
+	b.WriteString("    Name     tail\n")
+	b.WriteString("    Path     " + strings.Join(fi.opts.Inputs, ",") + "\n")
+	b.WriteString("    Path_Key file\n")
+	b.WriteString("    Tag      host.*\n")
+	b.WriteString("    DB       " + fluentBitStateDir + "/tail.db\n\n")
+
+	// Identify the source instance in every record
+	b.WriteString("[FILTER]\n")
+	b.WriteString("    Name   record_modifier\n")
+	b.WriteString("    Match  *\n")
+	for _, field := range []struct{ key, value string }{
+		{"instance_id", data.InstanceID},
+		{"account", data.Account},
+		{"region", data.Region},
+	} {
+		if field.value != "" {
+			b.WriteString("    Record " + field.key + " " + configValue(field.value) + "\n")
+		}
+	}
+	for _, column := range fi.opts.RecordColumns {
+		if value := data.Metadata[column]; value != "" {
+			b.WriteString("    Record " + column + " " + configValue(value) + "\n")
+		}
+	}
+	b.WriteString("\n[OUTPUT]\n")
+
+	switch fi.opts.Output {
+	case FluentBitOutputElasticsearch, FluentBitOutputLoki:
+		host, err := render("host", fi.opts.Host)
+		if err != nil {
+			return "", err
+		}
+		if fi.opts.Output == FluentBitOutputElasticsearch {
+			index, err := render("index", fi.opts.Index)
+			if err != nil {
+				return "", err
+			}
+			b.WriteString("    Name               es\n")
+			b.WriteString("    Match              *\n")
+			b.WriteString("    Host               " + host + "\n")
+			fmt.Fprintf(&b, "    Port               %d\n", fi.opts.Port)
+			b.WriteString("    Index              " + index + "\n")
+			b.WriteString("    Suppress_Type_Name On\n")
+		} else {
+			labels, err := render("labels", fi.opts.Labels)
+			if err != nil {
+				return "", err
+			}
+			b.WriteString("    Name   loki\n")
+			b.WriteString("    Match  *\n")
+			b.WriteString("    Host   " + host + "\n")
+			fmt.Fprintf(&b, "    Port   %d\n", fi.opts.Port)
+			b.WriteString("    Labels " + strings.ReplaceAll(labels, ",", ", ") + "\n")
+		}
+		if fi.opts.TLS {
+			b.WriteString("    tls    On\n")
+		}
+	case FluentBitOutputCloudWatch:
+		logGroup, err := render("log group", fi.opts.LogGroup)
+		if err != nil {
+			return "", err
+		}
+		region, err := render("region", fi.opts.Region)
+		if err != nil {
+			return "", err
+		}
+		if region == "" {
+			region = data.Region
+		}
+		b.WriteString("    Name              cloudwatch_logs\n")
+		b.WriteString("    Match             *\n")
+		b.WriteString("    region            " + region + "\n")
+		b.WriteString("    log_group_name    " + logGroup + "\n")
+		b.WriteString("    log_stream_prefix " + configValue(data.InstanceID) + "-\n")
+		b.WriteString("    auto_create_group On\n")
+	default:
+		return "", fmt.Errorf("invalid fluent-bit output %q", fi.opts.Output)
+	}
+
+	return b.String(), nil
+}
+
+// renderFluentBitValue executes a templated option value.
+func renderFluentBitValue(name, value string, data FluentBitTemplateData) (string, error) {
+	tmpl, err := template.New(name).Option("missingkey=zero").Parse(value)
+	if err != nil {
+		return "", fmt.Errorf("invalid fluent-bit %s template: %w", name, err)
+	}
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, data); err != nil {
+		return "", fmt.Errorf("failed to render fluent-bit %s for %s: %w", name, data.InstanceID, err)
+	}
+	return configValue(out.String()), nil
+}
+
+// configValue keeps values on one config line.
+func configValue(value string) string {
+	return strings.TrimSpace(strings.NewReplacer("\r", " ", "\n", " ").Replace(value))
+}
+
+// ValidatePrerequisites checks SSM connectivity and, for es/loki and
+// cloudwatch, that the instance reaches the output endpoint.
+func (fi *FluentBitInstaller) ValidatePrerequisites(ctx context.Context, instance *cloud.Instance, provider cloud.CloudProvider) error {
+	validators := []validator.Validator{validator.NewSSMValidator(0)}
+
+	if host, port, err := fi.outputEndpoint(instance); err != nil {
+		return err
+	} else if host != "" {
+		validators = append(validators, validator.NewConnectivityValidator("fluent_bit_output_reachable", host, port, 0))
+	}
+
+	results := validator.NewCompositeValidator(validators, false).Validate(ctx, instance, provider)
+	if !validator.AllPassed(results) {
+		var failures []string
+		for _, failed := range validator.GetFailedValidations(results) {
+			failures = append(failures, fmt.Sprintf("%s: %s", failed.Name, failed.Message))
+		}
+		return fmt.Errorf("fluent-bit prerequisites validation failed:\n  - %s", strings.Join(failures, "\n  - "))
+	}
+	return nil
+}
+
+// outputEndpoint returns the host:port the instance ships logs to
+// ("" when unknown, e.g. with RawConfig).
+func (fi *FluentBitInstaller) outputEndpoint(instance *cloud.Instance) (string, int, error) {
+	if fi.opts.RawConfig != "" {
+		return "", 0, nil
+	}
+	data := newFluentBitTemplateData(instance)
+
+	switch fi.opts.Output {
+	case FluentBitOutputElasticsearch, FluentBitOutputLoki:
+		host, err := renderFluentBitValue("host", fi.opts.Host, data)
+		return host, fi.opts.Port, err
+	case FluentBitOutputCloudWatch:
+		region, err := renderFluentBitValue("region", fi.opts.Region, data)
+		if region == "" {
+			region = data.Region
+		}
+		if region == "" {
+			return "", 0, err
+		}
+		return "logs." + region + ".amazonaws.com", 443, err
+	}
+	return "", 0, nil
+}
+
+// VerifyInstallation checks the binary and that the service is running.
+func (*FluentBitInstaller) VerifyInstallation(ctx context.Context, instance *cloud.Instance, provider cloud.CloudProvider) error {
+	verifyCommands := []string{
+		"test -x " + fluentBitBinary + " || exit 1",
+		fluentBitBinary + " --version || exit 2",
+		"systemctl is-active --quiet fluent-bit || exit 3",
+		"systemctl is-enabled --quiet fluent-bit || exit 4",
+	}
+
+	result, err := provider.ExecuteCommand(ctx, instance, verifyCommands, DefaultSSMTimeout)
+	if err != nil {
+		return fmt.Errorf("failed to verify fluent-bit installation: %w", err)
+	}
+	if result.ExitCode != 0 {
+		return fmt.Errorf("fluent-bit verification failed (exit code %d):\nstdout: %s\nstderr: %s",
+			result.ExitCode, result.Stdout, result.Stderr)
+	}
+	return nil
+}
+
+// GetSuccessTags returns tags to apply after successful installation.
+func (*FluentBitInstaller) GetSuccessTags() map[string]string {
+	return map[string]string{
+		"fluent-bit": "true",
+	}
+}
+
+// GetFailureTags returns tags to apply when installation fails.
+func (*FluentBitInstaller) GetFailureTags(_ error) map[string]string {
+	return map[string]string{}
+}
+
+// GetInstallMetadata returns metadata from the last installation attempt.
+func (fi *FluentBitInstaller) GetInstallMetadata() *InstallMetadata {
+	if fi.lastMetadata == nil {
+		return &InstallMetadata{}
+	}
+	return fi.lastMetadata
+}
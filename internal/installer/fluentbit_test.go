@@ -0,0 +1,201 @@
+package installer
+
+import (
+	"context"
+	"encoding/base64"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/estudosdevops/opsmaster/internal/cloud"
+	"github.com/estudosdevops/opsmaster/internal/cloud/cloudtest"
+)
+
+// TestFluentBitOptions_Validate tests output and template validation
+func TestFluentBitOptions_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    FluentBitOptions
+		wantErr string
+	}{
+		{name: "es with host", opts: FluentBitOptions{Output: "es", Host: "es.internal"}},
+		{name: "loki with templated host", opts: FluentBitOptions{Output: "loki", Host: "loki-{{ .Metadata.env }}.internal"}},
+		{name: "cloudwatch with log group", opts: FluentBitOptions{Output: "cloudwatch", LogGroup: "/opsmaster/{{ .Account }}"}},
+		{name: "raw config skips output checks", opts: FluentBitOptions{RawConfig: "[SERVICE]\n"}},
+		{name: "missing output", opts: FluentBitOptions{}, wantErr: "invalid fluent-bit output"},
+		{name: "es without host", opts: FluentBitOptions{Output: "es"}, wantErr: "requires a host"},
+		{name: "cloudwatch without log group", opts: FluentBitOptions{Output: "cloudwatch"}, wantErr: "requires a log group"},
+		{name: "invalid port", opts: FluentBitOptions{Output: "es", Host: "h", Port: 70000}, wantErr: "invalid fluent-bit output port"},
+		{name: "broken template", opts: FluentBitOptions{Output: "es", Host: "h", Index: "{{ .Metadata.env"}, wantErr: "invalid fluent-bit index template"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// ACT
+			err := tt.opts.Validate()
+
+			// ASSERT
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+// TestFluentBitInstaller_RenderConfig tests the generated fluent-bit.conf per output
+func TestFluentBitInstaller_RenderConfig(t *testing.T) {
+	instance := &cloud.Instance{
+		ID:       "i-abc",
+		Account:  "111111111111",
+		Region:   "sa-east-1",
+		Metadata: map[string]string{"env": "prod", "team": "payments\n"},
+	}
+
+	tests := []struct {
+		name        string
+		opts        FluentBitOptions
+		contains    []string
+		notContains []string
+	}{
+		{
+			name: "elasticsearch with templated index",
+			opts: FluentBitOptions{Output: "es", Host: "es.internal", Index: "logs-{{ .Metadata.env }}", TLS: true},
+			contains: []string{
+				"Name               es",
+				"Host               es.internal",
+				"Port               9200",
+				"Index              logs-prod",
+				"tls    On",
+				"Record instance_id i-abc",
+			},
+		},
+		{
+			name:        "loki with default labels",
+			opts:        FluentBitOptions{Output: "loki", Host: "loki.internal"},
+			contains:    []string{"Name   loki", "Port   3100", "Labels job=fluent-bit"},
+			notContains: []string{"tls"},
+		},
+		{
+			name: "cloudwatch defaults to instance region",
+			opts: FluentBitOptions{Output: "cloudwatch", LogGroup: "/opsmaster/{{ .Account }}"},
+			contains: []string{
+				"Name              cloudwatch_logs",
+				"region            sa-east-1",
+				"log_group_name    /opsmaster/111111111111",
+				"log_stream_prefix i-abc-",
+			},
+		},
+		{
+			name:     "record columns from CSV metadata are single-line",
+			opts:     FluentBitOptions{Output: "es", Host: "h", RecordColumns: []string{"team", "missing"}},
+			contains: []string{"Record team payments\n"},
+			// 🎓 CONCEPT: empty columns are skipped instead of producing "Record missing "
+			notContains: []string{"Record missing"},
+		},
+		{
+			name:        "raw config is used verbatim",
+			opts:        FluentBitOptions{Output: "es", RawConfig: "[SERVICE]\n    Flush 1\n"},
+			contains:    []string{"[SERVICE]\n    Flush 1\n"},
+			notContains: []string{"[OUTPUT]"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// ARRANGE
+			fi := NewFluentBitInstaller(tt.opts)
+
+			// ACT
+			config, err := fi.renderConfig(instance)
+
+			// ASSERT
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for _, want := range tt.contains {
+				if !strings.Contains(config, want) {
+					t.Errorf("config missing %q:\n%s", want, config)
+				}
+			}
+			for _, unwanted := range tt.notContains {
+				if strings.Contains(config, unwanted) {
+					t.Errorf("config should not contain %q:\n%s", unwanted, config)
+				}
+			}
+		})
+	}
+}
+
+// TestFluentBitInstaller_GenerateInstallScript tests repository setup per OS family
+func TestFluentBitInstaller_GenerateInstallScript(t *testing.T) {
+	fi := NewFluentBitInstaller(FluentBitOptions{Output: "loki", Host: "loki.internal"})
+
+	tests := []struct {
+		os          string
+		contains    []string
+		notContains []string
+	}{
+		{os: "ubuntu", contains: []string{"/etc/apt/sources.list.d/fluent-bit.list", "apt-get install -y fluent-bit"}, notContains: []string{"yum"}},
+		{os: "amazonlinux", contains: []string{"/etc/yum.repos.d/fluent-bit.repo", "amazonlinux/", "yum install -y fluent-bit"}, notContains: []string{"apt-get"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.os, func(t *testing.T) {
+			// ACT
+			scripts, err := fi.GenerateInstallScript(tt.os, nil)
+
+			// ASSERT
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			script := scripts[0]
+			for _, want := range append(tt.contains, "--dry-run", "systemctl restart fluent-bit") {
+				if !strings.Contains(script, want) {
+					t.Errorf("script missing %q", want)
+				}
+			}
+			for _, unwanted := range tt.notContains {
+				if strings.Contains(script, unwanted) {
+					t.Errorf("script should not contain %q", unwanted)
+				}
+			}
+		})
+	}
+}
+
+// TestFluentBitInstaller_AutoDetect tests that the config is rendered for the instance
+func TestFluentBitInstaller_AutoDetect(t *testing.T) {
+	// ARRANGE
+	provider := &cloudtest.Provider{
+		ExecuteCommandFunc: func(context.Context, *cloud.Instance, []string, time.Duration) (*cloud.CommandResult, error) {
+			return &cloud.CommandResult{ExitCode: 0, Stdout: "debian\n"}, nil
+		},
+	}
+	instance := &cloud.Instance{ID: "i-1", Region: "us-east-1", Metadata: map[string]string{"env": "dev"}}
+	fi := NewFluentBitInstaller(FluentBitOptions{Output: "es", Host: "es-{{ .Metadata.env }}.internal"})
+
+	// ACT
+	scripts, metadata, err := fi.GenerateInstallScriptWithAutoDetect(context.Background(), instance, provider, nil)
+
+	// ASSERT
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if metadata.Get(MetadataKeyFluentBitOutput) != "es" {
+		t.Errorf("expected output metadata es, got %q", metadata.Get(MetadataKeyFluentBitOutput))
+	}
+	// The config travels base64-encoded inside the script
+	config, _ := fi.renderConfig(instance)
+	if !strings.Contains(scripts[0], base64.StdEncoding.EncodeToString([]byte(config))) {
+		t.Error("script does not embed the instance config")
+	}
+	if !strings.Contains(config, "Host               es-dev.internal") {
+		t.Errorf("host template not rendered:\n%s", config)
+	}
+}
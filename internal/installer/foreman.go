@@ -0,0 +1,206 @@
+package installer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/estudosdevops/opsmaster/internal/httpclient"
+)
+
+// DefaultForemanHostgroupColumn is the CSV column holding the Foreman hostgroup.
+const DefaultForemanHostgroupColumn = "hostgroup"
+
+// ForemanConfig configures host registration in Foreman after installation.
+type ForemanConfig struct {
+	URL             string       // Foreman base URL (e.g., https://foreman.example.com)
+	Username        string       // API user
+	Password        string       // API password or personal access token
+	Organization    string       // Organization name (optional)
+	Location        string       // Location name (optional)
+	HostgroupColumn string       // CSV column with the hostgroup title (default: "hostgroup")
+	Client          *http.Client // HTTP client (default: httpclient.Shared())
+}
+
+// ForemanRegistrar creates or updates Foreman host entries (name = certname).
+// Hostgroup, organization and location IDs are looked up once and cached.
+// Safe for concurrent use.
+type ForemanRegistrar struct {
+	config ForemanConfig
+
+	mu  sync.Mutex
+	ids map[string]int // "<resource>/<name>" -> Foreman ID
+}
+
+// NewForemanRegistrar validates the configuration and creates a registrar.
+func NewForemanRegistrar(config ForemanConfig) (*ForemanRegistrar, error) {
+	parsed, err := url.Parse(config.URL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return nil, fmt.Errorf("invalid Foreman URL %q: must be an http(s) URL", config.URL)
+	}
+	if config.Username == "" || config.Password == "" {
+		return nil, fmt.Errorf("foreman credentials are required (username and password)")
+	}
+	if config.HostgroupColumn == "" {
+		config.HostgroupColumn = DefaultForemanHostgroupColumn
+	}
+	config.URL = strings.TrimRight(config.URL, "/")
+
+	return &ForemanRegistrar{config: config, ids: make(map[string]int)}, nil
+}
+
+// foremanHost is the host body accepted by POST/PUT /api/v2/hosts.
+type foremanHost struct {
+	Name           string `json:"name"`
+	HostgroupID    int    `json:"hostgroup_id,omitempty"`
+	OrganizationID int    `json:"organization_id,omitempty"`
+	LocationID     int    `json:"location_id,omitempty"`
+	Managed        bool   `json:"managed"`
+	Comment        string `json:"comment,omitempty"`
+}
+
+// Register creates the host in Foreman or updates it when it already exists.
+func (f *ForemanRegistrar) Register(ctx context.Context, node ENCNode) error {
+	host := foremanHost{
+		Name:    node.Certname,
+		Managed: false, // Provisioning is not done by Foreman, only reporting/classification
+		Comment: fmt.Sprintf("Registered by opsmaster (instance %s, account %s, region %s)", node.InstanceID, node.Account, node.Region),
+	}
+	if node.RunID != "" {
+		host.Comment = fmt.Sprintf("Registered by opsmaster run %s (instance %s, account %s, region %s)",
+			node.RunID, node.InstanceID, node.Account, node.Region)
+	}
+
+	var err error
+	if hostgroup := node.Metadata[f.config.HostgroupColumn]; hostgroup != "" {
+		if host.HostgroupID, err = f.lookupID(ctx, "hostgroups", "title", hostgroup); err != nil {
+			return err
+		}
+	}
+	if f.config.Organization != "" {
+		if host.OrganizationID, err = f.lookupID(ctx, "organizations", "name", f.config.Organization); err != nil {
+			return err
+		}
+	}
+	if f.config.Location != "" {
+		if host.LocationID, err = f.lookupID(ctx, "locations", "name", f.config.Location); err != nil {
+			return err
+		}
+	}
+
+	hostPath := "/api/v2/hosts/" + url.PathEscape(node.Certname)
+	status, _, err := f.do(ctx, http.MethodGet, hostPath, nil)
+	if err != nil {
+		return err
+	}
+
+	body := map[string]foremanHost{"host": host}
+	switch status {
+	case http.StatusOK:
+		_, err = f.expect(ctx, http.MethodPut, hostPath, body, http.StatusOK)
+	case http.StatusNotFound:
+		_, err = f.expect(ctx, http.MethodPost, "/api/v2/hosts", body, http.StatusCreated, http.StatusOK)
+	default:
+		err = fmt.Errorf("foreman returned %d looking up host %s", status, node.Certname)
+	}
+	return err
+}
+
+// lookupID returns the ID of a named Foreman resource (hostgroups, organizations, locations).
+func (f *ForemanRegistrar) lookupID(ctx context.Context, resource, field, name string) (int, error) {
+	key := resource + "/" + name
+	f.mu.Lock()
+	id, cached := f.ids[key]
+	f.mu.Unlock()
+	if cached {
+		return id, nil
+	}
+
+	query := url.Values{"search": {fmt.Sprintf("%s=%q", field, name)}}
+	data, err := f.expect(ctx, http.MethodGet, "/api/v2/"+resource+"?"+query.Encode(), nil, http.StatusOK)
+	if err != nil {
+		return 0, err
+	}
+
+	var response struct {
+		Results []struct {
+			ID int `json:"id"`
+		} `json:"results"`
+	}
+	if err := json.Unmarshal(data, &response); err != nil {
+		return 0, fmt.Errorf("failed to decode foreman %s response: %w", resource, err)
+	}
+	if len(response.Results) == 0 {
+		return 0, fmt.Errorf("foreman %s %q not found", strings.TrimSuffix(resource, "s"), name)
+	}
+
+	id = response.Results[0].ID
+	f.mu.Lock()
+	f.ids[key] = id
+	f.mu.Unlock()
+	return id, nil
+}
+
+// expect performs a request and fails unless the status is one of wantStatus.
+func (f *ForemanRegistrar) expect(ctx context.Context, method, path string, body any, wantStatus ...int) ([]byte, error) {
+	status, data, err := f.do(ctx, method, path, body)
+	if err != nil {
+		return nil, err
+	}
+	for _, want := range wantStatus {
+		if status == want {
+			return data, nil
+		}
+	}
+
+	detail := strings.TrimSpace(string(data))
+	if len(detail) > maxENCErrorBody {
+		detail = detail[:maxENCErrorBody]
+	}
+	return nil, fmt.Errorf("foreman returned %d for %s %s: %s", status, method, path, detail)
+}
+
+// do performs a Foreman API request and returns status and body.
+func (f *ForemanRegistrar) do(ctx context.Context, method, path string, body any) (int, []byte, error) {
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return 0, nil, fmt.Errorf("failed to encode foreman request: %w", err)
+		}
+		reader = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, f.config.URL+path, reader)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to create foreman request: %w", err)
+	}
+	req.SetBasicAuth(f.config.Username, f.config.Password)
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	client := f.config.Client
+	if client == nil {
+		client = httpclient.Shared()
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, nil, fmt.Errorf("foreman request %s %s failed: %w", method, path, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to read foreman response: %w", err)
+	}
+	return resp.StatusCode, data, nil
+}
@@ -0,0 +1,139 @@
+package installer
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeForeman is a minimal Foreman API: one hostgroup, one organization,
+// one location and an in-memory host list.
+type fakeForeman struct {
+	mu       sync.Mutex
+	hosts    map[string]foremanHost
+	requests []string // "METHOD path"
+}
+
+func (f *fakeForeman) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.requests = append(f.requests, r.Method+" "+r.URL.Path)
+
+	if user, pass, ok := r.BasicAuth(); !ok || user != "admin" || pass != "changeme" {
+		http.Error(w, `{"error":"unauthorized"}`, http.StatusUnauthorized)
+		return
+	}
+
+	search := r.URL.Query().Get("search")
+	switch {
+	case r.URL.Path == "/api/v2/hostgroups" && search == `title="web/prod"`:
+		_, _ = w.Write([]byte(`{"results":[{"id":7}]}`))
+	case r.URL.Path == "/api/v2/hostgroups":
+		_, _ = w.Write([]byte(`{"results":[]}`))
+	case r.URL.Path == "/api/v2/organizations":
+		_, _ = w.Write([]byte(`{"results":[{"id":1}]}`))
+	case r.URL.Path == "/api/v2/locations":
+		_, _ = w.Write([]byte(`{"results":[{"id":2}]}`))
+	case strings.HasPrefix(r.URL.Path, "/api/v2/hosts/"):
+		name := strings.TrimPrefix(r.URL.Path, "/api/v2/hosts/")
+		if _, exists := f.hosts[name]; !exists {
+			http.Error(w, `{"error":"not found"}`, http.StatusNotFound)
+			return
+		}
+		if r.Method == http.MethodPut {
+			f.hosts[name] = decodeForemanHost(r)
+		}
+		_, _ = w.Write([]byte(`{}`))
+	case r.URL.Path == "/api/v2/hosts" && r.Method == http.MethodPost:
+		host := decodeForemanHost(r)
+		f.hosts[host.Name] = host
+		w.WriteHeader(http.StatusCreated)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func decodeForemanHost(r *http.Request) foremanHost {
+	var body map[string]foremanHost
+	_ = json.NewDecoder(r.Body).Decode(&body)
+	return body["host"]
+}
+
+// TestForemanRegistrar_Register tests host creation, update and ID caching.
+//
+// 🎓 CONCEPT: Fake API server
+// fakeForeman keeps state between requests, so create-then-update flows can be tested.
+func TestForemanRegistrar_Register(t *testing.T) {
+	// ARRANGE
+	fake := &fakeForeman{hosts: map[string]foremanHost{}}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	registrar, err := NewForemanRegistrar(ForemanConfig{
+		URL:          server.URL + "/",
+		Username:     "admin",
+		Password:     "changeme",
+		Organization: "Acme",
+		Location:     "us-east-1",
+		Client:       server.Client(),
+	})
+	if err != nil {
+		t.Fatalf("NewForemanRegistrar() error: %v", err)
+	}
+	node := ENCNode{Certname: "abc.puppet", InstanceID: "i-abc", Metadata: map[string]string{"hostgroup": "web/prod"}}
+
+	// ACT: first registration creates, second updates
+	if err := registrar.Register(context.Background(), node); err != nil {
+		t.Fatalf("Register() create error: %v", err)
+	}
+	if err := registrar.Register(context.Background(), node); err != nil {
+		t.Fatalf("Register() update error: %v", err)
+	}
+
+	// ASSERT
+	host := fake.hosts["abc.puppet"]
+	if host.HostgroupID != 7 || host.OrganizationID != 1 || host.LocationID != 2 || host.Managed {
+		t.Errorf("unexpected host: %+v", host)
+	}
+
+	var lookups, creates, updates int
+	for _, req := range fake.requests {
+		switch {
+		case strings.HasPrefix(req, "GET /api/v2/hostgroups"), strings.HasPrefix(req, "GET /api/v2/organizations"), strings.HasPrefix(req, "GET /api/v2/locations"):
+			lookups++
+		case req == "POST /api/v2/hosts":
+			creates++
+		case req == "PUT /api/v2/hosts/abc.puppet":
+			updates++
+		}
+	}
+	if lookups != 3 || creates != 1 || updates != 1 {
+		t.Errorf("lookups=%d creates=%d updates=%d, want 3/1/1 (IDs cached)", lookups, creates, updates)
+	}
+}
+
+// TestForemanRegistrar_Errors tests configuration and lookup errors
+func TestForemanRegistrar_Errors(t *testing.T) {
+	if _, err := NewForemanRegistrar(ForemanConfig{URL: "https://foreman.example.com"}); err == nil {
+		t.Error("expected error without credentials")
+	}
+	if _, err := NewForemanRegistrar(ForemanConfig{URL: "foreman", Username: "a", Password: "b"}); err == nil {
+		t.Error("expected error for URL without scheme")
+	}
+
+	server := httptest.NewServer(&fakeForeman{hosts: map[string]foremanHost{}})
+	defer server.Close()
+
+	registrar, err := NewForemanRegistrar(ForemanConfig{URL: server.URL, Username: "admin", Password: "changeme", Client: server.Client()})
+	if err != nil {
+		t.Fatalf("NewForemanRegistrar() error: %v", err)
+	}
+	err = registrar.Register(context.Background(), ENCNode{Certname: "abc.puppet", Metadata: map[string]string{"hostgroup": "missing"}})
+	if err == nil || !strings.Contains(err.Error(), `hostgroup "missing" not found`) {
+		t.Errorf("Register() error = %v, want hostgroup not found", err)
+	}
+}
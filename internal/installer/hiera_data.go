@@ -0,0 +1,170 @@
+package installer
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/estudosdevops/opsmaster/internal/cloud"
+)
+
+// DefaultHieraNodeDataPath is where the node-local hiera data file is
+// written when the mapping doesn't set a path.
+const DefaultHieraNodeDataPath = "/etc/hiera-node.yaml"
+
+// HieraNodeData maps CSV columns to the keys of a node-local hiera data
+// file, for roles that read per-node data on the node itself instead of
+// data/nodes/<certname>.yaml on the Puppet Server.
+type HieraNodeData struct {
+	Path string            // Absolute path of the YAML file on the node ("" = DefaultHieraNodeDataPath)
+	Keys map[string]string // Hiera key (e.g., profile::app::tier) → CSV column
+}
+
+var (
+	// hieraKeyPattern matches hiera keys, optionally namespaced with "::".
+	hieraKeyPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(::[A-Za-z_][A-Za-z0-9_]*)*$`)
+
+	// hieraDataPathPattern matches absolute paths of YAML files.
+	hieraDataPathPattern = regexp.MustCompile(`^(/[A-Za-z0-9_][A-Za-z0-9_.-]*)+\.(yaml|yml)$`)
+)
+
+// LoadHieraNodeDataFromYAML loads the node-local hiera data mapping from a
+// YAML file. Each entry maps a hiera key to a CSV column.
+//
+// Expected YAML format:
+//
+//	path: /etc/puppetlabs/facter/hiera-node.yaml   # optional
+//	keys:
+//	  profile::app::tier: "tier"
+//	  profile::nginx::worker_processes: "nginx_workers"
+func LoadHieraNodeDataFromYAML(filepath string) (*HieraNodeData, error) {
+	data, err := os.ReadFile(filepath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read hiera node data file: %w", err)
+	}
+
+	var raw struct {
+		Path string            `yaml:"path"`
+		Keys map[string]string `yaml:"keys"`
+	}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&raw); err != nil {
+		return nil, fmt.Errorf("failed to parse hiera node data YAML: %w", err)
+	}
+
+	hieraData := &HieraNodeData{Path: raw.Path, Keys: raw.Keys}
+	if len(hieraData.Keys) == 0 {
+		return nil, fmt.Errorf("no keys defined in file")
+	}
+	if err := ValidateHieraNodeData(hieraData); err != nil {
+		return nil, err
+	}
+	return hieraData, nil
+}
+
+// ValidateHieraNodeData checks the path, hiera keys and CSV columns of a
+// mapping.
+func ValidateHieraNodeData(hieraData *HieraNodeData) error {
+	if hieraData.Path != "" {
+		if !hieraDataPathPattern.MatchString(hieraData.Path) || strings.Contains(hieraData.Path, "/..") {
+			return fmt.Errorf("invalid hiera node data path %q: use an absolute path to a .yaml file", hieraData.Path)
+		}
+	}
+	for key, column := range hieraData.Keys {
+		if !hieraKeyPattern.MatchString(key) {
+			return fmt.Errorf("invalid hiera key %q: use letters, digits and '_', namespaced with '::'", key)
+		}
+		if column == "" {
+			return fmt.Errorf("hiera key %q: CSV column is required", key)
+		}
+		if !factNamePattern.MatchString(column) {
+			return fmt.Errorf("hiera key %q: invalid CSV column %q", key, column)
+		}
+	}
+	return nil
+}
+
+// ValidateHieraNodeDataColumns returns the CSV columns referenced by the
+// mapping that are missing or empty for instance (their keys are omitted).
+func ValidateHieraNodeDataColumns(hieraData *HieraNodeData, instance *cloud.Instance) []string {
+	if hieraData == nil {
+		return nil
+	}
+	missing := []string{}
+	seen := make(map[string]bool)
+	for _, column := range hieraData.Keys {
+		if seen[column] || csvColumnValue(instance, column) != "" {
+			continue
+		}
+		seen[column] = true
+		missing = append(missing, column)
+	}
+	sort.Strings(missing)
+	return missing
+}
+
+// path returns the file written on the node.
+func (hieraData *HieraNodeData) path() string {
+	if hieraData.Path == "" {
+		return DefaultHieraNodeDataPath
+	}
+	return hieraData.Path
+}
+
+// renderHieraNodeData renders the hiera data file for instance, sorted by
+// key. Values keep their YAML types like custom facts (42 is a number,
+// true a boolean). Keys with an empty CSV value are omitted; returns ""
+// when nothing is left.
+func renderHieraNodeData(hieraData *HieraNodeData, instance *cloud.Instance) string {
+	keys := make([]string, 0, len(hieraData.Keys))
+	for key, column := range hieraData.Keys {
+		if csvColumnValue(instance, column) != "" {
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) == 0 {
+		return ""
+	}
+	sort.Strings(keys)
+
+	var content strings.Builder
+	content.WriteString("---\n")
+	for _, key := range keys {
+		fmt.Fprintf(&content, "%s: %s\n", key, yamlScalar(csvColumnValue(instance, hieraData.Keys[key])))
+	}
+	return content.String()
+}
+
+// generateHieraNodeDataScript generates shell script to write the
+// node-local hiera data file from CSV data, before the first agent run so
+// the initial catalog already sees it.
+//
+// Returns empty string if no mapping is configured.
+func (pi *PuppetInstaller) generateHieraNodeDataScript(instance *cloud.Instance) string {
+	if pi.hieraNodeData == nil || instance == nil {
+		return ""
+	}
+	content := renderHieraNodeData(pi.hieraNodeData, instance)
+	if content == "" {
+		return "# hiera node data skipped: no CSV values for the configured keys\n"
+	}
+
+	file := pi.hieraNodeData.path()
+	return fmt.Sprintf(`# ============================================================
+# Creating node-local hiera data from CSV data
+# ============================================================
+echo "Creating hiera node data..."
+mkdir -p %[3]s
+cat > %[1]s << 'HIERA_NODE_DATA_EOF'
+%[2]sHIERA_NODE_DATA_EOF
+chmod 644 %[1]s
+echo "  ✓ Created %[1]s"
+`, file, content, path.Dir(file))
+}
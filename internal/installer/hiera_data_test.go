@@ -0,0 +1,168 @@
+package installer
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/estudosdevops/opsmaster/internal/cloud"
+)
+
+func TestLoadHieraNodeDataFromYAML(t *testing.T) {
+	tests := []struct {
+		name        string
+		content     string
+		wantPath    string
+		expectError string
+	}{
+		{
+			name: "keys with default path",
+			content: `keys:
+  profile::app::tier: tier
+  profile::nginx::worker_processes: nginx_workers
+`,
+			wantPath: DefaultHieraNodeDataPath,
+		},
+		{
+			name:     "custom path",
+			content:  "path: /etc/puppetlabs/facter/hiera-node.yaml\nkeys:\n  role: role\n",
+			wantPath: "/etc/puppetlabs/facter/hiera-node.yaml",
+		},
+		{name: "no keys", content: "path: /etc/hiera-node.yaml\n", expectError: "no keys defined"},
+		{name: "unknown section", content: "data:\n  role: role\n", expectError: "field data not found"},
+		{name: "relative path", content: "path: hiera-node.yaml\nkeys:\n  role: role\n", expectError: "invalid hiera node data path"},
+		{name: "path traversal", content: "path: /etc/../root/x.yaml\nkeys:\n  role: role\n", expectError: "invalid hiera node data path"},
+		{name: "invalid key", content: "keys:\n  \"profile:app\": role\n", expectError: `invalid hiera key "profile:app"`},
+		{name: "invalid column", content: "keys:\n  role: \"ro le\"\n", expectError: "invalid CSV column"},
+		{name: "missing column", content: "keys:\n  role: \"\"\n", expectError: "CSV column is required"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// ARRANGE
+			path, cleanup := createTempYAMLFile(t, tt.content)
+			defer cleanup()
+
+			// ACT
+			hieraData, err := LoadHieraNodeDataFromYAML(path)
+
+			// ASSERT
+			if tt.expectError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectError) {
+					t.Fatalf("error = %v, want containing %q", err, tt.expectError)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := hieraData.path(); got != tt.wantPath {
+				t.Errorf("path() = %q, want %q", got, tt.wantPath)
+			}
+		})
+	}
+}
+
+func TestValidateHieraNodeDataColumns(t *testing.T) {
+	hieraData := &HieraNodeData{Keys: map[string]string{
+		"profile::app::tier": "tier", "profile::app::region": "region", "profile::app::owner": "owner",
+	}}
+	instance := &cloud.Instance{Region: "us-east-1", Metadata: map[string]string{"tier": "web"}}
+
+	missing := ValidateHieraNodeDataColumns(hieraData, instance)
+
+	if len(missing) != 1 || missing[0] != "owner" {
+		t.Errorf("missing = %v, want [owner]", missing)
+	}
+}
+
+func TestGenerateHieraNodeDataScript(t *testing.T) {
+	pi := NewPuppetInstaller(PuppetOptions{
+		Server: "puppet.example.com",
+		HieraNodeData: &HieraNodeData{Keys: map[string]string{
+			"profile::app::tier":               "tier",
+			"profile::nginx::worker_processes": "nginx_workers",
+			"profile::app::region":             "region",
+			"profile::app::owner":              "owner",
+		}},
+	})
+	instance := &cloud.Instance{
+		Region:   "us-east-1",
+		Metadata: map[string]string{"tier": "web", "nginx_workers": "4"},
+	}
+
+	script := pi.generateHieraNodeDataScript(instance)
+
+	for _, want := range []string{
+		"mkdir -p /etc\n",
+		"cat > /etc/hiera-node.yaml << 'HIERA_NODE_DATA_EOF'\n---\n" +
+			"profile::app::region: us-east-1\nprofile::app::tier: web\nprofile::nginx::worker_processes: 4\nHIERA_NODE_DATA_EOF\n",
+		"chmod 644 /etc/hiera-node.yaml",
+	} {
+		if !strings.Contains(script, want) {
+			t.Errorf("script missing %q:\n%s", want, script)
+		}
+	}
+	if strings.Contains(script, "profile::app::owner") {
+		t.Error("keys with empty CSV values should be omitted")
+	}
+
+	// Without any value the file is not written
+	script = pi.generateHieraNodeDataScript(&cloud.Instance{})
+	if strings.Contains(script, "cat >") {
+		t.Errorf("expected no hiera data file without values, got:\n%s", script)
+	}
+
+	// Disabled by default
+	if got := NewPuppetInstaller(PuppetOptions{}).generateHieraNodeDataScript(instance); got != "" {
+		t.Errorf("expected empty script without mapping, got:\n%s", got)
+	}
+}
+
+// TestGenerateHieraNodeDataScript_HostileCSV runs the generated script and
+// checks that hostile CSV values round-trip as YAML strings.
+func TestGenerateHieraNodeDataScript_HostileCSV(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+
+	hostile := "x\" $(touch pwned) `id` 'q': HIERA_NODE_DATA_EOF"
+	dir := t.TempDir()
+	pi := NewPuppetInstaller(PuppetOptions{
+		Server:        "puppet.example.com",
+		HieraNodeData: &HieraNodeData{Path: "/etc/hiera-node.yaml", Keys: map[string]string{"profile::app::tier": "tier"}},
+	})
+	instance := &cloud.Instance{Metadata: map[string]string{"tier": hostile + "\n"}}
+
+	// Control characters are rejected before any script is generated
+	if err := pi.checkScriptInputs("web01", instance); err == nil || !strings.Contains(err.Error(), "CSV column tier") {
+		t.Fatalf("expected newline in CSV value to be rejected, got %v", err)
+	}
+
+	instance.Metadata["tier"] = hostile
+	script := strings.ReplaceAll(pi.generateHieraNodeDataScript(instance), "/etc", dir)
+	cmd := exec.Command("sh", "-c", script)
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("script failed: %v\n%s", err, out)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "pwned")); err == nil {
+		t.Fatal("CSV value was executed by the shell")
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "hiera-node.yaml"))
+	if err != nil {
+		t.Fatalf("hiera data file not written: %v", err)
+	}
+	var parsed map[string]string
+	if err := yaml.Unmarshal(data, &parsed); err != nil {
+		t.Fatalf("invalid YAML: %v\n%s", err, data)
+	}
+	if parsed["profile::app::tier"] != hostile {
+		t.Errorf("profile::app::tier = %q, want %q", parsed["profile::app::tier"], hostile)
+	}
+}
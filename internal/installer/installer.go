@@ -43,10 +43,10 @@ type PackageInstaller interface {
 	GetFailureTags(err error) map[string]string
 
 	// GetInstallMetadata returns metadata from the last installation attempt.
-	// E.g., os=rhel, certname=abc123.puppet, certname_preserved=true
+	// E.g., OS=rhel, Certname=abc123.puppet, CertnamePreserved=true
 	// Used for reporting and auditing purposes.
-	// Returns empty map if no installation attempt was made yet.
-	GetInstallMetadata() map[string]string
+	// Returns empty metadata if no installation attempt was made yet.
+	GetInstallMetadata() *InstallMetadata
 }
 
 // InstallOptions contains generic installation options.
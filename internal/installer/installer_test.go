@@ -71,11 +71,8 @@ func (m *mockPackageInstaller) GetFailureTags(_ error) map[string]string {
 }
 
 // GetInstallMetadata implements PackageInstaller interface
-func (m *mockPackageInstaller) GetInstallMetadata() map[string]string {
-	if m.metadata != nil {
-		return m.metadata
-	}
-	return map[string]string{}
+func (m *mockPackageInstaller) GetInstallMetadata() *InstallMetadata {
+	return MetadataFromMap(m.metadata)
 }
 
 // ============================================================
@@ -398,12 +395,12 @@ func TestPackageInstaller_GetInstallMetadata(t *testing.T) {
 				t.Fatal("GetInstallMetadata() returned nil")
 			}
 
-			if tt.expectedNotEmpty && len(metadata) == 0 {
+			if tt.expectedNotEmpty && metadata.IsEmpty() {
 				t.Error("Expected non-empty metadata")
 			}
 
-			if !tt.expectedNotEmpty && len(metadata) > 0 {
-				t.Errorf("Expected empty metadata, got %v", metadata)
+			if !tt.expectedNotEmpty && !metadata.IsEmpty() {
+				t.Errorf("Expected empty metadata, got %v", metadata.Map())
 			}
 		})
 	}
@@ -0,0 +1,189 @@
+package installer
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"strconv"
+	"time"
+)
+
+// MetadataSchemaVersion is the version of the InstallMetadata JSON schema.
+// Bump it when a field is renamed or changes meaning; new fields are compatible.
+const MetadataSchemaVersion = 1
+
+// Well-known metadata keys (flat map form and Get/Set).
+const (
+	MetadataKeyOS                = "os"
+	MetadataKeyShell             = "shell"
+	MetadataKeyCertname          = "certname"
+	MetadataKeyCertnamePreserved = "certname_preserved"
+	MetadataKeyFirstRunSplay     = "first_run_splay"
+
+	// MetadataKeyVerifiedLate holds (in Extra) how long after the first
+	// failed check verification passed, when it needed the grace period.
+	MetadataKeyVerifiedLate = "verified_late"
+
+	// MetadataKeyAgentLockAge holds (in Extra) the age of the puppet agent
+	// run lock found on the instance before the install script ran.
+	MetadataKeyAgentLockAge = "agent_lock_age"
+)
+
+// InstallMetadata describes one installation attempt. It is produced by
+// installers and consumed by the executor, presenters and report writers.
+//
+// Fields cover facts shared across installers; installer-specific values go
+// in Extra so new installers don't need schema changes.
+type InstallMetadata struct {
+	OS                string            // Detected operating system (debian, rhel)
+	Shell             string            // Shell detected on the instance (bash, sh, busybox)
+	Certname          string            // Puppet certname used for the installation
+	CertnamePreserved bool              // Certname reused from an existing puppet.conf
+	FirstRunSplay     time.Duration     // Sleep chosen before the initial agent run
+	Extra             map[string]string // Installer-specific values
+}
+
+// MetadataFromMap builds InstallMetadata from the flat key/value form.
+// Unknown keys are kept in Extra.
+func MetadataFromMap(values map[string]string) *InstallMetadata {
+	metadata := &InstallMetadata{}
+	for key, value := range values {
+		metadata.Set(key, value)
+	}
+	return metadata
+}
+
+// Get returns the value for a key as string ("" if unset).
+// Safe to call on nil metadata.
+func (m *InstallMetadata) Get(key string) string {
+	if m == nil {
+		return ""
+	}
+
+	switch key {
+	case MetadataKeyOS:
+		return m.OS
+	case MetadataKeyShell:
+		return m.Shell
+	case MetadataKeyCertname:
+		return m.Certname
+	case MetadataKeyCertnamePreserved:
+		if m.Certname == "" && !m.CertnamePreserved {
+			return ""
+		}
+		return strconv.FormatBool(m.CertnamePreserved)
+	case MetadataKeyFirstRunSplay:
+		if m.FirstRunSplay == 0 {
+			return ""
+		}
+		return m.FirstRunSplay.String()
+	default:
+		return m.Extra[key]
+	}
+}
+
+// Set stores a value by key, parsing well-known keys into typed fields.
+// Values that don't parse are kept in Extra to avoid losing information.
+func (m *InstallMetadata) Set(key, value string) {
+	switch key {
+	case MetadataKeyOS:
+		m.OS = value
+	case MetadataKeyShell:
+		m.Shell = value
+	case MetadataKeyCertname:
+		m.Certname = value
+	case MetadataKeyCertnamePreserved:
+		m.CertnamePreserved = value == "true"
+	case MetadataKeyFirstRunSplay:
+		splay, err := time.ParseDuration(value)
+		if err != nil {
+			m.setExtra(key, value)
+			return
+		}
+		m.FirstRunSplay = splay
+	default:
+		m.setExtra(key, value)
+	}
+}
+
+func (m *InstallMetadata) setExtra(key, value string) {
+	if m.Extra == nil {
+		m.Extra = make(map[string]string)
+	}
+	m.Extra[key] = value
+}
+
+// Map returns the flat key/value form (e.g., for tags or CSV columns).
+// Unset fields are omitted.
+func (m *InstallMetadata) Map() map[string]string {
+	values := make(map[string]string)
+	if m == nil {
+		return values
+	}
+
+	maps.Copy(values, m.Extra)
+	for _, key := range []string{MetadataKeyOS, MetadataKeyShell, MetadataKeyCertname, MetadataKeyCertnamePreserved, MetadataKeyFirstRunSplay} {
+		if value := m.Get(key); value != "" {
+			values[key] = value
+		}
+	}
+	return values
+}
+
+// IsEmpty returns true if no field is set. Safe to call on nil metadata.
+func (m *InstallMetadata) IsEmpty() bool {
+	return len(m.Map()) == 0
+}
+
+// metadataJSON is the versioned wire format of InstallMetadata.
+type metadataJSON struct {
+	SchemaVersion     int               `json:"schema_version"`
+	OS                string            `json:"os,omitempty"`
+	Shell             string            `json:"shell,omitempty"`
+	Certname          string            `json:"certname,omitempty"`
+	CertnamePreserved bool              `json:"certname_preserved,omitempty"`
+	FirstRunSplay     string            `json:"first_run_splay,omitempty"`
+	Extra             map[string]string `json:"extra,omitempty"`
+}
+
+// MarshalJSON encodes metadata with its schema version, so report consumers
+// can detect format changes.
+func (m InstallMetadata) MarshalJSON() ([]byte, error) {
+	return json.Marshal(metadataJSON{
+		SchemaVersion:     MetadataSchemaVersion,
+		OS:                m.OS,
+		Shell:             m.Shell,
+		Certname:          m.Certname,
+		CertnamePreserved: m.CertnamePreserved,
+		FirstRunSplay:     m.Get(MetadataKeyFirstRunSplay),
+		Extra:             m.Extra,
+	})
+}
+
+// UnmarshalJSON decodes metadata, rejecting schema versions newer than this build.
+func (m *InstallMetadata) UnmarshalJSON(data []byte) error {
+	var wire metadataJSON
+	if err := json.Unmarshal(data, &wire); err != nil {
+		return err
+	}
+	if wire.SchemaVersion > MetadataSchemaVersion {
+		return fmt.Errorf("unsupported install metadata schema version %d (max supported: %d)",
+			wire.SchemaVersion, MetadataSchemaVersion)
+	}
+
+	*m = InstallMetadata{
+		OS:                wire.OS,
+		Shell:             wire.Shell,
+		Certname:          wire.Certname,
+		CertnamePreserved: wire.CertnamePreserved,
+		Extra:             wire.Extra,
+	}
+	if wire.FirstRunSplay != "" {
+		splay, err := time.ParseDuration(wire.FirstRunSplay)
+		if err != nil {
+			return fmt.Errorf("invalid first_run_splay %q: %w", wire.FirstRunSplay, err)
+		}
+		m.FirstRunSplay = splay
+	}
+	return nil
+}
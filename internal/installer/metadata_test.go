@@ -0,0 +1,100 @@
+package installer
+
+import (
+	"encoding/json"
+	"maps"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestMetadataFromMap tests conversion from the flat key/value form
+func TestMetadataFromMap(t *testing.T) {
+	// ARRANGE
+	values := map[string]string{
+		MetadataKeyOS:                "debian",
+		MetadataKeyCertname:          "abc123.puppet",
+		MetadataKeyCertnamePreserved: "true",
+		MetadataKeyFirstRunSplay:     "1m30s",
+		"agent_version":              "7.28.0",
+	}
+
+	// ACT
+	metadata := MetadataFromMap(values)
+
+	// ASSERT
+	if metadata.OS != "debian" || metadata.Certname != "abc123.puppet" || !metadata.CertnamePreserved {
+		t.Errorf("typed fields not populated: %+v", metadata)
+	}
+	if metadata.FirstRunSplay != 90*time.Second {
+		t.Errorf("FirstRunSplay = %s, want 1m30s", metadata.FirstRunSplay)
+	}
+	if metadata.Get("agent_version") != "7.28.0" {
+		t.Errorf("Extra[agent_version] = %q, want 7.28.0", metadata.Get("agent_version"))
+	}
+	if got := metadata.Map(); !maps.Equal(got, values) {
+		t.Errorf("Map() = %v, want %v", got, values)
+	}
+}
+
+// TestInstallMetadata_NilSafe tests accessors on metadata of instances that never installed
+func TestInstallMetadata_NilSafe(t *testing.T) {
+	var metadata *InstallMetadata
+
+	if metadata.Get(MetadataKeyOS) != "" {
+		t.Error("Get() on nil metadata should return empty string")
+	}
+	if !metadata.IsEmpty() {
+		t.Error("IsEmpty() on nil metadata should be true")
+	}
+	if len(metadata.Map()) != 0 {
+		t.Error("Map() on nil metadata should be empty")
+	}
+}
+
+// TestInstallMetadata_JSON tests the versioned JSON format used in reports.
+//
+// 🎓 CONCEPT: Schema versioning
+// Consumers read schema_version to detect format changes; newer versions are rejected.
+func TestInstallMetadata_JSON(t *testing.T) {
+	t.Run("round trip with schema version", func(t *testing.T) {
+		// ARRANGE
+		original := InstallMetadata{
+			OS:                "rhel",
+			Shell:             ShellBash,
+			Certname:          "abc123.puppet",
+			CertnamePreserved: true,
+			FirstRunSplay:     2 * time.Minute,
+			Extra:             map[string]string{"agent_version": "8.4.0"},
+		}
+
+		// ACT
+		data, err := json.Marshal(original)
+		if err != nil {
+			t.Fatalf("Marshal() error: %v", err)
+		}
+		var decoded InstallMetadata
+		err = json.Unmarshal(data, &decoded)
+
+		// ASSERT
+		if err != nil {
+			t.Fatalf("Unmarshal() error: %v", err)
+		}
+		if !strings.Contains(string(data), `"schema_version":1`) {
+			t.Errorf("expected schema_version in JSON, got %s", data)
+		}
+		if !strings.Contains(string(data), `"first_run_splay":"2m0s"`) {
+			t.Errorf("expected first_run_splay as duration string, got %s", data)
+		}
+		if !maps.Equal(decoded.Map(), original.Map()) {
+			t.Errorf("round trip = %v, want %v", decoded.Map(), original.Map())
+		}
+	})
+
+	t.Run("rejects newer schema", func(t *testing.T) {
+		var decoded InstallMetadata
+		if err := json.Unmarshal([]byte(`{"schema_version":99,"os":"debian"}`), &decoded); err == nil {
+			t.Error("expected error for unsupported schema version")
+		}
+	})
+}
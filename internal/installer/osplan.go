@@ -0,0 +1,88 @@
+package installer
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/estudosdevops/opsmaster/internal/cloud"
+)
+
+// OSTypeWindows is the script family of Windows instances (PowerShell).
+const OSTypeWindows = "windows"
+
+// OSFamilyUnknown groups instances whose OS is only known after remote
+// detection (no CSV "os" column value nor Windows platform metadata).
+const OSFamilyUnknown = "unknown"
+
+// ScriptFamily returns the install script family of an instance, known
+// before any remote call: OSTypeDebian or OSTypeRHEL from the CSV "os"
+// column, OSTypeWindows from the platform metadata, otherwise OSFamilyUnknown.
+func ScriptFamily(instance *cloud.Instance) string {
+	if strings.EqualFold(instance.Metadata["platform"], cloud.PlatformWindows) {
+		return OSTypeWindows
+	}
+	if normalized, err := normalizeOS(instance.Metadata["os"]); err == nil {
+		return normalized
+	}
+	return OSFamilyUnknown
+}
+
+// ParseOSFamily normalizes an OS family selector (e.g., --only-os): a script
+// family or any OS alias ("ubuntu" selects debian, "amzn" selects rhel).
+func ParseOSFamily(value string) (string, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if value == OSTypeWindows {
+		return OSTypeWindows, nil
+	}
+	if normalized, ok := osAliases[value]; ok {
+		return normalized, nil
+	}
+	return "", fmt.Errorf("unsupported OS family %q (valid: %s, %s, %s or a distribution such as ubuntu, amzn, rocky)",
+		value, OSTypeDebian, OSTypeRHEL, OSTypeWindows)
+}
+
+// OSPlanEntry is the number of instances using one script family.
+type OSPlanEntry struct {
+	Family string // Script family (debian, rhel, windows, unknown)
+	Count  int    // Instances using it
+}
+
+// PlanByOS counts instances per script family, largest first, with
+// OSFamilyUnknown last.
+func PlanByOS(instances []*cloud.Instance) []OSPlanEntry {
+	counts := make(map[string]int)
+	for _, instance := range instances {
+		counts[ScriptFamily(instance)]++
+	}
+
+	plan := make([]OSPlanEntry, 0, len(counts))
+	for family, count := range counts {
+		plan = append(plan, OSPlanEntry{Family: family, Count: count})
+	}
+	sort.Slice(plan, func(i, j int) bool {
+		if (plan[i].Family == OSFamilyUnknown) != (plan[j].Family == OSFamilyUnknown) {
+			return plan[j].Family == OSFamilyUnknown
+		}
+		if plan[i].Count != plan[j].Count {
+			return plan[i].Count > plan[j].Count
+		}
+		return plan[i].Family < plan[j].Family
+	})
+	return plan
+}
+
+// FilterByOS returns the instances of a script family (see ParseOSFamily).
+// Instances of unknown family are never selected, since their script is
+// only chosen after remote detection; their count is returned separately.
+func FilterByOS(instances []*cloud.Instance, family string) (selected []*cloud.Instance, unknown int) {
+	for _, instance := range instances {
+		switch ScriptFamily(instance) {
+		case family:
+			selected = append(selected, instance)
+		case OSFamilyUnknown:
+			unknown++
+		}
+	}
+	return selected, unknown
+}
@@ -0,0 +1,112 @@
+package installer
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/estudosdevops/opsmaster/internal/cloud"
+)
+
+func osPlanInstance(id, os, platform string) *cloud.Instance {
+	return &cloud.Instance{ID: id, Metadata: map[string]string{"os": os, "platform": platform}}
+}
+
+// TestScriptFamily tests script family resolution from CSV os and platform metadata.
+func TestScriptFamily(t *testing.T) {
+	tests := []struct {
+		name     string
+		instance *cloud.Instance
+		expected string
+	}{
+		{"ubuntu column", osPlanInstance("i-1", "ubuntu", ""), OSTypeDebian},
+		{"amzn column", osPlanInstance("i-2", "Amzn", ""), OSTypeRHEL},
+		{"windows platform", osPlanInstance("i-3", "", "windows"), OSTypeWindows},
+		{"windows wins over column", osPlanInstance("i-4", "ubuntu", "Windows"), OSTypeWindows},
+		{"no os column", osPlanInstance("i-5", "", ""), OSFamilyUnknown},
+		{"unrecognized os column", osPlanInstance("i-6", "solaris", ""), OSFamilyUnknown},
+		{"nil metadata", &cloud.Instance{ID: "i-7"}, OSFamilyUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ScriptFamily(tt.instance); got != tt.expected {
+				t.Errorf("ScriptFamily() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+// TestParseOSFamily tests --only-os value normalization.
+func TestParseOSFamily(t *testing.T) {
+	tests := []struct {
+		value       string
+		expected    string
+		expectError bool
+	}{
+		{"rhel", OSTypeRHEL, false},
+		{" Ubuntu ", OSTypeDebian, false},
+		{"rocky", OSTypeRHEL, false},
+		{"windows", OSTypeWindows, false},
+		{"unknown", "", true},
+		{"", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseOSFamily(tt.value)
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("ParseOSFamily(%q) expected error", tt.value)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseOSFamily(%q) unexpected error: %v", tt.value, err)
+			}
+			if got != tt.expected {
+				t.Errorf("ParseOSFamily(%q) = %q, want %q", tt.value, got, tt.expected)
+			}
+		})
+	}
+}
+
+// TestPlanByOS tests per-family counts and their ordering.
+func TestPlanByOS(t *testing.T) {
+	instances := []*cloud.Instance{
+		osPlanInstance("i-1", "", ""),
+		osPlanInstance("i-2", "", ""),
+		osPlanInstance("i-3", "", ""),
+		osPlanInstance("i-4", "centos", ""),
+		osPlanInstance("i-5", "ubuntu", ""),
+		osPlanInstance("i-6", "debian", ""),
+		osPlanInstance("i-7", "", "windows"),
+	}
+
+	expected := []OSPlanEntry{
+		{Family: OSTypeDebian, Count: 2},
+		{Family: OSTypeRHEL, Count: 1},
+		{Family: OSTypeWindows, Count: 1},
+		{Family: OSFamilyUnknown, Count: 3},
+	}
+	if got := PlanByOS(instances); !reflect.DeepEqual(got, expected) {
+		t.Errorf("PlanByOS() = %+v, want %+v", got, expected)
+	}
+}
+
+// TestFilterByOS tests --only-os targeting and the unknown count.
+func TestFilterByOS(t *testing.T) {
+	instances := []*cloud.Instance{
+		osPlanInstance("i-1", "rocky", ""),
+		osPlanInstance("i-2", "ubuntu", ""),
+		osPlanInstance("i-3", "", ""),
+		osPlanInstance("i-4", "amzn", ""),
+	}
+
+	selected, unknown := FilterByOS(instances, OSTypeRHEL)
+	if len(selected) != 2 || selected[0].ID != "i-1" || selected[1].ID != "i-4" {
+		t.Errorf("FilterByOS() selected %+v, want i-1 and i-4", selected)
+	}
+	if unknown != 1 {
+		t.Errorf("FilterByOS() unknown = %d, want 1", unknown)
+	}
+}
@@ -0,0 +1,314 @@
+package installer
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/estudosdevops/opsmaster/internal/cloud"
+	"github.com/estudosdevops/opsmaster/internal/validator"
+)
+
+// osquery paths installed by the official packages.
+const (
+	osqueryConfigDir    = "/etc/osquery"
+	osqueryFlagsFile    = osqueryConfigDir + "/osquery.flags"
+	osquerySecretFile   = osqueryConfigDir + "/enroll_secret"
+	osqueryServerCerts  = osqueryConfigDir + "/fleet.pem"
+	osqueryWarningLog   = "/var/log/osquery/osqueryd.WARNING"
+	osqueryDefaultPort  = 443
+	osqueryEnrollWindow = 15 * time.Second
+)
+
+// osquerySecretEnv carries the enroll secret to the write-config step.
+// Environment keeps the secret out of the script body (logs, dry-run output).
+const osquerySecretEnv = "OSQUERY_ENROLL_SECRET"
+
+// Valid --host_identifier values.
+var osqueryHostIdentifiers = []string{"instance", "uuid", "hostname"}
+
+// OsqueryOptions contains osquery installation options.
+type OsqueryOptions struct {
+	FleetURL       string // Fleet/TLS server, "host[:port]" or https:// URL (required)
+	HostIdentifier string // osquery --host_identifier (default: "instance")
+	ServerCerts    string // PEM bundle trusted for the TLS server (default: system CAs)
+
+	// The enroll secret comes from exactly one of these
+	EnrollSecret          string // Secret value (already resolved from vault: references)
+	EnrollSecretParameter string // Secret store parameter resolved on the instance (AWS: SSM Parameter Store)
+}
+
+// Validate checks the options and normalizes FleetURL to host:port.
+func (o *OsqueryOptions) Validate() error {
+	if o.FleetURL == "" {
+		return fmt.Errorf("osquery requires a fleet server")
+	}
+	hostPort, err := osqueryHostPort(o.FleetURL)
+	if err != nil {
+		return err
+	}
+	o.FleetURL = hostPort
+
+	if (o.EnrollSecret == "") == (o.EnrollSecretParameter == "") {
+		return fmt.Errorf("osquery requires exactly one of enroll secret or enroll secret parameter")
+	}
+	if o.HostIdentifier != "" && !containsString(osqueryHostIdentifiers, o.HostIdentifier) {
+		return fmt.Errorf("invalid osquery host identifier %q (valid: %s)", o.HostIdentifier, strings.Join(osqueryHostIdentifiers, ", "))
+	}
+	if o.ServerCerts != "" && !strings.Contains(o.ServerCerts, "-----BEGIN CERTIFICATE-----") {
+		return fmt.Errorf("osquery server certs must be a PEM bundle")
+	}
+	return nil
+}
+
+// osqueryHostPort normalizes "https://fleet.example.com[:port][/]" or
+// "fleet.example.com[:port]" to host:port (osquery --tls_hostname format).
+func osqueryHostPort(server string) (string, error) {
+	hostPort := strings.TrimSuffix(strings.TrimPrefix(server, "https://"), "/")
+	if strings.Contains(hostPort, "://") || strings.Contains(hostPort, "/") {
+		return "", fmt.Errorf("invalid fleet server %q: use host[:port] or an https:// URL without path", server)
+	}
+
+	host, port, err := net.SplitHostPort(hostPort)
+	if err != nil {
+		host, port = hostPort, strconv.Itoa(osqueryDefaultPort)
+	}
+	if n, err := strconv.Atoi(port); host == "" || err != nil || n < 1 || n > 65535 {
+		return "", fmt.Errorf("invalid fleet server %q", server)
+	}
+	return net.JoinHostPort(host, port), nil
+}
+
+// OsqueryInstaller implements PackageInstaller for osquery enrolled in a
+// Fleet (or any osquery TLS) server. Implements StepBasedInstaller so the
+// enroll secret is passed as step environment instead of script text.
+type OsqueryInstaller struct {
+	opts         OsqueryOptions
+	lastMetadata *InstallMetadata
+}
+
+func init() {
+	Register(Descriptor{
+		Name:          "osquery",
+		OSFamilies:    []string{OSTypeDebian, OSTypeRHEL},
+		Architectures: []string{"amd64", "arm64"},
+		New:           func() PackageInstaller { return NewOsqueryInstaller(OsqueryOptions{}) },
+	})
+}
+
+// NewOsqueryInstaller creates a new osquery installer.
+// Call opts.Validate first.
+func NewOsqueryInstaller(opts OsqueryOptions) *OsqueryInstaller {
+	if opts.HostIdentifier == "" {
+		opts.HostIdentifier = "instance"
+	}
+	return &OsqueryInstaller{opts: opts, lastMetadata: &InstallMetadata{}}
+}
+
+// Name returns the package name
+func (*OsqueryInstaller) Name() string {
+	return "osquery"
+}
+
+// GenerateInstallSteps implements StepBasedInstaller. The package manager is
+// detected on the instance, so the CSV "os" column is not required.
+func (oi *OsqueryInstaller) GenerateInstallSteps(_ string, _ map[string]string) ([]InstallStep, error) {
+	configure := InstallStep{
+		Name:     "configure",
+		Commands: []string{oi.configureScript()},
+	}
+	if oi.opts.EnrollSecretParameter != "" {
+		configure.SecretEnv = map[string]string{osquerySecretEnv: oi.opts.EnrollSecretParameter}
+	} else {
+		configure.Env = map[string]string{osquerySecretEnv: oi.opts.EnrollSecret}
+	}
+
+	return []InstallStep{
+		{Name: "install-package", Commands: []string{osqueryPackageScript}},
+		configure,
+		{Name: "start-service", Commands: []string{osqueryServiceScript}},
+	}, nil
+}
+
+// GenerateInstallScript returns all steps as one script. The enroll secret
+// must be exported as OSQUERY_ENROLL_SECRET by the caller.
+func (oi *OsqueryInstaller) GenerateInstallScript(os string, options map[string]string) ([]string, error) {
+	steps, err := oi.GenerateInstallSteps(os, options)
+	if err != nil {
+		return nil, err
+	}
+	var commands []string
+	for _, step := range steps {
+		commands = append(commands, step.Commands...)
+	}
+	return commands, nil
+}
+
+// osqueryPackageScript adds the official repository for the available
+// package manager and installs osquery.
+const osqueryPackageScript = `#!/bin/sh
+` + downloadShellFunc + `
+if command -v apt-get >/dev/null 2>&1; then
+    echo "Configuring osquery apt repository..."
+    install -d -m 0755 /usr/share/keyrings
+    if ! download https://pkg.osquery.io/deb/pubkey.gpg | gpg --batch --yes --dearmor -o /usr/share/keyrings/osquery.gpg; then
+        echo "Error importing osquery repository key"
+        exit 20
+    fi
+    echo "deb [arch=$(dpkg --print-architecture) signed-by=/usr/share/keyrings/osquery.gpg] https://pkg.osquery.io/deb deb main" > /etc/apt/sources.list.d/osquery.list
+    apt-get update -qq || { echo "Error updating package cache"; exit 20; }
+    DEBIAN_FRONTEND=noninteractive apt-get install -y osquery || { echo "Error installing osquery package"; exit 30; }
+elif command -v yum >/dev/null 2>&1; then
+    echo "Configuring osquery yum repository..."
+    if ! download https://pkg.osquery.io/rpm/osquery-s3-rpm.repo > /etc/yum.repos.d/osquery-s3-rpm.repo; then
+        echo "Error downloading osquery repository definition"
+        exit 20
+    fi
+    yum install -y osquery || { echo "Error installing osquery package"; exit 30; }
+else
+    echo "ERROR: no supported package manager (apt-get or yum)"
+    exit 10
+fi
+echo "✓ osquery package installed"
+`
+
+// configureScript writes the enroll secret (from the environment) and the
+// flags file pointing osqueryd at the TLS server.
+func (oi *OsqueryInstaller) configureScript() string {
+	flags := oi.flagsFile()
+
+	script := `#!/bin/sh
+if [ -z "${` + osquerySecretEnv + `}" ]; then
+    echo "ERROR: enroll secret is empty"
+    exit 10
+fi
+mkdir -p ` + osqueryConfigDir + `
+umask 077
+printf '%s' "${` + osquerySecretEnv + `}" > ` + osquerySecretFile + `
+chmod 600 ` + osquerySecretFile + `
+`
+	if oi.opts.ServerCerts != "" {
+		script += `echo '` + base64.StdEncoding.EncodeToString([]byte(oi.opts.ServerCerts)) + `' | base64 -d > ` + osqueryServerCerts + `
+chmod 644 ` + osqueryServerCerts + `
+`
+	}
+	script += `echo '` + base64.StdEncoding.EncodeToString([]byte(flags)) + `' | base64 -d > ` + osqueryFlagsFile + `
+chmod 644 ` + osqueryFlagsFile + `
+echo "✓ osquery configured for ` + oi.opts.FleetURL + `"
+`
+	return script
+}
+
+// flagsFile renders osquery.flags for the Fleet TLS endpoints.
+func (oi *OsqueryInstaller) flagsFile() string {
+	flags := []string{
+		"--tls_hostname=" + oi.opts.FleetURL,
+		"--enroll_secret_path=" + osquerySecretFile,
+		"--host_identifier=" + oi.opts.HostIdentifier,
+		"--enroll_tls_endpoint=/api/osquery/enroll",
+		"--config_plugin=tls",
+		"--config_tls_endpoint=/api/osquery/config",
+		"--config_refresh=10",
+		"--logger_plugin=tls",
+		"--logger_tls_endpoint=/api/osquery/log",
+		"--logger_tls_period=10",
+		"--disable_distributed=false",
+		"--distributed_plugin=tls",
+		"--distributed_interval=10",
+		"--distributed_tls_max_attempts=3",
+		"--distributed_tls_read_endpoint=/api/osquery/distributed/read",
+		"--distributed_tls_write_endpoint=/api/osquery/distributed/write",
+	}
+	if oi.opts.ServerCerts != "" {
+		flags = append(flags, "--tls_server_certs="+osqueryServerCerts)
+	}
+	return strings.Join(flags, "\n") + "\n"
+}
+
+// osqueryServiceScript enables and (re)starts osqueryd.
+const osqueryServiceScript = `#!/bin/sh
+systemctl enable osqueryd
+if ! systemctl restart osqueryd; then
+    echo "Error starting osqueryd service"
+    journalctl -u osqueryd -n 20 --no-pager 2>/dev/null
+    exit 40
+fi
+echo "✓ osqueryd running"
+`
+
+// ValidatePrerequisites checks SSM connectivity and that the instance
+// reaches the fleet server.
+func (oi *OsqueryInstaller) ValidatePrerequisites(ctx context.Context, instance *cloud.Instance, provider cloud.CloudProvider) error {
+	host, portStr, err := net.SplitHostPort(oi.opts.FleetURL)
+	if err != nil {
+		return fmt.Errorf("invalid fleet server %q: %w", oi.opts.FleetURL, err)
+	}
+	port, _ := strconv.Atoi(portStr)
+
+	results := validator.NewCompositeValidator([]validator.Validator{
+		validator.NewSSMValidator(0),
+		validator.NewConnectivityValidator("fleet_server_reachable", host, port, 0),
+	}, false).Validate(ctx, instance, provider)
+
+	if !validator.AllPassed(results) {
+		var failures []string
+		for _, failed := range validator.GetFailedValidations(results) {
+			failures = append(failures, fmt.Sprintf("%s: %s", failed.Name, failed.Message))
+		}
+		return fmt.Errorf("osquery prerequisites validation failed:\n  - %s", strings.Join(failures, "\n  - "))
+	}
+	return nil
+}
+
+// VerifyInstallation checks that osqueryd is running and enrolled: after the
+// enrollment window, the current osqueryd warning log must not report
+// enrollment failures (wrong secret, TLS errors).
+func (*OsqueryInstaller) VerifyInstallation(ctx context.Context, instance *cloud.Instance, provider cloud.CloudProvider) error {
+	verifyCommands := []string{
+		"command -v osqueryd >/dev/null || exit 1",
+		"osqueryd --version || exit 2",
+		"systemctl is-active --quiet osqueryd || exit 3",
+		fmt.Sprintf("sleep %d", int(osqueryEnrollWindow.Seconds())),
+		"systemctl is-active --quiet osqueryd || exit 3",
+		"if grep -i 'enroll' " + osqueryWarningLog + " 2>/dev/null | tail -3 | grep .; then exit 5; fi",
+	}
+
+	result, err := provider.ExecuteCommand(ctx, instance, verifyCommands, DefaultSSMTimeout)
+	if err != nil {
+		return fmt.Errorf("failed to verify osquery installation: %w", err)
+	}
+
+	switch result.ExitCode {
+	case 0:
+		return nil
+	case 5:
+		return fmt.Errorf("osqueryd is running but enrollment failed: %s", strings.TrimSpace(result.Stdout))
+	default:
+		return fmt.Errorf("osquery verification failed (exit code %d):\nstdout: %s\nstderr: %s",
+			result.ExitCode, result.Stdout, result.Stderr)
+	}
+}
+
+// GetSuccessTags returns tags to apply after successful installation.
+func (*OsqueryInstaller) GetSuccessTags() map[string]string {
+	return map[string]string{
+		"osquery": "true",
+	}
+}
+
+// GetFailureTags returns tags to apply when installation fails.
+func (*OsqueryInstaller) GetFailureTags(_ error) map[string]string {
+	return map[string]string{}
+}
+
+// GetInstallMetadata returns metadata from the last installation attempt.
+func (oi *OsqueryInstaller) GetInstallMetadata() *InstallMetadata {
+	if oi.lastMetadata == nil {
+		return &InstallMetadata{}
+	}
+	return oi.lastMetadata
+}
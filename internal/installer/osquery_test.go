@@ -0,0 +1,161 @@
+package installer
+
+import (
+	"context"
+	"encoding/base64"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/estudosdevops/opsmaster/internal/cloud"
+	"github.com/estudosdevops/opsmaster/internal/cloud/cloudtest"
+)
+
+// TestOsqueryOptions_Validate tests fleet server normalization and secret sources
+func TestOsqueryOptions_Validate(t *testing.T) {
+	tests := []struct {
+		name      string
+		opts      OsqueryOptions
+		wantFleet string
+		wantErr   string
+	}{
+		{name: "host defaults to 443", opts: OsqueryOptions{FleetURL: "fleet.internal", EnrollSecret: "s"}, wantFleet: "fleet.internal:443"},
+		{name: "https URL with port", opts: OsqueryOptions{FleetURL: "https://fleet.internal:8412/", EnrollSecret: "s"}, wantFleet: "fleet.internal:8412"},
+		{name: "secret parameter", opts: OsqueryOptions{FleetURL: "fleet.internal", EnrollSecretParameter: "/fleet/enroll"}, wantFleet: "fleet.internal:443"},
+		{name: "missing fleet", opts: OsqueryOptions{EnrollSecret: "s"}, wantErr: "requires a fleet server"},
+		{name: "URL with path", opts: OsqueryOptions{FleetURL: "https://fleet.internal/api", EnrollSecret: "s"}, wantErr: "without path"},
+		{name: "http scheme", opts: OsqueryOptions{FleetURL: "http://fleet.internal", EnrollSecret: "s"}, wantErr: "invalid fleet server"},
+		{name: "no secret", opts: OsqueryOptions{FleetURL: "fleet.internal"}, wantErr: "exactly one"},
+		{name: "both secrets", opts: OsqueryOptions{FleetURL: "fleet.internal", EnrollSecret: "s", EnrollSecretParameter: "/p"}, wantErr: "exactly one"},
+		{name: "invalid host identifier", opts: OsqueryOptions{FleetURL: "fleet.internal", EnrollSecret: "s", HostIdentifier: "ec2"}, wantErr: "invalid osquery host identifier"},
+		{name: "server certs not PEM", opts: OsqueryOptions{FleetURL: "fleet.internal", EnrollSecret: "s", ServerCerts: "not a cert"}, wantErr: "PEM bundle"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// ACT
+			err := tt.opts.Validate()
+
+			// ASSERT
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.opts.FleetURL != tt.wantFleet {
+				t.Errorf("FleetURL = %q, want %q", tt.opts.FleetURL, tt.wantFleet)
+			}
+		})
+	}
+}
+
+// TestOsqueryInstaller_GenerateInstallSteps tests that the enroll secret never
+// appears in the script text
+func TestOsqueryInstaller_GenerateInstallSteps(t *testing.T) {
+	t.Run("secret value travels as step env", func(t *testing.T) {
+		// ARRANGE
+		oi := NewOsqueryInstaller(OsqueryOptions{FleetURL: "fleet.internal:443", EnrollSecret: "top-secret"})
+
+		// ACT
+		steps, err := oi.GenerateInstallSteps("ubuntu", nil)
+
+		// ASSERT
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		var names []string
+		for _, step := range steps {
+			names = append(names, step.Name)
+			if strings.Contains(strings.Join(step.Commands, "\n"), "top-secret") {
+				t.Errorf("step %s contains the enroll secret", step.Name)
+			}
+		}
+		if strings.Join(names, ",") != "install-package,configure,start-service" {
+			t.Errorf("unexpected steps %v", names)
+		}
+		if steps[1].Env[osquerySecretEnv] != "top-secret" || steps[1].SecretEnv != nil {
+			t.Errorf("unexpected configure env %v / %v", steps[1].Env, steps[1].SecretEnv)
+		}
+	})
+
+	t.Run("secret parameter travels as secret env", func(t *testing.T) {
+		oi := NewOsqueryInstaller(OsqueryOptions{FleetURL: "fleet.internal:443", EnrollSecretParameter: "/fleet/enroll"})
+
+		steps, _ := oi.GenerateInstallSteps("ubuntu", nil)
+
+		if steps[1].SecretEnv[osquerySecretEnv] != "/fleet/enroll" || steps[1].Env != nil {
+			t.Errorf("unexpected configure env %v / %v", steps[1].Env, steps[1].SecretEnv)
+		}
+	})
+}
+
+// TestOsqueryInstaller_FlagsFile tests the rendered osquery.flags
+func TestOsqueryInstaller_FlagsFile(t *testing.T) {
+	// ARRANGE
+	pem := "-----BEGIN CERTIFICATE-----\nMIIB\n-----END CERTIFICATE-----\n"
+	oi := NewOsqueryInstaller(OsqueryOptions{FleetURL: "fleet.internal:8412", EnrollSecret: "s", ServerCerts: pem})
+
+	// ACT
+	flags := oi.flagsFile()
+	script := oi.configureScript()
+
+	// ASSERT
+	for _, want := range []string{
+		"--tls_hostname=fleet.internal:8412",
+		"--enroll_secret_path=/etc/osquery/enroll_secret",
+		"--host_identifier=instance",
+		"--tls_server_certs=/etc/osquery/fleet.pem",
+		"--config_plugin=tls",
+	} {
+		if !strings.Contains(flags, want) {
+			t.Errorf("flags missing %q:\n%s", want, flags)
+		}
+	}
+	if !strings.Contains(script, base64.StdEncoding.EncodeToString([]byte(flags))) ||
+		!strings.Contains(script, base64.StdEncoding.EncodeToString([]byte(pem))) {
+		t.Error("configure script does not embed flags and server certs")
+	}
+}
+
+// TestOsqueryInstaller_VerifyInstallation tests enrollment failure detection
+func TestOsqueryInstaller_VerifyInstallation(t *testing.T) {
+	tests := []struct {
+		name     string
+		result   *cloud.CommandResult
+		wantErr  string
+		wantNone bool
+	}{
+		{name: "running and enrolled", result: &cloud.CommandResult{ExitCode: 0}, wantNone: true},
+		{name: "enrollment failed", result: &cloud.CommandResult{ExitCode: 5, Stdout: "Failed enrollment request to https://fleet.internal:443/api/osquery/enroll\n"}, wantErr: "enrollment failed: Failed enrollment request"},
+		{name: "service not running", result: &cloud.CommandResult{ExitCode: 3}, wantErr: "exit code 3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// ARRANGE
+			provider := &cloudtest.Provider{
+				ExecuteCommandFunc: func(context.Context, *cloud.Instance, []string, time.Duration) (*cloud.CommandResult, error) {
+					return tt.result, nil
+				},
+			}
+
+			// ACT
+			err := NewOsqueryInstaller(OsqueryOptions{}).VerifyInstallation(context.Background(), &cloud.Instance{ID: "i-1"}, provider)
+
+			// ASSERT
+			if tt.wantNone {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
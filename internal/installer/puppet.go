@@ -3,16 +3,25 @@ package installer
 import (
 	"context"
 	"fmt"
+	"net"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
 
 	"github.com/estudosdevops/opsmaster/internal/cloud"
+	"github.com/estudosdevops/opsmaster/internal/csv"
+	"github.com/estudosdevops/opsmaster/internal/logger"
 	"github.com/estudosdevops/opsmaster/internal/retry"
 	"github.com/estudosdevops/opsmaster/internal/validator"
 )
 
+// PuppetServerColumn is the optional CSV column overriding --puppet-server per instance
+// (e.g., fleets split across several Puppet Servers/compilers).
+const PuppetServerColumn = "puppet_server"
+
 // OS type constants for normalized OS detection
 const (
 	OSTypeDebian = "debian"
@@ -22,6 +31,25 @@ const (
 // Default timeout for SSM commands (AWS SSM requires minimum 30 seconds)
 const DefaultSSMTimeout = 30 * time.Second
 
+// repoValidationTimeout bounds each mirror reachability check.
+const repoValidationTimeout = 10 * time.Second
+
+// Puppet service state constants (desired state after installation)
+const (
+	ServiceStateRunning = "running"
+	ServiceStateStopped = "stopped"
+)
+
+// ValidateServiceState checks if the desired puppet service state is supported.
+func ValidateServiceState(state string) error {
+	switch state {
+	case ServiceStateRunning, ServiceStateStopped:
+		return nil
+	default:
+		return fmt.Errorf("invalid service state: %s (valid: %s, %s)", state, ServiceStateRunning, ServiceStateStopped)
+	}
+}
+
 // osAliases maps OS distribution IDs to normalized OS types
 var osAliases = map[string]string{
 	// Debian family
@@ -88,17 +116,40 @@ type FactDefinition struct {
 	// Fields maps CSV column names to fact field names
 	// Example: {"account": "account", "environment": "environment"}
 	Fields map[string]string
+
+	// Environments limits the fact to instances whose environment column is
+	// one of these, case-insensitive (optional, default: every instance)
+	Environments []string
+
+	// When limits the fact to instances matching every selector, in the
+	// --where syntax (optional, e.g. "tier=db")
+	When []*csv.Selector
 }
 
 // PuppetInstaller implements PackageInstaller for Puppet Agent.
 // Supports Debian/Ubuntu and RHEL/Amazon Linux distributions.
 type PuppetInstaller struct {
-	puppetServer  string
-	puppetPort    int
-	puppetVersion string
-	environment   string
-	lastMetadata  map[string]string         // Stores metadata from last installation attempt
-	customFacts   map[string]FactDefinition // Custom facts to create on instances
+	puppetServer    string
+	puppetPort      int
+	puppetVersion   string
+	environment     string
+	lastMetadata    *InstallMetadata          // Stores metadata from last installation attempt
+	customFacts     map[string]FactDefinition // Custom facts to create on instances
+	validationStats *validator.StatsCollector // Per-validator timing stats across instances
+	disableService  bool                      // Leave puppet service disabled at boot (cron-triggered runs)
+	serviceState    string                    // Desired service state: running or stopped
+	remoteWorkdir   string                    // Remote staging directory ("" = /tmp with noexec fallback)
+	firstRunSplay   time.Duration             // Max random sleep before the initial puppet run (0 = disabled)
+	enc             *ENCRegistrar             // Registers nodes in an ENC/CMDB after install (nil = disabled)
+	foreman         *ForemanRegistrar         // Creates/updates Foreman hosts after install (nil = disabled)
+	runID           string                    // Invocation ID sent to ENC/Foreman registrations
+	repo            PuppetRepoOptions         // Internal apt/yum mirrors (zero value = official repos)
+	shell           ShellOptions              // Safety options of the install scripts (zero value = none)
+	csrAttributes   *CSRAttributes            // csr_attributes.yaml mapping (nil = not written)
+	hieraNodeData   *HieraNodeData            // Node-local hiera data mapping (nil = not written)
+	proxy           PuppetProxy               // HTTP proxy of the agent (zero value = direct connections)
+	pruneFacts      bool                      // Remove fact files of earlier runs no longer declared
+	agentLock       AgentLockOptions          // Handling of a stuck agent run lock before the first run
 }
 
 // PuppetOptions contains Puppet-specific installation options.
@@ -108,6 +159,70 @@ type PuppetOptions struct {
 	Version     string                    // Puppet version (default: "7")
 	Environment string                    // Puppet environment (default: "production")
 	CustomFacts map[string]FactDefinition // Custom facts to create on instances (optional)
+
+	// DisableService leaves the puppet service disabled at boot (default: enabled).
+	// Useful for teams that trigger agent runs via cron instead of the daemon.
+	DisableService bool
+
+	// ServiceState is the desired service state after installation (default: "running")
+	ServiceState string
+
+	// RemoteWorkdir is where files are staged on the instance (default: /tmp,
+	// falling back to /var/lib/opsmaster when /tmp is mounted noexec)
+	RemoteWorkdir string
+
+	// FirstRunSplay is the max random sleep before the initial puppet run
+	// (default: 0, disabled). The chosen value is recorded in metadata.
+	FirstRunSplay time.Duration
+
+	// ENC registers installed nodes in an external node classifier/CMDB
+	// (optional, see NewENCRegistrar)
+	ENC *ENCRegistrar
+
+	// Foreman creates/updates the host in Foreman after install
+	// (optional, see NewForemanRegistrar)
+	Foreman *ForemanRegistrar
+
+	// RunID identifies the invocation in ENC/Foreman registrations (optional)
+	RunID string
+
+	// Repo installs from internal apt/yum mirrors instead of the official
+	// repos (optional, validate with PuppetRepoOptions.Validate)
+	Repo PuppetRepoOptions
+
+	// Shell enables safety options in the install scripts (default: none)
+	Shell ShellOptions
+
+	// CSRAttributes writes csr_attributes.yaml from CSV columns before the
+	// first agent run, requesting trusted facts (optional, see
+	// LoadCSRAttributesFromYAML)
+	CSRAttributes *CSRAttributes
+
+	// HieraNodeData writes a node-local hiera data file from CSV columns
+	// before the first agent run (optional, see LoadHieraNodeDataFromYAML)
+	HieraNodeData *HieraNodeData
+
+	// Proxy is the HTTP proxy the agent uses to reach the Puppet Server and
+	// the Forge (optional, validate with PuppetProxy.Validate)
+	Proxy PuppetProxy
+
+	// PruneFacts removes the fact files listed in the facts.d manifest by an
+	// earlier run and no longer declared (default: files are left in place)
+	PruneFacts bool
+
+	// AgentLock decides how a stuck agent run (agent_catalog_run.lock held
+	// for long) is handled before the initial puppet run (default: wait up
+	// to 10m; validate with AgentLockOptions.Validate)
+	AgentLock AgentLockOptions
+}
+
+func init() {
+	Register(Descriptor{
+		Name:          "puppet",
+		OSFamilies:    []string{OSTypeDebian, OSTypeRHEL},
+		Architectures: []string{"amd64", "arm64"},
+		New:           func() PackageInstaller { return NewPuppetInstaller(PuppetOptions{}) },
+	})
 }
 
 // NewPuppetInstaller creates a new Puppet installer with given options.
@@ -122,6 +237,9 @@ func NewPuppetInstaller(opts PuppetOptions) *PuppetInstaller {
 	if opts.Environment == "" {
 		opts.Environment = "production"
 	}
+	if opts.ServiceState == "" {
+		opts.ServiceState = ServiceStateRunning
+	}
 
 	// Initialize custom facts with default if not provided
 	customFacts := opts.CustomFacts
@@ -130,12 +248,27 @@ func NewPuppetInstaller(opts PuppetOptions) *PuppetInstaller {
 	}
 
 	return &PuppetInstaller{
-		puppetServer:  opts.Server,
-		puppetPort:    opts.Port,
-		puppetVersion: opts.Version,
-		environment:   opts.Environment,
-		lastMetadata:  make(map[string]string),
-		customFacts:   customFacts,
+		puppetServer:    opts.Server,
+		puppetPort:      opts.Port,
+		puppetVersion:   opts.Version,
+		environment:     opts.Environment,
+		lastMetadata:    &InstallMetadata{},
+		customFacts:     customFacts,
+		validationStats: validator.NewStatsCollector(),
+		disableService:  opts.DisableService,
+		serviceState:    opts.ServiceState,
+		remoteWorkdir:   opts.RemoteWorkdir,
+		firstRunSplay:   opts.FirstRunSplay,
+		enc:             opts.ENC,
+		foreman:         opts.Foreman,
+		runID:           opts.RunID,
+		repo:            opts.Repo,
+		shell:           opts.Shell,
+		csrAttributes:   opts.CSRAttributes,
+		hieraNodeData:   opts.HieraNodeData,
+		proxy:           opts.Proxy,
+		pruneFacts:      opts.PruneFacts,
+		agentLock:       opts.AgentLock.withDefaults(),
 	}
 }
 
@@ -182,7 +315,7 @@ func (pi *PuppetInstaller) InstallWithRetry(ctx context.Context, instance *cloud
 
 		// Check if installation was successful
 		if result.ExitCode != 0 {
-			return fmt.Errorf("puppet installation failed with exit code %d: %s", result.ExitCode, result.Stderr)
+			return &ScriptError{ExitCode: result.ExitCode, Stdout: result.Stdout, Stderr: result.Stderr}
 		}
 
 		return nil
@@ -193,9 +326,14 @@ func (pi *PuppetInstaller) InstallWithRetry(ctx context.Context, instance *cloud
 // This is a convenience method that detects the OS and then calls GenerateInstallScript.
 // Use this method when you want automatic OS detection instead of providing it manually.
 // Returns: (commands, metadata, error) where metadata contains os, certname, and certname_preserved.
-func (pi *PuppetInstaller) GenerateInstallScriptWithAutoDetect(ctx context.Context, instance *cloud.Instance, provider cloud.CloudProvider, _ map[string]string) (commands []string, metadata map[string]string, err error) {
-	// Step 1: Detect OS
-	detectedOS, err := pi.detectOS(ctx, instance, provider)
+func (pi *PuppetInstaller) GenerateInstallScriptWithAutoDetect(ctx context.Context, instance *cloud.Instance, provider cloud.CloudProvider, _ map[string]string) (commands []string, metadata *InstallMetadata, err error) {
+	// Fail early on versions without a known repo layout (before any remote call)
+	if err := ValidatePuppetVersion(pi.puppetVersion); err != nil {
+		return nil, nil, err
+	}
+
+	// Step 1: Detect OS and shell
+	detectedOS, shell, err := detectOS(ctx, instance, provider)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to detect OS: %w", err)
 	}
@@ -222,10 +360,19 @@ func (pi *PuppetInstaller) GenerateInstallScriptWithAutoDetect(ctx context.Conte
 	}
 
 	// Step 4: Create metadata for THIS execution (not stored in shared variable to avoid race condition)
-	metadata = map[string]string{
-		"os":                 detectedOS,
-		"certname":           certname,
-		"certname_preserved": fmt.Sprintf("%v", certnamePreserved),
+	splay := pi.chooseSplay()
+	metadata = &InstallMetadata{
+		OS:                detectedOS,
+		Shell:             shell,
+		Certname:          certname,
+		CertnamePreserved: certnamePreserved,
+		FirstRunSplay:     splay,
+	}
+
+	// Record the age of a leftover agent run lock, handled by the script
+	// (non-fatal: the script checks the lock again)
+	if lockAge, err := pi.getAgentLockAge(ctx, instance, provider); err == nil && lockAge > 0 {
+		metadata.Set(MetadataKeyAgentLockAge, lockAge.String())
 	}
 
 	// Step 5: Normalize OS type
@@ -234,13 +381,18 @@ func (pi *PuppetInstaller) GenerateInstallScriptWithAutoDetect(ctx context.Conte
 		return nil, nil, fmt.Errorf("failed to normalize OS type: %w", err)
 	}
 
+	// Reject CSV/instance values that can't be rendered safely in the script
+	if err := pi.checkScriptInputs(certname, instance); err != nil {
+		return nil, nil, err
+	}
+
 	// Step 6: Generate script with certname and custom facts based on normalized OS type
 	var script string
 	switch normalizedOS {
 	case OSTypeDebian:
-		script = pi.generateDebianScript(certname, instance)
+		script = pi.generateDebianScript(certname, instance, splay)
 	case OSTypeRHEL:
-		script = pi.generateRHELScript(certname, instance)
+		script = pi.generateRHELScript(certname, instance, splay)
 	default:
 		// This should never happen if normalizeOS works correctly
 		return nil, nil, fmt.Errorf("internal error: unexpected normalized OS type: %s", normalizedOS)
@@ -249,57 +401,55 @@ func (pi *PuppetInstaller) GenerateInstallScriptWithAutoDetect(ctx context.Conte
 	return []string{script}, metadata, nil
 }
 
-// detectOS detects the operating system of the instance via remote command execution.
-// Uses /etc/os-release which is the standard systemd way to identify Linux distributions.
-//
-// Returns normalized OS type:
-//   - "debian" for Debian/Ubuntu
-//   - "rhel" for RHEL/CentOS/Amazon Linux/Rocky/AlmaLinux
-//
-// This ensures we generate the correct installation script for the target OS.
-func (*PuppetInstaller) detectOS(ctx context.Context, instance *cloud.Instance, provider cloud.CloudProvider) (string, error) {
-	// Script to detect OS from /etc/os-release
-	detectScript := `#!/bin/bash
-if [ -f /etc/os-release ]; then
-    . /etc/os-release
-    # Normalize ID to match our supported types
-    case "$ID" in
-        ubuntu|debian)
-            echo "debian"
-            ;;
-        rhel|centos|fedora|rocky|alma|almalinux)
-            echo "rhel"
-            ;;
-        amzn|amazonlinux|amazon)
-            echo "rhel"
-            ;;
-        *)
-            echo "unknown:$ID"
-            ;;
-    esac
-else
-    echo "unknown:no-os-release"
-fi
-`
-
-	commands := []string{detectScript}
-	result, err := provider.ExecuteCommand(ctx, instance, commands, DefaultSSMTimeout)
-	if err != nil {
-		return "", fmt.Errorf("failed to detect OS: %w", err)
+// checkScriptInputs validates the values interpolated into the install
+// script of an instance: Puppet Server, environment, certname (possibly read
+// from the instance) and the CSV values of custom facts, csr attributes and
+// hiera node data.
+func (pi *PuppetInstaller) checkScriptInputs(certname string, instance *cloud.Instance) error {
+	if server := pi.ServerFor(instance); !hostnamePattern.MatchString(server) {
+		return fmt.Errorf("invalid puppet server %q: expected a hostname", server)
 	}
-
-	if result.ExitCode != 0 {
-		return "", fmt.Errorf("OS detection failed with exit code %d: %s", result.ExitCode, result.Stderr)
+	if err := ValidatePuppetEnvironment(pi.environment); err != nil {
+		return err
 	}
-
-	osType := strings.TrimSpace(result.Stdout)
-
-	// Handle unknown OS
-	if strings.HasPrefix(osType, "unknown:") {
-		return "", fmt.Errorf("unsupported or undetected OS: %s", osType)
+	if !puppetCertnamePattern.MatchString(certname) {
+		return fmt.Errorf("invalid certname %q: use lowercase letters, digits, '.', '_' and '-'", certname)
 	}
 
-	return osType, nil
+	if instance == nil {
+		return nil
+	}
+	for _, factDef := range pi.customFacts {
+		if err := ValidateFactDefinition(factDef); err != nil {
+			return err
+		}
+		for column := range factDef.Fields {
+			if err := checkScriptValue("CSV column "+column, instance.Metadata[column]); err != nil {
+				return err
+			}
+		}
+	}
+	if pi.csrAttributes != nil {
+		if err := ValidateCSRAttributes(pi.csrAttributes); err != nil {
+			return err
+		}
+		for _, column := range pi.csrAttributes.columns() {
+			if err := checkScriptValue("CSV column "+column, csvColumnValue(instance, column)); err != nil {
+				return err
+			}
+		}
+	}
+	if pi.hieraNodeData != nil {
+		if err := ValidateHieraNodeData(pi.hieraNodeData); err != nil {
+			return err
+		}
+		for _, column := range pi.hieraNodeData.Keys {
+			if err := checkScriptValue("CSV column "+column, csvColumnValue(instance, column)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
 }
 
 // getCertnameFromConfig retrieves existing certname from puppet.conf if it exists.
@@ -309,16 +459,16 @@ fi
 //   - Existing certname if found
 //   - Empty string if puppet.conf doesn't exist or certname not found
 //   - Error if command execution fails
-func (*PuppetInstaller) getCertnameFromConfig(ctx context.Context, instance *cloud.Instance, provider cloud.CloudProvider) (string, error) {
-	extractScript := `#!/bin/bash
+func (pi *PuppetInstaller) getCertnameFromConfig(ctx context.Context, instance *cloud.Instance, provider cloud.CloudProvider) (string, error) {
+	extractScript := `#!/bin/sh
 # Check if puppet.conf exists
-if [ ! -f /etc/puppetlabs/puppet/puppet.conf ]; then
+if [ ! -f ` + pi.repo.paths().confDir + `/puppet.conf ]; then
     echo "NOT_FOUND"
     exit 0
 fi
 
 # Extract certname from config
-CERTNAME=$(grep -E '^\s*certname\s*=' /etc/puppetlabs/puppet/puppet.conf | sed 's/.*=\s*//' | tr -d ' ')
+CERTNAME=$(grep -E '^[[:space:]]*certname[[:space:]]*=' ` + pi.repo.paths().confDir + `/puppet.conf | sed 's/.*=[[:space:]]*//' | tr -d ' ')
 
 if [ -z "$CERTNAME" ]; then
     echo "NOT_FOUND"
@@ -383,7 +533,7 @@ func (*PuppetInstaller) generateCustomFact(factDef FactDefinition, instance *clo
 
 		// Only write field if value exists and is not empty
 		if value != "" {
-			content.WriteString(fmt.Sprintf("  %s: %s\n", factField, value))
+			content.WriteString(fmt.Sprintf("  %s: %s\n", factField, yamlScalar(value)))
 			fieldCount++
 		}
 	}
@@ -398,6 +548,7 @@ func (*PuppetInstaller) generateCustomFact(factDef FactDefinition, instance *clo
 
 // generateFactsScript generates bash commands to create all custom fact files.
 // Creates directory structure and writes YAML fact files to /opt/puppetlabs/facter/facts.d/
+// (/etc/facter/facts.d/ with the distro package)
 //
 // Example generated script:
 //
@@ -410,6 +561,9 @@ func (*PuppetInstaller) generateCustomFact(factDef FactDefinition, instance *clo
 //	FACT_EOF_location
 //	chmod 644 /opt/puppetlabs/facter/facts.d/location.yaml
 //
+// The written files are listed in the facts.d manifest (FactsManifestFile),
+// so a later run with PruneFacts removes those no longer declared.
+//
 // Parameters:
 //   - instance: Instance with metadata containing values for fact fields
 //
@@ -421,16 +575,22 @@ func (pi *PuppetInstaller) generateFactsScript(instance *cloud.Instance) string
 	}
 
 	var script strings.Builder
+	factsDir := pi.repo.paths().factsDir
 
 	// Create facts directory and add header comment
 	script.WriteString("\n# ============================================================\n")
 	script.WriteString("# Creating custom Facter facts from CSV data\n")
 	script.WriteString("# ============================================================\n")
 	script.WriteString("echo \"Creating custom Facter facts...\"\n")
-	script.WriteString("mkdir -p /opt/puppetlabs/facter/facts.d\n\n")
+	script.WriteString("mkdir -p " + factsDir + "\n\n")
 
 	// Generate each fact file
+	var written []string
 	for _, factDef := range pi.customFacts {
+		if !factDef.AppliesTo(instance) {
+			continue
+		}
+		written = append(written, factDef.FilePath)
 		factContent := pi.generateCustomFact(factDef, instance)
 
 		// Use HERE document to safely write YAML content
@@ -438,24 +598,74 @@ func (pi *PuppetInstaller) generateFactsScript(instance *cloud.Instance) string
 		eofMarker := fmt.Sprintf("FACT_EOF_%s", factDef.FactName)
 
 		script.WriteString(fmt.Sprintf("# Create %s fact file (fact name: %s)\n", factDef.FilePath, factDef.FactName))
-		script.WriteString(fmt.Sprintf("cat > /opt/puppetlabs/facter/facts.d/%s << '%s'\n", factDef.FilePath, eofMarker))
+		script.WriteString(fmt.Sprintf("cat > %s/%s << '%s'\n", factsDir, factDef.FilePath, eofMarker))
 		script.WriteString(factContent)
 		script.WriteString(eofMarker + "\n")
-		script.WriteString(fmt.Sprintf("chmod 644 /opt/puppetlabs/facter/facts.d/%s\n", factDef.FilePath))
+		script.WriteString(fmt.Sprintf("chmod 644 %s/%s\n", factsDir, factDef.FilePath))
 		script.WriteString(fmt.Sprintf("echo \"  ✓ Created fact: %s\"\n\n", factDef.FilePath))
 	}
 
+	sort.Strings(written)
+	if pi.pruneFacts {
+		script.WriteString(generateFactsPruneScript(factsDir, written))
+	}
+	script.WriteString(generateFactsManifestScript(factsDir, written))
+
 	script.WriteString("echo \"Custom facts created successfully!\"\n")
 	script.WriteString("# ============================================================\n")
 
 	return script.String()
 }
 
+// generateFactsPruneScript generates shell script removing the fact files
+// listed in the facts.d manifest by an earlier run and not in written.
+// Only plain .yaml/.yml names are removed, whatever the manifest contains.
+func generateFactsPruneScript(factsDir string, written []string) string {
+	manifest := factsDir + "/" + FactsManifestFile
+
+	var script strings.Builder
+	script.WriteString("# Remove fact files of earlier runs no longer declared (--prune-facts)\n")
+	script.WriteString("if [ -f " + manifest + " ]; then\n")
+	script.WriteString("  while IFS= read -r fact_file; do\n")
+	script.WriteString("    case \"$fact_file\" in\n")
+	script.WriteString("      ''|*/*|.*) continue ;;\n")
+	if len(written) > 0 {
+		script.WriteString("      " + strings.Join(written, "|") + ") continue ;;\n")
+	}
+	script.WriteString("      *.yaml|*.yml) ;;\n")
+	script.WriteString("      *) continue ;;\n")
+	script.WriteString("    esac\n")
+	script.WriteString("    if [ -f \"" + factsDir + "/$fact_file\" ]; then\n")
+	script.WriteString("      rm -f \"" + factsDir + "/$fact_file\"\n")
+	script.WriteString("      echo \"  ✓ Removed stale fact: $fact_file\"\n")
+	script.WriteString("    fi\n")
+	script.WriteString("  done < " + manifest + "\n")
+	script.WriteString("fi\n\n")
+	return script.String()
+}
+
+// generateFactsManifestScript generates shell script writing the facts.d
+// manifest with the fact files managed by opsmaster (one name per line).
+func generateFactsManifestScript(factsDir string, written []string) string {
+	manifest := factsDir + "/" + FactsManifestFile
+
+	var script strings.Builder
+	script.WriteString("# Record the fact files managed by opsmaster\n")
+	script.WriteString("cat > " + manifest + " << 'FACT_EOF_MANIFEST'\n")
+	for _, name := range written {
+		script.WriteString(name + "\n")
+	}
+	script.WriteString("FACT_EOF_MANIFEST\n")
+	script.WriteString("chmod 644 " + manifest + "\n\n")
+	return script.String()
+}
+
 // generateFacterBlocklistScript generates shell script to configure Facter blocklist.
 // This prevents "exceeds the value length limit: 4096" errors caused by oversized facts
 // like ec2_userdata in cloud environments.
 //
-// Creates /etc/puppetlabs/facter/facter.conf with blocklist configuration.
+// Creates /etc/puppetlabs/facter/facter.conf (/etc/facter/facter.conf with
+// the distro package) with blocklist configuration.
 // The directory is created if it doesn't exist (mkdir -p).
 //
 // Blocklisted facts:
@@ -463,17 +673,17 @@ func (pi *PuppetInstaller) generateFactsScript(instance *cloud.Instance) string
 //   - ec2_metadata: EC2 metadata can be large in some AWS configurations
 //
 // Returns bash script ready to be inserted into installation script.
-func (*PuppetInstaller) generateFacterBlocklistScript() string {
-	return `# Configure Facter blocklist to prevent oversized facts errors
+func (pi *PuppetInstaller) generateFacterBlocklistScript() string {
+	return fmt.Sprintf(`# Configure Facter blocklist to prevent oversized facts errors
 echo "Configuring Facter blocklist..."
-mkdir -p /etc/puppetlabs/facter
-cat > /etc/puppetlabs/facter/facter.conf <<EOF
+mkdir -p %[1]s
+cat > %[1]s/facter.conf <<EOF
 facts : {
   blocklist : [ "ec2_userdata" ]
 }
 EOF
 echo "  ✓ Facter blocklist configured"
-`
+`, pi.repo.paths().facterDir)
 }
 
 // generateElasticPreventionScript generates shell script to prevent Elastic Agent enrollment errors.
@@ -499,7 +709,7 @@ func (*PuppetInstaller) generateElasticPreventionScript() string {
 echo "Checking for Elastic Agent enrollment prevention..."
 
 # Check if elastic-agent service exists (indicating it's already installed)
-if systemctl list-unit-files 2>/dev/null | grep -q "elastic-agent.service"; then
+if systemctl list-unit-files 2>/dev/null | grep "elastic-agent.service" >/dev/null; then
     echo "  ✓ Elastic Agent service detected"
 
     # Check if flag file is missing (would cause enrollment errors)
@@ -532,30 +742,81 @@ echo "============================================================"
 // generatePuppetConfigScript generates shell script to configure puppet.conf.
 // This is common to both Debian and RHEL installation scripts.
 //
-// Creates /etc/puppetlabs/puppet/puppet.conf with agent configuration:
+// Creates puppet.conf (in /etc/puppetlabs/puppet, or /etc/puppet with the
+// distro package) with agent configuration:
 //   - server: Puppet Server hostname
 //   - environment: Puppet environment (production, staging, etc.)
 //   - certname: Unique certname for this agent
 //   - runinterval: How often agent checks for updates (default: 1h)
+//   - http_proxy_host, http_proxy_port, no_proxy: with a proxy (see PuppetProxy)
 //
 // Returns bash script with formatted puppet.conf content.
-func (pi *PuppetInstaller) generatePuppetConfigScript(certname string) string {
-	return fmt.Sprintf(`# Configure Puppet
+func (pi *PuppetInstaller) generatePuppetConfigScript(certname, server string) string {
+	agentSettings := []string{
+		"server = " + server,
+		"environment = " + pi.environment,
+		"certname = " + certname,
+		"runinterval = 1h",
+	}
+	agentSettings = append(agentSettings, pi.proxy.confLines()...)
+
+	script := fmt.Sprintf(`# Configure Puppet
 echo "Configuring Puppet Agent..."
-cat > /etc/puppetlabs/puppet/puppet.conf <<EOF
+mkdir -p %s
+cat > %s/puppet.conf <<'EOF'
 [agent]
-server = %s
-environment = %s
-certname = %s
-runinterval = 1h
+%s
 EOF
 
 echo "Puppet configured with:"
-echo "  Server: %s"
-echo "  Environment: %s"
-echo "  Certname: %s"
-`, pi.puppetServer, pi.environment, certname,
-		pi.puppetServer, pi.environment, certname)
+echo %s
+echo %s
+echo %s
+`, pi.repo.paths().confDir, pi.repo.paths().confDir, strings.Join(agentSettings, "\n"),
+		shellQuote("  Server: "+server), shellQuote("  Environment: "+pi.environment), shellQuote("  Certname: "+certname))
+	if pi.proxy.Enabled() {
+		script += "echo " + shellQuote(fmt.Sprintf("  Proxy: %s:%d", pi.proxy.Host, pi.proxy.port())) + "\n"
+	}
+	return script
+}
+
+// generateServiceScript generates shell script to configure the puppet service.
+// This is common to both Debian and RHEL installation scripts.
+//
+// Drives systemctl according to the configured options:
+//   - enable/disable: whether puppet starts at boot
+//   - start/stop: desired service state after installation
+//
+// A masked unit cannot be enabled or started, so it is unmasked first when
+// the service must be enabled or running.
+//
+// Returns bash script that applies the desired service configuration.
+func (pi *PuppetInstaller) generateServiceScript() string {
+	enableAction := "enable"
+	if pi.disableService {
+		enableAction = "disable"
+	}
+
+	stateAction := "start"
+	if pi.serviceState == ServiceStateStopped {
+		stateAction = "stop"
+	}
+
+	unmask := ""
+	if !pi.disableService || pi.serviceState == ServiceStateRunning {
+		unmask = `if [ "$(systemctl is-enabled puppet 2>/dev/null)" = "masked" ]; then
+    echo "  🔧 puppet service is masked - unmasking"
+    systemctl unmask puppet
+fi
+`
+	}
+
+	return fmt.Sprintf(`# Configure puppet service
+echo "Configuring puppet service (%s, %s)..."
+%ssystemctl %s puppet
+systemctl %s puppet
+echo "  ✓ puppet service configured: boot=%s state=%s"
+`, enableAction, pi.serviceState, unmask, enableAction, stateAction, enableAction, pi.serviceState)
 }
 
 // generatePuppetRunScript generates shell script to run initial Puppet agent.
@@ -565,13 +826,16 @@ echo "  Certname: %s"
 //   - --test: Run in foreground (not as service)
 //   - --waitforcert 60: Wait up to 60 seconds for certificate signing
 //   - Handles Puppet exit codes properly (0, 2, 6 are success; others need investigation)
+//   - Optional splay: random sleep before the run (--first-run-splay)
 //
 // Returns bash script that runs puppet agent and reports version.
-func (*PuppetInstaller) generatePuppetRunScript() string {
-	return `# Run initial puppet agent (will request certificate)
+func (pi *PuppetInstaller) generatePuppetRunScript(splay time.Duration) string {
+	bin := pi.repo.paths().bin
+	return generateSplayScript(splay) + pi.generateAgentLockScript() + `# Run initial puppet agent (will request certificate)
 echo "Running initial Puppet agent..."
-/opt/puppetlabs/bin/puppet agent --test --waitforcert 60
-PUPPET_EXIT_CODE=$?
+# Non-zero exit codes are handled below (tolerated even with set -e)
+PUPPET_EXIT_CODE=0
+` + bin + ` agent --test --waitforcert 60 || PUPPET_EXIT_CODE=$?
 
 echo "Puppet agent completed with exit code: $PUPPET_EXIT_CODE"
 
@@ -597,7 +861,7 @@ case $PUPPET_EXIT_CODE in
 esac
 
 # Check puppet version
-PUPPET_VERSION=$(/opt/puppetlabs/bin/puppet --version)
+PUPPET_VERSION=$(` + bin + ` --version)
 echo "================================================"
 echo "Puppet Agent ${PUPPET_VERSION} installation completed!"
 echo "================================================"
@@ -609,14 +873,25 @@ echo "================================================"
 // 1. Instance is accessible (SSM connectivity)
 // 2. Instance can reach Puppet Server on configured port
 func (pi *PuppetInstaller) ValidatePrerequisites(ctx context.Context, instance *cloud.Instance, provider cloud.CloudProvider) error {
-	// Use validator package for reusable validation logic
-	results, err := validator.ValidatePuppetPrerequisites(
-		ctx,
-		instance,
-		provider,
-		pi.puppetServer,
-		pi.puppetPort,
-	)
+	// Use validator package for reusable validation logic. Behind a proxy
+	// the instance may not reach the server directly: the proxy is checked
+	server := pi.ServerFor(instance)
+	var results []*validator.ValidationResult
+	var err error
+	if pi.proxy.Enabled() && !pi.proxy.Bypasses(server) {
+		results, err = validator.ValidatePuppetPrerequisitesViaProxy(ctx, instance, provider, pi.proxy.Host, pi.proxy.port(), pi.repoValidators()...)
+	} else {
+		results, err = validator.ValidatePuppetPrerequisites(ctx, instance, provider, server, pi.puppetPort, pi.repoValidators()...)
+	}
+
+	// Record timings for the run summary (slowest validators)
+	if pi.validationStats != nil {
+		pi.validationStats.Record(instance.ID, results)
+	}
+	if slowest := validator.SlowestValidation(results); slowest != nil {
+		logger.FromContext(ctx).Debug("Slowest prerequisite validation",
+			"validator", slowest.Name, "duration", slowest.Duration, "timed_out", slowest.TimedOut)
+	}
 
 	if err != nil {
 		// Format validation failures for better error message
@@ -631,6 +906,42 @@ func (pi *PuppetInstaller) ValidatePrerequisites(ctx context.Context, instance *
 	return nil
 }
 
+// repoValidators checks that instances reach the internal mirrors (and the
+// mirror key) before installing from them.
+func (pi *PuppetInstaller) repoValidators() []validator.Validator {
+	var validators []validator.Validator
+	for _, endpoint := range pi.repo.Endpoints() {
+		host, portStr, _ := net.SplitHostPort(endpoint)
+		port, _ := strconv.Atoi(portStr)
+		validators = append(validators, validator.NewConnectivityValidator("puppet_repo_reachable", host, port, repoValidationTimeout))
+	}
+	return validators
+}
+
+// ServerFor returns the Puppet Server for an instance: the puppet_server CSV
+// column when present, otherwise the configured server.
+func (pi *PuppetInstaller) ServerFor(instance *cloud.Instance) string {
+	if instance != nil && instance.Metadata[PuppetServerColumn] != "" {
+		return instance.Metadata[PuppetServerColumn]
+	}
+	return pi.puppetServer
+}
+
+// ConcurrencyGroup implements ConcurrencyGrouper: instances are grouped by
+// Puppet Server so per-server limits protect each CA and compiler.
+func (pi *PuppetInstaller) ConcurrencyGroup(instance *cloud.Instance) string {
+	return pi.ServerFor(instance)
+}
+
+// ValidationStats returns timing statistics for prerequisite validations
+// executed so far, slowest validator first.
+func (pi *PuppetInstaller) ValidationStats() []validator.ValidatorStats {
+	if pi.validationStats == nil {
+		return nil
+	}
+	return pi.validationStats.Snapshot()
+}
+
 // GenerateInstallScript generates installation script based on OS.
 // Supports: debian (for Debian/Ubuntu) and rhel (for RHEL/CentOS/Amazon Linux).
 //
@@ -644,9 +955,16 @@ func (pi *PuppetInstaller) ValidatePrerequisites(ctx context.Context, instance *
 //
 // Note: For automatic OS detection, use GenerateInstallScriptWithAutoDetect instead.
 func (pi *PuppetInstaller) GenerateInstallScript(os string, _ map[string]string) ([]string, error) {
+	if err := ValidatePuppetVersion(pi.puppetVersion); err != nil {
+		return nil, err
+	}
+
 	// Generate new certname for manual script generation
 	// Note: GenerateInstallScriptWithAutoDetect handles certname preservation automatically
 	certname := generatePuppetCertname()
+	if err := pi.checkScriptInputs(certname, nil); err != nil {
+		return nil, err
+	}
 
 	// Normalize OS type using centralized function
 	normalizedOS, err := normalizeOS(os)
@@ -661,10 +979,10 @@ func (pi *PuppetInstaller) GenerateInstallScript(os string, _ map[string]string)
 	switch normalizedOS {
 	case OSTypeDebian:
 		// Note: instance is nil here - custom facts only work with GenerateInstallScriptWithAutoDetect
-		script = pi.generateDebianScript(certname, nil)
+		script = pi.generateDebianScript(certname, nil, pi.chooseSplay())
 	case OSTypeRHEL:
 		// Note: instance is nil here - custom facts only work with GenerateInstallScriptWithAutoDetect
-		script = pi.generateRHELScript(certname, nil)
+		script = pi.generateRHELScript(certname, nil, pi.chooseSplay())
 	default:
 		// This should never happen if normalizeOS works correctly
 		return nil, fmt.Errorf("internal error: unexpected normalized OS type: %s", normalizedOS)
@@ -677,16 +995,22 @@ func (pi *PuppetInstaller) GenerateInstallScript(os string, _ map[string]string)
 // generateDebianScript generates installation script for Debian/Ubuntu.
 // Includes custom Facter facts creation if configured.
 // Also includes automatic Elastic Agent flag fix for enrollment errors.
-func (pi *PuppetInstaller) generateDebianScript(certname string, instance *cloud.Instance) string {
+func (pi *PuppetInstaller) generateDebianScript(certname string, instance *cloud.Instance, splay time.Duration) string {
 	// Generate script components (reusable across Debian/RHEL)
 	factsScript := pi.generateFactsScript(instance)
+	csrAttributes := pi.generateCSRAttributesScript(certname, instance)
+	hieraNodeData := pi.generateHieraNodeDataScript(instance)
 	facterBlocklist := pi.generateFacterBlocklistScript()
 	elasticPrevention := pi.generateElasticPreventionScript()
-	puppetConfig := pi.generatePuppetConfigScript(certname)
-	puppetRun := pi.generatePuppetRunScript()
-
-	return fmt.Sprintf(`#!/bin/bash
-# Note: Removed 'set -e' to allow Puppet exit codes to be handled gracefully
+	puppetConfig := pi.generatePuppetConfigScript(certname, pi.ServerFor(instance))
+	puppetRun := pi.generatePuppetRunScript(splay)
+	serviceConfig := pi.generateServiceScript()
+	repoCheck := pi.generateDebianRepoCheckScript()
+	workdir := pi.generateWorkdirScript()
+	repoInstall := pi.generateDebianRepoScript()
+
+	return fmt.Sprintf(`#!/bin/sh
+%s
 
 echo "================================================"
 echo "Installing Puppet Agent on Debian/Ubuntu"
@@ -695,58 +1019,61 @@ echo "================================================"
 # Detect OS version
 if [ -f /etc/os-release ]; then
     . /etc/os-release
-    VERSION_CODENAME=${VERSION_CODENAME}
+    NAME=${NAME:-Linux}
+    VERSION=${VERSION:-}
+    VERSION_CODENAME=${VERSION_CODENAME:-}
     echo "Detected OS: ${NAME} ${VERSION}"
 else
     echo "ERROR: Cannot detect OS version"
-    exit 1
-fi
-
-# Download and install Puppet repository
-echo "Installing Puppet %s repository..."
-REPO_DEB="puppet%s-release-${VERSION_CODENAME}.deb"
-wget -q "https://apt.puppet.com/${REPO_DEB}" -O /tmp/${REPO_DEB}
-if ! dpkg -i /tmp/${REPO_DEB}; then
-    echo "Error installing Puppet repository"
-    exit 1
+    exit 10
 fi
-rm /tmp/${REPO_DEB}
 
+%s
+%s
+%s
 # Update apt cache
 echo "Updating package cache..."
 if ! apt-get update -qq; then
     echo "Error updating package cache"
-    exit 1
+    exit 20
 fi
 
-# Install puppet-agent
-echo "Installing puppet-agent package..."
-if ! DEBIAN_FRONTEND=noninteractive apt-get install -y puppet-agent; then
-    echo "Error installing puppet-agent package"
-    exit 1
+# Install %[5]s
+echo "Installing %[5]s package..."
+if ! DEBIAN_FRONTEND=noninteractive apt-get install -y %[5]s; then
+    echo "Error installing %[5]s package"
+    exit 30
 fi
-
-%s
-%s
-%s
-%s
-%s
-`, pi.puppetVersion, pi.puppetVersion, facterBlocklist, elasticPrevention, factsScript, puppetConfig, puppetRun)
+%[6]s
+%[7]s
+%[8]s
+%[9]s
+%[10]s
+%[11]s
+%[12]s
+%[13]s
+%[14]s
+`, pi.shell.preamble(), repoCheck, workdir, repoInstall, pi.repo.packageName(), pi.generateBinaryCheckScript(), facterBlocklist, elasticPrevention, factsScript, csrAttributes, hieraNodeData, puppetConfig, puppetRun, serviceConfig)
 }
 
 // generateRHELScript generates installation script for RHEL/CentOS/Amazon Linux.
 // Includes custom Facter facts creation if configured.
 // Also includes automatic Elastic Agent flag fix for enrollment errors.
-func (pi *PuppetInstaller) generateRHELScript(certname string, instance *cloud.Instance) string {
+func (pi *PuppetInstaller) generateRHELScript(certname string, instance *cloud.Instance, splay time.Duration) string {
 	// Generate script components (reusable across Debian/RHEL)
 	factsScript := pi.generateFactsScript(instance)
+	csrAttributes := pi.generateCSRAttributesScript(certname, instance)
+	hieraNodeData := pi.generateHieraNodeDataScript(instance)
 	facterBlocklist := pi.generateFacterBlocklistScript()
 	elasticPrevention := pi.generateElasticPreventionScript()
-	puppetConfig := pi.generatePuppetConfigScript(certname)
-	puppetRun := pi.generatePuppetRunScript()
+	puppetConfig := pi.generatePuppetConfigScript(certname, pi.ServerFor(instance))
+	puppetRun := pi.generatePuppetRunScript(splay)
+	serviceConfig := pi.generateServiceScript()
+	repoResolve := pi.generateRHELRepoResolveScript()
+	repoInstall := pi.generateRHELRepoScript()
 
-	return fmt.Sprintf(`#!/bin/bash
-# Note: Removed 'set -e' to allow Puppet exit codes to be handled gracefully
+	return fmt.Sprintf(`#!/bin/sh
+%s
 
 echo "================================================"
 echo "Installing Puppet Agent on RHEL/Amazon Linux"
@@ -755,71 +1082,63 @@ echo "================================================"
 # Detect OS version
 if [ -f /etc/os-release ]; then
     . /etc/os-release
-
-    # Amazon Linux version-specific repo selection
-    if [[ "$ID" == "amzn" ]]; then
-        if [[ "$VERSION_ID" == "2023" ]]; then
-            # Amazon Linux 2023 uses official Amazon repositories
-            REPO_TYPE="amazon"
-            REPO_VERSION="2023"
-            echo "Detected OS: Amazon Linux 2023 (${VERSION}) - using official Amazon repositories"
-        else
-            # Amazon Linux 2 uses EL7 repos (validated and working)
-            REPO_TYPE="el"
-            REPO_VERSION="7"
-            echo "Detected OS: Amazon Linux ${VERSION} - using EL7 repositories"
-        fi
-    else
-        # Other RHEL family uses EL repos
-        REPO_TYPE="el"
-        REPO_VERSION=$(echo $VERSION_ID | cut -d. -f1)
-        echo "Detected OS: ${NAME} ${VERSION_ID}"
-    fi
+    NAME=${NAME:-Linux}
+    ID=${ID:-linux}
+    VERSION_ID=${VERSION_ID:-}
+    echo "Detected OS: ${NAME} ${VERSION_ID}"
 else
     echo "ERROR: Cannot detect OS version"
-    exit 1
+    exit 10
 fi
 
-# Install Puppet repository
-echo "Installing Puppet %s repository..."
-REPO_RPM="puppet%s-release-${REPO_TYPE}-${REPO_VERSION}.noarch.rpm"
-if ! yum install -y "https://yum.puppet.com/${REPO_RPM}"; then
-    echo "Error installing Puppet repository: ${REPO_RPM}"
-    echo "Please check if the repository URL is correct and accessible"
-    exit 1
+%s
+%s
+# Install %[4]s
+echo "Installing %[4]s package..."
+if ! yum install -y %[4]s; then
+    echo "Error installing %[4]s package"
+    exit 30
 fi
-echo "✓ Puppet repository installed successfully"
+%[5]s
+%[6]s
+%[7]s
+%[8]s
+%[9]s
+%[10]s
+%[11]s
+%[12]s
+%[13]s
+`, pi.shell.preamble(), repoResolve, repoInstall, pi.repo.packageName(), pi.generateBinaryCheckScript(), facterBlocklist, elasticPrevention, factsScript, csrAttributes, hieraNodeData, puppetConfig, puppetRun, serviceConfig)
+}
 
-# Install puppet-agent
-echo "Installing puppet-agent package..."
-if ! yum install -y puppet-agent; then
-    echo "Error installing puppet-agent package"
-    exit 1
+// generateBinaryCheckScript generates shell script that fails the install
+// when the package did not provide the puppet binary at the path expected
+// for the package source (e.g., a vendor package without the agent).
+func (pi *PuppetInstaller) generateBinaryCheckScript() string {
+	return fmt.Sprintf(`if [ ! -x %[1]s ]; then
+    echo "ERROR: puppet binary not found at %[1]s after installing %[2]s"
+    exit 30
 fi
-
-%s
-%s
-%s
-%s
-%s
-`, pi.puppetVersion, pi.puppetVersion, facterBlocklist, elasticPrevention, factsScript, puppetConfig, puppetRun)
+echo "✓ Puppet binary: %[1]s"
+`, pi.repo.paths().bin, pi.repo.packageName())
 }
 
 // VerifyInstallation verifies that Puppet was installed successfully.
 // Checks:
 // 1. Puppet binary exists and is executable
-// 2. Puppet service is active
-// 3. Can execute 'puppet --version' successfully
-func (*PuppetInstaller) VerifyInstallation(ctx context.Context, instance *cloud.Instance, provider cloud.CloudProvider) error {
+// 2. Can execute 'puppet --version' successfully
+// 3. Puppet service matches the desired state (running/stopped)
+// 4. Puppet service matches the desired boot setting (enabled/disabled)
+func (pi *PuppetInstaller) VerifyInstallation(ctx context.Context, instance *cloud.Instance, provider cloud.CloudProvider) error {
 	// Commands to verify installation
+	bin := pi.repo.paths().bin
 	verifyCommands := []string{
-		// Check if puppet binary exists
-		"test -x /opt/puppetlabs/bin/puppet || exit 1",
+		// Check if puppet binary exists (path depends on the package source)
+		"test -x " + bin + " || exit 1",
 		// Check puppet version
-		"/opt/puppetlabs/bin/puppet --version || exit 2",
-		// Check if service is active
-		"systemctl is-active puppet || exit 3",
+		bin + " --version || exit 2",
 	}
+	verifyCommands = append(verifyCommands, pi.serviceVerifyCommands()...)
 
 	result, err := provider.ExecuteCommand(ctx, instance, verifyCommands, DefaultSSMTimeout)
 	if err != nil {
@@ -840,6 +1159,28 @@ func (*PuppetInstaller) VerifyInstallation(ctx context.Context, instance *cloud.
 	return nil
 }
 
+// serviceVerifyCommands returns commands checking the puppet service against
+// the configured desired state and boot setting.
+func (pi *PuppetInstaller) serviceVerifyCommands() []string {
+	commands := make([]string, 0, 2)
+
+	// Check service state
+	if pi.serviceState == ServiceStateStopped {
+		commands = append(commands, "! systemctl is-active --quiet puppet || exit 3")
+	} else {
+		commands = append(commands, "systemctl is-active --quiet puppet || exit 3")
+	}
+
+	// Check boot setting (is-enabled fails for disabled and masked units)
+	if pi.disableService {
+		commands = append(commands, "! systemctl is-enabled --quiet puppet || exit 4")
+	} else {
+		commands = append(commands, "systemctl is-enabled --quiet puppet || exit 4")
+	}
+
+	return commands
+}
+
 // GetSuccessTags returns tags to apply after successful installation.
 // Tags include:
 //   - puppet: "true"
@@ -863,14 +1204,14 @@ func (*PuppetInstaller) GetFailureTags(_ error) map[string]string {
 
 // GetInstallMetadata returns metadata from the last installation attempt.
 // Metadata includes:
-//   - os: detected operating system (debian, rhel)
-//   - certname: Puppet certname used for installation
-//   - certname_preserved: "true" if certname was preserved from existing installation, "false" if newly generated
+//   - OS: detected operating system (debian, rhel)
+//   - Certname: Puppet certname used for installation
+//   - CertnamePreserved: true if certname was preserved from existing installation, false if newly generated
 //
-// Returns empty map if no installation attempt was made yet.
-func (pi *PuppetInstaller) GetInstallMetadata() map[string]string {
+// Returns empty metadata if no installation attempt was made yet.
+func (pi *PuppetInstaller) GetInstallMetadata() *InstallMetadata {
 	if pi.lastMetadata == nil {
-		return map[string]string{}
+		return &InstallMetadata{}
 	}
 	return pi.lastMetadata
 }
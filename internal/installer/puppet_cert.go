@@ -0,0 +1,337 @@
+package installer
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/estudosdevops/opsmaster/internal/cloud"
+)
+
+// Puppet certificate regeneration settings.
+const (
+	puppetBin              = "/opt/puppetlabs/bin/puppet"
+	puppetSSLDir           = "/etc/puppetlabs/puppet/ssl"
+	puppetSSLBackupDir     = "/var/lib/opsmaster/puppet-ssl-backups"
+	puppetCertRunTimeout   = 15 * time.Minute
+	defaultPuppetWaitCert  = 60 * time.Second
+	puppetAgentLockTimeout = 120
+)
+
+// Certname strategies for certificate regeneration.
+const (
+	CertnamePreserve = "preserve" // Keep the current certname
+	CertnameGenerate = "generate" // New <uuid>.puppet certname, like fresh installs
+)
+
+// Metadata keys recorded by certificate regeneration.
+const (
+	MetadataKeyPreviousCertname = "previous_certname"
+	MetadataKeySSLBackup        = "ssl_backup"
+)
+
+// puppetCertnamePattern matches certnames accepted by the Puppet CA
+// (lowercase, no shell metacharacters).
+var puppetCertnamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]*$`)
+
+// CertificateAuthority cleans and signs agent certificates on the Puppet CA
+// (see puppetca.Client).
+type CertificateAuthority interface {
+	// Clean revokes and deletes the certificate of a certname; unknown
+	// certnames are not an error.
+	Clean(ctx context.Context, certname string) error
+
+	// Sign signs the pending certificate request of a certname.
+	Sign(ctx context.Context, certname string) error
+}
+
+// PuppetCertOptions contains options for regenerating agent certificates.
+type PuppetCertOptions struct {
+	// Certname is the certname strategy: CertnamePreserve (default) or
+	// CertnameGenerate. Ignored for instances with a CertnameColumn value.
+	Certname string
+
+	// CertnameColumn is a CSV column with the new certname (optional)
+	CertnameColumn string
+
+	// CA cleans the old certificate before the agent requests a new one
+	// (optional; without it the CA must be cleaned out of band)
+	CA CertificateAuthority
+
+	// Sign signs the new request through CA (for CAs without autosigning)
+	Sign bool
+
+	// WaitForCert is how long the agent waits for the signed certificate
+	// (default: 60s)
+	WaitForCert time.Duration
+}
+
+// Validate checks the options and applies defaults.
+func (o *PuppetCertOptions) Validate() error {
+	if o.Certname == "" {
+		o.Certname = CertnamePreserve
+	}
+	if o.Certname != CertnamePreserve && o.Certname != CertnameGenerate {
+		return fmt.Errorf("invalid certname strategy %q: must be %s or %s", o.Certname, CertnamePreserve, CertnameGenerate)
+	}
+	if o.Sign && o.CA == nil {
+		return fmt.Errorf("signing requires a Puppet CA")
+	}
+	if o.WaitForCert <= 0 {
+		o.WaitForCert = defaultPuppetWaitCert
+	}
+	return nil
+}
+
+// PuppetCertRegenerator implements PackageInstaller to replace the agent
+// certificate of existing Puppet nodes (recovery from CA rotation).
+// Implements LocalInstaller: the CA cleanup runs between remote steps.
+//
+// Workflow per instance:
+//  1. inspect: read current certname and service state
+//  2. backup-ssl: stop the agent, archive and remove the ssl directory,
+//     set the new certname
+//  3. CA cleanup of the old (and new) certname
+//  4. submit-request + CA signing (optional)
+//  5. run-agent: request and download the new certificate
+//  6. start-agent: restart the service if it was running
+type PuppetCertRegenerator struct {
+	opts PuppetCertOptions
+}
+
+// NewPuppetCertRegenerator creates a certificate regenerator.
+// Call opts.Validate first.
+func NewPuppetCertRegenerator(opts PuppetCertOptions) *PuppetCertRegenerator {
+	return &PuppetCertRegenerator{opts: opts}
+}
+
+// Name returns the package name
+func (*PuppetCertRegenerator) Name() string {
+	return "puppet-cert"
+}
+
+// InstallLocal regenerates the certificate of one instance.
+func (pr *PuppetCertRegenerator) InstallLocal(ctx context.Context, instance *cloud.Instance, provider cloud.CloudProvider) (*InstallMetadata, error) {
+	output, err := pr.runStep(ctx, instance, provider, InstallStep{Name: "inspect", Commands: []string{pr.inspectScript()}, Timeout: DefaultSSMTimeout})
+	if err != nil {
+		return nil, err
+	}
+	values := parseKeyValues(output)
+	oldCertname := values["certname"]
+	if !puppetCertnamePattern.MatchString(oldCertname) {
+		return nil, fmt.Errorf("unexpected current certname %q", oldCertname)
+	}
+
+	newCertname, err := pr.certnameFor(instance, oldCertname)
+	if err != nil {
+		return nil, err
+	}
+	metadata := &InstallMetadata{Certname: newCertname, CertnamePreserved: newCertname == oldCertname}
+	metadata.Set(MetadataKeyPreviousCertname, oldCertname)
+
+	output, err = pr.runStep(ctx, instance, provider, InstallStep{Name: "backup-ssl", Commands: []string{pr.backupScript(oldCertname, newCertname)}, Timeout: DefaultSSMTimeout})
+	if err != nil {
+		return metadata, err
+	}
+	backup := parseKeyValues(output)["backup"]
+	metadata.Set(MetadataKeySSLBackup, backup)
+
+	// From here on the node has no certificate: point at the backup on errors
+	if err := pr.requestCertificate(ctx, instance, provider, oldCertname, newCertname); err != nil {
+		return metadata, fmt.Errorf("%w (previous ssl directory saved in %s)", err, backup)
+	}
+
+	if values["service"] == "active" {
+		if _, err := pr.runStep(ctx, instance, provider, InstallStep{Name: "start-agent", Commands: []string{"systemctl start puppet"}, Timeout: DefaultSSMTimeout}); err != nil {
+			return metadata, err
+		}
+	}
+	return metadata, nil
+}
+
+// requestCertificate cleans the CA and runs the agent to obtain the new
+// certificate.
+func (pr *PuppetCertRegenerator) requestCertificate(ctx context.Context, instance *cloud.Instance, provider cloud.CloudProvider, oldCertname, newCertname string) error {
+	if pr.opts.CA != nil {
+		for _, certname := range uniqueCertnames(oldCertname, newCertname) {
+			if err := pr.opts.CA.Clean(ctx, certname); err != nil {
+				return fmt.Errorf("failed to clean %s on the Puppet CA: %w", certname, err)
+			}
+		}
+	}
+
+	if pr.opts.Sign {
+		if _, err := pr.runStep(ctx, instance, provider, InstallStep{Name: "submit-request", Commands: []string{puppetBin + " ssl submit_request"}, Timeout: DefaultSSMTimeout}); err != nil {
+			return err
+		}
+		if err := pr.opts.CA.Sign(ctx, newCertname); err != nil {
+			return fmt.Errorf("failed to sign %s on the Puppet CA: %w", newCertname, err)
+		}
+	}
+
+	_, err := pr.runStep(ctx, instance, provider, InstallStep{Name: "run-agent", Commands: []string{pr.agentRunScript()}, Timeout: puppetCertRunTimeout})
+	return err
+}
+
+// runStep executes one step and returns its stdout.
+func (*PuppetCertRegenerator) runStep(ctx context.Context, instance *cloud.Instance, provider cloud.CloudProvider, step InstallStep) (string, error) {
+	result, err := provider.ExecuteCommandWithOptions(ctx, instance, step.Commands, step.Timeout,
+		cloud.ExecOptions{Comment: "puppet regen-cert step " + step.Name})
+	if err != nil {
+		return "", fmt.Errorf("failed to execute step %q: %w", step.Name, err)
+	}
+	if result.ExitCode != 0 {
+		return result.Stdout, &ScriptError{Step: step.Name, ExitCode: result.ExitCode, Stdout: result.Stdout, Stderr: result.Stderr}
+	}
+	return result.Stdout, nil
+}
+
+// certnameFor returns the certname requested for an instance: the CSV
+// column value, a generated certname or the current one.
+func (pr *PuppetCertRegenerator) certnameFor(instance *cloud.Instance, current string) (string, error) {
+	if pr.opts.CertnameColumn != "" {
+		if certname := strings.TrimSpace(instance.Metadata[pr.opts.CertnameColumn]); certname != "" {
+			if !puppetCertnamePattern.MatchString(certname) {
+				return "", fmt.Errorf("invalid certname %q in column %s: use lowercase letters, digits, '.', '_' and '-'", certname, pr.opts.CertnameColumn)
+			}
+			return certname, nil
+		}
+	}
+	if pr.opts.Certname == CertnameGenerate {
+		return generatePuppetCertname(), nil
+	}
+	return current, nil
+}
+
+// inspectScript prints the current certname and puppet service state.
+func (*PuppetCertRegenerator) inspectScript() string {
+	return `#!/bin/sh
+test -x ` + puppetBin + ` || { echo "Error: puppet agent not installed"; exit 10; }
+echo "certname=$(` + puppetBin + ` config print certname --section agent)"
+echo "service=$(systemctl is-active puppet 2>/dev/null)"
+`
+}
+
+// backupScript stops the agent, waits for a running catalog to finish,
+// archives and removes the ssl directory and sets the new certname.
+func (*PuppetCertRegenerator) backupScript(oldCertname, newCertname string) string {
+	script := `#!/bin/sh
+systemctl stop puppet 2>/dev/null
+LOCK="$(` + puppetBin + ` config print agent_catalog_run_lockfile)"
+WAITED=0
+while [ -e "${LOCK}" ]; do
+    if [ "${WAITED}" -ge ` + strconv.Itoa(puppetAgentLockTimeout) + ` ]; then
+        echo "Error: puppet agent run still in progress (${LOCK})"
+        exit 10
+    fi
+    sleep 5
+    WAITED=$((WAITED + 5))
+done
+
+mkdir -p ` + puppetSSLBackupDir + `
+chmod 0700 ` + puppetSSLBackupDir + `
+BACKUP="` + puppetSSLBackupDir + `/ssl-` + oldCertname + `-$(date -u +%Y%m%dT%H%M%SZ).tar.gz"
+if [ -d ` + puppetSSLDir + ` ]; then
+    if ! tar -czf "${BACKUP}" -C "$(dirname ` + puppetSSLDir + `)" ssl; then
+        echo "Error: failed to back up ` + puppetSSLDir + `"
+        rm -f "${BACKUP}"
+        exit 40
+    fi
+    chmod 0600 "${BACKUP}"
+    rm -rf ` + puppetSSLDir + `
+else
+    BACKUP="none"
+fi
+echo "backup=${BACKUP}"
+`
+	if newCertname != oldCertname {
+		script += puppetBin + ` config set certname ` + newCertname + ` --section agent || exit 40
+echo "certname=` + newCertname + `"
+`
+	}
+	return script
+}
+
+// agentRunScript runs the agent to request the new certificate. Catalog
+// failures (exit 4/6) are tolerated: only the certificate matters here.
+func (pr *PuppetCertRegenerator) agentRunScript() string {
+	return `#!/bin/sh
+` + puppetBin + ` agent --test --waitforcert ` + strconv.Itoa(int(pr.opts.WaitForCert.Seconds())) + `
+echo "Puppet agent completed with exit code: $?"
+if [ ! -s "$(` + puppetBin + ` config print hostcert)" ]; then
+    echo "Error: no certificate issued (request not signed; is autosign enabled?)"
+    exit 50
+fi
+echo "✓ New certificate installed"
+`
+}
+
+// GenerateInstallScript returns the remote scripts with the certname
+// preserved and without CA calls (used in dry-run mode).
+func (pr *PuppetCertRegenerator) GenerateInstallScript(_ string, _ map[string]string) ([]string, error) {
+	return []string{pr.inspectScript(), pr.backupScript("<certname>", "<certname>"), pr.agentRunScript()}, nil
+}
+
+// ValidatePrerequisites checks that the puppet agent is installed.
+func (*PuppetCertRegenerator) ValidatePrerequisites(ctx context.Context, instance *cloud.Instance, provider cloud.CloudProvider) error {
+	result, err := provider.ExecuteCommand(ctx, instance, []string{"test -x " + puppetBin + " || exit 1"}, DefaultSSMTimeout)
+	if err != nil {
+		return fmt.Errorf("puppet-cert prerequisites validation failed: %w", err)
+	}
+	if result.ExitCode != 0 {
+		return fmt.Errorf("puppet-cert prerequisites validation failed: puppet agent not installed")
+	}
+	return nil
+}
+
+// VerifyInstallation checks that the agent has a certificate.
+func (*PuppetCertRegenerator) VerifyInstallation(ctx context.Context, instance *cloud.Instance, provider cloud.CloudProvider) error {
+	result, err := provider.ExecuteCommand(ctx, instance, []string{`test -s "$(` + puppetBin + ` config print hostcert)" || exit 1`}, DefaultSSMTimeout)
+	if err != nil {
+		return fmt.Errorf("failed to verify puppet certificate: %w", err)
+	}
+	if result.ExitCode != 0 {
+		return fmt.Errorf("puppet certificate verification failed (exit code %d): %s", result.ExitCode, result.Stderr)
+	}
+	return nil
+}
+
+// GetSuccessTags returns tags to apply after a successful regeneration.
+func (*PuppetCertRegenerator) GetSuccessTags() map[string]string {
+	return map[string]string{
+		"puppet_cert_regenerated_at": time.Now().UTC().Format("2006-01-02"),
+	}
+}
+
+// GetFailureTags returns tags to apply when regeneration fails.
+func (*PuppetCertRegenerator) GetFailureTags(_ error) map[string]string {
+	return map[string]string{}
+}
+
+// GetInstallMetadata returns empty metadata: per-instance metadata is
+// returned by InstallLocal.
+func (*PuppetCertRegenerator) GetInstallMetadata() *InstallMetadata {
+	return &InstallMetadata{}
+}
+
+// parseKeyValues parses "key=value" lines from script output.
+func parseKeyValues(output string) map[string]string {
+	values := make(map[string]string)
+	for line := range strings.Lines(output) {
+		if key, value, ok := strings.Cut(strings.TrimSpace(line), "="); ok {
+			values[key] = value
+		}
+	}
+	return values
+}
+
+// uniqueCertnames returns the certnames to clean on the CA, without duplicates.
+func uniqueCertnames(oldCertname, newCertname string) []string {
+	if oldCertname == newCertname {
+		return []string{oldCertname}
+	}
+	return []string{oldCertname, newCertname}
+}
@@ -0,0 +1,191 @@
+package installer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/estudosdevops/opsmaster/internal/cloud"
+	"github.com/estudosdevops/opsmaster/internal/cloud/cloudtest"
+)
+
+// recordingCA records CA calls in the shared call log.
+type recordingCA struct {
+	calls   *[]string
+	signErr error
+}
+
+func (ca *recordingCA) Clean(_ context.Context, certname string) error {
+	*ca.calls = append(*ca.calls, "ca clean "+certname)
+	return nil
+}
+
+func (ca *recordingCA) Sign(_ context.Context, certname string) error {
+	*ca.calls = append(*ca.calls, "ca sign "+certname)
+	return ca.signErr
+}
+
+// certStepName identifies a regen-cert script by its content.
+func certStepName(script string) string {
+	switch {
+	case strings.Contains(script, "config print certname"):
+		return "inspect"
+	case strings.Contains(script, "tar -czf"):
+		return "backup-ssl"
+	case strings.Contains(script, "ssl submit_request"):
+		return "submit-request"
+	case strings.Contains(script, "agent --test"):
+		return "run-agent"
+	default:
+		return script
+	}
+}
+
+// TestPuppetCertRegenerator_InstallLocal tests step ordering around the CA
+//
+// 🎓 CONCEPT: Shared call log
+// Remote steps and CA calls append to the same slice, so the test asserts
+// that the CA is cleaned after the backup and before the agent run.
+func TestPuppetCertRegenerator_InstallLocal(t *testing.T) {
+	tests := []struct {
+		name         string
+		opts         PuppetCertOptions
+		instance     *cloud.Instance
+		service      string
+		wantCalls    []string
+		wantCertname string
+	}{
+		{
+			name:         "preserve certname with CA cleanup",
+			opts:         PuppetCertOptions{},
+			instance:     &cloud.Instance{ID: "i-1"},
+			service:      "active",
+			wantCalls:    []string{"inspect", "backup-ssl", "ca clean old.puppet", "run-agent", "systemctl start puppet"},
+			wantCertname: "old.puppet",
+		},
+		{
+			name:         "certname column and signing",
+			opts:         PuppetCertOptions{CertnameColumn: "certname", Sign: true},
+			instance:     &cloud.Instance{ID: "i-2", Metadata: map[string]string{"certname": "web-01.example.com"}},
+			service:      "inactive",
+			wantCalls:    []string{"inspect", "backup-ssl", "ca clean old.puppet", "ca clean web-01.example.com", "submit-request", "ca sign web-01.example.com", "run-agent"},
+			wantCertname: "web-01.example.com",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// ARRANGE
+			var calls []string
+			provider := &cloudtest.Provider{
+				ExecuteCommandFunc: func(_ context.Context, _ *cloud.Instance, commands []string, _ time.Duration) (*cloud.CommandResult, error) {
+					name := certStepName(commands[0])
+					calls = append(calls, name)
+					switch name {
+					case "inspect":
+						return &cloud.CommandResult{Stdout: "certname=old.puppet\nservice=" + tt.service + "\n"}, nil
+					case "backup-ssl":
+						return &cloud.CommandResult{Stdout: "backup=/var/lib/opsmaster/puppet-ssl-backups/ssl.tar.gz\n"}, nil
+					}
+					return &cloud.CommandResult{}, nil
+				},
+			}
+			tt.opts.CA = &recordingCA{calls: &calls}
+			if err := tt.opts.Validate(); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			// ACT
+			metadata, err := NewPuppetCertRegenerator(tt.opts).InstallLocal(context.Background(), tt.instance, provider)
+
+			// ASSERT
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if strings.Join(calls, ",") != strings.Join(tt.wantCalls, ",") {
+				t.Errorf("calls = %v, want %v", calls, tt.wantCalls)
+			}
+			if metadata.Certname != tt.wantCertname || metadata.Get(MetadataKeyPreviousCertname) != "old.puppet" {
+				t.Errorf("unexpected metadata %+v", metadata)
+			}
+			if metadata.Get(MetadataKeySSLBackup) == "" {
+				t.Error("expected ssl backup path in metadata")
+			}
+		})
+	}
+}
+
+// TestPuppetCertRegenerator_Errors tests failures reported after the ssl removal
+func TestPuppetCertRegenerator_Errors(t *testing.T) {
+	provider := &cloudtest.Provider{
+		ExecuteCommandFunc: func(_ context.Context, _ *cloud.Instance, commands []string, _ time.Duration) (*cloud.CommandResult, error) {
+			switch certStepName(commands[0]) {
+			case "inspect":
+				return &cloud.CommandResult{Stdout: "certname=old.puppet\nservice=active\n"}, nil
+			case "backup-ssl":
+				return &cloud.CommandResult{Stdout: "backup=/backup/ssl.tar.gz\n"}, nil
+			}
+			return &cloud.CommandResult{}, nil
+		},
+	}
+	var calls []string
+	opts := PuppetCertOptions{Sign: true, CA: &recordingCA{calls: &calls, signErr: fmt.Errorf("403 forbidden")}}
+	if err := opts.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	_, err := NewPuppetCertRegenerator(opts).InstallLocal(context.Background(), &cloud.Instance{ID: "i-1"}, provider)
+
+	if err == nil || !strings.Contains(err.Error(), "failed to sign old.puppet") || !strings.Contains(err.Error(), "/backup/ssl.tar.gz") {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+// TestPuppetCertOptions_Validate tests strategy and signing validation
+func TestPuppetCertOptions_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    PuppetCertOptions
+		wantErr string
+	}{
+		{name: "defaults", opts: PuppetCertOptions{}},
+		{name: "generate", opts: PuppetCertOptions{Certname: CertnameGenerate}},
+		{name: "unknown strategy", opts: PuppetCertOptions{Certname: "random"}, wantErr: "invalid certname strategy"},
+		{name: "sign without CA", opts: PuppetCertOptions{Sign: true}, wantErr: "signing requires a Puppet CA"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.opts.Validate()
+
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.opts.WaitForCert != defaultPuppetWaitCert {
+				t.Errorf("WaitForCert = %v, want default", tt.opts.WaitForCert)
+			}
+		})
+	}
+}
+
+// TestPuppetCertRegenerator_CertnameFor tests certname selection
+func TestPuppetCertRegenerator_CertnameFor(t *testing.T) {
+	pr := NewPuppetCertRegenerator(PuppetCertOptions{Certname: CertnameGenerate, CertnameColumn: "certname"})
+
+	generated, err := pr.certnameFor(&cloud.Instance{ID: "i-1"}, "old.puppet")
+	if err != nil || generated == "old.puppet" || !strings.HasSuffix(generated, ".puppet") {
+		t.Errorf("expected generated certname, got %q (%v)", generated, err)
+	}
+
+	if _, err := pr.certnameFor(&cloud.Instance{Metadata: map[string]string{"certname": "Web;reboot"}}, "old.puppet"); err == nil {
+		t.Error("expected error for invalid certname in column")
+	}
+}
@@ -0,0 +1,165 @@
+package installer
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/estudosdevops/opsmaster/internal/cloud"
+)
+
+// Behaviors for a stale agent run lock (--agent-lock).
+const (
+	AgentLockWait  = "wait"  // Wait for the lock to be released, up to the timeout
+	AgentLockClear = "clear" // Stop the stuck agent and remove the lock
+	AgentLockFail  = "fail"  // Fail the instance without running the agent
+)
+
+// Defaults of the agent run lock handling.
+const (
+	DefaultAgentLockStaleAfter = time.Hour
+	DefaultAgentLockTimeout    = 10 * time.Minute
+
+	// MaxAgentLockTimeout bounds the wait so it fits in the executor's
+	// install timeout (30 minutes), like MaxFirstRunSplay.
+	MaxAgentLockTimeout = 20 * time.Minute
+)
+
+// agentLockFile is the lock the agent holds while applying a catalog; a
+// stuck run keeps it and every later "puppet agent --test" gives up.
+const agentLockFile = "agent_catalog_run.lock"
+
+// AgentLockOptions configures how the install script handles an agent run
+// lock found on the instance before the initial puppet run.
+//
+// A lock younger than StaleAfter belongs to a run in progress and is always
+// waited for (up to Timeout). An older one held by a live puppet process is
+// stale: Action decides whether to wait, clear it or fail. Locks whose
+// process is gone are left to the agent, which removes them itself.
+type AgentLockOptions struct {
+	Action     string        // wait (default), clear or fail
+	StaleAfter time.Duration // Age from which a lock is stale (default: 1h)
+	Timeout    time.Duration // Max wait for the lock to be released (default: 10m)
+}
+
+// withDefaults returns the options with zero values replaced by defaults.
+func (o AgentLockOptions) withDefaults() AgentLockOptions {
+	if o.Action == "" {
+		o.Action = AgentLockWait
+	}
+	if o.StaleAfter == 0 {
+		o.StaleAfter = DefaultAgentLockStaleAfter
+	}
+	if o.Timeout == 0 {
+		o.Timeout = DefaultAgentLockTimeout
+	}
+	return o
+}
+
+// Validate checks the action and durations (zero values take the defaults).
+func (o AgentLockOptions) Validate() error {
+	o = o.withDefaults()
+	switch o.Action {
+	case AgentLockWait, AgentLockClear, AgentLockFail:
+	default:
+		return fmt.Errorf("invalid agent lock action %q: use %s, %s or %s", o.Action, AgentLockWait, AgentLockClear, AgentLockFail)
+	}
+	if o.StaleAfter < time.Minute {
+		return fmt.Errorf("invalid agent lock stale age %s: must be at least 1m", o.StaleAfter)
+	}
+	if o.Timeout < 0 || o.Timeout > MaxAgentLockTimeout {
+		return fmt.Errorf("invalid agent lock timeout %s: must be between 0 and %s", o.Timeout, MaxAgentLockTimeout)
+	}
+	return nil
+}
+
+// getAgentLockAge returns the age of the agent run lock on the instance,
+// or 0 when there is no lock. Like getCertnameFromConfig, it runs before the
+// install script so the age is recorded in the metadata even if the
+// script fails.
+func (pi *PuppetInstaller) getAgentLockAge(ctx context.Context, instance *cloud.Instance, provider cloud.CloudProvider) (time.Duration, error) {
+	lock := pi.repo.paths().stateDir + "/" + agentLockFile
+	probeScript := `#!/bin/sh
+if [ ! -f ` + lock + ` ]; then
+    echo "NOT_FOUND"
+    exit 0
+fi
+echo $(( $(date +%s) - $(stat -c %Y ` + lock + `) ))
+`
+
+	result, err := provider.ExecuteCommand(cloud.ReadOnlyCommands(ctx), instance, []string{probeScript}, DefaultSSMTimeout)
+	if err != nil {
+		return 0, fmt.Errorf("failed to check the agent run lock: %w", err)
+	}
+
+	output := strings.TrimSpace(result.Stdout)
+	if output == "NOT_FOUND" || output == "" {
+		return 0, nil
+	}
+	seconds, err := strconv.ParseInt(output, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("unexpected agent run lock age %q", output)
+	}
+	// A lock written in the same second (or with clock skew) still counts
+	return max(time.Duration(seconds)*time.Second, time.Second), nil
+}
+
+// generateAgentLockScript generates shell script that checks the agent run
+// lock before the initial puppet run, following pi.agentLock.
+func (pi *PuppetInstaller) generateAgentLockScript() string {
+	opts := pi.agentLock
+	lock := pi.repo.paths().stateDir + "/" + agentLockFile
+	stale := int64(opts.StaleAfter / time.Second)
+	timeout := int64(opts.Timeout / time.Second)
+
+	wait := fmt.Sprintf(`        WAITED=0
+        while [ -f "$AGENT_LOCK" ] && [ "$WAITED" -lt %d ]; do
+            sleep 10
+            WAITED=$((WAITED + 10))
+        done
+        if [ -f "$AGENT_LOCK" ]; then
+            echo "ERROR: puppet agent run lock still held after %ds (age ${LOCK_AGE}s, pid $LOCK_PID)"
+            exit %d
+        fi
+        echo "  ✓ Agent run lock released after ${WAITED}s"
+`, timeout, timeout, ExitCodeValidation)
+
+	var staleAction string
+	switch opts.Action {
+	case AgentLockClear:
+		staleAction = `        echo "  ⚠️  Stale agent run lock - stopping pid $LOCK_PID and removing the lock"
+        kill "$LOCK_PID" 2>/dev/null || true
+        for i in 1 2 3 4 5; do
+            kill -0 "$LOCK_PID" 2>/dev/null || break
+            sleep 1
+        done
+        kill -9 "$LOCK_PID" 2>/dev/null || true
+        rm -f "$AGENT_LOCK"
+        echo "  ✓ Stale agent run lock cleared"
+`
+	case AgentLockFail:
+		staleAction = fmt.Sprintf(`        echo "ERROR: stale puppet agent run lock (age ${LOCK_AGE}s, pid $LOCK_PID) - clear it or use --agent-lock clear"
+        exit %d
+`, ExitCodeValidation)
+	default:
+		staleAction = `        echo "  ⚠️  Stale agent run lock - waiting for it to be released"
+` + wait
+	}
+
+	return fmt.Sprintf(`# Check for a stuck puppet agent run (--agent-lock %s)
+AGENT_LOCK=%s
+if [ -f "$AGENT_LOCK" ]; then
+    LOCK_PID=$(cat "$AGENT_LOCK" 2>/dev/null || true)
+    LOCK_AGE=$(( $(date +%%s) - $(stat -c %%Y "$AGENT_LOCK" 2>/dev/null || date +%%s) ))
+    echo "Puppet agent run lock found (pid ${LOCK_PID:-unknown}, age ${LOCK_AGE}s)"
+    if [ -z "$LOCK_PID" ] || ! grep -qa puppet "/proc/$LOCK_PID/cmdline" 2>/dev/null; then
+        echo "  ✓ Lock holder is not running - the agent removes the lock"
+    elif [ "$LOCK_AGE" -ge %d ]; then
+%s    else
+        echo "  Agent run in progress - waiting for it to finish"
+%s    fi
+fi
+`, opts.Action, lock, stale, staleAction, wait)
+}
@@ -0,0 +1,127 @@
+package installer
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestAgentLockOptions_Validate tests the --agent-lock settings.
+func TestAgentLockOptions_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    AgentLockOptions
+		wantErr bool
+	}{
+		{"defaults", AgentLockOptions{}, false},
+		{"clear", AgentLockOptions{Action: AgentLockClear, StaleAfter: 2 * time.Hour}, false},
+		{"fail", AgentLockOptions{Action: AgentLockFail, Timeout: MaxAgentLockTimeout}, false},
+		{"unknown action", AgentLockOptions{Action: "kill"}, true},
+		{"stale age too short", AgentLockOptions{StaleAfter: 30 * time.Second}, true},
+		{"timeout too long", AgentLockOptions{Timeout: MaxAgentLockTimeout + time.Second}, true},
+		{"negative timeout", AgentLockOptions{Timeout: -time.Second}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.opts.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	if got := NewPuppetInstaller(PuppetOptions{}).agentLock; got.Action != AgentLockWait ||
+		got.StaleAfter != DefaultAgentLockStaleAfter || got.Timeout != DefaultAgentLockTimeout {
+		t.Errorf("default agent lock options = %+v", got)
+	}
+}
+
+// TestGenerateAgentLockScript runs the lock check against a lock held by a
+// fake puppet process and checks each action.
+func TestGenerateAgentLockScript(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+	if _, err := os.Stat("/proc/self/cmdline"); err != nil {
+		t.Skip("/proc not available")
+	}
+
+	tests := []struct {
+		name      string
+		action    string
+		lockAge   time.Duration
+		deadPID   bool // lock holder no longer running
+		wantExit  int
+		wantLock  bool // lock still present afterwards
+		wantInLog string
+	}{
+		{"no holder", AgentLockFail, 3 * time.Hour, true, 0, true, "Lock holder is not running"},
+		{"stale lock fails", AgentLockFail, 3 * time.Hour, false, ExitCodeValidation, true, "stale puppet agent run lock"},
+		{"stale lock cleared", AgentLockClear, 3 * time.Hour, false, 0, false, "Stale agent run lock cleared"},
+		{"stale lock waited", AgentLockWait, 3 * time.Hour, false, ExitCodeValidation, true, "still held after 0s"},
+		{"run in progress", AgentLockClear, time.Minute, false, ExitCodeValidation, true, "Agent run in progress"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// ARRANGE: a process whose command line mentions puppet holds the lock
+			dir := t.TempDir()
+			fake := filepath.Join(dir, "puppet-agent.sh")
+			if err := os.WriteFile(fake, []byte("sleep 30\n"), 0o755); err != nil {
+				t.Fatal(err)
+			}
+			holder := exec.Command("sh", fake)
+			if err := holder.Start(); err != nil {
+				t.Fatal(err)
+			}
+			done := make(chan struct{})
+			go func() { holder.Wait(); close(done) }()
+			t.Cleanup(func() { holder.Process.Kill(); <-done })
+
+			pid := strconv.Itoa(holder.Process.Pid)
+			if tt.deadPID {
+				pid = "999999999"
+			}
+			lock := filepath.Join(dir, agentLockFile)
+			if err := os.WriteFile(lock, []byte(pid), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			modTime := time.Now().Add(-tt.lockAge)
+			if err := os.Chtimes(lock, modTime, modTime); err != nil {
+				t.Fatal(err)
+			}
+
+			pi := NewPuppetInstaller(PuppetOptions{
+				Server:    "puppet.example.com",
+				AgentLock: AgentLockOptions{Action: tt.action, Timeout: time.Nanosecond},
+			})
+			script := strings.ReplaceAll(pi.generateAgentLockScript(), "/opt/puppetlabs/puppet/cache/state", dir)
+
+			// ACT
+			out, err := exec.Command("sh", "-c", script).CombinedOutput()
+
+			// ASSERT
+			exitCode := 0
+			var exitErr *exec.ExitError
+			if errors.As(err, &exitErr) {
+				exitCode = exitErr.ExitCode()
+			} else if err != nil {
+				t.Fatal(err)
+			}
+			if exitCode != tt.wantExit {
+				t.Errorf("exit code = %d, want %d\n%s", exitCode, tt.wantExit, out)
+			}
+			if !strings.Contains(string(out), tt.wantInLog) {
+				t.Errorf("output missing %q:\n%s", tt.wantInLog, out)
+			}
+			if _, err := os.Stat(lock); (err == nil) != tt.wantLock {
+				t.Errorf("lock present = %v, want %v", err == nil, tt.wantLock)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package installer
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// DefaultProxyPort is the http_proxy_port used when only the host is set
+// (the Puppet default).
+const DefaultProxyPort = 3128
+
+// PuppetProxy is the HTTP proxy agents in private subnets use to reach the
+// Puppet Server and the Forge, written to the [agent] section of puppet.conf
+// (zero value = direct connections).
+type PuppetProxy struct {
+	Host    string   // http_proxy_host ("" = no proxy)
+	Port    int      // http_proxy_port (default: DefaultProxyPort)
+	NoProxy []string // no_proxy: hosts reached directly, "*.domain" or ".domain" for a whole domain
+}
+
+// Enabled reports whether a proxy is configured.
+func (p PuppetProxy) Enabled() bool {
+	return p.Host != ""
+}
+
+// Validate checks the proxy settings. Host is a bare hostname or IP: Puppet
+// takes the port from http_proxy_port and has no scheme setting.
+func (p PuppetProxy) Validate() error {
+	if !p.Enabled() {
+		if p.Port != 0 || len(p.NoProxy) > 0 {
+			return fmt.Errorf("proxy port and no_proxy require a proxy host")
+		}
+		return nil
+	}
+	if strings.Contains(p.Host, "://") || strings.ContainsAny(p.Host, ":/ \t") {
+		return fmt.Errorf("invalid proxy host %q: use a bare hostname or IP (port goes in the proxy port)", p.Host)
+	}
+	if p.Port < 0 || p.Port > 65535 {
+		return fmt.Errorf("invalid proxy port %d", p.Port)
+	}
+	for _, host := range p.NoProxy {
+		if host == "" || strings.ContainsAny(host, ", \t") {
+			return fmt.Errorf("invalid no_proxy entry %q", host)
+		}
+	}
+	return nil
+}
+
+// port returns the proxy port, DefaultProxyPort when unset.
+func (p PuppetProxy) port() int {
+	if p.Port == 0 {
+		return DefaultProxyPort
+	}
+	return p.Port
+}
+
+// Bypasses reports whether host is reached directly (matched by NoProxy):
+// exact names, and "*.domain" or ".domain" for any host of the domain.
+func (p PuppetProxy) Bypasses(host string) bool {
+	host = strings.ToLower(host)
+	for _, entry := range p.NoProxy {
+		entry = strings.ToLower(entry)
+		switch {
+		case entry == "*", entry == host:
+			return true
+		case strings.HasPrefix(entry, "*.") && strings.HasSuffix(host, entry[1:]):
+			return true
+		case strings.HasPrefix(entry, ".") && strings.HasSuffix(host, entry):
+			return true
+		}
+	}
+	return false
+}
+
+// confLines returns the puppet.conf settings of the proxy (nil when disabled).
+func (p PuppetProxy) confLines() []string {
+	if !p.Enabled() {
+		return nil
+	}
+	lines := []string{
+		"http_proxy_host = " + p.Host,
+		"http_proxy_port = " + strconv.Itoa(p.port()),
+	}
+	if len(p.NoProxy) > 0 {
+		lines = append(lines, "no_proxy = "+strings.Join(p.NoProxy, ","))
+	}
+	return lines
+}
@@ -0,0 +1,140 @@
+package installer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/estudosdevops/opsmaster/internal/cloud"
+	"github.com/estudosdevops/opsmaster/internal/cloud/cloudtest"
+)
+
+// TestPuppetProxy_Validate tests proxy settings validation.
+func TestPuppetProxy_Validate(t *testing.T) {
+	tests := []struct {
+		name        string
+		proxy       PuppetProxy
+		expectError bool
+	}{
+		{"no proxy", PuppetProxy{}, false},
+		{"host only", PuppetProxy{Host: "proxy.internal"}, false},
+		{"host, port and no_proxy", PuppetProxy{Host: "10.0.0.5", Port: 8080, NoProxy: []string{"puppet.internal", "*.corp"}}, false},
+		{"url instead of host", PuppetProxy{Host: "http://proxy.internal"}, true},
+		{"port in host", PuppetProxy{Host: "proxy.internal:3128"}, true},
+		{"invalid port", PuppetProxy{Host: "proxy.internal", Port: 70000}, true},
+		{"port without host", PuppetProxy{Port: 3128}, true},
+		{"no_proxy without host", PuppetProxy{NoProxy: []string{"puppet.internal"}}, true},
+		{"comma in no_proxy entry", PuppetProxy{Host: "proxy.internal", NoProxy: []string{"a,b"}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.proxy.Validate()
+			if tt.expectError && err == nil {
+				t.Errorf("Validate() expected error for %+v", tt.proxy)
+			}
+			if !tt.expectError && err != nil {
+				t.Errorf("Validate() unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+// TestPuppetProxy_Bypasses tests no_proxy matching.
+func TestPuppetProxy_Bypasses(t *testing.T) {
+	proxy := PuppetProxy{Host: "proxy.internal", NoProxy: []string{"Puppet.Internal", "*.corp.example", ".lan"}}
+	tests := []struct {
+		host string
+		want bool
+	}{
+		{"puppet.internal", true},
+		{"compiler.corp.example", true},
+		{"corp.example", false},
+		{"ca.lan", true},
+		{"forgeapi.puppet.com", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			if got := proxy.Bypasses(tt.host); got != tt.want {
+				t.Errorf("Bypasses(%q) = %v, want %v", tt.host, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestGenerateInstallScript_Proxy tests the proxy settings in the [agent]
+// section of puppet.conf.
+func TestGenerateInstallScript_Proxy(t *testing.T) {
+	installer := NewPuppetInstaller(PuppetOptions{
+		Server: "puppet.example.com",
+		Proxy:  PuppetProxy{Host: "proxy.internal", NoProxy: []string{"puppet.example.com", ".lan"}},
+	})
+	scripts, err := installer.GenerateInstallScript("debian", nil)
+	if err != nil {
+		t.Fatalf("GenerateInstallScript() unexpected error: %v", err)
+	}
+
+	want := "[agent]\nserver = puppet.example.com\nenvironment = production\ncertname = "
+	if !strings.Contains(scripts[0], want) {
+		t.Fatalf("Script missing agent section %q", want)
+	}
+	for _, setting := range []string{
+		"runinterval = 1h\nhttp_proxy_host = proxy.internal\nhttp_proxy_port = 3128\nno_proxy = puppet.example.com,.lan\nEOF",
+		"Proxy: proxy.internal:3128",
+	} {
+		if !strings.Contains(scripts[0], setting) {
+			t.Errorf("Script missing %q", setting)
+		}
+	}
+
+	direct := NewPuppetInstaller(PuppetOptions{Server: "puppet.example.com"})
+	scripts, err = direct.GenerateInstallScript("debian", nil)
+	if err != nil {
+		t.Fatalf("GenerateInstallScript() unexpected error: %v", err)
+	}
+	if strings.Contains(scripts[0], "http_proxy_host") {
+		t.Error("Script without proxy should not set http_proxy_host")
+	}
+}
+
+// TestValidatePrerequisites_Proxy tests that the proxy, not the Puppet
+// Server, is checked unless the server bypasses it.
+func TestValidatePrerequisites_Proxy(t *testing.T) {
+	tests := []struct {
+		name    string
+		noProxy []string
+		want    string
+	}{
+		{name: "server behind proxy", want: "proxy.internal:3128"},
+		{name: "server in no_proxy", noProxy: []string{"puppet.example.com"}, want: "puppet.example.com:8140"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			var checked []string
+			provider := &cloudtest.Provider{
+				TestConnectivityFunc: func(_ context.Context, _ *cloud.Instance, host string, port int) error {
+					mu.Lock()
+					defer mu.Unlock()
+					checked = append(checked, fmt.Sprintf("%s:%d", host, port))
+					return nil
+				},
+			}
+			installer := NewPuppetInstaller(PuppetOptions{
+				Server: "puppet.example.com",
+				Proxy:  PuppetProxy{Host: "proxy.internal", NoProxy: tt.noProxy},
+			})
+
+			if err := installer.ValidatePrerequisites(context.Background(), &cloud.Instance{ID: "i-1"}, provider); err != nil {
+				t.Fatalf("ValidatePrerequisites() unexpected error: %v", err)
+			}
+			if len(checked) != 1 || checked[0] != tt.want {
+				t.Errorf("checked %v, want [%s]", checked, tt.want)
+			}
+		})
+	}
+}
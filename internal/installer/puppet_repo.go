@@ -0,0 +1,621 @@
+package installer
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// puppetRepoSupport describes which OS releases have an official Puppet
+// release package for a given Puppet major version.
+//
+// Release packages follow the naming used by apt.puppet.com and yum.puppet.com:
+//   - Debian/Ubuntu: puppet<N>-release-<codename>.deb
+//   - RHEL family:   puppet<N>-release-el-<major>.noarch.rpm
+//   - Amazon Linux:  puppet<N>-release-<suffix>.noarch.rpm
+type puppetRepoSupport struct {
+	DebianCodenames []string          // Supported Debian/Ubuntu VERSION_CODENAME values
+	ELVersions      []string          // Supported RHEL-family major versions
+	AmazonRepos     map[string]string // Amazon Linux VERSION_ID -> release package suffix
+}
+
+// puppetRepoMatrix maps Puppet major versions to supported OS releases.
+// Puppet 8 dropped older releases (buster, bionic) and ships dedicated
+// Amazon Linux 2 packages instead of reusing EL7.
+var puppetRepoMatrix = map[string]puppetRepoSupport{
+	"7": {
+		DebianCodenames: []string{"buster", "bullseye", "bookworm", "bionic", "focal", "jammy", "noble"},
+		ELVersions:      []string{"7", "8", "9"},
+		AmazonRepos: map[string]string{
+			"2":    "el-7", // Amazon Linux 2 uses EL7 repos (validated and working)
+			"2023": "amazon-2023",
+		},
+	},
+	"8": {
+		DebianCodenames: []string{"bullseye", "bookworm", "focal", "jammy", "noble"},
+		ELVersions:      []string{"7", "8", "9"},
+		AmazonRepos: map[string]string{
+			"2":    "amazon-2",
+			"2023": "amazon-2023",
+		},
+	},
+}
+
+// puppetMajorVersion extracts the major version from a version string.
+// Examples: "7" → "7", "8.4.0" → "8".
+func puppetMajorVersion(version string) string {
+	major, _, _ := strings.Cut(strings.TrimSpace(version), ".")
+	return major
+}
+
+// SupportedPuppetVersions returns the Puppet major versions with known repo layouts.
+func SupportedPuppetVersions() []string {
+	versions := make([]string, 0, len(puppetRepoMatrix))
+	for version := range puppetRepoMatrix {
+		versions = append(versions, version)
+	}
+	sort.Strings(versions)
+	return versions
+}
+
+// ValidatePuppetVersion checks if the Puppet version has a known repo layout.
+// Use it to fail early (e.g., on CLI flag parsing) before touching any instance.
+func ValidatePuppetVersion(version string) error {
+	if _, err := resolveRepoSupport(version); err != nil {
+		return err
+	}
+	return nil
+}
+
+// resolveRepoSupport returns the repo support matrix for a Puppet version.
+func resolveRepoSupport(version string) (puppetRepoSupport, error) {
+	support, ok := puppetRepoMatrix[puppetMajorVersion(version)]
+	if !ok {
+		return puppetRepoSupport{}, fmt.Errorf("unsupported puppet version: %s (supported: %s)",
+			version, strings.Join(SupportedPuppetVersions(), ", "))
+	}
+	return support, nil
+}
+
+// IsPlatformSupported checks if a Puppet version can be installed on an OS release.
+// osID and osVersion follow /etc/os-release (ID and VERSION_CODENAME for Debian family,
+// ID and VERSION_ID for RHEL family).
+func IsPlatformSupported(version, osID, osVersion string) bool {
+	support, err := resolveRepoSupport(version)
+	if err != nil {
+		return false
+	}
+
+	normalized, err := normalizeOS(osID)
+	if err != nil {
+		return false
+	}
+
+	switch {
+	case normalized == OSTypeDebian:
+		return containsString(support.DebianCodenames, osVersion)
+	case strings.EqualFold(osID, "amzn"):
+		_, ok := support.AmazonRepos[osVersion]
+		return ok
+	default:
+		return containsString(support.ELVersions, puppetMajorVersion(osVersion))
+	}
+}
+
+// containsString reports whether value is present in list.
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+// generateDebianRepoCheckScript generates shell script that fails early when
+// the Debian/Ubuntu release has no Puppet release package for this version.
+// Expects VERSION_CODENAME to be set (sourced from /etc/os-release).
+func (pi *PuppetInstaller) generateDebianRepoCheckScript() string {
+	if pi.repo.IsDistro() {
+		return ""
+	}
+	support, _ := resolveRepoSupport(pi.puppetVersion)
+	major := puppetMajorVersion(pi.puppetVersion)
+
+	return fmt.Sprintf(`# Check Puppet %[1]s support for this release
+case "${VERSION_CODENAME}" in
+    %[2]s)
+        echo "✓ Puppet %[1]s is supported on ${VERSION_CODENAME}"
+        ;;
+    *)
+        echo "ERROR: Puppet %[1]s is not supported on ${NAME} ${VERSION} (${VERSION_CODENAME})"
+        echo "Supported releases: %[3]s"
+        exit %[4]d
+        ;;
+esac
+`, major, strings.Join(support.DebianCodenames, "|"), strings.Join(support.DebianCodenames, ", "), ExitCodeValidation)
+}
+
+// generateRHELRepoResolveScript generates shell script that resolves the
+// release package suffix (REPO_SUFFIX) for RHEL-family and Amazon Linux,
+// failing early when the release is not supported by this Puppet version.
+// Expects ID and VERSION_ID to be set (sourced from /etc/os-release).
+func (pi *PuppetInstaller) generateRHELRepoResolveScript() string {
+	if pi.repo.IsDistro() {
+		return ""
+	}
+	support, _ := resolveRepoSupport(pi.puppetVersion)
+	major := puppetMajorVersion(pi.puppetVersion)
+
+	// Sort Amazon versions for deterministic output
+	amazonVersions := make([]string, 0, len(support.AmazonRepos))
+	for version := range support.AmazonRepos {
+		amazonVersions = append(amazonVersions, version)
+	}
+	sort.Strings(amazonVersions)
+
+	var amazonCases strings.Builder
+	for _, version := range amazonVersions {
+		amazonCases.WriteString(fmt.Sprintf("        %s) REPO_SUFFIX=\"%s\" ;;\n", version, support.AmazonRepos[version]))
+	}
+
+	return fmt.Sprintf(`# Resolve Puppet %[1]s repository for this release
+EL_MAJOR=$(echo "$VERSION_ID" | cut -d. -f1)
+REPO_SUFFIX=""
+if [ "$ID" = "amzn" ]; then
+    case "$VERSION_ID" in
+%[2]s    esac
+else
+    case "$EL_MAJOR" in
+        %[3]s) REPO_SUFFIX="el-${EL_MAJOR}" ;;
+    esac
+fi
+
+if [ -z "$REPO_SUFFIX" ]; then
+    echo "ERROR: Puppet %[1]s is not supported on ${NAME} ${VERSION_ID}"
+    echo "Supported releases: EL %[4]s, Amazon Linux %[5]s"
+    exit %[6]d
+fi
+echo "✓ Puppet %[1]s repository: ${REPO_SUFFIX}"
+`, major, amazonCases.String(), strings.Join(support.ELVersions, "|"),
+		strings.Join(support.ELVersions, "/"), strings.Join(amazonVersions, "/"), ExitCodeValidation)
+}
+
+// Puppet package sources (PuppetRepoOptions.Source).
+const (
+	RepoSourcePuppetlabs = "puppetlabs" // puppet-agent (AIO) from apt/yum.puppet.com or their mirrors
+	RepoSourceDistro     = "distro"     // puppet package of the OS vendor repositories
+)
+
+// puppetPaths are the locations of a Puppet installation, which depend on
+// the package source: puppet-agent (AIO) lives under /opt/puppetlabs and
+// /etc/puppetlabs, vendor packages use the FHS paths.
+type puppetPaths struct {
+	bin       string // puppet binary
+	confDir   string // puppet.conf and csr_attributes.yaml
+	sslDir    string // Agent certificates
+	factsDir  string // External facts
+	facterDir string // facter.conf
+	logFile   string // Agent log, when logging to a file instead of syslog
+	stateDir  string // Agent state (run locks, last run summary)
+}
+
+var (
+	puppetlabsPaths = puppetPaths{
+		bin:       "/opt/puppetlabs/bin/puppet",
+		confDir:   "/etc/puppetlabs/puppet",
+		sslDir:    "/etc/puppetlabs/puppet/ssl",
+		factsDir:  "/opt/puppetlabs/facter/facts.d",
+		facterDir: "/etc/puppetlabs/facter",
+		logFile:   "/var/log/puppetlabs/puppet/puppet.log",
+		stateDir:  "/opt/puppetlabs/puppet/cache/state",
+	}
+	distroPaths = puppetPaths{
+		bin:       "/usr/bin/puppet",
+		confDir:   "/etc/puppet",
+		sslDir:    "/var/lib/puppet/ssl",
+		factsDir:  "/etc/facter/facts.d",
+		facterDir: "/etc/facter",
+		logFile:   "/var/log/puppet/puppet.log",
+		stateDir:  "/var/cache/puppet/state",
+	}
+)
+
+// paths returns the installation paths of the configured package source.
+func (o PuppetRepoOptions) paths() puppetPaths {
+	if o.IsDistro() {
+		return distroPaths
+	}
+	return puppetlabsPaths
+}
+
+// packageName returns the package installing the agent: puppet-agent (AIO)
+// or the vendor "puppet" package (a transitional package on newer releases).
+func (o PuppetRepoOptions) packageName() string {
+	if o.IsDistro() {
+		return "puppet"
+	}
+	return "puppet-agent"
+}
+
+// IsDistro reports whether Puppet is installed from the OS vendor repositories.
+func (o PuppetRepoOptions) IsDistro() bool {
+	return o.Source == RepoSourceDistro
+}
+
+// Paths of the mirror signing key on the instance.
+const (
+	puppetMirrorKeyring = "/usr/share/keyrings/opsmaster-puppet-mirror.gpg"
+	puppetMirrorRPMKey  = "/etc/pki/rpm-gpg/RPM-GPG-KEY-opsmaster-puppet-mirror"
+)
+
+// PuppetRepoOptions installs Puppet from internal mirrors of apt.puppet.com
+// and yum.puppet.com (e.g., re-signed repos mandated by security) instead of
+// the official release packages. Mirrors must keep the upstream layout:
+//   - apt: dists/<codename>/puppet<N> (sources line "<AptURL> <codename> puppet<N>")
+//   - yum: puppet<N>/el/<major>/<arch> and puppet<N>/amazon/<version>/<arch>
+//
+// The zero value uses the official repos. A family without a mirror URL
+// keeps using the official repo. Source "distro" installs the vendor
+// package instead, without configuring any Puppet repository.
+type PuppetRepoOptions struct {
+	Source         string // Package source: puppetlabs (default) or distro
+	AptURL         string // Debian/Ubuntu mirror base URL
+	YumURL         string // RHEL/Amazon Linux mirror base URL
+	GPGKeyURL      string // Key signing the mirrors (required unless SkipGPGCheck)
+	GPGFingerprint string // Expected key fingerprint, checked on the instance before trusting the key (optional)
+	SkipGPGCheck   bool   // Trust the mirrors without signature checks (discouraged: air-gapped mirrors only)
+
+	// Official release packages (puppet<N>-release) are verified before
+	// installing them: the keys a .deb trusts and the signature of an .rpm
+	// must match the pinned fingerprints
+	ReleaseFingerprints []string // Pinned release key fingerprints (default: PuppetReleaseFingerprints)
+	ReleaseKeyURL       string   // Key verifying the .rpm signature (default: DefaultPuppetReleaseKeyURL)
+}
+
+// PuppetReleaseFingerprints are the pinned fingerprints of the Puppet, Inc.
+// release key signing the official release packages. When Puppet rotates
+// its key, pin the new one with PuppetRepoOptions.ReleaseFingerprints.
+var PuppetReleaseFingerprints = []string{"D6811ED3ADEEB8441AF5AA8F4528B6CD9E61EF26"}
+
+// DefaultPuppetReleaseKeyURL is the Puppet, Inc. release key imported to
+// verify the signature of the official .rpm release packages.
+const DefaultPuppetReleaseKeyURL = "https://yum.puppet.com/RPM-GPG-KEY-puppet-20250406"
+
+// fingerprintPattern matches a normalized OpenPGP v4 fingerprint.
+var fingerprintPattern = regexp.MustCompile(`^[0-9A-F]{40}$`)
+
+// IsMirror reports whether any mirror is configured.
+func (o PuppetRepoOptions) IsMirror() bool {
+	return o.AptURL != "" || o.YumURL != ""
+}
+
+// Validate checks the source, URLs and GPG settings and normalizes the
+// fingerprint (spaces removed, upper case) and URLs (no trailing slash).
+func (o *PuppetRepoOptions) Validate() error {
+	switch o.Source {
+	case "", RepoSourcePuppetlabs:
+	case RepoSourceDistro:
+		if o.IsMirror() {
+			return fmt.Errorf("puppet mirrors require the %s repo source", RepoSourcePuppetlabs)
+		}
+	default:
+		return fmt.Errorf("invalid repo source %q (valid: %s, %s)", o.Source, RepoSourcePuppetlabs, RepoSourceDistro)
+	}
+
+	for _, u := range []*string{&o.AptURL, &o.YumURL, &o.GPGKeyURL} {
+		*u = strings.TrimRight(strings.TrimSpace(*u), "/")
+		if *u == "" {
+			continue
+		}
+		if _, _, err := urlEndpoint(*u); err != nil {
+			return err
+		}
+	}
+	o.GPGFingerprint = strings.ToUpper(strings.ReplaceAll(o.GPGFingerprint, " ", ""))
+	if err := o.validateRelease(); err != nil {
+		return err
+	}
+
+	switch {
+	case !o.IsMirror() && (o.GPGKeyURL != "" || o.GPGFingerprint != "" || o.SkipGPGCheck):
+		return fmt.Errorf("GPG settings require a puppet mirror URL (apt or yum)")
+	case !o.IsMirror():
+		return nil
+	case o.SkipGPGCheck && (o.GPGKeyURL != "" || o.GPGFingerprint != ""):
+		return fmt.Errorf("skip GPG check conflicts with GPG key settings")
+	case !o.SkipGPGCheck && o.GPGKeyURL == "":
+		return fmt.Errorf("puppet mirror requires a GPG key URL (or skipping the GPG check)")
+	case o.GPGFingerprint != "" && !fingerprintPattern.MatchString(o.GPGFingerprint):
+		return fmt.Errorf("invalid GPG fingerprint %q: expected 40 hex characters", o.GPGFingerprint)
+	}
+	return nil
+}
+
+// validateRelease checks and normalizes the release package verification
+// settings, which only apply to the official (puppetlabs) repos.
+func (o *PuppetRepoOptions) validateRelease() error {
+	if o.IsDistro() && (len(o.ReleaseFingerprints) > 0 || o.ReleaseKeyURL != "") {
+		return fmt.Errorf("release package verification settings require the %s repo source", RepoSourcePuppetlabs)
+	}
+	for i, fingerprint := range o.ReleaseFingerprints {
+		fingerprint = strings.ToUpper(strings.ReplaceAll(fingerprint, " ", ""))
+		if !fingerprintPattern.MatchString(fingerprint) {
+			return fmt.Errorf("invalid release GPG fingerprint %q: expected 40 hex characters", o.ReleaseFingerprints[i])
+		}
+		o.ReleaseFingerprints[i] = fingerprint
+	}
+	o.ReleaseKeyURL = strings.TrimSpace(o.ReleaseKeyURL)
+	if o.ReleaseKeyURL != "" {
+		if _, _, err := urlEndpoint(o.ReleaseKeyURL); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// releaseFingerprints returns the pinned release key fingerprints.
+func (o PuppetRepoOptions) releaseFingerprints() []string {
+	if len(o.ReleaseFingerprints) > 0 {
+		return o.ReleaseFingerprints
+	}
+	return PuppetReleaseFingerprints
+}
+
+// releaseKeyURL returns the URL of the key verifying .rpm release packages.
+func (o PuppetRepoOptions) releaseKeyURL() string {
+	if o.ReleaseKeyURL != "" {
+		return o.ReleaseKeyURL
+	}
+	return DefaultPuppetReleaseKeyURL
+}
+
+// generateReleaseKeyCheckScript generates shell script that fails unless
+// the primary keys in keyFiles (a shell word list) are all pinned release
+// keys, and at least one is present. what names the checked files in
+// messages.
+func (o PuppetRepoOptions) generateReleaseKeyCheckScript(keyFiles, what string) string {
+	return fmt.Sprintf(`if ! command -v gpg >/dev/null 2>&1; then
+    echo "ERROR: gpg is required to verify the Puppet release package (install gnupg)"
+    exit %[4]d
+fi
+PINNED_FPRS=" %[3]s "
+KEY_FOUND=0
+for KEY_FILE in %[1]s; do
+    [ -f "${KEY_FILE}" ] || continue
+    KEY_FPRS=$(gpg --batch --with-colons --show-keys "${KEY_FILE}" 2>/dev/null || gpg --batch --with-colons --with-fingerprint "${KEY_FILE}" 2>/dev/null) || KEY_FPRS=""
+    # Primary keys only: the fpr record right after each pub record
+    for FPR in $(echo "${KEY_FPRS}" | awk -F: '$1 == "pub" {pub = 1; next} $1 == "fpr" && pub {print $10} {pub = 0}'); do
+        case "${PINNED_FPRS}" in
+            *" ${FPR} "*) KEY_FOUND=1 ;;
+            *)
+                echo "ERROR: %[2]s carries GPG key ${FPR}, which is not a pinned Puppet release key"
+                exit %[5]d
+                ;;
+        esac
+    done
+done
+if [ "${KEY_FOUND}" -ne 1 ]; then
+    echo "ERROR: no pinned Puppet release key (%[3]s) found in %[2]s"
+    exit %[5]d
+fi
+`, keyFiles, what, strings.Join(o.releaseFingerprints(), " "), ExitCodeValidation, ExitCodeDownload)
+}
+
+// releaseKeyIDs returns the short key IDs (last 8 hex digits, lower case)
+// of the pinned release keys as an extended regex alternation, matched
+// against the end of the key IDs printed by rpm -Kv.
+func (o PuppetRepoOptions) releaseKeyIDs() string {
+	ids := make([]string, 0, len(o.releaseFingerprints()))
+	for _, fingerprint := range o.releaseFingerprints() {
+		ids = append(ids, strings.ToLower(fingerprint[len(fingerprint)-8:]))
+	}
+	return strings.Join(ids, "|")
+}
+
+// Endpoints returns the distinct host:port pairs instances must reach to
+// install from the mirrors (mirrors and key), for preflight checks.
+func (o PuppetRepoOptions) Endpoints() []string {
+	var endpoints []string
+	for _, u := range []string{o.AptURL, o.YumURL, o.GPGKeyURL} {
+		if u == "" {
+			continue
+		}
+		host, port, err := urlEndpoint(u)
+		if err != nil {
+			continue
+		}
+		endpoint := net.JoinHostPort(host, strconv.Itoa(port))
+		if !containsString(endpoints, endpoint) {
+			endpoints = append(endpoints, endpoint)
+		}
+	}
+	return endpoints
+}
+
+// urlEndpoint returns the host and port of an http(s) URL.
+func urlEndpoint(rawURL string) (string, int, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return "", 0, fmt.Errorf("invalid repository URL %q: expected http(s)://host/path", rawURL)
+	}
+	if u.Port() == "" {
+		if u.Scheme == "http" {
+			return u.Hostname(), 80, nil
+		}
+		return u.Hostname(), 443, nil
+	}
+	port, err := strconv.Atoi(u.Port())
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid port in repository URL %q", rawURL)
+	}
+	return u.Hostname(), port, nil
+}
+
+// generateMirrorKeyScript generates shell script that downloads the mirror
+// key to keyFile and, when a fingerprint is set, refuses keys that don't
+// match it. Expects STAGE_DIR and the download function to be defined.
+func (o PuppetRepoOptions) generateMirrorKeyScript() string {
+	var check string
+	if o.GPGFingerprint != "" {
+		check = fmt.Sprintf(`if ! command -v gpg >/dev/null 2>&1; then
+    echo "ERROR: gpg is required to check the mirror key fingerprint"
+    exit 10
+fi
+KEY_FPRS=$(gpg --batch --with-colons --show-keys "${MIRROR_KEY}" 2>/dev/null || gpg --batch --with-colons --with-fingerprint "${MIRROR_KEY}" 2>/dev/null) || KEY_FPRS=""
+if ! echo "${KEY_FPRS}" | awk -F: '$1 == "fpr" {print $10}' | grep -x "%[1]s" >/dev/null; then
+    echo "ERROR: mirror GPG key fingerprint does not match %[1]s"
+    exit 20
+fi
+echo "✓ Mirror GPG key fingerprint verified"
+`, o.GPGFingerprint)
+	}
+
+	return fmt.Sprintf(`MIRROR_KEY="${STAGE_DIR}/puppet-mirror.key"
+if ! download "%s" > "${MIRROR_KEY}"; then
+    echo "Error downloading mirror GPG key"
+    exit 20
+fi
+%s`, o.GPGKeyURL, check)
+}
+
+// distroRepoScript replaces the repository setup when installing the vendor
+// package (and the Puppet version checks: the release decides the version).
+const distroRepoScript = `# Puppet from the OS vendor repositories (no Puppet repository configured)
+echo "Installing Puppet from the ${NAME} repositories"
+`
+
+// generateDebianRepoScript generates shell script that configures the Puppet
+// apt repository: the official release package, the mirror or none (distro).
+// Expects VERSION_CODENAME and STAGE_DIR to be set.
+func (pi *PuppetInstaller) generateDebianRepoScript() string {
+	major := puppetMajorVersion(pi.puppetVersion)
+	repo := pi.repo
+	if repo.IsDistro() {
+		return distroRepoScript
+	}
+	if repo.AptURL == "" {
+		return fmt.Sprintf(`# Download and install Puppet repository
+echo "Installing Puppet %[1]s repository..."
+REPO_DEB="puppet%[1]s-release-${VERSION_CODENAME}.deb"
+if ! wget -q "https://apt.puppet.com/${REPO_DEB}" -O "${STAGE_DIR}/${REPO_DEB}"; then
+    echo "Error downloading Puppet repository package: ${REPO_DEB}"
+    exit 20
+fi
+# Verify the keys apt will trust for the repository (the .deb is unsigned)
+RELEASE_CHECK="${STAGE_DIR}/puppet-release-check"
+rm -rf "${RELEASE_CHECK}"
+if ! dpkg-deb -x "${STAGE_DIR}/${REPO_DEB}" "${RELEASE_CHECK}"; then
+    echo "ERROR: could not unpack ${REPO_DEB} for verification"
+    exit 20
+fi
+%[2]srm -rf "${RELEASE_CHECK}"
+echo "✓ Puppet repository package keys verified"
+if ! dpkg -i "${STAGE_DIR}/${REPO_DEB}"; then
+    echo "Error installing Puppet repository"
+    exit 20
+fi
+rm -f "${STAGE_DIR}/${REPO_DEB}"
+`, major, repo.generateReleaseKeyCheckScript(`$(find "${RELEASE_CHECK}" -type f \( -name '*.gpg' -o -name '*.asc' \))`, "${REPO_DEB}"))
+	}
+
+	options := "[trusted=yes]"
+	var key string
+	if !repo.SkipGPGCheck {
+		options = "[signed-by=" + puppetMirrorKeyring + "]"
+		key = repo.generateMirrorKeyScript() + fmt.Sprintf(`if grep -q "BEGIN PGP" "${MIRROR_KEY}"; then
+    gpg --batch --yes --dearmor -o %[1]s "${MIRROR_KEY}"
+else
+    cp "${MIRROR_KEY}" %[1]s
+fi
+chmod 644 %[1]s
+rm -f "${MIRROR_KEY}"
+`, puppetMirrorKeyring)
+	} else {
+		key = "echo \"WARNING: GPG check disabled for the Puppet mirror\"\n"
+	}
+
+	return fmt.Sprintf(`# Configure Puppet repository from internal mirror
+echo "Configuring Puppet %[1]s repository from mirror %[2]s..."
+%[3]s
+%[4]secho "deb %[5]s %[2]s ${VERSION_CODENAME} puppet%[1]s" > /etc/apt/sources.list.d/puppet%[1]s.list
+echo "✓ Puppet mirror repository configured"
+`, major, repo.AptURL, downloadShellFunc, key, options)
+}
+
+// generateRHELRepoScript generates shell script that configures the Puppet
+// yum repository: the official release package, the mirror or none (distro).
+// Expects REPO_SUFFIX (see generateRHELRepoResolveScript) and STAGE_DIR.
+func (pi *PuppetInstaller) generateRHELRepoScript() string {
+	major := puppetMajorVersion(pi.puppetVersion)
+	repo := pi.repo
+	if repo.IsDistro() {
+		return distroRepoScript
+	}
+	if repo.YumURL == "" {
+		return fmt.Sprintf(`# Install Puppet repository
+echo "Installing Puppet %[1]s repository..."
+REPO_RPM="puppet%[1]s-release-${REPO_SUFFIX}.noarch.rpm"
+%[2]s
+# Import the pinned release key and verify the package signature
+RELEASE_KEY="${STAGE_DIR}/puppet-release.key"
+if ! download "%[3]s" > "${RELEASE_KEY}"; then
+    echo "Error downloading Puppet release GPG key"
+    exit 20
+fi
+%[4]srpm --import "${RELEASE_KEY}"
+rm -f "${RELEASE_KEY}"
+if ! download "https://yum.puppet.com/${REPO_RPM}" > "${STAGE_DIR}/${REPO_RPM}"; then
+    echo "Error downloading Puppet repository package: ${REPO_RPM}"
+    echo "Please check if the repository URL is correct and accessible"
+    exit 20
+fi
+RPM_SIG=$(rpm -Kv "${STAGE_DIR}/${REPO_RPM}" 2>&1)
+if echo "${RPM_SIG}" | grep -Eq 'NOT OK|NOKEY|BAD' || ! echo "${RPM_SIG}" | grep -Eiq 'Signature, key ID [0-9a-f]*(%[5]s): OK'; then
+    echo "ERROR: signature verification failed for ${REPO_RPM}:"
+    echo "${RPM_SIG}"
+    exit 20
+fi
+echo "✓ Puppet repository package signature verified"
+if ! yum install -y "${STAGE_DIR}/${REPO_RPM}"; then
+    echo "Error installing Puppet repository: ${REPO_RPM}"
+    exit 20
+fi
+rm -f "${STAGE_DIR}/${REPO_RPM}"
+echo "✓ Puppet repository installed successfully"
+`, major, downloadShellFunc, repo.releaseKeyURL(),
+			repo.generateReleaseKeyCheckScript(`"${RELEASE_KEY}"`, "the release key"), repo.releaseKeyIDs())
+	}
+
+	gpg := "gpgcheck=0"
+	var key string
+	if !repo.SkipGPGCheck {
+		gpg = "gpgcheck=1\ngpgkey=file://" + puppetMirrorRPMKey
+		key = repo.generateMirrorKeyScript() + fmt.Sprintf(`mkdir -p /etc/pki/rpm-gpg
+mv "${MIRROR_KEY}" %[1]s
+chmod 644 %[1]s
+rpm --import %[1]s
+`, puppetMirrorRPMKey)
+	} else {
+		key = "echo \"WARNING: GPG check disabled for the Puppet mirror\"\n"
+	}
+
+	return fmt.Sprintf(`# Configure Puppet repository from internal mirror
+# Upstream layout: el-9 -> el/9, amazon-2023 -> amazon/2023
+REPO_PATH=$(echo "${REPO_SUFFIX}" | sed 's|-|/|')
+echo "Configuring Puppet %[1]s repository from mirror %[2]s..."
+%[3]s
+%[4]scat > /etc/yum.repos.d/puppet%[1]s.repo <<REPO_EOF
+[puppet%[1]s]
+name=Puppet %[1]s (mirror)
+baseurl=%[2]s/puppet%[1]s/${REPO_PATH}/\$basearch
+enabled=1
+%[5]s
+REPO_EOF
+echo "✓ Puppet mirror repository configured"
+`, major, repo.YumURL, downloadShellFunc, key, gpg)
+}
@@ -0,0 +1,326 @@
+package installer
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestValidatePuppetVersion tests version validation against the repo matrix.
+func TestValidatePuppetVersion(t *testing.T) {
+	tests := []struct {
+		version     string
+		expectError bool
+	}{
+		{"7", false},
+		{"8", false},
+		{"8.4.0", false},
+		{"6", true},
+		{"", true},
+		{"latest", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			err := ValidatePuppetVersion(tt.version)
+			if tt.expectError && err == nil {
+				t.Errorf("ValidatePuppetVersion(%q) expected error", tt.version)
+			}
+			if !tt.expectError && err != nil {
+				t.Errorf("ValidatePuppetVersion(%q) unexpected error: %v", tt.version, err)
+			}
+		})
+	}
+}
+
+// TestIsPlatformSupported tests OS compatibility checks per Puppet version.
+func TestIsPlatformSupported(t *testing.T) {
+	tests := []struct {
+		name      string
+		version   string
+		osID      string
+		osVersion string
+		expected  bool
+	}{
+		{"puppet 7 on ubuntu bionic", "7", "ubuntu", "bionic", true},
+		{"puppet 8 on ubuntu bionic", "8", "ubuntu", "bionic", false},
+		{"puppet 8 on debian bookworm", "8", "debian", "bookworm", true},
+		{"puppet 8 on rocky 9.3", "8", "rocky", "9.3", true},
+		{"puppet 8 on centos 6", "8", "centos", "6", false},
+		{"puppet 8 on amazon linux 2023", "8", "amzn", "2023", true},
+		{"puppet 7 on amazon linux 2", "7", "amzn", "2", true},
+		{"unsupported version", "5", "ubuntu", "jammy", false},
+		{"unsupported os", "8", "windows", "2022", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsPlatformSupported(tt.version, tt.osID, tt.osVersion); got != tt.expected {
+				t.Errorf("IsPlatformSupported(%q, %q, %q) = %v, want %v",
+					tt.version, tt.osID, tt.osVersion, got, tt.expected)
+			}
+		})
+	}
+}
+
+// TestGenerateInstallScript_VersionAwareRepos tests that scripts use the
+// repo layout of the requested Puppet version.
+func TestGenerateInstallScript_VersionAwareRepos(t *testing.T) {
+	t.Run("puppet 8 rhel uses amazon-2 package", func(t *testing.T) {
+		installer := NewPuppetInstaller(PuppetOptions{Server: "puppet.example.com", Version: "8"})
+		scripts, err := installer.GenerateInstallScript("rhel", nil)
+		if err != nil {
+			t.Fatalf("GenerateInstallScript() unexpected error: %v", err)
+		}
+
+		script := scripts[0]
+		for _, want := range []string{
+			`2) REPO_SUFFIX="amazon-2"`,
+			`puppet8-release-${REPO_SUFFIX}.noarch.rpm`,
+			"exit 10",
+		} {
+			if !strings.Contains(script, want) {
+				t.Errorf("Script missing %q", want)
+			}
+		}
+	})
+
+	t.Run("puppet 8 debian drops buster", func(t *testing.T) {
+		installer := NewPuppetInstaller(PuppetOptions{Server: "puppet.example.com", Version: "8"})
+		scripts, err := installer.GenerateInstallScript("debian", nil)
+		if err != nil {
+			t.Fatalf("GenerateInstallScript() unexpected error: %v", err)
+		}
+
+		script := scripts[0]
+		if strings.Contains(script, "buster") {
+			t.Error("Puppet 8 script should not accept buster")
+		}
+		if !strings.Contains(script, `REPO_DEB="puppet8-release-${VERSION_CODENAME}.deb"`) {
+			t.Error("Script missing puppet8 release package")
+		}
+	})
+
+	t.Run("unsupported version fails early", func(t *testing.T) {
+		installer := NewPuppetInstaller(PuppetOptions{Server: "puppet.example.com", Version: "5"})
+		if _, err := installer.GenerateInstallScript("debian", nil); err == nil {
+			t.Error("Expected error for unsupported puppet version")
+		}
+	})
+}
+
+// TestPuppetRepoOptions_Validate tests mirror URL and GPG settings validation.
+func TestPuppetRepoOptions_Validate(t *testing.T) {
+	const fpr = "D6811ED3ADEEB8441AF5AA8F4528B6CD9E61EF26"
+	tests := []struct {
+		name        string
+		opts        PuppetRepoOptions
+		expectError bool
+	}{
+		{"official repos", PuppetRepoOptions{}, false},
+		{"apt mirror with key", PuppetRepoOptions{AptURL: "https://mirror.example.com/apt/", GPGKeyURL: "https://mirror.example.com/key.asc"}, false},
+		{"yum mirror with fingerprint", PuppetRepoOptions{YumURL: "http://mirror:8080/yum", GPGKeyURL: "http://mirror:8080/key", GPGFingerprint: "d681 1ed3 adee b844 1af5 aa8f 4528 b6cd 9e61 ef26"}, false},
+		{"mirror skipping gpg", PuppetRepoOptions{AptURL: "https://mirror.example.com/apt", SkipGPGCheck: true}, false},
+		{"mirror without key", PuppetRepoOptions{AptURL: "https://mirror.example.com/apt"}, true},
+		{"key without mirror", PuppetRepoOptions{GPGKeyURL: "https://mirror.example.com/key.asc"}, true},
+		{"skip gpg without mirror", PuppetRepoOptions{SkipGPGCheck: true}, true},
+		{"skip gpg with key", PuppetRepoOptions{AptURL: "https://m/apt", GPGKeyURL: "https://m/key", SkipGPGCheck: true}, true},
+		{"invalid scheme", PuppetRepoOptions{AptURL: "ftp://mirror/apt", GPGKeyURL: "https://m/key"}, true},
+		{"invalid fingerprint", PuppetRepoOptions{AptURL: "https://m/apt", GPGKeyURL: "https://m/key", GPGFingerprint: "ABCD"}, true},
+		{"valid fingerprint", PuppetRepoOptions{AptURL: "https://m/apt", GPGKeyURL: "https://m/key", GPGFingerprint: fpr}, false},
+		{"distro source", PuppetRepoOptions{Source: RepoSourceDistro}, false},
+		{"puppetlabs source with mirror", PuppetRepoOptions{Source: RepoSourcePuppetlabs, AptURL: "https://m/apt", SkipGPGCheck: true}, false},
+		{"distro source with mirror", PuppetRepoOptions{Source: RepoSourceDistro, AptURL: "https://m/apt", SkipGPGCheck: true}, true},
+		{"invalid source", PuppetRepoOptions{Source: "epel"}, true},
+		{"release fingerprints", PuppetRepoOptions{ReleaseFingerprints: []string{"d681 1ed3 adee b844 1af5 aa8f 4528 b6cd 9e61 ef26"}}, false},
+		{"invalid release fingerprint", PuppetRepoOptions{ReleaseFingerprints: []string{"9E61EF26"}}, true},
+		{"invalid release key url", PuppetRepoOptions{ReleaseKeyURL: "file:///tmp/key"}, true},
+		{"distro source with release settings", PuppetRepoOptions{Source: RepoSourceDistro, ReleaseFingerprints: []string{fpr}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.opts.Validate()
+			if tt.expectError && err == nil {
+				t.Errorf("Validate() expected error for %+v", tt.opts)
+			}
+			if !tt.expectError && err != nil {
+				t.Errorf("Validate() unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+// TestPuppetRepoOptions_Endpoints tests the distinct host:port list used in preflight.
+func TestPuppetRepoOptions_Endpoints(t *testing.T) {
+	opts := PuppetRepoOptions{
+		AptURL:    "https://mirror.example.com/apt",
+		YumURL:    "https://mirror.example.com/yum",
+		GPGKeyURL: "http://keys.example.com:8080/puppet.asc",
+	}
+	got := strings.Join(opts.Endpoints(), ",")
+	want := "mirror.example.com:443,keys.example.com:8080"
+	if got != want {
+		t.Errorf("Endpoints() = %q, want %q", got, want)
+	}
+}
+
+// TestGenerateInstallScript_Mirror tests mirror repos in Debian and RHEL scripts.
+func TestGenerateInstallScript_Mirror(t *testing.T) {
+	repo := PuppetRepoOptions{
+		AptURL:         "https://mirror.example.com/apt",
+		YumURL:         "https://mirror.example.com/yum",
+		GPGKeyURL:      "https://mirror.example.com/key.asc",
+		GPGFingerprint: "D6811ED3ADEEB8441AF5AA8F4528B6CD9E61EF26",
+	}
+	installer := NewPuppetInstaller(PuppetOptions{Server: "puppet.example.com", Version: "8", Repo: repo})
+	script := func(pi *PuppetInstaller, osType string) string {
+		t.Helper()
+		scripts, err := pi.GenerateInstallScript(osType, nil)
+		if err != nil {
+			t.Fatalf("GenerateInstallScript(%q) unexpected error: %v", osType, err)
+		}
+		return scripts[0]
+	}
+
+	debian := script(installer, "debian")
+	for _, want := range []string{
+		"deb [signed-by=" + puppetMirrorKeyring + "] https://mirror.example.com/apt ${VERSION_CODENAME} puppet8",
+		`download "https://mirror.example.com/key.asc"`,
+		`grep -x "D6811ED3ADEEB8441AF5AA8F4528B6CD9E61EF26" >/dev/null`,
+	} {
+		if !strings.Contains(debian, want) {
+			t.Errorf("Debian script missing %q", want)
+		}
+	}
+	if strings.Contains(debian, "apt.puppet.com") {
+		t.Error("Debian script should not use the official repo with a mirror")
+	}
+
+	rhel := script(installer, "rhel")
+	for _, want := range []string{
+		"baseurl=https://mirror.example.com/yum/puppet8/${REPO_PATH}/\\$basearch",
+		"gpgcheck=1",
+		"rpm --import " + puppetMirrorRPMKey,
+	} {
+		if !strings.Contains(rhel, want) {
+			t.Errorf("RHEL script missing %q", want)
+		}
+	}
+	if strings.Contains(rhel, "yum.puppet.com") {
+		t.Error("RHEL script should not use the official repo with a mirror")
+	}
+
+	insecure := NewPuppetInstaller(PuppetOptions{Server: "puppet.example.com", Version: "8", Repo: PuppetRepoOptions{
+		AptURL: "https://mirror.example.com/apt", YumURL: "https://mirror.example.com/yum", SkipGPGCheck: true,
+	}})
+	if !strings.Contains(script(insecure, "debian"), "deb [trusted=yes]") {
+		t.Error("Debian script should trust the mirror with SkipGPGCheck")
+	}
+	if !strings.Contains(script(insecure, "rhel"), "gpgcheck=0") {
+		t.Error("RHEL script should disable gpgcheck with SkipGPGCheck")
+	}
+}
+
+// TestGenerateInstallScript_ReleaseVerification tests that the official
+// release packages are checked against the pinned keys before installing.
+func TestGenerateInstallScript_ReleaseVerification(t *testing.T) {
+	const pinned = "0123456789ABCDEF0123456789ABCDEF01234567"
+	tests := []struct {
+		name   string
+		repo   PuppetRepoOptions
+		osType string
+		want   []string
+	}{
+		{
+			name:   "debian default keys",
+			osType: "debian",
+			want: []string{
+				`dpkg-deb -x "${STAGE_DIR}/${REPO_DEB}" "${RELEASE_CHECK}"`,
+				`PINNED_FPRS=" D6811ED3ADEEB8441AF5AA8F4528B6CD9E61EF26 "`,
+				"exit 10",
+			},
+		},
+		{
+			name:   "rhel default key",
+			osType: "rhel",
+			want: []string{
+				`download "` + DefaultPuppetReleaseKeyURL + `"`,
+				`rpm --import "${RELEASE_KEY}"`,
+				"grep -Eiq 'Signature, key ID [0-9a-f]*(9e61ef26): OK'",
+				`yum install -y "${STAGE_DIR}/${REPO_RPM}"`,
+			},
+		},
+		{
+			name:   "rhel pinned keys",
+			repo:   PuppetRepoOptions{ReleaseFingerprints: []string{pinned}, ReleaseKeyURL: "https://keys.example.com/puppet.asc"},
+			osType: "rhel",
+			want: []string{
+				`download "https://keys.example.com/puppet.asc"`,
+				`PINNED_FPRS=" ` + pinned + ` "`,
+				"(01234567): OK",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pi := NewPuppetInstaller(PuppetOptions{Server: "puppet.example.com", Version: "8", Repo: tt.repo})
+			scripts, err := pi.GenerateInstallScript(tt.osType, nil)
+			if err != nil {
+				t.Fatalf("GenerateInstallScript() unexpected error: %v", err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(scripts[0], want) {
+					t.Errorf("script missing %q", want)
+				}
+			}
+			if strings.Contains(scripts[0], `yum install -y "https://yum.puppet.com/`) {
+				t.Error("script should not install the release package unverified from the URL")
+			}
+		})
+	}
+}
+
+// TestGenerateInstallScript_DistroSource tests install scripts using the OS
+// vendor package: no Puppet repository and the FHS paths.
+func TestGenerateInstallScript_DistroSource(t *testing.T) {
+	pi := NewPuppetInstaller(PuppetOptions{Server: "puppet.example.com", Repo: PuppetRepoOptions{Source: RepoSourceDistro}})
+
+	for _, osType := range []string{"debian", "rhel"} {
+		t.Run(osType, func(t *testing.T) {
+			scripts, err := pi.GenerateInstallScript(osType, nil)
+			if err != nil {
+				t.Fatalf("GenerateInstallScript(%q) unexpected error: %v", osType, err)
+			}
+			script := scripts[0]
+
+			for _, want := range []string{
+				"install -y puppet;",
+				"if [ ! -x /usr/bin/puppet ]; then",
+				"cat > /etc/puppet/puppet.conf",
+				"cat > /etc/facter/facter.conf",
+				"/usr/bin/puppet agent --test",
+			} {
+				if !strings.Contains(script, want) {
+					t.Errorf("script missing %q", want)
+				}
+			}
+			for _, unwanted := range []string{"puppet.com", "puppetlabs", "puppet-agent", "is not supported on"} {
+				if strings.Contains(script, unwanted) {
+					t.Errorf("script should not contain %q with the distro source", unwanted)
+				}
+			}
+		})
+	}
+
+	// Default source keeps the puppet-agent (AIO) layout
+	scripts, err := NewPuppetInstaller(PuppetOptions{Server: "puppet.example.com"}).GenerateInstallScript("debian", nil)
+	if err != nil {
+		t.Fatalf("GenerateInstallScript unexpected error: %v", err)
+	}
+	for _, want := range []string{"apt-get install -y puppet-agent;", "if [ ! -x /opt/puppetlabs/bin/puppet ]; then"} {
+		if !strings.Contains(scripts[0], want) {
+			t.Errorf("default script missing %q", want)
+		}
+	}
+}
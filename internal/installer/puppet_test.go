@@ -4,73 +4,14 @@ import (
 	"context"
 	"fmt"
 	"strings"
-	"sync"
 	"testing"
 	"time"
 
 	"github.com/estudosdevops/opsmaster/internal/cloud"
+	"github.com/estudosdevops/opsmaster/internal/cloud/cloudtest"
+	"github.com/estudosdevops/opsmaster/internal/stresstest"
 )
 
-// ============================================================
-// MOCKS - External dependency simulation
-// ============================================================
-
-// mockCloudProvider simulates a cloud provider (AWS/Azure/GCP) for testing.
-// This allows testing the installer logic without requiring real infrastructure.
-//
-// 🎓 CONCEPT: A Mock is a "fake object" that simulates the behavior of a real object.
-// We use mocks to:
-// - Test without external dependencies (AWS, Azure, etc)
-// - Control exactly what the dependency returns
-// - Test error scenarios (e.g., SSM command fails)
-type mockCloudProvider struct {
-	// executeCommandFunc is a function we can configure for each test.
-	// Allows simulating different responses (success, error, different OS, etc)
-	executeCommandFunc func(ctx context.Context, instance *cloud.Instance, commands []string, timeout time.Duration) (*cloud.CommandResult, error)
-}
-
-// Name implements the cloud.CloudProvider interface
-func (*mockCloudProvider) Name() string {
-	return "mock"
-}
-
-// ExecuteCommand implements the cloud.CloudProvider interface
-// Calls the configured function in the mock or returns error if not configured
-//
-// 🎓 CONCEPT: Interfaces in Go
-// For a type to implement an interface, it must have EXACTLY
-// the same methods with SAME signature (parameters and returns)
-func (m *mockCloudProvider) ExecuteCommand(ctx context.Context, instance *cloud.Instance, commands []string, timeout time.Duration) (*cloud.CommandResult, error) {
-	if m.executeCommandFunc != nil {
-		return m.executeCommandFunc(ctx, instance, commands, timeout)
-	}
-	return nil, fmt.Errorf("executeCommandFunc not configured in mock")
-}
-
-// ValidateInstance implements the cloud.CloudProvider interface
-// Simple mock that always returns success
-func (*mockCloudProvider) ValidateInstance(context.Context, *cloud.Instance) error {
-	return nil
-}
-
-// TestConnectivity implements the cloud.CloudProvider interface
-// Simple mock that always returns success
-func (*mockCloudProvider) TestConnectivity(context.Context, *cloud.Instance, string, int) error {
-	return nil
-}
-
-// TagInstance implements the cloud.CloudProvider interface
-// Simple mock that always returns success
-func (*mockCloudProvider) TagInstance(context.Context, *cloud.Instance, map[string]string) error {
-	return nil
-}
-
-// HasTag implements the cloud.CloudProvider interface
-// Simple mock that always returns false
-func (*mockCloudProvider) HasTag(context.Context, *cloud.Instance, string, string) (bool, error) {
-	return false, nil
-}
-
 // ============================================================
 // HELPER FUNCTIONS - Test utility functions
 // ============================================================
@@ -98,9 +39,9 @@ func createTestInstance() *cloud.Instance {
 // The mock needs to differentiate between DIFFERENT commands:
 // - OS detection command: returns OS name
 // - Certname reading command: returns error (file doesn't exist)
-func createMockProviderWithOSResponse(osName string) *mockCloudProvider {
-	return &mockCloudProvider{
-		executeCommandFunc: func(_ context.Context, _ *cloud.Instance, commands []string, _ time.Duration) (*cloud.CommandResult, error) {
+func createMockProviderWithOSResponse(osName string) *cloudtest.Provider {
+	return &cloudtest.Provider{
+		ExecuteCommandFunc: func(_ context.Context, _ *cloud.Instance, commands []string, _ time.Duration) (*cloud.CommandResult, error) {
 			// If it's an OS detection command (contains "os-release")
 			if len(commands) > 0 && strings.Contains(commands[0], "os-release") {
 				return &cloud.CommandResult{
@@ -126,9 +67,9 @@ func createMockProviderWithOSResponse(osName string) *mockCloudProvider {
 
 // createMockProviderWithCertnameResponse creates a mock that returns an existing certname.
 // Simulates scenario where Puppet is already installed.
-func createMockProviderWithCertnameResponse(certname string, hasError bool) *mockCloudProvider {
-	return &mockCloudProvider{
-		executeCommandFunc: func(_ context.Context, _ *cloud.Instance, commands []string, _ time.Duration) (*cloud.CommandResult, error) {
+func createMockProviderWithCertnameResponse(certname string, hasError bool) *cloudtest.Provider {
+	return &cloudtest.Provider{
+		ExecuteCommandFunc: func(_ context.Context, _ *cloud.Instance, commands []string, _ time.Duration) (*cloud.CommandResult, error) {
 			// If it's OS detection command, return "ubuntu"
 			if len(commands) > 0 && strings.Contains(commands[0], "os-release") {
 				return &cloud.CommandResult{
@@ -361,25 +302,25 @@ func TestGenerateInstallScriptWithAutoDetect_ReturnsMetadata(t *testing.T) {
 		name                      string
 		osResponse                string
 		expectedOS                string
-		expectedCertnamePreserved string
+		expectedCertnamePreserved bool
 	}{
 		{
 			name:                      "debian OS",
 			osResponse:                "ubuntu",
 			expectedOS:                "ubuntu",
-			expectedCertnamePreserved: "false",
+			expectedCertnamePreserved: false,
 		},
 		{
 			name:                      "rhel OS",
 			osResponse:                "amzn",
 			expectedOS:                "amzn",
-			expectedCertnamePreserved: "false",
+			expectedCertnamePreserved: false,
 		},
 		{
 			name:                      "centos OS",
 			osResponse:                "centos",
 			expectedOS:                "centos",
-			expectedCertnamePreserved: "false",
+			expectedCertnamePreserved: false,
 		},
 	}
 
@@ -416,25 +357,25 @@ func TestGenerateInstallScriptWithAutoDetect_ReturnsMetadata(t *testing.T) {
 			}
 
 			// Verify metadata - OS
-			if metadata["os"] != tt.expectedOS {
-				t.Errorf("metadata[os] = %q, want %q", metadata["os"], tt.expectedOS)
+			if metadata.OS != tt.expectedOS {
+				t.Errorf("metadata.OS = %q, want %q", metadata.OS, tt.expectedOS)
 			}
 
 			// Verify metadata - certname exists and is not empty
-			certname := metadata["certname"]
+			certname := metadata.Certname
 			if certname == "" {
-				t.Error("metadata[certname] is empty")
+				t.Error("metadata.Certname is empty")
 			}
 
 			// Verify metadata - certname has correct format
 			if !strings.HasSuffix(certname, ".puppet") {
-				t.Errorf("metadata[certname] = %q, want suffix '.puppet'", certname)
+				t.Errorf("metadata.Certname = %q, want suffix '.puppet'", certname)
 			}
 
 			// Verify metadata - certname_preserved
-			if metadata["certname_preserved"] != tt.expectedCertnamePreserved {
-				t.Errorf("metadata[certname_preserved] = %q, want %q",
-					metadata["certname_preserved"], tt.expectedCertnamePreserved)
+			if metadata.CertnamePreserved != tt.expectedCertnamePreserved {
+				t.Errorf("metadata.CertnamePreserved = %v, want %v",
+					metadata.CertnamePreserved, tt.expectedCertnamePreserved)
 			}
 		})
 	}
@@ -475,13 +416,13 @@ func TestGenerateInstallScriptWithAutoDetect_PreservesCertname(t *testing.T) {
 		}
 
 		// Verify that certname was preserved
-		if metadata["certname"] != existingCertname {
-			t.Errorf("metadata[certname] = %q, want %q", metadata["certname"], existingCertname)
+		if metadata.Certname != existingCertname {
+			t.Errorf("metadata.Certname = %q, want %q", metadata.Certname, existingCertname)
 		}
 
 		// Verify that preservation flag is true
-		if metadata["certname_preserved"] != "true" {
-			t.Errorf("metadata[certname_preserved] = %q, want %q", metadata["certname_preserved"], "true")
+		if !metadata.CertnamePreserved {
+			t.Error("metadata.CertnamePreserved = false, want true")
 		}
 	})
 
@@ -511,13 +452,13 @@ func TestGenerateInstallScriptWithAutoDetect_PreservesCertname(t *testing.T) {
 		}
 
 		// Verify that certname was generated (not empty)
-		if metadata["certname"] == "" {
-			t.Error("metadata[certname] is empty, expected generated certname")
+		if metadata.Certname == "" {
+			t.Error("metadata.Certname is empty, expected generated certname")
 		}
 
 		// Verify that preservation flag is false
-		if metadata["certname_preserved"] != "false" {
-			t.Errorf("metadata[certname_preserved] = %q, want %q", metadata["certname_preserved"], "false")
+		if metadata.CertnamePreserved {
+			t.Error("metadata.CertnamePreserved = true, want false")
 		}
 	})
 }
@@ -534,8 +475,8 @@ func TestGenerateInstallScriptWithAutoDetect_ErrorHandling(t *testing.T) {
 		instance := createTestInstance()
 
 		// Mock que retorna erro
-		mockProvider := &mockCloudProvider{
-			executeCommandFunc: func(_ context.Context, _ *cloud.Instance, _ []string, _ time.Duration) (*cloud.CommandResult, error) {
+		mockProvider := &cloudtest.Provider{
+			ExecuteCommandFunc: func(_ context.Context, _ *cloud.Instance, _ []string, _ time.Duration) (*cloud.CommandResult, error) {
 				return nil, fmt.Errorf("SSM command failed")
 			},
 		}
@@ -597,99 +538,18 @@ func TestGenerateInstallScriptWithAutoDetect_ErrorHandling(t *testing.T) {
 // CONCURRENCY TESTS (RACE CONDITION)
 // ============================================================
 
-// concurrentTestResult stores result from a single goroutine execution
-type concurrentTestResult struct {
-	commands []string
-	metadata map[string]string
-	err      error
-}
-
-// executeConcurrentInstalls runs GenerateInstallScriptWithAutoDetect in parallel
-// 🎓 CONCEPT: WaitGroup coordinates goroutines
-func executeConcurrentInstalls(ctx context.Context, t *testing.T, installer *PuppetInstaller, numGoroutines int) []concurrentTestResult {
-	t.Helper()
-	results := make([]concurrentTestResult, numGoroutines)
-	var wg sync.WaitGroup
-
-	for i := range numGoroutines {
-		wg.Add(1)
-		idx := i
-
-		go func(index int) {
-			defer wg.Done()
-
-			instance := &cloud.Instance{
-				ID:      fmt.Sprintf("i-instance%d", index),
-				Account: "123456789012",
-				Region:  "us-east-1",
-				Cloud:   "aws",
-				Metadata: map[string]string{
-					"environment": "production",
-				},
-			}
-
-			mockProvider := createMockProviderWithOSResponse("ubuntu")
-			commands, metadata, err := installer.GenerateInstallScriptWithAutoDetect(ctx, instance, mockProvider, nil)
-
-			results[index] = concurrentTestResult{
-				commands: commands,
-				metadata: metadata,
-				err:      err,
-			}
-		}(idx)
-	}
-
-	wg.Wait()
-	return results
-}
-
-// validateNoErrors checks that no goroutine encountered an error
-func validateNoErrors(t *testing.T, results []concurrentTestResult) {
-	t.Helper()
-	for i, r := range results {
-		if r.err != nil {
-			t.Errorf("goroutine %d: unexpected error: %v", i, r.err)
-		}
-	}
-}
-
-// validateUniqueCertnames ensures each result has a unique certname
-// 🎓 THIS IS THE MAIN VALIDATION - detects race conditions!
-func validateUniqueCertnames(t *testing.T, results []concurrentTestResult, expectedCount int) {
-	t.Helper()
-	certnames := make(map[string]int)
-
-	for i, r := range results {
-		certname := r.metadata["certname"]
-
-		if certname == "" {
-			t.Errorf("goroutine %d: metadata[certname] is empty", i)
-			continue
-		}
-
-		if previousIndex, exists := certnames[certname]; exists {
-			t.Errorf("RACE CONDITION DETECTED: goroutine %d and %d have same certname: %q",
-				i, previousIndex, certname)
-		}
-
-		certnames[certname] = i
-	}
-
-	if len(certnames) != expectedCount {
-		t.Errorf("generated %d unique certnames, want %d (possible race condition)",
-			len(certnames), expectedCount)
+// stressCall runs GenerateInstallScriptWithAutoDetect for one instance,
+// returning its metadata for the stresstest invariants.
+func stressCall(installer *PuppetInstaller, instances []*cloud.Instance, provider cloud.CloudProvider) func(ctx context.Context, worker, _ int) (*InstallMetadata, error) {
+	return func(ctx context.Context, worker, _ int) (*InstallMetadata, error) {
+		_, metadata, err := installer.GenerateInstallScriptWithAutoDetect(ctx, instances[worker], provider, nil)
+		return metadata, err
 	}
 }
 
-// validateCertnameFormat checks that all certnames have correct .puppet suffix
-func validateCertnameFormat(t *testing.T, results []concurrentTestResult) {
-	t.Helper()
-	for _, r := range results {
-		certname := r.metadata["certname"]
-		if certname != "" && !strings.HasSuffix(certname, ".puppet") {
-			t.Errorf("certname %q does not have .puppet suffix", certname)
-		}
-	}
+// certnameOf is the key of the unique-metadata invariant.
+func certnameOf(call stresstest.Call[*InstallMetadata]) string {
+	return call.Value.Certname
 }
 
 // TestGenerateInstallScriptWithAutoDetect_Concurrent_NoRaceCondition validates that
@@ -712,120 +572,66 @@ func validateCertnameFormat(t *testing.T, results []concurrentTestResult) {
 // - No sharing = no race condition
 //
 // THIS TEST VALIDATES THAT THE SOLUTION WORKS!
+// The harness (package stresstest) releases all goroutines together and the
+// provider latency keeps the calls overlapping.
 func TestGenerateInstallScriptWithAutoDetect_Concurrent_NoRaceCondition(t *testing.T) {
 	t.Run("concurrent calls return unique metadata", func(t *testing.T) {
 		// ARRANGE
-		ctx := context.Background()
 		installer := NewPuppetInstaller(PuppetOptions{
 			Server: "puppet.example.com",
 		})
-
+		provider := createMockProviderWithOSResponse("ubuntu")
+		provider.WithLatency(0, 2*time.Millisecond)
 		numGoroutines := 50
-		results := executeConcurrentInstalls(ctx, t, installer, numGoroutines)
+		instances := stresstest.Instances("instance", numGoroutines)
+
+		// ACT
+		calls := stresstest.Run(t, stresstest.Config{Goroutines: numGoroutines}, stressCall(installer, instances, provider))
 
 		// ASSERT
-		validateNoErrors(t, results)
-		validateUniqueCertnames(t, results, numGoroutines)
-		validateCertnameFormat(t, results)
+		stresstest.NoErrors(t, calls)
+		stresstest.Unique(t, calls, certnameOf)
+		stresstest.Each(t, calls, func(call stresstest.Call[*InstallMetadata]) error {
+			if !strings.HasSuffix(call.Value.Certname, ".puppet") {
+				return fmt.Errorf("certname %q does not have .puppet suffix", call.Value.Certname)
+			}
+			return nil
+		})
 	})
 
 	t.Run("concurrent calls with different OS return correct metadata", func(t *testing.T) {
 		// ARRANGE
-		ctx := context.Background()
 		installer := NewPuppetInstaller(PuppetOptions{
 			Server: "puppet.example.com",
 		})
 
-		// Test with different operating systems in parallel
+		// Test with different operating systems in parallel: each worker
+		// gets its own provider answering one OS
 		osTypes := []string{"ubuntu", "debian", "centos", "rhel", "amzn", "rocky"}
 		numIterations := 10 // Each OS will be tested 10 times
-		totalGoroutines := len(osTypes) * numIterations
-
-		type result struct {
-			osRequested string
-			osReturned  string
-			certname    string
-			err         error
+		instances := stresstest.Instances("os", len(osTypes))
+		providers := make([]*cloudtest.Provider, len(osTypes))
+		for i, osType := range osTypes {
+			providers[i] = createMockProviderWithOSResponse(osType).WithLatency(0, time.Millisecond)
 		}
-		results := make([]result, 0, totalGoroutines)
-		var resultsMutex sync.Mutex // Protects results slice (append is not thread-safe)
-
-		var wg sync.WaitGroup
 
 		// ACT: Execute with different OS in parallel
-		for _, osType := range osTypes {
-			for i := range numIterations {
-				wg.Add(1)
-
-				// Capture variables in local scope
-				os := osType
-				iteration := i
-
-				go func() {
-					defer wg.Done()
-
-					instance := &cloud.Instance{
-						ID:      fmt.Sprintf("i-%s-%d", os, iteration),
-						Account: "123456789012",
-						Region:  "us-east-1",
-						Cloud:   "aws",
-					}
-
-					mockProvider := createMockProviderWithOSResponse(os)
-
-					_, metadata, err := installer.GenerateInstallScriptWithAutoDetect(
-						ctx,
-						instance,
-						mockProvider,
-						nil,
-					)
-
-					// Store result in thread-safe manner
-					resultsMutex.Lock()
-					results = append(results, result{
-						osRequested: os,
-						osReturned:  metadata["os"],
-						certname:    metadata["certname"],
-						err:         err,
-					})
-					resultsMutex.Unlock()
-				}()
-			}
-		}
-
-		wg.Wait()
+		calls := stresstest.Run(t, stresstest.Config{Goroutines: len(osTypes), Iterations: numIterations},
+			func(ctx context.Context, worker, _ int) (*InstallMetadata, error) {
+				_, metadata, err := installer.GenerateInstallScriptWithAutoDetect(ctx, instances[worker], providers[worker], nil)
+				return metadata, err
+			})
 
 		// ASSERT
-
-		// 1. Verify that all succeeded
-		for i, r := range results {
-			if r.err != nil {
-				t.Errorf("result %d (OS=%s): unexpected error: %v", i, r.osRequested, r.err)
-			}
-		}
-
-		// 2. Verify that returned OS matches requested
-		for i, r := range results {
-			if r.osReturned != r.osRequested {
-				t.Errorf("result %d: metadata[os] = %q, want %q",
-					i, r.osReturned, r.osRequested)
-			}
-		}
-
-		// 3. Verify uniqueness of certnames (CRITICAL)
-		certnames := make(map[string]bool)
-		for i, r := range results {
-			if certnames[r.certname] {
-				t.Errorf("result %d: duplicate certname detected: %q", i, r.certname)
+		stresstest.NoErrors(t, calls)
+		stresstest.Each(t, calls, func(call stresstest.Call[*InstallMetadata]) error {
+			if want := osTypes[call.Worker]; call.Value.OS != want {
+				return fmt.Errorf("metadata.OS = %q, want %q", call.Value.OS, want)
 			}
-			certnames[r.certname] = true
-		}
-
-		// 4. Verify that we have exactly totalGoroutines unique certnames
-		if len(certnames) != totalGoroutines {
-			t.Errorf("generated %d unique certnames, want %d",
-				len(certnames), totalGoroutines)
-		}
+			return nil
+		})
+		// CRITICAL: a new certname per call, even for the same instance
+		stresstest.Unique(t, calls, certnameOf)
 	})
 }
 
@@ -843,56 +649,31 @@ func TestGenerateInstallScriptWithAutoDetect_Concurrent_NoRaceCondition(t *testi
 // THIS TEST forces race conditions to validate that they DON'T exist.
 func TestGenerateInstallScriptWithAutoDetect_RaceDetector(t *testing.T) {
 	// ARRANGE
-	ctx := context.Background()
 	installer := NewPuppetInstaller(PuppetOptions{
 		Server: "puppet.example.com",
 	})
 
-	// Use high number of goroutines to increase race probability
+	// Use high number of goroutines to increase race probability,
+	// executing multiple times in the same goroutine
 	numGoroutines := 100
-	var wg sync.WaitGroup
-
-	// ACT: Stress test with many goroutines
-	for i := range numGoroutines {
-		wg.Add(1)
+	instances := stresstest.Instances("stress", numGoroutines)
+	provider := createMockProviderWithOSResponse("ubuntu")
 
-		go func(idx int) {
-			defer wg.Done()
-
-			instance := &cloud.Instance{
-				ID:      fmt.Sprintf("i-stress%d", idx),
-				Account: "123456789012",
-				Region:  "us-east-1",
-				Cloud:   "aws",
-			}
-
-			mockProvider := createMockProviderWithOSResponse("ubuntu")
-
-			// Execute multiple times in the same goroutine
-			for j := range 5 {
-				_, metadata, err := installer.GenerateInstallScriptWithAutoDetect(
-					ctx,
-					instance,
-					mockProvider,
-					nil,
-				)
-
-				// Basic verifications
-				if err != nil {
-					t.Errorf("goroutine %d, iteration %d: unexpected error: %v", idx, j, err)
-				}
-				if metadata["certname"] == "" {
-					t.Errorf("goroutine %d, iteration %d: empty certname", idx, j)
-				}
-			}
-		}(i)
-	}
-
-	wg.Wait()
+	// ACT: Stress test with many goroutines; every call must get its certname
+	call := stressCall(installer, instances, provider)
+	calls := stresstest.Run(t, stresstest.Config{Goroutines: numGoroutines, Iterations: 5}, func(ctx context.Context, worker, iteration int) (*InstallMetadata, error) {
+		metadata, err := call(ctx, worker, iteration)
+		if err == nil && (metadata == nil || metadata.Certname == "") {
+			return metadata, fmt.Errorf("metadata.Certname is empty for %s", instances[worker].ID)
+		}
+		return metadata, err
+	})
 
 	// ASSERT
 	// If there's a race condition, `go test -race` will detect and report!
-	// No need for explicit assertions here - the race detector does the work.
+	// The basic verifications below don't replace the race detector.
+	stresstest.NoErrors(t, calls)
+	stresstest.Unique(t, calls, certnameOf)
 }
 
 // ============================================================
@@ -1128,3 +909,96 @@ func TestGenerateElasticPreventionScript_Integration(t *testing.T) {
 		}
 	})
 }
+
+// TestGenerateServiceScript tests systemctl commands generated for service options.
+func TestGenerateServiceScript(t *testing.T) {
+	tests := []struct {
+		name           string
+		opts           PuppetOptions
+		expected       []string
+		notExpected    []string
+		verifyContains []string
+	}{
+		{
+			name:           "default enables and starts service",
+			opts:           PuppetOptions{Server: "puppet.example.com"},
+			expected:       []string{"systemctl enable puppet", "systemctl start puppet", "systemctl unmask puppet"},
+			notExpected:    []string{"systemctl disable puppet", "systemctl stop puppet"},
+			verifyContains: []string{"systemctl is-active --quiet puppet || exit 3", "systemctl is-enabled --quiet puppet || exit 4"},
+		},
+		{
+			name:           "disabled and stopped for cron-triggered runs",
+			opts:           PuppetOptions{Server: "puppet.example.com", DisableService: true, ServiceState: ServiceStateStopped},
+			expected:       []string{"systemctl disable puppet", "systemctl stop puppet"},
+			notExpected:    []string{"systemctl enable puppet", "systemctl start puppet", "unmask"},
+			verifyContains: []string{"! systemctl is-active --quiet puppet || exit 3", "! systemctl is-enabled --quiet puppet || exit 4"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			installer := NewPuppetInstaller(tt.opts)
+			script := installer.generateServiceScript()
+
+			for _, want := range tt.expected {
+				if !strings.Contains(script, want) {
+					t.Errorf("Script missing %q", want)
+				}
+			}
+			for _, unwanted := range tt.notExpected {
+				if strings.Contains(script, unwanted) {
+					t.Errorf("Script should not contain %q", unwanted)
+				}
+			}
+
+			verify := strings.Join(installer.serviceVerifyCommands(), "\n")
+			for _, want := range tt.verifyContains {
+				if !strings.Contains(verify, want) {
+					t.Errorf("Verify commands missing %q, got:\n%s", want, verify)
+				}
+			}
+		})
+	}
+}
+
+// TestValidateServiceState tests validation of desired service states.
+func TestValidateServiceState(t *testing.T) {
+	for _, state := range []string{ServiceStateRunning, ServiceStateStopped} {
+		if err := ValidateServiceState(state); err != nil {
+			t.Errorf("ValidateServiceState(%q) unexpected error: %v", state, err)
+		}
+	}
+	if err := ValidateServiceState("paused"); err == nil {
+		t.Error("ValidateServiceState(\"paused\") expected error")
+	}
+}
+
+// TestServerFor tests per-instance Puppet Server selection from the CSV.
+func TestServerFor(t *testing.T) {
+	installer := NewPuppetInstaller(PuppetOptions{Server: "puppet.example.com"})
+
+	tests := []struct {
+		name     string
+		instance *cloud.Instance
+		want     string
+	}{
+		{name: "nil instance", instance: nil, want: "puppet.example.com"},
+		{name: "no column", instance: &cloud.Instance{ID: "i-1"}, want: "puppet.example.com"},
+		{
+			name:     "column override",
+			instance: &cloud.Instance{ID: "i-2", Metadata: map[string]string{PuppetServerColumn: "puppet-eu.example.com"}},
+			want:     "puppet-eu.example.com",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := installer.ServerFor(tt.instance); got != tt.want {
+				t.Errorf("ServerFor() = %q, want %q", got, tt.want)
+			}
+			if got := installer.ConcurrencyGroup(tt.instance); got != tt.want {
+				t.Errorf("ConcurrencyGroup() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
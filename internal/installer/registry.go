@@ -0,0 +1,51 @@
+package installer
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Descriptor is the registry entry of an installer: what it supports, for
+// discovery ("opsmaster installers list") without reading the source.
+// Each installer registers itself in an init function of its file.
+type Descriptor struct {
+	Name          string                  // Installer name (PackageInstaller.Name, also the "install" subcommand)
+	OSFamilies    []string                // Script families supported (OSTypeDebian, OSTypeRHEL, OSTypeWindows)
+	Architectures []string                // CPU architectures supported (nil = any)
+	New           func() PackageInstaller // Unconfigured installer, to discover its capabilities
+}
+
+// Capabilities returns the names of the optional capabilities the installer
+// implements (see CapabilitiesOf).
+func (d Descriptor) Capabilities() []string {
+	return CapabilitiesOf(d.New()).Names()
+}
+
+var (
+	registryMu sync.Mutex
+	registry   = make(map[string]Descriptor)
+)
+
+// Register adds an installer to the registry. Registering a name twice
+// panics, like registering two flags with the same name.
+func Register(d Descriptor) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if _, exists := registry[d.Name]; exists {
+		panic(fmt.Sprintf("installer %q registered twice", d.Name))
+	}
+	registry[d.Name] = d
+}
+
+// Registered returns every registered installer, sorted by name.
+func Registered() []Descriptor {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	descriptors := make([]Descriptor, 0, len(registry))
+	for _, d := range registry {
+		descriptors = append(descriptors, d)
+	}
+	sort.Slice(descriptors, func(i, j int) bool { return descriptors[i].Name < descriptors[j].Name })
+	return descriptors
+}
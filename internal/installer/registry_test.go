@@ -0,0 +1,77 @@
+package installer
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/estudosdevops/opsmaster/internal/stresstest"
+)
+
+func TestRegistered(t *testing.T) {
+	descriptors := Registered()
+
+	names := make([]string, len(descriptors))
+	for i, d := range descriptors {
+		names[i] = d.Name
+	}
+	want := []string{"fluent-bit", "osquery", "puppet", "systemd-unit", "teleport"}
+	if !slices.Equal(names, want) {
+		t.Errorf("Registered() names = %v, want %v", names, want)
+	}
+
+	for _, d := range descriptors {
+		if got := d.New().Name(); got != d.Name {
+			t.Errorf("descriptor %q builds installer named %q", d.Name, got)
+		}
+		if len(d.OSFamilies) == 0 {
+			t.Errorf("descriptor %q has no OS families", d.Name)
+		}
+		if d.Name == "puppet" && !slices.Contains(d.Capabilities(), "auto-detect") {
+			t.Errorf("puppet capabilities = %v, want auto-detect", d.Capabilities())
+		}
+	}
+}
+
+func TestRegister_Duplicate(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Register() of a duplicate name expected panic")
+		}
+	}()
+	Register(Descriptor{Name: "puppet"})
+}
+
+// TestRegistered_ConcurrentScripts is the concurrency regression suite every
+// registered installer gets for free: one installer shared by many
+// goroutines (as the executor does) must generate each OS family's script as
+// it does sequentially. Run with -race to catch unsynchronized state.
+func TestRegistered_ConcurrentScripts(t *testing.T) {
+	for _, d := range Registered() {
+		for _, osFamily := range d.OSFamilies {
+			t.Run(d.Name+"/"+osFamily, func(t *testing.T) {
+				installer := d.New()
+				_, err := installer.GenerateInstallScript(osFamily, nil)
+				baseline := fmt.Sprint(err)
+
+				// Each call returns its error text ("<nil>" on success)
+				calls := stresstest.Run(t, stresstest.Config{Goroutines: 20, Iterations: 3}, func(_ context.Context, _, _ int) (string, error) {
+					commands, err := installer.GenerateInstallScript(osFamily, nil)
+					if err == nil && len(commands) == 0 {
+						return "", fmt.Errorf("no commands generated")
+					}
+					return fmt.Sprint(err), nil
+				})
+
+				stresstest.NoErrors(t, calls)
+				stresstest.Each(t, calls, func(call stresstest.Call[string]) error {
+					if call.Value != baseline {
+						return fmt.Errorf("GenerateInstallScript() error = %s, sequential call returned %s", call.Value, baseline)
+					}
+					return nil
+				})
+			})
+		}
+	}
+}
@@ -0,0 +1,189 @@
+package installer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/estudosdevops/opsmaster/internal/cloud"
+	"github.com/estudosdevops/opsmaster/internal/logger"
+)
+
+// Shell kinds detected on instances.
+// Generated scripts are POSIX sh compatible, so any of them can run them;
+// the detected shell is recorded in install metadata for troubleshooting.
+const (
+	ShellBash    = "bash"
+	ShellSh      = "sh"      // POSIX sh (dash, ash, ...)
+	ShellBusyBox = "busybox" // BusyBox ash (minimal images)
+)
+
+// shellDetectScript reports the best available shell as "shell=<kind>".
+// Appended to the OS detection script so both facts come from one remote call.
+const shellDetectScript = `
+# Detect available shell (scripts are POSIX sh, bash preferred when present)
+if command -v bash >/dev/null 2>&1; then
+    echo "shell=bash"
+elif readlink -f /bin/sh 2>/dev/null | grep -q busybox; then
+    echo "shell=busybox"
+else
+    echo "shell=sh"
+fi
+`
+
+// downloadShellFunc defines download URL -> stdout for generated scripts,
+// using curl or wget (minimal images often ship only one of them).
+const downloadShellFunc = `
+download() {
+    if command -v curl >/dev/null 2>&1; then
+        curl -fsSL "$1"
+    else
+        wget -qO- "$1"
+    fi
+}
+`
+
+// parseDetectOutput splits OS detection output into OS type and shell kind.
+// Output without a shell line (older scripts, mocks) defaults to POSIX sh.
+func parseDetectOutput(stdout string) (osType, shell string) {
+	shell = ShellSh
+	for _, line := range strings.Split(strings.TrimSpace(stdout), "\n") {
+		line = strings.TrimSpace(line)
+		if kind, ok := strings.CutPrefix(line, "shell="); ok {
+			shell = kind
+		} else if osType == "" && line != "" {
+			osType = line
+		}
+	}
+	return osType, shell
+}
+
+// noShellError returns a clear error when the command failed because the
+// instance has no usable shell (exit 126/127 from the agent launching sh).
+// Returns nil when the failure has another cause.
+func noShellError(result *cloud.CommandResult) error {
+	if result.ExitCode != 126 && result.ExitCode != 127 {
+		return nil
+	}
+
+	stderr := strings.ToLower(result.Stderr)
+	if !strings.Contains(stderr, "sh") ||
+		(!strings.Contains(stderr, "not found") && !strings.Contains(stderr, "no such file") &&
+			!strings.Contains(stderr, "permission denied")) {
+		return nil
+	}
+
+	return fmt.Errorf("no usable shell on instance: /bin/sh is missing or not executable "+
+		"(bash or POSIX sh required): %s", strings.TrimSpace(result.Stderr))
+}
+
+// forceDetectKey marks contexts in which the CSV "os" column is ignored.
+type forceDetectKey struct{}
+
+// WithForceDetect returns a context in which installers detect the OS on the
+// instance even when the CSV "os" column is set (e.g., stale inventory).
+func WithForceDetect(ctx context.Context) context.Context {
+	return context.WithValue(ctx, forceDetectKey{}, true)
+}
+
+// forceDetect reports whether ctx was created by WithForceDetect.
+func forceDetect(ctx context.Context) bool {
+	forced, _ := ctx.Value(forceDetectKey{}).(bool)
+	return forced
+}
+
+// ValidateInventoryOS checks the CSV "os" column of the instances: empty
+// values are detected remotely, others must name a supported OS family
+// (see ParseOSFamily). Invalid instances are listed in the error.
+func ValidateInventoryOS(instances []*cloud.Instance) error {
+	var invalid []string
+	for _, instance := range instances {
+		value := instance.Metadata["os"]
+		if value == "" {
+			continue
+		}
+		if _, err := ParseOSFamily(value); err != nil {
+			invalid = append(invalid, fmt.Sprintf("%s (%q)", instance.ID, value))
+		}
+	}
+	if len(invalid) == 0 {
+		return nil
+	}
+	const maxShown = 5
+	shown := invalid[:min(len(invalid), maxShown)]
+	more := ""
+	if len(invalid) > maxShown {
+		more = fmt.Sprintf(" and %d more", len(invalid)-maxShown)
+	}
+	return fmt.Errorf("unsupported os column value for %d instance(s): %s%s (valid: %s, %s, %s or a distribution such as ubuntu, amzn, rocky)",
+		len(invalid), strings.Join(shown, ", "), more, OSTypeDebian, OSTypeRHEL, OSTypeWindows)
+}
+
+// detectOS detects the operating system and shell of the instance via remote command execution.
+// Uses /etc/os-release which is the standard systemd way to identify Linux distributions.
+// Shared by installers that auto-detect the OS (Puppet, Fluent Bit, Teleport).
+//
+// When the CSV "os" column names a Linux family, it is trusted and no remote
+// call is made (the shell is then unknown, ""), unless ctx was created by
+// WithForceDetect.
+//
+// Returns normalized OS type:
+//   - "debian" for Debian/Ubuntu
+//   - "rhel" for RHEL/CentOS/Amazon Linux/Rocky/AlmaLinux
+//
+// and the shell kind (bash, sh, busybox). Generated scripts are POSIX sh, so the
+// shell is informational; a missing shell fails with a clear error.
+func detectOS(ctx context.Context, instance *cloud.Instance, provider cloud.CloudProvider) (osType, shell string, err error) {
+	if !forceDetect(ctx) {
+		if inventoryOS, err := normalizeOS(instance.Metadata["os"]); err == nil {
+			logger.FromContext(ctx).Debug("Using OS from CSV, skipping remote detection", "os", inventoryOS)
+			return inventoryOS, "", nil
+		}
+	}
+
+	// Script to detect OS from /etc/os-release
+	detectScript := `#!/bin/sh
+if [ -f /etc/os-release ]; then
+    . /etc/os-release
+    # Normalize ID to match our supported types
+    case "$ID" in
+        ubuntu|debian)
+            echo "debian"
+            ;;
+        rhel|centos|fedora|rocky|alma|almalinux)
+            echo "rhel"
+            ;;
+        amzn|amazonlinux|amazon)
+            echo "rhel"
+            ;;
+        *)
+            echo "unknown:$ID"
+            ;;
+    esac
+else
+    echo "unknown:no-os-release"
+fi
+` + shellDetectScript
+
+	commands := []string{detectScript}
+	result, err := provider.ExecuteCommand(ctx, instance, commands, DefaultSSMTimeout)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to detect OS: %w", err)
+	}
+
+	if result.ExitCode != 0 {
+		if shellErr := noShellError(result); shellErr != nil {
+			return "", "", shellErr
+		}
+		return "", "", fmt.Errorf("OS detection failed with exit code %d: %s", result.ExitCode, result.Stderr)
+	}
+
+	osType, shell = parseDetectOutput(result.Stdout)
+
+	// Handle unknown OS
+	if osType == "" || strings.HasPrefix(osType, "unknown:") {
+		return "", "", fmt.Errorf("unsupported or undetected OS: %s", osType)
+	}
+
+	return osType, shell, nil
+}
@@ -0,0 +1,150 @@
+package installer
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/estudosdevops/opsmaster/internal/cloud"
+	"github.com/estudosdevops/opsmaster/internal/cloud/cloudtest"
+)
+
+// TestParseDetectOutput tests parsing of OS and shell detection output
+func TestParseDetectOutput(t *testing.T) {
+	tests := []struct {
+		name      string
+		stdout    string
+		wantOS    string
+		wantShell string
+	}{
+		{name: "bash instance", stdout: "debian\nshell=bash\n", wantOS: "debian", wantShell: ShellBash},
+		{name: "busybox instance", stdout: "rhel\nshell=busybox", wantOS: "rhel", wantShell: ShellBusyBox},
+		{name: "no shell line defaults to sh", stdout: "debian\n", wantOS: "debian", wantShell: ShellSh},
+		{name: "unknown OS", stdout: "unknown:alpine\nshell=busybox", wantOS: "unknown:alpine", wantShell: ShellBusyBox},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			osType, shell := parseDetectOutput(tt.stdout)
+			if osType != tt.wantOS || shell != tt.wantShell {
+				t.Errorf("parseDetectOutput() = (%q, %q), want (%q, %q)", osType, shell, tt.wantOS, tt.wantShell)
+			}
+		})
+	}
+}
+
+// TestDetectOS_InventoryColumn tests that a supported CSV os column skips
+// remote detection unless forced.
+func TestDetectOS_InventoryColumn(t *testing.T) {
+	tests := []struct {
+		name        string
+		csvOS       string
+		force       bool
+		wantOS      string
+		remoteCalls int
+	}{
+		{name: "column trusted", csvOS: "amzn", wantOS: OSTypeRHEL, remoteCalls: 0},
+		{name: "force detect", csvOS: "amzn", force: true, wantOS: OSTypeDebian, remoteCalls: 1},
+		{name: "no column", csvOS: "", wantOS: OSTypeDebian, remoteCalls: 1},
+		{name: "windows column detected remotely", csvOS: "windows", wantOS: OSTypeDebian, remoteCalls: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := cloudtest.New().OnCommand("/etc/os-release", &cloud.CommandResult{Stdout: "debian\nshell=bash\n"})
+			instance := &cloud.Instance{ID: "i-123", Metadata: map[string]string{"os": tt.csvOS}}
+			ctx := context.Background()
+			if tt.force {
+				ctx = WithForceDetect(ctx)
+			}
+
+			osType, _, err := detectOS(ctx, instance, provider)
+			if err != nil {
+				t.Fatalf("detectOS() unexpected error: %v", err)
+			}
+			if osType != tt.wantOS {
+				t.Errorf("detectOS() = %q, want %q", osType, tt.wantOS)
+			}
+			if got := provider.CallCount(cloudtest.MethodExecuteCommand); got != tt.remoteCalls {
+				t.Errorf("remote calls = %d, want %d", got, tt.remoteCalls)
+			}
+		})
+	}
+}
+
+// TestValidateInventoryOS tests validation of the CSV os column.
+func TestValidateInventoryOS(t *testing.T) {
+	valid := []*cloud.Instance{
+		{ID: "i-1", Metadata: map[string]string{"os": "ubuntu"}},
+		{ID: "i-2", Metadata: map[string]string{"os": "Rocky"}},
+		{ID: "i-3", Metadata: map[string]string{"os": "windows"}},
+		{ID: "i-4", Metadata: map[string]string{}},
+	}
+	if err := ValidateInventoryOS(valid); err != nil {
+		t.Errorf("ValidateInventoryOS() unexpected error: %v", err)
+	}
+
+	invalid := append(valid, &cloud.Instance{ID: "i-5", Metadata: map[string]string{"os": "solaris"}})
+	err := ValidateInventoryOS(invalid)
+	if err == nil || !strings.Contains(err.Error(), `i-5 ("solaris")`) {
+		t.Errorf("ValidateInventoryOS() = %v, want error listing i-5", err)
+	}
+}
+
+// TestNoShellError tests the clear error for instances without a usable shell
+func TestNoShellError(t *testing.T) {
+	tests := []struct {
+		name    string
+		result  *cloud.CommandResult
+		wantErr bool
+	}{
+		{name: "sh missing", result: &cloud.CommandResult{ExitCode: 127, Stderr: "sh: not found"}, wantErr: true},
+		{name: "sh not executable", result: &cloud.CommandResult{ExitCode: 126, Stderr: "/bin/sh: Permission denied"}, wantErr: true},
+		{name: "other command missing", result: &cloud.CommandResult{ExitCode: 127, Stderr: "wget: command not found"}, wantErr: false},
+		{name: "regular failure", result: &cloud.CommandResult{ExitCode: 1, Stderr: "sh: syntax error"}, wantErr: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := noShellError(tt.result)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("noShellError() = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestGeneratedScripts_POSIX tests that install scripts run under POSIX sh.
+//
+// 🎓 CONCEPT: Syntax check with sh -n
+// "sh -n" parses without executing; on dash-based systems this rejects bash-isms.
+func TestGeneratedScripts_POSIX(t *testing.T) {
+	installer := NewPuppetInstaller(PuppetOptions{Server: "puppet.example.com"})
+
+	for _, osType := range []string{"ubuntu", "rhel"} {
+		t.Run(osType, func(t *testing.T) {
+			scripts, err := installer.GenerateInstallScript(osType, nil)
+			if err != nil {
+				t.Fatalf("GenerateInstallScript() error: %v", err)
+			}
+			script := strings.Join(scripts, "\n")
+
+			if !strings.HasPrefix(script, "#!/bin/sh") {
+				t.Errorf("expected #!/bin/sh shebang, got %q", strings.SplitN(script, "\n", 2)[0])
+			}
+			if strings.Contains(script, "[[ ") {
+				t.Error("script uses bash-only [[ ]] test")
+			}
+
+			if _, err := exec.LookPath("sh"); err != nil {
+				t.Skip("sh not available for syntax check")
+			}
+			cmd := exec.Command("sh", "-n")
+			cmd.Stdin = strings.NewReader(script)
+			if out, err := cmd.CombinedOutput(); err != nil {
+				t.Errorf("sh -n failed: %v\n%s", err, out)
+			}
+		})
+	}
+}
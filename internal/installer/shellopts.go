@@ -0,0 +1,101 @@
+package installer
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Shell safety option names, as accepted by ParseShellOptions.
+const (
+	ShellOptErrExit  = "errexit"
+	ShellOptNoUnset  = "nounset"
+	ShellOptPipefail = "pipefail"
+	ShellOptErrTrap  = "errtrap"
+)
+
+// ShellOptions are safety options enabled at the top of generated install
+// scripts. The zero value enables none: failing commands are only caught
+// where the script checks them explicitly (historical behavior).
+//
+// Sections that must tolerate non-zero exit codes (e.g., the puppet agent
+// run, where 2 and 6 mean success) are written to work with every option.
+type ShellOptions struct {
+	ErrExit  bool // set -e: exit at the first unchecked failing command
+	NoUnset  bool // set -u: fail on unset variables
+	Pipefail bool // set -o pipefail, when the shell supports it (bash, busybox ash, recent dash)
+	ErrTrap  bool // Print the failing line and command (shells with ERR traps: bash, /bin/sh on RHEL-family)
+}
+
+// StrictShellOptions enables every safety option.
+var StrictShellOptions = ShellOptions{ErrExit: true, NoUnset: true, Pipefail: true, ErrTrap: true}
+
+// ParseShellOptions parses a comma-separated list of option names
+// (errexit, nounset, pipefail, errtrap), "strict" (all) or "none".
+func ParseShellOptions(value string) (ShellOptions, error) {
+	var opts ShellOptions
+	for _, name := range strings.Split(value, ",") {
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "", "none":
+		case "strict":
+			opts = StrictShellOptions
+		case ShellOptErrExit:
+			opts.ErrExit = true
+		case ShellOptNoUnset:
+			opts.NoUnset = true
+		case ShellOptPipefail:
+			opts.Pipefail = true
+		case ShellOptErrTrap:
+			opts.ErrTrap = true
+		default:
+			return ShellOptions{}, fmt.Errorf("unknown shell option %q (valid: %s, %s, %s, %s, strict or none)",
+				name, ShellOptErrExit, ShellOptNoUnset, ShellOptPipefail, ShellOptErrTrap)
+		}
+	}
+	return opts, nil
+}
+
+// Names returns the enabled option names, sorted.
+func (o ShellOptions) Names() []string {
+	var names []string
+	for name, enabled := range map[string]bool{
+		ShellOptErrExit:  o.ErrExit,
+		ShellOptNoUnset:  o.NoUnset,
+		ShellOptPipefail: o.Pipefail,
+		ShellOptErrTrap:  o.ErrTrap,
+	} {
+		if enabled {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// preamble generates the shell commands enabling the options, placed right
+// after the shebang of generated scripts.
+func (o ShellOptions) preamble() string {
+	if o == (ShellOptions{}) {
+		return "# No shell safety options: failures are handled by explicit checks\n"
+	}
+
+	script := "# Shell safety options\n"
+	if o.ErrExit {
+		script += "set -e\n"
+	}
+	if o.NoUnset {
+		script += "set -u\n"
+	}
+	if o.Pipefail {
+		script += "if (set -o pipefail) 2>/dev/null; then set -o pipefail; fi\n"
+	}
+	if o.ErrTrap {
+		// POSIX sh has no ERR trap; bash (also /bin/sh on RHEL-family) does
+		script += `if [ -n "${BASH_VERSION:-}" ]; then
+    set -E
+    trap 'echo "ERROR: line ${LINENO}: \"${BASH_COMMAND}\" failed with exit code $?" >&2' ERR
+fi
+`
+	}
+	return script
+}
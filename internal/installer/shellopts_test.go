@@ -0,0 +1,109 @@
+package installer
+
+import (
+	"os/exec"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestParseShellOptions tests parsing of shell safety option lists
+func TestParseShellOptions(t *testing.T) {
+	tests := []struct {
+		value       string
+		expected    ShellOptions
+		expectError bool
+	}{
+		{"", ShellOptions{}, false},
+		{"none", ShellOptions{}, false},
+		{"strict", StrictShellOptions, false},
+		{"errexit, pipefail", ShellOptions{ErrExit: true, Pipefail: true}, false},
+		{"NoUnset,errtrap", ShellOptions{NoUnset: true, ErrTrap: true}, false},
+		{"errexit,xtrace", ShellOptions{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseShellOptions(tt.value)
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("ParseShellOptions(%q) expected error", tt.value)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseShellOptions(%q) unexpected error: %v", tt.value, err)
+			}
+			if got != tt.expected {
+				t.Errorf("ParseShellOptions(%q) = %+v, want %+v", tt.value, got, tt.expected)
+			}
+		})
+	}
+
+	if got := StrictShellOptions.Names(); !reflect.DeepEqual(got, []string{"errexit", "errtrap", "nounset", "pipefail"}) {
+		t.Errorf("Names() = %v", got)
+	}
+}
+
+// TestShellOptions_Preamble runs the strict preamble under the available
+// shells: unchecked failures stop the script, tolerated exit codes don't.
+func TestShellOptions_Preamble(t *testing.T) {
+	preamble := StrictShellOptions.preamble()
+
+	for _, shell := range []string{"sh", "bash"} {
+		if _, err := exec.LookPath(shell); err != nil {
+			continue
+		}
+
+		t.Run(shell+" stops at failure", func(t *testing.T) {
+			out, err := exec.Command(shell, "-c", preamble+"echo before\nfalse\necho after\n").CombinedOutput()
+			if err == nil {
+				t.Fatal("expected non-zero exit")
+			}
+			if !strings.Contains(string(out), "before") || strings.Contains(string(out), "after") {
+				t.Errorf("unexpected output: %s", out)
+			}
+			if shell == "bash" && !strings.Contains(string(out), `"false" failed with exit code 1`) {
+				t.Errorf("expected failing line from ERR trap, got: %s", out)
+			}
+		})
+
+		t.Run(shell+" tolerates puppet exit codes", func(t *testing.T) {
+			script := preamble + "PUPPET_EXIT_CODE=0\nsh -c 'exit 2' || PUPPET_EXIT_CODE=$?\necho \"code=$PUPPET_EXIT_CODE\"\n"
+			out, err := exec.Command(shell, "-c", script).CombinedOutput()
+			if err != nil {
+				t.Fatalf("unexpected failure: %v: %s", err, out)
+			}
+			if strings.TrimSpace(string(out)) != "code=2" {
+				t.Errorf("unexpected output: %s", out)
+			}
+		})
+	}
+}
+
+// TestGenerateInstallScript_ShellOptions tests the preamble of Puppet scripts
+func TestGenerateInstallScript_ShellOptions(t *testing.T) {
+	for _, osType := range []string{"debian", "rhel"} {
+		t.Run(osType, func(t *testing.T) {
+			strict := NewPuppetInstaller(PuppetOptions{Server: "puppet.example.com", Shell: StrictShellOptions})
+			scripts, err := strict.GenerateInstallScript(osType, nil)
+			if err != nil {
+				t.Fatalf("GenerateInstallScript() unexpected error: %v", err)
+			}
+			for _, want := range []string{"#!/bin/sh\n# Shell safety options\nset -e\nset -u\n", "set -o pipefail", "trap '", "|| PUPPET_EXIT_CODE=$?"} {
+				if !strings.Contains(scripts[0], want) {
+					t.Errorf("strict script missing %q", want)
+				}
+			}
+
+			legacy := NewPuppetInstaller(PuppetOptions{Server: "puppet.example.com"})
+			scripts, err = legacy.GenerateInstallScript(osType, nil)
+			if err != nil {
+				t.Fatalf("GenerateInstallScript() unexpected error: %v", err)
+			}
+			if strings.Contains(scripts[0], "\nset -e\n") || strings.Contains(scripts[0], "\nset -u\n") {
+				t.Error("default script should not enable shell options")
+			}
+		})
+	}
+}
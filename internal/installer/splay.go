@@ -0,0 +1,44 @@
+package installer
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+)
+
+// MaxFirstRunSplay bounds --first-run-splay so the sleep plus installation
+// fits in the executor's install timeout (30 minutes).
+const MaxFirstRunSplay = 20 * time.Minute
+
+// ValidateFirstRunSplay checks that a --first-run-splay value is within bounds.
+func ValidateFirstRunSplay(splay time.Duration) error {
+	if splay < 0 || splay > MaxFirstRunSplay {
+		return fmt.Errorf("invalid first run splay %s: must be between 0 and %s", splay, MaxFirstRunSplay)
+	}
+	return nil
+}
+
+// chooseSplay picks a random splay in [0, firstRunSplay], in whole seconds
+// (sleep arguments must be integers on POSIX sh). Returns 0 when disabled.
+func (pi *PuppetInstaller) chooseSplay() time.Duration {
+	seconds := int64(pi.firstRunSplay / time.Second)
+	if seconds <= 0 {
+		return 0
+	}
+	// #nosec G404 - Using math/rand for load spreading is acceptable (not cryptographic)
+	return time.Duration(rand.Int63n(seconds+1)) * time.Second
+}
+
+// generateSplayScript generates shell script that sleeps before the initial
+// puppet run, so large fleets don't hit the Puppet Server in the same minute.
+// Returns empty string when splay is disabled.
+func generateSplayScript(splay time.Duration) string {
+	if splay <= 0 {
+		return ""
+	}
+
+	return fmt.Sprintf(`# Splay initial puppet run to spread load on the Puppet Server
+echo "Waiting %ds before initial Puppet run (first-run splay)..."
+sleep %d
+`, int64(splay/time.Second), int64(splay/time.Second))
+}
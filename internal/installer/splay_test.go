@@ -0,0 +1,66 @@
+package installer
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestValidateFirstRunSplay tests bounds of --first-run-splay values
+func TestValidateFirstRunSplay(t *testing.T) {
+	tests := []struct {
+		splay   time.Duration
+		wantErr bool
+	}{
+		{splay: 0, wantErr: false},
+		{splay: 10 * time.Minute, wantErr: false},
+		{splay: MaxFirstRunSplay, wantErr: false},
+		{splay: MaxFirstRunSplay + time.Second, wantErr: true},
+		{splay: -time.Second, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.splay.String(), func(t *testing.T) {
+			err := ValidateFirstRunSplay(tt.splay)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateFirstRunSplay(%s) error = %v, wantErr %v", tt.splay, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestChooseSplay tests that chosen splays stay within bounds in whole seconds
+func TestChooseSplay(t *testing.T) {
+	disabled := NewPuppetInstaller(PuppetOptions{Server: "puppet.example.com"})
+	if got := disabled.chooseSplay(); got != 0 {
+		t.Errorf("chooseSplay() without splay = %s, want 0", got)
+	}
+
+	installer := NewPuppetInstaller(PuppetOptions{Server: "puppet.example.com", FirstRunSplay: 10 * time.Second})
+	for range 100 {
+		splay := installer.chooseSplay()
+		if splay < 0 || splay > 10*time.Second {
+			t.Fatalf("chooseSplay() = %s, want within [0, 10s]", splay)
+		}
+		if splay%time.Second != 0 {
+			t.Fatalf("chooseSplay() = %s, want whole seconds", splay)
+		}
+	}
+}
+
+// TestGeneratePuppetRunScript_Splay tests that the sleep precedes the initial run
+func TestGeneratePuppetRunScript_Splay(t *testing.T) {
+	installer := NewPuppetInstaller(PuppetOptions{Server: "puppet.example.com"})
+
+	script := installer.generatePuppetRunScript(90 * time.Second)
+	sleepIdx := strings.Index(script, "sleep 90\n")
+	runIdx := strings.Index(script, "puppet agent --test")
+	if sleepIdx < 0 || sleepIdx > runIdx {
+		t.Errorf("expected 'sleep 90' before puppet agent --test, got:\n%s", script)
+	}
+
+	// The agent lock check has its own wait loop; only the splay sleep counts
+	if script := installer.generatePuppetRunScript(0); strings.Contains(script, "first-run splay") {
+		t.Errorf("expected no splay sleep without splay, got:\n%s", script)
+	}
+}
@@ -0,0 +1,274 @@
+package installer
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/estudosdevops/opsmaster/internal/cloud"
+)
+
+// systemdUnitDir holds units installed by opsmaster (admin units directory).
+const systemdUnitDir = "/etc/systemd/system"
+
+// systemdBinaryURLEnv carries the binary URL to the download step, keeping
+// it out of the script body and the dry-run output. SSM still records the
+// environment of the command in its history, and presigned URLs are
+// credentials until they expire: s3:// URLs are signed per instance right
+// before its download step (see SystemdUnitOptions.PresignBinaryURL).
+const systemdBinaryURLEnv = "OPSMASTER_BINARY_URL"
+
+// systemdDownloadTimeout bounds the binary download step.
+const systemdDownloadTimeout = 10 * time.Minute
+
+// systemdUnitNamePattern matches service unit names accepted by systemd.
+var systemdUnitNamePattern = regexp.MustCompile(`^[A-Za-z0-9:_.@-]+\.service$`)
+
+// SystemdUnitOptions contains options for deploying a systemd service unit.
+type SystemdUnitOptions struct {
+	UnitName    string // Unit file name, e.g. "my-agent.service" (required)
+	UnitContent string // Unit file content (required)
+
+	BinaryURL    string // HTTPS URL of the binary, e.g. a presigned S3 URL (optional)
+	BinaryPath   string // Install path of the binary (default: /usr/local/bin/<URL file name>)
+	BinarySHA256 string // Expected SHA-256 of the binary, hex (optional)
+
+	// PresignBinaryURL signs a fresh, short-lived BinaryURL for each
+	// instance (s3:// binaries), so the URL recorded in the SSM command
+	// history expires shortly after the download. Nil = BinaryURL as is.
+	PresignBinaryURL func() (string, error)
+}
+
+// Validate checks the unit and binary settings and fills BinaryPath.
+func (o *SystemdUnitOptions) Validate() error {
+	if !systemdUnitNamePattern.MatchString(o.UnitName) {
+		return fmt.Errorf("invalid unit name %q: expected <name>.service", o.UnitName)
+	}
+	if !strings.Contains(o.UnitContent, "[Service]") {
+		return fmt.Errorf("unit file %s has no [Service] section", o.UnitName)
+	}
+
+	if o.BinaryURL == "" {
+		if o.BinaryPath != "" || o.BinarySHA256 != "" {
+			return fmt.Errorf("binary path and checksum require a binary URL")
+		}
+		return nil
+	}
+	u, err := url.Parse(o.BinaryURL)
+	if err != nil || u.Scheme != "https" || u.Host == "" {
+		return fmt.Errorf("invalid binary URL: only https:// is supported")
+	}
+	if o.BinaryPath == "" {
+		name := path.Base(u.Path)
+		if name == "." || name == "/" {
+			return fmt.Errorf("cannot derive binary name from URL, set the binary path")
+		}
+		o.BinaryPath = "/usr/local/bin/" + name
+	}
+	if !path.IsAbs(o.BinaryPath) || strings.ContainsAny(o.BinaryPath, "'\"$` \n") {
+		return fmt.Errorf("invalid binary path %q: must be an absolute path without spaces or quotes", o.BinaryPath)
+	}
+	if o.BinarySHA256 != "" {
+		if decoded, err := hex.DecodeString(o.BinarySHA256); err != nil || len(decoded) != 32 {
+			return fmt.Errorf("invalid binary SHA-256 %q", o.BinarySHA256)
+		}
+		o.BinarySHA256 = strings.ToLower(o.BinarySHA256)
+	}
+	return nil
+}
+
+// SystemdUnitInstaller implements PackageInstaller for small internal agents
+// shipped as a binary plus a systemd unit, without OS packaging.
+// Implements StepBasedInstaller; the steps only need systemd and curl/wget.
+type SystemdUnitInstaller struct {
+	opts         SystemdUnitOptions
+	lastMetadata *InstallMetadata
+}
+
+func init() {
+	Register(Descriptor{
+		Name:       "systemd-unit",
+		OSFamilies: []string{OSTypeDebian, OSTypeRHEL}, // Any: the unit runs the configured binary
+		New:        func() PackageInstaller { return NewSystemdUnitInstaller(SystemdUnitOptions{}) },
+	})
+}
+
+// NewSystemdUnitInstaller creates a new systemd unit installer.
+// Call opts.Validate first.
+func NewSystemdUnitInstaller(opts SystemdUnitOptions) *SystemdUnitInstaller {
+	return &SystemdUnitInstaller{opts: opts, lastMetadata: &InstallMetadata{}}
+}
+
+// Name returns the package name
+func (*SystemdUnitInstaller) Name() string {
+	return "systemd-unit"
+}
+
+// GenerateInstallSteps implements StepBasedInstaller.
+func (si *SystemdUnitInstaller) GenerateInstallSteps(_ string, _ map[string]string) ([]InstallStep, error) {
+	var steps []InstallStep
+	if si.opts.BinaryURL != "" {
+		binaryURL := si.opts.BinaryURL
+		if si.opts.PresignBinaryURL != nil {
+			var err error
+			if binaryURL, err = si.opts.PresignBinaryURL(); err != nil {
+				return nil, fmt.Errorf("failed to presign binary URL: %w", err)
+			}
+		}
+		steps = append(steps, InstallStep{
+			Name:     "download-binary",
+			Commands: []string{si.downloadScript()},
+			Timeout:  systemdDownloadTimeout,
+			Env:      map[string]string{systemdBinaryURLEnv: binaryURL},
+		})
+	}
+	return append(steps,
+		InstallStep{Name: "install-unit", Commands: []string{si.unitScript()}, Timeout: DefaultSSMTimeout},
+		InstallStep{Name: "start-unit", Commands: []string{si.startScript()}, Timeout: DefaultSSMTimeout},
+	), nil
+}
+
+// GenerateInstallScript returns all steps as one script. With a binary URL,
+// OPSMASTER_BINARY_URL must be exported by the caller.
+func (si *SystemdUnitInstaller) GenerateInstallScript(os string, options map[string]string) ([]string, error) {
+	steps, err := si.GenerateInstallSteps(os, options)
+	if err != nil {
+		return nil, err
+	}
+	var commands []string
+	for _, step := range steps {
+		commands = append(commands, step.Commands...)
+	}
+	return commands, nil
+}
+
+// downloadScript downloads the binary to a temporary file next to the
+// target, checks it and replaces the target atomically.
+func (si *SystemdUnitInstaller) downloadScript() string {
+	target := si.opts.BinaryPath
+	script := `#!/bin/sh
+` + downloadShellFunc + `
+mkdir -p ` + path.Dir(target) + `
+TMP="` + target + `.opsmaster-new"
+if ! download "${` + systemdBinaryURLEnv + `}" > "${TMP}"; then
+    echo "Error downloading binary"
+    rm -f "${TMP}"
+    exit 20
+fi
+`
+	if si.opts.BinarySHA256 != "" {
+		script += `ACTUAL="$(sha256sum "${TMP}" | cut -d' ' -f1)"
+if [ "${ACTUAL}" != "` + si.opts.BinarySHA256 + `" ]; then
+    echo "Error: binary checksum mismatch (got ${ACTUAL})"
+    rm -f "${TMP}"
+    exit 20
+fi
+`
+	}
+	return script + `chmod 0755 "${TMP}"
+mv -f "${TMP}" ` + target + `
+echo "✓ Binary installed at ` + target + `"
+`
+}
+
+// unitScript writes the unit file and reloads systemd.
+func (si *SystemdUnitInstaller) unitScript() string {
+	unitPath := systemdUnitDir + "/" + si.opts.UnitName
+	return `#!/bin/sh
+echo '` + base64.StdEncoding.EncodeToString([]byte(si.opts.UnitContent)) + `' | base64 -d > ` + unitPath + `
+chmod 0644 ` + unitPath + `
+if ! systemctl daemon-reload; then
+    echo "Error reloading systemd"
+    exit 40
+fi
+echo "✓ Unit ` + si.opts.UnitName + ` installed"
+`
+}
+
+// startScript enables and (re)starts the unit, so redeployments pick up a
+// new binary or unit file.
+func (si *SystemdUnitInstaller) startScript() string {
+	unit := si.opts.UnitName
+	return `#!/bin/sh
+systemctl enable ` + unit + ` || exit 40
+if ! systemctl restart ` + unit + `; then
+    echo "Error starting ` + unit + `"
+    journalctl -u ` + unit + ` -n 20 --no-pager 2>/dev/null
+    exit 40
+fi
+echo "✓ ` + unit + ` running"
+`
+}
+
+// ValidatePrerequisites checks that the instance runs systemd.
+func (si *SystemdUnitInstaller) ValidatePrerequisites(ctx context.Context, instance *cloud.Instance, provider cloud.CloudProvider) error {
+	result, err := provider.ExecuteCommand(ctx, instance, []string{"test -d /run/systemd/system || exit 1", "command -v curl >/dev/null || command -v wget >/dev/null || exit 2"}, DefaultSSMTimeout)
+	if err != nil {
+		return fmt.Errorf("systemd-unit prerequisites validation failed: %w", err)
+	}
+	switch result.ExitCode {
+	case 0:
+		return nil
+	case 1:
+		return fmt.Errorf("systemd-unit prerequisites validation failed: instance is not running systemd")
+	case 2:
+		if si.opts.BinaryURL != "" {
+			return fmt.Errorf("systemd-unit prerequisites validation failed: curl or wget is required to download the binary")
+		}
+		return nil
+	default:
+		return fmt.Errorf("systemd-unit prerequisites validation failed (exit code %d): %s", result.ExitCode, result.Stderr)
+	}
+}
+
+// VerifyInstallation checks that the unit is enabled and still active a few
+// seconds after start (catches crash loops with Restart=always).
+func (si *SystemdUnitInstaller) VerifyInstallation(ctx context.Context, instance *cloud.Instance, provider cloud.CloudProvider) error {
+	unit := si.opts.UnitName
+	verifyCommands := []string{
+		"systemctl is-enabled --quiet " + unit + " || exit 1",
+		"sleep 5",
+		"systemctl is-active --quiet " + unit + " || exit 2",
+		`[ "$(systemctl show -p NRestarts --value ` + unit + `)" = "0" ] || exit 3`,
+	}
+
+	result, err := provider.ExecuteCommand(ctx, instance, verifyCommands, DefaultSSMTimeout)
+	if err != nil {
+		return fmt.Errorf("failed to verify %s: %w", unit, err)
+	}
+	switch result.ExitCode {
+	case 0:
+		return nil
+	case 3:
+		return fmt.Errorf("%s is restarting (crash loop)", unit)
+	default:
+		return fmt.Errorf("%s verification failed (exit code %d):\nstdout: %s\nstderr: %s",
+			unit, result.ExitCode, result.Stdout, result.Stderr)
+	}
+}
+
+// GetSuccessTags returns tags to apply after successful installation.
+func (si *SystemdUnitInstaller) GetSuccessTags() map[string]string {
+	return map[string]string{
+		"systemd-unit:" + strings.TrimSuffix(si.opts.UnitName, ".service"): "true",
+	}
+}
+
+// GetFailureTags returns tags to apply when installation fails.
+func (*SystemdUnitInstaller) GetFailureTags(_ error) map[string]string {
+	return map[string]string{}
+}
+
+// GetInstallMetadata returns metadata from the last installation attempt.
+func (si *SystemdUnitInstaller) GetInstallMetadata() *InstallMetadata {
+	if si.lastMetadata == nil {
+		return &InstallMetadata{}
+	}
+	return si.lastMetadata
+}
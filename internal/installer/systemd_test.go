@@ -0,0 +1,132 @@
+package installer
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+const testUnit = "[Unit]\nDescription=My agent\n\n[Service]\nExecStart=/usr/local/bin/my-agent\nRestart=always\n\n[Install]\nWantedBy=multi-user.target\n"
+
+// TestSystemdUnitOptions_Validate tests unit and binary validation
+func TestSystemdUnitOptions_Validate(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     SystemdUnitOptions
+		wantPath string
+		wantErr  string
+	}{
+		{name: "unit only", opts: SystemdUnitOptions{UnitName: "my-agent.service", UnitContent: testUnit}},
+		{
+			name:     "binary path from presigned URL",
+			opts:     SystemdUnitOptions{UnitName: "my-agent.service", UnitContent: testUnit, BinaryURL: "https://b.s3.us-east-1.amazonaws.com/agents/my-agent?X-Amz-Signature=abc"},
+			wantPath: "/usr/local/bin/my-agent",
+		},
+		{
+			name:     "explicit binary path",
+			opts:     SystemdUnitOptions{UnitName: "my-agent.service", UnitContent: testUnit, BinaryURL: "https://example.com/dl", BinaryPath: "/opt/agent/bin/agent"},
+			wantPath: "/opt/agent/bin/agent",
+		},
+		{name: "not a service", opts: SystemdUnitOptions{UnitName: "my-agent.timer", UnitContent: testUnit}, wantErr: "expected <name>.service"},
+		{name: "shell characters in name", opts: SystemdUnitOptions{UnitName: "x;reboot.service", UnitContent: testUnit}, wantErr: "invalid unit name"},
+		{name: "no service section", opts: SystemdUnitOptions{UnitName: "a.service", UnitContent: "[Unit]\n"}, wantErr: "no [Service] section"},
+		{name: "http URL", opts: SystemdUnitOptions{UnitName: "a.service", UnitContent: testUnit, BinaryURL: "http://example.com/a"}, wantErr: "only https://"},
+		{name: "URL without file name", opts: SystemdUnitOptions{UnitName: "a.service", UnitContent: testUnit, BinaryURL: "https://example.com/"}, wantErr: "set the binary path"},
+		{name: "relative binary path", opts: SystemdUnitOptions{UnitName: "a.service", UnitContent: testUnit, BinaryURL: "https://example.com/a", BinaryPath: "bin/a"}, wantErr: "absolute path"},
+		{name: "bad checksum", opts: SystemdUnitOptions{UnitName: "a.service", UnitContent: testUnit, BinaryURL: "https://example.com/a", BinarySHA256: "abc"}, wantErr: "invalid binary SHA-256"},
+		{name: "checksum without binary", opts: SystemdUnitOptions{UnitName: "a.service", UnitContent: testUnit, BinarySHA256: "abc"}, wantErr: "require a binary URL"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// ACT
+			err := tt.opts.Validate()
+
+			// ASSERT
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.opts.BinaryPath != tt.wantPath {
+				t.Errorf("BinaryPath = %q, want %q", tt.opts.BinaryPath, tt.wantPath)
+			}
+		})
+	}
+}
+
+// TestSystemdUnitInstaller_GenerateInstallSteps tests step layout and URL transport
+func TestSystemdUnitInstaller_GenerateInstallSteps(t *testing.T) {
+	t.Run("binary download with checksum", func(t *testing.T) {
+		// ARRANGE
+		opts := SystemdUnitOptions{
+			UnitName:     "my-agent.service",
+			UnitContent:  testUnit,
+			BinaryURL:    "https://example.com/agents/my-agent?X-Amz-Signature=secret",
+			BinarySHA256: strings.Repeat("AB", 32),
+		}
+		if err := opts.Validate(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		si := NewSystemdUnitInstaller(opts)
+
+		// ACT
+		steps, err := si.GenerateInstallSteps("", nil)
+
+		// ASSERT
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(steps) != 3 || steps[0].Name != "download-binary" || steps[2].Name != "start-unit" {
+			t.Fatalf("unexpected steps %+v", steps)
+		}
+		download := steps[0].Commands[0]
+		if strings.Contains(download, "X-Amz-Signature") || steps[0].Env[systemdBinaryURLEnv] != opts.BinaryURL {
+			t.Error("binary URL must travel as step env, not script text")
+		}
+		if !strings.Contains(download, strings.Repeat("ab", 32)) || !strings.Contains(download, "mv -f \"${TMP}\" /usr/local/bin/my-agent") {
+			t.Errorf("unexpected download script:\n%s", download)
+		}
+		if !strings.Contains(steps[2].Commands[0], "systemctl restart my-agent.service") {
+			t.Errorf("unexpected start script:\n%s", steps[2].Commands[0])
+		}
+	})
+
+	t.Run("presigned per instance", func(t *testing.T) {
+		signed := 0
+		si := NewSystemdUnitInstaller(SystemdUnitOptions{
+			UnitName:    "my-agent.service",
+			UnitContent: testUnit,
+			BinaryURL:   "https://bucket.s3.amazonaws.com/my-agent?X-Amz-Signature=first",
+			PresignBinaryURL: func() (string, error) {
+				signed++
+				return fmt.Sprintf("https://bucket.s3.amazonaws.com/my-agent?X-Amz-Signature=%d", signed), nil
+			},
+		})
+
+		first, _ := si.GenerateInstallSteps("", nil)
+		second, _ := si.GenerateInstallSteps("", nil)
+
+		if signed != 2 || first[0].Env[systemdBinaryURLEnv] == second[0].Env[systemdBinaryURLEnv] {
+			t.Errorf("expected a fresh URL per instance, got %q and %q",
+				first[0].Env[systemdBinaryURLEnv], second[0].Env[systemdBinaryURLEnv])
+		}
+	})
+
+	t.Run("unit only", func(t *testing.T) {
+		si := NewSystemdUnitInstaller(SystemdUnitOptions{UnitName: "my-agent.service", UnitContent: testUnit})
+
+		steps, _ := si.GenerateInstallSteps("", nil)
+
+		if len(steps) != 2 || steps[0].Name != "install-unit" {
+			t.Errorf("unexpected steps %+v", steps)
+		}
+		if tags := si.GetSuccessTags(); tags["systemd-unit:my-agent"] != "true" {
+			t.Errorf("unexpected success tags %v", tags)
+		}
+	})
+}
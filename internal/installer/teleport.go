@@ -0,0 +1,441 @@
+package installer
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/estudosdevops/opsmaster/internal/cloud"
+	"github.com/estudosdevops/opsmaster/internal/validator"
+)
+
+// Teleport paths used by the node configuration.
+const (
+	teleportConfigFile  = "/etc/teleport.yaml"
+	teleportTokenFile   = "/etc/teleport/join-token"
+	teleportDataDir     = "/var/lib/teleport"
+	teleportJoinWindow  = 15 * time.Second
+	teleportInstallTime = 10 * time.Minute
+)
+
+// teleportTokenEnv carries the join token to the configure step.
+const teleportTokenEnv = "TELEPORT_JOIN_TOKEN"
+
+// DefaultTeleportNodenameColumn is the CSV column used as Teleport node name.
+const DefaultTeleportNodenameColumn = "hostname"
+
+// MetadataKeyTeleportNodename records the node name in install metadata.
+const MetadataKeyTeleportNodename = "teleport_nodename"
+
+// TeleportOptions contains Teleport node installation options.
+type TeleportOptions struct {
+	ProxyServer string // Proxy address "host[:port]" (default port 443); exclusive with AuthServer
+	AuthServer  string // Auth server address "host[:port]" (default port 3025); exclusive with ProxyServer
+	Version     string // Major version of the release channel, e.g. "16" (required)
+	CAPin       string // CA pin ("sha256:...") recommended when joining an auth server directly
+
+	// The join token comes from exactly one of these
+	JoinToken          string // Token value (already resolved from vault: references)
+	JoinTokenParameter string // Secret store parameter resolved on the instance (AWS: SSM Parameter Store)
+
+	NodenameColumn string   // CSV column with the node name (default: "hostname"; instance ID when empty)
+	LabelColumns   []string // CSV columns added as node labels
+}
+
+// Validate checks the options and normalizes server addresses to host:port.
+func (o *TeleportOptions) Validate() error {
+	switch {
+	case (o.ProxyServer == "") == (o.AuthServer == ""):
+		return fmt.Errorf("teleport requires exactly one of proxy server or auth server")
+	case o.ProxyServer != "":
+		addr, err := teleportHostPort(o.ProxyServer, 443)
+		if err != nil {
+			return err
+		}
+		o.ProxyServer = addr
+	default:
+		addr, err := teleportHostPort(o.AuthServer, 3025)
+		if err != nil {
+			return err
+		}
+		o.AuthServer = addr
+	}
+
+	if major, err := strconv.Atoi(o.Version); err != nil || major < 1 {
+		return fmt.Errorf("invalid teleport version %q: use the major version, e.g. 16", o.Version)
+	}
+	if (o.JoinToken == "") == (o.JoinTokenParameter == "") {
+		return fmt.Errorf("teleport requires exactly one of join token or join token parameter")
+	}
+	if o.CAPin != "" && !strings.HasPrefix(o.CAPin, "sha256:") {
+		return fmt.Errorf("invalid teleport CA pin %q: expected sha256:<hash>", o.CAPin)
+	}
+	return nil
+}
+
+// teleportHostPort normalizes "host", "host:port" or "https://host[:port]".
+func teleportHostPort(server string, defaultPort int) (string, error) {
+	hostPort := strings.TrimSuffix(strings.TrimPrefix(server, "https://"), "/")
+	if strings.Contains(hostPort, "://") || strings.Contains(hostPort, "/") {
+		return "", fmt.Errorf("invalid teleport server %q: use host[:port]", server)
+	}
+
+	host, port, err := net.SplitHostPort(hostPort)
+	if err != nil {
+		host, port = hostPort, strconv.Itoa(defaultPort)
+	}
+	if n, err := strconv.Atoi(port); host == "" || err != nil || n < 1 || n > 65535 {
+		return "", fmt.Errorf("invalid teleport server %q", server)
+	}
+	return net.JoinHostPort(host, port), nil
+}
+
+// TeleportInstaller implements PackageInstaller for Teleport SSH nodes.
+// Implements LocalInstaller: it detects the OS, then runs the install,
+// configure and start steps itself, so the per-instance teleport.yaml and
+// the join token (as environment) go to separate remote calls.
+type TeleportInstaller struct {
+	opts         TeleportOptions
+	lastMetadata *InstallMetadata
+}
+
+func init() {
+	Register(Descriptor{
+		Name:          "teleport",
+		OSFamilies:    []string{OSTypeDebian, OSTypeRHEL},
+		Architectures: []string{"amd64", "arm64"},
+		New:           func() PackageInstaller { return NewTeleportInstaller(TeleportOptions{}) },
+	})
+}
+
+// NewTeleportInstaller creates a new Teleport installer.
+// Call opts.Validate first.
+func NewTeleportInstaller(opts TeleportOptions) *TeleportInstaller {
+	if opts.NodenameColumn == "" {
+		opts.NodenameColumn = DefaultTeleportNodenameColumn
+	}
+	return &TeleportInstaller{opts: opts, lastMetadata: &InstallMetadata{}}
+}
+
+// Name returns the package name
+func (*TeleportInstaller) Name() string {
+	return "teleport"
+}
+
+// InstallLocal implements LocalInstaller.
+func (ti *TeleportInstaller) InstallLocal(ctx context.Context, instance *cloud.Instance, provider cloud.CloudProvider) (*InstallMetadata, error) {
+	detectedOS, shell, err := detectOS(ctx, instance, provider)
+	if err != nil {
+		return nil, fmt.Errorf("failed to detect OS: %w", err)
+	}
+	normalizedOS, err := normalizeOS(detectedOS)
+	if err != nil {
+		return nil, fmt.Errorf("failed to normalize OS type: %w", err)
+	}
+
+	metadata := &InstallMetadata{OS: detectedOS, Shell: shell}
+	metadata.Set(MetadataKeyTeleportNodename, ti.nodename(instance))
+
+	steps, err := ti.installSteps(normalizedOS, instance)
+	if err != nil {
+		return metadata, err
+	}
+	for _, step := range steps {
+		result, err := provider.ExecuteCommandWithOptions(ctx, instance, step.Commands, step.Timeout,
+			cloud.ExecOptions{Env: step.Env, SecretEnv: step.SecretEnv, Comment: "install step " + step.Name})
+		if err != nil {
+			return metadata, fmt.Errorf("failed to execute install step %q: %w", step.Name, err)
+		}
+		if result.ExitCode != 0 {
+			return metadata, &ScriptError{Step: step.Name, ExitCode: result.ExitCode, Stdout: result.Stdout, Stderr: result.Stderr}
+		}
+	}
+	return metadata, nil
+}
+
+// GenerateInstallScript generates the installation script for an OS (used in
+// dry-run mode). Instance labels and node name are not rendered, and the join
+// token must be exported as TELEPORT_JOIN_TOKEN by the caller.
+func (ti *TeleportInstaller) GenerateInstallScript(os string, _ map[string]string) ([]string, error) {
+	normalizedOS, err := normalizeOS(os)
+	if err != nil {
+		// Same fallback as the Puppet installer for unknown CSV values
+		normalizedOS = OSTypeDebian
+	}
+
+	steps, err := ti.installSteps(normalizedOS, nil)
+	if err != nil {
+		return nil, err
+	}
+	var commands []string
+	for _, step := range steps {
+		commands = append(commands, step.Commands...)
+	}
+	return commands, nil
+}
+
+// installSteps returns the install, configure and start steps.
+func (ti *TeleportInstaller) installSteps(normalizedOS string, instance *cloud.Instance) ([]InstallStep, error) {
+	var repo string
+	switch normalizedOS {
+	case OSTypeDebian:
+		repo = teleportDebianRepoScript
+	case OSTypeRHEL:
+		repo = teleportRHELRepoScript
+	default:
+		return nil, fmt.Errorf("internal error: unexpected normalized OS type: %s", normalizedOS)
+	}
+
+	config, err := ti.renderConfig(instance)
+	if err != nil {
+		return nil, err
+	}
+
+	configure := InstallStep{
+		Name:     "configure",
+		Commands: []string{teleportConfigureScript(config)},
+		Timeout:  DefaultSSMTimeout,
+	}
+	if ti.opts.JoinTokenParameter != "" {
+		configure.SecretEnv = map[string]string{teleportTokenEnv: ti.opts.JoinTokenParameter}
+	} else {
+		configure.Env = map[string]string{teleportTokenEnv: ti.opts.JoinToken}
+	}
+
+	return []InstallStep{
+		{
+			Name:     "install-package",
+			Commands: []string{"#!/bin/sh\nTELEPORT_VERSION=" + ti.opts.Version + "\n. /etc/os-release\n" + downloadShellFunc + repo},
+			Timeout:  teleportInstallTime,
+		},
+		configure,
+		{Name: "start-service", Commands: []string{teleportServiceScript}, Timeout: DefaultSSMTimeout},
+	}, nil
+}
+
+// teleportDebianRepoScript adds the apt repository of the major version channel.
+const teleportDebianRepoScript = `
+echo "Configuring Teleport apt repository (v${TELEPORT_VERSION})..."
+install -d -m 0755 /usr/share/keyrings
+if ! download https://apt.releases.teleport.dev/gpg > /usr/share/keyrings/teleport-archive-keyring.asc; then
+    echo "Error downloading Teleport repository key"
+    exit 20
+fi
+echo "deb [signed-by=/usr/share/keyrings/teleport-archive-keyring.asc] https://apt.releases.teleport.dev/${ID} ${VERSION_CODENAME} stable/v${TELEPORT_VERSION}" > /etc/apt/sources.list.d/teleport.list
+apt-get update -qq || { echo "Error updating package cache"; exit 20; }
+DEBIAN_FRONTEND=noninteractive apt-get install -y teleport || { echo "Error installing teleport package"; exit 30; }
+echo "✓ Teleport package installed"
+`
+
+// teleportRHELRepoScript adds the yum repository of the major version channel.
+const teleportRHELRepoScript = `
+echo "Configuring Teleport yum repository (v${TELEPORT_VERSION})..."
+REPO_URL="$(rpm --eval "https://yum.releases.teleport.dev/${ID}/${VERSION_ID%%.*}/Teleport/%{_arch}/stable/v${TELEPORT_VERSION}/teleport.repo")"
+if ! download "${REPO_URL}" > /etc/yum.repos.d/teleport.repo; then
+    echo "Error downloading Teleport repository definition (${REPO_URL})"
+    exit 20
+fi
+yum install -y teleport || { echo "Error installing teleport package"; exit 30; }
+echo "✓ Teleport package installed"
+`
+
+// teleportConfigureScript writes the join token (from the environment) and
+// teleport.yaml, validating the configuration before replacing it.
+func teleportConfigureScript(config string) string {
+	return `#!/bin/sh
+if [ -z "${` + teleportTokenEnv + `}" ]; then
+    echo "ERROR: join token is empty"
+    exit 10
+fi
+mkdir -p /etc/teleport ` + teleportDataDir + `
+umask 077
+printf '%s' "${` + teleportTokenEnv + `}" > ` + teleportTokenFile + `
+chmod 600 ` + teleportTokenFile + `
+echo '` + base64.StdEncoding.EncodeToString([]byte(config)) + `' | base64 -d > ` + teleportConfigFile + `.new
+if ! teleport configure --test ` + teleportConfigFile + `.new >/dev/null; then
+    echo "Error: invalid Teleport configuration"
+    rm -f ` + teleportConfigFile + `.new
+    exit 40
+fi
+mv ` + teleportConfigFile + `.new ` + teleportConfigFile + `
+chmod 600 ` + teleportConfigFile + `
+echo "✓ Teleport configured"
+`
+}
+
+// teleportServiceScript enables and (re)starts the node service.
+const teleportServiceScript = `#!/bin/sh
+systemctl enable teleport
+if ! systemctl restart teleport; then
+    echo "Error starting teleport service"
+    journalctl -u teleport -n 20 --no-pager 2>/dev/null
+    exit 40
+fi
+echo "✓ Teleport running"
+`
+
+// teleportConfig is the subset of teleport.yaml (config v3) rendered for nodes.
+type teleportConfig struct {
+	Version  string `yaml:"version"`
+	Teleport struct {
+		Nodename    string `yaml:"nodename,omitempty"`
+		DataDir     string `yaml:"data_dir"`
+		ProxyServer string `yaml:"proxy_server,omitempty"`
+		AuthServer  string `yaml:"auth_server,omitempty"`
+		CAPin       string `yaml:"ca_pin,omitempty"`
+		JoinParams  struct {
+			TokenName string `yaml:"token_name"`
+			Method    string `yaml:"method"`
+		} `yaml:"join_params"`
+		Log struct {
+			Output   string `yaml:"output"`
+			Severity string `yaml:"severity"`
+		} `yaml:"log"`
+	} `yaml:"teleport"`
+	AuthService  teleportService `yaml:"auth_service"`
+	ProxyService teleportService `yaml:"proxy_service"`
+	SSHService   teleportService `yaml:"ssh_service"`
+}
+
+// teleportService toggles a Teleport service.
+type teleportService struct {
+	Enabled bool              `yaml:"enabled"`
+	Labels  map[string]string `yaml:"labels,omitempty"`
+}
+
+// renderConfig builds teleport.yaml for an instance (nil = no instance values).
+func (ti *TeleportInstaller) renderConfig(instance *cloud.Instance) (string, error) {
+	var config teleportConfig
+	config.Version = "v3"
+	config.Teleport.Nodename = ti.nodename(instance)
+	config.Teleport.DataDir = teleportDataDir
+	config.Teleport.ProxyServer = ti.opts.ProxyServer
+	config.Teleport.AuthServer = ti.opts.AuthServer
+	config.Teleport.CAPin = ti.opts.CAPin
+	config.Teleport.JoinParams.TokenName = teleportTokenFile
+	config.Teleport.JoinParams.Method = "token"
+	config.Teleport.Log.Output = "stderr"
+	config.Teleport.Log.Severity = "INFO"
+	config.SSHService = teleportService{Enabled: true, Labels: ti.labels(instance)}
+
+	out, err := yaml.Marshal(config)
+	if err != nil {
+		return "", fmt.Errorf("failed to render teleport.yaml: %w", err)
+	}
+	return string(out), nil
+}
+
+// nodename returns the node name: the NodenameColumn value, or the instance ID.
+func (ti *TeleportInstaller) nodename(instance *cloud.Instance) string {
+	if instance == nil {
+		return ""
+	}
+	if name := strings.TrimSpace(instance.Metadata[ti.opts.NodenameColumn]); name != "" {
+		return name
+	}
+	return instance.ID
+}
+
+// labels returns node labels: instance identity plus LabelColumns.
+func (ti *TeleportInstaller) labels(instance *cloud.Instance) map[string]string {
+	if instance == nil {
+		return nil
+	}
+	labels := map[string]string{"instance_id": instance.ID}
+	if instance.Account != "" {
+		labels["account"] = instance.Account
+	}
+	if instance.Region != "" {
+		labels["region"] = instance.Region
+	}
+	for _, column := range ti.opts.LabelColumns {
+		if value := instance.Metadata[column]; value != "" {
+			labels[column] = value
+		}
+	}
+	return labels
+}
+
+// ValidatePrerequisites checks SSM connectivity and that the instance
+// reaches the proxy or auth server.
+func (ti *TeleportInstaller) ValidatePrerequisites(ctx context.Context, instance *cloud.Instance, provider cloud.CloudProvider) error {
+	server := ti.opts.ProxyServer
+	if server == "" {
+		server = ti.opts.AuthServer
+	}
+	host, portStr, err := net.SplitHostPort(server)
+	if err != nil {
+		return fmt.Errorf("invalid teleport server %q: %w", server, err)
+	}
+	port, _ := strconv.Atoi(portStr)
+
+	results := validator.NewCompositeValidator([]validator.Validator{
+		validator.NewSSMValidator(0),
+		validator.NewConnectivityValidator("teleport_server_reachable", host, port, 0),
+	}, false).Validate(ctx, instance, provider)
+
+	if !validator.AllPassed(results) {
+		var failures []string
+		for _, failed := range validator.GetFailedValidations(results) {
+			failures = append(failures, fmt.Sprintf("%s: %s", failed.Name, failed.Message))
+		}
+		return fmt.Errorf("teleport prerequisites validation failed:\n  - %s", strings.Join(failures, "\n  - "))
+	}
+	return nil
+}
+
+// VerifyInstallation checks that the node registered with the cluster: the
+// service stays active through the join window and its journal since the
+// last start has no join errors (invalid/expired token, CA pin mismatch).
+func (*TeleportInstaller) VerifyInstallation(ctx context.Context, instance *cloud.Instance, provider cloud.CloudProvider) error {
+	verifyCommands := []string{
+		"command -v teleport >/dev/null || exit 1",
+		"teleport version || exit 2",
+		"systemctl is-active --quiet teleport || exit 3",
+		fmt.Sprintf("sleep %d", int(teleportJoinWindow.Seconds())),
+		"systemctl is-active --quiet teleport || exit 3",
+		`SINCE="$(systemctl show -p ActiveEnterTimestamp --value teleport)"`,
+		`if journalctl -u teleport --since "${SINCE}" --no-pager 2>/dev/null | grep -iE 'failed to (join|register)|token .*(expired|not found|invalid)|access denied|ca pin' | tail -3 | grep .; then exit 5; fi`,
+	}
+
+	result, err := provider.ExecuteCommand(ctx, instance, verifyCommands, DefaultSSMTimeout)
+	if err != nil {
+		return fmt.Errorf("failed to verify teleport installation: %w", err)
+	}
+
+	switch result.ExitCode {
+	case 0:
+		return nil
+	case 5:
+		return fmt.Errorf("teleport is running but the node failed to join the cluster: %s", strings.TrimSpace(result.Stdout))
+	default:
+		return fmt.Errorf("teleport verification failed (exit code %d):\nstdout: %s\nstderr: %s",
+			result.ExitCode, result.Stdout, result.Stderr)
+	}
+}
+
+// GetSuccessTags returns tags to apply after successful installation.
+func (*TeleportInstaller) GetSuccessTags() map[string]string {
+	return map[string]string{
+		"teleport": "true",
+	}
+}
+
+// GetFailureTags returns tags to apply when installation fails.
+func (*TeleportInstaller) GetFailureTags(_ error) map[string]string {
+	return map[string]string{}
+}
+
+// GetInstallMetadata returns metadata from the last installation attempt.
+func (ti *TeleportInstaller) GetInstallMetadata() *InstallMetadata {
+	if ti.lastMetadata == nil {
+		return &InstallMetadata{}
+	}
+	return ti.lastMetadata
+}
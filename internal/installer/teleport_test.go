@@ -0,0 +1,169 @@
+package installer
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/estudosdevops/opsmaster/internal/cloud"
+	"github.com/estudosdevops/opsmaster/internal/cloud/cloudtest"
+)
+
+// TestTeleportOptions_Validate tests server normalization and token sources
+func TestTeleportOptions_Validate(t *testing.T) {
+	tests := []struct {
+		name      string
+		opts      TeleportOptions
+		wantProxy string
+		wantAuth  string
+		wantErr   string
+	}{
+		{name: "proxy defaults to 443", opts: TeleportOptions{ProxyServer: "teleport.example.com", Version: "16", JoinToken: "t"}, wantProxy: "teleport.example.com:443"},
+		{name: "auth defaults to 3025", opts: TeleportOptions{AuthServer: "auth.internal", Version: "16", JoinTokenParameter: "/teleport/token", CAPin: "sha256:abc"}, wantAuth: "auth.internal:3025"},
+		{name: "no server", opts: TeleportOptions{Version: "16", JoinToken: "t"}, wantErr: "exactly one of proxy server or auth server"},
+		{name: "both servers", opts: TeleportOptions{ProxyServer: "p", AuthServer: "a", Version: "16", JoinToken: "t"}, wantErr: "exactly one of proxy server or auth server"},
+		{name: "full version", opts: TeleportOptions{ProxyServer: "p", Version: "16.4.2", JoinToken: "t"}, wantErr: "use the major version"},
+		{name: "no token", opts: TeleportOptions{ProxyServer: "p", Version: "16"}, wantErr: "exactly one of join token"},
+		{name: "bad CA pin", opts: TeleportOptions{AuthServer: "a", Version: "16", JoinToken: "t", CAPin: "abc"}, wantErr: "invalid teleport CA pin"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// ACT
+			err := tt.opts.Validate()
+
+			// ASSERT
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.opts.ProxyServer != tt.wantProxy || tt.opts.AuthServer != tt.wantAuth {
+				t.Errorf("got proxy=%q auth=%q", tt.opts.ProxyServer, tt.opts.AuthServer)
+			}
+		})
+	}
+}
+
+// TestTeleportInstaller_RenderConfig tests the per-instance teleport.yaml
+func TestTeleportInstaller_RenderConfig(t *testing.T) {
+	ti := NewTeleportInstaller(TeleportOptions{ProxyServer: "teleport.example.com:443", Version: "16", JoinToken: "secret", LabelColumns: []string{"environment", "missing"}})
+
+	tests := []struct {
+		name         string
+		instance     *cloud.Instance
+		wantNodename string
+		wantLabels   map[string]string
+	}{
+		{
+			name:         "hostname column and label columns",
+			instance:     &cloud.Instance{ID: "i-1", Account: "111111111111", Region: "us-east-1", Metadata: map[string]string{"hostname": "web-01", "environment": "prod"}},
+			wantNodename: "web-01",
+			wantLabels:   map[string]string{"instance_id": "i-1", "account": "111111111111", "region": "us-east-1", "environment": "prod"},
+		},
+		{
+			name:         "instance ID without hostname",
+			instance:     &cloud.Instance{ID: "i-2"},
+			wantNodename: "i-2",
+			wantLabels:   map[string]string{"instance_id": "i-2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// ACT
+			out, err := ti.renderConfig(tt.instance)
+
+			// ASSERT
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var config teleportConfig
+			if err := yaml.Unmarshal([]byte(out), &config); err != nil {
+				t.Fatalf("invalid YAML: %v\n%s", err, out)
+			}
+			if config.Teleport.Nodename != tt.wantNodename || config.Teleport.ProxyServer != "teleport.example.com:443" ||
+				config.Teleport.JoinParams.TokenName != teleportTokenFile || !config.SSHService.Enabled || config.AuthService.Enabled {
+				t.Errorf("unexpected config:\n%s", out)
+			}
+			if len(config.SSHService.Labels) != len(tt.wantLabels) {
+				t.Errorf("labels = %v, want %v", config.SSHService.Labels, tt.wantLabels)
+			}
+			for key, value := range tt.wantLabels {
+				if config.SSHService.Labels[key] != value {
+					t.Errorf("label %s = %q, want %q", key, config.SSHService.Labels[key], value)
+				}
+			}
+			if strings.Contains(out, "secret") {
+				t.Error("teleport.yaml must not contain the join token")
+			}
+		})
+	}
+}
+
+// TestTeleportInstaller_InstallLocal tests the step sequence and token transport
+func TestTeleportInstaller_InstallLocal(t *testing.T) {
+	t.Run("token parameter goes to configure step only", func(t *testing.T) {
+		// ARRANGE
+		provider := &cloudtest.Provider{
+			ExecuteCommandFunc: func(_ context.Context, _ *cloud.Instance, commands []string, _ time.Duration) (*cloud.CommandResult, error) {
+				if strings.Contains(commands[0], "/etc/os-release") && !strings.Contains(commands[0], "TELEPORT_VERSION") {
+					return &cloud.CommandResult{Stdout: "rhel\nshell=bash\n"}, nil
+				}
+				return &cloud.CommandResult{}, nil
+			},
+		}
+		ti := NewTeleportInstaller(TeleportOptions{ProxyServer: "p:443", Version: "16", JoinTokenParameter: "/teleport/token"})
+
+		// ACT
+		metadata, err := ti.InstallLocal(context.Background(), &cloud.Instance{ID: "i-1"}, provider)
+
+		// ASSERT
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if metadata.OS != "rhel" || metadata.Get(MetadataKeyTeleportNodename) != "i-1" {
+			t.Errorf("unexpected metadata %+v", metadata)
+		}
+		var calls []cloudtest.Call
+		for _, call := range provider.Calls(cloudtest.MethodExecuteCommand) {
+			if strings.HasPrefix(call.Options.Comment, "install step") {
+				calls = append(calls, call)
+			}
+		}
+		if len(calls) != 3 {
+			t.Fatalf("expected 3 install steps, got %d", len(calls))
+		}
+		for i, call := range calls {
+			hasToken := call.Options.SecretEnv[teleportTokenEnv] == "/teleport/token"
+			if hasToken != (i == 1) {
+				t.Errorf("step %d (%s): unexpected secret env %v", i, call.Options.Comment, call.Options.SecretEnv)
+			}
+		}
+	})
+
+	t.Run("failing step is named", func(t *testing.T) {
+		provider := cloudtest.New().
+			OnCommand("teleport configure --test", &cloud.CommandResult{ExitCode: ExitCodeConfigure, Stdout: "Error: invalid Teleport configuration"}).
+			OnCommand("", &cloud.CommandResult{Stdout: "debian\n"})
+		ti := NewTeleportInstaller(TeleportOptions{ProxyServer: "p:443", Version: "16", JoinToken: "t"})
+
+		_, err := ti.InstallLocal(context.Background(), &cloud.Instance{ID: "i-1"}, provider)
+
+		if err == nil || !strings.Contains(err.Error(), `install step "configure" failed with exit code 40 (configure)`) {
+			t.Errorf("unexpected error %v", err)
+		}
+		var scriptErr *ScriptError
+		if !errors.As(err, &scriptErr) || scriptErr.Phase() != ScriptPhaseConfigure {
+			t.Errorf("expected configure ScriptError, got %#v", err)
+		}
+	})
+}
@@ -0,0 +1,67 @@
+package installer
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// Remote working directories for staged files (downloaded packages, probes).
+const (
+	DefaultRemoteWorkdir  = "/tmp"
+	FallbackRemoteWorkdir = "/var/lib/opsmaster" // Used when /tmp is mounted noexec
+)
+
+// ValidateRemoteWorkdir checks that a --remote-workdir value is safe to embed
+// in generated scripts. Empty means automatic (/tmp with noexec fallback).
+func ValidateRemoteWorkdir(dir string) error {
+	if dir == "" {
+		return nil
+	}
+	if !path.IsAbs(dir) {
+		return fmt.Errorf("invalid remote workdir %q: must be an absolute path", dir)
+	}
+	if strings.ContainsAny(dir, " \t\n'\"`$\\;&|<>*?") {
+		return fmt.Errorf("invalid remote workdir %q: must not contain spaces or shell metacharacters", dir)
+	}
+	return nil
+}
+
+// generateWorkdirScript generates shell script that creates a private staging
+// directory (STAGE_DIR) removed on exit, even when the installation fails.
+//
+// With a configured workdir, it must allow execution or the script fails.
+// Otherwise /tmp is used, falling back to /var/lib/opsmaster when /tmp is noexec
+// (common on hardened images).
+func (pi *PuppetInstaller) generateWorkdirScript() string {
+	candidates := DefaultRemoteWorkdir + " " + FallbackRemoteWorkdir
+	if pi.remoteWorkdir != "" {
+		candidates = pi.remoteWorkdir
+	}
+
+	return fmt.Sprintf(`# Prepare remote working directory (noexec-aware)
+WORKDIR=""
+for CANDIDATE in %s; do
+    mkdir -p "$CANDIDATE" 2>/dev/null || continue
+    PROBE="$CANDIDATE/.opsmaster-exec-probe.$$"
+    PROBE_EXIT=1
+    if printf '#!/bin/sh\nexit 0\n' > "$PROBE" 2>/dev/null && chmod 700 "$PROBE" && "$PROBE" 2>/dev/null; then
+        PROBE_EXIT=0
+    fi
+    rm -f "$PROBE"
+    if [ $PROBE_EXIT -eq 0 ]; then
+        WORKDIR="$CANDIDATE"
+        break
+    fi
+    echo "  ⚠ $CANDIDATE is not writable or mounted noexec"
+done
+if [ -z "$WORKDIR" ]; then
+    echo "ERROR: No usable remote working directory (tried: %s)"
+    echo "Use --remote-workdir with a writable directory that allows execution"
+    exit 10
+fi
+STAGE_DIR=$(mktemp -d "$WORKDIR/opsmaster.XXXXXX") || exit 10
+trap 'rm -rf "$STAGE_DIR"' EXIT
+echo "✓ Staging files in ${STAGE_DIR}"
+`, candidates, candidates)
+}
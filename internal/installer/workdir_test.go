@@ -0,0 +1,80 @@
+package installer
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestValidateRemoteWorkdir tests validation of --remote-workdir values
+func TestValidateRemoteWorkdir(t *testing.T) {
+	tests := []struct {
+		dir     string
+		wantErr bool
+	}{
+		{dir: "", wantErr: false},
+		{dir: "/var/lib/opsmaster", wantErr: false},
+		{dir: "relative/dir", wantErr: true},
+		{dir: "/tmp/with space", wantErr: true},
+		{dir: "/tmp/$(reboot)", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.dir, func(t *testing.T) {
+			err := ValidateRemoteWorkdir(tt.dir)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateRemoteWorkdir(%q) error = %v, wantErr %v", tt.dir, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestGenerateWorkdirScript tests candidate selection in the generated script
+func TestGenerateWorkdirScript(t *testing.T) {
+	auto := NewPuppetInstaller(PuppetOptions{Server: "puppet.example.com"}).generateWorkdirScript()
+	if !strings.Contains(auto, "for CANDIDATE in /tmp /var/lib/opsmaster; do") {
+		t.Errorf("expected /tmp with /var/lib/opsmaster fallback, got:\n%s", auto)
+	}
+
+	custom := NewPuppetInstaller(PuppetOptions{Server: "puppet.example.com", RemoteWorkdir: "/srv/ops"}).generateWorkdirScript()
+	if !strings.Contains(custom, "for CANDIDATE in /srv/ops; do") {
+		t.Errorf("expected only the configured workdir, got:\n%s", custom)
+	}
+}
+
+// TestGenerateWorkdirScript_StagesAndCleansUp runs the generated script locally.
+//
+// 🎓 CONCEPT: Executing generated shell
+// The staging directory must exist during the script and be removed on exit.
+func TestGenerateWorkdirScript_StagesAndCleansUp(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+
+	// ARRANGE
+	workdir := t.TempDir()
+	marker := filepath.Join(t.TempDir(), "stage_dir")
+	installer := NewPuppetInstaller(PuppetOptions{Server: "puppet.example.com", RemoteWorkdir: workdir})
+	script := installer.generateWorkdirScript() + `echo "$STAGE_DIR" > ` + marker + "\n" + `touch "$STAGE_DIR/package.deb"` + "\n"
+
+	// ACT
+	out, err := exec.Command("sh", "-c", script).CombinedOutput()
+
+	// ASSERT
+	if err != nil {
+		t.Fatalf("script failed: %v\n%s", err, out)
+	}
+	stageDir, err := os.ReadFile(marker)
+	if err != nil {
+		t.Fatalf("STAGE_DIR not recorded: %v", err)
+	}
+	dir := strings.TrimSpace(string(stageDir))
+	if !strings.HasPrefix(dir, workdir+"/opsmaster.") {
+		t.Errorf("STAGE_DIR = %s, want under %s", dir, workdir)
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("expected %s to be removed on exit, stat err = %v", dir, err)
+	}
+}
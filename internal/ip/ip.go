@@ -7,9 +7,10 @@ import (
 	"fmt"
 	"net"
 	"net/http"
-	"time"
 
 	"github.com/jackpal/gateway"
+
+	"github.com/estudosdevops/opsmaster/internal/httpclient"
 )
 
 // LocalNetworkInfo agrupa as informações de uma interface de rede local.
@@ -36,10 +37,8 @@ type PublicIPInfo struct {
 // Agora aceita um contexto para controle de timeout e cancelamento.
 func FetchPublicIP(ctx context.Context) (*PublicIPInfo, error) {
 	const serviceURL = "https://ipinfo.io/json"
-	client := http.Client{
-		// O timeout do cliente ainda é uma boa prática como um fallback geral.
-		Timeout: 10 * time.Second,
-	}
+	// Cliente compartilhado: respeita proxy, --ca-bundle, timeouts e retries.
+	client := httpclient.Shared()
 
 	// Cria a requisição com o contexto.
 	req, err := http.NewRequestWithContext(ctx, "GET", serviceURL, http.NoBody)
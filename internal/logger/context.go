@@ -0,0 +1,31 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+)
+
+// contextKey is the context key of the logger carried by a context.
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying log. Workers attach a child
+// logger with their fields (e.g., instance_id, account, region) once, and
+// everything called with that context logs them without repeating them.
+func NewContext(ctx context.Context, log *slog.Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, log)
+}
+
+// FromContext returns the logger carried by ctx, or the global logger (Get)
+// if there is none.
+func FromContext(ctx context.Context) *slog.Logger {
+	if log, ok := ctx.Value(contextKey{}).(*slog.Logger); ok {
+		return log
+	}
+	return Get()
+}
+
+// With returns a copy of ctx whose logger has args added to every record.
+// Example: ctx = logger.With(ctx, "instance_id", instance.ID)
+func With(ctx context.Context, args ...any) context.Context {
+	return NewContext(ctx, FromContext(ctx).With(args...))
+}
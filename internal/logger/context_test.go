@@ -0,0 +1,59 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"strings"
+	"testing"
+)
+
+// TestFromContext tests that context loggers keep the fields of their parents
+//
+// 🎓 CONCEPT: Context-scoped logger
+// A worker attaches its fields once (logger.With); functions deeper in the
+// call chain only need ctx to log them.
+func TestFromContext(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+	}{
+		{name: "text", format: "text"},
+		{name: "json", format: "json"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// ARRANGE
+			var buf bytes.Buffer
+			SetOutput(&buf)
+			if err := SetFormat(tt.format); err != nil {
+				t.Fatal(err)
+			}
+			t.Cleanup(func() {
+				SetFormat("text")
+				SetOutput(os.Stdout)
+			})
+			ctx := With(context.Background(), "instance_id", "i-123")
+			ctx = With(ctx, "region", "us-east-1")
+
+			// ACT
+			FromContext(ctx).Info("Installing package", "package", "puppet")
+
+			// ASSERT
+			line := buf.String()
+			for _, want := range []string{"i-123", "us-east-1", "puppet"} {
+				if !strings.Contains(line, want) {
+					t.Errorf("log line %q missing %q", line, want)
+				}
+			}
+		})
+	}
+}
+
+// TestFromContext_Default tests the fallback to the global logger
+func TestFromContext_Default(t *testing.T) {
+	if FromContext(context.Background()) != Get() {
+		t.Error("expected global logger for context without logger")
+	}
+}
@@ -141,7 +141,13 @@ func createLogger(config *Configuration) *slog.Logger {
 		}
 	}
 
-	return slog.New(handler)
+	// Redact registered secrets (e.g., Vault values) from every record
+	log := slog.New(&redactHandler{next: handler})
+	// run_id on every line is only useful for machine-read (JSON) logs
+	if runID != "" && config.Format == "json" {
+		log = log.With("run_id", runID)
+	}
+	return log
 }
 
 // SetLevel configures the minimum log level
@@ -187,6 +193,7 @@ func SetOutput(output io.Writer) {
 type CustomTextHandler struct {
 	level  slog.Level
 	output io.Writer
+	attrs  string // Pre-formatted attributes of child loggers (With)
 }
 
 // Get retorna uma instância pré-configurada do logger slog com configuração global.
@@ -282,7 +289,7 @@ func (h *CustomTextHandler) Handle(ctx context.Context, r slog.Record) error {
 	levelStr := colorizeLevel(r.Level, r.Level.String())
 
 	// Monta a string de atributos (chave=valor) que vêm depois da mensagem.
-	attrs := ""
+	attrs := h.attrs
 	r.Attrs(func(a slog.Attr) bool {
 		attrs = attrs + " " + color.CyanString(a.Key+"=") + a.Value.String()
 		return true
@@ -303,10 +310,16 @@ func (h *CustomTextHandler) Handle(ctx context.Context, r slog.Record) error {
 }
 
 // WithAttrs e WithGroup são necessários para implementar a interface slog.Handler.
+// WithAttrs mantém os atributos dos loggers filhos (ex: instance_id por instância).
 func (h *CustomTextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	formatted := h.attrs
+	for _, a := range attrs {
+		formatted = formatted + " " + color.CyanString(a.Key+"=") + a.Value.String()
+	}
 	return &CustomTextHandler{
 		level:  h.level,
 		output: h.output,
+		attrs:  formatted,
 	}
 }
 
@@ -314,6 +327,7 @@ func (h *CustomTextHandler) WithGroup(name string) slog.Handler {
 	return &CustomTextHandler{
 		level:  h.level,
 		output: h.output,
+		attrs:  h.attrs,
 	}
 }
 
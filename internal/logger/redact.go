@@ -0,0 +1,117 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"sync"
+
+	"github.com/estudosdevops/opsmaster/internal/ascii"
+)
+
+// redactedValue replaces registered secrets in log output.
+const redactedValue = "***"
+
+// secretValues holds values that must never appear in logs (e.g., resolved
+// Vault secrets). Guarded by secretsMu; read on every log record.
+var (
+	secretsMu    sync.RWMutex
+	secretValues []string
+)
+
+// RegisterSecret marks a value as secret: any occurrence in log messages or
+// attributes is replaced by "***". Empty values are ignored.
+func RegisterSecret(value string) {
+	if value == "" {
+		return
+	}
+
+	secretsMu.Lock()
+	defer secretsMu.Unlock()
+	for _, existing := range secretValues {
+		if existing == value {
+			return
+		}
+	}
+	secretValues = append(secretValues, value)
+}
+
+// Redact replaces registered secrets in s.
+func Redact(s string) string {
+	secretsMu.RLock()
+	defer secretsMu.RUnlock()
+	for _, secret := range secretValues {
+		s = strings.ReplaceAll(s, secret, redactedValue)
+	}
+	return s
+}
+
+// hasSecrets reports whether any secret was registered.
+func hasSecrets() bool {
+	secretsMu.RLock()
+	defer secretsMu.RUnlock()
+	return len(secretValues) > 0
+}
+
+// scrub redacts secrets in s and, in plain-ASCII mode (--ascii), rewrites
+// emoji and other non-ASCII characters.
+func scrub(s string) string {
+	s = Redact(s)
+	if ascii.Enabled() {
+		s = ascii.Plain(s)
+	}
+	return s
+}
+
+// redactHandler wraps a handler, redacting registered secrets before output
+// (and rewriting records to plain ASCII in --ascii mode).
+type redactHandler struct {
+	next slog.Handler
+}
+
+func (h *redactHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *redactHandler) Handle(ctx context.Context, r slog.Record) error {
+	if !hasSecrets() && !ascii.Enabled() {
+		return h.next.Handle(ctx, r)
+	}
+
+	redacted := slog.NewRecord(r.Time, r.Level, scrub(r.Message), r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		redacted.AddAttrs(redactAttr(a))
+		return true
+	})
+	return h.next.Handle(ctx, redacted)
+}
+
+func (h *redactHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	redacted := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		redacted[i] = redactAttr(a)
+	}
+	return &redactHandler{next: h.next.WithAttrs(redacted)}
+}
+
+func (h *redactHandler) WithGroup(name string) slog.Handler {
+	return &redactHandler{next: h.next.WithGroup(name)}
+}
+
+// redactAttr redacts string-like attribute values (strings, errors, stringers).
+func redactAttr(a slog.Attr) slog.Attr {
+	value := a.Value.Resolve()
+	switch value.Kind() {
+	case slog.KindString, slog.KindAny:
+		return slog.String(a.Key, scrub(value.String()))
+	case slog.KindGroup:
+		group := value.Group()
+		redacted := make([]any, len(group))
+		for i, ga := range group {
+			redacted[i] = redactAttr(ga)
+		}
+		return slog.Group(a.Key, redacted...)
+	default:
+		return a
+	}
+}
@@ -0,0 +1,22 @@
+package logger
+
+// runID identifies the current invocation. Guarded by mutex.
+var runID string
+
+// SetRunID sets the ID of the current invocation. JSON logs carry it as
+// the run_id attribute on every record, so log lines can be correlated
+// with SSM commands, instance tags and reports from the same run.
+func SetRunID(id string) {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	runID = id
+	globalLogger = createLogger(globalConfig)
+}
+
+// RunID returns the ID of the current invocation ("" if not set).
+func RunID() string {
+	mutex.RLock()
+	defer mutex.RUnlock()
+	return runID
+}
@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/estudosdevops/opsmaster/internal/httpclient"
 	"github.com/estudosdevops/opsmaster/internal/logger"
 )
 
@@ -16,7 +17,8 @@ const defaultCheckTimeout = 10 * time.Second
 // checkURL realiza uma única verificação HTTP na URL fornecida.
 func checkURL(ctx context.Context, url string) string {
 	startTime := time.Now()
-	client := http.Client{}
+	// Sem retries: o monitor deve reportar cada tentativa como ela ocorreu.
+	client := httpclient.SharedNoRetry()
 
 	// Cria a requisição com o contexto.
 	req, err := http.NewRequestWithContext(ctx, "GET", url, http.NoBody)
@@ -0,0 +1,280 @@
+// Package pipeline runs named pipelines from the config file: ordered stages
+// that reuse the opsmaster commands as stage types, so a nightly baseline
+// (preflight → install puppet → install an exporter → verify → notify) is
+// one command instead of a wrapper script. Example:
+//
+//	pipelines:
+//	  nightly-baseline:
+//	    stages:
+//	      - name: preflight
+//	        command: install puppet
+//	        profile: prod-puppet
+//	        flags: {dry-run: true}
+//	      - name: puppet
+//	        command: install puppet
+//	        profile: prod-puppet
+//	      - name: node-exporter
+//	        command: install systemd-unit
+//	        flags: {unit-file: node_exporter.service, binary-url: https://...}
+//	      - name: verify
+//	        command: coverage
+//	        flags: {expect: [puppet=true]}
+//	      - name: notify
+//	        command: notify
+//	        always: true
+//	        flags: {events-arn: "arn:aws:sns:us-east-1:111111111111:ops"}
+//
+// Stage flags are flag names of the stage command, with the same value rules
+// as flag profiles (see profile.Apply); the instances file of the pipeline
+// run is passed to every command stage.
+package pipeline
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// NotifyCommand is the built-in stage type publishing the pipeline summary
+// (see Event) to an SNS topic or EventBridge bus.
+const NotifyCommand = "notify"
+
+// Commands maps the opsmaster commands usable as stage types, the commands
+// that process the instances of a CSV file, to the flag receiving the
+// instances file of the pipeline run.
+var Commands = map[string]string{
+	"install puppet":       "instances-file",
+	"install fluent-bit":   "instances-file",
+	"install osquery":      "instances-file",
+	"install systemd-unit": "instances-file",
+	"install teleport":     "instances-file",
+	"run script":           "instances-file",
+	"facts get":            "instances-file",
+	"puppet reconcile":     "instances-file",
+	"puppet regen-cert":    "instances-file",
+	"reboot":               "instances-file",
+	"ec2 start":            "instances-file",
+	"ec2 stop":             "instances-file",
+	"coverage":             "inventory",
+}
+
+// Pipeline is a named sequence of stages.
+type Pipeline struct {
+	Name        string
+	Description string
+	Stages      []Stage
+}
+
+// Stage is one step of a pipeline.
+type Stage struct {
+	Name            string         // Stage name used in logs and the summary (default: the command)
+	Command         string         // A key of Commands or NotifyCommand
+	Profile         string         // Flag profile applied to the command (optional, see package profile)
+	Flags           map[string]any // Flag values of the command, applied over the profile
+	ContinueOnError bool           // Run the next stages even if this one fails
+	Always          bool           // Run even after a failed stage (e.g., notify)
+}
+
+// Lookup returns pipeline name from the pipelines section of the config.
+// Pipeline names are case-insensitive.
+func Lookup(pipelines map[string]any, name string) (*Pipeline, error) {
+	for key, value := range pipelines {
+		if strings.EqualFold(key, name) {
+			return Parse(key, value)
+		}
+	}
+
+	names := Names(pipelines)
+	if len(names) == 0 {
+		return nil, fmt.Errorf("pipeline %q not found: no pipelines in the config file", name)
+	}
+	return nil, fmt.Errorf("pipeline %q not found (available: %s)", name, strings.Join(names, ", "))
+}
+
+// Names returns the pipeline names of the pipelines section, sorted.
+func Names(pipelines map[string]any) []string {
+	names := make([]string, 0, len(pipelines))
+	for key := range pipelines {
+		names = append(names, key)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Parse decodes and validates the config value of a pipeline.
+func Parse(name string, value any) (*Pipeline, error) {
+	fields, ok := value.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("pipeline %q: expected a map with stages", name)
+	}
+	p := &Pipeline{Name: name}
+	for key, v := range fields {
+		switch key {
+		case "description":
+			p.Description = fmt.Sprint(v)
+		case "stages":
+			items, ok := v.([]any)
+			if !ok {
+				return nil, fmt.Errorf("pipeline %q: stages must be a list", name)
+			}
+			for i, item := range items {
+				stage, err := parseStage(item)
+				if err != nil {
+					return nil, fmt.Errorf("pipeline %q: stage %d: %w", name, i+1, err)
+				}
+				p.Stages = append(p.Stages, stage)
+			}
+		default:
+			return nil, fmt.Errorf("pipeline %q: unknown field %q (supported: description, stages)", name, key)
+		}
+	}
+	if len(p.Stages) == 0 {
+		return nil, fmt.Errorf("pipeline %q has no stages", name)
+	}
+
+	seen := make(map[string]bool, len(p.Stages))
+	for _, stage := range p.Stages {
+		if seen[stage.Name] {
+			return nil, fmt.Errorf("pipeline %q: duplicate stage name %q", name, stage.Name)
+		}
+		seen[stage.Name] = true
+	}
+	return p, nil
+}
+
+// parseStage decodes and validates one stage.
+func parseStage(value any) (Stage, error) {
+	fields, ok := value.(map[string]any)
+	if !ok {
+		return Stage{}, fmt.Errorf("expected a map with a command")
+	}
+
+	var stage Stage
+	for key, v := range fields {
+		var err error
+		switch key {
+		case "name":
+			stage.Name = fmt.Sprint(v)
+		case "command":
+			stage.Command = strings.Join(strings.Fields(fmt.Sprint(v)), " ")
+		case "profile":
+			stage.Profile = fmt.Sprint(v)
+		case "flags":
+			if stage.Flags, ok = v.(map[string]any); !ok {
+				err = fmt.Errorf("flags must be a map of flag values")
+			}
+		case "continue_on_error":
+			stage.ContinueOnError, err = parseBool(key, v)
+		case "always":
+			stage.Always, err = parseBool(key, v)
+		default:
+			err = fmt.Errorf("unknown field %q (supported: name, command, profile, flags, continue_on_error, always)", key)
+		}
+		if err != nil {
+			return Stage{}, err
+		}
+	}
+
+	if stage.Command == "" {
+		return Stage{}, fmt.Errorf("command is required")
+	}
+	if stage.Name == "" {
+		stage.Name = stage.Command
+	}
+	if err := stage.validate(); err != nil {
+		return Stage{}, fmt.Errorf("%s: %w", stage.Name, err)
+	}
+	return stage, nil
+}
+
+// validate checks the stage type and its flags.
+func (s Stage) validate() error {
+	if s.Command == NotifyCommand {
+		if s.Profile != "" {
+			return fmt.Errorf("notify stages don't take a profile")
+		}
+		for name := range s.Flags {
+			if name != "events-arn" && name != "aws-profile" {
+				return fmt.Errorf("unknown notify flag %q (supported: events-arn, aws-profile)", name)
+			}
+		}
+		if arn, _ := s.Flags["events-arn"].(string); arn == "" {
+			return fmt.Errorf("notify stages require the events-arn flag")
+		}
+		return nil
+	}
+
+	instancesFlag, ok := Commands[s.Command]
+	if !ok {
+		commands := slices.Sorted(maps.Keys(Commands))
+		return fmt.Errorf("unsupported command %q (supported: %s, %s)", s.Command, strings.Join(commands, ", "), NotifyCommand)
+	}
+	if _, ok := s.Flags[instancesFlag]; ok {
+		return fmt.Errorf("--%s is set by the pipeline run, not per stage", instancesFlag)
+	}
+	for name, value := range s.Flags {
+		if _, err := flagArgs(name, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Args returns the opsmaster arguments running a command stage on
+// instancesFile: the command words, the instances file, the profile and the
+// stage flags (sorted, lists as repeated flags).
+func (s Stage) Args(instancesFile string) []string {
+	args := strings.Fields(s.Command)
+	args = append(args, "--"+Commands[s.Command]+"="+instancesFile)
+	if s.Profile != "" {
+		args = append(args, "--profile="+s.Profile)
+	}
+
+	names := make([]string, 0, len(s.Flags))
+	for name := range s.Flags {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		values, _ := flagArgs(name, s.Flags[name]) // Checked by validate
+		args = append(args, values...)
+	}
+	return args
+}
+
+// flagArgs converts a flag value to --name=value arguments, one per item of
+// a list.
+func flagArgs(name string, value any) ([]string, error) {
+	var items []any
+	switch v := value.(type) {
+	case nil:
+		return nil, fmt.Errorf("flag --%s: empty value", name)
+	case map[string]any:
+		return nil, fmt.Errorf("flag --%s: expected a value or a list, got a map", name)
+	case []any:
+		items = v
+	default:
+		items = []any{v}
+	}
+
+	args := make([]string, 0, len(items))
+	for _, item := range items {
+		switch item.(type) {
+		case []any, map[string]any, nil:
+			return nil, fmt.Errorf("flag --%s: list items must be scalars", name)
+		}
+		args = append(args, fmt.Sprintf("--%s=%v", name, item))
+	}
+	return args, nil
+}
+
+// parseBool decodes a boolean field.
+func parseBool(key string, value any) (bool, error) {
+	b, ok := value.(bool)
+	if !ok {
+		return false, fmt.Errorf("%s must be true or false", key)
+	}
+	return b, nil
+}
@@ -0,0 +1,93 @@
+package pipeline
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestLookup tests finding and parsing pipelines of the config.
+func TestLookup(t *testing.T) {
+	pipelines := map[string]any{
+		"nightly-baseline": map[string]any{
+			"description": "Puppet and exporters",
+			"stages": []any{
+				map[string]any{"name": "preflight", "command": "install puppet", "profile": "prod-puppet", "flags": map[string]any{"dry-run": true}},
+				map[string]any{"command": "install  systemd-unit", "continue_on_error": true},
+				map[string]any{"command": "notify", "always": true, "flags": map[string]any{"events-arn": "arn:aws:sns:us-east-1:111111111111:ops"}},
+			},
+		},
+		"weekly": map[string]any{"stages": []any{map[string]any{"command": "reboot"}}},
+	}
+
+	p, err := Lookup(pipelines, "Nightly-Baseline")
+	if err != nil {
+		t.Fatalf("Lookup() unexpected error: %v", err)
+	}
+	if p.Name != "nightly-baseline" || p.Description != "Puppet and exporters" || len(p.Stages) != 3 {
+		t.Fatalf("Lookup() = %+v", p)
+	}
+	if stage := p.Stages[1]; stage.Name != "install systemd-unit" || stage.Command != "install systemd-unit" || !stage.ContinueOnError {
+		t.Errorf("stage without name = %+v, want named after the normalized command", stage)
+	}
+	if !p.Stages[2].Always {
+		t.Error("notify stage should run always")
+	}
+
+	_, err = Lookup(pipelines, "missing")
+	if err == nil || !strings.Contains(err.Error(), "available: nightly-baseline, weekly") {
+		t.Errorf("Lookup(missing) error = %v, want the available pipelines", err)
+	}
+	if _, err := Lookup(nil, "missing"); err == nil || !strings.Contains(err.Error(), "no pipelines") {
+		t.Errorf("Lookup() without pipelines error = %v", err)
+	}
+}
+
+// TestParse_Invalid tests rejection of invalid pipelines.
+func TestParse_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   any
+		wantErr string
+	}{
+		{"not a map", "install puppet", "expected a map"},
+		{"no stages", map[string]any{}, "has no stages"},
+		{"unknown field", map[string]any{"stage": []any{}}, `unknown field "stage"`},
+		{"stage without command", map[string]any{"stages": []any{map[string]any{"name": "x"}}}, "command is required"},
+		{"unsupported command", map[string]any{"stages": []any{map[string]any{"command": "nelm install"}}}, `unsupported command "nelm install"`},
+		{"instances file per stage", map[string]any{"stages": []any{map[string]any{"command": "reboot", "flags": map[string]any{"instances-file": "a.csv"}}}}, "set by the pipeline run"},
+		{"inventory of coverage", map[string]any{"stages": []any{map[string]any{"command": "coverage", "flags": map[string]any{"inventory": "a.csv"}}}}, "--inventory is set by the pipeline run"},
+		{"map flag value", map[string]any{"stages": []any{map[string]any{"command": "reboot", "flags": map[string]any{"where": map[string]any{"a": "b"}}}}}, "got a map"},
+		{"notify without target", map[string]any{"stages": []any{map[string]any{"command": "notify"}}}, "require the events-arn flag"},
+		{"notify unknown flag", map[string]any{"stages": []any{map[string]any{"command": "notify", "flags": map[string]any{"events-arn": "arn", "dry-run": true}}}}, `unknown notify flag "dry-run"`},
+		{"duplicate names", map[string]any{"stages": []any{map[string]any{"command": "reboot"}, map[string]any{"command": "reboot"}}}, "duplicate stage name"},
+		{"string boolean", map[string]any{"stages": []any{map[string]any{"command": "reboot", "always": "yes"}}}, "always must be true or false"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse("p", tt.value)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Parse() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestStage_Args tests the command line of a command stage.
+func TestStage_Args(t *testing.T) {
+	stage := Stage{
+		Command: "install puppet",
+		Profile: "prod-puppet",
+		Flags: map[string]any{
+			"where":           []any{"env=prod", "tier!=db"},
+			"dry-run":         true,
+			"max-concurrency": 20,
+		},
+	}
+
+	got := strings.Join(stage.Args("fleet.csv"), " ")
+	want := "install puppet --instances-file=fleet.csv --profile=prod-puppet --dry-run=true --max-concurrency=20 --where=env=prod --where=tier!=db"
+	if got != want {
+		t.Errorf("Args() = %q, want %q", got, want)
+	}
+}
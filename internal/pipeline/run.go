@@ -0,0 +1,101 @@
+package pipeline
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// Stage outcomes in StageResult.Status.
+const (
+	StatusSuccess = "success"
+	StatusFailed  = "failed"
+	StatusSkipped = "skipped" // Not run after an earlier failure
+)
+
+// EventDetailType identifies pipeline completion events published by notify
+// stages (EventBridge DetailType, SNS Subject).
+const EventDetailType = "opsmaster Pipeline Completed"
+
+// StageRunner runs one stage. Command stages run the opsmaster command,
+// notify stages publish the summary of the stages run so far (see Event).
+type StageRunner func(ctx context.Context, stage Stage, done []StageResult) error
+
+// StageResult is the outcome of one stage.
+type StageResult struct {
+	Name     string        `json:"name"`
+	Command  string        `json:"command"`
+	Status   string        `json:"status"`
+	Error    string        `json:"error,omitempty"`
+	Duration time.Duration `json:"-"`
+}
+
+// Result is the outcome of a pipeline run.
+type Result struct {
+	Pipeline string        `json:"pipeline"`
+	Stages   []StageResult `json:"stages"`
+}
+
+// Failed reports whether any stage failed.
+func (r *Result) Failed() bool {
+	for _, stage := range r.Stages {
+		if stage.Status == StatusFailed {
+			return true
+		}
+	}
+	return false
+}
+
+// Run runs the stages of p in order with run. After a failed stage (unless
+// it continues on error) the remaining stages are skipped, except those
+// marked Always. A canceled ctx skips every stage not started yet.
+func Run(ctx context.Context, p *Pipeline, run StageRunner) *Result {
+	result := &Result{Pipeline: p.Name, Stages: make([]StageResult, 0, len(p.Stages))}
+	halted := false
+	for _, stage := range p.Stages {
+		stageResult := StageResult{Name: stage.Name, Command: stage.Command}
+		if ctx.Err() != nil || halted && !stage.Always {
+			stageResult.Status = StatusSkipped
+			result.Stages = append(result.Stages, stageResult)
+			continue
+		}
+
+		start := time.Now()
+		err := run(ctx, stage, result.Stages)
+		stageResult.Duration = time.Since(start)
+		stageResult.Status = StatusSuccess
+		if err != nil {
+			stageResult.Status = StatusFailed
+			stageResult.Error = err.Error()
+			halted = halted || !stage.ContinueOnError
+		}
+		result.Stages = append(result.Stages, stageResult)
+	}
+	return result
+}
+
+// PipelineEvent is the payload published by notify stages.
+type PipelineEvent struct {
+	Pipeline string         `json:"pipeline"`
+	RunID    string         `json:"run_id,omitempty"`
+	Status   string         `json:"status"` // success or failed
+	Stages   []StageSummary `json:"stages"`
+}
+
+// StageSummary is a stage in a PipelineEvent.
+type StageSummary struct {
+	StageResult
+	DurationSeconds float64 `json:"duration_seconds"`
+}
+
+// Event returns the JSON payload of a notify stage: the stages run before it.
+func Event(pipeline, runID string, done []StageResult) ([]byte, error) {
+	event := PipelineEvent{Pipeline: pipeline, RunID: runID, Status: StatusSuccess, Stages: make([]StageSummary, 0, len(done))}
+	for _, stage := range done {
+		if stage.Status == StatusFailed {
+			event.Status = StatusFailed
+		}
+		event.Stages = append(event.Stages, StageSummary{StageResult: stage, DurationSeconds: stage.Duration.Round(10 * time.Millisecond).Seconds()})
+	}
+	return json.Marshal(event)
+}
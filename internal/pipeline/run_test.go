@@ -0,0 +1,101 @@
+package pipeline
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// TestRun tests stage ordering, halting after failures and always stages.
+func TestRun(t *testing.T) {
+	tests := []struct {
+		name   string
+		stages []Stage
+		fail   string // Stage failing
+		want   string // name=status of each stage
+	}{
+		{
+			name:   "all succeed",
+			stages: []Stage{{Name: "a"}, {Name: "b"}},
+			want:   "a=success b=success",
+		},
+		{
+			name:   "failure skips the rest but always stages",
+			stages: []Stage{{Name: "a"}, {Name: "b"}, {Name: "notify", Always: true}},
+			fail:   "a",
+			want:   "a=failed b=skipped notify=success",
+		},
+		{
+			name:   "continue on error",
+			stages: []Stage{{Name: "a", ContinueOnError: true}, {Name: "b"}},
+			fail:   "a",
+			want:   "a=failed b=success",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			position := make(map[string]int, len(tt.stages))
+			for i, stage := range tt.stages {
+				position[stage.Name] = i
+			}
+			result := Run(context.Background(), &Pipeline{Name: "p", Stages: tt.stages}, func(_ context.Context, stage Stage, done []StageResult) error {
+				// Skipped stages are reported too (e.g., by notify stages)
+				if len(done) != position[stage.Name] {
+					t.Errorf("stage %s got %d earlier results, want %d", stage.Name, len(done), position[stage.Name])
+				}
+				if stage.Name == tt.fail {
+					return errors.New("exit status 1")
+				}
+				return nil
+			})
+
+			var got []string
+			for _, stage := range result.Stages {
+				got = append(got, stage.Name+"="+stage.Status)
+			}
+			if strings.Join(got, " ") != tt.want {
+				t.Errorf("Run() = %v, want %s", got, tt.want)
+			}
+			if result.Failed() != (tt.fail != "") {
+				t.Errorf("Failed() = %v", result.Failed())
+			}
+		})
+	}
+}
+
+// TestRun_Canceled tests that stages are skipped once ctx is canceled.
+func TestRun_Canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	result := Run(ctx, &Pipeline{Stages: []Stage{{Name: "a"}, {Name: "b", Always: true}}}, func(context.Context, Stage, []StageResult) error {
+		cancel()
+		return nil
+	})
+	if result.Stages[0].Status != StatusSuccess || result.Stages[1].Status != StatusSkipped {
+		t.Errorf("Run() = %+v, want the second stage skipped", result.Stages)
+	}
+}
+
+// TestEvent tests the payload of notify stages.
+func TestEvent(t *testing.T) {
+	detail, err := Event("nightly", "run-1", []StageResult{
+		{Name: "puppet", Command: "install puppet", Status: StatusSuccess},
+		{Name: "exporter", Command: "install systemd-unit", Status: StatusFailed, Error: "exit status 1"},
+	})
+	if err != nil {
+		t.Fatalf("Event() unexpected error: %v", err)
+	}
+
+	var event PipelineEvent
+	if err := json.Unmarshal(detail, &event); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if event.Pipeline != "nightly" || event.RunID != "run-1" || event.Status != StatusFailed || len(event.Stages) != 2 {
+		t.Errorf("Event() = %+v", event)
+	}
+	if event.Stages[1].Error != "exit status 1" {
+		t.Errorf("stage error = %q", event.Stages[1].Error)
+	}
+}
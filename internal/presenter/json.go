@@ -0,0 +1,31 @@
+package presenter
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// Output formats accepted by commands with an --output flag.
+const (
+	OutputTable = "table"
+	OutputJSON  = "json"
+)
+
+// ValidateOutputFormat checks an --output value.
+func ValidateOutputFormat(format string) error {
+	switch format {
+	case OutputTable, OutputJSON:
+		return nil
+	default:
+		return fmt.Errorf("invalid output format: %s (valid: %s, %s)", format, OutputTable, OutputJSON)
+	}
+}
+
+// PrintJSON prints data as indented JSON to the console.
+// Used by the --output json mode, meant for scripts and pipelines (e.g., jq).
+func PrintJSON(data any) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(data)
+}
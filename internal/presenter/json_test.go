@@ -0,0 +1,49 @@
+package presenter
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"testing"
+)
+
+// TestPrintJSON testa a impressão de dados em JSON.
+func TestPrintJSON(t *testing.T) {
+	// Captura a saída padrão (stdout), como em TestPrintTable.
+	oldStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	err := PrintJSON([]map[string]string{{"nome": "servico-a", "status": "Healthy"}})
+
+	w.Close()
+	os.Stdout = oldStdout
+	var buf bytes.Buffer
+	if _, copyErr := io.Copy(&buf, r); copyErr != nil {
+		t.Fatalf("Erro ao ler do cano: %v", copyErr)
+	}
+
+	if err != nil {
+		t.Fatalf("PrintJSON() retornou erro: %v", err)
+	}
+	var decoded []map[string]string
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("A saída não é um JSON válido: %v\n%s", err, buf.String())
+	}
+	if len(decoded) != 1 || decoded[0]["status"] != "Healthy" {
+		t.Errorf("Conteúdo inesperado: %v", decoded)
+	}
+}
+
+// TestValidateOutputFormat testa os formatos aceitos em --output.
+func TestValidateOutputFormat(t *testing.T) {
+	for _, format := range []string{OutputTable, OutputJSON} {
+		if err := ValidateOutputFormat(format); err != nil {
+			t.Errorf("ValidateOutputFormat(%q) retornou erro: %v", format, err)
+		}
+	}
+	if err := ValidateOutputFormat("yaml"); err == nil {
+		t.Error("ValidateOutputFormat(\"yaml\") deveria retornar erro")
+	}
+}
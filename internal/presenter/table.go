@@ -6,6 +6,9 @@ import (
 	"github.com/olekukonko/tablewriter"
 	"github.com/olekukonko/tablewriter/renderer"
 	"github.com/olekukonko/tablewriter/tw"
+
+	"github.com/estudosdevops/opsmaster/internal/ascii"
+	"github.com/estudosdevops/opsmaster/internal/i18n"
 )
 
 // PrintTable formats and prints data in a table to the console with rounded Unicode borders.
@@ -22,11 +25,32 @@ import (
 // This implementation uses tablewriter.NewTable (modern API) instead of
 // tablewriter.NewWriter (legacy API) to access advanced rendering features
 // like rounded corners via tw.StyleRounded symbols.
+//
+// In plain-ASCII mode (--ascii) borders use +, - and | and cells are
+// rewritten with ascii.Plain (e.g., ✅ → [OK]). Header cells are translated
+// to the --lang language (i18n.T); rows are data and kept as they are.
 func PrintTable(header []string, rows [][]string) {
+	translated := make([]string, len(header))
+	for i, cell := range header {
+		translated[i] = i18n.T(cell)
+	}
+	header = translated
+
+	style := tw.StyleRounded
+	if ascii.Enabled() {
+		style = tw.StyleASCII
+		header = plainCells(header)
+		plainRows := make([][]string, len(rows))
+		for i, row := range rows {
+			plainRows[i] = plainCells(row)
+		}
+		rows = plainRows
+	}
+
 	// Create table with rounded border style
 	table := tablewriter.NewTable(os.Stdout,
 		tablewriter.WithRenderer(renderer.NewBlueprint(tw.Rendition{
-			Symbols: tw.NewSymbols(tw.StyleRounded),
+			Symbols: tw.NewSymbols(style),
 		})),
 		tablewriter.WithConfig(tablewriter.Config{
 			Header: tw.CellConfig{
@@ -50,3 +74,11 @@ func PrintTable(header []string, rows [][]string) {
 	// Render table
 	_ = table.Render() // Error explicitly ignored (writes to stdout)
 }
+
+// OrDash returns value, or "-" for an empty table cell.
+func OrDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
@@ -6,6 +6,9 @@ import (
 	"os"
 	"strings"
 	"testing"
+
+	"github.com/estudosdevops/opsmaster/internal/ascii"
+	"github.com/estudosdevops/opsmaster/internal/i18n"
 )
 
 // TestPrintTable testa a nossa função de impressão de tabelas.
@@ -44,3 +47,76 @@ func TestPrintTable(t *testing.T) {
 		t.Errorf("A segunda linha não foi impressa corretamente. Saída:\n%s", output)
 	}
 }
+
+// TestPrintTable_ASCII testa a tabela no modo ASCII (--ascii).
+func TestPrintTable_ASCII(t *testing.T) {
+	ascii.Enable(true)
+	defer ascii.Enable(false)
+
+	oldStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	PrintTable([]string{"INSTANCE ID", "STATUS"}, [][]string{{"i-123", "✅"}, {"i-456", "⏭️"}})
+
+	w.Close()
+	os.Stdout = oldStdout
+	var buf bytes.Buffer
+	if _, err := io.Copy(&buf, r); err != nil {
+		t.Fatalf("Erro ao ler do cano: %v", err)
+	}
+	output := buf.String()
+
+	for _, want := range []string{"[OK]", "[SKIP]", "+-", "|"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Saída sem %q:\n%s", want, output)
+		}
+	}
+	for _, r := range output {
+		if r > 127 {
+			t.Fatalf("Saída com caractere não ASCII %q:\n%s", r, output)
+		}
+	}
+}
+
+// TestPrintTable_Lang testa a tradução do cabeçalho para o idioma de --lang.
+func TestPrintTable_Lang(t *testing.T) {
+	i18n.Set(i18n.English)
+	defer i18n.Set(i18n.Default)
+
+	oldStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	PrintTable([]string{"NOME", "DURAÇÃO"}, [][]string{{"servico-a", "1m"}})
+
+	w.Close()
+	os.Stdout = oldStdout
+	var buf bytes.Buffer
+	if _, err := io.Copy(&buf, r); err != nil {
+		t.Fatalf("Erro ao ler do cano: %v", err)
+	}
+	output := buf.String()
+
+	for _, want := range []string{"NAME", "DURATION", "servico-a"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Saída sem %q:\n%s", want, output)
+		}
+	}
+}
+
+func TestOrDash(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"", "-"},
+		{"i-123", "i-123"},
+		{" ", " "},
+	}
+	for _, tt := range tests {
+		if got := OrDash(tt.value); got != tt.want {
+			t.Errorf("OrDash(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package presenter
+
+import (
+	"fmt"
+
+	"github.com/estudosdevops/opsmaster/internal/ascii"
+	"github.com/estudosdevops/opsmaster/internal/i18n"
+)
+
+// Printf prints formatted text to stdout, with format translated to the
+// --lang language (i18n.T) and rewritten to plain ASCII in --ascii mode
+// (e.g., "📊 Summary" → "Summary"). Use it for console output outside tables.
+func Printf(format string, args ...any) {
+	fmt.Print(Text(fmt.Sprintf(i18n.T(format), args...)))
+}
+
+// Println is Printf's counterpart of fmt.Println. Only the first argument
+// (the message) is translated.
+func Println(args ...any) {
+	if len(args) > 0 {
+		if message, ok := args[0].(string); ok {
+			args = append([]any{i18n.T(message)}, args[1:]...)
+		}
+	}
+	fmt.Print(Text(fmt.Sprintln(args...)))
+}
+
+// Text returns s, rewritten to plain ASCII in --ascii mode.
+func Text(s string) string {
+	if ascii.Enabled() {
+		return ascii.Plain(s)
+	}
+	return s
+}
+
+// plainCells rewrites table cells to plain ASCII.
+func plainCells(cells []string) []string {
+	plain := make([]string, len(cells))
+	for i, cell := range cells {
+		plain[i] = ascii.Plain(cell)
+	}
+	return plain
+}
@@ -0,0 +1,47 @@
+package profile
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+// EnvPrefix prefixes the environment variables of flags
+// (--puppet-server → OPSMASTER_PUPPET_SERVER).
+const EnvPrefix = "OPSMASTER_"
+
+// EnvName returns the environment variable of a flag.
+func EnvName(flag string) string {
+	return EnvPrefix + strings.ToUpper(strings.ReplaceAll(flag, "-", "_"))
+}
+
+// ApplyEnv sets the flags not set on the command line from their
+// environment variables (see EnvName), read with lookup (os.LookupEnv), and
+// returns their names, sorted. Run it before Apply so the precedence is
+// command line > environment > config profile.
+//
+// Hidden flags (deprecated aliases, internal switches) are skipped.
+// Repeatable flags take the variable as a single value.
+func ApplyEnv(flags *pflag.FlagSet, lookup func(string) (string, bool)) ([]string, error) {
+	var applied []string
+	var err error
+	flags.VisitAll(func(flag *pflag.Flag) {
+		if err != nil || flag.Changed || flag.Hidden {
+			return
+		}
+		value, ok := lookup(EnvName(flag.Name))
+		if !ok {
+			return
+		}
+		if setErr := flags.Set(flag.Name, value); setErr != nil {
+			err = fmt.Errorf("%s: %w", EnvName(flag.Name), setErr)
+			return
+		}
+		applied = append(applied, flag.Name)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return applied, nil
+}
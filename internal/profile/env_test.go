@@ -0,0 +1,122 @@
+package profile
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestEnvName tests the environment variable names of flags
+func TestEnvName(t *testing.T) {
+	tests := []struct {
+		flag string
+		want string
+	}{
+		{"puppet-server", "OPSMASTER_PUPPET_SERVER"},
+		{"max-concurrency", "OPSMASTER_MAX_CONCURRENCY"},
+		{"timeout", "OPSMASTER_TIMEOUT"},
+	}
+
+	for _, tt := range tests {
+		if got := EnvName(tt.flag); got != tt.want {
+			t.Errorf("EnvName(%q) = %q, want %q", tt.flag, got, tt.want)
+		}
+	}
+}
+
+// TestApplyEnv tests setting flags from environment variables
+func TestApplyEnv(t *testing.T) {
+	tests := []struct {
+		name        string
+		args        []string
+		env         map[string]string
+		wantApplied string
+		want        map[string]string
+		expectError string
+	}{
+		{
+			name:        "sets flags from env",
+			env:         map[string]string{"OPSMASTER_PUPPET_SERVER": "puppet.ci", "OPSMASTER_MAX_CONCURRENCY": "5", "OPSMASTER_DRY_RUN": "true"},
+			wantApplied: "dry-run,max-concurrency,puppet-server",
+			want:        map[string]string{"puppet-server": "puppet.ci", "max-concurrency": "5", "dry-run": "true"},
+		},
+		{
+			name:        "command line wins",
+			args:        []string{"--max-concurrency", "50"},
+			env:         map[string]string{"OPSMASTER_MAX_CONCURRENCY": "5"},
+			wantApplied: "",
+			want:        map[string]string{"max-concurrency": "50"},
+		},
+		{
+			name:        "hidden flags ignored",
+			env:         map[string]string{"OPSMASTER_CHAOS": "1"},
+			wantApplied: "",
+			want:        map[string]string{"chaos": ""},
+		},
+		{
+			name:        "invalid value",
+			env:         map[string]string{"OPSMASTER_TIMEOUT": "soon"},
+			expectError: "OPSMASTER_TIMEOUT",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// ARRANGE
+			flags := newFlags()
+			flags.String("chaos", "", "")
+			_ = flags.MarkHidden("chaos")
+			if err := flags.Parse(tt.args); err != nil {
+				t.Fatalf("Parse() unexpected error: %v", err)
+			}
+			lookup := func(name string) (string, bool) {
+				value, ok := tt.env[name]
+				return value, ok
+			}
+
+			// ACT
+			applied, err := ApplyEnv(flags, lookup)
+
+			// ASSERT
+			if tt.expectError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectError) {
+					t.Fatalf("ApplyEnv() error = %v, want containing %q", err, tt.expectError)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ApplyEnv() unexpected error: %v", err)
+			}
+			if got := strings.Join(applied, ","); got != tt.wantApplied {
+				t.Errorf("ApplyEnv() applied = %q, want %q", got, tt.wantApplied)
+			}
+			for name, want := range tt.want {
+				if got := flags.Lookup(name).Value.String(); got != want {
+					t.Errorf("--%s = %q, want %q", name, got, want)
+				}
+			}
+		})
+	}
+}
+
+// TestApplyEnv_BeforeProfile tests the precedence command line > env > profile
+func TestApplyEnv_BeforeProfile(t *testing.T) {
+	flags := newFlags()
+	if err := flags.Parse([]string{"--environment", "staging"}); err != nil {
+		t.Fatal(err)
+	}
+	env := map[string]string{"OPSMASTER_PUPPET_SERVER": "puppet.env", "OPSMASTER_ENVIRONMENT": "env"}
+
+	if _, err := ApplyEnv(flags, func(name string) (string, bool) { v, ok := env[name]; return v, ok }); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Apply(flags, nil, map[string]any{"puppet-server": "puppet.profile", "environment": "profile", "max-concurrency": 30}); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{"environment": "staging", "puppet-server": "puppet.env", "max-concurrency": "30"}
+	for name, value := range want {
+		if got := flags.Lookup(name).Value.String(); got != value {
+			t.Errorf("--%s = %q, want %q", name, got, value)
+		}
+	}
+}
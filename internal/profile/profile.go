@@ -0,0 +1,121 @@
+// Package profile applies named flag profiles from the config file, so teams
+// keep blessed flag combinations in opsmaster.yaml instead of copying long
+// command lines. Example:
+//
+//	profiles:
+//	  prod-puppet:
+//	    puppet-server: puppet.prod.example.com
+//	    environment: production
+//	    max-concurrency: 20
+//	    where: [env=prod, tier!=db]
+//
+// Keys are flag names of the command the profile is used with. Flags can
+// also come from OPSMASTER_* environment variables (ApplyEnv); precedence
+// is command line > environment > profile.
+package profile
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+// Lookup returns the flag values of profile name from the profiles section
+// of the config. Profile names are case-insensitive.
+func Lookup(profiles map[string]any, name string) (map[string]any, error) {
+	for key, value := range profiles {
+		if !strings.EqualFold(key, name) {
+			continue
+		}
+		values, ok := value.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("profile %q: expected a map of flag values", name)
+		}
+		return values, nil
+	}
+
+	names := make([]string, 0, len(profiles))
+	for key := range profiles {
+		names = append(names, key)
+	}
+	sort.Strings(names)
+	if len(names) == 0 {
+		return nil, fmt.Errorf("profile %q not found: no profiles in the config file", name)
+	}
+	return nil, fmt.Errorf("profile %q not found (available: %s)", name, strings.Join(names, ", "))
+}
+
+// Apply sets the flags of values that were not set on the command line and
+// returns their names, sorted. Lists set repeatable flags (e.g., where) one
+// value at a time.
+//
+// Flags in reserved (e.g., global flags already consumed at startup) and
+// flags the command doesn't have are rejected, so a typo or a profile meant
+// for another command fails before anything runs.
+func Apply(flags, reserved *pflag.FlagSet, values map[string]any) ([]string, error) {
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	applied := make([]string, 0, len(names))
+	for _, name := range names {
+		if reserved != nil && reserved.Lookup(name) != nil {
+			return nil, fmt.Errorf("flag --%s can't be set from a profile", name)
+		}
+		flag := flags.Lookup(name)
+		if flag == nil {
+			return nil, fmt.Errorf("unknown flag --%s for this command", name)
+		}
+		if flag.Changed {
+			continue // Command line wins
+		}
+
+		items, err := flagValues(name, values[name])
+		if err != nil {
+			return nil, err
+		}
+		if len(items) > 1 && !isRepeatable(flag) {
+			return nil, fmt.Errorf("flag --%s takes a single value", name)
+		}
+		for _, item := range items {
+			if err := flags.Set(name, item); err != nil {
+				return nil, fmt.Errorf("flag --%s: %w", name, err)
+			}
+		}
+		applied = append(applied, name)
+	}
+	return applied, nil
+}
+
+// flagValues converts a config value to flag arguments.
+func flagValues(name string, value any) ([]string, error) {
+	switch v := value.(type) {
+	case nil:
+		return nil, fmt.Errorf("flag --%s: empty value", name)
+	case []any:
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			switch item.(type) {
+			case []any, map[string]any, nil:
+				return nil, fmt.Errorf("flag --%s: list items must be scalars", name)
+			}
+			items = append(items, fmt.Sprint(item))
+		}
+		return items, nil
+	case map[string]any:
+		return nil, fmt.Errorf("flag --%s: expected a value or a list, got a map", name)
+	default:
+		return []string{fmt.Sprint(v)}, nil
+	}
+}
+
+// isRepeatable reports whether a flag accepts several values (slice, array
+// and map flags).
+func isRepeatable(flag *pflag.Flag) bool {
+	kind := flag.Value.Type()
+	return strings.HasSuffix(kind, "Slice") || strings.HasSuffix(kind, "Array") || strings.HasPrefix(kind, "stringTo")
+}
@@ -0,0 +1,140 @@
+package profile
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+// newFlags creates a flag set shaped like an install command.
+func newFlags() *pflag.FlagSet {
+	flags := pflag.NewFlagSet("install", pflag.ContinueOnError)
+	flags.String("puppet-server", "", "")
+	flags.String("environment", "production", "")
+	flags.Int("max-concurrency", 10, "")
+	flags.Duration("timeout", 5*time.Minute, "")
+	flags.Bool("dry-run", false, "")
+	flags.StringArray("where", nil, "")
+	flags.String("quarantine-file", "", "")
+	return flags
+}
+
+// TestApply tests applying profile values to flags
+func TestApply(t *testing.T) {
+	tests := []struct {
+		name        string
+		args        []string
+		values      map[string]any
+		wantApplied string
+		want        map[string]string
+		expectError string
+	}{
+		{
+			name:        "sets scalar flags",
+			values:      map[string]any{"puppet-server": "puppet.prod", "max-concurrency": 20, "timeout": "10m", "dry-run": true},
+			wantApplied: "dry-run,max-concurrency,puppet-server,timeout",
+			want:        map[string]string{"puppet-server": "puppet.prod", "max-concurrency": "20", "timeout": "10m0s", "dry-run": "true"},
+		},
+		{
+			name:        "command line wins",
+			args:        []string{"--environment", "staging"},
+			values:      map[string]any{"environment": "production", "puppet-server": "puppet.prod"},
+			wantApplied: "puppet-server",
+			want:        map[string]string{"environment": "staging"},
+		},
+		{
+			name:        "list sets repeatable flag",
+			values:      map[string]any{"where": []any{"env=prod", "shard<5"}},
+			wantApplied: "where",
+			want:        map[string]string{"where": "[env=prod,shard<5]"},
+		},
+		{
+			name:        "unknown flag",
+			values:      map[string]any{"concurrency": 20},
+			expectError: "unknown flag --concurrency",
+		},
+		{
+			name:        "reserved flag",
+			values:      map[string]any{"quarantine-file": "q.yaml"},
+			expectError: "can't be set from a profile",
+		},
+		{
+			name:        "list for single value flag",
+			values:      map[string]any{"environment": []any{"a", "b"}},
+			expectError: "takes a single value",
+		},
+		{
+			name:        "invalid value",
+			values:      map[string]any{"max-concurrency": "many"},
+			expectError: "flag --max-concurrency",
+		},
+		{
+			name:        "map value",
+			values:      map[string]any{"environment": map[string]any{"a": "b"}},
+			expectError: "got a map",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// ARRANGE
+			flags := newFlags()
+			if err := flags.Parse(tt.args); err != nil {
+				t.Fatalf("Parse() unexpected error: %v", err)
+			}
+			reserved := pflag.NewFlagSet("global", pflag.ContinueOnError)
+			reserved.String("quarantine-file", "", "")
+
+			// ACT
+			applied, err := Apply(flags, reserved, tt.values)
+
+			// ASSERT
+			if tt.expectError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectError) {
+					t.Fatalf("Apply() error = %v, want containing %q", err, tt.expectError)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Apply() unexpected error: %v", err)
+			}
+			if got := strings.Join(applied, ","); got != tt.wantApplied {
+				t.Errorf("Apply() applied = %q, want %q", got, tt.wantApplied)
+			}
+			for name, want := range tt.want {
+				if got := flags.Lookup(name).Value.String(); got != want {
+					t.Errorf("--%s = %q, want %q", name, got, want)
+				}
+			}
+		})
+	}
+}
+
+// TestLookup tests finding a profile in the config
+func TestLookup(t *testing.T) {
+	profiles := map[string]any{
+		"prod-puppet": map[string]any{"environment": "production"},
+		"staging":     map[string]any{"environment": "staging"},
+		"broken":      "not a map",
+	}
+
+	values, err := Lookup(profiles, "PROD-puppet")
+	if err != nil {
+		t.Fatalf("Lookup() unexpected error: %v", err)
+	}
+	if values["environment"] != "production" {
+		t.Errorf("Lookup() = %v, want the prod-puppet values", values)
+	}
+
+	if _, err := Lookup(profiles, "broken"); err == nil {
+		t.Error("Lookup() expected error for a profile that is not a map")
+	}
+	if _, err := Lookup(profiles, "prod"); err == nil || !strings.Contains(err.Error(), "available: broken, prod-puppet, staging") {
+		t.Errorf("Lookup() error = %v, want the available profiles", err)
+	}
+	if _, err := Lookup(nil, "prod"); err == nil || !strings.Contains(err.Error(), "no profiles") {
+		t.Errorf("Lookup() error = %v, want no profiles error", err)
+	}
+}
@@ -0,0 +1,120 @@
+// Package puppetca manages agent certificates through the Puppet Server CA
+// API (v1 certificate_status endpoint).
+package puppetca
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/estudosdevops/opsmaster/internal/httpclient"
+)
+
+// maxErrorBody limits how much of an error response is kept in errors.
+const maxErrorBody = 512
+
+// certificateStatusPath is the CA endpoint for a single certname.
+const certificateStatusPath = "/puppet-ca/v1/certificate_status/"
+
+// Config configures the Puppet CA client.
+//
+// The CA authorizes certificate_status by client certificate (auth.conf
+// allow list), so Client usually carries an mTLS certificate of an admin node.
+type Config struct {
+	URL    string       // Puppet CA base URL (e.g., https://puppet.example.com:8140)
+	Token  string       // Optional RBAC token (Puppet Enterprise), sent as X-Authentication
+	Client *http.Client // HTTP client (default: httpclient.Shared())
+}
+
+// Client revokes, deletes and signs certificates on the Puppet CA.
+type Client struct {
+	config Config
+}
+
+// NewClient validates the configuration and creates a client.
+func NewClient(config Config) (*Client, error) {
+	parsed, err := url.Parse(config.URL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return nil, fmt.Errorf("invalid Puppet CA URL %q: must be an http(s) URL", config.URL)
+	}
+	config.URL = strings.TrimRight(config.URL, "/")
+	return &Client{config: config}, nil
+}
+
+// Clean revokes and deletes the certificate (and any pending request) of a
+// certname, like "puppetserver ca clean". Unknown certnames are not an error,
+// so cleaning is safe to repeat.
+func (c *Client) Clean(ctx context.Context, certname string) error {
+	// Revoking answers 404 when only a CSR (or nothing) exists and 409 when
+	// the certificate is already revoked; both still need the delete.
+	status, err := c.setDesiredState(ctx, certname, "revoked")
+	if err != nil && status != http.StatusNotFound && status != http.StatusConflict {
+		return err
+	}
+
+	status, err = c.do(ctx, http.MethodDelete, certname, nil)
+	if err != nil && status != http.StatusNotFound {
+		return err
+	}
+	return nil
+}
+
+// Sign signs the pending certificate request of a certname (for CAs without
+// autosigning).
+func (c *Client) Sign(ctx context.Context, certname string) error {
+	_, err := c.setDesiredState(ctx, certname, "signed")
+	return err
+}
+
+// setDesiredState changes the state of a certificate or request.
+func (c *Client) setDesiredState(ctx context.Context, certname, state string) (int, error) {
+	body, err := json.Marshal(map[string]string{"desired_state": state})
+	if err != nil {
+		return 0, fmt.Errorf("failed to encode Puppet CA request: %w", err)
+	}
+	return c.do(ctx, http.MethodPut, certname, body)
+}
+
+// do sends a request to the certificate_status endpoint of a certname and
+// returns the response status; non-2xx responses are returned as errors.
+func (c *Client) do(ctx context.Context, method, certname string, body []byte) (int, error) {
+	if certname == "" {
+		return 0, fmt.Errorf("certname is required")
+	}
+	target := c.config.URL + certificateStatusPath + url.PathEscape(certname)
+
+	req, err := http.NewRequestWithContext(ctx, method, target, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create Puppet CA request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.config.Token != "" {
+		req.Header.Set("X-Authentication", c.config.Token)
+	}
+
+	client := c.config.Client
+	if client == nil {
+		client = httpclient.Shared()
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("puppet CA %s %s failed: %w", method, certname, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		return resp.StatusCode, fmt.Errorf("puppet CA returned %d for %s %s: %s",
+			resp.StatusCode, method, certname, strings.TrimSpace(string(detail)))
+	}
+	return resp.StatusCode, nil
+}
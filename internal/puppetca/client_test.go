@@ -0,0 +1,101 @@
+package puppetca
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// TestClient_Clean tests revoke + delete and tolerated statuses.
+//
+// 🎓 CONCEPT: httptest.Server
+// The fake records each call, so the request sequence can be asserted.
+func TestClient_Clean(t *testing.T) {
+	tests := []struct {
+		name         string
+		revokeStatus int
+		deleteStatus int
+		wantErr      bool
+	}{
+		{name: "signed certificate", revokeStatus: http.StatusNoContent, deleteStatus: http.StatusNoContent},
+		{name: "already revoked", revokeStatus: http.StatusConflict, deleteStatus: http.StatusNoContent},
+		{name: "unknown certname", revokeStatus: http.StatusNotFound, deleteStatus: http.StatusNotFound},
+		{name: "not authorized", revokeStatus: http.StatusForbidden, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// ARRANGE
+			var calls []string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				calls = append(calls, r.Method+" "+r.URL.Path+" "+string(body))
+				if r.Method == http.MethodPut {
+					w.WriteHeader(tt.revokeStatus)
+					return
+				}
+				w.WriteHeader(tt.deleteStatus)
+			}))
+			defer server.Close()
+
+			client, err := NewClient(Config{URL: server.URL + "/", Client: server.Client()})
+			if err != nil {
+				t.Fatalf("NewClient() error: %v", err)
+			}
+
+			// ACT
+			err = client.Clean(context.Background(), "abc.puppet")
+
+			// ASSERT
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Clean() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			want := []string{
+				`PUT /puppet-ca/v1/certificate_status/abc.puppet {"desired_state":"revoked"}`,
+				"DELETE /puppet-ca/v1/certificate_status/abc.puppet ",
+			}
+			if strings.Join(calls, "\n") != strings.Join(want, "\n") {
+				t.Errorf("calls:\n%s\nwant:\n%s", strings.Join(calls, "\n"), strings.Join(want, "\n"))
+			}
+		})
+	}
+}
+
+// TestClient_Sign tests signing and error reporting
+func TestClient_Sign(t *testing.T) {
+	var gotBody, gotToken string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		gotBody, gotToken = string(body), r.Header.Get("X-Authentication")
+		if strings.HasSuffix(r.URL.Path, "/missing.puppet") {
+			http.Error(w, "Invalid certificate subject.", http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	client, _ := NewClient(Config{URL: server.URL, Token: "rbac", Client: server.Client()})
+
+	if err := client.Sign(context.Background(), "abc.puppet"); err != nil {
+		t.Fatalf("Sign() error: %v", err)
+	}
+	if gotBody != `{"desired_state":"signed"}` || gotToken != "rbac" {
+		t.Errorf("unexpected request body=%s token=%q", gotBody, gotToken)
+	}
+
+	err := client.Sign(context.Background(), "missing.puppet")
+	if err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("expected 404 error, got %v", err)
+	}
+
+	if _, err := NewClient(Config{URL: "puppet:8140"}); err == nil {
+		t.Error("expected error for URL without scheme")
+	}
+}
@@ -0,0 +1,124 @@
+// Package puppetdb queries PuppetDB (v4 query API) for node and fact data.
+package puppetdb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/estudosdevops/opsmaster/internal/httpclient"
+)
+
+// maxErrorBody limits how much of an error response is kept in errors.
+const maxErrorBody = 512
+
+// InstanceIDFactPath is the fact path holding the EC2 instance ID
+// (structured fact ec2_metadata, available on AWS instances).
+var InstanceIDFactPath = []string{"ec2_metadata", "instance-id"}
+
+// Config configures the PuppetDB client.
+type Config struct {
+	URL    string       // PuppetDB base URL (e.g., https://puppetdb.example.com:8081)
+	Token  string       // Optional RBAC token (Puppet Enterprise), sent as X-Authentication
+	Client *http.Client // HTTP client (default: httpclient.Shared())
+}
+
+// Client queries the PuppetDB v4 API.
+type Client struct {
+	config Config
+}
+
+// Node is an active node as returned by /pdb/query/v4/nodes.
+type Node struct {
+	Certname           string     `json:"certname"`
+	ReportTimestamp    *time.Time `json:"report_timestamp"`
+	CatalogTimestamp   *time.Time `json:"catalog_timestamp"`
+	FactsTimestamp     *time.Time `json:"facts_timestamp"`
+	LatestReportStatus string     `json:"latest_report_status"`
+	ReportEnvironment  string     `json:"report_environment"`
+}
+
+// NewClient validates the configuration and creates a client.
+func NewClient(config Config) (*Client, error) {
+	parsed, err := url.Parse(config.URL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return nil, fmt.Errorf("invalid PuppetDB URL %q: must be an http(s) URL", config.URL)
+	}
+	config.URL = strings.TrimRight(config.URL, "/")
+	return &Client{config: config}, nil
+}
+
+// Nodes returns active (not deactivated or expired) nodes.
+func (c *Client) Nodes(ctx context.Context) ([]Node, error) {
+	var nodes []Node
+	if err := c.query(ctx, "/pdb/query/v4/nodes", "", &nodes); err != nil {
+		return nil, err
+	}
+	return nodes, nil
+}
+
+// FactValues returns the value of a structured fact path for every node
+// that has it, keyed by certname (e.g., ec2_metadata.instance-id).
+func (c *Client) FactValues(ctx context.Context, path []string) (map[string]string, error) {
+	query, err := json.Marshal([]any{"=", "path", path})
+	if err != nil {
+		return nil, fmt.Errorf("failed to build fact query: %w", err)
+	}
+
+	var contents []struct {
+		Certname string `json:"certname"`
+		Value    any    `json:"value"`
+	}
+	if err := c.query(ctx, "/pdb/query/v4/fact-contents", string(query), &contents); err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]string, len(contents))
+	for _, content := range contents {
+		values[content.Certname] = fmt.Sprint(content.Value)
+	}
+	return values, nil
+}
+
+// query performs a GET on a query endpoint and decodes the JSON array response.
+func (c *Client) query(ctx context.Context, endpoint, query string, out any) error {
+	target := c.config.URL + endpoint
+	if query != "" {
+		target += "?" + url.Values{"query": {query}}.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create PuppetDB request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if c.config.Token != "" {
+		req.Header.Set("X-Authentication", c.config.Token)
+	}
+
+	client := c.config.Client
+	if client == nil {
+		client = httpclient.Shared()
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("PuppetDB request to %s failed: %w", endpoint, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		return fmt.Errorf("PuppetDB returned %d for %s: %s", resp.StatusCode, endpoint, strings.TrimSpace(string(detail)))
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode PuppetDB response from %s: %w", endpoint, err)
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+package puppetdb
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/estudosdevops/opsmaster/internal/cloud"
+)
+
+// TestClient_NodesAndFacts tests PuppetDB queries against a fake server.
+//
+// 🎓 CONCEPT: httptest.Server
+// The fake answers the v4 endpoints with canned JSON, so no PuppetDB is needed.
+func TestClient_NodesAndFacts(t *testing.T) {
+	// ARRANGE
+	var gotQuery, gotToken string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotToken = r.Header.Get("X-Authentication")
+		switch r.URL.Path {
+		case "/pdb/query/v4/nodes":
+			_, _ = w.Write([]byte(`[{"certname":"a.puppet","report_timestamp":"2026-01-01T10:00:00Z","latest_report_status":"unchanged"},{"certname":"b.puppet","report_timestamp":null}]`))
+		case "/pdb/query/v4/fact-contents":
+			gotQuery = r.URL.Query().Get("query")
+			_, _ = w.Write([]byte(`[{"certname":"a.puppet","value":"i-aaa"}]`))
+		default:
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewClient(Config{URL: server.URL + "/", Token: "rbac", Client: server.Client()})
+	if err != nil {
+		t.Fatalf("NewClient() error: %v", err)
+	}
+
+	// ACT
+	nodes, err := client.Nodes(context.Background())
+	if err != nil {
+		t.Fatalf("Nodes() error: %v", err)
+	}
+	ids, err := client.FactValues(context.Background(), InstanceIDFactPath)
+	if err != nil {
+		t.Fatalf("FactValues() error: %v", err)
+	}
+
+	// ASSERT
+	if len(nodes) != 2 || nodes[0].ReportTimestamp == nil || nodes[1].ReportTimestamp != nil {
+		t.Errorf("unexpected nodes: %+v", nodes)
+	}
+	if ids["a.puppet"] != "i-aaa" {
+		t.Errorf("FactValues() = %v, want a.puppet -> i-aaa", ids)
+	}
+	if gotQuery != `["=","path",["ec2_metadata","instance-id"]]` {
+		t.Errorf("fact query = %s", gotQuery)
+	}
+	if gotToken != "rbac" {
+		t.Errorf("X-Authentication = %q, want rbac", gotToken)
+	}
+}
+
+// TestClient_Errors tests invalid URLs and non-200 responses
+func TestClient_Errors(t *testing.T) {
+	if _, err := NewClient(Config{URL: "puppetdb:8081"}); err == nil {
+		t.Error("expected error for URL without scheme")
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		http.Error(w, "forbidden", http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	client, _ := NewClient(Config{URL: server.URL, Client: server.Client()})
+	if _, err := client.Nodes(context.Background()); err == nil {
+		t.Error("expected error for 403 response")
+	}
+}
+
+// TestReconcile tests classification of inventory instances and PuppetDB nodes
+func TestReconcile(t *testing.T) {
+	// ARRANGE
+	now := time.Date(2026, 1, 2, 12, 0, 0, 0, time.UTC)
+	recent := now.Add(-time.Hour)
+	old := now.Add(-48 * time.Hour)
+
+	instances := []*cloud.Instance{
+		{ID: "i-ok", Metadata: map[string]string{}},
+		{ID: "i-stale", Metadata: map[string]string{}},
+		{ID: "i-csv", Metadata: map[string]string{"certname": "csv.puppet"}},
+		{ID: "i-missing", Metadata: map[string]string{}},
+		{ID: "i-never", Metadata: map[string]string{}},
+	}
+	nodes := []Node{
+		{Certname: "ok.puppet", ReportTimestamp: &recent, LatestReportStatus: "changed"},
+		{Certname: "stale.puppet", ReportTimestamp: &old},
+		{Certname: "csv.puppet", ReportTimestamp: &recent},
+		{Certname: "never.puppet"},
+		{Certname: "zz-orphan.puppet", ReportTimestamp: &recent},
+		{Certname: "aa-orphan.puppet", ReportTimestamp: &recent},
+	}
+	instanceIDs := map[string]string{
+		"ok.puppet":        "i-ok",
+		"stale.puppet":     "i-stale",
+		"never.puppet":     "i-never",
+		"aa-orphan.puppet": "i-orphan",
+	}
+
+	// ACT
+	entries := Reconcile(instances, nodes, instanceIDs, ReconcileOptions{StaleAfter: 24 * time.Hour, Now: now})
+
+	// ASSERT
+	want := []struct{ instanceID, certname, status string }{
+		{"i-ok", "ok.puppet", StatusOK},
+		{"i-stale", "stale.puppet", StatusStale},
+		{"i-csv", "csv.puppet", StatusOK},
+		{"i-missing", "", StatusNotReporting},
+		{"i-never", "never.puppet", StatusNotReporting},
+		{"i-orphan", "aa-orphan.puppet", StatusNotInInventory},
+		{"", "zz-orphan.puppet", StatusNotInInventory},
+	}
+	if len(entries) != len(want) {
+		t.Fatalf("got %d entries, want %d: %+v", len(entries), len(want), entries)
+	}
+	for i, w := range want {
+		e := entries[i]
+		if e.InstanceID != w.instanceID || e.Certname != w.certname || e.Status != w.status {
+			t.Errorf("entry %d = (%s, %s, %s), want (%s, %s, %s)", i, e.InstanceID, e.Certname, e.Status, w.instanceID, w.certname, w.status)
+		}
+	}
+
+	counts := CountByStatus(entries)
+	if counts[StatusOK] != 2 || counts[StatusNotInInventory] != 2 {
+		t.Errorf("CountByStatus() = %v", counts)
+	}
+}
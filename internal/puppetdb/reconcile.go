@@ -0,0 +1,124 @@
+package puppetdb
+
+import (
+	"sort"
+	"time"
+
+	"github.com/estudosdevops/opsmaster/internal/cloud"
+)
+
+// Reconciliation statuses.
+const (
+	StatusOK             = "ok"               // In inventory and reporting recently
+	StatusNotReporting   = "not-reporting"    // In inventory, no active node in PuppetDB
+	StatusStale          = "stale"            // In inventory, last report older than the threshold
+	StatusNotInInventory = "not-in-inventory" // Active in PuppetDB, missing from inventory
+)
+
+// DefaultCertnameColumn is the optional CSV column with the node certname.
+// Without it, instances are matched by the ec2_metadata instance-id fact.
+const DefaultCertnameColumn = "certname"
+
+// ReconcileOptions controls how inventory and PuppetDB are compared.
+type ReconcileOptions struct {
+	StaleAfter     time.Duration // Nodes without a report for longer are stale
+	Now            time.Time     // Reference time (default: time.Now())
+	CertnameColumn string        // CSV column with the certname (default: "certname")
+}
+
+// Entry is one row of the reconciliation report.
+type Entry struct {
+	InstanceID string     `json:"instance_id,omitempty"`
+	Account    string     `json:"account,omitempty"`
+	Region     string     `json:"region,omitempty"`
+	Certname   string     `json:"certname,omitempty"`
+	Status     string     `json:"status"`
+	LastReport *time.Time `json:"last_report,omitempty"`
+	Detail     string     `json:"detail,omitempty"`
+}
+
+// Reconcile compares inventory instances with active PuppetDB nodes.
+//
+// Instances are matched to nodes by the certname CSV column when present,
+// otherwise by instance ID (instanceIDs maps certname -> instance ID, see
+// InstanceIDFactPath). Inventory entries come first, in CSV order, followed
+// by PuppetDB nodes missing from the inventory sorted by certname.
+func Reconcile(instances []*cloud.Instance, nodes []Node, instanceIDs map[string]string, opts ReconcileOptions) []Entry {
+	if opts.Now.IsZero() {
+		opts.Now = time.Now()
+	}
+	if opts.CertnameColumn == "" {
+		opts.CertnameColumn = DefaultCertnameColumn
+	}
+
+	nodesByCertname := make(map[string]Node, len(nodes))
+	for _, node := range nodes {
+		nodesByCertname[node.Certname] = node
+	}
+	certnameByInstance := make(map[string]string, len(instanceIDs))
+	for certname, instanceID := range instanceIDs {
+		certnameByInstance[instanceID] = certname
+	}
+
+	matched := make(map[string]bool)
+	entries := make([]Entry, 0, len(instances)+len(nodes))
+
+	for _, instance := range instances {
+		entry := Entry{InstanceID: instance.ID, Account: instance.Account, Region: instance.Region}
+
+		certname := instance.Metadata[opts.CertnameColumn]
+		if certname == "" {
+			certname = certnameByInstance[instance.ID]
+		}
+		entry.Certname = certname
+
+		node, found := nodesByCertname[certname]
+		switch {
+		case !found:
+			entry.Status = StatusNotReporting
+			entry.Detail = "no active node in PuppetDB"
+		case node.ReportTimestamp == nil:
+			entry.Status = StatusNotReporting
+			entry.Detail = "node registered but never reported"
+		case opts.Now.Sub(*node.ReportTimestamp) > opts.StaleAfter:
+			entry.Status = StatusStale
+			entry.LastReport = node.ReportTimestamp
+			entry.Detail = "no report for " + opts.Now.Sub(*node.ReportTimestamp).Round(time.Minute).String()
+		default:
+			entry.Status = StatusOK
+			entry.LastReport = node.ReportTimestamp
+			entry.Detail = node.LatestReportStatus
+		}
+		if found {
+			matched[certname] = true
+		}
+
+		entries = append(entries, entry)
+	}
+
+	var orphans []Entry
+	for _, node := range nodes {
+		if matched[node.Certname] {
+			continue
+		}
+		orphans = append(orphans, Entry{
+			InstanceID: instanceIDs[node.Certname],
+			Certname:   node.Certname,
+			Status:     StatusNotInInventory,
+			LastReport: node.ReportTimestamp,
+			Detail:     "active in PuppetDB but missing from inventory",
+		})
+	}
+	sort.Slice(orphans, func(i, j int) bool { return orphans[i].Certname < orphans[j].Certname })
+
+	return append(entries, orphans...)
+}
+
+// CountByStatus returns how many entries have each status.
+func CountByStatus(entries []Entry) map[string]int {
+	counts := make(map[string]int)
+	for _, entry := range entries {
+		counts[entry.Status]++
+	}
+	return counts
+}
@@ -0,0 +1,247 @@
+// Package quarantine lists instances excluded from all automation, each with
+// a reason and an optional expiry. Every command consults the list loaded at
+// startup (Configure), so on-call can exclude a fragile host without editing
+// the shared CSVs.
+package quarantine
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/estudosdevops/opsmaster/internal/cloud"
+)
+
+// DefaultFile is the quarantine file used when none is configured,
+// relative to the home directory. It is optional.
+const DefaultFile = ".opsmaster-quarantine.yaml"
+
+// Entry is one quarantined instance.
+type Entry struct {
+	InstanceID string    // Instance excluded from automation
+	Reason     string    // Why it is quarantined, shown when skipping it
+	Expires    time.Time // When the quarantine ends (zero = until removed)
+	AddedBy    string    // Who added it (optional)
+}
+
+// Active reports whether the entry still applies at now.
+func (e Entry) Active(now time.Time) bool {
+	return e.Expires.IsZero() || now.Before(e.Expires)
+}
+
+// SkipReason is the reason recorded for skipped instances.
+func (e Entry) SkipReason() string {
+	reason := "quarantined: " + e.Reason
+	if !e.Expires.IsZero() {
+		reason += " (until " + e.Expires.UTC().Format(time.RFC3339) + ")"
+	}
+	if e.AddedBy != "" {
+		reason += " [by " + e.AddedBy + "]"
+	}
+	return reason
+}
+
+// List is a set of quarantine entries keyed by instance ID.
+// The nil and zero values are empty lists.
+type List struct {
+	path    string
+	entries map[string]Entry
+}
+
+// New creates a list from entries (later entries of an instance win).
+func New(entries ...Entry) *List {
+	list := &List{entries: make(map[string]Entry, len(entries))}
+	for _, entry := range entries {
+		list.entries[entry.InstanceID] = entry
+	}
+	return list
+}
+
+// expiryLayouts are the accepted formats of the expires field.
+var expiryLayouts = []string{time.RFC3339, "2006-01-02"}
+
+// Load reads a quarantine file.
+//
+// Expected YAML format:
+//
+//	instances:
+//	  - instance_id: i-0abc123
+//	    reason: "kernel panic after reboot (INC-4521)"
+//	    expires: 2026-10-20            # date (00:00 UTC) or RFC 3339, optional
+//	    added_by: alice                # optional
+func Load(path string) (*List, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read quarantine file: %w", err)
+	}
+
+	var raw struct {
+		Instances []struct {
+			InstanceID string `yaml:"instance_id"`
+			Reason     string `yaml:"reason"`
+			Expires    string `yaml:"expires"`
+			AddedBy    string `yaml:"added_by"`
+		} `yaml:"instances"`
+	}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse quarantine file %s: %w", path, err)
+	}
+
+	list := &List{path: path, entries: make(map[string]Entry, len(raw.Instances))}
+	for i, item := range raw.Instances {
+		if item.InstanceID == "" {
+			return nil, fmt.Errorf("quarantine entry %d: instance_id is required", i+1)
+		}
+		if item.Reason == "" {
+			return nil, fmt.Errorf("quarantine entry %s: reason is required", item.InstanceID)
+		}
+		if _, exists := list.entries[item.InstanceID]; exists {
+			return nil, fmt.Errorf("quarantine entry %s: duplicated instance_id", item.InstanceID)
+		}
+
+		entry := Entry{InstanceID: item.InstanceID, Reason: item.Reason, AddedBy: item.AddedBy}
+		if item.Expires != "" {
+			if entry.Expires, err = parseExpiry(item.Expires); err != nil {
+				return nil, fmt.Errorf("quarantine entry %s: %w", item.InstanceID, err)
+			}
+		}
+		list.entries[item.InstanceID] = entry
+	}
+	return list, nil
+}
+
+// parseExpiry parses an expires value in one of expiryLayouts.
+func parseExpiry(value string) (time.Time, error) {
+	for _, layout := range expiryLayouts {
+		if expires, err := time.Parse(layout, value); err == nil {
+			return expires, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid expires %q (use 2006-01-02 or RFC 3339)", value)
+}
+
+// Path returns the file the list was loaded from ("" = no file).
+func (l *List) Path() string {
+	if l == nil {
+		return ""
+	}
+	return l.path
+}
+
+// Lookup returns the active entry of instanceID at now, if any.
+func (l *List) Lookup(instanceID string, now time.Time) (Entry, bool) {
+	if l == nil {
+		return Entry{}, false
+	}
+	entry, exists := l.entries[instanceID]
+	if !exists || !entry.Active(now) {
+		return Entry{}, false
+	}
+	return entry, true
+}
+
+// Entries returns all entries (expired included), sorted by instance ID.
+func (l *List) Entries() []Entry {
+	if l == nil {
+		return nil
+	}
+	entries := make([]Entry, 0, len(l.entries))
+	for _, entry := range l.entries {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].InstanceID < entries[j].InstanceID })
+	return entries
+}
+
+// Match is a quarantined instance and its entry.
+type Match struct {
+	Instance *cloud.Instance
+	Entry    Entry
+}
+
+// Partition splits instances into the ones automation may process and the
+// quarantined ones, keeping input order.
+func (l *List) Partition(instances []*cloud.Instance, now time.Time) (kept []*cloud.Instance, quarantined []Match) {
+	kept = make([]*cloud.Instance, 0, len(instances))
+	for _, instance := range instances {
+		if entry, ok := l.Lookup(instance.ID, now); ok {
+			quarantined = append(quarantined, Match{Instance: instance, Entry: entry})
+			continue
+		}
+		kept = append(kept, instance)
+	}
+	return kept, quarantined
+}
+
+// The process-wide list set by Configure. Guarded by mu.
+var (
+	mu      sync.RWMutex
+	current *List
+)
+
+// Configure loads the process-wide list consulted by every command.
+// An empty path uses DefaultFile in the home directory, which is optional;
+// an explicit path must exist. Call once during CLI initialization.
+func Configure(path string) error {
+	explicit := path != ""
+	if !explicit {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil
+		}
+		path = filepath.Join(home, DefaultFile)
+	}
+
+	list, err := Load(path)
+	if !explicit && errors.Is(err, os.ErrNotExist) {
+		list, err = nil, nil
+	}
+	if err != nil {
+		return err
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	current = list
+	return nil
+}
+
+// Default returns the process-wide list (empty until Configure loads one).
+func Default() *List {
+	mu.RLock()
+	defer mu.RUnlock()
+	return current
+}
+
+// Exclude removes the instances quarantined in the process-wide list,
+// logging each one with its reason. For commands that don't report skipped
+// instances themselves (ParallelExecutor records them as Skipped).
+func Exclude(log *slog.Logger, instances []*cloud.Instance) []*cloud.Instance {
+	kept, quarantined := Default().Partition(instances, time.Now())
+	for _, match := range quarantined {
+		log.Warn("⛔ Skipping quarantined instance",
+			"instance_id", match.Instance.ID,
+			"reason", match.Entry.Reason,
+			"expires", formatExpiry(match.Entry.Expires),
+			"added_by", match.Entry.AddedBy)
+	}
+	if len(quarantined) > 0 {
+		log.Warn("Quarantined instances skipped", "count", len(quarantined), "file", Default().Path())
+	}
+	return kept
+}
+
+// formatExpiry renders an expiry for logs.
+func formatExpiry(expires time.Time) string {
+	if expires.IsZero() {
+		return "never"
+	}
+	return expires.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,135 @@
+package quarantine
+
+import (
+	"bytes"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/estudosdevops/opsmaster/internal/cloud"
+)
+
+func writeFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "quarantine.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name        string
+		content     string
+		expectError string
+	}{
+		{
+			name: "valid entries",
+			content: `instances:
+  - instance_id: i-disk
+    reason: "disk failing (INC-4521)"
+    expires: 2026-10-20
+    added_by: alice
+  - instance_id: i-kernel
+    reason: kernel panic after reboot
+    expires: "2026-10-18T15:00:00-03:00"
+  - instance_id: i-legacy
+    reason: legacy host, owner migrating it
+`,
+		},
+		{name: "empty file", content: ""},
+		{name: "missing reason", content: "instances:\n  - instance_id: i-1\n", expectError: "reason is required"},
+		{name: "missing instance_id", content: "instances:\n  - reason: x\n", expectError: "entry 1: instance_id is required"},
+		{name: "duplicated", content: "instances:\n  - {instance_id: i-1, reason: a}\n  - {instance_id: i-1, reason: b}\n", expectError: "duplicated"},
+		{name: "invalid expires", content: "instances:\n  - {instance_id: i-1, reason: a, expires: next week}\n", expectError: "invalid expires"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			list, err := Load(writeFile(t, tt.content))
+
+			if tt.expectError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectError) {
+					t.Fatalf("error = %v, want containing %q", err, tt.expectError)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.name == "valid entries" {
+				entries := list.Entries()
+				if len(entries) != 3 || entries[0].InstanceID != "i-disk" || entries[0].AddedBy != "alice" {
+					t.Fatalf("unexpected entries %+v", entries)
+				}
+				if want := time.Date(2026, 10, 20, 0, 0, 0, 0, time.UTC); !entries[0].Expires.Equal(want) {
+					t.Errorf("date expiry = %s, want %s", entries[0].Expires, want)
+				}
+				if want := time.Date(2026, 10, 18, 18, 0, 0, 0, time.UTC); !entries[1].Expires.Equal(want) {
+					t.Errorf("RFC 3339 expiry = %s, want %s", entries[1].Expires, want)
+				}
+			}
+		})
+	}
+}
+
+func TestList_Partition(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	list := New(
+		Entry{InstanceID: "i-active", Reason: "flaky NIC", Expires: now.Add(time.Hour)},
+		Entry{InstanceID: "i-expired", Reason: "old incident", Expires: now.Add(-time.Hour)},
+		Entry{InstanceID: "i-forever", Reason: "legacy"},
+	)
+	instances := []*cloud.Instance{{ID: "i-ok"}, {ID: "i-active"}, {ID: "i-expired"}, {ID: "i-forever"}}
+
+	kept, quarantined := list.Partition(instances, now)
+
+	if len(kept) != 2 || kept[0].ID != "i-ok" || kept[1].ID != "i-expired" {
+		t.Errorf("kept = %v, want i-ok and i-expired", kept)
+	}
+	if len(quarantined) != 2 || quarantined[0].Instance.ID != "i-active" || quarantined[1].Instance.ID != "i-forever" {
+		t.Fatalf("quarantined = %+v", quarantined)
+	}
+	if got := quarantined[0].Entry.SkipReason(); got != "quarantined: flaky NIC (until 2026-10-16T13:00:00Z)" {
+		t.Errorf("SkipReason() = %q", got)
+	}
+
+	// Nil list quarantines nothing
+	var empty *List
+	if kept, quarantined := empty.Partition(instances, now); len(kept) != 4 || len(quarantined) != 0 {
+		t.Errorf("nil list: kept %d, quarantined %d", len(kept), len(quarantined))
+	}
+}
+
+func TestConfigure(t *testing.T) {
+	t.Cleanup(func() { current = nil })
+	t.Setenv("HOME", t.TempDir())
+
+	// Default file is optional
+	if err := Configure(""); err != nil || Default() != nil {
+		t.Fatalf("Configure(\"\") without default file: err=%v list=%v", err, Default())
+	}
+
+	// Explicit file must exist
+	if err := Configure(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Error("expected error for missing explicit file")
+	}
+
+	path := writeFile(t, "instances:\n  - {instance_id: i-1, reason: maintenance window, added_by: bob}\n")
+	if err := Configure(path); err != nil {
+		t.Fatalf("Configure() error: %v", err)
+	}
+
+	var buf bytes.Buffer
+	kept := Exclude(slog.New(slog.NewTextHandler(&buf, nil)), []*cloud.Instance{{ID: "i-1"}, {ID: "i-2"}})
+	if len(kept) != 1 || kept[0].ID != "i-2" {
+		t.Errorf("Exclude() kept %v", kept)
+	}
+	if !strings.Contains(buf.String(), "reason=\"maintenance window\"") {
+		t.Errorf("skipped instance logged without reason:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,362 @@
+// Package retention prunes the run artifacts opsmaster leaves on long-lived
+// hosts (e.g., a bastion running it from cron): the JSON run reports written
+// with --report and the coverage history files (JSON lines) of coverage
+// --history. Artifacts older than the keep age are removed first, then the
+// oldest ones until the total size fits the size limit. Example config:
+//
+//	history:
+//	  report_dirs: [/var/lib/opsmaster/reports]
+//	  files: [/var/lib/opsmaster/coverage.jsonl]
+//	  keep: 90d
+//	  max_size: 1GB
+//	  auto_prune: true
+package retention
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Artifact kinds of a Result.
+const (
+	KindReports = "reports"
+	KindHistory = "history"
+)
+
+// Policy limits the age and size of the artifacts of a report directory or
+// history file.
+type Policy struct {
+	Keep    time.Duration // Remove artifacts older than this (0: no age limit)
+	MaxSize int64         // Size limit in bytes of each directory or file (0: no size limit)
+}
+
+// Enabled reports whether the policy limits anything.
+func (p Policy) Enabled() bool {
+	return p.Keep > 0 || p.MaxSize > 0
+}
+
+// Config is the history section of the config file.
+type Config struct {
+	Policy
+	ReportDirs []string // Directories with run reports (--report files)
+	Files      []string // Coverage history files (--history files)
+	AutoPrune  bool     // Prune after each run writing a report or history point
+}
+
+// Result summarizes the pruning of one report directory or history file.
+type Result struct {
+	Path    string   // Report directory or history file
+	Kind    string   // KindReports or KindHistory
+	Total   int      // Reports or history points found
+	Removed []string // Removed reports, or history points as "<file>:<line>" (or that would be removed in a dry run)
+	Size    int64    // Size in bytes before pruning
+	Freed   int64    // Bytes freed (or that would be freed in a dry run)
+}
+
+// item is one artifact considered for pruning.
+type item struct {
+	name string
+	time time.Time
+	size int64
+}
+
+// ParseConfig decodes the history section of the config file.
+func ParseConfig(section map[string]any) (Config, error) {
+	var cfg Config
+	for key, value := range section {
+		var err error
+		switch key {
+		case "report_dirs":
+			cfg.ReportDirs, err = stringList(value)
+		case "files":
+			cfg.Files, err = stringList(value)
+		case "keep":
+			cfg.Keep, err = ParseAge(fmt.Sprint(value))
+		case "max_size":
+			cfg.MaxSize, err = ParseSize(fmt.Sprint(value))
+		case "auto_prune":
+			var ok bool
+			if cfg.AutoPrune, ok = value.(bool); !ok {
+				err = fmt.Errorf("must be true or false")
+			}
+		default:
+			return Config{}, fmt.Errorf("history: unknown field %q (supported: report_dirs, files, keep, max_size, auto_prune)", key)
+		}
+		if err != nil {
+			return Config{}, fmt.Errorf("history: %s: %w", key, err)
+		}
+	}
+	return cfg, nil
+}
+
+// stringList decodes a string or a list of strings.
+func stringList(value any) ([]string, error) {
+	switch v := value.(type) {
+	case string:
+		return []string{v}, nil
+	case []any:
+		list := make([]string, 0, len(v))
+		for _, entry := range v {
+			s, ok := entry.(string)
+			if !ok {
+				return nil, fmt.Errorf("expected a list of paths, got %v", entry)
+			}
+			list = append(list, s)
+		}
+		return list, nil
+	case []string:
+		return v, nil
+	default:
+		return nil, fmt.Errorf("expected a path or a list of paths, got %v", value)
+	}
+}
+
+// ParseAge parses an age such as 90d, 2w or 36h (any Go duration).
+func ParseAge(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	for suffix, unit := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} {
+		if number, ok := strings.CutSuffix(value, suffix); ok {
+			n, err := strconv.Atoi(number)
+			if err != nil || n < 0 {
+				return 0, fmt.Errorf("invalid age %q (expected e.g. 90d, 2w or 36h)", value)
+			}
+			return time.Duration(n) * unit, nil
+		}
+	}
+	age, err := time.ParseDuration(value)
+	if err != nil || age < 0 {
+		return 0, fmt.Errorf("invalid age %q (expected e.g. 90d, 2w or 36h)", value)
+	}
+	return age, nil
+}
+
+// sizeUnits are the size suffixes accepted by ParseSize (1024-based),
+// longest first so "MB" is not read as "B".
+var sizeUnits = []struct {
+	suffix string
+	bytes  int64
+}{
+	{"TB", 1 << 40},
+	{"GB", 1 << 30},
+	{"MB", 1 << 20},
+	{"KB", 1 << 10},
+	{"B", 1},
+}
+
+// ParseSize parses a size such as 1GB, 500MB or 1.5GB (1024-based units)
+// or a plain number of bytes.
+func ParseSize(value string) (int64, error) {
+	number := strings.ToUpper(strings.TrimSpace(value))
+	unit := int64(1)
+	for _, u := range sizeUnits {
+		if n, ok := strings.CutSuffix(number, u.suffix); ok {
+			number, unit = strings.TrimSpace(n), u.bytes
+			break
+		}
+	}
+	n, err := strconv.ParseFloat(number, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size %q (expected e.g. 1GB or 500MB)", value)
+	}
+	return int64(n * float64(unit)), nil
+}
+
+// FormatSize formats a size in bytes with the largest ParseSize unit that
+// fits (e.g., 1.5GB).
+func FormatSize(size int64) string {
+	for _, u := range sizeUnits {
+		if size >= u.bytes && u.bytes > 1 {
+			return fmt.Sprintf("%.1f%s", float64(size)/float64(u.bytes), u.suffix)
+		}
+	}
+	return fmt.Sprintf("%dB", size)
+}
+
+// Prune prunes every report directory and history file of the config.
+func (c Config) Prune(now time.Time, dryRun bool) ([]Result, error) {
+	var results []Result
+	for _, dir := range c.ReportDirs {
+		result, err := PruneReports(dir, c.Policy, now, dryRun)
+		if err != nil {
+			return results, err
+		}
+		results = append(results, *result)
+	}
+	for _, path := range c.Files {
+		result, err := PruneHistory(path, c.Policy, now, dryRun)
+		if err != nil {
+			return results, err
+		}
+		results = append(results, *result)
+	}
+	return results, nil
+}
+
+// AutoPrune prunes the artifacts of the history section of the config when
+// auto_prune is set, after a run wrote a report or history point. Returns no
+// results when auto-pruning is off.
+func AutoPrune(section map[string]any, now time.Time) ([]Result, error) {
+	cfg, err := ParseConfig(section)
+	if err != nil || !cfg.AutoPrune || !cfg.Enabled() {
+		return nil, err
+	}
+	return cfg.Prune(now, false)
+}
+
+// selectItems returns the indexes of the items to remove: those older than
+// policy.Keep, then the oldest until the rest fits policy.MaxSize. items must
+// be sorted oldest first. The newest item is never removed for size.
+func (p Policy) selectItems(items []item, now time.Time) []int {
+	var remove []int
+	var total int64
+	for _, it := range items {
+		total += it.size
+	}
+	for i, it := range items {
+		switch {
+		case p.Keep > 0 && now.Sub(it.time) > p.Keep:
+		case p.MaxSize > 0 && total > p.MaxSize && i < len(items)-1:
+		default:
+			continue
+		}
+		remove = append(remove, i)
+		total -= it.size
+	}
+	return remove
+}
+
+// PruneReports removes old run reports from dir, by modification time.
+// Only JSON files written as opsmaster run reports are considered, and
+// reports locked by a run in progress (a "<report>.lock" file) are kept.
+func PruneReports(dir string, policy Policy, now time.Time, dryRun bool) (*Result, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return &Result{Path: dir, Kind: KindReports}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read report directory: %w", err)
+	}
+
+	var items []item
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		if _, err := os.Stat(path + ".lock"); err == nil {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || !isRunReport(path) {
+			continue
+		}
+		items = append(items, item{name: path, time: info.ModTime(), size: info.Size()})
+	}
+	sort.SliceStable(items, func(i, j int) bool { return items[i].time.Before(items[j].time) })
+
+	result := &Result{Path: dir, Kind: KindReports, Total: len(items)}
+	for _, it := range items {
+		result.Size += it.size
+	}
+	for _, i := range policy.selectItems(items, now) {
+		if !dryRun {
+			if err := os.Remove(items[i].name); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return result, fmt.Errorf("failed to remove report: %w", err)
+			}
+		}
+		result.Removed = append(result.Removed, items[i].name)
+		result.Freed += items[i].size
+	}
+	return result, nil
+}
+
+// isRunReport reports whether path is a run report: a JSON object whose
+// first field is schema_version, as written by executor.WriteReport.
+func isRunReport(path string) bool {
+	file, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+
+	decoder := json.NewDecoder(io.LimitReader(file, 4096))
+	if token, err := decoder.Token(); err != nil || token != json.Delim('{') {
+		return false
+	}
+	token, err := decoder.Token()
+	return err == nil && token == "schema_version"
+}
+
+// PruneHistory removes old points from a history file (JSON lines with a
+// generated_at field), rewriting it atomically. Lines without generated_at
+// are kept.
+func PruneHistory(path string, policy Policy, now time.Time, dryRun bool) (*Result, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return &Result{Path: path, Kind: KindHistory}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history file: %w", err)
+	}
+
+	var lines [][]byte
+	var items []item
+	index := map[string]int{} // item name -> line
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := append([]byte(nil), scanner.Bytes()...)
+		lines = append(lines, line)
+		var point struct {
+			GeneratedAt time.Time `json:"generated_at"`
+		}
+		if len(bytes.TrimSpace(line)) == 0 || json.Unmarshal(line, &point) != nil || point.GeneratedAt.IsZero() {
+			continue
+		}
+		name := fmt.Sprintf("%s:%d", path, len(lines))
+		index[name] = len(lines) - 1
+		items = append(items, item{name: name, time: point.GeneratedAt, size: int64(len(line) + 1)})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read history file: %w", err)
+	}
+	sort.SliceStable(items, func(i, j int) bool { return items[i].time.Before(items[j].time) })
+
+	result := &Result{Path: path, Kind: KindHistory, Total: len(items), Size: int64(len(data))}
+	removed := map[int]bool{}
+	for _, i := range policy.selectItems(items, now) {
+		removed[index[items[i].name]] = true
+		result.Removed = append(result.Removed, items[i].name)
+		result.Freed += items[i].size
+	}
+	if dryRun || len(removed) == 0 {
+		return result, nil
+	}
+
+	var kept bytes.Buffer
+	for i, line := range lines {
+		if !removed[i] {
+			kept.Write(line)
+			kept.WriteByte('\n')
+		}
+	}
+	tmp := filepath.Join(filepath.Dir(path), "."+filepath.Base(path)+".tmp")
+	if err := os.WriteFile(tmp, kept.Bytes(), 0o644); err != nil {
+		return nil, fmt.Errorf("failed to write history file: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return nil, fmt.Errorf("failed to write history file: %w", err)
+	}
+	return result, nil
+}
@@ -0,0 +1,240 @@
+package retention
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestParseAge tests ages with day and week suffixes and Go durations.
+func TestParseAge(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"90d", 90 * 24 * time.Hour, false},
+		{"2w", 14 * 24 * time.Hour, false},
+		{"36h", 36 * time.Hour, false},
+		{" 7d ", 7 * 24 * time.Hour, false},
+		{"1.5d", 0, true},
+		{"-1d", 0, true},
+		{"soon", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseAge(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseAge(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseAge(%q) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestParseSize tests sizes with 1024-based units.
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    int64
+		wantErr bool
+	}{
+		{"1GB", 1 << 30, false},
+		{"500mb", 500 << 20, false},
+		{"1.5 KB", 1536, false},
+		{"2048", 2048, false},
+		{"10B", 10, false},
+		{"1TB", 1 << 40, false},
+		{"GB", 0, true},
+		{"-1MB", 0, true},
+		{"big", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseSize(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseSize(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseSize(%q) = %d, want %d", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestParseConfig tests decoding the history section of the config.
+func TestParseConfig(t *testing.T) {
+	cfg, err := ParseConfig(map[string]any{
+		"report_dirs": []any{"/var/lib/opsmaster/reports"},
+		"files":       "/var/lib/opsmaster/coverage.jsonl",
+		"keep":        "90d",
+		"max_size":    "1GB",
+		"auto_prune":  true,
+	})
+	if err != nil {
+		t.Fatalf("ParseConfig() unexpected error: %v", err)
+	}
+	if cfg.Keep != 90*24*time.Hour || cfg.MaxSize != 1<<30 || !cfg.AutoPrune ||
+		len(cfg.ReportDirs) != 1 || len(cfg.Files) != 1 {
+		t.Errorf("ParseConfig() = %+v", cfg)
+	}
+
+	errorTests := []struct {
+		name    string
+		section map[string]any
+		wantErr string
+	}{
+		{"unknown field", map[string]any{"keep_days": 90}, `unknown field "keep_days"`},
+		{"invalid age", map[string]any{"keep": "forever"}, "invalid age"},
+		{"invalid size", map[string]any{"max_size": "huge"}, "invalid size"},
+		{"string boolean", map[string]any{"auto_prune": "yes"}, "must be true or false"},
+		{"invalid dirs", map[string]any{"report_dirs": []any{1}}, "expected a list of paths"},
+	}
+	for _, tt := range errorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseConfig(tt.section)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ParseConfig() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// writeFile writes content to dir/name with the given modification time.
+func writeFile(t *testing.T, dir, name, content string, modTime time.Time) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// TestPruneReports tests removing run reports by age and size.
+func TestPruneReports(t *testing.T) {
+	now := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	report := `{"schema_version": 1, "run_id": "r", "results": []}` + strings.Repeat(" ", 48) + "\n" // 100 bytes
+
+	tests := []struct {
+		name        string
+		policy      Policy
+		dryRun      bool
+		wantRemoved []string
+	}{
+		{"age", Policy{Keep: 30 * 24 * time.Hour}, false, []string{"old.json"}},
+		{"size", Policy{MaxSize: 150}, false, []string{"old.json", "recent.json"}},
+		{"age and size", Policy{Keep: 30 * 24 * time.Hour, MaxSize: 200}, false, []string{"old.json"}},
+		{"newest kept for size", Policy{MaxSize: 10}, false, []string{"old.json", "recent.json"}},
+		{"dry run", Policy{Keep: 30 * 24 * time.Hour}, true, []string{"old.json"}},
+		{"no limits", Policy{}, false, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFile(t, dir, "old.json", report, now.AddDate(0, -3, 0))
+			writeFile(t, dir, "recent.json", report, now.AddDate(0, 0, -2))
+			writeFile(t, dir, "latest.json", report, now.AddDate(0, 0, -1))
+			writeFile(t, dir, "running.json", report, now.AddDate(0, -6, 0))
+			writeFile(t, dir, "running.json.lock", "{}", now)
+			writeFile(t, dir, "inventory.json", `{"instances": []}`, now.AddDate(-1, 0, 0))
+			writeFile(t, dir, "notes.txt", "x", now.AddDate(-1, 0, 0))
+
+			result, err := PruneReports(dir, tt.policy, now, tt.dryRun)
+			if err != nil {
+				t.Fatalf("PruneReports() unexpected error: %v", err)
+			}
+			if result.Total != 3 || result.Size != 300 {
+				t.Errorf("Total, Size = %d, %d, want 3, 300", result.Total, result.Size)
+			}
+			var removed []string
+			for _, path := range result.Removed {
+				removed = append(removed, filepath.Base(path))
+			}
+			if fmt.Sprint(removed) != fmt.Sprint(tt.wantRemoved) {
+				t.Errorf("Removed = %v, want %v", removed, tt.wantRemoved)
+			}
+			if result.Freed != int64(100*len(tt.wantRemoved)) {
+				t.Errorf("Freed = %d, want %d", result.Freed, 100*len(tt.wantRemoved))
+			}
+			for _, name := range []string{"old.json", "recent.json", "latest.json", "running.json", "inventory.json"} {
+				_, err := os.Stat(filepath.Join(dir, name))
+				wantGone := !tt.dryRun && strings.Contains(fmt.Sprint(tt.wantRemoved), name)
+				if gone := os.IsNotExist(err); gone != wantGone {
+					t.Errorf("%s removed = %v, want %v", name, gone, wantGone)
+				}
+			}
+		})
+	}
+
+	result, err := PruneReports(filepath.Join(t.TempDir(), "missing"), Policy{Keep: time.Hour}, now, false)
+	if err != nil || result.Total != 0 {
+		t.Errorf("PruneReports(missing dir) = %+v, %v", result, err)
+	}
+}
+
+// TestPruneHistory tests removing old points from a history file.
+func TestPruneHistory(t *testing.T) {
+	now := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	point := func(at time.Time) string {
+		return fmt.Sprintf(`{"generated_at":%q,"percent":90}`, at.Format(time.RFC3339))
+	}
+	content := strings.Join([]string{
+		point(now.AddDate(0, -4, 0)),
+		point(now.AddDate(0, -2, 0)),
+		"",
+		point(now.AddDate(0, 0, -1)),
+	}, "\n") + "\n"
+
+	path := writeFile(t, t.TempDir(), "coverage.jsonl", content, now)
+	result, err := PruneHistory(path, Policy{Keep: 90 * 24 * time.Hour}, now, true)
+	if err != nil {
+		t.Fatalf("PruneHistory(dry run) unexpected error: %v", err)
+	}
+	if result.Total != 3 || len(result.Removed) != 1 || result.Removed[0] != path+":1" {
+		t.Errorf("PruneHistory(dry run) = %+v", result)
+	}
+	if data, _ := os.ReadFile(path); string(data) != content {
+		t.Error("PruneHistory(dry run) changed the file")
+	}
+
+	result, err = PruneHistory(path, Policy{MaxSize: int64(len(point(now)) + 2)}, now, false)
+	if err != nil {
+		t.Fatalf("PruneHistory() unexpected error: %v", err)
+	}
+	if len(result.Removed) != 2 {
+		t.Errorf("Removed = %v, want the 2 oldest points", result.Removed)
+	}
+	want := "\n" + point(now.AddDate(0, 0, -1)) + "\n"
+	if data, _ := os.ReadFile(path); string(data) != want {
+		t.Errorf("history file = %q, want %q", data, want)
+	}
+}
+
+// TestFormatSize tests formatting sizes with the ParseSize units.
+func TestFormatSize(t *testing.T) {
+	tests := []struct {
+		size int64
+		want string
+	}{
+		{512, "512B"},
+		{1536, "1.5KB"},
+		{1 << 30, "1.0GB"},
+	}
+
+	for _, tt := range tests {
+		if got := FormatSize(tt.size); got != tt.want {
+			t.Errorf("FormatSize(%d) = %q, want %q", tt.size, got, tt.want)
+		}
+	}
+}
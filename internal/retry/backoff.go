@@ -18,6 +18,21 @@ type RetryConfig struct {
 	BaseDelay   time.Duration // Initial delay (e.g., 1s)
 	MaxDelay    time.Duration // Maximum delay (e.g., 30s)
 	Jitter      bool          // Add randomness? (e.g., true)
+
+	// OnRetry is called before waiting for the next attempt (optional).
+	// Lets callers reflect retry progress in their own displays.
+	OnRetry func(RetryEvent)
+}
+
+// RetryEvent describes an upcoming retry, so long operations don't look hung.
+type RetryEvent struct {
+	Attempt     int           // Next attempt number (2..MaxAttempts)
+	MaxAttempts int           // Maximum number of attempts
+	Delay       time.Duration // Total wait before the next attempt (jitter included)
+	Jitter      time.Duration // Random portion of Delay
+	Reason      string        // Short classification of the failure (e.g., "throttled")
+	NextAt      time.Time     // When the next attempt starts
+	Err         error         // Error of the failed attempt
 }
 
 // Retryer interface defines the contract for retry implementations.
@@ -124,15 +139,29 @@ func (e *exponentialBackoff) Do(ctx context.Context, fn func() error) error {
 		}
 
 		// Calculate delay for next attempt
-		delay := e.calculateDelay(attempt)
+		delay, jitter := e.computeDelay(attempt)
+		event := RetryEvent{
+			Attempt:     attempt + 1,
+			MaxAttempts: e.config.MaxAttempts,
+			Delay:       delay,
+			Jitter:      jitter,
+			Reason:      retryReason(err),
+			NextAt:      time.Now().Add(delay),
+			Err:         err,
+		}
 
-		// Log retry warning
+		// Log retry warning with next attempt ETA
 		e.log.Warn("Operation attempt failed, retrying",
-			"attempt", attempt,
-			"max_attempts", e.config.MaxAttempts,
-			"delay_seconds", delay.Seconds(),
-			"error", err.Error(),
-			"retryable", isRetryableError(err))
+			"next_attempt", fmt.Sprintf("%d/%d", event.Attempt, event.MaxAttempts),
+			"wait", delay.Round(time.Millisecond).String(),
+			"jitter", jitter.Round(time.Millisecond).String(),
+			"reason", event.Reason,
+			"next_attempt_at", event.NextAt.Format(time.TimeOnly),
+			"error", err.Error())
+
+		if e.config.OnRetry != nil {
+			e.config.OnRetry(event)
+		}
 
 		// Wait for the delay (with possibility of cancellation)
 		select {
@@ -157,29 +186,71 @@ func (e *exponentialBackoff) Do(ctx context.Context, fn func() error) error {
 
 // calculateDelay calculates exponential delay with optional jitter.
 func (e *exponentialBackoff) calculateDelay(attempt int) time.Duration {
+	delay, _ := e.computeDelay(attempt)
+	return delay
+}
+
+// computeDelay returns the delay for the next attempt and its jitter portion.
+func (e *exponentialBackoff) computeDelay(attempt int) (delay, jitter time.Duration) {
+	// #nosec G404 - Using math/rand for jitter is acceptable (not cryptographic)
+	return backoffDelay(e.config, attempt, rand.Float64)
+}
+
+// backoffDelay returns the delay of config after a failed attempt and its
+// jitter portion, drawing the jitter from random (values in [0, 1)).
+func backoffDelay(config RetryConfig, attempt int, random func() float64) (delay, jitter time.Duration) {
 	// Exponential backoff formula: baseDelay * 2^(attempt-1)
 	// attempt=1: 1s * 2^0 = 1s
 	// attempt=2: 1s * 2^1 = 2s
 	// attempt=3: 1s * 2^2 = 4s
-	exponentialDelay := float64(e.config.BaseDelay) * math.Pow(2, float64(attempt-1))
+	exponentialDelay := float64(config.BaseDelay) * math.Pow(2, float64(attempt-1))
 
 	// Apply maximum limit
-	if exponentialDelay > float64(e.config.MaxDelay) {
-		exponentialDelay = float64(e.config.MaxDelay)
+	if exponentialDelay > float64(config.MaxDelay) {
+		exponentialDelay = float64(config.MaxDelay)
 	}
 
-	delay := time.Duration(exponentialDelay)
+	delay = time.Duration(exponentialDelay)
 
 	// JITTER: Add randomness to avoid "thundering herd"
-	if e.config.Jitter {
+	if config.Jitter {
 		// Add up to 25% random variation
 		jitterRange := float64(delay) * 0.25
-		// #nosec G404 - Using math/rand for jitter is acceptable (not cryptographic)
-		jitter := time.Duration(rand.Float64() * jitterRange)
+		jitter = time.Duration(random() * jitterRange)
 		delay += jitter
 	}
 
-	return delay
+	return delay, jitter
+}
+
+// retryReasons maps error fragments to short, people-friendly retry reasons.
+// Checked in order; the first match wins.
+var retryReasons = []struct {
+	fragment string
+	reason   string
+}{
+	{"throttl", "throttled"},
+	{"rate limit", "throttled"},
+	{"too many requests", "throttled"},
+	{"timeout", "timeout"},
+	{"deadline exceeded", "timeout"},
+	{"connection refused", "connection refused"},
+	{"network is unreachable", "network unreachable"},
+	{"service unavailable", "service unavailable"},
+	{"bad gateway", "bad gateway"},
+	{"internal server error", "server error"},
+	{"temporary failure", "temporary failure"},
+}
+
+// retryReason classifies an error into a short reason for retry progress logs.
+func retryReason(err error) string {
+	errStr := strings.ToLower(err.Error())
+	for _, r := range retryReasons {
+		if strings.Contains(errStr, r.fragment) {
+			return r.reason
+		}
+	}
+	return "transient error"
 }
 
 // isRetryableError determines which errors should trigger retry and which are permanent.
@@ -221,6 +292,7 @@ func isRetryableError(err error) bool {
 		"invalid argument",
 		"bad request",
 		"invalid credentials",
+		"read-only mode",
 	}
 
 	for _, nonRetryable := range nonRetryableErrors {
@@ -401,6 +401,79 @@ func TestJitterVariation(t *testing.T) {
 	}
 }
 
+// TestOnRetryEvents verifies retry progress events (attempt N/M, wait, reason)
+func TestOnRetryEvents(t *testing.T) {
+	var events []RetryEvent
+	config := RetryConfig{
+		MaxAttempts: 3,
+		BaseDelay:   10 * time.Millisecond,
+		MaxDelay:    100 * time.Millisecond,
+		Jitter:      false,
+		OnRetry: func(event RetryEvent) {
+			events = append(events, event)
+		},
+	}
+
+	retryer := New(config)
+	callCount := 0
+	err := retryer.Do(context.Background(), func() error {
+		callCount++
+		if callCount < 3 {
+			return errors.New("ThrottlingException: Rate exceeded")
+		}
+		return nil
+	})
+
+	if err != nil {
+		t.Fatalf("Expected success, got %v", err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("Expected 2 retry events, got %d", len(events))
+	}
+
+	for i, event := range events {
+		if event.Attempt != i+2 || event.MaxAttempts != 3 {
+			t.Errorf("Event %d: expected attempt %d/3, got %d/%d", i, i+2, event.Attempt, event.MaxAttempts)
+		}
+		if event.Reason != "throttled" {
+			t.Errorf("Event %d: expected reason throttled, got %q", i, event.Reason)
+		}
+		if event.Jitter != 0 {
+			t.Errorf("Event %d: expected no jitter, got %v", i, event.Jitter)
+		}
+		if event.NextAt.IsZero() || event.Err == nil {
+			t.Errorf("Event %d: expected NextAt and Err to be set", i)
+		}
+	}
+	if events[1].Delay != 2*events[0].Delay {
+		t.Errorf("Expected exponential delays, got %v then %v", events[0].Delay, events[1].Delay)
+	}
+}
+
+// TestRetryReason verifies people-friendly retry reason classification
+func TestRetryReason(t *testing.T) {
+	tests := []struct {
+		err      string
+		expected string
+	}{
+		{"ThrottlingException: Rate exceeded", "throttled"},
+		{"server returned 429 Too Many Requests", "throttled"},
+		{"dial tcp 10.0.0.1:443: i/o timeout", "timeout"},
+		{"context deadline exceeded", "timeout"},
+		{"dial tcp: connection refused", "connection refused"},
+		{"server returned 503 Service Unavailable", "service unavailable"},
+		{"something odd happened", "transient error"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.err, func(t *testing.T) {
+			if got := retryReason(errors.New(tt.err)); got != tt.expected {
+				t.Errorf("retryReason(%q) = %q, want %q", tt.err, got, tt.expected)
+			}
+		})
+	}
+}
+
 // TestPredefinedPolicies verifies predefined policies have correct values
 func TestPredefinedPolicies(t *testing.T) {
 	tests := []struct {
@@ -0,0 +1,122 @@
+package retry
+
+import (
+	"fmt"
+	"math/rand"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Policies are the predefined policies by name (see PolicyByName).
+var Policies = map[string]RetryConfig{
+	"ssm":     SSMPolicy,
+	"ec2":     EC2Policy,
+	"network": NetworkPolicy,
+}
+
+// PolicyByName returns a predefined policy ("ssm", "ec2" or "network").
+func PolicyByName(name string) (RetryConfig, error) {
+	policy, ok := Policies[name]
+	if !ok {
+		names := make([]string, 0, len(Policies))
+		for n := range Policies {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return RetryConfig{}, fmt.Errorf("unknown retry policy %q (supported: %s)", name, strings.Join(names, ", "))
+	}
+	return policy, nil
+}
+
+// SimulationConfig describes a Monte-Carlo simulation of a retry policy.
+type SimulationConfig struct {
+	Policy      RetryConfig
+	FailureRate float64       // Probability of each attempt failing with a retryable error (0-1)
+	Operations  int           // Operations simulated
+	CallTime    time.Duration // Duration of each API call (0 = instantaneous)
+	Concurrency int           // Operations running in parallel, for the wall time (0 = 1)
+	Seed        int64         // Random seed (same seed = same result)
+}
+
+// SimulationResult is the outcome of Simulate.
+type SimulationResult struct {
+	Operations int
+	Succeeded  int
+	Exhausted  int // Failed after MaxAttempts
+	APICalls   int
+	CallsPerOp float64
+
+	TotalTime time.Duration // Sum of the operation times (calls + waits)
+	WallTime  time.Duration // Elapsed time with Concurrency operations in parallel
+
+	// Backoff wait per operation
+	WaitP50 time.Duration
+	WaitP90 time.Duration
+	WaitP99 time.Duration
+	WaitMax time.Duration
+}
+
+// Simulate runs config.Operations operations through the policy, each
+// attempt failing independently with config.FailureRate, and returns the
+// call volume and time spent. Delays follow the same backoff (and jitter)
+// as the retryer; nothing actually sleeps.
+func Simulate(config SimulationConfig) (SimulationResult, error) {
+	switch {
+	case config.Operations < 1:
+		return SimulationResult{}, fmt.Errorf("operations must be at least 1")
+	case config.FailureRate < 0 || config.FailureRate > 1:
+		return SimulationResult{}, fmt.Errorf("failure rate must be between 0 and 1")
+	case config.Policy.MaxAttempts < 1:
+		return SimulationResult{}, fmt.Errorf("max attempts must be at least 1")
+	case config.CallTime < 0:
+		return SimulationResult{}, fmt.Errorf("call time must not be negative")
+	}
+	concurrency := max(config.Concurrency, 1)
+
+	// #nosec G404 - Simulation only, not cryptographic
+	random := rand.New(rand.NewSource(config.Seed))
+	result := SimulationResult{Operations: config.Operations}
+	waits := make([]time.Duration, config.Operations)
+	workers := make([]time.Duration, concurrency) // Time each worker is busy until
+
+	for op := range config.Operations {
+		var elapsed time.Duration
+		for attempt := 1; ; attempt++ {
+			result.APICalls++
+			elapsed += config.CallTime
+			if random.Float64() >= config.FailureRate {
+				result.Succeeded++
+				break
+			}
+			if attempt == config.Policy.MaxAttempts {
+				result.Exhausted++
+				break
+			}
+			delay, _ := backoffDelay(config.Policy, attempt, random.Float64)
+			waits[op] += delay
+			elapsed += delay
+		}
+		result.TotalTime += elapsed
+
+		// Next operation goes to the first worker free
+		free := slices.Index(workers, slices.Min(workers))
+		workers[free] += elapsed
+	}
+
+	result.CallsPerOp = float64(result.APICalls) / float64(config.Operations)
+	result.WallTime = slices.Max(workers)
+	slices.Sort(waits)
+	result.WaitP50 = percentile(waits, 50)
+	result.WaitP90 = percentile(waits, 90)
+	result.WaitP99 = percentile(waits, 99)
+	result.WaitMax = waits[len(waits)-1]
+	return result, nil
+}
+
+// percentile returns the p-th percentile of sorted (nearest rank).
+func percentile(sorted []time.Duration, p int) time.Duration {
+	rank := (p*len(sorted) + 99) / 100
+	return sorted[max(rank, 1)-1]
+}
@@ -0,0 +1,101 @@
+package retry
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSimulate(t *testing.T) {
+	policy := RetryConfig{MaxAttempts: 3, BaseDelay: time.Second, MaxDelay: 10 * time.Second}
+
+	tests := []struct {
+		name          string
+		config        SimulationConfig
+		wantCalls     int
+		wantExhausted int
+		wantWaitMax   time.Duration
+		wantWallTime  time.Duration
+	}{
+		{
+			name:         "no failures",
+			config:       SimulationConfig{Policy: policy, Operations: 10, CallTime: time.Second, Concurrency: 5},
+			wantCalls:    10,
+			wantWallTime: 2 * time.Second,
+		},
+		{
+			name:          "every attempt fails",
+			config:        SimulationConfig{Policy: policy, FailureRate: 1, Operations: 4, CallTime: time.Second},
+			wantCalls:     12,
+			wantExhausted: 4,
+			wantWaitMax:   3 * time.Second, // 1s + 2s before attempts 2 and 3
+			wantWallTime:  4 * (3*time.Second + 3*time.Second),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Simulate(tt.config)
+			if err != nil {
+				t.Fatalf("Simulate() error = %v", err)
+			}
+			if result.APICalls != tt.wantCalls || result.Exhausted != tt.wantExhausted {
+				t.Errorf("calls = %d, exhausted = %d, want %d, %d", result.APICalls, result.Exhausted, tt.wantCalls, tt.wantExhausted)
+			}
+			if result.WaitMax != tt.wantWaitMax || result.WallTime != tt.wantWallTime {
+				t.Errorf("wait max = %v, wall time = %v, want %v, %v", result.WaitMax, result.WallTime, tt.wantWaitMax, tt.wantWallTime)
+			}
+		})
+	}
+}
+
+// TestSimulate_Distribution tests the expected call volume and that the
+// same seed reproduces the result
+func TestSimulate_Distribution(t *testing.T) {
+	config := SimulationConfig{Policy: SSMPolicy, FailureRate: 0.2, Operations: 20000, Seed: 42}
+
+	result, err := Simulate(config)
+	if err != nil {
+		t.Fatalf("Simulate() error = %v", err)
+	}
+
+	// Expected calls per operation: 1 + 0.2 + 0.04 = 1.24
+	if result.CallsPerOp < 1.2 || result.CallsPerOp > 1.28 {
+		t.Errorf("CallsPerOp = %.3f, want about 1.24", result.CallsPerOp)
+	}
+	if result.WaitP50 != 0 || result.WaitP99 == 0 || result.WaitP99 > result.WaitMax {
+		t.Errorf("percentiles p50=%v p99=%v max=%v, want 0 < p99 <= max", result.WaitP50, result.WaitP99, result.WaitMax)
+	}
+	if again, _ := Simulate(config); again != result {
+		t.Errorf("same seed gave %+v, then %+v", result, again)
+	}
+}
+
+func TestSimulate_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  SimulationConfig
+		wantErr string
+	}{
+		{name: "no operations", config: SimulationConfig{Policy: SSMPolicy}, wantErr: "operations"},
+		{name: "failure rate", config: SimulationConfig{Policy: SSMPolicy, Operations: 1, FailureRate: 1.5}, wantErr: "failure rate"},
+		{name: "max attempts", config: SimulationConfig{Operations: 1}, wantErr: "max attempts"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Simulate(tt.config); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPolicyByName(t *testing.T) {
+	if policy, err := PolicyByName("ec2"); err != nil || policy.MaxAttempts != EC2Policy.MaxAttempts {
+		t.Errorf("PolicyByName(ec2) = %+v, %v", policy, err)
+	}
+	if _, err := PolicyByName("s3"); err == nil || !strings.Contains(err.Error(), "ec2, network, ssm") {
+		t.Errorf("PolicyByName(s3) error = %v, want the supported policies", err)
+	}
+}
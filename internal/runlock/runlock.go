@@ -0,0 +1,258 @@
+// Package runlock prevents two opsmaster processes from using the same run
+// files (e.g., the --report file) at the same time.
+//
+// A lock is a "<file>.lock" file next to the protected file. It is held with
+// an advisory OS lock (flock / LockFileEx), released automatically when the
+// process dies, and records the owner (PID, host, run ID) plus a heartbeat
+// refreshed while the run is alive. The heartbeat detects live runs on other
+// hosts sharing the file (e.g., NFS home directories), where the OS lock is
+// not reliable.
+package runlock
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// DefaultHeartbeat is the interval between heartbeat updates.
+const DefaultHeartbeat = 15 * time.Second
+
+// staleHeartbeats is the number of missed heartbeats after which the owner
+// of a lock file is considered dead.
+const staleHeartbeats = 4
+
+// Info describes the owner of a lock, as stored in the lock file.
+type Info struct {
+	PID       int       `json:"pid"`
+	Host      string    `json:"host"`
+	RunID     string    `json:"run_id,omitempty"`
+	Operator  string    `json:"operator,omitempty"`
+	StartedAt time.Time `json:"started_at"`
+	Heartbeat time.Time `json:"heartbeat"`
+}
+
+// Options configures Acquire.
+type Options struct {
+	RunID     string        // Run ID recorded in the lock file
+	Operator  string        // Operator recorded in the lock file
+	Force     bool          // Take the lock even if another live run holds it
+	Heartbeat time.Duration // Heartbeat interval (default: DefaultHeartbeat)
+}
+
+// HeldError is returned by Acquire when another live run holds the lock.
+type HeldError struct {
+	Target string // Protected file
+	Owner  *Info  // Owner of the lock (nil if the lock file could not be read)
+}
+
+func (e *HeldError) Error() string {
+	if e.Owner == nil {
+		return fmt.Sprintf("%s is in use by another opsmaster run (lock %s)", e.Target, lockPath(e.Target))
+	}
+	return fmt.Sprintf("%s is in use by another opsmaster run (pid %d on %s, run %s, started %s, last heartbeat %s ago)",
+		e.Target, e.Owner.PID, e.Owner.Host, e.Owner.RunID,
+		e.Owner.StartedAt.Format(time.RFC3339), time.Since(e.Owner.Heartbeat).Round(time.Second))
+}
+
+// Lock is a held run lock. Release it when the run finishes.
+type Lock struct {
+	path     string
+	file     *os.File
+	locked   bool // OS lock held (false when forced over a live run)
+	info     Info
+	previous *Info
+
+	mu       sync.Mutex
+	stop     chan struct{}
+	done     chan struct{}
+	released bool
+}
+
+// Acquire locks target for this process. Returns a *HeldError when another
+// live run holds it, unless opts.Force is set. A lock left by a dead run is
+// taken over (see Previous).
+func Acquire(target string, opts Options) (*Lock, error) {
+	if opts.Heartbeat <= 0 {
+		opts.Heartbeat = DefaultHeartbeat
+	}
+	path := lockPath(target)
+
+	file, locked, err := openLocked(path)
+	if err != nil {
+		return nil, err
+	}
+
+	owner := readInfo(file)
+	hostname, _ := os.Hostname()
+	// Without the OS lock, the owner is a live process on this host; with it,
+	// a fresh heartbeat from another host still means a live run
+	live := !locked || (owner != nil && owner.Host != hostname &&
+		time.Since(owner.Heartbeat) < staleHeartbeats*opts.Heartbeat)
+	if live && !opts.Force {
+		if locked {
+			_ = unlockFile(file)
+		}
+		_ = file.Close()
+		return nil, &HeldError{Target: target, Owner: owner}
+	}
+
+	now := time.Now()
+	lock := &Lock{
+		path:   path,
+		file:   file,
+		locked: locked,
+		info: Info{
+			PID:       os.Getpid(),
+			Host:      hostname,
+			RunID:     opts.RunID,
+			Operator:  opts.Operator,
+			StartedAt: now,
+			Heartbeat: now,
+		},
+		previous: owner,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	if err := lock.write(); err != nil {
+		lock.close()
+		return nil, err
+	}
+
+	go lock.heartbeat(opts.Heartbeat)
+	return lock, nil
+}
+
+// Previous returns the owner recorded in the lock file before it was taken
+// (a dead run, or a live one with Force), or nil if the file was free.
+func (l *Lock) Previous() *Info {
+	return l.previous
+}
+
+// Path returns the lock file path.
+func (l *Lock) Path() string {
+	return l.path
+}
+
+// Release stops the heartbeat, removes the lock file and releases the OS
+// lock. Safe to call more than once.
+func (l *Lock) Release() error {
+	l.mu.Lock()
+	if l.released {
+		l.mu.Unlock()
+		return nil
+	}
+	l.released = true
+	l.mu.Unlock()
+
+	close(l.stop)
+	<-l.done
+
+	// Remove while still holding the OS lock, so no other run locks the old file
+	err := os.Remove(l.path)
+	if errors.Is(err, os.ErrNotExist) {
+		err = nil
+	}
+	l.close()
+	return err
+}
+
+// heartbeat refreshes the heartbeat in the lock file until Release.
+func (l *Lock) heartbeat(interval time.Duration) {
+	defer close(l.done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-l.stop:
+			return
+		case now := <-ticker.C:
+			l.info.Heartbeat = now
+			_ = l.write()
+		}
+	}
+}
+
+// write replaces the lock file content with the owner info.
+func (l *Lock) write() error {
+	data, err := json.Marshal(l.info)
+	if err != nil {
+		return err
+	}
+	if err := l.file.Truncate(0); err != nil {
+		return fmt.Errorf("failed to write lock file %s: %w", l.path, err)
+	}
+	if _, err := l.file.WriteAt(append(data, '\n'), 0); err != nil {
+		return fmt.Errorf("failed to write lock file %s: %w", l.path, err)
+	}
+	return l.file.Sync()
+}
+
+func (l *Lock) close() {
+	if l.locked {
+		_ = unlockFile(l.file)
+	}
+	_ = l.file.Close()
+}
+
+// openLocked opens (creating if needed) the lock file and tries to take the
+// OS lock without blocking. locked is false when another process holds it.
+func openLocked(path string) (file *os.File, locked bool, err error) {
+	// Retry once if the file was replaced (removed by its releasing owner)
+	// between opening and locking it
+	for attempt := 0; ; attempt++ {
+		file, err = os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to open lock file %s: %w", path, err)
+		}
+
+		locked, err = tryLockFile(file)
+		if err != nil {
+			_ = file.Close()
+			return nil, false, fmt.Errorf("failed to lock %s: %w", path, err)
+		}
+		if !locked || attempt > 0 || samePath(file, path) {
+			return file, locked, nil
+		}
+		_ = unlockFile(file)
+		_ = file.Close()
+	}
+}
+
+// samePath reports whether file is still the file at path.
+func samePath(file *os.File, path string) bool {
+	opened, err := file.Stat()
+	if err != nil {
+		return false
+	}
+	current, err := os.Stat(path)
+	if err != nil {
+		return false
+	}
+	return os.SameFile(opened, current)
+}
+
+// readInfo reads the owner stored in the lock file (nil if empty or invalid).
+func readInfo(file *os.File) *Info {
+	stat, err := file.Stat()
+	if err != nil || stat.Size() == 0 {
+		return nil
+	}
+	data := make([]byte, stat.Size())
+	if _, err := file.ReadAt(data, 0); err != nil {
+		return nil
+	}
+	var info Info
+	if err := json.Unmarshal(data, &info); err != nil {
+		return nil
+	}
+	return &info
+}
+
+func lockPath(target string) string {
+	return target + ".lock"
+}
@@ -0,0 +1,149 @@
+package runlock
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeLockFile stores a lock file as left by another run.
+func writeLockFile(t *testing.T, target string, info Info) {
+	t.Helper()
+	data, err := json.Marshal(info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(lockPath(target), data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestAcquire_Exclusive tests that a held lock rejects a second run until released
+func TestAcquire_Exclusive(t *testing.T) {
+	// ARRANGE
+	target := filepath.Join(t.TempDir(), "report.json")
+	lock, err := Acquire(target, Options{RunID: "run-1"})
+	if err != nil {
+		t.Fatalf("Acquire() unexpected error: %v", err)
+	}
+
+	// ACT
+	_, err = Acquire(target, Options{RunID: "run-2"})
+
+	// ASSERT
+	var held *HeldError
+	if !errors.As(err, &held) {
+		t.Fatalf("second Acquire() error = %v, want *HeldError", err)
+	}
+	if held.Owner == nil || held.Owner.PID != os.Getpid() || held.Owner.RunID != "run-1" {
+		t.Errorf("HeldError.Owner = %+v, want this process with run-1", held.Owner)
+	}
+
+	if err := lock.Release(); err != nil {
+		t.Fatalf("Release() unexpected error: %v", err)
+	}
+	if _, err := os.Stat(lockPath(target)); !os.IsNotExist(err) {
+		t.Errorf("lock file still exists after Release: %v", err)
+	}
+	if err := lock.Release(); err != nil {
+		t.Errorf("second Release() unexpected error: %v", err)
+	}
+
+	again, err := Acquire(target, Options{RunID: "run-2"})
+	if err != nil {
+		t.Fatalf("Acquire() after Release unexpected error: %v", err)
+	}
+	_ = again.Release()
+}
+
+// TestAcquire_Force tests taking over a live lock with Force
+func TestAcquire_Force(t *testing.T) {
+	target := filepath.Join(t.TempDir(), "report.json")
+	first, err := Acquire(target, Options{RunID: "run-1"})
+	if err != nil {
+		t.Fatalf("Acquire() unexpected error: %v", err)
+	}
+	defer first.Release()
+
+	forced, err := Acquire(target, Options{RunID: "run-2", Force: true})
+	if err != nil {
+		t.Fatalf("Acquire(Force) unexpected error: %v", err)
+	}
+	defer forced.Release()
+
+	if prev := forced.Previous(); prev == nil || prev.RunID != "run-1" {
+		t.Errorf("Previous() = %+v, want run-1", prev)
+	}
+}
+
+// TestAcquire_OtherHost tests heartbeat-based detection of runs on other hosts
+func TestAcquire_OtherHost(t *testing.T) {
+	tests := []struct {
+		name      string
+		heartbeat time.Duration // Age of the other run's heartbeat
+		wantHeld  bool
+	}{
+		{name: "live run", heartbeat: time.Second, wantHeld: true},
+		{name: "stale run", heartbeat: time.Hour, wantHeld: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// ARRANGE
+			target := filepath.Join(t.TempDir(), "report.json")
+			writeLockFile(t, target, Info{
+				PID:       4242,
+				Host:      "other-host.invalid",
+				RunID:     "run-other",
+				StartedAt: time.Now().Add(-2 * time.Hour),
+				Heartbeat: time.Now().Add(-tt.heartbeat),
+			})
+
+			// ACT
+			lock, err := Acquire(target, Options{RunID: "run-1"})
+
+			// ASSERT
+			var held *HeldError
+			if tt.wantHeld {
+				if !errors.As(err, &held) || held.Owner.Host != "other-host.invalid" {
+					t.Fatalf("Acquire() error = %v, want *HeldError from other-host.invalid", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Acquire() unexpected error: %v", err)
+			}
+			defer lock.Release()
+			if prev := lock.Previous(); prev == nil || prev.RunID != "run-other" {
+				t.Errorf("Previous() = %+v, want run-other", prev)
+			}
+		})
+	}
+}
+
+// TestLock_Heartbeat tests that the heartbeat is refreshed while held
+func TestLock_Heartbeat(t *testing.T) {
+	target := filepath.Join(t.TempDir(), "report.json")
+	lock, err := Acquire(target, Options{Heartbeat: 10 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("Acquire() unexpected error: %v", err)
+	}
+	defer lock.Release()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		time.Sleep(20 * time.Millisecond)
+		data, err := os.ReadFile(lock.Path())
+		if err != nil {
+			continue
+		}
+		var info Info
+		if json.Unmarshal(data, &info) == nil && info.Heartbeat.After(info.StartedAt) {
+			return
+		}
+	}
+	t.Error("heartbeat was not refreshed")
+}
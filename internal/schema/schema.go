@@ -0,0 +1,283 @@
+// Package schema embeds the JSON schemas of the files opsmaster writes for
+// other tools (run reports) and validates documents against them, so
+// downstream consumers can rely on a stable, versioned structure.
+//
+// The validator implements the JSON Schema keywords used by the embedded
+// schemas: type, const, enum, required, properties, additionalProperties,
+// items, minimum, format (date-time, duration) and local $ref.
+package schema
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// KindReport is the run report written by install --report.
+const KindReport = "report"
+
+//go:embed schemas/*.json
+var files embed.FS
+
+// Violation is a document value that doesn't match the schema.
+type Violation struct {
+	Path    string // JSON pointer of the value (e.g., /results/3/status)
+	Message string
+}
+
+// String returns "path: message".
+func (v Violation) String() string {
+	return v.Path + ": " + v.Message
+}
+
+// Schema returns the embedded JSON schema of a file kind and schema version.
+func Schema(kind string, version int) ([]byte, error) {
+	data, err := files.ReadFile(fmt.Sprintf("schemas/%s.v%d.json", kind, version))
+	if err != nil {
+		return nil, fmt.Errorf("no schema for %s schema_version %d", kind, version)
+	}
+	return data, nil
+}
+
+// Validate checks a document of the given kind against the schema of its
+// schema_version field. Returns the schema version and the violations found
+// (none = valid); errors mean the document could not be checked at all
+// (invalid JSON, missing or unknown schema_version).
+func Validate(kind string, data []byte) (int, []Violation, error) {
+	doc, err := decode(data)
+	if err != nil {
+		return 0, nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+
+	object, ok := doc.(map[string]any)
+	if !ok {
+		return 0, nil, fmt.Errorf("document is not a JSON object")
+	}
+	number, ok := object["schema_version"].(json.Number)
+	if !ok {
+		return 0, nil, fmt.Errorf("missing schema_version field")
+	}
+	version, err := number.Int64()
+	if err != nil {
+		return 0, nil, fmt.Errorf("invalid schema_version %s", number)
+	}
+
+	raw, err := Schema(kind, int(version))
+	if err != nil {
+		return int(version), nil, err
+	}
+	root, err := decode(raw)
+	if err != nil {
+		return int(version), nil, fmt.Errorf("embedded schema is invalid: %w", err)
+	}
+
+	v := &validator{root: root.(map[string]any)}
+	v.validate(v.root, doc, "")
+	sort.SliceStable(v.violations, func(a, b int) bool { return v.violations[a].Path < v.violations[b].Path })
+	return int(version), v.violations, nil
+}
+
+// decode parses JSON keeping numbers as json.Number (integer checks).
+func decode(data []byte) (any, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var doc any
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// validator walks a document alongside its schema.
+type validator struct {
+	root       map[string]any
+	violations []Violation
+}
+
+func (v *validator) fail(path, format string, args ...any) {
+	if path == "" {
+		path = "/"
+	}
+	v.violations = append(v.violations, Violation{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+// validate checks value against schema, recording violations under path.
+func (v *validator) validate(schema map[string]any, value any, path string) {
+	if ref, ok := schema["$ref"].(string); ok {
+		resolved, err := v.resolve(ref)
+		if err != nil {
+			v.fail(path, "%v", err)
+			return
+		}
+		schema = resolved
+	}
+
+	if expected, ok := schema["const"]; ok && !equal(expected, value) {
+		v.fail(path, "must be %v", expected)
+	}
+	if options, ok := schema["enum"].([]any); ok && !containsValue(options, value) {
+		v.fail(path, "must be one of %v", options)
+	}
+	if typ, ok := schema["type"].(string); ok && !hasType(value, typ) {
+		v.fail(path, "must be of type %s, got %s", typ, typeName(value))
+		return
+	}
+
+	switch value := value.(type) {
+	case map[string]any:
+		v.validateObject(schema, value, path)
+	case []any:
+		if items, ok := schema["items"].(map[string]any); ok {
+			for i, item := range value {
+				v.validate(items, item, fmt.Sprintf("%s/%d", path, i))
+			}
+		}
+	case json.Number:
+		if minimum, ok := schema["minimum"].(json.Number); ok {
+			if n, _ := value.Float64(); n < mustFloat(minimum) {
+				v.fail(path, "must be >= %s", minimum)
+			}
+		}
+	case string:
+		if format, ok := schema["format"].(string); ok {
+			if err := checkFormat(format, value); err != nil {
+				v.fail(path, "%v", err)
+			}
+		}
+	}
+}
+
+// validateObject checks required fields, properties and additionalProperties.
+func (v *validator) validateObject(schema, object map[string]any, path string) {
+	if required, ok := schema["required"].([]any); ok {
+		for _, name := range required {
+			if _, present := object[name.(string)]; !present {
+				v.fail(path, "missing required field %q", name)
+			}
+		}
+	}
+
+	properties, _ := schema["properties"].(map[string]any)
+	for name, value := range object {
+		childPath := path + "/" + escapePointer(name)
+		if property, ok := properties[name].(map[string]any); ok {
+			v.validate(property, value, childPath)
+			continue
+		}
+		switch additional := schema["additionalProperties"].(type) {
+		case bool:
+			if !additional {
+				v.fail(childPath, "unknown field")
+			}
+		case map[string]any:
+			v.validate(additional, value, childPath)
+		}
+	}
+}
+
+// resolve follows a local reference such as "#/$defs/entry".
+func (v *validator) resolve(ref string) (map[string]any, error) {
+	if !strings.HasPrefix(ref, "#/") {
+		return nil, fmt.Errorf("unsupported $ref %q", ref)
+	}
+	var node any = v.root
+	for _, part := range strings.Split(strings.TrimPrefix(ref, "#/"), "/") {
+		object, ok := node.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("unresolvable $ref %q", ref)
+		}
+		node = object[part]
+	}
+	resolved, ok := node.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("unresolvable $ref %q", ref)
+	}
+	return resolved, nil
+}
+
+// hasType reports whether value is of the JSON Schema type typ.
+func hasType(value any, typ string) bool {
+	switch typ {
+	case "integer":
+		number, ok := value.(json.Number)
+		if !ok {
+			return false
+		}
+		_, err := number.Int64()
+		return err == nil
+	case "number":
+		_, ok := value.(json.Number)
+		return ok
+	default:
+		return typeName(value) == typ
+	}
+}
+
+// typeName returns the JSON type of a decoded value.
+func typeName(value any) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case json.Number:
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+// checkFormat validates the string formats used by the schemas.
+func checkFormat(format, value string) error {
+	switch format {
+	case "date-time":
+		if _, err := time.Parse(time.RFC3339Nano, value); err != nil {
+			return fmt.Errorf("must be an RFC 3339 date-time")
+		}
+	case "duration":
+		if _, err := time.ParseDuration(value); err != nil {
+			return fmt.Errorf("must be a duration (e.g., 1m24.2s)")
+		}
+	}
+	return nil
+}
+
+// equal compares decoded JSON values, numbers by value.
+func equal(a, b any) bool {
+	an, aok := a.(json.Number)
+	bn, bok := b.(json.Number)
+	if aok && bok {
+		return mustFloat(an) == mustFloat(bn)
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func containsValue(options []any, value any) bool {
+	for _, option := range options {
+		if equal(option, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func mustFloat(number json.Number) float64 {
+	f, _ := number.Float64()
+	return f
+}
+
+// escapePointer escapes a JSON pointer token (RFC 6901).
+func escapePointer(token string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(token)
+}
@@ -0,0 +1,176 @@
+package schema
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/estudosdevops/opsmaster/internal/executor"
+)
+
+// validReport returns a report as written by install --report.
+func validReport(t *testing.T) map[string]any {
+	t.Helper()
+
+	start := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	report := &executor.Report{
+		SchemaVersion: executor.ReportSchemaVersion,
+		RunID:         "run-1",
+		Package:       "puppet",
+		StartTime:     start,
+		EndTime:       start.Add(time.Minute),
+		Accounts:      []executor.ReportGroup{{Key: "111", Total: 1, Success: 1, AvgDuration: "1m0s", MaxDuration: "1m0s"}},
+		Regions:       []executor.ReportGroup{{Key: "us-east-1", Total: 1, Success: 1, AvgDuration: "1m0s", MaxDuration: "1m0s"}},
+		Results: []executor.ReportEntry{{
+			InstanceID:  "i-1",
+			Cloud:       "aws",
+			Account:     "111",
+			Region:      "us-east-1",
+			Status:      executor.StatusSuccess.String(),
+			Duration:    "1m0s",
+			ValidatedAt: &start,
+			Phases:      map[string]string{"install": "50s"},
+			Tags:        map[string]string{"puppet": "true"},
+		}, {
+			InstanceID:   "i-2",
+			Cloud:        "aws",
+			Account:      "111",
+			Region:       "us-east-1",
+			Status:       executor.StatusFailed.String(),
+			FailurePhase: "install",
+			ExitCode:     1,
+			ErrorContext: &executor.ErrorContext{Step: "install", OutputTail: []string{"Error: Nothing to do"}, Stderr: "Error: Nothing to do\n"},
+			OutputURLs:   []string{"s3://ssm-output/opsmaster/cmd-1/i-2/"},
+		}},
+		DeprecatedFlags: []executor.ReportDeprecatedFlag{{Flag: "instances-file", Replacement: "inventory"}},
+	}
+
+	data, err := json.Marshal(report)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc map[string]any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatal(err)
+	}
+	return doc
+}
+
+// TestValidate tests report validation against the embedded schema
+//
+// 🎓 CONCEPT: Schema and struct in sync
+// The valid case marshals executor.Report itself, so a field added to the
+// struct without updating the schema (or vice versa) fails here.
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		mutate   func(doc map[string]any)
+		wantPath string // Expected violation path ("" = valid)
+	}{
+		{name: "report written by executor", mutate: func(map[string]any) {}},
+		{
+			name:     "missing required field",
+			mutate:   func(doc map[string]any) { delete(doc, "package") },
+			wantPath: "/",
+		},
+		{
+			name:     "unknown status",
+			mutate:   func(doc map[string]any) { doc["results"].([]any)[0].(map[string]any)["status"] = "DONE" },
+			wantPath: "/results/0/status",
+		},
+		{
+			name:     "invalid date-time",
+			mutate:   func(doc map[string]any) { doc["start_time"] = "yesterday" },
+			wantPath: "/start_time",
+		},
+		{
+			name: "invalid phase duration",
+			mutate: func(doc map[string]any) {
+				doc["results"].([]any)[0].(map[string]any)["phases"] = map[string]any{"install": "slow"}
+			},
+			wantPath: "/results/0/phases/install",
+		},
+		{
+			name:     "negative counter",
+			mutate:   func(doc map[string]any) { doc["accounts"].([]any)[0].(map[string]any)["failed"] = -1 },
+			wantPath: "/accounts/0/failed",
+		},
+		{
+			name:     "deprecated flag without replacement",
+			mutate:   func(doc map[string]any) { delete(doc["deprecated_flags"].([]any)[0].(map[string]any), "replacement") },
+			wantPath: "/deprecated_flags/0",
+		},
+		{
+			name:     "wrong type",
+			mutate:   func(doc map[string]any) { doc["dry_run"] = "no" },
+			wantPath: "/dry_run",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// ARRANGE
+			doc := validReport(t)
+			tt.mutate(doc)
+			data, err := json.Marshal(doc)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// ACT
+			version, violations, err := Validate(KindReport, data)
+
+			// ASSERT
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if version != executor.ReportSchemaVersion {
+				t.Errorf("version = %d, want %d", version, executor.ReportSchemaVersion)
+			}
+			if tt.wantPath == "" {
+				if len(violations) != 0 {
+					t.Errorf("expected no violations, got %v", violations)
+				}
+				return
+			}
+			if len(violations) != 1 || violations[0].Path != tt.wantPath {
+				t.Errorf("expected one violation at %s, got %v", tt.wantPath, violations)
+			}
+		})
+	}
+}
+
+// TestValidate_Errors tests documents that can't be checked against a schema
+func TestValidate_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantErr string
+	}{
+		{name: "invalid JSON", data: `{"schema_version":`, wantErr: "invalid JSON"},
+		{name: "not an object", data: `[]`, wantErr: "not a JSON object"},
+		{name: "missing version", data: `{"package": "puppet"}`, wantErr: "missing schema_version"},
+		{name: "unknown version", data: `{"schema_version": 99}`, wantErr: "no schema for report schema_version 99"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := Validate(KindReport, []byte(tt.data))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+// TestSchema tests that the embedded schema of the current version exists
+func TestSchema(t *testing.T) {
+	data, err := Schema(KindReport, executor.ReportSchemaVersion)
+	if err != nil {
+		t.Fatalf("Schema() error: %v", err)
+	}
+	if !json.Valid(data) {
+		t.Error("embedded schema is not valid JSON")
+	}
+}
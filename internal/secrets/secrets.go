@@ -0,0 +1,113 @@
+// Package secrets resolves secret references used in flag and config values.
+//
+// A value of the form "<scheme>:<reference>" is resolved at runtime by the
+// backend registered for scheme; any other value is returned unchanged, so
+// plain secrets keep working. Example:
+//
+//	--token vault:secret/data/argocd#token
+//
+// Resolved values are cached for the run and registered with the logger so
+// they are redacted from log output.
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/spf13/pflag"
+
+	"github.com/estudosdevops/opsmaster/internal/logger"
+)
+
+// Backend resolves references for one scheme (e.g., "vault").
+type Backend interface {
+	// Resolve returns the secret value for a reference (scheme prefix removed).
+	Resolve(ctx context.Context, ref string) (string, error)
+}
+
+// Resolver resolves secret references using registered backends.
+// Safe for concurrent use; each reference is fetched at most once per run.
+type Resolver struct {
+	mu       sync.Mutex
+	backends map[string]Backend
+	cache    map[string]string
+}
+
+// NewResolver creates a Resolver with the given backends keyed by scheme.
+func NewResolver(backends map[string]Backend) *Resolver {
+	return &Resolver{
+		backends: backends,
+		cache:    make(map[string]string),
+	}
+}
+
+// defaultResolver is the process-wide resolver used by Resolve and ResolveFlags.
+var defaultResolver = NewResolver(map[string]Backend{
+	"vault": NewVaultBackendFromEnv(),
+})
+
+// Resolve resolves value with the process-wide resolver.
+func Resolve(ctx context.Context, value string) (string, error) {
+	return defaultResolver.Resolve(ctx, value)
+}
+
+// ResolveFlags resolves secret references in the named flags, in place.
+// Flags that don't exist or hold plain values are left untouched.
+func ResolveFlags(ctx context.Context, flags *pflag.FlagSet, names ...string) error {
+	return defaultResolver.ResolveFlags(ctx, flags, names...)
+}
+
+// IsReference reports whether value uses a registered scheme.
+func (r *Resolver) IsReference(value string) bool {
+	scheme, _, ok := strings.Cut(value, ":")
+	if !ok {
+		return false
+	}
+	_, registered := r.backends[scheme]
+	return registered
+}
+
+// Resolve returns the secret for a reference, or value unchanged if it isn't one.
+func (r *Resolver) Resolve(ctx context.Context, value string) (string, error) {
+	if !r.IsReference(value) {
+		return value, nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if cached, ok := r.cache[value]; ok {
+		return cached, nil
+	}
+
+	scheme, ref, _ := strings.Cut(value, ":")
+	secret, err := r.backends[scheme].Resolve(ctx, ref)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve secret %s: %w", value, err)
+	}
+
+	logger.RegisterSecret(secret)
+	r.cache[value] = secret
+	return secret, nil
+}
+
+// ResolveFlags resolves secret references in the named flags, in place.
+func (r *Resolver) ResolveFlags(ctx context.Context, flags *pflag.FlagSet, names ...string) error {
+	for _, name := range names {
+		flag := flags.Lookup(name)
+		if flag == nil || !r.IsReference(flag.Value.String()) {
+			continue
+		}
+
+		secret, err := r.Resolve(ctx, flag.Value.String())
+		if err != nil {
+			return fmt.Errorf("--%s: %w", name, err)
+		}
+		if err := flag.Value.Set(secret); err != nil {
+			return fmt.Errorf("--%s: %w", name, err)
+		}
+	}
+	return nil
+}
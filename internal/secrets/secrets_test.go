@@ -0,0 +1,159 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/spf13/pflag"
+
+	"github.com/estudosdevops/opsmaster/internal/logger"
+)
+
+// newVaultServer simulates a Vault server with KV v1/v2 mounts and AppRole auth.
+func newVaultServer(t *testing.T, reads *atomic.Int32) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/auth/approle/login" {
+			var body map[string]string
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			if body["role_id"] != "role" || body["secret_id"] != "secret" {
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = w.Write([]byte(`{"errors":["invalid role or secret ID"]}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"auth":{"client_token":"approle-token"}}`))
+			return
+		}
+
+		if token := r.Header.Get("X-Vault-Token"); token != "root" && token != "approle-token" {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"errors":["permission denied"]}`))
+			return
+		}
+
+		reads.Add(1)
+		switch r.URL.Path {
+		case "/v1/secret/data/puppet":
+			_, _ = w.Write([]byte(`{"data":{"data":{"token":"s3cr3t-kv2","user":"puppet"},"metadata":{"version":3}}}`))
+		case "/v1/kv/argocd":
+			_, _ = w.Write([]byte(`{"data":{"token":"s3cr3t-kv1"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"errors":[]}`))
+		}
+	}))
+}
+
+// TestResolver_Vault tests resolving vault references
+func TestResolver_Vault(t *testing.T) {
+	var reads atomic.Int32
+	server := newVaultServer(t, &reads)
+	defer server.Close()
+
+	tests := []struct {
+		name     string
+		config   VaultConfig
+		value    string
+		expected string
+		wantErr  string
+	}{
+		{name: "plain value unchanged", value: "plain-token", expected: "plain-token"},
+		{name: "kv v2 field", config: VaultConfig{Token: "root"}, value: "vault:secret/data/puppet#token", expected: "s3cr3t-kv2"},
+		{name: "kv v1 single field", config: VaultConfig{Token: "root"}, value: "vault:kv/argocd", expected: "s3cr3t-kv1"},
+		{name: "approle login", config: VaultConfig{RoleID: "role", SecretID: "secret"}, value: "vault:kv/argocd#token", expected: "s3cr3t-kv1"},
+		{name: "missing field", config: VaultConfig{Token: "root"}, value: "vault:secret/data/puppet#password", wantErr: "not found"},
+		{name: "ambiguous field", config: VaultConfig{Token: "root"}, value: "vault:secret/data/puppet", wantErr: "specify one"},
+		{name: "unknown path", config: VaultConfig{Token: "root"}, value: "vault:secret/data/missing#token", wantErr: "404"},
+		{name: "no auth configured", value: "vault:kv/argocd#token", wantErr: "VAULT_TOKEN"},
+		{name: "bad approle", config: VaultConfig{RoleID: "role", SecretID: "wrong"}, value: "vault:kv/argocd#token", wantErr: "invalid role"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// ARRANGE
+			tt.config.Address = server.URL
+			resolver := NewResolver(map[string]Backend{"vault": NewVaultBackend(tt.config, server.Client())})
+
+			// ACT
+			got, err := resolver.Resolve(context.Background(), tt.value)
+
+			// ASSERT
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("Resolve(%q) = %q, want %q", tt.value, got, tt.expected)
+			}
+		})
+	}
+}
+
+// TestResolver_CachesAndRedacts tests per-run caching and log redaction
+func TestResolver_CachesAndRedacts(t *testing.T) {
+	// ARRANGE
+	var reads atomic.Int32
+	server := newVaultServer(t, &reads)
+	defer server.Close()
+	resolver := NewResolver(map[string]Backend{
+		"vault": NewVaultBackend(VaultConfig{Address: server.URL, Token: "root"}, server.Client()),
+	})
+
+	// ACT
+	for range 3 {
+		if _, err := resolver.Resolve(context.Background(), "vault:secret/data/puppet#token"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	// ASSERT
+	if reads.Load() != 1 {
+		t.Errorf("expected 1 vault read (cached), got %d", reads.Load())
+	}
+	if got := logger.Redact("token is s3cr3t-kv2"); got != "token is ***" {
+		t.Errorf("expected secret redacted from logs, got %q", got)
+	}
+}
+
+// TestResolver_ResolveFlags tests in-place resolution of secret-bearing flags
+func TestResolver_ResolveFlags(t *testing.T) {
+	// ARRANGE
+	var reads atomic.Int32
+	server := newVaultServer(t, &reads)
+	defer server.Close()
+	resolver := NewResolver(map[string]Backend{
+		"vault": NewVaultBackend(VaultConfig{Address: server.URL, Token: "root"}, server.Client()),
+	})
+
+	var token, password string
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	flags.StringVar(&token, "token", "", "")
+	flags.StringVar(&password, "password", "", "")
+	if err := flags.Parse([]string{"--token", "vault:kv/argocd#token", "--password", "plain"}); err != nil {
+		t.Fatalf("failed to parse flags: %v", err)
+	}
+
+	// ACT
+	err := resolver.ResolveFlags(context.Background(), flags, "token", "password", "missing")
+
+	// ASSERT
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if token != "s3cr3t-kv1" {
+		t.Errorf("token = %q, want s3cr3t-kv1", token)
+	}
+	if password != "plain" {
+		t.Errorf("password = %q, want plain (unchanged)", password)
+	}
+}
@@ -0,0 +1,200 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/estudosdevops/opsmaster/internal/httpclient"
+)
+
+// VaultConfig configures access to HashiCorp Vault.
+// Authentication uses Token when set, otherwise AppRole (RoleID + SecretID).
+type VaultConfig struct {
+	Address      string // VAULT_ADDR (e.g., https://vault.example.com:8200)
+	Token        string // VAULT_TOKEN
+	Namespace    string // VAULT_NAMESPACE (Vault Enterprise, optional)
+	RoleID       string // VAULT_ROLE_ID (AppRole)
+	SecretID     string // VAULT_SECRET_ID (AppRole)
+	AppRoleMount string // VAULT_APPROLE_MOUNT (default: approle)
+}
+
+// VaultBackend resolves "vault:<path>#<field>" references via the Vault HTTP API.
+// Supports KV v1 and KV v2 mounts (e.g., vault:secret/data/puppet#token).
+type VaultBackend struct {
+	config VaultConfig
+	client *http.Client
+
+	mu    sync.Mutex
+	token string // Token in use (configured or obtained via AppRole login)
+}
+
+// NewVaultBackend creates a Vault backend. The HTTP client defaults to httpclient.Shared().
+func NewVaultBackend(config VaultConfig, client *http.Client) *VaultBackend {
+	if config.AppRoleMount == "" {
+		config.AppRoleMount = "approle"
+	}
+	return &VaultBackend{
+		config: config,
+		client: client,
+		token:  config.Token,
+	}
+}
+
+// NewVaultBackendFromEnv creates a Vault backend configured from VAULT_* environment variables.
+func NewVaultBackendFromEnv() *VaultBackend {
+	return NewVaultBackend(VaultConfig{
+		Address:      os.Getenv("VAULT_ADDR"),
+		Token:        os.Getenv("VAULT_TOKEN"),
+		Namespace:    os.Getenv("VAULT_NAMESPACE"),
+		RoleID:       os.Getenv("VAULT_ROLE_ID"),
+		SecretID:     os.Getenv("VAULT_SECRET_ID"),
+		AppRoleMount: os.Getenv("VAULT_APPROLE_MOUNT"),
+	}, nil)
+}
+
+// vaultResponse is the subset of Vault API responses we use.
+type vaultResponse struct {
+	Data map[string]any `json:"data"`
+	Auth struct {
+		ClientToken string `json:"client_token"`
+	} `json:"auth"`
+	Errors []string `json:"errors"`
+}
+
+// Resolve reads the secret at path and returns the requested field.
+// The field may be omitted when the secret has a single key.
+func (v *VaultBackend) Resolve(ctx context.Context, ref string) (string, error) {
+	if v.config.Address == "" {
+		return "", fmt.Errorf("VAULT_ADDR is not set")
+	}
+
+	path, field, _ := strings.Cut(ref, "#")
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return "", fmt.Errorf("invalid vault reference %q: expected vault:<path>#<field>", ref)
+	}
+
+	token, err := v.authToken(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	response, err := v.do(ctx, http.MethodGet, "/v1/"+path, token, nil)
+	if err != nil {
+		return "", err
+	}
+
+	data := response.Data
+	// KV v2 nests the secret under data.data
+	if nested, ok := data["data"].(map[string]any); ok && strings.Contains(path, "/data/") {
+		data = nested
+	}
+
+	if field == "" {
+		if len(data) != 1 {
+			return "", fmt.Errorf("secret %s has %d fields, specify one with #<field>", path, len(data))
+		}
+		for key := range data {
+			field = key
+		}
+	}
+
+	value, ok := data[field]
+	if !ok {
+		return "", fmt.Errorf("field %q not found in secret %s", field, path)
+	}
+	secret, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("field %q in secret %s is not a string", field, path)
+	}
+	return secret, nil
+}
+
+// authToken returns the configured token or logs in with AppRole once.
+func (v *VaultBackend) authToken(ctx context.Context) (string, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if v.token != "" {
+		return v.token, nil
+	}
+	if v.config.RoleID == "" || v.config.SecretID == "" {
+		return "", fmt.Errorf("vault authentication not configured: set VAULT_TOKEN or VAULT_ROLE_ID and VAULT_SECRET_ID")
+	}
+
+	body, err := json.Marshal(map[string]string{
+		"role_id":   v.config.RoleID,
+		"secret_id": v.config.SecretID,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode approle login: %w", err)
+	}
+
+	response, err := v.do(ctx, http.MethodPost, "/v1/auth/"+strings.Trim(v.config.AppRoleMount, "/")+"/login", "", body)
+	if err != nil {
+		return "", fmt.Errorf("approle login failed: %w", err)
+	}
+	if response.Auth.ClientToken == "" {
+		return "", fmt.Errorf("approle login returned no client token")
+	}
+
+	v.token = response.Auth.ClientToken
+	return v.token, nil
+}
+
+// do performs a Vault API request and decodes the JSON response.
+func (v *VaultBackend) do(ctx context.Context, method, path, token string, body []byte) (*vaultResponse, error) {
+	url := strings.TrimRight(v.config.Address, "/") + path
+
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create vault request: %w", err)
+	}
+	if token != "" {
+		req.Header.Set("X-Vault-Token", token)
+	}
+	if v.config.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", v.config.Namespace)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	client := v.client
+	if client == nil {
+		client = httpclient.Shared()
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("vault request to %s failed: %w", path, err)
+	}
+	defer resp.Body.Close()
+
+	var response vaultResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil && err != io.EOF {
+		return nil, fmt.Errorf("failed to decode vault response from %s: %w", path, err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		detail := strings.Join(response.Errors, "; ")
+		if detail == "" {
+			detail = resp.Status
+		}
+		return nil, fmt.Errorf("vault returned %d for %s: %s", resp.StatusCode, path, detail)
+	}
+
+	return &response, nil
+}
@@ -0,0 +1,173 @@
+package sink
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/estudosdevops/opsmaster/internal/cloud/aws"
+)
+
+const (
+	// DynamoDBKey is the table's partition key (one item per instance).
+	DynamoDBKey = "instance_id"
+
+	// tableActiveTimeout bounds the wait for a newly created table.
+	tableActiveTimeout = 2 * time.Minute
+
+	// tablePollInterval is how often table status is checked while waiting.
+	tablePollInterval = 2 * time.Second
+)
+
+// DynamoDBAPI is the subset of the DynamoDB client used by the sink.
+// Implemented by *aws.DynamoDB; tests provide fakes.
+type DynamoDBAPI interface {
+	Call(ctx context.Context, operation string, input, output any) error
+}
+
+// DynamoDBSink upserts each instance's latest state into a DynamoDB table
+// keyed by instance_id.
+type DynamoDBSink struct {
+	api          DynamoDBAPI
+	table        string
+	createTable  bool
+	pollInterval time.Duration
+}
+
+// NewDynamoDBSink creates a sink writing to table. With createTable, a
+// missing table is created (on-demand billing) before the first write.
+func NewDynamoDBSink(api DynamoDBAPI, table string, createTable bool) *DynamoDBSink {
+	return &DynamoDBSink{
+		api:          api,
+		table:        table,
+		createTable:  createTable,
+		pollInterval: tablePollInterval,
+	}
+}
+
+// attributeValue is a DynamoDB attribute in JSON API form.
+type attributeValue map[string]string
+
+func stringValue(s string) attributeValue {
+	return attributeValue{"S": s}
+}
+
+// item converts a record to a DynamoDB item, leaving out empty attributes.
+func (r Record) item() map[string]attributeValue {
+	item := map[string]attributeValue{
+		DynamoDBKey:  stringValue(r.InstanceID),
+		"status":     stringValue(r.Status),
+		"updated_at": stringValue(r.UpdatedAt.UTC().Format(time.RFC3339)),
+	}
+	optional := map[string]string{
+		"name":     r.Name,
+		"account":  r.Account,
+		"region":   r.Region,
+		"certname": r.Certname,
+		"os":       r.OS,
+		"version":  r.Version,
+		"error":    r.Error,
+		"run_id":   r.RunID,
+	}
+	for name, value := range optional {
+		if value != "" {
+			item[name] = stringValue(value)
+		}
+	}
+	return item
+}
+
+// Write upserts every record. Individual failures don't stop the others;
+// they are returned joined.
+func (s *DynamoDBSink) Write(ctx context.Context, records []Record) error {
+	if len(records) == 0 {
+		return nil
+	}
+	if s.createTable {
+		if err := s.ensureTable(ctx); err != nil {
+			return err
+		}
+	}
+
+	var errs []error
+	for _, record := range records {
+		input := map[string]any{
+			"TableName": s.table,
+			"Item":      record.item(),
+		}
+		if err := s.api.Call(ctx, "PutItem", input, nil); err != nil {
+			errs = append(errs, fmt.Errorf("instance %s: %w", record.InstanceID, err))
+			if ctx.Err() != nil {
+				break
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// tableDescription is the part of DescribeTable/CreateTable output we read.
+type tableDescription struct {
+	Table struct {
+		TableStatus string `json:"TableStatus"`
+	} `json:"Table"`
+}
+
+// ensureTable creates the table when it doesn't exist and waits until it
+// is ACTIVE.
+func (s *DynamoDBSink) ensureTable(ctx context.Context) error {
+	var described tableDescription
+	err := s.api.Call(ctx, "DescribeTable", map[string]any{"TableName": s.table}, &described)
+	switch {
+	case err == nil && described.Table.TableStatus == "ACTIVE":
+		return nil
+	case err == nil:
+		// Exists but still being created/updated
+	case aws.IsAPIError(err, "ResourceNotFoundException"):
+		if err := s.api.Call(ctx, "CreateTable", s.createTableInput(), nil); err != nil &&
+			!aws.IsAPIError(err, "ResourceInUseException") { // Created concurrently
+			return fmt.Errorf("failed to create DynamoDB table %s: %w", s.table, err)
+		}
+	default:
+		return fmt.Errorf("failed to describe DynamoDB table %s: %w", s.table, err)
+	}
+
+	return s.waitActive(ctx)
+}
+
+// createTableInput is an on-demand table keyed by instance_id.
+func (s *DynamoDBSink) createTableInput() map[string]any {
+	return map[string]any{
+		"TableName":   s.table,
+		"BillingMode": "PAY_PER_REQUEST",
+		"AttributeDefinitions": []map[string]string{
+			{"AttributeName": DynamoDBKey, "AttributeType": "S"},
+		},
+		"KeySchema": []map[string]string{
+			{"AttributeName": DynamoDBKey, "KeyType": "HASH"},
+		},
+	}
+}
+
+// waitActive polls DescribeTable until the table is ACTIVE.
+func (s *DynamoDBSink) waitActive(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, tableActiveTimeout)
+	defer cancel()
+
+	for {
+		var described tableDescription
+		if err := s.api.Call(ctx, "DescribeTable", map[string]any{"TableName": s.table}, &described); err != nil &&
+			!aws.IsAPIError(err, "ResourceNotFoundException") {
+			return fmt.Errorf("failed to describe DynamoDB table %s: %w", s.table, err)
+		}
+		if described.Table.TableStatus == "ACTIVE" {
+			return nil
+		}
+
+		select {
+		case <-time.After(s.pollInterval):
+		case <-ctx.Done():
+			return fmt.Errorf("DynamoDB table %s not active: %w", s.table, ctx.Err())
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/estudosdevops/opsmaster/internal/cloud"
+	"github.com/estudosdevops/opsmaster/internal/cloud/aws"
+	"github.com/estudosdevops/opsmaster/internal/executor"
+	"github.com/estudosdevops/opsmaster/internal/installer"
+)
+
+// fakeDynamoDB records calls and answers DescribeTable from a status script.
+type fakeDynamoDB struct {
+	calls       []string
+	items       []map[string]attributeValue
+	tableStates []string // Successive DescribeTable answers ("" = not found)
+	failPut     string   // instance_id whose PutItem fails
+}
+
+func (f *fakeDynamoDB) Call(_ context.Context, operation string, input, output any) error {
+	f.calls = append(f.calls, operation)
+	switch operation {
+	case "DescribeTable":
+		state := ""
+		if len(f.tableStates) > 0 {
+			state, f.tableStates = f.tableStates[0], f.tableStates[1:]
+		}
+		if state == "" {
+			return &aws.APIError{Service: "dynamodb", StatusCode: 400, Type: "ResourceNotFoundException"}
+		}
+		return json.Unmarshal([]byte(`{"Table":{"TableStatus":"`+state+`"}}`), output)
+	case "PutItem":
+		item := input.(map[string]any)["Item"].(map[string]attributeValue)
+		if item[DynamoDBKey]["S"] == f.failPut {
+			return errors.New("throttled")
+		}
+		f.items = append(f.items, item)
+	}
+	return nil
+}
+
+// TestRecordsFromResult tests conversion and that skipped instances are left out
+func TestRecordsFromResult(t *testing.T) {
+	// ARRANGE
+	metadata := &installer.InstallMetadata{Certname: "web-01.example.com"}
+	end := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	result := executor.NewAggregatedResult()
+	result.RunID = "run-1"
+	result.Add(&executor.ExecutionResult{
+		Instance: &cloud.Instance{ID: "i-ok", Account: "111111111111", Region: "us-east-1"},
+		Status:   executor.StatusSuccess,
+		Metadata: metadata,
+		EndTime:  end,
+	})
+	result.Add(&executor.ExecutionResult{
+		Instance:        &cloud.Instance{ID: "i-fail"},
+		Status:          executor.StatusFailed,
+		InstallationErr: errors.New("repo unreachable"),
+	})
+	result.Add(&executor.ExecutionResult{Instance: &cloud.Instance{ID: "i-skip"}, Status: executor.StatusSkipped})
+
+	// ACT
+	records := RecordsFromResult(result, "8")
+
+	// ASSERT
+	if len(records) != 2 {
+		t.Fatalf("expected 2 records, got %d", len(records))
+	}
+	if r := records[0]; r.Status != "SUCCESS" || r.Certname != "web-01.example.com" || r.Version != "8" || r.RunID != "run-1" || !r.UpdatedAt.Equal(end) {
+		t.Errorf("unexpected success record %+v", r)
+	}
+	if r := records[1]; r.Status != "FAILED" || r.Error != "repo unreachable" {
+		t.Errorf("unexpected failed record %+v", r)
+	}
+}
+
+// TestDynamoDBSink_Write tests upserts, table auto-creation and partial failures
+func TestDynamoDBSink_Write(t *testing.T) {
+	records := []Record{
+		{InstanceID: "i-1", Status: "SUCCESS", Certname: "web-01", UpdatedAt: time.Now()},
+		{InstanceID: "i-2", Status: "FAILED", Error: "boom", UpdatedAt: time.Now()},
+	}
+
+	tests := []struct {
+		name        string
+		createTable bool
+		api         *fakeDynamoDB
+		wantCalls   string
+		wantItems   int
+		wantErr     string
+	}{
+		{
+			name:      "upserts every record",
+			api:       &fakeDynamoDB{},
+			wantCalls: "PutItem,PutItem",
+			wantItems: 2,
+		},
+		{
+			name:        "existing table is not created",
+			createTable: true,
+			api:         &fakeDynamoDB{tableStates: []string{"ACTIVE"}},
+			wantCalls:   "DescribeTable,PutItem,PutItem",
+			wantItems:   2,
+		},
+		{
+			name:        "missing table is created and awaited",
+			createTable: true,
+			api:         &fakeDynamoDB{tableStates: []string{"", "CREATING", "ACTIVE"}},
+			wantCalls:   "DescribeTable,CreateTable,DescribeTable,DescribeTable,PutItem,PutItem",
+			wantItems:   2,
+		},
+		{
+			name:      "failed put does not stop the others",
+			api:       &fakeDynamoDB{failPut: "i-1"},
+			wantCalls: "PutItem,PutItem",
+			wantItems: 1,
+			wantErr:   "instance i-1: throttled",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// ARRANGE
+			sink := NewDynamoDBSink(tt.api, "fleet-state", tt.createTable)
+			sink.pollInterval = time.Millisecond
+
+			// ACT
+			err := sink.Write(context.Background(), records)
+
+			// ASSERT
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("expected error %q, got %v", tt.wantErr, err)
+			}
+			if got := strings.Join(tt.api.calls, ","); got != tt.wantCalls {
+				t.Errorf("calls = %s, want %s", got, tt.wantCalls)
+			}
+			if len(tt.api.items) != tt.wantItems {
+				t.Errorf("items = %d, want %d", len(tt.api.items), tt.wantItems)
+			}
+		})
+	}
+}
+
+// TestRecordItem tests that empty attributes are left out of the item
+func TestRecordItem(t *testing.T) {
+	item := Record{InstanceID: "i-1", Status: "SUCCESS", Certname: "web-01"}.item()
+
+	if item[DynamoDBKey]["S"] != "i-1" || item["certname"]["S"] != "web-01" {
+		t.Errorf("unexpected item %v", item)
+	}
+	if _, ok := item["error"]; ok {
+		t.Error("empty error attribute should be omitted")
+	}
+}
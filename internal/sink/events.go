@@ -0,0 +1,73 @@
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+
+	"github.com/estudosdevops/opsmaster/internal/executor"
+)
+
+const (
+	// EventSource is the EventBridge source of opsmaster events.
+	EventSource = "opsmaster"
+
+	// EventDetailType identifies instance completion events
+	// (EventBridge DetailType, SNS Subject).
+	EventDetailType = "opsmaster Instance Completed"
+)
+
+// Publisher sends one event to a topic or bus.
+// Implemented by *aws.EventPublisher; tests provide fakes.
+type Publisher interface {
+	Publish(ctx context.Context, detailType string, detail []byte, attributes map[string]string) error
+}
+
+// InstanceEvent is the payload emitted when an instance finishes.
+type InstanceEvent struct {
+	Record
+	Command         string  `json:"command"`
+	SkipReason      string  `json:"skip_reason,omitempty"`
+	DurationSeconds float64 `json:"duration_seconds"`
+}
+
+// EventEmitter publishes an InstanceEvent for each finished instance, so
+// downstream automation (tickets, CMDB sync) reacts without waiting for the
+// run summary.
+type EventEmitter struct {
+	publisher Publisher
+	command   string
+	runID     string
+	version   string
+}
+
+// NewEventEmitter creates an emitter for events of one run.
+func NewEventEmitter(publisher Publisher, command, runID, version string) *EventEmitter {
+	return &EventEmitter{publisher: publisher, command: command, runID: runID, version: version}
+}
+
+// Emit publishes the event of one finished instance. The status and
+// command are also sent as attributes for SNS subscription filters.
+func (e *EventEmitter) Emit(ctx context.Context, result *executor.ExecutionResult) error {
+	event := InstanceEvent{
+		Record:          NewRecord(result, e.runID, e.version),
+		Command:         e.command,
+		SkipReason:      result.SkipReason,
+		DurationSeconds: math.Round(result.Duration.Seconds()*100) / 100,
+	}
+
+	detail, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode event for %s: %w", result.Instance.ID, err)
+	}
+
+	attributes := map[string]string{
+		"status":  event.Status,
+		"command": e.command,
+	}
+	if err := e.publisher.Publish(ctx, EventDetailType, detail, attributes); err != nil {
+		return fmt.Errorf("failed to publish event for %s: %w", result.Instance.ID, err)
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/estudosdevops/opsmaster/internal/cloud"
+	"github.com/estudosdevops/opsmaster/internal/executor"
+)
+
+// fakePublisher captures published events.
+type fakePublisher struct {
+	detailType string
+	detail     []byte
+	attributes map[string]string
+	err        error
+}
+
+func (f *fakePublisher) Publish(_ context.Context, detailType string, detail []byte, attributes map[string]string) error {
+	f.detailType, f.detail, f.attributes = detailType, detail, attributes
+	return f.err
+}
+
+// TestEventEmitter_Emit tests the event payload and attributes
+func TestEventEmitter_Emit(t *testing.T) {
+	result := &executor.ExecutionResult{
+		Instance:   &cloud.Instance{ID: "i-1", Account: "111111111111", Region: "us-east-1"},
+		Status:     executor.StatusSkipped,
+		SkipReason: "maintenance mode",
+		Duration:   1500 * time.Millisecond,
+	}
+
+	t.Run("payload and attributes", func(t *testing.T) {
+		// ARRANGE
+		publisher := &fakePublisher{}
+		emitter := NewEventEmitter(publisher, "install puppet", "run-1", "8")
+
+		// ACT
+		err := emitter.Emit(context.Background(), result)
+
+		// ASSERT
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		var event InstanceEvent
+		if err := json.Unmarshal(publisher.detail, &event); err != nil {
+			t.Fatalf("invalid JSON detail: %v", err)
+		}
+		if event.InstanceID != "i-1" || event.Status != "SKIPPED" || event.SkipReason != "maintenance mode" ||
+			event.RunID != "run-1" || event.Command != "install puppet" || event.DurationSeconds != 1.5 {
+			t.Errorf("unexpected event %+v", event)
+		}
+		if publisher.detailType != EventDetailType || publisher.attributes["status"] != "SKIPPED" {
+			t.Errorf("unexpected detail type %q / attributes %v", publisher.detailType, publisher.attributes)
+		}
+	})
+
+	t.Run("publish errors name the instance", func(t *testing.T) {
+		emitter := NewEventEmitter(&fakePublisher{err: errors.New("denied")}, "install puppet", "run-1", "8")
+		if err := emitter.Emit(context.Background(), result); err == nil || err.Error() != "failed to publish event for i-1: denied" {
+			t.Errorf("unexpected error %v", err)
+		}
+	})
+}
@@ -0,0 +1,71 @@
+// Package sink publishes per-instance results to external systems after a
+// run, so dashboards can track fleet state without parsing reports.
+package sink
+
+import (
+	"context"
+	"time"
+
+	"github.com/estudosdevops/opsmaster/internal/executor"
+	"github.com/estudosdevops/opsmaster/internal/installer"
+)
+
+// Sink receives the records of a finished run.
+type Sink interface {
+	Write(ctx context.Context, records []Record) error
+}
+
+// Record is the latest install state of one instance.
+type Record struct {
+	InstanceID string    `json:"instance_id"`
+	Name       string    `json:"name,omitempty"` // Display name (CSV name/hostname column or Name tag)
+	Account    string    `json:"account,omitempty"`
+	Region     string    `json:"region,omitempty"`
+	Status     string    `json:"status"` // executor status, e.g. "SUCCESS" or "FAILED"
+	Certname   string    `json:"certname,omitempty"`
+	OS         string    `json:"os,omitempty"`
+	Version    string    `json:"version,omitempty"` // Requested package version (e.g., Puppet "8")
+	Error      string    `json:"error,omitempty"`   // First error of failed executions
+	RunID      string    `json:"run_id,omitempty"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// NewRecord builds the record of one finished instance.
+func NewRecord(r *executor.ExecutionResult, runID, version string) Record {
+	record := Record{
+		InstanceID: r.Instance.ID,
+		Name:       r.Instance.DisplayName(),
+		Account:    r.Instance.Account,
+		Region:     r.Instance.Region,
+		Status:     r.Status.String(),
+		Certname:   r.Metadata.Get(installer.MetadataKeyCertname),
+		OS:         r.Metadata.Get(installer.MetadataKeyOS),
+		Version:    version,
+		RunID:      runID,
+		UpdatedAt:  r.EndTime,
+	}
+	if record.UpdatedAt.IsZero() {
+		record.UpdatedAt = time.Now()
+	}
+	if err := r.GetError(); err != nil {
+		record.Error = err.Error()
+	}
+	return record
+}
+
+// RecordsFromResult builds one record per processed instance.
+// Skipped instances are left out so their last real install state is kept.
+func RecordsFromResult(result *executor.AggregatedResult, version string) []Record {
+	records := make([]Record, 0, len(result.Results))
+	for _, r := range result.Results {
+		if r.Status == executor.StatusSkipped || r.Instance == nil {
+			continue
+		}
+		record := NewRecord(r, result.RunID, version)
+		if r.EndTime.IsZero() {
+			record.UpdatedAt = result.EndTime
+		}
+		records = append(records, record)
+	}
+	return records
+}
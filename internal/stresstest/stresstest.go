@@ -0,0 +1,240 @@
+// Package stresstest provides a concurrency stress harness and invariant
+// assertions for race-condition regression tests of executors, installers
+// and providers.
+//
+// Run starts every worker behind a barrier, so the calls really overlap,
+// and collects each call's value and error; the assertions check the
+// invariants concurrent code must keep (no errors, unique per-call values,
+// bounded parallelism). Combined with cloudtest.Provider latencies and
+// "go test -race", a new installer or provider gets the same concurrency
+// coverage as the existing ones in a few lines.
+//
+// Example:
+//
+//	calls := stresstest.Run(t, stresstest.Config{Goroutines: 50}, func(ctx context.Context, worker, _ int) (*installer.InstallMetadata, error) {
+//		_, metadata, err := pi.GenerateInstallScriptWithAutoDetect(ctx, instances[worker], provider, nil)
+//		return metadata, err
+//	})
+//	stresstest.NoErrors(t, calls)
+//	stresstest.Unique(t, calls, func(c stresstest.Call[*installer.InstallMetadata]) string { return c.Value.Certname })
+//
+// The package only depends on cloud, so tests of any package can import it.
+package stresstest
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/estudosdevops/opsmaster/internal/cloud"
+)
+
+// Defaults of Config.
+const (
+	DefaultGoroutines = 50
+	DefaultIterations = 1
+	DefaultTimeout    = 30 * time.Second
+)
+
+// Config sizes a stress run.
+type Config struct {
+	Goroutines int           // Concurrent workers (default: 50)
+	Iterations int           // Calls per worker (default: 1)
+	Timeout    time.Duration // Deadline of the context given to calls (default: 30s)
+}
+
+func (c Config) withDefaults() Config {
+	if c.Goroutines <= 0 {
+		c.Goroutines = DefaultGoroutines
+	}
+	if c.Iterations <= 0 {
+		c.Iterations = DefaultIterations
+	}
+	if c.Timeout <= 0 {
+		c.Timeout = DefaultTimeout
+	}
+	return c
+}
+
+// Call is the outcome of one call made by Run.
+type Call[T any] struct {
+	Worker    int
+	Iteration int
+	Value     T
+	Err       error
+	Duration  time.Duration
+}
+
+// String identifies the call in assertion messages.
+func (c Call[T]) String() string {
+	return fmt.Sprintf("worker %d, iteration %d", c.Worker, c.Iteration)
+}
+
+// failed lets the assertions skip calls that returned an error (reported by
+// NoErrors), whose value is usually unset.
+func (c Call[T]) failed() bool {
+	return c.Err != nil
+}
+
+// Run calls fn Iterations times from each of Goroutines workers, all
+// released together, and returns the calls ordered by worker and iteration.
+// A panic in fn fails the test instead of crashing the binary.
+func Run[T any](t testing.TB, cfg Config, fn func(ctx context.Context, worker, iteration int) (T, error)) []Call[T] {
+	t.Helper()
+	cfg = cfg.withDefaults()
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Timeout)
+	defer cancel()
+
+	calls := make([]Call[T], cfg.Goroutines*cfg.Iterations)
+	start := make(chan struct{})
+	var wg sync.WaitGroup
+	for worker := range cfg.Goroutines {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			for iteration := range cfg.Iterations {
+				calls[worker*cfg.Iterations+iteration] = runCall(ctx, worker, iteration, fn)
+			}
+		}()
+	}
+
+	close(start)
+	wg.Wait()
+	if ctx.Err() != nil {
+		t.Errorf("stress run exceeded %s (possible deadlock)", cfg.Timeout)
+	}
+	return calls
+}
+
+// runCall runs one call, turning a panic into the call error.
+func runCall[T any](ctx context.Context, worker, iteration int, fn func(ctx context.Context, worker, iteration int) (T, error)) (call Call[T]) {
+	call = Call[T]{Worker: worker, Iteration: iteration}
+	began := time.Now()
+	defer func() {
+		call.Duration = time.Since(began)
+		if r := recover(); r != nil {
+			call.Err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	call.Value, call.Err = fn(ctx, worker, iteration)
+	return call
+}
+
+// NoErrors fails the test for every call that returned an error.
+func NoErrors[T any](t testing.TB, calls []Call[T]) {
+	t.Helper()
+	for _, call := range calls {
+		if call.Err != nil {
+			t.Errorf("%s: unexpected error: %v", call, call.Err)
+		}
+	}
+}
+
+// Unique fails the test when two items share the same key, the typical
+// symptom of state shared between goroutines (e.g., metadata of one instance
+// overwritten by another). Empty keys fail too. Items are the calls of Run
+// (failed calls are skipped) or any other results, like the per-instance
+// results of an executor run.
+func Unique[T any, K comparable](t testing.TB, items []T, key func(T) K) {
+	t.Helper()
+	var zero K
+	seen := make(map[K]string, len(items))
+	for i, item := range items {
+		if skip(item) {
+			continue
+		}
+		value := key(item)
+		if value == zero {
+			t.Errorf("%s: empty key", describe(item, i))
+			continue
+		}
+		if previous, ok := seen[value]; ok {
+			t.Errorf("RACE CONDITION DETECTED: %s and %s share %v", previous, describe(item, i), value)
+			continue
+		}
+		seen[value] = describe(item, i)
+	}
+}
+
+// Each fails the test for every item the check rejects (failed calls of Run
+// are skipped).
+func Each[T any](t testing.TB, items []T, check func(T) error) {
+	t.Helper()
+	for i, item := range items {
+		if skip(item) {
+			continue
+		}
+		if err := check(item); err != nil {
+			t.Errorf("%s: %v", describe(item, i), err)
+		}
+	}
+}
+
+// skip reports whether item is a failed call.
+func skip(item any) bool {
+	call, ok := item.(interface{ failed() bool })
+	return ok && call.failed()
+}
+
+// describe names item in assertion messages.
+func describe(item any, index int) string {
+	if stringer, ok := item.(fmt.Stringer); ok {
+		return stringer.String()
+	}
+	return fmt.Sprintf("item %d", index)
+}
+
+// Gauge tracks how many operations are in flight and the maximum seen, to
+// assert concurrency limits. The zero value is ready to use.
+type Gauge struct {
+	current atomic.Int64
+	max     atomic.Int64
+}
+
+// Enter marks an operation in flight; call the returned func when it ends.
+func (g *Gauge) Enter() (leave func()) {
+	current := g.current.Add(1)
+	for {
+		seen := g.max.Load()
+		if current <= seen || g.max.CompareAndSwap(seen, current) {
+			break
+		}
+	}
+	return func() { g.current.Add(-1) }
+}
+
+// Max returns the most operations seen in flight at once.
+func (g *Gauge) Max() int {
+	return int(g.max.Load())
+}
+
+// AtMost fails the test when more than limit operations overlapped.
+func (g *Gauge) AtMost(t testing.TB, limit int) {
+	t.Helper()
+	if got := g.Max(); got > limit {
+		t.Errorf("max operations in flight = %d, want <= %d", got, limit)
+	}
+}
+
+// Instances returns n AWS instances with distinct IDs (i-<prefix>000, ...)
+// spread over two accounts and regions, for stress runs.
+func Instances(prefix string, n int) []*cloud.Instance {
+	instances := make([]*cloud.Instance, n)
+	for i := range n {
+		instances[i] = &cloud.Instance{
+			ID:      fmt.Sprintf("i-%s%03d", prefix, i),
+			Account: []string{"123456789012", "210987654321"}[i%2],
+			Region:  []string{"us-east-1", "sa-east-1"}[(i/2)%2],
+			Cloud:   "aws",
+			Metadata: map[string]string{
+				"environment": "stress",
+			},
+		}
+	}
+	return instances
+}
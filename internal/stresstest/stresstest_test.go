@@ -0,0 +1,148 @@
+package stresstest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/estudosdevops/opsmaster/internal/cloud"
+)
+
+// recorder captures assertion failures instead of failing the test.
+type recorder struct {
+	*testing.T
+	mu     sync.Mutex
+	errors []string
+}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+// TestRun tests that every worker runs its iterations concurrently and that
+// panics become call errors.
+func TestRun(t *testing.T) {
+	var gauge Gauge
+	calls := Run(t, Config{Goroutines: 8, Iterations: 3}, func(_ context.Context, worker, iteration int) (string, error) {
+		defer gauge.Enter()()
+		time.Sleep(10 * time.Millisecond)
+		if worker == 7 && iteration == 2 {
+			panic("boom")
+		}
+		return fmt.Sprintf("%d/%d", worker, iteration), nil
+	})
+
+	if len(calls) != 24 {
+		t.Fatalf("len(calls) = %d, want 24", len(calls))
+	}
+	for i, call := range calls[:23] {
+		if want := fmt.Sprintf("%d/%d", i/3, i%3); call.Value != want || call.Err != nil {
+			t.Errorf("calls[%d] = %q, %v, want %q", i, call.Value, call.Err, want)
+		}
+	}
+	if err := calls[23].Err; err == nil || !strings.Contains(err.Error(), "panic: boom") {
+		t.Errorf("panicking call error = %v, want panic: boom", err)
+	}
+	if gauge.Max() < 2 {
+		t.Errorf("gauge.Max() = %d, want overlapping calls", gauge.Max())
+	}
+}
+
+// TestRun_Timeout tests that a run exceeding the timeout fails the test.
+func TestRun_Timeout(t *testing.T) {
+	rec := &recorder{T: t}
+	calls := Run(rec, Config{Goroutines: 2, Timeout: 10 * time.Millisecond}, func(ctx context.Context, _, _ int) (int, error) {
+		<-ctx.Done()
+		return 0, ctx.Err()
+	})
+
+	if len(rec.errors) != 1 || !strings.Contains(rec.errors[0], "possible deadlock") {
+		t.Errorf("errors = %v, want a deadlock failure", rec.errors)
+	}
+	if !errors.Is(calls[0].Err, context.DeadlineExceeded) {
+		t.Errorf("call error = %v, want context deadline", calls[0].Err)
+	}
+}
+
+// TestAssertions tests the invariant assertions.
+func TestAssertions(t *testing.T) {
+	calls := []Call[string]{
+		{Worker: 0, Value: "a"},
+		{Worker: 1, Value: "b"},
+		{Worker: 2, Value: "a"},
+		{Worker: 3, Value: ""},
+		{Worker: 4, Err: errors.New("offline")},
+	}
+
+	tests := []struct {
+		name   string
+		assert func(t testing.TB)
+		want   []string
+	}{
+		{"no errors", func(t testing.TB) { NoErrors(t, calls) }, []string{"worker 4, iteration 0: unexpected error: offline"}},
+		{"unique calls", func(t testing.TB) { Unique(t, calls, func(c Call[string]) string { return c.Value }) }, []string{
+			"RACE CONDITION DETECTED: worker 0, iteration 0 and worker 2, iteration 0 share a",
+			"worker 3, iteration 0: empty key",
+		}},
+		{"unique items", func(t testing.TB) { Unique(t, []int{1, 2, 1}, func(i int) int { return i }) }, []string{
+			"RACE CONDITION DETECTED: item 0 and item 2 share 1",
+		}},
+		{"each", func(t testing.TB) {
+			Each(t, calls, func(c Call[string]) error {
+				if c.Value != "a" {
+					return errors.New("not a")
+				}
+				return nil
+			})
+		}, []string{"worker 1, iteration 0: not a", "worker 3, iteration 0: not a"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := &recorder{T: t}
+			tt.assert(rec)
+			if fmt.Sprint(rec.errors) != fmt.Sprint(tt.want) {
+				t.Errorf("errors = %q, want %q", rec.errors, tt.want)
+			}
+		})
+	}
+}
+
+// TestGauge tests the in-flight tracking and limit assertion.
+func TestGauge(t *testing.T) {
+	var gauge Gauge
+	first := gauge.Enter()
+	second := gauge.Enter()
+	second()
+	third := gauge.Enter()
+	third()
+	first()
+
+	if gauge.Max() != 2 {
+		t.Errorf("Max() = %d, want 2", gauge.Max())
+	}
+	rec := &recorder{T: t}
+	gauge.AtMost(rec, 1)
+	gauge.AtMost(rec, 2)
+	if len(rec.errors) != 1 {
+		t.Errorf("AtMost() errors = %v, want 1 failure", rec.errors)
+	}
+}
+
+// TestInstances tests the generated stress fleet.
+func TestInstances(t *testing.T) {
+	instances := Instances("stress", 4)
+	Unique(t, instances, func(i *cloud.Instance) string { return i.ID })
+	if instances[0].ID != "i-stress000" || instances[3].ID != "i-stress003" {
+		t.Errorf("IDs = %s..%s", instances[0].ID, instances[3].ID)
+	}
+	if instances[0].Account == instances[1].Account || instances[0].Region == instances[2].Region {
+		t.Error("instances should spread over accounts and regions")
+	}
+}
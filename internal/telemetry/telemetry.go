@@ -0,0 +1,154 @@
+// Package telemetry sends opt-in, anonymized usage reports.
+//
+// Disabled by default. Reports never contain instance IDs, accounts,
+// regions, hostnames, certnames or run IDs - only the command, the
+// opsmaster version, a bucketed instance count, the success rate and
+// how many targets ran each OS family.
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"runtime"
+	"time"
+
+	"github.com/estudosdevops/opsmaster/internal/httpclient"
+	"github.com/estudosdevops/opsmaster/internal/version"
+)
+
+// sendTimeout bounds how long a report may delay command exit.
+const sendTimeout = 3 * time.Second
+
+// Config controls telemetry (config file keys telemetry.enabled and telemetry.endpoint).
+type Config struct {
+	Enabled  bool         // Opt-in switch (default: false)
+	Endpoint string       // URL receiving reports (HTTP POST, JSON)
+	Client   *http.Client // HTTP client (default: httpclient.Shared())
+}
+
+// Validate checks the endpoint when telemetry is enabled.
+func (c Config) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	parsed, err := url.Parse(c.Endpoint)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("invalid telemetry endpoint %q: must be an http(s) URL", c.Endpoint)
+	}
+	return nil
+}
+
+// Summary is the outcome of a command, before anonymization.
+type Summary struct {
+	Total   int
+	Success int
+	Failed  int
+	Skipped int
+	DryRun  bool
+	OS      []string // Detected OS family of each processed instance ("" = unknown)
+}
+
+// Event is the anonymized report sent to the endpoint.
+type Event struct {
+	Command        string         `json:"command"`
+	Version        string         `json:"version"`
+	ClientOS       string         `json:"client_os"`
+	InstanceBucket string         `json:"instance_bucket"`
+	SuccessRate    float64        `json:"success_rate"` // 0-1, rounded to 2 decimals
+	DryRun         bool           `json:"dry_run"`
+	TargetOS       map[string]int `json:"target_os,omitempty"`
+}
+
+// NewEvent anonymizes a command summary.
+func NewEvent(command string, summary Summary) Event {
+	event := Event{
+		Command:        command,
+		Version:        version.Version,
+		ClientOS:       runtime.GOOS,
+		InstanceBucket: Bucket(summary.Total),
+		DryRun:         summary.DryRun,
+	}
+
+	if attempted := summary.Success + summary.Failed; attempted > 0 {
+		event.SuccessRate = math.Round(float64(summary.Success)/float64(attempted)*100) / 100
+	}
+
+	for _, osFamily := range summary.OS {
+		if osFamily == "" {
+			osFamily = "unknown"
+		}
+		if event.TargetOS == nil {
+			event.TargetOS = make(map[string]int)
+		}
+		event.TargetOS[osFamily]++
+	}
+
+	return event
+}
+
+// Bucket hides exact fleet sizes by grouping instance counts.
+func Bucket(count int) string {
+	switch {
+	case count <= 0:
+		return "0"
+	case count == 1:
+		return "1"
+	case count <= 10:
+		return "2-10"
+	case count <= 50:
+		return "11-50"
+	case count <= 200:
+		return "51-200"
+	case count <= 1000:
+		return "201-1000"
+	default:
+		return "1000+"
+	}
+}
+
+// Send posts the event when telemetry is enabled. Failures are returned
+// for debug logging only - telemetry must never fail a command.
+func Send(ctx context.Context, config Config, event Event) error {
+	if !config.Enabled {
+		return nil
+	}
+	if err := config.Validate(); err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode telemetry event: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, sendTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, config.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create telemetry request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "opsmaster/"+version.Version)
+
+	client := config.Client
+	if client == nil {
+		client = httpclient.Shared()
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("telemetry request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("telemetry endpoint returned %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package telemetry
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// TestBucket tests instance count grouping
+func TestBucket(t *testing.T) {
+	tests := []struct {
+		count int
+		want  string
+	}{
+		{0, "0"},
+		{1, "1"},
+		{7, "2-10"},
+		{50, "11-50"},
+		{51, "51-200"},
+		{1000, "201-1000"},
+		{25000, "1000+"},
+	}
+
+	for _, tt := range tests {
+		if got := Bucket(tt.count); got != tt.want {
+			t.Errorf("Bucket(%d) = %q, want %q", tt.count, got, tt.want)
+		}
+	}
+}
+
+// TestNewEvent tests anonymization of a command summary
+func TestNewEvent(t *testing.T) {
+	// ARRANGE
+	summary := Summary{Total: 12, Success: 2, Failed: 1, Skipped: 9, OS: []string{"debian", "debian", "rhel", ""}}
+
+	// ACT
+	event := NewEvent("install puppet", summary)
+
+	// ASSERT
+	if event.InstanceBucket != "11-50" {
+		t.Errorf("InstanceBucket = %q, want 11-50", event.InstanceBucket)
+	}
+	if event.SuccessRate != 0.67 {
+		t.Errorf("SuccessRate = %v, want 0.67 (skipped instances excluded)", event.SuccessRate)
+	}
+	if event.TargetOS["debian"] != 2 || event.TargetOS["rhel"] != 1 || event.TargetOS["unknown"] != 1 {
+		t.Errorf("unexpected TargetOS %v", event.TargetOS)
+	}
+	if event.Version == "" || event.ClientOS == "" {
+		t.Error("expected version and client OS to be set")
+	}
+}
+
+// TestSend tests opt-in behaviour and delivery
+func TestSend(t *testing.T) {
+	var received []Event
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event Event
+		if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
+			t.Errorf("invalid body: %v", err)
+		}
+		received = append(received, event)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	event := NewEvent("install puppet", Summary{Total: 1, Success: 1})
+
+	t.Run("disabled sends nothing", func(t *testing.T) {
+		if err := Send(context.Background(), Config{Endpoint: server.URL}, event); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(received) != 0 {
+			t.Errorf("expected no request, got %d", len(received))
+		}
+	})
+
+	t.Run("enabled posts the event", func(t *testing.T) {
+		config := Config{Enabled: true, Endpoint: server.URL, Client: server.Client()}
+		if err := Send(context.Background(), config, event); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(received) != 1 || received[0].Command != "install puppet" {
+			t.Errorf("unexpected events %+v", received)
+		}
+	})
+
+	t.Run("enabled without endpoint is an error", func(t *testing.T) {
+		err := Send(context.Background(), Config{Enabled: true}, event)
+		if err == nil || !strings.Contains(err.Error(), "invalid telemetry endpoint") {
+			t.Errorf("expected endpoint error, got %v", err)
+		}
+	})
+}
@@ -3,6 +3,8 @@ package validator
 import (
 	"context"
 	"fmt"
+	"sort"
+	"sync"
 	"time"
 
 	"github.com/estudosdevops/opsmaster/internal/cloud"
@@ -17,10 +19,13 @@ const (
 // ValidationResult represents the result of a validation check.
 // Contains success status and any error encountered.
 type ValidationResult struct {
-	Name    string // Name of validation (e.g., "ssm_connectivity", "puppet_server_reachable")
-	Success bool   // Whether validation passed
-	Error   error  // Error if validation failed
-	Message string // Human-readable message
+	Name     string        // Name of validation (e.g., "ssm_connectivity", "puppet_server_reachable")
+	Success  bool          // Whether validation passed
+	Error    error         // Error if validation failed
+	Message  string        // Human-readable message
+	Duration time.Duration // Time the validation took
+	Timeout  time.Duration // Timeout enforced for this validation (0 = none)
+	TimedOut bool          // Whether validation was aborted because it exceeded Timeout
 }
 
 // Validator interface for reusable validation logic.
@@ -30,6 +35,13 @@ type Validator interface {
 	Validate(ctx context.Context, instance *cloud.Instance, provider cloud.CloudProvider) *ValidationResult
 }
 
+// timeoutValidator is implemented by validators that declare their own timeout.
+// CompositeValidator uses it to enforce the timeout even when the underlying
+// provider call ignores context cancellation.
+type timeoutValidator interface {
+	ValidationTimeout() time.Duration
+}
+
 // ConnectivityValidator validates network connectivity to a specific host:port.
 // This is useful for checking if instance can reach external services
 // (e.g., Puppet Server, Docker Registry, etc).
@@ -56,9 +68,12 @@ func NewConnectivityValidator(name, host string, port int, timeout time.Duration
 
 // Validate checks if instance can reach the target host:port.
 func (cv *ConnectivityValidator) Validate(ctx context.Context, instance *cloud.Instance, provider cloud.CloudProvider) *ValidationResult {
+	start := time.Now()
 	result := &ValidationResult{
-		Name: cv.Name,
+		Name:    cv.Name,
+		Timeout: cv.Timeout,
 	}
+	defer func() { result.Duration = time.Since(start) }()
 
 	// Create timeout context
 	timeoutCtx, cancel := context.WithTimeout(ctx, cv.Timeout)
@@ -78,6 +93,11 @@ func (cv *ConnectivityValidator) Validate(ctx context.Context, instance *cloud.I
 	return result
 }
 
+// ValidationTimeout returns the timeout enforced for this validator.
+func (cv *ConnectivityValidator) ValidationTimeout() time.Duration {
+	return cv.Timeout
+}
+
 // SSMValidator validates that instance is accessible via Systems Manager (SSM).
 // This is AWS-specific but could be extended for Azure Run Command, etc.
 type SSMValidator struct {
@@ -99,9 +119,12 @@ func NewSSMValidator(timeout time.Duration) *SSMValidator {
 
 // Validate checks if instance is online and accessible via SSM.
 func (sv *SSMValidator) Validate(ctx context.Context, instance *cloud.Instance, provider cloud.CloudProvider) *ValidationResult {
+	start := time.Now()
 	result := &ValidationResult{
-		Name: sv.Name,
+		Name:    sv.Name,
+		Timeout: sv.Timeout,
 	}
+	defer func() { result.Duration = time.Since(start) }()
 
 	// Create timeout context
 	timeoutCtx, cancel := context.WithTimeout(ctx, sv.Timeout)
@@ -121,6 +144,11 @@ func (sv *SSMValidator) Validate(ctx context.Context, instance *cloud.Instance,
 	return result
 }
 
+// ValidationTimeout returns the timeout enforced for this validator.
+func (sv *SSMValidator) ValidationTimeout() time.Duration {
+	return sv.Timeout
+}
+
 // CompositeValidator runs multiple validators in sequence.
 // Useful for running all prerequisite checks before installation.
 type CompositeValidator struct {
@@ -156,8 +184,8 @@ func (cv *CompositeValidator) Validate(ctx context.Context, instance *cloud.Inst
 		default:
 		}
 
-		// Run validator
-		result := validator.Validate(ctx, instance, provider)
+		// Run validator with its own timeout enforced
+		result := runWithTimeout(ctx, validator, instance, provider)
 		results = append(results, result)
 
 		// Stop on first failure if configured
@@ -169,6 +197,76 @@ func (cv *CompositeValidator) Validate(ctx context.Context, instance *cloud.Inst
 	return results
 }
 
+// runWithTimeout runs a single validator, aborting it once its timeout expires.
+// The validator runs in its own goroutine so a provider call that ignores
+// context cancellation cannot stall the whole validation phase.
+func runWithTimeout(ctx context.Context, v Validator, instance *cloud.Instance, provider cloud.CloudProvider) *ValidationResult {
+	timeout := defaultValidationTimeout
+	if tv, ok := v.(timeoutValidator); ok && tv.ValidationTimeout() > 0 {
+		timeout = tv.ValidationTimeout()
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan *ValidationResult, 1) // Buffered so an abandoned goroutine never blocks
+	go func() {
+		done <- v.Validate(timeoutCtx, instance, provider)
+	}()
+
+	select {
+	case result := <-done:
+		if result.Duration == 0 {
+			result.Duration = time.Since(start)
+		}
+		if result.Timeout == 0 {
+			result.Timeout = timeout
+		}
+		return result
+	case <-timeoutCtx.Done():
+		// Parent cancellation is not a timeout of this validator
+		timedOut := ctx.Err() == nil
+		message := "Validation canceled"
+		if timedOut {
+			message = fmt.Sprintf("Validation aborted after %s", timeout)
+		}
+		return &ValidationResult{
+			Name:     validatorName(v),
+			Success:  false,
+			Error:    timeoutCtx.Err(),
+			Message:  message,
+			Duration: time.Since(start),
+			Timeout:  timeout,
+			TimedOut: timedOut,
+		}
+	}
+}
+
+// validatorName returns the reporting name of known validators.
+func validatorName(v Validator) string {
+	switch typed := v.(type) {
+	case *ConnectivityValidator:
+		return typed.Name
+	case *SSMValidator:
+		return typed.Name
+	default:
+		return fmt.Sprintf("%T", v)
+	}
+}
+
+// SlowestValidation returns the validation result that took the longest.
+// Returns nil if results is empty.
+func SlowestValidation(results []*ValidationResult) *ValidationResult {
+	var slowest *ValidationResult
+	for _, result := range results {
+		if slowest == nil || result.Duration > slowest.Duration {
+			slowest = result
+		}
+	}
+	return slowest
+}
+
 // AllPassed checks if all validation results passed.
 func AllPassed(results []*ValidationResult) bool {
 	for _, result := range results {
@@ -203,6 +301,9 @@ func FormatValidationResults(results []*ValidationResult) string {
 			status = "✗"
 		}
 		output += fmt.Sprintf("%s %s: %s", status, result.Name, result.Message)
+		if result.Duration > 0 {
+			output += fmt.Sprintf(" (%s)", result.Duration.Round(time.Millisecond))
+		}
 		if i < len(results)-1 {
 			output += "\n"
 		}
@@ -211,13 +312,29 @@ func FormatValidationResults(results []*ValidationResult) string {
 }
 
 // ValidatePuppetPrerequisites is a convenience function for Puppet installation.
-// Validates SSM connectivity and Puppet Server reachability.
-func ValidatePuppetPrerequisites(ctx context.Context, instance *cloud.Instance, provider cloud.CloudProvider, puppetServer string, puppetPort int) ([]*ValidationResult, error) {
+// Validates SSM connectivity and Puppet Server reachability, plus any extra
+// validators (e.g., reachability of internal package mirrors).
+func ValidatePuppetPrerequisites(ctx context.Context, instance *cloud.Instance, provider cloud.CloudProvider, puppetServer string, puppetPort int, extra ...Validator) ([]*ValidationResult, error) {
+	reachable := NewConnectivityValidator("puppet_server_reachable", puppetServer, puppetPort, defaultValidationTimeout)
+	return validatePuppet(ctx, instance, provider, reachable, extra)
+}
+
+// ValidatePuppetPrerequisitesViaProxy is ValidatePuppetPrerequisites for
+// agents reaching the Puppet Server through an HTTP proxy: the proxy must be
+// reachable instead of the server, which private subnets may not reach.
+func ValidatePuppetPrerequisitesViaProxy(ctx context.Context, instance *cloud.Instance, provider cloud.CloudProvider, proxyHost string, proxyPort int, extra ...Validator) ([]*ValidationResult, error) {
+	reachable := NewConnectivityValidator("puppet_proxy_reachable", proxyHost, proxyPort, defaultValidationTimeout)
+	return validatePuppet(ctx, instance, provider, reachable, extra)
+}
+
+// validatePuppet runs the SSM check, the reachability check and extra.
+func validatePuppet(ctx context.Context, instance *cloud.Instance, provider cloud.CloudProvider, reachable Validator, extra []Validator) ([]*ValidationResult, error) {
 	// Create validators
 	validators := []Validator{
 		NewSSMValidator(defaultValidationTimeout),
-		NewConnectivityValidator("puppet_server_reachable", puppetServer, puppetPort, defaultValidationTimeout),
+		reachable,
 	}
+	validators = append(validators, extra...)
 
 	// Run all validations
 	composite := NewCompositeValidator(validators, false) // Run all, don't stop on first failure
@@ -231,3 +348,75 @@ func ValidatePuppetPrerequisites(ctx context.Context, instance *cloud.Instance,
 
 	return results, nil
 }
+
+// ValidatorStats holds aggregated timing statistics for one validator name.
+type ValidatorStats struct {
+	Name            string        // Validator name (e.g., "ssm_connectivity")
+	Count           int           // Number of executions
+	TimedOut        int           // Number of executions aborted by timeout
+	TotalDuration   time.Duration // Sum of all execution durations
+	MaxDuration     time.Duration // Longest single execution
+	SlowestInstance string        // Instance ID of the longest execution
+}
+
+// AverageDuration returns the mean execution time.
+func (vs ValidatorStats) AverageDuration() time.Duration {
+	if vs.Count == 0 {
+		return 0
+	}
+	return vs.TotalDuration / time.Duration(vs.Count)
+}
+
+// StatsCollector aggregates validation timings across instances.
+// Thread-safe: installers record results from many goroutines concurrently.
+type StatsCollector struct {
+	mu    sync.Mutex
+	stats map[string]*ValidatorStats
+}
+
+// NewStatsCollector creates an empty stats collector.
+func NewStatsCollector() *StatsCollector {
+	return &StatsCollector{
+		stats: make(map[string]*ValidatorStats),
+	}
+}
+
+// Record adds validation results for an instance to the collector.
+func (sc *StatsCollector) Record(instanceID string, results []*ValidationResult) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+
+	for _, result := range results {
+		stat, ok := sc.stats[result.Name]
+		if !ok {
+			stat = &ValidatorStats{Name: result.Name}
+			sc.stats[result.Name] = stat
+		}
+
+		stat.Count++
+		stat.TotalDuration += result.Duration
+		if result.TimedOut {
+			stat.TimedOut++
+		}
+		if result.Duration > stat.MaxDuration {
+			stat.MaxDuration = result.Duration
+			stat.SlowestInstance = instanceID
+		}
+	}
+}
+
+// Snapshot returns a copy of the collected stats, slowest validator first.
+func (sc *StatsCollector) Snapshot() []ValidatorStats {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+
+	snapshot := make([]ValidatorStats, 0, len(sc.stats))
+	for _, stat := range sc.stats {
+		snapshot = append(snapshot, *stat)
+	}
+
+	sort.Slice(snapshot, func(i, j int) bool {
+		return snapshot[i].MaxDuration > snapshot[j].MaxDuration
+	})
+	return snapshot
+}
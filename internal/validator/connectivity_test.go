@@ -7,59 +7,9 @@ import (
 	"time"
 
 	"github.com/estudosdevops/opsmaster/internal/cloud"
+	"github.com/estudosdevops/opsmaster/internal/cloud/cloudtest"
 )
 
-// ============================================================
-// MOCKS - External dependency simulation
-// ============================================================
-
-// mockCloudProvider simulates a cloud provider for testing validators.
-// Allows configuring specific behaviors for validation scenarios.
-type mockCloudProvider struct {
-	validateInstanceFunc func(ctx context.Context, instance *cloud.Instance) error
-	testConnectivityFunc func(ctx context.Context, instance *cloud.Instance, host string, port int) error
-	executeCommandFunc   func(ctx context.Context, instance *cloud.Instance, commands []string, timeout time.Duration) (*cloud.CommandResult, error)
-}
-
-// Name implements the cloud.CloudProvider interface
-func (_ *mockCloudProvider) Name() string {
-	return "mock"
-}
-
-// ValidateInstance implements cloud.CloudProvider interface
-func (m *mockCloudProvider) ValidateInstance(ctx context.Context, instance *cloud.Instance) error {
-	if m.validateInstanceFunc != nil {
-		return m.validateInstanceFunc(ctx, instance)
-	}
-	return nil
-}
-
-// TestConnectivity implements cloud.CloudProvider interface
-func (m *mockCloudProvider) TestConnectivity(ctx context.Context, instance *cloud.Instance, host string, port int) error {
-	if m.testConnectivityFunc != nil {
-		return m.testConnectivityFunc(ctx, instance, host, port)
-	}
-	return nil
-}
-
-// ExecuteCommand implements cloud.CloudProvider interface
-func (m *mockCloudProvider) ExecuteCommand(ctx context.Context, instance *cloud.Instance, commands []string, timeout time.Duration) (*cloud.CommandResult, error) {
-	if m.executeCommandFunc != nil {
-		return m.executeCommandFunc(ctx, instance, commands, timeout)
-	}
-	return &cloud.CommandResult{Stdout: "", ExitCode: 0}, nil
-}
-
-// TagInstance implements cloud.CloudProvider interface
-func (_ *mockCloudProvider) TagInstance(_ context.Context, _ *cloud.Instance, _ map[string]string) error {
-	return nil
-}
-
-// HasTag implements cloud.CloudProvider interface
-func (_ *mockCloudProvider) HasTag(_ context.Context, _ *cloud.Instance, _, _ string) (bool, error) {
-	return false, nil
-}
-
 // ============================================================
 // HELPER FUNCTIONS - Test utilities
 // ============================================================
@@ -182,8 +132,8 @@ func TestConnectivityValidator_Validate(t *testing.T) {
 			ctx := context.Background()
 			instance := createTestInstance()
 
-			mockProvider := &mockCloudProvider{
-				testConnectivityFunc: func(_ context.Context, _ *cloud.Instance, _ string, _ int) error {
+			mockProvider := &cloudtest.Provider{
+				TestConnectivityFunc: func(_ context.Context, _ *cloud.Instance, _ string, _ int) error {
 					return tt.mockError
 				},
 			}
@@ -228,8 +178,8 @@ func TestConnectivityValidator_Validate_ContextCancellation(t *testing.T) {
 	cancel() // Cancel immediately
 
 	instance := createTestInstance()
-	mockProvider := &mockCloudProvider{
-		testConnectivityFunc: func(ctx context.Context, _ *cloud.Instance, _ string, _ int) error {
+	mockProvider := &cloudtest.Provider{
+		TestConnectivityFunc: func(ctx context.Context, _ *cloud.Instance, _ string, _ int) error {
 			return ctx.Err()
 		},
 	}
@@ -322,8 +272,8 @@ func TestSSMValidator_Validate(t *testing.T) {
 			ctx := context.Background()
 			instance := createTestInstance()
 
-			mockProvider := &mockCloudProvider{
-				validateInstanceFunc: func(_ context.Context, _ *cloud.Instance) error {
+			mockProvider := &cloudtest.Provider{
+				ValidateInstanceFunc: func(_ context.Context, _ *cloud.Instance) error {
 					return tt.mockError
 				},
 			}
@@ -389,11 +339,11 @@ func TestCompositeValidator_Validate_AllPass(t *testing.T) {
 	ctx := context.Background()
 	instance := createTestInstance()
 
-	mockProvider := &mockCloudProvider{
-		validateInstanceFunc: func(_ context.Context, _ *cloud.Instance) error {
+	mockProvider := &cloudtest.Provider{
+		ValidateInstanceFunc: func(_ context.Context, _ *cloud.Instance) error {
 			return nil // SSM check passes
 		},
-		testConnectivityFunc: func(_ context.Context, _ *cloud.Instance, _ string, _ int) error {
+		TestConnectivityFunc: func(_ context.Context, _ *cloud.Instance, _ string, _ int) error {
 			return nil // Connectivity check passes
 		},
 	}
@@ -430,11 +380,11 @@ func TestCompositeValidator_Validate_OneFails(t *testing.T) {
 	ctx := context.Background()
 	instance := createTestInstance()
 
-	mockProvider := &mockCloudProvider{
-		validateInstanceFunc: func(_ context.Context, _ *cloud.Instance) error {
+	mockProvider := &cloudtest.Provider{
+		ValidateInstanceFunc: func(_ context.Context, _ *cloud.Instance) error {
 			return nil // SSM check passes
 		},
-		testConnectivityFunc: func(_ context.Context, _ *cloud.Instance, _ string, _ int) error {
+		TestConnectivityFunc: func(_ context.Context, _ *cloud.Instance, _ string, _ int) error {
 			return errors.New("connection refused") // Connectivity check fails
 		},
 	}
@@ -484,11 +434,11 @@ func TestCompositeValidator_Validate_StopOnFail(t *testing.T) {
 	ctx := context.Background()
 	instance := createTestInstance()
 
-	mockProvider := &mockCloudProvider{
-		validateInstanceFunc: func(_ context.Context, _ *cloud.Instance) error {
+	mockProvider := &cloudtest.Provider{
+		ValidateInstanceFunc: func(_ context.Context, _ *cloud.Instance) error {
 			return errors.New("ssm not available") // First check fails
 		},
-		testConnectivityFunc: func(_ context.Context, _ *cloud.Instance, _ string, _ int) error {
+		TestConnectivityFunc: func(_ context.Context, _ *cloud.Instance, _ string, _ int) error {
 			t.Error("TestConnectivity should not be called when StopOnFail=true and first validator fails")
 			return nil
 		},
@@ -523,8 +473,8 @@ func TestCompositeValidator_Validate_ContextCancellation(t *testing.T) {
 
 	instance := createTestInstance()
 
-	mockProvider := &mockCloudProvider{
-		validateInstanceFunc: func(ctx context.Context, _ *cloud.Instance) error {
+	mockProvider := &cloudtest.Provider{
+		ValidateInstanceFunc: func(ctx context.Context, _ *cloud.Instance) error {
 			// Check if context is already canceled
 			select {
 			case <-ctx.Done():
@@ -533,7 +483,7 @@ func TestCompositeValidator_Validate_ContextCancellation(t *testing.T) {
 				return nil
 			}
 		},
-		testConnectivityFunc: func(_ context.Context, _ *cloud.Instance, _ string, _ int) error {
+		TestConnectivityFunc: func(_ context.Context, _ *cloud.Instance, _ string, _ int) error {
 			return nil
 		},
 	}
@@ -753,11 +703,11 @@ func TestValidatePuppetPrerequisites(t *testing.T) {
 			ctx := context.Background()
 			instance := createTestInstance()
 
-			mockProvider := &mockCloudProvider{
-				validateInstanceFunc: func(_ context.Context, _ *cloud.Instance) error {
+			mockProvider := &cloudtest.Provider{
+				ValidateInstanceFunc: func(_ context.Context, _ *cloud.Instance) error {
 					return tt.ssmError
 				},
-				testConnectivityFunc: func(_ context.Context, _ *cloud.Instance, _ string, _ int) error {
+				TestConnectivityFunc: func(_ context.Context, _ *cloud.Instance, _ string, _ int) error {
 					return tt.connectError
 				},
 			}
@@ -782,6 +732,109 @@ func TestValidatePuppetPrerequisites(t *testing.T) {
 	}
 }
 
+// ============================================================
+// TIMEOUT AND STATS TESTS
+// ============================================================
+
+// TestCompositeValidator_Validate_EnforcesTimeout tests that a hung check
+// (provider ignoring context) is aborted after the validator timeout.
+func TestCompositeValidator_Validate_EnforcesTimeout(t *testing.T) {
+	// ARRANGE
+	instance := createTestInstance()
+	release := make(chan struct{})
+	defer close(release)
+
+	mockProvider := &cloudtest.Provider{
+		TestConnectivityFunc: func(_ context.Context, _ *cloud.Instance, _ string, _ int) error {
+			<-release // Hangs until test ends, ignoring context
+			return nil
+		},
+	}
+
+	validators := []Validator{
+		NewSSMValidator(time.Second),
+		NewConnectivityValidator("puppet", "puppet.example.com", 8140, 50*time.Millisecond),
+	}
+	composite := NewCompositeValidator(validators, false)
+
+	// ACT
+	start := time.Now()
+	results := composite.Validate(context.Background(), instance, mockProvider)
+	elapsed := time.Since(start)
+
+	// ASSERT
+	if elapsed > time.Second {
+		t.Fatalf("Validation took %v, expected to be aborted after ~50ms", elapsed)
+	}
+	if len(results) != 2 {
+		t.Fatalf("len(results) = %d, want 2", len(results))
+	}
+
+	hung := results[1]
+	if hung.Success || !hung.TimedOut {
+		t.Errorf("Expected timed out failure, got Success=%v TimedOut=%v", hung.Success, hung.TimedOut)
+	}
+	if hung.Name != "puppet" {
+		t.Errorf("Name = %q, want %q", hung.Name, "puppet")
+	}
+	if hung.Timeout != 50*time.Millisecond {
+		t.Errorf("Timeout = %v, want %v", hung.Timeout, 50*time.Millisecond)
+	}
+	if results[0].Duration <= 0 || results[0].Timeout != time.Second {
+		t.Errorf("Expected duration and timeout on SSM result, got %v/%v", results[0].Duration, results[0].Timeout)
+	}
+}
+
+// TestSlowestValidation tests selection of the slowest result.
+func TestSlowestValidation(t *testing.T) {
+	if SlowestValidation(nil) != nil {
+		t.Error("Expected nil for empty results")
+	}
+
+	results := []*ValidationResult{
+		{Name: "fast", Duration: 10 * time.Millisecond},
+		{Name: "slow", Duration: 2 * time.Second},
+		{Name: "medium", Duration: time.Second},
+	}
+
+	if got := SlowestValidation(results); got.Name != "slow" {
+		t.Errorf("SlowestValidation() = %q, want %q", got.Name, "slow")
+	}
+}
+
+// TestStatsCollector tests aggregation of validation timings across instances.
+func TestStatsCollector(t *testing.T) {
+	// ARRANGE
+	collector := NewStatsCollector()
+
+	// ACT
+	collector.Record("i-1", []*ValidationResult{
+		{Name: "ssm_connectivity", Duration: 100 * time.Millisecond},
+		{Name: "puppet_server_reachable", Duration: 3 * time.Second, TimedOut: true},
+	})
+	collector.Record("i-2", []*ValidationResult{
+		{Name: "ssm_connectivity", Duration: 300 * time.Millisecond},
+		{Name: "puppet_server_reachable", Duration: time.Second},
+	})
+	stats := collector.Snapshot()
+
+	// ASSERT
+	if len(stats) != 2 {
+		t.Fatalf("len(stats) = %d, want 2", len(stats))
+	}
+
+	slowest := stats[0]
+	if slowest.Name != "puppet_server_reachable" {
+		t.Errorf("stats[0].Name = %q, want %q", slowest.Name, "puppet_server_reachable")
+	}
+	if slowest.SlowestInstance != "i-1" || slowest.TimedOut != 1 || slowest.Count != 2 {
+		t.Errorf("Unexpected stats: %+v", slowest)
+	}
+	if avg := stats[1].AverageDuration(); avg != 200*time.Millisecond {
+		t.Errorf("AverageDuration() = %v, want %v", avg, 200*time.Millisecond)
+	}
+}
+
 // ============================================================
 // UTILITY FUNCTIONS
 // ============================================================
@@ -0,0 +1,6 @@
+// Package version holds the opsmaster release version.
+package version
+
+// Version is the release version, overridden at build time with
+// -ldflags "-X github.com/estudosdevops/opsmaster/internal/version.Version=v1.2.3".
+var Version = "v0.1.0"